@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+)
+
+// backupCommand 备份子命令名称
+const backupCommand = "backup"
+
+// runBackup 请求运行中的服务创建、列出或恢复备份，返回进程退出码
+// 视频元数据保存在服务进程内存中，备份和恢复都由服务执行
+func runBackup(args []string) int {
+	flags := flag.NewFlagSet(backupCommand, flag.ContinueOnError)
+	server := flags.String("server", "http://localhost:8888", "服务地址")
+	token := flags.String("token", os.Getenv("ZHULONG_TOKEN"), "管理员访问令牌，默认读取ZHULONG_TOKEN环境变量")
+	configOut := flags.String("config-out", "", "恢复时将备份中的配置文件写入该路径")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	action := flags.Arg(0)
+	valid := (action == "create" || action == "list") && flags.NArg() == 1 ||
+		action == "restore" && flags.NArg() == 2
+	if !valid || *token == "" {
+		fmt.Fprintf(os.Stderr, "用法: zhulong %s [-server <服务地址>] [-token <令牌>] [-config-out <文件>] create | list | restore <备份名称|latest>\n", backupCommand)
+		return 2
+	}
+
+	client := &backupClient{baseURL: strings.TrimRight(*server, "/"), token: *token}
+	switch action {
+	case "create":
+		var result api.BackupResponse
+		if err := client.do(http.MethodPost, "", nil, &result); err != nil {
+			fmt.Fprintf(os.Stderr, "备份失败: %v\n", err)
+			return 1
+		}
+		fmt.Printf("备份完成: %s (%d字节)\n", result.Backup.Name, result.Backup.Size)
+	case "list":
+		var result api.BackupListResponse
+		if err := client.do(http.MethodGet, "", nil, &result); err != nil {
+			fmt.Fprintf(os.Stderr, "获取备份列表失败: %v\n", err)
+			return 1
+		}
+		for _, info := range result.Backups {
+			fmt.Printf("%s\t%s\t%d\n", info.Name, time.UnixMilli(info.CreatedAt).Format(time.RFC3339), info.Size)
+		}
+	case "restore":
+		var result api.BackupRestoreResponse
+		if err := client.do(http.MethodPost, "/restore", &api.BackupRestoreRequest{Name: flags.Arg(1)}, &result); err != nil {
+			fmt.Fprintf(os.Stderr, "恢复备份失败: %v\n", err)
+			return 1
+		}
+		fmt.Printf("已从 %s 恢复%d个视频的元数据\n", result.Backup.Name, result.RestoredVideos)
+		if result.Config == nil {
+			return 0
+		}
+		if *configOut == "" {
+			fmt.Printf("备份包含配置文件 %s，使用 -config-out 写出\n", result.GetConfigFile())
+			return 0
+		}
+		if err := os.WriteFile(*configOut, []byte(*result.Config), 0o600); err != nil {
+			fmt.Fprintf(os.Stderr, "写入配置文件失败: %v\n", err)
+			return 1
+		}
+		fmt.Printf("配置文件已写入 %s，重启服务后生效\n", *configOut)
+	}
+	return 0
+}
+
+// backupClient 备份接口客户端
+type backupClient struct {
+	baseURL string
+	token   string
+}
+
+// do 调用备份接口，path为/api/v1/admin/backups之后的路径
+func (c *backupClient) do(method, path string, body any, result interface{ GetBase() *api.BaseResponse }) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("编码请求失败: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+"/api/v1/admin/backups"+path, reader)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求服务失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("解析响应失败(HTTP %d): %w", resp.StatusCode, err)
+	}
+	if base := result.GetBase(); base == nil || base.Code != 0 {
+		if base != nil {
+			return fmt.Errorf("HTTP %d: %s", resp.StatusCode, base.Message)
+		}
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
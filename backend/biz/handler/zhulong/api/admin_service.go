@@ -0,0 +1,380 @@
+// Code generated by hertz generator.
+
+package api
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/download"
+)
+
+// GetAdminStats .
+// @router /api/v1/admin/stats [GET]
+func GetAdminStats(ctx context.Context, c *app.RequestContext) {
+	resp, err := videoService.GetAdminStats(ctx, userService.CountUsers(ctx))
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.AdminStatsResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(consts.StatusOK, resp)
+}
+
+// RevokePlaybackTokens .
+// @router /api/v1/admin/playback/revoke [POST]
+func RevokePlaybackTokens(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.PlaybackRevokeRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.PlaybackRevokeResponse{
+			Base: &api.BaseResponse{
+				Code:    6402,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.RevokePlaybackTokens(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.PlaybackRevokeResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// ListModerationQueue .
+// @router /api/v1/admin/moderation [GET]
+func ListModerationQueue(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.ModerationListRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.ModerationListResponse{
+			Base: &api.BaseResponse{
+				Code:    9501,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.ListModerationQueue(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.ModerationListResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// ReviewModeration .
+// @router /api/v1/admin/moderation/:video_id [POST]
+func ReviewModeration(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.ModerationReviewRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.ModerationReviewResponse{
+			Base: &api.BaseResponse{
+				Code:    9501,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.ReviewModeration(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.ModerationReviewResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 9502:
+		c.JSON(consts.StatusNotFound, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// CreateBackup .
+// @router /api/v1/admin/backups [POST]
+func CreateBackup(ctx context.Context, c *app.RequestContext) {
+	resp, err := videoService.CreateBackup(ctx)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.BackupResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusCreated, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// ListBackups .
+// @router /api/v1/admin/backups [GET]
+func ListBackups(ctx context.Context, c *app.RequestContext) {
+	resp, err := videoService.ListBackups(ctx)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.BackupListResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// RestoreBackup .
+// @router /api/v1/admin/backups/restore [POST]
+func RestoreBackup(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.BackupRestoreRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.BackupRestoreResponse{
+			Base: &api.BaseResponse{
+				Code:    9701,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.RestoreBackup(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.BackupRestoreResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 9702:
+		c.JSON(consts.StatusNotFound, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// GetReplicationReport .
+// @router /api/v1/admin/replication [GET]
+func GetReplicationReport(ctx context.Context, c *app.RequestContext) {
+	resp, err := videoService.GetReplicationReport(ctx)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.ReplicationReportResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// SyncReplication .
+// @router /api/v1/admin/replication/sync [POST]
+func SyncReplication(ctx context.Context, c *app.RequestContext) {
+	resp, err := videoService.SyncReplication(ctx)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.ReplicationReportResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusAccepted, resp)
+	case 9802:
+		c.JSON(consts.StatusConflict, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// CheckConsistency .
+// @router /api/v1/admin/consistency [GET]
+func CheckConsistency(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.ConsistencyRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.ConsistencyResponse{
+			Base: &api.BaseResponse{
+				Code:    9901,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.CheckConsistency(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.ConsistencyResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// ExportCatalog .
+// @router /api/v1/admin/catalog [GET]
+func ExportCatalog(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.CatalogExportRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.CatalogExportResponse{
+			Base: &api.BaseResponse{
+				Code:    7201,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.ExportCatalog(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.CatalogExportResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	// 成功时以附件形式返回目录文件
+	if resp.Base.Code != 0 {
+		c.JSON(consts.StatusBadRequest, &api.CatalogExportResponse{Base: resp.Base})
+		return
+	}
+	c.Header("Content-Disposition", download.ContentDisposition(resp.FileName))
+	c.Data(consts.StatusOK, resp.ContentType, resp.Data)
+}
+
+// ImportCatalog .
+// @router /api/v1/admin/catalog/import [POST]
+func ImportCatalog(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.CatalogImportRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.CatalogImportResponse{
+			Base: &api.BaseResponse{
+				Code:    7201,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.CatalogImportResponse{
+			Base: &api.BaseResponse{
+				Code:    7201,
+				Message: "获取目录文件失败: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.ImportCatalog(ctx, &req, fileHeader)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.CatalogImportResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
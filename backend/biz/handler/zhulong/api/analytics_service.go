@@ -0,0 +1,47 @@
+// Code generated by hertz generator.
+
+package api
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+)
+
+// GetTopVideos .
+// @router /api/v1/analytics/top-videos [GET]
+func GetTopVideos(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.TopVideosRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.TopVideosResponse{
+			Base: &api.BaseResponse{
+				Code:    9201,
+				Message: "请求参数错误: " + err.Error(),
+			},
+			Videos: []*api.VideoViewStat{},
+		})
+		return
+	}
+
+	resp, err := videoService.GetTopVideos(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.TopVideosResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+			Videos: []*api.VideoViewStat{},
+		})
+		return
+	}
+
+	if resp.Base.Code == 0 {
+		c.JSON(consts.StatusOK, resp)
+	} else {
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
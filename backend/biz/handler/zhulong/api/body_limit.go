@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	"github.com/manteia/zhulong/pkg/middleware"
+)
+
+// multipartFormOverhead 表单上传时请求体中除视频文件外的部分（分隔符、表单字段）允许的大小
+const multipartFormOverhead = 1024 * 1024
+
+var (
+	bodyLimitPolicy     *middleware.BodyLimitPolicy
+	bodyLimitPolicyOnce sync.Once
+)
+
+// UploadBodyLimitPolicy 获取上传请求体大小限制策略，供路由请求体大小限制中间件使用
+// 限制按请求时的上传配置计算，配置重新加载后立即生效
+func UploadBodyLimitPolicy() *middleware.BodyLimitPolicy {
+	bodyLimitPolicyOnce.Do(func() {
+		bodyLimitPolicy = middleware.NewBodyLimitPolicy()
+		bodyLimitPolicy.SetRoute("POST", "/api/v1/videos", maxUploadFormSize)
+		bodyLimitPolicy.SetRoute("POST", "/api/v1/videos/:video_id/versions", maxUploadFormSize)
+		bodyLimitPolicy.SetRoute("PUT", "/storage/:bucket/*object", maxUploadFileSize)
+		bodyLimitPolicy.SetRoute("PUT", "/api/v1/uploads/:upload_id/parts/:part_number", maxMultipartPartSize)
+	})
+	return bodyLimitPolicy
+}
+
+// maxUploadFormSize 表单上传视频的最大请求体大小
+func maxUploadFormSize(ctx context.Context, c *app.RequestContext) int64 {
+	return videoService.MaxUploadSize(ctx) + multipartFormOverhead
+}
+
+// maxUploadFileSize 直传视频文件的最大请求体大小
+func maxUploadFileSize(ctx context.Context, c *app.RequestContext) int64 {
+	return videoService.MaxUploadSize(ctx)
+}
+
+// maxMultipartPartSize 分片的最大请求体大小，即初始化时的分片大小
+func maxMultipartPartSize(ctx context.Context, c *app.RequestContext) int64 {
+	return videoService.MaxMultipartPartSize(ctx, c.Param("upload_id"))
+}
@@ -0,0 +1,140 @@
+// Code generated by hertz generator.
+
+package api
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+)
+
+// CreateCollection .
+// @router /api/v1/collections [POST]
+func CreateCollection(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.CollectionCreateRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, collectionBindErrorResponse(err))
+		return
+	}
+
+	resp, err := videoService.CreateCollection(ctx, &req)
+	writeCollectionResponse(c, resp, err)
+}
+
+// ListCollections .
+// @router /api/v1/collections [GET]
+func ListCollections(ctx context.Context, c *app.RequestContext) {
+	resp, err := videoService.ListCollections(ctx)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.CollectionListResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+			Collections: []*api.Collection{},
+		})
+		return
+	}
+
+	c.JSON(consts.StatusOK, resp)
+}
+
+// UpdateCollection .
+// @router /api/v1/collections/:collection_id [PUT]
+func UpdateCollection(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.CollectionUpdateRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, collectionBindErrorResponse(err))
+		return
+	}
+
+	resp, err := videoService.UpdateCollection(ctx, &req)
+	writeCollectionResponse(c, resp, err)
+}
+
+// DeleteCollection .
+// @router /api/v1/collections/:collection_id [DELETE]
+func DeleteCollection(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.CollectionDeleteRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, collectionBindErrorResponse(err))
+		return
+	}
+
+	resp, err := videoService.DeleteCollection(ctx, &req)
+	writeCollectionResponse(c, resp, err)
+}
+
+// AddCollectionVideos .
+// @router /api/v1/collections/:collection_id/videos [POST]
+func AddCollectionVideos(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.CollectionVideosRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, collectionBindErrorResponse(err))
+		return
+	}
+
+	resp, err := videoService.AddCollectionVideos(ctx, &req)
+	writeCollectionResponse(c, resp, err)
+}
+
+// RemoveCollectionVideos .
+// @router /api/v1/collections/:collection_id/videos [DELETE]
+func RemoveCollectionVideos(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.CollectionVideosRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, collectionBindErrorResponse(err))
+		return
+	}
+
+	resp, err := videoService.RemoveCollectionVideos(ctx, &req)
+	writeCollectionResponse(c, resp, err)
+}
+
+// collectionBindErrorResponse 创建请求参数绑定失败的响应
+func collectionBindErrorResponse(err error) *api.CollectionResponse {
+	return &api.CollectionResponse{
+		Base: &api.BaseResponse{
+			Code:    4201,
+			Message: "请求参数错误: " + err.Error(),
+		},
+	}
+}
+
+// writeCollectionResponse 根据业务错误码写入合集响应
+func writeCollectionResponse(c *app.RequestContext, resp *api.CollectionResponse, err error) {
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.CollectionResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 4202, 4204:
+		c.JSON(consts.StatusNotFound, resp)
+	case 4203:
+		c.JSON(consts.StatusConflict, resp)
+	case 4205:
+		c.JSON(consts.StatusForbidden, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
@@ -0,0 +1,86 @@
+// Code generated by hertz generator.
+
+package api
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+)
+
+// GetEmbedPlayer .
+// @router /embed/:video_id [GET]
+func GetEmbedPlayer(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.EmbedPlayerRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		writeEmbedPage(c, consts.StatusBadRequest, &api.EmbedPlayerResponse{
+			Base: &api.BaseResponse{
+				Code:    6601,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.GetEmbedPlayer(ctx, &req)
+	if err != nil {
+		writeEmbedPage(c, consts.StatusInternalServerError, &api.EmbedPlayerResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	// 嵌入播放器总是返回HTML页面，错误时在页面中显示错误信息
+	writeEmbedPage(c, embedStatusCode(resp.Base.Code), resp)
+}
+
+// GetOEmbed .
+// @router /oembed [GET]
+func GetOEmbed(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.OEmbedRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.OEmbedResponse{
+			Base: &api.BaseResponse{
+				Code:    6601,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.GetOEmbed(ctx, &req, requestBaseURL(c))
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.OEmbedResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(embedStatusCode(resp.Base.Code), resp)
+}
+
+// embedStatusCode 嵌入业务错误码对应的HTTP状态码，与oEmbed规范的错误状态码一致
+func embedStatusCode(code int32) int {
+	switch code {
+	case 0:
+		return consts.StatusOK
+	case 6602:
+		return consts.StatusNotFound
+	case 6603:
+		return consts.StatusNotImplemented
+	default:
+		return consts.StatusBadRequest
+	}
+}
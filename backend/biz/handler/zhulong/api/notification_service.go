@@ -0,0 +1,44 @@
+// Code generated by hertz generator.
+
+package api
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/notify"
+)
+
+// SubscribeNotifications .
+// @router /api/v1/notifications/ws [GET]
+func SubscribeNotifications(ctx context.Context, c *app.RequestContext) {
+	key := string(c.GetHeader("Sec-WebSocket-Key"))
+	err := notify.ValidateHandshake(
+		string(c.GetHeader("Upgrade")),
+		string(c.GetHeader("Connection")),
+		string(c.GetHeader("Sec-WebSocket-Version")),
+		key,
+	)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.NotificationResponse{
+			Base: &api.BaseResponse{
+				Code:    9001,
+				Message: "WebSocket握手失败: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	c.SetStatusCode(consts.StatusSwitchingProtocols)
+	c.Response.Header.Set("Upgrade", "websocket")
+	c.Response.Header.Set("Connection", "Upgrade")
+	c.Response.Header.Set("Sec-WebSocket-Accept", notify.AcceptKey(key))
+	c.Hijack(func(conn network.Conn) {
+		sub := videoService.SubscribeNotifications(ctx)
+		defer sub.Close()
+		notify.ServeConn(conn, sub)
+	})
+}
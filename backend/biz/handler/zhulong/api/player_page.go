@@ -0,0 +1,128 @@
+package api
+
+import (
+	"bytes"
+	"html/template"
+	"net/url"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/middleware"
+)
+
+// playerPageTemplate 播放页面，用于分享链接和嵌入播放器
+// 分享需要密码时显示密码输入框；嵌入时播放器填满整个页面
+var playerPageTemplate = template.Must(template.New("player").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{if .Video}}{{.Video.Title}}{{else}}视频播放{{end}} - 烛龙</title>
+{{if .OEmbedURL}}<link rel="alternate" type="application/json+oembed" href="{{.OEmbedURL}}"{{if .Video}} title="{{.Video.Title}}"{{end}}>
+{{end}}<style>
+{{if .Embed}}html, body { margin: 0; height: 100%; background: #000; overflow: hidden; }
+video { width: 100%; height: 100%; }
+{{else}}body { margin: 0 auto; max-width: 960px; padding: 16px; font-family: sans-serif; }
+video { width: 100%; background: #000; }
+{{end}}.message { color: #c00; }
+</style>
+</head>
+<body>
+{{if .Video}}
+{{if not .Embed}}<h1>{{.Video.Title}}</h1>
+{{end}}<video controls preload="metadata" src="{{.PlayURL}}"{{if .ThumbnailURL}} poster="{{.ThumbnailURL}}"{{end}}>
+{{range .Subtitles}}<track kind="subtitles" srclang="{{.Language}}" label="{{.Language}}" src="{{.URL}}">
+{{end}}</video>
+{{if and .Video.Description (not .Embed)}}<p>{{.Video.Description}}</p>{{end}}
+{{else}}
+<p class="message">{{.Message}}</p>
+{{if .PasswordRequired}}
+<form method="get">
+<input type="password" name="password" placeholder="请输入访问密码" autofocus required>
+<button type="submit">访问</button>
+</form>
+{{end}}
+{{end}}
+</body>
+</html>
+`))
+
+// playerPage 播放页面数据，嵌入播放器页面提供oEmbed发现地址
+type playerPage struct {
+	Embed            bool
+	OEmbedURL        string
+	Video            *api.SharedVideo
+	PlayURL          string
+	ThumbnailURL     string
+	Subtitles        []*api.SubtitleTrack
+	Message          string
+	PasswordRequired bool
+}
+
+// prefersHTML 请求是否优先接受HTML，浏览器直接打开链接时为true
+func prefersHTML(c *app.RequestContext) bool {
+	accept := string(c.GetHeader("Accept"))
+	return strings.Contains(accept, "text/html")
+}
+
+// writeSharePage 将访问分享的结果渲染为播放页面
+func writeSharePage(c *app.RequestContext, status int, resp *api.ShareResolveResponse) {
+	page := &playerPage{
+		Message:          resp.Base.Message,
+		PasswordRequired: resp.Base.Code == 6503 || resp.Base.Code == 6504,
+	}
+	if resp.Base.Code == 0 {
+		page.setVideo(resp.Video, resp.GetPlayURL(), resp.GetThumbnailURL(), resp.Subtitles)
+	}
+	writePlayerPage(c, status, page)
+}
+
+// writeEmbedPage 将嵌入播放器的结果渲染为播放页面
+func writeEmbedPage(c *app.RequestContext, status int, resp *api.EmbedPlayerResponse) {
+	page := &playerPage{
+		Embed:   true,
+		Message: resp.Base.Message,
+	}
+	if resp.Base.Code == 0 {
+		page.setVideo(resp.Video, resp.GetPlayURL(), resp.GetThumbnailURL(), resp.Subtitles)
+		embedURL := requestBaseURL(c) + string(c.Path())
+		page.OEmbedURL = requestBaseURL(c) + "/oembed?url=" + url.QueryEscape(embedURL)
+	}
+	writePlayerPage(c, status, page)
+}
+
+// setVideo 设置播放页面的视频信息
+func (p *playerPage) setVideo(video *api.SharedVideo, playURL, thumbnailURL string, subtitles []*api.SubtitleTrack) {
+	p.Video = video
+	p.PlayURL = playURL
+	p.ThumbnailURL = thumbnailURL
+	// 浏览器只支持WebVTT格式的字幕轨道
+	for _, subtitle := range subtitles {
+		if subtitle.Format == "vtt" {
+			p.Subtitles = append(p.Subtitles, subtitle)
+		}
+	}
+}
+
+// writePlayerPage 渲染播放页面
+// 覆盖接口默认的内容安全策略：嵌入播放器允许任意页面通过iframe嵌入，分享页面包含密码输入框，不允许被嵌入
+func writePlayerPage(c *app.RequestContext, status int, page *playerPage) {
+	frameAncestors := "'none'"
+	if page.Embed {
+		frameAncestors = "*"
+	}
+	c.Header("Content-Security-Policy", middleware.PlayerContentSecurityPolicy+"; frame-ancestors "+frameAncestors)
+
+	var body bytes.Buffer
+	if err := playerPageTemplate.Execute(&body, page); err != nil {
+		c.String(status, page.Message)
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", body.Bytes())
+}
+
+// requestBaseURL 请求的协议和主机，如http://nas.lan:8888
+func requestBaseURL(c *app.RequestContext) string {
+	return string(c.URI().Scheme()) + "://" + string(c.Host())
+}
@@ -0,0 +1,203 @@
+// Code generated by hertz generator.
+
+package api
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+)
+
+// CreatePlaylist .
+// @router /api/v1/playlists [POST]
+func CreatePlaylist(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.PlaylistCreateRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, playlistBindErrorResponse(err))
+		return
+	}
+
+	resp, err := videoService.CreatePlaylist(ctx, &req)
+	writePlaylistResponse(c, resp, err)
+}
+
+// ListPlaylists .
+// @router /api/v1/playlists [GET]
+func ListPlaylists(ctx context.Context, c *app.RequestContext) {
+	resp, err := videoService.ListPlaylists(ctx)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.PlaylistListResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+			Playlists: []*api.Playlist{},
+		})
+		return
+	}
+
+	c.JSON(consts.StatusOK, resp)
+}
+
+// GetPlaylist .
+// @router /api/v1/playlists/:playlist_id [GET]
+func GetPlaylist(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.PlaylistRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, playlistBindErrorResponse(err))
+		return
+	}
+
+	resp, err := videoService.GetPlaylist(ctx, &req)
+	writePlaylistResponse(c, resp, err)
+}
+
+// UpdatePlaylist .
+// @router /api/v1/playlists/:playlist_id [PUT]
+func UpdatePlaylist(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.PlaylistUpdateRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, playlistBindErrorResponse(err))
+		return
+	}
+
+	resp, err := videoService.UpdatePlaylist(ctx, &req)
+	writePlaylistResponse(c, resp, err)
+}
+
+// DeletePlaylist .
+// @router /api/v1/playlists/:playlist_id [DELETE]
+func DeletePlaylist(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.PlaylistRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, playlistBindErrorResponse(err))
+		return
+	}
+
+	resp, err := videoService.DeletePlaylist(ctx, &req)
+	writePlaylistResponse(c, resp, err)
+}
+
+// AddPlaylistVideos .
+// @router /api/v1/playlists/:playlist_id/videos [POST]
+func AddPlaylistVideos(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.PlaylistVideosRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, playlistBindErrorResponse(err))
+		return
+	}
+
+	resp, err := videoService.AddPlaylistVideos(ctx, &req)
+	writePlaylistResponse(c, resp, err)
+}
+
+// RemovePlaylistVideos .
+// @router /api/v1/playlists/:playlist_id/videos [DELETE]
+func RemovePlaylistVideos(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.PlaylistVideosRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, playlistBindErrorResponse(err))
+		return
+	}
+
+	resp, err := videoService.RemovePlaylistVideos(ctx, &req)
+	writePlaylistResponse(c, resp, err)
+}
+
+// ReorderPlaylist .
+// @router /api/v1/playlists/:playlist_id/order [PUT]
+func ReorderPlaylist(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.PlaylistVideosRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, playlistBindErrorResponse(err))
+		return
+	}
+
+	resp, err := videoService.ReorderPlaylist(ctx, &req)
+	writePlaylistResponse(c, resp, err)
+}
+
+// NavigatePlaylist .
+// @router /api/v1/playlists/:playlist_id/navigation [GET]
+func NavigatePlaylist(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.PlaylistNavigationRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.PlaylistNavigationResponse{
+			Base: &api.BaseResponse{
+				Code:    4301,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.NavigatePlaylist(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.PlaylistNavigationResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(playlistStatusCode(resp.Base.Code), resp)
+}
+
+// playlistBindErrorResponse 创建请求参数绑定失败的响应
+func playlistBindErrorResponse(err error) *api.PlaylistResponse {
+	return &api.PlaylistResponse{
+		Base: &api.BaseResponse{
+			Code:    4301,
+			Message: "请求参数错误: " + err.Error(),
+		},
+	}
+}
+
+// writePlaylistResponse 根据业务错误码写入播放列表响应
+func writePlaylistResponse(c *app.RequestContext, resp *api.PlaylistResponse, err error) {
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.PlaylistResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(playlistStatusCode(resp.Base.Code), resp)
+}
+
+// playlistStatusCode 播放列表业务错误码对应的HTTP状态码
+func playlistStatusCode(code int32) int {
+	switch code {
+	case 0:
+		return consts.StatusOK
+	case 4302, 4303, 4304:
+		return consts.StatusNotFound
+	case 4305:
+		return consts.StatusForbidden
+	default:
+		return consts.StatusBadRequest
+	}
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"sync"
+
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/middleware"
+)
+
+// rateLimitRoute 使用独立限流规则的路由
+type rateLimitRoute struct {
+	method string
+	path   string
+}
+
+var (
+	// uploadRoutes 上传相关路由，共用上传限流配额；分片上传只限制初始化和完成，分片可以并行上传
+	uploadRoutes = []rateLimitRoute{
+		{"POST", "/api/v1/videos"},
+		{"POST", "/api/v1/videos/upload-url"},
+		{"POST", "/api/v1/videos/confirm"},
+		{"POST", "/api/v1/uploads"},
+		{"POST", "/api/v1/uploads/:upload_id/complete"},
+		{"POST", "/api/v1/videos/:video_id/versions"},
+		{"PUT", "/storage/:bucket/*object"},
+	}
+	// playbackRoutes 播放相关路由，共用播放限流配额
+	playbackRoutes = []rateLimitRoute{
+		{"GET", "/api/v1/videos/:video_id/play"},
+		{"GET", "/api/v1/videos/:video_id/stream"},
+		{"GET", "/api/v1/videos/:video_id/download"},
+		{"POST", "/api/v1/videos/export"},
+		{"GET", "/api/v1/videos/:video_id/hls/:playlist"},
+		{"GET", "/api/v1/videos/:video_id/manifest.mpd"},
+		{"GET", "/api/v1/videos/:video_id/thumbnails.vtt"},
+		{"GET", "/storage/:bucket/*object"},
+		{"HEAD", "/storage/:bucket/*object"},
+		{"GET", "/stream/:video_id"},
+		{"GET", "/s/:token"},
+		{"GET", "/embed/:video_id"},
+	}
+)
+
+var (
+	rateLimitPolicy     *middleware.RateLimitPolicy
+	rateLimiters        rateLimiterSet
+	rateLimitPolicyOnce sync.Once
+
+	bandwidthPolicy     *middleware.BandwidthPolicy
+	bandwidthPolicyOnce sync.Once
+)
+
+// rateLimiterSet 限流策略使用的各规则限流器，配置重新加载时更新速率
+type rateLimiterSet struct {
+	global   *middleware.RateLimiter
+	upload   *middleware.RateLimiter
+	playback *middleware.RateLimiter
+}
+
+// RateLimitPolicy 获取限流策略，供路由限流中间件使用
+// 未启用限流时策略处于关闭状态，配置重新加载后可以开启
+func RateLimitPolicy() *middleware.RateLimitPolicy {
+	rateLimitPolicyOnce.Do(func() {
+		cfg := videoService.Config().RateLimit
+		rateLimiters = rateLimiterSet{
+			global:   newRateLimiter(cfg.Global),
+			upload:   newRateLimiter(cfg.Upload),
+			playback: newRateLimiter(cfg.Playback),
+		}
+
+		rateLimitPolicy = middleware.NewRateLimitPolicy(rateLimiters.global)
+		rateLimitPolicy.SetEnabled(cfg.Enabled)
+		setRouteLimiter(rateLimitPolicy, uploadRoutes, rateLimiters.upload)
+		setRouteLimiter(rateLimitPolicy, playbackRoutes, rateLimiters.playback)
+	})
+	return rateLimitPolicy
+}
+
+// applyRateLimitConfig 应用重新加载的限流配置，修改开关和各规则的速率
+// 已有客户端的剩余令牌保留，不会因为重新加载而重置配额
+func applyRateLimitConfig(cfg config.RateLimitConfig) {
+	policy := RateLimitPolicy()
+	rateLimiters.global.SetRate(cfg.Global.RequestsPerSecond, cfg.Global.Burst)
+	rateLimiters.upload.SetRate(cfg.Upload.RequestsPerSecond, cfg.Upload.Burst)
+	rateLimiters.playback.SetRate(cfg.Playback.RequestsPerSecond, cfg.Playback.Burst)
+	policy.SetEnabled(cfg.Enabled)
+}
+
+// newRateLimiter 根据配置创建限流器
+func newRateLimiter(rule config.RateLimitRule) *middleware.RateLimiter {
+	return middleware.NewRateLimiter(rule.RequestsPerSecond, rule.Burst)
+}
+
+// setRouteLimiter 为一组路由设置共用的限流器
+func setRouteLimiter(policy *middleware.RateLimitPolicy, routes []rateLimitRoute, limiter *middleware.RateLimiter) {
+	for _, route := range routes {
+		policy.SetRouteLimiter(route.method, route.path, limiter)
+	}
+}
+
+// UploadBandwidthPolicy 获取上传带宽限制策略，供路由带宽限制中间件使用，未配置带宽限制时返回nil
+// 限制所有上传路由及分片上传的请求体读取速率，避免大文件上传占满带宽影响播放
+func UploadBandwidthPolicy() *middleware.BandwidthPolicy {
+	bandwidthPolicyOnce.Do(func() {
+		cfg := videoService.Config()
+		// 配置加载时已校验格式
+		globalLimit, _ := cfg.GetUploadBandwidthLimit()
+		requestLimit, _ := cfg.GetUploadConnectionBandwidthLimit()
+
+		bandwidthPolicy = middleware.NewBandwidthPolicy(globalLimit, requestLimit)
+		if bandwidthPolicy == nil {
+			return
+		}
+		for _, route := range uploadRoutes {
+			bandwidthPolicy.SetRoute(route.method, route.path)
+		}
+		bandwidthPolicy.SetRoute("PUT", "/api/v1/uploads/:upload_id/parts/:part_number")
+	})
+	return bandwidthPolicy
+}
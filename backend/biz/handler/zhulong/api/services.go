@@ -0,0 +1,29 @@
+package api
+
+import (
+	"github.com/manteia/zhulong/biz/service"
+	"github.com/manteia/zhulong/pkg/config"
+)
+
+var (
+	// videoService 视频服务实例，由SetServices注入
+	videoService *service.VideoService
+	// userService 用户服务实例，由SetServices注入
+	userService *service.UserService
+)
+
+// SetServices 注入处理器使用的服务实例，需要在注册路由前调用
+func SetServices(video *service.VideoService, user *service.UserService) {
+	videoService = video
+	userService = user
+}
+
+// ApplyConfig 应用重新加载的配置：上传的大小和格式限制、限流开关和规则
+// 配置无效时返回错误，所有设置保持不变
+func ApplyConfig(cfg *config.Config) error {
+	if err := videoService.ApplyConfig(cfg); err != nil {
+		return err
+	}
+	applyRateLimitConfig(cfg.RateLimit)
+	return nil
+}
@@ -0,0 +1,149 @@
+// Code generated by hertz generator.
+
+package api
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+)
+
+// CreateShare .
+// @router /api/v1/videos/:video_id/shares [POST]
+func CreateShare(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.ShareCreateRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, shareBindErrorResponse(err))
+		return
+	}
+
+	resp, err := videoService.CreateShare(ctx, &req)
+	writeShareResponse(c, resp, err)
+}
+
+// ListShares .
+// @router /api/v1/shares [GET]
+func ListShares(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.ShareListRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.ShareListResponse{
+			Base:   shareBindErrorResponse(err).Base,
+			Shares: []*api.VideoShare{},
+		})
+		return
+	}
+
+	resp, err := videoService.ListShares(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.ShareListResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+			Shares: []*api.VideoShare{},
+		})
+		return
+	}
+
+	c.JSON(consts.StatusOK, resp)
+}
+
+// RevokeShare .
+// @router /api/v1/shares/:token [DELETE]
+func RevokeShare(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.ShareRevokeRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, shareBindErrorResponse(err))
+		return
+	}
+
+	resp, err := videoService.RevokeShare(ctx, &req)
+	writeShareResponse(c, resp, err)
+}
+
+// ResolveShare .
+// @router /s/:token [GET]
+func ResolveShare(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.ShareResolveRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.ShareResolveResponse{
+			Base: shareBindErrorResponse(err).Base,
+		})
+		return
+	}
+
+	resp, err := videoService.ResolveShare(ctx, &req)
+	if err != nil {
+		resp = &api.ShareResolveResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		}
+	}
+
+	status := consts.StatusInternalServerError
+	if err == nil {
+		status = shareStatusCode(resp.Base.Code)
+	}
+
+	// 浏览器直接打开分享地址时返回播放页面
+	if prefersHTML(c) {
+		writeSharePage(c, status, resp)
+		return
+	}
+	c.JSON(status, resp)
+}
+
+// shareBindErrorResponse 创建分享参数绑定错误响应
+func shareBindErrorResponse(err error) *api.ShareResponse {
+	return &api.ShareResponse{
+		Base: &api.BaseResponse{
+			Code:    6501,
+			Message: "请求参数错误: " + err.Error(),
+		},
+	}
+}
+
+// writeShareResponse 根据业务错误码写入分享响应
+func writeShareResponse(c *app.RequestContext, resp *api.ShareResponse, err error) {
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.ShareResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(shareStatusCode(resp.Base.Code), resp)
+}
+
+// shareStatusCode 分享业务错误码对应的HTTP状态码
+func shareStatusCode(code int32) int {
+	switch code {
+	case 0:
+		return consts.StatusOK
+	case 6502:
+		return consts.StatusNotFound
+	case 6503:
+		return consts.StatusUnauthorized
+	case 6504, 6506:
+		return consts.StatusForbidden
+	case 6505:
+		return consts.StatusGone
+	default:
+		return consts.StatusBadRequest
+	}
+}
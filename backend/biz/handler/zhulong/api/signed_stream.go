@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/biz/service"
+	"github.com/manteia/zhulong/pkg/download"
+)
+
+// ServeSignedStream 使用播放令牌访问视频流
+// 令牌由获取播放URL接口签发并绑定用户、视频和过期时间，请求不需要登录
+func ServeSignedStream(ctx context.Context, c *app.RequestContext) {
+	stream, err := videoService.StreamSignedVideo(ctx, c.Param("video_id"), c.Query("token"), string(c.GetHeader("Range")))
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoStreamResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	writeVideoStream(c, stream)
+}
+
+// ServeSignedPoster 使用播放令牌读取视频的海报，投屏设备加载海报时不携带登录凭据
+func ServeSignedPoster(ctx context.Context, c *app.RequestContext) {
+	asset, err := videoService.GetSignedPoster(ctx, c.Param("video_id"), c.Query("token"))
+	writeCastAsset(c, asset, err)
+}
+
+// ServeSignedSubtitle 使用播放令牌读取视频的WebVTT字幕
+func ServeSignedSubtitle(ctx context.Context, c *app.RequestContext) {
+	asset, err := videoService.GetSignedSubtitle(ctx, c.Param("video_id"), c.Param("name"), c.Query("token"))
+	writeCastAsset(c, asset, err)
+}
+
+// writeCastAsset 返回投屏资源内容，错误时返回对应状态码的JSON响应
+func writeCastAsset(c *app.RequestContext, asset *service.CastAsset, err error) {
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoCastResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp := &api.VideoCastResponse{Base: asset.Base}
+	switch asset.Base.Code {
+	case 0:
+		c.Data(consts.StatusOK, asset.ContentType, asset.Data)
+	case 6401:
+		c.JSON(consts.StatusForbidden, resp)
+	case 7102, 7103:
+		c.JSON(consts.StatusNotFound, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// writeVideoDownload 以附件形式返回视频下载流或导出的压缩包，原始文件支持Range断点续传
+func writeVideoDownload(c *app.RequestContext, resp *service.VideoDownload) {
+	body := &api.VideoDownloadResponse{Base: resp.Base}
+	switch resp.Base.Code {
+	case 0:
+	case 2402, 2403, 2502:
+		c.JSON(consts.StatusNotFound, body)
+		return
+	case 2404:
+		c.Header("Content-Range", fmt.Sprintf("bytes */%d", resp.Stream.Size))
+		c.JSON(consts.StatusRequestedRangeNotSatisfiable, body)
+		return
+	default:
+		c.JSON(consts.StatusBadRequest, body)
+		return
+	}
+
+	stream := resp.Stream
+	c.Header("Content-Disposition", download.ContentDisposition(resp.FileName))
+	// 拼接多个分片的档位没有ETag，不支持断点续传
+	if stream.ETag != "" {
+		c.Header("Accept-Ranges", "bytes")
+		c.Header("ETag", `"`+strings.Trim(stream.ETag, `"`)+`"`)
+	}
+	if !stream.LastModified.IsZero() {
+		c.Header("Last-Modified", stream.LastModified.UTC().Format(http.TimeFormat))
+	}
+	c.SetContentType(stream.ContentType)
+
+	status := consts.StatusOK
+	if stream.Range != nil {
+		status = consts.StatusPartialContent
+		c.Header("Content-Range", stream.Range.ContentRange(stream.Size))
+	}
+	c.SetStatusCode(status)
+	c.SetBodyStream(stream.Body, int(stream.ContentLength()))
+}
+
+// writeVideoStream 返回视频流，Range请求返回206以支持拖动播放；错误时返回对应状态码的JSON响应
+func writeVideoStream(c *app.RequestContext, stream *service.VideoStream) {
+	resp := &api.VideoStreamResponse{Base: stream.Base}
+	switch stream.Base.Code {
+	case 0:
+	case 6102:
+		c.JSON(consts.StatusNotFound, resp)
+		return
+	case 6103:
+		c.Header("Content-Range", fmt.Sprintf("bytes */%d", stream.Size))
+		c.JSON(consts.StatusRequestedRangeNotSatisfiable, resp)
+		return
+	case 6401:
+		c.JSON(consts.StatusForbidden, resp)
+		return
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+		return
+	}
+
+	// 代理存储中的视频内容
+	c.Header("Accept-Ranges", "bytes")
+	if stream.ETag != "" {
+		c.Header("ETag", `"`+strings.Trim(stream.ETag, `"`)+`"`)
+	}
+	if !stream.LastModified.IsZero() {
+		c.Header("Last-Modified", stream.LastModified.UTC().Format(http.TimeFormat))
+	}
+	c.SetContentType(stream.ContentType)
+
+	status := consts.StatusOK
+	if stream.Range != nil {
+		status = consts.StatusPartialContent
+		c.Header("Content-Range", stream.Range.ContentRange(stream.Size))
+	}
+	c.SetStatusCode(status)
+	c.SetBodyStream(stream.Body, int(stream.ContentLength()))
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/download"
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+var (
+	// localFS 本地存储文件服务，关闭压缩避免在存储目录生成压缩缓存文件
+	localFS     *app.FS
+	localFSOnce sync.Once
+)
+
+// selfServedStorage 由应用自身提供预签名URL访问的存储驱动（local和memory）
+type selfServedStorage interface {
+	storage.StorageInterface
+	VerifyPresignedURL(method, bucketName, objectName, expires, signature string) error
+}
+
+// ServeLocalFile 通过预签名URL下载本地存储或内存存储中的文件（仅local和memory存储驱动），支持Range请求
+// @router /storage/:bucket/*object [GET,HEAD]
+func ServeLocalFile(ctx context.Context, c *app.RequestContext) {
+	selfServed, bucketName, objectName, ok := verifyLocalFileRequest(c)
+	if !ok {
+		return
+	}
+
+	exists, err := selfServed.FileExists(ctx, bucketName, objectName)
+	if err != nil || !exists {
+		localFileError(c, consts.StatusNotFound, 9102, "文件不存在")
+		return
+	}
+
+	local, ok := selfServed.(*storage.LocalStorage)
+	if !ok {
+		serveStoredFile(ctx, c, selfServed, bucketName, objectName)
+		return
+	}
+
+	localFSOnce.Do(func() {
+		localFS = &app.FS{
+			Root:            local.RootDir(),
+			AcceptByteRange: true,
+		}
+	})
+	c.FileFromFS("/"+bucketName+"/"+objectName, localFS)
+}
+
+// UploadLocalFile 通过预签名URL上传文件到本地存储或内存存储（仅local和memory存储驱动）
+// @router /storage/:bucket/*object [PUT]
+func UploadLocalFile(ctx context.Context, c *app.RequestContext) {
+	selfServed, bucketName, objectName, ok := verifyLocalFileRequest(c)
+	if !ok {
+		return
+	}
+
+	var reader io.Reader = c.RequestBodyStream()
+	if reader == nil {
+		reader = bytes.NewReader(c.Request.Body())
+	}
+	size := int64(c.Request.Header.ContentLength())
+	if size < 0 {
+		size = -1
+	}
+
+	_, err := selfServed.UploadStream(ctx, bucketName, objectName, reader, size, string(c.ContentType()))
+	if err != nil {
+		localFileError(c, consts.StatusInternalServerError, 5000, "服务器内部错误: "+err.Error())
+		return
+	}
+	c.Status(consts.StatusOK)
+}
+
+// verifyLocalFileRequest 校验本地存储或内存存储请求的预签名参数
+func verifyLocalFileRequest(c *app.RequestContext) (selfServedStorage, string, string, bool) {
+	var selfServed selfServedStorage
+	if local, ok := videoService.LocalStorage(); ok {
+		selfServed = local
+	} else if memory, ok := videoService.MemoryStorage(); ok {
+		selfServed = memory
+	} else {
+		localFileError(c, consts.StatusNotFound, 9103, "未启用本地存储")
+		return nil, "", "", false
+	}
+
+	bucketName := c.Param("bucket")
+	objectName := strings.TrimPrefix(c.Param("object"), "/")
+	err := selfServed.VerifyPresignedURL(string(c.Method()), bucketName, objectName, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		message := "签名无效"
+		if errors.Is(err, storage.ErrPresignedURLExpired) {
+			message = "签名已过期"
+		}
+		localFileError(c, consts.StatusForbidden, 9101, message)
+		return nil, "", "", false
+	}
+	return selfServed, bucketName, objectName, true
+}
+
+// serveStoredFile 从存储服务读取文件返回，用于没有本地文件的内存存储，支持Range请求
+func serveStoredFile(ctx context.Context, c *app.RequestContext, stored storage.StorageInterface, bucketName, objectName string) {
+	stream, err := download.NewDownloadService(stored).OpenFile(ctx, &download.DownloadRequest{
+		BucketName: bucketName,
+		ObjectName: objectName,
+	}, string(c.GetHeader("Range")))
+	if errors.Is(err, download.ErrRangeNotSatisfiable) {
+		c.Header("Content-Range", fmt.Sprintf("bytes */%d", stream.Size))
+		c.Status(consts.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+	if err != nil {
+		localFileError(c, consts.StatusInternalServerError, 5000, "服务器内部错误: "+err.Error())
+		return
+	}
+
+	c.Header("Accept-Ranges", "bytes")
+	c.Header("ETag", `"`+strings.Trim(stream.ETag, `"`)+`"`)
+	c.Header("Last-Modified", stream.LastModified.UTC().Format(http.TimeFormat))
+	c.SetContentType(stream.ContentType)
+
+	status := consts.StatusOK
+	if stream.Range != nil {
+		status = consts.StatusPartialContent
+		c.Header("Content-Range", stream.Range.ContentRange(stream.Size))
+	}
+	c.SetStatusCode(status)
+	c.SetBodyStream(stream.Body, int(stream.ContentLength()))
+}
+
+// localFileError 返回本地存储请求错误
+func localFileError(c *app.RequestContext, status int, code int32, message string) {
+	c.JSON(status, &api.BaseResponse{
+		Code:    code,
+		Message: message,
+	})
+}
@@ -0,0 +1,70 @@
+// Code generated by hertz generator.
+
+package api
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+)
+
+// StartStorageMigration .
+// @router /api/v1/storage/migration [POST]
+func StartStorageMigration(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.StorageMigrationRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.StorageMigrationResponse{
+			Base: &api.BaseResponse{
+				Code:    9301,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.StartStorageMigration(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.StorageMigrationResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusAccepted, resp)
+	case 9303:
+		c.JSON(consts.StatusConflict, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// GetStorageMigration .
+// @router /api/v1/storage/migration [GET]
+func GetStorageMigration(ctx context.Context, c *app.RequestContext) {
+	resp, err := videoService.GetStorageMigration(ctx)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.StorageMigrationResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	default:
+		c.JSON(consts.StatusNotFound, resp)
+	}
+}
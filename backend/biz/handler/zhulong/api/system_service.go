@@ -8,12 +8,22 @@ import (
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/middleware"
+	"github.com/manteia/zhulong/pkg/storage"
 )
 
 // HealthCheck .
 // @router /health [GET]
 func HealthCheck(ctx context.Context, c *app.RequestContext) {
 	resp := new(api.HealthCheckResponse)
+	resp.PanicCount = middleware.PanicCount()
+	// 存储服务熔断时仍返回200，监控通过status区分
+	if stats := videoService.StorageResilienceStats(); stats != nil {
+		resp.Storage = stats
+		if stats.CircuitState == storage.CircuitOpen {
+			resp.Status = "degraded"
+		}
+	}
 
 	c.JSON(consts.StatusOK, resp)
 }
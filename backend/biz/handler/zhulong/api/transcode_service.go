@@ -0,0 +1,242 @@
+// Code generated by hertz generator.
+
+package api
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+)
+
+// CreateTranscodeJob .
+// @router /api/v1/transcode/jobs [POST]
+func CreateTranscodeJob(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.TranscodeJobCreateRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.TranscodeJobResponse{
+			Base: &api.BaseResponse{
+				Code:    6801,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.CreateTranscodeJob(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.TranscodeJobResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusCreated, resp)
+	case 6803:
+		c.JSON(consts.StatusNotFound, resp)
+	case 6805:
+		// 视频已有未完成的任务，响应中包含该任务
+		c.JSON(consts.StatusConflict, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// ListTranscodeJobs .
+// @router /api/v1/transcode/jobs [GET]
+func ListTranscodeJobs(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.TranscodeJobListRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.TranscodeJobListResponse{
+			Base: &api.BaseResponse{
+				Code:    6801,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.ListTranscodeJobs(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.TranscodeJobListResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// GetTranscodeJob .
+// @router /api/v1/transcode/jobs/:job_id [GET]
+func GetTranscodeJob(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.TranscodeJobRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.TranscodeJobResponse{
+			Base: &api.BaseResponse{
+				Code:    6801,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.GetTranscodeJob(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.TranscodeJobResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 6804:
+		c.JSON(consts.StatusNotFound, resp)
+	case 6805, 6806:
+		c.JSON(consts.StatusConflict, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// UpdateTranscodeJobPriority .
+// @router /api/v1/transcode/jobs/:job_id/priority [PUT]
+func UpdateTranscodeJobPriority(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.TranscodeJobPriorityRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.TranscodeJobResponse{
+			Base: &api.BaseResponse{
+				Code:    6801,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.UpdateTranscodeJobPriority(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.TranscodeJobResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 6804:
+		c.JSON(consts.StatusNotFound, resp)
+	case 6805, 6806:
+		c.JSON(consts.StatusConflict, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// CancelTranscodeJob .
+// @router /api/v1/transcode/jobs/:job_id [DELETE]
+func CancelTranscodeJob(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.TranscodeJobRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.TranscodeJobResponse{
+			Base: &api.BaseResponse{
+				Code:    6801,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.CancelTranscodeJob(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.TranscodeJobResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 6804:
+		c.JSON(consts.StatusNotFound, resp)
+	case 6805, 6806:
+		c.JSON(consts.StatusConflict, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// RequeueTranscodeJob .
+// @router /api/v1/transcode/jobs/:job_id/requeue [POST]
+func RequeueTranscodeJob(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.TranscodeJobRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.TranscodeJobResponse{
+			Base: &api.BaseResponse{
+				Code:    6801,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.RequeueTranscodeJob(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.TranscodeJobResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 6804:
+		c.JSON(consts.StatusNotFound, resp)
+	case 6805, 6806:
+		c.JSON(consts.StatusConflict, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
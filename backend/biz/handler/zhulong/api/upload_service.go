@@ -0,0 +1,301 @@
+// Code generated by hertz generator.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/protocol/http1/resp"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/biz/service"
+)
+
+// progressHeartbeatInterval SSE心跳间隔，用于保持连接并及时发现客户端断开
+const progressHeartbeatInterval = 15 * time.Second
+
+// GetUploadProgress .
+// @router /api/v1/uploads/:upload_id/progress [GET]
+func GetUploadProgress(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.UploadProgressRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.UploadProgressResponse{
+			Base: &api.BaseResponse{
+				Code:    8001,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	sub, err := videoService.SubscribeUploadProgress(req.UploadID)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.UploadProgressResponse{
+			Base: &api.BaseResponse{
+				Code:    8001,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+	defer sub.Close()
+
+	c.SetStatusCode(consts.StatusOK)
+	c.Response.Header.Set("Content-Type", "text/event-stream")
+	c.Response.Header.Set("Cache-Control", "no-cache")
+	c.Response.Header.Set("Connection", "keep-alive")
+	c.Response.Header.Set("X-Accel-Buffering", "no")
+	c.Response.HijackWriter(resp.NewChunkedBodyWriter(&c.Response, c.GetWriter()))
+
+	heartbeat := time.NewTicker(progressHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case progress, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(service.ConvertUploadProgress(progress))
+			if err != nil {
+				return
+			}
+			if err := writeProgressEvent(c, fmt.Sprintf("event: progress\ndata: %s\n\n", data)); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := writeProgressEvent(c, ": ping\n\n"); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// writeProgressEvent 写入一条SSE消息并立即刷新到客户端
+func writeProgressEvent(c *app.RequestContext, event string) error {
+	if _, err := c.Write([]byte(event)); err != nil {
+		return err
+	}
+	return c.Flush()
+}
+
+// InitMultipartUpload .
+// @router /api/v1/uploads [POST]
+func InitMultipartUpload(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.MultipartUploadInitRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.MultipartUploadInitResponse{
+			Base: &api.BaseResponse{
+				Code:    8101,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.InitMultipartUpload(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.MultipartUploadInitResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(multipartStatus(resp.Base.Code), resp)
+}
+
+// ListMultipartUploads .
+// @router /api/v1/uploads [GET]
+func ListMultipartUploads(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.MultipartUploadListRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.MultipartUploadListResponse{
+			Base: &api.BaseResponse{
+				Code:    8101,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.ListMultipartUploads(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.MultipartUploadListResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(consts.StatusOK, resp)
+}
+
+// UploadMultipartPart .
+// @router /api/v1/uploads/:upload_id/parts/:part_number [PUT]
+func UploadMultipartPart(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.MultipartUploadPartRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.MultipartUploadPartResponse{
+			Base: &api.BaseResponse{
+				Code:    8101,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	// 请求体即分片数据，流式读取，大小以Content-Length为准
+	var reader io.Reader = c.RequestBodyStream()
+	if reader == nil {
+		reader = bytes.NewReader(c.Request.Body())
+	}
+	size := int64(c.Request.Header.ContentLength())
+	if size < 0 {
+		size = -1
+	}
+	resp, err := videoService.UploadMultipartPart(ctx, &req, reader, size)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.MultipartUploadPartResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(multipartStatus(resp.Base.Code), resp)
+}
+
+// ListMultipartUploadParts .
+// @router /api/v1/uploads/:upload_id/parts [GET]
+func ListMultipartUploadParts(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.MultipartUploadPartsRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.MultipartUploadPartsResponse{
+			Base: &api.BaseResponse{
+				Code:    8101,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.ListMultipartUploadParts(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.MultipartUploadPartsResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(multipartStatus(resp.Base.Code), resp)
+}
+
+// CompleteMultipartUpload .
+// @router /api/v1/uploads/:upload_id/complete [POST]
+func CompleteMultipartUpload(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.MultipartUploadCompleteRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoUploadResponse{
+			Base: &api.BaseResponse{
+				Code:    8101,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.CompleteMultipartUpload(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoUploadResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(multipartStatus(resp.Base.Code), resp)
+}
+
+// AbortMultipartUpload .
+// @router /api/v1/uploads/:upload_id [DELETE]
+func AbortMultipartUpload(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.MultipartUploadAbortRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.MultipartUploadAbortResponse{
+			Base: &api.BaseResponse{
+				Code:    8101,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.AbortMultipartUpload(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.MultipartUploadAbortResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(multipartStatus(resp.Base.Code), resp)
+}
+
+// multipartStatus 分片上传接口的业务错误码对应的HTTP状态码
+func multipartStatus(code int32) int {
+	switch code {
+	case 0:
+		return consts.StatusOK
+	case 1009, 1012:
+		// 用户存储配额不足或无权上传
+		return consts.StatusForbidden
+	case 8102:
+		// 分片上传不存在或已过期
+		return consts.StatusNotFound
+	case 1011, 8103:
+		// 视频已存在，或分片缺失、与已上传的分片不一致，客户端补传后可以重试
+		return consts.StatusConflict
+	case 1013:
+		// 保存视频信息失败，合并后的文件已清理
+		return consts.StatusInternalServerError
+	default:
+		return consts.StatusBadRequest
+	}
+}
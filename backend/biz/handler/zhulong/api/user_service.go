@@ -0,0 +1,192 @@
+// Code generated by hertz generator.
+
+package api
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/middleware"
+)
+
+// TokenParser 获取令牌解析器，供路由认证中间件使用，解析时使用用户当前的角色
+func TokenParser() middleware.TokenParser {
+	return userService
+}
+
+// Register .
+// @router /api/v1/auth/register [POST]
+func Register(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.RegisterRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.UserResponse{
+			Base: &api.BaseResponse{
+				Code:    7001,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := userService.Register(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.UserResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 7002:
+		c.JSON(consts.StatusConflict, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// Login .
+// @router /api/v1/auth/login [POST]
+func Login(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.LoginRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.LoginResponse{
+			Base: &api.BaseResponse{
+				Code:    7001,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := userService.Login(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.LoginResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 7003:
+		c.JSON(consts.StatusUnauthorized, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// GetCurrentUser .
+// @router /api/v1/users/me [GET]
+func GetCurrentUser(ctx context.Context, c *app.RequestContext) {
+	resp, err := userService.GetCurrentUser(ctx)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.UserResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 7010:
+		c.JSON(consts.StatusUnauthorized, resp)
+	default:
+		c.JSON(consts.StatusNotFound, resp)
+	}
+}
+
+// GetCurrentUserQuota .
+// @router /api/v1/users/me/quota [GET]
+func GetCurrentUserQuota(ctx context.Context, c *app.RequestContext) {
+	resp, err := videoService.GetCurrentUserQuota(ctx)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.UserQuotaResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	default:
+		c.JSON(consts.StatusUnauthorized, resp)
+	}
+}
+
+// ListUsers .
+// @router /api/v1/users [GET]
+func ListUsers(ctx context.Context, c *app.RequestContext) {
+	resp, err := userService.ListUsers(ctx)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.UserListResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+			Users: []*api.User{},
+		})
+		return
+	}
+
+	c.JSON(consts.StatusOK, resp)
+}
+
+// UpdateUserRole .
+// @router /api/v1/users/:user_id/role [PUT]
+func UpdateUserRole(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.UpdateUserRoleRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.UserResponse{
+			Base: &api.BaseResponse{
+				Code:    7001,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := userService.UpdateUserRole(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.UserResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 7004:
+		c.JSON(consts.StatusNotFound, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
@@ -4,26 +4,14 @@ package api
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 	api "github.com/manteia/zhulong/biz/model/zhulong/api"
-	"github.com/manteia/zhulong/biz/service"
+	"github.com/manteia/zhulong/pkg/streaming"
+	"github.com/manteia/zhulong/pkg/video"
 )
 
-// 全局视频服务实例
-var videoService *service.VideoService
-
-// init 初始化服务
-func init() {
-	var err error
-	videoService, err = service.NewVideoService()
-	if err != nil {
-		panic(fmt.Sprintf("初始化视频服务失败: %v", err))
-	}
-}
-
 // UploadVideo .
 // @router /api/v1/videos [POST]
 func UploadVideo(ctx context.Context, c *app.RequestContext) {
@@ -40,6 +28,9 @@ func UploadVideo(ctx context.Context, c *app.RequestContext) {
 	if description != "" {
 		req.Description = description
 	}
+	req.UploadID = c.PostForm("upload_id")
+	req.Checksum = c.PostForm("checksum")
+	req.Visibility = c.PostForm("visibility")
 
 	// 获取上传的文件
 	fileHeader, err := c.FormFile("file")
@@ -66,9 +57,100 @@ func UploadVideo(ctx context.Context, c *app.RequestContext) {
 	}
 
 	// 根据业务逻辑返回相应的HTTP状态码
-	if resp.Base.Code == 0 {
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 1009, 1012:
+		// 用户存储配额不足或无权上传
+		c.JSON(consts.StatusForbidden, resp)
+	case 1011:
+		// 视频已存在，响应中包含已存在的视频
+		c.JSON(consts.StatusConflict, resp)
+	case 1013:
+		// 保存视频信息失败，已写入的文件已清理
+		c.JSON(consts.StatusInternalServerError, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// CreateUploadURL .
+// @router /api/v1/videos/upload-url [POST]
+func CreateUploadURL(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.VideoUploadURLRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoUploadURLResponse{
+			Base: &api.BaseResponse{
+				Code:    1001,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.CreateUploadURL(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoUploadURLResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 1009, 1012:
+		c.JSON(consts.StatusForbidden, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// ConfirmUpload .
+// @router /api/v1/videos/confirm [POST]
+func ConfirmUpload(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.VideoUploadConfirmRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoUploadResponse{
+			Base: &api.BaseResponse{
+				Code:    1001,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.ConfirmUpload(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoUploadResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
 		c.JSON(consts.StatusOK, resp)
-	} else {
+	case 1008:
+		// 文件尚未上传完成，客户端可稍后重试
+		c.JSON(consts.StatusConflict, resp)
+	case 1009, 1012:
+		c.JSON(consts.StatusForbidden, resp)
+	case 1011:
+		c.JSON(consts.StatusConflict, resp)
+	case 1013:
+		c.JSON(consts.StatusInternalServerError, resp)
+	default:
 		c.JSON(consts.StatusBadRequest, resp)
 	}
 }
@@ -106,9 +188,12 @@ func GetVideoList(ctx context.Context, c *app.RequestContext) {
 	}
 
 	// 返回响应
-	if resp.Base.Code == 0 {
+	switch resp.Base.Code {
+	case 0:
 		c.JSON(consts.StatusOK, resp)
-	} else {
+	case 2003:
+		c.JSON(consts.StatusNotFound, resp)
+	default:
 		c.JSON(consts.StatusBadRequest, resp)
 	}
 }
@@ -120,13 +205,34 @@ func GetVideoDetail(ctx context.Context, c *app.RequestContext) {
 	var req api.VideoDetailRequest
 	err = c.BindAndValidate(&req)
 	if err != nil {
-		c.String(consts.StatusBadRequest, err.Error())
+		c.JSON(consts.StatusBadRequest, &api.VideoDetailResponse{
+			Base: &api.BaseResponse{
+				Code:    2101,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
 		return
 	}
 
-	resp := new(api.VideoDetailResponse)
+	resp, err := videoService.GetVideoDetail(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoDetailResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
 
-	c.JSON(consts.StatusOK, resp)
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 2102:
+		c.JSON(consts.StatusNotFound, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
 }
 
 // GetVideoPlayURL .
@@ -136,13 +242,112 @@ func GetVideoPlayURL(ctx context.Context, c *app.RequestContext) {
 	var req api.VideoPlayURLRequest
 	err = c.BindAndValidate(&req)
 	if err != nil {
-		c.String(consts.StatusBadRequest, err.Error())
+		c.JSON(consts.StatusBadRequest, &api.VideoPlayURLResponse{
+			Base: &api.BaseResponse{
+				Code:    6301,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.GetVideoPlayURL(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoPlayURLResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
 		return
 	}
 
-	resp := new(api.VideoPlayURLResponse)
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 6302:
+		c.JSON(consts.StatusNotFound, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
 
-	c.JSON(consts.StatusOK, resp)
+// GetVideoCastInfo .
+// @router /api/v1/videos/:video_id/cast [GET]
+func GetVideoCastInfo(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.VideoCastRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoCastResponse{
+			Base: &api.BaseResponse{
+				Code:    7101,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.GetVideoCastInfo(ctx, &req, requestBaseURL(c))
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoCastResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 7102:
+		c.JSON(consts.StatusNotFound, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// UpdateVideo .
+// @router /api/v1/videos/:video_id [PUT]
+func UpdateVideo(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.VideoUpdateRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoUpdateResponse{
+			Base: &api.BaseResponse{
+				Code:    4001,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.UpdateVideo(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoUpdateResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 4002:
+		c.JSON(consts.StatusNotFound, resp)
+	case 4004:
+		c.JSON(consts.StatusForbidden, resp)
+	case 4003:
+		c.JSON(consts.StatusConflict, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
 }
 
 // DeleteVideo .
@@ -152,11 +357,1084 @@ func DeleteVideo(ctx context.Context, c *app.RequestContext) {
 	var req api.VideoDeleteRequest
 	err = c.BindAndValidate(&req)
 	if err != nil {
-		c.String(consts.StatusBadRequest, err.Error())
+		c.JSON(consts.StatusBadRequest, &api.VideoDeleteResponse{
+			Base: &api.BaseResponse{
+				Code:    3001,
+				Message: "请求参数错误: " + err.Error(),
+			},
+			Failures: []*api.DeleteFailure{},
+		})
+		return
+	}
+
+	resp, err := videoService.DeleteVideo(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoDeleteResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+			Failures: []*api.DeleteFailure{},
+		})
 		return
 	}
 
-	resp := new(api.VideoDeleteResponse)
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 3002:
+		c.JSON(consts.StatusNotFound, resp)
+	case 3005:
+		c.JSON(consts.StatusForbidden, resp)
+	case 3003:
+		// 部分失败：返回207以便客户端读取失败详情
+		c.JSON(consts.StatusMultiStatus, resp)
+	case 3004:
+		c.JSON(consts.StatusConflict, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
 
-	c.JSON(consts.StatusOK, resp)
+// GetHLSPlaylist .
+// @router /api/v1/videos/:video_id/hls/:playlist [GET]
+func GetHLSPlaylist(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.HLSPlaylistRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.HLSPlaylistResponse{
+			Base: &api.BaseResponse{
+				Code:    6001,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.GetHLSPlaylist(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.HLSPlaylistResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	// 成功时直接返回m3u8内容，供播放器解析
+	switch resp.Base.Code {
+	case 0:
+		c.Data(consts.StatusOK, streaming.PlaylistContentType, []byte(resp.Content))
+	case 6003:
+		c.JSON(consts.StatusNotFound, resp)
+	case 6004:
+		c.JSON(consts.StatusAccepted, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// GetDASHManifest .
+// @router /api/v1/videos/:video_id/manifest.mpd [GET]
+func GetDASHManifest(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.DASHManifestRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.DASHManifestResponse{
+			Base: &api.BaseResponse{
+				Code:    6701,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.GetDASHManifest(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.DASHManifestResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	// 成功时直接返回mpd内容，供播放器解析
+	switch resp.Base.Code {
+	case 0:
+		c.Data(consts.StatusOK, streaming.ManifestContentType, []byte(resp.Content))
+	case 6703:
+		c.JSON(consts.StatusNotFound, resp)
+	case 6704:
+		c.JSON(consts.StatusAccepted, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// StreamVideo .
+// @router /api/v1/videos/:video_id/stream [GET]
+func StreamVideo(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.VideoStreamRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoStreamResponse{
+			Base: &api.BaseResponse{
+				Code:    6101,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	stream, err := videoService.StreamVideo(ctx, &req, string(c.GetHeader("Range")))
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoStreamResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	writeVideoStream(c, stream)
+}
+
+// DownloadVideo .
+// @router /api/v1/videos/:video_id/download [GET]
+func DownloadVideo(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.VideoDownloadRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoDownloadResponse{
+			Base: &api.BaseResponse{
+				Code:    2401,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.DownloadVideo(ctx, &req, string(c.GetHeader("Range")))
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoDownloadResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	writeVideoDownload(c, resp)
+}
+
+// ExportVideos .
+// @router /api/v1/videos/export [POST]
+func ExportVideos(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.VideoExportRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoExportResponse{
+			Base: &api.BaseResponse{
+				Code:    2501,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.ExportVideos(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoExportResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	writeVideoDownload(c, resp)
+}
+
+// GetThumbnailTrack .
+// @router /api/v1/videos/:video_id/thumbnails.vtt [GET]
+func GetThumbnailTrack(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.ThumbnailTrackRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.ThumbnailTrackResponse{
+			Base: &api.BaseResponse{
+				Code:    6201,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.GetThumbnailTrack(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.ThumbnailTrackResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	// 成功时直接返回WebVTT内容，供播放器作为缩略图轨道加载
+	switch resp.Base.Code {
+	case 0:
+		c.Data(consts.StatusOK, video.SpriteVTTContentType, []byte(resp.Content))
+	case 6202, 6203:
+		c.JSON(consts.StatusNotFound, resp)
+	case 6204:
+		c.JSON(consts.StatusAccepted, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// AddVideoTags .
+// @router /api/v1/videos/:video_id/tags [POST]
+func AddVideoTags(ctx context.Context, c *app.RequestContext) {
+	modifyVideoTags(ctx, c, videoService.AddVideoTags)
+}
+
+// RemoveVideoTags .
+// @router /api/v1/videos/:video_id/tags [DELETE]
+func RemoveVideoTags(ctx context.Context, c *app.RequestContext) {
+	modifyVideoTags(ctx, c, videoService.RemoveVideoTags)
+}
+
+// modifyVideoTags 绑定标签修改请求并调用对应的服务方法
+func modifyVideoTags(ctx context.Context, c *app.RequestContext, modify func(context.Context, *api.VideoTagsRequest) (*api.VideoTagsResponse, error)) {
+	var err error
+	var req api.VideoTagsRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoTagsResponse{
+			Base: &api.BaseResponse{
+				Code:    4101,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := modify(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoTagsResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 4102:
+		c.JSON(consts.StatusNotFound, resp)
+	case 4103:
+		c.JSON(consts.StatusForbidden, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// ListTags .
+// @router /api/v1/tags [GET]
+func ListTags(ctx context.Context, c *app.RequestContext) {
+	resp, err := videoService.ListTags(ctx)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.TagListResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+			Tags: []*api.TagCount{},
+		})
+		return
+	}
+
+	c.JSON(consts.StatusOK, resp)
+}
+
+// UpdateWatchProgress .
+// @router /api/v1/videos/:video_id/progress [PUT]
+func UpdateWatchProgress(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.VideoProgressRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoProgressResponse{
+			Base: &api.BaseResponse{
+				Code:    2201,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.UpdateWatchProgress(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoProgressResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 2202:
+		c.JSON(consts.StatusNotFound, resp)
+	case 7010:
+		c.JSON(consts.StatusUnauthorized, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// GetWatchHistory .
+// @router /api/v1/users/me/history [GET]
+func GetWatchHistory(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.WatchHistoryRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.WatchHistoryResponse{
+			Base: &api.BaseResponse{
+				Code:    2201,
+				Message: "请求参数错误: " + err.Error(),
+			},
+			History: []*api.WatchProgress{},
+		})
+		return
+	}
+
+	resp, err := videoService.GetWatchHistory(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.WatchHistoryResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+			History: []*api.WatchProgress{},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 7010:
+		c.JSON(consts.StatusUnauthorized, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// AddFavorite .
+// @router /api/v1/videos/:video_id/favorite [PUT]
+func AddFavorite(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.VideoFavoriteRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoFavoriteResponse{
+			Base: &api.BaseResponse{
+				Code:    2301,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.AddFavorite(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoFavoriteResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 2302:
+		c.JSON(consts.StatusNotFound, resp)
+	case 7010:
+		c.JSON(consts.StatusUnauthorized, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// RemoveFavorite .
+// @router /api/v1/videos/:video_id/favorite [DELETE]
+func RemoveFavorite(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.VideoFavoriteRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoFavoriteResponse{
+			Base: &api.BaseResponse{
+				Code:    2301,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.RemoveFavorite(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoFavoriteResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 2302:
+		c.JSON(consts.StatusNotFound, resp)
+	case 7010:
+		c.JSON(consts.StatusUnauthorized, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// GetFavorites .
+// @router /api/v1/users/me/favorites [GET]
+func GetFavorites(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.FavoriteListRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.FavoriteListResponse{
+			Base: &api.BaseResponse{
+				Code:    2301,
+				Message: "请求参数错误: " + err.Error(),
+			},
+			Favorites: []*api.FavoriteVideo{},
+		})
+		return
+	}
+
+	resp, err := videoService.GetFavorites(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.FavoriteListResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+			Favorites: []*api.FavoriteVideo{},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 7010:
+		c.JSON(consts.StatusUnauthorized, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// UpdateVideoChapters .
+// @router /api/v1/videos/:video_id/chapters [PUT]
+func UpdateVideoChapters(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.VideoChaptersRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoChaptersResponse{
+			Base: &api.BaseResponse{
+				Code:    4401,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.UpdateVideoChapters(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoChaptersResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 4402:
+		c.JSON(consts.StatusNotFound, resp)
+	case 4403:
+		c.JSON(consts.StatusForbidden, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// ArchiveVideo .
+// @router /api/v1/videos/:video_id/archive [PUT]
+func ArchiveVideo(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.VideoArchiveRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoArchiveResponse{
+			Base: &api.BaseResponse{
+				Code:    4501,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.ArchiveVideo(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoArchiveResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 4502:
+		c.JSON(consts.StatusNotFound, resp)
+	case 4503:
+		c.JSON(consts.StatusServiceUnavailable, resp)
+	case 4504:
+		c.JSON(consts.StatusConflict, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// RestoreVideo .
+// @router /api/v1/videos/:video_id/archive [DELETE]
+func RestoreVideo(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.VideoArchiveRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoArchiveResponse{
+			Base: &api.BaseResponse{
+				Code:    4501,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.RestoreVideo(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoArchiveResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 4502:
+		c.JSON(consts.StatusNotFound, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// ReprocessVideo .
+// @router /api/v1/videos/:video_id/reprocess [POST]
+func ReprocessVideo(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.VideoReprocessRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoReprocessResponse{
+			Base: &api.BaseResponse{
+				Code:    4601,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.ReprocessVideo(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoReprocessResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 4602:
+		c.JSON(consts.StatusNotFound, resp)
+	case 4603:
+		c.JSON(consts.StatusForbidden, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// GenerateThumbnailCandidates .
+// @router /api/v1/videos/:video_id/thumbnail/candidates [POST]
+func GenerateThumbnailCandidates(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.ThumbnailCandidatesRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.ThumbnailCandidatesResponse{
+			Base: &api.BaseResponse{
+				Code:    4701,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.GenerateThumbnailCandidates(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.ThumbnailCandidatesResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 4702:
+		c.JSON(consts.StatusNotFound, resp)
+	case 4703:
+		c.JSON(consts.StatusForbidden, resp)
+	case 4705:
+		c.JSON(consts.StatusServiceUnavailable, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// SelectThumbnail .
+// @router /api/v1/videos/:video_id/thumbnail [PUT]
+func SelectThumbnail(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.ThumbnailSelectRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.ThumbnailUpdateResponse{
+			Base: &api.BaseResponse{
+				Code:    4701,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.SelectThumbnail(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.ThumbnailUpdateResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(thumbnailUpdateStatus(resp.Base.Code), resp)
+}
+
+// UploadCustomThumbnail .
+// @router /api/v1/videos/:video_id/thumbnail [POST]
+func UploadCustomThumbnail(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.ThumbnailUploadRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.ThumbnailUpdateResponse{
+			Base: &api.BaseResponse{
+				Code:    4701,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	if fit := c.PostForm("fit"); fit != "" {
+		req.Fit = fit
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.ThumbnailUpdateResponse{
+			Base: &api.BaseResponse{
+				Code:    4701,
+				Message: "获取封面图片失败: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.UploadCustomThumbnail(ctx, &req, fileHeader)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.ThumbnailUpdateResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(thumbnailUpdateStatus(resp.Base.Code), resp)
+}
+
+// thumbnailUpdateStatus 更换缩略图业务码对应的HTTP状态码
+func thumbnailUpdateStatus(code int32) int {
+	switch code {
+	case 0:
+		return consts.StatusOK
+	case 4702, 4704:
+		return consts.StatusNotFound
+	case 4703:
+		return consts.StatusForbidden
+	default:
+		return consts.StatusBadRequest
+	}
+}
+
+// ListVideoVersions .
+// @router /api/v1/videos/:video_id/versions [GET]
+func ListVideoVersions(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.VideoVersionsRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoVersionsResponse{
+			Base: &api.BaseResponse{
+				Code:    4801,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.ListVideoVersions(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoVersionsResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(videoVersionStatus(resp.Base.Code), resp)
+}
+
+// UploadVideoVersion .
+// @router /api/v1/videos/:video_id/versions [POST]
+func UploadVideoVersion(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.VideoVersionUploadRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoVersionsResponse{
+			Base: &api.BaseResponse{
+				Code:    4801,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoVersionsResponse{
+			Base: &api.BaseResponse{
+				Code:    4801,
+				Message: "获取上传文件失败: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.UploadVideoVersion(ctx, &req, fileHeader)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoVersionsResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(videoVersionStatus(resp.Base.Code), resp)
+}
+
+// RestoreVideoVersion .
+// @router /api/v1/videos/:video_id/versions/:version/restore [POST]
+func RestoreVideoVersion(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.VideoVersionRestoreRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoVersionsResponse{
+			Base: &api.BaseResponse{
+				Code:    4801,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.RestoreVideoVersion(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoVersionsResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(videoVersionStatus(resp.Base.Code), resp)
+}
+
+// videoVersionStatus 视频版本业务码对应的HTTP状态码
+func videoVersionStatus(code int32) int {
+	switch code {
+	case 0:
+		return consts.StatusOK
+	case 4802, 4804:
+		return consts.StatusNotFound
+	case 4803, 4805:
+		return consts.StatusForbidden
+	case 4806:
+		return consts.StatusConflict
+	default:
+		return consts.StatusBadRequest
+	}
+}
+
+// CreateVideoClip .
+// @router /api/v1/videos/:video_id/clips [POST]
+func CreateVideoClip(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.VideoClipRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoClipResponse{
+			Base: &api.BaseResponse{
+				Code:    7301,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.CreateVideoClip(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoClipResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 7302:
+		c.JSON(consts.StatusNotFound, resp)
+	case 7303:
+		c.JSON(consts.StatusConflict, resp)
+	case 7304:
+		// 服务器未安装FFmpeg
+		c.JSON(consts.StatusServiceUnavailable, resp)
+	case 7305:
+		// 用户存储配额不足
+		c.JSON(consts.StatusForbidden, resp)
+	case 7306:
+		c.JSON(consts.StatusUnprocessableEntity, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// StartVideoImport .
+// @router /api/v1/videos/import [POST]
+func StartVideoImport(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.VideoImportRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.VideoImportResponse{
+			Base: &api.BaseResponse{
+				Code:    9401,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.StartVideoImport(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoImportResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusAccepted, resp)
+	case 9402:
+		c.JSON(consts.StatusNotFound, resp)
+	case 9403:
+		c.JSON(consts.StatusConflict, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// GetVideoImport .
+// @router /api/v1/videos/import [GET]
+func GetVideoImport(ctx context.Context, c *app.RequestContext) {
+	resp, err := videoService.GetVideoImport(ctx)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.VideoImportResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	default:
+		c.JSON(consts.StatusNotFound, resp)
+	}
+}
+
+// StartNFOExport .
+// @router /api/v1/videos/nfo-export [POST]
+func StartNFOExport(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.NFOExportRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.NFOExportResponse{
+			Base: &api.BaseResponse{
+				Code:    9601,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.StartNFOExport(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.NFOExportResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusAccepted, resp)
+	case 9603:
+		c.JSON(consts.StatusConflict, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
+
+// GetNFOExport .
+// @router /api/v1/videos/nfo-export [GET]
+func GetNFOExport(ctx context.Context, c *app.RequestContext) {
+	resp, err := videoService.GetNFOExport(ctx)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.NFOExportResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	default:
+		c.JSON(consts.StatusNotFound, resp)
+	}
 }
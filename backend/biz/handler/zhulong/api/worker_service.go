@@ -0,0 +1,123 @@
+// Code generated by hertz generator.
+
+package api
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+)
+
+// WorkerSecret 获取worker共享密钥，供路由认证中间件使用
+func WorkerSecret() string {
+	return videoService.Config().Worker.Secret
+}
+
+// ClaimWorkerJob .
+// @router /api/v1/worker/jobs/claim [POST]
+func ClaimWorkerJob(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.WorkerJobClaimRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.WorkerJobResponse{
+			Base: &api.BaseResponse{
+				Code:    6901,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.ClaimWorkerJob(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.WorkerJobResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	writeWorkerJobResponse(c, resp)
+}
+
+// HeartbeatWorkerJob .
+// @router /api/v1/worker/jobs/:job_id/heartbeat [POST]
+func HeartbeatWorkerJob(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.WorkerJobHeartbeatRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.WorkerJobResponse{
+			Base: &api.BaseResponse{
+				Code:    6901,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.HeartbeatWorkerJob(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.WorkerJobResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	writeWorkerJobResponse(c, resp)
+}
+
+// CompleteWorkerJob .
+// @router /api/v1/worker/jobs/:job_id/complete [POST]
+func CompleteWorkerJob(ctx context.Context, c *app.RequestContext) {
+	var err error
+	var req api.WorkerJobCompleteRequest
+	err = c.BindAndValidate(&req)
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, &api.WorkerJobResponse{
+			Base: &api.BaseResponse{
+				Code:    6901,
+				Message: "请求参数错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	resp, err := videoService.CompleteWorkerJob(ctx, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, &api.WorkerJobResponse{
+			Base: &api.BaseResponse{
+				Code:    5000,
+				Message: "服务器内部错误: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	writeWorkerJobResponse(c, resp)
+}
+
+// writeWorkerJobResponse 根据业务错误码返回worker任务响应
+func writeWorkerJobResponse(c *app.RequestContext, resp *api.WorkerJobResponse) {
+	switch resp.Base.Code {
+	case 0:
+		c.JSON(consts.StatusOK, resp)
+	case 6902:
+		c.JSON(consts.StatusServiceUnavailable, resp)
+	case 6903:
+		c.JSON(consts.StatusNotFound, resp)
+	case 6904:
+		// 租约已失效，worker应该放弃当前任务
+		c.JSON(consts.StatusConflict, resp)
+	default:
+		c.JSON(consts.StatusBadRequest, resp)
+	}
+}
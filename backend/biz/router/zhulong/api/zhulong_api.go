@@ -18,18 +18,153 @@ func Register(r *server.Hertz) {
 
 	root := r.Group("/", rootMw()...)
 	root.GET("/health", append(_healthcheckMw(), api.HealthCheck)...)
+	root.GET("/oembed", append(_getoembedMw(), api.GetOEmbed)...)
 	{
 		_api := root.Group("/api", _apiMw()...)
 		{
 			_v1 := _api.Group("/v1", _v1Mw()...)
+			_admin := _v1.Group("/admin", _adminMw()...)
+			_admin.GET("/backups", append(_listbackupsMw(), api.ListBackups)...)
+			_admin.POST("/backups", append(_createbackupMw(), api.CreateBackup)...)
+			_backups := _admin.Group("/backups", _backupsMw()...)
+			_backups.POST("/restore", append(_restorebackupMw(), api.RestoreBackup)...)
+			_admin.GET("/catalog", append(_exportcatalogMw(), api.ExportCatalog)...)
+			_catalog := _admin.Group("/catalog", _catalogMw()...)
+			_catalog.POST("/import", append(_importcatalogMw(), api.ImportCatalog)...)
+			_admin.GET("/consistency", append(_checkconsistencyMw(), api.CheckConsistency)...)
+			_admin.GET("/moderation", append(_listmoderationqueueMw(), api.ListModerationQueue)...)
+			_moderation := _admin.Group("/moderation", _moderationMw()...)
+			_moderation.POST("/:video_id", append(_reviewmoderationMw(), api.ReviewModeration)...)
+			_admin.GET("/replication", append(_getreplicationreportMw(), api.GetReplicationReport)...)
+			_replication := _admin.Group("/replication", _replicationMw()...)
+			_replication.POST("/sync", append(_syncreplicationMw(), api.SyncReplication)...)
+			_admin.GET("/stats", append(_getadminstatsMw(), api.GetAdminStats)...)
+			_playback := _admin.Group("/playback", _playbackMw()...)
+			_playback.POST("/revoke", append(_revokeplaybacktokensMw(), api.RevokePlaybackTokens)...)
+			_analytics := _v1.Group("/analytics", _analyticsMw()...)
+			_analytics.GET("/top-videos", append(_gettopvideosMw(), api.GetTopVideos)...)
+			_v1.GET("/collections", append(_listcollectionsMw(), api.ListCollections)...)
+			_collections := _v1.Group("/collections", _collectionsMw()...)
+			_collections.DELETE("/:collection_id", append(_deletecollectionMw(), api.DeleteCollection)...)
+			_collections.PUT("/:collection_id", append(_updatecollectionMw(), api.UpdateCollection)...)
+			_collection_id := _collections.Group("/:collection_id", _collection_idMw()...)
+			_collection_id.DELETE("/videos", append(_removecollectionvideosMw(), api.RemoveCollectionVideos)...)
+			_collection_id.POST("/videos", append(_addcollectionvideosMw(), api.AddCollectionVideos)...)
+			_v1.POST("/collections", append(_createcollectionMw(), api.CreateCollection)...)
 			_v1.GET("/info", append(_getserverinfoMw(), api.GetServerInfo)...)
+			_v1.GET("/playlists", append(_listplaylistsMw(), api.ListPlaylists)...)
+			_playlists := _v1.Group("/playlists", _playlistsMw()...)
+			_playlists.DELETE("/:playlist_id", append(_deleteplaylistMw(), api.DeletePlaylist)...)
+			_playlists.GET("/:playlist_id", append(_getplaylistMw(), api.GetPlaylist)...)
+			_playlists.PUT("/:playlist_id", append(_updateplaylistMw(), api.UpdatePlaylist)...)
+			_playlist_id := _playlists.Group("/:playlist_id", _playlist_idMw()...)
+			_playlist_id.GET("/navigation", append(_navigateplaylistMw(), api.NavigatePlaylist)...)
+			_playlist_id.PUT("/order", append(_reorderplaylistMw(), api.ReorderPlaylist)...)
+			_playlist_id.DELETE("/videos", append(_removeplaylistvideosMw(), api.RemovePlaylistVideos)...)
+			_playlist_id.POST("/videos", append(_addplaylistvideosMw(), api.AddPlaylistVideos)...)
+			_v1.POST("/playlists", append(_createplaylistMw(), api.CreatePlaylist)...)
+			_v1.GET("/shares", append(_listsharesMw(), api.ListShares)...)
+			_shares := _v1.Group("/shares", _sharesMw()...)
+			_shares.DELETE("/:token", append(_revokeshareMw(), api.RevokeShare)...)
+			_storage := _v1.Group("/storage", _storageMw()...)
+			_storage.GET("/migration", append(_getstoragemigrationMw(), api.GetStorageMigration)...)
+			_storage.POST("/migration", append(_startstoragemigrationMw(), api.StartStorageMigration)...)
+			_v1.GET("/tags", append(_listtagsMw(), api.ListTags)...)
+			_transcode := _v1.Group("/transcode", _transcodeMw()...)
+			_transcode.GET("/jobs", append(_listtranscodejobsMw(), api.ListTranscodeJobs)...)
+			_jobs := _transcode.Group("/jobs", _jobsMw()...)
+			_jobs.DELETE("/:job_id", append(_canceltranscodejobMw(), api.CancelTranscodeJob)...)
+			_jobs.GET("/:job_id", append(_gettranscodejobMw(), api.GetTranscodeJob)...)
+			_job_id := _jobs.Group("/:job_id", _job_idMw()...)
+			_job_id.PUT("/priority", append(_updatetranscodejobpriorityMw(), api.UpdateTranscodeJobPriority)...)
+			_job_id.POST("/requeue", append(_requeuetranscodejobMw(), api.RequeueTranscodeJob)...)
+			_transcode.POST("/jobs", append(_createtranscodejobMw(), api.CreateTranscodeJob)...)
+			{
+				_worker := _v1.Group("/worker", _workerMw()...)
+				_jobs0 := _worker.Group("/jobs", _jobs0Mw()...)
+				_jobs0.POST("/claim", append(_claimworkerjobMw(), api.ClaimWorkerJob)...)
+				_job_id0 := _jobs0.Group("/:job_id", _job_id0Mw()...)
+				_job_id0.POST("/complete", append(_completeworkerjobMw(), api.CompleteWorkerJob)...)
+				_job_id0.POST("/heartbeat", append(_heartbeatworkerjobMw(), api.HeartbeatWorkerJob)...)
+			}
+			_notifications := _v1.Group("/notifications", _notificationsMw()...)
+			_notifications.GET("/ws", append(_subscribenotificationsMw(), api.SubscribeNotifications)...)
+			_v1.GET("/uploads", append(_listmultipartuploadsMw(), api.ListMultipartUploads)...)
+			_uploads := _v1.Group("/uploads", _uploadsMw()...)
+			_uploads.DELETE("/:upload_id", append(_abortmultipartuploadMw(), api.AbortMultipartUpload)...)
+			_upload_id := _uploads.Group("/:upload_id", _upload_idMw()...)
+			_upload_id.POST("/complete", append(_completemultipartuploadMw(), api.CompleteMultipartUpload)...)
+			_upload_id.GET("/progress", append(_getuploadprogressMw(), api.GetUploadProgress)...)
+			_upload_id.GET("/parts", append(_listmultipartuploadpartsMw(), api.ListMultipartUploadParts)...)
+			_parts := _upload_id.Group("/parts", _partsMw()...)
+			_parts.PUT("/:part_number", append(_uploadmultipartpartMw(), api.UploadMultipartPart)...)
+			_v1.POST("/uploads", append(_initmultipartuploadMw(), api.InitMultipartUpload)...)
+			_v1.GET("/users", append(_listusersMw(), api.ListUsers)...)
+			_users := _v1.Group("/users", _usersMw()...)
+			_users.GET("/me", append(_getcurrentuserMw(), api.GetCurrentUser)...)
+			_me := _users.Group("/me", _meMw()...)
+			_me.GET("/favorites", append(_getfavoritesMw(), api.GetFavorites)...)
+			_me.GET("/history", append(_getwatchhistoryMw(), api.GetWatchHistory)...)
+			_me.GET("/quota", append(_getcurrentuserquotaMw(), api.GetCurrentUserQuota)...)
+			_user_id := _users.Group("/:user_id", _user_idMw()...)
+			_user_id.PUT("/role", append(_updateuserroleMw(), api.UpdateUserRole)...)
+			{
+				_auth := _v1.Group("/auth", _authMw()...)
+				_auth.POST("/login", append(_loginMw(), api.Login)...)
+				_auth.POST("/register", append(_registerMw(), api.Register)...)
+			}
 			_v1.GET("/videos", append(_getvideolistMw(), api.GetVideoList)...)
 			_videos := _v1.Group("/videos", _videosMw()...)
+			_videos.POST("/confirm", append(_confirmuploadMw(), api.ConfirmUpload)...)
+			_videos.POST("/export", append(_exportvideosMw(), api.ExportVideos)...)
+			_videos.GET("/import", append(_getvideoimportMw(), api.GetVideoImport)...)
+			_videos.POST("/import", append(_startvideoimportMw(), api.StartVideoImport)...)
+			_videos.GET("/nfo-export", append(_getnfoexportMw(), api.GetNFOExport)...)
+			_videos.POST("/nfo-export", append(_startnfoexportMw(), api.StartNFOExport)...)
+			_videos.POST("/upload-url", append(_createuploadurlMw(), api.CreateUploadURL)...)
 			_videos.DELETE("/:video_id", append(_deletevideoMw(), api.DeleteVideo)...)
 			_videos.GET("/:video_id", append(_getvideodetailMw(), api.GetVideoDetail)...)
+			_videos.PUT("/:video_id", append(_updatevideoMw(), api.UpdateVideo)...)
 			_video_id := _videos.Group("/:video_id", _video_idMw()...)
+			_video_id.DELETE("/archive", append(_restorevideoMw(), api.RestoreVideo)...)
+			_video_id.PUT("/archive", append(_archivevideoMw(), api.ArchiveVideo)...)
+			_video_id.GET("/cast", append(_getvideocastinfoMw(), api.GetVideoCastInfo)...)
+			_video_id.POST("/clips", append(_createvideoclipMw(), api.CreateVideoClip)...)
+			_video_id.PUT("/chapters", append(_updatevideochaptersMw(), api.UpdateVideoChapters)...)
+			_video_id.GET("/download", append(_downloadvideoMw(), api.DownloadVideo)...)
+			_video_id.DELETE("/favorite", append(_removefavoriteMw(), api.RemoveFavorite)...)
+			_video_id.PUT("/favorite", append(_addfavoriteMw(), api.AddFavorite)...)
+			_video_id.GET("/manifest.mpd", append(_getdashmanifestMw(), api.GetDASHManifest)...)
 			_video_id.GET("/play", append(_getvideoplayurlMw(), api.GetVideoPlayURL)...)
+			_video_id.PUT("/progress", append(_updatewatchprogressMw(), api.UpdateWatchProgress)...)
+			_video_id.POST("/reprocess", append(_reprocessvideoMw(), api.ReprocessVideo)...)
+			_video_id.POST("/shares", append(_createshareMw(), api.CreateShare)...)
+			_video_id.GET("/stream", append(_streamvideoMw(), api.StreamVideo)...)
+			_video_id.DELETE("/tags", append(_removevideotagsMw(), api.RemoveVideoTags)...)
+			_video_id.POST("/tags", append(_addvideotagsMw(), api.AddVideoTags)...)
+			_video_id.POST("/thumbnail", append(_uploadcustomthumbnailMw(), api.UploadCustomThumbnail)...)
+			_video_id.PUT("/thumbnail", append(_selectthumbnailMw(), api.SelectThumbnail)...)
+			_thumbnail := _video_id.Group("/thumbnail", _thumbnailMw()...)
+			_thumbnail.POST("/candidates", append(_generatethumbnailcandidatesMw(), api.GenerateThumbnailCandidates)...)
+			_video_id.GET("/thumbnails.vtt", append(_getthumbnailtrackMw(), api.GetThumbnailTrack)...)
+			_video_id.GET("/versions", append(_listvideoversionsMw(), api.ListVideoVersions)...)
+			_video_id.POST("/versions", append(_uploadvideoversionMw(), api.UploadVideoVersion)...)
+			_versions := _video_id.Group("/versions", _versionsMw()...)
+			_version := _versions.Group("/:version", _versionMw()...)
+			_version.POST("/restore", append(_restorevideoversionMw(), api.RestoreVideoVersion)...)
+			{
+				_hls := _video_id.Group("/hls", _hlsMw()...)
+				_hls.GET("/:playlist", append(_gethlsplaylistMw(), api.GetHLSPlaylist)...)
+			}
 			_v1.POST("/videos", append(_uploadvideoMw(), api.UploadVideo)...)
 		}
 	}
+	{
+		_embed := root.Group("/embed", _embedMw()...)
+		_embed.GET("/:video_id", append(_getembedplayerMw(), api.GetEmbedPlayer)...)
+	}
+	{
+		_s := root.Group("/s", _sMw()...)
+		_s.GET("/:token", append(_resolveshareMw(), api.ResolveShare)...)
+	}
 }
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/tempfile"
+)
+
+// storageScanMaxAge 存储扫描结果的有效期，过期后获取统计时在后台重新扫描
+const storageScanMaxAge = 10 * time.Minute
+
+// 存储扫描检查的文件前缀，前缀之外的文件（例如仅登记的导入文件、HLS分片）不计入孤立文件
+const (
+	scanVideoPrefix     = "videos/"
+	scanThumbnailPrefix = "thumbnails/"
+	scanPreviewPrefix   = "previews/"
+)
+
+// storageScan 存储扫描结果
+type storageScan struct {
+	scannedAt        time.Time
+	duration         time.Duration
+	thumbnailObjects int
+	thumbnailBytes   int64
+	previewObjects   int
+	previewBytes     int64
+	orphanedObjects  int
+	orphanedBytes    int64
+	err              error
+}
+
+// scanLocation 存储扫描的存储桶和前缀，objects和bytes不为空时累加文件数量和大小
+type scanLocation struct {
+	bucket  string
+	prefix  string
+	objects *int
+	bytes   *int64
+}
+
+// GetAdminStats 获取存储使用统计，users为用户数量
+// 视频统计由元数据服务增量维护；缩略图占用和孤立文件需要列出存储，使用缓存的扫描结果，过期时在后台重新扫描
+func (s *VideoService) GetAdminStats(ctx context.Context, users int) (*api.AdminStatsResponse, error) {
+	stats := s.metadataService.GetStats(ctx)
+
+	scan := s.storageScan.Load()
+	if scan == nil || time.Since(scan.scannedAt) > storageScanMaxAge {
+		s.refreshStorageScan()
+	}
+
+	result := &api.AdminStats{
+		TotalVideos:         int32(stats.Total.Count),
+		TotalBytes:          stats.Total.Bytes,
+		ArchivedVideos:      int32(stats.Archived.Count),
+		ArchivedBytes:       stats.Archived.Bytes,
+		VideosWithThumbnail: int32(stats.WithThumbnail),
+		VideosWithPreview:   int32(stats.WithPreview),
+		ByFormat:            convertUsageStats(stats.ByFormat, true),
+		ByUploader:          convertUsageStats(stats.ByUploader, true),
+		ByMonth:             convertUsageStats(stats.ByMonth, false),
+		Rows: &api.TableRowCounts{
+			Videos:       int32(s.metadataService.Count(ctx)),
+			Tags:         int32(stats.Tags),
+			Users:        int32(users),
+			Collections:  int32(s.collections.Count(ctx)),
+			Playlists:    int32(s.playlists.Count(ctx)),
+			Favorites:    int32(s.favorites.Count(ctx)),
+			WatchHistory: int32(s.history.Count(ctx)),
+			ViewStats:    int32(s.views.Count(ctx)),
+		},
+		GeneratedAt: time.Now().UnixMilli(),
+	}
+	if scan != nil {
+		result.Storage = convertStorageScan(scan)
+	}
+	if s.tempFiles != nil {
+		result.Temp = convertTempStats(s.tempFiles.Stats())
+	}
+
+	return &api.AdminStatsResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Stats: result,
+	}, nil
+}
+
+// refreshStorageScan 在后台扫描存储，已有扫描正在运行时直接返回
+func (s *VideoService) refreshStorageScan() {
+	if !s.storageScanning.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer s.storageScanning.Store(false)
+		scan := s.scanStorage(context.Background())
+		if scan.err != nil {
+			fmt.Printf("存储扫描失败: %v\n", scan.err)
+		}
+		s.storageScan.Store(scan)
+	}()
+}
+
+// scanStorage 列出视频、归档、缩略图和动态预览文件，统计缩略图占用和没有被任何视频引用的文件
+func (s *VideoService) scanStorage(ctx context.Context) *storageScan {
+	started := time.Now()
+	scan := &storageScan{}
+	defer func() {
+		scan.scannedAt = time.Now()
+		scan.duration = scan.scannedAt.Sub(started)
+	}()
+
+	list, err := s.metadataService.ListMetadata(ctx, &metadata.ListMetadataRequest{Limit: math.MaxInt})
+	if err != nil {
+		scan.err = fmt.Errorf("获取视频列表失败: %w", err)
+		return scan
+	}
+
+	thumbnailBucket := s.buckets.Bucket(storage.ContentThumbnails)
+	referenced := make(map[string]bool, len(list.Items)*3)
+	for _, meta := range list.Items {
+		bucket := meta.BucketName
+		if meta.Archived && s.archiver != nil {
+			bucket = s.archiver.Bucket()
+		}
+		referenced[bucket+"/"+meta.ObjectName] = true
+		for _, version := range meta.Versions {
+			referenced[version.BucketName+"/"+version.ObjectName] = true
+		}
+		if meta.Thumbnail != "" {
+			referenced[thumbnailBucket+"/"+meta.Thumbnail] = true
+		}
+		if meta.Preview != "" {
+			referenced[thumbnailBucket+"/"+meta.Preview] = true
+		}
+	}
+
+	locations := []scanLocation{
+		{bucket: s.buckets.Bucket(storage.ContentVideos), prefix: scanVideoPrefix},
+		{bucket: thumbnailBucket, prefix: scanThumbnailPrefix, objects: &scan.thumbnailObjects, bytes: &scan.thumbnailBytes},
+		{bucket: thumbnailBucket, prefix: scanPreviewPrefix, objects: &scan.previewObjects, bytes: &scan.previewBytes},
+	}
+	if s.archiver != nil {
+		locations = append(locations, scanLocation{bucket: s.archiver.Bucket(), prefix: scanVideoPrefix})
+	}
+
+	for _, location := range locations {
+		exists, err := s.storageClient.BucketExists(ctx, location.bucket)
+		if err != nil {
+			scan.err = fmt.Errorf("检查存储桶失败(%s): %w", location.bucket, err)
+			return scan
+		}
+		if !exists {
+			continue
+		}
+
+		files, err := s.storageClient.ListFiles(ctx, location.bucket, location.prefix)
+		if err != nil {
+			scan.err = fmt.Errorf("列出文件失败(%s/%s): %w", location.bucket, location.prefix, err)
+			return scan
+		}
+		for _, file := range files {
+			if location.objects != nil {
+				*location.objects++
+				*location.bytes += file.Size
+			}
+			if !referenced[location.bucket+"/"+file.Key] {
+				scan.orphanedObjects++
+				scan.orphanedBytes += file.Size
+			}
+		}
+	}
+	return scan
+}
+
+// convertUsageStats 转换分组统计，bySize为true时按大小降序，否则按分组键升序
+func convertUsageStats(groups map[string]metadata.Usage, bySize bool) []*api.UsageStat {
+	stats := make([]*api.UsageStat, 0, len(groups))
+	for key, usage := range groups {
+		stats = append(stats, &api.UsageStat{Key: key, Count: int32(usage.Count), Bytes: usage.Bytes})
+	}
+	slices.SortFunc(stats, func(a, b *api.UsageStat) int {
+		if bySize && a.Bytes != b.Bytes {
+			return cmp.Compare(b.Bytes, a.Bytes)
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	return stats
+}
+
+// convertStorageScan 转换存储扫描结果
+func convertStorageScan(scan *storageScan) *api.StorageScanStats {
+	stats := &api.StorageScanStats{
+		ScannedAt:        scan.scannedAt.UnixMilli(),
+		DurationMs:       scan.duration.Milliseconds(),
+		ThumbnailObjects: int32(scan.thumbnailObjects),
+		ThumbnailBytes:   scan.thumbnailBytes,
+		PreviewObjects:   int32(scan.previewObjects),
+		PreviewBytes:     scan.previewBytes,
+		OrphanedObjects:  int32(scan.orphanedObjects),
+		OrphanedBytes:    scan.orphanedBytes,
+	}
+	if scan.err != nil {
+		message := scan.err.Error()
+		stats.Error = &message
+	}
+	return stats
+}
+
+// convertTempStats 转换临时目录统计
+func convertTempStats(stats tempfile.Stats) *api.TempDirStats {
+	result := &api.TempDirStats{
+		Dir:          stats.Dir,
+		Files:        int32(stats.Files),
+		Bytes:        stats.Bytes,
+		MaxBytes:     stats.MaxBytes,
+		Created:      stats.Created,
+		Rejected:     stats.Rejected,
+		CleanedFiles: stats.CleanedFiles,
+		CleanedBytes: stats.CleanedBytes,
+	}
+	if !stats.LastCleanupAt.IsZero() {
+		result.LastCleanupAt = stats.LastCleanupAt.UnixMilli()
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/tempfile"
+)
+
+func TestVideoService_GetAdminStats(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("统计视频和存储使用", func(t *testing.T) {
+		service, store := createArchiveTestService(t)
+		first := uploadTestVideo(t, service, "first.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), first.Base.Code, first.Base.Message)
+		second := uploadTestVideo(t, service, "second.MP4", mp4TestData(4096))
+		require.Equal(t, int32(0), second.Base.Code, second.Base.Message)
+		_, err := service.ArchiveVideo(ctx, &api.VideoArchiveRequest{VideoID: second.Video.ID})
+		require.NoError(t, err)
+		_, err = service.favorites.Add(ctx, "alice", first.Video.ID)
+		require.NoError(t, err)
+
+		// 没有被任何视频引用的文件
+		_, err = store.UploadFile(ctx, "zhulong-videos", "videos/2025/08/orphan.mp4", []byte("orphan"), "video/mp4")
+		require.NoError(t, err)
+		_, err = store.UploadFile(ctx, "zhulong-videos", "thumbnails/2025/08/orphan.jpg", []byte("jpg"), "image/jpeg")
+		require.NoError(t, err)
+
+		resp, err := service.GetAdminStats(ctx, 3)
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		stats := resp.Stats
+		assert.Equal(t, int32(2), stats.TotalVideos)
+		assert.Equal(t, int64(2048+4096), stats.TotalBytes)
+		assert.Equal(t, int32(1), stats.ArchivedVideos)
+		assert.Equal(t, int64(4096), stats.ArchivedBytes)
+		assert.Equal(t, []*api.UsageStat{{Key: "mp4", Count: 2, Bytes: 2048 + 4096}}, stats.ByFormat)
+		assert.Equal(t, []*api.UsageStat{{Key: "system", Count: 2, Bytes: 2048 + 4096}}, stats.ByUploader)
+		require.Len(t, stats.ByMonth, 1)
+		assert.Equal(t, time.Now().Format("2006-01"), stats.ByMonth[0].Key)
+		assert.Equal(t, &api.TableRowCounts{Videos: 2, Users: 3, Favorites: 1}, stats.Rows)
+
+		// 首次获取时在后台扫描存储
+		require.Eventually(t, func() bool {
+			resp, err = service.GetAdminStats(ctx, 3)
+			require.NoError(t, err)
+			return resp.Stats.Storage != nil
+		}, 5*time.Second, 10*time.Millisecond)
+		scan := resp.Stats.Storage
+		assert.Nil(t, scan.Error)
+		assert.Equal(t, int32(2), scan.OrphanedObjects, "只有未被引用的视频和缩略图应该计为孤立文件")
+		assert.Equal(t, int64(len("orphan")+len("jpg")), scan.OrphanedBytes)
+		assert.GreaterOrEqual(t, scan.ThumbnailObjects, int32(1))
+		assert.Greater(t, scan.ScannedAt, int64(0))
+	})
+
+	t.Run("临时目录统计", func(t *testing.T) {
+		service, _ := createArchiveTestService(t)
+		resp, err := service.GetAdminStats(ctx, 0)
+		require.NoError(t, err)
+		assert.Nil(t, resp.Stats.Temp, "未设置临时目录管理器时不返回统计")
+
+		tempFiles, err := tempfile.NewManager(&tempfile.Config{Dir: t.TempDir(), MaxBytes: 1024})
+		require.NoError(t, err)
+		service.tempFiles = tempFiles
+		file, err := tempFiles.CreateTemp("zhulong-preview-*")
+		require.NoError(t, err)
+		_, err = file.Write([]byte("buffered"))
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+
+		resp, err = service.GetAdminStats(ctx, 0)
+		require.NoError(t, err)
+		require.NotNil(t, resp.Stats.Temp)
+		assert.Equal(t, &api.TempDirStats{
+			Dir:      tempFiles.Dir(),
+			Files:    1,
+			Bytes:    int64(len("buffered")),
+			MaxBytes: 1024,
+			Created:  1,
+		}, resp.Stats.Temp)
+	})
+
+	t.Run("按大小降序排列分组", func(t *testing.T) {
+		groups := convertUsageStats(map[string]metadata.Usage{
+			"mp4":  {Count: 1, Bytes: 10},
+			"mkv":  {Count: 2, Bytes: 30},
+			"webm": {Count: 1, Bytes: 10},
+		}, true)
+		require.Len(t, groups, 3)
+		assert.Equal(t, []string{"mkv", "mp4", "webm"}, []string{groups[0].Key, groups[1].Key, groups[2].Key}, "大小相同时按分组键升序")
+
+		months := convertUsageStats(map[string]metadata.Usage{
+			"2025-09": {Count: 1, Bytes: 10},
+			"2025-08": {Count: 1, Bytes: 30},
+		}, false)
+		assert.Equal(t, "2025-08", months[0].Key, "月份应该按时间顺序排列")
+	})
+}
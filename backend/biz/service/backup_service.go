@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/backup"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// newBackupStore 根据backup配置创建备份存储，未配置备份存储桶时返回nil
+func newBackupStore(cfg *config.Config, storageClient storage.StorageInterface) (*backup.Store, error) {
+	if cfg.Backup.Bucket == "" {
+		return nil, nil
+	}
+	return backup.NewStore(storageClient, cfg.Backup.Bucket, max(cfg.Backup.Retention, 0))
+}
+
+// CreateBackup 立即备份视频元数据和配置文件
+func (s *VideoService) CreateBackup(ctx context.Context) (*api.BackupResponse, error) {
+	if s.backups == nil {
+		return s.backupErrorResponse(9701, "未配置备份存储桶"), nil
+	}
+
+	info, err := s.backupNow(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &api.BackupResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "备份成功",
+		},
+		Backup: convertBackupInfo(info),
+	}, nil
+}
+
+// ListBackups 列出备份存储桶中的备份，按时间从新到旧排列
+func (s *VideoService) ListBackups(ctx context.Context) (*api.BackupListResponse, error) {
+	if s.backups == nil {
+		return &api.BackupListResponse{
+			Base: &api.BaseResponse{
+				Code:    9701,
+				Message: "未配置备份存储桶",
+			},
+		}, nil
+	}
+
+	infos, err := s.backups.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	backups := make([]*api.BackupInfo, 0, len(infos))
+	for i := range infos {
+		backups = append(backups, convertBackupInfo(&infos[i]))
+	}
+	return &api.BackupListResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Backups: backups,
+	}, nil
+}
+
+// RestoreBackup 用备份替换全部视频元数据，name为latest时恢复最近一次备份
+// 配置文件不会被改写，备份时的配置文件内容在响应中返回，需要时手动写回并重启服务
+func (s *VideoService) RestoreBackup(ctx context.Context, req *api.BackupRestoreRequest) (*api.BackupRestoreResponse, error) {
+	if s.backups == nil {
+		return s.backupRestoreErrorResponse(9701, "未配置备份存储桶"), nil
+	}
+	if req.Name == "" {
+		return s.backupRestoreErrorResponse(9701, "备份名称不能为空"), nil
+	}
+
+	s.backupMutex.Lock()
+	defer s.backupMutex.Unlock()
+
+	info, data, err := s.backups.Load(ctx, req.Name)
+	if errors.Is(err, backup.ErrNotFound) {
+		return s.backupRestoreErrorResponse(9702, "备份不存在"), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	restored, err := s.metadataService.Restore(ctx, data.Videos)
+	if err != nil {
+		return s.backupRestoreErrorResponse(9701, err.Error()), nil
+	}
+
+	resp := &api.BackupRestoreResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "恢复成功",
+		},
+		Backup:         convertBackupInfo(info),
+		RestoredVideos: int32(restored),
+	}
+	if data.ConfigFile != "" {
+		resp.ConfigFile = &data.ConfigFile
+	}
+	if data.Config != "" {
+		resp.Config = &data.Config
+	}
+	return resp, nil
+}
+
+// backupNow 保存当前的全部视频元数据和配置文件
+func (s *VideoService) backupNow(ctx context.Context) (*backup.Info, error) {
+	s.backupMutex.Lock()
+	defer s.backupMutex.Unlock()
+
+	data := &backup.Backup{
+		Version:    backup.FormatVersion,
+		CreatedAt:  time.Now(),
+		Videos:     s.metadataService.Snapshot(ctx),
+		ConfigFile: s.config.SourceFile(),
+	}
+	if data.ConfigFile != "" {
+		content, err := os.ReadFile(data.ConfigFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取配置文件失败: %w", err)
+		}
+		data.Config = string(content)
+	}
+	return s.backups.Save(ctx, data)
+}
+
+// startBackupScheduler 在后台定期备份
+func (s *VideoService) startBackupScheduler(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			info, err := s.backupNow(context.Background())
+			if err != nil {
+				fmt.Printf("定期备份失败: %v\n", err)
+				continue
+			}
+			fmt.Printf("定期备份完成: %s/%s\n", s.backups.Bucket(), info.Name)
+		}
+	}()
+}
+
+// convertBackupInfo 转换备份信息
+func convertBackupInfo(info *backup.Info) *api.BackupInfo {
+	return &api.BackupInfo{
+		Name:      info.Name,
+		Size:      info.Size,
+		CreatedAt: info.CreatedAt.UnixMilli(),
+	}
+}
+
+// backupErrorResponse 创建备份错误响应
+func (s *VideoService) backupErrorResponse(code int32, message string) *api.BackupResponse {
+	return &api.BackupResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// backupRestoreErrorResponse 创建恢复备份错误响应
+func (s *VideoService) backupRestoreErrorResponse(code int32, message string) *api.BackupRestoreResponse {
+	return &api.BackupRestoreResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
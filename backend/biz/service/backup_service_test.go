@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/backup"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+// createBackupTestService 创建配置了备份存储桶的测试服务，配置从临时文件加载
+func createBackupTestService(t *testing.T) *VideoService {
+	service := createStreamTestService(t)
+
+	configFile := filepath.Join(t.TempDir(), "zhulong.yml")
+	require.NoError(t, os.WriteFile(configFile, []byte("server:\n  port: 8888\n"), 0o600))
+	cfg, err := config.LoadFromFile(configFile)
+	require.NoError(t, err)
+	service.config = cfg
+
+	service.backups, err = backup.NewStore(service.storageClient, "zhulong-backups", 2)
+	require.NoError(t, err)
+	return service
+}
+
+func TestVideoService_Backup(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("备份后恢复元数据", func(t *testing.T) {
+		service := createBackupTestService(t)
+
+		created, err := service.CreateBackup(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int32(0), created.Base.Code, created.Base.Message)
+		assert.Positive(t, created.Backup.Size)
+
+		// 备份之后的修改在恢复时被丢弃
+		require.NoError(t, service.metadataService.DeleteMetadata(ctx, "video1"))
+		require.NoError(t, service.metadataService.SaveMetadata(ctx, &metadata.FileMetadata{
+			FileID:     "video2",
+			BucketName: "zhulong-videos",
+			ObjectName: "videos/2025/08/video2.mp4",
+			FileName:   "video2.mp4",
+			Title:      "备份后上传",
+			CreatedBy:  "system",
+		}))
+
+		restored, err := service.RestoreBackup(ctx, &api.BackupRestoreRequest{Name: backup.Latest})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), restored.Base.Code, restored.Base.Message)
+		assert.Equal(t, created.Backup.Name, restored.Backup.Name)
+		assert.Equal(t, int32(1), restored.RestoredVideos)
+		assert.Equal(t, service.config.SourceFile(), restored.GetConfigFile())
+		assert.Equal(t, "server:\n  port: 8888\n", restored.GetConfig())
+
+		meta, err := service.metadataService.GetMetadata(ctx, "video1")
+		require.NoError(t, err)
+		assert.Equal(t, "测试视频", meta.Title)
+		_, err = service.metadataService.GetMetadata(ctx, "video2")
+		assert.Error(t, err)
+	})
+
+	t.Run("只保留最近的备份", func(t *testing.T) {
+		service := createBackupTestService(t)
+
+		for i := 0; i < 3; i++ {
+			created, err := service.CreateBackup(ctx)
+			require.NoError(t, err)
+			require.Equal(t, int32(0), created.Base.Code, created.Base.Message)
+			time.Sleep(2 * time.Millisecond)
+		}
+
+		list, err := service.ListBackups(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int32(0), list.Base.Code, list.Base.Message)
+		require.Len(t, list.Backups, 2)
+		assert.Greater(t, list.Backups[0].CreatedAt, list.Backups[1].CreatedAt, "应该按时间从新到旧排列")
+	})
+
+	t.Run("未配置备份和备份不存在", func(t *testing.T) {
+		service := createStreamTestService(t)
+
+		created, err := service.CreateBackup(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int32(9701), created.Base.Code)
+
+		list, err := service.ListBackups(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int32(9701), list.Base.Code)
+
+		service = createBackupTestService(t)
+		restored, err := service.RestoreBackup(ctx, &api.BackupRestoreRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(9701), restored.Base.Code)
+
+		restored, err = service.RestoreBackup(ctx, &api.BackupRestoreRequest{Name: backup.Latest})
+		require.NoError(t, err)
+		assert.Equal(t, int32(9702), restored.Base.Code)
+	})
+}
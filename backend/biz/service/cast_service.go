@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/media"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/streaming"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+const (
+	// castStreamType 投屏的流类型，点播媒体为BUFFERED
+	castStreamType = "BUFFERED"
+	// castExpiryMargin 默认地址有效期在时长之外的余量，暂停或拖动后仍可继续播放
+	castExpiryMargin = time.Hour
+	// castPosterPath 海报在签名视频流地址之后的路径
+	castPosterPath = "/poster"
+	// castSubtitlePath 字幕在签名视频流地址之后的路径前缀，字幕地址为subtitles/<语言>.vtt
+	castSubtitlePath = "/subtitles/"
+)
+
+// 投屏接收端可以直接播放的格式，按文件扩展名判断，不检查编码
+var (
+	// chromecastFormats Chromecast默认媒体接收器支持的容器、音频和图片格式
+	chromecastFormats = []string{"mp4", "webm", "ts", "mp3", "flac", "wav", "ogg", "jpg", "png", "gif", "webp"}
+	// airplayFormats AirPlay可以直接播放的视频和音频格式
+	airplayFormats = []string{"mp4", "mov", "mp3", "wav"}
+)
+
+// CastAsset 投屏设备通过播放令牌读取的海报或字幕
+type CastAsset struct {
+	Base        *api.BaseResponse
+	ContentType string
+	Data        []byte
+}
+
+// GetVideoCastInfo 获取投屏需要的媒体信息，地址均为带播放令牌的绝对地址
+// 未配置playback.base_url时使用requestBaseURL（请求的协议和主机），投屏设备需要能够访问该地址
+func (s *VideoService) GetVideoCastInfo(ctx context.Context, req *api.VideoCastRequest, requestBaseURL string) (*api.VideoCastResponse, error) {
+	if req.VideoID == "" {
+		return s.castErrorResponse(7101, "视频ID不能为空"), nil
+	}
+	if req.ExpireSeconds < 0 || time.Duration(req.ExpireSeconds)*time.Second > maxPlayURLExpiry {
+		return s.castErrorResponse(7101, fmt.Sprintf("地址过期时间必须在1到%d秒之间", int64(maxPlayURLExpiry/time.Second))), nil
+	}
+
+	meta, err := s.getVisibleMetadata(ctx, req.VideoID)
+	if err != nil {
+		return s.castErrorResponse(7102, "视频不存在"), nil
+	}
+	meta, err = s.restoreArchivedVideo(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	// 默认有效期覆盖完整的播放时长
+	expiry := time.Duration(meta.Duration)*time.Second + castExpiryMargin
+	if req.ExpireSeconds > 0 {
+		expiry = time.Duration(req.ExpireSeconds) * time.Second
+	}
+	if expiry > maxPlayURLExpiry {
+		expiry = maxPlayURLExpiry
+	}
+
+	token, _, err := s.playbackSigner.Sign(currentUserID(ctx), meta.FileID, expiry)
+	if err != nil {
+		return nil, fmt.Errorf("签发播放令牌失败: %w", err)
+	}
+	baseURL := s.playbackBaseURL()
+	if baseURL == "" {
+		baseURL = strings.TrimRight(requestBaseURL, "/")
+	}
+
+	format := media.FormatFromFilename(meta.FileName)
+	contentURL := castAssetURL(baseURL, meta.FileID, "", token)
+	contentType := castContentType(meta, format)
+	streamType := castStreamType
+	duration := float64(meta.Duration)
+	expiresAt := time.Now().Add(expiry).UnixMilli()
+	mediaType := getValueOrDefaultFromString(meta.MediaType, metadata.MediaTypeVideo)
+	chromecastSupported := slices.Contains(chromecastFormats, format)
+	airplaySupported := slices.Contains(airplayFormats, format)
+	resp := &api.VideoCastResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		ContentURL:          &contentURL,
+		ContentType:         &contentType,
+		StreamType:          &streamType,
+		Duration:            &duration,
+		Title:               &meta.Title,
+		ExpiresAt:           &expiresAt,
+		MediaType:           &mediaType,
+		ChromecastSupported: &chromecastSupported,
+		AirplaySupported:    &airplaySupported,
+		Subtitles:           []*api.SubtitleTrack{},
+	}
+	if meta.Thumbnail != "" {
+		posterURL := castAssetURL(baseURL, meta.FileID, castPosterPath, token)
+		resp.PosterURL = &posterURL
+	}
+
+	// 投屏接收端只支持WebVTT，同一语言同时有vtt和srt时只返回一个轨道
+	files, err := s.listSubtitleFiles(ctx, meta.FileID)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if n := len(resp.Subtitles); n > 0 && resp.Subtitles[n-1].Language == file.Language {
+			continue
+		}
+		resp.Subtitles = append(resp.Subtitles, &api.SubtitleTrack{
+			Language: file.Language,
+			Format:   "vtt",
+			URL:      castAssetURL(baseURL, meta.FileID, castSubtitlePath+url.PathEscape(file.Language)+".vtt", token),
+		})
+	}
+
+	s.views.RecordView(ctx, meta.FileID)
+	return resp, nil
+}
+
+// castAssetURL 带播放令牌的投屏地址，suffix为签名视频流地址之后的路径，为空时为视频流本身
+func castAssetURL(baseURL, videoID, suffix, token string) string {
+	return fmt.Sprintf("%s%s/%s%s?token=%s", baseURL, signedStreamPath, url.PathEscape(videoID), suffix, url.QueryEscape(token))
+}
+
+// castContentType 投屏媒体的内容类型，优先使用按格式确定的标准类型，客户端上传时提供的类型可能不准确
+func castContentType(meta *metadata.FileMetadata, format string) string {
+	contentType := media.ContentTypeForFormat(format)
+	if meta.IsVideo() {
+		contentType = video.ContentTypeForFormat(format)
+	}
+	if contentType == "application/octet-stream" && meta.ContentType != "" {
+		return meta.ContentType
+	}
+	return contentType
+}
+
+// GetSignedPoster 校验播放令牌后读取视频的缩略图，作为投屏时的海报
+func (s *VideoService) GetSignedPoster(ctx context.Context, videoID, token string) (*CastAsset, error) {
+	meta, errAsset := s.signedCastVideo(ctx, videoID, token)
+	if errAsset != nil {
+		return errAsset, nil
+	}
+	if meta.Thumbnail == "" {
+		return castAssetError(7103, "视频没有缩略图"), nil
+	}
+
+	data, err := s.readStorageObject(ctx, s.buckets.Bucket(storage.ContentThumbnails), meta.Thumbnail)
+	if err != nil {
+		return nil, fmt.Errorf("读取缩略图失败: %w", err)
+	}
+	return &CastAsset{
+		Base:        &api.BaseResponse{Code: 0, Message: "获取成功"},
+		ContentType: "image/jpeg",
+		Data:        data,
+	}, nil
+}
+
+// GetSignedSubtitle 校验播放令牌后读取视频的字幕，name为"<语言>.vtt"
+// 只有SRT格式的字幕转换为WebVTT返回
+func (s *VideoService) GetSignedSubtitle(ctx context.Context, videoID, name, token string) (*CastAsset, error) {
+	language, ok := strings.CutSuffix(name, ".vtt")
+	if !ok || language == "" || path.Base(language) != language {
+		return castAssetError(7101, "字幕名称无效"), nil
+	}
+	if _, errAsset := s.signedCastVideo(ctx, videoID, token); errAsset != nil {
+		return errAsset, nil
+	}
+
+	files, err := s.listSubtitleFiles(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+	// 按格式排序时srt位于vtt之前，已有WebVTT字幕时直接使用
+	index := slices.IndexFunc(files, func(file subtitleFile) bool {
+		return file.Language == language && file.Format == "vtt"
+	})
+	if index < 0 {
+		index = slices.IndexFunc(files, func(file subtitleFile) bool { return file.Language == language })
+	}
+	if index < 0 {
+		return castAssetError(7103, "字幕不存在"), nil
+	}
+
+	data, err := s.readStorageObject(ctx, s.buckets.Bucket(storage.ContentSubtitles), files[index].Key)
+	if err != nil {
+		return nil, fmt.Errorf("读取字幕失败: %w", err)
+	}
+	if files[index].Format == "srt" {
+		data = streaming.SRTToWebVTT(data)
+	}
+	return &CastAsset{
+		Base:        &api.BaseResponse{Code: 0, Message: "获取成功"},
+		ContentType: streaming.WebVTTContentType,
+		Data:        data,
+	}, nil
+}
+
+// signedCastVideo 校验播放令牌并获取视频元数据，令牌签发时已经检查过可见性
+// 签发后被审核限制或按delete处理到期的视频按不存在处理
+func (s *VideoService) signedCastVideo(ctx context.Context, videoID, token string) (*metadata.FileMetadata, *CastAsset) {
+	if token == "" {
+		return nil, castAssetError(6401, "播放令牌不能为空")
+	}
+	if _, err := s.playbackSigner.Verify(token, videoID); err != nil {
+		return nil, castAssetError(6401, err.Error())
+	}
+	meta, err := s.metadataService.GetMetadata(ctx, videoID)
+	if err != nil || meta.Blocked(time.Now()) {
+		return nil, castAssetError(7102, "视频不存在")
+	}
+	return meta, nil
+}
+
+// readStorageObject 读取存储对象的完整内容，用于缩略图和字幕等小文件
+func (s *VideoService) readStorageObject(ctx context.Context, bucketName, objectName string) ([]byte, error) {
+	reader, err := s.storageClient.OpenFile(ctx, bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// castAssetError 创建投屏资源错误响应
+func castAssetError(code int32, message string) *CastAsset {
+	return &CastAsset{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// castErrorResponse 创建投屏媒体信息错误响应
+func (s *VideoService) castErrorResponse(code int32, message string) *api.VideoCastResponse {
+	return &api.VideoCastResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/storage"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/streaming"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// castURLToken 解析投屏地址，返回路径和播放令牌
+func castURLToken(t *testing.T, rawURL string) (string, string) {
+	parsed, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	assert.Equal(t, "nas.lan:8888", parsed.Host)
+	return parsed.Path, parsed.Query().Get("token")
+}
+
+func TestVideoService_GetVideoCastInfo(t *testing.T) {
+	ctx := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "alice"})
+
+	t.Run("返回带播放令牌的绝对地址", func(t *testing.T) {
+		service := createSignedPlaybackTestService(t)
+		store := service.storageClient.(*storage.MemoryStorage)
+		thumbnail := "thumbnails/2025/08/video1.jpg"
+		duration := int64(600)
+		require.NoError(t, service.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{
+			FileID:    "video1",
+			Thumbnail: &thumbnail,
+			Duration:  &duration,
+		}))
+		putTestObject(t, store, thumbnail, []byte("jpg"))
+		putTestObject(t, store, "subtitles/video1/zh-CN.srt", []byte("1\n00:00:01,000 --> 00:00:02,000\n你好\n"))
+		putTestObject(t, store, "subtitles/video1/en.srt", []byte("1"))
+		putTestObject(t, store, "subtitles/video1/en.vtt", []byte("WEBVTT\n"))
+
+		resp, err := service.GetVideoCastInfo(ctx, &api.VideoCastRequest{VideoID: "video1"}, "http://localhost:8888")
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, "video/mp4", *resp.ContentType)
+		assert.Equal(t, castStreamType, *resp.StreamType)
+		assert.Equal(t, float64(600), *resp.Duration)
+		assert.Equal(t, "测试视频", *resp.Title)
+		assert.Equal(t, metadata.MediaTypeVideo, *resp.MediaType)
+		assert.True(t, *resp.ChromecastSupported)
+		assert.True(t, *resp.AirplaySupported)
+		assert.InDelta(t, time.Now().Add(600*time.Second+castExpiryMargin).UnixMilli(), *resp.ExpiresAt, 5000, "默认有效期应该覆盖视频时长")
+
+		path, token := castURLToken(t, *resp.ContentURL)
+		assert.Equal(t, "/stream/video1", path)
+		claims, err := service.playbackSigner.Verify(token, "video1")
+		require.NoError(t, err)
+		assert.Equal(t, "alice", claims.UserID)
+
+		require.NotNil(t, resp.PosterURL)
+		path, posterToken := castURLToken(t, *resp.PosterURL)
+		assert.Equal(t, "/stream/video1/poster", path)
+		poster, err := service.GetSignedPoster(context.Background(), "video1", posterToken)
+		require.NoError(t, err)
+		require.Equal(t, int32(0), poster.Base.Code, poster.Base.Message)
+		assert.Equal(t, "image/jpeg", poster.ContentType)
+		assert.Equal(t, "jpg", string(poster.Data))
+
+		require.Len(t, resp.Subtitles, 2, "同一语言只返回一个WebVTT轨道")
+		assert.Equal(t, "en", resp.Subtitles[0].Language)
+		assert.Equal(t, "zh-CN", resp.Subtitles[1].Language)
+		for _, track := range resp.Subtitles {
+			assert.Equal(t, "vtt", track.Format)
+		}
+		path, subtitleToken := castURLToken(t, resp.Subtitles[1].URL)
+		assert.Equal(t, "/stream/video1/subtitles/zh-CN.vtt", path)
+
+		subtitle, err := service.GetSignedSubtitle(context.Background(), "video1", "zh-CN.vtt", subtitleToken)
+		require.NoError(t, err)
+		require.Equal(t, int32(0), subtitle.Base.Code, subtitle.Base.Message)
+		assert.Equal(t, streaming.WebVTTContentType, subtitle.ContentType)
+		assert.Equal(t, "WEBVTT\n\n1\n00:00:01.000 --> 00:00:02.000\n你好\n", string(subtitle.Data), "SRT字幕应该转换为WebVTT")
+
+		subtitle, err = service.GetSignedSubtitle(context.Background(), "video1", "en.vtt", subtitleToken)
+		require.NoError(t, err)
+		assert.Equal(t, "WEBVTT\n", string(subtitle.Data), "已有WebVTT字幕时直接返回")
+	})
+
+	t.Run("未配置服务地址时使用请求地址", func(t *testing.T) {
+		service := createSignedPlaybackTestService(t)
+		service.config.Playback.BaseURL = ""
+
+		resp, err := service.GetVideoCastInfo(ctx, &api.VideoCastRequest{VideoID: "video1"}, "http://192.168.1.10:8888/")
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Contains(t, *resp.ContentURL, "http://192.168.1.10:8888/stream/video1?token=")
+		assert.Nil(t, resp.PosterURL, "没有缩略图时不返回海报")
+		assert.Empty(t, resp.Subtitles)
+	})
+
+	t.Run("参数错误和视频不存在", func(t *testing.T) {
+		service := createSignedPlaybackTestService(t)
+
+		resp, err := service.GetVideoCastInfo(ctx, &api.VideoCastRequest{}, "")
+		require.NoError(t, err)
+		assert.Equal(t, int32(7101), resp.Base.Code)
+
+		resp, err = service.GetVideoCastInfo(ctx, &api.VideoCastRequest{VideoID: "video1", ExpireSeconds: 8 * 24 * 3600}, "")
+		require.NoError(t, err)
+		assert.Equal(t, int32(7101), resp.Base.Code)
+
+		resp, err = service.GetVideoCastInfo(ctx, &api.VideoCastRequest{VideoID: "missing"}, "")
+		require.NoError(t, err)
+		assert.Equal(t, int32(7102), resp.Base.Code)
+	})
+
+	t.Run("投屏资源需要有效的播放令牌", func(t *testing.T) {
+		service := createSignedPlaybackTestService(t)
+		token := signedPlayToken(t, service, ctx)
+
+		asset, err := service.GetSignedPoster(context.Background(), "video1", "")
+		require.NoError(t, err)
+		assert.Equal(t, int32(6401), asset.Base.Code)
+
+		asset, err = service.GetSignedSubtitle(context.Background(), "video2", "en.vtt", token)
+		require.NoError(t, err)
+		assert.Equal(t, int32(6401), asset.Base.Code, "令牌不能用于其他视频")
+
+		asset, err = service.GetSignedPoster(context.Background(), "video1", token)
+		require.NoError(t, err)
+		assert.Equal(t, int32(7103), asset.Base.Code)
+
+		asset, err = service.GetSignedSubtitle(context.Background(), "video1", "en.vtt", token)
+		require.NoError(t, err)
+		assert.Equal(t, int32(7103), asset.Base.Code)
+
+		asset, err = service.GetSignedSubtitle(context.Background(), "video1", "en.srt", token)
+		require.NoError(t, err)
+		assert.Equal(t, int32(7101), asset.Base.Code)
+	})
+}
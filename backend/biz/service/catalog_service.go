@@ -0,0 +1,256 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"slices"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/catalog"
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+const (
+	// maxCatalogFileSize 导入的目录文件的最大大小
+	maxCatalogFileSize = 64 << 20
+	// maxCatalogSkippedRecords 导入报告中最多返回的跳过记录数量
+	maxCatalogSkippedRecords = 100
+)
+
+// CatalogFile 导出的目录文件，Base.Code为0时Data有效
+type CatalogFile struct {
+	Base        *api.BaseResponse
+	Data        []byte
+	FileName    string // 附件文件名
+	ContentType string
+}
+
+// ExportCatalog 导出全部视频元数据，按文件ID排序
+func (s *VideoService) ExportCatalog(ctx context.Context, req *api.CatalogExportRequest) (*CatalogFile, error) {
+	format := req.Format
+	if format == "" {
+		format = catalog.FormatJSON
+	}
+	format, err := catalog.NormalizeFormat(format, "")
+	if err != nil {
+		return &CatalogFile{Base: &api.BaseResponse{Code: 7201, Message: err.Error()}}, nil
+	}
+
+	now := time.Now()
+	var buf bytes.Buffer
+	if err := catalog.Write(&buf, format, s.metadataService.Snapshot(ctx), now); err != nil {
+		return nil, err
+	}
+	return &CatalogFile{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "导出成功",
+		},
+		Data:        buf.Bytes(),
+		FileName:    fmt.Sprintf("zhulong-catalog-%s.%s", now.Format("20060102-150405"), format),
+		ContentType: catalog.ContentType(format),
+	}, nil
+}
+
+// ImportCatalog 导入视频元数据目录，先按文件ID、再按校验和匹配已有视频
+// 匹配到的视频用目录中的标题、描述、标签、章节和可见性覆盖，文件位置、创建者和处理状态保持不变；
+// 没有匹配的记录在视频文件存在时按目录内容新建，否则跳过
+func (s *VideoService) ImportCatalog(ctx context.Context, req *api.CatalogImportRequest, fileHeader *multipart.FileHeader) (*api.CatalogImportResponse, error) {
+	if fileHeader == nil {
+		return s.catalogImportErrorResponse(7201, "目录文件不能为空"), nil
+	}
+	format, err := catalog.NormalizeFormat(req.Format, fileHeader.Filename)
+	if err != nil {
+		return s.catalogImportErrorResponse(7201, err.Error()), nil
+	}
+	if fileHeader.Size > maxCatalogFileSize {
+		return s.catalogImportErrorResponse(7201, fmt.Sprintf("目录文件不能超过%dMB", maxCatalogFileSize>>20)), nil
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("打开目录文件失败: %w", err)
+	}
+	defer file.Close()
+	records, err := catalog.Read(io.LimitReader(file, maxCatalogFileSize), format)
+	if err != nil {
+		return s.catalogImportErrorResponse(7202, err.Error()), nil
+	}
+
+	report := &api.CatalogImportReport{
+		Total:          int32(len(records)),
+		SkippedRecords: []*api.CatalogImportSkip{},
+		DryRun:         req.DryRun,
+	}
+	skip := func(record catalog.Record, reason string) {
+		report.Skipped++
+		if len(report.SkippedRecords) < maxCatalogSkippedRecords {
+			report.SkippedRecords = append(report.SkippedRecords, &api.CatalogImportSkip{
+				Line:   int32(record.Line),
+				FileID: record.Metadata.FileID,
+				Reason: reason,
+			})
+		}
+	}
+
+	// 同一个视频只与目录中的一条记录匹配；去重后共享文件的视频校验和相同，
+	// 校验和匹配到的视频已与其他记录匹配时按没有匹配处理
+	seen := make(map[string]bool)
+	matched := make(map[string]int)
+	for _, record := range records {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		item := record.Metadata
+		if err := s.metadataService.ValidateMetadata(item); err != nil {
+			skip(record, err.Error())
+			continue
+		}
+		if seen[item.FileID] {
+			skip(record, "目录中的文件ID重复")
+			continue
+		}
+		seen[item.FileID] = true
+
+		existing, byChecksum := s.matchCatalogRecord(ctx, item)
+		if existing != nil && byChecksum {
+			if _, ok := matched[existing.FileID]; ok {
+				existing = nil
+			}
+		}
+		if existing == nil {
+			created, reason, err := s.importCatalogRecord(ctx, item, req.DryRun)
+			if err != nil {
+				return nil, err
+			}
+			if !created {
+				skip(record, reason)
+				continue
+			}
+			matched[item.FileID] = record.Line
+			report.Created++
+			continue
+		}
+		if line, ok := matched[existing.FileID]; ok {
+			skip(record, fmt.Sprintf("视频%s已与第%d条记录匹配", existing.FileID, line))
+			continue
+		}
+		matched[existing.FileID] = record.Line
+		if byChecksum {
+			report.MatchedByChecksum++
+		}
+
+		update := catalogUpdateRequest(existing, item)
+		if update == nil {
+			report.Unchanged++
+			continue
+		}
+		if !req.DryRun {
+			if err := s.metadataService.UpdateMetadata(ctx, update); err != nil {
+				return nil, fmt.Errorf("更新视频%s失败: %w", existing.FileID, err)
+			}
+		}
+		report.Updated++
+	}
+
+	message := "导入完成"
+	if req.DryRun {
+		message = "检查完成"
+	}
+	return &api.CatalogImportResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: message,
+		},
+		Report: report,
+	}, nil
+}
+
+// matchCatalogRecord 按文件ID或校验和查找目录记录对应的视频，byChecksum表示按校验和匹配
+func (s *VideoService) matchCatalogRecord(ctx context.Context, item *metadata.FileMetadata) (existing *metadata.FileMetadata, byChecksum bool) {
+	if meta, err := s.metadataService.GetMetadata(ctx, item.FileID); err == nil {
+		return meta, false
+	}
+	if item.Checksum == "" {
+		return nil, false
+	}
+	if meta, err := s.metadataService.GetMetadataByChecksum(ctx, item.Checksum); err == nil {
+		return meta, true
+	}
+	return nil, false
+}
+
+// importCatalogRecord 按目录内容新建视频，视频文件不存在时返回跳过原因
+func (s *VideoService) importCatalogRecord(ctx context.Context, item *metadata.FileMetadata, dryRun bool) (bool, string, error) {
+	if item.BucketName == "" || item.ObjectName == "" {
+		return false, "没有匹配的视频且缺少视频文件位置", nil
+	}
+	bucket := item.BucketName
+	if item.Archived && s.archiver != nil {
+		bucket = s.archiver.Bucket()
+	}
+	_, exists, err := s.statObject(ctx, bucket, item.ObjectName)
+	if err != nil {
+		return false, "", err
+	}
+	if !exists {
+		return false, fmt.Sprintf("没有匹配的视频且视频文件不存在: %s/%s", bucket, item.ObjectName), nil
+	}
+
+	if !dryRun {
+		if err := s.metadataService.SaveMetadata(ctx, item); err != nil {
+			return false, "", fmt.Errorf("保存视频%s失败: %w", item.FileID, err)
+		}
+	}
+	return true, "", nil
+}
+
+// catalogUpdateRequest 比较已有视频和目录记录，生成只包含变化字段的更新请求，没有变化时返回nil
+// 目录中的可见性为空时保持不变
+func catalogUpdateRequest(existing, item *metadata.FileMetadata) *metadata.UpdateMetadataRequest {
+	update := &metadata.UpdateMetadataRequest{FileID: existing.FileID}
+	changed := false
+	if item.Title != existing.Title {
+		update.Title = &item.Title
+		changed = true
+	}
+	if item.Description != existing.Description {
+		update.Description = &item.Description
+		changed = true
+	}
+	if !slices.Equal(item.Tags, existing.Tags) {
+		tags := normalizeTags(item.Tags)
+		update.Tags = &tags
+		changed = true
+	}
+	if !slices.Equal(item.Chapters, existing.Chapters) {
+		chapters := slices.Clone(item.Chapters)
+		update.Chapters = &chapters
+		changed = true
+	}
+	if item.Visibility != "" {
+		visibility, _ := metadata.NormalizeVisibility(item.Visibility)
+		if visibility != existing.Visibility {
+			update.Visibility = &visibility
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return update
+}
+
+// catalogImportErrorResponse 创建目录导入错误响应
+func (s *VideoService) catalogImportErrorResponse(code int32, message string) *api.CatalogImportResponse {
+	return &api.CatalogImportResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
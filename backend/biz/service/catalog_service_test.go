@@ -0,0 +1,179 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/catalog"
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+// createCatalogTestService 创建包含两个视频的测试服务，存储中另有video3和video4的视频文件
+func createCatalogTestService(t *testing.T) *VideoService {
+	ctx := context.Background()
+	service, store := createDirectUploadTestService(t)
+	for _, id := range []string{"video1", "video2", "video3", "video4"} {
+		_, err := store.UploadFile(ctx, "zhulong-videos", "videos/2025/08/"+id+".mp4", []byte(id), "video/mp4")
+		require.NoError(t, err)
+	}
+	for _, id := range []string{"video1", "video2"} {
+		require.NoError(t, service.metadataService.SaveMetadata(ctx, &metadata.FileMetadata{
+			FileID:     id,
+			BucketName: "zhulong-videos",
+			ObjectName: "videos/2025/08/" + id + ".mp4",
+			Title:      id,
+			Tags:       []string{"old"},
+			Checksum:   "checksum-" + id,
+			CreatedBy:  "admin",
+		}))
+	}
+	return service
+}
+
+// catalogRecord 创建目录记录
+func catalogRecord(id, objectID, title, checksum string) *metadata.FileMetadata {
+	return &metadata.FileMetadata{
+		FileID:     id,
+		BucketName: "zhulong-videos",
+		ObjectName: "videos/2025/08/" + objectID + ".mp4",
+		Title:      title,
+		Checksum:   checksum,
+		Visibility: metadata.VisibilityPublic,
+		CreatedBy:  "admin",
+	}
+}
+
+// catalogSkipLines 获取跳过记录的行号
+func catalogSkipLines(report *api.CatalogImportReport) []int32 {
+	lines := make([]int32, 0, len(report.SkippedRecords))
+	for _, skip := range report.SkippedRecords {
+		lines = append(lines, skip.Line)
+	}
+	return lines
+}
+
+func TestVideoService_ExportCatalog(t *testing.T) {
+	ctx := context.Background()
+	service := createCatalogTestService(t)
+
+	file, err := service.ExportCatalog(ctx, &api.CatalogExportRequest{})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), file.Base.Code, file.Base.Message)
+	assert.Regexp(t, `^zhulong-catalog-\d{8}-\d{6}\.json$`, file.FileName)
+	assert.Equal(t, "application/json", file.ContentType)
+	records, err := catalog.Read(bytes.NewReader(file.Data), catalog.FormatJSON)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "video1", records[0].Metadata.FileID)
+	assert.Equal(t, "checksum-video2", records[1].Metadata.Checksum)
+
+	file, err = service.ExportCatalog(ctx, &api.CatalogExportRequest{Format: "CSV"})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), file.Base.Code, file.Base.Message)
+	assert.Equal(t, "text/csv; charset=utf-8", file.ContentType)
+	records, err = catalog.Read(bytes.NewReader(file.Data), catalog.FormatCSV)
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	file, err = service.ExportCatalog(ctx, &api.CatalogExportRequest{Format: "xml"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(7201), file.Base.Code)
+}
+
+func TestVideoService_ImportCatalog(t *testing.T) {
+	ctx := context.Background()
+
+	renamed := catalogRecord("video1", "video1", "新标题", "checksum-video1")
+	renamed.Tags = []string{"old"}
+	migrated := catalogRecord("old-2", "video2", "video2", "checksum-video2")
+	migrated.Tags = []string{"migrated"}
+	records := []*metadata.FileMetadata{
+		renamed,  // 1: 按文件ID匹配，更新标题和可见性
+		migrated, // 2: 按校验和匹配video2，更新标签和可见性
+		catalogRecord("old-2b", "video3", "去重副本", "checksum-video2"), // 3: video2已匹配，文件存在时新建
+		catalogRecord("video4", "video4", "新视频", ""),                 // 4: 没有匹配，文件存在时新建
+		catalogRecord("video5", "video5", "文件缺失", ""),                // 5: 文件不存在，跳过
+		catalogRecord("video6", "video4", "", ""),                    // 6: 标题为空，跳过
+		catalogRecord("video1", "video1", "重复", ""),                  // 7: 文件ID重复，跳过
+	}
+	var buf bytes.Buffer
+	require.NoError(t, catalog.Write(&buf, catalog.FormatCSV, records, time.Now()))
+	data := buf.Bytes()
+
+	t.Run("只检查不修改", func(t *testing.T) {
+		service := createCatalogTestService(t)
+
+		resp, err := service.ImportCatalog(ctx, &api.CatalogImportRequest{DryRun: true}, createTestFileHeader(t, "catalog.csv", "text/csv", data))
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.True(t, resp.Report.DryRun)
+		assert.Equal(t, int32(2), resp.Report.Updated)
+		assert.Equal(t, int32(2), resp.Report.Created)
+
+		meta, err := service.metadataService.GetMetadata(ctx, "video1")
+		require.NoError(t, err)
+		assert.Equal(t, "video1", meta.Title)
+		_, err = service.metadataService.GetMetadata(ctx, "video4")
+		assert.Error(t, err)
+	})
+
+	t.Run("按文件ID或校验和匹配", func(t *testing.T) {
+		service := createCatalogTestService(t)
+
+		resp, err := service.ImportCatalog(ctx, &api.CatalogImportRequest{}, createTestFileHeader(t, "catalog.csv", "text/csv", data))
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		report := resp.Report
+		assert.Equal(t, int32(7), report.Total)
+		assert.Equal(t, int32(2), report.Updated)
+		assert.Equal(t, int32(2), report.Created)
+		assert.Equal(t, int32(1), report.MatchedByChecksum)
+		assert.Equal(t, int32(3), report.Skipped)
+		assert.Equal(t, []int32{6, 7, 8}, catalogSkipLines(report), "CSV行号包含表头")
+
+		video1, err := service.metadataService.GetMetadata(ctx, "video1")
+		require.NoError(t, err)
+		assert.Equal(t, "新标题", video1.Title)
+		assert.Equal(t, metadata.VisibilityPublic, video1.Visibility)
+
+		video2, err := service.metadataService.GetMetadata(ctx, "video2")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"migrated"}, video2.Tags)
+		assert.Equal(t, "checksum-video2", video2.Checksum, "文件位置和校验和保持不变")
+
+		for _, id := range []string{"old-2b", "video4"} {
+			created, err := service.metadataService.GetMetadata(ctx, id)
+			require.NoError(t, err, id)
+			assert.Equal(t, metadata.VisibilityPublic, created.Visibility)
+		}
+
+		// 再次导入时全部匹配，内容没有变化
+		resp, err = service.ImportCatalog(ctx, &api.CatalogImportRequest{}, createTestFileHeader(t, "catalog.csv", "text/csv", data))
+		require.NoError(t, err)
+		assert.Equal(t, int32(4), resp.Report.Unchanged)
+		assert.Zero(t, resp.Report.Updated)
+		assert.Zero(t, resp.Report.Created)
+	})
+
+	t.Run("请求无效", func(t *testing.T) {
+		service := createCatalogTestService(t)
+
+		resp, err := service.ImportCatalog(ctx, &api.CatalogImportRequest{}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int32(7201), resp.Base.Code)
+
+		resp, err = service.ImportCatalog(ctx, &api.CatalogImportRequest{}, createTestFileHeader(t, "catalog.txt", "text/plain", data))
+		require.NoError(t, err)
+		assert.Equal(t, int32(7201), resp.Base.Code, "无法判断目录格式")
+
+		resp, err = service.ImportCatalog(ctx, &api.CatalogImportRequest{Format: "json"}, createTestFileHeader(t, "catalog.txt", "text/plain", data))
+		require.NoError(t, err)
+		assert.Equal(t, int32(7202), resp.Base.Code, "内容与格式不符")
+	})
+}
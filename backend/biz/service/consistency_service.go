@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// 一致性问题类型
+const (
+	issueMissingObject    = "missing_object"    // 视频文件不存在，无法自动修复
+	issueSizeMismatch     = "size_mismatch"     // 文件大小与元数据不一致，修复时以存储中的文件为准
+	issueChecksumMismatch = "checksum_mismatch" // 校验和与元数据不一致，文件可能已损坏，无法自动修复
+	issueMissingThumbnail = "missing_thumbnail" // 缩略图不存在，修复时清除缩略图路径
+	issueMissingPreview   = "missing_preview"   // 动态预览不存在，修复时清除动态预览路径
+)
+
+const (
+	// consistencyDefaultLimit 默认返回的问题数量
+	consistencyDefaultLimit = 100
+	// consistencyMaxLimit 最多返回的问题数量
+	consistencyMaxLimit = 1000
+)
+
+// consistencyCheck 一次一致性检查的进度和结果
+type consistencyCheck struct {
+	fix    bool
+	limit  int
+	report *api.ConsistencyReport
+}
+
+// addIssue 记录问题，超过limit的问题只计数
+func (c *consistencyCheck) addIssue(issue *api.ConsistencyIssue) {
+	c.report.TotalIssues++
+	c.report.IssuesByKind[issue.Kind]++
+	if issue.Fixed {
+		c.report.FixedIssues++
+	}
+	if len(c.report.Issues) < c.limit {
+		c.report.Issues = append(c.report.Issues, issue)
+	}
+}
+
+// CheckConsistency 检查每个视频的文件、缩略图和动态预览是否存在，文件大小和校验和是否与元数据一致
+// 与管理统计中的孤立文件（存储中没有元数据引用的文件）相反，这里检查元数据引用但存储中缺失或不一致的文件
+func (s *VideoService) CheckConsistency(ctx context.Context, req *api.ConsistencyRequest) (*api.ConsistencyResponse, error) {
+	if req.Limit < 0 || req.Limit > consistencyMaxLimit {
+		return s.consistencyErrorResponse(9901, fmt.Sprintf("limit必须在0到%d之间", consistencyMaxLimit)), nil
+	}
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = consistencyDefaultLimit
+	}
+
+	check := &consistencyCheck{
+		fix:   req.Fix,
+		limit: limit,
+		report: &api.ConsistencyReport{
+			IssuesByKind: map[string]int32{},
+			Issues:       []*api.ConsistencyIssue{},
+		},
+	}
+	for _, meta := range s.metadataService.Snapshot(ctx) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := s.checkVideoConsistency(ctx, check, meta, req.Checksum); err != nil {
+			return nil, fmt.Errorf("检查视频%s失败: %w", meta.FileID, err)
+		}
+		check.report.CheckedVideos++
+	}
+	check.report.CheckedAt = time.Now().UnixMilli()
+
+	message := "检查完成"
+	if req.Fix {
+		message = "检查并修复完成"
+	}
+	return &api.ConsistencyResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: message,
+		},
+		Report: check.report,
+	}, nil
+}
+
+// checkVideoConsistency 检查单个视频，已归档视频的文件在归档存储桶中
+func (s *VideoService) checkVideoConsistency(ctx context.Context, check *consistencyCheck, meta *metadata.FileMetadata, verifyChecksum bool) error {
+	bucket := meta.BucketName
+	if meta.Archived && s.archiver != nil {
+		bucket = s.archiver.Bucket()
+	}
+
+	check.report.CheckedObjects++
+	info, exists, err := s.statObject(ctx, bucket, meta.ObjectName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		check.addIssue(&api.ConsistencyIssue{VideoID: meta.FileID, Kind: issueMissingObject, Bucket: bucket, Object: meta.ObjectName})
+	} else {
+		if info.Size != meta.FileSize {
+			issue := &api.ConsistencyIssue{
+				VideoID:  meta.FileID,
+				Kind:     issueSizeMismatch,
+				Bucket:   bucket,
+				Object:   meta.ObjectName,
+				Expected: optionalString(strconv.FormatInt(meta.FileSize, 10)),
+				Actual:   optionalString(strconv.FormatInt(info.Size, 10)),
+			}
+			if check.fix {
+				if err := s.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{FileID: meta.FileID, FileSize: &info.Size}); err != nil {
+					return err
+				}
+				issue.Fixed = true
+			}
+			check.addIssue(issue)
+		}
+
+		// 默认比较上传时记录在对象元数据中的校验和，不读取文件内容
+		actual := info.Metadata[storage.ObjectKeyContentHash]
+		if verifyChecksum && meta.Checksum != "" {
+			if actual, err = s.uploadService.ComputeChecksum(ctx, bucket, meta.ObjectName); err != nil {
+				return err
+			}
+		}
+		if meta.Checksum != "" && actual != "" && !strings.EqualFold(meta.Checksum, actual) {
+			check.addIssue(&api.ConsistencyIssue{
+				VideoID:  meta.FileID,
+				Kind:     issueChecksumMismatch,
+				Bucket:   bucket,
+				Object:   meta.ObjectName,
+				Expected: optionalString(meta.Checksum),
+				Actual:   optionalString(actual),
+			})
+		}
+	}
+
+	thumbnailBucket := s.buckets.Bucket(storage.ContentThumbnails)
+	for _, asset := range []struct {
+		kind   string
+		object string
+		clear  *metadata.UpdateMetadataRequest
+	}{
+		{issueMissingThumbnail, meta.Thumbnail, &metadata.UpdateMetadataRequest{FileID: meta.FileID, Thumbnail: optionalString("")}},
+		{issueMissingPreview, meta.Preview, &metadata.UpdateMetadataRequest{FileID: meta.FileID, Preview: optionalString("")}},
+	} {
+		if asset.object == "" {
+			continue
+		}
+		check.report.CheckedObjects++
+		_, exists, err := s.statObject(ctx, thumbnailBucket, asset.object)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		issue := &api.ConsistencyIssue{VideoID: meta.FileID, Kind: asset.kind, Bucket: thumbnailBucket, Object: asset.object}
+		if check.fix {
+			if err := s.metadataService.UpdateMetadata(ctx, asset.clear); err != nil {
+				return err
+			}
+			issue.Fixed = true
+		}
+		check.addIssue(issue)
+	}
+	return nil
+}
+
+// statObject 获取文件信息，文件不存在时返回false，无法访问存储时返回错误
+func (s *VideoService) statObject(ctx context.Context, bucket, object string) (*storage.FileInfo, bool, error) {
+	info, err := s.storageClient.GetFileInfo(ctx, bucket, object)
+	if err == nil {
+		return info, true, nil
+	}
+	exists, existsErr := s.storageClient.FileExists(ctx, bucket, object)
+	if existsErr != nil {
+		return nil, false, fmt.Errorf("检查文件失败: %w", existsErr)
+	}
+	if exists {
+		return nil, false, fmt.Errorf("获取文件信息失败: %w", err)
+	}
+	return nil, false, nil
+}
+
+// optionalString 返回字符串指针，用于可选字段
+func optionalString(value string) *string {
+	return &value
+}
+
+// consistencyErrorResponse 创建一致性检查错误响应
+func (s *VideoService) consistencyErrorResponse(code int32, message string) *api.ConsistencyResponse {
+	return &api.ConsistencyResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
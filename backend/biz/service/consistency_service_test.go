@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+// createConsistencyTestService 创建包含不一致视频的测试服务
+// video1的文件大小与元数据不一致且缩略图缺失，video2的文件缺失
+func createConsistencyTestService(t *testing.T) *VideoService {
+	service := createStreamTestService(t)
+	ctx := context.Background()
+
+	thumbnail := "thumbnails/2025/08/video1.jpg"
+	require.NoError(t, service.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{FileID: "video1", Thumbnail: &thumbnail}))
+	require.NoError(t, service.metadataService.SaveMetadata(ctx, &metadata.FileMetadata{
+		FileID:     "video2",
+		BucketName: "zhulong-videos",
+		ObjectName: "videos/2025/08/video2.mp4",
+		FileName:   "video2.mp4",
+		FileSize:   10,
+		Title:      "文件缺失",
+		CreatedBy:  "system",
+	}))
+	return service
+}
+
+// issueKinds 获取报告中的问题类型
+func issueKinds(report *api.ConsistencyReport) []string {
+	kinds := make([]string, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		kinds = append(kinds, issue.VideoID+":"+issue.Kind)
+	}
+	return kinds
+}
+
+func TestVideoService_CheckConsistency(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("报告不一致的问题", func(t *testing.T) {
+		service := createConsistencyTestService(t)
+
+		resp, err := service.CheckConsistency(ctx, &api.ConsistencyRequest{})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		report := resp.Report
+		assert.Equal(t, int32(2), report.CheckedVideos)
+		assert.Equal(t, int32(3), report.CheckedObjects)
+		assert.Equal(t, int32(3), report.TotalIssues)
+		assert.Zero(t, report.FixedIssues)
+		assert.Equal(t, []string{"video1:size_mismatch", "video1:missing_thumbnail", "video2:missing_object"}, issueKinds(report))
+		assert.Equal(t, "0", *report.Issues[0].Expected)
+		assert.Equal(t, "10", *report.Issues[0].Actual)
+
+		meta, err := service.metadataService.GetMetadata(ctx, "video1")
+		require.NoError(t, err)
+		assert.NotEmpty(t, meta.Thumbnail, "未要求修复时不修改元数据")
+	})
+
+	t.Run("修复大小和缩略图", func(t *testing.T) {
+		service := createConsistencyTestService(t)
+
+		resp, err := service.CheckConsistency(ctx, &api.ConsistencyRequest{Fix: true, Limit: 1})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, int32(3), resp.Report.TotalIssues)
+		assert.Equal(t, int32(2), resp.Report.FixedIssues, "文件缺失无法自动修复")
+		assert.Len(t, resp.Report.Issues, 1, "超过limit的问题只计数")
+		assert.Equal(t, map[string]int32{"size_mismatch": 1, "missing_thumbnail": 1, "missing_object": 1}, resp.Report.IssuesByKind)
+
+		meta, err := service.metadataService.GetMetadata(ctx, "video1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), meta.FileSize)
+		assert.Empty(t, meta.Thumbnail)
+
+		resp, err = service.CheckConsistency(ctx, &api.ConsistencyRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"video2:missing_object"}, issueKinds(resp.Report))
+	})
+
+	t.Run("重新计算校验和", func(t *testing.T) {
+		service := createStreamTestService(t)
+		size := int64(10)
+		require.NoError(t, service.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{FileID: "video1", FileSize: &size}))
+		meta, err := service.metadataService.GetMetadata(ctx, "video1")
+		require.NoError(t, err)
+		meta.Checksum = "0000000000000000000000000000000000000000000000000000000000000000"
+		require.NoError(t, service.metadataService.SaveMetadata(ctx, meta))
+
+		resp, err := service.CheckConsistency(ctx, &api.ConsistencyRequest{})
+		require.NoError(t, err)
+		assert.Zero(t, resp.Report.TotalIssues, "对象没有记录校验和时默认不比较")
+
+		resp, err = service.CheckConsistency(ctx, &api.ConsistencyRequest{Checksum: true, Fix: true})
+		require.NoError(t, err)
+		require.Equal(t, []string{"video1:checksum_mismatch"}, issueKinds(resp.Report))
+		assert.False(t, resp.Report.Issues[0].Fixed, "校验和不一致无法自动修复")
+		assert.Equal(t, "84d89877f0d4041efb6bf91a16f0248f2fd573e6af05c19f96bedb9f882f7882", *resp.Report.Issues[0].Actual)
+	})
+
+	t.Run("参数错误", func(t *testing.T) {
+		service := createStreamTestService(t)
+
+		resp, err := service.CheckConsistency(ctx, &api.ConsistencyRequest{Limit: 1001})
+		require.NoError(t, err)
+		assert.Equal(t, int32(9901), resp.Base.Code)
+	})
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/upload"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+// DemoVideo 演示模式预置的示例视频
+type DemoVideo struct {
+	FileName    string
+	Title       string // 为空时使用不含扩展名的文件名
+	Description string
+	Tags        []string
+	Data        []byte
+	Info        *video.VideoInfo // 视频信息，为nil时从数据中提取
+	CreatedBy   string           // 上传者用户ID
+}
+
+// SeedDemoVideo 将示例视频写入存储并登记为公开视频，与批量导入相同经过缩略图生成和HLS打包
+// 示例视频由服务自身生成，不经过上传验证和配额检查；内容已存在时跳过并返回false
+func (s *VideoService) SeedDemoVideo(ctx context.Context, demo *DemoVideo) (bool, error) {
+	if demo == nil || demo.FileName == "" || len(demo.Data) == 0 {
+		return false, fmt.Errorf("示例视频的文件名和内容不能为空")
+	}
+	if demo.CreatedBy == "" {
+		return false, fmt.Errorf("示例视频的上传者不能为空")
+	}
+
+	checksum, err := upload.ChecksumReader(bytes.NewReader(demo.Data))
+	if err != nil {
+		return false, err
+	}
+	if _, err := s.metadataService.GetMetadataByChecksum(ctx, checksum); err == nil {
+		return false, nil
+	}
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(demo.FileName)), ".")
+	size := int64(len(demo.Data))
+	info := demo.Info
+	if info == nil {
+		info, err = s.videoExtractor.ExtractInfo(&video.InfoExtractionRequest{
+			Data:     demo.Data[:min(len(demo.Data), videoHeadSize)],
+			Filename: demo.FileName,
+			Reader:   bytes.NewReader(demo.Data),
+			Size:     size,
+		})
+		if err != nil {
+			info = &video.VideoInfo{Filename: demo.FileName, Format: format, FileSize: size}
+		}
+	}
+
+	videoID := uuid.New().String()
+	bucketName := s.buckets.Bucket(storage.ContentVideos)
+	contentType := video.ContentTypeForFormat(format)
+	now := time.Now()
+	result, err := s.uploadService.UploadFile(ctx, &upload.UploadRequest{
+		BucketName:  bucketName,
+		FileName:    demo.FileName,
+		ObjectName:  fmt.Sprintf("videos/%d/%02d/%s%s", now.Year(), now.Month(), videoID, upload.ObjectExtension(demo.FileName)),
+		Reader:      bytes.NewReader(demo.Data),
+		Size:        size,
+		ContentType: contentType,
+		Checksum:    checksum,
+		Metadata:    videoObjectLabels(videoID, demo.CreatedBy),
+		Tags:        videoObjectLabels(videoID, demo.CreatedBy),
+	})
+	if err != nil {
+		return false, fmt.Errorf("文件上传失败: %w", err)
+	}
+
+	title := demo.Title
+	if title == "" {
+		title = strings.TrimSuffix(demo.FileName, filepath.Ext(demo.FileName))
+	}
+	_, err = s.finalizeUpload(ctx, &uploadedVideo{
+		VideoID:     videoID,
+		BucketName:  bucketName,
+		ObjectName:  result.ObjectName,
+		FileName:    demo.FileName,
+		ContentType: contentType,
+		Size:        size,
+		Title:       title,
+		Description: demo.Description,
+		Info:        info,
+		Checksum:    checksum,
+		HeadData:    demo.Data[:min(len(demo.Data), videoHeadSize)],
+		Reader:      bytes.NewReader(demo.Data),
+		// 公开后未登录也可以浏览和播放
+		Visibility: metadata.VisibilityPublic,
+		CreatedBy:  demo.CreatedBy,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if len(demo.Tags) > 0 {
+		if err := s.metadataService.AddTags(ctx, videoID, demo.Tags); err != nil {
+			return false, fmt.Errorf("添加标签失败: %w", err)
+		}
+	}
+	return true, nil
+}
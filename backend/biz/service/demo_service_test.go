@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/video"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVideoService_SeedDemoVideo(t *testing.T) {
+	ctx := context.Background()
+	service, store := createDirectUploadTestService(t)
+
+	demo := &DemoVideo{
+		FileName:    "color-bars.mp4",
+		Description: "演示用彩条",
+		Tags:        []string{"Demo", "test-pattern"},
+		Data:        mp4TestData(4096),
+		Info:        &video.VideoInfo{Duration: 10 * time.Second, Width: 640, Height: 360},
+		CreatedBy:   "admin-1",
+	}
+	seeded, err := service.SeedDemoVideo(ctx, demo)
+	require.NoError(t, err)
+	assert.True(t, seeded)
+
+	videos := importedVideos(t, service)
+	require.Len(t, videos, 1)
+	meta := videos["color-bars"]
+	require.NotNil(t, meta, "未指定标题时应该使用不含扩展名的文件名")
+	assert.Equal(t, metadata.VisibilityPublic, meta.Visibility, "示例视频应该公开")
+	assert.Equal(t, "video/mp4", meta.ContentType)
+	assert.Equal(t, int64(10), meta.Duration)
+	assert.Equal(t, 640, meta.Width)
+	assert.Equal(t, []string{"demo", "test-pattern"}, meta.Tags)
+	assert.Equal(t, "admin-1", meta.CreatedBy)
+
+	data, err := store.DownloadFile(ctx, meta.BucketName, meta.ObjectName)
+	require.NoError(t, err)
+	assert.Equal(t, demo.Data, data, "视频内容应该写入内存存储")
+	tags, err := store.GetObjectTags(ctx, meta.BucketName, meta.ObjectName)
+	require.NoError(t, err)
+	assert.Equal(t, meta.FileID, tags[storage.ObjectKeyVideoID])
+
+	seeded, err = service.SeedDemoVideo(ctx, demo)
+	require.NoError(t, err)
+	assert.False(t, seeded, "内容已存在时应该跳过")
+	assert.Len(t, importedVideos(t, service), 1)
+
+	_, err = service.SeedDemoVideo(ctx, &DemoVideo{FileName: "empty.mp4", CreatedBy: "admin-1"})
+	assert.Error(t, err, "内容为空时应该返回错误")
+	_, err = service.SeedDemoVideo(ctx, &DemoVideo{FileName: "orphan.mp4", Data: mp4TestData(1024)})
+	assert.Error(t, err, "未指定上传者时应该返回错误")
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+const (
+	// embedPath 嵌入播放器页面的路径前缀
+	embedPath = "/embed"
+	// oembedProviderName oEmbed响应中的服务名称
+	oembedProviderName = "Zhulong"
+	// oembedCacheAge oEmbed响应的建议缓存时间，小于缩略图URL的有效期
+	oembedCacheAge = 24 * time.Hour
+	// 嵌入播放器的默认宽度，未知分辨率的视频按16:9计算高度
+	defaultEmbedWidth  = 640
+	defaultEmbedHeight = 360
+)
+
+// GetEmbedPlayer 获取嵌入播放器页面需要的视频信息和播放URL
+func (s *VideoService) GetEmbedPlayer(ctx context.Context, req *api.EmbedPlayerRequest) (*api.EmbedPlayerResponse, error) {
+	if req.VideoID == "" {
+		return s.embedPlayerErrorResponse(6601, "视频ID不能为空"), nil
+	}
+
+	meta, err := s.getVisibleMetadata(ctx, req.VideoID)
+	if err != nil {
+		return s.embedPlayerErrorResponse(6602, "视频不存在"), nil
+	}
+	meta, err = s.restoreArchivedVideo(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	playURL, err := s.playURL(ctx, meta, defaultPlayURLExpiry)
+	if err != nil {
+		return nil, err
+	}
+	playResp := &api.VideoPlayURLResponse{}
+	if err := s.fillPlaybackAssets(ctx, playResp, meta, defaultPlayURLExpiry); err != nil {
+		return nil, err
+	}
+
+	s.views.RecordView(ctx, meta.FileID)
+	return &api.EmbedPlayerResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Video:        convertToSharedVideo(meta),
+		PlayURL:      &playURL,
+		ThumbnailURL: playResp.ThumbnailURL,
+		Subtitles:    playResp.Subtitles,
+	}, nil
+}
+
+// GetOEmbed 按oEmbed规范返回视频链接的嵌入信息
+// 未配置playback.base_url时，嵌入地址使用requestBaseURL（请求的协议和主机）
+func (s *VideoService) GetOEmbed(ctx context.Context, req *api.OEmbedRequest, requestBaseURL string) (*api.OEmbedResponse, error) {
+	if format := getValueOrDefault(req.Format, "json"); format != "json" {
+		return s.oembedErrorResponse(6603, fmt.Sprintf("不支持的响应格式: %s", format)), nil
+	}
+	if req.URL == "" {
+		return s.oembedErrorResponse(6601, "URL不能为空"), nil
+	}
+	var maxWidth, maxHeight int
+	if req.Maxwidth != nil {
+		maxWidth = int(*req.Maxwidth)
+	}
+	if req.Maxheight != nil {
+		maxHeight = int(*req.Maxheight)
+	}
+	if maxWidth < 0 || maxHeight < 0 {
+		return s.oembedErrorResponse(6601, "最大宽度和最大高度不能为负数"), nil
+	}
+
+	videoID, ok := videoIDFromURL(req.URL)
+	if !ok {
+		return s.oembedErrorResponse(6602, "无法识别的视频地址"), nil
+	}
+	meta, err := s.getVisibleMetadata(ctx, videoID)
+	if err != nil {
+		return s.oembedErrorResponse(6602, "视频不存在"), nil
+	}
+
+	baseURL := s.playbackBaseURL()
+	if baseURL == "" {
+		baseURL = strings.TrimRight(requestBaseURL, "/")
+	}
+	apiVideo := convertToAPIVideo(meta)
+	width, height := embedSize(int(apiVideo.Width), int(apiVideo.Height), maxWidth, maxHeight)
+	embedURL := fmt.Sprintf("%s%s/%s", baseURL, embedPath, url.PathEscape(meta.FileID))
+
+	resp := &api.OEmbedResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Version:      "1.0",
+		Type:         "video",
+		Title:        meta.Title,
+		ProviderName: oembedProviderName,
+		ProviderURL:  baseURL + "/",
+		HTML: fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" frameborder="0" allow="autoplay; fullscreen" allowfullscreen></iframe>`,
+			html.EscapeString(embedURL), width, height),
+		Width:  int32(width),
+		Height: int32(height),
+	}
+
+	if meta.Thumbnail != "" {
+		// 缩略图URL需要在展开的链接被缓存期间保持有效
+		thumbnailURL, err := s.presignedURL(ctx, s.buckets.Bucket(storage.ContentThumbnails), meta.Thumbnail, maxPlayURLExpiry)
+		if err != nil {
+			return nil, fmt.Errorf("生成缩略图URL失败: %w", err)
+		}
+		thumbnailW, thumbnailH := fitSize(int(apiVideo.Width), int(apiVideo.Height), thumbnailWidth, thumbnailHeight)
+		resp.ThumbnailURL = &thumbnailURL
+		resp.ThumbnailWidth = int32Ptr(int32(thumbnailW))
+		resp.ThumbnailHeight = int32Ptr(int32(thumbnailH))
+	}
+	cacheAge := int64(oembedCacheAge / time.Second)
+	resp.CacheAge = &cacheAge
+
+	return resp, nil
+}
+
+// videoIDFromURL 从视频页面、视频接口或嵌入播放器地址中解析视频ID
+// 支持路径中包含/embed/<视频ID>或/videos/<视频ID>的地址
+func videoIDFromURL(rawURL string) (string, bool) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+
+	segments := strings.Split(strings.Trim(path.Clean(parsed.Path), "/"), "/")
+	for i := len(segments) - 2; i >= 0; i-- {
+		if (segments[i] == "embed" || segments[i] == "videos") && segments[i+1] != "" {
+			return segments[i+1], true
+		}
+	}
+	return "", false
+}
+
+// embedSize 计算嵌入播放器的尺寸，默认宽度640并保持视频宽高比，不超过指定的最大宽度和最大高度
+func embedSize(videoWidth, videoHeight, maxWidth, maxHeight int) (int, int) {
+	if videoWidth <= 0 || videoHeight <= 0 {
+		videoWidth, videoHeight = defaultEmbedWidth, defaultEmbedHeight
+	}
+
+	width, height := defaultEmbedWidth, max(1, videoHeight*defaultEmbedWidth/videoWidth)
+	if maxWidth > 0 || maxHeight > 0 {
+		if maxWidth == 0 {
+			maxWidth = width
+		}
+		if maxHeight == 0 {
+			maxHeight = height
+		}
+		if width > maxWidth || height > maxHeight {
+			width, height = fitSize(width, height, maxWidth, maxHeight)
+		}
+	}
+	return width, height
+}
+
+// fitSize 按宽高比缩放到不超过maxWidth×maxHeight，分辨率未知时返回上限尺寸
+func fitSize(width, height, maxWidth, maxHeight int) (int, int) {
+	if width <= 0 || height <= 0 {
+		return maxWidth, maxHeight
+	}
+	if width*maxHeight > height*maxWidth {
+		return maxWidth, max(1, height*maxWidth/width)
+	}
+	return max(1, width*maxHeight/height), maxHeight
+}
+
+// int32Ptr 返回int32值的指针
+func int32Ptr(value int32) *int32 {
+	return &value
+}
+
+// embedPlayerErrorResponse 创建嵌入播放器错误响应
+func (s *VideoService) embedPlayerErrorResponse(code int32, message string) *api.EmbedPlayerResponse {
+	return &api.EmbedPlayerResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// oembedErrorResponse 创建oEmbed错误响应
+func (s *VideoService) oembedErrorResponse(code int32, message string) *api.OEmbedResponse {
+	return &api.OEmbedResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
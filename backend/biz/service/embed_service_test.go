@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+)
+
+func TestVideoService_GetEmbedPlayer(t *testing.T) {
+	ctx := context.Background()
+	service := createStreamTestService(t)
+
+	resp, err := service.GetEmbedPlayer(ctx, &api.EmbedPlayerRequest{VideoID: "video1"})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+	assert.Equal(t, "测试视频", resp.Video.Title)
+	assert.NotEmpty(t, resp.GetPlayURL())
+	assert.Equal(t, int64(1), service.views.ViewCount(ctx, "video1"), "打开嵌入播放器应该计入播放次数")
+
+	resp, err = service.GetEmbedPlayer(ctx, &api.EmbedPlayerRequest{VideoID: "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(6602), resp.Base.Code)
+}
+
+func TestVideoService_GetOEmbed(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("返回嵌入代码", func(t *testing.T) {
+		service := createStreamTestService(t)
+
+		resp, err := service.GetOEmbed(ctx, &api.OEmbedRequest{URL: "http://nas.lan:8888/embed/video1"}, "http://127.0.0.1:8888")
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, "1.0", resp.Version)
+		assert.Equal(t, "video", resp.Type)
+		assert.Equal(t, "测试视频", resp.Title)
+		assert.Equal(t, "http://127.0.0.1:8888/", resp.ProviderURL, "未配置服务地址时应该使用请求地址")
+		assert.Contains(t, resp.HTML, `<iframe src="http://127.0.0.1:8888/embed/video1" width="640" height="360"`)
+		assert.Equal(t, int32(640), resp.Width)
+		assert.Equal(t, int32(360), resp.Height)
+		assert.Nil(t, resp.ThumbnailURL, "没有缩略图时不返回缩略图地址")
+	})
+
+	t.Run("使用配置的服务地址和最大尺寸", func(t *testing.T) {
+		service := createStreamTestService(t)
+		service.config.Playback.BaseURL = "http://nas.lan:8888/"
+		maxWidth := int32(320)
+
+		resp, err := service.GetOEmbed(ctx, &api.OEmbedRequest{URL: "http://nas.lan:8888/videos/video1", Maxwidth: &maxWidth}, "http://127.0.0.1:8888")
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Contains(t, resp.HTML, `src="http://nas.lan:8888/embed/video1" width="320" height="180"`)
+	})
+
+	t.Run("错误请求", func(t *testing.T) {
+		service := createStreamTestService(t)
+		xml := "xml"
+		negative := int32(-1)
+
+		for _, tt := range []struct {
+			req  *api.OEmbedRequest
+			code int32
+		}{
+			{&api.OEmbedRequest{URL: "http://nas.lan:8888/embed/video1", Format: &xml}, 6603},
+			{&api.OEmbedRequest{}, 6601},
+			{&api.OEmbedRequest{URL: "http://nas.lan:8888/embed/video1", Maxheight: &negative}, 6601},
+			{&api.OEmbedRequest{URL: "http://nas.lan:8888/about"}, 6602},
+			{&api.OEmbedRequest{URL: "http://nas.lan:8888/embed/missing"}, 6602},
+		} {
+			resp, err := service.GetOEmbed(ctx, tt.req, "http://127.0.0.1:8888")
+			require.NoError(t, err)
+			assert.Equal(t, tt.code, resp.Base.Code, "请求%+v", tt.req)
+		}
+	})
+}
+
+func TestVideoIDFromURL(t *testing.T) {
+	for _, tt := range []struct {
+		url     string
+		videoID string
+		ok      bool
+	}{
+		{"http://nas.lan:8888/embed/abc", "abc", true},
+		{"http://nas.lan:8888/embed/abc/", "abc", true},
+		{"http://nas.lan:8888/api/v1/videos/abc", "abc", true},
+		{"http://nas.lan:8888/api/v1/videos/abc/play", "abc", true},
+		{"/videos/abc?t=10", "abc", true},
+		{"http://nas.lan:8888/videos", "", false},
+		{"http://nas.lan:8888/", "", false},
+		{"://bad", "", false},
+	} {
+		videoID, ok := videoIDFromURL(tt.url)
+		assert.Equal(t, tt.ok, ok, tt.url)
+		assert.Equal(t, tt.videoID, videoID, tt.url)
+	}
+}
+
+func TestEmbedSize(t *testing.T) {
+	for _, tt := range []struct {
+		videoWidth, videoHeight int
+		maxWidth, maxHeight     int
+		width, height           int
+	}{
+		{0, 0, 0, 0, 640, 360},
+		{1920, 1080, 0, 0, 640, 360},
+		{1080, 1920, 0, 0, 640, 1137},
+		{1080, 1920, 0, 480, 270, 480},
+		{1920, 1080, 480, 0, 480, 270},
+		{640, 480, 1280, 1280, 640, 480},
+	} {
+		width, height := embedSize(tt.videoWidth, tt.videoHeight, tt.maxWidth, tt.maxHeight)
+		assert.Equal(t, tt.width, width, "%+v", tt)
+		assert.Equal(t, tt.height, height, "%+v", tt)
+	}
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/notify"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/streaming"
+	"github.com/manteia/zhulong/pkg/tempfile"
+	"github.com/manteia/zhulong/pkg/transcode"
+)
+
+const (
+	// hlsPackageTimeout 单个视频HLS打包超时时间
+	hlsPackageTimeout = 30 * time.Minute
+	// hlsSegmentURLExpiry 分片预签名URL有效期
+	hlsSegmentURLExpiry = time.Hour
+)
+
+// newHLSPackager 根据配置创建HLS打包服务，未启用时返回nil
+func newHLSPackager(cfg *config.Config, storageClient storage.StorageInterface, tempFiles *tempfile.Manager) *streaming.HLSPackager {
+	if !cfg.Streaming.Enabled {
+		return nil
+	}
+
+	var renditions []*streaming.Rendition
+	for _, r := range cfg.Streaming.Renditions {
+		renditions = append(renditions, &streaming.Rendition{
+			Name:         r.Name,
+			Width:        r.Width,
+			Height:       r.Height,
+			VideoBitrate: r.VideoBitrate,
+			AudioBitrate: r.AudioBitrate,
+		})
+	}
+
+	segmenter := streaming.NewFFmpegSegmenter(cfg.Streaming.FFmpegPath)
+	packager := streaming.NewHLSPackager(storageClient, segmenter, cfg.Streaming.SegmentDuration, renditions)
+	packager.SetTempFiles(tempFiles)
+	return packager
+}
+
+// scheduleHLSPackaging 将视频加入转码队列，视频已有未完成的转码任务时只提升其优先级；图片和音频不打包
+func (s *VideoService) scheduleHLSPackaging(meta *metadata.FileMetadata, priority int) {
+	if !meta.IsVideo() || !s.hlsPackagingAvailable() {
+		return
+	}
+
+	job, err := s.transcodeQueue.Enqueue(transcode.KindHLS, meta.FileID, priority)
+	if err != nil {
+		if !errors.Is(err, transcode.ErrJobActive) {
+			fmt.Printf("创建转码任务失败(%s): %v\n", meta.FileID, err)
+		}
+		return
+	}
+	s.resumeProcessing(job)
+}
+
+// packageVideo 执行转码任务：视频已归档时先恢复，然后进行HLS打包
+func (s *VideoService) packageVideo(ctx context.Context, job transcode.Job) error {
+	ctx, cancel := context.WithTimeout(ctx, hlsPackageTimeout)
+	defer cancel()
+
+	meta, err := s.metadataService.GetMetadata(ctx, job.VideoID)
+	if err != nil {
+		return fmt.Errorf("获取视频元数据失败: %w", err)
+	}
+	meta, err = s.restoreArchivedVideo(ctx, meta)
+	if err != nil {
+		fmt.Printf("HLS打包失败(%s): %v\n", job.VideoID, err)
+		return err
+	}
+
+	result, err := s.hlsPackager.Package(ctx, s.packageRequest(meta))
+	if err != nil {
+		fmt.Printf("HLS打包失败(%s, 第%d次): %v\n", job.VideoID, job.Attempts, err)
+		return err
+	}
+	s.publishEvent(notify.EventTranscodeFinished, meta, result)
+	return nil
+}
+
+// packageRequest 根据视频元数据创建HLS打包请求
+func (s *VideoService) packageRequest(meta *metadata.FileMetadata) *streaming.PackageRequest {
+	return &streaming.PackageRequest{
+		VideoID:      meta.FileID,
+		BucketName:   meta.BucketName,
+		ObjectName:   meta.ObjectName,
+		OutputBucket: s.buckets.Bucket(storage.ContentRenditions),
+		Width:        meta.Width,
+		Height:       meta.Height,
+		Bitrate:      meta.Bitrate,
+		Rotation:     meta.Rotation,
+	}
+}
+
+// GetHLSPlaylist 获取HLS播放列表，未打包的视频会触发按需打包
+func (s *VideoService) GetHLSPlaylist(ctx context.Context, req *api.HLSPlaylistRequest) (*api.HLSPlaylistResponse, error) {
+	if req.VideoID == "" {
+		return s.hlsErrorResponse(6001, "视频ID不能为空"), nil
+	}
+	if err := streaming.ValidatePlaylistName(req.Playlist); err != nil {
+		return s.hlsErrorResponse(6001, err.Error()), nil
+	}
+
+	if s.hlsPackager == nil {
+		return s.hlsErrorResponse(6002, "HLS流媒体未启用"), nil
+	}
+
+	meta, err := s.getVisibleMetadata(ctx, req.VideoID)
+	if err != nil {
+		return s.hlsErrorResponse(6003, "视频不存在"), nil
+	}
+	if !meta.IsVideo() {
+		return s.hlsErrorResponse(6002, "只有视频支持HLS流媒体"), nil
+	}
+
+	renditionBucket := s.buckets.Bucket(storage.ContentRenditions)
+	packaged, err := s.hlsPackager.IsPackaged(ctx, renditionBucket, meta.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("检查HLS打包状态失败: %w", err)
+	}
+	if !packaged {
+		if !s.hlsPackagingAvailable() {
+			return s.hlsErrorResponse(6002, "HLS切片器不可用"), nil
+		}
+		s.scheduleHLSPackaging(meta, onDemandTranscodePriority)
+		return s.hlsErrorResponse(6004, "视频正在进行HLS打包，请稍后重试"), nil
+	}
+
+	content, err := s.hlsPackager.GetPlaylist(ctx, renditionBucket, meta.FileID, req.Playlist, hlsSegmentURLExpiry)
+	if err != nil {
+		return s.hlsErrorResponse(6005, fmt.Sprintf("获取播放列表失败: %v", err)), nil
+	}
+	// 播放器只在开始播放时获取一次主播放列表
+	if req.Playlist == streaming.MasterPlaylistName {
+		s.views.RecordView(ctx, meta.FileID)
+	}
+
+	return &api.HLSPlaylistResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Content: string(content),
+	}, nil
+}
+
+// GetDASHManifest 获取DASH清单，清单与HLS播放列表引用相同的fMP4分片
+// 未打包或打包时尚未生成清单（TS分片）的视频会触发按需打包
+func (s *VideoService) GetDASHManifest(ctx context.Context, req *api.DASHManifestRequest) (*api.DASHManifestResponse, error) {
+	if req.VideoID == "" {
+		return s.dashErrorResponse(6701, "视频ID不能为空"), nil
+	}
+	if s.hlsPackager == nil {
+		return s.dashErrorResponse(6702, "流媒体打包未启用"), nil
+	}
+
+	meta, err := s.getVisibleMetadata(ctx, req.VideoID)
+	if err != nil {
+		return s.dashErrorResponse(6703, "视频不存在"), nil
+	}
+	if !meta.IsVideo() {
+		return s.dashErrorResponse(6702, "只有视频支持DASH流媒体"), nil
+	}
+
+	renditionBucket := s.buckets.Bucket(storage.ContentRenditions)
+	packaged, err := s.hlsPackager.IsManifestPackaged(ctx, renditionBucket, meta.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("检查DASH清单状态失败: %w", err)
+	}
+	if !packaged {
+		if !s.hlsPackagingAvailable() {
+			return s.dashErrorResponse(6702, "切片器不可用"), nil
+		}
+		s.scheduleHLSPackaging(meta, onDemandTranscodePriority)
+		return s.dashErrorResponse(6704, "视频正在进行打包，请稍后重试"), nil
+	}
+
+	content, err := s.hlsPackager.GetManifest(ctx, renditionBucket, meta.FileID, hlsSegmentURLExpiry)
+	if err != nil {
+		return s.dashErrorResponse(6705, fmt.Sprintf("获取DASH清单失败: %v", err)), nil
+	}
+	s.views.RecordView(ctx, meta.FileID)
+
+	return &api.DASHManifestResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Content: string(content),
+	}, nil
+}
+
+// hlsErrorResponse 创建HLS播放列表错误响应
+func (s *VideoService) hlsErrorResponse(code int32, message string) *api.HLSPlaylistResponse {
+	return &api.HLSPlaylistResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// dashErrorResponse 创建DASH清单错误响应
+func (s *VideoService) dashErrorResponse(code int32, message string) *api.DASHManifestResponse {
+	return &api.DASHManifestResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/manteia/zhulong/pkg/storage"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/streaming"
+)
+
+func TestVideoService_GetHLSPlaylist(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("获取HLS播放列表_未启用", func(t *testing.T) {
+		service := createTestVideoService(t)
+
+		resp, err := service.GetHLSPlaylist(ctx, &api.HLSPlaylistRequest{
+			VideoID:  "video1",
+			Playlist: streaming.MasterPlaylistName,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6002), resp.Base.Code)
+		assert.Contains(t, resp.Base.Message, "未启用")
+	})
+
+	t.Run("获取HLS播放列表_无效参数", func(t *testing.T) {
+		service := createTestVideoService(t)
+
+		resp, err := service.GetHLSPlaylist(ctx, &api.HLSPlaylistRequest{
+			Playlist: streaming.MasterPlaylistName,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6001), resp.Base.Code)
+		assert.Contains(t, resp.Base.Message, "视频ID")
+
+		resp, err = service.GetHLSPlaylist(ctx, &api.HLSPlaylistRequest{
+			VideoID:  "video1",
+			Playlist: "../secret.m3u8",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6001), resp.Base.Code)
+	})
+
+	t.Run("获取HLS播放列表_视频不存在", func(t *testing.T) {
+		service := createTestVideoService(t)
+		service.hlsPackager = streaming.NewHLSPackager(nil, streaming.NewFFmpegSegmenter(""), 6, nil)
+
+		resp, err := service.GetHLSPlaylist(ctx, &api.HLSPlaylistRequest{
+			VideoID:  "not-exist",
+			Playlist: streaming.MasterPlaylistName,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6003), resp.Base.Code)
+		assert.Equal(t, "视频不存在", resp.Base.Message)
+	})
+}
+
+func TestNewHLSPackager(t *testing.T) {
+	cfg := &config.Config{}
+	assert.Nil(t, newHLSPackager(cfg, nil, nil), "未启用时不应该创建打包服务")
+
+	cfg.Streaming = config.StreamingConfig{
+		Enabled:         true,
+		FFmpegPath:      "ffmpeg",
+		SegmentDuration: 4,
+		Renditions: []config.RenditionConfig{
+			{Name: "720p", Width: 1280, Height: 720, VideoBitrate: 2800, AudioBitrate: 128},
+		},
+	}
+	assert.NotNil(t, newHLSPackager(cfg, nil, nil), "启用时应该创建打包服务")
+}
+
+func TestVideoService_PackageRequest(t *testing.T) {
+	service := createTestVideoService(t)
+	meta := &metadata.FileMetadata{
+		FileID:     "portrait",
+		BucketName: "zhulong-videos",
+		ObjectName: "videos/portrait.mov",
+		Width:      1080,
+		Height:     1920,
+		Bitrate:    6000000,
+		Rotation:   90,
+	}
+
+	req := service.packageRequest(meta)
+	assert.Equal(t, 1080, req.Width)
+	assert.Equal(t, 1920, req.Height)
+	assert.Equal(t, 90, req.Rotation, "打包请求应该携带旋转信息")
+	assert.Equal(t, int32(90), convertToAPIVideo(meta).Rotation)
+}
+
+func TestVideoService_GetDASHManifest(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("获取DASH清单_未启用", func(t *testing.T) {
+		service := createStreamTestService(t)
+
+		resp, err := service.GetDASHManifest(ctx, &api.DASHManifestRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6702), resp.Base.Code)
+
+		resp, err = service.GetDASHManifest(ctx, &api.DASHManifestRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6701), resp.Base.Code)
+	})
+
+	t.Run("获取DASH清单_视频不存在", func(t *testing.T) {
+		service := createStreamTestService(t)
+		service.hlsPackager = streaming.NewHLSPackager(service.storageClient, streaming.NewFFmpegSegmenter("/nonexistent/ffmpeg"), 6, nil)
+
+		resp, err := service.GetDASHManifest(ctx, &api.DASHManifestRequest{VideoID: "missing"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6703), resp.Base.Code)
+
+		resp, err = service.GetDASHManifest(ctx, &api.DASHManifestRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6702), resp.Base.Code, "未打包且切片器不可用时应该返回6702")
+	})
+
+	t.Run("获取DASH清单并返回播放地址", func(t *testing.T) {
+		service := createStreamTestService(t)
+		service.hlsPackager = streaming.NewHLSPackager(service.storageClient, streaming.NewFFmpegSegmenter("/nonexistent/ffmpeg"), 6, nil)
+		store := service.storageClient.(*storage.MemoryStorage)
+
+		manifest, err := streaming.GenerateManifest([]*streaming.Representation{{
+			ID:        streaming.SourceRenditionName,
+			Bandwidth: 1000000,
+			Playlist: &streaming.MediaPlaylist{
+				InitURI:  "source/init.mp4",
+				Segments: []*streaming.MediaSegment{{URI: "source/segment_00000.m4s", Duration: 6}},
+			},
+		}})
+		require.NoError(t, err)
+		putTestObject(t, store, streaming.PlaylistObjectName("video1", streaming.MasterPlaylistName), []byte("#EXTM3U\n"))
+		putTestObject(t, store, streaming.PlaylistObjectName("video1", streaming.ManifestName), manifest)
+
+		resp, err := service.GetDASHManifest(ctx, &api.DASHManifestRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Contains(t, resp.Content, "hls/video1/source/init.mp4", "初始化分片地址应该被替换为预签名URL")
+		assert.Contains(t, resp.Content, "hls/video1/source/segment_00000.m4s", "分片地址应该被替换为预签名URL")
+		assert.Equal(t, int64(1), service.views.ViewCount(ctx, "video1"), "获取DASH清单应该计入播放次数")
+
+		playResp, err := service.GetVideoPlayURL(ctx, &api.VideoPlayURLRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), playResp.Base.Code, playResp.Base.Message)
+		assert.Equal(t, "/api/v1/videos/video1/hls/master.m3u8", playResp.GetHlsURL())
+		assert.Equal(t, "/api/v1/videos/video1/manifest.mpd", playResp.GetDashURL())
+	})
+}
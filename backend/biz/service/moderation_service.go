@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/moderation"
+)
+
+// moderationURLExpiry 传给审核钩子的视频下载地址有效期
+const moderationURLExpiry = time.Hour
+
+// moderateVideo 对刚上传的视频执行审核钩子，在保存元数据前调用
+// 被标记或拦截的视频加入审核队列并记录审核状态，审核通过前只有上传者和管理员可见
+func (s *VideoService) moderateVideo(ctx context.Context, meta *metadata.FileMetadata) {
+	if s.moderation == nil {
+		return
+	}
+
+	// 下载地址生成失败时仍然执行钩子，钩子可以只根据标题和文件名审核
+	videoURL, err := s.presignedURL(ctx, meta.BucketName, meta.ObjectName, moderationURLExpiry)
+	if err != nil {
+		fmt.Printf("生成审核下载地址失败(%s): %v\n", meta.FileID, err)
+	}
+	decision := s.moderation.Run(ctx, &moderation.Subject{
+		VideoID:     meta.FileID,
+		Title:       meta.Title,
+		Description: meta.Description,
+		FileName:    meta.FileName,
+		ContentType: meta.ContentType,
+		Size:        meta.FileSize,
+		Duration:    meta.Duration,
+		CreatedBy:   meta.CreatedBy,
+		URL:         videoURL,
+	})
+	if decision.Verdict == moderation.VerdictApproved {
+		return
+	}
+
+	item, err := s.reviews.Add(meta.FileID, decision)
+	if err != nil {
+		fmt.Printf("加入审核队列失败(%s): %v\n", meta.FileID, err)
+		return
+	}
+	meta.Moderation = item.Status
+}
+
+// ListModerationQueue 按状态列出内容审核队列，默认列出待审核的视频
+func (s *VideoService) ListModerationQueue(ctx context.Context, req *api.ModerationListRequest) (*api.ModerationListResponse, error) {
+	status := strings.ToLower(strings.TrimSpace(getValueOrDefault(req.Status, moderation.StatusPending)))
+	switch status {
+	case moderation.StatusPending, moderation.StatusRejected, moderation.StatusApproved:
+	case "all":
+		status = ""
+	default:
+		return s.moderationListErrorResponse(9501, "审核状态必须为pending、rejected、approved或all"), nil
+	}
+
+	reviewItems := s.reviews.List(status)
+	items := make([]*api.ModerationItem, 0, len(reviewItems))
+	for _, item := range reviewItems {
+		meta, _ := s.metadataService.GetMetadata(ctx, item.VideoID)
+		items = append(items, convertToAPIModerationItem(item, meta))
+	}
+
+	return &api.ModerationListResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Items: items,
+	}, nil
+}
+
+// ReviewModeration 管理员审核视频，通过后视频按可见性正常发布，拒绝后只有上传者和管理员可见
+func (s *VideoService) ReviewModeration(ctx context.Context, req *api.ModerationReviewRequest) (*api.ModerationReviewResponse, error) {
+	if req.VideoID == "" {
+		return s.moderationReviewErrorResponse(9501, "视频ID不能为空"), nil
+	}
+	var status string
+	switch strings.ToLower(strings.TrimSpace(req.Action)) {
+	case moderation.ActionApprove:
+		status = metadata.ModerationApproved
+	case moderation.ActionReject:
+		status = metadata.ModerationRejected
+	default:
+		return s.moderationReviewErrorResponse(9501, "审核操作必须为approve或reject"), nil
+	}
+
+	if _, ok := s.reviews.Get(req.VideoID); !ok {
+		return s.moderationReviewErrorResponse(9502, "视频不在审核队列中"), nil
+	}
+	if _, err := s.metadataService.GetMetadata(ctx, req.VideoID); err != nil {
+		return s.moderationReviewErrorResponse(9502, "视频不存在"), nil
+	}
+
+	item, err := s.reviews.Review(req.VideoID, req.Action, currentUserID(ctx), getValueOrDefault(req.Note, ""))
+	if err != nil {
+		return s.moderationReviewErrorResponse(9502, err.Error()), nil
+	}
+	if err := s.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{FileID: req.VideoID, Moderation: &status}); err != nil {
+		return nil, fmt.Errorf("更新审核状态失败: %w", err)
+	}
+
+	meta, err := s.metadataService.GetMetadata(ctx, req.VideoID)
+	if err != nil {
+		return nil, fmt.Errorf("获取视频元数据失败: %w", err)
+	}
+
+	return &api.ModerationReviewResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "审核成功",
+		},
+		Item: convertToAPIModerationItem(item, meta),
+	}, nil
+}
+
+// convertToAPIModerationItem 转换审核记录，视频已被删除时meta为nil
+func convertToAPIModerationItem(item *moderation.ReviewItem, meta *metadata.FileMetadata) *api.ModerationItem {
+	apiItem := &api.ModerationItem{
+		VideoID:   item.VideoID,
+		Status:    item.Status,
+		Verdict:   item.Verdict,
+		Reasons:   item.Reasons,
+		CreatedAt: item.CreatedAt.UnixMilli(),
+	}
+	if apiItem.Reasons == nil {
+		apiItem.Reasons = []string{}
+	}
+	if meta != nil {
+		apiItem.Video = convertToAPIVideo(meta)
+	}
+	if item.ReviewedBy != "" {
+		reviewedAt := item.ReviewedAt.UnixMilli()
+		apiItem.ReviewedBy = &item.ReviewedBy
+		apiItem.ReviewedAt = &reviewedAt
+	}
+	if item.Note != "" {
+		apiItem.Note = &item.Note
+	}
+	return apiItem
+}
+
+// moderationListErrorResponse 创建审核队列查询错误响应
+func (s *VideoService) moderationListErrorResponse(code int32, message string) *api.ModerationListResponse {
+	return &api.ModerationListResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+		Items: []*api.ModerationItem{},
+	}
+}
+
+// moderationReviewErrorResponse 创建审核错误响应
+func (s *VideoService) moderationReviewErrorResponse(code int32, message string) *api.ModerationReviewResponse {
+	return &api.ModerationReviewResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/manteia/zhulong/pkg/storage"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/delete"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/moderation"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// titleModerationHook 按视频标题给出审核结论的测试钩子
+type titleModerationHook struct {
+	verdicts map[string]string
+	subjects []*moderation.Subject
+}
+
+func (h *titleModerationHook) Name() string {
+	return "title"
+}
+
+func (h *titleModerationHook) Check(ctx context.Context, subject *moderation.Subject) (*moderation.Result, error) {
+	h.subjects = append(h.subjects, subject)
+	return &moderation.Result{Verdict: h.verdicts[subject.Title], Reason: "标题命中规则"}, nil
+}
+
+// createModerationTestService 创建启用内容审核的测试服务
+func createModerationTestService(t *testing.T) (*VideoService, *storage.MemoryStorage, *titleModerationHook) {
+	service, store := createDirectUploadTestService(t)
+	hook := &titleModerationHook{verdicts: map[string]string{
+		"可疑视频": moderation.VerdictFlagged,
+		"违规视频": moderation.VerdictBlocked,
+	}}
+	pipeline, err := moderation.NewPipeline([]moderation.Hook{hook}, moderation.OnErrorFlag)
+	require.NoError(t, err)
+	service.moderation = pipeline
+	return service, store, hook
+}
+
+// uploadModerationTestVideo 以指定用户上传指定标题的测试视频
+func uploadModerationTestVideo(t *testing.T, service *VideoService, ctx context.Context, title string) *api.Video {
+	resp, err := service.UploadVideo(ctx, &api.VideoUploadRequest{Title: title, Visibility: metadata.VisibilityPublic}, createTestFileHeader(t, "moderation.mp4", "video/mp4", mp4TestData(1024)))
+	require.NoError(t, err)
+	require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+	return resp.Video
+}
+
+func TestVideoService_Moderation(t *testing.T) {
+	uploader := claimsContext("uploader-1", user.RoleUploader)
+	admin := claimsContext("admin-1", user.RoleAdmin)
+	anonymous := context.Background()
+
+	t.Run("上传后执行审核钩子", func(t *testing.T) {
+		service, _, hook := createModerationTestService(t)
+
+		approved := uploadModerationTestVideo(t, service, uploader, "正常视频")
+		flagged := uploadModerationTestVideo(t, service, uploader, "可疑视频")
+		blocked := uploadModerationTestVideo(t, service, uploader, "违规视频")
+
+		require.Len(t, hook.subjects, 3)
+		assert.Equal(t, approved.ID, hook.subjects[0].VideoID)
+		assert.Equal(t, "uploader-1", hook.subjects[0].CreatedBy)
+		assert.NotEmpty(t, hook.subjects[0].URL, "钩子应该收到视频的下载地址")
+
+		assert.Empty(t, approved.ModerationStatus)
+		assert.Equal(t, moderation.StatusPending, flagged.ModerationStatus)
+		assert.Equal(t, moderation.StatusRejected, blocked.ModerationStatus)
+
+		// 被标记和拦截的视频只有上传者和管理员可见
+		assert.ElementsMatch(t, []string{approved.ID}, listedVideoIDs(t, service, anonymous))
+		assert.ElementsMatch(t, []string{approved.ID, flagged.ID, blocked.ID}, listedVideoIDs(t, service, uploader))
+		detail, err := service.GetVideoDetail(anonymous, &api.VideoDetailRequest{VideoID: flagged.ID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2102), detail.Base.Code)
+	})
+
+	t.Run("审核队列", func(t *testing.T) {
+		service, _, _ := createModerationTestService(t)
+		uploadModerationTestVideo(t, service, uploader, "正常视频")
+		flagged := uploadModerationTestVideo(t, service, uploader, "可疑视频")
+		blocked := uploadModerationTestVideo(t, service, uploader, "违规视频")
+
+		resp, err := service.ListModerationQueue(admin, &api.ModerationListRequest{})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		require.Len(t, resp.Items, 1, "默认只列出待审核的视频")
+		assert.Equal(t, flagged.ID, resp.Items[0].VideoID)
+		assert.Equal(t, moderation.VerdictFlagged, resp.Items[0].Verdict)
+		assert.Equal(t, []string{"title: 标题命中规则"}, resp.Items[0].Reasons)
+		require.NotNil(t, resp.Items[0].Video)
+		assert.Equal(t, "可疑视频", resp.Items[0].Video.Title)
+
+		all := "all"
+		resp, err = service.ListModerationQueue(admin, &api.ModerationListRequest{Status: &all})
+		require.NoError(t, err)
+		assert.Len(t, resp.Items, 2)
+
+		rejected := "rejected"
+		resp, err = service.ListModerationQueue(admin, &api.ModerationListRequest{Status: &rejected})
+		require.NoError(t, err)
+		require.Len(t, resp.Items, 1)
+		assert.Equal(t, blocked.ID, resp.Items[0].VideoID)
+
+		invalid := "flagged"
+		resp, err = service.ListModerationQueue(admin, &api.ModerationListRequest{Status: &invalid})
+		require.NoError(t, err)
+		assert.Equal(t, int32(9501), resp.Base.Code)
+	})
+
+	t.Run("管理员审核", func(t *testing.T) {
+		service, _, _ := createModerationTestService(t)
+		flagged := uploadModerationTestVideo(t, service, uploader, "可疑视频")
+		note := "误报"
+
+		resp, err := service.ReviewModeration(admin, &api.ModerationReviewRequest{VideoID: flagged.ID, Action: "approve", Note: &note})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, moderation.StatusApproved, resp.Item.Status)
+		assert.Equal(t, "admin-1", resp.Item.GetReviewedBy())
+		assert.Equal(t, "误报", resp.Item.GetNote())
+		assert.Equal(t, metadata.ModerationApproved, resp.Item.Video.ModerationStatus)
+		assert.Contains(t, listedVideoIDs(t, service, anonymous), flagged.ID, "审核通过后按可见性正常发布")
+
+		resp, err = service.ReviewModeration(admin, &api.ModerationReviewRequest{VideoID: flagged.ID, Action: "reject"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, moderation.StatusRejected, resp.Item.Status)
+		assert.NotContains(t, listedVideoIDs(t, service, anonymous), flagged.ID)
+	})
+
+	t.Run("审核参数错误", func(t *testing.T) {
+		service, _, _ := createModerationTestService(t)
+		flagged := uploadModerationTestVideo(t, service, uploader, "可疑视频")
+		approved := uploadModerationTestVideo(t, service, uploader, "正常视频")
+
+		resp, err := service.ReviewModeration(admin, &api.ModerationReviewRequest{VideoID: flagged.ID, Action: "delete"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(9501), resp.Base.Code)
+
+		resp, err = service.ReviewModeration(admin, &api.ModerationReviewRequest{VideoID: approved.ID, Action: "approve"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(9502), resp.Base.Code, "未被标记的视频不在审核队列中")
+	})
+
+	t.Run("删除视频时移出审核队列", func(t *testing.T) {
+		service, store, _ := createModerationTestService(t)
+		service.deleteService = delete.NewDeleteService(store)
+		flagged := uploadModerationTestVideo(t, service, uploader, "可疑视频")
+
+		deleteResp, err := service.DeleteVideo(uploader, &api.VideoDeleteRequest{VideoID: flagged.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), deleteResp.Base.Code, deleteResp.Base.Message)
+
+		_, ok := service.reviews.Get(flagged.ID)
+		assert.False(t, ok)
+	})
+
+	t.Run("未配置审核钩子", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+
+		video := uploadModerationTestVideo(t, service, uploader, "违规视频")
+		assert.Empty(t, video.ModerationStatus)
+		assert.Empty(t, service.reviews.List(""))
+	})
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/notify"
+	"github.com/manteia/zhulong/pkg/webhook"
+)
+
+// webhookEvents 通知事件与Webhook事件的对应关系，未列出的事件不投递Webhook
+var webhookEvents = map[string]string{
+	notify.EventUploadCompleted:   webhook.EventVideoUploaded,
+	notify.EventTranscodeFinished: webhook.EventVideoTranscoded,
+	notify.EventVideoDeleted:      webhook.EventVideoDeleted,
+	notify.EventThumbnailReady:    webhook.EventThumbnailReady,
+}
+
+// SubscribeNotifications 订阅视频处理事件通知，只推送当前用户可以查看的视频的事件
+func (s *VideoService) SubscribeNotifications(ctx context.Context) *notify.Subscription {
+	return s.notifier.Subscribe(currentViewer(ctx))
+}
+
+// publishEvent 广播视频处理事件并投递对应的Webhook，未配置通知中心或Webhook时忽略
+// 事件按发布时的视频元数据判断订阅者是否可以接收
+func (s *VideoService) publishEvent(eventType string, meta *metadata.FileMetadata, data interface{}) {
+	if s.notifier != nil {
+		s.notifier.Publish(notify.NewVideoEvent(eventType, meta, data))
+	}
+	if event, ok := webhookEvents[eventType]; ok && s.webhooks != nil {
+		s.webhooks.Dispatch(event, meta.FileID, data)
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/delete"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/notify"
+	"github.com/manteia/zhulong/pkg/user"
+	"github.com/manteia/zhulong/pkg/webhook"
+)
+
+// receiveNotification 读取一条通知事件
+func receiveNotification(t *testing.T, sub *notify.Subscription) *notify.Event {
+	select {
+	case event, ok := <-sub.C:
+		require.True(t, ok, "订阅通道不应该已关闭")
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("等待通知事件超时")
+		return nil
+	}
+}
+
+func TestVideoService_Notifications(t *testing.T) {
+	ctx := context.Background()
+	service, store := createDirectUploadTestService(t)
+	service.deleteService = delete.NewDeleteService(store)
+
+	sub := service.SubscribeNotifications(claimsContext("admin-1", user.RoleAdmin))
+	defer sub.Close()
+
+	t.Run("通知_上传完成", func(t *testing.T) {
+		fileHeader := createTestFileHeader(t, "notify.mp4", "video/mp4", mp4TestData(2048))
+		resp, err := service.UploadVideo(ctx, &api.VideoUploadRequest{}, fileHeader)
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+		event := receiveNotification(t, sub)
+		assert.Equal(t, notify.EventUploadCompleted, event.Type)
+		assert.Equal(t, resp.Video.ID, event.VideoID)
+		assert.Equal(t, resp.Video, event.Data, "上传完成事件应该携带视频信息")
+
+		meta, err := service.metadataService.GetMetadata(ctx, resp.Video.ID)
+		require.NoError(t, err)
+		if meta.Thumbnail != "" {
+			event = receiveNotification(t, sub)
+			assert.Equal(t, notify.EventThumbnailReady, event.Type, "生成缩略图后应该推送缩略图事件")
+		}
+
+		// 删除视频
+		deleteResp, err := service.DeleteVideo(ctx, &api.VideoDeleteRequest{VideoID: resp.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), deleteResp.Base.Code, deleteResp.Base.Message)
+
+		event = receiveNotification(t, sub)
+		assert.Equal(t, notify.EventVideoDeleted, event.Type)
+		assert.Equal(t, resp.Video.ID, event.VideoID)
+	})
+
+	t.Run("通知_业务失败不推送", func(t *testing.T) {
+		fileHeader := createTestFileHeader(t, "notes.txt", "text/plain", []byte("not a video"))
+		resp, err := service.UploadVideo(ctx, &api.VideoUploadRequest{}, fileHeader)
+		require.NoError(t, err)
+		assert.NotEqual(t, int32(0), resp.Base.Code)
+
+		select {
+		case event := <-sub.C:
+			t.Fatalf("上传失败不应该推送事件: %s", event.Type)
+		default:
+		}
+	})
+}
+
+func TestVideoService_NotificationVisibility(t *testing.T) {
+	service, _ := createDirectUploadTestService(t)
+	uploader := claimsContext("uploader-1", user.RoleUploader)
+
+	anonymous := service.SubscribeNotifications(context.Background())
+	defer anonymous.Close()
+	other := service.SubscribeNotifications(claimsContext("viewer-1", user.RoleViewer))
+	defer other.Close()
+	owner := service.SubscribeNotifications(uploader)
+	defer owner.Close()
+
+	fileHeader := createTestFileHeader(t, "private.mp4", "video/mp4", mp4TestData(2048))
+	resp, err := service.UploadVideo(uploader, &api.VideoUploadRequest{Visibility: metadata.VisibilityPrivate}, fileHeader)
+	require.NoError(t, err)
+	require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+	event := receiveNotification(t, owner)
+	assert.Equal(t, notify.EventUploadCompleted, event.Type, "上传者应该收到私有视频的事件")
+	assert.Equal(t, resp.Video.ID, event.VideoID)
+	for name, sub := range map[string]*notify.Subscription{"未登录": anonymous, "其他用户": other} {
+		select {
+		case event := <-sub.C:
+			t.Fatalf("%s的订阅者不应该收到私有视频的事件: %s", name, event.Type)
+		default:
+		}
+	}
+
+	fileHeader = createTestFileHeader(t, "public.mp4", "video/mp4", mp4TestData(2048))
+	resp, err = service.UploadVideo(uploader, &api.VideoUploadRequest{Visibility: metadata.VisibilityPublic}, fileHeader)
+	require.NoError(t, err)
+	require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+	event = receiveNotification(t, anonymous)
+	assert.Equal(t, resp.Video.ID, event.VideoID, "公开视频的事件推送给所有订阅者")
+}
+
+func TestVideoService_Webhooks(t *testing.T) {
+	ctx := context.Background()
+	payloads := make(chan *webhook.Payload, 16)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhook.Payload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err == nil {
+			payloads <- &payload
+		}
+	}))
+	defer server.Close()
+
+	service, store := createDirectUploadTestService(t)
+	service.deleteService = delete.NewDeleteService(store)
+	dispatcher, err := webhook.NewDispatcher([]*webhook.Endpoint{{
+		URL:    server.URL,
+		Events: []string{webhook.EventVideoUploaded, webhook.EventVideoDeleted},
+	}})
+	require.NoError(t, err)
+	defer dispatcher.Close()
+	service.webhooks = dispatcher
+
+	receivePayload := func() *webhook.Payload {
+		select {
+		case payload := <-payloads:
+			return payload
+		case <-time.After(2 * time.Second):
+			t.Fatal("等待Webhook请求超时")
+			return nil
+		}
+	}
+
+	uploaded := uploadTestVideo(t, service, "webhook.mp4", mp4TestData(2048))
+	require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+
+	payload := receivePayload()
+	assert.Equal(t, webhook.EventVideoUploaded, payload.Event, "上传完成应该投递video.uploaded")
+	assert.Equal(t, uploaded.Video.ID, payload.VideoID)
+
+	deleteResp, err := service.DeleteVideo(ctx, &api.VideoDeleteRequest{VideoID: uploaded.Video.ID})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), deleteResp.Base.Code, deleteResp.Base.Message)
+
+	payload = receivePayload()
+	assert.Equal(t, webhook.EventVideoDeleted, payload.Event, "未订阅的缩略图事件不应该投递")
+	assert.Equal(t, uploaded.Video.ID, payload.VideoID)
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/streaming"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+const (
+	// subtitleRootPrefix 字幕文件在存储桶中的根前缀，字幕文件保存为subtitles/<视频ID>/<语言>.<格式>
+	subtitleRootPrefix = "subtitles"
+	// videoAPIPrefix 视频接口路径前缀，用于返回需要经过服务改写的播放列表和轨道地址
+	videoAPIPrefix = "/api/v1/videos"
+)
+
+// subtitleFormats 支持的字幕格式
+var subtitleFormats = []string{"vtt", "srt"}
+
+// subtitlePrefix 视频字幕文件的存储前缀
+func subtitlePrefix(videoID string) string {
+	return path.Join(subtitleRootPrefix, videoID) + "/"
+}
+
+// fillPlaybackAssets 填充播放器需要的HLS、DASH、缩略图、预览图和字幕地址
+// 存储中的文件返回预签名URL；HLS播放列表、DASH清单和WebVTT轨道中的相对地址需要由服务改写，返回接口路径
+func (s *VideoService) fillPlaybackAssets(ctx context.Context, resp *api.VideoPlayURLResponse, meta *metadata.FileMetadata, expiry time.Duration) error {
+	thumbnailBucket := s.buckets.Bucket(storage.ContentThumbnails)
+
+	if s.hlsPackager != nil {
+		renditionBucket := s.buckets.Bucket(storage.ContentRenditions)
+		packaged, err := s.hlsPackager.IsPackaged(ctx, renditionBucket, meta.FileID)
+		if err != nil {
+			return fmt.Errorf("检查HLS打包状态失败: %w", err)
+		}
+		if packaged {
+			hlsURL := fmt.Sprintf("%s/%s/hls/%s", videoAPIPrefix, meta.FileID, streaming.MasterPlaylistName)
+			resp.HlsURL = &hlsURL
+
+			hasManifest, err := s.hlsPackager.IsManifestPackaged(ctx, renditionBucket, meta.FileID)
+			if err != nil {
+				return fmt.Errorf("检查DASH清单状态失败: %w", err)
+			}
+			if hasManifest {
+				dashURL := fmt.Sprintf("%s/%s/%s", videoAPIPrefix, meta.FileID, streaming.ManifestName)
+				resp.DashURL = &dashURL
+			}
+		}
+	}
+
+	if meta.Thumbnail != "" {
+		thumbnailURL, err := s.presignedURL(ctx, thumbnailBucket, meta.Thumbnail, expiry)
+		if err != nil {
+			return fmt.Errorf("生成缩略图URL失败: %w", err)
+		}
+		resp.ThumbnailURL = &thumbnailURL
+	}
+	if meta.Preview != "" {
+		previewURL, err := s.presignedURL(ctx, thumbnailBucket, meta.Preview, expiry)
+		if err != nil {
+			return fmt.Errorf("生成动态预览URL失败: %w", err)
+		}
+		resp.PreviewURL = &previewURL
+	}
+
+	prefix := spritePrefix(meta.FileID)
+	exists, err := s.storageClient.FileExists(ctx, thumbnailBucket, prefix+spriteVTTName)
+	if err != nil {
+		return fmt.Errorf("检查预览图失败: %w", err)
+	}
+	if exists {
+		spriteURL, err := s.presignedURL(ctx, thumbnailBucket, prefix+video.SpriteImageName, expiry)
+		if err != nil {
+			return fmt.Errorf("生成雪碧图URL失败: %w", err)
+		}
+		trackURL := fmt.Sprintf("%s/%s/thumbnails.vtt", videoAPIPrefix, meta.FileID)
+		resp.SpriteURL = &spriteURL
+		resp.ThumbnailTrackURL = &trackURL
+	}
+
+	subtitles, err := s.subtitleTracks(ctx, meta.FileID, expiry)
+	if err != nil {
+		return err
+	}
+	resp.Subtitles = subtitles
+	return nil
+}
+
+// subtitleFile 存储中的字幕文件
+type subtitleFile struct {
+	Key          string    // 对象名
+	Language     string    // 语言（文件名，不含扩展名）
+	Format       string    // 字幕格式（vtt或srt）
+	Size         int64     // 文件大小
+	LastModified time.Time // 最后修改时间
+}
+
+// listSubtitleFiles 列出视频的字幕文件，按语言和格式排序，不支持的格式被忽略
+func (s *VideoService) listSubtitleFiles(ctx context.Context, videoID string) ([]subtitleFile, error) {
+	bucketName := s.buckets.Bucket(storage.ContentSubtitles)
+	exists, err := s.storageClient.BucketExists(ctx, bucketName)
+	if err != nil {
+		return nil, fmt.Errorf("检查字幕存储桶失败: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	files, err := s.storageClient.ListFiles(ctx, bucketName, subtitlePrefix(videoID))
+	if err != nil {
+		return nil, fmt.Errorf("列出字幕文件失败: %w", err)
+	}
+
+	subtitles := make([]subtitleFile, 0, len(files))
+	for _, file := range files {
+		name := path.Base(file.Key)
+		format := strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))
+		language := strings.TrimSuffix(name, path.Ext(name))
+		if language == "" || !slices.Contains(subtitleFormats, format) {
+			continue
+		}
+		subtitles = append(subtitles, subtitleFile{
+			Key:          file.Key,
+			Language:     language,
+			Format:       format,
+			Size:         file.Size,
+			LastModified: file.LastModified,
+		})
+	}
+
+	slices.SortFunc(subtitles, func(a, b subtitleFile) int {
+		if c := strings.Compare(a.Language, b.Language); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Format, b.Format)
+	})
+	return subtitles, nil
+}
+
+// subtitleTracks 列出视频的字幕文件并生成预签名URL，按语言排序，不支持的格式被忽略
+func (s *VideoService) subtitleTracks(ctx context.Context, videoID string, expiry time.Duration) ([]*api.SubtitleTrack, error) {
+	files, err := s.listSubtitleFiles(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	bucketName := s.buckets.Bucket(storage.ContentSubtitles)
+	tracks := make([]*api.SubtitleTrack, 0, len(files))
+	for _, file := range files {
+		url, err := s.presignedURL(ctx, bucketName, file.Key, expiry)
+		if err != nil {
+			return nil, fmt.Errorf("生成字幕URL失败: %w", err)
+		}
+		tracks = append(tracks, &api.SubtitleTrack{Language: file.Language, Format: file.Format, URL: url})
+	}
+	return tracks, nil
+}
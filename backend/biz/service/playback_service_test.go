@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/manteia/zhulong/pkg/storage"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/delete"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+func TestVideoService_PlaybackAssets(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("返回缩略图、预览图和字幕地址", func(t *testing.T) {
+		service := createStreamTestService(t)
+		store := service.storageClient.(*storage.MemoryStorage)
+		thumbnail := "thumbnails/2025/08/video1.jpg"
+		preview := "previews/2025/08/video1.gif"
+		require.NoError(t, service.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{
+			FileID:    "video1",
+			Thumbnail: &thumbnail,
+			Preview:   &preview,
+		}))
+		putTestObject(t, store, spritePrefix("video1")+video.SpriteImageName, []byte("jpg"))
+		putTestObject(t, store, spritePrefix("video1")+spriteVTTName, []byte("WEBVTT"))
+		putTestObject(t, store, "subtitles/video1/zh-CN.srt", []byte("1"))
+		putTestObject(t, store, "subtitles/video1/en.vtt", []byte("WEBVTT"))
+		putTestObject(t, store, "subtitles/video1/notes.txt", []byte("notes"))
+		putTestObject(t, store, "subtitles/video10/fr.vtt", []byte("WEBVTT"))
+
+		resp, err := service.GetVideoPlayURL(ctx, &api.VideoPlayURLRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Nil(t, resp.HlsURL, "未启用HLS时不返回播放列表地址")
+		require.NotNil(t, resp.ThumbnailURL)
+		assert.Contains(t, *resp.ThumbnailURL, thumbnail)
+		require.NotNil(t, resp.PreviewURL)
+		assert.Contains(t, *resp.PreviewURL, preview)
+		require.NotNil(t, resp.SpriteURL)
+		assert.Contains(t, *resp.SpriteURL, spritePrefix("video1")+video.SpriteImageName)
+		require.NotNil(t, resp.ThumbnailTrackURL)
+		assert.Equal(t, "/api/v1/videos/video1/thumbnails.vtt", *resp.ThumbnailTrackURL)
+
+		require.Len(t, resp.Subtitles, 2, "不支持的格式和其他视频的字幕不应该返回")
+		assert.Equal(t, "en", resp.Subtitles[0].Language)
+		assert.Equal(t, "vtt", resp.Subtitles[0].Format)
+		assert.Contains(t, resp.Subtitles[0].URL, "subtitles/video1/en.vtt")
+		assert.Equal(t, "zh-CN", resp.Subtitles[1].Language)
+		assert.Equal(t, "srt", resp.Subtitles[1].Format)
+	})
+
+	t.Run("没有附属文件", func(t *testing.T) {
+		service := createStreamTestService(t)
+
+		resp, err := service.GetVideoPlayURL(ctx, &api.VideoPlayURLRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.NotEmpty(t, resp.PlayURL)
+		assert.Nil(t, resp.ThumbnailURL)
+		assert.Nil(t, resp.PreviewURL)
+		assert.Nil(t, resp.SpriteURL, "预览图未生成时不返回雪碧图地址")
+		assert.Nil(t, resp.ThumbnailTrackURL)
+		assert.Empty(t, resp.Subtitles)
+	})
+
+	t.Run("删除视频时删除字幕文件", func(t *testing.T) {
+		service := createStreamTestService(t)
+		store := service.storageClient.(*storage.MemoryStorage)
+		service.deleteService = delete.NewDeleteService(store)
+		putTestObject(t, store, "subtitles/video1/en.vtt", []byte("WEBVTT"))
+
+		resp, err := service.DeleteVideo(ctx, &api.VideoDeleteRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.NotContains(t, testObjectNames(t, store, ""), "subtitles/video1/en.vtt")
+	})
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/playback"
+)
+
+// signedStreamPath 使用播放令牌访问视频流的路径前缀
+const signedStreamPath = "/stream"
+
+// newPlaybackSigner 创建播放令牌签发器，未配置密钥时使用随机密钥
+func newPlaybackSigner(cfg *config.Config) (*playback.Signer, error) {
+	secret := cfg.GetPlaybackSecret()
+	if secret == "" {
+		var err error
+		secret, err = randomSecret()
+		if err != nil {
+			return nil, fmt.Errorf("生成播放令牌密钥失败: %v", err)
+		}
+		if cfg.Playback.SignedURLs {
+			// 重启后已签发的播放URL将失效
+			fmt.Println("警告: 未配置播放令牌密钥，已使用随机密钥")
+		}
+	}
+	return playback.NewSigner(secret)
+}
+
+// playURL 生成视频的播放URL，启用播放令牌时返回服务代理的视频流地址，不暴露存储地址
+func (s *VideoService) playURL(ctx context.Context, meta *metadata.FileMetadata, expiry time.Duration) (string, error) {
+	var playURL string
+	var err error
+	if s.config.Playback.SignedURLs {
+		playURL, err = s.signedPlayURL(ctx, meta.FileID, expiry)
+	} else {
+		playURL, err = s.presignedURL(ctx, meta.BucketName, meta.ObjectName, expiry)
+	}
+	if err != nil {
+		return "", fmt.Errorf("生成播放URL失败: %w", err)
+	}
+	return playURL, nil
+}
+
+// signedPlayURL 为当前用户签发视频的播放令牌URL
+func (s *VideoService) signedPlayURL(ctx context.Context, videoID string, expiry time.Duration) (string, error) {
+	return s.signedStreamURL(currentUserID(ctx), videoID, expiry)
+}
+
+// signedStreamURL 为指定用户签发视频的播放令牌URL，按用户撤销令牌时一并失效
+func (s *VideoService) signedStreamURL(userID, videoID string, expiry time.Duration) (string, error) {
+	token, _, err := s.playbackSigner.Sign(userID, videoID, expiry)
+	if err != nil {
+		return "", fmt.Errorf("签发播放令牌失败: %w", err)
+	}
+
+	return fmt.Sprintf("%s%s/%s?token=%s", s.playbackBaseURL(), signedStreamPath, url.PathEscape(videoID), url.QueryEscape(token)), nil
+}
+
+// playbackBaseURL 播放和分享地址的服务地址前缀，未配置时为空，返回相对路径
+func (s *VideoService) playbackBaseURL() string {
+	return strings.TrimRight(s.config.Playback.BaseURL, "/")
+}
+
+// StreamSignedVideo 校验播放令牌后打开视频流，令牌必须是为该视频签发且未过期、未撤销的
+func (s *VideoService) StreamSignedVideo(ctx context.Context, videoID, token, rangeHeader string) (*VideoStream, error) {
+	if token == "" {
+		return s.streamErrorResponse(6401, "播放令牌不能为空"), nil
+	}
+	if _, err := s.playbackSigner.Verify(token, videoID); err != nil {
+		return s.streamErrorResponse(6401, err.Error()), nil
+	}
+
+	// 令牌签发时已经检查过可见性，持有有效令牌即可播放；签发后被审核限制或按delete处理到期的视频按不存在处理
+	meta, err := s.metadataService.GetMetadata(ctx, videoID)
+	if err != nil || meta.Blocked(time.Now()) {
+		return s.streamErrorResponse(6102, "视频不存在"), nil
+	}
+	return s.openVideoStream(ctx, meta, rangeHeader)
+}
+
+// RevokePlaybackTokens 按用户或视频撤销此前签发的全部播放令牌
+func (s *VideoService) RevokePlaybackTokens(ctx context.Context, req *api.PlaybackRevokeRequest) (*api.PlaybackRevokeResponse, error) {
+	userID := strings.TrimSpace(getValueOrDefault(req.UserID, ""))
+	videoID := strings.TrimSpace(getValueOrDefault(req.VideoID, ""))
+	if userID == "" && videoID == "" {
+		return s.playbackRevokeErrorResponse(6402, "用户ID和视频ID至少需要指定一个"), nil
+	}
+
+	var revokedAt time.Time
+	if userID != "" {
+		revokedAt = s.playbackSigner.RevokeUser(userID)
+	}
+	if videoID != "" {
+		revokedAt = s.playbackSigner.RevokeVideo(videoID)
+	}
+
+	return &api.PlaybackRevokeResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "撤销成功",
+		},
+		RevokedAt: revokedAt.UnixMilli(),
+	}, nil
+}
+
+// playbackRevokeErrorResponse 创建播放令牌撤销错误响应
+func (s *VideoService) playbackRevokeErrorResponse(code int32, message string) *api.PlaybackRevokeResponse {
+	return &api.PlaybackRevokeResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
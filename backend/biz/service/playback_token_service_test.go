@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/playback"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// createSignedPlaybackTestService 创建启用播放令牌的视频服务
+func createSignedPlaybackTestService(t *testing.T) *VideoService {
+	service := createStreamTestService(t)
+	signer, err := playback.NewSigner("test-secret")
+	require.NoError(t, err)
+	service.playbackSigner = signer
+	service.config.Playback.SignedURLs = true
+	service.config.Playback.BaseURL = "http://nas.lan:8888/"
+	return service
+}
+
+// signedPlayToken 获取播放URL并解析其中的播放令牌
+func signedPlayToken(t *testing.T, service *VideoService, ctx context.Context) string {
+	resp, err := service.GetVideoPlayURL(ctx, &api.VideoPlayURLRequest{VideoID: "video1"})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+	playURL, err := url.Parse(resp.PlayURL)
+	require.NoError(t, err)
+	assert.Equal(t, "nas.lan:8888", playURL.Host)
+	assert.Equal(t, "/stream/video1", playURL.Path)
+	token := playURL.Query().Get("token")
+	require.NotEmpty(t, token)
+	return token
+}
+
+func TestVideoService_SignedPlayback(t *testing.T) {
+	ctx := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "alice"})
+
+	t.Run("播放URL使用播放令牌", func(t *testing.T) {
+		service := createSignedPlaybackTestService(t)
+		token := signedPlayToken(t, service, ctx)
+
+		claims, err := service.playbackSigner.Verify(token, "video1")
+		require.NoError(t, err)
+		assert.Equal(t, "alice", claims.UserID, "令牌应该绑定签发时的用户")
+
+		stream, err := service.StreamSignedVideo(context.Background(), "video1", token, "bytes=2-5")
+		require.NoError(t, err)
+		require.Equal(t, int32(0), stream.Base.Code, stream.Base.Message)
+		assert.Equal(t, "2345", readStream(t, stream))
+	})
+
+	t.Run("令牌无效", func(t *testing.T) {
+		service := createSignedPlaybackTestService(t)
+		token := signedPlayToken(t, service, ctx)
+
+		for _, tt := range []struct {
+			videoID string
+			token   string
+		}{
+			{"video1", ""},
+			{"video1", "invalid"},
+			{"video2", token},
+			{"video1", strings.TrimSuffix(token, token[len(token)-2:])},
+		} {
+			stream, err := service.StreamSignedVideo(context.Background(), tt.videoID, tt.token, "")
+			require.NoError(t, err)
+			assert.Equal(t, int32(6401), stream.Base.Code, "视频%s的令牌%q应该被拒绝", tt.videoID, tt.token)
+		}
+	})
+
+	t.Run("撤销播放令牌", func(t *testing.T) {
+		service := createSignedPlaybackTestService(t)
+		token := signedPlayToken(t, service, ctx)
+
+		resp, err := service.RevokePlaybackTokens(ctx, &api.PlaybackRevokeRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6402), resp.Base.Code, "未指定用户和视频时应该返回参数错误")
+
+		userID := "alice"
+		resp, err = service.RevokePlaybackTokens(ctx, &api.PlaybackRevokeRequest{UserID: &userID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Greater(t, resp.RevokedAt, int64(0))
+
+		stream, err := service.StreamSignedVideo(context.Background(), "video1", token, "")
+		require.NoError(t, err)
+		assert.Equal(t, int32(6401), stream.Base.Code, "撤销前签发的令牌应该失效")
+
+		time.Sleep(2 * time.Millisecond)
+		token = signedPlayToken(t, service, ctx)
+		stream, err = service.StreamSignedVideo(context.Background(), "video1", token, "")
+		require.NoError(t, err)
+		require.Equal(t, int32(0), stream.Base.Code, "撤销后重新签发的令牌应该可用")
+		readStream(t, stream)
+	})
+
+	t.Run("签发后被限制的视频不能播放", func(t *testing.T) {
+		expired := time.Now().Add(-time.Minute)
+		for name, req := range map[string]*metadata.UpdateMetadataRequest{
+			"审核拒绝":      {FileID: "video1", Moderation: stringPtr(metadata.ModerationRejected)},
+			"按delete到期": {FileID: "video1", ExpiresAt: &expired, ExpiryAction: stringPtr(metadata.ExpiryDelete)},
+		} {
+			service := createSignedPlaybackTestService(t)
+			token := signedPlayToken(t, service, ctx)
+			require.NoError(t, service.metadataService.UpdateMetadata(context.Background(), req))
+
+			stream, err := service.StreamSignedVideo(context.Background(), "video1", token, "")
+			require.NoError(t, err)
+			assert.Equal(t, int32(6102), stream.Base.Code, "%s的视频不能使用此前签发的令牌播放", name)
+
+			poster, err := service.GetSignedPoster(context.Background(), "video1", token)
+			require.NoError(t, err)
+			assert.Equal(t, int32(7102), poster.Base.Code, "%s的视频不能使用此前签发的令牌投屏", name)
+		}
+	})
+
+	t.Run("未启用时返回存储的预签名URL", func(t *testing.T) {
+		service := createSignedPlaybackTestService(t)
+		service.config.Playback.SignedURLs = false
+
+		resp, err := service.GetVideoPlayURL(ctx, &api.VideoPlayURLRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		assert.Contains(t, resp.PlayURL, "videos/2025/08/video1.mp4")
+	})
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// presignedURL 生成对象的预签名下载URL，启用缓存时复用近期签发且剩余有效期足够的URL
+func (s *VideoService) presignedURL(ctx context.Context, bucketName, objectName string, expiry time.Duration) (string, error) {
+	return s.urlCache.Get(ctx, bucketName, objectName, expiry, func(expiry time.Duration) (string, error) {
+		return s.storageClient.GetPresignedURL(ctx, bucketName, objectName, expiry)
+	})
+}
+
+// invalidatePresignedURLs 对象被删除或移出存储桶后删除其预签名URL缓存
+func (s *VideoService) invalidatePresignedURLs(ctx context.Context, bucketName string, objectNames ...string) {
+	s.urlCache.Invalidate(ctx, bucketName, objectNames...)
+}
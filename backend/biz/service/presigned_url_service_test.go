@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/cache"
+)
+
+func TestVideoService_PresignedURLCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("删除视频后清除缓存的URL", func(t *testing.T) {
+		service, _ := createDeleteTestService(t)
+		memory := cache.NewMemoryCache(100)
+		service.urlCache = cache.NewURLCache(memory)
+
+		url, err := service.presignedURL(ctx, "zhulong-videos", "thumbnails/video1.jpg", time.Hour)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(url, "http://storage.local/zhulong-videos/thumbnails/video1.jpg?"), url)
+		_, err = service.presignedURL(ctx, "zhulong-videos", "videos/2025/08/video1.mp4", time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, 2, memory.Len())
+
+		resp, err := service.DeleteVideo(ctx, &api.VideoDeleteRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, 0, memory.Len(), "视频文件和缩略图的URL缓存应该被删除")
+	})
+
+	t.Run("未启用缓存", func(t *testing.T) {
+		service, _ := createDeleteTestService(t)
+
+		url, err := service.presignedURL(ctx, "zhulong-videos", "thumbnails/video1.jpg", time.Hour)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(url, "http://storage.local/zhulong-videos/thumbnails/video1.jpg?"), url)
+		service.invalidatePresignedURLs(ctx, "zhulong-videos", "thumbnails/video1.jpg")
+	})
+}
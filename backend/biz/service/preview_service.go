@@ -0,0 +1,170 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/notify"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/transcode"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+// previewGenerateTimeout 单个视频生成预览图超时时间
+const previewGenerateTimeout = 30 * time.Minute
+
+// schedulePreviewGeneration 异步生成进度条预览图和动态预览，帧提取器不可用或正在生成时跳过
+// 视频已归档时先恢复，生成期间不会被再次归档；远程worker模式下加入转码队列由worker生成；图片和音频不生成预览
+func (s *VideoService) schedulePreviewGeneration(meta *metadata.FileMetadata) {
+	if !meta.IsVideo() || !s.previewGenerationAvailable() {
+		return
+	}
+	if s.config.IsRemoteWorkerMode() {
+		if _, err := s.transcodeQueue.Enqueue(transcode.KindPreview, meta.FileID, transcode.DefaultPriority); err != nil && !errors.Is(err, transcode.ErrJobActive) {
+			fmt.Printf("创建预览图任务失败(%s): %v\n", meta.FileID, err)
+		}
+		return
+	}
+	if _, running := s.previewJobs.LoadOrStore(meta.FileID, struct{}{}); running {
+		return
+	}
+
+	go func() {
+		defer s.previewJobs.Delete(meta.FileID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), previewGenerateTimeout)
+		defer cancel()
+
+		if err := s.generatePreviews(ctx, meta); err != nil {
+			fmt.Printf("生成预览图失败(%s): %v\n", meta.FileID, err)
+		}
+	}()
+}
+
+// generateQueuedPreviews 执行管理员创建的预览图任务，视频正在生成预览图时直接返回
+func (s *VideoService) generateQueuedPreviews(ctx context.Context, job transcode.Job) error {
+	if !s.thumbnailGenerator.CanExtractFrames() {
+		return fmt.Errorf("视频帧提取器不可用")
+	}
+	if _, running := s.previewJobs.LoadOrStore(job.VideoID, struct{}{}); running {
+		return nil
+	}
+	defer s.previewJobs.Delete(job.VideoID)
+
+	ctx, cancel := context.WithTimeout(ctx, previewGenerateTimeout)
+	defer cancel()
+
+	meta, err := s.metadataService.GetMetadata(ctx, job.VideoID)
+	if err != nil {
+		return fmt.Errorf("获取视频元数据失败: %w", err)
+	}
+	return s.generatePreviews(ctx, meta)
+}
+
+// generatePreviews 生成进度条预览图，视频没有动态预览时一并生成
+// 两种预览各自失败时只记录日志，恢复或下载视频失败时返回错误
+func (s *VideoService) generatePreviews(ctx context.Context, meta *metadata.FileMetadata) error {
+	// 按需生成时视频可能已归档，先恢复到视频存储桶
+	meta, err := s.restoreArchivedVideo(ctx, meta)
+	if err != nil {
+		return err
+	}
+
+	// 截取多帧需要可随机访问的输入，视频只下载一次供两种预览共用
+	videoPath, err := s.downloadVideoToTemp(ctx, meta)
+	if err != nil {
+		return err
+	}
+	defer s.tempFiles.Remove(videoPath)
+
+	if result, err := s.generateSprite(ctx, meta, videoPath); err != nil {
+		fmt.Printf("生成进度条预览图失败(%s): %v\n", meta.FileID, err)
+	} else {
+		s.publishEvent(notify.EventSpriteReady, meta, result)
+	}
+
+	if meta.Preview == "" {
+		if previewPath, err := s.generateAnimatedPreview(ctx, meta, videoPath); err != nil {
+			fmt.Printf("生成动态预览失败(%s): %v\n", meta.FileID, err)
+		} else {
+			s.publishEvent(notify.EventPreviewReady, meta, map[string]string{"preview_path": previewPath})
+		}
+	}
+	return nil
+}
+
+// downloadVideoToTemp 将视频下载到临时文件，调用方负责删除
+func (s *VideoService) downloadVideoToTemp(ctx context.Context, meta *metadata.FileMetadata) (string, error) {
+	reader, err := s.storageClient.OpenFile(ctx, meta.BucketName, meta.ObjectName)
+	if err != nil {
+		return "", fmt.Errorf("读取视频失败: %w", err)
+	}
+	defer reader.Close()
+
+	tempFile, err := s.tempFiles.CreateTemp("zhulong-preview-*")
+	if err != nil {
+		return "", fmt.Errorf("创建临时文件失败: %w", err)
+	}
+
+	if _, err := io.Copy(tempFile, reader); err != nil {
+		tempFile.Close()
+		s.tempFiles.Remove(tempFile.Name())
+		return "", fmt.Errorf("写入临时文件失败: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		s.tempFiles.Remove(tempFile.Name())
+		return "", fmt.Errorf("关闭临时文件失败: %w", err)
+	}
+
+	return tempFile.Name(), nil
+}
+
+// generateAnimatedPreview 生成动态预览，保存后将路径写入元数据
+func (s *VideoService) generateAnimatedPreview(ctx context.Context, meta *metadata.FileMetadata, videoPath string) (string, error) {
+	previewPath, err := s.uploadAnimatedPreview(ctx, meta, videoPath)
+	if err != nil {
+		return "", err
+	}
+	if err := s.savePreviewPath(ctx, meta.FileID, previewPath); err != nil {
+		return "", err
+	}
+	return previewPath, nil
+}
+
+// uploadAnimatedPreview 生成动态预览并上传到缩略图存储桶，返回存储路径
+func (s *VideoService) uploadAnimatedPreview(ctx context.Context, meta *metadata.FileMetadata, videoPath string) (string, error) {
+	result, err := s.thumbnailGenerator.GenerateAnimatedPreview(&video.AnimatedPreviewRequest{
+		VideoPath: videoPath,
+		Duration:  float64(meta.Duration),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// 与缩略图使用相同的目录结构
+	previewPath := fmt.Sprintf("previews/%d/%02d/%s.gif", meta.CreatedAt.Year(), meta.CreatedAt.Month(), meta.FileID)
+	if _, err := s.storageClient.UploadStream(ctx, s.buckets.Bucket(storage.ContentThumbnails), previewPath,
+		bytes.NewReader(result.ImageData), result.FileSize, video.PreviewContentType); err != nil {
+		return "", fmt.Errorf("上传动态预览失败: %w", err)
+	}
+	return previewPath, nil
+}
+
+// savePreviewPath 将动态预览路径写入元数据，失败时删除已上传的预览
+func (s *VideoService) savePreviewPath(ctx context.Context, videoID, previewPath string) error {
+	err := s.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{
+		FileID:  videoID,
+		Preview: &previewPath,
+	})
+	if err != nil {
+		// 视频已被删除时清理刚上传的预览
+		s.storageClient.DeleteFile(ctx, s.buckets.Bucket(storage.ContentThumbnails), previewPath)
+		return fmt.Errorf("保存动态预览路径失败: %w", err)
+	}
+	return nil
+}
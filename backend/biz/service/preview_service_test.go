@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/manteia/zhulong/pkg/storage"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/user"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+func TestVideoService_PreviewGeneration(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("上传后异步生成动态预览", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		service.thumbnailGenerator.SetFrameExtractor(&stubFrameExtractor{available: true})
+		events := service.SubscribeNotifications(claimsContext("admin-1", user.RoleAdmin))
+		defer events.Close()
+
+		resp, err := service.UploadVideo(ctx, &api.VideoUploadRequest{}, createTestFileHeader(t, "preview.mp4", "video/mp4", mp4TestData(1024)))
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		videoID := resp.Video.ID
+
+		require.Eventually(t, func() bool {
+			_, running := service.previewJobs.Load(videoID)
+			return !running
+		}, 5*time.Second, 10*time.Millisecond, "预览图应该生成完成")
+
+		detail, err := service.metadataService.GetMetadata(ctx, videoID)
+		require.NoError(t, err)
+		require.NotEmpty(t, detail.Preview, "动态预览路径应该写入元数据")
+		assert.Contains(t, detail.Preview, videoID+".gif")
+		assert.NotEmpty(t, testObjectData(t, store, detail.Preview))
+		assert.Equal(t, detail.Preview, convertToAPIVideo(detail).PreviewPath, "API模型应该返回动态预览路径")
+
+		var eventTypes []string
+		for len(eventTypes) < 4 {
+			select {
+			case event := <-events.C:
+				eventTypes = append(eventTypes, event.Type)
+			case <-time.After(time.Second):
+				t.Fatalf("未收到全部事件: %v", eventTypes)
+			}
+		}
+		assert.Contains(t, eventTypes, "sprite.ready")
+		assert.Contains(t, eventTypes, "preview.ready")
+
+		objects, err := service.collectVideoObjects(ctx, detail)
+		require.NoError(t, err)
+		assert.Contains(t, bucketObjectNames(objects, "zhulong-videos"), detail.Preview, "删除视频时应该一并删除动态预览")
+	})
+
+	t.Run("视频已删除时清理动态预览", func(t *testing.T) {
+		service := createStreamTestService(t)
+		service.thumbnailGenerator.SetFrameExtractor(&stubFrameExtractor{available: true})
+		store := service.storageClient.(*storage.MemoryStorage)
+
+		meta, err := service.metadataService.GetMetadata(ctx, "video1")
+		require.NoError(t, err)
+		videoPath, err := service.downloadVideoToTemp(ctx, meta)
+		require.NoError(t, err)
+		defer os.Remove(videoPath)
+		require.NoError(t, service.metadataService.DeleteMetadata(ctx, "video1"))
+
+		_, err = service.generateAnimatedPreview(ctx, meta, videoPath)
+		assert.Error(t, err)
+		for _, key := range testObjectNames(t, store, "") {
+			assert.NotContains(t, key, "previews/", "保存元数据失败时应该删除已上传的预览")
+		}
+	})
+
+	t.Run("帧提取器不可用时不生成", func(t *testing.T) {
+		service := createStreamTestService(t)
+		meta, err := service.metadataService.GetMetadata(ctx, "video1")
+		require.NoError(t, err)
+
+		service.schedulePreviewGeneration(meta)
+		_, running := service.previewJobs.Load("video1")
+		assert.False(t, running)
+		assert.Equal(t, "image/gif", video.PreviewContentType)
+	})
+}
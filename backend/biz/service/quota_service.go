@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/quota"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// reserveQuota 为上传预占用户配额，未启用配额管理时不限制
+// 配额不足时返回的错误包装quota.ErrQuotaExceeded
+func (s *VideoService) reserveQuota(ctx context.Context, owner string, size int64) (*quota.Reservation, error) {
+	if s.quotaManager == nil {
+		return nil, nil
+	}
+	return s.quotaManager.Reserve(ctx, owner, size)
+}
+
+// checkQuota 检查用户配额是否足够，不预占配额
+func (s *VideoService) checkQuota(ctx context.Context, owner string, size int64) error {
+	if s.quotaManager == nil {
+		return nil
+	}
+	return s.quotaManager.Check(ctx, owner, size)
+}
+
+// GetCurrentUserQuota 获取当前用户的存储配额使用情况
+func (s *VideoService) GetCurrentUserQuota(ctx context.Context) (*api.UserQuotaResponse, error) {
+	claims, ok := user.ClaimsFromContext(ctx)
+	if !ok {
+		return &api.UserQuotaResponse{
+			Base: &api.BaseResponse{
+				Code:    7010,
+				Message: "未登录",
+			},
+		}, nil
+	}
+
+	usage := &quota.Usage{Owner: claims.UserID, Remaining: -1}
+	if s.quotaManager != nil {
+		var err error
+		usage, err = s.quotaManager.GetUsage(ctx, claims.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("获取存储配额失败: %w", err)
+		}
+	}
+
+	return &api.UserQuotaResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Quota: &api.UserQuota{
+			Used:      usage.Used,
+			Reserved:  usage.Reserved,
+			Limit:     usage.Limit,
+			Remaining: usage.Remaining,
+		},
+	}, nil
+}
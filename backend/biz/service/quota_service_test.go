@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/manteia/zhulong/pkg/storage"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/quota"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// createQuotaTestService 创建启用用户配额的测试服务
+func createQuotaTestService(t *testing.T, limit int64) (*VideoService, *storage.MemoryStorage) {
+	service, store := createDirectUploadTestService(t)
+	service.quotaManager = quota.NewQuotaManager(limit, service.metadataService.GetStorageUsage)
+	return service, store
+}
+
+func TestVideoService_UploadQuota(t *testing.T) {
+	ctx := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "user-1", Role: user.RoleUploader})
+
+	t.Run("上传计入用户配额", func(t *testing.T) {
+		service, _ := createQuotaTestService(t, 5000)
+
+		resp, err := service.UploadVideo(ctx, &api.VideoUploadRequest{}, createTestFileHeader(t, "first.mp4", "video/mp4", mp4TestData(3000)))
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+		quotaResp, err := service.GetCurrentUserQuota(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int32(0), quotaResp.Base.Code)
+		assert.Equal(t, &api.UserQuota{Used: 3000, Limit: 5000, Remaining: 2000}, quotaResp.Quota)
+
+		resp, err = service.UploadVideo(ctx, &api.VideoUploadRequest{}, createTestFileHeader(t, "second.mp4", "video/mp4", mp4TestData(3000)))
+		require.NoError(t, err)
+		assert.Equal(t, int32(1009), resp.Base.Code, "超出配额的上传应该被拒绝")
+
+		otherCtx := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "user-2", Role: user.RoleUploader})
+		resp, err = service.UploadVideo(otherCtx, &api.VideoUploadRequest{}, createTestFileHeader(t, "other.mp4", "video/mp4", mp4TestData(3000)))
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code, "其他用户的配额不受影响")
+	})
+
+	t.Run("直传上传检查配额", func(t *testing.T) {
+		service, store := createQuotaTestService(t, 5000)
+
+		resp, err := service.CreateUploadURL(ctx, &api.VideoUploadURLRequest{Filename: "big.mp4", Size: 6000})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1009), resp.Base.Code, "声明大小超出配额时不应该生成上传地址")
+
+		first := createTestUploadURL(t, service, 3000)
+		second := createTestUploadURL(t, service, 3000)
+		session, err := service.directUploads.GetSession(first.UploadToken)
+		require.NoError(t, err)
+		putTestObject(t, store, session.ObjectName, mp4TestData(3000))
+		secondSession, err := service.directUploads.GetSession(second.UploadToken)
+		require.NoError(t, err)
+		putTestObject(t, store, secondSession.ObjectName, mp4TestData(3000))
+
+		confirmResp, err := service.ConfirmUpload(ctx, &api.VideoUploadConfirmRequest{UploadToken: first.UploadToken})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), confirmResp.Base.Code, confirmResp.Base.Message)
+
+		confirmResp, err = service.ConfirmUpload(ctx, &api.VideoUploadConfirmRequest{UploadToken: second.UploadToken})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1009), confirmResp.Base.Code, "确认时超出配额应该被拒绝")
+		assert.NotContains(t, testObjectNames(t, store, ""), secondSession.ObjectName, "超出配额的直传文件应该被删除")
+	})
+
+	t.Run("删除元数据后释放配额", func(t *testing.T) {
+		service, _ := createQuotaTestService(t, 5000)
+
+		resp, err := service.UploadVideo(ctx, &api.VideoUploadRequest{}, createTestFileHeader(t, "first.mp4", "video/mp4", mp4TestData(3000)))
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		require.NoError(t, service.metadataService.DeleteMetadata(ctx, resp.Video.ID))
+
+		quotaResp, err := service.GetCurrentUserQuota(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), quotaResp.Quota.Used)
+		assert.Equal(t, int64(5000), quotaResp.Quota.Remaining)
+	})
+
+	t.Run("未登录获取配额", func(t *testing.T) {
+		service, _ := createQuotaTestService(t, 5000)
+
+		quotaResp, err := service.GetCurrentUserQuota(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int32(7010), quotaResp.Base.Code)
+	})
+}
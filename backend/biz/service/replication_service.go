@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/replication"
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// newReplicator 根据replication配置创建存储复制，未启用时返回nil
+// 复制全部内容存储桶，启用归档时包括归档存储桶，配置了备份存储桶时包括备份存储桶
+func newReplicator(cfg *config.Config, storageClient storage.StorageInterface) (*replication.Replicator, error) {
+	if !cfg.Replication.Enabled {
+		return nil, nil
+	}
+	replica, err := storage.NewFromConfig(cfg.GetReplicationDriverConfig())
+	if err != nil {
+		return nil, fmt.Errorf("初始化副本存储失败: %w", err)
+	}
+
+	buckets := cfg.GetBucketResolver().Buckets()
+	if cfg.Archive.Enabled {
+		buckets = append(buckets, cfg.Archive.Bucket)
+	}
+	buckets = append(buckets, cfg.Backup.Bucket)
+	return replication.NewReplicator(storageClient, replica, replication.Options{
+		Buckets:       buckets,
+		Workers:       cfg.Replication.Workers,
+		QueueSize:     cfg.Replication.QueueSize,
+		MirrorDeletes: cfg.Replication.MirrorDeletes,
+	})
+}
+
+// GetReplicationReport 获取复制统计并对比主存储和副本，存储桶中的文件较多时需要一段时间
+func (s *VideoService) GetReplicationReport(ctx context.Context) (*api.ReplicationReportResponse, error) {
+	if s.replicator == nil {
+		return s.replicationErrorResponse(9801, "未启用存储复制"), nil
+	}
+
+	report, err := s.replicator.Check(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &api.ReplicationReportResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Stats:  s.convertReplicationStats(),
+		Report: convertReplicationReport(report),
+	}, nil
+}
+
+// SyncReplication 在后台对比主存储和副本，将缺少的文件加入复制队列
+func (s *VideoService) SyncReplication(ctx context.Context) (*api.ReplicationReportResponse, error) {
+	if s.replicator == nil {
+		return s.replicationErrorResponse(9801, "未启用存储复制"), nil
+	}
+	if s.replicator.Syncing() {
+		return s.replicationErrorResponse(9802, "同步正在执行"), nil
+	}
+
+	go s.syncReplication()
+	return &api.ReplicationReportResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "已开始同步",
+		},
+		Stats: s.convertReplicationStats(),
+	}, nil
+}
+
+// syncReplication 执行一次同步并输出结果
+func (s *VideoService) syncReplication() {
+	report, queued, err := s.replicator.Sync(context.Background())
+	if errors.Is(err, replication.ErrSyncRunning) {
+		return
+	}
+	if err != nil {
+		fmt.Printf("存储复制同步失败: %v\n", err)
+		return
+	}
+	if !report.Consistent {
+		fmt.Printf("存储复制同步完成: %d个文件待复制\n", queued)
+	}
+}
+
+// startReplication 启动复制worker并在后台同步一次以补齐未复制的已有文件，syncInterval大于0时定期同步
+func (s *VideoService) startReplication(syncInterval time.Duration) {
+	s.replicator.Start(context.Background())
+	go func() {
+		s.syncReplication()
+		if syncInterval <= 0 {
+			return
+		}
+
+		ticker := time.NewTicker(syncInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.syncReplication()
+		}
+	}()
+}
+
+// convertReplicationStats 转换复制统计
+func (s *VideoService) convertReplicationStats() *api.ReplicationStats {
+	stats := s.replicator.Stats()
+	result := &api.ReplicationStats{
+		Queued:         int32(stats.Queued),
+		Replicated:     stats.Replicated,
+		Deleted:        stats.Deleted,
+		Failed:         stats.Failed,
+		Dropped:        stats.Dropped,
+		LastSyncQueued: int32(stats.LastSyncQueued),
+		Syncing:        s.replicator.Syncing(),
+		MirrorDeletes:  s.replicator.MirrorDeletes(),
+	}
+	if stats.LastError != "" {
+		lastErrorAt := stats.LastErrorAt.UnixMilli()
+		result.LastError = &stats.LastError
+		result.LastErrorAt = &lastErrorAt
+	}
+	if !stats.LastSyncAt.IsZero() {
+		lastSyncAt := stats.LastSyncAt.UnixMilli()
+		result.LastSyncAt = &lastSyncAt
+	}
+	return result
+}
+
+// convertReplicationReport 转换一致性报告
+func convertReplicationReport(report *replication.Report) *api.ReplicationReport {
+	buckets := make([]*api.ReplicationBucketReport, 0, len(report.Buckets))
+	for _, bucket := range report.Buckets {
+		buckets = append(buckets, &api.ReplicationBucketReport{
+			Bucket:            bucket.Bucket,
+			PrimaryObjects:    int32(bucket.PrimaryObjects),
+			PrimaryBytes:      bucket.PrimaryBytes,
+			ReplicaObjects:    int32(bucket.ReplicaObjects),
+			ReplicaBytes:      bucket.ReplicaBytes,
+			Missing:           int32(bucket.Missing),
+			Mismatched:        int32(bucket.Mismatched),
+			Extra:             int32(bucket.Extra),
+			MissingObjects:    bucket.MissingObjects,
+			MismatchedObjects: bucket.MismatchedObjects,
+			ExtraObjects:      bucket.ExtraObjects,
+		})
+	}
+	return &api.ReplicationReport{
+		Consistent: report.Consistent,
+		Buckets:    buckets,
+		CheckedAt:  report.CheckedAt.UnixMilli(),
+	}
+}
+
+// replicationErrorResponse 创建存储复制错误响应
+func (s *VideoService) replicationErrorResponse(code int32, message string) *api.ReplicationReportResponse {
+	return &api.ReplicationReportResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
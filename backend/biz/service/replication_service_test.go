@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/replication"
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// createReplicationTestService 创建将视频存储桶复制到本地存储的测试服务
+func createReplicationTestService(t *testing.T) (*VideoService, *storage.LocalStorage) {
+	service := createStreamTestService(t)
+	replica, err := storage.NewLocalStorage(&storage.LocalConfig{RootDir: t.TempDir()})
+	require.NoError(t, err)
+
+	service.replicator, err = replication.NewReplicator(service.storageClient, replica, replication.Options{
+		Buckets:   []string{"zhulong-videos"},
+		QueueSize: 10,
+	})
+	require.NoError(t, err)
+	service.storageClient = service.replicator.Wrap(service.storageClient)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	service.replicator.Start(ctx)
+	return service, replica
+}
+
+func TestVideoService_Replication(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("同步后副本一致", func(t *testing.T) {
+		service, replica := createReplicationTestService(t)
+
+		resp, err := service.GetReplicationReport(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.False(t, resp.Report.Consistent)
+		require.Len(t, resp.Report.Buckets, 1)
+		assert.Equal(t, []string{"videos/2025/08/video1.mp4"}, resp.Report.Buckets[0].MissingObjects)
+
+		resp, err = service.SyncReplication(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Nil(t, resp.Report, "同步在后台执行")
+
+		require.Eventually(t, func() bool {
+			resp, err := service.GetReplicationReport(ctx)
+			return err == nil && resp.Report.Consistent
+		}, time.Second, 10*time.Millisecond)
+		data, err := replica.DownloadFile(ctx, "zhulong-videos", "videos/2025/08/video1.mp4")
+		require.NoError(t, err)
+		assert.Equal(t, "0123456789", string(data))
+
+		resp, err = service.GetReplicationReport(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), resp.Stats.Replicated)
+		assert.NotNil(t, resp.Stats.LastSyncAt)
+	})
+
+	t.Run("服务写入的文件自动复制", func(t *testing.T) {
+		service, replica := createReplicationTestService(t)
+
+		_, err := service.storageClient.UploadFile(ctx, "zhulong-videos", "videos/2025/08/video2.mp4", []byte("new"), "video/mp4")
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			exists, err := replica.FileExists(ctx, "zhulong-videos", "videos/2025/08/video2.mp4")
+			return err == nil && exists
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("未启用存储复制", func(t *testing.T) {
+		service := createStreamTestService(t)
+
+		resp, err := service.GetReplicationReport(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int32(9801), resp.Base.Code)
+
+		resp, err = service.SyncReplication(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int32(9801), resp.Base.Code)
+
+		replicator, err := newReplicator(service.config, service.storageClient)
+		require.NoError(t, err)
+		assert.Nil(t, replicator)
+	})
+
+	t.Run("包装后仍能获取本地存储", func(t *testing.T) {
+		service := createStreamTestService(t)
+		local, err := storage.NewLocalStorage(&storage.LocalConfig{RootDir: t.TempDir()})
+		require.NoError(t, err)
+		replica, err := storage.NewMemoryStorage(nil)
+		require.NoError(t, err)
+		replicator, err := replication.NewReplicator(local, replica, replication.Options{Buckets: []string{"zhulong-videos"}})
+		require.NoError(t, err)
+		service.storageClient = replicator.Wrap(local)
+
+		unwrapped, ok := service.LocalStorage()
+		require.True(t, ok)
+		assert.Same(t, local, unwrapped)
+	})
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+const (
+	// spriteRootPrefix 预览图在存储桶中的根前缀
+	spriteRootPrefix = "sprites"
+	// spriteVTTName WebVTT缩略图轨道文件名
+	spriteVTTName = "sprite.vtt"
+	// spriteURLExpiry 雪碧图预签名URL有效期
+	spriteURLExpiry = time.Hour
+)
+
+// spritePrefix 视频预览图文件的存储前缀
+func spritePrefix(videoID string) string {
+	return path.Join(spriteRootPrefix, videoID) + "/"
+}
+
+// generateSprite 生成雪碧图和WebVTT轨道并保存到缩略图存储桶
+func (s *VideoService) generateSprite(ctx context.Context, meta *metadata.FileMetadata, videoPath string) (*video.SpriteSheetResult, error) {
+	result, err := s.thumbnailGenerator.GenerateSpriteSheet(&video.SpriteSheetRequest{
+		VideoPath: videoPath,
+		Duration:  float64(meta.Duration),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 先上传雪碧图，WebVTT轨道存在即表示预览图已就绪
+	bucketName := s.buckets.Bucket(storage.ContentThumbnails)
+	prefix := spritePrefix(meta.FileID)
+	if _, err := s.storageClient.UploadStream(ctx, bucketName, prefix+video.SpriteImageName,
+		bytes.NewReader(result.ImageData), int64(len(result.ImageData)), "image/jpeg"); err != nil {
+		return nil, fmt.Errorf("上传雪碧图失败: %w", err)
+	}
+	if _, err := s.storageClient.UploadStream(ctx, bucketName, prefix+spriteVTTName,
+		bytes.NewReader(result.VTT), int64(len(result.VTT)), video.SpriteVTTContentType); err != nil {
+		return nil, fmt.Errorf("上传WebVTT轨道失败: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetThumbnailTrack 获取进度条悬停预览的WebVTT轨道，雪碧图地址替换为预签名URL
+// 预览图尚未生成时会触发按需生成
+func (s *VideoService) GetThumbnailTrack(ctx context.Context, req *api.ThumbnailTrackRequest) (*api.ThumbnailTrackResponse, error) {
+	if req.VideoID == "" {
+		return s.thumbnailTrackErrorResponse(6201, "视频ID不能为空"), nil
+	}
+
+	meta, err := s.getVisibleMetadata(ctx, req.VideoID)
+	if err != nil {
+		return s.thumbnailTrackErrorResponse(6202, "视频不存在"), nil
+	}
+
+	bucketName := s.buckets.Bucket(storage.ContentThumbnails)
+	prefix := spritePrefix(meta.FileID)
+	exists, err := s.storageClient.FileExists(ctx, bucketName, prefix+spriteVTTName)
+	if err != nil {
+		return nil, fmt.Errorf("检查预览图失败: %w", err)
+	}
+	if !exists {
+		if !s.previewGenerationAvailable() {
+			return s.thumbnailTrackErrorResponse(6203, "视频帧提取器不可用，无法生成预览图"), nil
+		}
+		s.schedulePreviewGeneration(meta)
+		return s.thumbnailTrackErrorResponse(6204, "预览图正在生成，请稍后重试"), nil
+	}
+
+	reader, err := s.storageClient.OpenFile(ctx, bucketName, prefix+spriteVTTName)
+	if err != nil {
+		return nil, fmt.Errorf("读取WebVTT轨道失败: %w", err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("读取WebVTT轨道失败: %w", err)
+	}
+
+	imageURL, err := s.presignedURL(ctx, bucketName, prefix+video.SpriteImageName, spriteURLExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("生成雪碧图URL失败: %w", err)
+	}
+
+	return &api.ThumbnailTrackResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Content: string(video.ReplaceSpriteImageURL(content, video.SpriteImageName, imageURL)),
+	}, nil
+}
+
+// thumbnailTrackErrorResponse 创建缩略图预览轨道错误响应
+func (s *VideoService) thumbnailTrackErrorResponse(code int32, message string) *api.ThumbnailTrackResponse {
+	return &api.ThumbnailTrackResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
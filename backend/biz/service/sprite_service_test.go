@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"image"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/manteia/zhulong/pkg/storage"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+// stubFrameExtractor 测试用帧提取器，返回与请求尺寸一致的空白帧，模拟时长为20秒的视频
+type stubFrameExtractor struct {
+	available bool
+}
+
+// stubVideoDuration 测试用帧提取器模拟的视频时长（秒）
+const stubVideoDuration = 20
+
+func (s *stubFrameExtractor) IsAvailable() bool {
+	return s.available
+}
+
+func (s *stubFrameExtractor) ExtractFrame(reader io.Reader, options *video.ThumbnailOptions) (image.Image, error) {
+	if _, err := io.ReadAll(reader); err != nil {
+		return nil, err
+	}
+	if options.TimeOffset >= stubVideoDuration {
+		return nil, io.EOF
+	}
+	return image.NewRGBA(image.Rect(0, 0, options.Width, options.Height)), nil
+}
+
+func TestVideoService_GetThumbnailTrack(t *testing.T) {
+	ctx := context.Background()
+	req := &api.ThumbnailTrackRequest{VideoID: "video1"}
+
+	t.Run("按需生成预览图", func(t *testing.T) {
+		service := createStreamTestService(t)
+		service.thumbnailGenerator.SetFrameExtractor(&stubFrameExtractor{available: true})
+
+		resp, err := service.GetThumbnailTrack(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, int32(6204), resp.Base.Code, "未生成时应该触发生成")
+
+		require.Eventually(t, func() bool {
+			_, running := service.previewJobs.Load("video1")
+			return !running
+		}, 5*time.Second, 10*time.Millisecond, "预览图应该生成完成")
+
+		resp, err = service.GetThumbnailTrack(ctx, req)
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.True(t, strings.HasPrefix(resp.Content, "WEBVTT\n"))
+		assert.Regexp(t, `http://storage\.local/zhulong-videos/sprites/video1/sprite\.jpg\?expires=\d+&signature=[0-9a-f]+#xywh=0,0,160,90`, resp.Content,
+			"雪碧图地址应该替换为预签名URL")
+	})
+
+	t.Run("生成后保存到缩略图存储桶", func(t *testing.T) {
+		service := createStreamTestService(t)
+		service.thumbnailGenerator.SetFrameExtractor(&stubFrameExtractor{available: true})
+		store := service.storageClient.(*storage.MemoryStorage)
+
+		meta, err := service.metadataService.GetMetadata(ctx, "video1")
+		require.NoError(t, err)
+		meta.Duration = 15
+
+		videoPath, err := service.downloadVideoToTemp(ctx, meta)
+		require.NoError(t, err)
+		defer os.Remove(videoPath)
+
+		result, err := service.generateSprite(ctx, meta, videoPath)
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Frames)
+		assert.Equal(t, result.ImageData, testObjectData(t, store, "sprites/video1/sprite.jpg"))
+		assert.Equal(t, result.VTT, testObjectData(t, store, "sprites/video1/sprite.vtt"))
+
+		objects, err := service.collectVideoObjects(ctx, meta)
+		require.NoError(t, err)
+		assert.Contains(t, bucketObjectNames(objects, "zhulong-videos"), "sprites/video1/sprite.jpg", "删除视频时应该一并删除预览图")
+		assert.Contains(t, bucketObjectNames(objects, "zhulong-videos"), "sprites/video1/sprite.vtt")
+	})
+
+	t.Run("帧提取器不可用", func(t *testing.T) {
+		service := createStreamTestService(t)
+		service.thumbnailGenerator.SetFrameExtractor(&stubFrameExtractor{available: false})
+
+		resp, err := service.GetThumbnailTrack(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, int32(6203), resp.Base.Code)
+	})
+
+	t.Run("无效参数", func(t *testing.T) {
+		service := createStreamTestService(t)
+
+		resp, err := service.GetThumbnailTrack(ctx, &api.ThumbnailTrackRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6201), resp.Base.Code)
+
+		resp, err = service.GetThumbnailTrack(ctx, &api.ThumbnailTrackRequest{VideoID: "not-exist"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6202), resp.Base.Code)
+	})
+}
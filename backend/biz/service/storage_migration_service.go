@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/migrate"
+)
+
+// storageMigration 存储桶迁移任务
+type storageMigration struct {
+	migrator      *migrate.Migrator
+	updatedVideos atomic.Int32 // 已改写存储桶引用的视频数量
+}
+
+// StartStorageMigration 在后台将存储桶中的全部文件复制到目标存储桶，并将视频的存储桶引用改为目标存储桶
+// 每个文件复制完成后立即改写引用，源存储桶中的文件保留；重新发起相同的迁移时跳过已复制的文件
+func (s *VideoService) StartStorageMigration(ctx context.Context, req *api.StorageMigrationRequest) (*api.StorageMigrationResponse, error) {
+	if req.FromBucket == "" || req.ToBucket == "" {
+		return s.storageMigrationErrorResponse(9301, "源存储桶和目标存储桶不能为空"), nil
+	}
+	if req.FromBucket == req.ToBucket {
+		return s.storageMigrationErrorResponse(9301, "源存储桶和目标存储桶不能相同"), nil
+	}
+	if s.archiver != nil && (req.FromBucket == s.archiver.Bucket() || req.ToBucket == s.archiver.Bucket()) {
+		return s.storageMigrationErrorResponse(9301, "归档存储桶不能参与迁移"), nil
+	}
+
+	exists, err := s.storageClient.BucketExists(ctx, req.FromBucket)
+	if err != nil {
+		return nil, fmt.Errorf("检查源存储桶失败: %w", err)
+	}
+	if !exists {
+		return s.storageMigrationErrorResponse(9302, "源存储桶不存在"), nil
+	}
+
+	s.migrationMutex.Lock()
+	defer s.migrationMutex.Unlock()
+
+	if s.migration != nil && s.migration.migrator.Running() {
+		return s.storageMigrationErrorResponse(9303, "已有存储迁移正在运行"), nil
+	}
+
+	migrator, err := migrate.NewMigrator(
+		migrate.Endpoint{Storage: s.storageClient, Bucket: req.FromBucket},
+		migrate.Endpoint{Storage: s.storageClient, Bucket: req.ToBucket},
+	)
+	if err != nil {
+		return s.storageMigrationErrorResponse(9301, err.Error()), nil
+	}
+
+	job := &storageMigration{migrator: migrator}
+	migrator.SetObjectHook(func(ctx context.Context, objectName string) error {
+		job.updatedVideos.Add(int32(s.moveVideoReferences(ctx, req.FromBucket, objectName, req.ToBucket)))
+		return nil
+	})
+	migrator.SetCompleteHook(func(ctx context.Context) error {
+		return s.moveArchivedVideoReferences(ctx, job)
+	})
+	s.migration = job
+
+	go s.runStorageMigration(job)
+
+	return &api.StorageMigrationResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "存储迁移已开始",
+		},
+		Migration: convertStorageMigration(job),
+	}, nil
+}
+
+// GetStorageMigration 获取正在运行或最近一次的存储迁移进度
+func (s *VideoService) GetStorageMigration(ctx context.Context) (*api.StorageMigrationResponse, error) {
+	s.migrationMutex.Lock()
+	job := s.migration
+	s.migrationMutex.Unlock()
+
+	if job == nil {
+		return s.storageMigrationErrorResponse(9302, "没有存储迁移记录"), nil
+	}
+
+	return &api.StorageMigrationResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Migration: convertStorageMigration(job),
+	}, nil
+}
+
+// runStorageMigration 在后台执行存储迁移
+func (s *VideoService) runStorageMigration(job *storageMigration) {
+	source, target := job.migrator.Source().Bucket, job.migrator.Target().Bucket
+
+	failed, err := job.migrator.Run(context.Background())
+	if err != nil {
+		fmt.Printf("存储迁移失败(%s -> %s): %v\n", source, target, err)
+		return
+	}
+	fmt.Printf("存储迁移完成(%s -> %s): 失败%d个文件，改写%d个视频\n", source, target, failed, job.updatedVideos.Load())
+}
+
+// moveArchivedVideoReferences 改写已归档视频的存储桶引用
+// 已归档视频的文件位于归档存储桶，不会被复制，恢复时应该写入目标存储桶
+func (s *VideoService) moveArchivedVideoReferences(ctx context.Context, job *storageMigration) error {
+	source, target := job.migrator.Source().Bucket, job.migrator.Target().Bucket
+
+	list, err := s.metadataService.ListMetadata(ctx, &metadata.ListMetadataRequest{Limit: math.MaxInt})
+	if err != nil {
+		return fmt.Errorf("获取视频列表失败: %w", err)
+	}
+	for _, meta := range list.Items {
+		if meta.Archived && meta.BucketName == source {
+			job.updatedVideos.Add(int32(s.moveVideoReferences(ctx, source, meta.ObjectName, target)))
+		}
+	}
+	return nil
+}
+
+// moveVideoReferences 将引用源存储桶中指定对象的视频改为引用目标存储桶，返回改写的视频数量
+// 与归档和恢复使用同一对象锁，避免改写期间文件被移动
+func (s *VideoService) moveVideoReferences(ctx context.Context, fromBucket, objectName, toBucket string) int {
+	if s.archiver != nil {
+		unlock := s.archiver.Lock(objectName)
+		defer unlock()
+	}
+	return s.metadataService.MoveObjectReferences(ctx, fromBucket, objectName, toBucket)
+}
+
+// convertStorageMigration 转换存储迁移进度
+func convertStorageMigration(job *storageMigration) *api.StorageMigration {
+	progress := job.migrator.Progress()
+	migration := &api.StorageMigration{
+		FromBucket:    job.migrator.Source().Bucket,
+		ToBucket:      job.migrator.Target().Bucket,
+		Status:        progress.Status,
+		Total:         int32(progress.Total),
+		Copied:        int32(progress.Copied),
+		Skipped:       int32(progress.Skipped),
+		Failed:        int32(progress.Failed),
+		BytesCopied:   progress.BytesCopied,
+		UpdatedVideos: job.updatedVideos.Load(),
+		StartedAt:     progress.StartedAt.UnixMilli(),
+	}
+	if progress.Error != "" {
+		migration.Error = &progress.Error
+	}
+	if !progress.FinishedAt.IsZero() {
+		finishedAt := progress.FinishedAt.UnixMilli()
+		migration.FinishedAt = &finishedAt
+	}
+	return migration
+}
+
+// storageMigrationErrorResponse 创建存储迁移错误响应
+func (s *VideoService) storageMigrationErrorResponse(code int32, message string) *api.StorageMigrationResponse {
+	return &api.StorageMigrationResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
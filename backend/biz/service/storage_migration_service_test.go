@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/migrate"
+)
+
+// waitStorageMigration 等待存储迁移结束
+func waitStorageMigration(t *testing.T, service *VideoService) *api.StorageMigration {
+	var resp *api.StorageMigrationResponse
+	require.Eventually(t, func() bool {
+		var err error
+		resp, err = service.GetStorageMigration(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		return resp.Migration.FinishedAt != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	return resp.Migration
+}
+
+func TestVideoService_StorageMigration(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("迁移存储桶并改写视频引用", func(t *testing.T) {
+		service, store := createArchiveTestService(t)
+		playing := uploadTestVideo(t, service, "playing.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), playing.Base.Code, playing.Base.Message)
+		archived := uploadTestVideo(t, service, "archived.mp4", mp4TestData(4096))
+		require.Equal(t, int32(0), archived.Base.Code, archived.Base.Message)
+		_, err := service.ArchiveVideo(ctx, &api.VideoArchiveRequest{VideoID: archived.Video.ID})
+		require.NoError(t, err)
+
+		resp, err := service.StartStorageMigration(ctx, &api.StorageMigrationRequest{FromBucket: "zhulong-videos", ToBucket: "zhulong-media"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, "zhulong-media", resp.Migration.ToBucket)
+
+		migration := waitStorageMigration(t, service)
+		assert.Equal(t, migrate.StatusCompleted, migration.Status)
+		assert.Equal(t, int32(0), migration.Failed)
+		assert.Greater(t, migration.Copied, int32(0))
+
+		meta, err := service.metadataService.GetMetadata(ctx, playing.Video.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "zhulong-media", meta.BucketName, "视频应该引用目标存储桶")
+		exists, err := store.FileExists(ctx, "zhulong-media", meta.ObjectName)
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		archivedMeta, err := service.metadataService.GetMetadata(ctx, archived.Video.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "zhulong-media", archivedMeta.BucketName, "已归档视频应该恢复到目标存储桶")
+
+		stream, err := service.StreamVideo(ctx, &api.VideoStreamRequest{VideoID: archived.Video.ID}, "")
+		require.NoError(t, err)
+		require.Equal(t, int32(0), stream.Base.Code, stream.Base.Message)
+		body, err := io.ReadAll(stream.Body)
+		stream.Body.Close()
+		require.NoError(t, err)
+		assert.Equal(t, mp4TestData(4096), body)
+		exists, err = store.FileExists(ctx, "zhulong-media", archivedMeta.ObjectName)
+		require.NoError(t, err)
+		assert.True(t, exists, "恢复的文件应该写入目标存储桶")
+
+		// 重新发起迁移时跳过已复制的文件
+		resp, err = service.StartStorageMigration(ctx, &api.StorageMigrationRequest{FromBucket: "zhulong-videos", ToBucket: "zhulong-media"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		migration = waitStorageMigration(t, service)
+		assert.Equal(t, int32(0), migration.Copied)
+		assert.Equal(t, migration.Total, migration.Skipped)
+	})
+
+	t.Run("参数错误", func(t *testing.T) {
+		service, _ := createArchiveTestService(t)
+
+		resp, err := service.GetStorageMigration(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int32(9302), resp.Base.Code, "没有迁移记录")
+
+		invalid := []*api.StorageMigrationRequest{
+			{ToBucket: "zhulong-media"},
+			{FromBucket: "zhulong-videos", ToBucket: "zhulong-videos"},
+			{FromBucket: "zhulong-videos", ToBucket: archiveTestBucket},
+		}
+		for _, req := range invalid {
+			resp, err := service.StartStorageMigration(ctx, req)
+			require.NoError(t, err)
+			assert.Equal(t, int32(9301), resp.Base.Code, "无效请求应该失败: %+v", req)
+		}
+
+		resp, err = service.StartStorageMigration(ctx, &api.StorageMigrationRequest{FromBucket: "missing", ToBucket: "zhulong-media"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(9302), resp.Base.Code, "源存储桶不存在")
+	})
+
+	t.Run("已有迁移正在运行", func(t *testing.T) {
+		service, store := createArchiveTestService(t)
+		require.NoError(t, store.CreateBucket(ctx, "zhulong-videos"))
+
+		pending, err := migrate.NewMigrator(
+			migrate.Endpoint{Storage: store, Bucket: "zhulong-videos"},
+			migrate.Endpoint{Storage: store, Bucket: "zhulong-media"},
+		)
+		require.NoError(t, err)
+		service.migration = &storageMigration{migrator: pending}
+
+		resp, err := service.StartStorageMigration(ctx, &api.StorageMigrationRequest{FromBucket: "zhulong-videos", ToBucket: "zhulong-media"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(9303), resp.Base.Code)
+	})
+}
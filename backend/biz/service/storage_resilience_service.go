@@ -0,0 +1,30 @@
+package service
+
+import (
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// StorageResilienceStats 获取存储调用的重试和熔断统计，未启用重试和熔断时返回nil
+func (s *VideoService) StorageResilienceStats() *api.StorageResilienceStats {
+	resilient, ok := storage.FindResilientStorage(s.storageClient)
+	if !ok {
+		return nil
+	}
+
+	stats := resilient.Stats()
+	result := &api.StorageResilienceStats{
+		CircuitState: stats.CircuitState,
+		CircuitOpens: stats.CircuitOpens,
+		Calls:        stats.Calls,
+		Retries:      stats.Retries,
+		Failures:     stats.Failures,
+		Rejected:     stats.Rejected,
+	}
+	if stats.LastError != "" {
+		lastErrorAt := stats.LastErrorAt.UnixMilli()
+		result.LastError = &stats.LastError
+		result.LastErrorAt = &lastErrorAt
+	}
+	return result
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+func TestVideoService_StorageResilienceStats(t *testing.T) {
+	service := createStreamTestService(t)
+	assert.Nil(t, service.StorageResilienceStats(), "未启用重试和熔断")
+
+	service.storageClient = storage.WithResilience(service.storageClient, storage.ResilienceOptions{
+		MaxAttempts:      2,
+		FailureThreshold: 5,
+		OpenDuration:     time.Minute,
+	})
+	_, err := service.storageClient.GetFileInfo(context.Background(), "zhulong-videos", "videos/2025/08/video1.mp4")
+	require.NoError(t, err)
+
+	stats := service.StorageResilienceStats()
+	require.NotNil(t, stats)
+	assert.Equal(t, storage.CircuitClosed, stats.CircuitState)
+	assert.Equal(t, int64(1), stats.Calls)
+	assert.Nil(t, stats.LastError)
+}
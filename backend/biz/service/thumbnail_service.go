@@ -0,0 +1,273 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/middleware"
+	"github.com/manteia/zhulong/pkg/notify"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+const (
+	// thumbnailCandidateRootPrefix 候选缩略图在存储桶中的根前缀
+	thumbnailCandidateRootPrefix = "thumbnail-candidates"
+	// thumbnailCandidateURLExpiry 候选缩略图预签名URL有效期
+	thumbnailCandidateURLExpiry = time.Hour
+	// maxCustomThumbnailSize 自定义封面图片的最大大小
+	maxCustomThumbnailSize = 10 << 20
+)
+
+// thumbnailCandidatePrefix 视频候选缩略图的存储前缀
+func thumbnailCandidatePrefix(videoID string) string {
+	return path.Join(thumbnailCandidateRootPrefix, videoID) + "/"
+}
+
+// thumbnailCandidateObjectName 候选缩略图的对象名
+func thumbnailCandidateObjectName(videoID string, index int) string {
+	return fmt.Sprintf("%s%d.jpg", thumbnailCandidatePrefix(videoID), index)
+}
+
+// customThumbnailObjectName 更换后的缩略图对象名，每次更换使用新路径，避免客户端缓存旧图片
+func customThumbnailObjectName(videoID string, now time.Time) string {
+	return fmt.Sprintf("thumbnails/%d/%02d/%s-%d.jpg", now.Year(), now.Month(), videoID, now.UnixMilli())
+}
+
+// thumbnailOptions 缩略图统一使用的生成选项
+func thumbnailOptions() *video.ThumbnailOptions {
+	return &video.ThumbnailOptions{
+		Width:      thumbnailWidth,
+		Height:     thumbnailHeight,
+		Quality:    80,
+		Format:     "jpeg",
+		KeepAspect: true,
+	}
+}
+
+// GenerateThumbnailCandidates 在视频时长的10%/25%/50%/75%处截取候选缩略图，重新生成时覆盖上一批候选
+func (s *VideoService) GenerateThumbnailCandidates(ctx context.Context, req *api.ThumbnailCandidatesRequest) (*api.ThumbnailCandidatesResponse, error) {
+	meta, errResp := s.thumbnailTargetVideo(ctx, req.VideoID)
+	if errResp != nil {
+		return &api.ThumbnailCandidatesResponse{Base: errResp.Base}, nil
+	}
+	if !s.thumbnailGenerator.CanExtractFrames() {
+		return s.thumbnailCandidatesErrorResponse(4705, "视频帧提取器不可用，无法生成候选缩略图"), nil
+	}
+
+	meta, err := s.restoreArchivedVideo(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+	videoPath, err := s.downloadVideoToTemp(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+	defer s.tempFiles.Remove(videoPath)
+
+	results, err := s.thumbnailGenerator.GenerateCandidates(&video.CandidateRequest{
+		VideoPath: videoPath,
+		Duration:  float64(meta.Duration),
+		Options:   thumbnailOptions(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("生成候选缩略图失败: %w", err)
+	}
+
+	// 先删除上一批候选，避免残留的候选序号可以被选择
+	bucketName := s.buckets.Bucket(storage.ContentThumbnails)
+	if err := s.deleteThumbnailCandidates(ctx, meta.FileID); err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*api.ThumbnailCandidate, 0, len(results))
+	for i, result := range results {
+		objectName := thumbnailCandidateObjectName(meta.FileID, i)
+		if _, err := s.storageClient.UploadStream(ctx, bucketName, objectName,
+			bytes.NewReader(result.ImageData), result.FileSize, "image/jpeg"); err != nil {
+			return nil, fmt.Errorf("上传候选缩略图失败: %w", err)
+		}
+		url, err := s.presignedURL(ctx, bucketName, objectName, thumbnailCandidateURLExpiry)
+		if err != nil {
+			return nil, fmt.Errorf("生成候选缩略图URL失败: %w", err)
+		}
+		candidates = append(candidates, &api.ThumbnailCandidate{
+			Index:      int32(i),
+			Percent:    int32(video.DefaultCandidatePercents[i]),
+			TimeOffset: result.TimeOffset,
+			URL:        url,
+		})
+	}
+
+	return &api.ThumbnailCandidatesResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "已生成候选缩略图",
+		},
+		Candidates: candidates,
+	}, nil
+}
+
+// SelectThumbnail 选择一张候选缩略图作为视频缩略图
+func (s *VideoService) SelectThumbnail(ctx context.Context, req *api.ThumbnailSelectRequest) (*api.ThumbnailUpdateResponse, error) {
+	meta, errResp := s.thumbnailTargetVideo(ctx, req.VideoID)
+	if errResp != nil {
+		return errResp, nil
+	}
+	if req.Index < 0 || int(req.Index) >= len(video.DefaultCandidatePercents) {
+		return s.thumbnailUpdateErrorResponse(4701, "候选缩略图序号无效"), nil
+	}
+
+	bucketName := s.buckets.Bucket(storage.ContentThumbnails)
+	objectName := thumbnailCandidateObjectName(meta.FileID, int(req.Index))
+	exists, err := s.storageClient.FileExists(ctx, bucketName, objectName)
+	if err != nil {
+		return nil, fmt.Errorf("检查候选缩略图失败: %w", err)
+	}
+	if !exists {
+		return s.thumbnailUpdateErrorResponse(4704, "候选缩略图不存在，请先生成候选缩略图"), nil
+	}
+	data, err := s.storageClient.DownloadFile(ctx, bucketName, objectName)
+	if err != nil {
+		return nil, fmt.Errorf("读取候选缩略图失败: %w", err)
+	}
+
+	return s.replaceThumbnail(ctx, meta, data)
+}
+
+// UploadCustomThumbnail 上传自定义封面作为视频缩略图，图片按缩略图尺寸和缩放模式缩放后重新编码为JPEG
+func (s *VideoService) UploadCustomThumbnail(ctx context.Context, req *api.ThumbnailUploadRequest, fileHeader *multipart.FileHeader) (*api.ThumbnailUpdateResponse, error) {
+	meta, errResp := s.thumbnailTargetVideo(ctx, req.VideoID)
+	if errResp != nil {
+		return errResp, nil
+	}
+	if fileHeader == nil {
+		return s.thumbnailUpdateErrorResponse(4701, "封面图片不能为空"), nil
+	}
+	if !video.IsValidFit(req.Fit) {
+		return s.thumbnailUpdateErrorResponse(4701, fmt.Sprintf("不支持的缩放模式: %s", req.Fit)), nil
+	}
+	if fileHeader.Size > maxCustomThumbnailSize {
+		return s.thumbnailUpdateErrorResponse(4701, fmt.Sprintf("封面图片不能超过%dMB", maxCustomThumbnailSize>>20)), nil
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("打开封面图片失败: %w", err)
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxCustomThumbnailSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取封面图片失败: %w", err)
+	}
+	if len(data) > maxCustomThumbnailSize {
+		return s.thumbnailUpdateErrorResponse(4701, fmt.Sprintf("封面图片不能超过%dMB", maxCustomThumbnailSize>>20)), nil
+	}
+
+	options := thumbnailOptions()
+	options.Fit = req.Fit
+	result, err := s.thumbnailGenerator.GenerateFromImage(data, options)
+	if err != nil {
+		return s.thumbnailUpdateErrorResponse(4706, err.Error()), nil
+	}
+
+	return s.replaceThumbnail(ctx, meta, result.ImageData)
+}
+
+// thumbnailTargetVideo 获取要更换缩略图的视频并校验归属，失败时返回错误响应
+func (s *VideoService) thumbnailTargetVideo(ctx context.Context, videoID string) (*metadata.FileMetadata, *api.ThumbnailUpdateResponse) {
+	if videoID == "" {
+		return nil, s.thumbnailUpdateErrorResponse(4701, "视频ID不能为空")
+	}
+	meta, err := s.metadataService.GetMetadata(ctx, videoID)
+	if err != nil {
+		return nil, s.thumbnailUpdateErrorResponse(4702, "视频不存在")
+	}
+	if !canModifyVideo(ctx, meta) {
+		return nil, s.thumbnailUpdateErrorResponse(4703, "无权修改其他用户的视频")
+	}
+	return meta, nil
+}
+
+// replaceThumbnail 将图片保存为视频的新缩略图并删除原缩略图
+func (s *VideoService) replaceThumbnail(ctx context.Context, meta *metadata.FileMetadata, data []byte) (*api.ThumbnailUpdateResponse, error) {
+	bucketName := s.buckets.Bucket(storage.ContentThumbnails)
+	objectName := customThumbnailObjectName(meta.FileID, time.Now())
+	if _, err := s.storageClient.UploadStream(ctx, bucketName, objectName,
+		bytes.NewReader(data), int64(len(data)), "image/jpeg"); err != nil {
+		return nil, fmt.Errorf("上传缩略图失败: %w", err)
+	}
+
+	if err := s.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{
+		FileID:    meta.FileID,
+		Thumbnail: &objectName,
+	}); err != nil {
+		s.storageClient.DeleteFile(ctx, bucketName, objectName)
+		return nil, fmt.Errorf("更新视频元数据失败: %w", err)
+	}
+
+	if meta.Thumbnail != "" && meta.Thumbnail != objectName {
+		if err := s.storageClient.DeleteFile(ctx, bucketName, meta.Thumbnail); err != nil {
+			fmt.Printf("删除原缩略图失败(%s, request_id=%s): %v\n", meta.FileID, middleware.RequestIDFromContext(ctx), err)
+		}
+		s.invalidatePresignedURLs(ctx, bucketName, meta.Thumbnail)
+	}
+
+	updated, err := s.metadataService.GetMetadata(ctx, meta.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("获取视频元数据失败: %w", err)
+	}
+	apiVideo := convertToAPIVideo(updated)
+	s.publishEvent(notify.EventThumbnailReady, updated, apiVideo)
+
+	return &api.ThumbnailUpdateResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "缩略图已更新",
+		},
+		Video: apiVideo,
+	}, nil
+}
+
+// deleteThumbnailCandidates 删除视频的全部候选缩略图
+func (s *VideoService) deleteThumbnailCandidates(ctx context.Context, videoID string) error {
+	bucketName := s.buckets.Bucket(storage.ContentThumbnails)
+	files, err := s.storageClient.ListFiles(ctx, bucketName, thumbnailCandidatePrefix(videoID))
+	if err != nil {
+		return fmt.Errorf("列出候选缩略图失败: %w", err)
+	}
+	for _, file := range files {
+		if err := s.storageClient.DeleteFile(ctx, bucketName, file.Key); err != nil {
+			return fmt.Errorf("删除候选缩略图失败: %w", err)
+		}
+		s.invalidatePresignedURLs(ctx, bucketName, file.Key)
+	}
+	return nil
+}
+
+// thumbnailCandidatesErrorResponse 创建候选缩略图错误响应
+func (s *VideoService) thumbnailCandidatesErrorResponse(code int32, message string) *api.ThumbnailCandidatesResponse {
+	return &api.ThumbnailCandidatesResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// thumbnailUpdateErrorResponse 创建更换缩略图错误响应
+func (s *VideoService) thumbnailUpdateErrorResponse(code int32, message string) *api.ThumbnailUpdateResponse {
+	return &api.ThumbnailUpdateResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/notify"
+	"github.com/manteia/zhulong/pkg/user"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+// pngTestData 生成指定尺寸的PNG图片
+func pngTestData(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestVideoService_ThumbnailCandidates(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("生成候选并选择", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		uploaded := uploadTestVideo(t, service, "candidates.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+		duration := int64(stubVideoDuration)
+		require.NoError(t, service.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{
+			FileID:   uploaded.Video.ID,
+			Duration: &duration,
+		}))
+		service.thumbnailGenerator.SetFrameExtractor(&stubFrameExtractor{available: true})
+
+		resp, err := service.GenerateThumbnailCandidates(ctx, &api.ThumbnailCandidatesRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		require.Len(t, resp.Candidates, 4)
+		for i, percent := range []int32{10, 25, 50, 75} {
+			candidate := resp.Candidates[i]
+			assert.Equal(t, int32(i), candidate.Index)
+			assert.Equal(t, percent, candidate.Percent)
+			assert.Equal(t, float64(stubVideoDuration)*float64(percent)/100, candidate.TimeOffset)
+			assert.Contains(t, candidate.URL, "thumbnail-candidates/"+uploaded.Video.ID+"/")
+		}
+		assert.Equal(t, 4, countObjects(t, store, "thumbnail-candidates/"+uploaded.Video.ID+"/"))
+
+		events := service.SubscribeNotifications(claimsContext("admin-1", user.RoleAdmin))
+		defer events.Close()
+		selected, err := service.SelectThumbnail(ctx, &api.ThumbnailSelectRequest{VideoID: uploaded.Video.ID, Index: 2})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), selected.Base.Code, selected.Base.Message)
+		assert.NotEqual(t, uploaded.Video.ThumbnailPath, selected.Video.ThumbnailPath, "更换缩略图应该使用新路径")
+		assert.Equal(t, testObjectData(t, store, "thumbnail-candidates/"+uploaded.Video.ID+"/2.jpg"), testObjectData(t, store, selected.Video.ThumbnailPath))
+		assert.NotContains(t, testObjectNames(t, store, ""), uploaded.Video.ThumbnailPath, "原缩略图应该被删除")
+		assert.Equal(t, 1, countObjects(t, store, "thumbnails/"))
+
+		event := <-events.C
+		assert.Equal(t, notify.EventThumbnailReady, event.Type)
+		assert.Equal(t, uploaded.Video.ID, event.VideoID)
+
+		meta, err := service.metadataService.GetMetadata(ctx, uploaded.Video.ID)
+		require.NoError(t, err)
+		objects, err := service.collectVideoObjects(ctx, meta)
+		require.NoError(t, err)
+		names := bucketObjectNames(objects, "zhulong-videos")
+		assert.Contains(t, names, selected.Video.ThumbnailPath)
+		assert.Contains(t, names, "thumbnail-candidates/"+uploaded.Video.ID+"/0.jpg", "删除视频时应该一并删除候选缩略图")
+	})
+
+	t.Run("重新生成时替换上一批候选", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		uploaded := uploadTestVideo(t, service, "regenerate.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+		service.thumbnailGenerator.SetFrameExtractor(&stubFrameExtractor{available: true})
+
+		// 时长未知时按5秒间隔截取，超出模拟时长的位置没有视频帧
+		resp, err := service.GenerateThumbnailCandidates(ctx, &api.ThumbnailCandidatesRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		require.Len(t, resp.Candidates, 3)
+
+		putTestObject(t, store, "thumbnail-candidates/"+uploaded.Video.ID+"/3.jpg", []byte("stale"))
+		resp, err = service.GenerateThumbnailCandidates(ctx, &api.ThumbnailCandidatesRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, 3, countObjects(t, store, "thumbnail-candidates/"))
+
+		selected, err := service.SelectThumbnail(ctx, &api.ThumbnailSelectRequest{VideoID: uploaded.Video.ID, Index: 3})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4704), selected.Base.Code, "上一批残留的候选不能被选择")
+	})
+
+	t.Run("帧提取器不可用", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+		uploaded := uploadTestVideo(t, service, "mock.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+
+		resp, err := service.GenerateThumbnailCandidates(ctx, &api.ThumbnailCandidatesRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4705), resp.Base.Code)
+
+		selected, err := service.SelectThumbnail(ctx, &api.ThumbnailSelectRequest{VideoID: uploaded.Video.ID, Index: 0})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4704), selected.Base.Code)
+
+		selected, err = service.SelectThumbnail(ctx, &api.ThumbnailSelectRequest{VideoID: uploaded.Video.ID, Index: 4})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4701), selected.Base.Code)
+	})
+}
+
+func TestVideoService_UploadCustomThumbnail(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("上传自定义封面", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		uploaded := uploadTestVideo(t, service, "cover.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+
+		resp, err := service.UploadCustomThumbnail(ctx, &api.ThumbnailUploadRequest{VideoID: uploaded.Video.ID},
+			createTestFileHeader(t, "cover.png", "image/png", pngTestData(t, 1280, 720)))
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.NotContains(t, testObjectNames(t, store, ""), uploaded.Video.ThumbnailPath, "原缩略图应该被删除")
+
+		img, err := jpeg.Decode(bytes.NewReader(testObjectData(t, store, resp.Video.ThumbnailPath)))
+		require.NoError(t, err, "封面应该重新编码为JPEG")
+		assert.Equal(t, thumbnailWidth, img.Bounds().Dx())
+		assert.Equal(t, 180, img.Bounds().Dy(), "应该保持封面的宽高比")
+
+		resp, err = service.UploadCustomThumbnail(ctx, &api.ThumbnailUploadRequest{VideoID: uploaded.Video.ID, Fit: video.FitCover},
+			createTestFileHeader(t, "cover.png", "image/png", pngTestData(t, 1280, 720)))
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		img, err = jpeg.Decode(bytes.NewReader(testObjectData(t, store, resp.Video.ThumbnailPath)))
+		require.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, thumbnailWidth, thumbnailHeight), img.Bounds(), "cover模式应该裁剪到缩略图尺寸")
+	})
+
+	t.Run("无效图片", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		uploaded := uploadTestVideo(t, service, "invalid.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+
+		resp, err := service.UploadCustomThumbnail(ctx, &api.ThumbnailUploadRequest{VideoID: uploaded.Video.ID},
+			createTestFileHeader(t, "cover.gif", "image/gif", []byte("GIF89a")))
+		require.NoError(t, err)
+		assert.Equal(t, int32(4706), resp.Base.Code)
+		assert.Contains(t, testObjectNames(t, store, ""), uploaded.Video.ThumbnailPath, "失败时保留原缩略图")
+
+		resp, err = service.UploadCustomThumbnail(ctx, &api.ThumbnailUploadRequest{VideoID: uploaded.Video.ID}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int32(4701), resp.Base.Code)
+
+		resp, err = service.UploadCustomThumbnail(ctx, &api.ThumbnailUploadRequest{VideoID: uploaded.Video.ID, Fit: "fill"},
+			createTestFileHeader(t, "cover.png", "image/png", pngTestData(t, 320, 180)))
+		require.NoError(t, err)
+		assert.Equal(t, int32(4701), resp.Base.Code, "不支持的缩放模式")
+	})
+
+	t.Run("参数和权限", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+		aliceCtx := user.ContextWithClaims(ctx, &user.Claims{UserID: "alice", Role: user.RoleUploader})
+		bobCtx := user.ContextWithClaims(ctx, &user.Claims{UserID: "bob", Role: user.RoleUploader})
+		uploaded, err := service.UploadVideo(aliceCtx, &api.VideoUploadRequest{Title: "alice的视频"}, createTestFileHeader(t, "alice.mp4", "video/mp4", mp4TestData(2048)))
+		require.NoError(t, err)
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+		cover := func() *api.ThumbnailUploadRequest {
+			return &api.ThumbnailUploadRequest{VideoID: uploaded.Video.ID}
+		}
+
+		resp, err := service.UploadCustomThumbnail(ctx, &api.ThumbnailUploadRequest{}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int32(4701), resp.Base.Code)
+
+		resp, err = service.UploadCustomThumbnail(ctx, &api.ThumbnailUploadRequest{VideoID: "missing"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int32(4702), resp.Base.Code)
+
+		fileHeader := createTestFileHeader(t, "cover.png", "image/png", pngTestData(t, 320, 180))
+		resp, err = service.UploadCustomThumbnail(bobCtx, cover(), fileHeader)
+		require.NoError(t, err)
+		assert.Equal(t, int32(4703), resp.Base.Code, "上传者不能修改其他用户的视频")
+
+		candidates, err := service.GenerateThumbnailCandidates(bobCtx, &api.ThumbnailCandidatesRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4703), candidates.Base.Code)
+
+		resp, err = service.UploadCustomThumbnail(aliceCtx, cover(), fileHeader)
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+	})
+}
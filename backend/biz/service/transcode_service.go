@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/transcode"
+)
+
+// onDemandTranscodePriority 播放时按需打包的优先级，有用户在等待，先于上传后自动打包执行
+const onDemandTranscodePriority = 10
+
+// transcodeStatuses 可用于过滤的转码任务状态
+var transcodeStatuses = []string{
+	transcode.StatusPending,
+	transcode.StatusRunning,
+	transcode.StatusRetrying,
+	transcode.StatusSucceeded,
+	transcode.StatusCanceled,
+	transcode.StatusDead,
+}
+
+// startTranscodeQueue 创建转码队列：远程worker模式下任务由worker领取，否则在启用HLS打包时由本进程执行
+func (s *VideoService) startTranscodeQueue() error {
+	if s.config.IsRemoteWorkerMode() {
+		leaseTimeout, err := s.config.GetWorkerLeaseTimeout()
+		if err != nil {
+			return fmt.Errorf("解析worker租约时长失败: %w", err)
+		}
+		s.transcodeQueue = transcode.NewRemoteQueue(s.config.Streaming.MaxAttempts, leaseTimeout)
+		s.transcodeQueue.SetFinishHandler(s.handleJobFinished)
+		return nil
+	}
+	if s.hlsPackager == nil {
+		return nil
+	}
+	s.transcodeQueue = transcode.NewQueue(s.processJob, s.config.Streaming.Workers, s.config.Streaming.MaxAttempts)
+	s.transcodeQueue.SetFinishHandler(s.handleJobFinished)
+	return nil
+}
+
+// processJob 在本进程执行转码队列中的任务
+func (s *VideoService) processJob(ctx context.Context, job transcode.Job) error {
+	if job.Kind == transcode.KindPreview {
+		return s.generateQueuedPreviews(ctx, job)
+	}
+	return s.packageVideo(ctx, job)
+}
+
+// hlsPackagingAvailable 是否可以创建HLS打包任务，远程worker模式下由worker负责切片
+func (s *VideoService) hlsPackagingAvailable() bool {
+	if s.transcodeQueue == nil || s.hlsPackager == nil {
+		return false
+	}
+	return s.config.IsRemoteWorkerMode() || s.hlsPackager.IsAvailable()
+}
+
+// previewGenerationAvailable 是否可以生成预览图，远程worker模式下由worker负责截帧
+func (s *VideoService) previewGenerationAvailable() bool {
+	if s.config.IsRemoteWorkerMode() {
+		return s.transcodeQueue != nil
+	}
+	return s.thumbnailGenerator.CanExtractFrames()
+}
+
+// isRunningJob 视频的指定类型任务是否正在执行
+func (s *VideoService) isRunningJob(kind, videoID string) bool {
+	if s.transcodeQueue == nil {
+		return false
+	}
+	job, ok := s.transcodeQueue.ActiveJob(kind, videoID)
+	return ok && job.Status == transcode.StatusRunning
+}
+
+// isPackagingVideo 视频是否正在执行转码任务
+func (s *VideoService) isPackagingVideo(videoID string) bool {
+	if s.isRunningJob(transcode.KindHLS, videoID) {
+		return true
+	}
+	return s.hlsPackager != nil && s.hlsPackager.IsPackaging(videoID)
+}
+
+// cancelPendingTranscode 取消视频等待执行或等待重试的转码和预览图任务
+func (s *VideoService) cancelPendingTranscode(videoID string) {
+	if s.transcodeQueue == nil {
+		return
+	}
+	for _, kind := range []string{transcode.KindHLS, transcode.KindPreview} {
+		if job, ok := s.transcodeQueue.ActiveJob(kind, videoID); ok && job.Status != transcode.StatusRunning {
+			_, _ = s.transcodeQueue.Cancel(job.ID)
+		}
+	}
+}
+
+// CreateTranscodeJob 为视频创建转码或预览图任务，视频已有未完成的同类任务时返回该任务
+func (s *VideoService) CreateTranscodeJob(ctx context.Context, req *api.TranscodeJobCreateRequest) (*api.TranscodeJobResponse, error) {
+	if req.VideoID == "" {
+		return s.transcodeJobErrorResponse(6801, "视频ID不能为空"), nil
+	}
+	kind := getValueOrDefault(req.Kind, transcode.KindHLS)
+	switch kind {
+	case transcode.KindHLS:
+		if !s.hlsPackagingAvailable() {
+			return s.transcodeJobErrorResponse(6802, "转码未启用或切片器不可用"), nil
+		}
+	case transcode.KindPreview:
+		if s.transcodeQueue == nil || !s.previewGenerationAvailable() {
+			return s.transcodeJobErrorResponse(6802, "转码未启用或帧提取器不可用"), nil
+		}
+	default:
+		return s.transcodeJobErrorResponse(6801, transcode.ErrInvalidKind.Error()), nil
+	}
+
+	meta, err := s.metadataService.GetMetadata(ctx, req.VideoID)
+	if err != nil {
+		return s.transcodeJobErrorResponse(6803, "视频不存在"), nil
+	}
+
+	job, err := s.transcodeQueue.Enqueue(kind, meta.FileID, int(req.Priority))
+	if err != nil {
+		return s.transcodeJobQueueErrorResponse(job, err), nil
+	}
+	s.resumeProcessing(job)
+	return s.transcodeJobResponse(job, "转码任务已创建"), nil
+}
+
+// ListTranscodeJobs 列出转码任务，未结束的任务按执行顺序排在前面
+func (s *VideoService) ListTranscodeJobs(ctx context.Context, req *api.TranscodeJobListRequest) (*api.TranscodeJobListResponse, error) {
+	status := getValueOrDefault(req.Status, "")
+	if status != "" && !slices.Contains(transcodeStatuses, status) {
+		return &api.TranscodeJobListResponse{
+			Base: &api.BaseResponse{
+				Code:    6801,
+				Message: fmt.Sprintf("无效的任务状态: %s", status),
+			},
+			Jobs: []*api.TranscodeJob{},
+		}, nil
+	}
+
+	jobs := []*api.TranscodeJob{}
+	if s.transcodeQueue != nil {
+		for _, job := range s.transcodeQueue.List(getValueOrDefault(req.VideoID, ""), status) {
+			jobs = append(jobs, convertTranscodeJob(job))
+		}
+	}
+
+	return &api.TranscodeJobListResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Jobs: jobs,
+	}, nil
+}
+
+// GetTranscodeJob 获取转码任务
+func (s *VideoService) GetTranscodeJob(ctx context.Context, req *api.TranscodeJobRequest) (*api.TranscodeJobResponse, error) {
+	return s.updateTranscodeJob(req.JobID, "获取成功", func(jobID string) (transcode.Job, error) {
+		return s.transcodeQueue.Get(jobID)
+	})
+}
+
+// UpdateTranscodeJobPriority 修改等待执行或等待重试的转码任务的优先级
+func (s *VideoService) UpdateTranscodeJobPriority(ctx context.Context, req *api.TranscodeJobPriorityRequest) (*api.TranscodeJobResponse, error) {
+	return s.updateTranscodeJob(req.JobID, "优先级已更新", func(jobID string) (transcode.Job, error) {
+		return s.transcodeQueue.SetPriority(jobID, int(req.Priority))
+	})
+}
+
+// CancelTranscodeJob 取消未结束的转码任务，正在执行的任务会被中断
+func (s *VideoService) CancelTranscodeJob(ctx context.Context, req *api.TranscodeJobRequest) (*api.TranscodeJobResponse, error) {
+	return s.updateTranscodeJob(req.JobID, "转码任务已取消", func(jobID string) (transcode.Job, error) {
+		return s.transcodeQueue.Cancel(jobID)
+	})
+}
+
+// RequeueTranscodeJob 将已取消或进入死信状态的转码任务重新排队，等待重试的任务立即执行
+func (s *VideoService) RequeueTranscodeJob(ctx context.Context, req *api.TranscodeJobRequest) (*api.TranscodeJobResponse, error) {
+	return s.updateTranscodeJob(req.JobID, "转码任务已重新排队", func(jobID string) (transcode.Job, error) {
+		job, err := s.transcodeQueue.Requeue(jobID)
+		if err == nil {
+			s.resumeProcessing(job)
+		}
+		return job, err
+	})
+}
+
+// updateTranscodeJob 对转码任务执行队列操作并转换结果
+func (s *VideoService) updateTranscodeJob(jobID, message string, operate func(jobID string) (transcode.Job, error)) (*api.TranscodeJobResponse, error) {
+	if jobID == "" {
+		return s.transcodeJobErrorResponse(6801, "任务ID不能为空"), nil
+	}
+	if s.transcodeQueue == nil {
+		return s.transcodeJobErrorResponse(6802, "转码未启用"), nil
+	}
+
+	job, err := operate(jobID)
+	if err != nil {
+		return s.transcodeJobQueueErrorResponse(job, err), nil
+	}
+	return s.transcodeJobResponse(job, message), nil
+}
+
+// transcodeJobQueueErrorResponse 将转码队列错误转换为错误响应，任务存在时一并返回
+func (s *VideoService) transcodeJobQueueErrorResponse(job transcode.Job, err error) *api.TranscodeJobResponse {
+	var resp *api.TranscodeJobResponse
+	switch {
+	case errors.Is(err, transcode.ErrJobNotFound):
+		return s.transcodeJobErrorResponse(6804, "转码任务不存在")
+	case errors.Is(err, transcode.ErrJobActive):
+		resp = s.transcodeJobErrorResponse(6805, err.Error())
+	case errors.Is(err, transcode.ErrInvalidState):
+		resp = s.transcodeJobErrorResponse(6806, fmt.Sprintf("%v: %s", err, job.Status))
+	default:
+		return s.transcodeJobErrorResponse(6801, err.Error())
+	}
+	resp.Job = convertTranscodeJob(job)
+	return resp
+}
+
+// transcodeJobResponse 创建转码任务成功响应
+func (s *VideoService) transcodeJobResponse(job transcode.Job, message string) *api.TranscodeJobResponse {
+	return &api.TranscodeJobResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: message,
+		},
+		Job: convertTranscodeJob(job),
+	}
+}
+
+// convertTranscodeJob 转换转码任务
+func convertTranscodeJob(job transcode.Job) *api.TranscodeJob {
+	apiJob := &api.TranscodeJob{
+		JobID:       job.ID,
+		VideoID:     job.VideoID,
+		Priority:    int32(job.Priority),
+		Status:      job.Status,
+		Attempts:    int32(job.Attempts),
+		MaxAttempts: int32(job.MaxAttempts),
+		CreatedAt:   job.CreatedAt.UnixMilli(),
+		UpdatedAt:   job.UpdatedAt.UnixMilli(),
+		Kind:        job.Kind,
+	}
+	if job.Error != "" {
+		apiJob.Error = &job.Error
+	}
+	if job.Worker != "" {
+		apiJob.Worker = &job.Worker
+	}
+	if !job.StartedAt.IsZero() {
+		startedAt := job.StartedAt.UnixMilli()
+		apiJob.StartedAt = &startedAt
+	}
+	if !job.FinishedAt.IsZero() {
+		finishedAt := job.FinishedAt.UnixMilli()
+		apiJob.FinishedAt = &finishedAt
+	}
+	if !job.NextAttemptAt.IsZero() {
+		nextAttemptAt := job.NextAttemptAt.UnixMilli()
+		apiJob.NextAttemptAt = &nextAttemptAt
+	}
+	if !job.LeaseExpiresAt.IsZero() {
+		leaseExpiresAt := job.LeaseExpiresAt.UnixMilli()
+		apiJob.LeaseExpiresAt = &leaseExpiresAt
+	}
+	return apiJob
+}
+
+// transcodeJobErrorResponse 创建转码任务错误响应
+func (s *VideoService) transcodeJobErrorResponse(code int32, message string) *api.TranscodeJobResponse {
+	return &api.TranscodeJobResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
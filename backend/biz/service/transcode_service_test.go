@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/streaming"
+	"github.com/manteia/zhulong/pkg/transcode"
+)
+
+func TestVideoService_TranscodeJobs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("转码未启用", func(t *testing.T) {
+		service := createStreamTestService(t)
+
+		resp, err := service.CreateTranscodeJob(ctx, &api.TranscodeJobCreateRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6802), resp.Base.Code)
+
+		resp, err = service.CreateTranscodeJob(ctx, &api.TranscodeJobCreateRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6801), resp.Base.Code)
+
+		resp, err = service.GetTranscodeJob(ctx, &api.TranscodeJobRequest{JobID: "job1"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6802), resp.Base.Code)
+
+		listResp, err := service.ListTranscodeJobs(ctx, &api.TranscodeJobListRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), listResp.Base.Code)
+		assert.Empty(t, listResp.Jobs)
+	})
+
+	t.Run("切片器不可用时不能创建任务", func(t *testing.T) {
+		service := createStreamTestService(t)
+		service.hlsPackager = streaming.NewHLSPackager(service.storageClient, streaming.NewFFmpegSegmenter("/nonexistent/ffmpeg"), 6, nil)
+		require.NoError(t, service.startTranscodeQueue())
+		t.Cleanup(service.transcodeQueue.Close)
+
+		resp, err := service.CreateTranscodeJob(ctx, &api.TranscodeJobCreateRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6802), resp.Base.Code)
+	})
+
+	t.Run("修改优先级、取消和重新排队", func(t *testing.T) {
+		service := createStreamTestService(t)
+		// 任务一直执行到被取消
+		service.transcodeQueue = transcode.NewQueue(func(ctx context.Context, job transcode.Job) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, 1, 1)
+		t.Cleanup(service.transcodeQueue.Close)
+
+		running, err := service.transcodeQueue.Enqueue(transcode.KindHLS, "video1", transcode.DefaultPriority)
+		require.NoError(t, err)
+		pending, err := service.transcodeQueue.Enqueue(transcode.KindHLS, "video2", transcode.DefaultPriority)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			return service.isPackagingVideo("video1")
+		}, time.Second, 10*time.Millisecond)
+
+		resp, err := service.UpdateTranscodeJobPriority(ctx, &api.TranscodeJobPriorityRequest{JobID: pending.ID, Priority: 50})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code)
+		assert.Equal(t, int32(50), resp.Job.Priority)
+
+		resp, err = service.UpdateTranscodeJobPriority(ctx, &api.TranscodeJobPriorityRequest{JobID: pending.ID, Priority: 101})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6801), resp.Base.Code, "优先级超出范围应该返回6801")
+
+		resp, err = service.UpdateTranscodeJobPriority(ctx, &api.TranscodeJobPriorityRequest{JobID: running.ID, Priority: 50})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6806), resp.Base.Code, "正在执行的任务不能修改优先级")
+		assert.Equal(t, transcode.StatusRunning, resp.Job.Status)
+
+		listResp, err := service.ListTranscodeJobs(ctx, &api.TranscodeJobListRequest{})
+		require.NoError(t, err)
+		require.Len(t, listResp.Jobs, 2)
+		assert.Equal(t, running.ID, listResp.Jobs[0].JobID, "正在执行的任务应该排在前面")
+		assert.NotNil(t, listResp.Jobs[0].StartedAt)
+
+		resp, err = service.CancelTranscodeJob(ctx, &api.TranscodeJobRequest{JobID: pending.ID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code)
+		assert.Equal(t, transcode.StatusCanceled, resp.Job.Status)
+		assert.NotNil(t, resp.Job.FinishedAt)
+
+		resp, err = service.CancelTranscodeJob(ctx, &api.TranscodeJobRequest{JobID: pending.ID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6806), resp.Base.Code, "已取消的任务不能再次取消")
+
+		resp, err = service.RequeueTranscodeJob(ctx, &api.TranscodeJobRequest{JobID: pending.ID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code)
+		assert.Equal(t, transcode.StatusPending, resp.Job.Status)
+
+		listResp, err = service.ListTranscodeJobs(ctx, &api.TranscodeJobListRequest{Status: stringPtr(transcode.StatusPending)})
+		require.NoError(t, err)
+		require.Len(t, listResp.Jobs, 1)
+		assert.Equal(t, pending.ID, listResp.Jobs[0].JobID)
+
+		listResp, err = service.ListTranscodeJobs(ctx, &api.TranscodeJobListRequest{Status: stringPtr("unknown")})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6801), listResp.Base.Code)
+	})
+
+	t.Run("任务不存在", func(t *testing.T) {
+		service := createStreamTestService(t)
+		service.transcodeQueue = transcode.NewQueue(func(ctx context.Context, job transcode.Job) error {
+			return nil
+		}, 1, 1)
+		t.Cleanup(service.transcodeQueue.Close)
+
+		resp, err := service.GetTranscodeJob(ctx, &api.TranscodeJobRequest{JobID: "missing"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6804), resp.Base.Code)
+		assert.Nil(t, resp.Job)
+
+		resp, err = service.CancelTranscodeJob(ctx, &api.TranscodeJobRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6801), resp.Base.Code)
+	})
+}
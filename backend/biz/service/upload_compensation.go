@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/manteia/zhulong/pkg/middleware"
+)
+
+// uploadCompensation 上传完成处理中已执行步骤的撤销操作
+// 保存元数据失败时按相反顺序执行，避免视频库中留下没有元数据的存储对象或审核记录
+type uploadCompensation struct {
+	steps []compensationStep
+}
+
+// compensationStep 撤销操作
+type compensationStep struct {
+	name string
+	undo func(ctx context.Context) error
+}
+
+// add 记录已完成步骤的撤销操作
+func (c *uploadCompensation) add(name string, undo func(ctx context.Context) error) {
+	c.steps = append(c.steps, compensationStep{name: name, undo: undo})
+}
+
+// run 按相反顺序执行撤销操作，单个操作失败时记录日志并继续
+// 请求被取消时仍然需要清理，撤销操作使用不随请求取消的上下文
+func (c *uploadCompensation) run(ctx context.Context) {
+	cleanupCtx := context.WithoutCancel(ctx)
+	for i := len(c.steps) - 1; i >= 0; i-- {
+		step := c.steps[i]
+		if err := step.undo(cleanupCtx); err != nil {
+			fmt.Printf("撤销%s失败(request_id=%s): %v\n", step.name, middleware.RequestIDFromContext(ctx), err)
+		}
+	}
+	c.steps = nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/manteia/zhulong/pkg/storage"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/config"
+)
+
+// countObjects 统计测试存储桶中指定前缀的文件数量
+func countObjects(t *testing.T, store *storage.MemoryStorage, prefix string) int {
+	return len(testObjectNames(t, store, prefix))
+}
+
+// TestVideoService_FinalizeUploadCompensation 测试保存元数据失败时清理已写入的文件
+func TestVideoService_FinalizeUploadCompensation(t *testing.T) {
+	ctx := context.Background()
+	// 标题超过元数据允许的长度，文件写入存储后保存元数据失败
+	longTitle := strings.Repeat("长", 100)
+
+	t.Run("表单上传", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+
+		resp, err := service.UploadVideo(ctx, &api.VideoUploadRequest{Title: longTitle}, createTestFileHeader(t, "clip.mp4", "video/mp4", mp4TestData(2048)))
+		require.NoError(t, err)
+		assert.Equal(t, int32(1013), resp.Base.Code)
+		assert.Contains(t, resp.Base.Message, "保存视频信息失败")
+
+		assert.Zero(t, countObjects(t, store, "videos/"), "应该删除已上传的视频文件")
+		assert.Zero(t, countObjects(t, store, "thumbnails/"), "应该删除已上传的缩略图")
+		assert.Zero(t, service.metadataService.Count(ctx))
+	})
+
+	t.Run("重复视频不删除共享的文件", func(t *testing.T) {
+		service, store := createDedupTestService(t, config.DeduplicationAlias)
+		data := mp4TestData(2048)
+		first := uploadTestVideo(t, service, "first.mp4", data)
+		require.Equal(t, int32(0), first.Base.Code, first.Base.Message)
+
+		resp, err := service.UploadVideo(ctx, &api.VideoUploadRequest{Title: longTitle}, createTestFileHeader(t, "second.mp4", "video/mp4", data))
+		require.NoError(t, err)
+		assert.Equal(t, int32(1013), resp.Base.Code)
+		assert.Contains(t, testObjectNames(t, store, ""), first.Video.StoragePath, "已有视频的文件应该保留")
+		assert.Equal(t, 1, countObjects(t, store, "thumbnails/"), "只删除本次上传的缩略图")
+	})
+
+	t.Run("直传确认", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		urlResp, err := service.CreateUploadURL(ctx, &api.VideoUploadURLRequest{Filename: "direct.mp4", Size: 2048, Title: longTitle})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), urlResp.Base.Code, urlResp.Base.Message)
+		session, err := service.directUploads.GetSession(urlResp.UploadToken)
+		require.NoError(t, err)
+		putTestObject(t, store, session.ObjectName, mp4TestData(2048))
+
+		resp, err := service.ConfirmUpload(ctx, &api.VideoUploadConfirmRequest{UploadToken: urlResp.UploadToken})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1013), resp.Base.Code)
+		assert.NotContains(t, testObjectNames(t, store, ""), session.ObjectName, "应该删除客户端上传的文件")
+		assert.Zero(t, countObjects(t, store, "thumbnails/"))
+		_, err = service.metadataService.GetMetadata(ctx, urlResp.VideoID)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/upload"
+)
+
+// SubscribeUploadProgress 订阅上传进度
+// 单文件上传和分片上传共用同一个进度注册表，上传开始前订阅会先收到等待状态
+func (s *VideoService) SubscribeUploadProgress(uploadID string) (*upload.ProgressSubscription, error) {
+	if err := upload.ValidateUploadID(uploadID); err != nil {
+		return nil, err
+	}
+	return s.progressRegistry.Subscribe(uploadID), nil
+}
+
+// ConvertUploadProgress 将上传进度转换为API格式
+func ConvertUploadProgress(progress *upload.UploadProgress) *api.UploadProgress {
+	return &api.UploadProgress{
+		UploadID:      progress.UploadID,
+		Percentage:    int32(progress.Percentage),
+		BytesUploaded: progress.BytesUploaded,
+		TotalBytes:    progress.TotalBytes,
+		Status:        progress.Status,
+		Error:         progress.Error,
+		UpdatedAt:     progress.UpdatedAt.UnixMilli(),
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/upload"
+)
+
+// createTestFileHeader 构造multipart表单中的上传文件
+func createTestFileHeader(t *testing.T, filename, contentType string, data []byte) *multipart.FileHeader {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="file"; filename="`+filename+`"`)
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(int64(len(data)) + 1024)
+	require.NoError(t, err)
+	t.Cleanup(func() { form.RemoveAll() })
+
+	return form.File["file"][0]
+}
+
+// collectProgress 读取订阅通道直到关闭，返回最后一条进度
+func collectProgress(sub *upload.ProgressSubscription) *upload.UploadProgress {
+	var last *upload.UploadProgress
+	for progress := range sub.C {
+		last = progress
+	}
+	return last
+}
+
+func TestVideoService_UploadProgress(t *testing.T) {
+	ctx := context.Background()
+	service, _ := createDirectUploadTestService(t)
+
+	t.Run("上传进度_先订阅后上传", func(t *testing.T) {
+		sub, err := service.SubscribeUploadProgress("client-upload-1")
+		require.NoError(t, err)
+		defer sub.Close()
+
+		fileHeader := createTestFileHeader(t, "progress.mp4", "video/mp4", mp4TestData(4096))
+		resp, err := service.UploadVideo(ctx, &api.VideoUploadRequest{UploadID: "client-upload-1"}, fileHeader)
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, "client-upload-1", resp.UploadID, "应该返回客户端指定的上传ID")
+
+		last := collectProgress(sub)
+		require.NotNil(t, last)
+		assert.Equal(t, upload.ProgressCompleted, last.Status, "上传完成后应该推送完成状态")
+		assert.Equal(t, int64(4096), last.BytesUploaded)
+		assert.Equal(t, 100, last.Percentage)
+	})
+
+	t.Run("上传进度_未指定上传ID时使用视频ID", func(t *testing.T) {
+		fileHeader := createTestFileHeader(t, "default.mp4", "video/mp4", mp4TestData(2048))
+		resp, err := service.UploadVideo(ctx, &api.VideoUploadRequest{}, fileHeader)
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, resp.Video.ID, resp.UploadID)
+
+		progress, ok := service.progressRegistry.Get(resp.UploadID)
+		require.True(t, ok)
+		assert.Equal(t, upload.ProgressCompleted, progress.Status)
+	})
+
+	t.Run("上传进度_上传失败推送失败原因", func(t *testing.T) {
+		fileHeader := createTestFileHeader(t, "notes.txt", "text/plain", []byte("not a video"))
+		resp, err := service.UploadVideo(ctx, &api.VideoUploadRequest{UploadID: "client-upload-2"}, fileHeader)
+		require.NoError(t, err)
+		assert.NotEqual(t, int32(0), resp.Base.Code)
+
+		sub, err := service.SubscribeUploadProgress("client-upload-2")
+		require.NoError(t, err)
+		last := collectProgress(sub)
+		require.NotNil(t, last)
+		assert.Equal(t, upload.ProgressFailed, last.Status)
+		assert.Equal(t, resp.Base.Message, last.Error, "失败原因应该与响应一致")
+	})
+
+	t.Run("上传进度_无效上传ID", func(t *testing.T) {
+		_, err := service.SubscribeUploadProgress("../progress")
+		assert.Error(t, err, "无效上传ID应该订阅失败")
+
+		fileHeader := createTestFileHeader(t, "invalid.mp4", "video/mp4", mp4TestData(1024))
+		resp, err := service.UploadVideo(ctx, &api.VideoUploadRequest{UploadID: "a/b"}, fileHeader)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1001), resp.Base.Code)
+	})
+}
+
+func TestConvertUploadProgress(t *testing.T) {
+	registry := upload.NewProgressRegistry()
+	registry.Start("upload-1", 200)
+	registry.Add("upload-1", 50)
+
+	progress, ok := registry.Get("upload-1")
+	require.True(t, ok)
+
+	result := ConvertUploadProgress(progress)
+	assert.Equal(t, "upload-1", result.UploadID)
+	assert.Equal(t, int32(25), result.Percentage)
+	assert.Equal(t, int64(50), result.BytesUploaded)
+	assert.Equal(t, int64(200), result.TotalBytes)
+	assert.Equal(t, upload.ProgressUploading, result.Status)
+	assert.Equal(t, progress.UpdatedAt.UnixMilli(), result.UpdatedAt)
+}
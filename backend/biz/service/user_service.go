@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// UserService 用户服务
+type UserService struct {
+	config       *config.Config
+	users        *user.UserService
+	tokenManager *user.TokenManager
+}
+
+// NewUserService 使用注入的配置创建用户服务
+func NewUserService(cfg *config.Config) (*UserService, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("配置不能为空")
+	}
+
+	expiry, err := cfg.GetJWTExpiry()
+	if err != nil {
+		return nil, fmt.Errorf("解析JWT有效期失败: %v", err)
+	}
+
+	secret := cfg.JWT.Secret
+	if secret == "" {
+		// 未配置密钥时使用随机密钥，重启后已签发的令牌将失效
+		secret, err = randomSecret()
+		if err != nil {
+			return nil, fmt.Errorf("生成JWT密钥失败: %v", err)
+		}
+		fmt.Println("警告: 未配置JWT密钥，已使用随机密钥")
+	}
+
+	tokenManager, err := user.NewTokenManager(secret, expiry)
+	if err != nil {
+		return nil, fmt.Errorf("初始化令牌管理器失败: %v", err)
+	}
+
+	users := user.NewUserService()
+	if cfg.Admin.Username != "" {
+		if _, err := users.EnsureAdmin(context.Background(), &user.RegisterRequest{
+			Username: cfg.Admin.Username,
+			Password: cfg.Admin.Password,
+			Email:    cfg.Admin.Email,
+		}); err != nil {
+			return nil, fmt.Errorf("创建初始管理员失败: %v", err)
+		}
+	} else {
+		fmt.Println("警告: 未配置初始管理员（admin.username/admin.password），注册的用户都为观看者")
+	}
+
+	return &UserService{
+		config:       cfg,
+		users:        users,
+		tokenManager: tokenManager,
+	}, nil
+}
+
+// TokenManager 获取令牌管理器
+func (s *UserService) TokenManager() *user.TokenManager {
+	return s.tokenManager
+}
+
+// ParseToken 校验令牌并使用用户当前的角色，用户已删除时令牌失效
+// 令牌中的角色是签发时的角色，角色变更后立即生效，不需要等待令牌过期
+func (s *UserService) ParseToken(token string) (*user.Claims, error) {
+	claims, err := s.tokenManager.ParseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.users.GetUser(context.Background(), claims.UserID)
+	if err != nil {
+		return nil, user.ErrInvalidToken
+	}
+	claims.Role = current.Role
+	return claims, nil
+}
+
+// GetUserByUsername 根据用户名获取用户
+func (s *UserService) GetUserByUsername(ctx context.Context, username string) (*user.User, error) {
+	return s.users.GetUserByUsername(ctx, username)
+}
+
+// CountUsers 统计用户数量
+func (s *UserService) CountUsers(ctx context.Context) int {
+	return s.users.Count(ctx)
+}
+
+// Register 用户注册
+func (s *UserService) Register(ctx context.Context, req *api.RegisterRequest) (*api.UserResponse, error) {
+	registered, err := s.users.Register(ctx, &user.RegisterRequest{
+		Username: req.Username,
+		Password: req.Password,
+		Email:    req.Email,
+	})
+	if err != nil {
+		if errors.Is(err, user.ErrUserExists) {
+			return s.userErrorResponse(7002, err.Error()), nil
+		}
+		return s.userErrorResponse(7001, err.Error()), nil
+	}
+
+	return &api.UserResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "注册成功",
+		},
+		User: convertToAPIUser(registered),
+	}, nil
+}
+
+// Login 用户登录
+func (s *UserService) Login(ctx context.Context, req *api.LoginRequest) (*api.LoginResponse, error) {
+	if req.Username == "" || req.Password == "" {
+		return s.loginErrorResponse(7001, "用户名和密码不能为空"), nil
+	}
+
+	authenticated, err := s.users.Authenticate(ctx, req.Username, req.Password)
+	if err != nil {
+		return s.loginErrorResponse(7003, err.Error()), nil
+	}
+
+	token, claims, err := s.tokenManager.GenerateToken(authenticated)
+	if err != nil {
+		return nil, fmt.Errorf("签发令牌失败: %w", err)
+	}
+
+	return &api.LoginResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "登录成功",
+		},
+		Token:     token,
+		ExpiresAt: claims.ExpiresAt * 1000,
+		User:      convertToAPIUser(authenticated),
+	}, nil
+}
+
+// GetCurrentUser 获取当前登录用户
+func (s *UserService) GetCurrentUser(ctx context.Context) (*api.UserResponse, error) {
+	claims, ok := user.ClaimsFromContext(ctx)
+	if !ok {
+		return s.userErrorResponse(7010, "未登录"), nil
+	}
+
+	current, err := s.users.GetUser(ctx, claims.UserID)
+	if err != nil {
+		return s.userErrorResponse(7004, err.Error()), nil
+	}
+
+	return &api.UserResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		User: convertToAPIUser(current),
+	}, nil
+}
+
+// ListUsers 获取用户列表
+func (s *UserService) ListUsers(ctx context.Context) (*api.UserListResponse, error) {
+	users := s.users.ListUsers(ctx)
+
+	apiUsers := make([]*api.User, 0, len(users))
+	for _, u := range users {
+		apiUsers = append(apiUsers, convertToAPIUser(u))
+	}
+
+	return &api.UserListResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Users: apiUsers,
+	}, nil
+}
+
+// UpdateUserRole 更新用户角色
+func (s *UserService) UpdateUserRole(ctx context.Context, req *api.UpdateUserRoleRequest) (*api.UserResponse, error) {
+	if req.UserID == "" {
+		return s.userErrorResponse(7001, "用户ID不能为空"), nil
+	}
+
+	updated, err := s.users.UpdateRole(ctx, req.UserID, req.Role)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return s.userErrorResponse(7004, err.Error()), nil
+		}
+		return s.userErrorResponse(7005, err.Error()), nil
+	}
+
+	return &api.UserResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "更新成功",
+		},
+		User: convertToAPIUser(updated),
+	}, nil
+}
+
+// userErrorResponse 创建用户错误响应
+func (s *UserService) userErrorResponse(code int32, message string) *api.UserResponse {
+	return &api.UserResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// loginErrorResponse 创建登录错误响应
+func (s *UserService) loginErrorResponse(code int32, message string) *api.LoginResponse {
+	return &api.LoginResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// convertToAPIUser 将用户转换为API模型
+func convertToAPIUser(u *user.User) *api.User {
+	return &api.User{
+		ID:        u.ID,
+		Username:  u.Username,
+		Email:     u.Email,
+		Role:      u.Role,
+		CreatedAt: u.CreatedAt.UnixMilli(),
+	}
+}
+
+// randomSecret 生成随机密钥
+func randomSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
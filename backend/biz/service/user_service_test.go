@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+func TestNewUserService(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.JWT.Secret = "test-secret"
+	cfg.JWT.Expire = "1h"
+	service, err := NewUserService(cfg)
+	require.NoError(t, err)
+	assert.NotNil(t, service.TokenManager())
+	assert.Equal(t, 0, service.CountUsers(context.Background()))
+
+	_, err = NewUserService(nil)
+	assert.Error(t, err, "配置为空时应该返回错误")
+
+	t.Run("初始管理员", func(t *testing.T) {
+		cfg.Admin = config.AdminConfig{Username: "root", Password: "password123"}
+		service, err := NewUserService(cfg)
+		require.NoError(t, err)
+		admin, err := service.GetUserByUsername(context.Background(), "root")
+		require.NoError(t, err)
+		assert.Equal(t, user.RoleAdmin, admin.Role, "配置的账号应该创建为管理员")
+
+		cfg.Admin.Password = "short"
+		_, err = NewUserService(cfg)
+		assert.Error(t, err, "初始管理员密码无效时应该返回错误")
+	})
+}
+
+func TestUserService_ParseToken(t *testing.T) {
+	service := createTestUserService(t)
+	ctx := context.Background()
+
+	_, err := service.users.EnsureAdmin(ctx, &user.RegisterRequest{Username: "admin", Password: "password123"})
+	require.NoError(t, err)
+	registered, err := service.Register(ctx, &api.RegisterRequest{Username: "alice", Password: "password123"})
+	require.NoError(t, err)
+	_, err = service.users.UpdateRole(ctx, registered.User.ID, user.RoleAdmin)
+	require.NoError(t, err)
+
+	login, err := service.Login(ctx, &api.LoginRequest{Username: "alice", Password: "password123"})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), login.Base.Code, login.Base.Message)
+
+	claims, err := service.ParseToken(login.Token)
+	require.NoError(t, err)
+	assert.Equal(t, user.RoleAdmin, claims.Role)
+
+	// 降级后已签发的令牌立即使用新角色
+	_, err = service.users.UpdateRole(ctx, registered.User.ID, user.RoleViewer)
+	require.NoError(t, err)
+	claims, err = service.ParseToken(login.Token)
+	require.NoError(t, err)
+	assert.Equal(t, user.RoleViewer, claims.Role, "降级后不应该继续使用令牌中的管理员角色")
+
+	// 用户不存在时令牌无效
+	other := createTestUserService(t)
+	_, err = other.ParseToken(login.Token)
+	assert.ErrorIs(t, err, user.ErrInvalidToken)
+
+	_, err = service.ParseToken("invalid")
+	assert.Error(t, err)
+}
+
+func TestUserService_RegisterAndLogin(t *testing.T) {
+	service := createTestUserService(t)
+	ctx := context.Background()
+
+	t.Run("注册_成功", func(t *testing.T) {
+		resp, err := service.Register(ctx, &api.RegisterRequest{Username: "admin", Password: "password123"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code)
+		assert.Equal(t, "admin", resp.User.Username)
+		assert.Equal(t, user.RoleViewer, resp.User.Role, "注册的用户不应该成为管理员")
+	})
+
+	t.Run("注册_用户名重复", func(t *testing.T) {
+		resp, err := service.Register(ctx, &api.RegisterRequest{Username: "admin", Password: "password123"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(7002), resp.Base.Code)
+	})
+
+	t.Run("注册_参数无效", func(t *testing.T) {
+		resp, err := service.Register(ctx, &api.RegisterRequest{Username: "bob", Password: "short"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(7001), resp.Base.Code)
+		assert.Contains(t, resp.Base.Message, "密码")
+	})
+
+	t.Run("登录_成功", func(t *testing.T) {
+		resp, err := service.Login(ctx, &api.LoginRequest{Username: "admin", Password: "password123"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code)
+		assert.NotEmpty(t, resp.Token)
+		assert.Greater(t, resp.ExpiresAt, time.Now().UnixMilli())
+
+		claims, err := service.TokenManager().ParseToken(resp.Token)
+		require.NoError(t, err)
+		assert.Equal(t, resp.User.ID, claims.UserID)
+	})
+
+	t.Run("登录_密码错误", func(t *testing.T) {
+		resp, err := service.Login(ctx, &api.LoginRequest{Username: "admin", Password: "wrong-password"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(7003), resp.Base.Code)
+		assert.Empty(t, resp.Token)
+	})
+}
+
+func TestUserService_CurrentUserAndRoles(t *testing.T) {
+	service := createTestUserService(t)
+	ctx := context.Background()
+
+	_, err := service.Register(ctx, &api.RegisterRequest{Username: "admin", Password: "password123"})
+	require.NoError(t, err)
+	registered, err := service.Register(ctx, &api.RegisterRequest{Username: "alice", Password: "password123"})
+	require.NoError(t, err)
+
+	t.Run("获取当前用户_未登录", func(t *testing.T) {
+		resp, err := service.GetCurrentUser(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int32(7010), resp.Base.Code)
+	})
+
+	t.Run("获取当前用户_已登录", func(t *testing.T) {
+		userCtx := user.ContextWithClaims(ctx, &user.Claims{UserID: registered.User.ID})
+		resp, err := service.GetCurrentUser(userCtx)
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code)
+		assert.Equal(t, "alice", resp.User.Username)
+	})
+
+	t.Run("更新角色", func(t *testing.T) {
+		resp, err := service.UpdateUserRole(ctx, &api.UpdateUserRoleRequest{UserID: registered.User.ID, Role: user.RoleUploader})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code)
+		assert.Equal(t, user.RoleUploader, resp.User.Role)
+
+		resp, err = service.UpdateUserRole(ctx, &api.UpdateUserRoleRequest{UserID: "missing", Role: user.RoleViewer})
+		require.NoError(t, err)
+		assert.Equal(t, int32(7004), resp.Base.Code)
+
+		resp, err = service.UpdateUserRole(ctx, &api.UpdateUserRoleRequest{UserID: registered.User.ID, Role: "root"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(7005), resp.Base.Code)
+	})
+
+	t.Run("用户列表", func(t *testing.T) {
+		resp, err := service.ListUsers(ctx)
+		require.NoError(t, err)
+		assert.Len(t, resp.Users, 2)
+	})
+}
+
+func TestCurrentUserID(t *testing.T) {
+	assert.Equal(t, "system", currentUserID(context.Background()), "未登录时应该返回system")
+
+	ctx := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "user-1"})
+	assert.Equal(t, "user-1", currentUserID(ctx))
+}
+
+// createTestUserService 创建测试用的用户服务
+func createTestUserService(t *testing.T) *UserService {
+	tokenManager, err := user.NewTokenManager("test-secret", time.Hour)
+	require.NoError(t, err)
+
+	return &UserService{
+		users:        user.NewUserService(),
+		tokenManager: tokenManager,
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/analytics"
+)
+
+// 播放URL有效期
+const (
+	defaultPlayURLExpiry = time.Hour
+	maxPlayURLExpiry     = 7 * 24 * time.Hour // 与S3预签名URL的最长有效期一致
+)
+
+// 热门视频统计参数
+const (
+	defaultTopVideosWindowHours = 24
+	defaultTopVideosLimit       = 10
+	maxTopVideosLimit           = 100
+)
+
+// GetVideoPlayURL 生成视频播放URL以及缩略图、预览图和字幕等播放器需要的地址，每次签发计为一次播放
+func (s *VideoService) GetVideoPlayURL(ctx context.Context, req *api.VideoPlayURLRequest) (*api.VideoPlayURLResponse, error) {
+	if req.VideoID == "" {
+		return s.playURLErrorResponse(6301, "视频ID不能为空"), nil
+	}
+
+	expiry := defaultPlayURLExpiry
+	if req.ExpireSeconds != 0 {
+		expiry = time.Duration(req.ExpireSeconds) * time.Second
+	}
+	if expiry <= 0 || expiry > maxPlayURLExpiry {
+		return s.playURLErrorResponse(6301, fmt.Sprintf("URL过期时间必须在1到%d秒之间", int64(maxPlayURLExpiry/time.Second))), nil
+	}
+
+	meta, err := s.getVisibleMetadata(ctx, req.VideoID)
+	if err != nil {
+		return s.playURLErrorResponse(6302, "视频不存在"), nil
+	}
+	meta, err = s.restoreArchivedVideo(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	playURL, err := s.playURL(ctx, meta, expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &api.VideoPlayURLResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		PlayURL:   playURL,
+		ExpiresAt: time.Now().Add(expiry).UnixMilli(),
+	}
+	if err := s.fillPlaybackAssets(ctx, resp, meta, expiry); err != nil {
+		return nil, err
+	}
+	s.views.RecordView(ctx, meta.FileID)
+	return resp, nil
+}
+
+// GetTopVideos 获取统计窗口内播放次数最多的视频，已删除的视频不包含在结果中
+func (s *VideoService) GetTopVideos(ctx context.Context, req *api.TopVideosRequest) (*api.TopVideosResponse, error) {
+	windowHours := req.WindowHours
+	if windowHours == 0 {
+		windowHours = defaultTopVideosWindowHours
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultTopVideosLimit
+	}
+
+	window := time.Duration(windowHours) * time.Hour
+	if windowHours < 0 || window > analytics.Retention {
+		return s.topVideosErrorResponse(9201, fmt.Sprintf("统计窗口必须在1到%d小时之间", int64(analytics.Retention/time.Hour))), nil
+	}
+	if limit < 0 || limit > maxTopVideosLimit {
+		return s.topVideosErrorResponse(9201, fmt.Sprintf("返回数量必须在1到%d之间", maxTopVideosLimit)), nil
+	}
+
+	top := s.views.TopVideos(ctx, window, int(limit))
+	stats := make([]*api.VideoViewStat, 0, len(top))
+	for _, item := range top {
+		meta, err := s.metadataService.GetMetadata(ctx, item.VideoID)
+		if err != nil {
+			continue
+		}
+		video := convertToAPIVideo(meta)
+		video.ViewCount = s.views.ViewCount(ctx, item.VideoID)
+		stats = append(stats, &api.VideoViewStat{
+			Video: video,
+			Views: item.Views,
+		})
+	}
+
+	return &api.TopVideosResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		WindowStart: time.Now().Add(-window).Truncate(analytics.BucketSize).UnixMilli(),
+		Videos:      stats,
+	}, nil
+}
+
+// fillViewCounts 填充视频的总播放次数
+func (s *VideoService) fillViewCounts(ctx context.Context, videos []*api.Video) {
+	if len(videos) == 0 {
+		return
+	}
+
+	videoIDs := make([]string, 0, len(videos))
+	for _, video := range videos {
+		videoIDs = append(videoIDs, video.ID)
+	}
+
+	counts := s.views.ViewCounts(ctx, videoIDs)
+	for _, video := range videos {
+		video.ViewCount = counts[video.ID]
+	}
+}
+
+// playURLErrorResponse 创建播放URL错误响应
+func (s *VideoService) playURLErrorResponse(code int32, message string) *api.VideoPlayURLResponse {
+	return &api.VideoPlayURLResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// topVideosErrorResponse 创建热门视频错误响应
+func (s *VideoService) topVideosErrorResponse(code int32, message string) *api.TopVideosResponse {
+	return &api.TopVideosResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+		Videos: []*api.VideoViewStat{},
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/delete"
+)
+
+func TestVideoService_GetVideoPlayURL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("获取播放URL", func(t *testing.T) {
+		service := createStreamTestService(t)
+
+		resp, err := service.GetVideoPlayURL(ctx, &api.VideoPlayURLRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Contains(t, resp.PlayURL, "videos/2025/08/video1.mp4")
+		assert.Greater(t, resp.ExpiresAt, int64(0))
+		assert.Equal(t, int64(1), service.views.ViewCount(ctx, "video1"), "签发播放URL应该计为一次播放")
+	})
+
+	t.Run("过期时间无效", func(t *testing.T) {
+		service := createStreamTestService(t)
+
+		for _, expire := range []int32{-1, 7*24*3600 + 1} {
+			resp, err := service.GetVideoPlayURL(ctx, &api.VideoPlayURLRequest{VideoID: "video1", ExpireSeconds: expire})
+			require.NoError(t, err)
+			assert.Equal(t, int32(6301), resp.Base.Code, "过期时间%d应该被拒绝", expire)
+		}
+		assert.Equal(t, int64(0), service.views.ViewCount(ctx, "video1"), "失败的请求不应该计入播放")
+	})
+
+	t.Run("视频不存在", func(t *testing.T) {
+		service := createStreamTestService(t)
+
+		resp, err := service.GetVideoPlayURL(ctx, &api.VideoPlayURLRequest{VideoID: "missing"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6302), resp.Base.Code)
+	})
+}
+
+func TestVideoService_ViewCount(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("播放开始时计数", func(t *testing.T) {
+		service := createStreamTestService(t)
+
+		stream, err := service.StreamVideo(ctx, &api.VideoStreamRequest{VideoID: "video1"}, "")
+		require.NoError(t, err)
+		require.Equal(t, int32(0), stream.Base.Code, stream.Base.Message)
+		readStream(t, stream)
+
+		stream, err = service.StreamVideo(ctx, &api.VideoStreamRequest{VideoID: "video1"}, "bytes=0-3")
+		require.NoError(t, err)
+		readStream(t, stream)
+
+		// 拖动进度产生的后续Range请求不计入播放
+		stream, err = service.StreamVideo(ctx, &api.VideoStreamRequest{VideoID: "video1"}, "bytes=4-")
+		require.NoError(t, err)
+		readStream(t, stream)
+
+		detail, err := service.GetVideoDetail(ctx, &api.VideoDetailRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), detail.Base.Code, detail.Base.Message)
+		assert.Equal(t, int64(2), detail.Video.ViewCount)
+
+		list, err := service.GetVideoList(ctx, &api.VideoListRequest{})
+		require.NoError(t, err)
+		require.Len(t, list.Videos, 1)
+		assert.Equal(t, int64(2), list.Videos[0].ViewCount, "视频列表应该返回播放次数")
+	})
+
+	t.Run("删除视频后清除播放统计", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		service.deleteService = delete.NewDeleteService(store)
+
+		uploaded := uploadTestVideo(t, service, "first.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+
+		_, err := service.GetVideoPlayURL(ctx, &api.VideoPlayURLRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), service.views.ViewCount(ctx, uploaded.Video.ID))
+
+		deleteResp, err := service.DeleteVideo(ctx, &api.VideoDeleteRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), deleteResp.Base.Code, deleteResp.Base.Message)
+		assert.Equal(t, int64(0), service.views.ViewCount(ctx, uploaded.Video.ID), "删除视频后应该清除播放统计")
+	})
+}
+
+func TestVideoService_GetTopVideos(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("按播放次数排序", func(t *testing.T) {
+		service := createStreamTestService(t)
+		for i := 0; i < 3; i++ {
+			_, err := service.GetVideoPlayURL(ctx, &api.VideoPlayURLRequest{VideoID: "video1"})
+			require.NoError(t, err)
+		}
+		// 已删除视频的残留统计不应该出现在结果中
+		service.views.RecordView(ctx, "deleted")
+
+		resp, err := service.GetTopVideos(ctx, &api.TopVideosRequest{})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		require.Len(t, resp.Videos, 1)
+		assert.Equal(t, "video1", resp.Videos[0].Video.ID)
+		assert.Equal(t, int64(3), resp.Videos[0].Views)
+		assert.Equal(t, int64(3), resp.Videos[0].Video.ViewCount)
+		assert.Greater(t, resp.WindowStart, int64(0))
+	})
+
+	t.Run("参数无效", func(t *testing.T) {
+		service := createStreamTestService(t)
+
+		invalid := []*api.TopVideosRequest{
+			{WindowHours: -1},
+			{WindowHours: 90*24 + 1},
+			{Limit: -1},
+			{Limit: 101},
+		}
+		for _, req := range invalid {
+			resp, err := service.GetTopVideos(ctx, req)
+			require.NoError(t, err)
+			assert.Equal(t, int32(9201), resp.Base.Code, "无效参数应该被拒绝: %+v", req)
+			assert.NotNil(t, resp.Videos)
+		}
+	})
+}
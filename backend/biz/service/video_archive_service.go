@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/transcode"
+)
+
+// errArchiveBusy 视频正在打包或生成预览图，需要读取视频文件，暂时不能归档
+var errArchiveBusy = errors.New("视频正在处理中，请稍后重试")
+
+// ArchiveVideo 将视频文件移动到归档存储桶，去重后共享同一文件的视频一起归档
+func (s *VideoService) ArchiveVideo(ctx context.Context, req *api.VideoArchiveRequest) (*api.VideoArchiveResponse, error) {
+	if req.VideoID == "" {
+		return s.videoArchiveErrorResponse(4501, "视频ID不能为空"), nil
+	}
+	if !s.config.Archive.Enabled || s.archiver == nil {
+		return s.videoArchiveErrorResponse(4503, "冷存储归档未启用"), nil
+	}
+
+	meta, err := s.metadataService.GetMetadata(ctx, req.VideoID)
+	if err != nil {
+		return s.videoArchiveErrorResponse(4502, "视频不存在"), nil
+	}
+
+	if _, err := s.archiveObject(ctx, meta, time.Time{}); errors.Is(err, errArchiveBusy) {
+		return s.videoArchiveErrorResponse(4504, err.Error()), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("归档视频失败: %w", err)
+	}
+
+	return s.videoArchiveResponse(ctx, req.VideoID, "归档成功")
+}
+
+// RestoreVideo 将已归档的视频文件移回视频存储桶，未归档时不做处理
+func (s *VideoService) RestoreVideo(ctx context.Context, req *api.VideoArchiveRequest) (*api.VideoArchiveResponse, error) {
+	if req.VideoID == "" {
+		return s.videoArchiveErrorResponse(4501, "视频ID不能为空"), nil
+	}
+
+	meta, err := s.metadataService.GetMetadata(ctx, req.VideoID)
+	if err != nil {
+		return s.videoArchiveErrorResponse(4502, "视频不存在"), nil
+	}
+
+	if _, err := s.restoreArchivedVideo(ctx, meta); err != nil {
+		return nil, err
+	}
+
+	return s.videoArchiveResponse(ctx, req.VideoID, "恢复成功")
+}
+
+// ArchiveIdleVideos 归档超过idleAfter没有播放的视频，返回归档的视频数量
+// 从未播放的视频按上传时间计算，恢复后的视频按恢复时间计算；单个文件归档失败时记录日志并继续
+func (s *VideoService) ArchiveIdleVideos(ctx context.Context, idleAfter time.Duration) (int, error) {
+	list, err := s.metadataService.ListMetadata(ctx, &metadata.ListMetadataRequest{Limit: math.MaxInt})
+	if err != nil {
+		return 0, fmt.Errorf("获取视频列表失败: %w", err)
+	}
+
+	cutoff := time.Now().Add(-idleAfter)
+	checked := make(map[string]bool)
+	archived := 0
+	for _, meta := range list.Items {
+		key := meta.BucketName + "/" + meta.ObjectName
+		if meta.Archived || checked[key] {
+			continue
+		}
+		checked[key] = true
+
+		count, err := s.archiveObject(ctx, meta, cutoff)
+		if err != nil && !errors.Is(err, errArchiveBusy) {
+			fmt.Printf("归档视频失败(%s): %v\n", meta.FileID, err)
+		}
+		archived += count
+	}
+	return archived, nil
+}
+
+// startArchiveScheduler 在后台定期归档闲置视频
+func (s *VideoService) startArchiveScheduler(interval, idleAfter time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			count, err := s.ArchiveIdleVideos(context.Background(), idleAfter)
+			if err != nil {
+				fmt.Printf("归档闲置视频失败: %v\n", err)
+			} else if count > 0 {
+				fmt.Printf("已归档%d个闲置视频\n", count)
+			}
+		}
+	}()
+}
+
+// archiveObject 归档视频文件并更新所有引用该文件的视频记录，返回归档的视频数量
+// cutoff非零时，只有共享该文件的视频在cutoff之后都没有活动才归档
+func (s *VideoService) archiveObject(ctx context.Context, meta *metadata.FileMetadata, cutoff time.Time) (int, error) {
+	unlock := s.archiver.Lock(meta.ObjectName)
+	defer unlock()
+
+	// 加锁后重新读取，期间可能已被归档或产生了新的播放
+	refs := s.metadataService.ListObjectReferences(ctx, meta.BucketName, meta.ObjectName)
+	// 历史版本的文件始终保存在视频存储桶中，被其他视频的历史版本引用的文件不归档
+	if s.metadataService.CountObjectReferences(ctx, meta.BucketName, meta.ObjectName) > len(refs) {
+		return 0, nil
+	}
+	for _, ref := range refs {
+		if ref.Archived {
+			return 0, nil
+		}
+		if s.isProcessingVideo(ref.FileID) {
+			return 0, errArchiveBusy
+		}
+		if !cutoff.IsZero() && s.lastVideoActivity(ctx, ref).After(cutoff) {
+			return 0, nil
+		}
+	}
+
+	if err := s.archiver.Archive(ctx, meta.BucketName, meta.ObjectName); err != nil {
+		return 0, err
+	}
+	s.invalidatePresignedURLs(ctx, meta.BucketName, meta.ObjectName)
+	return s.metadataService.SetObjectArchived(ctx, meta.BucketName, meta.ObjectName, true), nil
+}
+
+// restoreArchivedVideo 视频已归档时将文件恢复到视频存储桶，返回最新的元数据
+// 播放、生成播放URL和按需HLS打包前调用，恢复期间同一文件的其他请求会等待恢复完成
+func (s *VideoService) restoreArchivedVideo(ctx context.Context, meta *metadata.FileMetadata) (*metadata.FileMetadata, error) {
+	if !meta.Archived || s.archiver == nil {
+		return meta, nil
+	}
+
+	unlock := s.archiver.Lock(meta.ObjectName)
+	defer unlock()
+
+	current, err := s.metadataService.GetMetadata(ctx, meta.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("获取视频元数据失败: %w", err)
+	}
+	if !current.Archived {
+		return current, nil
+	}
+
+	if err := s.archiver.Restore(ctx, current.BucketName, current.ObjectName); err != nil {
+		return nil, err
+	}
+	s.metadataService.SetObjectArchived(ctx, current.BucketName, current.ObjectName, false)
+
+	restored, err := s.metadataService.GetMetadata(ctx, meta.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("获取视频元数据失败: %w", err)
+	}
+	return restored, nil
+}
+
+// lastVideoActivity 获取视频最近一次活动时间：上传、从归档恢复或播放
+func (s *VideoService) lastVideoActivity(ctx context.Context, meta *metadata.FileMetadata) time.Time {
+	last := meta.CreatedAt
+	if meta.RestoredAt.After(last) {
+		last = meta.RestoredAt
+	}
+	if viewed := s.views.LastViewed(ctx, meta.FileID); viewed.After(last) {
+		last = viewed
+	}
+	return last
+}
+
+// isProcessingVideo 判断视频是否正在进行HLS打包、生成预览图或替换内容
+func (s *VideoService) isProcessingVideo(videoID string) bool {
+	if s.isPackagingVideo(videoID) || s.isRunningJob(transcode.KindPreview, videoID) {
+		return true
+	}
+	if _, running := s.previewJobs.Load(videoID); running {
+		return true
+	}
+	_, running := s.versionJobs.Load(videoID)
+	return running
+}
+
+// videoArchiveResponse 创建归档操作成功响应，返回最新的视频信息
+func (s *VideoService) videoArchiveResponse(ctx context.Context, videoID, message string) (*api.VideoArchiveResponse, error) {
+	updated, err := s.metadataService.GetMetadata(ctx, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("获取视频元数据失败: %w", err)
+	}
+
+	return &api.VideoArchiveResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: message,
+		},
+		Video: convertToAPIVideo(updated),
+	}, nil
+}
+
+// videoArchiveErrorResponse 创建视频归档错误响应
+func (s *VideoService) videoArchiveErrorResponse(code int32, message string) *api.VideoArchiveResponse {
+	return &api.VideoArchiveResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/archive"
+	"github.com/manteia/zhulong/pkg/delete"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/upload"
+)
+
+// archiveTestBucket 测试用归档存储桶
+const archiveTestBucket = "zhulong-archive"
+
+// createArchiveTestService 创建使用本地文件系统存储并启用归档的视频服务
+// 测试用内存存储不区分存储桶，无法验证文件在存储桶之间移动
+func createArchiveTestService(t *testing.T) (*VideoService, *storage.LocalStorage) {
+	store, err := storage.NewLocalStorage(&storage.LocalConfig{
+		RootDir: t.TempDir(),
+		BaseURL: "http://localhost:8888/storage",
+	})
+	require.NoError(t, err)
+
+	service, _ := createDirectUploadTestService(t)
+	service.storageClient = store
+	service.uploadService = upload.NewUploadService(store)
+	service.deleteService = delete.NewDeleteService(store)
+	service.archiver = archive.NewArchiver(store, archiveTestBucket)
+	service.config.Archive.Enabled = true
+	return service, store
+}
+
+// assertObjectLocation 验证视频文件所在的存储桶
+func assertObjectLocation(t *testing.T, store *storage.LocalStorage, objectName string, archived bool) {
+	ctx := context.Background()
+	inVideos, err := store.FileExists(ctx, "zhulong-videos", objectName)
+	require.NoError(t, err)
+	inArchive, err := store.FileExists(ctx, archiveTestBucket, objectName)
+	require.NoError(t, err)
+	assert.Equal(t, !archived, inVideos, "视频存储桶中的文件状态不正确")
+	assert.Equal(t, archived, inArchive, "归档存储桶中的文件状态不正确")
+}
+
+func TestVideoService_ArchiveVideo(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("归档后播放时自动恢复", func(t *testing.T) {
+		service, store := createArchiveTestService(t)
+		data := mp4TestData(2048)
+		uploaded := uploadTestVideo(t, service, "archive.mp4", data)
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+
+		resp, err := service.ArchiveVideo(ctx, &api.VideoArchiveRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.True(t, resp.Video.Archived)
+		assertObjectLocation(t, store, uploaded.Video.StoragePath, true)
+
+		stream, err := service.StreamVideo(ctx, &api.VideoStreamRequest{VideoID: uploaded.Video.ID}, "")
+		require.NoError(t, err)
+		require.Equal(t, int32(0), stream.Base.Code, stream.Base.Message)
+		body, err := io.ReadAll(stream.Body)
+		stream.Body.Close()
+		require.NoError(t, err)
+		assert.Equal(t, data, body, "播放时应该自动恢复归档的文件")
+		assertObjectLocation(t, store, uploaded.Video.StoragePath, false)
+
+		detail, err := service.GetVideoDetail(ctx, &api.VideoDetailRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		assert.False(t, detail.Video.Archived, "恢复后应该清除归档状态")
+	})
+
+	t.Run("手动恢复和获取播放地址时恢复", func(t *testing.T) {
+		service, store := createArchiveTestService(t)
+		uploaded := uploadTestVideo(t, service, "archive.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+
+		_, err := service.ArchiveVideo(ctx, &api.VideoArchiveRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		restored, err := service.RestoreVideo(ctx, &api.VideoArchiveRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), restored.Base.Code, restored.Base.Message)
+		assert.False(t, restored.Video.Archived)
+		assertObjectLocation(t, store, uploaded.Video.StoragePath, false)
+
+		_, err = service.ArchiveVideo(ctx, &api.VideoArchiveRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		playURL, err := service.GetVideoPlayURL(ctx, &api.VideoPlayURLRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), playURL.Base.Code, playURL.Base.Message)
+		assertObjectLocation(t, store, uploaded.Video.StoragePath, false)
+	})
+
+	t.Run("共享文件的视频一起归档", func(t *testing.T) {
+		service, store := createArchiveTestService(t)
+		service.config.Upload.Deduplication = "alias"
+		data := mp4TestData(2048)
+		first := uploadTestVideo(t, service, "first.mp4", data)
+		require.Equal(t, int32(0), first.Base.Code, first.Base.Message)
+
+		_, err := service.ArchiveVideo(ctx, &api.VideoArchiveRequest{VideoID: first.Video.ID})
+		require.NoError(t, err)
+
+		second := uploadTestVideo(t, service, "second.mp4", data)
+		require.Equal(t, int32(0), second.Base.Code, second.Base.Message)
+		assert.Equal(t, first.Video.StoragePath, second.Video.StoragePath)
+		assertObjectLocation(t, store, first.Video.StoragePath, false)
+
+		resp, err := service.ArchiveVideo(ctx, &api.VideoArchiveRequest{VideoID: second.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		detail, err := service.GetVideoDetail(ctx, &api.VideoDetailRequest{VideoID: first.Video.ID})
+		require.NoError(t, err)
+		assert.True(t, detail.Video.Archived, "共享同一文件的视频应该一起归档")
+
+		_, err = service.DeleteVideo(ctx, &api.VideoDeleteRequest{VideoID: first.Video.ID})
+		require.NoError(t, err)
+		assertObjectLocation(t, store, first.Video.StoragePath, true)
+
+		deleteResp, err := service.DeleteVideo(ctx, &api.VideoDeleteRequest{VideoID: second.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), deleteResp.Base.Code, deleteResp.Base.Message)
+		exists, err := store.FileExists(ctx, archiveTestBucket, first.Video.StoragePath)
+		require.NoError(t, err)
+		assert.False(t, exists, "删除最后一个引用的视频时应该删除归档文件")
+	})
+
+	t.Run("参数错误", func(t *testing.T) {
+		service, _ := createArchiveTestService(t)
+
+		resp, err := service.ArchiveVideo(ctx, &api.VideoArchiveRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4501), resp.Base.Code)
+
+		resp, err = service.ArchiveVideo(ctx, &api.VideoArchiveRequest{VideoID: "missing"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4502), resp.Base.Code)
+
+		resp, err = service.RestoreVideo(ctx, &api.VideoArchiveRequest{VideoID: "missing"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4502), resp.Base.Code)
+
+		service.config.Archive.Enabled = false
+		resp, err = service.ArchiveVideo(ctx, &api.VideoArchiveRequest{VideoID: "missing"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4503), resp.Base.Code, "未启用归档时不能手动归档")
+	})
+}
+
+func TestVideoService_ArchiveIdleVideos(t *testing.T) {
+	ctx := context.Background()
+	service, store := createArchiveTestService(t)
+
+	first := uploadTestVideo(t, service, "first.mp4", mp4TestData(2048))
+	require.Equal(t, int32(0), first.Base.Code, first.Base.Message)
+	second := uploadTestVideo(t, service, "second.mp4", mp4TestData(4096))
+	require.Equal(t, int32(0), second.Base.Code, second.Base.Message)
+
+	count, err := service.ArchiveIdleVideos(ctx, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count, "刚上传的视频不应该被归档")
+
+	// 正在生成预览图的视频需要读取文件，跳过归档
+	service.previewJobs.Store(second.Video.ID, struct{}{})
+	count, err = service.ArchiveIdleVideos(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assertObjectLocation(t, store, first.Video.StoragePath, true)
+	assertObjectLocation(t, store, second.Video.StoragePath, false)
+
+	service.previewJobs.Delete(second.Video.ID)
+	count, err = service.ArchiveIdleVideos(ctx, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/streaming"
+	"github.com/manteia/zhulong/pkg/transcode"
+)
+
+// 衍生文件类型
+const (
+	assetThumbnail           = "thumbnail"            // 缩略图
+	assetPreview             = "preview"              // 动态预览
+	assetSprite              = "sprite"               // 进度条预览雪碧图和WebVTT轨道
+	assetThumbnailCandidates = "thumbnail_candidates" // 候选缩略图
+	assetHLSPlaylist         = "hls_playlist"         // HLS主播放列表
+	assetDASHManifest        = "dash_manifest"        // DASH清单
+	assetRendition           = "rendition"            // HLS码率档位的媒体播放列表和分片
+	assetSubtitle            = "subtitle"             // 字幕
+)
+
+// 衍生文件状态
+const (
+	assetReady      = "ready"      // 已生成
+	assetProcessing = "processing" // 任务排队或正在执行
+	assetIncomplete = "incomplete" // 生成中断，只有部分文件
+	assetMissing    = "missing"    // 元数据引用的文件不存在
+)
+
+// videoAssets 列出视频的衍生文件
+// 上传者和管理员查询存储，列出存储位置、大小和状态；其他用户按元数据列出，不访问存储，也不返回存储位置
+func (s *VideoService) videoAssets(ctx context.Context, meta *metadata.FileMetadata) ([]*api.VideoAsset, error) {
+	if !meta.OwnedBy(currentViewer(ctx)) {
+		return s.metadataAssets(meta), nil
+	}
+	return s.storedAssets(ctx, meta)
+}
+
+// metadataAssets 按元数据和任务状态列出衍生文件：元数据记录的缩略图和动态预览，以及正在生成的预览图和HLS文件
+func (s *VideoService) metadataAssets(meta *metadata.FileMetadata) []*api.VideoAsset {
+	assets := []*api.VideoAsset{}
+	previewActive := s.hasActiveJob(transcode.KindPreview, meta.FileID)
+	if meta.Thumbnail != "" {
+		assets = append(assets, &api.VideoAsset{Kind: assetThumbnail, Name: assetThumbnail, Status: assetReady})
+	}
+	if meta.Preview != "" {
+		assets = append(assets, &api.VideoAsset{Kind: assetPreview, Name: assetPreview, Status: assetReady})
+	} else if previewActive {
+		assets = append(assets, &api.VideoAsset{Kind: assetPreview, Name: assetPreview, Status: assetProcessing})
+	}
+	if previewActive {
+		assets = append(assets, &api.VideoAsset{Kind: assetSprite, Name: assetSprite, Status: assetProcessing})
+	}
+	if s.hasActiveJob(transcode.KindHLS, meta.FileID) || s.isPackagingVideo(meta.FileID) {
+		assets = append(assets, &api.VideoAsset{Kind: assetHLSPlaylist, Name: assetHLSPlaylist, Status: assetProcessing})
+	}
+	return assets
+}
+
+// storedAssets 查询存储列出视频的衍生文件：缩略图、动态预览、进度条预览图、候选缩略图、HLS/DASH文件和字幕
+// 没有文件的类型不列出，对应的转码或预览图任务未结束时列出为processing
+func (s *VideoService) storedAssets(ctx context.Context, meta *metadata.FileMetadata) ([]*api.VideoAsset, error) {
+	var assets []*api.VideoAsset
+	previewActive := s.hasActiveJob(transcode.KindPreview, meta.FileID)
+
+	thumbnailBucket := s.buckets.Bucket(storage.ContentThumbnails)
+	for _, ref := range []struct{ kind, objectName string }{
+		{assetThumbnail, meta.Thumbnail},
+		{assetPreview, meta.Preview},
+	} {
+		if ref.objectName == "" {
+			if ref.kind == assetPreview && previewActive {
+				assets = append(assets, &api.VideoAsset{Kind: ref.kind, Name: ref.kind, Bucket: thumbnailBucket, Status: assetProcessing})
+			}
+			continue
+		}
+		asset, err := s.objectAsset(ctx, ref.kind, thumbnailBucket, ref.objectName)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+
+	spriteFiles, err := s.storageClient.ListFiles(ctx, thumbnailBucket, spritePrefix(meta.FileID))
+	if err != nil {
+		return nil, fmt.Errorf("列出预览图文件失败: %w", err)
+	}
+	// WebVTT轨道最后上传，存在即表示预览图已就绪
+	if sprite := prefixAsset(assetSprite, assetSprite, thumbnailBucket, spritePrefix(meta.FileID), spriteFiles); sprite != nil {
+		sprite.Status = pendingStatus(previewActive, hasObject(spriteFiles, spritePrefix(meta.FileID)+spriteVTTName))
+		assets = append(assets, sprite)
+	} else if previewActive {
+		assets = append(assets, &api.VideoAsset{Kind: assetSprite, Name: assetSprite, Bucket: thumbnailBucket, Path: spritePrefix(meta.FileID), Status: assetProcessing})
+	}
+
+	candidateFiles, err := s.storageClient.ListFiles(ctx, thumbnailBucket, thumbnailCandidatePrefix(meta.FileID))
+	if err != nil {
+		return nil, fmt.Errorf("列出候选缩略图失败: %w", err)
+	}
+	if candidates := prefixAsset(assetThumbnailCandidates, assetThumbnailCandidates, thumbnailBucket, thumbnailCandidatePrefix(meta.FileID), candidateFiles); candidates != nil {
+		candidates.Status = assetReady
+		assets = append(assets, candidates)
+	}
+
+	streamAssets, err := s.streamingAssets(ctx, meta.FileID)
+	if err != nil {
+		return nil, err
+	}
+	assets = append(assets, streamAssets...)
+
+	subtitles, err := s.listSubtitleFiles(ctx, meta.FileID)
+	if err != nil {
+		return nil, err
+	}
+	subtitleBucket := s.buckets.Bucket(storage.ContentSubtitles)
+	for _, file := range subtitles {
+		assets = append(assets, &api.VideoAsset{
+			Kind:      assetSubtitle,
+			Name:      file.Language,
+			Bucket:    subtitleBucket,
+			Path:      file.Key,
+			Size:      file.Size,
+			FileCount: 1,
+			Status:    assetReady,
+			UpdatedAt: file.LastModified.UnixMilli(),
+		})
+	}
+
+	return assets, nil
+}
+
+// streamingAssets 按文件名将HLS目录中的文件分为主播放列表、DASH清单和各码率档位
+// 档位的媒体播放列表<档位>.m3u8在分片上传后写入，存在即表示档位已就绪
+func (s *VideoService) streamingAssets(ctx context.Context, videoID string) ([]*api.VideoAsset, error) {
+	bucketName := s.buckets.Bucket(storage.ContentRenditions)
+	prefix := streaming.VideoPrefix(videoID)
+	files, err := s.storageClient.ListFiles(ctx, bucketName, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("列出HLS文件失败: %w", err)
+	}
+	packaging := s.hasActiveJob(transcode.KindHLS, videoID) || s.isPackagingVideo(videoID)
+	if len(files) == 0 {
+		if !packaging {
+			return nil, nil
+		}
+		return []*api.VideoAsset{{Kind: assetHLSPlaylist, Name: assetHLSPlaylist, Bucket: bucketName, Path: prefix + streaming.MasterPlaylistName, Status: assetProcessing}}, nil
+	}
+
+	var names []string
+	groups := make(map[string][]*storage.FileInfo)
+	for _, file := range files {
+		name := strings.TrimPrefix(file.Key, prefix)
+		if before, _, found := strings.Cut(name, "/"); found {
+			name = before
+		} else if name != streaming.MasterPlaylistName && name != streaming.ManifestName {
+			name = strings.TrimSuffix(name, path.Ext(name))
+		}
+		if _, ok := groups[name]; !ok {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], file)
+	}
+
+	assets := make([]*api.VideoAsset, 0, len(names))
+	for _, name := range names {
+		var asset *api.VideoAsset
+		switch name {
+		case streaming.MasterPlaylistName:
+			asset = prefixAsset(assetHLSPlaylist, assetHLSPlaylist, bucketName, prefix+name, groups[name])
+			asset.Status = assetReady
+		case streaming.ManifestName:
+			asset = prefixAsset(assetDASHManifest, assetDASHManifest, bucketName, prefix+name, groups[name])
+			asset.Status = assetReady
+		default:
+			asset = prefixAsset(assetRendition, name, bucketName, prefix+name+"/", groups[name])
+			asset.Status = pendingStatus(packaging, hasObject(groups[name], prefix+name+".m3u8"))
+		}
+		assets = append(assets, asset)
+	}
+	return assets, nil
+}
+
+// objectAsset 元数据引用的单个文件，文件不存在时状态为missing
+func (s *VideoService) objectAsset(ctx context.Context, kind, bucketName, objectName string) (*api.VideoAsset, error) {
+	asset := &api.VideoAsset{Kind: kind, Name: kind, Bucket: bucketName, Path: objectName, Status: assetMissing}
+	info, exists, err := s.statObject(ctx, bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		asset.Size = info.Size
+		asset.FileCount = 1
+		asset.Status = assetReady
+		asset.UpdatedAt = info.LastModified.UnixMilli()
+	}
+	return asset, nil
+}
+
+// hasActiveJob 视频是否有未结束的指定类型任务
+func (s *VideoService) hasActiveJob(kind, videoID string) bool {
+	if s.transcodeQueue == nil {
+		return false
+	}
+	_, ok := s.transcodeQueue.ActiveJob(kind, videoID)
+	return ok
+}
+
+// prefixAsset 汇总多个文件的大小和最后修改时间，没有文件时返回nil
+func prefixAsset(kind, name, bucketName, objectPath string, files []*storage.FileInfo) *api.VideoAsset {
+	if len(files) == 0 {
+		return nil
+	}
+	asset := &api.VideoAsset{Kind: kind, Name: name, Bucket: bucketName, Path: objectPath, FileCount: int32(len(files))}
+	var updated time.Time
+	for _, file := range files {
+		asset.Size += file.Size
+		if file.LastModified.After(updated) {
+			updated = file.LastModified
+		}
+	}
+	asset.UpdatedAt = updated.UnixMilli()
+	return asset
+}
+
+// pendingStatus 根据完成标志和任务状态确定由多个文件组成的衍生文件的状态
+func pendingStatus(active, complete bool) string {
+	switch {
+	case complete:
+		return assetReady
+	case active:
+		return assetProcessing
+	default:
+		return assetIncomplete
+	}
+}
+
+// hasObject 文件列表中是否包含指定对象
+func hasObject(files []*storage.FileInfo, objectName string) bool {
+	for _, file := range files {
+		if file.Key == objectName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/transcode"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// createAssetTestService 创建包含衍生文件的测试视频
+// 缩略图已生成、动态预览缺失、雪碧图缺少WebVTT轨道，HLS的480p档位缺少媒体播放列表
+func createAssetTestService(t *testing.T) *VideoService {
+	ctx := context.Background()
+	service, store := createDirectUploadTestService(t)
+
+	thumbnailBucket := service.buckets.Bucket(storage.ContentThumbnails)
+	renditionBucket := service.buckets.Bucket(storage.ContentRenditions)
+	subtitleBucket := service.buckets.Bucket(storage.ContentSubtitles)
+	for _, object := range []struct {
+		bucket, name string
+		size         int
+	}{
+		{"zhulong-videos", "videos/2025/08/video1.mp4", 100},
+		{thumbnailBucket, "thumbnails/2025/08/video1.jpg", 10},
+		{thumbnailBucket, "sprites/video1/sprite.jpg", 20},
+		{thumbnailBucket, "thumbnail-candidates/video1/0.jpg", 5},
+		{thumbnailBucket, "thumbnail-candidates/video1/1.jpg", 6},
+		{renditionBucket, "hls/video1/master.m3u8", 1},
+		{renditionBucket, "hls/video1/manifest.mpd", 2},
+		{renditionBucket, "hls/video1/720p.m3u8", 3},
+		{renditionBucket, "hls/video1/720p/init.mp4", 4},
+		{renditionBucket, "hls/video1/720p/segment_000.m4s", 50},
+		{renditionBucket, "hls/video1/480p/segment_000.m4s", 30},
+		{subtitleBucket, "subtitles/video1/en.vtt", 7},
+	} {
+		_, err := store.UploadFile(ctx, object.bucket, object.name, make([]byte, object.size), "application/octet-stream")
+		require.NoError(t, err)
+	}
+
+	thumbnail := "thumbnails/2025/08/video1.jpg"
+	preview := "previews/2025/08/video1.gif"
+	require.NoError(t, service.metadataService.SaveMetadata(ctx, &metadata.FileMetadata{
+		FileID:     "video1",
+		BucketName: "zhulong-videos",
+		ObjectName: "videos/2025/08/video1.mp4",
+		FileName:   "video1.mp4",
+		FileSize:   100,
+		Title:      "衍生文件",
+		Thumbnail:  thumbnail,
+		Preview:    preview,
+		Visibility: metadata.VisibilityPublic,
+		CreatedBy:  "system",
+	}))
+	return service
+}
+
+// assetStatuses 获取衍生文件的类型、名称和状态
+func assetStatuses(assets []*api.VideoAsset) []string {
+	statuses := make([]string, 0, len(assets))
+	for _, asset := range assets {
+		statuses = append(statuses, asset.Kind+":"+asset.Name+":"+asset.Status)
+	}
+	return statuses
+}
+
+func TestVideoService_VideoAssets(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("视频详情返回衍生文件清单", func(t *testing.T) {
+		service := createAssetTestService(t)
+
+		resp, err := service.GetVideoDetail(claimsContext("admin-1", user.RoleAdmin), &api.VideoDetailRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, []string{
+			"thumbnail:thumbnail:ready",
+			"preview:preview:missing",
+			"sprite:sprite:incomplete",
+			"thumbnail_candidates:thumbnail_candidates:ready",
+			"rendition:480p:incomplete",
+			"rendition:720p:ready",
+			"dash_manifest:dash_manifest:ready",
+			"hls_playlist:hls_playlist:ready",
+			"subtitle:en:ready",
+		}, assetStatuses(resp.Assets))
+
+		thumbnail := resp.Assets[0]
+		assert.Equal(t, service.buckets.Bucket(storage.ContentThumbnails), thumbnail.Bucket)
+		assert.Equal(t, "thumbnails/2025/08/video1.jpg", thumbnail.Path)
+		assert.Equal(t, int64(10), thumbnail.Size)
+		assert.Equal(t, int32(1), thumbnail.FileCount)
+		assert.NotZero(t, thumbnail.UpdatedAt)
+
+		assert.Zero(t, resp.Assets[1].FileCount, "缺失的文件没有大小")
+		assert.Equal(t, int64(11), resp.Assets[3].Size)
+		assert.Equal(t, int32(2), resp.Assets[3].FileCount)
+
+		rendition := resp.Assets[5]
+		assert.Equal(t, "hls/video1/720p/", rendition.Path)
+		assert.Equal(t, int64(57), rendition.Size, "档位包含媒体播放列表、初始化分片和媒体分片")
+		assert.Equal(t, int32(3), rendition.FileCount)
+		assert.Equal(t, "subtitles/video1/en.vtt", resp.Assets[8].Path)
+	})
+
+	t.Run("其他用户按元数据列出且不返回存储位置", func(t *testing.T) {
+		service := createAssetTestService(t)
+
+		for name, viewerCtx := range map[string]context.Context{
+			"未登录":  ctx,
+			"其他用户": claimsContext("viewer-1", user.RoleViewer),
+		} {
+			resp, err := service.GetVideoDetail(viewerCtx, &api.VideoDetailRequest{VideoID: "video1"})
+			require.NoError(t, err)
+			require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+			assert.Equal(t, []string{
+				"thumbnail:thumbnail:ready",
+				"preview:preview:ready",
+			}, assetStatuses(resp.Assets), "%s只按元数据列出，不检查文件是否存在", name)
+			for _, asset := range resp.Assets {
+				assert.Empty(t, asset.Bucket, name)
+				assert.Empty(t, asset.Path, name)
+				assert.Zero(t, asset.Size, name)
+			}
+		}
+	})
+
+	t.Run("任务未结束时列出为正在生成", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+		release := make(chan struct{})
+		service.transcodeQueue = transcode.NewQueue(func(ctx context.Context, job transcode.Job) error {
+			<-release
+			return nil
+		}, 2, 1)
+		t.Cleanup(service.transcodeQueue.Close)
+		t.Cleanup(func() { close(release) })
+
+		require.NoError(t, service.metadataService.SaveMetadata(ctx, &metadata.FileMetadata{
+			FileID:     "video1",
+			BucketName: "zhulong-videos",
+			ObjectName: "videos/2025/08/video1.mp4",
+			Title:      "处理中",
+			Visibility: metadata.VisibilityPublic,
+			CreatedBy:  "system",
+		}))
+		for _, kind := range []string{transcode.KindHLS, transcode.KindPreview} {
+			_, err := service.transcodeQueue.Enqueue(kind, "video1", transcode.DefaultPriority)
+			require.NoError(t, err)
+		}
+
+		for _, viewerCtx := range []context.Context{claimsContext("admin-1", user.RoleAdmin), ctx} {
+			resp, err := service.GetVideoDetail(viewerCtx, &api.VideoDetailRequest{VideoID: "video1"})
+			require.NoError(t, err)
+			require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+			assert.Equal(t, []string{
+				"preview:preview:processing",
+				"sprite:sprite:processing",
+				"hls_playlist:hls_playlist:processing",
+			}, assetStatuses(resp.Assets))
+		}
+	})
+
+	t.Run("没有衍生文件时返回空列表", func(t *testing.T) {
+		service := createHistoryTestService(t)
+
+		resp, err := service.GetVideoDetail(ctx, &api.VideoDetailRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.NotNil(t, resp.Assets)
+		assert.Empty(t, resp.Assets)
+	})
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+// maxChapterTitleLength 章节标题的最大长度（按字符计）
+const maxChapterTitleLength = 100
+
+// UpdateVideoChapters 设置视频章节，替换从视频文件中解析出的或之前设置的全部章节
+func (s *VideoService) UpdateVideoChapters(ctx context.Context, req *api.VideoChaptersRequest) (*api.VideoChaptersResponse, error) {
+	if req.VideoID == "" {
+		return s.videoChaptersErrorResponse(4401, "视频ID不能为空"), nil
+	}
+
+	meta, err := s.metadataService.GetMetadata(ctx, req.VideoID)
+	if err != nil {
+		return s.videoChaptersErrorResponse(4402, "视频不存在"), nil
+	}
+	if !canModifyVideo(ctx, meta) {
+		return s.videoChaptersErrorResponse(4403, "无权修改其他用户的视频"), nil
+	}
+
+	chapters, err := buildVideoChapters(req.Chapters, meta.Duration*1000)
+	if err != nil {
+		return s.videoChaptersErrorResponse(4401, err.Error()), nil
+	}
+
+	if err := s.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{
+		FileID:   req.VideoID,
+		Chapters: &chapters,
+	}); err != nil {
+		return nil, fmt.Errorf("更新视频章节失败: %w", err)
+	}
+
+	updated, err := s.metadataService.GetMetadata(ctx, req.VideoID)
+	if err != nil {
+		return nil, fmt.Errorf("获取视频元数据失败: %w", err)
+	}
+
+	return &api.VideoChaptersResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "设置章节成功",
+		},
+		Video: convertToAPIVideo(updated),
+	}, nil
+}
+
+// buildVideoChapters 验证并整理手动设置的章节，durationMillis为视频时长（毫秒），未知时为0
+// 章节按开始时间排序，未设置结束时间的章节以下一章节的开始时间或视频时长作为结束时间
+func buildVideoChapters(items []*api.Chapter, durationMillis int64) ([]metadata.Chapter, error) {
+	if len(items) > video.MaxChapters {
+		return nil, fmt.Errorf("章节数量不能超过%d个", video.MaxChapters)
+	}
+
+	chapters := make([]metadata.Chapter, 0, len(items))
+	for _, item := range items {
+		if item == nil {
+			return nil, fmt.Errorf("章节不能为空")
+		}
+		title := strings.TrimSpace(item.Title)
+		if title == "" {
+			return nil, fmt.Errorf("章节标题不能为空")
+		}
+		if utf8.RuneCountInString(title) > maxChapterTitleLength {
+			return nil, fmt.Errorf("章节标题长度不能超过%d个字符", maxChapterTitleLength)
+		}
+		if item.StartTime < 0 {
+			return nil, fmt.Errorf("章节开始时间不能为负数")
+		}
+		if durationMillis > 0 && item.StartTime >= durationMillis {
+			return nil, fmt.Errorf("章节开始时间不能超过视频时长")
+		}
+		if item.EndTime != 0 && item.EndTime <= item.StartTime {
+			return nil, fmt.Errorf("章节结束时间必须晚于开始时间")
+		}
+		chapters = append(chapters, metadata.Chapter{
+			Title:     title,
+			StartTime: item.StartTime,
+			EndTime:   item.EndTime,
+		})
+	}
+
+	sort.SliceStable(chapters, func(i, j int) bool {
+		return chapters[i].StartTime < chapters[j].StartTime
+	})
+
+	for i := range chapters {
+		next := durationMillis
+		if i+1 < len(chapters) {
+			next = chapters[i+1].StartTime
+			if next == chapters[i].StartTime {
+				return nil, fmt.Errorf("章节开始时间不能重复")
+			}
+		}
+		if chapters[i].EndTime == 0 {
+			chapters[i].EndTime = next
+		} else if next > 0 && chapters[i].EndTime > next {
+			return nil, fmt.Errorf("章节时间不能重叠: %s", chapters[i].Title)
+		}
+	}
+	return chapters, nil
+}
+
+// convertVideoChapters 将从视频文件中解析出的章节转换为元数据章节
+func convertVideoChapters(chapters []video.Chapter) []metadata.Chapter {
+	if len(chapters) == 0 {
+		return nil
+	}
+
+	result := make([]metadata.Chapter, 0, len(chapters))
+	for _, chapter := range chapters {
+		result = append(result, metadata.Chapter{
+			Title:     chapter.Title,
+			StartTime: chapter.Start.Milliseconds(),
+			EndTime:   chapter.End.Milliseconds(),
+		})
+	}
+	return result
+}
+
+// convertToAPIChapters 转换为API章节格式
+func convertToAPIChapters(chapters []metadata.Chapter) []*api.Chapter {
+	result := make([]*api.Chapter, 0, len(chapters))
+	for _, chapter := range chapters {
+		result = append(result, &api.Chapter{
+			Title:     chapter.Title,
+			StartTime: chapter.StartTime,
+			EndTime:   chapter.EndTime,
+		})
+	}
+	return result
+}
+
+// videoChaptersErrorResponse 创建视频章节错误响应
+func (s *VideoService) videoChaptersErrorResponse(code int32, message string) *api.VideoChaptersResponse {
+	return &api.VideoChaptersResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
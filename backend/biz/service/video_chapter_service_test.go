@@ -0,0 +1,108 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+)
+
+// mp4ChapterTestData 生成10秒、带有Nero章节（0秒"Intro"和4秒"Main"）的MP4测试数据
+func mp4ChapterTestData() []byte {
+	box := func(boxType string, payload []byte) []byte {
+		header := make([]byte, 8)
+		binary.BigEndian.PutUint32(header[0:4], uint32(8+len(payload)))
+		copy(header[4:8], boxType)
+		return append(header, payload...)
+	}
+
+	// mvhd版本0：version/flags(4) creation(4) modification(4) timescale(4) duration(4)，其余字段补零
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:16], 1000)
+	binary.BigEndian.PutUint32(mvhd[16:20], 10000)
+
+	chpl := []byte{0, 0, 0, 0, 2}
+	for _, chapter := range []struct {
+		start uint64
+		title string
+	}{{0, "Intro"}, {40000000, "Main"}} {
+		start := make([]byte, 8)
+		binary.BigEndian.PutUint64(start, chapter.start)
+		chpl = append(append(append(chpl, start...), byte(len(chapter.title))), chapter.title...)
+	}
+
+	return bytes.Join([][]byte{
+		box("ftyp", []byte("isom\x00\x00\x02\x00isomavc1")),
+		box("moov", append(box("mvhd", mvhd), box("udta", box("chpl", chpl))...)),
+		box("mdat", make([]byte, 2048)),
+	}, nil)
+}
+
+func TestVideoService_UpdateVideoChapters(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("设置章节", func(t *testing.T) {
+		service := createHistoryTestService(t)
+
+		resp, err := service.UpdateVideoChapters(ctx, &api.VideoChaptersRequest{
+			VideoID: "video1",
+			Chapters: []*api.Chapter{
+				{Title: " 正片 ", StartTime: 30000},
+				{Title: "开场", StartTime: 0, EndTime: 20000},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, []*api.Chapter{
+			{Title: "开场", StartTime: 0, EndTime: 20000},
+			{Title: "正片", StartTime: 30000, EndTime: 100000},
+		}, resp.Video.Chapters, "章节应该按开始时间排序并补全结束时间")
+
+		detail, err := service.GetVideoDetail(ctx, &api.VideoDetailRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		assert.Equal(t, resp.Video.Chapters, detail.Video.Chapters, "详情应该返回章节")
+
+		resp, err = service.UpdateVideoChapters(ctx, &api.VideoChaptersRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Empty(t, resp.Video.Chapters, "空列表应该清除章节")
+		assert.NotNil(t, resp.Video.Chapters)
+	})
+
+	t.Run("章节无效", func(t *testing.T) {
+		service := createHistoryTestService(t)
+
+		invalid := map[string][]*api.Chapter{
+			"标题为空":    {{Title: " ", StartTime: 0}},
+			"开始时间为负数": {{Title: "开场", StartTime: -1}},
+			"超过视频时长":  {{Title: "片尾", StartTime: 100000}},
+			"结束时间过早":  {{Title: "开场", StartTime: 5000, EndTime: 5000}},
+			"开始时间重复":  {{Title: "第一章", StartTime: 0}, {Title: "第二章", StartTime: 0}},
+			"时间重叠":    {{Title: "第一章", StartTime: 0, EndTime: 40000}, {Title: "第二章", StartTime: 30000}},
+		}
+		for name, chapters := range invalid {
+			resp, err := service.UpdateVideoChapters(ctx, &api.VideoChaptersRequest{VideoID: "video1", Chapters: chapters})
+			require.NoError(t, err)
+			assert.Equal(t, int32(4401), resp.Base.Code, name)
+		}
+
+		resp, err := service.UpdateVideoChapters(ctx, &api.VideoChaptersRequest{VideoID: "missing"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4402), resp.Base.Code)
+	})
+
+	t.Run("上传时解析容器中的章节", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+
+		uploaded := uploadTestVideo(t, service, "chapters.mp4", mp4ChapterTestData())
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+		assert.Equal(t, []*api.Chapter{
+			{Title: "Intro", StartTime: 0, EndTime: 4000},
+			{Title: "Main", StartTime: 4000, EndTime: 10000},
+		}, uploaded.Video.Chapters)
+	})
+}
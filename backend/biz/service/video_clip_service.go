@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/quota"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/upload"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+// CreateVideoClip 截取源视频的片段创建新视频，片段属于当前用户并记录来源
+// 默认使用流复制，起点对齐到之前的关键帧；请求precise或流复制失败时重新编码
+func (s *VideoService) CreateVideoClip(ctx context.Context, req *api.VideoClipRequest) (*api.VideoClipResponse, error) {
+	if err := validateVideoClipRequest(req); err != nil {
+		return s.videoClipErrorResponse(7301, err.Error()), nil
+	}
+	visibility, err := s.uploadVisibility(req.Visibility)
+	if err != nil {
+		return s.videoClipErrorResponse(7301, err.Error()), nil
+	}
+
+	source, err := s.getVisibleMetadata(ctx, req.VideoID)
+	if err != nil {
+		return s.videoClipErrorResponse(7302, "视频不存在"), nil
+	}
+	if mediaType, _ := metadata.NormalizeMediaType(source.MediaType); mediaType != metadata.MediaTypeVideo {
+		return s.videoClipErrorResponse(7303, "只能剪辑视频"), nil
+	}
+
+	// 时长未知时按请求的结束时间截取，超出视频结尾的部分由FFmpeg忽略
+	start, end := req.StartTime, req.EndTime
+	if duration := source.Duration * 1000; duration > 0 {
+		if start >= duration {
+			return s.videoClipErrorResponse(7301, "开始时间超出视频时长"), nil
+		}
+		if end > duration {
+			end = duration
+		}
+	}
+	if !s.clipper.IsAvailable() {
+		return s.videoClipErrorResponse(7304, "FFmpeg不可用，无法剪辑视频"), nil
+	}
+
+	source, err = s.restoreArchivedVideo(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	inputPath, err := s.downloadVideoToTemp(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	defer s.tempFiles.Remove(inputPath)
+
+	// 输出使用源视频的容器格式
+	ext := upload.ObjectExtension(source.FileName)
+	if ext == "" {
+		ext = ".mp4"
+	}
+	output, err := s.tempFiles.CreateTemp("zhulong-clip-*" + ext)
+	if err != nil {
+		return nil, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	output.Close()
+	defer s.tempFiles.Remove(output.Name())
+
+	result, err := s.clipper.Clip(ctx, &video.ClipRequest{
+		InputPath:  inputPath,
+		OutputPath: output.Name(),
+		Start:      time.Duration(start) * time.Millisecond,
+		End:        time.Duration(end) * time.Millisecond,
+		Reencode:   req.Precise,
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return s.videoClipErrorResponse(7306, fmt.Sprintf("剪辑视频失败: %v", err)), nil
+	}
+
+	owner := currentUserID(ctx)
+	reservation, err := s.reserveQuota(ctx, owner, result.Size)
+	if errors.Is(err, quota.ErrQuotaExceeded) {
+		return s.videoClipErrorResponse(7305, err.Error()), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer reservation.Release()
+
+	baseName := strings.TrimSuffix(source.FileName, filepath.Ext(source.FileName))
+	if baseName == "" {
+		baseName = source.FileID
+	}
+	fileName := baseName + "-clip" + ext
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = fmt.Sprintf("%s (%s-%s)", source.Title, formatClipTime(start), formatClipTime(end))
+	}
+	clip, err := s.storeVideoClip(ctx, output.Name(), result.Size, &uploadedVideo{
+		VideoID:     uuid.New().String(),
+		BucketName:  s.buckets.Bucket(storage.ContentVideos),
+		FileName:    fileName,
+		ContentType: source.ContentType,
+		Size:        result.Size,
+		Title:       title,
+		Description: req.Description,
+		Visibility:  visibility,
+		CreatedBy:   owner,
+		ClipSource: &metadata.ClipSource{
+			FileID:    source.FileID,
+			StartTime: start,
+			EndTime:   end,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 片段沿用源视频的标签
+	if len(source.Tags) > 0 {
+		if err := s.metadataService.AddTags(ctx, clip.ID, source.Tags); err != nil {
+			return nil, fmt.Errorf("添加标签失败: %w", err)
+		}
+		clip.Tags = source.Tags
+	}
+
+	return &api.VideoClipResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "剪辑成功",
+		},
+		Video:      clip,
+		StreamCopy: result.StreamCopy,
+	}, nil
+}
+
+// storeVideoClip 将剪辑生成的文件写入视频存储桶并登记为新视频，与上传相同经过缩略图生成和HLS打包
+func (s *VideoService) storeVideoClip(ctx context.Context, path string, size int64, clip *uploadedVideo) (*api.Video, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开剪辑结果失败: %w", err)
+	}
+	defer file.Close()
+
+	headData, err := readFileHead(file, videoHeadSize)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("重置文件读取位置失败: %w", err)
+	}
+	checksum, err := upload.ChecksumReader(file)
+	if err != nil {
+		return nil, err
+	}
+
+	// 信息提取失败不阻断剪辑，使用片段时长
+	info, err := s.videoExtractor.ExtractInfo(&video.InfoExtractionRequest{
+		Data:     headData,
+		Filename: clip.FileName,
+		Reader:   file,
+		Size:     size,
+	})
+	if err != nil {
+		info = &video.VideoInfo{
+			Filename: clip.FileName,
+			Format:   strings.TrimPrefix(filepath.Ext(clip.FileName), "."),
+			FileSize: size,
+			Duration: time.Duration(clip.ClipSource.EndTime-clip.ClipSource.StartTime) * time.Millisecond,
+		}
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("重置文件读取位置失败: %w", err)
+	}
+	now := time.Now()
+	result, err := s.uploadService.UploadFile(ctx, &upload.UploadRequest{
+		BucketName:  clip.BucketName,
+		FileName:    clip.FileName,
+		ObjectName:  fmt.Sprintf("videos/%d/%02d/%s%s", now.Year(), now.Month(), clip.VideoID, upload.ObjectExtension(clip.FileName)),
+		Reader:      file,
+		Size:        size,
+		ContentType: clip.ContentType,
+		Checksum:    checksum,
+		Metadata:    videoObjectLabels(clip.VideoID, clip.CreatedBy),
+		Tags:        videoObjectLabels(clip.VideoID, clip.CreatedBy),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("文件上传失败: %w", err)
+	}
+
+	// 重置读取位置，用于抽帧生成缩略图
+	var videoReader io.Reader
+	if _, err := file.Seek(0, io.SeekStart); err == nil {
+		videoReader = file
+	}
+	clip.ObjectName = result.ObjectName
+	clip.Info = info
+	clip.Checksum = checksum
+	clip.HeadData = headData
+	clip.Reader = videoReader
+	return s.finalizeUpload(ctx, clip)
+}
+
+// validateVideoClipRequest 验证剪辑请求
+func validateVideoClipRequest(req *api.VideoClipRequest) error {
+	if req.VideoID == "" {
+		return fmt.Errorf("视频ID不能为空")
+	}
+	if req.StartTime < 0 {
+		return fmt.Errorf("开始时间不能为负数")
+	}
+	if req.EndTime <= req.StartTime {
+		return fmt.Errorf("结束时间必须大于开始时间")
+	}
+	if utf8.RuneCountInString(strings.TrimSpace(req.Title)) > maxVideoTitleLength {
+		return fmt.Errorf("标题长度不能超过%d个字符", maxVideoTitleLength)
+	}
+	if utf8.RuneCountInString(req.Description) > maxVideoDescriptionLength {
+		return fmt.Errorf("描述长度不能超过%d个字符", maxVideoDescriptionLength)
+	}
+	return nil
+}
+
+// formatClipTime 将毫秒格式化为片段标题中的时间，如1:05或1:02:03
+func formatClipTime(ms int64) string {
+	seconds := ms / 1000
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// videoClipErrorResponse 创建剪辑视频错误响应
+func (s *VideoService) videoClipErrorResponse(code int32, message string) *api.VideoClipResponse {
+	return &api.VideoClipResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/tempfile"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+// createClipTestService 创建使用模拟FFmpeg的视频服务，返回源视频
+func createClipTestService(t *testing.T) (*VideoService, *metadata.FileMetadata) {
+	service, _ := createDirectUploadTestService(t)
+	tempFiles, err := tempfile.NewManager(&tempfile.Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+	service.tempFiles = tempFiles
+
+	// 模拟FFmpeg向最后一个参数（输出文件）写入MP4文件头
+	ffmpegPath := filepath.Join(t.TempDir(), "ffmpeg")
+	script := "#!/bin/sh\nfor arg; do out=$arg; done\nprintf '\\000\\000\\000\\040ftypisom' > \"$out\"\n"
+	require.NoError(t, os.WriteFile(ffmpegPath, []byte(script), 0o755))
+	service.clipper = video.NewFFmpegClipper(ffmpegPath)
+
+	_, err = service.SeedDemoVideo(context.Background(), &DemoVideo{
+		FileName:  "source.mp4",
+		Title:     "源视频",
+		Tags:      []string{"demo"},
+		Data:      mp4TestData(4096),
+		Info:      &video.VideoInfo{Duration: 60 * time.Second},
+		CreatedBy: "system",
+	})
+	require.NoError(t, err)
+	source := importedVideos(t, service)["源视频"]
+	require.NotNil(t, source)
+	return service, source
+}
+
+func TestVideoService_CreateVideoClip(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("剪辑片段并记录来源", func(t *testing.T) {
+		service, source := createClipTestService(t)
+
+		resp, err := service.CreateVideoClip(ctx, &api.VideoClipRequest{
+			VideoID:   source.FileID,
+			StartTime: 5000,
+			EndTime:   90000,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.True(t, resp.StreamCopy)
+
+		clip := resp.Video
+		require.NotNil(t, clip)
+		assert.NotEqual(t, source.FileID, clip.ID)
+		assert.Equal(t, "源视频 (0:05-1:00)", clip.Title, "结束时间超出视频时长时截取到结尾")
+		assert.Equal(t, "source-clip.mp4", clip.Filename)
+		assert.Equal(t, []string{"demo"}, clip.Tags)
+		require.NotNil(t, clip.ClipSource)
+		assert.Equal(t, source.FileID, clip.ClipSource.VideoID)
+		assert.Equal(t, int64(5000), clip.ClipSource.StartTime)
+		assert.Equal(t, int64(60000), clip.ClipSource.EndTime)
+
+		saved, err := service.metadataService.GetMetadata(ctx, clip.ID)
+		require.NoError(t, err)
+		require.NotNil(t, saved.ClipSource)
+		assert.Equal(t, source.FileID, saved.ClipSource.FileID)
+	})
+
+	t.Run("指定标题", func(t *testing.T) {
+		service, source := createClipTestService(t)
+		resp, err := service.CreateVideoClip(ctx, &api.VideoClipRequest{
+			VideoID:   source.FileID,
+			StartTime: 0,
+			EndTime:   3000,
+			Title:     "精彩片段",
+			Precise:   true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, "精彩片段", resp.Video.Title)
+		assert.False(t, resp.StreamCopy, "指定精确剪辑时重新编码")
+	})
+
+	t.Run("时间范围无效", func(t *testing.T) {
+		service, source := createClipTestService(t)
+		for name, req := range map[string]*api.VideoClipRequest{
+			"开始时间为负数":   {VideoID: source.FileID, StartTime: -1, EndTime: 1000},
+			"结束时间不大于开始": {VideoID: source.FileID, StartTime: 2000, EndTime: 2000},
+			"开始时间超出时长":  {VideoID: source.FileID, StartTime: 60000, EndTime: 70000},
+		} {
+			resp, err := service.CreateVideoClip(ctx, req)
+			require.NoError(t, err)
+			assert.Equal(t, int32(7301), resp.Base.Code, name)
+		}
+	})
+
+	t.Run("视频不存在", func(t *testing.T) {
+		service, _ := createClipTestService(t)
+		resp, err := service.CreateVideoClip(ctx, &api.VideoClipRequest{VideoID: "missing", EndTime: 1000})
+		require.NoError(t, err)
+		assert.Equal(t, int32(7302), resp.Base.Code)
+	})
+
+	t.Run("只能剪辑视频", func(t *testing.T) {
+		service, _ := createClipTestService(t)
+		require.NoError(t, service.metadataService.SaveMetadata(ctx, &metadata.FileMetadata{
+			FileID:     "image1",
+			FileName:   "cover.jpg",
+			Title:      "封面",
+			MediaType:  metadata.MediaTypeImage,
+			Visibility: metadata.VisibilityPublic,
+			CreatedBy:  "system",
+		}))
+		resp, err := service.CreateVideoClip(ctx, &api.VideoClipRequest{VideoID: "image1", EndTime: 1000})
+		require.NoError(t, err)
+		assert.Equal(t, int32(7303), resp.Base.Code)
+	})
+
+	t.Run("FFmpeg不可用", func(t *testing.T) {
+		service, source := createClipTestService(t)
+		service.clipper = video.NewFFmpegClipper(filepath.Join(t.TempDir(), "missing-ffmpeg"))
+		resp, err := service.CreateVideoClip(ctx, &api.VideoClipRequest{VideoID: source.FileID, EndTime: 1000})
+		require.NoError(t, err)
+		assert.Equal(t, int32(7304), resp.Base.Code)
+	})
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/collection"
+)
+
+// maxCollectionVideosPerRequest 单次加入或移出合集的最大视频数量
+const maxCollectionVideosPerRequest = 100
+
+// CreateCollection 创建合集
+func (s *VideoService) CreateCollection(ctx context.Context, req *api.CollectionCreateRequest) (*api.CollectionResponse, error) {
+	if !canCreateCollection(ctx) {
+		return s.collectionCodeResponse(4205, "无权创建合集"), nil
+	}
+
+	created, err := s.collections.Create(ctx, &collection.CreateRequest{
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedBy:   currentUserID(ctx),
+	})
+	if err != nil {
+		return s.collectionErrorResponse(err), nil
+	}
+
+	return s.collectionResponse("创建成功", created), nil
+}
+
+// ListCollections 列出所有合集
+func (s *VideoService) ListCollections(ctx context.Context) (*api.CollectionListResponse, error) {
+	collections := s.collections.List(ctx)
+
+	apiCollections := make([]*api.Collection, 0, len(collections))
+	for _, item := range collections {
+		apiCollections = append(apiCollections, convertToAPICollection(item))
+	}
+
+	return &api.CollectionListResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Collections: apiCollections,
+	}, nil
+}
+
+// UpdateCollection 重命名合集或修改描述
+func (s *VideoService) UpdateCollection(ctx context.Context, req *api.CollectionUpdateRequest) (*api.CollectionResponse, error) {
+	if req.CollectionID == "" {
+		return s.collectionCodeResponse(4201, "合集ID不能为空"), nil
+	}
+	if req.Name == nil && req.Description == nil {
+		return s.collectionCodeResponse(4201, "至少需要更新一个字段"), nil
+	}
+	if resp := s.checkCollectionOwner(ctx, req.CollectionID); resp != nil {
+		return resp, nil
+	}
+
+	updated, err := s.collections.Update(ctx, req.CollectionID, &collection.UpdateRequest{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		return s.collectionErrorResponse(err), nil
+	}
+
+	return s.collectionResponse("更新成功", updated), nil
+}
+
+// DeleteCollection 删除合集，合集中的视频不会被删除
+func (s *VideoService) DeleteCollection(ctx context.Context, req *api.CollectionDeleteRequest) (*api.CollectionResponse, error) {
+	if req.CollectionID == "" {
+		return s.collectionCodeResponse(4201, "合集ID不能为空"), nil
+	}
+	if resp := s.checkCollectionOwner(ctx, req.CollectionID); resp != nil {
+		return resp, nil
+	}
+
+	if err := s.collections.Delete(ctx, req.CollectionID); err != nil {
+		return s.collectionErrorResponse(err), nil
+	}
+
+	return s.collectionCodeResponse(0, "删除成功"), nil
+}
+
+// AddCollectionVideos 将视频加入合集，所有视频都必须存在且当前用户可以查看
+func (s *VideoService) AddCollectionVideos(ctx context.Context, req *api.CollectionVideosRequest) (*api.CollectionResponse, error) {
+	if resp := s.validateCollectionVideosRequest(req); resp != nil {
+		return resp, nil
+	}
+	if resp := s.checkCollectionOwner(ctx, req.CollectionID); resp != nil {
+		return resp, nil
+	}
+
+	for _, videoID := range req.VideoIds {
+		if _, err := s.getVisibleMetadata(ctx, videoID); err != nil {
+			return s.collectionCodeResponse(4204, fmt.Sprintf("视频不存在: %s", videoID)), nil
+		}
+	}
+
+	updated, err := s.collections.AddVideos(ctx, req.CollectionID, req.VideoIds)
+	if err != nil {
+		return s.collectionErrorResponse(err), nil
+	}
+
+	return s.collectionResponse("加入成功", updated), nil
+}
+
+// RemoveCollectionVideos 将视频移出合集，不在合集中的视频会被忽略
+func (s *VideoService) RemoveCollectionVideos(ctx context.Context, req *api.CollectionVideosRequest) (*api.CollectionResponse, error) {
+	if resp := s.validateCollectionVideosRequest(req); resp != nil {
+		return resp, nil
+	}
+	if resp := s.checkCollectionOwner(ctx, req.CollectionID); resp != nil {
+		return resp, nil
+	}
+
+	updated, err := s.collections.RemoveVideos(ctx, req.CollectionID, req.VideoIds)
+	if err != nil {
+		return s.collectionErrorResponse(err), nil
+	}
+
+	return s.collectionResponse("移出成功", updated), nil
+}
+
+// validateCollectionVideosRequest 验证合集视频修改请求，验证失败时返回错误响应
+func (s *VideoService) validateCollectionVideosRequest(req *api.CollectionVideosRequest) *api.CollectionResponse {
+	if req.CollectionID == "" {
+		return s.collectionCodeResponse(4201, "合集ID不能为空")
+	}
+	if len(req.VideoIds) == 0 {
+		return s.collectionCodeResponse(4201, "视频ID列表不能为空")
+	}
+	if len(req.VideoIds) > maxCollectionVideosPerRequest {
+		return s.collectionCodeResponse(4201, fmt.Sprintf("单次最多修改%d个视频", maxCollectionVideosPerRequest))
+	}
+	return nil
+}
+
+// checkCollectionOwner 检查当前用户是否可以修改合集，合集不存在或无权修改时返回错误响应
+func (s *VideoService) checkCollectionOwner(ctx context.Context, collectionID string) *api.CollectionResponse {
+	found, err := s.collections.Get(ctx, collectionID)
+	if err != nil {
+		return s.collectionErrorResponse(err)
+	}
+	if !canModifyCollection(ctx, found.CreatedBy) {
+		return s.collectionCodeResponse(4205, "无权修改其他用户的合集")
+	}
+	return nil
+}
+
+// convertToAPICollection 转换为API合集格式
+func convertToAPICollection(item *collection.Collection) *api.Collection {
+	return &api.Collection{
+		ID:          item.ID,
+		Name:        item.Name,
+		Description: item.Description,
+		VideoCount:  int32(item.VideoCount),
+		CreatedBy:   item.CreatedBy,
+		CreatedAt:   item.CreatedAt.UnixMilli(),
+		UpdatedAt:   item.UpdatedAt.UnixMilli(),
+	}
+}
+
+// collectionResponse 创建包含合集信息的成功响应
+func (s *VideoService) collectionResponse(message string, item *collection.Collection) *api.CollectionResponse {
+	resp := s.collectionCodeResponse(0, message)
+	resp.Collection = convertToAPICollection(item)
+	return resp
+}
+
+// collectionErrorResponse 根据合集服务返回的错误创建错误响应
+func (s *VideoService) collectionErrorResponse(err error) *api.CollectionResponse {
+	switch {
+	case errors.Is(err, collection.ErrCollectionNotFound):
+		return s.collectionCodeResponse(4202, err.Error())
+	case errors.Is(err, collection.ErrCollectionExists):
+		return s.collectionCodeResponse(4203, err.Error())
+	default:
+		return s.collectionCodeResponse(4201, err.Error())
+	}
+}
+
+// collectionCodeResponse 创建合集响应
+func (s *VideoService) collectionCodeResponse(code int32, message string) *api.CollectionResponse {
+	return &api.CollectionResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/delete"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// collectionOwnerContext 创建合集的上传者的上下文
+func collectionOwnerContext() context.Context {
+	return user.ContextWithClaims(context.Background(), &user.Claims{UserID: "uploader-1", Role: user.RoleUploader})
+}
+
+// createCollectionTestService 创建带测试视频和合集的视频服务
+func createCollectionTestService(t *testing.T) (*VideoService, string) {
+	service := createTestVideoService(t)
+	for _, id := range []string{"video1", "video2", "video3"} {
+		require.NoError(t, service.metadataService.SaveMetadata(context.Background(), &metadata.FileMetadata{
+			FileID:     id,
+			Title:      id,
+			Visibility: metadata.VisibilityPublic,
+			CreatedBy:  "system",
+		}))
+	}
+
+	resp, err := service.CreateCollection(collectionOwnerContext(), &api.CollectionCreateRequest{Name: "旅行"})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+	return service, resp.Collection.ID
+}
+
+func TestVideoService_Collections(t *testing.T) {
+	ctx := collectionOwnerContext()
+
+	t.Run("创建合集", func(t *testing.T) {
+		service, _ := createCollectionTestService(t)
+
+		resp, err := service.CreateCollection(ctx, &api.CollectionCreateRequest{Name: "音乐", Description: "音乐视频"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, "音乐", resp.Collection.Name)
+		assert.Equal(t, "uploader-1", resp.Collection.CreatedBy)
+
+		resp, err = service.CreateCollection(ctx, &api.CollectionCreateRequest{Name: "旅行"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4203), resp.Base.Code, "重名合集应该返回冲突")
+
+		resp, err = service.CreateCollection(ctx, &api.CollectionCreateRequest{Name: " "})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4201), resp.Base.Code)
+
+		listResp, err := service.ListCollections(ctx)
+		require.NoError(t, err)
+		require.Len(t, listResp.Collections, 2)
+		assert.Equal(t, "旅行", listResp.Collections[0].Name)
+	})
+
+	t.Run("重命名和删除合集", func(t *testing.T) {
+		service, collectionID := createCollectionTestService(t)
+
+		resp, err := service.UpdateCollection(ctx, &api.CollectionUpdateRequest{CollectionID: collectionID, Name: stringPtr("旅行Vlog")})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, "旅行Vlog", resp.Collection.Name)
+
+		resp, err = service.UpdateCollection(ctx, &api.CollectionUpdateRequest{CollectionID: collectionID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4201), resp.Base.Code, "未传入任何字段应该返回参数错误")
+
+		resp, err = service.DeleteCollection(ctx, &api.CollectionDeleteRequest{CollectionID: collectionID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+		resp, err = service.DeleteCollection(ctx, &api.CollectionDeleteRequest{CollectionID: collectionID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4202), resp.Base.Code)
+
+		_, err = service.metadataService.GetMetadata(ctx, "video1")
+		assert.NoError(t, err, "删除合集不应该删除视频")
+	})
+
+	t.Run("按合集列出视频", func(t *testing.T) {
+		service, collectionID := createCollectionTestService(t)
+
+		resp, err := service.AddCollectionVideos(ctx, &api.CollectionVideosRequest{CollectionID: collectionID, VideoIds: []string{"video1", "video3"}})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, int32(2), resp.Collection.VideoCount)
+
+		listResp, err := service.GetVideoList(ctx, &api.VideoListRequest{CollectionID: collectionID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), listResp.Base.Code, listResp.Base.Message)
+		ids := []string{}
+		for _, video := range listResp.Videos {
+			ids = append(ids, video.ID)
+		}
+		assert.ElementsMatch(t, []string{"video1", "video3"}, ids)
+
+		resp, err = service.RemoveCollectionVideos(ctx, &api.CollectionVideosRequest{CollectionID: collectionID, VideoIds: []string{"video1", "video3"}})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+		listResp, err = service.GetVideoList(ctx, &api.VideoListRequest{CollectionID: collectionID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), listResp.Total, "空合集不应该返回任何视频")
+
+		listResp, err = service.GetVideoList(ctx, &api.VideoListRequest{CollectionID: "missing"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2003), listResp.Base.Code)
+	})
+
+	t.Run("加入合集_视频不存在", func(t *testing.T) {
+		service, collectionID := createCollectionTestService(t)
+
+		resp, err := service.AddCollectionVideos(ctx, &api.CollectionVideosRequest{CollectionID: collectionID, VideoIds: []string{"video1", "missing"}})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4204), resp.Base.Code)
+
+		collected, err := service.collections.ListVideoIDs(ctx, collectionID)
+		require.NoError(t, err)
+		assert.Empty(t, collected, "部分视频不存在时不应该加入任何视频")
+
+		resp, err = service.AddCollectionVideos(ctx, &api.CollectionVideosRequest{CollectionID: collectionID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4201), resp.Base.Code)
+	})
+
+	t.Run("加入合集_无权查看的视频", func(t *testing.T) {
+		service, collectionID := createCollectionTestService(t)
+		require.NoError(t, service.metadataService.SaveMetadata(ctx, &metadata.FileMetadata{
+			FileID:     "private1",
+			Title:      "私有视频",
+			Visibility: metadata.VisibilityPrivate,
+			CreatedBy:  "uploader-2",
+		}))
+
+		resp, err := service.AddCollectionVideos(ctx, &api.CollectionVideosRequest{CollectionID: collectionID, VideoIds: []string{"private1"}})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4204), resp.Base.Code, "其他用户的私有视频应该按不存在处理")
+	})
+
+	t.Run("删除视频时移出合集", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		service.deleteService = delete.NewDeleteService(store)
+
+		uploaded := uploadTestVideo(t, service, "first.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+
+		created, err := service.CreateCollection(ctx, &api.CollectionCreateRequest{Name: "旅行"})
+		require.NoError(t, err)
+		_, err = service.AddCollectionVideos(ctx, &api.CollectionVideosRequest{CollectionID: created.Collection.ID, VideoIds: []string{uploaded.Video.ID}})
+		require.NoError(t, err)
+
+		deleteResp, err := service.DeleteVideo(context.Background(), &api.VideoDeleteRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), deleteResp.Base.Code, deleteResp.Base.Message)
+
+		collected, err := service.collections.ListVideoIDs(ctx, created.Collection.ID)
+		require.NoError(t, err)
+		assert.Empty(t, collected, "删除的视频应该移出合集")
+	})
+}
+
+func TestVideoService_CollectionPermissions(t *testing.T) {
+	anonymous := context.Background()
+	viewer := user.ContextWithClaims(anonymous, &user.Claims{UserID: "viewer-1", Role: user.RoleViewer})
+	other := user.ContextWithClaims(anonymous, &user.Claims{UserID: "uploader-2", Role: user.RoleUploader})
+	admin := user.ContextWithClaims(anonymous, &user.Claims{UserID: "admin-1", Role: user.RoleAdmin})
+
+	t.Run("未登录和观看者不能创建合集", func(t *testing.T) {
+		service, _ := createCollectionTestService(t)
+		for name, ctx := range map[string]context.Context{"未登录": anonymous, "观看者": viewer} {
+			resp, err := service.CreateCollection(ctx, &api.CollectionCreateRequest{Name: "音乐"})
+			require.NoError(t, err)
+			assert.Equal(t, int32(4205), resp.Base.Code, name)
+		}
+	})
+
+	t.Run("不能修改其他用户的合集", func(t *testing.T) {
+		service, collectionID := createCollectionTestService(t)
+		for name, ctx := range map[string]context.Context{"未登录": anonymous, "其他上传者": other} {
+			resp, err := service.UpdateCollection(ctx, &api.CollectionUpdateRequest{CollectionID: collectionID, Name: stringPtr("改名")})
+			require.NoError(t, err)
+			assert.Equal(t, int32(4205), resp.Base.Code, name)
+
+			resp, err = service.AddCollectionVideos(ctx, &api.CollectionVideosRequest{CollectionID: collectionID, VideoIds: []string{"video1"}})
+			require.NoError(t, err)
+			assert.Equal(t, int32(4205), resp.Base.Code, name)
+
+			resp, err = service.RemoveCollectionVideos(ctx, &api.CollectionVideosRequest{CollectionID: collectionID, VideoIds: []string{"video1"}})
+			require.NoError(t, err)
+			assert.Equal(t, int32(4205), resp.Base.Code, name)
+
+			resp, err = service.DeleteCollection(ctx, &api.CollectionDeleteRequest{CollectionID: collectionID})
+			require.NoError(t, err)
+			assert.Equal(t, int32(4205), resp.Base.Code, name)
+		}
+
+		found, err := service.collections.Get(context.Background(), collectionID)
+		require.NoError(t, err)
+		assert.Equal(t, "旅行", found.Name, "无权修改时合集应该保持不变")
+		assert.Zero(t, found.VideoCount)
+	})
+
+	t.Run("管理员可以修改任意合集", func(t *testing.T) {
+		service, collectionID := createCollectionTestService(t)
+		resp, err := service.UpdateCollection(admin, &api.CollectionUpdateRequest{CollectionID: collectionID, Name: stringPtr("精选")})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+		resp, err = service.DeleteCollection(admin, &api.CollectionDeleteRequest{CollectionID: collectionID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+	})
+}
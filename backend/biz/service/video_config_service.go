@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/media"
+	"github.com/manteia/zhulong/pkg/user"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+// newUploadValidators 根据上传配置创建格式验证器和大小限制
+func newUploadValidators(cfg *config.Config) (*video.VideoValidator, *video.SizeLimitManager, error) {
+	videoValidator, err := video.NewVideoValidatorFromConfig(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("初始化视频验证器失败: %v", err)
+	}
+	sizeLimitManager := video.NewSizeLimitManager()
+	sizeLimitManager.SetMaxFileSize(videoValidator.GetMaxFileSize())
+
+	formatLimits, err := cfg.GetUploadFormatMaxSizes()
+	if err != nil {
+		return nil, nil, err
+	}
+	for format := range formatLimits {
+		if !slices.Contains(video.KnownFormats, format) && !slices.Contains(media.KnownFormats, format) {
+			return nil, nil, fmt.Errorf("按格式的上传文件大小限制无效: 不支持的格式: %s", format)
+		}
+	}
+	roleLimits, err := cfg.GetUploadRoleMaxSizes()
+	if err != nil {
+		return nil, nil, err
+	}
+	sizeLimitManager.SetFormatLimits(formatLimits)
+	sizeLimitManager.SetRoleLimits(roleLimits)
+	return videoValidator, sizeLimitManager, nil
+}
+
+// validateUploadSize 按文件格式和当前用户角色验证上传文件大小，格式取自文件扩展名
+func (s *VideoService) validateUploadSize(ctx context.Context, fileName string, size int64) error {
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")
+	return s.sizeLimitManager.Load().ValidateSizeFor(format, currentRole(ctx), size)
+}
+
+// MaxUploadSize 获取当前用户上传任意格式时允许的最大文件大小，未登录时返回所有角色中最大的限制
+// 格式需要读取请求体后才能确定，读取前只能按上限拒绝
+func (s *VideoService) MaxUploadSize(ctx context.Context) int64 {
+	return s.sizeLimitManager.Load().MaxUploadSize(currentRole(ctx))
+}
+
+// currentRole 获取当前用户的角色，未登录时返回空
+func currentRole(ctx context.Context) string {
+	if claims, ok := user.ClaimsFromContext(ctx); ok {
+		return claims.Role
+	}
+	return ""
+}
+
+// ApplyConfig 应用重新加载的上传配置：单个视频最大大小、允许的内容类型、格式和媒体类型
+// 替换后的验证使用新的限制，正在传输的上传不会中断；配置无效时返回错误并保留原来的限制
+func (s *VideoService) ApplyConfig(cfg *config.Config) error {
+	videoValidator, sizeLimitManager, err := newUploadValidators(cfg)
+	if err != nil {
+		return err
+	}
+	mediaPolicy, err := newMediaUploadPolicy(cfg)
+	if err != nil {
+		return err
+	}
+	s.videoValidator.Store(videoValidator)
+	s.mediaPolicy.Store(mediaPolicy)
+	s.sizeLimitManager.Store(sizeLimitManager)
+	return nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// TestVideoService_ApplyConfig 测试重新加载上传大小和格式限制
+func TestVideoService_ApplyConfig(t *testing.T) {
+	ctx := context.Background()
+	service, _ := createDirectUploadTestService(t)
+	createURL := func(filename string, size int64) int32 {
+		resp, err := service.CreateUploadURL(ctx, &api.VideoUploadURLRequest{Filename: filename, Size: size})
+		require.NoError(t, err)
+		return resp.Base.Code
+	}
+	require.Equal(t, int32(0), createURL("clip.mp4", 2*1024*1024))
+	require.Equal(t, int32(0), createURL("clip.webm", 1024))
+
+	cfg := &config.Config{}
+	cfg.Upload.MaxSize = "1MB"
+	cfg.Upload.AllowedFormats = "mp4"
+	require.NoError(t, service.ApplyConfig(cfg))
+	assert.Equal(t, int32(1003), createURL("clip.mp4", 2*1024*1024), "应该使用新的大小限制")
+	assert.Equal(t, int32(1005), createURL("clip.webm", 1024), "应该使用新的允许格式")
+	assert.Equal(t, int32(0), createURL("clip.mp4", 1024))
+
+	cfg = &config.Config{}
+	cfg.Upload.AllowedFormats = "mp4,unknown"
+	assert.Error(t, service.ApplyConfig(cfg))
+	assert.Equal(t, int32(1005), createURL("clip.webm", 1024), "配置无效时保留原来的限制")
+}
+
+// TestVideoService_UploadSizeLimitsByFormatAndRole 测试按格式和用户角色的上传大小限制
+func TestVideoService_UploadSizeLimitsByFormatAndRole(t *testing.T) {
+	const MB = int64(1024 * 1024)
+	service, _ := createDirectUploadTestService(t)
+	createURL := func(ctx context.Context, filename string, size int64) int32 {
+		resp, err := service.CreateUploadURL(ctx, &api.VideoUploadURLRequest{Filename: filename, Size: size})
+		require.NoError(t, err)
+		return resp.Base.Code
+	}
+
+	cfg := &config.Config{}
+	cfg.Upload.MaxSize = "10MB"
+	cfg.Upload.FormatMaxSizes = map[string]string{"MP4": "20MB", "webm": "5MB"}
+	cfg.Upload.RoleMaxSizes = map[string]string{user.RoleUploader: "8MB"}
+	require.NoError(t, service.ApplyConfig(cfg))
+
+	admin := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "admin", Role: user.RoleAdmin})
+	uploader := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "uploader", Role: user.RoleUploader})
+
+	assert.Equal(t, int32(0), createURL(admin, "clip.mp4", 15*MB), "格式限制代替全局限制")
+	assert.Equal(t, int32(1003), createURL(admin, "clip.webm", 6*MB), "超过格式限制")
+	assert.Equal(t, int32(1003), createURL(admin, "clip.mkv", 11*MB), "未配置格式时使用全局限制")
+	assert.Equal(t, int32(1003), createURL(uploader, "clip.mp4", 9*MB), "同时匹配时使用较小的角色限制")
+	assert.Equal(t, int32(1003), createURL(uploader, "clip.webm", 6*MB), "同时匹配时使用较小的格式限制")
+	assert.Equal(t, int32(0), createURL(uploader, "clip.mkv", 8*MB))
+	assert.Equal(t, 20*MB, service.MaxUploadSize(admin))
+	assert.Equal(t, 8*MB, service.MaxUploadSize(uploader))
+	assert.Equal(t, 20*MB, service.MaxUploadSize(context.Background()), "未登录时使用所有角色中最大的限制")
+
+	cfg.Upload.FormatMaxSizes = map[string]string{"rmvb": "1GB"}
+	assert.Error(t, service.ApplyConfig(cfg), "不支持的格式应该返回错误")
+	cfg.Upload.FormatMaxSizes = nil
+	cfg.Upload.RoleMaxSizes = map[string]string{"guest": "1GB"}
+	assert.Error(t, service.ApplyConfig(cfg), "不存在的角色应该返回错误")
+	assert.Equal(t, int32(0), createURL(admin, "clip.mp4", 15*MB), "配置无效时保留原来的限制")
+}
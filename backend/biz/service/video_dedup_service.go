@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+// findDuplicateVideo 根据内容校验和查找已存在的视频
+// 未开启重复检测或没有相同内容的视频时返回nil；共享已归档的文件前先恢复，恢复失败时按新视频处理
+func (s *VideoService) findDuplicateVideo(ctx context.Context, checksum string) *metadata.FileMetadata {
+	mode := s.config.GetDeduplicationMode()
+	if checksum == "" || mode == config.DeduplicationOff {
+		return nil
+	}
+
+	existing, err := s.metadataService.GetMetadataByChecksum(ctx, checksum)
+	if err != nil {
+		return nil
+	}
+	if mode == config.DeduplicationAlias {
+		restored, err := s.restoreArchivedVideo(ctx, existing)
+		if err != nil {
+			fmt.Printf("恢复重复视频的归档文件失败(%s): %v\n", existing.FileID, err)
+			return nil
+		}
+		existing = restored
+	}
+	return existing
+}
+
+// duplicateVideoResponse 拒绝重复上传，当前用户可以查看已存在的视频时在响应中返回该视频
+// 其他用户的私有视频只提示已存在，不返回视频信息
+func (s *VideoService) duplicateVideoResponse(ctx context.Context, existing *metadata.FileMetadata) *api.VideoUploadResponse {
+	if !existing.VisibleTo(currentViewer(ctx)) {
+		return s.errorResponse(1011, "视频已存在")
+	}
+	resp := s.errorResponse(1011, fmt.Sprintf("视频已存在: %s", existing.FileID))
+	resp.Video = convertToAPIVideo(existing)
+	return resp
+}
+
+// isSharedObject 判断视频文件是否被其他视频记录共享
+func (s *VideoService) isSharedObject(ctx context.Context, meta *metadata.FileMetadata) bool {
+	return s.metadataService.CountObjectReferences(ctx, meta.BucketName, meta.ObjectName) > 1
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/manteia/zhulong/pkg/storage"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/delete"
+)
+
+// createDedupTestService 创建开启重复检测的视频服务
+func createDedupTestService(t *testing.T, mode string) (*VideoService, *storage.MemoryStorage) {
+	service, store := createDirectUploadTestService(t)
+	service.config.Upload.Deduplication = mode
+	service.deleteService = delete.NewDeleteService(store)
+	return service, store
+}
+
+// uploadTestVideo 上传测试视频并返回响应
+func uploadTestVideo(t *testing.T, service *VideoService, filename string, data []byte) *api.VideoUploadResponse {
+	resp, err := service.UploadVideo(context.Background(), &api.VideoUploadRequest{}, createTestFileHeader(t, filename, "video/mp4", data))
+	require.NoError(t, err)
+	return resp
+}
+
+func TestVideoService_DuplicateUpload(t *testing.T) {
+	ctx := context.Background()
+	data := mp4TestData(2048)
+
+	t.Run("重复上传_未开启检测", func(t *testing.T) {
+		service, store := createDedupTestService(t, config.DeduplicationOff)
+
+		first := uploadTestVideo(t, service, "first.mp4", data)
+		require.Equal(t, int32(0), first.Base.Code, first.Base.Message)
+		second := uploadTestVideo(t, service, "second.mp4", data)
+		require.Equal(t, int32(0), second.Base.Code, second.Base.Message)
+
+		assert.NotEqual(t, first.Video.StoragePath, second.Video.StoragePath)
+		assert.Equal(t, 2, countObjects(t, store, "videos/"), "未开启检测时应该分别存储")
+	})
+
+	t.Run("重复上传_拒绝", func(t *testing.T) {
+		service, store := createDedupTestService(t, config.DeduplicationReject)
+
+		first := uploadTestVideo(t, service, "first.mp4", data)
+		require.Equal(t, int32(0), first.Base.Code, first.Base.Message)
+
+		second := uploadTestVideo(t, service, "second.mp4", data)
+		assert.Equal(t, int32(1011), second.Base.Code)
+		require.NotNil(t, second.Video, "应该返回已存在的视频")
+		assert.Equal(t, first.Video.ID, second.Video.ID)
+		assert.Equal(t, 1, countObjects(t, store, "videos/"), "重复的视频不应该写入存储")
+
+		other := uploadTestVideo(t, service, "other.mp4", mp4TestData(4096))
+		assert.Equal(t, int32(0), other.Base.Code, "内容不同的视频应该上传成功")
+	})
+
+	t.Run("重复上传_共享存储对象", func(t *testing.T) {
+		service, store := createDedupTestService(t, config.DeduplicationAlias)
+
+		first := uploadTestVideo(t, service, "first.mp4", data)
+		require.Equal(t, int32(0), first.Base.Code, first.Base.Message)
+
+		second := uploadTestVideo(t, service, "second.mp4", data)
+		require.Equal(t, int32(0), second.Base.Code, second.Base.Message)
+		assert.NotEqual(t, first.Video.ID, second.Video.ID, "应该创建新的视频记录")
+		assert.Equal(t, first.Video.StoragePath, second.Video.StoragePath, "应该共享同一存储对象")
+		assert.Equal(t, "second.mp4", second.Video.Filename)
+		assert.Equal(t, first.Video.Checksum, second.Video.Checksum)
+		assert.Equal(t, 1, countObjects(t, store, "videos/"), "重复的视频不应该写入存储")
+
+		resp, err := service.DeleteVideo(ctx, &api.VideoDeleteRequest{VideoID: first.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Contains(t, testObjectNames(t, store, ""), first.Video.StoragePath, "仍被其他视频引用的文件不应该删除")
+
+		resp, err = service.DeleteVideo(ctx, &api.VideoDeleteRequest{VideoID: second.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.NotContains(t, testObjectNames(t, store, ""), first.Video.StoragePath, "最后一个引用删除后应该删除文件")
+	})
+
+	t.Run("重复上传_校验和不一致", func(t *testing.T) {
+		service, store := createDedupTestService(t, config.DeduplicationAlias)
+
+		req := &api.VideoUploadRequest{Checksum: sha256Hex([]byte("other"))}
+		resp, err := service.UploadVideo(ctx, req, createTestFileHeader(t, "first.mp4", "video/mp4", data))
+		require.NoError(t, err)
+		assert.Equal(t, int32(1010), resp.Base.Code)
+		assert.Empty(t, testObjectNames(t, store, ""))
+	})
+}
+
+func TestVideoService_DuplicateConfirmUpload(t *testing.T) {
+	ctx := context.Background()
+	data := mp4TestData(2048)
+
+	t.Run("确认上传_拒绝重复视频", func(t *testing.T) {
+		service, store := createDedupTestService(t, config.DeduplicationReject)
+		first := uploadTestVideo(t, service, "first.mp4", data)
+		require.Equal(t, int32(0), first.Base.Code, first.Base.Message)
+
+		urlResp := createTestUploadURL(t, service, 2048)
+		session, err := service.directUploads.GetSession(urlResp.UploadToken)
+		require.NoError(t, err)
+		putTestObject(t, store, session.ObjectName, data)
+
+		resp, err := service.ConfirmUpload(ctx, &api.VideoUploadConfirmRequest{UploadToken: urlResp.UploadToken})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1011), resp.Base.Code)
+		assert.Equal(t, first.Video.ID, resp.Video.ID)
+		assert.NotContains(t, testObjectNames(t, store, ""), session.ObjectName, "重复的直传文件应该被删除")
+
+		_, err = service.metadataService.GetMetadata(ctx, urlResp.VideoID)
+		assert.Error(t, err, "不应该保存重复视频的元数据")
+	})
+
+	t.Run("确认上传_共享存储对象", func(t *testing.T) {
+		service, store := createDedupTestService(t, config.DeduplicationAlias)
+		first := uploadTestVideo(t, service, "first.mp4", data)
+		require.Equal(t, int32(0), first.Base.Code, first.Base.Message)
+
+		urlResp := createTestUploadURL(t, service, 2048)
+		session, err := service.directUploads.GetSession(urlResp.UploadToken)
+		require.NoError(t, err)
+		putTestObject(t, store, session.ObjectName, data)
+
+		resp, err := service.ConfirmUpload(ctx, &api.VideoUploadConfirmRequest{UploadToken: urlResp.UploadToken})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, urlResp.VideoID, resp.Video.ID)
+		assert.Equal(t, first.Video.StoragePath, resp.Video.StoragePath, "应该共享已存在视频的存储对象")
+		assert.NotContains(t, testObjectNames(t, store, ""), session.ObjectName, "重复的直传文件应该被删除")
+		assert.Equal(t, 1, countObjects(t, store, "videos/"))
+	})
+}
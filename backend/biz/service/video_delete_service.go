@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/delete"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/notify"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/streaming"
+)
+
+// videoDeleteBatchSize 单次批量删除的对象数量上限
+const videoDeleteBatchSize = 1000
+
+// DeleteVideo 删除视频及其关联的缩略图、预览图、动态预览、HLS文件和元数据
+// 存储对象全部删除成功后才删除元数据；部分失败时保留元数据以便重试，并返回失败详情
+func (s *VideoService) DeleteVideo(ctx context.Context, req *api.VideoDeleteRequest) (*api.VideoDeleteResponse, error) {
+	if req.VideoID == "" {
+		return s.videoDeleteErrorResponse(3001, "视频ID不能为空"), nil
+	}
+
+	meta, err := s.metadataService.GetMetadata(ctx, req.VideoID)
+	if err != nil {
+		return s.videoDeleteErrorResponse(3002, "视频不存在"), nil
+	}
+	if !canModifyVideo(ctx, meta) {
+		return s.videoDeleteErrorResponse(3005, "无权删除其他用户的视频"), nil
+	}
+
+	if s.isPackagingVideo(meta.FileID) {
+		return s.videoDeleteErrorResponse(3004, "视频正在进行HLS打包，请稍后重试"), nil
+	}
+	s.cancelPendingTranscode(meta.FileID)
+
+	groups, err := s.collectVideoObjects(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	deletedCount := 0
+	failures := make([]*api.DeleteFailure, 0)
+
+	// 已归档的视频文件在归档存储桶中，单独删除
+	if meta.Archived && s.archiver != nil && !s.isSharedObject(ctx, meta) {
+		if err := s.archiver.Delete(ctx, meta.ObjectName); err != nil {
+			failures = append(failures, &api.DeleteFailure{
+				ObjectName: meta.ObjectName,
+				Error:      err.Error(),
+			})
+		} else {
+			deletedCount++
+		}
+	}
+
+	for _, group := range groups {
+		objectNames := group.ObjectNames
+		for start := 0; start < len(objectNames); start += videoDeleteBatchSize {
+			end := min(start+videoDeleteBatchSize, len(objectNames))
+
+			batchResult, err := s.deleteService.DeleteMultipleFiles(ctx, &delete.BatchDeleteRequest{
+				BucketName:  group.BucketName,
+				ObjectNames: objectNames[start:end],
+			})
+			if err != nil {
+				return nil, fmt.Errorf("删除视频文件失败: %w", err)
+			}
+
+			for _, result := range batchResult.Results {
+				switch {
+				case result.Success:
+					deletedCount++
+				case result.NotFound:
+					// 对象已不存在，视为已删除
+				default:
+					failures = append(failures, &api.DeleteFailure{
+						ObjectName: result.ObjectName,
+						Error:      result.ErrorMessage,
+					})
+				}
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return &api.VideoDeleteResponse{
+			Base: &api.BaseResponse{
+				Code:    3003,
+				Message: fmt.Sprintf("部分文件删除失败（%d个），视频记录已保留，可重试删除", len(failures)),
+			},
+			DeletedCount: int32(deletedCount),
+			Failures:     failures,
+		}, nil
+	}
+
+	if err := s.metadataService.DeleteMetadata(ctx, meta.FileID); err != nil {
+		return nil, fmt.Errorf("删除视频元数据失败: %w", err)
+	}
+	for _, group := range groups {
+		s.invalidatePresignedURLs(ctx, group.BucketName, group.ObjectNames...)
+	}
+	s.collections.RemoveVideoFromAll(ctx, meta.FileID)
+	s.playlists.RemoveVideoFromAll(ctx, meta.FileID)
+	s.history.RemoveVideo(ctx, meta.FileID)
+	s.views.RemoveVideo(ctx, meta.FileID)
+	s.favorites.RemoveVideo(ctx, meta.FileID)
+	s.shares.RemoveVideo(ctx, meta.FileID)
+	s.reviews.Remove(meta.FileID)
+	s.publishEvent(notify.EventVideoDeleted, meta, nil)
+
+	return &api.VideoDeleteResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "删除成功",
+		},
+		DeletedCount: int32(deletedCount),
+		Failures:     failures,
+	}, nil
+}
+
+// bucketObjects 同一存储桶中待删除的对象
+type bucketObjects struct {
+	BucketName  string
+	ObjectNames []string
+}
+
+// collectVideoObjects 收集视频关联的所有存储对象，按存储桶分组
+// 视频文件被其他去重的视频记录共享时保留，由最后一个引用它的视频删除；已归档的视频文件不在视频存储桶中
+func (s *VideoService) collectVideoObjects(ctx context.Context, meta *metadata.FileMetadata) ([]*bucketObjects, error) {
+	var groups []*bucketObjects
+	add := func(bucketName string, objectNames ...string) {
+		for _, group := range groups {
+			if group.BucketName == bucketName {
+				group.ObjectNames = append(group.ObjectNames, objectNames...)
+				return
+			}
+		}
+		groups = append(groups, &bucketObjects{BucketName: bucketName, ObjectNames: objectNames})
+	}
+
+	// 视频文件按元数据记录的存储桶删除，配置变更前上传的视频仍在原存储桶中
+	if !meta.Archived && !s.isSharedObject(ctx, meta) {
+		add(meta.BucketName, meta.ObjectName)
+	}
+	// 历史版本的文件同样只在没有被其他视频引用时删除
+	for _, version := range meta.Versions {
+		if s.metadataService.CountObjectReferences(ctx, version.BucketName, version.ObjectName) <= 1 {
+			add(version.BucketName, version.ObjectName)
+		}
+	}
+
+	thumbnailBucket := s.buckets.Bucket(storage.ContentThumbnails)
+	if meta.Thumbnail != "" {
+		add(thumbnailBucket, meta.Thumbnail)
+	}
+	if meta.Preview != "" {
+		add(thumbnailBucket, meta.Preview)
+	}
+
+	renditionBucket := s.buckets.Bucket(storage.ContentRenditions)
+	hlsFiles, err := s.storageClient.ListFiles(ctx, renditionBucket, streaming.VideoPrefix(meta.FileID))
+	if err != nil {
+		return nil, fmt.Errorf("列出HLS文件失败: %w", err)
+	}
+	for _, file := range hlsFiles {
+		add(renditionBucket, file.Key)
+	}
+
+	spriteFiles, err := s.storageClient.ListFiles(ctx, thumbnailBucket, spritePrefix(meta.FileID))
+	if err != nil {
+		return nil, fmt.Errorf("列出预览图文件失败: %w", err)
+	}
+	for _, file := range spriteFiles {
+		add(thumbnailBucket, file.Key)
+	}
+
+	candidateFiles, err := s.storageClient.ListFiles(ctx, thumbnailBucket, thumbnailCandidatePrefix(meta.FileID))
+	if err != nil {
+		return nil, fmt.Errorf("列出候选缩略图失败: %w", err)
+	}
+	for _, file := range candidateFiles {
+		add(thumbnailBucket, file.Key)
+	}
+
+	subtitleBucket := s.buckets.Bucket(storage.ContentSubtitles)
+	exists, err := s.storageClient.BucketExists(ctx, subtitleBucket)
+	if err != nil {
+		return nil, fmt.Errorf("检查字幕存储桶失败: %w", err)
+	}
+	if exists {
+		subtitleFiles, err := s.storageClient.ListFiles(ctx, subtitleBucket, subtitlePrefix(meta.FileID))
+		if err != nil {
+			return nil, fmt.Errorf("列出字幕文件失败: %w", err)
+		}
+		for _, file := range subtitleFiles {
+			add(subtitleBucket, file.Key)
+		}
+	}
+
+	return groups, nil
+}
+
+// videoDeleteErrorResponse 创建视频删除错误响应
+func (s *VideoService) videoDeleteErrorResponse(code int32, message string) *api.VideoDeleteResponse {
+	return &api.VideoDeleteResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+		Failures: []*api.DeleteFailure{},
+	}
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/analytics"
+	"github.com/manteia/zhulong/pkg/collection"
+	"github.com/manteia/zhulong/pkg/delete"
+	"github.com/manteia/zhulong/pkg/favorite"
+	"github.com/manteia/zhulong/pkg/history"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/moderation"
+	"github.com/manteia/zhulong/pkg/playlist"
+	"github.com/manteia/zhulong/pkg/share"
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// createDeleteTestService 创建带内存存储和测试视频的视频服务
+func createDeleteTestService(t *testing.T) (*VideoService, *storage.MemoryStorage) {
+	store, err := storage.NewMemoryStorage(&storage.MemoryConfig{BaseURL: "http://storage.local"})
+	require.NoError(t, err)
+	for _, objectName := range []string{
+		"videos/2025/08/video1.mp4",
+		"thumbnails/video1.jpg",
+		"hls/video1/master.m3u8",
+		"hls/video1/source.m3u8",
+		"hls/video1/source/segment_00000.ts",
+		"hls/video10/master.m3u8",
+	} {
+		putTestObject(t, store, objectName, []byte("data"))
+	}
+
+	service := &VideoService{
+		storageClient:   store,
+		buckets:         storage.NewBucketResolver(testBucket, nil),
+		metadataService: metadata.NewMetadataService(),
+		deleteService:   delete.NewDeleteService(store),
+		collections:     collection.NewCollectionService(),
+		playlists:       playlist.NewPlaylistService(),
+		history:         history.NewHistoryService(),
+		views:           analytics.NewViewTracker(),
+		favorites:       favorite.NewFavoriteService(),
+		shares:          share.NewShareService(),
+		reviews:         moderation.NewReviewQueue(),
+	}
+	err = service.metadataService.SaveMetadata(context.Background(), &metadata.FileMetadata{
+		FileID:      "video1",
+		BucketName:  "zhulong-videos",
+		ObjectName:  "videos/2025/08/video1.mp4",
+		FileName:    "video1.mp4",
+		Title:       "测试视频1",
+		FileSize:    1024,
+		ContentType: "video/mp4",
+		Thumbnail:   "thumbnails/video1.jpg",
+		CreatedBy:   "system",
+	})
+	require.NoError(t, err)
+
+	return service, store
+}
+
+// failingDeleteStorage 删除指定对象时失败的存储
+type failingDeleteStorage struct {
+	*storage.MemoryStorage
+	failOnKey string
+}
+
+func (s *failingDeleteStorage) DeleteFile(ctx context.Context, bucketName, objectName string) error {
+	if objectName == s.failOnKey {
+		return fmt.Errorf("模拟删除失败")
+	}
+	return s.MemoryStorage.DeleteFile(ctx, bucketName, objectName)
+}
+
+func (s *failingDeleteStorage) DeleteFiles(ctx context.Context, bucketName string, objectNames []string) (map[string]error, error) {
+	failures := make(map[string]error)
+	for _, objectName := range objectNames {
+		if err := s.DeleteFile(ctx, bucketName, objectName); err != nil {
+			failures[objectName] = err
+		}
+	}
+	return failures, nil
+}
+
+// bucketObjectNames 获取指定存储桶中待删除的对象
+func bucketObjectNames(groups []*bucketObjects, bucketName string) []string {
+	for _, group := range groups {
+		if group.BucketName == bucketName {
+			return group.ObjectNames
+		}
+	}
+	return nil
+}
+
+func TestVideoService_DeleteVideo(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("删除视频_参数为空", func(t *testing.T) {
+		service, _ := createDeleteTestService(t)
+
+		resp, err := service.DeleteVideo(ctx, &api.VideoDeleteRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(3001), resp.Base.Code)
+	})
+
+	t.Run("删除视频_视频不存在", func(t *testing.T) {
+		service, _ := createDeleteTestService(t)
+
+		resp, err := service.DeleteVideo(ctx, &api.VideoDeleteRequest{VideoID: "not-exist"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(3002), resp.Base.Code)
+	})
+
+	t.Run("删除视频_级联清理", func(t *testing.T) {
+		service, store := createDeleteTestService(t)
+
+		resp, err := service.DeleteVideo(ctx, &api.VideoDeleteRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code, "删除应该成功")
+		assert.Equal(t, int32(5), resp.DeletedCount, "应该删除视频、缩略图和HLS文件")
+		assert.Empty(t, resp.Failures)
+
+		assert.Len(t, testObjectNames(t, store, ""), 1, "视频关联的对象应该全部被删除")
+		assert.Contains(t, testObjectNames(t, store, ""), "hls/video10/master.m3u8", "不应该删除其他视频的HLS文件")
+
+		_, err = service.metadataService.GetMetadata(ctx, "video1")
+		assert.Error(t, err, "元数据应该已被删除")
+	})
+
+	t.Run("删除视频_按内容类别的存储桶删除", func(t *testing.T) {
+		service, store := createDeleteTestService(t)
+		service.buckets = storage.NewBucketResolver(testBucket, map[storage.ContentClass]string{
+			storage.ContentThumbnails: "zhulong-images",
+			storage.ContentRenditions: "zhulong-hls",
+		})
+		require.NoError(t, store.MoveFile(ctx, testBucket, "thumbnails/video1.jpg", "zhulong-images", "thumbnails/video1.jpg"))
+		for _, objectName := range testObjectNames(t, store, "hls/video1/") {
+			require.NoError(t, store.MoveFile(ctx, testBucket, objectName, "zhulong-hls", objectName))
+		}
+		meta, err := service.metadataService.GetMetadata(ctx, "video1")
+		require.NoError(t, err)
+
+		groups, err := service.collectVideoObjects(ctx, meta)
+		require.NoError(t, err)
+		require.Len(t, groups, 3)
+		assert.Equal(t, []string{"videos/2025/08/video1.mp4"}, bucketObjectNames(groups, "zhulong-videos"))
+		assert.Equal(t, []string{"thumbnails/video1.jpg"}, bucketObjectNames(groups, "zhulong-images"))
+		assert.Len(t, bucketObjectNames(groups, "zhulong-hls"), 3, "HLS文件应该在播放文件存储桶中删除")
+	})
+
+	t.Run("删除视频_部分失败保留元数据", func(t *testing.T) {
+		service, store := createDeleteTestService(t)
+		failing := &failingDeleteStorage{MemoryStorage: store, failOnKey: "thumbnails/video1.jpg"}
+		service.storageClient = failing
+		service.deleteService = delete.NewDeleteService(failing)
+
+		resp, err := service.DeleteVideo(ctx, &api.VideoDeleteRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(3003), resp.Base.Code, "部分失败应该返回3003")
+		assert.Equal(t, int32(4), resp.DeletedCount)
+		require.Len(t, resp.Failures, 1)
+		assert.Equal(t, "thumbnails/video1.jpg", resp.Failures[0].ObjectName)
+
+		_, err = service.metadataService.GetMetadata(ctx, "video1")
+		assert.NoError(t, err, "部分失败时应该保留元数据")
+
+		// 重试时已删除的对象视为成功
+		failing.failOnKey = ""
+		resp, err = service.DeleteVideo(ctx, &api.VideoDeleteRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code, "重试删除应该成功")
+		assert.Equal(t, int32(1), resp.DeletedCount)
+	})
+}
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/middleware"
+	"github.com/manteia/zhulong/pkg/quota"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/upload"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+// directUploadExpiry 直传上传地址和令牌的有效期
+const directUploadExpiry = 15 * time.Minute
+
+// CreateUploadURL 生成直传上传地址
+// 客户端使用预签名PUT URL直接上传到存储，上传完成后调用ConfirmUpload确认
+func (s *VideoService) CreateUploadURL(ctx context.Context, req *api.VideoUploadURLRequest) (*api.VideoUploadURLResponse, error) {
+	if !canUploadVideo(ctx) {
+		return s.uploadURLErrorResponse(1012, "无权上传视频"), nil
+	}
+	if req.Filename == "" {
+		return s.uploadURLErrorResponse(1001, "文件名不能为空"), nil
+	}
+
+	if err := s.validateUploadSize(ctx, req.Filename, req.Size); err != nil {
+		return s.uploadURLErrorResponse(1003, fmt.Sprintf("文件大小验证失败: %v", err)), nil
+	}
+
+	visibility, err := s.uploadVisibility(req.Visibility)
+	if err != nil {
+		return s.uploadURLErrorResponse(1001, err.Error()), nil
+	}
+
+	ext := strings.TrimPrefix(filepath.Ext(req.Filename), ".")
+	if !s.isUploadFormatSupported(req.Filename) {
+		return s.uploadURLErrorResponse(1005, fmt.Sprintf("不支持的文件格式: %s", ext)), nil
+	}
+
+	// 创建上传地址时只检查配额，确认上传时按实际大小预占
+	err = s.checkQuota(ctx, currentUserID(ctx), req.Size)
+	if errors.Is(err, quota.ErrQuotaExceeded) {
+		return s.uploadURLErrorResponse(1009, err.Error()), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	videoID := uuid.New().String()
+	now := time.Now()
+	objectName := fmt.Sprintf("videos/%d/%02d/%s%s",
+		now.Year(), now.Month(), videoID, upload.ObjectExtension(req.Filename))
+
+	session, err := s.directUploads.CreateSession(&upload.DirectUploadSession{
+		VideoID:     videoID,
+		BucketName:  s.buckets.Bucket(storage.ContentVideos),
+		ObjectName:  objectName,
+		FileName:    req.Filename,
+		ContentType: req.ContentType,
+		Size:        req.Size,
+		Title:       getValueOrDefaultFromString(req.Title, req.Filename),
+		Description: req.Description,
+		Visibility:  visibility,
+		CreatedBy:   currentUserID(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uploadURL, err := s.storageClient.GeneratePresignedURL(ctx, session.BucketName, session.ObjectName, s.directUploads.GetExpiry(), "PUT")
+	if err != nil {
+		s.directUploads.RemoveSession(session.Token)
+		return nil, fmt.Errorf("生成上传地址失败: %w", err)
+	}
+
+	return &api.VideoUploadURLResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		VideoID:     videoID,
+		UploadURL:   uploadURL,
+		UploadToken: session.Token,
+		ExpiresAt:   session.ExpiresAt.UnixMilli(),
+	}, nil
+}
+
+// ConfirmUpload 确认直传上传完成
+// 校验存储中的对象大小和格式后生成缩略图并保存元数据；校验失败时删除已上传的对象
+func (s *VideoService) ConfirmUpload(ctx context.Context, req *api.VideoUploadConfirmRequest) (*api.VideoUploadResponse, error) {
+	if !canUploadVideo(ctx) {
+		return s.errorResponse(1012, "无权上传视频"), nil
+	}
+	if req.UploadToken == "" {
+		return s.errorResponse(1007, "上传令牌不能为空"), nil
+	}
+	expectedChecksum, err := upload.NormalizeChecksum(req.Checksum)
+	if err != nil {
+		return s.errorResponse(1001, err.Error()), nil
+	}
+
+	session, err := s.directUploads.GetSession(req.UploadToken)
+	if err != nil {
+		return s.errorResponse(1007, err.Error()), nil
+	}
+
+	fileInfo, err := s.storageClient.GetFileInfo(ctx, session.BucketName, session.ObjectName)
+	if err != nil {
+		// 保留会话，客户端可在上传完成后重试确认
+		return s.errorResponse(1008, "文件尚未上传完成"), nil
+	}
+
+	if fileInfo.Size != session.Size {
+		s.rejectDirectUpload(ctx, session)
+		return s.errorResponse(1003, fmt.Sprintf("文件大小与声明不一致: 声明%d字节，实际%d字节", session.Size, fileInfo.Size)), nil
+	}
+
+	contentType := session.ContentType
+	if contentType == "" {
+		contentType = fileInfo.ContentType
+	}
+
+	resp, err := s.finalizeStoredUpload(ctx, &storedUpload{
+		VideoID:     session.VideoID,
+		BucketName:  session.BucketName,
+		ObjectName:  session.ObjectName,
+		FileName:    session.FileName,
+		ContentType: contentType,
+		Size:        fileInfo.Size,
+		Title:       session.Title,
+		Description: session.Description,
+		Visibility:  session.Visibility,
+		CreatedBy:   session.CreatedBy,
+	}, expectedChecksum, func() { s.rejectDirectUpload(ctx, session) })
+	if err != nil || resp.Base.Code != 0 {
+		return resp, err
+	}
+	s.directUploads.RemoveSession(session.Token)
+	return resp, nil
+}
+
+// storedUpload 已写入存储、等待校验并保存元数据的上传文件
+type storedUpload struct {
+	VideoID     string
+	BucketName  string
+	ObjectName  string
+	FileName    string
+	ContentType string
+	Size        int64
+	Title       string
+	Description string
+	Visibility  string
+	CreatedBy   string
+	Checksum    string // 已计算的校验和，为空时从存储读取完整文件计算
+}
+
+// finalizeStoredUpload 校验已写入存储的上传文件并保存元数据，用于直传确认和分片上传完成
+// 格式、校验和不符或重复视频被拒绝时调用reject删除文件并使会话失效
+func (s *VideoService) finalizeStoredUpload(ctx context.Context, stored *storedUpload, expectedChecksum string, reject func()) (*api.VideoUploadResponse, error) {
+	reservation, err := s.reserveQuota(ctx, stored.CreatedBy, stored.Size)
+	if errors.Is(err, quota.ErrQuotaExceeded) {
+		reject()
+		return s.errorResponse(1009, err.Error()), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer reservation.Release()
+
+	headData, err := s.readObjectHead(ctx, stored.BucketName, stored.ObjectName)
+	if err != nil {
+		return s.errorResponse(1002, "读取文件数据失败"), nil
+	}
+
+	// 通过范围读取访问存储中的完整文件
+	objectReader := storage.NewObjectReaderAt(ctx, s.storageClient, stored.BucketName, stored.ObjectName, stored.Size)
+
+	// 图片和音频使用各自的格式验证和信息提取，视频信息在重复检测之后提取
+	mediaType := s.uploadMediaType(stored.FileName)
+	var videoInfo *video.VideoInfo
+	detectedFormat := ""
+	if mediaType != metadata.MediaTypeVideo {
+		videoInfo, err = s.inspectMediaFile(stored.FileName, headData, objectReader, stored.Size)
+		if err != nil {
+			reject()
+			return s.errorResponse(1004, fmt.Sprintf("文件格式验证失败: %v", err)), nil
+		}
+	} else {
+		if !s.videoUploadAllowed() {
+			reject()
+			return s.errorResponse(1005, "不支持的文件格式: 不允许上传视频"), nil
+		}
+		validationResult, err := s.videoValidator.Load().ValidateFormat(&video.ValidationRequest{
+			Filename:    stored.FileName,
+			ContentType: stored.ContentType,
+			Data:        headData[:min(len(headData), 512)],
+		})
+		if err != nil {
+			reject()
+			return s.errorResponse(1004, fmt.Sprintf("文件格式验证失败: %v", err)), nil
+		}
+		if !validationResult.IsValid {
+			reject()
+			return s.errorResponse(1005, fmt.Sprintf("不支持的文件格式: %s", validationResult.ErrorMessage)), nil
+		}
+		if err := s.verifyVideoStructure(objectReader, stored.Size, validationResult.DetectedFormat); err != nil {
+			reject()
+			return s.errorResponse(1004, fmt.Sprintf("文件结构验证失败: %v", err)), nil
+		}
+		detectedFormat = validationResult.DetectedFormat
+	}
+
+	// 数据未经过服务端时，需要从存储读取完整文件计算校验和
+	checksum := stored.Checksum
+	if checksum == "" {
+		checksum, err = s.uploadService.ComputeChecksum(ctx, stored.BucketName, stored.ObjectName)
+		if err != nil {
+			return s.errorResponse(1002, "读取文件数据失败"), nil
+		}
+	}
+	if expectedChecksum != "" && checksum != expectedChecksum {
+		reject()
+		return s.errorResponse(1010, fmt.Sprintf("%v: 期望 %s, 实际 %s", upload.ErrChecksumMismatch, expectedChecksum, checksum)), nil
+	}
+
+	// 内容与已有视频相同时拒绝上传，或删除上传的文件并共享已有视频的存储对象
+	duplicate := s.findDuplicateVideo(ctx, checksum)
+	if duplicate != nil && s.config.GetDeduplicationMode() == config.DeduplicationReject {
+		reject()
+		return s.duplicateVideoResponse(ctx, duplicate), nil
+	}
+
+	if mediaType == metadata.MediaTypeVideo {
+		videoInfo, err = s.videoExtractor.ExtractInfo(&video.InfoExtractionRequest{
+			Data:     headData,
+			Filename: stored.FileName,
+			// 避免moov位于末尾时无法解析
+			Reader: objectReader,
+			Size:   stored.Size,
+		})
+		if err != nil {
+			// 信息提取失败不阻断上传，使用默认值
+			videoInfo = &video.VideoInfo{
+				Filename: stored.FileName,
+				Format:   detectedFormat,
+				FileSize: stored.Size,
+			}
+		}
+
+		// 重复的视频共享已检查过的存储对象
+		if duplicate == nil {
+			if err := s.probeStoredVideo(ctx, stored.BucketName, stored.ObjectName); err != nil {
+				reject()
+				return s.errorResponse(1004, fmt.Sprintf("文件结构验证失败: %v", err)), nil
+			}
+		}
+	}
+
+	bucketName, objectName := stored.BucketName, stored.ObjectName
+	if duplicate != nil {
+		if err := s.storageClient.DeleteFile(ctx, stored.BucketName, stored.ObjectName); err != nil {
+			fmt.Printf("删除重复的上传文件失败(request_id=%s): %v\n", middleware.RequestIDFromContext(ctx), err)
+		}
+		bucketName, objectName = duplicate.BucketName, duplicate.ObjectName
+	} else {
+		// 客户端直接写入存储或分片上传时，上传前未知校验和，完成后补充对象标签
+		tags := videoObjectLabels(stored.VideoID, stored.CreatedBy)
+		tags[storage.ObjectKeyContentHash] = checksum
+		if err := s.storageClient.SetObjectTags(ctx, bucketName, objectName, tags); err != nil {
+			fmt.Printf("设置上传文件标签失败(request_id=%s): %v\n", middleware.RequestIDFromContext(ctx), err)
+		}
+	}
+
+	uploaded := &uploadedVideo{
+		VideoID:     stored.VideoID,
+		BucketName:  bucketName,
+		ObjectName:  objectName,
+		FileName:    stored.FileName,
+		ContentType: stored.ContentType,
+		Size:        stored.Size,
+		Title:       stored.Title,
+		Description: stored.Description,
+		Info:        videoInfo,
+		MediaType:   mediaType,
+		Visibility:  stored.Visibility,
+		Checksum:    checksum,
+		HeadData:    headData,
+		CreatedBy:   stored.CreatedBy,
+		// 重复的视频已删除上传的文件，共享已有视频的存储对象
+		SharedObject: duplicate != nil,
+	}
+
+	// 从存储流式读取完整视频用于抽帧，失败时退化为使用文件头部
+	if reader, err := s.storageClient.OpenFile(ctx, bucketName, objectName); err == nil {
+		defer reader.Close()
+		uploaded.Reader = reader
+	}
+
+	videoResponse, err := s.finalizeUpload(ctx, uploaded)
+	if err != nil {
+		return s.errorResponse(1013, err.Error()), nil
+	}
+
+	return &api.VideoUploadResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "上传成功",
+		},
+		Video: videoResponse,
+	}, nil
+}
+
+// readObjectHead 读取存储对象的头部数据
+func (s *VideoService) readObjectHead(ctx context.Context, bucketName, objectName string) ([]byte, error) {
+	reader, err := s.storageClient.OpenFile(ctx, bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return readFileHead(reader, videoHeadSize)
+}
+
+// rejectDirectUpload 拒绝直传上传：删除已上传的对象并使令牌失效
+func (s *VideoService) rejectDirectUpload(ctx context.Context, session *upload.DirectUploadSession) {
+	if err := s.storageClient.DeleteFile(ctx, session.BucketName, session.ObjectName); err != nil {
+		fmt.Printf("删除无效的直传文件失败(request_id=%s): %v\n", middleware.RequestIDFromContext(ctx), err)
+	}
+	s.directUploads.RemoveSession(session.Token)
+}
+
+// uploadURLErrorResponse 创建直传上传地址错误响应
+func (s *VideoService) uploadURLErrorResponse(code int32, message string) *api.VideoUploadURLResponse {
+	return &api.VideoUploadURLResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
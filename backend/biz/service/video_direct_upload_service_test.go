@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/analytics"
+	"github.com/manteia/zhulong/pkg/collection"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/download"
+	"github.com/manteia/zhulong/pkg/favorite"
+	"github.com/manteia/zhulong/pkg/history"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/moderation"
+	"github.com/manteia/zhulong/pkg/notify"
+	"github.com/manteia/zhulong/pkg/playlist"
+	"github.com/manteia/zhulong/pkg/share"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/upload"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+// testBucket 测试视频服务的存储桶，未单独配置的内容类型都写入该存储桶
+const testBucket = "zhulong-videos"
+
+// createDirectUploadTestService 创建使用内存存储的视频服务
+func createDirectUploadTestService(t *testing.T) (*VideoService, *storage.MemoryStorage) {
+	store, err := storage.NewMemoryStorage(&storage.MemoryConfig{BaseURL: "http://storage.local"})
+	require.NoError(t, err)
+	require.NoError(t, store.CreateBucket(context.Background(), testBucket))
+	// 不使用FFmpeg，避免上传后在后台异步生成预览图
+	thumbnailGenerator := video.NewThumbnailGenerator()
+	thumbnailGenerator.SetFrameExtractor(nil)
+	// 上传的视频默认公开，未登录的请求也可以查看和播放
+	cfg := &config.Config{}
+	cfg.Upload.DefaultVisibility = metadata.VisibilityPublic
+	service := &VideoService{
+		config:             cfg,
+		storageClient:      store,
+		buckets:            storage.NewBucketResolver(testBucket, nil),
+		uploadService:      upload.NewUploadService(store),
+		downloadService:    download.NewDownloadService(store),
+		metadataService:    metadata.NewMetadataService(),
+		videoExtractor:     video.NewVideoInfoExtractor(),
+		thumbnailGenerator: thumbnailGenerator,
+		directUploads:      upload.NewDirectUploadManager(directUploadExpiry),
+		multipartUploads:   upload.NewMultipartSessionManager(0),
+		progressRegistry:   upload.NewProgressRegistry(),
+		notifier:           notify.NewHub(),
+		collections:        collection.NewCollectionService(),
+		playlists:          playlist.NewPlaylistService(),
+		history:            history.NewHistoryService(),
+		views:              analytics.NewViewTracker(),
+		favorites:          favorite.NewFavoriteService(),
+		shares:             share.NewShareService(),
+		reviews:            moderation.NewReviewQueue(),
+	}
+	service.videoValidator.Store(video.NewVideoValidator())
+	service.sizeLimitManager.Store(video.NewSizeLimitManager())
+	return service, store
+}
+
+// putTestObject 向测试存储桶写入对象
+func putTestObject(t *testing.T, store *storage.MemoryStorage, objectName string, data []byte) {
+	t.Helper()
+	_, err := store.UploadFile(context.Background(), testBucket, objectName, data, "")
+	require.NoError(t, err)
+}
+
+// testObjectData 读取测试存储桶中的对象，对象不存在时返回nil
+func testObjectData(t *testing.T, store *storage.MemoryStorage, objectName string) []byte {
+	t.Helper()
+	data, err := store.DownloadFile(context.Background(), testBucket, objectName)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		return nil
+	}
+	require.NoError(t, err)
+	return data
+}
+
+// testObjectNames 列出测试存储桶中指定前缀的对象名
+func testObjectNames(t *testing.T, store *storage.MemoryStorage, prefix string) []string {
+	t.Helper()
+	files, err := store.ListFiles(context.Background(), testBucket, prefix)
+	require.NoError(t, err)
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.Key)
+	}
+	return names
+}
+
+// mp4TestData 生成带有MP4文件头的测试数据
+func mp4TestData(size int) []byte {
+	data := make([]byte, size)
+	copy(data, []byte{0x00, 0x00, 0x00, 0x20, 'f', 't', 'y', 'p', 'i', 's', 'o', 'm'})
+	return data
+}
+
+// createTestUploadURL 获取直传上传地址
+func createTestUploadURL(t *testing.T, service *VideoService, size int64) *api.VideoUploadURLResponse {
+	resp, err := service.CreateUploadURL(context.Background(), &api.VideoUploadURLRequest{
+		Filename:    "direct.mp4",
+		Size:        size,
+		ContentType: "video/mp4",
+		Title:       "直传视频",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+	return resp
+}
+
+func TestVideoService_CreateUploadURL(t *testing.T) {
+	ctx := context.Background()
+	service, store := createDirectUploadTestService(t)
+
+	t.Run("获取上传地址_成功", func(t *testing.T) {
+		resp := createTestUploadURL(t, service, 2048)
+		assert.NotEmpty(t, resp.VideoID)
+		assert.NotEmpty(t, resp.UploadToken)
+		assert.Contains(t, resp.UploadURL, resp.VideoID+".mp4", "上传地址应该指向视频对象")
+		uploadURL, err := url.Parse(resp.UploadURL)
+		require.NoError(t, err)
+		query := uploadURL.Query()
+		assert.NoError(t, store.VerifyPresignedURL("PUT", testBucket, strings.TrimPrefix(uploadURL.Path, "/"+testBucket+"/"), query.Get("expires"), query.Get("signature")), "应该生成PUT预签名地址")
+		assert.Greater(t, resp.ExpiresAt, int64(0))
+	})
+
+	t.Run("获取上传地址_参数错误", func(t *testing.T) {
+		invalid := []*api.VideoUploadURLRequest{
+			{Size: 1024},
+			{Filename: "empty.mp4"},
+			{Filename: "huge.mp4", Size: 10 * 1024 * 1024 * 1024},
+			{Filename: "document.pdf", Size: 1024},
+		}
+		for _, req := range invalid {
+			resp, err := service.CreateUploadURL(ctx, req)
+			require.NoError(t, err)
+			assert.NotEqual(t, int32(0), resp.Base.Code, "无效请求应该失败: %+v", req)
+			assert.Empty(t, resp.UploadToken)
+		}
+	})
+}
+
+func TestVideoService_ConfirmUpload(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("确认上传_成功", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		urlResp := createTestUploadURL(t, service, 2048)
+
+		session, err := service.directUploads.GetSession(urlResp.UploadToken)
+		require.NoError(t, err)
+		putTestObject(t, store, session.ObjectName, mp4TestData(2048))
+
+		resp, err := service.ConfirmUpload(ctx, &api.VideoUploadConfirmRequest{UploadToken: urlResp.UploadToken})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, urlResp.VideoID, resp.Video.ID)
+		assert.Equal(t, "直传视频", resp.Video.Title)
+		assert.Equal(t, int64(2048), resp.Video.Size)
+
+		meta, err := service.metadataService.GetMetadata(ctx, urlResp.VideoID)
+		require.NoError(t, err, "确认后应该保存元数据")
+		assert.Equal(t, session.ObjectName, meta.ObjectName)
+		assert.Equal(t, "system", meta.CreatedBy)
+		tags, err := store.GetObjectTags(ctx, testBucket, session.ObjectName)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			storage.ObjectKeyVideoID:     urlResp.VideoID,
+			storage.ObjectKeyUploader:    "system",
+			storage.ObjectKeyContentHash: meta.Checksum,
+		}, tags, "确认后应该补充对象标签")
+
+		require.NotEmpty(t, meta.Thumbnail, "应该生成缩略图")
+		assert.Contains(t, testObjectNames(t, store, ""), meta.Thumbnail, "缩略图应该按记录的路径上传")
+
+		resp, err = service.ConfirmUpload(ctx, &api.VideoUploadConfirmRequest{UploadToken: urlResp.UploadToken})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1007), resp.Base.Code, "令牌只能使用一次")
+	})
+
+	t.Run("确认上传_令牌无效", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+
+		resp, err := service.ConfirmUpload(ctx, &api.VideoUploadConfirmRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1007), resp.Base.Code)
+
+		resp, err = service.ConfirmUpload(ctx, &api.VideoUploadConfirmRequest{UploadToken: "invalid"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1007), resp.Base.Code)
+	})
+
+	t.Run("确认上传_文件未上传", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+		urlResp := createTestUploadURL(t, service, 2048)
+
+		resp, err := service.ConfirmUpload(ctx, &api.VideoUploadConfirmRequest{UploadToken: urlResp.UploadToken})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1008), resp.Base.Code)
+
+		_, err = service.directUploads.GetSession(urlResp.UploadToken)
+		assert.NoError(t, err, "文件未上传时应该保留会话以便重试")
+	})
+
+	t.Run("确认上传_大小不一致", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		urlResp := createTestUploadURL(t, service, 2048)
+
+		session, err := service.directUploads.GetSession(urlResp.UploadToken)
+		require.NoError(t, err)
+		putTestObject(t, store, session.ObjectName, mp4TestData(4096))
+
+		resp, err := service.ConfirmUpload(ctx, &api.VideoUploadConfirmRequest{UploadToken: urlResp.UploadToken})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1003), resp.Base.Code)
+		assert.NotContains(t, testObjectNames(t, store, ""), session.ObjectName, "大小不一致时应该删除已上传的对象")
+	})
+
+	t.Run("确认上传_格式无效", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		urlResp := createTestUploadURL(t, service, 2048)
+
+		session, err := service.directUploads.GetSession(urlResp.UploadToken)
+		require.NoError(t, err)
+		putTestObject(t, store, session.ObjectName, []byte(strings.Repeat("x", 2048)))
+
+		resp, err := service.ConfirmUpload(ctx, &api.VideoUploadConfirmRequest{UploadToken: urlResp.UploadToken})
+		require.NoError(t, err)
+		assert.Contains(t, []int32{1004, 1005}, resp.Base.Code, "格式无效时应该返回格式错误")
+		assert.NotContains(t, testObjectNames(t, store, ""), session.ObjectName, "格式无效时应该删除已上传的对象")
+
+		_, err = service.metadataService.GetMetadata(ctx, urlResp.VideoID)
+		assert.Error(t, err, "格式无效时不应该保存元数据")
+	})
+
+	t.Run("确认上传_校验和", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		urlResp := createTestUploadURL(t, service, 2048)
+
+		session, err := service.directUploads.GetSession(urlResp.UploadToken)
+		require.NoError(t, err)
+		data := mp4TestData(2048)
+		putTestObject(t, store, session.ObjectName, data)
+
+		resp, err := service.ConfirmUpload(ctx, &api.VideoUploadConfirmRequest{
+			UploadToken: urlResp.UploadToken,
+			Checksum:    strings.ToUpper(sha256Hex(data)),
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, sha256Hex(data), resp.Video.Checksum)
+
+		meta, err := service.metadataService.GetMetadata(ctx, urlResp.VideoID)
+		require.NoError(t, err)
+		assert.Equal(t, sha256Hex(data), meta.Checksum, "校验和应该保存到元数据")
+	})
+
+	t.Run("确认上传_校验和不一致", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		urlResp := createTestUploadURL(t, service, 2048)
+
+		session, err := service.directUploads.GetSession(urlResp.UploadToken)
+		require.NoError(t, err)
+		putTestObject(t, store, session.ObjectName, mp4TestData(2048))
+
+		resp, err := service.ConfirmUpload(ctx, &api.VideoUploadConfirmRequest{
+			UploadToken: urlResp.UploadToken,
+			Checksum:    sha256Hex([]byte("other")),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1010), resp.Base.Code)
+		assert.NotContains(t, testObjectNames(t, store, ""), session.ObjectName, "校验和不一致时应该删除已上传的对象")
+
+		resp, err = service.ConfirmUpload(ctx, &api.VideoUploadConfirmRequest{UploadToken: urlResp.UploadToken, Checksum: "abc"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1001), resp.Base.Code, "校验和格式错误")
+	})
+}
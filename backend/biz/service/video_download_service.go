@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/download"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/streaming"
+)
+
+// VideoDownload 视频下载流，Base.Code为0时Stream有效，由调用方负责关闭Stream.Body
+type VideoDownload struct {
+	Base     *api.BaseResponse
+	Stream   *download.FileStream
+	FileName string // 附件文件名
+}
+
+// DownloadVideo 打开视频下载流，默认下载原始文件并支持Range断点续传
+// 指定档位时按顺序拼接该档位的初始化分片和媒体分片，fMP4分片拼接后为可直接播放的MP4文件
+func (s *VideoService) DownloadVideo(ctx context.Context, req *api.VideoDownloadRequest, rangeHeader string) (*VideoDownload, error) {
+	if req.VideoID == "" {
+		return s.downloadErrorResponse(2401, "视频ID不能为空"), nil
+	}
+	if req.Rendition != "" {
+		if err := streaming.ValidatePlaylistName(req.Rendition + ".m3u8"); err != nil {
+			return s.downloadErrorResponse(2401, fmt.Sprintf("无效的档位名称: %s", req.Rendition)), nil
+		}
+	}
+
+	meta, err := s.getVisibleMetadata(ctx, req.VideoID)
+	if err != nil {
+		return s.downloadErrorResponse(2402, "视频不存在"), nil
+	}
+	if req.Rendition != "" {
+		return s.downloadRendition(ctx, meta, req.Rendition)
+	}
+
+	meta, err = s.restoreArchivedVideo(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := s.downloadService.OpenFile(ctx, &download.DownloadRequest{
+		BucketName: meta.BucketName,
+		ObjectName: meta.ObjectName,
+	}, rangeHeader)
+	if errors.Is(err, download.ErrRangeNotSatisfiable) {
+		resp := s.downloadErrorResponse(2404, "请求的范围无法满足")
+		resp.Stream = stream
+		return resp, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开视频文件失败: %w", err)
+	}
+	if meta.ContentType != "" {
+		stream.ContentType = meta.ContentType
+	}
+
+	return &VideoDownload{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Stream:   stream,
+		FileName: downloadFileName(meta, "", path.Ext(meta.ObjectName)),
+	}, nil
+}
+
+// downloadRendition 打开HLS档位的下载流，档位的分片按媒体播放列表中的顺序拼接
+func (s *VideoService) downloadRendition(ctx context.Context, meta *metadata.FileMetadata, rendition string) (*VideoDownload, error) {
+	renditionBucket := s.buckets.Bucket(storage.ContentRenditions)
+	content, err := s.storageClient.DownloadFile(ctx, renditionBucket, streaming.PlaylistObjectName(meta.FileID, rendition+".m3u8"))
+	if err != nil {
+		return s.downloadErrorResponse(2403, fmt.Sprintf("档位不存在或视频尚未完成打包: %s", rendition)), nil
+	}
+	playlist, err := streaming.ParseMediaPlaylist(content)
+	if err != nil || len(playlist.Segments) == 0 {
+		return s.downloadErrorResponse(2403, fmt.Sprintf("档位不存在或视频尚未完成打包: %s", rendition)), nil
+	}
+
+	objectNames := make([]string, 0, len(playlist.Segments)+1)
+	contentType, ext := "video/mp2t", ".ts"
+	if playlist.InitURI != "" {
+		objectNames = append(objectNames, streaming.PlaylistObjectName(meta.FileID, playlist.InitURI))
+		contentType, ext = streaming.InitSegmentContentType, ".mp4"
+	}
+	for _, segment := range playlist.Segments {
+		objectNames = append(objectNames, streaming.PlaylistObjectName(meta.FileID, segment.URI))
+	}
+
+	stream, err := s.downloadService.OpenObjects(ctx, renditionBucket, objectNames, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("打开档位分片失败: %w", err)
+	}
+
+	return &VideoDownload{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Stream:   stream,
+		FileName: downloadFileName(meta, rendition, ext),
+	}, nil
+}
+
+// downloadFileName 生成附件文件名：下载原始文件时使用原始文件名
+// 没有原始文件名时使用标题或视频ID，下载档位时在扩展名前加上档位名称
+func downloadFileName(meta *metadata.FileMetadata, rendition, ext string) string {
+	if rendition == "" && meta.FileName != "" {
+		return meta.FileName
+	}
+
+	name := strings.TrimSuffix(meta.FileName, path.Ext(meta.FileName))
+	if name == "" {
+		name = meta.Title
+	}
+	if name == "" {
+		name = meta.FileID
+	}
+	if rendition != "" {
+		name += "-" + rendition
+	}
+	return name + ext
+}
+
+// downloadErrorResponse 创建视频下载错误响应
+func (s *VideoService) downloadErrorResponse(code int32, message string) *VideoDownload {
+	return &VideoDownload{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// readDownload 读取并关闭视频下载流
+func readDownload(t *testing.T, resp *VideoDownload) string {
+	defer resp.Stream.Body.Close()
+	data, err := io.ReadAll(resp.Stream.Body)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestVideoService_DownloadVideo(t *testing.T) {
+	ctx := context.Background()
+	req := &api.VideoDownloadRequest{VideoID: "video1"}
+
+	t.Run("下载原始文件", func(t *testing.T) {
+		service := createStreamTestService(t)
+
+		resp, err := service.DownloadVideo(ctx, req, "")
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, "video1.mp4", resp.FileName, "应该使用原始文件名")
+		assert.Equal(t, "video/mp4", resp.Stream.ContentType)
+		assert.Equal(t, "0123456789", readDownload(t, resp))
+
+		// 断点续传
+		resp, err = service.DownloadVideo(ctx, req, "bytes=6-")
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, "bytes 6-9/10", resp.Stream.Range.ContentRange(resp.Stream.Size))
+		assert.Equal(t, "6789", readDownload(t, resp))
+
+		resp, err = service.DownloadVideo(ctx, req, "bytes=100-")
+		require.NoError(t, err)
+		assert.Equal(t, int32(2404), resp.Base.Code)
+		assert.Equal(t, int64(10), resp.Stream.Size, "应该返回文件大小用于Content-Range")
+	})
+
+	t.Run("下载HLS档位", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		putTestObject(t, store, "videos/2025/08/video1.mp4", []byte("0123456789"))
+		require.NoError(t, service.metadataService.SaveMetadata(ctx, &metadata.FileMetadata{
+			FileID:     "video1",
+			BucketName: "zhulong-videos",
+			ObjectName: "videos/2025/08/video1.mp4",
+			FileName:   "假期.mov",
+			Title:      "假期",
+			CreatedBy:  "system",
+			Visibility: metadata.VisibilityPublic,
+		}))
+
+		resp, err := service.DownloadVideo(ctx, &api.VideoDownloadRequest{VideoID: "video1", Rendition: "720p"}, "")
+		require.NoError(t, err)
+		assert.Equal(t, int32(2403), resp.Base.Code, "未打包时档位不存在")
+
+		putTestObject(t, store, "hls/video1/720p.m3u8", []byte("#EXTM3U\n#EXT-X-MAP:URI=\"720p/init.mp4\"\n#EXTINF:6.0,\n720p/seg_000.m4s\n#EXTINF:4.0,\n720p/seg_001.m4s\n#EXT-X-ENDLIST\n"))
+		putTestObject(t, store, "hls/video1/720p/init.mp4", []byte("init|"))
+		putTestObject(t, store, "hls/video1/720p/seg_000.m4s", []byte("first|"))
+		putTestObject(t, store, "hls/video1/720p/seg_001.m4s", []byte("second"))
+
+		resp, err = service.DownloadVideo(ctx, &api.VideoDownloadRequest{VideoID: "video1", Rendition: "720p"}, "bytes=0-3")
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, "假期-720p.mp4", resp.FileName)
+		assert.Equal(t, "video/mp4", resp.Stream.ContentType)
+		assert.Nil(t, resp.Stream.Range, "档位下载不支持Range请求")
+		assert.Equal(t, int64(len("init|first|second")), resp.Stream.ContentLength())
+		assert.Equal(t, "init|first|second", readDownload(t, resp), "应该按播放列表顺序拼接分片")
+
+		resp, err = service.DownloadVideo(ctx, &api.VideoDownloadRequest{VideoID: "video1", Rendition: "../720p"}, "")
+		require.NoError(t, err)
+		assert.Equal(t, int32(2401), resp.Base.Code)
+	})
+
+	t.Run("不能下载不可见的视频", func(t *testing.T) {
+		service := createStreamTestService(t)
+		private := metadata.VisibilityPrivate
+		err := service.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{FileID: "video1", Visibility: &private})
+		require.NoError(t, err)
+
+		resp, err := service.DownloadVideo(claimsContext("viewer-1", user.RoleViewer), req, "")
+		require.NoError(t, err)
+		assert.Equal(t, int32(2402), resp.Base.Code)
+
+		resp, err = service.DownloadVideo(ctx, &api.VideoDownloadRequest{}, "")
+		require.NoError(t, err)
+		assert.Equal(t, int32(2401), resp.Base.Code)
+	})
+}
+
+func TestDownloadFileName(t *testing.T) {
+	meta := &metadata.FileMetadata{FileID: "video1", FileName: "clip.MOV", Title: "Ep. 1"}
+	assert.Equal(t, "clip.MOV", downloadFileName(meta, "", ".mov"))
+	assert.Equal(t, "clip-480p.ts", downloadFileName(meta, "480p", ".ts"))
+
+	meta.FileName = ""
+	assert.Equal(t, "Ep. 1.mov", downloadFileName(meta, "", ".mov"), "没有原始文件名时使用标题")
+	meta.Title = ""
+	assert.Equal(t, "video1-source.mp4", downloadFileName(meta, "source", ".mp4"))
+}
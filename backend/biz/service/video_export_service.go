@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/download"
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// maxExportVideos 单次批量导出的最大视频数量
+const maxExportVideos = 100
+
+// ExportVideos 将多个视频的原始文件打包为zip压缩包下载，便于离线拷贝
+// 视频以原始文件名存放在压缩包根目录，字幕与视频同名并带语言后缀（如假期.en.vtt），播放器可以自动加载
+func (s *VideoService) ExportVideos(ctx context.Context, req *api.VideoExportRequest) (*VideoDownload, error) {
+	if len(req.VideoIds) == 0 {
+		return s.downloadErrorResponse(2501, "视频ID列表不能为空"), nil
+	}
+
+	videoIDs := make([]string, 0, len(req.VideoIds))
+	for _, videoID := range req.VideoIds {
+		if videoID == "" {
+			return s.downloadErrorResponse(2501, "视频ID不能为空"), nil
+		}
+		if !slices.Contains(videoIDs, videoID) {
+			videoIDs = append(videoIDs, videoID)
+		}
+	}
+	if len(videoIDs) > maxExportVideos {
+		return s.downloadErrorResponse(2501, fmt.Sprintf("单次最多导出%d个视频", maxExportVideos)), nil
+	}
+
+	names := make(map[string]bool)
+	entries := make([]download.ZipEntry, 0, len(videoIDs))
+	for _, videoID := range videoIDs {
+		meta, err := s.getVisibleMetadata(ctx, videoID)
+		if err != nil {
+			return s.downloadErrorResponse(2502, fmt.Sprintf("视频不存在: %s", videoID)), nil
+		}
+		meta, err = s.restoreArchivedVideo(ctx, meta)
+		if err != nil {
+			return nil, err
+		}
+
+		name := uniqueExportName(names, exportFileName(downloadFileName(meta, "", path.Ext(meta.ObjectName))))
+		entries = append(entries, download.ZipEntry{
+			BucketName: meta.BucketName,
+			ObjectName: meta.ObjectName,
+			Name:       name,
+		})
+
+		if req.SkipSubtitles {
+			continue
+		}
+		subtitles, err := s.subtitleEntries(ctx, meta.FileID, strings.TrimSuffix(name, path.Ext(name)))
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range subtitles {
+			entry.Name = uniqueExportName(names, entry.Name)
+			entries = append(entries, entry)
+		}
+	}
+
+	stream, err := s.downloadService.OpenZip(ctx, entries)
+	if err != nil {
+		return nil, fmt.Errorf("打包视频失败: %w", err)
+	}
+
+	return &VideoDownload{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Stream:   stream,
+		FileName: fmt.Sprintf("zhulong-%s.zip", time.Now().Format("20060102-150405")),
+	}, nil
+}
+
+// subtitleEntries 获取视频字幕的压缩包条目，字幕文件名为视频文件名加语言和格式后缀
+func (s *VideoService) subtitleEntries(ctx context.Context, videoID, baseName string) ([]download.ZipEntry, error) {
+	bucketName := s.buckets.Bucket(storage.ContentSubtitles)
+	exists, err := s.storageClient.BucketExists(ctx, bucketName)
+	if err != nil {
+		return nil, fmt.Errorf("检查字幕存储桶失败: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	files, err := s.storageClient.ListFiles(ctx, bucketName, subtitlePrefix(videoID))
+	if err != nil {
+		return nil, fmt.Errorf("列出字幕文件失败: %w", err)
+	}
+
+	entries := make([]download.ZipEntry, 0, len(files))
+	for _, file := range files {
+		name := path.Base(file.Key)
+		format := strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))
+		language := strings.TrimSuffix(name, path.Ext(name))
+		if language == "" || !slices.Contains(subtitleFormats, format) {
+			continue
+		}
+		entries = append(entries, download.ZipEntry{
+			BucketName: bucketName,
+			ObjectName: file.Key,
+			Name:       baseName + "." + language + "." + format,
+		})
+	}
+
+	slices.SortFunc(entries, func(a, b download.ZipEntry) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return entries, nil
+}
+
+// exportFileName 清理压缩包内的文件名，去掉路径分隔符避免解压到压缩包目录之外
+func exportFileName(name string) string {
+	name = strings.NewReplacer("/", "_", "\\", "_").Replace(name)
+	if strings.Trim(name, ".") == "" {
+		return "_" + name
+	}
+	return name
+}
+
+// uniqueExportName 压缩包内文件名重复时在扩展名前加上序号，如clip (2).mp4
+func uniqueExportName(names map[string]bool, name string) string {
+	unique := name
+	ext := path.Ext(name)
+	for i := 2; names[unique]; i++ {
+		unique = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), i, ext)
+	}
+	names[unique] = true
+	return unique
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// readExport 读取导出的压缩包，返回按顺序排列的文件名和文件内容
+func readExport(t *testing.T, resp *VideoDownload) ([]string, map[string]string) {
+	data := readDownload(t, resp)
+	archive, err := zip.NewReader(bytes.NewReader([]byte(data)), int64(len(data)))
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(archive.File))
+	contents := make(map[string]string, len(archive.File))
+	for _, file := range archive.File {
+		assert.Equal(t, zip.Store, file.Method, "视频不应该重新压缩")
+		body, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(body)
+		body.Close()
+		require.NoError(t, err)
+		names = append(names, file.Name)
+		contents[file.Name] = string(content)
+	}
+	return names, contents
+}
+
+func TestVideoService_ExportVideos(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) *VideoService {
+		service, store := createDirectUploadTestService(t)
+		videos := []struct{ id, fileName, content string }{
+			{"video1", "假期.mp4", "first"},
+			{"video2", "假期.mp4", "second"},
+			{"video3", "../clip.mov", "third"},
+		}
+		for _, v := range videos {
+			objectName := "videos/2025/08/" + v.id + ".mp4"
+			putTestObject(t, store, objectName, []byte(v.content))
+			require.NoError(t, service.metadataService.SaveMetadata(ctx, &metadata.FileMetadata{
+				FileID:     v.id,
+				BucketName: "zhulong-videos",
+				ObjectName: objectName,
+				FileName:   v.fileName,
+				Title:      v.id,
+				CreatedBy:  "system",
+				Visibility: metadata.VisibilityPublic,
+			}))
+		}
+		putTestObject(t, store, "subtitles/video1/zh.srt", []byte("1\n"))
+		putTestObject(t, store, "subtitles/video1/en.vtt", []byte("WEBVTT\n"))
+		putTestObject(t, store, "subtitles/video1/notes.txt", []byte("ignored"))
+		return service
+	}
+
+	t.Run("导出视频和字幕", func(t *testing.T) {
+		service := setup(t)
+
+		resp, err := service.ExportVideos(ctx, &api.VideoExportRequest{VideoIds: []string{"video1", "video2", "video3", "video1"}})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, "application/zip", resp.Stream.ContentType)
+		assert.Regexp(t, `^zhulong-\d{8}-\d{6}\.zip$`, resp.FileName)
+
+		names, contents := readExport(t, resp)
+		assert.Equal(t, []string{"假期.mp4", "假期.en.vtt", "假期.zh.srt", "假期 (2).mp4", ".._clip.mov"}, names, "重复的视频ID只导出一次，重名文件加上序号")
+		assert.Equal(t, "first", contents["假期.mp4"])
+		assert.Equal(t, "second", contents["假期 (2).mp4"])
+		assert.Equal(t, "WEBVTT\n", contents["假期.en.vtt"])
+	})
+
+	t.Run("不导出字幕", func(t *testing.T) {
+		service := setup(t)
+
+		resp, err := service.ExportVideos(ctx, &api.VideoExportRequest{VideoIds: []string{"video1"}, SkipSubtitles: true})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		names, _ := readExport(t, resp)
+		assert.Equal(t, []string{"假期.mp4"}, names)
+	})
+
+	t.Run("无效的导出请求", func(t *testing.T) {
+		service := setup(t)
+
+		resp, err := service.ExportVideos(ctx, &api.VideoExportRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2501), resp.Base.Code)
+
+		resp, err = service.ExportVideos(ctx, &api.VideoExportRequest{VideoIds: []string{"video1", ""}})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2501), resp.Base.Code)
+
+		tooMany := make([]string, maxExportVideos+1)
+		for i := range tooMany {
+			tooMany[i] = "video" + string(rune('a'+i%26)) + string(rune('a'+i/26))
+		}
+		resp, err = service.ExportVideos(ctx, &api.VideoExportRequest{VideoIds: tooMany})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2501), resp.Base.Code)
+
+		resp, err = service.ExportVideos(ctx, &api.VideoExportRequest{VideoIds: []string{"video1", "missing"}})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2502), resp.Base.Code, "任意一个视频不存在时不导出")
+	})
+
+	t.Run("不能导出不可见的视频", func(t *testing.T) {
+		service := setup(t)
+		private := metadata.VisibilityPrivate
+		require.NoError(t, service.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{FileID: "video2", Visibility: &private}))
+
+		resp, err := service.ExportVideos(claimsContext("viewer-1", user.RoleViewer), &api.VideoExportRequest{VideoIds: []string{"video1", "video2"}})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2502), resp.Base.Code)
+	})
+}
+
+func TestUniqueExportName(t *testing.T) {
+	names := make(map[string]bool)
+	assert.Equal(t, "clip.mp4", uniqueExportName(names, "clip.mp4"))
+	assert.Equal(t, "clip (2).mp4", uniqueExportName(names, "clip.mp4"))
+	assert.Equal(t, "clip (3).mp4", uniqueExportName(names, "clip.mp4"))
+	assert.Equal(t, "clip", uniqueExportName(names, "clip"))
+	assert.Equal(t, "clip (2)", uniqueExportName(names, "clip"))
+
+	assert.Equal(t, "a_b_c.mp4", exportFileName(`a/b\c.mp4`))
+	assert.Equal(t, "_..", exportFileName(".."))
+}
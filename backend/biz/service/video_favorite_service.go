@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// AddFavorite 收藏视频，重复收藏时保留原收藏时间
+func (s *VideoService) AddFavorite(ctx context.Context, req *api.VideoFavoriteRequest) (*api.VideoFavoriteResponse, error) {
+	claims, ok := user.ClaimsFromContext(ctx)
+	if !ok {
+		return s.videoFavoriteErrorResponse(7010, "未登录"), nil
+	}
+	if req.VideoID == "" {
+		return s.videoFavoriteErrorResponse(2301, "视频ID不能为空"), nil
+	}
+	if _, err := s.getVisibleMetadata(ctx, req.VideoID); err != nil {
+		return s.videoFavoriteErrorResponse(2302, "视频不存在"), nil
+	}
+
+	favorited, err := s.favorites.Add(ctx, claims.UserID, req.VideoID)
+	if err != nil {
+		return nil, fmt.Errorf("收藏视频失败: %w", err)
+	}
+
+	return &api.VideoFavoriteResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "收藏成功",
+		},
+		Favorited:   true,
+		FavoritedAt: favorited.FavoritedAt.UnixMilli(),
+	}, nil
+}
+
+// RemoveFavorite 取消收藏视频，未收藏时同样返回成功
+func (s *VideoService) RemoveFavorite(ctx context.Context, req *api.VideoFavoriteRequest) (*api.VideoFavoriteResponse, error) {
+	claims, ok := user.ClaimsFromContext(ctx)
+	if !ok {
+		return s.videoFavoriteErrorResponse(7010, "未登录"), nil
+	}
+	if req.VideoID == "" {
+		return s.videoFavoriteErrorResponse(2301, "视频ID不能为空"), nil
+	}
+
+	s.favorites.Remove(ctx, claims.UserID, req.VideoID)
+
+	return &api.VideoFavoriteResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "已取消收藏",
+		},
+	}, nil
+}
+
+// GetFavorites 获取当前用户收藏的视频，已删除和当前用户无权查看的视频不会出现在列表中
+func (s *VideoService) GetFavorites(ctx context.Context, req *api.FavoriteListRequest) (*api.FavoriteListResponse, error) {
+	claims, ok := user.ClaimsFromContext(ctx)
+	if !ok {
+		return s.favoriteListErrorResponse(7010, "未登录"), nil
+	}
+	if req.Limit < 0 {
+		return s.favoriteListErrorResponse(2301, "返回数量限制不能为负数"), nil
+	}
+
+	entries := s.favorites.List(ctx, claims.UserID, int(req.Limit))
+	videos := make([]*api.Video, 0, len(entries))
+	items := make([]*api.FavoriteVideo, 0, len(entries))
+	for _, entry := range entries {
+		meta, err := s.getVisibleMetadata(ctx, entry.VideoID)
+		if err != nil {
+			continue
+		}
+		video := convertToAPIVideo(meta)
+		video.IsFavorited = true
+		videos = append(videos, video)
+		items = append(items, &api.FavoriteVideo{
+			Video:       video,
+			FavoritedAt: entry.FavoritedAt.UnixMilli(),
+		})
+	}
+	s.fillResumePositions(ctx, videos)
+	s.fillViewCounts(ctx, videos)
+
+	return &api.FavoriteListResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Favorites: items,
+	}, nil
+}
+
+// fillFavorited 为当前登录用户标记已收藏的视频，未登录时不处理
+func (s *VideoService) fillFavorited(ctx context.Context, videos []*api.Video) {
+	claims, ok := user.ClaimsFromContext(ctx)
+	if !ok || len(videos) == 0 {
+		return
+	}
+
+	videoIDs := make([]string, 0, len(videos))
+	for _, video := range videos {
+		videoIDs = append(videoIDs, video.ID)
+	}
+
+	favorited := s.favorites.Favorited(ctx, claims.UserID, videoIDs)
+	for _, video := range videos {
+		video.IsFavorited = favorited[video.ID]
+	}
+}
+
+// videoFavoriteErrorResponse 创建收藏视频错误响应
+func (s *VideoService) videoFavoriteErrorResponse(code int32, message string) *api.VideoFavoriteResponse {
+	return &api.VideoFavoriteResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// favoriteListErrorResponse 创建收藏列表错误响应
+func (s *VideoService) favoriteListErrorResponse(code int32, message string) *api.FavoriteListResponse {
+	return &api.FavoriteListResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+		Favorites: []*api.FavoriteVideo{},
+	}
+}
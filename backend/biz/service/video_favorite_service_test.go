@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/delete"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+func TestVideoService_Favorites(t *testing.T) {
+	aliceCtx := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "alice"})
+	bobCtx := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "bob"})
+
+	t.Run("收藏视频并在列表中标记", func(t *testing.T) {
+		service := createHistoryTestService(t)
+
+		resp, err := service.AddFavorite(aliceCtx, &api.VideoFavoriteRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.True(t, resp.Favorited)
+		assert.Greater(t, resp.FavoritedAt, int64(0))
+
+		again, err := service.AddFavorite(aliceCtx, &api.VideoFavoriteRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		assert.Equal(t, resp.FavoritedAt, again.FavoritedAt, "重复收藏应该保留原收藏时间")
+
+		list, err := service.GetVideoList(aliceCtx, &api.VideoListRequest{})
+		require.NoError(t, err)
+		require.Len(t, list.Videos, 2)
+		for _, video := range list.Videos {
+			assert.Equal(t, video.ID == "video1", video.IsFavorited, "列表应该标记当前用户收藏的视频")
+		}
+
+		detail, err := service.GetVideoDetail(aliceCtx, &api.VideoDetailRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		assert.True(t, detail.Video.IsFavorited)
+
+		detail, err = service.GetVideoDetail(bobCtx, &api.VideoDetailRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		assert.False(t, detail.Video.IsFavorited, "收藏应该按用户隔离")
+
+		removed, err := service.RemoveFavorite(aliceCtx, &api.VideoFavoriteRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), removed.Base.Code, removed.Base.Message)
+		assert.False(t, removed.Favorited)
+
+		detail, err = service.GetVideoDetail(aliceCtx, &api.VideoDetailRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		assert.False(t, detail.Video.IsFavorited, "取消收藏后不应该再标记")
+	})
+
+	t.Run("收藏视频_参数错误", func(t *testing.T) {
+		service := createHistoryTestService(t)
+
+		resp, err := service.AddFavorite(context.Background(), &api.VideoFavoriteRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(7010), resp.Base.Code, "未登录时不能收藏")
+
+		resp, err = service.AddFavorite(aliceCtx, &api.VideoFavoriteRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2301), resp.Base.Code)
+
+		resp, err = service.AddFavorite(aliceCtx, &api.VideoFavoriteRequest{VideoID: "missing"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2302), resp.Base.Code)
+
+		resp, err = service.RemoveFavorite(context.Background(), &api.VideoFavoriteRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(7010), resp.Base.Code, "未登录时不能取消收藏")
+	})
+
+	t.Run("收藏列表", func(t *testing.T) {
+		service := createHistoryTestService(t)
+
+		for _, id := range []string{"video1", "video2"} {
+			_, err := service.AddFavorite(aliceCtx, &api.VideoFavoriteRequest{VideoID: id})
+			require.NoError(t, err)
+		}
+
+		resp, err := service.GetFavorites(aliceCtx, &api.FavoriteListRequest{})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		require.Len(t, resp.Favorites, 2)
+		require.NotNil(t, resp.Favorites[0].Video)
+		assert.True(t, resp.Favorites[0].Video.IsFavorited)
+
+		resp, err = service.GetFavorites(aliceCtx, &api.FavoriteListRequest{Limit: 1})
+		require.NoError(t, err)
+		assert.Len(t, resp.Favorites, 1)
+
+		resp, err = service.GetFavorites(aliceCtx, &api.FavoriteListRequest{Limit: -1})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2301), resp.Base.Code)
+
+		resp, err = service.GetFavorites(bobCtx, &api.FavoriteListRequest{})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Favorites)
+
+		resp, err = service.GetFavorites(context.Background(), &api.FavoriteListRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(7010), resp.Base.Code)
+	})
+
+	t.Run("收藏列表_视频改为私有", func(t *testing.T) {
+		service := createHistoryTestService(t)
+		for _, id := range []string{"video1", "video2"} {
+			_, err := service.AddFavorite(aliceCtx, &api.VideoFavoriteRequest{VideoID: id})
+			require.NoError(t, err)
+		}
+
+		private := metadata.VisibilityPrivate
+		require.NoError(t, service.metadataService.UpdateMetadata(context.Background(), &metadata.UpdateMetadataRequest{FileID: "video2", Visibility: &private}))
+
+		resp, err := service.GetFavorites(aliceCtx, &api.FavoriteListRequest{})
+		require.NoError(t, err)
+		require.Len(t, resp.Favorites, 1, "收藏后改为私有的视频不应该返回")
+		assert.Equal(t, "video1", resp.Favorites[0].Video.ID)
+	})
+
+	t.Run("删除视频时清除收藏", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		service.deleteService = delete.NewDeleteService(store)
+
+		uploaded := uploadTestVideo(t, service, "first.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+		_, err := service.AddFavorite(aliceCtx, &api.VideoFavoriteRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+
+		deleteResp, err := service.DeleteVideo(context.Background(), &api.VideoDeleteRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), deleteResp.Base.Code, deleteResp.Base.Message)
+
+		assert.Empty(t, service.favorites.List(aliceCtx, "alice", 0), "删除视频后应该清除收藏")
+	})
+}
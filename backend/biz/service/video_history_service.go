@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/history"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// UpdateWatchProgress 记录当前用户的播放进度
+func (s *VideoService) UpdateWatchProgress(ctx context.Context, req *api.VideoProgressRequest) (*api.VideoProgressResponse, error) {
+	claims, ok := user.ClaimsFromContext(ctx)
+	if !ok {
+		return s.videoProgressErrorResponse(7010, "未登录"), nil
+	}
+	if req.VideoID == "" {
+		return s.videoProgressErrorResponse(2201, "视频ID不能为空"), nil
+	}
+	if req.Position < 0 {
+		return s.videoProgressErrorResponse(2201, "播放位置不能为负数"), nil
+	}
+
+	meta, err := s.getVisibleMetadata(ctx, req.VideoID)
+	if err != nil {
+		return s.videoProgressErrorResponse(2202, "视频不存在"), nil
+	}
+
+	progress, err := s.history.RecordProgress(ctx, claims.UserID, req.VideoID, req.Position, meta.Duration)
+	if err != nil {
+		return nil, fmt.Errorf("记录播放进度失败: %w", err)
+	}
+
+	return &api.VideoProgressResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "记录成功",
+		},
+		Progress: convertToAPIWatchProgress(progress),
+	}, nil
+}
+
+// GetWatchHistory 获取当前用户的观看历史，已删除和当前用户无权查看的视频不会出现在历史中
+func (s *VideoService) GetWatchHistory(ctx context.Context, req *api.WatchHistoryRequest) (*api.WatchHistoryResponse, error) {
+	claims, ok := user.ClaimsFromContext(ctx)
+	if !ok {
+		return s.watchHistoryErrorResponse(7010, "未登录"), nil
+	}
+	if req.Limit < 0 {
+		return s.watchHistoryErrorResponse(2201, "返回数量限制不能为负数"), nil
+	}
+
+	entries := s.history.ListHistory(ctx, claims.UserID, int(req.Limit))
+	items := make([]*api.WatchProgress, 0, len(entries))
+	for _, progress := range entries {
+		meta, err := s.getVisibleMetadata(ctx, progress.VideoID)
+		if err != nil {
+			continue
+		}
+		item := convertToAPIWatchProgress(progress)
+		item.Video = convertToAPIVideo(meta)
+		item.Video.ResumePosition = progress.ResumePosition()
+		items = append(items, item)
+	}
+
+	return &api.WatchHistoryResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		History: items,
+	}, nil
+}
+
+// fillResumePositions 为当前登录用户填充视频的续播位置，未登录时不处理
+func (s *VideoService) fillResumePositions(ctx context.Context, videos []*api.Video) {
+	claims, ok := user.ClaimsFromContext(ctx)
+	if !ok || len(videos) == 0 {
+		return
+	}
+
+	videoIDs := make([]string, 0, len(videos))
+	for _, video := range videos {
+		videoIDs = append(videoIDs, video.ID)
+	}
+
+	positions := s.history.ResumePositions(ctx, claims.UserID, videoIDs)
+	for _, video := range videos {
+		video.ResumePosition = positions[video.ID]
+	}
+}
+
+// convertToAPIWatchProgress 转换为API播放进度格式
+func convertToAPIWatchProgress(progress *history.Progress) *api.WatchProgress {
+	return &api.WatchProgress{
+		VideoID:       progress.VideoID,
+		Position:      progress.Position,
+		Completed:     progress.Completed,
+		LastWatchedAt: progress.LastWatchedAt.UnixMilli(),
+	}
+}
+
+// videoProgressErrorResponse 创建播放进度错误响应
+func (s *VideoService) videoProgressErrorResponse(code int32, message string) *api.VideoProgressResponse {
+	return &api.VideoProgressResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// watchHistoryErrorResponse 创建观看历史错误响应
+func (s *VideoService) watchHistoryErrorResponse(code int32, message string) *api.WatchHistoryResponse {
+	return &api.WatchHistoryResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+		History: []*api.WatchProgress{},
+	}
+}
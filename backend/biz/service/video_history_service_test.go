@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/delete"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// createHistoryTestService 创建带测试视频的视频服务
+func createHistoryTestService(t *testing.T) *VideoService {
+	service := createTestVideoService(t)
+	for _, id := range []string{"video1", "video2"} {
+		require.NoError(t, service.metadataService.SaveMetadata(context.Background(), &metadata.FileMetadata{
+			FileID:     id,
+			Title:      id,
+			Duration:   100,
+			Visibility: metadata.VisibilityPublic,
+			CreatedBy:  "system",
+		}))
+	}
+	return service
+}
+
+func TestVideoService_WatchProgress(t *testing.T) {
+	aliceCtx := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "alice"})
+	bobCtx := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "bob"})
+
+	t.Run("上报播放进度并返回续播位置", func(t *testing.T) {
+		service := createHistoryTestService(t)
+
+		resp, err := service.UpdateWatchProgress(aliceCtx, &api.VideoProgressRequest{VideoID: "video1", Position: 42})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, int64(42), resp.Progress.Position)
+		assert.False(t, resp.Progress.Completed)
+
+		detail, err := service.GetVideoDetail(aliceCtx, &api.VideoDetailRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), detail.Base.Code, detail.Base.Message)
+		assert.Equal(t, int64(42), detail.Video.ResumePosition, "详情应该包含续播位置")
+
+		detail, err = service.GetVideoDetail(bobCtx, &api.VideoDetailRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), detail.Video.ResumePosition, "续播位置应该按用户隔离")
+
+		list, err := service.GetVideoList(aliceCtx, &api.VideoListRequest{})
+		require.NoError(t, err)
+		require.Len(t, list.Videos, 2)
+		for _, video := range list.Videos {
+			if video.ID == "video1" {
+				assert.Equal(t, int64(42), video.ResumePosition, "列表应该包含续播位置")
+			} else {
+				assert.Equal(t, int64(0), video.ResumePosition)
+			}
+		}
+
+		resp, err = service.UpdateWatchProgress(aliceCtx, &api.VideoProgressRequest{VideoID: "video1", Position: 99})
+		require.NoError(t, err)
+		assert.True(t, resp.Progress.Completed, "接近结尾时应该视为已看完")
+		detail, err = service.GetVideoDetail(aliceCtx, &api.VideoDetailRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), detail.Video.ResumePosition, "已看完的视频应该从头播放")
+	})
+
+	t.Run("上报播放进度_参数错误", func(t *testing.T) {
+		service := createHistoryTestService(t)
+
+		resp, err := service.UpdateWatchProgress(context.Background(), &api.VideoProgressRequest{VideoID: "video1", Position: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int32(7010), resp.Base.Code, "未登录时不能上报进度")
+
+		resp, err = service.UpdateWatchProgress(aliceCtx, &api.VideoProgressRequest{VideoID: "video1", Position: -1})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2201), resp.Base.Code)
+
+		resp, err = service.UpdateWatchProgress(aliceCtx, &api.VideoProgressRequest{VideoID: "missing", Position: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2202), resp.Base.Code)
+	})
+
+	t.Run("观看历史", func(t *testing.T) {
+		service := createHistoryTestService(t)
+
+		for _, id := range []string{"video1", "video2"} {
+			_, err := service.UpdateWatchProgress(aliceCtx, &api.VideoProgressRequest{VideoID: id, Position: 10})
+			require.NoError(t, err)
+		}
+
+		resp, err := service.GetWatchHistory(aliceCtx, &api.WatchHistoryRequest{})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		require.Len(t, resp.History, 2)
+		require.NotNil(t, resp.History[0].Video)
+		assert.Equal(t, int64(10), resp.History[0].Video.ResumePosition)
+
+		resp, err = service.GetWatchHistory(aliceCtx, &api.WatchHistoryRequest{Limit: 1})
+		require.NoError(t, err)
+		assert.Len(t, resp.History, 1)
+
+		resp, err = service.GetWatchHistory(bobCtx, &api.WatchHistoryRequest{})
+		require.NoError(t, err)
+		assert.Empty(t, resp.History)
+	})
+
+	t.Run("观看历史_视频改为私有", func(t *testing.T) {
+		service := createHistoryTestService(t)
+		for _, id := range []string{"video1", "video2"} {
+			_, err := service.UpdateWatchProgress(aliceCtx, &api.VideoProgressRequest{VideoID: id, Position: 10})
+			require.NoError(t, err)
+		}
+
+		private := metadata.VisibilityPrivate
+		require.NoError(t, service.metadataService.UpdateMetadata(context.Background(), &metadata.UpdateMetadataRequest{FileID: "video1", Visibility: &private}))
+
+		resp, err := service.GetWatchHistory(aliceCtx, &api.WatchHistoryRequest{})
+		require.NoError(t, err)
+		require.Len(t, resp.History, 1, "观看后改为私有的视频不应该返回")
+		assert.Equal(t, "video2", resp.History[0].Video.ID)
+	})
+
+	t.Run("视频详情_视频不存在", func(t *testing.T) {
+		service := createHistoryTestService(t)
+
+		resp, err := service.GetVideoDetail(context.Background(), &api.VideoDetailRequest{VideoID: "missing"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2102), resp.Base.Code)
+	})
+
+	t.Run("删除视频时清除播放记录", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		service.deleteService = delete.NewDeleteService(store)
+
+		uploaded := uploadTestVideo(t, service, "first.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+		_, err := service.UpdateWatchProgress(aliceCtx, &api.VideoProgressRequest{VideoID: uploaded.Video.ID, Position: 1})
+		require.NoError(t, err)
+
+		deleteResp, err := service.DeleteVideo(context.Background(), &api.VideoDeleteRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), deleteResp.Base.Code, deleteResp.Base.Message)
+
+		assert.Empty(t, service.history.ListHistory(aliceCtx, "alice", 0), "删除视频后应该清除播放记录")
+	})
+}
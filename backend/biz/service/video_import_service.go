@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/importer"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/upload"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+// videoImport 批量导入任务
+type videoImport struct {
+	importer  *importer.Importer
+	register  bool   // 仅登记local存储中已有的文件，不复制
+	createdBy string // 发起导入的用户，作为导入视频的上传者
+}
+
+// StartVideoImport 在后台递归导入服务器目录中的视频文件
+// 每个文件经过与上传相同的大小和格式验证、信息提取和缩略图生成；内容已存在的文件被跳过，重新导入同一目录即可补齐失败的文件
+// 批量导入由管理员发起，不检查存储配额
+func (s *VideoService) StartVideoImport(ctx context.Context, req *api.VideoImportRequest) (*api.VideoImportResponse, error) {
+	if req.Path == "" {
+		return s.videoImportErrorResponse(9401, "导入目录不能为空"), nil
+	}
+	if !filepath.IsAbs(req.Path) {
+		return s.videoImportErrorResponse(9401, "导入目录必须是绝对路径"), nil
+	}
+	if _, err := os.Stat(req.Path); errors.Is(err, fs.ErrNotExist) {
+		return s.videoImportErrorResponse(9402, "导入目录不存在"), nil
+	}
+
+	if req.Register {
+		local, ok := s.LocalStorage()
+		if !ok {
+			return s.videoImportErrorResponse(9401, "仅登记文件需要使用local存储驱动"), nil
+		}
+		// 使用目录下的文件名检查，导入目录可以是视频存储桶目录本身
+		if _, err := local.ObjectName(s.buckets.Bucket(storage.ContentVideos), filepath.Join(req.Path, "_")); err != nil {
+			return s.videoImportErrorResponse(9401, "仅登记文件时导入目录必须位于视频存储桶目录内"), nil
+		}
+	}
+
+	imp, err := importer.NewImporter(req.Path, s.videoValidator.Load().GetSupportedFormats())
+	if err != nil {
+		return s.videoImportErrorResponse(9401, err.Error()), nil
+	}
+
+	s.importMutex.Lock()
+	defer s.importMutex.Unlock()
+
+	if s.videoImport != nil && s.videoImport.importer.Running() {
+		return s.videoImportErrorResponse(9403, "已有批量导入正在运行"), nil
+	}
+
+	job := &videoImport{importer: imp, register: req.Register, createdBy: currentUserID(ctx)}
+	s.videoImport = job
+
+	go s.runVideoImport(job)
+
+	return &api.VideoImportResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "批量导入已开始",
+		},
+		ImportJob: convertVideoImport(job),
+	}, nil
+}
+
+// GetVideoImport 获取正在运行或最近一次的批量导入进度
+func (s *VideoService) GetVideoImport(ctx context.Context) (*api.VideoImportResponse, error) {
+	s.importMutex.Lock()
+	job := s.videoImport
+	s.importMutex.Unlock()
+
+	if job == nil {
+		return s.videoImportErrorResponse(9402, "没有批量导入记录"), nil
+	}
+
+	return &api.VideoImportResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		ImportJob: convertVideoImport(job),
+	}, nil
+}
+
+// runVideoImport 在后台执行批量导入
+func (s *VideoService) runVideoImport(job *videoImport) {
+	failed, err := job.importer.Run(context.Background(), func(ctx context.Context, file importer.File) (bool, error) {
+		return s.importVideoFile(ctx, job, file)
+	})
+	if err != nil {
+		fmt.Printf("批量导入失败(%s): %v\n", job.importer.Root(), err)
+		return
+	}
+	progress := job.importer.Progress()
+	fmt.Printf("批量导入完成(%s): 导入%d个，跳过%d个，失败%d个\n", job.importer.Root(), progress.Imported, progress.Skipped, failed)
+}
+
+// importVideoFile 导入单个视频文件，内容已存在或文件已登记时返回false
+func (s *VideoService) importVideoFile(ctx context.Context, job *videoImport, file importer.File) (bool, error) {
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return false, fmt.Errorf("打开文件失败: %w", err)
+	}
+	defer f.Close()
+
+	headData, err := readFileHead(f, videoHeadSize)
+	if err != nil {
+		return false, err
+	}
+
+	if err := s.validateUploadSize(ctx, file.Path, file.Size); err != nil {
+		return false, fmt.Errorf("文件大小验证失败: %w", err)
+	}
+
+	fileName := filepath.Base(file.Path)
+	validationResult, err := s.videoValidator.Load().ValidateFormat(&video.ValidationRequest{
+		Filename: fileName,
+		Data:     headData[:min(len(headData), 512)],
+	})
+	if err != nil {
+		return false, fmt.Errorf("文件格式验证失败: %w", err)
+	}
+	if !validationResult.IsValid {
+		return false, fmt.Errorf("不支持的文件格式: %s", validationResult.ErrorMessage)
+	}
+	if err := s.verifyVideoStructure(f, file.Size, validationResult.DetectedFormat); err != nil {
+		return false, fmt.Errorf("文件结构验证失败: %w", err)
+	}
+	if err := s.probeLocalVideo(ctx, file.Path); err != nil {
+		return false, fmt.Errorf("文件结构验证失败: %w", err)
+	}
+	contentType := video.ContentTypeForFormat(validationResult.DetectedFormat)
+
+	// 内容已存在时跳过，重复导入同一目录不会产生重复的视频
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return false, fmt.Errorf("重置文件读取位置失败: %w", err)
+	}
+	checksum, err := upload.ChecksumReader(f)
+	if err != nil {
+		return false, err
+	}
+	if _, err := s.metadataService.GetMetadataByChecksum(ctx, checksum); err == nil {
+		return false, nil
+	}
+
+	videoInfo, err := s.videoExtractor.ExtractInfo(&video.InfoExtractionRequest{
+		Data:     headData,
+		Filename: fileName,
+		Reader:   f,
+		Size:     file.Size,
+	})
+	if err != nil {
+		// 信息提取失败不阻断导入，使用默认值
+		videoInfo = &video.VideoInfo{
+			Filename: fileName,
+			Format:   validationResult.DetectedFormat,
+			FileSize: file.Size,
+		}
+	}
+
+	videoID := uuid.New().String()
+	bucketName := s.buckets.Bucket(storage.ContentVideos)
+	var objectName string
+	if job.register {
+		objectName, err = s.registeredObjectName(ctx, bucketName, file.Path)
+		if err != nil {
+			return false, err
+		}
+	} else {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return false, fmt.Errorf("重置文件读取位置失败: %w", err)
+		}
+		now := time.Now()
+		result, err := s.uploadService.UploadFile(ctx, &upload.UploadRequest{
+			BucketName:  bucketName,
+			FileName:    fileName,
+			ObjectName:  fmt.Sprintf("videos/%d/%02d/%s%s", now.Year(), now.Month(), videoID, upload.ObjectExtension(fileName)),
+			Reader:      f,
+			Size:        file.Size,
+			ContentType: contentType,
+			Checksum:    checksum,
+			Metadata:    videoObjectLabels(videoID, job.createdBy),
+			Tags:        videoObjectLabels(videoID, job.createdBy),
+		})
+		if err != nil {
+			return false, fmt.Errorf("文件上传失败: %w", err)
+		}
+		objectName = result.ObjectName
+	}
+
+	// 重置读取位置，用于抽帧生成缩略图
+	var videoReader io.Reader
+	if _, err := f.Seek(0, io.SeekStart); err == nil {
+		videoReader = f
+	}
+
+	_, err = s.finalizeUpload(ctx, &uploadedVideo{
+		VideoID:     videoID,
+		BucketName:  bucketName,
+		ObjectName:  objectName,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        file.Size,
+		Title:       strings.TrimSuffix(fileName, filepath.Ext(fileName)),
+		Info:        videoInfo,
+		Checksum:    checksum,
+		HeadData:    headData,
+		Reader:      videoReader,
+		Visibility:  s.config.GetDefaultVisibility(),
+		CreatedBy:   job.createdBy,
+		// 登记的文件保留在原位置，保存失败时不删除
+		SharedObject: job.register,
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// registeredObjectName 获取视频存储桶目录中已有文件的对象名，文件已被其他视频引用时返回错误
+func (s *VideoService) registeredObjectName(ctx context.Context, bucketName, filePath string) (string, error) {
+	local, ok := s.LocalStorage()
+	if !ok {
+		return "", fmt.Errorf("仅登记文件需要使用local存储驱动")
+	}
+	objectName, err := local.ObjectName(bucketName, filePath)
+	if err != nil {
+		return "", err
+	}
+	if existing, err := s.metadataService.GetMetadataByObjectName(ctx, bucketName, objectName); err == nil {
+		return "", fmt.Errorf("文件已被视频%s引用", existing.FileID)
+	}
+	return objectName, nil
+}
+
+// convertVideoImport 转换批量导入进度
+func convertVideoImport(job *videoImport) *api.VideoImport {
+	progress := job.importer.Progress()
+	result := &api.VideoImport{
+		Path:          job.importer.Root(),
+		Register:      job.register,
+		Status:        progress.Status,
+		Total:         int32(progress.Total),
+		Imported:      int32(progress.Imported),
+		Skipped:       int32(progress.Skipped),
+		Failed:        int32(progress.Failed),
+		BytesImported: progress.BytesImported,
+		StartedAt:     progress.StartedAt.UnixMilli(),
+	}
+	if progress.Error != "" {
+		result.Error = &progress.Error
+	}
+	if !progress.FinishedAt.IsZero() {
+		finishedAt := progress.FinishedAt.UnixMilli()
+		result.FinishedAt = &finishedAt
+	}
+	return result
+}
+
+// videoImportErrorResponse 创建批量导入错误响应
+func (s *VideoService) videoImportErrorResponse(code int32, message string) *api.VideoImportResponse {
+	return &api.VideoImportResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/importer"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// writeImportFile 在导入目录中写入测试文件
+func writeImportFile(t *testing.T, root, name string, data []byte) {
+	path := filepath.Join(root, filepath.FromSlash(name))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+}
+
+// startTestVideoImport 发起批量导入并等待结束
+func startTestVideoImport(t *testing.T, service *VideoService, req *api.VideoImportRequest) *api.VideoImport {
+	ctx := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "admin-1"})
+	resp, err := service.StartVideoImport(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+	require.Eventually(t, func() bool {
+		resp, err = service.GetVideoImport(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		return resp.ImportJob.FinishedAt != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	return resp.ImportJob
+}
+
+// importedVideos 按标题索引全部视频元数据
+func importedVideos(t *testing.T, service *VideoService) map[string]*metadata.FileMetadata {
+	list, err := service.metadataService.ListMetadata(context.Background(), &metadata.ListMetadataRequest{Limit: math.MaxInt})
+	require.NoError(t, err)
+	videos := make(map[string]*metadata.FileMetadata, len(list.Items))
+	for _, meta := range list.Items {
+		videos[meta.Title] = meta
+	}
+	return videos
+}
+
+func TestVideoService_VideoImport(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("上传目录中的视频并跳过已导入的文件", func(t *testing.T) {
+		service, store := createArchiveTestService(t)
+		root := t.TempDir()
+		writeImportFile(t, root, "a.mp4", mp4TestData(2048))
+		writeImportFile(t, root, "season 1/b.mp4", mp4TestData(4096))
+		writeImportFile(t, root, "season 1/notes.txt", []byte("notes"))
+		writeImportFile(t, root, "broken.mp4", []byte("not a video"))
+
+		job := startTestVideoImport(t, service, &api.VideoImportRequest{Path: root})
+		assert.Equal(t, importer.StatusCompleted, job.Status)
+		assert.Equal(t, int32(3), job.Total, "非视频扩展名的文件应该被忽略")
+		assert.Equal(t, int32(2), job.Imported)
+		assert.Equal(t, int32(1), job.Failed)
+		assert.Equal(t, int64(2048+4096), job.BytesImported)
+		require.NotNil(t, job.Error)
+		assert.Contains(t, *job.Error, "broken.mp4")
+
+		videos := importedVideos(t, service)
+		require.Len(t, videos, 2)
+		meta := videos["b"]
+		require.NotNil(t, meta, "标题应该使用不含扩展名的文件名")
+		assert.Equal(t, "b.mp4", meta.FileName)
+		assert.Equal(t, "video/mp4", meta.ContentType)
+		assert.Equal(t, "admin-1", meta.CreatedBy, "导入的视频应该属于发起导入的用户")
+		assert.NotEmpty(t, meta.Checksum)
+		assert.Equal(t, "zhulong-videos", meta.BucketName)
+		data, err := store.DownloadFile(ctx, meta.BucketName, meta.ObjectName)
+		require.NoError(t, err)
+		assert.Equal(t, mp4TestData(4096), data, "视频文件应该被复制到存储")
+
+		// 重新导入时跳过内容已存在的文件
+		job = startTestVideoImport(t, service, &api.VideoImportRequest{Path: root})
+		assert.Equal(t, int32(0), job.Imported)
+		assert.Equal(t, int32(2), job.Skipped)
+		assert.Equal(t, int32(1), job.Failed)
+		assert.Len(t, importedVideos(t, service), 2)
+	})
+
+	t.Run("仅登记视频存储桶中已有的文件", func(t *testing.T) {
+		service, store := createArchiveTestService(t)
+		require.NoError(t, store.CreateBucket(ctx, "zhulong-videos"))
+		library := filepath.Join(store.RootDir(), "zhulong-videos", "library")
+		writeImportFile(t, library, "movies/c.mp4", mp4TestData(3072))
+
+		job := startTestVideoImport(t, service, &api.VideoImportRequest{Path: library, Register: true})
+		assert.Equal(t, importer.StatusCompleted, job.Status)
+		assert.True(t, job.Register)
+		assert.Equal(t, int32(1), job.Imported)
+
+		meta := importedVideos(t, service)["c"]
+		require.NotNil(t, meta)
+		assert.Equal(t, "library/movies/c.mp4", meta.ObjectName, "登记的文件应该使用原有路径作为对象名")
+		files, err := store.ListFiles(ctx, "zhulong-videos", "videos/")
+		require.NoError(t, err)
+		assert.Empty(t, files, "登记文件不应该复制视频")
+
+		resp, err := service.StartVideoImport(ctx, &api.VideoImportRequest{Path: t.TempDir(), Register: true})
+		require.NoError(t, err)
+		assert.Equal(t, int32(9401), resp.Base.Code, "视频存储桶之外的目录不能登记")
+	})
+
+	t.Run("非local存储驱动不能登记文件", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+		resp, err := service.StartVideoImport(ctx, &api.VideoImportRequest{Path: t.TempDir(), Register: true})
+		require.NoError(t, err)
+		assert.Equal(t, int32(9401), resp.Base.Code)
+	})
+
+	t.Run("参数验证", func(t *testing.T) {
+		service, _ := createArchiveTestService(t)
+
+		resp, err := service.GetVideoImport(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int32(9402), resp.Base.Code, "没有导入记录时应该返回9402")
+
+		resp, err = service.StartVideoImport(ctx, &api.VideoImportRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(9401), resp.Base.Code)
+
+		resp, err = service.StartVideoImport(ctx, &api.VideoImportRequest{Path: "videos"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(9401), resp.Base.Code, "相对路径应该被拒绝")
+
+		resp, err = service.StartVideoImport(ctx, &api.VideoImportRequest{Path: filepath.Join(t.TempDir(), "missing")})
+		require.NoError(t, err)
+		assert.Equal(t, int32(9402), resp.Base.Code)
+	})
+}
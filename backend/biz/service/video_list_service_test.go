@@ -1,14 +1,22 @@
 package service
 
 import (
-	"context"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/analytics"
+	"github.com/manteia/zhulong/pkg/collection"
+	"github.com/manteia/zhulong/pkg/favorite"
+	"github.com/manteia/zhulong/pkg/history"
 	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/moderation"
+	"github.com/manteia/zhulong/pkg/playlist"
+	"github.com/manteia/zhulong/pkg/share"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/user"
 )
 
 func TestVideoService_GetVideoList(t *testing.T) {
@@ -24,7 +32,8 @@ func TestVideoService_GetVideoList(t *testing.T) {
 			ContentType: "video/mp4",
 			FileSize:    1024000,
 			Duration:    60,
-			Resolution:  "1920x1080",
+			Width:       1920,
+			Height:      1080,
 			Thumbnail:   "thumbnails/video1.jpg",
 			CreatedBy:   "system",
 			CreatedAt:   time.Now().Add(-2 * time.Hour),
@@ -37,7 +46,8 @@ func TestVideoService_GetVideoList(t *testing.T) {
 			ContentType: "video/mp4",
 			FileSize:    2048000,
 			Duration:    120,
-			Resolution:  "1280x720",
+			Width:       1280,
+			Height:      720,
 			Thumbnail:   "thumbnails/video2.jpg",
 			CreatedBy:   "system",
 			CreatedAt:   time.Now().Add(-1 * time.Hour),
@@ -50,7 +60,8 @@ func TestVideoService_GetVideoList(t *testing.T) {
 			ContentType: "video/mp4",
 			FileSize:    512000,
 			Duration:    30,
-			Resolution:  "1920x1080",
+			Width:       1920,
+			Height:      1080,
 			Thumbnail:   "thumbnails/video3.jpg",
 			CreatedBy:   "system",
 			CreatedAt:   time.Now(),
@@ -58,8 +69,8 @@ func TestVideoService_GetVideoList(t *testing.T) {
 		},
 	}
 
-	// 保存测试数据
-	ctx := context.Background()
+	// 保存测试数据，以管理员身份列出全部视频
+	ctx := claimsContext("admin", user.RoleAdmin)
 	for _, video := range testVideos {
 		err := service.metadataService.SaveMetadata(ctx, video)
 		require.NoError(t, err)
@@ -121,6 +132,33 @@ func TestVideoService_GetVideoList(t *testing.T) {
 		assert.Equal(t, "测试视频1", resp.Videos[2].Title)
 	})
 
+	t.Run("获取视频列表_多字段排序", func(t *testing.T) {
+		resp, err := service.GetVideoList(ctx, &api.VideoListRequest{
+			Page:      1,
+			PageSize:  10,
+			SortBy:    "uploaded_at, title",
+			SortOrder: "asc",
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		for i := 1; i < len(resp.Videos); i++ {
+			assert.LessOrEqual(t, resp.Videos[i-1].UploadedAt, resp.Videos[i].UploadedAt, "应该按上传时间升序排列")
+		}
+	})
+
+	t.Run("获取视频列表_无效排序", func(t *testing.T) {
+		for _, req := range []*api.VideoListRequest{
+			{SortBy: "title; DROP TABLE videos"},
+			{SortBy: "checksum"},
+			{SortBy: "title", SortOrder: "sideways"},
+		} {
+			resp, err := service.GetVideoList(ctx, req)
+			require.NoError(t, err)
+			assert.Equal(t, int32(2001), resp.Base.Code, req.SortBy)
+			assert.Contains(t, resp.Base.Message, "排序条件无效")
+		}
+	})
+
 	t.Run("获取视频列表_空结果", func(t *testing.T) {
 		// 创建新的服务实例，没有数据
 		emptyService := createTestVideoService(t)
@@ -160,11 +198,79 @@ func TestVideoService_GetVideoList(t *testing.T) {
 		assert.NotEqual(t, int32(0), resp.Base.Code)
 		assert.Contains(t, resp.Base.Message, "页面大小")
 	})
+
+	t.Run("获取视频列表_过滤条件", func(t *testing.T) {
+		other := &metadata.FileMetadata{
+			FileID:      "video4",
+			Title:       "测试视频4",
+			ContentType: "video/webm",
+			Duration:    300,
+			Width:       640,
+			Height:      360,
+			CreatedBy:   "alice",
+			CreatedAt:   time.Now().Add(-3 * time.Hour),
+		}
+		require.NoError(t, service.metadataService.SaveMetadata(ctx, other))
+		defer service.metadataService.DeleteMetadata(ctx, other.FileID)
+
+		tests := []struct {
+			name     string
+			req      *api.VideoListRequest
+			expected []string
+		}{
+			{"内容类型", &api.VideoListRequest{ContentType: "video/webm, video/x-flv"}, []string{"video4"}},
+			{"时长范围", &api.VideoListRequest{MinDuration: 60, MaxDuration: 120}, []string{"video2", "video1"}},
+			{"最低分辨率", &api.VideoListRequest{MinHeight: 1080}, []string{"video3", "video1"}},
+			{"上传者", &api.VideoListRequest{CreatedBy: "alice"}, []string{"video4"}},
+			{"上传时间范围", &api.VideoListRequest{
+				UploadedAfter:  time.Now().Add(-150 * time.Minute).UnixMilli(),
+				UploadedBefore: time.Now().Add(-30 * time.Minute).UnixMilli(),
+			}, []string{"video2", "video1"}},
+		}
+
+		for _, tc := range tests {
+			resp, err := service.GetVideoList(ctx, tc.req)
+			require.NoError(t, err)
+			require.Equal(t, int32(0), resp.Base.Code, tc.name)
+			assert.Equal(t, int32(len(tc.expected)), resp.Total, tc.name)
+
+			ids := make([]string, 0, len(resp.Videos))
+			for _, video := range resp.Videos {
+				ids = append(ids, video.ID)
+			}
+			assert.Equal(t, tc.expected, ids, tc.name)
+		}
+	})
+
+	t.Run("获取视频列表_过滤条件无效", func(t *testing.T) {
+		invalid := []*api.VideoListRequest{
+			{MinDuration: -1},
+			{MinDuration: 120, MaxDuration: 60},
+			{MinHeight: -720},
+			{UploadedAfter: 2000, UploadedBefore: 1000},
+		}
+		for _, req := range invalid {
+			resp, err := service.GetVideoList(ctx, req)
+			require.NoError(t, err)
+			assert.Equal(t, int32(2001), resp.Base.Code, "无效的过滤条件应该返回参数错误: %+v", req)
+		}
+	})
 }
 
 // createTestVideoService 创建测试用的视频服务
 func createTestVideoService(t *testing.T) *VideoService {
+	store, err := storage.NewMemoryStorage(nil)
+	require.NoError(t, err)
 	return &VideoService{
+		buckets:         storage.NewBucketResolver(testBucket, nil),
+		storageClient:   store,
 		metadataService: metadata.NewMetadataService(),
+		collections:     collection.NewCollectionService(),
+		playlists:       playlist.NewPlaylistService(),
+		history:         history.NewHistoryService(),
+		views:           analytics.NewViewTracker(),
+		favorites:       favorite.NewFavoriteService(),
+		shares:          share.NewShareService(),
+		reviews:         moderation.NewReviewQueue(),
 	}
 }
\ No newline at end of file
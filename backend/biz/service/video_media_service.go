@@ -0,0 +1,161 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/media"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+const (
+	// mediaImageMaxSize 生成图片缩略图时最多读取的图片大小，超过时不生成缩略图
+	mediaImageMaxSize = 64 * 1024 * 1024
+	// waveformBuckets 音频波形缩略图的峰值区间数量
+	waveformBuckets = 400
+)
+
+// mediaUploadPolicy 允许上传的媒体类型，配置重新加载时替换；为nil时只允许视频
+type mediaUploadPolicy struct {
+	videoAllowed bool
+	validator    *media.Validator // 允许的图片和音频格式
+}
+
+// newMediaUploadPolicy 根据upload.media_types创建媒体类型策略
+func newMediaUploadPolicy(cfg *config.Config) (*mediaUploadPolicy, error) {
+	mediaTypes, err := cfg.GetUploadMediaTypes()
+	if err != nil {
+		return nil, err
+	}
+	validator, err := media.NewValidator(media.FormatsForMediaTypes(mediaTypes))
+	if err != nil {
+		return nil, err
+	}
+	return &mediaUploadPolicy{
+		videoAllowed: slices.Contains(mediaTypes, metadata.MediaTypeVideo),
+		validator:    validator,
+	}, nil
+}
+
+// allowsVideo 是否允许上传视频
+func (p *mediaUploadPolicy) allowsVideo() bool {
+	return p == nil || p.videoAllowed
+}
+
+// mediaFormat 文件扩展名为允许上传的图片或音频格式时返回该格式
+func (p *mediaUploadPolicy) mediaFormat(fileName string) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	format := media.FormatFromFilename(fileName)
+	return format, p.validator.IsFormatSupported(format)
+}
+
+// uploadMediaType 按文件扩展名判断上传文件的媒体类型，不是允许的图片或音频格式时按视频处理
+func (s *VideoService) uploadMediaType(fileName string) string {
+	format, ok := s.mediaPolicy.Load().mediaFormat(fileName)
+	if !ok {
+		return metadata.MediaTypeVideo
+	}
+	return media.MediaTypeForFormat(format)
+}
+
+// isUploadFormatSupported 检查文件扩展名是否允许上传，用于获取直传地址和初始化分片上传
+func (s *VideoService) isUploadFormatSupported(fileName string) bool {
+	policy := s.mediaPolicy.Load()
+	if _, ok := policy.mediaFormat(fileName); ok {
+		return true
+	}
+	return policy.allowsVideo() && s.videoValidator.Load().IsFormatSupported(media.FormatFromFilename(fileName))
+}
+
+// uploadContentType 按文件扩展名获取上传文件的默认内容类型
+func (s *VideoService) uploadContentType(fileName string) string {
+	if format, ok := s.mediaPolicy.Load().mediaFormat(fileName); ok {
+		return media.ContentTypeForFormat(format)
+	}
+	return video.ContentTypeForFormat(media.FormatFromFilename(fileName))
+}
+
+// videoUploadAllowed 是否允许上传视频，upload.media_types不包含video时只能上传图片和音频
+func (s *VideoService) videoUploadAllowed() bool {
+	return s.mediaPolicy.Load().allowsVideo()
+}
+
+// inspectMediaFile 验证图片或音频的格式并提取信息，信息转换为VideoInfo用于保存元数据
+// 验证方式不是header时，无法解析出图片尺寸或音频时长的文件视为结构无效
+func (s *VideoService) inspectMediaFile(fileName string, headData []byte, reader io.ReaderAt, size int64) (*video.VideoInfo, error) {
+	result, err := s.mediaPolicy.Load().validator.Validate(fileName, headData)
+	if err != nil {
+		return nil, err
+	}
+
+	var info *media.Info
+	if result.MediaType == metadata.MediaTypeImage {
+		info, err = media.ExtractImageInfo(headData, result.Format)
+	} else {
+		info, err = media.ExtractAudioInfo(reader, size, result.Format)
+	}
+	videoInfo := &video.VideoInfo{Filename: fileName, Format: result.Format, FileSize: size}
+	if err != nil {
+		if s.config.GetUploadVerification() != config.VerificationHeader {
+			return nil, fmt.Errorf("%w: %v", video.ErrInvalidStructure, err)
+		}
+		return videoInfo, nil
+	}
+
+	videoInfo.Width, videoInfo.Height = info.Width, info.Height
+	videoInfo.Duration, videoInfo.Bitrate = info.Duration, info.Bitrate
+	// 图片编码记录在视频编码中
+	if result.MediaType == metadata.MediaTypeImage {
+		videoInfo.VideoCodec = info.Codec
+	} else {
+		videoInfo.AudioCodec = info.Codec
+	}
+	return videoInfo, nil
+}
+
+// uploadMediaThumbnail 生成并上传图片或音频的缩略图：图片缩放原图，音频绘制波形
+func (s *VideoService) uploadMediaThumbnail(ctx context.Context, objectName string, uploaded *uploadedVideo) error {
+	options := &video.ThumbnailOptions{
+		Width:      thumbnailWidth,
+		Height:     thumbnailHeight,
+		Quality:    80,
+		Format:     "jpeg",
+		KeepAspect: true,
+	}
+	var reader io.Reader = bytes.NewReader(uploaded.HeadData)
+	if uploaded.Reader != nil {
+		reader = uploaded.Reader
+	}
+
+	var thumbnailResult *video.ThumbnailResult
+	switch uploaded.MediaType {
+	case metadata.MediaTypeImage:
+		data, err := io.ReadAll(io.LimitReader(reader, mediaImageMaxSize))
+		if err != nil {
+			return fmt.Errorf("读取图片失败: %w", err)
+		}
+		thumbnailResult, err = s.thumbnailGenerator.GenerateFromPicture(data, options)
+		if err != nil {
+			return err
+		}
+	case metadata.MediaTypeAudio:
+		peaks, err := s.waveforms.Extract(ctx, reader, uploaded.Size, uploaded.Info.Format, waveformBuckets)
+		if err != nil {
+			return err
+		}
+		thumbnailResult, err = s.thumbnailGenerator.GenerateWaveform(peaks, options)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("不支持的媒体类型: %s", uploaded.MediaType)
+	}
+	return s.saveThumbnail(ctx, objectName, thumbnailResult)
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/storage"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+// createMediaTestService 创建允许上传指定媒体类型的测试服务
+func createMediaTestService(t *testing.T, mediaTypes string) (*VideoService, *storage.MemoryStorage) {
+	service, store := createDirectUploadTestService(t)
+	service.config.Upload.MediaTypes = mediaTypes
+	policy, err := newMediaUploadPolicy(service.config)
+	require.NoError(t, err)
+	service.mediaPolicy.Store(policy)
+	return service, store
+}
+
+// wavTestData 生成单声道16位PCM的正弦波WAV
+func wavTestData(sampleRate int, seconds float64) []byte {
+	samples := int(float64(sampleRate) * seconds)
+	data := make([]byte, 44+2*samples)
+	copy(data[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(data[4:8], uint32(len(data)-8))
+	copy(data[8:16], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(data[16:20], 16)
+	binary.LittleEndian.PutUint16(data[20:22], 1)
+	binary.LittleEndian.PutUint16(data[22:24], 1)
+	binary.LittleEndian.PutUint32(data[24:28], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(data[28:32], uint32(sampleRate*2))
+	binary.LittleEndian.PutUint16(data[32:34], 2)
+	binary.LittleEndian.PutUint16(data[34:36], 16)
+	copy(data[36:40], "data")
+	binary.LittleEndian.PutUint32(data[40:44], uint32(2*samples))
+	for i := 0; i < samples; i++ {
+		sample := int16(math.Sin(float64(i)/10) * 20000)
+		binary.LittleEndian.PutUint16(data[44+2*i:], uint16(sample))
+	}
+	return data
+}
+
+// uploadTestMedia 通过表单上传图片或音频
+func uploadTestMedia(t *testing.T, service *VideoService, filename, contentType string, data []byte) *api.VideoUploadResponse {
+	resp, err := service.UploadVideo(context.Background(), &api.VideoUploadRequest{}, createTestFileHeader(t, filename, contentType, data))
+	require.NoError(t, err)
+	return resp
+}
+
+func TestVideoService_MediaUpload(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("上传图片", func(t *testing.T) {
+		service, store := createMediaTestService(t, "video,image,audio")
+
+		resp := uploadTestMedia(t, service, "photo.png", "image/png", pngTestData(t, 640, 480))
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, metadata.MediaTypeImage, resp.Video.MediaType)
+		assert.Equal(t, metadata.StatusReady, resp.Video.Status, "图片不需要打包，上传后即可访问")
+		assert.Equal(t, int32(640), resp.Video.Width)
+		assert.Equal(t, int32(480), resp.Video.Height)
+		assert.Equal(t, "PNG", resp.Video.VideoCodec)
+		require.NotEmpty(t, resp.Video.ThumbnailPath, "图片应该生成缩略图")
+		assert.NotEmpty(t, testObjectData(t, store, resp.Video.ThumbnailPath))
+	})
+
+	t.Run("上传音频_生成波形缩略图", func(t *testing.T) {
+		service, store := createMediaTestService(t, "audio")
+
+		resp := uploadTestMedia(t, service, "voice.wav", "audio/wav", wavTestData(8000, 2))
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, metadata.MediaTypeAudio, resp.Video.MediaType)
+		assert.Equal(t, int64(2), resp.Video.Duration)
+		assert.Equal(t, "PCM", resp.Video.AudioCodec)
+		require.NotEmpty(t, resp.Video.ThumbnailPath, "音频应该生成波形缩略图")
+		assert.NotEmpty(t, testObjectData(t, store, resp.Video.ThumbnailPath))
+	})
+
+	t.Run("未允许的媒体类型", func(t *testing.T) {
+		service, _ := createMediaTestService(t, "")
+
+		resp := uploadTestMedia(t, service, "photo.png", "image/png", pngTestData(t, 16, 16))
+		assert.Equal(t, int32(1004), resp.Base.Code, "未配置media_types时按视频验证")
+	})
+
+	t.Run("只允许图片时拒绝视频", func(t *testing.T) {
+		service, _ := createMediaTestService(t, "image")
+
+		resp := uploadTestVideo(t, service, "movie.mp4", mp4TestData(2048))
+		assert.Equal(t, int32(1005), resp.Base.Code)
+
+		urlResp, err := service.CreateUploadURL(ctx, &api.VideoUploadURLRequest{Filename: "movie.mp4", Size: 2048})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1005), urlResp.Base.Code)
+		urlResp, err = service.CreateUploadURL(ctx, &api.VideoUploadURLRequest{Filename: "photo.jpeg", Size: 2048})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), urlResp.Base.Code, urlResp.Base.Message)
+	})
+
+	t.Run("内容与扩展名不匹配", func(t *testing.T) {
+		service, _ := createMediaTestService(t, "image,audio")
+
+		resp := uploadTestMedia(t, service, "photo.png", "image/png", wavTestData(8000, 1))
+		assert.Equal(t, int32(1004), resp.Base.Code)
+	})
+
+	t.Run("结构验证拒绝无法解析的图片", func(t *testing.T) {
+		service, _ := createMediaTestService(t, "image")
+		service.config.Upload.Verification = config.VerificationStructure
+
+		data := pngTestData(t, 16, 16)[:20]
+		resp := uploadTestMedia(t, service, "broken.png", "image/png", data)
+		assert.Equal(t, int32(1004), resp.Base.Code)
+	})
+
+	t.Run("按媒体类型过滤列表", func(t *testing.T) {
+		service, _ := createMediaTestService(t, "video,image,audio")
+		require.Equal(t, int32(0), uploadTestVideo(t, service, "movie.mp4", mp4TestData(2048)).Base.Code)
+		require.Equal(t, int32(0), uploadTestMedia(t, service, "photo.png", "image/png", pngTestData(t, 32, 32)).Base.Code)
+		require.Equal(t, int32(0), uploadTestMedia(t, service, "voice.wav", "audio/wav", wavTestData(8000, 1)).Base.Code)
+
+		for _, mediaType := range metadata.MediaTypes {
+			list, err := service.GetVideoList(ctx, &api.VideoListRequest{MediaType: strings.ToUpper(mediaType)})
+			require.NoError(t, err)
+			require.Equal(t, int32(0), list.Base.Code, list.Base.Message)
+			require.Len(t, list.Videos, 1, mediaType)
+			assert.Equal(t, mediaType, list.Videos[0].MediaType)
+		}
+
+		list, err := service.GetVideoList(ctx, &api.VideoListRequest{MediaType: "document"})
+		require.NoError(t, err)
+		assert.NotEqual(t, int32(0), list.Base.Code, "无效的媒体类型应该返回错误")
+	})
+
+	t.Run("图片不支持重新处理", func(t *testing.T) {
+		service, _ := createMediaTestService(t, "image")
+		resp := uploadTestMedia(t, service, "photo.png", "image/png", pngTestData(t, 32, 32))
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+		reprocess, err := service.ReprocessVideo(ctx, &api.VideoReprocessRequest{VideoID: resp.Video.ID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4604), reprocess.Base.Code)
+	})
+}
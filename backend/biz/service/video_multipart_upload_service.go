@@ -0,0 +1,443 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/middleware"
+	"github.com/manteia/zhulong/pkg/quota"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/upload"
+)
+
+// multipartDefaultChunkSize 未指定分片大小时使用的分片大小
+const multipartDefaultChunkSize = 8 * 1024 * 1024
+
+// multipartMaxChunkSize 允许的最大分片大小，分片请求体的大小限制等于分片大小
+const multipartMaxChunkSize = 64 * 1024 * 1024
+
+// multipartDefaultCleanupInterval 未配置时过期分片上传的清理间隔
+const multipartDefaultCleanupInterval = time.Hour
+
+// InitMultipartUpload 初始化分片上传
+// 客户端按分片大小依次或并行上传分片，全部上传后调用CompleteMultipartUpload完成
+func (s *VideoService) InitMultipartUpload(ctx context.Context, req *api.MultipartUploadInitRequest) (*api.MultipartUploadInitResponse, error) {
+	if !canUploadVideo(ctx) {
+		return s.multipartInitErrorResponse(1012, "无权上传视频"), nil
+	}
+	if req.Filename == "" {
+		return s.multipartInitErrorResponse(8101, "文件名不能为空"), nil
+	}
+
+	if err := s.validateUploadSize(ctx, req.Filename, req.Size); err != nil {
+		return s.multipartInitErrorResponse(1003, fmt.Sprintf("文件大小验证失败: %v", err)), nil
+	}
+
+	chunkSize := req.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = multipartDefaultChunkSize
+	}
+	// 只有一个分片时不受最小分片大小限制
+	if chunkSize < upload.MinPartSize && chunkSize < req.Size {
+		return s.multipartInitErrorResponse(8101, fmt.Sprintf("分片大小不能小于%d字节", upload.MinPartSize)), nil
+	}
+	if chunkSize > multipartMaxChunkSize {
+		return s.multipartInitErrorResponse(8101, fmt.Sprintf("分片大小不能大于%d字节", multipartMaxChunkSize)), nil
+	}
+	// 分片大小不超过文件大小，避免放宽分片请求体的大小限制
+	if req.Size > 0 && chunkSize > req.Size {
+		chunkSize = req.Size
+	}
+
+	visibility, err := s.uploadVisibility(req.Visibility)
+	if err != nil {
+		return s.multipartInitErrorResponse(8101, err.Error()), nil
+	}
+
+	ext := strings.TrimPrefix(filepath.Ext(req.Filename), ".")
+	if !s.isUploadFormatSupported(req.Filename) {
+		return s.multipartInitErrorResponse(1005, fmt.Sprintf("不支持的文件格式: %s", ext)), nil
+	}
+
+	// 初始化时只检查配额，完成上传时按实际大小预占
+	err = s.checkQuota(ctx, currentUserID(ctx), req.Size)
+	if errors.Is(err, quota.ErrQuotaExceeded) {
+		return s.multipartInitErrorResponse(1009, err.Error()), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := req.ContentType
+	if contentType == "" {
+		contentType = s.uploadContentType(req.Filename)
+	}
+
+	videoID := uuid.New().String()
+	now := time.Now()
+	bucketName := s.buckets.Bucket(storage.ContentVideos)
+	multipart, err := s.uploadService.InitMultipartUpload(ctx, &upload.MultipartUploadRequest{
+		FileName:    req.Filename,
+		ObjectName:  fmt.Sprintf("videos/%d/%02d/%s%s", now.Year(), now.Month(), videoID, upload.ObjectExtension(req.Filename)),
+		ContentType: contentType,
+		TotalSize:   req.Size,
+		BucketName:  bucketName,
+		ChunkSize:   chunkSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.multipartUploads.CreateSession(&upload.MultipartSession{
+		UploadID:    multipart.UploadID,
+		VideoID:     videoID,
+		BucketName:  bucketName,
+		ObjectName:  multipart.ObjectName,
+		FileName:    req.Filename,
+		ContentType: contentType,
+		TotalSize:   req.Size,
+		ChunkSize:   chunkSize,
+		Title:       getValueOrDefaultFromString(req.Title, req.Filename),
+		Description: req.Description,
+		Visibility:  visibility,
+		CreatedBy:   currentUserID(ctx),
+	})
+	if err != nil {
+		s.abortStorageMultipart(ctx, multipart.UploadID, bucketName, multipart.ObjectName)
+		return nil, err
+	}
+
+	return &api.MultipartUploadInitResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "初始化成功",
+		},
+		Upload: convertToAPIMultipartUpload(session),
+	}, nil
+}
+
+// ListMultipartUploads 列出当前用户进行中的分片上传，客户端可据此在断线或重启后继续上传
+func (s *VideoService) ListMultipartUploads(ctx context.Context, req *api.MultipartUploadListRequest) (*api.MultipartUploadListResponse, error) {
+	sessions := s.multipartUploads.ListSessions(currentUserID(ctx))
+	uploads := make([]*api.MultipartUpload, 0, len(sessions))
+	for _, session := range sessions {
+		uploads = append(uploads, convertToAPIMultipartUpload(session))
+	}
+
+	return &api.MultipartUploadListResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Uploads: uploads,
+	}, nil
+}
+
+// MaxMultipartPartSize 获取分片上传会话允许的最大分片大小，用于在读取分片数据之前拒绝过大的请求
+// 会话不存在或不属于当前用户时返回0，由处理函数返回对应的错误
+func (s *VideoService) MaxMultipartPartSize(ctx context.Context, uploadID string) int64 {
+	session, code, _ := s.ownMultipartSession(ctx, uploadID)
+	if code != 0 {
+		return 0
+	}
+	return session.ChunkSize
+}
+
+// UploadMultipartPart 上传一个分片，同一分片重复上传时覆盖此前的数据
+// 除最后一个分片外，分片大小必须等于初始化时的分片大小；size为请求声明的分片大小，未知时为-1
+// 分片数据从reader流式写入存储，不在内存中缓存整个分片
+func (s *VideoService) UploadMultipartPart(ctx context.Context, req *api.MultipartUploadPartRequest, reader io.Reader, size int64) (*api.MultipartUploadPartResponse, error) {
+	session, code, message := s.ownMultipartSession(ctx, req.UploadID)
+	if code != 0 {
+		return s.multipartPartErrorResponse(code, message), nil
+	}
+
+	partNumber := int(req.PartNumber)
+	totalParts := session.TotalParts()
+	if partNumber < 1 || partNumber > totalParts {
+		return s.multipartPartErrorResponse(8101, fmt.Sprintf("分片号必须在1-%d范围内", totalParts)), nil
+	}
+	expectedSize := session.ChunkSize
+	if partNumber == totalParts {
+		expectedSize = session.TotalSize - session.ChunkSize*int64(totalParts-1)
+	}
+	if size < 0 {
+		return s.multipartPartErrorResponse(8101, "分片请求必须声明Content-Length"), nil
+	}
+	if size != expectedSize {
+		return s.multipartPartErrorResponse(8101, fmt.Sprintf("分片%d大小必须为%d字节，实际%d字节", partNumber, expectedSize, size)), nil
+	}
+
+	part, err := s.uploadService.UploadPart(ctx, &upload.UploadPartRequest{
+		UploadID:   session.UploadID,
+		ObjectName: session.ObjectName,
+		PartNumber: partNumber,
+		Reader:     reader,
+		Size:       size,
+		BucketName: session.BucketName,
+	})
+	if errors.Is(err, upload.ErrPartIncomplete) {
+		return s.multipartPartErrorResponse(8101, err.Error()), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.multipartUploads.Touch(session.UploadID)
+
+	return &api.MultipartUploadPartResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "上传成功",
+		},
+		Part: &api.MultipartUploadPart{
+			PartNumber: req.PartNumber,
+			Etag:       part.ETag,
+			Size:       part.Size,
+		},
+	}, nil
+}
+
+// ListMultipartUploadParts 查询分片上传中已上传和缺失的分片
+// 客户端可以并行上传分片，断线后据此只重传缺失的分片，再用已上传分片的ETag完成上传
+func (s *VideoService) ListMultipartUploadParts(ctx context.Context, req *api.MultipartUploadPartsRequest) (*api.MultipartUploadPartsResponse, error) {
+	session, code, message := s.ownMultipartSession(ctx, req.UploadID)
+	if code != 0 {
+		return &api.MultipartUploadPartsResponse{
+			Base: &api.BaseResponse{
+				Code:    code,
+				Message: message,
+			},
+		}, nil
+	}
+
+	uploaded, err := s.uploadService.ListParts(ctx, &upload.ListPartsRequest{
+		UploadID:   session.UploadID,
+		ObjectName: session.ObjectName,
+		BucketName: session.BucketName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	totalParts := session.TotalParts()
+	received := make(map[int]bool, len(uploaded))
+	receivedParts := make([]*api.MultipartUploadPart, 0, len(uploaded))
+	var receivedSize int64
+	for _, part := range uploaded {
+		if part.PartNumber < 1 || part.PartNumber > totalParts {
+			continue
+		}
+		received[part.PartNumber] = true
+		receivedSize += part.Size
+		receivedParts = append(receivedParts, &api.MultipartUploadPart{
+			PartNumber: int32(part.PartNumber),
+			Etag:       part.ETag,
+			Size:       part.Size,
+		})
+	}
+	missingParts := make([]int32, 0, totalParts-len(received))
+	for partNumber := 1; partNumber <= totalParts; partNumber++ {
+		if !received[partNumber] {
+			missingParts = append(missingParts, int32(partNumber))
+		}
+	}
+
+	return &api.MultipartUploadPartsResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Upload:        convertToAPIMultipartUpload(session),
+		ReceivedParts: receivedParts,
+		MissingParts:  missingParts,
+		ReceivedSize:  receivedSize,
+	}, nil
+}
+
+// CompleteMultipartUpload 完成分片上传并创建视频
+// 分片缺失或与已上传的分片不一致时保留会话，客户端补传后可以再次完成
+func (s *VideoService) CompleteMultipartUpload(ctx context.Context, req *api.MultipartUploadCompleteRequest) (*api.VideoUploadResponse, error) {
+	expectedChecksum, err := upload.NormalizeChecksum(req.Checksum)
+	if err != nil {
+		return s.errorResponse(8101, err.Error()), nil
+	}
+	if len(req.Parts) == 0 {
+		return s.errorResponse(8101, "分片列表不能为空"), nil
+	}
+	parts := make([]upload.CompletedPart, 0, len(req.Parts))
+	for i, part := range req.Parts {
+		if int(part.PartNumber) != i+1 {
+			return s.errorResponse(8101, fmt.Sprintf("分片编号不连续: 期望 %d, 实际 %d", i+1, part.PartNumber)), nil
+		}
+		if part.Etag == "" {
+			return s.errorResponse(8101, fmt.Sprintf("分片 %d 的ETag不能为空", part.PartNumber)), nil
+		}
+		parts = append(parts, upload.CompletedPart{PartNumber: int(part.PartNumber), ETag: part.Etag})
+	}
+
+	session, code, message := s.ownMultipartSession(ctx, req.UploadID)
+	if code != 0 {
+		return s.errorResponse(code, message), nil
+	}
+
+	result, err := s.uploadService.CompleteMultipartUpload(ctx, &upload.CompleteMultipartRequest{
+		UploadID:   session.UploadID,
+		ObjectName: session.ObjectName,
+		Parts:      parts,
+		BucketName: session.BucketName,
+		Checksum:   expectedChecksum,
+		TotalSize:  session.TotalSize,
+	})
+	switch {
+	case errors.Is(err, upload.ErrPartMissing), errors.Is(err, upload.ErrPartMismatch), errors.Is(err, upload.ErrSizeMismatch):
+		return s.errorResponse(8103, err.Error()), nil
+	case errors.Is(err, upload.ErrChecksumMismatch):
+		// 校验和不一致时合并后的文件已删除，存储服务端的上传也已结束
+		s.multipartUploads.RemoveSession(session.UploadID)
+		return s.errorResponse(1010, err.Error()), nil
+	case err != nil:
+		return nil, err
+	}
+	s.multipartUploads.RemoveSession(session.UploadID)
+
+	return s.finalizeStoredUpload(ctx, &storedUpload{
+		VideoID:     session.VideoID,
+		BucketName:  session.BucketName,
+		ObjectName:  session.ObjectName,
+		FileName:    session.FileName,
+		ContentType: session.ContentType,
+		Size:        result.Size,
+		Title:       session.Title,
+		Description: session.Description,
+		Visibility:  session.Visibility,
+		CreatedBy:   session.CreatedBy,
+		Checksum:    result.Checksum,
+	}, "", func() {
+		if err := s.storageClient.DeleteFile(ctx, session.BucketName, session.ObjectName); err != nil {
+			fmt.Printf("删除无效的分片上传文件失败(request_id=%s): %v\n", middleware.RequestIDFromContext(ctx), err)
+		}
+	})
+}
+
+// AbortMultipartUpload 中止分片上传并清理存储中已上传的分片
+func (s *VideoService) AbortMultipartUpload(ctx context.Context, req *api.MultipartUploadAbortRequest) (*api.MultipartUploadAbortResponse, error) {
+	session, code, message := s.ownMultipartSession(ctx, req.UploadID)
+	if code != 0 {
+		return &api.MultipartUploadAbortResponse{
+			Base: &api.BaseResponse{
+				Code:    code,
+				Message: message,
+			},
+		}, nil
+	}
+
+	if err := s.uploadService.AbortMultipartUpload(ctx, &upload.AbortMultipartRequest{
+		UploadID:   session.UploadID,
+		ObjectName: session.ObjectName,
+		BucketName: session.BucketName,
+	}); err != nil {
+		return nil, err
+	}
+	s.multipartUploads.RemoveSession(session.UploadID)
+
+	return &api.MultipartUploadAbortResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "中止成功",
+		},
+	}, nil
+}
+
+// CleanupExpiredMultipartUploads 中止超过有效期没有上传分片的分片上传，返回清理的数量
+// 中止失败时记录日志并移除会话，存储中残留的分片由存储桶生命周期规则清理
+func (s *VideoService) CleanupExpiredMultipartUploads(ctx context.Context) int {
+	sessions := s.multipartUploads.ExpiredSessions(time.Now())
+	for _, session := range sessions {
+		s.abortStorageMultipart(ctx, session.UploadID, session.BucketName, session.ObjectName)
+		s.multipartUploads.RemoveSession(session.UploadID)
+	}
+	return len(sessions)
+}
+
+// startMultipartCleanup 在后台定期清理过期的分片上传
+func (s *VideoService) startMultipartCleanup(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if count := s.CleanupExpiredMultipartUploads(context.Background()); count > 0 {
+				fmt.Printf("已清理%d个过期的分片上传\n", count)
+			}
+		}
+	}()
+}
+
+// ownMultipartSession 获取当前用户的分片上传会话，管理员可以操作任意会话
+// 其他用户的会话与不存在的会话返回相同的错误，避免泄露上传ID
+func (s *VideoService) ownMultipartSession(ctx context.Context, uploadID string) (*upload.MultipartSession, int32, string) {
+	if uploadID == "" {
+		return nil, 8101, "上传ID不能为空"
+	}
+	session, err := s.multipartUploads.GetSession(uploadID)
+	if err != nil {
+		return nil, 8102, err.Error()
+	}
+	if session.CreatedBy != currentUserID(ctx) && !isAdmin(ctx) {
+		return nil, 8102, upload.ErrMultipartSessionNotFound.Error()
+	}
+	return session, 0, ""
+}
+
+// abortStorageMultipart 中止存储服务端的分片上传，失败时只记录日志
+func (s *VideoService) abortStorageMultipart(ctx context.Context, uploadID, bucketName, objectName string) {
+	if err := s.uploadService.AbortMultipartUpload(ctx, &upload.AbortMultipartRequest{
+		UploadID:   uploadID,
+		ObjectName: objectName,
+		BucketName: bucketName,
+	}); err != nil {
+		fmt.Printf("中止分片上传失败(%s): %v\n", uploadID, err)
+	}
+}
+
+// convertToAPIMultipartUpload 转换分片上传会话
+func convertToAPIMultipartUpload(session *upload.MultipartSession) *api.MultipartUpload {
+	return &api.MultipartUpload{
+		UploadID:   session.UploadID,
+		VideoID:    session.VideoID,
+		Filename:   session.FileName,
+		Size:       session.TotalSize,
+		ChunkSize:  session.ChunkSize,
+		TotalParts: int32(session.TotalParts()),
+		CreatedAt:  session.CreatedAt.UnixMilli(),
+		UpdatedAt:  session.UpdatedAt.UnixMilli(),
+		ExpiresAt:  session.ExpiresAt.UnixMilli(),
+	}
+}
+
+// multipartInitErrorResponse 创建初始化分片上传错误响应
+func (s *VideoService) multipartInitErrorResponse(code int32, message string) *api.MultipartUploadInitResponse {
+	return &api.MultipartUploadInitResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// multipartPartErrorResponse 创建上传分片错误响应
+func (s *VideoService) multipartPartErrorResponse(code int32, message string) *api.MultipartUploadPartResponse {
+	return &api.MultipartUploadPartResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/upload"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// initTestMultipartUpload 初始化两个分片的测试分片上传，返回会话和完整文件数据
+func initTestMultipartUpload(t *testing.T, service *VideoService, ctx context.Context) (*api.MultipartUpload, []byte) {
+	data := mp4TestData(upload.MinPartSize + 1024)
+	resp, err := service.InitMultipartUpload(ctx, &api.MultipartUploadInitRequest{
+		Filename:  "multipart.mp4",
+		Size:      int64(len(data)),
+		ChunkSize: upload.MinPartSize,
+		Title:     "分片视频",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+	require.Equal(t, int32(2), resp.Upload.TotalParts)
+	return resp.Upload, data
+}
+
+// uploadTestPart 上传测试分片，返回分片信息
+func uploadTestPart(t *testing.T, service *VideoService, ctx context.Context, uploadID string, partNumber int, data []byte) *api.MultipartUploadPart {
+	start := (partNumber - 1) * upload.MinPartSize
+	end := min(start+upload.MinPartSize, len(data))
+	resp, err := service.UploadMultipartPart(ctx, &api.MultipartUploadPartRequest{UploadID: uploadID, PartNumber: int32(partNumber)}, bytes.NewReader(data[start:end]), int64(end-start))
+	require.NoError(t, err)
+	require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+	return resp.Part
+}
+
+func TestVideoService_MultipartUpload(t *testing.T) {
+	uploader := claimsContext("uploader-1", user.RoleUploader)
+	other := claimsContext("uploader-2", user.RoleUploader)
+
+	t.Run("并行上传分片后完成", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		session, data := initTestMultipartUpload(t, service, uploader)
+
+		// 分片可以按任意顺序上传
+		second := uploadTestPart(t, service, uploader, session.UploadID, 2, data)
+		first := uploadTestPart(t, service, uploader, session.UploadID, 1, data)
+		stored, err := service.multipartUploads.GetSession(session.UploadID)
+		require.NoError(t, err)
+
+		list, err := service.ListMultipartUploads(uploader, &api.MultipartUploadListRequest{})
+		require.NoError(t, err)
+		require.Len(t, list.Uploads, 1)
+		assert.Equal(t, session.UploadID, list.Uploads[0].UploadID)
+
+		resp, err := service.CompleteMultipartUpload(uploader, &api.MultipartUploadCompleteRequest{
+			UploadID: session.UploadID,
+			Parts:    []*api.MultipartUploadPart{first, second},
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, session.VideoID, resp.Video.ID)
+		assert.Equal(t, "分片视频", resp.Video.Title)
+		assert.Equal(t, int64(len(data)), resp.Video.Size)
+		assert.NotEmpty(t, resp.Video.Checksum)
+		_, err = store.ListParts(context.Background(), testBucket, stored.ObjectName, session.UploadID)
+		assert.Error(t, err, "存储中的分片上传应该已完成")
+
+		list, err = service.ListMultipartUploads(uploader, &api.MultipartUploadListRequest{})
+		require.NoError(t, err)
+		assert.Empty(t, list.Uploads, "完成后不再列出")
+	})
+
+	t.Run("查询分片状态后只重传缺失的分片", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+		session, data := initTestMultipartUpload(t, service, uploader)
+
+		parts, err := service.ListMultipartUploadParts(uploader, &api.MultipartUploadPartsRequest{UploadID: session.UploadID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), parts.Base.Code, parts.Base.Message)
+		assert.Empty(t, parts.ReceivedParts)
+		assert.Equal(t, []int32{1, 2}, parts.MissingParts)
+
+		second := uploadTestPart(t, service, uploader, session.UploadID, 2, data)
+
+		// 断线后查询分片状态，只重传缺失的分片
+		parts, err = service.ListMultipartUploadParts(uploader, &api.MultipartUploadPartsRequest{UploadID: session.UploadID})
+		require.NoError(t, err)
+		require.Len(t, parts.ReceivedParts, 1)
+		assert.Equal(t, second.Etag, parts.ReceivedParts[0].Etag)
+		assert.Equal(t, []int32{1}, parts.MissingParts)
+		assert.Equal(t, int64(len(data)-upload.MinPartSize), parts.ReceivedSize)
+		assert.Equal(t, int32(2), parts.Upload.TotalParts)
+
+		otherParts, err := service.ListMultipartUploadParts(other, &api.MultipartUploadPartsRequest{UploadID: session.UploadID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(8102), otherParts.Base.Code, "不能查询其他用户的分片上传")
+
+		first := uploadTestPart(t, service, uploader, session.UploadID, 1, data)
+		parts, err = service.ListMultipartUploadParts(uploader, &api.MultipartUploadPartsRequest{UploadID: session.UploadID})
+		require.NoError(t, err)
+		assert.Empty(t, parts.MissingParts)
+
+		resp, err := service.CompleteMultipartUpload(uploader, &api.MultipartUploadCompleteRequest{
+			UploadID: session.UploadID,
+			Parts:    []*api.MultipartUploadPart{first, parts.ReceivedParts[1]},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+	})
+
+	t.Run("分片缺失时保留会话", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+		session, data := initTestMultipartUpload(t, service, uploader)
+		first := uploadTestPart(t, service, uploader, session.UploadID, 1, data)
+
+		resp, err := service.CompleteMultipartUpload(uploader, &api.MultipartUploadCompleteRequest{
+			UploadID: session.UploadID,
+			Parts:    []*api.MultipartUploadPart{first, {PartNumber: 2, Etag: first.Etag}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(8103), resp.Base.Code)
+
+		second := uploadTestPart(t, service, uploader, session.UploadID, 2, data)
+		resp, err = service.CompleteMultipartUpload(uploader, &api.MultipartUploadCompleteRequest{
+			UploadID: session.UploadID,
+			Parts:    []*api.MultipartUploadPart{first, second},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+	})
+
+	t.Run("分片参数错误", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+		session, data := initTestMultipartUpload(t, service, uploader)
+
+		resp, err := service.UploadMultipartPart(uploader, &api.MultipartUploadPartRequest{UploadID: session.UploadID, PartNumber: 3}, bytes.NewReader(data[:1024]), 1024)
+		require.NoError(t, err)
+		assert.Equal(t, int32(8101), resp.Base.Code, "分片号超出范围")
+
+		resp, err = service.UploadMultipartPart(uploader, &api.MultipartUploadPartRequest{UploadID: session.UploadID, PartNumber: 1}, bytes.NewReader(data[:1024]), 1024)
+		require.NoError(t, err)
+		assert.Equal(t, int32(8101), resp.Base.Code, "分片大小与初始化时的分片大小不一致")
+
+		initResp, err := service.InitMultipartUpload(uploader, &api.MultipartUploadInitRequest{Filename: "small.mp4", Size: upload.MinPartSize * 2, ChunkSize: 1024})
+		require.NoError(t, err)
+		assert.Equal(t, int32(8101), initResp.Base.Code, "分片大小不能小于最小分片大小")
+
+		initResp, err = service.InitMultipartUpload(uploader, &api.MultipartUploadInitRequest{Filename: "large.mp4", Size: 1 << 30, ChunkSize: multipartMaxChunkSize + 1})
+		require.NoError(t, err)
+		assert.Equal(t, int32(8101), initResp.Base.Code, "分片大小不能超过最大分片大小")
+
+		initResp, err = service.InitMultipartUpload(uploader, &api.MultipartUploadInitRequest{Filename: "single.mp4", Size: 1024, ChunkSize: multipartMaxChunkSize})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), initResp.Base.Code, initResp.Base.Message)
+		assert.Equal(t, int64(1024), initResp.Upload.ChunkSize, "分片大小不应该超过文件大小")
+		assert.Equal(t, int64(1024), service.MaxMultipartPartSize(uploader, initResp.Upload.UploadID))
+	})
+
+	t.Run("分片请求体不完整", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+		session, data := initTestMultipartUpload(t, service, uploader)
+		request := &api.MultipartUploadPartRequest{UploadID: session.UploadID, PartNumber: 1}
+
+		resp, err := service.UploadMultipartPart(uploader, request, bytes.NewReader(data[:upload.MinPartSize]), -1)
+		require.NoError(t, err)
+		assert.Equal(t, int32(8101), resp.Base.Code, "未声明Content-Length")
+
+		resp, err = service.UploadMultipartPart(uploader, request, bytes.NewReader(data[:1024]), upload.MinPartSize)
+		require.NoError(t, err)
+		assert.Equal(t, int32(8101), resp.Base.Code, "请求体少于声明的大小")
+
+		// 重新上传完整的分片后覆盖不完整的数据
+		uploadTestPart(t, service, uploader, session.UploadID, 1, data)
+	})
+
+	t.Run("只能操作自己的分片上传", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+		session, data := initTestMultipartUpload(t, service, uploader)
+
+		resp, err := service.UploadMultipartPart(other, &api.MultipartUploadPartRequest{UploadID: session.UploadID, PartNumber: 1}, bytes.NewReader(data[:upload.MinPartSize]), upload.MinPartSize)
+		require.NoError(t, err)
+		assert.Equal(t, int32(8102), resp.Base.Code)
+		assert.Equal(t, int64(upload.MinPartSize), service.MaxMultipartPartSize(uploader, session.UploadID))
+		assert.Zero(t, service.MaxMultipartPartSize(other, session.UploadID), "不属于当前用户的会话不限制，由处理函数返回错误")
+
+		abortResp, err := service.AbortMultipartUpload(other, &api.MultipartUploadAbortRequest{UploadID: session.UploadID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(8102), abortResp.Base.Code)
+
+		list, err := service.ListMultipartUploads(other, &api.MultipartUploadListRequest{})
+		require.NoError(t, err)
+		assert.Empty(t, list.Uploads)
+	})
+
+	t.Run("中止分片上传", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		session, data := initTestMultipartUpload(t, service, uploader)
+		uploadTestPart(t, service, uploader, session.UploadID, 1, data)
+		stored, err := service.multipartUploads.GetSession(session.UploadID)
+		require.NoError(t, err)
+
+		resp, err := service.AbortMultipartUpload(uploader, &api.MultipartUploadAbortRequest{UploadID: session.UploadID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		_, err = store.ListParts(context.Background(), testBucket, stored.ObjectName, session.UploadID)
+		assert.Error(t, err, "存储中的分片应该被清理")
+
+		partResp, err := service.UploadMultipartPart(uploader, &api.MultipartUploadPartRequest{UploadID: session.UploadID, PartNumber: 1}, bytes.NewReader(data[:upload.MinPartSize]), upload.MinPartSize)
+		require.NoError(t, err)
+		assert.Equal(t, int32(8102), partResp.Base.Code)
+	})
+
+	t.Run("清理过期的分片上传", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		service.multipartUploads = upload.NewMultipartSessionManager(time.Millisecond)
+		session, _ := initTestMultipartUpload(t, service, uploader)
+		stored, err := service.multipartUploads.GetSession(session.UploadID)
+		require.NoError(t, err)
+		time.Sleep(5 * time.Millisecond)
+
+		assert.Equal(t, 1, service.CleanupExpiredMultipartUploads(context.Background()))
+		_, err = store.ListParts(context.Background(), testBucket, stored.ObjectName, session.UploadID)
+		assert.Error(t, err, "过期的分片上传应该在存储中中止")
+
+		resp, err := service.AbortMultipartUpload(uploader, &api.MultipartUploadAbortRequest{UploadID: session.UploadID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(8102), resp.Base.Code)
+	})
+}
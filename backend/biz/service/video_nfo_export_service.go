@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path"
+	"strings"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/nfo"
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// StartNFOExport 在后台为全部视频生成Kodi/Jellyfin格式的NFO元数据文件和海报
+// 文件按视频的对象名存放（如videos/2025/08/<视频ID>.nfo），导出到视频存储桶或其目录时与视频文件相邻，媒体中心扫描时直接匹配；
+// 图片和音频不导出，重新导出时覆盖已有的文件
+func (s *VideoService) StartNFOExport(ctx context.Context, req *api.NFOExportRequest) (*api.NFOExportResponse, error) {
+	dir := req.GetPath()
+	bucket := req.GetBucket()
+	if (dir == "") == (bucket == "") {
+		return s.nfoExportErrorResponse(9601, "导出目录和存储桶必须指定且只能指定一个"), nil
+	}
+
+	var target nfo.Target
+	if dir != "" {
+		dirTarget, err := nfo.NewDirTarget(dir)
+		if err != nil {
+			return s.nfoExportErrorResponse(9601, err.Error()), nil
+		}
+		target = dirTarget
+	} else {
+		prefix := req.GetPrefix()
+		if strings.Contains("/"+prefix+"/", "/../") {
+			return s.nfoExportErrorResponse(9601, "存储桶路径前缀无效"), nil
+		}
+		if err := s.ensureBucket(ctx, bucket); err != nil {
+			return nil, err
+		}
+		target = &nfo.BucketTarget{Storage: s.storageClient, Bucket: bucket, Prefix: prefix}
+	}
+
+	exporter, err := nfo.NewExporter(target)
+	if err != nil {
+		return s.nfoExportErrorResponse(9601, err.Error()), nil
+	}
+	list, err := s.metadataService.ListMetadata(ctx, &metadata.ListMetadataRequest{Limit: math.MaxInt})
+	if err != nil {
+		return nil, fmt.Errorf("获取视频列表失败: %w", err)
+	}
+	videoIDs := make([]string, 0, len(list.Items))
+	for _, meta := range list.Items {
+		videoIDs = append(videoIDs, meta.FileID)
+	}
+
+	s.nfoExportMutex.Lock()
+	defer s.nfoExportMutex.Unlock()
+
+	if s.nfoExport != nil && s.nfoExport.Running() {
+		return s.nfoExportErrorResponse(9603, "已有NFO导出正在运行"), nil
+	}
+	s.nfoExport = exporter
+
+	go s.runNFOExport(exporter, videoIDs)
+
+	return &api.NFOExportResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "NFO导出已开始",
+		},
+		ExportJob: convertNFOExport(exporter),
+	}, nil
+}
+
+// GetNFOExport 获取正在运行或最近一次的NFO导出进度
+func (s *VideoService) GetNFOExport(ctx context.Context) (*api.NFOExportResponse, error) {
+	s.nfoExportMutex.Lock()
+	exporter := s.nfoExport
+	s.nfoExportMutex.Unlock()
+
+	if exporter == nil {
+		return s.nfoExportErrorResponse(9602, "没有NFO导出记录"), nil
+	}
+
+	return &api.NFOExportResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		ExportJob: convertNFOExport(exporter),
+	}, nil
+}
+
+// runNFOExport 在后台执行NFO导出
+func (s *VideoService) runNFOExport(exporter *nfo.Exporter, videoIDs []string) {
+	failed, err := exporter.Run(context.Background(), videoIDs, s.nfoEntry)
+	if err != nil {
+		fmt.Printf("NFO导出失败(%s): %v\n", exporter.Target(), err)
+		return
+	}
+	progress := exporter.Progress()
+	fmt.Printf("NFO导出完成(%s): 导出%d个，跳过%d个，失败%d个\n", exporter.Target(), progress.Exported, progress.Skipped, failed)
+}
+
+// nfoEntry 获取视频的NFO内容和海报，视频已删除或不是视频时跳过
+func (s *VideoService) nfoEntry(ctx context.Context, videoID string) (*nfo.Entry, error) {
+	meta, err := s.metadataService.GetMetadata(ctx, videoID)
+	if err != nil || !meta.IsVideo() {
+		return nil, nil
+	}
+
+	entry := &nfo.Entry{
+		BaseName: strings.TrimSuffix(meta.ObjectName, path.Ext(meta.ObjectName)),
+		Movie: &nfo.Movie{
+			ID:         meta.FileID,
+			Title:      meta.Title,
+			Plot:       meta.Description,
+			Tags:       meta.Tags,
+			Duration:   meta.Duration,
+			Width:      meta.Width,
+			Height:     meta.Height,
+			VideoCodec: meta.VideoCodec,
+			AudioCodec: meta.AudioCodec,
+			CreatedBy:  meta.CreatedBy,
+			CreatedAt:  meta.CreatedAt,
+		},
+	}
+	if meta.Thumbnail != "" {
+		entry.Poster, err = s.readStorageObject(ctx, s.buckets.Bucket(storage.ContentThumbnails), meta.Thumbnail)
+		if err != nil {
+			return nil, fmt.Errorf("读取缩略图失败: %w", err)
+		}
+	}
+	return entry, nil
+}
+
+// ensureBucket 存储桶不存在时创建
+func (s *VideoService) ensureBucket(ctx context.Context, bucketName string) error {
+	exists, err := s.storageClient.BucketExists(ctx, bucketName)
+	if err != nil {
+		return fmt.Errorf("检查存储桶失败: %w", err)
+	}
+	if exists {
+		return nil
+	}
+	if err := s.storageClient.CreateBucket(ctx, bucketName); err != nil {
+		return fmt.Errorf("创建存储桶失败: %w", err)
+	}
+	return nil
+}
+
+// convertNFOExport 转换NFO导出进度
+func convertNFOExport(exporter *nfo.Exporter) *api.NFOExport {
+	progress := exporter.Progress()
+	result := &api.NFOExport{
+		Target:    exporter.Target().String(),
+		Status:    progress.Status,
+		Total:     int32(progress.Total),
+		Exported:  int32(progress.Exported),
+		Skipped:   int32(progress.Skipped),
+		Failed:    int32(progress.Failed),
+		StartedAt: progress.StartedAt.UnixMilli(),
+	}
+	if progress.Error != "" {
+		result.Error = &progress.Error
+	}
+	if !progress.FinishedAt.IsZero() {
+		finishedAt := progress.FinishedAt.UnixMilli()
+		result.FinishedAt = &finishedAt
+	}
+	return result
+}
+
+// nfoExportErrorResponse 创建NFO导出错误响应
+func (s *VideoService) nfoExportErrorResponse(code int32, message string) *api.NFOExportResponse {
+	return &api.NFOExportResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/storage"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/nfo"
+)
+
+// startTestNFOExport 发起NFO导出并等待结束
+func startTestNFOExport(t *testing.T, service *VideoService, req *api.NFOExportRequest) *api.NFOExport {
+	resp, err := service.StartNFOExport(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+	require.Eventually(t, func() bool {
+		resp, err = service.GetNFOExport(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		return resp.ExportJob.FinishedAt != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	return resp.ExportJob
+}
+
+func TestVideoService_NFOExport(t *testing.T) {
+	ctx := context.Background()
+
+	// createNFOTestService 创建包含一个带缩略图的视频和一张图片的测试服务
+	createNFOTestService := func(t *testing.T) (*VideoService, *storage.MemoryStorage) {
+		service := createStreamTestService(t)
+		store := service.storageClient.(*storage.MemoryStorage)
+		thumbnail := "thumbnails/2025/08/video1.jpg"
+		duration := int64(90)
+		require.NoError(t, service.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{
+			FileID:    "video1",
+			Thumbnail: &thumbnail,
+			Duration:  &duration,
+		}))
+		putTestObject(t, store, thumbnail, []byte("jpg"))
+		require.NoError(t, service.metadataService.SaveMetadata(ctx, &metadata.FileMetadata{
+			FileID:     "image1",
+			BucketName: "zhulong-videos",
+			ObjectName: "videos/2025/08/image1.png",
+			FileName:   "photo.png",
+			MediaType:  metadata.MediaTypeImage,
+			Title:      "照片",
+			CreatedBy:  "system",
+		}))
+		return service, store
+	}
+
+	t.Run("导出到目录", func(t *testing.T) {
+		service, _ := createNFOTestService(t)
+		root := filepath.Join(t.TempDir(), "library")
+
+		job := startTestNFOExport(t, service, &api.NFOExportRequest{Path: &root})
+		assert.Equal(t, nfo.StatusCompleted, job.Status)
+		assert.Equal(t, root, job.Target)
+		assert.Equal(t, int32(2), job.Total)
+		assert.Equal(t, int32(1), job.Exported)
+		assert.Equal(t, int32(1), job.Skipped, "图片不导出")
+		assert.Equal(t, int32(0), job.Failed)
+
+		data, err := os.ReadFile(filepath.Join(root, "videos", "2025", "08", "video1.nfo"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "<title>测试视频</title>")
+		assert.Contains(t, string(data), "<runtime>2</runtime>")
+		assert.Contains(t, string(data), `<uniqueid type="zhulong" default="true">video1</uniqueid>`)
+		assert.Contains(t, string(data), `<thumb aspect="poster">video1-poster.jpg</thumb>`)
+
+		poster, err := os.ReadFile(filepath.Join(root, "videos", "2025", "08", "video1-poster.jpg"))
+		require.NoError(t, err)
+		assert.Equal(t, "jpg", string(poster))
+		_, err = os.Stat(filepath.Join(root, "videos", "2025", "08", "image1.nfo"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("导出到存储桶前缀", func(t *testing.T) {
+		service, store := createNFOTestService(t)
+		bucket, prefix := "zhulong-videos", "kodi"
+
+		job := startTestNFOExport(t, service, &api.NFOExportRequest{Bucket: &bucket, Prefix: &prefix})
+		assert.Equal(t, "zhulong-videos/kodi", job.Target)
+		assert.Equal(t, int32(1), job.Exported)
+		assert.Contains(t, string(testObjectData(t, store, "kodi/videos/2025/08/video1.nfo")), "<title>测试视频</title>")
+		assert.Equal(t, "jpg", string(testObjectData(t, store, "kodi/videos/2025/08/video1-poster.jpg")))
+	})
+
+	t.Run("缩略图读取失败时记录错误", func(t *testing.T) {
+		service, store := createNFOTestService(t)
+		require.NoError(t, store.DeleteFile(context.Background(), testBucket, "thumbnails/2025/08/video1.jpg"))
+		root := t.TempDir()
+
+		job := startTestNFOExport(t, service, &api.NFOExportRequest{Path: &root})
+		assert.Equal(t, nfo.StatusCompleted, job.Status)
+		assert.Equal(t, int32(1), job.Failed)
+		require.NotNil(t, job.Error)
+		assert.Contains(t, *job.Error, "video1")
+	})
+
+	t.Run("参数错误", func(t *testing.T) {
+		service, _ := createNFOTestService(t)
+		root, bucket, relative, prefix := t.TempDir(), "zhulong-videos", "library", "../kodi"
+
+		for _, req := range []*api.NFOExportRequest{
+			{},
+			{Path: &root, Bucket: &bucket},
+			{Path: &relative},
+			{Bucket: &bucket, Prefix: &prefix},
+		} {
+			resp, err := service.StartNFOExport(ctx, req)
+			require.NoError(t, err)
+			assert.Equal(t, int32(9601), resp.Base.Code)
+		}
+
+		resp, err := service.GetNFOExport(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int32(9602), resp.Base.Code)
+	})
+}
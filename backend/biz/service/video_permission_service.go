@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// canUploadVideo 当前用户是否可以上传视频
+// 上下文中没有登录用户时为命令行导入等内部调用，不做限制；HTTP接口由路由中间件要求登录
+func canUploadVideo(ctx context.Context) bool {
+	claims, ok := user.ClaimsFromContext(ctx)
+	return !ok || user.HasPermission(claims.Role, user.PermissionVideoUpload)
+}
+
+// canModifyVideo 当前用户是否可以修改或删除视频：管理员可以修改任意视频，上传者只能修改自己上传的视频
+// 上下文中没有登录用户时为内部调用，不做限制
+func canModifyVideo(ctx context.Context, meta *metadata.FileMetadata) bool {
+	claims, ok := user.ClaimsFromContext(ctx)
+	return !ok || user.CanModifyVideo(claims, meta.CreatedBy)
+}
+
+// canCreateCollection 当前用户是否可以创建合集：需要登录且拥有video:edit权限
+func canCreateCollection(ctx context.Context) bool {
+	claims, ok := user.ClaimsFromContext(ctx)
+	return ok && user.HasPermission(claims.Role, user.PermissionVideoEdit)
+}
+
+// canModifyCollection 当前用户是否可以修改或删除合集：管理员可以修改任意合集，上传者只能修改自己创建的合集
+// 合集没有内部调用，未登录时不允许修改
+func canModifyCollection(ctx context.Context, ownerID string) bool {
+	claims, ok := user.ClaimsFromContext(ctx)
+	return ok && user.CanModifyVideo(claims, ownerID)
+}
+
+// canModifyPlaylist 当前用户是否可以修改或删除播放列表：管理员可以修改任意播放列表，其他用户只能修改自己创建的播放列表
+// 播放列表没有内部调用，未登录时不允许修改
+func canModifyPlaylist(ctx context.Context, ownerID string) bool {
+	claims, ok := user.ClaimsFromContext(ctx)
+	if !ok {
+		return false
+	}
+	return claims.Role == user.RoleAdmin || (ownerID != "" && ownerID == claims.UserID)
+}
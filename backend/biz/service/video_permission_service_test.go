@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// claimsContext 创建带登录用户的上下文
+func claimsContext(userID, role string) context.Context {
+	return user.ContextWithClaims(context.Background(), &user.Claims{UserID: userID, Role: role})
+}
+
+// TestVideoService_UploadPermission 测试上传权限
+func TestVideoService_UploadPermission(t *testing.T) {
+	service := createTestVideoService(t)
+	viewer := claimsContext("viewer-1", user.RoleViewer)
+
+	resp, err := service.UploadVideo(viewer, &api.VideoUploadRequest{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1012), resp.Base.Code, "观看者不能上传视频")
+
+	urlResp, err := service.CreateUploadURL(viewer, &api.VideoUploadURLRequest{Filename: "a.mp4", Size: 1024})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1012), urlResp.Base.Code, "观看者不能获取直传地址")
+
+	confirmResp, err := service.ConfirmUpload(viewer, &api.VideoUploadConfirmRequest{UploadToken: "token"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1012), confirmResp.Base.Code, "观看者不能确认上传")
+
+	assert.True(t, canUploadVideo(claimsContext("uploader-1", user.RoleUploader)))
+	assert.True(t, canUploadVideo(context.Background()), "没有登录用户的内部调用不做限制")
+}
+
+// TestVideoService_ModifyPermission 测试修改和删除视频的权限
+func TestVideoService_ModifyPermission(t *testing.T) {
+	service := createUpdateTestService(t)
+	require.NoError(t, service.metadataService.SaveMetadata(context.Background(), &metadata.FileMetadata{
+		FileID:     "video2",
+		BucketName: "zhulong-videos",
+		ObjectName: "videos/2025/08/video2.mp4",
+		FileName:   "video2.mp4",
+		Title:      "上传者的视频",
+		CreatedBy:  "uploader-1",
+	}))
+
+	owner := claimsContext("uploader-1", user.RoleUploader)
+	other := claimsContext("uploader-2", user.RoleUploader)
+	viewer := claimsContext("uploader-1", user.RoleViewer)
+	admin := claimsContext("admin-1", user.RoleAdmin)
+	title := "新标题"
+
+	t.Run("只能修改自己的视频", func(t *testing.T) {
+		resp, err := service.UpdateVideo(other, &api.VideoUpdateRequest{VideoID: "video2", Title: &title})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4004), resp.Base.Code)
+
+		resp, err = service.UpdateVideo(viewer, &api.VideoUpdateRequest{VideoID: "video2", Title: &title})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4004), resp.Base.Code, "降级为观看者后不能再修改自己上传的视频")
+
+		resp, err = service.UpdateVideo(owner, &api.VideoUpdateRequest{VideoID: "video2", Title: &title})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+		resp, err = service.UpdateVideo(owner, &api.VideoUpdateRequest{VideoID: "video1", Title: &title})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4004), resp.Base.Code, "上传者不能修改导入的视频")
+
+		resp, err = service.UpdateVideo(admin, &api.VideoUpdateRequest{VideoID: "video1", Title: &title})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code, "管理员可以修改任意视频")
+	})
+
+	t.Run("标签和章节", func(t *testing.T) {
+		tagsResp, err := service.AddVideoTags(other, &api.VideoTagsRequest{VideoID: "video2", Tags: []string{"旅行"}})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4103), tagsResp.Base.Code)
+
+		tagsResp, err = service.AddVideoTags(owner, &api.VideoTagsRequest{VideoID: "video2", Tags: []string{"旅行"}})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), tagsResp.Base.Code, tagsResp.Base.Message)
+
+		chaptersResp, err := service.UpdateVideoChapters(other, &api.VideoChaptersRequest{VideoID: "video2"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4403), chaptersResp.Base.Code)
+	})
+
+	t.Run("只有管理员可以删除其他用户的视频", func(t *testing.T) {
+		resp, err := service.DeleteVideo(other, &api.VideoDeleteRequest{VideoID: "video2"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(3005), resp.Base.Code)
+
+		_, err = service.metadataService.GetMetadata(context.Background(), "video2")
+		assert.NoError(t, err, "被拒绝的删除不应该删除视频")
+	})
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/playlist"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// maxPlaylistVideosPerRequest 单次添加或移除的最大视频数量
+const maxPlaylistVideosPerRequest = 100
+
+// CreatePlaylist 创建播放列表
+func (s *VideoService) CreatePlaylist(ctx context.Context, req *api.PlaylistCreateRequest) (*api.PlaylistResponse, error) {
+	if _, ok := user.ClaimsFromContext(ctx); !ok {
+		return s.playlistCodeResponse(4305, "登录后才能创建播放列表"), nil
+	}
+
+	created, err := s.playlists.Create(ctx, &playlist.CreateRequest{
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedBy:   currentUserID(ctx),
+	})
+	if err != nil {
+		return s.playlistErrorResponse(err), nil
+	}
+
+	return s.playlistResponse(ctx, "创建成功", created), nil
+}
+
+// ListPlaylists 列出所有播放列表，不包含视频详情
+func (s *VideoService) ListPlaylists(ctx context.Context) (*api.PlaylistListResponse, error) {
+	playlists := s.playlists.List(ctx)
+
+	apiPlaylists := make([]*api.Playlist, 0, len(playlists))
+	for _, item := range playlists {
+		apiPlaylists = append(apiPlaylists, convertToAPIPlaylist(item))
+	}
+
+	return &api.PlaylistListResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Playlists: apiPlaylists,
+	}, nil
+}
+
+// GetPlaylist 获取播放列表详情，视频按播放顺序排列
+func (s *VideoService) GetPlaylist(ctx context.Context, req *api.PlaylistRequest) (*api.PlaylistResponse, error) {
+	if req.PlaylistID == "" {
+		return s.playlistCodeResponse(4301, "播放列表ID不能为空"), nil
+	}
+
+	found, err := s.playlists.Get(ctx, req.PlaylistID)
+	if err != nil {
+		return s.playlistErrorResponse(err), nil
+	}
+
+	return s.playlistResponse(ctx, "获取成功", found), nil
+}
+
+// UpdatePlaylist 修改播放列表名称或描述
+func (s *VideoService) UpdatePlaylist(ctx context.Context, req *api.PlaylistUpdateRequest) (*api.PlaylistResponse, error) {
+	if req.PlaylistID == "" {
+		return s.playlistCodeResponse(4301, "播放列表ID不能为空"), nil
+	}
+	if req.Name == nil && req.Description == nil {
+		return s.playlistCodeResponse(4301, "至少需要更新一个字段"), nil
+	}
+	if resp := s.checkPlaylistOwner(ctx, req.PlaylistID); resp != nil {
+		return resp, nil
+	}
+
+	updated, err := s.playlists.Update(ctx, req.PlaylistID, &playlist.UpdateRequest{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		return s.playlistErrorResponse(err), nil
+	}
+
+	return s.playlistResponse(ctx, "更新成功", updated), nil
+}
+
+// DeletePlaylist 删除播放列表，其中的视频不会被删除
+func (s *VideoService) DeletePlaylist(ctx context.Context, req *api.PlaylistRequest) (*api.PlaylistResponse, error) {
+	if req.PlaylistID == "" {
+		return s.playlistCodeResponse(4301, "播放列表ID不能为空"), nil
+	}
+	if resp := s.checkPlaylistOwner(ctx, req.PlaylistID); resp != nil {
+		return resp, nil
+	}
+
+	if err := s.playlists.Delete(ctx, req.PlaylistID); err != nil {
+		return s.playlistErrorResponse(err), nil
+	}
+
+	return s.playlistCodeResponse(0, "删除成功"), nil
+}
+
+// AddPlaylistVideos 向播放列表添加视频，所有视频都必须存在且当前用户可以查看
+func (s *VideoService) AddPlaylistVideos(ctx context.Context, req *api.PlaylistVideosRequest) (*api.PlaylistResponse, error) {
+	if resp := s.validatePlaylistVideosRequest(req); resp != nil {
+		return resp, nil
+	}
+	if resp := s.checkPlaylistOwner(ctx, req.PlaylistID); resp != nil {
+		return resp, nil
+	}
+
+	for _, videoID := range req.VideoIds {
+		if _, err := s.getVisibleMetadata(ctx, videoID); err != nil {
+			return s.playlistCodeResponse(4303, fmt.Sprintf("视频不存在: %s", videoID)), nil
+		}
+	}
+
+	position := -1
+	if req.Position != nil {
+		position = int(*req.Position)
+	}
+
+	updated, err := s.playlists.AddVideos(ctx, req.PlaylistID, req.VideoIds, position)
+	if err != nil {
+		return s.playlistErrorResponse(err), nil
+	}
+
+	return s.playlistResponse(ctx, "添加成功", updated), nil
+}
+
+// RemovePlaylistVideos 从播放列表移除视频，不在列表中的视频会被忽略
+func (s *VideoService) RemovePlaylistVideos(ctx context.Context, req *api.PlaylistVideosRequest) (*api.PlaylistResponse, error) {
+	if resp := s.validatePlaylistVideosRequest(req); resp != nil {
+		return resp, nil
+	}
+	if resp := s.checkPlaylistOwner(ctx, req.PlaylistID); resp != nil {
+		return resp, nil
+	}
+
+	updated, err := s.playlists.RemoveVideos(ctx, req.PlaylistID, req.VideoIds)
+	if err != nil {
+		return s.playlistErrorResponse(err), nil
+	}
+
+	return s.playlistResponse(ctx, "移除成功", updated), nil
+}
+
+// ReorderPlaylist 调整播放顺序，video_ids需包含播放列表中的全部视频
+func (s *VideoService) ReorderPlaylist(ctx context.Context, req *api.PlaylistVideosRequest) (*api.PlaylistResponse, error) {
+	if req.PlaylistID == "" {
+		return s.playlistCodeResponse(4301, "播放列表ID不能为空"), nil
+	}
+	if resp := s.checkPlaylistOwner(ctx, req.PlaylistID); resp != nil {
+		return resp, nil
+	}
+
+	updated, err := s.playlists.Reorder(ctx, req.PlaylistID, req.VideoIds)
+	if err != nil {
+		if errors.Is(err, playlist.ErrVideoNotInPlaylist) {
+			return s.playlistCodeResponse(4301, err.Error()), nil
+		}
+		return s.playlistErrorResponse(err), nil
+	}
+
+	return s.playlistResponse(ctx, "排序成功", updated), nil
+}
+
+// NavigatePlaylist 获取当前视频在播放列表中的位置及上一个、下一个视频，跳过当前用户无权查看的视频
+func (s *VideoService) NavigatePlaylist(ctx context.Context, req *api.PlaylistNavigationRequest) (*api.PlaylistNavigationResponse, error) {
+	if req.PlaylistID == "" || req.VideoID == "" {
+		return s.playlistNavigationErrorResponse(4301, "播放列表ID和视频ID不能为空"), nil
+	}
+
+	navigation, err := s.playlists.Navigate(ctx, req.PlaylistID, req.VideoID, req.Loop)
+	if err != nil {
+		resp := s.playlistErrorResponse(err)
+		return s.playlistNavigationErrorResponse(resp.Base.Code, resp.Base.Message), nil
+	}
+
+	resp := &api.PlaylistNavigationResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Position: int32(navigation.Position),
+		Total:    int32(navigation.Total),
+	}
+	resp.Previous = s.visiblePlaylistNeighbor(ctx, req, navigation.Previous, navigation.Total, false)
+	resp.Next = s.visiblePlaylistNeighbor(ctx, req, navigation.Next, navigation.Total, true)
+
+	return resp, nil
+}
+
+// visiblePlaylistNeighbor 从相邻的视频开始沿播放方向查找当前用户可以查看的视频，回到当前视频或没有更多视频时返回nil
+func (s *VideoService) visiblePlaylistNeighbor(ctx context.Context, req *api.PlaylistNavigationRequest, videoID string, total int, forward bool) *api.Video {
+	for i := 0; i < total && videoID != "" && videoID != req.VideoID; i++ {
+		if meta, err := s.getVisibleMetadata(ctx, videoID); err == nil {
+			return convertToAPIVideo(meta)
+		}
+		navigation, err := s.playlists.Navigate(ctx, req.PlaylistID, videoID, req.Loop)
+		if err != nil {
+			return nil
+		}
+		if forward {
+			videoID = navigation.Next
+		} else {
+			videoID = navigation.Previous
+		}
+	}
+	return nil
+}
+
+// validatePlaylistVideosRequest 验证播放列表视频修改请求，验证失败时返回错误响应
+func (s *VideoService) validatePlaylistVideosRequest(req *api.PlaylistVideosRequest) *api.PlaylistResponse {
+	if req.PlaylistID == "" {
+		return s.playlistCodeResponse(4301, "播放列表ID不能为空")
+	}
+	if len(req.VideoIds) == 0 {
+		return s.playlistCodeResponse(4301, "视频ID列表不能为空")
+	}
+	if len(req.VideoIds) > maxPlaylistVideosPerRequest {
+		return s.playlistCodeResponse(4301, fmt.Sprintf("单次最多修改%d个视频", maxPlaylistVideosPerRequest))
+	}
+	return nil
+}
+
+// checkPlaylistOwner 检查当前用户是否可以修改播放列表，播放列表不存在或无权修改时返回错误响应
+func (s *VideoService) checkPlaylistOwner(ctx context.Context, playlistID string) *api.PlaylistResponse {
+	found, err := s.playlists.Get(ctx, playlistID)
+	if err != nil {
+		return s.playlistErrorResponse(err)
+	}
+	if !canModifyPlaylist(ctx, found.CreatedBy) {
+		return s.playlistCodeResponse(4305, "无权修改其他用户的播放列表")
+	}
+	return nil
+}
+
+// convertToAPIPlaylist 转换为API播放列表格式，不包含视频详情
+func convertToAPIPlaylist(item *playlist.Playlist) *api.Playlist {
+	return &api.Playlist{
+		ID:          item.ID,
+		Name:        item.Name,
+		Description: item.Description,
+		VideoCount:  int32(len(item.VideoIDs)),
+		Videos:      []*api.Video{},
+		CreatedBy:   item.CreatedBy,
+		CreatedAt:   item.CreatedAt.UnixMilli(),
+		UpdatedAt:   item.UpdatedAt.UnixMilli(),
+	}
+}
+
+// playlistResponse 创建包含播放列表及其视频的成功响应，不包含当前用户无权查看的视频
+func (s *VideoService) playlistResponse(ctx context.Context, message string, item *playlist.Playlist) *api.PlaylistResponse {
+	apiPlaylist := convertToAPIPlaylist(item)
+	for _, videoID := range item.VideoIDs {
+		// 视频删除时会同步移出播放列表，这里跳过仍可能存在的并发删除和之后改为私有的视频
+		if meta, err := s.getVisibleMetadata(ctx, videoID); err == nil {
+			apiPlaylist.Videos = append(apiPlaylist.Videos, convertToAPIVideo(meta))
+		}
+	}
+
+	resp := s.playlistCodeResponse(0, message)
+	resp.Playlist = apiPlaylist
+	return resp
+}
+
+// playlistErrorResponse 根据播放列表服务返回的错误创建错误响应
+func (s *VideoService) playlistErrorResponse(err error) *api.PlaylistResponse {
+	switch {
+	case errors.Is(err, playlist.ErrPlaylistNotFound):
+		return s.playlistCodeResponse(4302, err.Error())
+	case errors.Is(err, playlist.ErrVideoNotInPlaylist):
+		return s.playlistCodeResponse(4304, err.Error())
+	default:
+		return s.playlistCodeResponse(4301, err.Error())
+	}
+}
+
+// playlistCodeResponse 创建播放列表响应
+func (s *VideoService) playlistCodeResponse(code int32, message string) *api.PlaylistResponse {
+	return &api.PlaylistResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// playlistNavigationErrorResponse 创建播放列表导航错误响应
+func (s *VideoService) playlistNavigationErrorResponse(code int32, message string) *api.PlaylistNavigationResponse {
+	return &api.PlaylistNavigationResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
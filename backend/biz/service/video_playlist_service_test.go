@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/delete"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// playlistOwnerContext 创建播放列表的用户的上下文
+func playlistOwnerContext() context.Context {
+	return user.ContextWithClaims(context.Background(), &user.Claims{UserID: "viewer-1", Role: user.RoleViewer})
+}
+
+// createPlaylistTestService 创建带测试视频和播放列表的视频服务
+func createPlaylistTestService(t *testing.T) (*VideoService, string) {
+	service := createTestVideoService(t)
+	for _, id := range []string{"video1", "video2", "video3"} {
+		require.NoError(t, service.metadataService.SaveMetadata(context.Background(), &metadata.FileMetadata{
+			FileID:     id,
+			Title:      id,
+			Visibility: metadata.VisibilityPublic,
+			CreatedBy:  "system",
+		}))
+	}
+
+	resp, err := service.CreatePlaylist(playlistOwnerContext(), &api.PlaylistCreateRequest{Name: "连播"})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+	return service, resp.Playlist.ID
+}
+
+// playlistVideoIDs 返回播放列表响应中按顺序排列的视频ID
+func playlistVideoIDs(resp *api.PlaylistResponse) []string {
+	ids := []string{}
+	for _, video := range resp.Playlist.Videos {
+		ids = append(ids, video.ID)
+	}
+	return ids
+}
+
+func TestVideoService_Playlists(t *testing.T) {
+	ctx := playlistOwnerContext()
+
+	t.Run("添加视频和调整顺序", func(t *testing.T) {
+		service, playlistID := createPlaylistTestService(t)
+
+		resp, err := service.AddPlaylistVideos(ctx, &api.PlaylistVideosRequest{PlaylistID: playlistID, VideoIds: []string{"video1", "video3"}})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+		position := int32(1)
+		resp, err = service.AddPlaylistVideos(ctx, &api.PlaylistVideosRequest{PlaylistID: playlistID, VideoIds: []string{"video2"}, Position: &position})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, []string{"video1", "video2", "video3"}, playlistVideoIDs(resp), "应该插入到指定位置")
+
+		resp, err = service.ReorderPlaylist(ctx, &api.PlaylistVideosRequest{PlaylistID: playlistID, VideoIds: []string{"video3", "video2", "video1"}})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+		resp, err = service.GetPlaylist(ctx, &api.PlaylistRequest{PlaylistID: playlistID})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"video3", "video2", "video1"}, playlistVideoIDs(resp))
+		assert.Equal(t, int32(3), resp.Playlist.VideoCount)
+
+		resp, err = service.ReorderPlaylist(ctx, &api.PlaylistVideosRequest{PlaylistID: playlistID, VideoIds: []string{"video3"}})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4301), resp.Base.Code, "排序缺少视频时应该返回参数错误")
+
+		resp, err = service.RemovePlaylistVideos(ctx, &api.PlaylistVideosRequest{PlaylistID: playlistID, VideoIds: []string{"video2"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"video3", "video1"}, playlistVideoIDs(resp))
+
+		listResp, err := service.ListPlaylists(ctx)
+		require.NoError(t, err)
+		require.Len(t, listResp.Playlists, 1)
+		assert.Equal(t, int32(2), listResp.Playlists[0].VideoCount)
+		assert.Empty(t, listResp.Playlists[0].Videos, "列表接口不应该返回视频详情")
+	})
+
+	t.Run("添加视频_视频不存在", func(t *testing.T) {
+		service, playlistID := createPlaylistTestService(t)
+
+		resp, err := service.AddPlaylistVideos(ctx, &api.PlaylistVideosRequest{PlaylistID: playlistID, VideoIds: []string{"video1", "missing"}})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4303), resp.Base.Code)
+
+		resp, err = service.AddPlaylistVideos(ctx, &api.PlaylistVideosRequest{PlaylistID: "missing", VideoIds: []string{"video1"}})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4302), resp.Base.Code)
+	})
+
+	t.Run("连续播放导航", func(t *testing.T) {
+		service, playlistID := createPlaylistTestService(t)
+		_, err := service.AddPlaylistVideos(ctx, &api.PlaylistVideosRequest{PlaylistID: playlistID, VideoIds: []string{"video1", "video2", "video3"}})
+		require.NoError(t, err)
+
+		resp, err := service.NavigatePlaylist(ctx, &api.PlaylistNavigationRequest{PlaylistID: playlistID, VideoID: "video2"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, int32(1), resp.Position)
+		assert.Equal(t, int32(3), resp.Total)
+		assert.Equal(t, "video1", resp.Previous.ID)
+		assert.Equal(t, "video3", resp.Next.ID)
+
+		resp, err = service.NavigatePlaylist(ctx, &api.PlaylistNavigationRequest{PlaylistID: playlistID, VideoID: "video3"})
+		require.NoError(t, err)
+		assert.Nil(t, resp.Next, "最后一个视频没有下一个")
+
+		resp, err = service.NavigatePlaylist(ctx, &api.PlaylistNavigationRequest{PlaylistID: playlistID, VideoID: "video3", Loop: true})
+		require.NoError(t, err)
+		require.NotNil(t, resp.Next)
+		assert.Equal(t, "video1", resp.Next.ID, "循环播放时最后一个视频的下一个应该是第一个")
+
+		resp, err = service.NavigatePlaylist(ctx, &api.PlaylistNavigationRequest{PlaylistID: playlistID, VideoID: "missing"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4304), resp.Base.Code)
+	})
+
+	t.Run("无权查看的视频", func(t *testing.T) {
+		service, playlistID := createPlaylistTestService(t)
+		require.NoError(t, service.metadataService.SaveMetadata(ctx, &metadata.FileMetadata{
+			FileID:     "private1",
+			Title:      "私有视频",
+			Visibility: metadata.VisibilityPrivate,
+			CreatedBy:  "uploader-2",
+		}))
+
+		resp, err := service.AddPlaylistVideos(ctx, &api.PlaylistVideosRequest{PlaylistID: playlistID, VideoIds: []string{"private1"}})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4303), resp.Base.Code, "其他用户的私有视频应该按不存在处理")
+
+		// 加入后改为私有的视频不在详情中返回，导航时跳过
+		_, err = service.AddPlaylistVideos(ctx, &api.PlaylistVideosRequest{PlaylistID: playlistID, VideoIds: []string{"video1", "video2", "video3"}})
+		require.NoError(t, err)
+		private := metadata.VisibilityPrivate
+		require.NoError(t, service.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{FileID: "video2", Visibility: &private}))
+
+		resp, err = service.GetPlaylist(ctx, &api.PlaylistRequest{PlaylistID: playlistID})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"video1", "video3"}, playlistVideoIDs(resp))
+
+		navigation, err := service.NavigatePlaylist(ctx, &api.PlaylistNavigationRequest{PlaylistID: playlistID, VideoID: "video1"})
+		require.NoError(t, err)
+		require.NotNil(t, navigation.Next)
+		assert.Equal(t, "video3", navigation.Next.ID, "应该跳过无权查看的视频")
+
+		navigation, err = service.NavigatePlaylist(ctx, &api.PlaylistNavigationRequest{PlaylistID: playlistID, VideoID: "video3"})
+		require.NoError(t, err)
+		require.NotNil(t, navigation.Previous)
+		assert.Equal(t, "video1", navigation.Previous.ID)
+
+		navigation, err = service.NavigatePlaylist(ctx, &api.PlaylistNavigationRequest{PlaylistID: playlistID, VideoID: "video1", Loop: true})
+		require.NoError(t, err)
+		require.NotNil(t, navigation.Previous)
+		assert.Equal(t, "video3", navigation.Previous.ID, "循环播放时从末尾继续查找")
+	})
+
+	t.Run("删除视频时移出播放列表", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		service.deleteService = delete.NewDeleteService(store)
+
+		uploaded := uploadTestVideo(t, service, "first.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+
+		created, err := service.CreatePlaylist(ctx, &api.PlaylistCreateRequest{Name: "连播"})
+		require.NoError(t, err)
+		_, err = service.AddPlaylistVideos(ctx, &api.PlaylistVideosRequest{PlaylistID: created.Playlist.ID, VideoIds: []string{uploaded.Video.ID}})
+		require.NoError(t, err)
+
+		deleteResp, err := service.DeleteVideo(context.Background(), &api.VideoDeleteRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), deleteResp.Base.Code, deleteResp.Base.Message)
+
+		resp, err := service.GetPlaylist(ctx, &api.PlaylistRequest{PlaylistID: created.Playlist.ID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Playlist.VideoCount, "删除的视频应该移出播放列表")
+	})
+}
+
+func TestVideoService_PlaylistPermissions(t *testing.T) {
+	anonymous := context.Background()
+	other := user.ContextWithClaims(anonymous, &user.Claims{UserID: "viewer-2", Role: user.RoleViewer})
+	admin := user.ContextWithClaims(anonymous, &user.Claims{UserID: "admin-1", Role: user.RoleAdmin})
+
+	t.Run("未登录不能创建播放列表", func(t *testing.T) {
+		service, _ := createPlaylistTestService(t)
+		resp, err := service.CreatePlaylist(anonymous, &api.PlaylistCreateRequest{Name: "稍后观看"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4305), resp.Base.Code)
+	})
+
+	t.Run("不能修改其他用户的播放列表", func(t *testing.T) {
+		service, playlistID := createPlaylistTestService(t)
+		_, err := service.AddPlaylistVideos(playlistOwnerContext(), &api.PlaylistVideosRequest{PlaylistID: playlistID, VideoIds: []string{"video1", "video2"}})
+		require.NoError(t, err)
+
+		for name, ctx := range map[string]context.Context{"未登录": anonymous, "其他用户": other} {
+			resp, err := service.UpdatePlaylist(ctx, &api.PlaylistUpdateRequest{PlaylistID: playlistID, Name: stringPtr("改名")})
+			require.NoError(t, err)
+			assert.Equal(t, int32(4305), resp.Base.Code, name)
+
+			resp, err = service.AddPlaylistVideos(ctx, &api.PlaylistVideosRequest{PlaylistID: playlistID, VideoIds: []string{"video3"}})
+			require.NoError(t, err)
+			assert.Equal(t, int32(4305), resp.Base.Code, name)
+
+			resp, err = service.RemovePlaylistVideos(ctx, &api.PlaylistVideosRequest{PlaylistID: playlistID, VideoIds: []string{"video1"}})
+			require.NoError(t, err)
+			assert.Equal(t, int32(4305), resp.Base.Code, name)
+
+			resp, err = service.ReorderPlaylist(ctx, &api.PlaylistVideosRequest{PlaylistID: playlistID, VideoIds: []string{"video2", "video1"}})
+			require.NoError(t, err)
+			assert.Equal(t, int32(4305), resp.Base.Code, name)
+
+			resp, err = service.DeletePlaylist(ctx, &api.PlaylistRequest{PlaylistID: playlistID})
+			require.NoError(t, err)
+			assert.Equal(t, int32(4305), resp.Base.Code, name)
+		}
+
+		found, err := service.playlists.Get(context.Background(), playlistID)
+		require.NoError(t, err)
+		assert.Equal(t, "连播", found.Name, "无权修改时播放列表应该保持不变")
+		assert.Equal(t, []string{"video1", "video2"}, found.VideoIDs)
+	})
+
+	t.Run("管理员可以修改任意播放列表", func(t *testing.T) {
+		service, playlistID := createPlaylistTestService(t)
+		resp, err := service.UpdatePlaylist(admin, &api.PlaylistUpdateRequest{PlaylistID: playlistID, Name: stringPtr("精选")})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+		resp, err = service.DeletePlaylist(admin, &api.PlaylistRequest{PlaylistID: playlistID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+	})
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/middleware"
+	"github.com/manteia/zhulong/pkg/notify"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/transcode"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+// ReprocessVideo 重新处理视频：重新提取视频信息和生成缩略图，并重新创建预览图和HLS打包任务
+// 用于处理失败或在相应功能上线前上传的视频；已归档的视频先恢复，单个步骤失败时保留原有结果
+func (s *VideoService) ReprocessVideo(ctx context.Context, req *api.VideoReprocessRequest) (*api.VideoReprocessResponse, error) {
+	if req.VideoID == "" {
+		return s.videoReprocessErrorResponse(4601, "视频ID不能为空"), nil
+	}
+
+	meta, err := s.metadataService.GetMetadata(ctx, req.VideoID)
+	if err != nil {
+		return s.videoReprocessErrorResponse(4602, "视频不存在"), nil
+	}
+	if !canModifyVideo(ctx, meta) {
+		return s.videoReprocessErrorResponse(4603, "无权处理其他用户的视频"), nil
+	}
+	if !meta.IsVideo() {
+		return s.videoReprocessErrorResponse(4604, "只有视频支持重新处理"), nil
+	}
+
+	meta, err = s.restoreArchivedVideo(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+	return s.reprocessVideo(ctx, meta)
+}
+
+// reprocessVideo 重新提取视频信息、生成缩略图并安排预览图和HLS打包任务，视频文件需要在视频存储桶中
+func (s *VideoService) reprocessVideo(ctx context.Context, meta *metadata.FileMetadata) (*api.VideoReprocessResponse, error) {
+	headData, err := s.readObjectHead(ctx, meta.BucketName, meta.ObjectName)
+	if err != nil {
+		return nil, fmt.Errorf("读取视频文件失败: %w", err)
+	}
+
+	resp := &api.VideoReprocessResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "已重新处理",
+		},
+	}
+	update := &metadata.UpdateMetadataRequest{FileID: meta.FileID}
+
+	videoInfo, err := s.videoExtractor.ExtractInfo(&video.InfoExtractionRequest{
+		Data:     headData,
+		Filename: meta.FileName,
+		Reader:   storage.NewObjectReaderAt(ctx, s.storageClient, meta.BucketName, meta.ObjectName, meta.FileSize),
+		Size:     meta.FileSize,
+	})
+	if err == nil {
+		duration := int64(videoInfo.Duration.Seconds())
+		update.Duration = &duration
+		update.Width = &videoInfo.Width
+		update.Height = &videoInfo.Height
+		update.Rotation = &videoInfo.Rotation
+		update.Bitrate = &videoInfo.Bitrate
+		update.VideoCodec = &videoInfo.VideoCodec
+		update.AudioCodec = &videoInfo.AudioCodec
+		update.FrameRate = &videoInfo.FrameRate
+		// 手动设置的章节优先，视频没有章节时使用文件中解析出的章节
+		if len(meta.Chapters) == 0 {
+			chapters := convertVideoChapters(videoInfo.Chapters)
+			update.Chapters = &chapters
+		}
+		resp.InfoUpdated = true
+	} else {
+		fmt.Printf("重新提取视频信息失败(%s, request_id=%s): %v\n", meta.FileID, middleware.RequestIDFromContext(ctx), err)
+	}
+
+	// 已有缩略图时覆盖原文件，保持缩略图路径不变
+	thumbnailObjectName := meta.Thumbnail
+	if thumbnailObjectName == "" {
+		thumbnailObjectName = newThumbnailObjectName(meta.FileID, time.Now())
+	}
+	// 从存储流式读取完整视频用于抽帧，失败时退化为使用文件头部
+	var reader io.Reader
+	if file, err := s.storageClient.OpenFile(ctx, meta.BucketName, meta.ObjectName); err == nil {
+		defer file.Close()
+		reader = file
+	}
+	if err := s.uploadThumbnail(ctx, thumbnailObjectName, headData, reader); err == nil {
+		update.Thumbnail = &thumbnailObjectName
+		resp.ThumbnailUpdated = true
+	} else {
+		fmt.Printf("重新生成缩略图失败(%s, request_id=%s): %v\n", meta.FileID, middleware.RequestIDFromContext(ctx), err)
+	}
+
+	if err := s.metadataService.UpdateMetadata(ctx, update); err != nil {
+		return nil, fmt.Errorf("更新视频元数据失败: %w", err)
+	}
+	meta, err = s.metadataService.GetMetadata(ctx, meta.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("获取视频元数据失败: %w", err)
+	}
+
+	if s.previewGenerationAvailable() {
+		s.schedulePreviewGeneration(meta)
+		resp.PreviewsScheduled = true
+	}
+	// 打包失败的视频重新创建任务后恢复为处理中
+	if s.hlsPackagingAvailable() {
+		s.scheduleHLSPackaging(meta, transcode.DefaultPriority)
+		resp.TranscodeScheduled = true
+	}
+
+	if refreshed, err := s.metadataService.GetMetadata(ctx, meta.FileID); err == nil {
+		meta = refreshed
+	}
+	resp.Video = convertToAPIVideo(meta)
+	if resp.ThumbnailUpdated {
+		s.publishEvent(notify.EventThumbnailReady, meta, resp.Video)
+	}
+	return resp, nil
+}
+
+// videoReprocessErrorResponse 创建重新处理视频错误响应
+func (s *VideoService) videoReprocessErrorResponse(code int32, message string) *api.VideoReprocessResponse {
+	return &api.VideoReprocessResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/notify"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// mp4CodecTestData 生成10秒、25fps、1280x720 H.264视频轨的MP4测试数据
+func mp4CodecTestData() []byte {
+	box := func(boxType string, payloads ...[]byte) []byte {
+		payload := bytes.Join(payloads, nil)
+		header := make([]byte, 8)
+		binary.BigEndian.PutUint32(header[0:4], uint32(8+len(payload)))
+		copy(header[4:8], boxType)
+		return append(header, payload...)
+	}
+	uint32s := func(values ...uint32) []byte {
+		data := make([]byte, 4*len(values))
+		for i, v := range values {
+			binary.BigEndian.PutUint32(data[i*4:], v)
+		}
+		return data
+	}
+
+	// tkhd版本0共84字节，宽高（16.16定点数）位于末尾
+	tkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(tkhd[76:80], 1280<<16)
+	binary.BigEndian.PutUint32(tkhd[80:84], 720<<16)
+	// 视觉样本描述条目在偏移32处记录宽高
+	entry := make([]byte, 86)
+	binary.BigEndian.PutUint32(entry[0:4], uint32(len(entry)))
+	copy(entry[4:8], "avc1")
+	binary.BigEndian.PutUint16(entry[32:34], 1280)
+	binary.BigEndian.PutUint16(entry[34:36], 720)
+	hdlr := append(uint32s(0, 0), append([]byte("vide"), make([]byte, 13)...)...)
+
+	// 250个样本，每个4000字节，时长10秒
+	trak := box("trak",
+		box("tkhd", tkhd),
+		box("mdia",
+			box("mdhd", uint32s(0, 0, 0, 12800, 128000), make([]byte, 4)),
+			box("hdlr", hdlr),
+			box("minf", box("stbl",
+				box("stsd", uint32s(0, 1), entry),
+				box("stts", uint32s(0, 1, 250, 512)),
+				box("stsz", uint32s(0, 4000, 250)),
+			)),
+		),
+	)
+	return bytes.Join([][]byte{
+		box("ftyp", []byte("isom\x00\x00\x02\x00isomavc1")),
+		box("moov", box("mvhd", uint32s(0, 0, 0, 1000, 10000), make([]byte, 80)), trak),
+		box("mdat", make([]byte, 2048)),
+	}, nil)
+}
+
+func TestVideoService_ReprocessVideo(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("补充缺失的缩略图", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		uploaded := uploadTestVideo(t, service, "legacy.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+
+		// 模拟缩略图功能上线前上传的视频
+		require.NoError(t, store.DeleteFile(ctx, testBucket, uploaded.Video.ThumbnailPath))
+		require.NoError(t, service.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{
+			FileID:    uploaded.Video.ID,
+			Thumbnail: stringPtr(""),
+		}))
+		events := service.SubscribeNotifications(claimsContext("admin-1", user.RoleAdmin))
+		defer events.Close()
+
+		resp, err := service.ReprocessVideo(ctx, &api.VideoReprocessRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.True(t, resp.ThumbnailUpdated)
+		assert.False(t, resp.PreviewsScheduled, "帧提取器不可用时不生成预览图")
+		assert.False(t, resp.TranscodeScheduled, "未启用HLS时不打包")
+		require.NotEmpty(t, resp.Video.ThumbnailPath)
+		assert.Contains(t, testObjectNames(t, store, ""), resp.Video.ThumbnailPath)
+
+		event := <-events.C
+		assert.Equal(t, notify.EventThumbnailReady, event.Type)
+		assert.Equal(t, uploaded.Video.ID, event.VideoID)
+
+		// 已有缩略图时覆盖原文件
+		again, err := service.ReprocessVideo(ctx, &api.VideoReprocessRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), again.Base.Code, again.Base.Message)
+		assert.Equal(t, resp.Video.ThumbnailPath, again.Video.ThumbnailPath)
+		assert.Equal(t, 1, countObjects(t, store, "thumbnails/"))
+	})
+
+	t.Run("补充编码、码率和帧率", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+		uploaded := uploadTestVideo(t, service, "codec.mp4", mp4CodecTestData())
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+		assert.Equal(t, "H.264", uploaded.Video.VideoCodec, "上传时应该保存提取的编码信息")
+		assert.Equal(t, int64(250*4000*8/10), uploaded.Video.Bitrate)
+		assert.InDelta(t, 25.0, uploaded.Video.FrameRate, 0.001)
+
+		// 模拟保存编码信息之前上传的视频
+		bitrate := int64(0)
+		frameRate := 0.0
+		require.NoError(t, service.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{
+			FileID:     uploaded.Video.ID,
+			VideoCodec: stringPtr(""),
+			Bitrate:    &bitrate,
+			FrameRate:  &frameRate,
+		}))
+
+		resp, err := service.ReprocessVideo(ctx, &api.VideoReprocessRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.True(t, resp.InfoUpdated)
+		assert.Equal(t, "H.264", resp.Video.VideoCodec)
+		assert.Empty(t, resp.Video.AudioCodec, "没有音频轨道")
+		assert.Equal(t, uploaded.Video.Bitrate, resp.Video.Bitrate)
+		assert.InDelta(t, 25.0, resp.Video.FrameRate, 0.001)
+	})
+
+	t.Run("保留手动设置的章节", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+		uploaded := uploadTestVideo(t, service, "chapters.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+		chapters := []metadata.Chapter{{Title: "片头", StartTime: 0}}
+		require.NoError(t, service.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{
+			FileID:   uploaded.Video.ID,
+			Chapters: &chapters,
+		}))
+
+		resp, err := service.ReprocessVideo(ctx, &api.VideoReprocessRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		require.Len(t, resp.Video.Chapters, 1)
+		assert.Equal(t, "片头", resp.Video.Chapters[0].Title)
+	})
+
+	t.Run("已归档的视频先恢复", func(t *testing.T) {
+		service, store := createArchiveTestService(t)
+		uploaded := uploadTestVideo(t, service, "archived.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+		archived, err := service.ArchiveVideo(ctx, &api.VideoArchiveRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), archived.Base.Code, archived.Base.Message)
+
+		resp, err := service.ReprocessVideo(ctx, &api.VideoReprocessRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.False(t, resp.Video.Archived)
+		assertObjectLocation(t, store, uploaded.Video.StoragePath, false)
+	})
+
+	t.Run("参数和权限", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+		aliceCtx := user.ContextWithClaims(ctx, &user.Claims{UserID: "alice", Role: user.RoleUploader})
+		bobCtx := user.ContextWithClaims(ctx, &user.Claims{UserID: "bob", Role: user.RoleUploader})
+		uploaded, err := service.UploadVideo(aliceCtx, &api.VideoUploadRequest{Title: "alice的视频"}, createTestFileHeader(t, "alice.mp4", "video/mp4", mp4TestData(2048)))
+		require.NoError(t, err)
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+
+		resp, err := service.ReprocessVideo(ctx, &api.VideoReprocessRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4601), resp.Base.Code)
+
+		resp, err = service.ReprocessVideo(ctx, &api.VideoReprocessRequest{VideoID: "missing"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4602), resp.Base.Code)
+
+		resp, err = service.ReprocessVideo(bobCtx, &api.VideoReprocessRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4603), resp.Base.Code, "上传者不能处理其他用户的视频")
+
+		resp, err = service.ReprocessVideo(aliceCtx, &api.VideoReprocessRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+	})
+}
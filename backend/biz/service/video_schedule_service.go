@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+// scheduleDefaultCheckInterval 未配置时处理到期视频的检查间隔
+const scheduleDefaultCheckInterval = time.Minute
+
+// ExpireVideos 处理已到期的视频，返回处理的视频数量
+// 定时发布和到期对其他用户的限制由可见性判断实时生效，这里只完成到期后的处理：
+// 按unlist处理的公开视频改为不公开并清除到期时间，按delete处理的视频连同衍生文件一起删除；单个视频处理失败时记录日志并继续
+func (s *VideoService) ExpireVideos(ctx context.Context) (int, error) {
+	list, err := s.metadataService.ListMetadata(ctx, &metadata.ListMetadataRequest{Limit: math.MaxInt})
+	if err != nil {
+		return 0, fmt.Errorf("获取视频列表失败: %w", err)
+	}
+
+	now := time.Now()
+	expired := 0
+	for _, meta := range list.Items {
+		if !meta.Expired(now) {
+			continue
+		}
+		if err := s.expireVideo(ctx, meta); err != nil {
+			fmt.Printf("处理到期视频失败(%s): %v\n", meta.FileID, err)
+			continue
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// expireVideo 按到期处理方式处理单个到期视频
+func (s *VideoService) expireVideo(ctx context.Context, meta *metadata.FileMetadata) error {
+	if meta.ExpiryAction == metadata.ExpiryDelete {
+		resp, err := s.DeleteVideo(ctx, &api.VideoDeleteRequest{VideoID: meta.FileID})
+		if err != nil {
+			return err
+		}
+		if resp.Base.Code != 0 {
+			return errors.New(resp.Base.Message)
+		}
+		return nil
+	}
+
+	// 清除到期时间，之后上传者可以重新公开视频；期间视频被修改时在下次检查时处理
+	cleared := time.Time{}
+	update := &metadata.UpdateMetadataRequest{
+		FileID:            meta.FileID,
+		ExpiresAt:         &cleared,
+		ExpectedUpdatedAt: &meta.UpdatedAt,
+	}
+	if meta.Visibility == metadata.VisibilityPublic {
+		unlisted := metadata.VisibilityUnlisted
+		update.Visibility = &unlisted
+	}
+	return s.metadataService.UpdateMetadata(ctx, update)
+}
+
+// startScheduleChecker 在后台定期处理到期视频
+func (s *VideoService) startScheduleChecker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			count, err := s.ExpireVideos(context.Background())
+			if err != nil {
+				fmt.Printf("处理到期视频失败: %v\n", err)
+			} else if count > 0 {
+				fmt.Printf("已处理%d个到期视频\n", count)
+			}
+		}
+	}()
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+func TestVideoService_UpdateVideoSchedule(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("设置和取消定时发布和到期", func(t *testing.T) {
+		service := createUpdateTestService(t)
+		publishAt := time.Now().Add(time.Hour).UnixMilli()
+		expiresAt := time.Now().Add(48 * time.Hour).UnixMilli()
+
+		resp, err := service.UpdateVideo(ctx, &api.VideoUpdateRequest{
+			VideoID:   "video1",
+			PublishAt: &publishAt,
+			ExpiresAt: &expiresAt,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, publishAt, resp.Video.PublishAt)
+		assert.Equal(t, expiresAt, resp.Video.ExpiresAt)
+		assert.Equal(t, metadata.ExpiryUnlist, resp.Video.ExpiryAction, "未指定处理方式时按unlist处理")
+
+		resp, err = service.UpdateVideo(ctx, &api.VideoUpdateRequest{VideoID: "video1", ExpiryAction: stringPtr("Delete")})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, metadata.ExpiryDelete, resp.Video.ExpiryAction)
+
+		var zero int64
+		resp, err = service.UpdateVideo(ctx, &api.VideoUpdateRequest{VideoID: "video1", PublishAt: &zero, ExpiresAt: &zero})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Zero(t, resp.Video.PublishAt)
+		assert.Zero(t, resp.Video.ExpiresAt)
+		assert.Empty(t, resp.Video.ExpiryAction, "没有到期时间时不返回处理方式")
+	})
+
+	t.Run("时间无效", func(t *testing.T) {
+		service := createUpdateTestService(t)
+		negative := int64(-1)
+		past := time.Now().Add(-time.Minute).UnixMilli()
+		publishAt := time.Now().Add(48 * time.Hour).UnixMilli()
+		expiresAt := time.Now().Add(time.Hour).UnixMilli()
+
+		for name, req := range map[string]*api.VideoUpdateRequest{
+			"发布时间为负数":  {VideoID: "video1", PublishAt: &negative},
+			"到期时间已过":   {VideoID: "video1", ExpiresAt: &past},
+			"处理方式无效":   {VideoID: "video1", ExpiryAction: stringPtr("archive")},
+			"到期早于发布时间": {VideoID: "video1", PublishAt: &publishAt, ExpiresAt: &expiresAt},
+		} {
+			resp, err := service.UpdateVideo(ctx, req)
+			require.NoError(t, err)
+			assert.Equal(t, int32(4001), resp.Base.Code, name)
+		}
+
+		// 与已保存的到期时间比较
+		resp, err := service.UpdateVideo(ctx, &api.VideoUpdateRequest{VideoID: "video1", ExpiresAt: &expiresAt})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		resp, err = service.UpdateVideo(ctx, &api.VideoUpdateRequest{VideoID: "video1", PublishAt: &publishAt})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4001), resp.Base.Code)
+	})
+
+	t.Run("定时发布前其他用户无法查看", func(t *testing.T) {
+		service := createUpdateTestService(t)
+		publishAt := time.Now().Add(time.Hour).UnixMilli()
+		resp, err := service.UpdateVideo(ctx, &api.VideoUpdateRequest{
+			VideoID:    "video1",
+			Visibility: stringPtr(metadata.VisibilityPublic),
+			PublishAt:  &publishAt,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+		other := user.ContextWithClaims(ctx, &user.Claims{UserID: "user-2"})
+		_, err = service.getVisibleMetadata(other, "video1")
+		assert.Error(t, err)
+		list, err := service.GetVideoList(other, &api.VideoListRequest{})
+		require.NoError(t, err)
+		assert.Empty(t, list.Videos)
+
+		owner := user.ContextWithClaims(ctx, &user.Claims{UserID: "system"})
+		_, err = service.getVisibleMetadata(owner, "video1")
+		assert.NoError(t, err, "上传者可以在发布前查看")
+	})
+}
+
+func TestVideoService_ExpireVideos(t *testing.T) {
+	ctx := context.Background()
+	service, store := createDeleteTestService(t)
+	expired := time.Now().Add(-time.Minute)
+
+	require.NoError(t, service.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{
+		FileID:       "video1",
+		ExpiresAt:    &expired,
+		ExpiryAction: stringPtr(metadata.ExpiryDelete),
+	}))
+	for _, meta := range []*metadata.FileMetadata{
+		{FileID: "public", Title: "公开", Visibility: metadata.VisibilityPublic, ExpiresAt: expired, CreatedBy: "system"},
+		{FileID: "private", Title: "私有", Visibility: metadata.VisibilityPrivate, ExpiresAt: expired, CreatedBy: "system"},
+		{FileID: "future", Title: "未到期", Visibility: metadata.VisibilityPublic, ExpiresAt: time.Now().Add(time.Hour), CreatedBy: "system"},
+	} {
+		meta.BucketName = "zhulong-videos"
+		meta.ObjectName = "videos/2025/08/" + meta.FileID + ".mp4"
+		require.NoError(t, service.metadataService.SaveMetadata(ctx, meta))
+	}
+
+	count, err := service.ExpireVideos(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	_, err = service.metadataService.GetMetadata(ctx, "video1")
+	assert.Error(t, err, "按delete处理的视频应该被删除")
+	assert.NotContains(t, testObjectNames(t, store, ""), "videos/2025/08/video1.mp4")
+
+	public, err := service.metadataService.GetMetadata(ctx, "public")
+	require.NoError(t, err)
+	assert.Equal(t, metadata.VisibilityUnlisted, public.Visibility, "公开视频到期后改为不公开")
+	assert.True(t, public.ExpiresAt.IsZero(), "处理后清除到期时间")
+
+	private, err := service.metadataService.GetMetadata(ctx, "private")
+	require.NoError(t, err)
+	assert.Equal(t, metadata.VisibilityPrivate, private.Visibility, "私有视频保持私有")
+
+	future, err := service.metadataService.GetMetadata(ctx, "future")
+	require.NoError(t, err)
+	assert.Equal(t, metadata.VisibilityPublic, future.Visibility)
+
+	count, err = service.ExpireVideos(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count, "已处理的视频不再重复处理")
+}
@@ -3,77 +3,350 @@ package service
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/analytics"
+	"github.com/manteia/zhulong/pkg/archive"
+	"github.com/manteia/zhulong/pkg/backup"
+	"github.com/manteia/zhulong/pkg/cache"
+	"github.com/manteia/zhulong/pkg/collection"
 	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/delete"
+	"github.com/manteia/zhulong/pkg/download"
+	"github.com/manteia/zhulong/pkg/favorite"
+	"github.com/manteia/zhulong/pkg/history"
+	"github.com/manteia/zhulong/pkg/media"
 	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/moderation"
+	"github.com/manteia/zhulong/pkg/nfo"
+	"github.com/manteia/zhulong/pkg/notify"
+	"github.com/manteia/zhulong/pkg/playback"
+	"github.com/manteia/zhulong/pkg/playlist"
+	"github.com/manteia/zhulong/pkg/quota"
+	"github.com/manteia/zhulong/pkg/replication"
+	"github.com/manteia/zhulong/pkg/share"
 	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/streaming"
+	"github.com/manteia/zhulong/pkg/tempfile"
+	"github.com/manteia/zhulong/pkg/transcode"
 	"github.com/manteia/zhulong/pkg/upload"
+	"github.com/manteia/zhulong/pkg/user"
 	"github.com/manteia/zhulong/pkg/video"
+	"github.com/manteia/zhulong/pkg/webhook"
+)
+
+// videoHeadSize 上传时缓存的文件头部大小，用于格式验证和信息提取
+const videoHeadSize = 1024 * 1024
+
+// 上传时生成的缩略图尺寸上限，保持视频宽高比
+const (
+	thumbnailWidth  = 320
+	thumbnailHeight = 240
 )
 
 // VideoService 视频服务
 type VideoService struct {
 	config            *config.Config
 	storageClient     storage.StorageInterface
+	buckets           *storage.BucketResolver
 	uploadService     *upload.UploadService
 	metadataService   *metadata.MetadataService
-	videoValidator    *video.VideoValidator
+	videoValidator    atomic.Pointer[video.VideoValidator] // 配置重新加载时替换
+	prober            *video.FFprobeChecker                 // 验证方式不是probe时为nil
+	mediaPolicy       atomic.Pointer[mediaUploadPolicy]     // 配置重新加载时替换
+	waveforms         *media.WaveformExtractor
+	clipper           *video.FFmpegClipper
 	videoExtractor    *video.VideoInfoExtractor
 	thumbnailGenerator *video.ThumbnailGenerator
-	sizeLimitManager  *video.SizeLimitManager
+	sizeLimitManager  atomic.Pointer[video.SizeLimitManager] // 配置重新加载时替换
+	deleteService     *delete.DeleteService
+	downloadService   *download.DownloadService
+	hlsPackager       *streaming.HLSPackager
+	tempFiles         *tempfile.Manager // 未设置时使用系统临时目录
+	transcodeQueue    *transcode.Queue
+	directUploads     *upload.DirectUploadManager
+	multipartUploads  *upload.MultipartSessionManager
+	progressRegistry  *upload.ProgressRegistry
+	notifier          *notify.Hub
+	quotaManager      *quota.QuotaManager
+	collections       *collection.CollectionService
+	playlists         *playlist.PlaylistService
+	history           *history.HistoryService
+	views             *analytics.ViewTracker
+	favorites         *favorite.FavoriteService
+	webhooks          *webhook.Dispatcher // 未配置Webhook时为nil
+	archiver          *archive.Archiver
+	playbackSigner    *playback.Signer
+	shares            *share.ShareService
+	urlCache          *cache.URLCache
+	moderation        *moderation.Pipeline // 未配置审核钩子时为nil
+	reviews           *moderation.ReviewQueue
+	previewJobs       sync.Map // 正在生成预览图的视频ID
+	versionJobs       sync.Map // 正在替换内容的视频ID
+	migration         *storageMigration // 正在运行或最近一次的存储迁移
+	migrationMutex    sync.Mutex
+	videoImport       *videoImport // 正在运行或最近一次的批量导入
+	importMutex       sync.Mutex
+	nfoExport         *nfo.Exporter // 正在运行或最近一次的NFO导出
+	nfoExportMutex    sync.Mutex
+	backups           *backup.Store // 未配置备份存储桶时为nil
+	backupMutex       sync.Mutex    // 备份和恢复互斥，备份不会读到恢复了一半的元数据
+	replicator        *replication.Replicator // 未启用存储复制时为nil
+	storageScan       atomic.Pointer[storageScan] // 最近一次存储扫描结果
+	storageScanning   atomic.Bool
+}
+
+// VideoComponents 视频处理组件：格式验证、信息提取、缩略图生成和大小限制
+type VideoComponents struct {
+	Validator          *video.VideoValidator
+	Extractor          *video.VideoInfoExtractor
+	ThumbnailGenerator *video.ThumbnailGenerator
+	SizeLimitManager   *video.SizeLimitManager
+	TempFiles          *tempfile.Manager // 转码、帧提取等使用的临时目录
 }
 
-// NewVideoService 创建视频服务
-func NewVideoService() (*VideoService, error) {
-	// 加载配置
-	cfg, err := config.LoadFromFile("../config/development.yml")
+// NewVideoComponents 根据上传和转码配置创建视频处理组件
+func NewVideoComponents(cfg *config.Config) (*VideoComponents, error) {
+	videoValidator, sizeLimitManager, err := newUploadValidators(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tempConfig, err := cfg.GetTempConfig()
 	if err != nil {
-		return nil, fmt.Errorf("加载配置失败: %v", err)
+		return nil, err
 	}
+	tempFiles, err := tempfile.NewManager(tempConfig)
+	if err != nil {
+		return nil, fmt.Errorf("初始化临时目录失败: %v", err)
+	}
+	frameExtractor := video.NewFFmpegFrameExtractor(cfg.Streaming.FFmpegPath)
+	frameExtractor.SetTempFiles(tempFiles)
+	thumbnailGenerator := video.NewThumbnailGenerator()
+	thumbnailGenerator.SetFrameExtractor(frameExtractor)
+
+	return &VideoComponents{
+		Validator:          videoValidator,
+		Extractor:          video.NewVideoInfoExtractor(),
+		ThumbnailGenerator: thumbnailGenerator,
+		SizeLimitManager:   sizeLimitManager,
+		TempFiles:          tempFiles,
+	}, nil
+}
 
-	// 初始化存储客户端 
-	storageClient, err := storage.NewMinIOStorage(&storage.MinIOConfig{
-		Endpoint:  cfg.MinIO.Endpoint,
-		AccessKey: cfg.MinIO.AccessKey,
-		SecretKey: cfg.MinIO.SecretKey,
-		UseSSL:    cfg.MinIO.UseSSL,
-		Region:    cfg.MinIO.Region,
-	})
+// NewVideoService 使用注入的配置、存储客户端、元数据服务和视频处理组件创建视频服务
+// 上传、删除、配额、合集等内部服务由这些依赖派生；同时启动过期分片上传的清理，启用归档、定期备份和存储复制时启动对应的调度
+func NewVideoService(cfg *config.Config, storageClient storage.StorageInterface, metadataService *metadata.MetadataService, components *VideoComponents) (*VideoService, error) {
+	if cfg == nil || storageClient == nil || metadataService == nil || components == nil {
+		return nil, fmt.Errorf("视频服务的依赖不能为空")
+	}
+	// 启用存储复制时，服务写入存储的文件都经过包装的存储加入复制队列
+	replicator, err := newReplicator(cfg, storageClient)
 	if err != nil {
-		return nil, fmt.Errorf("初始化存储客户端失败: %v", err)
+		return nil, fmt.Errorf("初始化存储复制失败: %v", err)
+	}
+	if replicator != nil {
+		storageClient = replicator.Wrap(storageClient)
 	}
 
 	// 初始化各种服务
 	uploadService := upload.NewUploadService(storageClient)
-	metadataService := metadata.NewMetadataService()
-	videoValidator := video.NewVideoValidator()
-	videoExtractor := video.NewVideoInfoExtractor()
-	thumbnailGenerator := video.NewThumbnailGenerator()
-	sizeLimitManager := video.NewSizeLimitManager()
+	progressRegistry := upload.NewProgressRegistry()
+	uploadService.SetProgressRegistry(progressRegistry)
+	deleteService := delete.NewDeleteService(storageClient)
+	deleteService.SetConcurrency(cfg.Storage.DeleteConcurrency)
+	deleteService.SetMaxPrefixObjects(cfg.Storage.PrefixDeleteMaxObjects)
+	quotaLimit, err := cfg.GetUserQuotaLimit()
+	if err != nil {
+		return nil, fmt.Errorf("解析用户存储配额失败: %v", err)
+	}
+	webhooks, err := webhook.NewDispatcher(cfg.GetWebhookEndpoints())
+	if err != nil {
+		return nil, fmt.Errorf("初始化Webhook失败: %v", err)
+	}
+	playbackSigner, err := newPlaybackSigner(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("初始化播放令牌失败: %v", err)
+	}
+	urlCache, err := cache.NewFromConfig(cfg.GetCacheConfig())
+	if err != nil {
+		return nil, fmt.Errorf("初始化缓存失败: %v", err)
+	}
+	moderationHooks, err := cfg.GetModerationHooks()
+	if err != nil {
+		return nil, fmt.Errorf("初始化审核钩子失败: %v", err)
+	}
+	moderationPipeline, err := moderation.NewPipeline(moderationHooks, cfg.Moderation.OnError)
+	if err != nil {
+		return nil, fmt.Errorf("初始化审核钩子失败: %v", err)
+	}
+	mediaPolicy, err := newMediaUploadPolicy(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("初始化媒体类型失败: %v", err)
+	}
+	backups, err := newBackupStore(cfg, storageClient)
+	if err != nil {
+		return nil, fmt.Errorf("初始化备份失败: %v", err)
+	}
+	// 未配置时使用默认值
+	multipartTTL, _ := cfg.GetMultipartSessionTTL()
+	multipartCleanupInterval, _ := cfg.GetMultipartCleanupInterval()
+	if multipartCleanupInterval <= 0 {
+		multipartCleanupInterval = multipartDefaultCleanupInterval
+	}
+	scheduleCheckInterval, _ := cfg.GetScheduleCheckInterval()
+	if scheduleCheckInterval <= 0 {
+		scheduleCheckInterval = scheduleDefaultCheckInterval
+	}
 
-	return &VideoService{
+	service := &VideoService{
 		config:            cfg,
 		storageClient:     storageClient,
+		buckets:           cfg.GetBucketResolver(),
 		uploadService:     uploadService,
 		metadataService:   metadataService,
-		videoValidator:    videoValidator,
-		videoExtractor:    videoExtractor,
-		thumbnailGenerator: thumbnailGenerator,
-		sizeLimitManager:  sizeLimitManager,
-	}, nil
+		videoExtractor:    components.Extractor,
+		thumbnailGenerator: components.ThumbnailGenerator,
+		deleteService:     deleteService,
+		downloadService:   download.NewDownloadService(storageClient),
+		hlsPackager:       newHLSPackager(cfg, storageClient, components.TempFiles),
+		tempFiles:         components.TempFiles,
+		directUploads:     upload.NewDirectUploadManager(directUploadExpiry),
+		multipartUploads:  upload.NewMultipartSessionManager(multipartTTL),
+		progressRegistry:  progressRegistry,
+		notifier:          notify.NewHub(),
+		quotaManager:      quota.NewQuotaManager(quotaLimit, metadataService.GetStorageUsage),
+		collections:       collection.NewCollectionService(),
+		playlists:         playlist.NewPlaylistService(),
+		history:           history.NewHistoryService(),
+		views:             analytics.NewViewTracker(),
+		favorites:         favorite.NewFavoriteService(),
+		webhooks:          webhooks,
+		archiver:          archive.NewArchiver(storageClient, cfg.Archive.Bucket),
+		playbackSigner:    playbackSigner,
+		shares:            share.NewShareService(),
+		urlCache:          cache.NewURLCache(urlCache),
+		moderation:        moderationPipeline,
+		reviews:           moderation.NewReviewQueue(),
+		prober:            newVideoProber(cfg),
+		waveforms:         media.NewWaveformExtractor(cfg.Streaming.FFmpegPath),
+		clipper:           video.NewFFmpegClipper(cfg.Streaming.FFmpegPath),
+		backups:           backups,
+		replicator:        replicator,
+	}
+	service.videoValidator.Store(components.Validator)
+	service.mediaPolicy.Store(mediaPolicy)
+	service.sizeLimitManager.Store(components.SizeLimitManager)
+
+	if err := service.startTranscodeQueue(); err != nil {
+		return nil, fmt.Errorf("初始化转码队列失败: %v", err)
+	}
+	service.startMultipartCleanup(multipartCleanupInterval)
+	service.startScheduleChecker(scheduleCheckInterval)
+	components.TempFiles.Start()
+
+	// 未启用时不再归档新的视频，已归档的视频仍会在播放时恢复
+	if cfg.Archive.Enabled {
+		idleAfter, err := cfg.GetArchiveIdleAfter()
+		if err != nil {
+			return nil, fmt.Errorf("解析归档闲置时长失败: %v", err)
+		}
+		interval, err := cfg.GetArchiveCheckInterval()
+		if err != nil {
+			return nil, fmt.Errorf("解析归档检查间隔失败: %v", err)
+		}
+		service.startArchiveScheduler(interval, idleAfter)
+	}
+	if cfg.Backup.Enabled && backups != nil {
+		interval, err := cfg.GetBackupInterval()
+		if err != nil {
+			return nil, fmt.Errorf("解析备份间隔失败: %v", err)
+		}
+		service.startBackupScheduler(interval)
+	}
+	if replicator != nil {
+		interval, err := cfg.GetReplicationSyncInterval()
+		if err != nil {
+			return nil, fmt.Errorf("解析复制同步间隔失败: %v", err)
+		}
+		service.startReplication(interval)
+	}
+
+	return service, nil
+}
+
+// Config 获取服务配置
+func (s *VideoService) Config() *config.Config {
+	return s.config
+}
+
+// LocalStorage 获取本地文件系统存储，未使用local存储驱动时返回false
+func (s *VideoService) LocalStorage() (*storage.LocalStorage, bool) {
+	// 启用存储复制或超时时间时存储客户端是包装后的存储
+	local, ok := storage.Underlying(s.storageClient).(*storage.LocalStorage)
+	return local, ok
+}
+
+// MemoryStorage 获取内存存储，未使用memory存储驱动时返回false
+func (s *VideoService) MemoryStorage() (*storage.MemoryStorage, bool) {
+	memory, ok := storage.Underlying(s.storageClient).(*storage.MemoryStorage)
+	return memory, ok
 }
 
 // UploadVideo 上传视频
+// 上传进度按上传ID记录，客户端未指定上传ID时使用视频ID
 func (s *VideoService) UploadVideo(ctx context.Context, req *api.VideoUploadRequest, fileHeader *multipart.FileHeader) (*api.VideoUploadResponse, error) {
+	if !canUploadVideo(ctx) {
+		return s.errorResponse(1012, "无权上传视频"), nil
+	}
+	if req.UploadID != "" {
+		if err := upload.ValidateUploadID(req.UploadID); err != nil {
+			return s.errorResponse(1001, err.Error()), nil
+		}
+	}
+	checksum, err := upload.NormalizeChecksum(req.Checksum)
+	if err != nil {
+		return s.errorResponse(1001, err.Error()), nil
+	}
+	req.Checksum = checksum
+	visibility, err := s.uploadVisibility(req.Visibility)
+	if err != nil {
+		return s.errorResponse(1001, err.Error()), nil
+	}
+	req.Visibility = visibility
+
 	// 生成视频ID
 	videoID := uuid.New().String()
+	uploadID := getValueOrDefaultFromString(req.UploadID, videoID)
 
+	s.progressRegistry.Start(uploadID, fileHeader.Size)
+	resp, err := s.storeUploadedVideo(ctx, req, fileHeader, videoID, uploadID)
+	switch {
+	case err != nil:
+		s.progressRegistry.Fail(uploadID, err.Error())
+	case resp.Base.Code != 0:
+		s.progressRegistry.Fail(uploadID, resp.Base.Message)
+	default:
+		s.progressRegistry.Complete(uploadID)
+	}
+
+	if resp != nil {
+		resp.UploadID = uploadID
+	}
+	return resp, err
+}
+
+// storeUploadedVideo 验证上传文件并写入存储，写入存储的进度记录到上传ID下
+func (s *VideoService) storeUploadedVideo(ctx context.Context, req *api.VideoUploadRequest, fileHeader *multipart.FileHeader, videoID, uploadID string) (*api.VideoUploadResponse, error) {
 	// 打开上传的文件
 	file, err := fileHeader.Open()
 	if err != nil {
@@ -81,41 +354,183 @@ func (s *VideoService) UploadVideo(ctx context.Context, req *api.VideoUploadRequ
 	}
 	defer file.Close()
 
-	// 读取文件数据进行验证
-	fileData := make([]byte, fileHeader.Size)
-	_, err = file.Read(fileData)
+	// 只读取文件头部用于格式验证和信息提取，避免将整个文件读入内存
+	headData, err := readFileHead(file, videoHeadSize)
 	if err != nil {
 		return s.errorResponse(1002, "读取文件数据失败"), nil
 	}
 
-	// 重置文件指针
-	file.Seek(0, 0)
-
 	// 验证文件大小
-	if err := s.sizeLimitManager.ValidateSize(fileHeader.Size); err != nil {
+	if err := s.validateUploadSize(ctx, fileHeader.Filename, fileHeader.Size); err != nil {
 		return s.errorResponse(1003, fmt.Sprintf("文件大小验证失败: %v", err)), nil
 	}
 
+	// 预占用户配额，元数据保存后释放，期间已用空间由元数据统计
+	reservation, err := s.reserveQuota(ctx, currentUserID(ctx), fileHeader.Size)
+	if errors.Is(err, quota.ErrQuotaExceeded) {
+		return s.errorResponse(1009, err.Error()), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer reservation.Release()
+
+	// 图片和音频使用各自的格式验证和信息提取，其余按视频处理
+	mediaType := s.uploadMediaType(fileHeader.Filename)
+	var videoInfo *video.VideoInfo
+	if mediaType != metadata.MediaTypeVideo {
+		videoInfo, err = s.inspectMediaFile(fileHeader.Filename, headData, file, fileHeader.Size)
+		if err != nil {
+			return s.errorResponse(1004, fmt.Sprintf("文件格式验证失败: %v", err)), nil
+		}
+	} else {
+		var errResp *api.VideoUploadResponse
+		videoInfo, errResp = s.inspectUploadedVideo(fileHeader, file, headData)
+		if errResp != nil {
+			return errResp, nil
+		}
+	}
+
+	// 生成存储路径
+	now := time.Now()
+	bucketName := s.buckets.Bucket(storage.ContentVideos)
+	objectName := fmt.Sprintf("videos/%d/%02d/%s%s",
+		now.Year(), now.Month(), videoID, upload.ObjectExtension(fileHeader.Filename))
+
+	// 开启重复检测时先计算本地文件的校验和，内容相同的视频无需再写入存储
+	checksum := req.Checksum
+	var duplicate *metadata.FileMetadata
+	if s.config.GetDeduplicationMode() != config.DeduplicationOff {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return s.errorResponse(1002, "重置文件读取位置失败"), nil
+		}
+		localChecksum, err := upload.ChecksumReader(file)
+		if err != nil {
+			return s.errorResponse(1002, "读取文件数据失败"), nil
+		}
+		if checksum != "" && checksum != localChecksum {
+			return s.errorResponse(1010, fmt.Sprintf("%v: 期望 %s, 实际 %s", upload.ErrChecksumMismatch, checksum, localChecksum)), nil
+		}
+		checksum = localChecksum
+
+		duplicate = s.findDuplicateVideo(ctx, checksum)
+		if duplicate != nil && s.config.GetDeduplicationMode() == config.DeduplicationReject {
+			return s.duplicateVideoResponse(ctx, duplicate), nil
+		}
+	}
+
+	// 从头开始流式上传文件到存储
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return s.errorResponse(1002, "重置文件读取位置失败"), nil
+	}
+
+	if duplicate != nil {
+		// 共享已存在视频的存储对象，只创建新的视频记录
+		bucketName, objectName = duplicate.BucketName, duplicate.ObjectName
+		s.progressRegistry.Add(uploadID, fileHeader.Size)
+	} else {
+		uploadRequest := &upload.UploadRequest{
+			BucketName:  bucketName,
+			FileName:    fileHeader.Filename,
+			ObjectName:  objectName,
+			Reader:      upload.NewProgressReader(file, s.progressRegistry, uploadID),
+			Size:        fileHeader.Size,
+			ContentType: fileHeader.Header.Get("Content-Type"),
+			Checksum:    checksum,
+			Metadata:    videoObjectLabels(videoID, currentUserID(ctx)),
+			Tags:        videoObjectLabels(videoID, currentUserID(ctx)),
+		}
+
+		uploadResult, err := s.uploadService.UploadFile(ctx, uploadRequest)
+		if errors.Is(err, upload.ErrChecksumMismatch) {
+			return s.errorResponse(1010, err.Error()), nil
+		}
+		if err != nil {
+			return s.errorResponse(1006, fmt.Sprintf("文件上传失败: %v", err)), nil
+		}
+		objectName = uploadResult.ObjectName
+		checksum = uploadResult.Checksum
+
+		// ffprobe要求存在视频流，图片和音频只检查文件结构
+		if mediaType == metadata.MediaTypeVideo {
+			if err := s.probeStoredVideo(ctx, bucketName, objectName); err != nil {
+				s.storageClient.DeleteFile(ctx, bucketName, objectName)
+				return s.errorResponse(1004, fmt.Sprintf("文件结构验证失败: %v", err)), nil
+			}
+		}
+	}
+
+	// 重置读取位置，用于抽帧生成缩略图
+	var videoReader io.Reader
+	if _, err := file.Seek(0, io.SeekStart); err == nil {
+		videoReader = file
+	}
+
+	videoResponse, err := s.finalizeUpload(ctx, &uploadedVideo{
+		VideoID:     videoID,
+		BucketName:  bucketName,
+		ObjectName:  objectName,
+		FileName:    fileHeader.Filename,
+		ContentType: fileHeader.Header.Get("Content-Type"),
+		Size:        fileHeader.Size,
+		Title:       getValueOrDefaultFromString(req.Title, fileHeader.Filename),
+		Description: getValueOrDefaultFromString(req.Description, ""),
+		Info:        videoInfo,
+		MediaType:   mediaType,
+		Checksum:    checksum,
+		HeadData:    headData,
+		Reader:      videoReader,
+		Visibility:  req.Visibility,
+		CreatedBy:   currentUserID(ctx),
+		// 重复的视频共享已有视频的存储对象
+		SharedObject: duplicate != nil,
+	})
+	if err != nil {
+		return s.errorResponse(1013, err.Error()), nil
+	}
+
+	return &api.VideoUploadResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "上传成功",
+		},
+		Video: videoResponse,
+	}, nil
+}
+
+// inspectUploadedVideo 验证上传视频的格式和文件结构并提取视频信息，验证失败时返回错误响应
+func (s *VideoService) inspectUploadedVideo(fileHeader *multipart.FileHeader, file multipart.File, headData []byte) (*video.VideoInfo, *api.VideoUploadResponse) {
+	if !s.videoUploadAllowed() {
+		return nil, s.errorResponse(1005, "不支持的文件格式: 不允许上传视频")
+	}
+
 	// 验证文件格式
 	validationRequest := &video.ValidationRequest{
 		Filename:    fileHeader.Filename,
 		ContentType: fileHeader.Header.Get("Content-Type"),
-		Data:        fileData[:min(len(fileData), 512)], // 只取前512字节用于验证
+		Data:        headData[:min(len(headData), 512)], // 只取前512字节用于验证
 	}
 
-	validationResult, err := s.videoValidator.ValidateFormat(validationRequest)
+	validationResult, err := s.videoValidator.Load().ValidateFormat(validationRequest)
 	if err != nil {
-		return s.errorResponse(1004, fmt.Sprintf("文件格式验证失败: %v", err)), nil
+		return nil, s.errorResponse(1004, fmt.Sprintf("文件格式验证失败: %v", err))
 	}
 
 	if !validationResult.IsValid {
-		return s.errorResponse(1005, fmt.Sprintf("不支持的文件格式: %s", validationResult.ErrorMessage)), nil
+		return nil, s.errorResponse(1005, fmt.Sprintf("不支持的文件格式: %s", validationResult.ErrorMessage))
+	}
+
+	// 文件头可以伪造，保存前检查完整的文件结构
+	if err := s.verifyVideoStructure(file, fileHeader.Size, validationResult.DetectedFormat); err != nil {
+		return nil, s.errorResponse(1004, fmt.Sprintf("文件结构验证失败: %v", err))
 	}
 
 	// 提取视频信息
 	infoRequest := &video.InfoExtractionRequest{
-		Data:     fileData[:min(len(fileData), 1024*1024)], // 取前1MB用于信息提取
+		Data:     headData, // 取文件头部用于信息提取
 		Filename: fileHeader.Filename,
+		Reader:   file, // MP4的moov可能位于文件末尾，需要随机访问完整文件
+		Size:     fileHeader.Size,
 	}
 
 	videoInfo, err := s.videoExtractor.ExtractInfo(infoRequest)
@@ -128,104 +543,196 @@ func (s *VideoService) UploadVideo(ctx context.Context, req *api.VideoUploadRequ
 		}
 	}
 
-	// 生成存储路径
-	now := time.Now()
-	objectName := fmt.Sprintf("videos/%d/%02d/%s%s",
-		now.Year(), now.Month(), videoID, filepath.Ext(fileHeader.Filename))
+	return videoInfo, nil
+}
 
-	// 上传文件到存储
-	uploadRequest := &upload.UploadRequest{
-		BucketName:  "zhulong-videos", // 暂时硬编码，后续从配置获取
-		FileName:    objectName,
-		Reader:      file,
-		Size:        fileHeader.Size,
-		ContentType: fileHeader.Header.Get("Content-Type"),
+// videoObjectLabels 视频文件的用户元数据和对象标签，content-hash由上传服务添加
+// 去重共享的存储对象保留首次上传时的标签
+func videoObjectLabels(videoID, uploader string) map[string]string {
+	labels := map[string]string{storage.ObjectKeyVideoID: videoID}
+	if uploader != "" {
+		labels[storage.ObjectKeyUploader] = uploader
 	}
+	return labels
+}
 
-	_, err = s.uploadService.UploadFile(ctx, uploadRequest)
-	if err != nil {
-		return s.errorResponse(1006, fmt.Sprintf("文件上传失败: %v", err)), nil
+// uploadedVideo 已写入存储的视频，用于生成缩略图和保存元数据
+type uploadedVideo struct {
+	VideoID     string
+	BucketName  string
+	ObjectName  string
+	FileName    string
+	ContentType string
+	Size        int64
+	Title       string
+	Description string
+	Info        *video.VideoInfo
+	MediaType   string    // 媒体类型，为空时为视频
+	Checksum    string    // 文件内容的SHA-256校验和
+	HeadData    []byte    // 文件头部数据
+	Reader      io.Reader // 完整视频读取器（可选），用于抽帧生成缩略图
+	Visibility  string    // 可见性，为空时为私有
+	CreatedBy   string
+	ClipSource  *metadata.ClipSource // 剪辑片段的来源（可选）
+	// SharedObject 视频文件由其他视频共享或由导入登记，保存元数据失败时不删除
+	SharedObject bool
+}
+
+// finalizeUpload 完成上传后处理：生成缩略图、保存元数据并触发HLS打包
+// 保存元数据失败时删除本次写入的视频文件和缩略图并移除审核记录，返回错误，视频库中不会出现不完整的视频
+func (s *VideoService) finalizeUpload(ctx context.Context, uploaded *uploadedVideo) (*api.Video, error) {
+	now := time.Now()
+
+	var compensation uploadCompensation
+	if !uploaded.SharedObject {
+		compensation.add("视频文件上传", func(ctx context.Context) error {
+			return s.storageClient.DeleteFile(ctx, uploaded.BucketName, uploaded.ObjectName)
+		})
 	}
 
-	// 生成缩略图
+	// 生成缩略图，图片和音频没有视频帧，使用缩放的原图或波形图
+	mediaType := getValueOrDefaultFromString(uploaded.MediaType, metadata.MediaTypeVideo)
 	thumbnailPath := ""
-	thumbnailRequest := &video.ThumbnailRequest{
-		VideoData: fileData,
+	thumbnailObjectName := newThumbnailObjectName(uploaded.VideoID, now)
+	var thumbnailErr error
+	if mediaType == metadata.MediaTypeVideo {
+		thumbnailErr = s.uploadThumbnail(ctx, thumbnailObjectName, uploaded.HeadData, uploaded.Reader)
+	} else {
+		thumbnailErr = s.uploadMediaThumbnail(ctx, thumbnailObjectName, uploaded)
+	}
+	if thumbnailErr == nil {
+		thumbnailPath = thumbnailObjectName
+		compensation.add("缩略图上传", func(ctx context.Context) error {
+			return s.storageClient.DeleteFile(ctx, s.buckets.Bucket(storage.ContentThumbnails), thumbnailObjectName)
+		})
+	}
+
+	// 保存元数据
+	metadataRequest := &metadata.FileMetadata{
+		FileID:      uploaded.VideoID,
+		BucketName:  uploaded.BucketName,
+		ObjectName:  uploaded.ObjectName,
+		FileName:    uploaded.FileName,
+		Title:       uploaded.Title,
+		Description: uploaded.Description,
+		ContentType: uploaded.ContentType,
+		FileSize:    uploaded.Size,
+		Duration:    int64(uploaded.Info.Duration.Seconds()),
+		Width:       uploaded.Info.Width,
+		Height:      uploaded.Info.Height,
+		Rotation:    uploaded.Info.Rotation,
+		Bitrate:     uploaded.Info.Bitrate,
+		VideoCodec:  uploaded.Info.VideoCodec,
+		AudioCodec:  uploaded.Info.AudioCodec,
+		FrameRate:   uploaded.Info.FrameRate,
+		Thumbnail:   thumbnailPath,
+		Checksum:    uploaded.Checksum,
+		Chapters:    convertVideoChapters(uploaded.Info.Chapters),
+		Tags:        []string{},
+		Visibility:  uploaded.Visibility,
+		Status:      s.uploadStatus(),
+		MediaType:   mediaType,
+		CreatedBy:   uploaded.CreatedBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		ClipSource:  uploaded.ClipSource,
+	}
+
+	// 图片和音频不需要HLS打包，上传后即可访问
+	if mediaType != metadata.MediaTypeVideo {
+		metadataRequest.Status = metadata.StatusReady
+	}
+
+	// 执行内容审核钩子，被标记或拦截的视频进入审核队列
+	s.moderateVideo(ctx, metadataRequest)
+	if metadataRequest.Moderation != "" {
+		compensation.add("加入审核队列", func(ctx context.Context) error {
+			s.reviews.Remove(uploaded.VideoID)
+			return nil
+		})
+	}
+
+	if err := s.metadataService.SaveMetadata(ctx, metadataRequest); err != nil {
+		compensation.run(ctx)
+		return nil, fmt.Errorf("保存视频信息失败: %w", err)
+	}
+
+	// 上传完成后异步进行HLS打包，打包结束后更新处理状态
+	if metadataRequest.Status == metadata.StatusProcessing {
+		s.scheduleHLSPackaging(metadataRequest, transcode.DefaultPriority)
+	}
+
+	// 异步生成进度条悬停预览图和动态预览
+	s.schedulePreviewGeneration(metadataRequest)
+
+	// 构造响应，更新时间与已保存的元数据保持一致
+	videoResponse := convertToAPIVideo(metadataRequest)
+
+	s.publishEvent(notify.EventUploadCompleted, metadataRequest, videoResponse)
+	if thumbnailPath != "" {
+		s.publishEvent(notify.EventThumbnailReady, metadataRequest, videoResponse)
+	}
+
+	return videoResponse, nil
+}
+
+// newThumbnailObjectName 按上传时间生成缩略图的对象名
+func newThumbnailObjectName(videoID string, now time.Time) string {
+	return fmt.Sprintf("thumbnails/%d/%02d/%s.jpg", now.Year(), now.Month(), videoID)
+}
+
+// uploadThumbnail 从视频中截取缩略图并上传到objectName，reader为空时只使用文件头部
+func (s *VideoService) uploadThumbnail(ctx context.Context, objectName string, headData []byte, reader io.Reader) error {
+	thumbnailResult, err := s.thumbnailGenerator.GenerateFromVideo(&video.ThumbnailRequest{
+		VideoData:   headData,
+		VideoReader: reader,
 		Options: &video.ThumbnailOptions{
-			Width:      320,
-			Height:     240,
+			Width:      thumbnailWidth,
+			Height:     thumbnailHeight,
 			Quality:    80,
 			Format:     "jpeg",
-			TimeOffset: 0.0,
+			TimeOffset: 1.0,
+			KeepAspect: true,
 		},
+	})
+	if err != nil {
+		return err
 	}
+	return s.saveThumbnail(ctx, objectName, thumbnailResult)
+}
 
-	thumbnailResult, err := s.thumbnailGenerator.GenerateFromVideo(thumbnailRequest)
-	if err == nil && thumbnailResult != nil {
-		// 上传缩略图
-		thumbnailObjectName := fmt.Sprintf("thumbnails/%d/%02d/%s.jpg", now.Year(), now.Month(), videoID)
-		thumbnailUploadRequest := &upload.UploadRequest{
-			BucketName:  "zhulong-videos",
-			FileName:    thumbnailObjectName,
-			Reader:      bytes.NewReader(thumbnailResult.ImageData),
-			Size:        thumbnailResult.FileSize,
-			ContentType: "image/jpeg",
-		}
-
-		_, thumbnailUploadErr := s.uploadService.UploadFile(ctx, thumbnailUploadRequest)
-		if thumbnailUploadErr == nil {
-			thumbnailPath = thumbnailObjectName
-		}
+// saveThumbnail 上传生成的缩略图到objectName
+func (s *VideoService) saveThumbnail(ctx context.Context, objectName string, thumbnailResult *video.ThumbnailResult) error {
+	if thumbnailResult == nil {
+		return fmt.Errorf("生成缩略图失败")
 	}
 
-	// 保存元数据
-	metadataRequest := &metadata.FileMetadata{
-		FileID:      videoID,
-		BucketName:  "zhulong-videos",
+	_, err := s.uploadService.UploadFile(ctx, &upload.UploadRequest{
+		BucketName:  s.buckets.Bucket(storage.ContentThumbnails),
+		FileName:    filepath.Base(objectName),
 		ObjectName:  objectName,
-		FileName:    fileHeader.Filename,
-		Title:       getValueOrDefaultFromString(req.Title, fileHeader.Filename),
-		Description: getValueOrDefaultFromString(req.Description, ""),
-		ContentType: fileHeader.Header.Get("Content-Type"),
-		FileSize:    fileHeader.Size,
-		Duration:    int64(videoInfo.Duration.Seconds()),
-		Resolution:  fmt.Sprintf("%dx%d", videoInfo.Width, videoInfo.Height),
-		Thumbnail:   thumbnailPath,
-		Tags:        []string{},
-		CreatedBy:   "system", // 暂时使用system，后续可以从上下文中获取用户信息
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		Reader:      bytes.NewReader(thumbnailResult.ImageData),
+		Size:        thumbnailResult.FileSize,
+		ContentType: "image/jpeg",
+	})
+	return err
+}
+
+// currentUserID 获取当前登录用户ID，未登录时返回system
+func currentUserID(ctx context.Context) string {
+	if claims, ok := user.ClaimsFromContext(ctx); ok {
+		return claims.UserID
 	}
+	return "system"
+}
 
-	err = s.metadataService.SaveMetadata(ctx, metadataRequest)
+// readFileHead 读取文件头部最多limit字节
+func readFileHead(reader io.Reader, limit int64) ([]byte, error) {
+	head, err := io.ReadAll(io.LimitReader(reader, limit))
 	if err != nil {
-		// 元数据保存失败，但不影响上传流程，记录日志即可
-		fmt.Printf("保存元数据失败: %v\n", err)
-	}
-
-	// 构造响应
-	videoResponse := &api.Video{
-		ID:            videoID,
-		Title:         getValueOrDefaultFromString(req.Title, fileHeader.Filename),
-		Filename:      fileHeader.Filename,
-		ContentType:   fileHeader.Header.Get("Content-Type"),
-		Size:          fileHeader.Size,
-		Duration:      int64(videoInfo.Duration.Seconds()),
-		Width:         int32(videoInfo.Width),
-		Height:        int32(videoInfo.Height),
-		StoragePath:   objectName,
-		ThumbnailPath: thumbnailPath,
-		UploadedAt:    time.Now().UnixMilli(),
-		UpdatedAt:     time.Now().UnixMilli(),
+		return nil, fmt.Errorf("读取文件头部失败: %w", err)
 	}
-
-	return &api.VideoUploadResponse{
-		Base: &api.BaseResponse{
-			Code:    0,
-			Message: "上传成功",
-		},
-		Video: videoResponse,
-	}, nil
+	return head, nil
 }
 
 // errorResponse 创建错误响应
@@ -279,23 +786,37 @@ func (s *VideoService) GetVideoList(ctx context.Context, req *api.VideoListReque
 		pageSize = 10
 	}
 
-	// 构建查询参数
+	// 构建查询参数，只列出当前用户可以在列表中看到的视频
+	viewer := currentViewer(ctx)
 	listRequest := &metadata.ListMetadataRequest{
 		Offset: int((page - 1) * pageSize),
 		Limit:  int(pageSize),
 		SortBy: req.SortBy,
-		Order:  "desc", // 默认降序
-	}
-
-	// 根据请求设置排序方向
-	if req.SortBy != "" {
-		// 默认降序，如果没有特别指定
-		listRequest.Order = "desc"
-	}
-
-	// 如果没有指定排序字段，默认按创建时间排序
-	if listRequest.SortBy == "" {
-		listRequest.SortBy = "created_at"
+		Order:  req.SortOrder,
+
+		ContentTypes: splitCommaList(req.ContentType),
+		MinDuration:  req.MinDuration,
+		MaxDuration:  req.MaxDuration,
+		MinWidth:     int(req.MinWidth),
+		MinHeight:    int(req.MinHeight),
+		CreatedBy:    req.CreatedBy,
+		Tags:         splitCommaList(req.Tags),
+		ListedFor:    &viewer,
+		Status:       strings.ToLower(strings.TrimSpace(req.Status)),
+		MediaType:    strings.ToLower(strings.TrimSpace(req.MediaType)),
+	}
+	if req.UploadedAfter > 0 {
+		listRequest.CreatedAfter = time.UnixMilli(req.UploadedAfter)
+	}
+	if req.UploadedBefore > 0 {
+		listRequest.CreatedBefore = time.UnixMilli(req.UploadedBefore)
+	}
+	if req.CollectionID != "" {
+		videoIDs, err := s.collections.ListVideoIDs(ctx, req.CollectionID)
+		if err != nil {
+			return s.videoListErrorResponse(2003, "合集不存在"), nil
+		}
+		listRequest.FileIDs = videoIDs
 	}
 
 	// 查询数据
@@ -307,28 +828,11 @@ func (s *VideoService) GetVideoList(ctx context.Context, req *api.VideoListReque
 	// 转换为API响应格式
 	var videos []*api.Video
 	for _, metadata := range listResponse.Items {
-		video := &api.Video{
-			ID:            metadata.FileID,
-			Title:         metadata.Title,
-			Filename:      metadata.FileName,
-			ContentType:   metadata.ContentType,
-			Size:          metadata.FileSize,
-			Duration:      metadata.Duration,
-			Width:         0, // 从分辨率字符串解析
-			Height:        0, // 从分辨率字符串解析
-			StoragePath:   metadata.ObjectName,
-			ThumbnailPath: metadata.Thumbnail,
-			UploadedAt:    metadata.CreatedAt.UnixMilli(),
-			UpdatedAt:     metadata.UpdatedAt.UnixMilli(),
-		}
-
-		// 解析分辨率
-		if metadata.Resolution != "" {
-			fmt.Sscanf(metadata.Resolution, "%dx%d", &video.Width, &video.Height)
-		}
-
-		videos = append(videos, video)
+		videos = append(videos, convertToAPIVideo(metadata))
 	}
+	s.fillResumePositions(ctx, videos)
+	s.fillViewCounts(ctx, videos)
+	s.fillFavorited(ctx, videos)
 
 	return &api.VideoListResponse{
 		Base: &api.BaseResponse{
@@ -340,6 +844,113 @@ func (s *VideoService) GetVideoList(ctx context.Context, req *api.VideoListReque
 	}, nil
 }
 
+// GetVideoDetail 获取视频详情，登录用户返回续播位置，同时返回缩略图、HLS档位和字幕等衍生文件清单
+func (s *VideoService) GetVideoDetail(ctx context.Context, req *api.VideoDetailRequest) (*api.VideoDetailResponse, error) {
+	if req.VideoID == "" {
+		return s.videoDetailErrorResponse(2101, "视频ID不能为空"), nil
+	}
+
+	meta, err := s.getVisibleMetadata(ctx, req.VideoID)
+	if err != nil {
+		// 直传或分片上传尚未完成的视频还没有记录，返回上传中的状态
+		if video, ok := s.uploadingVideo(ctx, req.VideoID); ok {
+			return &api.VideoDetailResponse{
+				Base: &api.BaseResponse{
+					Code:    0,
+					Message: "获取成功",
+				},
+				Video: video,
+			}, nil
+		}
+		return s.videoDetailErrorResponse(2102, "视频不存在"), nil
+	}
+
+	video := convertToAPIVideo(meta)
+	s.fillResumePositions(ctx, []*api.Video{video})
+	s.fillViewCounts(ctx, []*api.Video{video})
+	s.fillFavorited(ctx, []*api.Video{video})
+
+	// 衍生文件清单只用于展示，无法访问存储时仍返回视频详情
+	assets, err := s.videoAssets(ctx, meta)
+	if err != nil {
+		assets = nil
+	} else if assets == nil {
+		assets = []*api.VideoAsset{}
+	}
+
+	return &api.VideoDetailResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Video:  video,
+		Assets: assets,
+	}, nil
+}
+
+// videoDetailErrorResponse 创建视频详情错误响应
+func (s *VideoService) videoDetailErrorResponse(code int32, message string) *api.VideoDetailResponse {
+	return &api.VideoDetailResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// convertToAPIVideo 将文件元数据转换为API视频模型
+func convertToAPIVideo(meta *metadata.FileMetadata) *api.Video {
+	video := &api.Video{
+		ID:               meta.FileID,
+		Title:            meta.Title,
+		Description:      meta.Description,
+		Tags:             meta.Tags,
+		Filename:         meta.FileName,
+		ContentType:      meta.ContentType,
+		Size:             meta.FileSize,
+		Duration:         meta.Duration,
+		Width:            int32(meta.Width),
+		Height:           int32(meta.Height),
+		StoragePath:      meta.ObjectName,
+		ThumbnailPath:    meta.Thumbnail,
+		PreviewPath:      meta.Preview,
+		Checksum:         meta.Checksum,
+		Chapters:         convertToAPIChapters(meta.Chapters),
+		Archived:         meta.Archived,
+		Visibility:       meta.Visibility,
+		ModerationStatus: meta.Moderation,
+		Status:           meta.Status,
+		MediaType:        getValueOrDefaultFromString(meta.MediaType, metadata.MediaTypeVideo),
+		Rotation:         int32(meta.Rotation),
+		VideoCodec:       meta.VideoCodec,
+		AudioCodec:       meta.AudioCodec,
+		Bitrate:          meta.Bitrate,
+		FrameRate:        meta.FrameRate,
+		Version:          int32(meta.CurrentVersion().Number),
+		UploadedAt:       meta.CreatedAt.UnixMilli(),
+		UpdatedAt:        meta.UpdatedAt.UnixMilli(),
+	}
+	if video.Tags == nil {
+		video.Tags = []string{}
+	}
+	if !meta.PublishAt.IsZero() {
+		video.PublishAt = meta.PublishAt.UnixMilli()
+	}
+	if !meta.ExpiresAt.IsZero() {
+		video.ExpiresAt = meta.ExpiresAt.UnixMilli()
+		video.ExpiryAction, _ = metadata.NormalizeExpiryAction(meta.ExpiryAction)
+	}
+	if meta.ClipSource != nil {
+		video.ClipSource = &api.ClipSource{
+			VideoID:   meta.ClipSource.FileID,
+			StartTime: meta.ClipSource.StartTime,
+			EndTime:   meta.ClipSource.EndTime,
+		}
+	}
+
+	return video
+}
+
 // validateVideoListRequest 验证视频列表请求
 func (s *VideoService) validateVideoListRequest(req *api.VideoListRequest) error {
 	if req.Page < 0 {
@@ -351,9 +962,48 @@ func (s *VideoService) validateVideoListRequest(req *api.VideoListRequest) error
 	if req.PageSize > 100 {
 		return fmt.Errorf("页面大小不能超过100")
 	}
+	if req.MinDuration < 0 || req.MaxDuration < 0 {
+		return fmt.Errorf("时长过滤条件不能为负数")
+	}
+	if req.MaxDuration > 0 && req.MinDuration > req.MaxDuration {
+		return fmt.Errorf("最短时长不能大于最长时长")
+	}
+	if req.MinWidth < 0 || req.MinHeight < 0 {
+		return fmt.Errorf("分辨率过滤条件不能为负数")
+	}
+	if req.UploadedAfter < 0 || req.UploadedBefore < 0 {
+		return fmt.Errorf("上传时间过滤条件不能为负数")
+	}
+	if req.UploadedBefore > 0 && req.UploadedAfter >= req.UploadedBefore {
+		return fmt.Errorf("上传时间下限必须早于上限")
+	}
+	// 排序字段只能从白名单中选择
+	if _, err := metadata.ParseSort(req.SortBy, req.SortOrder); err != nil {
+		return err
+	}
+	// 上传中的视频还没有记录，不能按该状态过滤
+	if strings.TrimSpace(req.Status) != "" {
+		if _, err := metadata.NormalizeStatus(req.Status); err != nil {
+			return err
+		}
+	}
+	if _, err := metadata.NormalizeMediaType(req.MediaType); err != nil {
+		return err
+	}
 	return nil
 }
 
+// splitCommaList 解析逗号分隔的过滤条件，忽略空项
+func splitCommaList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // videoListErrorResponse 创建视频列表错误响应
 func (s *VideoService) videoListErrorResponse(code int32, message string) *api.VideoListResponse {
 	return &api.VideoListResponse{
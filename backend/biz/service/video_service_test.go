@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+func TestNewVideoService(t *testing.T) {
+	t.Run("使用注入的依赖创建服务", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.MinIO.Bucket = "zhulong-videos"
+		cfg.Quota.UserLimit = "0"
+		store, err := storage.NewMemoryStorage(nil)
+		require.NoError(t, err)
+		metadataService := metadata.NewMetadataService()
+		components, err := NewVideoComponents(cfg)
+		require.NoError(t, err)
+
+		service, err := NewVideoService(cfg, store, metadataService, components)
+		require.NoError(t, err)
+		assert.Same(t, cfg, service.Config())
+		assert.Same(t, metadataService, service.metadataService, "应该使用注入的元数据服务")
+		assert.Same(t, components.Validator, service.videoValidator.Load())
+
+		// 注入的存储客户端用于上传
+		uploaded := uploadTestVideo(t, service, "injected.mp4", mp4TestData(1024))
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+		assert.Equal(t, 1, metadataService.Count(context.Background()))
+		assert.NotEmpty(t, testObjectNames(t, store, ""), "视频应该保存到注入的存储客户端")
+	})
+
+	t.Run("缺少依赖", func(t *testing.T) {
+		_, err := NewVideoService(&config.Config{}, nil, metadata.NewMetadataService(), &VideoComponents{})
+		assert.Error(t, err, "存储客户端为空时应该返回错误")
+		store, err := storage.NewMemoryStorage(nil)
+		require.NoError(t, err)
+		_, err = NewVideoService(nil, store, metadata.NewMetadataService(), &VideoComponents{})
+		assert.Error(t, err, "配置为空时应该返回错误")
+	})
+}
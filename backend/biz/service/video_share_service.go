@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/share"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+const (
+	// sharePath 分享访问地址的路径前缀
+	sharePath = "/s"
+	// maxShareExpiry 分享的最长有效期
+	maxShareExpiry = 365 * 24 * time.Hour
+	// sharePlayURLExpiry 访问分享时签发的播放URL有效期，不超过分享的剩余有效期
+	sharePlayURLExpiry = time.Hour
+)
+
+// CreateShare 为视频创建分享链接，不需要账号即可通过分享地址播放
+func (s *VideoService) CreateShare(ctx context.Context, req *api.ShareCreateRequest) (*api.ShareResponse, error) {
+	if req.VideoID == "" {
+		return s.shareCodeResponse(6501, "视频ID不能为空"), nil
+	}
+	if req.ExpireSeconds < 0 || time.Duration(req.ExpireSeconds) > maxShareExpiry/time.Second {
+		return s.shareCodeResponse(6501, fmt.Sprintf("有效期必须在0到%d秒之间", int64(maxShareExpiry/time.Second))), nil
+	}
+
+	if _, err := s.getVisibleMetadata(ctx, req.VideoID); err != nil {
+		return s.shareCodeResponse(6502, "视频不存在"), nil
+	}
+
+	created, err := s.shares.Create(ctx, &share.CreateRequest{
+		VideoID:   req.VideoID,
+		CreatedBy: currentUserID(ctx),
+		Password:  getValueOrDefault(req.Password, ""),
+		MaxViews:  int(req.MaxViews),
+		ExpiresIn: time.Duration(req.ExpireSeconds) * time.Second,
+	})
+	if err != nil {
+		return s.shareCodeResponse(6501, err.Error()), nil
+	}
+
+	resp := s.shareCodeResponse(0, "创建成功")
+	resp.Share = s.convertToAPIShare(created)
+	return resp, nil
+}
+
+// ListShares 列出分享链接，管理员列出全部，其他用户列出自己创建的
+func (s *VideoService) ListShares(ctx context.Context, req *api.ShareListRequest) (*api.ShareListResponse, error) {
+	createdBy := currentUserID(ctx)
+	if isAdmin(ctx) {
+		createdBy = ""
+	}
+	videoID := getValueOrDefault(req.VideoID, "")
+
+	shares := s.shares.List(ctx, createdBy)
+	apiShares := make([]*api.VideoShare, 0, len(shares))
+	for _, item := range shares {
+		if videoID == "" || item.VideoID == videoID {
+			apiShares = append(apiShares, s.convertToAPIShare(item))
+		}
+	}
+
+	return &api.ShareListResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Shares: apiShares,
+	}, nil
+}
+
+// RevokeShare 撤销分享链接，只能撤销自己创建的，管理员可以撤销全部
+func (s *VideoService) RevokeShare(ctx context.Context, req *api.ShareRevokeRequest) (*api.ShareResponse, error) {
+	if req.Token == "" {
+		return s.shareCodeResponse(6501, "分享令牌不能为空"), nil
+	}
+
+	found, err := s.shares.Get(ctx, req.Token)
+	if err != nil {
+		return s.shareErrorResponse(err), nil
+	}
+	if found.CreatedBy != currentUserID(ctx) && !isAdmin(ctx) {
+		return s.shareCodeResponse(6506, "无权撤销其他用户的分享"), nil
+	}
+
+	if err := s.shares.Revoke(ctx, req.Token); err != nil {
+		return s.shareErrorResponse(err), nil
+	}
+
+	resp := s.shareCodeResponse(0, "撤销成功")
+	resp.Share = s.convertToAPIShare(found)
+	resp.Share.Active = false
+	return resp, nil
+}
+
+// ResolveShare 访问分享链接，校验密码、有效期和访问次数后返回视频信息和播放令牌签名的播放URL
+// 播放令牌以分享创建者的身份签发，撤销创建者的播放令牌时一并失效；待审核、审核拒绝和按delete处理到期的视频按不存在处理，
+// 未到定时发布时间的视频只有上传者和管理员可以访问
+func (s *VideoService) ResolveShare(ctx context.Context, req *api.ShareResolveRequest) (*api.ShareResolveResponse, error) {
+	if req.Token == "" {
+		return s.shareResolveErrorResponse(6501, "分享令牌不能为空"), nil
+	}
+	// 在计入访问次数之前检查，避免视频恢复后分享的访问次数已被用完
+	if found, err := s.shares.Get(ctx, req.Token); err == nil && s.shareVideoUnavailable(ctx, found.VideoID) {
+		return s.shareResolveErrorResponse(6502, "视频不存在"), nil
+	}
+
+	resolved, err := s.shares.Resolve(ctx, req.Token, getValueOrDefault(req.Password, ""))
+	if err != nil {
+		resp := s.shareErrorResponse(err)
+		return s.shareResolveErrorResponse(resp.Base.Code, resp.Base.Message), nil
+	}
+
+	meta, err := s.metadataService.GetMetadata(ctx, resolved.VideoID)
+	if err != nil || shareUnavailable(ctx, meta) {
+		return s.shareResolveErrorResponse(6502, "视频不存在"), nil
+	}
+	meta, err = s.restoreArchivedVideo(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	expiry := sharePlayURLExpiry
+	if remaining := time.Until(resolved.ExpiresAt); !resolved.ExpiresAt.IsZero() && remaining < expiry {
+		expiry = remaining
+	}
+	playURL, err := s.signedStreamURL(resolved.CreatedBy, meta.FileID, expiry)
+	if err != nil {
+		return nil, fmt.Errorf("生成播放URL失败: %w", err)
+	}
+
+	playResp := &api.VideoPlayURLResponse{}
+	if err := s.fillPlaybackAssets(ctx, playResp, meta, expiry); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(expiry).UnixMilli()
+	s.views.RecordView(ctx, meta.FileID)
+	return &api.ShareResolveResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Video:        convertToSharedVideo(meta),
+		PlayURL:      &playURL,
+		ExpiresAt:    &expiresAt,
+		ThumbnailURL: playResp.ThumbnailURL,
+		Subtitles:    playResp.Subtitles,
+	}, nil
+}
+
+// shareVideoUnavailable 分享的视频是否不能通过分享链接访问，视频不存在时由访问分享时处理
+func (s *VideoService) shareVideoUnavailable(ctx context.Context, videoID string) bool {
+	meta, err := s.metadataService.GetMetadata(ctx, videoID)
+	return err == nil && shareUnavailable(ctx, meta)
+}
+
+// shareUnavailable 视频是否不能通过分享链接访问：被内容审核限制、已按delete处理到期，或未到定时发布时间且当前用户不是上传者或管理员
+func shareUnavailable(ctx context.Context, meta *metadata.FileMetadata) bool {
+	now := time.Now()
+	return meta.Blocked(now) || (!meta.Published(now) && !meta.VisibleTo(currentViewer(ctx)))
+}
+
+// convertToSharedVideo 转换为分享和嵌入播放器使用的视频信息，不包含存储路径等内部信息
+func convertToSharedVideo(meta *metadata.FileMetadata) *api.SharedVideo {
+	apiVideo := convertToAPIVideo(meta)
+	return &api.SharedVideo{
+		Title:       apiVideo.Title,
+		Description: apiVideo.Description,
+		ContentType: apiVideo.ContentType,
+		Duration:    apiVideo.Duration,
+		Width:       apiVideo.Width,
+		Height:      apiVideo.Height,
+		Chapters:    apiVideo.Chapters,
+	}
+}
+
+// convertToAPIShare 转换为API分享格式
+func (s *VideoService) convertToAPIShare(item *share.Share) *api.VideoShare {
+	apiShare := &api.VideoShare{
+		Token:       item.Token,
+		VideoID:     item.VideoID,
+		URL:         fmt.Sprintf("%s%s/%s", s.playbackBaseURL(), sharePath, url.PathEscape(item.Token)),
+		HasPassword: item.HasPassword(),
+		MaxViews:    int32(item.MaxViews),
+		Views:       int32(item.Views),
+		Active:      !item.Expired(time.Now()) && !item.Exhausted(),
+		CreatedBy:   item.CreatedBy,
+		CreatedAt:   item.CreatedAt.UnixMilli(),
+	}
+	if !item.ExpiresAt.IsZero() {
+		expiresAt := item.ExpiresAt.UnixMilli()
+		apiShare.ExpiresAt = &expiresAt
+	}
+	return apiShare
+}
+
+// isAdmin 当前登录用户是否为管理员
+func isAdmin(ctx context.Context) bool {
+	claims, ok := user.ClaimsFromContext(ctx)
+	return ok && claims.Role == user.RoleAdmin
+}
+
+// shareErrorResponse 根据分享服务返回的错误创建错误响应
+func (s *VideoService) shareErrorResponse(err error) *api.ShareResponse {
+	switch {
+	case errors.Is(err, share.ErrShareNotFound):
+		return s.shareCodeResponse(6502, err.Error())
+	case errors.Is(err, share.ErrPasswordRequired):
+		return s.shareCodeResponse(6503, err.Error())
+	case errors.Is(err, share.ErrWrongPassword):
+		return s.shareCodeResponse(6504, err.Error())
+	case errors.Is(err, share.ErrShareExpired), errors.Is(err, share.ErrViewLimitReached):
+		return s.shareCodeResponse(6505, err.Error())
+	default:
+		return s.shareCodeResponse(6501, err.Error())
+	}
+}
+
+// shareCodeResponse 创建分享响应
+func (s *VideoService) shareCodeResponse(code int32, message string) *api.ShareResponse {
+	return &api.ShareResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// shareResolveErrorResponse 创建访问分享错误响应
+func (s *VideoService) shareResolveErrorResponse(code int32, message string) *api.ShareResolveResponse {
+	return &api.ShareResolveResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/delete"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/moderation"
+	"github.com/manteia/zhulong/pkg/playback"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// createShareTestService 创建用于分享测试的视频服务
+func createShareTestService(t *testing.T) *VideoService {
+	service := createStreamTestService(t)
+	signer, err := playback.NewSigner("test-secret")
+	require.NoError(t, err)
+	service.playbackSigner = signer
+	service.config.Playback.BaseURL = "http://nas.lan:8888"
+	return service
+}
+
+// createTestShare 以指定用户身份为video1创建分享
+func createTestShare(t *testing.T, service *VideoService, ctx context.Context, req *api.ShareCreateRequest) *api.VideoShare {
+	req.VideoID = "video1"
+	resp, err := service.CreateShare(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+	return resp.Share
+}
+
+func TestVideoService_CreateShare(t *testing.T) {
+	ctx := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "alice", Role: user.RoleViewer})
+
+	t.Run("创建分享", func(t *testing.T) {
+		service := createShareTestService(t)
+		created := createTestShare(t, service, ctx, &api.ShareCreateRequest{MaxViews: 5, ExpireSeconds: 3600})
+
+		assert.Equal(t, "http://nas.lan:8888/s/"+created.Token, created.URL)
+		assert.Equal(t, "alice", created.CreatedBy)
+		assert.Equal(t, int32(5), created.MaxViews)
+		assert.NotNil(t, created.ExpiresAt)
+		assert.True(t, created.Active)
+		assert.False(t, created.HasPassword)
+	})
+
+	t.Run("参数错误", func(t *testing.T) {
+		service := createShareTestService(t)
+
+		for _, req := range []*api.ShareCreateRequest{
+			{},
+			{VideoID: "video1", ExpireSeconds: -1},
+			{VideoID: "video1", ExpireSeconds: 366 * 24 * 3600},
+			{VideoID: "video1", MaxViews: -1},
+		} {
+			resp, err := service.CreateShare(ctx, req)
+			require.NoError(t, err)
+			assert.Equal(t, int32(6501), resp.Base.Code, "请求%+v应该被拒绝", req)
+		}
+
+		resp, err := service.CreateShare(ctx, &api.ShareCreateRequest{VideoID: "missing"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6502), resp.Base.Code, "视频不存在时应该返回6502")
+	})
+}
+
+func TestVideoService_ResolveShare(t *testing.T) {
+	ctx := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "alice", Role: user.RoleViewer})
+
+	t.Run("访问分享获取播放URL", func(t *testing.T) {
+		service := createShareTestService(t)
+		created := createTestShare(t, service, ctx, &api.ShareCreateRequest{})
+
+		resp, err := service.ResolveShare(context.Background(), &api.ShareResolveRequest{Token: created.Token})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, "测试视频", resp.Video.Title)
+
+		playURL, err := url.Parse(resp.GetPlayURL())
+		require.NoError(t, err)
+		assert.Equal(t, "/stream/video1", playURL.Path)
+		claims, err := service.playbackSigner.Verify(playURL.Query().Get("token"), "video1")
+		require.NoError(t, err, "播放URL应该使用有效的播放令牌")
+		assert.Equal(t, "alice", claims.UserID, "播放令牌应该以分享创建者的身份签发")
+
+		stream, err := service.StreamSignedVideo(context.Background(), "video1", playURL.Query().Get("token"), "")
+		require.NoError(t, err)
+		require.Equal(t, int32(0), stream.Base.Code, stream.Base.Message)
+		assert.Equal(t, "0123456789", readStream(t, stream))
+	})
+
+	t.Run("密码和访问次数", func(t *testing.T) {
+		service := createShareTestService(t)
+		password := "secret"
+		created := createTestShare(t, service, ctx, &api.ShareCreateRequest{Password: &password, MaxViews: 1})
+		assert.True(t, created.HasPassword)
+
+		resp, err := service.ResolveShare(context.Background(), &api.ShareResolveRequest{Token: created.Token})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6503), resp.Base.Code, "未提供密码时应该返回6503")
+
+		wrong := "wrong"
+		resp, err = service.ResolveShare(context.Background(), &api.ShareResolveRequest{Token: created.Token, Password: &wrong})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6504), resp.Base.Code, "密码错误时应该返回6504")
+
+		resp, err = service.ResolveShare(context.Background(), &api.ShareResolveRequest{Token: created.Token, Password: &password})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+		resp, err = service.ResolveShare(context.Background(), &api.ShareResolveRequest{Token: created.Token, Password: &password})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6505), resp.Base.Code, "访问次数用完后应该返回6505")
+	})
+
+	t.Run("审核限制后分享失效", func(t *testing.T) {
+		service := createShareTestService(t)
+		service.reviews = moderation.NewReviewQueue()
+		created := createTestShare(t, service, ctx, &api.ShareCreateRequest{MaxViews: 1})
+
+		// 钩子标记后视频待审核
+		_, err := service.reviews.Add("video1", &moderation.Decision{Verdict: moderation.VerdictFlagged, Reasons: []string{"hook: 疑似违规"}})
+		require.NoError(t, err)
+		pending := metadata.ModerationPending
+		require.NoError(t, service.metadataService.UpdateMetadata(context.Background(), &metadata.UpdateMetadataRequest{FileID: "video1", Moderation: &pending}))
+
+		resp, err := service.ResolveShare(context.Background(), &api.ShareResolveRequest{Token: created.Token})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6502), resp.Base.Code, "待审核的视频不能通过分享播放")
+		assert.Nil(t, resp.PlayURL)
+
+		adminCtx := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "admin", Role: user.RoleAdmin})
+		reviewed, err := service.ReviewModeration(adminCtx, &api.ModerationReviewRequest{VideoID: "video1", Action: "reject"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), reviewed.Base.Code, reviewed.Base.Message)
+
+		resp, err = service.ResolveShare(context.Background(), &api.ShareResolveRequest{Token: created.Token})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6502), resp.Base.Code, "审核拒绝的视频不能通过分享播放")
+
+		found, err := service.shares.Get(context.Background(), created.Token)
+		require.NoError(t, err)
+		assert.Zero(t, found.Views, "被拒绝的访问不应该计入访问次数")
+	})
+
+	t.Run("按delete处理到期后分享失效", func(t *testing.T) {
+		service := createShareTestService(t)
+		created := createTestShare(t, service, ctx, &api.ShareCreateRequest{})
+
+		expired := time.Now().Add(-time.Minute)
+		require.NoError(t, service.metadataService.UpdateMetadata(context.Background(), &metadata.UpdateMetadataRequest{
+			FileID:       "video1",
+			ExpiresAt:    &expired,
+			ExpiryAction: stringPtr(metadata.ExpiryDelete),
+		}))
+
+		resp, err := service.ResolveShare(context.Background(), &api.ShareResolveRequest{Token: created.Token})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6502), resp.Base.Code)
+	})
+
+	t.Run("定时发布前分享不可访问", func(t *testing.T) {
+		service := createShareTestService(t)
+		created := createTestShare(t, service, ctx, &api.ShareCreateRequest{MaxViews: 1})
+
+		publishAt := time.Now().Add(time.Hour)
+		require.NoError(t, service.metadataService.UpdateMetadata(context.Background(), &metadata.UpdateMetadataRequest{
+			FileID:    "video1",
+			PublishAt: &publishAt,
+		}))
+
+		resp, err := service.ResolveShare(context.Background(), &api.ShareResolveRequest{Token: created.Token})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6502), resp.Base.Code, "未到发布时间的视频不能通过分享播放")
+		assert.Nil(t, resp.PlayURL)
+
+		found, err := service.shares.Get(context.Background(), created.Token)
+		require.NoError(t, err)
+		assert.Zero(t, found.Views, "被拒绝的访问不应该计入访问次数")
+
+		// 上传者可以在发布前通过分享链接预览
+		owner := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "system", Role: user.RoleUploader})
+		resp, err = service.ResolveShare(owner, &api.ShareResolveRequest{Token: created.Token})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+	})
+
+	t.Run("删除视频后分享失效", func(t *testing.T) {
+		service := createShareTestService(t)
+		service.deleteService = delete.NewDeleteService(service.storageClient)
+		created := createTestShare(t, service, ctx, &api.ShareCreateRequest{})
+
+		adminCtx := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "admin", Role: user.RoleAdmin})
+		deleted, err := service.DeleteVideo(adminCtx, &api.VideoDeleteRequest{VideoID: "video1"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), deleted.Base.Code, deleted.Base.Message)
+
+		resp, err := service.ResolveShare(context.Background(), &api.ShareResolveRequest{Token: created.Token})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6502), resp.Base.Code)
+	})
+}
+
+func TestVideoService_ListAndRevokeShares(t *testing.T) {
+	alice := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "alice", Role: user.RoleViewer})
+	bob := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "bob", Role: user.RoleViewer})
+	admin := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "root", Role: user.RoleAdmin})
+
+	service := createShareTestService(t)
+	aliceShare := createTestShare(t, service, alice, &api.ShareCreateRequest{})
+	createTestShare(t, service, bob, &api.ShareCreateRequest{})
+
+	listed, err := service.ListShares(alice, &api.ShareListRequest{})
+	require.NoError(t, err)
+	require.Len(t, listed.Shares, 1, "普通用户只能看到自己创建的分享")
+	assert.Equal(t, aliceShare.Token, listed.Shares[0].Token)
+
+	listed, err = service.ListShares(admin, &api.ShareListRequest{})
+	require.NoError(t, err)
+	assert.Len(t, listed.Shares, 2, "管理员应该看到全部分享")
+
+	other := "video2"
+	listed, err = service.ListShares(admin, &api.ShareListRequest{VideoID: &other})
+	require.NoError(t, err)
+	assert.Empty(t, listed.Shares, "应该按视频过滤")
+
+	resp, err := service.RevokeShare(bob, &api.ShareRevokeRequest{Token: aliceShare.Token})
+	require.NoError(t, err)
+	assert.Equal(t, int32(6506), resp.Base.Code, "不能撤销其他用户的分享")
+
+	resp, err = service.RevokeShare(alice, &api.ShareRevokeRequest{Token: aliceShare.Token})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+	assert.False(t, resp.Share.Active)
+
+	resp, err = service.RevokeShare(admin, &api.ShareRevokeRequest{Token: aliceShare.Token})
+	require.NoError(t, err)
+	assert.Equal(t, int32(6502), resp.Base.Code, "已撤销的分享应该不存在")
+
+	resolved, err := service.ResolveShare(context.Background(), &api.ShareResolveRequest{Token: aliceShare.Token})
+	require.NoError(t, err)
+	assert.Equal(t, int32(6502), resolved.Base.Code)
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/notify"
+	"github.com/manteia/zhulong/pkg/transcode"
+)
+
+// uploadStatus 上传完成时视频的处理状态，上传后自动进行HLS打包时先处于处理中
+func (s *VideoService) uploadStatus() string {
+	if s.config.Streaming.PackageOnUpload && s.hlsPackagingAvailable() {
+		return metadata.StatusProcessing
+	}
+	return metadata.StatusReady
+}
+
+// handleJobFinished 转码任务结束后更新视频的处理状态
+// HLS打包成功时视频就绪；处理中的视频打包进入死信状态或被取消时标记为失败，已就绪的视频按需打包失败时不改变状态
+func (s *VideoService) handleJobFinished(job transcode.Job) {
+	if job.Kind != transcode.KindHLS {
+		return
+	}
+	switch job.Status {
+	case transcode.StatusSucceeded:
+		s.transitionStatus(job.VideoID, []string{metadata.StatusProcessing, metadata.StatusFailed}, metadata.StatusReady)
+	case transcode.StatusDead, transcode.StatusCanceled:
+		s.transitionStatus(job.VideoID, []string{metadata.StatusProcessing}, metadata.StatusFailed)
+	}
+}
+
+// resumeProcessing 为处理失败的视频重新创建或排队HLS打包任务后，视频恢复为处理中
+func (s *VideoService) resumeProcessing(job transcode.Job) {
+	if job.Kind == transcode.KindHLS {
+		s.transitionStatus(job.VideoID, []string{metadata.StatusFailed}, metadata.StatusProcessing)
+	}
+}
+
+// transitionStatus 视频处于from之一时改为to并发布状态变化事件，视频已删除时忽略
+func (s *VideoService) transitionStatus(videoID string, from []string, to string) {
+	ctx := context.Background()
+	changed, err := s.metadataService.TransitionStatus(ctx, videoID, from, to)
+	if err != nil || !changed {
+		return
+	}
+	if meta, err := s.metadataService.GetMetadata(ctx, videoID); err == nil {
+		s.publishEvent(notify.EventStatusChanged, meta, convertToAPIVideo(meta))
+	}
+}
+
+// uploadingVideo 当前用户进行中的直传或分片上传对应的视频，只有发起上传的用户和管理员可以查看
+func (s *VideoService) uploadingVideo(ctx context.Context, videoID string) (*api.Video, bool) {
+	if s.directUploads == nil || s.multipartUploads == nil {
+		return nil, false
+	}
+
+	video := &api.Video{ID: videoID, Tags: []string{}, Status: metadata.StatusUploading}
+	var createdBy string
+	if session, ok := s.directUploads.FindByVideoID(videoID); ok {
+		createdBy = session.CreatedBy
+		video.Title = session.Title
+		video.Description = session.Description
+		video.Filename = session.FileName
+		video.ContentType = session.ContentType
+		video.Size = session.Size
+		video.Visibility = session.Visibility
+	} else if session, ok := s.multipartUploads.FindByVideoID(videoID); ok {
+		createdBy = session.CreatedBy
+		video.Title = session.Title
+		video.Description = session.Description
+		video.Filename = session.FileName
+		video.ContentType = session.ContentType
+		video.Size = session.TotalSize
+		video.Visibility = session.Visibility
+	} else {
+		return nil, false
+	}
+
+	viewer := currentViewer(ctx)
+	if !viewer.Admin && (viewer.UserID == "" || viewer.UserID != createdBy) {
+		return nil, false
+	}
+	return video, true
+}
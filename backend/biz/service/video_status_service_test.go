@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/notify"
+	"github.com/manteia/zhulong/pkg/transcode"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// waitForVideoStatus 等待视频进入指定处理状态
+func waitForVideoStatus(t *testing.T, service *VideoService, videoID, status string) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		meta, err := service.metadataService.GetMetadata(context.Background(), videoID)
+		return err == nil && meta.Status == status
+	}, 2*time.Second, 5*time.Millisecond, "视频应该进入%s状态", status)
+}
+
+// TestVideoService_ProcessingStatus 测试HLS打包结果驱动的处理状态
+func TestVideoService_ProcessingStatus(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("未自动打包时上传后直接就绪", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+		resp := uploadTestVideo(t, service, "ready.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, metadata.StatusReady, resp.Video.Status)
+	})
+
+	t.Run("打包成功、失败和重新排队", func(t *testing.T) {
+		service := createStreamTestService(t)
+		fail := make(chan bool, 4)
+		service.transcodeQueue = transcode.NewQueue(func(ctx context.Context, job transcode.Job) error {
+			if <-fail {
+				return errors.New("FFmpeg执行失败")
+			}
+			return nil
+		}, 1, 1)
+		service.transcodeQueue.SetFinishHandler(service.handleJobFinished)
+		t.Cleanup(service.transcodeQueue.Close)
+		events := service.SubscribeNotifications(claimsContext("admin-1", user.RoleAdmin))
+		defer events.Close()
+
+		require.NoError(t, service.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{
+			FileID: "video1",
+			Status: stringPtr(metadata.StatusProcessing),
+		}))
+
+		fail <- true
+		job, err := service.transcodeQueue.Enqueue(transcode.KindHLS, "video1", transcode.DefaultPriority)
+		require.NoError(t, err)
+		waitForVideoStatus(t, service, "video1", metadata.StatusFailed)
+
+		select {
+		case event := <-events.C:
+			assert.Equal(t, notify.EventStatusChanged, event.Type)
+			assert.Equal(t, metadata.StatusFailed, event.Data.(*api.Video).Status)
+		case <-time.After(2 * time.Second):
+			t.Fatal("处理状态变化时应该发布事件")
+		}
+
+		listResp, err := service.GetVideoList(ctx, &api.VideoListRequest{Status: metadata.StatusFailed})
+		require.NoError(t, err)
+		require.Len(t, listResp.Videos, 1)
+		assert.Equal(t, metadata.StatusFailed, listResp.Videos[0].Status)
+
+		// 重新排队后恢复为处理中，打包成功后就绪
+		fail <- false
+		resp, err := service.RequeueTranscodeJob(ctx, &api.TranscodeJobRequest{JobID: job.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		waitForVideoStatus(t, service, "video1", metadata.StatusReady)
+
+		// 已就绪的视频按需打包失败时不改变状态
+		fail <- true
+		job, err = service.transcodeQueue.Enqueue(transcode.KindHLS, "video1", transcode.DefaultPriority)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			finished, err := service.transcodeQueue.Get(job.ID)
+			return err == nil && finished.Status == transcode.StatusDead
+		}, 2*time.Second, 5*time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		meta, err := service.metadataService.GetMetadata(ctx, "video1")
+		require.NoError(t, err)
+		assert.Equal(t, metadata.StatusReady, meta.Status)
+	})
+
+	t.Run("无效的状态过滤", func(t *testing.T) {
+		service := createStreamTestService(t)
+		for _, status := range []string{metadata.StatusUploading, "done"} {
+			resp, err := service.GetVideoList(ctx, &api.VideoListRequest{Status: status})
+			require.NoError(t, err)
+			assert.Equal(t, int32(2001), resp.Base.Code, status)
+		}
+	})
+}
+
+// TestVideoService_UploadingVideo 测试直传完成前通过视频详情查询上传中的视频
+func TestVideoService_UploadingVideo(t *testing.T) {
+	service, store := createDirectUploadTestService(t)
+	aliceCtx := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "alice", Role: user.RoleUploader})
+	bobCtx := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "bob", Role: user.RoleUploader})
+	adminCtx := user.ContextWithClaims(context.Background(), &user.Claims{UserID: "admin", Role: user.RoleAdmin})
+
+	urlResp, err := service.CreateUploadURL(aliceCtx, &api.VideoUploadURLRequest{Filename: "direct.mp4", Size: 2048, Title: "直传视频"})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), urlResp.Base.Code, urlResp.Base.Message)
+
+	for _, ctx := range []context.Context{aliceCtx, adminCtx} {
+		resp, err := service.GetVideoDetail(ctx, &api.VideoDetailRequest{VideoID: urlResp.VideoID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code)
+		assert.Equal(t, metadata.StatusUploading, resp.Video.Status)
+		assert.Equal(t, "直传视频", resp.Video.Title)
+		assert.Equal(t, int64(2048), resp.Video.Size)
+	}
+
+	resp, err := service.GetVideoDetail(bobCtx, &api.VideoDetailRequest{VideoID: urlResp.VideoID})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2102), resp.Base.Code, "其他用户不能查看上传中的视频")
+
+	// 确认上传后返回视频记录的状态
+	session, err := service.directUploads.GetSession(urlResp.UploadToken)
+	require.NoError(t, err)
+	putTestObject(t, store, session.ObjectName, mp4TestData(2048))
+	confirmResp, err := service.ConfirmUpload(aliceCtx, &api.VideoUploadConfirmRequest{UploadToken: urlResp.UploadToken})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), confirmResp.Base.Code, confirmResp.Base.Message)
+
+	resp, err = service.GetVideoDetail(aliceCtx, &api.VideoDetailRequest{VideoID: urlResp.VideoID})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), resp.Base.Code)
+	assert.Equal(t, metadata.StatusReady, resp.Video.Status)
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/download"
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+// VideoStream 视频流，Base.Code为0时Body有效，由调用方负责关闭
+type VideoStream struct {
+	Base         *api.BaseResponse
+	Body         io.ReadCloser       // 视频内容读取流
+	ContentType  string              // 内容类型
+	Size         int64               // 文件总大小
+	Range        *download.ByteRange // 请求的字节范围，为nil时返回完整文件
+	ETag         string              // 文件ETag
+	LastModified time.Time           // 最后修改时间
+}
+
+// ContentLength 获取响应内容长度
+func (v *VideoStream) ContentLength() int64 {
+	if v.Range != nil {
+		return v.Range.Length()
+	}
+	return v.Size
+}
+
+// StreamVideo 打开视频流，根据Range请求头返回部分内容
+func (s *VideoService) StreamVideo(ctx context.Context, req *api.VideoStreamRequest, rangeHeader string) (*VideoStream, error) {
+	if req.VideoID == "" {
+		return s.streamErrorResponse(6101, "视频ID不能为空"), nil
+	}
+
+	meta, err := s.getVisibleMetadata(ctx, req.VideoID)
+	if err != nil {
+		return s.streamErrorResponse(6102, "视频不存在"), nil
+	}
+	return s.openVideoStream(ctx, meta, rangeHeader)
+}
+
+// openVideoStream 打开视频文件流，调用方负责检查视频的访问权限
+func (s *VideoService) openVideoStream(ctx context.Context, meta *metadata.FileMetadata, rangeHeader string) (*VideoStream, error) {
+	meta, err := s.restoreArchivedVideo(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	fileInfo, err := s.storageClient.GetFileInfo(ctx, meta.BucketName, meta.ObjectName)
+	if err != nil {
+		return nil, fmt.Errorf("获取视频文件信息失败: %w", err)
+	}
+
+	byteRange, err := download.ParseRange(rangeHeader, fileInfo.Size)
+	if errors.Is(err, download.ErrRangeNotSatisfiable) {
+		resp := s.streamErrorResponse(6103, "请求的范围无法满足")
+		resp.Size = fileInfo.Size
+		return resp, nil
+	}
+
+	offset, length := int64(0), int64(-1)
+	if byteRange != nil {
+		offset, length = byteRange.Start, byteRange.Length()
+	}
+	body, err := s.storageClient.OpenFileRange(ctx, meta.BucketName, meta.ObjectName, offset, length)
+	if err != nil {
+		return nil, fmt.Errorf("打开视频流失败: %w", err)
+	}
+
+	// 从头开始的请求视为开始播放，拖动进度产生的后续Range请求不计数
+	if byteRange == nil || byteRange.Start == 0 {
+		s.views.RecordView(ctx, meta.FileID)
+	}
+
+	contentType := meta.ContentType
+	if contentType == "" {
+		contentType = fileInfo.ContentType
+	}
+
+	return &VideoStream{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Body:         body,
+		ContentType:  contentType,
+		Size:         fileInfo.Size,
+		Range:        byteRange,
+		ETag:         fileInfo.ETag,
+		LastModified: fileInfo.LastModified,
+	}, nil
+}
+
+// streamErrorResponse 创建视频流错误响应
+func (s *VideoService) streamErrorResponse(code int32, message string) *VideoStream {
+	return &VideoStream{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
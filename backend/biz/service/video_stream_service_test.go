@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+// createStreamTestService 创建带有测试视频的服务
+func createStreamTestService(t *testing.T) *VideoService {
+	service, store := createDirectUploadTestService(t)
+	putTestObject(t, store, "videos/2025/08/video1.mp4", []byte("0123456789"))
+
+	err := service.metadataService.SaveMetadata(context.Background(), &metadata.FileMetadata{
+		FileID:      "video1",
+		BucketName:  "zhulong-videos",
+		ObjectName:  "videos/2025/08/video1.mp4",
+		FileName:    "video1.mp4",
+		ContentType: "video/mp4",
+		Title:       "测试视频",
+		Visibility:  metadata.VisibilityPublic,
+		CreatedBy:   "system",
+	})
+	require.NoError(t, err)
+	return service
+}
+
+// readStream 读取并关闭视频流
+func readStream(t *testing.T, stream *VideoStream) string {
+	defer stream.Body.Close()
+	data, err := io.ReadAll(stream.Body)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestVideoService_StreamVideo(t *testing.T) {
+	ctx := context.Background()
+	req := &api.VideoStreamRequest{VideoID: "video1"}
+
+	t.Run("获取完整视频流", func(t *testing.T) {
+		service := createStreamTestService(t)
+
+		stream, err := service.StreamVideo(ctx, req, "")
+		require.NoError(t, err)
+		require.Equal(t, int32(0), stream.Base.Code, stream.Base.Message)
+		assert.Nil(t, stream.Range, "未携带Range时应该返回完整文件")
+		assert.Equal(t, int64(10), stream.ContentLength())
+		assert.Equal(t, "video/mp4", stream.ContentType)
+		assert.Equal(t, "0123456789", readStream(t, stream))
+	})
+
+	t.Run("获取部分视频流", func(t *testing.T) {
+		service := createStreamTestService(t)
+
+		stream, err := service.StreamVideo(ctx, req, "bytes=2-5")
+		require.NoError(t, err)
+		require.Equal(t, int32(0), stream.Base.Code, stream.Base.Message)
+		require.NotNil(t, stream.Range)
+		assert.Equal(t, int64(4), stream.ContentLength())
+		assert.Equal(t, "bytes 2-5/10", stream.Range.ContentRange(stream.Size))
+		assert.Equal(t, "2345", readStream(t, stream))
+
+		stream, err = service.StreamVideo(ctx, req, "bytes=-3")
+		require.NoError(t, err)
+		assert.Equal(t, "789", readStream(t, stream), "后缀范围应该返回文件末尾")
+	})
+
+	t.Run("范围无法满足", func(t *testing.T) {
+		service := createStreamTestService(t)
+
+		stream, err := service.StreamVideo(ctx, req, "bytes=100-")
+		require.NoError(t, err)
+		assert.Equal(t, int32(6103), stream.Base.Code)
+		assert.Equal(t, int64(10), stream.Size, "应该返回文件大小用于Content-Range")
+		assert.Nil(t, stream.Body)
+	})
+
+	t.Run("视频不存在", func(t *testing.T) {
+		service := createStreamTestService(t)
+
+		stream, err := service.StreamVideo(ctx, &api.VideoStreamRequest{VideoID: "not-exist"}, "")
+		require.NoError(t, err)
+		assert.Equal(t, int32(6102), stream.Base.Code)
+
+		stream, err = service.StreamVideo(ctx, &api.VideoStreamRequest{}, "")
+		require.NoError(t, err)
+		assert.Equal(t, int32(6101), stream.Base.Code)
+	})
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+// 标签限制
+const (
+	maxTagLength      = 50  // 单个标签的最大长度（按字符计）
+	maxTagsPerRequest = 100 // 单次请求的最大标签数量
+)
+
+// AddVideoTags 为视频添加标签，已存在的标签会被忽略
+func (s *VideoService) AddVideoTags(ctx context.Context, req *api.VideoTagsRequest) (*api.VideoTagsResponse, error) {
+	return s.modifyVideoTags(ctx, req, s.metadataService.AddTags, "添加标签成功")
+}
+
+// RemoveVideoTags 移除视频的标签，不存在的标签会被忽略
+func (s *VideoService) RemoveVideoTags(ctx context.Context, req *api.VideoTagsRequest) (*api.VideoTagsResponse, error) {
+	return s.modifyVideoTags(ctx, req, s.metadataService.RemoveTags, "移除标签成功")
+}
+
+// ListTags 列出所有标签及使用该标签的视频数量
+func (s *VideoService) ListTags(ctx context.Context) (*api.TagListResponse, error) {
+	tagCounts := s.metadataService.ListTags(ctx)
+
+	tags := make([]*api.TagCount, 0, len(tagCounts))
+	for _, tagCount := range tagCounts {
+		tags = append(tags, &api.TagCount{
+			Tag:   tagCount.Tag,
+			Count: int32(tagCount.Count),
+		})
+	}
+
+	return &api.TagListResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "获取成功",
+		},
+		Tags: tags,
+	}, nil
+}
+
+// modifyVideoTags 验证请求并通过modify修改视频标签，返回修改后的视频
+func (s *VideoService) modifyVideoTags(ctx context.Context, req *api.VideoTagsRequest, modify func(context.Context, string, []string) error, message string) (*api.VideoTagsResponse, error) {
+	if err := validateVideoTagsRequest(req); err != nil {
+		return s.videoTagsErrorResponse(4101, err.Error()), nil
+	}
+
+	meta, err := s.metadataService.GetMetadata(ctx, req.VideoID)
+	if err != nil {
+		return s.videoTagsErrorResponse(4102, "视频不存在"), nil
+	}
+	if !canModifyVideo(ctx, meta) {
+		return s.videoTagsErrorResponse(4103, "无权修改其他用户的视频"), nil
+	}
+
+	if err := modify(ctx, req.VideoID, req.Tags); err != nil {
+		return nil, fmt.Errorf("修改视频标签失败: %w", err)
+	}
+
+	updated, err := s.metadataService.GetMetadata(ctx, req.VideoID)
+	if err != nil {
+		return nil, fmt.Errorf("获取视频元数据失败: %w", err)
+	}
+
+	return &api.VideoTagsResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: message,
+		},
+		Video: convertToAPIVideo(updated),
+	}, nil
+}
+
+// validateVideoTagsRequest 验证视频标签修改请求
+func validateVideoTagsRequest(req *api.VideoTagsRequest) error {
+	if req.VideoID == "" {
+		return fmt.Errorf("视频ID不能为空")
+	}
+	if len(req.Tags) > maxTagsPerRequest {
+		return fmt.Errorf("单次最多修改%d个标签", maxTagsPerRequest)
+	}
+
+	valid := 0
+	for _, tag := range req.Tags {
+		tag = metadata.NormalizeTag(tag)
+		if tag == "" {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return fmt.Errorf("标签长度不能超过%d个字符", maxTagLength)
+		}
+		valid++
+	}
+	if valid == 0 {
+		return fmt.Errorf("标签不能为空")
+	}
+	return nil
+}
+
+// videoTagsErrorResponse 创建视频标签错误响应
+func (s *VideoService) videoTagsErrorResponse(code int32, message string) *api.VideoTagsResponse {
+	return &api.VideoTagsResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+// createTagTestService 创建带标签测试视频的视频服务
+func createTagTestService(t *testing.T) *VideoService {
+	service := createTestVideoService(t)
+	for _, item := range []*metadata.FileMetadata{
+		{FileID: "video1", Title: "视频1", Tags: []string{"旅行", "风景"}, Visibility: metadata.VisibilityPublic, CreatedBy: "system"},
+		{FileID: "video2", Title: "视频2", Tags: []string{"旅行"}, Visibility: metadata.VisibilityPublic, CreatedBy: "system"},
+	} {
+		require.NoError(t, service.metadataService.SaveMetadata(context.Background(), item))
+	}
+	return service
+}
+
+func TestVideoService_VideoTags(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("添加标签", func(t *testing.T) {
+		service := createTagTestService(t)
+
+		resp, err := service.AddVideoTags(ctx, &api.VideoTagsRequest{
+			VideoID: "video2",
+			Tags:    []string{"  Vlog ", "旅行", ""},
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.ElementsMatch(t, []string{"旅行", "vlog"}, resp.Video.Tags, "标签应该规范化并去重")
+	})
+
+	t.Run("移除标签", func(t *testing.T) {
+		service := createTagTestService(t)
+
+		resp, err := service.RemoveVideoTags(ctx, &api.VideoTagsRequest{
+			VideoID: "video1",
+			Tags:    []string{"风景", "不存在"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, []string{"旅行"}, resp.Video.Tags)
+	})
+
+	t.Run("标签统计和过滤", func(t *testing.T) {
+		service := createTagTestService(t)
+
+		tagsResp, err := service.ListTags(ctx)
+		require.NoError(t, err)
+		require.Len(t, tagsResp.Tags, 2)
+		assert.Equal(t, &api.TagCount{Tag: "旅行", Count: 2}, tagsResp.Tags[0])
+		assert.Equal(t, &api.TagCount{Tag: "风景", Count: 1}, tagsResp.Tags[1])
+
+		listResp, err := service.GetVideoList(ctx, &api.VideoListRequest{Tags: "旅行, 风景"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), listResp.Base.Code, listResp.Base.Message)
+		require.Len(t, listResp.Videos, 1)
+		assert.Equal(t, "video1", listResp.Videos[0].ID)
+	})
+
+	t.Run("修改标签_参数错误", func(t *testing.T) {
+		service := createTagTestService(t)
+
+		invalid := []*api.VideoTagsRequest{
+			{Tags: []string{"标签"}},
+			{VideoID: "video1"},
+			{VideoID: "video1", Tags: []string{" ", ""}},
+			{VideoID: "video1", Tags: []string{strings.Repeat("长", 51)}},
+		}
+		for _, req := range invalid {
+			resp, err := service.AddVideoTags(ctx, req)
+			require.NoError(t, err)
+			assert.Equal(t, int32(4101), resp.Base.Code, "无效请求应该返回参数错误: %+v", req)
+		}
+	})
+
+	t.Run("修改标签_视频不存在", func(t *testing.T) {
+		service := createTagTestService(t)
+
+		resp, err := service.RemoveVideoTags(ctx, &api.VideoTagsRequest{VideoID: "missing", Tags: []string{"旅行"}})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4102), resp.Base.Code)
+	})
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+// 视频信息长度限制（按字符计）
+const (
+	maxVideoTitleLength       = 255
+	maxVideoDescriptionLength = 1000
+)
+
+// UpdateVideo 更新视频标题、描述、标签、可见性、定时发布和到期时间
+// 请求携带updated_at时进行乐观并发控制，记录已被修改则返回4003
+func (s *VideoService) UpdateVideo(ctx context.Context, req *api.VideoUpdateRequest) (*api.VideoUpdateResponse, error) {
+	if err := s.validateVideoUpdateRequest(req); err != nil {
+		return s.videoUpdateErrorResponse(4001, err.Error()), nil
+	}
+
+	meta, err := s.metadataService.GetMetadata(ctx, req.VideoID)
+	if err != nil {
+		return s.videoUpdateErrorResponse(4002, "视频不存在"), nil
+	}
+	if !canModifyVideo(ctx, meta) {
+		return s.videoUpdateErrorResponse(4004, "无权修改其他用户的视频"), nil
+	}
+
+	publishAt, expiresAt := meta.PublishAt, meta.ExpiresAt
+	if req.PublishAt != nil {
+		publishAt = unixMilliOrZero(*req.PublishAt)
+	}
+	if req.ExpiresAt != nil {
+		expiresAt = unixMilliOrZero(*req.ExpiresAt)
+	}
+	if !publishAt.IsZero() && !expiresAt.IsZero() && !expiresAt.After(publishAt) {
+		return s.videoUpdateErrorResponse(4001, "到期时间必须晚于发布时间"), nil
+	}
+
+	updateRequest := &metadata.UpdateMetadataRequest{
+		FileID:      req.VideoID,
+		Description: req.Description,
+	}
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		updateRequest.Title = &title
+	}
+	if req.Tags != nil {
+		tags := normalizeTags(req.Tags)
+		updateRequest.Tags = &tags
+	}
+	if req.Visibility != nil {
+		visibility, _ := metadata.NormalizeVisibility(*req.Visibility)
+		updateRequest.Visibility = &visibility
+	}
+	if req.PublishAt != nil {
+		updateRequest.PublishAt = &publishAt
+	}
+	if req.ExpiresAt != nil {
+		updateRequest.ExpiresAt = &expiresAt
+	}
+	if req.ExpiryAction != nil {
+		expiryAction, _ := metadata.NormalizeExpiryAction(*req.ExpiryAction)
+		updateRequest.ExpiryAction = &expiryAction
+	}
+	if req.UpdatedAt != nil {
+		expected := time.UnixMilli(*req.UpdatedAt)
+		updateRequest.ExpectedUpdatedAt = &expected
+	}
+
+	if err := s.metadataService.UpdateMetadata(ctx, updateRequest); err != nil {
+		if errors.Is(err, metadata.ErrUpdateConflict) {
+			return s.videoUpdateErrorResponse(4003, err.Error()), nil
+		}
+		return nil, fmt.Errorf("更新视频元数据失败: %w", err)
+	}
+
+	updated, err := s.metadataService.GetMetadata(ctx, req.VideoID)
+	if err != nil {
+		return nil, fmt.Errorf("获取视频元数据失败: %w", err)
+	}
+
+	return &api.VideoUpdateResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: "更新成功",
+		},
+		Video: convertToAPIVideo(updated),
+	}, nil
+}
+
+// validateVideoUpdateRequest 验证视频更新请求
+func (s *VideoService) validateVideoUpdateRequest(req *api.VideoUpdateRequest) error {
+	if req.VideoID == "" {
+		return fmt.Errorf("视频ID不能为空")
+	}
+	if req.Title == nil && req.Description == nil && req.Tags == nil && req.Visibility == nil &&
+		req.PublishAt == nil && req.ExpiresAt == nil && req.ExpiryAction == nil {
+		return fmt.Errorf("至少需要更新一个字段")
+	}
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if title == "" {
+			return fmt.Errorf("标题不能为空")
+		}
+		if utf8.RuneCountInString(title) > maxVideoTitleLength {
+			return fmt.Errorf("标题长度不能超过%d个字符", maxVideoTitleLength)
+		}
+	}
+	if req.Description != nil && utf8.RuneCountInString(*req.Description) > maxVideoDescriptionLength {
+		return fmt.Errorf("描述长度不能超过%d个字符", maxVideoDescriptionLength)
+	}
+	if req.Visibility != nil {
+		if strings.TrimSpace(*req.Visibility) == "" {
+			return fmt.Errorf("可见性不能为空")
+		}
+		if _, err := metadata.NormalizeVisibility(*req.Visibility); err != nil {
+			return err
+		}
+	}
+	if req.PublishAt != nil && *req.PublishAt < 0 {
+		return fmt.Errorf("发布时间不能为负数")
+	}
+	if req.ExpiresAt != nil {
+		if *req.ExpiresAt < 0 {
+			return fmt.Errorf("到期时间不能为负数")
+		}
+		if *req.ExpiresAt > 0 && !time.UnixMilli(*req.ExpiresAt).After(time.Now()) {
+			return fmt.Errorf("到期时间必须晚于当前时间")
+		}
+	}
+	if req.ExpiryAction != nil {
+		if _, err := metadata.NormalizeExpiryAction(*req.ExpiryAction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unixMilliOrZero 将毫秒时间戳转换为时间，0转换为零值
+func unixMilliOrZero(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+// normalizeTags 去除标签首尾空白并过滤空标签
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// videoUpdateErrorResponse 创建视频更新错误响应
+func (s *VideoService) videoUpdateErrorResponse(code int32, message string) *api.VideoUpdateResponse {
+	return &api.VideoUpdateResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+// createUpdateTestService 创建带测试视频的视频服务
+func createUpdateTestService(t *testing.T) *VideoService {
+	service := createTestVideoService(t)
+	err := service.metadataService.SaveMetadata(context.Background(), &metadata.FileMetadata{
+		FileID:      "video1",
+		BucketName:  "zhulong-videos",
+		ObjectName:  "videos/2025/08/video1.mp4",
+		FileName:    "video1.mp4",
+		Title:       "原始标题",
+		Description: "原始描述",
+		Tags:        []string{"原始"},
+		Width:       1920,
+		Height:      1080,
+		CreatedBy:   "system",
+	})
+	require.NoError(t, err)
+	return service
+}
+
+func TestVideoService_UpdateVideo(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("更新视频_部分字段", func(t *testing.T) {
+		service := createUpdateTestService(t)
+
+		resp, err := service.UpdateVideo(ctx, &api.VideoUpdateRequest{
+			VideoID: "video1",
+			Title:   stringPtr("  新标题  "),
+			Tags:    []string{"旅行", " ", "旅行", "风景"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code, "更新应该成功")
+		require.NotNil(t, resp.Video)
+		assert.Equal(t, "新标题", resp.Video.Title, "标题应该去除首尾空白")
+		assert.Equal(t, "原始描述", resp.Video.Description, "未传入的字段不应该被修改")
+		assert.ElementsMatch(t, []string{"旅行", "风景"}, resp.Video.Tags, "标签应该去重并过滤空值")
+		assert.Equal(t, int32(1920), resp.Video.Width)
+	})
+
+	t.Run("更新视频_参数错误", func(t *testing.T) {
+		service := createUpdateTestService(t)
+
+		invalid := []*api.VideoUpdateRequest{
+			{Title: stringPtr("标题")},
+			{VideoID: "video1"},
+			{VideoID: "video1", Title: stringPtr("   ")},
+			{VideoID: "video1", Title: stringPtr(strings.Repeat("长", 256))},
+			{VideoID: "video1", Description: stringPtr(strings.Repeat("长", 1001))},
+		}
+		for _, req := range invalid {
+			resp, err := service.UpdateVideo(ctx, req)
+			require.NoError(t, err)
+			assert.Equal(t, int32(4001), resp.Base.Code, "无效请求应该返回4001")
+		}
+	})
+
+	t.Run("更新视频_视频不存在", func(t *testing.T) {
+		service := createUpdateTestService(t)
+
+		resp, err := service.UpdateVideo(ctx, &api.VideoUpdateRequest{
+			VideoID: "not-exist",
+			Title:   stringPtr("新标题"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4002), resp.Base.Code)
+	})
+
+	t.Run("更新视频_并发冲突", func(t *testing.T) {
+		service := createUpdateTestService(t)
+
+		original, err := service.metadataService.GetMetadata(ctx, "video1")
+		require.NoError(t, err)
+		readAt := original.UpdatedAt.UnixMilli()
+
+		resp, err := service.UpdateVideo(ctx, &api.VideoUpdateRequest{
+			VideoID:   "video1",
+			Title:     stringPtr("第一次更新"),
+			UpdatedAt: &readAt,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code, "版本一致时更新应该成功")
+
+		resp, err = service.UpdateVideo(ctx, &api.VideoUpdateRequest{
+			VideoID:   "video1",
+			Title:     stringPtr("第二次更新"),
+			UpdatedAt: &readAt,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4003), resp.Base.Code, "版本过期时应该返回冲突")
+	})
+}
+
+// stringPtr 辅助函数，返回字符串指针
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/upload"
+)
+
+func TestReadFileHead(t *testing.T) {
+	t.Run("读取文件头部_大文件只读取限制大小", func(t *testing.T) {
+		data := bytes.Repeat([]byte("a"), 4096)
+		reader := bytes.NewReader(data)
+
+		head, err := readFileHead(reader, 1024)
+		require.NoError(t, err)
+		assert.Len(t, head, 1024)
+		assert.Equal(t, 3072, reader.Len(), "剩余数据不应被读取")
+	})
+
+	t.Run("读取文件头部_小文件读取全部", func(t *testing.T) {
+		head, err := readFileHead(bytes.NewReader([]byte("small")), 1024)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("small"), head)
+	})
+}
+
+// sha256Hex 计算测试数据的SHA-256校验和
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestVideoService_UploadVideoChecksum(t *testing.T) {
+	ctx := context.Background()
+	data := mp4TestData(2048)
+
+	t.Run("上传视频_返回并保存校验和", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+
+		resp, err := service.UploadVideo(ctx, &api.VideoUploadRequest{}, createTestFileHeader(t, "checksum.mp4", "video/mp4", data))
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, sha256Hex(data), resp.Video.Checksum)
+
+		meta, err := service.metadataService.GetMetadata(ctx, resp.Video.ID)
+		require.NoError(t, err)
+		assert.Equal(t, sha256Hex(data), meta.Checksum, "校验和应该保存到元数据")
+	})
+
+	t.Run("上传视频_校验和一致", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+
+		req := &api.VideoUploadRequest{Checksum: sha256Hex(data)}
+		resp, err := service.UploadVideo(ctx, req, createTestFileHeader(t, "checksum.mp4", "video/mp4", data))
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, sha256Hex(data), resp.Video.Checksum)
+	})
+
+	t.Run("上传视频_校验和不一致", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+
+		req := &api.VideoUploadRequest{Checksum: sha256Hex([]byte("other"))}
+		resp, err := service.UploadVideo(ctx, req, createTestFileHeader(t, "checksum.mp4", "video/mp4", data))
+		require.NoError(t, err)
+		assert.Equal(t, int32(1010), resp.Base.Code)
+		assert.Empty(t, testObjectNames(t, store, ""), "校验和不一致时应该删除已上传的文件")
+	})
+
+	t.Run("上传视频_校验和格式错误", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+
+		req := &api.VideoUploadRequest{Checksum: "not-a-checksum"}
+		resp, err := service.UploadVideo(ctx, req, createTestFileHeader(t, "checksum.mp4", "video/mp4", data))
+		require.NoError(t, err)
+		assert.Equal(t, int32(1001), resp.Base.Code)
+		assert.Empty(t, testObjectNames(t, store, ""))
+	})
+}
+
+func TestVideoService_UploadVideoBuckets(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewLocalStorage(&storage.LocalConfig{RootDir: t.TempDir()})
+	require.NoError(t, err)
+
+	service, _ := createDirectUploadTestService(t)
+	service.storageClient = store
+	service.uploadService = upload.NewUploadService(store)
+	service.buckets = storage.NewBucketResolver("zhulong-videos", map[storage.ContentClass]string{
+		storage.ContentVideos:     "zhulong-media",
+		storage.ContentThumbnails: "zhulong-images",
+	})
+
+	resp := uploadTestVideo(t, service, "buckets.mp4", mp4TestData(2048))
+	require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+	meta, err := service.metadataService.GetMetadata(ctx, resp.Video.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "zhulong-media", meta.BucketName, "视频应该上传到视频类别的存储桶")
+	exists, err := store.FileExists(ctx, "zhulong-media", meta.ObjectName)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	require.NotEmpty(t, meta.Thumbnail)
+	exists, err = store.FileExists(ctx, "zhulong-images", meta.Thumbnail)
+	require.NoError(t, err)
+	assert.True(t, exists, "缩略图应该上传到缩略图类别的存储桶")
+}
+
+func TestVideoService_UploadVideoObjectTags(t *testing.T) {
+	ctx := context.Background()
+	service, store := createDirectUploadTestService(t)
+
+	resp := uploadTestVideo(t, service, "tags.mp4", mp4TestData(2048))
+	require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+	tags, err := store.GetObjectTags(ctx, "zhulong-videos", resp.Video.StoragePath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		storage.ObjectKeyVideoID:     resp.Video.ID,
+		storage.ObjectKeyUploader:    "system",
+		storage.ObjectKeyContentHash: resp.Video.Checksum,
+	}, tags, "视频文件应该带有视频ID、上传者和内容校验和标签")
+}
+
+func TestVideoService_UploadVideoObjectName(t *testing.T) {
+	ctx := context.Background()
+	service, store := createDirectUploadTestService(t)
+
+	resp := uploadTestVideo(t, service, `..\旅行 Vlog 🎬.MP4`, mp4TestData(2048))
+	require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+	meta, err := service.metadataService.GetMetadata(ctx, resp.Video.ID)
+	require.NoError(t, err)
+	assert.Equal(t, `..\旅行 Vlog 🎬.MP4`, meta.FileName, "原始文件名只保存在元数据中")
+	assert.True(t, strings.HasSuffix(meta.ObjectName, "/"+resp.Video.ID+".mp4"), "对象名只使用视频ID和规范化的扩展名: %s", meta.ObjectName)
+	assert.Contains(t, testObjectNames(t, store, ""), meta.ObjectName)
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/middleware"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+// newVideoProber 创建上传文件的ffprobe检查器，验证方式不是probe或ffprobe不可用时返回nil
+func newVideoProber(cfg *config.Config) *video.FFprobeChecker {
+	if cfg.GetUploadVerification() != config.VerificationProbe {
+		return nil
+	}
+	prober := video.NewFFprobeChecker(cfg.Upload.FFprobePath)
+	if !prober.IsAvailable() {
+		fmt.Printf("警告: ffprobe不可用(%s)，上传文件只检查容器结构\n", cfg.Upload.FFprobePath)
+		return nil
+	}
+	return prober
+}
+
+// verifyVideoStructure 按配置的验证方式解析完整文件的容器结构，验证方式为header时不检查
+func (s *VideoService) verifyVideoStructure(reader io.ReaderAt, size int64, format string) error {
+	switch s.config.GetUploadVerification() {
+	case config.VerificationStructure, config.VerificationProbe:
+		return s.videoValidator.Load().ValidateStructure(reader, size, format)
+	}
+	return nil
+}
+
+// probeStoredVideo 下载已写入存储的视频并使用ffprobe检查，未启用probe时不检查
+// 文件无法解析时返回ErrInvalidStructure；ffprobe执行失败不阻断上传
+func (s *VideoService) probeStoredVideo(ctx context.Context, bucketName, objectName string) error {
+	if s.prober == nil {
+		return nil
+	}
+	videoPath, err := s.downloadVideoToTemp(ctx, &metadata.FileMetadata{BucketName: bucketName, ObjectName: objectName})
+	if err != nil {
+		fmt.Printf("下载视频进行ffprobe检查失败(request_id=%s): %v\n", middleware.RequestIDFromContext(ctx), err)
+		return nil
+	}
+	defer s.tempFiles.Remove(videoPath)
+	return s.probeLocalVideo(ctx, videoPath)
+}
+
+// probeLocalVideo 使用ffprobe检查本地视频文件，未启用probe时不检查
+func (s *VideoService) probeLocalVideo(ctx context.Context, path string) error {
+	if s.prober == nil {
+		return nil
+	}
+	err := s.prober.Check(ctx, path)
+	if err != nil && !errors.Is(err, video.ErrInvalidStructure) {
+		fmt.Printf("ffprobe检查失败(request_id=%s): %v\n", middleware.RequestIDFromContext(ctx), err)
+		return nil
+	}
+	return err
+}
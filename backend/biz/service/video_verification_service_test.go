@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+// mp4StructureTestData 生成结构完整的MP4测试数据，mdat大小与样本表一致
+func mp4StructureTestData() []byte {
+	// mp4CodecTestData的样本表共250*4000字节
+	mdat := make([]byte, 8+250*4000)
+	binary.BigEndian.PutUint32(mdat[0:4], uint32(len(mdat)))
+	copy(mdat[4:8], "mdat")
+	return append(mp4CodecTestData(), mdat...)
+}
+
+// writeFakeFFprobe 写入按指定退出码退出的ffprobe脚本
+func writeFakeFFprobe(t *testing.T, output string, exitCode int) string {
+	path := filepath.Join(t.TempDir(), "ffprobe")
+	script := "#!/bin/sh\necho '" + output + "'\nexit " + strconv.Itoa(exitCode) + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+	return path
+}
+
+// TestVideoService_UploadStructureVerification 测试保存前检查完整的文件结构
+func TestVideoService_UploadStructureVerification(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("只检查文件头时接受伪造的文件", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+		resp := uploadTestVideo(t, service, "forged.mp4", mp4TestData(2048))
+		assert.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+	})
+
+	t.Run("拒绝伪造文件头的上传", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		service.config.Upload.Verification = config.VerificationStructure
+
+		resp := uploadTestVideo(t, service, "forged.mp4", mp4TestData(2048))
+		assert.Equal(t, int32(1004), resp.Base.Code)
+		assert.Contains(t, resp.Base.Message, "文件结构验证失败")
+		assert.Equal(t, 0, countObjects(t, store, "videos/"), "验证失败时不应该写入存储")
+
+		resp = uploadTestVideo(t, service, "valid.mp4", mp4StructureTestData())
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, "H.264", resp.Video.VideoCodec)
+	})
+
+	t.Run("拒绝伪造文件头的直传", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		service.config.Upload.Verification = config.VerificationStructure
+		urlResp := createTestUploadURL(t, service, 2048)
+		session, err := service.directUploads.GetSession(urlResp.UploadToken)
+		require.NoError(t, err)
+		putTestObject(t, store, session.ObjectName, mp4TestData(2048))
+
+		resp, err := service.ConfirmUpload(ctx, &api.VideoUploadConfirmRequest{UploadToken: urlResp.UploadToken})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1004), resp.Base.Code)
+		assert.NotContains(t, testObjectNames(t, store, ""), session.ObjectName, "验证失败时应该删除上传的文件")
+		_, err = service.metadataService.GetMetadata(ctx, urlResp.VideoID)
+		assert.Error(t, err, "验证失败时不应该保存元数据")
+	})
+
+	t.Run("ffprobe无法解析时删除上传的文件", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		service.config.Upload.Verification = config.VerificationProbe
+		service.prober = video.NewFFprobeChecker(writeFakeFFprobe(t, "", 1))
+
+		resp := uploadTestVideo(t, service, "valid.mp4", mp4StructureTestData())
+		assert.Equal(t, int32(1004), resp.Base.Code)
+		assert.Equal(t, 0, countObjects(t, store, "videos/"), "验证失败时应该删除上传的文件")
+
+		service.prober = video.NewFFprobeChecker(writeFakeFFprobe(t, "video,1280,720", 0))
+		resp = uploadTestVideo(t, service, "valid.mp4", mp4StructureTestData())
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, 1, countObjects(t, store, "videos/"))
+	})
+}
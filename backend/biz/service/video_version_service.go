@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"slices"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/middleware"
+	"github.com/manteia/zhulong/pkg/quota"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/streaming"
+	"github.com/manteia/zhulong/pkg/upload"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+// versionObjectName 新版本视频文件的对象名，以版本号作为后缀，与原文件位于同一目录结构
+func versionObjectName(videoID string, number int, fileName string, now time.Time) string {
+	return fmt.Sprintf("videos/%d/%02d/%s-v%d%s", now.Year(), now.Month(), videoID, number, upload.ObjectExtension(fileName))
+}
+
+// ListVideoVersions 获取视频的全部内容版本，包括当前版本
+func (s *VideoService) ListVideoVersions(ctx context.Context, req *api.VideoVersionsRequest) (*api.VideoVersionsResponse, error) {
+	meta, errResp := s.versionTargetVideo(ctx, req.VideoID)
+	if errResp != nil {
+		return errResp, nil
+	}
+	return videoVersionsResponse(meta, "获取成功"), nil
+}
+
+// UploadVideoVersion 上传新的视频文件替换视频内容，原文件保存为历史版本
+// 历史版本超过upload.max_versions个时删除最旧版本的文件；替换后重新提取视频信息、生成缩略图并重新打包
+func (s *VideoService) UploadVideoVersion(ctx context.Context, req *api.VideoVersionUploadRequest, fileHeader *multipart.FileHeader) (*api.VideoVersionsResponse, error) {
+	meta, errResp := s.versionTargetVideo(ctx, req.VideoID)
+	if errResp != nil {
+		return errResp, nil
+	}
+	if fileHeader == nil {
+		return s.videoVersionsErrorResponse(4801, "视频文件不能为空"), nil
+	}
+	if !meta.IsVideo() {
+		return s.videoVersionsErrorResponse(4801, "只有视频支持上传新版本"), nil
+	}
+	if err := s.validateUploadSize(ctx, fileHeader.Filename, fileHeader.Size); err != nil {
+		return s.videoVersionsErrorResponse(4801, fmt.Sprintf("文件大小验证失败: %v", err)), nil
+	}
+
+	unlock, ok := s.lockVideoContent(meta.FileID)
+	if !ok {
+		return s.videoVersionsErrorResponse(4806, "视频正在处理，请稍后重试"), nil
+	}
+	defer unlock()
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return s.videoVersionsErrorResponse(4801, "无法打开上传文件"), nil
+	}
+	defer file.Close()
+
+	headData, err := readFileHead(file, videoHeadSize)
+	if err != nil {
+		return s.videoVersionsErrorResponse(4801, "读取文件数据失败"), nil
+	}
+	validationResult, err := s.videoValidator.Load().ValidateFormat(&video.ValidationRequest{
+		Filename:    fileHeader.Filename,
+		ContentType: fileHeader.Header.Get("Content-Type"),
+		Data:        headData[:min(len(headData), 512)],
+	})
+	if err != nil {
+		return s.videoVersionsErrorResponse(4801, fmt.Sprintf("文件格式验证失败: %v", err)), nil
+	}
+	if !validationResult.IsValid {
+		return s.videoVersionsErrorResponse(4801, fmt.Sprintf("不支持的文件格式: %s", validationResult.ErrorMessage)), nil
+	}
+	if err := s.verifyVideoStructure(file, fileHeader.Size, validationResult.DetectedFormat); err != nil {
+		return s.videoVersionsErrorResponse(4801, fmt.Sprintf("文件结构验证失败: %v", err)), nil
+	}
+
+	// 历史版本计入视频所有者的存储配额
+	reservation, err := s.reserveQuota(ctx, meta.CreatedBy, fileHeader.Size)
+	if errors.Is(err, quota.ErrQuotaExceeded) {
+		return s.videoVersionsErrorResponse(4805, err.Error()), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer reservation.Release()
+
+	meta, err = s.restoreArchivedVideo(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("重置文件读取位置失败: %w", err)
+	}
+
+	now := time.Now()
+	bucketName := s.buckets.Bucket(storage.ContentVideos)
+	uploadResult, err := s.uploadService.UploadFile(ctx, &upload.UploadRequest{
+		BucketName:  bucketName,
+		FileName:    fileHeader.Filename,
+		ObjectName:  versionObjectName(meta.FileID, meta.NextVersion(), fileHeader.Filename, now),
+		Reader:      file,
+		Size:        fileHeader.Size,
+		ContentType: fileHeader.Header.Get("Content-Type"),
+		Metadata:    videoObjectLabels(meta.FileID, meta.CreatedBy),
+		Tags:        videoObjectLabels(meta.FileID, meta.CreatedBy),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("上传视频文件失败: %w", err)
+	}
+	if err := s.probeStoredVideo(ctx, bucketName, uploadResult.ObjectName); err != nil {
+		s.storageClient.DeleteFile(ctx, bucketName, uploadResult.ObjectName)
+		return s.videoVersionsErrorResponse(4801, fmt.Sprintf("文件结构验证失败: %v", err)), nil
+	}
+
+	_, removed, err := s.metadataService.AddVersion(ctx, meta.FileID, metadata.Version{
+		BucketName:  bucketName,
+		ObjectName:  uploadResult.ObjectName,
+		FileName:    fileHeader.Filename,
+		FileSize:    fileHeader.Size,
+		ContentType: fileHeader.Header.Get("Content-Type"),
+		Checksum:    uploadResult.Checksum,
+		CreatedBy:   currentUserID(ctx),
+		CreatedAt:   now,
+	}, s.config.GetMaxVersions())
+	if err != nil {
+		s.storageClient.DeleteFile(ctx, bucketName, uploadResult.ObjectName)
+		return nil, fmt.Errorf("保存视频版本失败: %w", err)
+	}
+	s.deleteVersionFiles(ctx, removed)
+
+	return s.refreshVersionContent(ctx, meta, "新版本已上传")
+}
+
+// RestoreVideoVersion 将历史版本恢复为视频的当前内容，被替换的内容保存为历史版本
+func (s *VideoService) RestoreVideoVersion(ctx context.Context, req *api.VideoVersionRestoreRequest) (*api.VideoVersionsResponse, error) {
+	meta, errResp := s.versionTargetVideo(ctx, req.VideoID)
+	if errResp != nil {
+		return errResp, nil
+	}
+	if req.Version <= 0 {
+		return s.videoVersionsErrorResponse(4801, "版本号无效"), nil
+	}
+	if int(req.Version) == meta.CurrentVersion().Number {
+		return s.videoVersionsErrorResponse(4801, "该版本已是当前版本"), nil
+	}
+
+	unlock, ok := s.lockVideoContent(meta.FileID)
+	if !ok {
+		return s.videoVersionsErrorResponse(4806, "视频正在处理，请稍后重试"), nil
+	}
+	defer unlock()
+
+	version, ok := meta.FindVersion(int(req.Version))
+	if !ok {
+		return s.videoVersionsErrorResponse(4804, "视频版本不存在"), nil
+	}
+	exists, err := s.storageClient.FileExists(ctx, version.BucketName, version.ObjectName)
+	if err != nil {
+		return nil, fmt.Errorf("检查版本文件失败: %w", err)
+	}
+	if !exists {
+		return s.videoVersionsErrorResponse(4804, "版本文件不存在"), nil
+	}
+
+	meta, err = s.restoreArchivedVideo(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+	err = s.metadataService.RestoreVersion(ctx, meta.FileID, version.Number)
+	if errors.Is(err, metadata.ErrVersionNotFound) {
+		return s.videoVersionsErrorResponse(4804, "视频版本不存在"), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("恢复视频版本失败: %w", err)
+	}
+
+	return s.refreshVersionContent(ctx, meta, fmt.Sprintf("已恢复版本%d", version.Number))
+}
+
+// versionTargetVideo 获取要管理版本的视频并校验归属，失败时返回错误响应
+func (s *VideoService) versionTargetVideo(ctx context.Context, videoID string) (*metadata.FileMetadata, *api.VideoVersionsResponse) {
+	if videoID == "" {
+		return nil, s.videoVersionsErrorResponse(4801, "视频ID不能为空")
+	}
+	meta, err := s.metadataService.GetMetadata(ctx, videoID)
+	if err != nil {
+		return nil, s.videoVersionsErrorResponse(4802, "视频不存在")
+	}
+	if !canModifyVideo(ctx, meta) {
+		return nil, s.videoVersionsErrorResponse(4803, "无权管理其他用户的视频版本")
+	}
+	return meta, nil
+}
+
+// lockVideoContent 标记视频正在替换内容，视频正在处理或替换内容时返回false
+// 替换期间视频不会被归档，也不会开始新的内容替换
+func (s *VideoService) lockVideoContent(videoID string) (func(), bool) {
+	if s.isProcessingVideo(videoID) {
+		return nil, false
+	}
+	if _, running := s.versionJobs.LoadOrStore(videoID, struct{}{}); running {
+		return nil, false
+	}
+	return func() { s.versionJobs.Delete(videoID) }, true
+}
+
+// refreshVersionContent 视频内容替换后删除原内容的动态预览和HLS文件，并重新处理视频
+// previous为替换前的元数据
+func (s *VideoService) refreshVersionContent(ctx context.Context, previous *metadata.FileMetadata, message string) (*api.VideoVersionsResponse, error) {
+	s.invalidatePresignedURLs(ctx, previous.BucketName, previous.ObjectName)
+
+	thumbnailBucket := s.buckets.Bucket(storage.ContentThumbnails)
+	if previous.Preview != "" {
+		if err := s.storageClient.DeleteFile(ctx, thumbnailBucket, previous.Preview); err != nil {
+			fmt.Printf("删除动态预览失败(%s, request_id=%s): %v\n", previous.FileID, middleware.RequestIDFromContext(ctx), err)
+		}
+		s.invalidatePresignedURLs(ctx, thumbnailBucket, previous.Preview)
+		// 清空后重新生成预览图时会一并生成动态预览
+		preview := ""
+		if err := s.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{
+			FileID:  previous.FileID,
+			Preview: &preview,
+		}); err != nil {
+			return nil, fmt.Errorf("更新视频元数据失败: %w", err)
+		}
+	}
+	if err := s.deleteHLSFiles(ctx, previous.FileID); err != nil {
+		fmt.Printf("删除HLS文件失败(%s, request_id=%s): %v\n", previous.FileID, middleware.RequestIDFromContext(ctx), err)
+	}
+
+	meta, err := s.metadataService.GetMetadata(ctx, previous.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("获取视频元数据失败: %w", err)
+	}
+	if _, err := s.reprocessVideo(ctx, meta); err != nil {
+		return nil, err
+	}
+	// 缩略图重新生成时覆盖原路径
+	if previous.Thumbnail != "" {
+		s.invalidatePresignedURLs(ctx, thumbnailBucket, previous.Thumbnail)
+	}
+
+	meta, err = s.metadataService.GetMetadata(ctx, previous.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("获取视频元数据失败: %w", err)
+	}
+	return videoVersionsResponse(meta, message), nil
+}
+
+// deleteHLSFiles 删除视频的全部HLS文件
+func (s *VideoService) deleteHLSFiles(ctx context.Context, videoID string) error {
+	bucketName := s.buckets.Bucket(storage.ContentRenditions)
+	files, err := s.storageClient.ListFiles(ctx, bucketName, streaming.VideoPrefix(videoID))
+	if err != nil {
+		return fmt.Errorf("列出HLS文件失败: %w", err)
+	}
+	for _, file := range files {
+		if err := s.storageClient.DeleteFile(ctx, bucketName, file.Key); err != nil {
+			return err
+		}
+		s.invalidatePresignedURLs(ctx, bucketName, file.Key)
+	}
+	return nil
+}
+
+// deleteVersionFiles 删除超出保留数量的历史版本文件，文件仍被其他视频引用时保留
+func (s *VideoService) deleteVersionFiles(ctx context.Context, versions []metadata.Version) {
+	for _, version := range versions {
+		if s.metadataService.CountObjectReferences(ctx, version.BucketName, version.ObjectName) > 0 {
+			continue
+		}
+		if err := s.storageClient.DeleteFile(ctx, version.BucketName, version.ObjectName); err != nil {
+			fmt.Printf("删除历史版本文件失败(%s/%s, request_id=%s): %v\n", version.BucketName, version.ObjectName, middleware.RequestIDFromContext(ctx), err)
+			continue
+		}
+		s.invalidatePresignedURLs(ctx, version.BucketName, version.ObjectName)
+	}
+}
+
+// videoVersionsResponse 创建视频版本响应，版本按版本号降序
+func videoVersionsResponse(meta *metadata.FileMetadata, message string) *api.VideoVersionsResponse {
+	versions := []*api.VideoVersion{convertVideoVersion(meta.CurrentVersion(), true)}
+	for _, version := range meta.Versions {
+		versions = append(versions, convertVideoVersion(version, false))
+	}
+	// 恢复旧版本后当前版本不一定是最大的版本号
+	slices.SortFunc(versions, func(a, b *api.VideoVersion) int {
+		return int(b.Version - a.Version)
+	})
+
+	return &api.VideoVersionsResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: message,
+		},
+		Video:    convertToAPIVideo(meta),
+		Versions: versions,
+	}
+}
+
+// convertVideoVersion 转换视频版本
+func convertVideoVersion(version metadata.Version, current bool) *api.VideoVersion {
+	return &api.VideoVersion{
+		Version:     int32(version.Number),
+		Filename:    version.FileName,
+		ContentType: version.ContentType,
+		Size:        version.FileSize,
+		Checksum:    version.Checksum,
+		CreatedBy:   version.CreatedBy,
+		CreatedAt:   version.CreatedAt.UnixMilli(),
+		Current:     current,
+	}
+}
+
+// videoVersionsErrorResponse 创建视频版本错误响应
+func (s *VideoService) videoVersionsErrorResponse(code int32, message string) *api.VideoVersionsResponse {
+	return &api.VideoVersionsResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
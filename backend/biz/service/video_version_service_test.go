@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/streaming"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// uploadTestVersion 为视频上传新版本
+func uploadTestVersion(t *testing.T, service *VideoService, videoID, filename string, data []byte) *api.VideoVersionsResponse {
+	resp, err := service.UploadVideoVersion(context.Background(), &api.VideoVersionUploadRequest{VideoID: videoID},
+		createTestFileHeader(t, filename, "video/mp4", data))
+	require.NoError(t, err)
+	return resp
+}
+
+// versionNumbers 获取版本响应中的版本号
+func versionNumbers(versions []*api.VideoVersion) []int32 {
+	numbers := make([]int32, 0, len(versions))
+	for _, version := range versions {
+		numbers = append(numbers, version.Version)
+	}
+	return numbers
+}
+
+func TestVideoService_VideoVersions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("上传新版本并恢复旧版本", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		uploaded := uploadTestVideo(t, service, "original.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+		assert.Equal(t, int32(1), uploaded.Video.Version)
+
+		resp := uploadTestVersion(t, service, uploaded.Video.ID, "updated.mp4", mp4CodecTestData())
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, int32(2), resp.Video.Version)
+		assert.Equal(t, uploaded.Video.ID, resp.Video.ID, "替换内容不改变视频ID")
+		assert.Equal(t, "updated.mp4", resp.Video.Filename)
+		assert.True(t, strings.HasSuffix(resp.Video.StoragePath, uploaded.Video.ID+"-v2.mp4"), resp.Video.StoragePath)
+		assert.Equal(t, "H.264", resp.Video.VideoCodec, "应该重新提取视频信息")
+		assert.Equal(t, int32(1280), resp.Video.Width)
+		assert.Contains(t, testObjectNames(t, store, ""), uploaded.Video.StoragePath, "原文件应该保留为历史版本")
+		require.Len(t, resp.Versions, 2)
+		assert.Equal(t, []int32{2, 1}, versionNumbers(resp.Versions))
+		assert.True(t, resp.Versions[0].Current)
+		assert.Equal(t, "original.mp4", resp.Versions[1].Filename)
+		assert.Equal(t, uploaded.Video.Checksum, resp.Versions[1].Checksum)
+
+		restored, err := service.RestoreVideoVersion(ctx, &api.VideoVersionRestoreRequest{VideoID: uploaded.Video.ID, Version: 1})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), restored.Base.Code, restored.Base.Message)
+		assert.Equal(t, int32(1), restored.Video.Version)
+		assert.Equal(t, uploaded.Video.StoragePath, restored.Video.StoragePath)
+		assert.Empty(t, restored.Video.VideoCodec, "恢复后应该按原文件重新提取视频信息")
+		assert.Equal(t, []int32{2, 1}, versionNumbers(restored.Versions))
+		assert.False(t, restored.Versions[0].Current)
+		assert.True(t, restored.Versions[1].Current)
+
+		list, err := service.ListVideoVersions(ctx, &api.VideoVersionsRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), list.Base.Code, list.Base.Message)
+		assert.Equal(t, restored.Versions, list.Versions)
+
+		meta, err := service.metadataService.GetMetadata(ctx, uploaded.Video.ID)
+		require.NoError(t, err)
+		objects, err := service.collectVideoObjects(ctx, meta)
+		require.NoError(t, err)
+		names := bucketObjectNames(objects, "zhulong-videos")
+		assert.Contains(t, names, uploaded.Video.StoragePath)
+		assert.Contains(t, names, resp.Video.StoragePath, "删除视频时应该一并删除历史版本")
+	})
+
+	t.Run("超过保留数量时删除最旧的版本", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		service.config.Upload.MaxVersions = 1
+		uploaded := uploadTestVideo(t, service, "original.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+
+		for _, name := range []string{"second.mp4", "third.mp4"} {
+			resp := uploadTestVersion(t, service, uploaded.Video.ID, name, mp4TestData(4096))
+			require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		}
+
+		list, err := service.ListVideoVersions(ctx, &api.VideoVersionsRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		assert.Equal(t, []int32{3, 2}, versionNumbers(list.Versions))
+		assert.NotContains(t, testObjectNames(t, store, ""), uploaded.Video.StoragePath, "超出保留数量的版本文件应该被删除")
+		assert.Equal(t, 2, countObjects(t, store, "videos/"))
+	})
+
+	t.Run("删除原内容的动态预览和HLS文件", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+		uploaded := uploadTestVideo(t, service, "original.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+		previewPath := "previews/2025/08/" + uploaded.Video.ID + ".gif"
+		putTestObject(t, store, previewPath, []byte("gif"))
+		putTestObject(t, store, streaming.VideoPrefix(uploaded.Video.ID)+"master.m3u8", []byte("#EXTM3U"))
+		require.NoError(t, service.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{
+			FileID:  uploaded.Video.ID,
+			Preview: &previewPath,
+		}))
+
+		resp := uploadTestVersion(t, service, uploaded.Video.ID, "updated.mp4", mp4TestData(4096))
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Empty(t, resp.Video.PreviewPath)
+		assert.NotContains(t, testObjectNames(t, store, ""), previewPath)
+		assert.Zero(t, countObjects(t, store, streaming.VideoPrefix(uploaded.Video.ID)))
+		assert.Equal(t, uploaded.Video.ThumbnailPath, resp.Video.ThumbnailPath, "缩略图应该覆盖原路径")
+	})
+
+	t.Run("历史版本计入存储配额", func(t *testing.T) {
+		service, _ := createQuotaTestService(t, 5000)
+		aliceCtx := user.ContextWithClaims(ctx, &user.Claims{UserID: "alice", Role: user.RoleUploader})
+		uploaded, err := service.UploadVideo(aliceCtx, &api.VideoUploadRequest{}, createTestFileHeader(t, "alice.mp4", "video/mp4", mp4TestData(2048)))
+		require.NoError(t, err)
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+
+		resp, err := service.UploadVideoVersion(aliceCtx, &api.VideoVersionUploadRequest{VideoID: uploaded.Video.ID},
+			createTestFileHeader(t, "v2.mp4", "video/mp4", mp4TestData(2048)))
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+		resp, err = service.UploadVideoVersion(aliceCtx, &api.VideoVersionUploadRequest{VideoID: uploaded.Video.ID},
+			createTestFileHeader(t, "v3.mp4", "video/mp4", mp4TestData(2048)))
+		require.NoError(t, err)
+		assert.Equal(t, int32(4805), resp.Base.Code)
+	})
+
+	t.Run("已归档的视频先恢复", func(t *testing.T) {
+		service, store := createArchiveTestService(t)
+		uploaded := uploadTestVideo(t, service, "archived.mp4", mp4TestData(2048))
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+		archived, err := service.ArchiveVideo(ctx, &api.VideoArchiveRequest{VideoID: uploaded.Video.ID})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), archived.Base.Code, archived.Base.Message)
+
+		resp := uploadTestVersion(t, service, uploaded.Video.ID, "updated.mp4", mp4TestData(4096))
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assertObjectLocation(t, store, uploaded.Video.StoragePath, false)
+
+		// 只归档当前内容，历史版本的文件保留在视频存储桶中
+		restored, err := service.RestoreVideoVersion(ctx, &api.VideoVersionRestoreRequest{VideoID: uploaded.Video.ID, Version: 1})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), restored.Base.Code, restored.Base.Message)
+		count, err := service.ArchiveIdleVideos(ctx, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assertObjectLocation(t, store, resp.Video.StoragePath, false)
+	})
+
+	t.Run("参数和权限", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+		aliceCtx := user.ContextWithClaims(ctx, &user.Claims{UserID: "alice", Role: user.RoleUploader})
+		bobCtx := user.ContextWithClaims(ctx, &user.Claims{UserID: "bob", Role: user.RoleUploader})
+		uploaded, err := service.UploadVideo(aliceCtx, &api.VideoUploadRequest{Title: "alice的视频"}, createTestFileHeader(t, "alice.mp4", "video/mp4", mp4TestData(2048)))
+		require.NoError(t, err)
+		require.Equal(t, int32(0), uploaded.Base.Code, uploaded.Base.Message)
+		videoID := uploaded.Video.ID
+
+		list, err := service.ListVideoVersions(ctx, &api.VideoVersionsRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4801), list.Base.Code)
+
+		list, err = service.ListVideoVersions(ctx, &api.VideoVersionsRequest{VideoID: "missing"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4802), list.Base.Code)
+
+		list, err = service.ListVideoVersions(bobCtx, &api.VideoVersionsRequest{VideoID: videoID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4803), list.Base.Code, "上传者不能查看其他用户视频的版本")
+
+		resp, err := service.UploadVideoVersion(bobCtx, &api.VideoVersionUploadRequest{VideoID: videoID},
+			createTestFileHeader(t, "bob.mp4", "video/mp4", mp4TestData(2048)))
+		require.NoError(t, err)
+		assert.Equal(t, int32(4803), resp.Base.Code)
+
+		resp, err = service.UploadVideoVersion(aliceCtx, &api.VideoVersionUploadRequest{VideoID: videoID}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int32(4801), resp.Base.Code)
+
+		resp, err = service.UploadVideoVersion(aliceCtx, &api.VideoVersionUploadRequest{VideoID: videoID},
+			createTestFileHeader(t, "notes.txt", "text/plain", []byte("not a video")))
+		require.NoError(t, err)
+		assert.Equal(t, int32(4801), resp.Base.Code, "不支持的文件格式")
+
+		resp, err = service.RestoreVideoVersion(aliceCtx, &api.VideoVersionRestoreRequest{VideoID: videoID, Version: 1})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4801), resp.Base.Code, "当前版本不需要恢复")
+
+		resp, err = service.RestoreVideoVersion(aliceCtx, &api.VideoVersionRestoreRequest{VideoID: videoID, Version: 9})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4804), resp.Base.Code)
+
+		service.versionJobs.Store(videoID, struct{}{})
+		resp, err = service.UploadVideoVersion(aliceCtx, &api.VideoVersionUploadRequest{VideoID: videoID},
+			createTestFileHeader(t, "v2.mp4", "video/mp4", mp4TestData(2048)))
+		require.NoError(t, err)
+		assert.Equal(t, int32(4806), resp.Base.Code, "同一视频不能同时替换内容")
+		service.versionJobs.Delete(videoID)
+
+		resp, err = service.UploadVideoVersion(aliceCtx, &api.VideoVersionUploadRequest{VideoID: videoID},
+			createTestFileHeader(t, "v2.mp4", "video/mp4", mp4TestData(2048)))
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, "alice", resp.Versions[0].CreatedBy)
+	})
+}
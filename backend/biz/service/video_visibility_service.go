@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// currentViewer 当前请求的用户，未登录时为匿名用户，只能查看公开和不公开的视频
+func currentViewer(ctx context.Context) metadata.Viewer {
+	claims, ok := user.ClaimsFromContext(ctx)
+	if !ok {
+		return metadata.Viewer{}
+	}
+	return metadata.Viewer{UserID: claims.UserID, Admin: claims.Role == user.RoleAdmin}
+}
+
+// getVisibleMetadata 获取当前用户可以查看的视频元数据
+// 私有视频对其他用户按不存在处理，不暴露视频是否存在
+func (s *VideoService) getVisibleMetadata(ctx context.Context, videoID string) (*metadata.FileMetadata, error) {
+	meta, err := s.metadataService.GetMetadata(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if !meta.VisibleTo(currentViewer(ctx)) {
+		return nil, fmt.Errorf("元数据不存在: %s", videoID)
+	}
+	return meta, nil
+}
+
+// uploadVisibility 上传视频的可见性，未指定时使用配置的默认可见性
+func (s *VideoService) uploadVisibility(visibility string) (string, error) {
+	if strings.TrimSpace(visibility) == "" {
+		return s.config.GetDefaultVisibility(), nil
+	}
+	return metadata.NormalizeVisibility(visibility)
+}
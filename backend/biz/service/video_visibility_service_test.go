@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// createVisibilityTestService 创建带私有、不公开和公开测试视频的服务
+func createVisibilityTestService(t *testing.T) *VideoService {
+	service, store := createDirectUploadTestService(t)
+	for _, visibility := range []string{metadata.VisibilityPrivate, metadata.VisibilityUnlisted, metadata.VisibilityPublic} {
+		objectName := "videos/2025/08/" + visibility + ".mp4"
+		putTestObject(t, store, objectName, []byte("0123456789"))
+		require.NoError(t, service.metadataService.SaveMetadata(context.Background(), &metadata.FileMetadata{
+			FileID:      visibility,
+			BucketName:  "zhulong-videos",
+			ObjectName:  objectName,
+			FileName:    visibility + ".mp4",
+			ContentType: "video/mp4",
+			Title:       visibility,
+			Visibility:  visibility,
+			CreatedBy:   "uploader-1",
+		}))
+	}
+	return service
+}
+
+// listedVideoIDs 返回当前用户在视频列表中看到的视频ID
+func listedVideoIDs(t *testing.T, service *VideoService, ctx context.Context) []string {
+	resp, err := service.GetVideoList(ctx, &api.VideoListRequest{})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+	ids := []string{}
+	for _, video := range resp.Videos {
+		ids = append(ids, video.ID)
+	}
+	return ids
+}
+
+func TestVideoService_Visibility(t *testing.T) {
+	anonymous := context.Background()
+	owner := claimsContext("uploader-1", user.RoleUploader)
+	other := claimsContext("uploader-2", user.RoleUploader)
+	admin := claimsContext("admin-1", user.RoleAdmin)
+
+	t.Run("列表只包含公开视频和自己的视频", func(t *testing.T) {
+		service := createVisibilityTestService(t)
+
+		assert.ElementsMatch(t, []string{"public"}, listedVideoIDs(t, service, anonymous))
+		assert.ElementsMatch(t, []string{"public"}, listedVideoIDs(t, service, other))
+		assert.ElementsMatch(t, []string{"private", "unlisted", "public"}, listedVideoIDs(t, service, owner))
+		assert.ElementsMatch(t, []string{"private", "unlisted", "public"}, listedVideoIDs(t, service, admin))
+	})
+
+	t.Run("私有视频对其他用户不存在", func(t *testing.T) {
+		service := createVisibilityTestService(t)
+
+		for _, ctx := range []context.Context{anonymous, other} {
+			detail, err := service.GetVideoDetail(ctx, &api.VideoDetailRequest{VideoID: "private"})
+			require.NoError(t, err)
+			assert.Equal(t, int32(2102), detail.Base.Code)
+
+			playURL, err := service.GetVideoPlayURL(ctx, &api.VideoPlayURLRequest{VideoID: "private"})
+			require.NoError(t, err)
+			assert.Equal(t, int32(6302), playURL.Base.Code)
+
+			stream, err := service.StreamVideo(ctx, &api.VideoStreamRequest{VideoID: "private"}, "")
+			require.NoError(t, err)
+			assert.Equal(t, int32(6102), stream.Base.Code)
+		}
+		assert.Equal(t, int64(0), service.views.ViewCount(anonymous, "private"), "无权查看的请求不应该计入播放")
+
+		for _, ctx := range []context.Context{owner, admin} {
+			detail, err := service.GetVideoDetail(ctx, &api.VideoDetailRequest{VideoID: "private"})
+			require.NoError(t, err)
+			require.Equal(t, int32(0), detail.Base.Code, detail.Base.Message)
+			assert.Equal(t, metadata.VisibilityPrivate, detail.Video.Visibility)
+		}
+	})
+
+	t.Run("知道ID可以查看不公开视频", func(t *testing.T) {
+		service := createVisibilityTestService(t)
+
+		detail, err := service.GetVideoDetail(anonymous, &api.VideoDetailRequest{VideoID: "unlisted"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), detail.Base.Code, detail.Base.Message)
+
+		stream, err := service.StreamVideo(anonymous, &api.VideoStreamRequest{VideoID: "unlisted"}, "")
+		require.NoError(t, err)
+		require.Equal(t, int32(0), stream.Base.Code, stream.Base.Message)
+		assert.Equal(t, "0123456789", readStream(t, stream))
+	})
+
+	t.Run("修改可见性", func(t *testing.T) {
+		service := createVisibilityTestService(t)
+
+		visibility := " Public "
+		resp, err := service.UpdateVideo(owner, &api.VideoUpdateRequest{VideoID: "private", Visibility: &visibility})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, metadata.VisibilityPublic, resp.Video.Visibility)
+		assert.Contains(t, listedVideoIDs(t, service, anonymous), "private")
+
+		for _, invalid := range []string{"", "friends"} {
+			resp, err = service.UpdateVideo(owner, &api.VideoUpdateRequest{VideoID: "private", Visibility: &invalid})
+			require.NoError(t, err)
+			assert.Equal(t, int32(4001), resp.Base.Code, "可见性%q应该被拒绝", invalid)
+		}
+	})
+}
+
+func TestVideoService_UploadVisibility(t *testing.T) {
+	ctx := claimsContext("uploader-1", user.RoleUploader)
+
+	t.Run("未指定时使用默认可见性", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+		service.config.Upload.DefaultVisibility = metadata.VisibilityUnlisted
+
+		resp, err := service.UploadVideo(ctx, &api.VideoUploadRequest{}, createTestFileHeader(t, "default.mp4", "video/mp4", mp4TestData(1024)))
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, metadata.VisibilityUnlisted, resp.Video.Visibility)
+	})
+
+	t.Run("上传时指定可见性", func(t *testing.T) {
+		service, _ := createDirectUploadTestService(t)
+
+		resp, err := service.UploadVideo(ctx, &api.VideoUploadRequest{Visibility: "private"}, createTestFileHeader(t, "private.mp4", "video/mp4", mp4TestData(1024)))
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		assert.Equal(t, metadata.VisibilityPrivate, resp.Video.Visibility)
+
+		resp, err = service.UploadVideo(ctx, &api.VideoUploadRequest{Visibility: "friends"}, createTestFileHeader(t, "invalid.mp4", "video/mp4", mp4TestData(1024)))
+		require.NoError(t, err)
+		assert.Equal(t, int32(1001), resp.Base.Code)
+	})
+
+	t.Run("直传上传保留可见性", func(t *testing.T) {
+		service, store := createDirectUploadTestService(t)
+
+		urlResp, err := service.CreateUploadURL(ctx, &api.VideoUploadURLRequest{Filename: "direct.mp4", Size: 1024, Visibility: "unlisted"})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), urlResp.Base.Code, urlResp.Base.Message)
+		session, err := service.directUploads.GetSession(urlResp.UploadToken)
+		require.NoError(t, err)
+		putTestObject(t, store, session.ObjectName, mp4TestData(1024))
+
+		confirmResp, err := service.ConfirmUpload(ctx, &api.VideoUploadConfirmRequest{UploadToken: urlResp.UploadToken})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), confirmResp.Base.Code, confirmResp.Base.Message)
+		assert.Equal(t, metadata.VisibilityUnlisted, confirmResp.Video.Visibility)
+
+		urlResp, err = service.CreateUploadURL(ctx, &api.VideoUploadURLRequest{Filename: "direct.mp4", Size: 1024, Visibility: "friends"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1001), urlResp.Base.Code)
+	})
+}
+
+func TestVideoService_DuplicatePrivateVideo(t *testing.T) {
+	service, _ := createDedupTestService(t, config.DeduplicationReject)
+	data := mp4TestData(2048)
+	owner := claimsContext("uploader-1", user.RoleUploader)
+	other := claimsContext("uploader-2", user.RoleUploader)
+
+	first, err := service.UploadVideo(owner, &api.VideoUploadRequest{Visibility: "private"}, createTestFileHeader(t, "first.mp4", "video/mp4", data))
+	require.NoError(t, err)
+	require.Equal(t, int32(0), first.Base.Code, first.Base.Message)
+
+	resp, err := service.UploadVideo(other, &api.VideoUploadRequest{}, createTestFileHeader(t, "second.mp4", "video/mp4", data))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1011), resp.Base.Code)
+	assert.Nil(t, resp.Video, "不应该返回其他用户的私有视频")
+	assert.NotContains(t, resp.Base.Message, first.Video.ID)
+
+	resp, err = service.UploadVideo(owner, &api.VideoUploadRequest{}, createTestFileHeader(t, "third.mp4", "video/mp4", data))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1011), resp.Base.Code)
+	require.NotNil(t, resp.Video, "上传者应该看到自己已存在的视频")
+	assert.Equal(t, first.Video.ID, resp.Video.ID)
+}
@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/notify"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/streaming"
+	"github.com/manteia/zhulong/pkg/transcode"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+// workerJobKinds worker可以领取的任务类型
+var workerJobKinds = []string{transcode.KindHLS, transcode.KindPreview}
+
+// ClaimWorkerJob 远程worker领取优先级最高的处理任务，没有待执行的任务时job为空
+// 领取时视频已被删除或恢复归档失败的任务按失败处理，继续领取下一个任务
+func (s *VideoService) ClaimWorkerJob(ctx context.Context, req *api.WorkerJobClaimRequest) (*api.WorkerJobResponse, error) {
+	if req.WorkerID == "" {
+		return s.workerJobErrorResponse(6901, "worker标识不能为空"), nil
+	}
+	for _, kind := range req.Kinds {
+		if !slices.Contains(workerJobKinds, kind) {
+			return s.workerJobErrorResponse(6901, transcode.ErrInvalidKind.Error()), nil
+		}
+	}
+	if !s.remoteWorkerEnabled() {
+		return s.workerJobErrorResponse(6902, "未启用远程worker模式"), nil
+	}
+
+	for {
+		job, ok := s.transcodeQueue.Claim(req.WorkerID, req.Kinds)
+		if !ok {
+			return s.workerJobResponse(nil, "没有待执行的任务"), nil
+		}
+
+		workerJob, err := s.prepareWorkerJob(ctx, job)
+		if err == nil {
+			return s.workerJobResponse(workerJob, "领取成功"), nil
+		}
+		fmt.Printf("准备处理任务失败(%s, %s): %v\n", job.Kind, job.VideoID, err)
+		_, _ = s.transcodeQueue.Complete(job.ID, req.WorkerID, err)
+	}
+}
+
+// HeartbeatWorkerJob 远程worker续租正在执行的处理任务
+func (s *VideoService) HeartbeatWorkerJob(ctx context.Context, req *api.WorkerJobHeartbeatRequest) (*api.WorkerJobResponse, error) {
+	if req.JobID == "" || req.WorkerID == "" {
+		return s.workerJobErrorResponse(6901, "任务ID和worker标识不能为空"), nil
+	}
+	if !s.remoteWorkerEnabled() {
+		return s.workerJobErrorResponse(6902, "未启用远程worker模式"), nil
+	}
+
+	job, err := s.transcodeQueue.Heartbeat(req.JobID, req.WorkerID)
+	if err != nil {
+		return s.workerJobQueueErrorResponse(err), nil
+	}
+	return s.workerJobResponse(&api.WorkerJob{
+		JobID:          job.ID,
+		Kind:           job.Kind,
+		Attempts:       int32(job.Attempts),
+		LeaseExpiresAt: job.LeaseExpiresAt.UnixMilli(),
+	}, "续租成功"), nil
+}
+
+// CompleteWorkerJob 远程worker上报处理任务结果，任务执行成功时发布完成事件并保存动态预览路径
+// 任务已被取消时丢弃worker上传的动态预览
+func (s *VideoService) CompleteWorkerJob(ctx context.Context, req *api.WorkerJobCompleteRequest) (*api.WorkerJobResponse, error) {
+	if req.JobID == "" || req.WorkerID == "" {
+		return s.workerJobErrorResponse(6901, "任务ID和worker标识不能为空"), nil
+	}
+	if !s.remoteWorkerEnabled() {
+		return s.workerJobErrorResponse(6902, "未启用远程worker模式"), nil
+	}
+
+	var jobErr error
+	if req.IsSetError() {
+		jobErr = errors.New(req.GetError())
+	}
+	job, err := s.transcodeQueue.Complete(req.JobID, req.WorkerID, jobErr)
+	if err != nil {
+		return s.workerJobQueueErrorResponse(err), nil
+	}
+
+	if job.Status == transcode.StatusSucceeded {
+		s.applyWorkerResult(ctx, job, req)
+	} else if req.IsSetPreviewPath() {
+		s.storageClient.DeleteFile(ctx, s.buckets.Bucket(storage.ContentThumbnails), req.GetPreviewPath())
+	}
+
+	return s.workerJobResponse(&api.WorkerJob{
+		JobID:    job.ID,
+		Kind:     job.Kind,
+		Attempts: int32(job.Attempts),
+	}, "结果已上报"), nil
+}
+
+// remoteWorkerEnabled 是否由远程worker执行处理任务
+func (s *VideoService) remoteWorkerEnabled() bool {
+	return s.transcodeQueue != nil && s.config.IsRemoteWorkerMode()
+}
+
+// prepareWorkerJob 获取任务对应的视频信息，视频已归档时先恢复，worker直接从视频存储桶读取
+func (s *VideoService) prepareWorkerJob(ctx context.Context, job transcode.Job) (*api.WorkerJob, error) {
+	meta, err := s.metadataService.GetMetadata(ctx, job.VideoID)
+	if err != nil {
+		return nil, fmt.Errorf("获取视频元数据失败: %w", err)
+	}
+	meta, err = s.restoreArchivedVideo(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.WorkerJob{
+		JobID:          job.ID,
+		Kind:           job.Kind,
+		Attempts:       int32(job.Attempts),
+		LeaseExpiresAt: job.LeaseExpiresAt.UnixMilli(),
+		Video: &api.WorkerVideo{
+			VideoID:    meta.FileID,
+			BucketName: meta.BucketName,
+			ObjectName: meta.ObjectName,
+			Resolution: fmt.Sprintf("%dx%d", meta.Width, meta.Height),
+			Width:      int32(meta.Width),
+			Height:     int32(meta.Height),
+			Rotation:   int32(meta.Rotation),
+			Bitrate:    meta.Bitrate,
+			Duration:   meta.Duration,
+			Preview:    meta.Preview,
+			CreatedAt:  meta.CreatedAt.UnixMilli(),
+		},
+	}, nil
+}
+
+// applyWorkerResult 处理执行成功的任务结果，与本进程执行时发布相同的事件，视频已删除时忽略
+func (s *VideoService) applyWorkerResult(ctx context.Context, job transcode.Job, req *api.WorkerJobCompleteRequest) {
+	meta, err := s.metadataService.GetMetadata(ctx, job.VideoID)
+	if err != nil {
+		return
+	}
+
+	if result := req.GetPackage(); result != nil {
+		s.publishEvent(notify.EventTranscodeFinished, meta, &streaming.PackageResult{
+			VideoID:        job.VideoID,
+			MasterPlaylist: result.MasterPlaylist,
+			Manifest:       result.GetManifest(),
+			Renditions:     result.Renditions,
+			SegmentCount:   int(result.SegmentCount),
+			PackagedAt:     time.Now(),
+		})
+	}
+
+	if sprite := req.GetSprite(); sprite != nil {
+		s.publishEvent(notify.EventSpriteReady, meta, &video.SpriteSheetResult{
+			Width:      int(sprite.Width),
+			Height:     int(sprite.Height),
+			Columns:    int(sprite.Columns),
+			Rows:       int(sprite.Rows),
+			TileWidth:  int(sprite.TileWidth),
+			TileHeight: int(sprite.TileHeight),
+			Frames:     int(sprite.Frames),
+			Interval:   sprite.Interval,
+		})
+	}
+
+	if req.IsSetPreviewPath() {
+		previewPath := req.GetPreviewPath()
+		if err := s.savePreviewPath(ctx, job.VideoID, previewPath); err != nil {
+			fmt.Printf("保存动态预览失败(%s): %v\n", job.VideoID, err)
+			return
+		}
+		s.publishEvent(notify.EventPreviewReady, meta, map[string]string{"preview_path": previewPath})
+	}
+}
+
+// workerJobQueueErrorResponse 将转码队列错误转换为错误响应
+func (s *VideoService) workerJobQueueErrorResponse(err error) *api.WorkerJobResponse {
+	switch {
+	case errors.Is(err, transcode.ErrJobNotFound):
+		return s.workerJobErrorResponse(6903, "处理任务不存在")
+	case errors.Is(err, transcode.ErrLeaseLost):
+		return s.workerJobErrorResponse(6904, err.Error())
+	default:
+		return s.workerJobErrorResponse(6901, err.Error())
+	}
+}
+
+// workerJobResponse 创建处理任务成功响应
+func (s *VideoService) workerJobResponse(job *api.WorkerJob, message string) *api.WorkerJobResponse {
+	return &api.WorkerJobResponse{
+		Base: &api.BaseResponse{
+			Code:    0,
+			Message: message,
+		},
+		Job: job,
+	}
+}
+
+// workerJobErrorResponse 创建处理任务错误响应
+func (s *VideoService) workerJobErrorResponse(code int32, message string) *api.WorkerJobResponse {
+	return &api.WorkerJobResponse{
+		Base: &api.BaseResponse{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// JobProcessor 远程worker的任务执行器，在worker进程中执行HLS打包和预览图生成
+// 结果直接写入与API服务共享的存储，元数据由API服务在上报结果时更新
+type JobProcessor struct {
+	service *VideoService
+}
+
+// NewJobProcessor 使用worker的配置、存储客户端和视频处理组件创建任务执行器
+func NewJobProcessor(cfg *config.Config, storageClient storage.StorageInterface, components *VideoComponents) (*JobProcessor, error) {
+	if cfg == nil || storageClient == nil || components == nil {
+		return nil, fmt.Errorf("任务执行器的依赖不能为空")
+	}
+	components.TempFiles.Start()
+
+	return &JobProcessor{
+		service: &VideoService{
+			config:             cfg,
+			storageClient:      storageClient,
+			buckets:            cfg.GetBucketResolver(),
+			thumbnailGenerator: components.ThumbnailGenerator,
+			hlsPackager:        newHLSPackager(cfg, storageClient, components.TempFiles),
+			tempFiles:          components.TempFiles,
+		},
+	}, nil
+}
+
+// Kinds 本机可以执行的任务类型，ffmpeg不可用时为空
+func (p *JobProcessor) Kinds() []string {
+	var kinds []string
+	if p.service.hlsPackager != nil && p.service.hlsPackager.IsAvailable() {
+		kinds = append(kinds, transcode.KindHLS)
+	}
+	if p.service.thumbnailGenerator.CanExtractFrames() {
+		kinds = append(kinds, transcode.KindPreview)
+	}
+	return kinds
+}
+
+// Process 执行领取到的任务并返回上报结果的请求，ctx在任务被取消时取消
+func (p *JobProcessor) Process(ctx context.Context, job *api.WorkerJob) *api.WorkerJobCompleteRequest {
+	req := &api.WorkerJobCompleteRequest{JobID: job.JobID}
+	info := job.GetVideo()
+	if info == nil {
+		message := "任务缺少视频信息"
+		req.Error = &message
+		return req
+	}
+
+	meta := workerVideoMetadata(info)
+	var err error
+	switch job.Kind {
+	case transcode.KindHLS:
+		req.Package, err = p.packageVideo(ctx, meta)
+	case transcode.KindPreview:
+		err = p.generatePreviews(ctx, meta, req)
+	default:
+		err = transcode.ErrInvalidKind
+	}
+	if err != nil {
+		message := err.Error()
+		req.Error = &message
+	}
+	return req
+}
+
+// workerVideoMetadata 根据任务中的视频信息构造处理用的元数据
+func workerVideoMetadata(info *api.WorkerVideo) *metadata.FileMetadata {
+	meta := &metadata.FileMetadata{
+		FileID:     info.VideoID,
+		BucketName: info.BucketName,
+		ObjectName: info.ObjectName,
+		Width:      int(info.Width),
+		Height:     int(info.Height),
+		Rotation:   int(info.Rotation),
+		Bitrate:    info.Bitrate,
+		Duration:   info.Duration,
+		Preview:    info.Preview,
+		CreatedAt:  time.UnixMilli(info.CreatedAt),
+	}
+	// 旧版本API服务下发的任务只有"宽x高"格式的分辨率
+	if meta.Width == 0 && meta.Height == 0 && info.Resolution != "" {
+		fmt.Sscanf(info.Resolution, "%dx%d", &meta.Width, &meta.Height)
+	}
+	return meta
+}
+
+// packageVideo 进行HLS打包
+func (p *JobProcessor) packageVideo(ctx context.Context, meta *metadata.FileMetadata) (*api.WorkerPackageResult, error) {
+	if p.service.hlsPackager == nil {
+		return nil, fmt.Errorf("HLS流媒体未启用")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, hlsPackageTimeout)
+	defer cancel()
+
+	result, err := p.service.hlsPackager.Package(ctx, p.service.packageRequest(meta))
+	if err != nil {
+		return nil, err
+	}
+
+	packageResult := &api.WorkerPackageResult{
+		MasterPlaylist: result.MasterPlaylist,
+		Renditions:     result.Renditions,
+		SegmentCount:   int32(result.SegmentCount),
+	}
+	if result.Manifest != "" {
+		packageResult.Manifest = &result.Manifest
+	}
+	return packageResult, nil
+}
+
+// generatePreviews 生成进度条预览图，视频没有动态预览时一并生成并上传
+// 进度条预览图生成失败时任务失败，动态预览失败时只记录日志
+func (p *JobProcessor) generatePreviews(ctx context.Context, meta *metadata.FileMetadata, req *api.WorkerJobCompleteRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, previewGenerateTimeout)
+	defer cancel()
+
+	videoPath, err := p.service.downloadVideoToTemp(ctx, meta)
+	if err != nil {
+		return err
+	}
+	defer p.service.tempFiles.Remove(videoPath)
+
+	result, err := p.service.generateSprite(ctx, meta, videoPath)
+	if err != nil {
+		return fmt.Errorf("生成进度条预览图失败: %w", err)
+	}
+	req.Sprite = &api.WorkerSpriteResult{
+		Width:      int32(result.Width),
+		Height:     int32(result.Height),
+		Columns:    int32(result.Columns),
+		Rows:       int32(result.Rows),
+		TileWidth:  int32(result.TileWidth),
+		TileHeight: int32(result.TileHeight),
+		Frames:     int32(result.Frames),
+		Interval:   result.Interval,
+	}
+
+	if meta.Preview == "" {
+		previewPath, err := p.service.uploadAnimatedPreview(ctx, meta, videoPath)
+		if err != nil {
+			fmt.Printf("生成动态预览失败(%s): %v\n", meta.FileID, err)
+		} else {
+			req.PreviewPath = &previewPath
+		}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/transcode"
+)
+
+// createWorkerTestService 创建远程worker模式的测试服务
+func createWorkerTestService(t *testing.T) *VideoService {
+	service := createStreamTestService(t)
+	service.config.Streaming.MaxAttempts = 3
+	service.config.Worker = config.WorkerConfig{
+		Mode:         config.WorkerModeRemote,
+		Secret:       "worker-secret",
+		LeaseTimeout: "1m",
+	}
+	require.NoError(t, service.startTranscodeQueue())
+	t.Cleanup(service.transcodeQueue.Close)
+	return service
+}
+
+func TestVideoService_WorkerJobs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("未启用远程worker模式", func(t *testing.T) {
+		service := createStreamTestService(t)
+
+		resp, err := service.ClaimWorkerJob(ctx, &api.WorkerJobClaimRequest{WorkerID: "worker1"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6902), resp.Base.Code)
+
+		resp, err = service.HeartbeatWorkerJob(ctx, &api.WorkerJobHeartbeatRequest{JobID: "job1", WorkerID: "worker1"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6902), resp.Base.Code)
+	})
+
+	t.Run("参数验证", func(t *testing.T) {
+		service := createWorkerTestService(t)
+
+		resp, err := service.ClaimWorkerJob(ctx, &api.WorkerJobClaimRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6901), resp.Base.Code)
+
+		resp, err = service.ClaimWorkerJob(ctx, &api.WorkerJobClaimRequest{WorkerID: "worker1", Kinds: []string{"unknown"}})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6901), resp.Base.Code, "未知的任务类型应该返回6901")
+
+		resp, err = service.CompleteWorkerJob(ctx, &api.WorkerJobCompleteRequest{JobID: "job1"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6901), resp.Base.Code)
+	})
+
+	t.Run("领取任务并续租", func(t *testing.T) {
+		service := createWorkerTestService(t)
+
+		resp, err := service.ClaimWorkerJob(ctx, &api.WorkerJobClaimRequest{WorkerID: "worker1"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code)
+		assert.Nil(t, resp.Job, "没有待执行的任务时job应该为空")
+
+		width, height := 1920, 1080
+		require.NoError(t, service.metadataService.UpdateMetadata(ctx, &metadata.UpdateMetadataRequest{FileID: "video1", Width: &width, Height: &height}))
+		queued, err := service.transcodeQueue.Enqueue(transcode.KindHLS, "video1", transcode.DefaultPriority)
+		require.NoError(t, err)
+
+		resp, err = service.ClaimWorkerJob(ctx, &api.WorkerJobClaimRequest{WorkerID: "worker1", Kinds: []string{transcode.KindPreview}})
+		require.NoError(t, err)
+		assert.Nil(t, resp.Job, "不应该领取其他类型的任务")
+
+		resp, err = service.ClaimWorkerJob(ctx, &api.WorkerJobClaimRequest{WorkerID: "worker1", Kinds: []string{transcode.KindHLS}})
+		require.NoError(t, err)
+		require.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+		require.NotNil(t, resp.Job)
+		assert.Equal(t, queued.ID, resp.Job.JobID)
+		assert.Equal(t, transcode.KindHLS, resp.Job.Kind)
+		assert.Equal(t, int32(1), resp.Job.Attempts)
+		require.NotNil(t, resp.Job.Video)
+		assert.Equal(t, "videos/2025/08/video1.mp4", resp.Job.Video.ObjectName)
+		assert.Equal(t, int32(1920), resp.Job.Video.Width)
+		assert.Equal(t, int32(1080), resp.Job.Video.Height)
+		assert.Equal(t, "1920x1080", resp.Job.Video.Resolution, "保留字符串格式的分辨率，兼容旧版本worker")
+
+		resp, err = service.HeartbeatWorkerJob(ctx, &api.WorkerJobHeartbeatRequest{JobID: queued.ID, WorkerID: "worker1"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code)
+
+		resp, err = service.HeartbeatWorkerJob(ctx, &api.WorkerJobHeartbeatRequest{JobID: queued.ID, WorkerID: "worker2"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6904), resp.Base.Code, "其他worker不能续租")
+
+		resp, err = service.HeartbeatWorkerJob(ctx, &api.WorkerJobHeartbeatRequest{JobID: "missing", WorkerID: "worker1"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6903), resp.Base.Code)
+
+		_, err = service.transcodeQueue.Cancel(queued.ID)
+		require.NoError(t, err)
+		resp, err = service.HeartbeatWorkerJob(ctx, &api.WorkerJobHeartbeatRequest{JobID: queued.ID, WorkerID: "worker1"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6904), resp.Base.Code, "任务取消后租约应该失效")
+	})
+
+	t.Run("视频已删除的任务按失败处理", func(t *testing.T) {
+		service := createWorkerTestService(t)
+		queued, err := service.transcodeQueue.Enqueue(transcode.KindHLS, "missing", transcode.DefaultPriority)
+		require.NoError(t, err)
+
+		resp, err := service.ClaimWorkerJob(ctx, &api.WorkerJobClaimRequest{WorkerID: "worker1"})
+		require.NoError(t, err)
+		assert.Nil(t, resp.Job)
+
+		job, err := service.transcodeQueue.Get(queued.ID)
+		require.NoError(t, err)
+		assert.Equal(t, transcode.StatusRetrying, job.Status)
+		assert.NotEmpty(t, job.Error)
+	})
+
+	t.Run("上报成功结果保存动态预览", func(t *testing.T) {
+		service := createWorkerTestService(t)
+		queued, err := service.transcodeQueue.Enqueue(transcode.KindPreview, "video1", transcode.DefaultPriority)
+		require.NoError(t, err)
+		resp, err := service.ClaimWorkerJob(ctx, &api.WorkerJobClaimRequest{WorkerID: "worker1"})
+		require.NoError(t, err)
+		require.NotNil(t, resp.Job)
+
+		previewPath := "previews/video1.webp"
+		resp, err = service.CompleteWorkerJob(ctx, &api.WorkerJobCompleteRequest{
+			JobID:       queued.ID,
+			WorkerID:    "worker1",
+			Sprite:      &api.WorkerSpriteResult{Width: 1600, Height: 900, Columns: 10, Rows: 10, Frames: 100, Interval: 1},
+			PreviewPath: &previewPath,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code, resp.Base.Message)
+
+		job, err := service.transcodeQueue.Get(queued.ID)
+		require.NoError(t, err)
+		assert.Equal(t, transcode.StatusSucceeded, job.Status)
+		meta, err := service.metadataService.GetMetadata(ctx, "video1")
+		require.NoError(t, err)
+		assert.Equal(t, previewPath, meta.Preview)
+
+		resp, err = service.CompleteWorkerJob(ctx, &api.WorkerJobCompleteRequest{JobID: queued.ID, WorkerID: "worker1"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6904), resp.Base.Code, "已结束的任务不能重复上报")
+	})
+
+	t.Run("上报失败结果", func(t *testing.T) {
+		service := createWorkerTestService(t)
+		queued, err := service.transcodeQueue.Enqueue(transcode.KindHLS, "video1", transcode.DefaultPriority)
+		require.NoError(t, err)
+		_, err = service.ClaimWorkerJob(ctx, &api.WorkerJobClaimRequest{WorkerID: "worker1"})
+		require.NoError(t, err)
+
+		message := "ffmpeg执行失败"
+		resp, err := service.CompleteWorkerJob(ctx, &api.WorkerJobCompleteRequest{JobID: queued.ID, WorkerID: "worker1", Error: &message})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Base.Code)
+
+		job, err := service.transcodeQueue.Get(queued.ID)
+		require.NoError(t, err)
+		assert.Equal(t, transcode.StatusRetrying, job.Status)
+		assert.Equal(t, message, job.Error)
+	})
+}
+
+func TestWorkerVideoMetadata(t *testing.T) {
+	meta := workerVideoMetadata(&api.WorkerVideo{VideoID: "video1", Width: 1080, Height: 1920, Rotation: 90})
+	assert.Equal(t, 1080, meta.Width)
+	assert.Equal(t, 1920, meta.Height)
+	assert.Equal(t, 90, meta.Rotation)
+
+	meta = workerVideoMetadata(&api.WorkerVideo{VideoID: "video1", Resolution: "1280x720"})
+	assert.Equal(t, 1280, meta.Width, "旧版本API服务下发的任务从分辨率字符串解析宽高")
+	assert.Equal(t, 720, meta.Height)
+}
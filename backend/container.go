@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/manteia/zhulong/biz/service"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// defaultConfigFile 未设置ZHULONG_CONFIG_FILE环境变量时使用的配置文件
+const defaultConfigFile = "../config/development.yml"
+
+// container 服务依赖容器，集中创建配置、存储客户端、元数据服务和业务服务
+type container struct {
+	config          *config.Config
+	storage         storage.StorageInterface
+	metadata        *metadata.MetadataService
+	videoComponents *service.VideoComponents
+	videoService    *service.VideoService
+	userService     *service.UserService
+}
+
+// configFile 获取配置文件路径，优先使用ZHULONG_CONFIG_FILE环境变量
+func configFile() string {
+	if file := os.Getenv("ZHULONG_CONFIG_FILE"); file != "" {
+		return file
+	}
+	return defaultConfigFile
+}
+
+// newContainer 根据配置创建服务依赖
+func newContainer(cfg *config.Config) (*container, error) {
+	storageClient, err := storage.NewFromConfig(cfg.GetDriverConfig())
+	if err != nil {
+		return nil, fmt.Errorf("初始化存储客户端失败: %w", err)
+	}
+	components, err := service.NewVideoComponents(cfg)
+	if err != nil {
+		return nil, err
+	}
+	metadataService := metadata.NewMetadataService()
+
+	videoService, err := service.NewVideoService(cfg, storageClient, metadataService, components)
+	if err != nil {
+		return nil, fmt.Errorf("初始化视频服务失败: %w", err)
+	}
+	userService, err := service.NewUserService(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("初始化用户服务失败: %w", err)
+	}
+
+	return &container{
+		config:          cfg,
+		storage:         storageClient,
+		metadata:        metadataService,
+		videoComponents: components,
+		videoService:    videoService,
+		userService:     userService,
+	}, nil
+}
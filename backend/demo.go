@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/manteia/zhulong/biz/service"
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/video"
+)
+
+// demoFlag 演示模式启动参数
+const demoFlag = "--demo"
+
+// 演示模式的初始管理员账号
+const (
+	demoUsername = "demo"
+	demoPassword = "demo123456"
+)
+
+// demoClipDuration 示例视频时长
+const demoClipDuration = 10 * time.Second
+
+// demoClip 演示模式预置的示例视频，使用FFmpeg的lavfi测试源生成
+type demoClip struct {
+	fileName    string
+	title       string
+	description string
+	tags        []string
+	source      string // lavfi视频源
+	width       int
+	height      int
+}
+
+// demoClips 演示模式预置的示例视频
+var demoClips = []demoClip{
+	{"color-bars.mp4", "彩条测试图", "SMPTE高清彩条和1kHz测试音，用于检查画面色彩和声道", []string{"演示", "测试图"}, "smptehdbars", 1280, 720},
+	{"test-pattern.mp4", "动态测试图", "带有帧计数和时间码的动态测试图，用于检查拖动播放和帧率", []string{"演示", "测试图"}, "testsrc2", 1280, 720},
+	{"mandelbrot.mp4", "曼德博集合", "逐渐放大的曼德博集合分形动画", []string{"演示", "动画"}, "mandelbrot", 640, 360},
+}
+
+// enableDemoMode 演示模式使用内存存储驱动，关闭备份和存储复制，不依赖MinIO或本地目录
+// 通过环境变量覆盖配置文件，配置热加载后仍然生效；视频、用户和元数据都只保存在进程内存中
+func enableDemoMode() {
+	os.Setenv("ZHULONG_STORAGE_DRIVER", storage.DriverMemory)
+	os.Setenv("ZHULONG_BACKUP_ENABLED", "false")
+	os.Setenv("ZHULONG_REPLICATION_ENABLED", "false")
+	os.Setenv("ZHULONG_ADMIN_USERNAME", demoUsername)
+	os.Setenv("ZHULONG_ADMIN_PASSWORD", demoPassword)
+}
+
+// seedDemo 以演示管理员账号写入示例视频，账号作为初始管理员在启动时创建
+// FFmpeg可用时生成可以播放的测试视频，否则写入只有文件头的占位视频，可以浏览视频库但不能播放
+func seedDemo(deps *container) error {
+	ctx := context.Background()
+	admin, err := deps.userService.GetUserByUsername(ctx, demoUsername)
+	if err != nil {
+		return fmt.Errorf("获取演示账号失败: %w", err)
+	}
+
+	ffmpegPath := deps.config.Streaming.FFmpegPath
+	if _, err := exec.LookPath(ffmpegPath); err != nil {
+		ffmpegPath = ""
+		fmt.Println("演示模式: 未找到FFmpeg，示例视频为占位文件，不能播放")
+	}
+
+	seeded := 0
+	for _, clip := range demoClips {
+		demo := &service.DemoVideo{
+			FileName:    clip.fileName,
+			Title:       clip.title,
+			Description: clip.description,
+			Tags:        clip.tags,
+			CreatedBy:   admin.ID,
+		}
+		if ffmpegPath != "" {
+			demo.Data, err = generateDemoClip(ctx, deps, ffmpegPath, clip)
+			if err != nil {
+				fmt.Printf("演示模式: 生成示例视频%s失败，使用占位文件: %v\n", clip.fileName, err)
+			}
+		}
+		if len(demo.Data) == 0 {
+			demo.Data = placeholderMP4(clip.title)
+			demo.Info = &video.VideoInfo{Duration: demoClipDuration, Width: clip.width, Height: clip.height}
+		}
+
+		if _, err := deps.videoService.SeedDemoVideo(ctx, demo); err != nil {
+			return fmt.Errorf("写入示例视频%s失败: %w", clip.fileName, err)
+		}
+		seeded++
+	}
+
+	fmt.Printf("演示模式: 数据只保存在内存中，已写入%d个示例视频，管理员账号 %s / %s\n", seeded, demoUsername, demoPassword)
+	return nil
+}
+
+// generateDemoClip 使用FFmpeg的测试源生成带有测试音的H.264/AAC示例视频
+// MP4的moov需要回写，先输出到临时文件再读入内存
+func generateDemoClip(ctx context.Context, deps *container, ffmpegPath string, clip demoClip) ([]byte, error) {
+	file, err := deps.videoComponents.TempFiles.CreateTemp("demo-*.mp4")
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+	defer deps.videoComponents.TempFiles.Remove(file.Name())
+
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	duration := strconv.FormatFloat(demoClipDuration.Seconds(), 'f', -1, 64)
+	cmd := exec.CommandContext(ctx, ffmpegPath, "-y", "-v", "error",
+		"-f", "lavfi", "-i", fmt.Sprintf("%s=size=%dx%d:rate=25", clip.source, clip.width, clip.height),
+		"-f", "lavfi", "-i", "sine=frequency=1000:sample_rate=48000",
+		"-t", duration,
+		"-c:v", "libx264", "-pix_fmt", "yuv420p", "-preset", "veryfast",
+		"-c:a", "aac", "-b:a", "128k",
+		"-movflags", "+faststart",
+		file.Name())
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, bytes.TrimSpace(output))
+	}
+	return os.ReadFile(file.Name())
+}
+
+// placeholderMP4 生成只有ftyp和free box的占位MP4，free box写入标题使每个占位文件的内容不同
+func placeholderMP4(title string) []byte {
+	var buf bytes.Buffer
+	writeBox := func(boxType string, payload []byte) {
+		binary.Write(&buf, binary.BigEndian, uint32(8+len(payload)))
+		buf.WriteString(boxType)
+		buf.Write(payload)
+	}
+	writeBox("ftyp", []byte("isom\x00\x00\x02\x00isomiso2avc1mp41"))
+	writeBox("free", []byte(title))
+	return buf.Bytes()
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/pkg/importer"
+)
+
+// importCommand 批量导入子命令名称
+const importCommand = "import"
+
+// importPollInterval 查询导入进度的间隔
+const importPollInterval = 2 * time.Second
+
+// runImport 请求运行中的服务导入服务器目录中的视频并等待完成，返回进程退出码
+// 视频元数据保存在服务进程内存中，导入由服务执行，目录路径按服务所在主机解析
+func runImport(args []string) int {
+	flags := flag.NewFlagSet(importCommand, flag.ContinueOnError)
+	server := flags.String("server", "http://localhost:8888", "服务地址")
+	token := flags.String("token", os.Getenv("ZHULONG_TOKEN"), "管理员访问令牌，默认读取ZHULONG_TOKEN环境变量")
+	register := flags.Bool("register", false, "仅登记local存储视频存储桶目录中的文件，不复制")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 || *token == "" {
+		fmt.Fprintf(os.Stderr, "用法: zhulong %s [-server <服务地址>] [-token <令牌>] [-register] <视频目录>\n", importCommand)
+		return 2
+	}
+
+	root, err := filepath.Abs(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "解析导入目录失败: %v\n", err)
+		return 1
+	}
+
+	client := &importClient{baseURL: strings.TrimRight(*server, "/"), token: *token}
+	job, err := client.start(&api.VideoImportRequest{Path: root, Register: *register})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "发起批量导入失败: %v\n", err)
+		return 1
+	}
+	fmt.Printf("开始导入 %s\n", job.Path)
+
+	// 中断命令只停止查询进度，服务端的导入继续执行
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ticker := time.NewTicker(importPollInterval)
+	defer ticker.Stop()
+	for job.FinishedAt == nil {
+		select {
+		case <-ctx.Done():
+			fmt.Println("已停止查询进度，导入在服务端继续执行")
+			return 1
+		case <-ticker.C:
+		}
+
+		job, err = client.progress()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "获取导入进度失败: %v\n", err)
+			return 1
+		}
+		printImportProgress(job)
+	}
+
+	if job.Status != importer.StatusCompleted {
+		fmt.Fprintln(os.Stderr, "批量导入失败")
+		return 1
+	}
+	if job.Failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d个文件导入失败，重新运行命令将跳过已导入的文件\n", job.Failed)
+		return 1
+	}
+	fmt.Println("批量导入完成")
+	return 0
+}
+
+// importClient 批量导入接口客户端
+type importClient struct {
+	baseURL string
+	token   string
+}
+
+// start 发起批量导入
+func (c *importClient) start(req *api.VideoImportRequest) (*api.VideoImport, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("编码请求失败: %w", err)
+	}
+	return c.do(http.MethodPost, bytes.NewReader(body))
+}
+
+// progress 获取批量导入进度
+func (c *importClient) progress() (*api.VideoImport, error) {
+	return c.do(http.MethodGet, nil)
+}
+
+// do 调用批量导入接口
+func (c *importClient) do(method string, body io.Reader) (*api.VideoImport, error) {
+	req, err := http.NewRequest(method, c.baseURL+"/api/v1/videos/import", body)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求服务失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result api.VideoImportResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析响应失败(HTTP %d): %w", resp.StatusCode, err)
+	}
+	if result.Base == nil || result.Base.Code != 0 || result.ImportJob == nil {
+		if result.Base != nil {
+			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, result.Base.Message)
+		}
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return result.ImportJob, nil
+}
+
+// printImportProgress 输出导入进度
+func printImportProgress(job *api.VideoImport) {
+	processed := job.Imported + job.Skipped + job.Failed
+	fmt.Printf("  [%s] %d/%d 导入%d 跳过%d 失败%d 已导入%d字节\n",
+		job.Status, processed, job.Total, job.Imported, job.Skipped, job.Failed, job.BytesImported)
+	if job.Error != nil {
+		fmt.Printf("  最近错误: %s\n", *job.Error)
+	}
+}
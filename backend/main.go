@@ -3,12 +3,133 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/cloudwego/hertz/pkg/app/server"
+	hertzconfig "github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/network/standard"
+	api "github.com/manteia/zhulong/biz/handler/zhulong/api"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/middleware"
 )
 
 func main() {
-	h := server.Default()
+	if len(os.Args) > 1 && os.Args[1] == migrateStorageCommand {
+		os.Exit(runMigrateStorage(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == importCommand {
+		os.Exit(runImport(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == backupCommand {
+		os.Exit(runBackup(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == workerCommand {
+		os.Exit(runWorker(os.Args[2:]))
+	}
+	demo := len(os.Args) > 1 && os.Args[1] == demoFlag
+	if demo {
+		enableDemoMode()
+	}
+
+	configManager := config.NewManager(configFile())
+	if err := configManager.Load(); err != nil {
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+		os.Exit(1)
+	}
+	cfg := configManager.GetConfig()
+	// 配置加载时已校验日志级别
+	logLevel, _ := cfg.GetLogLevel()
+	hlog.SetLevel(logLevel)
+	deps, err := newContainer(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if demo {
+		if err := seedDemo(deps); err != nil {
+			fmt.Fprintf(os.Stderr, "初始化演示数据失败: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	// 上传请求中较大的文件由multipart解析缓冲到系统临时目录，指向受管理的临时目录以统计占用并清理残留文件
+	if err := os.Setenv("TMPDIR", deps.videoComponents.TempFiles.Dir()); err != nil {
+		hlog.Warnf("设置临时目录失败: %v", err)
+	}
+	// 路由注册时会读取限流策略和令牌解析器，需要先注入服务
+	api.SetServices(deps.videoService, deps.userService)
+
+	corsPolicy, err := cfg.GetCORSPolicy()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "跨域配置无效: %v\n", err)
+		os.Exit(1)
+	}
+
+	proxyPolicy, err := cfg.GetProxyPolicy()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "受信任代理配置无效: %v\n", err)
+		os.Exit(1)
+	}
+
+	tlsConfig, err := serverTLSConfig(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "TLS配置无效: %v\n", err)
+		os.Exit(1)
+	}
+
+	options := []hertzconfig.Option{
+		server.WithHostPorts(cfg.GetServerAddress()),
+		// 以流的方式读取请求体，大文件上传时不在内存中缓存完整请求
+		server.WithStreamBody(true),
+		server.WithMaxRequestBodySize(2 * 1024 * 1024 * 1024),
+		// 客户端断开连接时取消请求的上下文，正在进行的存储操作随之中断
+		server.WithSenseClientDisconnection(true),
+	}
+	if tlsConfig != nil {
+		// 默认的netpoll传输层不支持TLS，启用HTTPS时使用标准库传输层
+		options = append(options, server.WithTLS(tlsConfig), server.WithTransport(standard.NewTransporter))
+	}
+	h := server.Default(options...)
+
+	// 客户端IP（限流）和请求的协议、主机（生成绝对地址）只在请求来自受信任代理时使用X-Forwarded-*请求头
+	h.SetClientIPFunc(proxyPolicy.ClientIPFunc())
+	h.Use(middleware.ForwardedHeaders(proxyPolicy))
+	// 请求ID需要在路由注册前添加，以覆盖所有路由
+	h.Use(middleware.RequestID())
+	// 跨域中间件需要全局注册，未匹配路由的OPTIONS预检请求也由它直接响应
+	h.Use(middleware.CORS(corsPolicy))
+	h.Use(middleware.SecurityHeaders())
+	// 按Accept-Language协商响应语言并替换错误消息
+	h.Use(middleware.Localize(cfg.Server.DefaultLanguage))
+	// 捕获处理函数的panic并返回统一的500响应，放在请求ID和本地化之后，响应带有trace_id并按语言返回消息
+	// server.Default自带的恢复中间件仍作为最外层兜底
+	h.Use(middleware.Recovery())
+	// 存储操作超时或熔断导致的内部错误返回504或503和对应的错误码，同样需要放在本地化之后
+	h.Use(middleware.StorageFailure())
 
 	register(h)
+
+	// 上传限制、限流和日志级别在配置文件修改后立即生效，其他配置需要重启
+	configManager.Subscribe(applyConfigChange)
+	if err := configManager.StartAutoReload(func(err error) {
+		hlog.Warnf("重新加载配置失败，继续使用当前配置: %v", err)
+	}); err != nil {
+		hlog.Warnf("监听配置文件失败，修改配置后需要重启: %v", err)
+	}
+
 	h.Spin()
 }
+
+// applyConfigChange 将重新加载的配置应用到运行中的服务
+func applyConfigChange(previous, current *config.Config) {
+	// 配置验证时已校验日志级别
+	logLevel, _ := current.GetLogLevel()
+	hlog.SetLevel(logLevel)
+
+	if err := api.ApplyConfig(current); err != nil {
+		hlog.Warnf("应用重新加载的配置失败: %v", err)
+		return
+	}
+	hlog.Infof("配置已重新加载")
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/migrate"
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// migrateStorageCommand 存储迁移子命令名称
+const migrateStorageCommand = "migrate-storage"
+
+// migrateProgressInterval 迁移进度输出间隔
+const migrateProgressInterval = 5 * time.Second
+
+// runMigrateStorage 将源配置的存储驱动和存储桶中的文件复制到目标配置，返回进程退出码
+// 视频元数据保存在服务进程内存中，命令行迁移只复制文件；运行中的服务迁移存储桶请使用管理接口
+func runMigrateStorage(args []string) int {
+	flags := flag.NewFlagSet(migrateStorageCommand, flag.ContinueOnError)
+	from := flags.String("from", "", "源配置文件")
+	to := flags.String("to", "", "目标配置文件")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *from == "" || *to == "" {
+		fmt.Fprintf(os.Stderr, "用法: zhulong %s -from <源配置文件> -to <目标配置文件>\n", migrateStorageCommand)
+		return 2
+	}
+
+	sourceConfig, sourceStorage, err := loadMigrationStorage(*from)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载源配置失败: %v\n", err)
+		return 1
+	}
+	targetConfig, targetStorage, err := loadMigrationStorage(*to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载目标配置失败: %v\n", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	failed := 0
+	for _, pair := range migrate.PlanBuckets(sourceConfig, targetConfig) {
+		migrator, err := migrate.NewMigrator(
+			migrate.Endpoint{Storage: sourceStorage, Bucket: pair.Source},
+			migrate.Endpoint{Storage: targetStorage, Bucket: pair.Target},
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "创建存储迁移失败(%s -> %s): %v\n", pair.Source, pair.Target, err)
+			return 1
+		}
+
+		fmt.Printf("开始迁移存储桶 %s -> %s\n", pair.Source, pair.Target)
+		count, err := runWithProgress(ctx, migrator)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "迁移存储桶失败(%s -> %s): %v\n", pair.Source, pair.Target, err)
+			return 1
+		}
+		failed += count
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d个文件迁移失败，重新运行命令将跳过已复制的文件\n", failed)
+		return 1
+	}
+	fmt.Println("存储迁移完成")
+	return 0
+}
+
+// loadMigrationStorage 加载配置文件并创建存储驱动
+func loadMigrationStorage(configFile string) (*config.Config, storage.StorageInterface, error) {
+	cfg, err := config.LoadFromFile(configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	storageClient, err := storage.NewFromConfig(cfg.GetDriverConfig())
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, storageClient, nil
+}
+
+// runWithProgress 执行迁移并定期输出进度
+func runWithProgress(ctx context.Context, migrator *migrate.Migrator) (int, error) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(migrateProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				printMigrationProgress(migrator.Progress())
+			}
+		}
+	}()
+
+	failed, err := migrator.Run(ctx)
+	close(done)
+	printMigrationProgress(migrator.Progress())
+	return failed, err
+}
+
+// printMigrationProgress 输出迁移进度
+func printMigrationProgress(progress migrate.Progress) {
+	processed := progress.Copied + progress.Skipped + progress.Failed
+	fmt.Printf("  [%s] %d/%d 复制%d 跳过%d 失败%d 已复制%d字节\n",
+		progress.Status, processed, progress.Total, progress.Copied, progress.Skipped, progress.Failed, progress.BytesCopied)
+	if progress.Error != "" {
+		fmt.Printf("  最近错误: %s\n", progress.Error)
+	}
+}
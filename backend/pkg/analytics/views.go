@@ -0,0 +1,153 @@
+package analytics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 播放统计的时间粒度和保留时长
+const (
+	BucketSize = time.Hour           // 按小时汇总播放次数
+	Retention  = 90 * 24 * time.Hour // 超过保留时长的小时统计会被清理，总播放次数不受影响
+)
+
+// VideoViews 视频在统计窗口内的播放次数
+type VideoViews struct {
+	VideoID string `json:"video_id"` // 视频ID
+	Views   int64  `json:"views"`    // 播放次数
+}
+
+// videoStats 单个视频的播放统计
+type videoStats struct {
+	total      int64           // 总播放次数
+	buckets    map[int64]int64 // 小时起始Unix时间戳 -> 播放次数
+	lastViewed time.Time       // 最近一次播放时间
+}
+
+// ViewTracker 视频播放次数统计
+type ViewTracker struct {
+	videos map[string]*videoStats // 视频ID -> 播放统计
+	now    func() time.Time
+	mutex  sync.RWMutex
+}
+
+// NewViewTracker 创建播放次数统计
+func NewViewTracker() *ViewTracker {
+	return &ViewTracker{
+		videos: make(map[string]*videoStats),
+		now:    time.Now,
+	}
+}
+
+// RecordView 记录一次播放
+func (t *ViewTracker) RecordView(ctx context.Context, videoID string) {
+	now := t.now()
+	bucket := now.Truncate(BucketSize).Unix()
+	expired := now.Add(-Retention).Truncate(BucketSize).Unix()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	stats, exists := t.videos[videoID]
+	if !exists {
+		stats = &videoStats{buckets: make(map[int64]int64)}
+		t.videos[videoID] = stats
+	}
+	stats.total++
+	stats.buckets[bucket]++
+	stats.lastViewed = now
+
+	// 记录时顺便清理该视频过期的小时统计
+	for start := range stats.buckets {
+		if start < expired {
+			delete(stats.buckets, start)
+		}
+	}
+}
+
+// ViewCount 获取视频的总播放次数
+func (t *ViewTracker) ViewCount(ctx context.Context, videoID string) int64 {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	if stats, exists := t.videos[videoID]; exists {
+		return stats.total
+	}
+	return 0
+}
+
+// LastViewed 获取视频最近一次播放时间，没有播放记录时返回零值
+func (t *ViewTracker) LastViewed(ctx context.Context, videoID string) time.Time {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	if stats, exists := t.videos[videoID]; exists {
+		return stats.lastViewed
+	}
+	return time.Time{}
+}
+
+// ViewCounts 批量获取视频的总播放次数，没有播放记录的视频不包含在结果中
+func (t *ViewTracker) ViewCounts(ctx context.Context, videoIDs []string) map[string]int64 {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	counts := make(map[string]int64)
+	for _, videoID := range videoIDs {
+		if stats, exists := t.videos[videoID]; exists {
+			counts[videoID] = stats.total
+		}
+	}
+	return counts
+}
+
+// TopVideos 统计window时间窗口内播放次数最多的limit个视频，按播放次数降序
+// 时间窗口按小时对齐，最长不超过保留时长
+func (t *ViewTracker) TopVideos(ctx context.Context, window time.Duration, limit int) []VideoViews {
+	since := t.now().Add(-window).Truncate(BucketSize).Unix()
+
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	var top []VideoViews
+	for videoID, stats := range t.videos {
+		var views int64
+		for start, count := range stats.buckets {
+			if start >= since {
+				views += count
+			}
+		}
+		if views > 0 {
+			top = append(top, VideoViews{VideoID: videoID, Views: views})
+		}
+	}
+
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Views != top[j].Views {
+			return top[i].Views > top[j].Views
+		}
+		return top[i].VideoID < top[j].VideoID
+	})
+
+	if limit > 0 && len(top) > limit {
+		top = top[:limit]
+	}
+	return top
+}
+
+// RemoveVideo 删除视频的播放统计，视频删除时调用
+func (t *ViewTracker) RemoveVideo(ctx context.Context, videoID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.videos, videoID)
+}
+
+// Count 统计有播放记录的视频数量
+func (t *ViewTracker) Count(ctx context.Context) int {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return len(t.videos)
+}
@@ -0,0 +1,75 @@
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestViewTracker 创建使用可控时钟的播放次数统计
+func newTestViewTracker(now *time.Time) *ViewTracker {
+	tracker := NewViewTracker()
+	tracker.now = func() time.Time { return *now }
+	return tracker
+}
+
+// TestViewTracker_RecordView 测试记录播放次数
+func TestViewTracker_RecordView(t *testing.T) {
+	now := time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newTestViewTracker(&now)
+	ctx := context.Background()
+
+	tracker.RecordView(ctx, "video1")
+	tracker.RecordView(ctx, "video1")
+	tracker.RecordView(ctx, "video2")
+	now = now.Add(time.Minute)
+	tracker.RecordView(ctx, "video1")
+
+	assert.Equal(t, int64(3), tracker.ViewCount(ctx, "video1"))
+	assert.Equal(t, now, tracker.LastViewed(ctx, "video1"), "应该记录最近一次播放时间")
+	assert.True(t, tracker.LastViewed(ctx, "missing").IsZero())
+	assert.Equal(t, int64(0), tracker.ViewCount(ctx, "missing"))
+	assert.Equal(t, map[string]int64{"video1": 3, "video2": 1}, tracker.ViewCounts(ctx, []string{"video1", "video2", "missing"}))
+	assert.Equal(t, 2, tracker.Count(ctx))
+
+	tracker.RemoveVideo(ctx, "video1")
+	assert.Equal(t, int64(0), tracker.ViewCount(ctx, "video1"), "删除视频后应该清除播放统计")
+	assert.Equal(t, 1, tracker.Count(ctx))
+}
+
+// TestViewTracker_TopVideos 测试按时间窗口统计热门视频
+func TestViewTracker_TopVideos(t *testing.T) {
+	now := time.Date(2025, 8, 1, 12, 30, 0, 0, time.UTC)
+	tracker := newTestViewTracker(&now)
+	ctx := context.Background()
+
+	// 两天前video1播放3次
+	now = now.Add(-48 * time.Hour)
+	for i := 0; i < 3; i++ {
+		tracker.RecordView(ctx, "video1")
+	}
+
+	// 最近一小时video2播放2次、video3播放1次
+	now = now.Add(48 * time.Hour)
+	tracker.RecordView(ctx, "video2")
+	tracker.RecordView(ctx, "video2")
+	tracker.RecordView(ctx, "video3")
+
+	assert.Equal(t, []VideoViews{
+		{VideoID: "video2", Views: 2},
+		{VideoID: "video3", Views: 1},
+	}, tracker.TopVideos(ctx, 24*time.Hour, 10), "时间窗口外的播放不应该计入")
+
+	assert.Equal(t, []VideoViews{
+		{VideoID: "video1", Views: 3},
+		{VideoID: "video2", Views: 2},
+	}, tracker.TopVideos(ctx, 7*24*time.Hour, 2), "应该按播放次数降序返回前N个")
+
+	// 超过保留时长后小时统计被清理，总播放次数保留
+	now = now.Add(Retention + 48*time.Hour)
+	tracker.RecordView(ctx, "video1")
+	assert.Equal(t, []VideoViews{{VideoID: "video1", Views: 1}}, tracker.TopVideos(ctx, Retention, 10))
+	assert.Equal(t, int64(4), tracker.ViewCount(ctx, "video1"))
+}
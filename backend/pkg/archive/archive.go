@@ -0,0 +1,126 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// Archiver 冷存储归档，在视频存储桶和归档存储桶之间移动文件
+// 归档存储桶可以是配置了低成本存储类别的存储桶，文件在两个存储桶中使用相同的对象名
+type Archiver struct {
+	storage storage.StorageInterface
+	bucket  string                 // 归档存储桶
+	locks   map[string]*objectLock // 对象名 -> 对象锁，没有持有者时删除
+	ready   bool                   // 归档存储桶是否已确认存在
+	mutex   sync.Mutex
+}
+
+// objectLock 单个对象的锁，同一对象的归档和恢复需要串行执行
+type objectLock struct {
+	mutex sync.Mutex
+	refs  int // 持有或等待该锁的调用方数量
+}
+
+// NewArchiver 创建冷存储归档
+func NewArchiver(storageClient storage.StorageInterface, bucket string) *Archiver {
+	return &Archiver{
+		storage: storageClient,
+		bucket:  bucket,
+		locks:   make(map[string]*objectLock),
+	}
+}
+
+// Bucket 获取归档存储桶
+func (a *Archiver) Bucket() string {
+	return a.bucket
+}
+
+// Lock 锁定对象，返回解锁函数
+// 调用方在检查归档状态、移动文件和更新元数据期间持有锁，避免同一对象同时被归档和恢复
+func (a *Archiver) Lock(objectName string) func() {
+	a.mutex.Lock()
+	lock, exists := a.locks[objectName]
+	if !exists {
+		lock = &objectLock{}
+		a.locks[objectName] = lock
+	}
+	lock.refs++
+	a.mutex.Unlock()
+
+	lock.mutex.Lock()
+	return func() {
+		lock.mutex.Unlock()
+
+		a.mutex.Lock()
+		defer a.mutex.Unlock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(a.locks, objectName)
+		}
+	}
+}
+
+// Archive 将文件从bucketName移动到归档存储桶
+func (a *Archiver) Archive(ctx context.Context, bucketName, objectName string) error {
+	if err := a.ensureBucket(ctx); err != nil {
+		return err
+	}
+	if err := a.move(ctx, bucketName, a.bucket, objectName); err != nil {
+		return fmt.Errorf("归档文件失败: %w", err)
+	}
+	return nil
+}
+
+// Restore 将文件从归档存储桶移回bucketName
+func (a *Archiver) Restore(ctx context.Context, bucketName, objectName string) error {
+	if err := a.move(ctx, a.bucket, bucketName, objectName); err != nil {
+		return fmt.Errorf("恢复归档文件失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除归档存储桶中的文件，文件不存在时视为删除成功
+func (a *Archiver) Delete(ctx context.Context, objectName string) error {
+	if err := a.storage.DeleteFile(ctx, a.bucket, objectName); err != nil {
+		return fmt.Errorf("删除归档文件失败: %w", err)
+	}
+	return nil
+}
+
+// move 在存储服务端复制文件到目标存储桶后删除源文件，复制时保留用户元数据和对象标签
+// 复制失败时源文件保持不变；复制成功后删除源文件失败只记录日志，残留的源文件会在下次移动时被覆盖
+func (a *Archiver) move(ctx context.Context, fromBucket, toBucket, objectName string) error {
+	if err := a.storage.CopyFile(ctx, fromBucket, objectName, toBucket, objectName); err != nil {
+		return err
+	}
+
+	if err := a.storage.DeleteFile(ctx, fromBucket, objectName); err != nil {
+		fmt.Printf("删除已移动的源文件失败(%s/%s): %v\n", fromBucket, objectName, err)
+	}
+	return nil
+}
+
+// ensureBucket 确保归档存储桶存在，不存在时创建
+func (a *Archiver) ensureBucket(ctx context.Context) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.ready {
+		return nil
+	}
+
+	exists, err := a.storage.BucketExists(ctx, a.bucket)
+	if err != nil {
+		return fmt.Errorf("检查归档存储桶失败: %w", err)
+	}
+	if !exists {
+		if err := a.storage.CreateBucket(ctx, a.bucket); err != nil {
+			return fmt.Errorf("创建归档存储桶失败: %w", err)
+		}
+	}
+	a.ready = true
+	return nil
+}
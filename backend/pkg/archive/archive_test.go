@@ -0,0 +1,87 @@
+package archive
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// newTestArchiver 创建使用本地文件系统存储的归档
+func newTestArchiver(t *testing.T) (*Archiver, *storage.LocalStorage) {
+	store, err := storage.NewLocalStorage(&storage.LocalConfig{RootDir: t.TempDir()})
+	require.NoError(t, err)
+	require.NoError(t, store.CreateBucket(context.Background(), "videos"))
+	return NewArchiver(store, "archive"), store
+}
+
+// TestArchiver_ArchiveAndRestore 测试归档和恢复文件
+func TestArchiver_ArchiveAndRestore(t *testing.T) {
+	ctx := context.Background()
+	archiver, store := newTestArchiver(t)
+	_, err := store.UploadFile(ctx, "videos", "videos/a.mp4", []byte("video data"), "video/mp4")
+	require.NoError(t, err)
+
+	require.NoError(t, archiver.Archive(ctx, "videos", "videos/a.mp4"))
+
+	exists, err := store.BucketExists(ctx, "archive")
+	require.NoError(t, err)
+	assert.True(t, exists, "归档存储桶不存在时应该自动创建")
+	exists, err = store.FileExists(ctx, "videos", "videos/a.mp4")
+	require.NoError(t, err)
+	assert.False(t, exists, "归档后视频存储桶中的文件应该被删除")
+	data, err := store.DownloadFile(ctx, "archive", "videos/a.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("video data"), data)
+
+	require.NoError(t, archiver.Restore(ctx, "videos", "videos/a.mp4"))
+
+	data, err = store.DownloadFile(ctx, "videos", "videos/a.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("video data"), data, "恢复后文件内容应该保持不变")
+	exists, err = store.FileExists(ctx, "archive", "videos/a.mp4")
+	require.NoError(t, err)
+	assert.False(t, exists, "恢复后归档存储桶中的文件应该被删除")
+}
+
+// TestArchiver_MissingFile 测试移动不存在的文件
+func TestArchiver_MissingFile(t *testing.T) {
+	ctx := context.Background()
+	archiver, _ := newTestArchiver(t)
+
+	assert.Error(t, archiver.Archive(ctx, "videos", "videos/missing.mp4"))
+	assert.Error(t, archiver.Restore(ctx, "videos", "videos/missing.mp4"))
+	assert.NoError(t, archiver.Delete(ctx, "videos/missing.mp4"), "删除不存在的归档文件应该视为成功")
+}
+
+// TestArchiver_Lock 测试同一对象的锁互斥，不同对象互不影响
+func TestArchiver_Lock(t *testing.T) {
+	archiver, _ := newTestArchiver(t)
+
+	unlock := archiver.Lock("videos/a.mp4")
+	archiver.Lock("videos/b.mp4")()
+
+	var wg sync.WaitGroup
+	acquired := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		archiver.Lock("videos/a.mp4")()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("对象已被锁定时不应该获取到锁")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	unlock()
+	wg.Wait()
+	assert.Empty(t, archiver.locks, "没有持有者的锁应该被清理")
+}
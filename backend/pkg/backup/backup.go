@@ -0,0 +1,240 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// FormatVersion 备份文件的格式版本，读取更高版本的备份时返回错误
+const FormatVersion = 1
+
+// Latest 恢复时表示最近一次备份的名称
+const Latest = "latest"
+
+// 备份文件名为zhulong-<UTC时间>.json.gz，按名称排序即按时间排序
+const (
+	namePrefix     = "zhulong-"
+	nameExtension  = ".json.gz"
+	nameTimeFormat = "20060102-150405.000"
+)
+
+// ErrNotFound 备份不存在
+var ErrNotFound = errors.New("备份不存在")
+
+// Backup 备份内容：视频元数据和配置文件
+type Backup struct {
+	Version    int                      `json:"version"`               // 格式版本
+	CreatedAt  time.Time                `json:"created_at"`            // 备份时间
+	Videos     []*metadata.FileMetadata `json:"videos"`                // 全部视频元数据
+	ConfigFile string                   `json:"config_file,omitempty"` // 配置文件路径
+	Config     string                   `json:"config,omitempty"`      // 配置文件内容，不包含环境变量覆盖的配置
+}
+
+// Info 存储中的备份文件
+type Info struct {
+	Name      string    // 备份名称（文件名）
+	Size      int64     // 压缩后的大小（字节）
+	CreatedAt time.Time // 备份时间
+}
+
+// Encode 将备份编码为gzip压缩的JSON
+func Encode(backup *Backup) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(writer).Encode(backup); err != nil {
+		return nil, fmt.Errorf("编码备份失败: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("压缩备份失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode 解码gzip压缩的JSON备份
+func Decode(reader io.Reader) (*Backup, error) {
+	gz, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("解压备份失败: %w", err)
+	}
+	defer gz.Close()
+
+	var backup Backup
+	if err := json.NewDecoder(gz).Decode(&backup); err != nil {
+		return nil, fmt.Errorf("解析备份失败: %w", err)
+	}
+	if backup.Version < 1 || backup.Version > FormatVersion {
+		return nil, fmt.Errorf("不支持的备份格式版本: %d", backup.Version)
+	}
+	return &backup, nil
+}
+
+// Store 存储桶中的备份，保存新备份后只保留最近retention个
+type Store struct {
+	storage   storage.StorageInterface
+	bucket    string
+	retention int
+}
+
+// NewStore 创建备份存储，retention为保留的备份数量，0表示全部保留
+func NewStore(storageClient storage.StorageInterface, bucket string, retention int) (*Store, error) {
+	if storageClient == nil {
+		return nil, fmt.Errorf("存储服务不能为空")
+	}
+	if bucket == "" {
+		return nil, fmt.Errorf("备份存储桶不能为空")
+	}
+	if retention < 0 {
+		return nil, fmt.Errorf("备份保留数量不能为负数: %d", retention)
+	}
+	return &Store{storage: storageClient, bucket: bucket, retention: retention}, nil
+}
+
+// Bucket 获取备份存储桶
+func (s *Store) Bucket() string {
+	return s.bucket
+}
+
+// Save 保存备份并清理超出保留数量的旧备份，存储桶不存在时自动创建
+// 清理失败不影响本次备份，只返回保存的备份
+func (s *Store) Save(ctx context.Context, backup *Backup) (*Info, error) {
+	data, err := Encode(backup)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := s.storage.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return nil, fmt.Errorf("检查备份存储桶失败: %w", err)
+	}
+	if !exists {
+		if err := s.storage.CreateBucket(ctx, s.bucket); err != nil {
+			return nil, fmt.Errorf("创建备份存储桶失败: %w", err)
+		}
+	}
+
+	createdAt := backup.CreatedAt.UTC()
+	name := namePrefix + createdAt.Format(nameTimeFormat) + nameExtension
+	if _, err := s.storage.UploadFile(ctx, s.bucket, name, data, "application/gzip"); err != nil {
+		return nil, fmt.Errorf("上传备份失败: %w", err)
+	}
+
+	if _, err := s.Prune(ctx); err != nil {
+		fmt.Printf("清理旧备份失败: %v\n", err)
+	}
+	return &Info{Name: name, Size: int64(len(data)), CreatedAt: createdAt}, nil
+}
+
+// List 列出全部备份，按时间从新到旧排序；存储桶不存在时返回空列表
+func (s *Store) List(ctx context.Context) ([]Info, error) {
+	exists, err := s.storage.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return nil, fmt.Errorf("检查备份存储桶失败: %w", err)
+	}
+	if !exists {
+		return []Info{}, nil
+	}
+
+	files, err := s.storage.ListFiles(ctx, s.bucket, namePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("列出备份失败: %w", err)
+	}
+	backups := make([]Info, 0, len(files))
+	for _, file := range files {
+		createdAt, ok := parseName(file.Key)
+		if !ok {
+			continue
+		}
+		backups = append(backups, Info{Name: file.Key, Size: file.Size, CreatedAt: createdAt})
+	}
+	slices.SortFunc(backups, func(a, b Info) int {
+		return strings.Compare(b.Name, a.Name)
+	})
+	return backups, nil
+}
+
+// Load 读取备份，name为Latest时读取最近一次备份；备份不存在时返回ErrNotFound
+func (s *Store) Load(ctx context.Context, name string) (*Info, *Backup, error) {
+	backups, err := s.List(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	index := slices.IndexFunc(backups, func(info Info) bool { return info.Name == name })
+	if name == Latest && len(backups) > 0 {
+		index = 0
+	}
+	if index < 0 {
+		return nil, nil, ErrNotFound
+	}
+
+	info := backups[index]
+	reader, err := s.storage.OpenFile(ctx, s.bucket, info.Name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取备份失败: %w", err)
+	}
+	defer reader.Close()
+
+	backup, err := Decode(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &info, backup, nil
+}
+
+// Prune 删除超出保留数量的旧备份，返回删除的数量
+func (s *Store) Prune(ctx context.Context) (int, error) {
+	if s.retention == 0 {
+		return 0, nil
+	}
+	backups, err := s.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(backups) <= s.retention {
+		return 0, nil
+	}
+
+	names := make([]string, 0, len(backups)-s.retention)
+	for _, info := range backups[s.retention:] {
+		names = append(names, info.Name)
+	}
+	failed, err := s.storage.DeleteFiles(ctx, s.bucket, names)
+	if err != nil {
+		return 0, fmt.Errorf("删除旧备份失败: %w", err)
+	}
+	if len(failed) > 0 {
+		return len(names) - len(failed), fmt.Errorf("%d个旧备份删除失败", len(failed))
+	}
+	return len(names), nil
+}
+
+// parseName 从备份文件名解析备份时间，不是备份文件时返回false
+func parseName(name string) (time.Time, bool) {
+	if path.Base(name) != name {
+		return time.Time{}, false
+	}
+	value, ok := strings.CutPrefix(name, namePrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	value, ok = strings.CutSuffix(value, nameExtension)
+	if !ok {
+		return time.Time{}, false
+	}
+	createdAt, err := time.Parse(nameTimeFormat, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return createdAt, true
+}
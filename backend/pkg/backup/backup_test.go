@@ -0,0 +1,112 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// newTestStore 创建使用临时目录本地存储的备份存储
+func newTestStore(t *testing.T, retention int) (*Store, *storage.LocalStorage) {
+	local, err := storage.NewLocalStorage(&storage.LocalConfig{RootDir: t.TempDir()})
+	require.NoError(t, err)
+	store, err := NewStore(local, "zhulong-backups", retention)
+	require.NoError(t, err)
+	return store, local
+}
+
+func TestEncodeDecode(t *testing.T) {
+	backup := &Backup{
+		Version:    FormatVersion,
+		CreatedAt:  time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC),
+		Videos:     []*metadata.FileMetadata{{FileID: "video1", Title: "测试视频", CreatedBy: "alice"}},
+		ConfigFile: "/etc/zhulong/production.yml",
+		Config:     "server:\n  port: 8888\n",
+	}
+	data, err := Encode(backup)
+	require.NoError(t, err)
+
+	decoded, err := Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, backup, decoded)
+
+	data, err = Encode(&Backup{Version: FormatVersion + 1})
+	require.NoError(t, err)
+	_, err = Decode(bytes.NewReader(data))
+	assert.Error(t, err, "不支持更高版本的备份")
+
+	_, err = Decode(bytes.NewReader([]byte("{}")))
+	assert.Error(t, err, "备份必须是gzip压缩的")
+}
+
+func TestStore_SaveListLoad(t *testing.T) {
+	ctx := context.Background()
+	store, local := newTestStore(t, 2)
+
+	backups, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, backups, "存储桶不存在时返回空列表")
+	_, _, err = store.Load(ctx, Latest)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	start := time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		info, err := store.Save(ctx, &Backup{
+			Version:   FormatVersion,
+			CreatedAt: start.Add(time.Duration(i) * time.Hour),
+			Videos:    []*metadata.FileMetadata{{FileID: "video1", Title: "测试视频", CreatedBy: "alice"}},
+		})
+		require.NoError(t, err)
+		assert.Positive(t, info.Size)
+	}
+	_, err = local.UploadFile(ctx, "zhulong-backups", "notes.txt", []byte("notes"), "text/plain")
+	require.NoError(t, err)
+
+	backups, err = store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, backups, 2, "只保留最近2个备份")
+	assert.Equal(t, "zhulong-20250801-140000.000.json.gz", backups[0].Name)
+	assert.Equal(t, "zhulong-20250801-130000.000.json.gz", backups[1].Name)
+	assert.True(t, backups[0].CreatedAt.Equal(start.Add(2*time.Hour)))
+
+	info, backup, err := store.Load(ctx, Latest)
+	require.NoError(t, err)
+	assert.Equal(t, backups[0].Name, info.Name)
+	require.Len(t, backup.Videos, 1)
+	assert.Equal(t, "video1", backup.Videos[0].FileID)
+
+	info, _, err = store.Load(ctx, backups[1].Name)
+	require.NoError(t, err)
+	assert.Equal(t, backups[1].Name, info.Name)
+
+	_, _, err = store.Load(ctx, "zhulong-20250801-120000.000.json.gz")
+	assert.ErrorIs(t, err, ErrNotFound, "超出保留数量的备份已删除")
+	_, _, err = store.Load(ctx, "notes.txt")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestNewStore(t *testing.T) {
+	local, err := storage.NewLocalStorage(&storage.LocalConfig{RootDir: t.TempDir()})
+	require.NoError(t, err)
+
+	_, err = NewStore(nil, "zhulong-backups", 7)
+	assert.Error(t, err)
+	_, err = NewStore(local, "", 7)
+	assert.Error(t, err)
+	_, err = NewStore(local, "zhulong-backups", -1)
+	assert.Error(t, err)
+
+	store, err := NewStore(local, "zhulong-backups", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "zhulong-backups", store.Bucket())
+	pruned, err := store.Prune(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, pruned, "保留数量为0时全部保留")
+}
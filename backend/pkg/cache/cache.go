@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// 缓存驱动名称
+const (
+	DriverNone   = "none"   // 不缓存
+	DriverMemory = "memory" // 进程内LRU缓存
+	DriverRedis  = "redis"  // Redis缓存，多个服务实例共享
+)
+
+// defaultMaxEntries 内存缓存未配置条目数时的默认值
+const defaultMaxEntries = 10000
+
+// Cache 键值缓存，值在ttl后过期
+type Cache interface {
+	// Get 获取缓存值，不存在或已过期时ok为false
+	Get(ctx context.Context, key string) (value string, ok bool, err error)
+	// Set 写入缓存值，ttl必须大于0
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	// Delete 删除缓存值，键不存在时忽略
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Config 缓存配置，Driver为空时不缓存
+type Config struct {
+	Driver        string // 驱动名称
+	MaxEntries    int    // 内存缓存的最大条目数
+	RedisAddr     string // Redis地址，如"localhost:6379"
+	RedisPassword string // Redis密码，为空时不认证
+	RedisDB       int    // Redis数据库编号
+}
+
+// NewFromConfig 根据配置创建缓存，不缓存时返回nil
+func NewFromConfig(cfg *Config) (Cache, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	switch cfg.Driver {
+	case "", DriverNone:
+		return nil, nil
+	case DriverMemory:
+		maxEntries := cfg.MaxEntries
+		if maxEntries <= 0 {
+			maxEntries = defaultMaxEntries
+		}
+		return NewMemoryCache(maxEntries), nil
+	case DriverRedis:
+		if cfg.RedisAddr == "" {
+			return nil, fmt.Errorf("Redis地址不能为空")
+		}
+		return NewRedisCache(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB), nil
+	default:
+		return nil, fmt.Errorf("不支持的缓存驱动: %s（可用: none, memory, redis）", cfg.Driver)
+	}
+}
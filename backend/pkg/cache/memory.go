@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryCache 进程内LRU缓存，超过最大条目数时淘汰最久未使用的条目
+type MemoryCache struct {
+	maxEntries int
+	items      map[string]*list.Element
+	// 按使用时间排序，最近使用的在前
+	order *list.List
+	now   func() time.Time
+	mutex sync.Mutex
+}
+
+// memoryEntry 内存缓存条目
+type memoryEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+// NewMemoryCache 创建内存缓存
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: max(maxEntries, 1),
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+// Get 获取缓存值，已过期的条目被删除
+func (c *MemoryCache) Get(ctx context.Context, key string) (string, bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, exists := c.items[key]
+	if !exists {
+		return "", false, nil
+	}
+	entry := elem.Value.(*memoryEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.remove(elem)
+		return "", false, nil
+	}
+	c.order.MoveToFront(elem)
+	return entry.value, true, nil
+}
+
+// Set 写入缓存值，超过最大条目数时淘汰最久未使用的条目
+func (c *MemoryCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	expiresAt := c.now().Add(ttl)
+	if elem, exists := c.items[key]; exists {
+		entry := elem.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.items[key] = c.order.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+// Delete 删除缓存值
+func (c *MemoryCache) Delete(ctx context.Context, keys ...string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, key := range keys {
+		if elem, exists := c.items[key]; exists {
+			c.remove(elem)
+		}
+	}
+	return nil
+}
+
+// Len 当前缓存条目数，包括已过期但尚未淘汰的条目
+func (c *MemoryCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.order.Len()
+}
+
+// remove 删除条目，调用方需持有锁
+func (c *MemoryCache) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, elem.Value.(*memoryEntry).key)
+}
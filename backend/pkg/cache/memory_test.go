@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("读写和删除", func(t *testing.T) {
+		cache := NewMemoryCache(10)
+		_, ok, err := cache.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		require.NoError(t, cache.Set(ctx, "a", "1", time.Minute))
+		value, ok, err := cache.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "1", value)
+
+		require.NoError(t, cache.Set(ctx, "a", "2", time.Minute))
+		value, _, _ = cache.Get(ctx, "a")
+		assert.Equal(t, "2", value, "重复写入应该覆盖旧值")
+		assert.Equal(t, 1, cache.Len())
+
+		require.NoError(t, cache.Delete(ctx, "a", "missing"))
+		_, ok, _ = cache.Get(ctx, "a")
+		assert.False(t, ok)
+	})
+
+	t.Run("过期", func(t *testing.T) {
+		cache := NewMemoryCache(10)
+		now := time.Now()
+		cache.now = func() time.Time { return now }
+
+		require.NoError(t, cache.Set(ctx, "a", "1", time.Minute))
+		now = now.Add(time.Minute)
+		_, ok, _ := cache.Get(ctx, "a")
+		assert.False(t, ok, "到达过期时间后应该失效")
+		assert.Equal(t, 0, cache.Len(), "过期的条目应该被删除")
+	})
+
+	t.Run("淘汰最久未使用的条目", func(t *testing.T) {
+		cache := NewMemoryCache(2)
+		require.NoError(t, cache.Set(ctx, "a", "1", time.Minute))
+		require.NoError(t, cache.Set(ctx, "b", "2", time.Minute))
+		_, ok, _ := cache.Get(ctx, "a")
+		require.True(t, ok)
+
+		require.NoError(t, cache.Set(ctx, "c", "3", time.Minute))
+		assert.Equal(t, 2, cache.Len())
+		_, ok, _ = cache.Get(ctx, "b")
+		assert.False(t, ok, "b最久未使用，应该被淘汰")
+		_, ok, _ = cache.Get(ctx, "a")
+		assert.True(t, ok)
+	})
+}
+
+func TestNewFromConfig(t *testing.T) {
+	cache, err := NewFromConfig(&Config{Driver: DriverNone})
+	require.NoError(t, err)
+	assert.Nil(t, cache)
+
+	cache, err = NewFromConfig(&Config{Driver: DriverMemory})
+	require.NoError(t, err)
+	assert.IsType(t, &MemoryCache{}, cache)
+
+	cache, err = NewFromConfig(&Config{Driver: DriverRedis, RedisAddr: "localhost:6379"})
+	require.NoError(t, err)
+	assert.IsType(t, &RedisCache{}, cache)
+
+	_, err = NewFromConfig(&Config{Driver: DriverRedis})
+	assert.Error(t, err, "Redis地址不能为空")
+
+	_, err = NewFromConfig(&Config{Driver: "memcached"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,224 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// redisTimeout 请求未设置截止时间时，连接和读写Redis的超时时间
+	redisTimeout = 3 * time.Second
+	// redisMaxIdleConns 保留的空闲连接数
+	redisMaxIdleConns = 8
+)
+
+// redisError Redis返回的错误回复
+type redisError string
+
+func (e redisError) Error() string {
+	return "Redis错误: " + string(e)
+}
+
+// RedisCache 使用Redis的缓存，多个服务实例可以共享缓存
+// 通过RESP协议直接访问Redis，只使用GET、SET和DEL命令
+type RedisCache struct {
+	addr     string
+	password string
+	db       int
+	idle     chan *redisConn
+}
+
+// redisConn Redis连接
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewRedisCache 创建Redis缓存，连接在首次使用时建立
+func NewRedisCache(addr, password string, db int) *RedisCache {
+	return &RedisCache{
+		addr:     addr,
+		password: password,
+		db:       db,
+		idle:     make(chan *redisConn, redisMaxIdleConns),
+	}
+}
+
+// Get 获取缓存值
+func (c *RedisCache) Get(ctx context.Context, key string) (string, bool, error) {
+	reply, err := c.do(ctx, "GET", key)
+	if err != nil {
+		return "", false, err
+	}
+	if reply == nil {
+		return "", false, nil
+	}
+	value, ok := reply.(string)
+	if !ok {
+		return "", false, fmt.Errorf("Redis GET返回了意外的回复: %v", reply)
+	}
+	return value, true, nil
+}
+
+// Set 写入缓存值，过期时间精确到毫秒
+func (c *RedisCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	milliseconds := max(ttl.Milliseconds(), 1)
+	_, err := c.do(ctx, "SET", key, value, "PX", strconv.FormatInt(milliseconds, 10))
+	return err
+}
+
+// Delete 删除缓存值
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_, err := c.do(ctx, append([]string{"DEL"}, keys...)...)
+	return err
+}
+
+// Close 关闭空闲连接
+func (c *RedisCache) Close() {
+	for {
+		select {
+		case conn := <-c.idle:
+			conn.conn.Close()
+		default:
+			return
+		}
+	}
+}
+
+// do 执行命令并读取回复，网络错误时关闭连接，Redis错误回复时连接仍可复用
+func (c *RedisCache) do(ctx context.Context, args ...string) (any, error) {
+	conn, err := c.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := conn.do(ctx, args...)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		conn.conn.Close()
+		return nil, err
+	}
+	c.putConn(conn)
+	return reply, err
+}
+
+// getConn 获取空闲连接，没有空闲连接时新建连接并完成认证和选择数据库
+func (c *RedisCache) getConn(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-c.idle:
+		return conn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: redisTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, fmt.Errorf("连接Redis失败: %w", err)
+	}
+	conn := &redisConn{conn: netConn, reader: bufio.NewReader(netConn)}
+
+	if c.password != "" {
+		if _, err := conn.do(ctx, "AUTH", c.password); err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("Redis认证失败: %w", err)
+		}
+	}
+	if c.db != 0 {
+		if _, err := conn.do(ctx, "SELECT", strconv.Itoa(c.db)); err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("选择Redis数据库失败: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// putConn 归还连接，空闲连接已满时关闭
+func (c *RedisCache) putConn(conn *redisConn) {
+	select {
+	case c.idle <- conn:
+	default:
+		conn.conn.Close()
+	}
+}
+
+// do 发送命令并读取一个回复
+func (c *redisConn) do(ctx context.Context, args ...string) (any, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(redisTimeout)
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var command strings.Builder
+	fmt.Fprintf(&command, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&command, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.conn, command.String()); err != nil {
+		return nil, fmt.Errorf("发送Redis命令失败: %w", err)
+	}
+	return c.readReply()
+}
+
+// readReply 读取一个回复：状态和批量字符串返回string，整数返回int64，空值返回nil，数组返回[]any
+func (c *redisConn) readReply() (any, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("读取Redis回复失败: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("Redis回复格式无效")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("Redis回复格式无效: %s", line)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, fmt.Errorf("读取Redis回复失败: %w", err)
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("Redis回复格式无效: %s", line)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]any, count)
+		for i := range items {
+			if items[i], err = c.readReply(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("Redis回复格式无效: %s", line)
+	}
+}
@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis 实现AUTH、SELECT、GET、SET和DEL的测试Redis服务
+type fakeRedis struct {
+	listener net.Listener
+	password string
+	values   map[string]string
+	commands []string
+	mutex    sync.Mutex
+}
+
+// newFakeRedis 启动测试Redis服务
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &fakeRedis{listener: listener, password: password, values: make(map[string]string)}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+// serve 处理一个连接的命令
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	authenticated := s.password == ""
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+
+		s.mutex.Lock()
+		s.commands = append(s.commands, strings.Join(args, " "))
+		var reply string
+		switch {
+		case args[0] == "AUTH":
+			authenticated = args[1] == s.password
+			reply = "+OK\r\n"
+			if !authenticated {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authenticated:
+			reply = "-NOAUTH Authentication required\r\n"
+		case args[0] == "SELECT":
+			reply = "+OK\r\n"
+		case args[0] == "GET":
+			if value, ok := s.values[args[1]]; ok {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+			} else {
+				reply = "$-1\r\n"
+			}
+		case args[0] == "SET":
+			s.values[args[1]] = args[2]
+			reply = "+OK\r\n"
+		case args[0] == "DEL":
+			deleted := 0
+			for _, key := range args[1:] {
+				if _, ok := s.values[key]; ok {
+					delete(s.values, key)
+					deleted++
+				}
+			}
+			reply = fmt.Sprintf(":%d\r\n", deleted)
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		s.mutex.Unlock()
+
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand 读取RESP数组格式的命令
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		if _, err := reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args[i] = strings.TrimSuffix(arg, "\r\n")
+	}
+	return args, nil
+}
+
+// lastCommand 最近一次收到的命令
+func (s *fakeRedis) lastCommand() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.commands[len(s.commands)-1]
+}
+
+func TestRedisCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("读写和删除", func(t *testing.T) {
+		server := newFakeRedis(t, "secret")
+		cache := NewRedisCache(server.listener.Addr().String(), "secret", 2)
+		t.Cleanup(cache.Close)
+
+		_, ok, err := cache.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		require.NoError(t, cache.Set(ctx, "a", "value with spaces", 1500*time.Millisecond))
+		assert.Equal(t, "SET a value with spaces PX 1500", server.lastCommand())
+		value, ok, err := cache.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "value with spaces", value)
+
+		require.NoError(t, cache.Delete(ctx, "a", "b"))
+		assert.Equal(t, "DEL a b", server.lastCommand())
+		_, ok, err = cache.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		server.mutex.Lock()
+		assert.Equal(t, []string{"AUTH secret", "SELECT 2"}, server.commands[:2], "新连接应该先认证再选择数据库")
+		assert.Len(t, server.commands, 7, "连接应该被复用，只认证一次")
+		server.mutex.Unlock()
+	})
+
+	t.Run("认证失败", func(t *testing.T) {
+		server := newFakeRedis(t, "secret")
+		cache := NewRedisCache(server.listener.Addr().String(), "wrong", 0)
+
+		_, _, err := cache.Get(ctx, "a")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "WRONGPASS")
+	})
+
+	t.Run("连接失败", func(t *testing.T) {
+		server := newFakeRedis(t, "")
+		addr := server.listener.Addr().String()
+		server.listener.Close()
+		cache := NewRedisCache(addr, "", 0)
+
+		err := cache.Set(ctx, "a", "1", time.Minute)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "连接Redis失败")
+	})
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// urlKeyPrefix 预签名URL缓存键前缀
+	urlKeyPrefix = "zhulong:url:"
+	// maxURLExpiry 预签名URL的最长有效期，与S3一致
+	maxURLExpiry = 7 * 24 * time.Hour
+)
+
+// SignFunc 按有效期生成预签名URL
+type SignFunc func(expiry time.Duration) (string, error)
+
+// URLCache 预签名下载URL缓存
+// 生成URL时有效期延长一半，剩余有效期不少于请求的有效期时复用，返回的URL始终满足请求的有效期
+// 相同的URL便于浏览器和CDN缓存缩略图等文件
+type URLCache struct {
+	cache Cache
+	now   func() time.Time
+}
+
+// urlEntry 缓存的预签名URL
+type urlEntry struct {
+	URL       string `json:"url"`
+	ExpiresAt int64  `json:"expires_at"` // URL过期时间（Unix毫秒）
+}
+
+// NewURLCache 创建预签名URL缓存，cache为nil时不缓存
+func NewURLCache(cache Cache) *URLCache {
+	return &URLCache{cache: cache, now: time.Now}
+}
+
+// Get 获取对象的预签名下载URL，缓存中没有满足有效期的URL时生成并缓存
+// 缓存读写失败时只记录日志，直接生成URL
+func (u *URLCache) Get(ctx context.Context, bucketName, objectName string, expiry time.Duration, sign SignFunc) (string, error) {
+	if u == nil || u.cache == nil {
+		return sign(expiry)
+	}
+
+	key := urlKey(bucketName, objectName)
+	now := u.now()
+	value, ok, err := u.cache.Get(ctx, key)
+	if err != nil {
+		fmt.Printf("读取预签名URL缓存失败(%s/%s): %v\n", bucketName, objectName, err)
+	}
+	if ok {
+		var entry urlEntry
+		if err := json.Unmarshal([]byte(value), &entry); err == nil && time.UnixMilli(entry.ExpiresAt).Sub(now) >= expiry {
+			return entry.URL, nil
+		}
+	}
+
+	signExpiry := min(expiry+expiry/2, max(expiry, maxURLExpiry))
+	url, err := sign(signExpiry)
+	if err != nil {
+		return "", err
+	}
+
+	// 剩余有效期短于请求的有效期后不再复用，缓存到此时过期
+	ttl := signExpiry - expiry
+	if ttl > 0 {
+		data, _ := json.Marshal(&urlEntry{URL: url, ExpiresAt: now.Add(signExpiry).UnixMilli()})
+		if err := u.cache.Set(ctx, key, string(data), ttl); err != nil {
+			fmt.Printf("写入预签名URL缓存失败(%s/%s): %v\n", bucketName, objectName, err)
+		}
+	}
+	return url, nil
+}
+
+// Invalidate 删除对象的预签名URL缓存，对象被删除或移动后调用
+func (u *URLCache) Invalidate(ctx context.Context, bucketName string, objectNames ...string) {
+	if u == nil || u.cache == nil || len(objectNames) == 0 {
+		return
+	}
+
+	keys := make([]string, len(objectNames))
+	for i, objectName := range objectNames {
+		keys[i] = urlKey(bucketName, objectName)
+	}
+	if err := u.cache.Delete(ctx, keys...); err != nil {
+		fmt.Printf("删除预签名URL缓存失败(%s): %v\n", bucketName, err)
+	}
+}
+
+// urlKey 预签名URL缓存键
+func urlKey(bucketName, objectName string) string {
+	return urlKeyPrefix + bucketName + "/" + objectName
+}
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingCache 读写都失败的缓存
+type failingCache struct{}
+
+func (failingCache) Get(ctx context.Context, key string) (string, bool, error) {
+	return "", false, errors.New("cache unavailable")
+}
+
+func (failingCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return errors.New("cache unavailable")
+}
+
+func (failingCache) Delete(ctx context.Context, keys ...string) error {
+	return errors.New("cache unavailable")
+}
+
+func TestURLCache(t *testing.T) {
+	ctx := context.Background()
+
+	// newSigner 记录签名次数和有效期的签名函数
+	newSigner := func() (SignFunc, *[]time.Duration) {
+		var expiries []time.Duration
+		return func(expiry time.Duration) (string, error) {
+			expiries = append(expiries, expiry)
+			return fmt.Sprintf("https://storage/object?n=%d", len(expiries)), nil
+		}, &expiries
+	}
+
+	t.Run("复用剩余有效期足够的URL", func(t *testing.T) {
+		memory := NewMemoryCache(10)
+		urls := NewURLCache(memory)
+		now := time.Now()
+		urls.now = func() time.Time { return now }
+		memory.now = urls.now
+		sign, expiries := newSigner()
+
+		first, err := urls.Get(ctx, "thumbs", "a.jpg", time.Hour, sign)
+		require.NoError(t, err)
+		assert.Equal(t, []time.Duration{90 * time.Minute}, *expiries, "生成URL时应该延长一半有效期")
+
+		now = now.Add(20 * time.Minute)
+		second, err := urls.Get(ctx, "thumbs", "a.jpg", time.Hour, sign)
+		require.NoError(t, err)
+		assert.Equal(t, first, second, "剩余70分钟，应该复用")
+		assert.Len(t, *expiries, 1)
+
+		now = now.Add(20 * time.Minute)
+		third, err := urls.Get(ctx, "thumbs", "a.jpg", time.Hour, sign)
+		require.NoError(t, err)
+		assert.NotEqual(t, first, third, "剩余50分钟不足1小时，应该重新生成")
+
+		other, err := urls.Get(ctx, "thumbs", "b.jpg", time.Hour, sign)
+		require.NoError(t, err)
+		assert.NotEqual(t, third, other, "不同对象的URL应该分别缓存")
+	})
+
+	t.Run("删除缓存", func(t *testing.T) {
+		urls := NewURLCache(NewMemoryCache(10))
+		sign, expiries := newSigner()
+
+		_, err := urls.Get(ctx, "thumbs", "a.jpg", time.Hour, sign)
+		require.NoError(t, err)
+		urls.Invalidate(ctx, "thumbs", "a.jpg")
+		_, err = urls.Get(ctx, "thumbs", "a.jpg", time.Hour, sign)
+		require.NoError(t, err)
+		assert.Len(t, *expiries, 2)
+	})
+
+	t.Run("有效期达到上限时不缓存", func(t *testing.T) {
+		memory := NewMemoryCache(10)
+		urls := NewURLCache(memory)
+		sign, expiries := newSigner()
+
+		_, err := urls.Get(ctx, "videos", "a.mp4", maxURLExpiry, sign)
+		require.NoError(t, err)
+		assert.Equal(t, []time.Duration{maxURLExpiry}, *expiries, "不能超过最长有效期")
+		assert.Equal(t, 0, memory.Len())
+
+		_, err = urls.Get(ctx, "videos", "a.mp4", 6*24*time.Hour, sign)
+		require.NoError(t, err)
+		assert.Equal(t, maxURLExpiry, (*expiries)[1])
+		assert.Equal(t, 1, memory.Len())
+	})
+
+	t.Run("缓存不可用或未启用时直接生成", func(t *testing.T) {
+		for _, urls := range []*URLCache{NewURLCache(failingCache{}), NewURLCache(nil), nil} {
+			sign, expiries := newSigner()
+			_, err := urls.Get(ctx, "thumbs", "a.jpg", time.Hour, sign)
+			require.NoError(t, err)
+			_, err = urls.Get(ctx, "thumbs", "a.jpg", time.Hour, sign)
+			require.NoError(t, err)
+			assert.Len(t, *expiries, 2)
+			urls.Invalidate(ctx, "thumbs", "a.jpg")
+		}
+	})
+
+	t.Run("签名失败", func(t *testing.T) {
+		urls := NewURLCache(NewMemoryCache(10))
+		_, err := urls.Get(ctx, "thumbs", "a.jpg", time.Hour, func(time.Duration) (string, error) {
+			return "", errors.New("sign failed")
+		})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,338 @@
+package catalog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+// 目录文件格式
+const (
+	FormatJSON = "json" // 带格式版本的JSON文档，字段与元数据一致
+	FormatCSV  = "csv"  // 每个视频一行，标签、章节和历史版本为JSON数组，便于用电子表格审计
+)
+
+// FormatVersion JSON目录的格式版本，读取更高版本的目录时返回错误
+const FormatVersion = 1
+
+// ErrUnsupportedFormat 不支持的目录格式
+var ErrUnsupportedFormat = errors.New("不支持的目录格式，只支持json和csv")
+
+// Catalog JSON格式的目录文件
+type Catalog struct {
+	Version    int                      `json:"version"`     // 格式版本
+	ExportedAt time.Time                `json:"exported_at"` // 导出时间
+	Videos     []*metadata.FileMetadata `json:"videos"`      // 全部视频元数据
+}
+
+// Record 从目录文件读取的一条记录
+type Record struct {
+	Line     int                    // 记录位置：JSON为videos数组中的序号（从1开始），CSV为行号（表头为第1行）
+	Metadata *metadata.FileMetadata // 元数据
+}
+
+// NormalizeFormat 规范化目录格式，为空时按文件名的扩展名判断
+func NormalizeFormat(format, fileName string) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = strings.ToLower(strings.TrimPrefix(path.Ext(fileName), "."))
+	}
+	if format != FormatJSON && format != FormatCSV {
+		return "", ErrUnsupportedFormat
+	}
+	return format, nil
+}
+
+// ContentType 目录格式对应的MIME类型
+func ContentType(format string) string {
+	if format == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/json"
+}
+
+// Write 按指定格式写出目录
+func Write(writer io.Writer, format string, videos []*metadata.FileMetadata, exportedAt time.Time) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(&Catalog{Version: FormatVersion, ExportedAt: exportedAt, Videos: videos}); err != nil {
+			return fmt.Errorf("编码目录失败: %w", err)
+		}
+		return nil
+	case FormatCSV:
+		return writeCSV(writer, videos)
+	default:
+		return ErrUnsupportedFormat
+	}
+}
+
+// Read 按指定格式读取目录，文件格式错误时返回错误，单条记录的内容由调用方验证
+func Read(reader io.Reader, format string) ([]Record, error) {
+	switch format {
+	case FormatJSON:
+		var catalog Catalog
+		if err := json.NewDecoder(reader).Decode(&catalog); err != nil {
+			return nil, fmt.Errorf("解析目录失败: %w", err)
+		}
+		if catalog.Version < 1 || catalog.Version > FormatVersion {
+			return nil, fmt.Errorf("不支持的目录格式版本: %d", catalog.Version)
+		}
+		records := make([]Record, 0, len(catalog.Videos))
+		for i, video := range catalog.Videos {
+			if video == nil {
+				return nil, fmt.Errorf("第%d条记录为空", i+1)
+			}
+			records = append(records, Record{Line: i + 1, Metadata: video})
+		}
+		return records, nil
+	case FormatCSV:
+		return readCSV(reader)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// column CSV目录的一列
+type column struct {
+	name   string
+	format func(meta *metadata.FileMetadata) (string, error)
+	parse  func(meta *metadata.FileMetadata, value string) error
+}
+
+// columns CSV目录的列，列名与元数据的JSON字段名一致
+var columns = []column{
+	stringColumn("file_id", func(m *metadata.FileMetadata) *string { return &m.FileID }),
+	stringColumn("bucket_name", func(m *metadata.FileMetadata) *string { return &m.BucketName }),
+	stringColumn("object_name", func(m *metadata.FileMetadata) *string { return &m.ObjectName }),
+	stringColumn("file_name", func(m *metadata.FileMetadata) *string { return &m.FileName }),
+	int64Column("file_size", func(m *metadata.FileMetadata) *int64 { return &m.FileSize }),
+	stringColumn("content_type", func(m *metadata.FileMetadata) *string { return &m.ContentType }),
+	stringColumn("media_type", func(m *metadata.FileMetadata) *string { return &m.MediaType }),
+	stringColumn("title", func(m *metadata.FileMetadata) *string { return &m.Title }),
+	stringColumn("description", func(m *metadata.FileMetadata) *string { return &m.Description }),
+	jsonColumn("tags", func(m *metadata.FileMetadata) any { return &m.Tags }),
+	int64Column("duration", func(m *metadata.FileMetadata) *int64 { return &m.Duration }),
+	intColumn("width", func(m *metadata.FileMetadata) *int { return &m.Width }),
+	intColumn("height", func(m *metadata.FileMetadata) *int { return &m.Height }),
+	intColumn("rotation", func(m *metadata.FileMetadata) *int { return &m.Rotation }),
+	int64Column("bitrate", func(m *metadata.FileMetadata) *int64 { return &m.Bitrate }),
+	stringColumn("video_codec", func(m *metadata.FileMetadata) *string { return &m.VideoCodec }),
+	stringColumn("audio_codec", func(m *metadata.FileMetadata) *string { return &m.AudioCodec }),
+	{
+		name: "frame_rate",
+		format: func(m *metadata.FileMetadata) (string, error) {
+			return strconv.FormatFloat(m.FrameRate, 'f', -1, 64), nil
+		},
+		parse: func(m *metadata.FileMetadata, value string) (err error) {
+			if value != "" {
+				m.FrameRate, err = strconv.ParseFloat(value, 64)
+			}
+			return err
+		},
+	},
+	stringColumn("thumbnail", func(m *metadata.FileMetadata) *string { return &m.Thumbnail }),
+	stringColumn("preview", func(m *metadata.FileMetadata) *string { return &m.Preview }),
+	stringColumn("checksum", func(m *metadata.FileMetadata) *string { return &m.Checksum }),
+	jsonColumn("chapters", func(m *metadata.FileMetadata) any { return &m.Chapters }),
+	stringColumn("visibility", func(m *metadata.FileMetadata) *string { return &m.Visibility }),
+	stringColumn("moderation", func(m *metadata.FileMetadata) *string { return &m.Moderation }),
+	stringColumn("status", func(m *metadata.FileMetadata) *string { return &m.Status }),
+	{
+		name: "archived",
+		format: func(m *metadata.FileMetadata) (string, error) {
+			return strconv.FormatBool(m.Archived), nil
+		},
+		parse: func(m *metadata.FileMetadata, value string) (err error) {
+			if value != "" {
+				m.Archived, err = strconv.ParseBool(value)
+			}
+			return err
+		},
+	},
+	timeColumn("archived_at", func(m *metadata.FileMetadata) *time.Time { return &m.ArchivedAt }),
+	timeColumn("restored_at", func(m *metadata.FileMetadata) *time.Time { return &m.RestoredAt }),
+	stringColumn("created_by", func(m *metadata.FileMetadata) *string { return &m.CreatedBy }),
+	timeColumn("created_at", func(m *metadata.FileMetadata) *time.Time { return &m.CreatedAt }),
+	timeColumn("updated_at", func(m *metadata.FileMetadata) *time.Time { return &m.UpdatedAt }),
+	intColumn("version", func(m *metadata.FileMetadata) *int { return &m.Version }),
+	stringColumn("version_created_by", func(m *metadata.FileMetadata) *string { return &m.VersionCreatedBy }),
+	timeColumn("version_created_at", func(m *metadata.FileMetadata) *time.Time { return &m.VersionCreatedAt }),
+	jsonColumn("versions", func(m *metadata.FileMetadata) any { return &m.Versions }),
+	timeColumn("publish_at", func(m *metadata.FileMetadata) *time.Time { return &m.PublishAt }),
+	timeColumn("expires_at", func(m *metadata.FileMetadata) *time.Time { return &m.ExpiresAt }),
+	stringColumn("expiry_action", func(m *metadata.FileMetadata) *string { return &m.ExpiryAction }),
+	jsonColumn("clip_source", func(m *metadata.FileMetadata) any { return &m.ClipSource }),
+}
+
+// writeCSV 写出CSV目录，第一行为表头
+func writeCSV(writer io.Writer, videos []*metadata.FileMetadata) error {
+	csvWriter := csv.NewWriter(writer)
+	row := make([]string, len(columns))
+	for i, col := range columns {
+		row[i] = col.name
+	}
+	if err := csvWriter.Write(row); err != nil {
+		return fmt.Errorf("写出目录失败: %w", err)
+	}
+
+	for _, video := range videos {
+		for i, col := range columns {
+			value, err := col.format(video)
+			if err != nil {
+				return fmt.Errorf("编码%s的%s失败: %w", video.FileID, col.name, err)
+			}
+			row[i] = value
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return fmt.Errorf("写出目录失败: %w", err)
+		}
+	}
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return fmt.Errorf("写出目录失败: %w", err)
+	}
+	return nil
+}
+
+// readCSV 读取CSV目录，按表头匹配列，未知的列被忽略，缺少的列保持零值
+func readCSV(reader io.Reader) ([]Record, error) {
+	csvReader := csv.NewReader(reader)
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("目录为空")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析目录失败: %w", err)
+	}
+
+	indexes := make(map[string]int, len(header))
+	for i, name := range header {
+		indexes[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	if _, ok := indexes["file_id"]; !ok {
+		return nil, fmt.Errorf("目录缺少file_id列")
+	}
+
+	var records []Record
+	for {
+		row, err := csvReader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析目录失败: %w", err)
+		}
+		// 带换行的字段会占用多行，记录位置取第一列所在的行号
+		line, _ := csvReader.FieldPos(0)
+
+		meta := &metadata.FileMetadata{}
+		for _, col := range columns {
+			index, ok := indexes[col.name]
+			if !ok || index >= len(row) {
+				continue
+			}
+			if err := col.parse(meta, strings.TrimSpace(row[index])); err != nil {
+				return nil, fmt.Errorf("第%d行的%s无效: %w", line, col.name, err)
+			}
+		}
+		records = append(records, Record{Line: line, Metadata: meta})
+	}
+}
+
+// stringColumn 字符串列
+func stringColumn(name string, field func(*metadata.FileMetadata) *string) column {
+	return column{
+		name: name,
+		format: func(m *metadata.FileMetadata) (string, error) {
+			return *field(m), nil
+		},
+		parse: func(m *metadata.FileMetadata, value string) error {
+			*field(m) = value
+			return nil
+		},
+	}
+}
+
+// intColumn 整数列，空值为0
+func intColumn(name string, field func(*metadata.FileMetadata) *int) column {
+	return column{
+		name: name,
+		format: func(m *metadata.FileMetadata) (string, error) {
+			return strconv.Itoa(*field(m)), nil
+		},
+		parse: func(m *metadata.FileMetadata, value string) (err error) {
+			if value != "" {
+				*field(m), err = strconv.Atoi(value)
+			}
+			return err
+		},
+	}
+}
+
+// int64Column 64位整数列，空值为0
+func int64Column(name string, field func(*metadata.FileMetadata) *int64) column {
+	return column{
+		name: name,
+		format: func(m *metadata.FileMetadata) (string, error) {
+			return strconv.FormatInt(*field(m), 10), nil
+		},
+		parse: func(m *metadata.FileMetadata, value string) (err error) {
+			if value != "" {
+				*field(m), err = strconv.ParseInt(value, 10, 64)
+			}
+			return err
+		},
+	}
+}
+
+// timeColumn RFC 3339时间列，零值写为空
+func timeColumn(name string, field func(*metadata.FileMetadata) *time.Time) column {
+	return column{
+		name: name,
+		format: func(m *metadata.FileMetadata) (string, error) {
+			if field(m).IsZero() {
+				return "", nil
+			}
+			return field(m).Format(time.RFC3339Nano), nil
+		},
+		parse: func(m *metadata.FileMetadata, value string) (err error) {
+			if value != "" {
+				*field(m), err = time.Parse(time.RFC3339Nano, value)
+			}
+			return err
+		},
+	}
+}
+
+// jsonColumn 以JSON编码的列表列，空列表写为空
+func jsonColumn(name string, field func(*metadata.FileMetadata) any) column {
+	return column{
+		name: name,
+		format: func(m *metadata.FileMetadata) (string, error) {
+			data, err := json.Marshal(field(m))
+			if err != nil {
+				return "", err
+			}
+			if value := string(data); value != "null" && value != "[]" {
+				return value, nil
+			}
+			return "", nil
+		},
+		parse: func(m *metadata.FileMetadata, value string) error {
+			if value == "" {
+				return nil
+			}
+			return json.Unmarshal([]byte(value), field(m))
+		},
+	}
+}
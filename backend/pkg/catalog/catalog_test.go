@@ -0,0 +1,136 @@
+package catalog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+// testVideos 创建测试用元数据，包含需要转义的描述、标签、章节和历史版本
+func testVideos() []*metadata.FileMetadata {
+	created := time.Date(2025, 8, 1, 10, 0, 0, 123000000, time.UTC)
+	return []*metadata.FileMetadata{
+		{
+			FileID:      "video1",
+			BucketName:  "zhulong-videos",
+			ObjectName:  "videos/2025/08/video1.mp4",
+			FileName:    "video1.mp4",
+			FileSize:    1024,
+			ContentType: "video/mp4",
+			MediaType:   metadata.MediaTypeVideo,
+			Title:       "第一个视频",
+			Description: "包含逗号, \"引号\"\n和换行",
+			Tags:        []string{"demo", "test pattern"},
+			Duration:    10,
+			Width:       1920,
+			Height:      1080,
+			FrameRate:   29.97,
+			Checksum:    "abc123",
+			Chapters:    []metadata.Chapter{{Title: "开场", StartTime: 0, EndTime: 5000}},
+			Visibility:  metadata.VisibilityPublic,
+			Status:      metadata.StatusReady,
+			CreatedBy:   "admin",
+			CreatedAt:   created,
+			UpdatedAt:   created.Add(time.Hour),
+			Version:     2,
+			Versions: []metadata.Version{{
+				Number:     1,
+				BucketName: "zhulong-videos",
+				ObjectName: "videos/2025/07/video1.mp4",
+				FileSize:   512,
+				CreatedBy:  "admin",
+				CreatedAt:  created.Add(-time.Hour),
+			}},
+			PublishAt:    created.Add(2 * time.Hour),
+			ExpiresAt:    created.Add(48 * time.Hour),
+			ExpiryAction: metadata.ExpiryDelete,
+			ClipSource:   &metadata.ClipSource{FileID: "video0", StartTime: 1000, EndTime: 11000},
+		},
+		{
+			FileID:     "video2",
+			BucketName: "zhulong-videos",
+			ObjectName: "videos/2025/08/video2.mp4",
+			Title:      "第二个视频",
+			Archived:   true,
+			ArchivedAt: created,
+			CreatedBy:  "alice",
+			CreatedAt:  created,
+			UpdatedAt:  created,
+		},
+	}
+}
+
+func TestNormalizeFormat(t *testing.T) {
+	format, err := NormalizeFormat(" CSV ", "catalog.json")
+	require.NoError(t, err)
+	assert.Equal(t, FormatCSV, format, "指定的格式优先于扩展名")
+
+	format, err = NormalizeFormat("", "zhulong-catalog.JSON")
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, format)
+
+	_, err = NormalizeFormat("", "catalog.xlsx")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+	_, err = NormalizeFormat("xml", "catalog.csv")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestWriteRead(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Write(&buf, format, testVideos(), time.Now()))
+
+			records, err := Read(&buf, format)
+			require.NoError(t, err)
+			require.Len(t, records, 2)
+			for i, video := range testVideos() {
+				assert.Equal(t, video, records[i].Metadata)
+			}
+			if format == FormatCSV {
+				assert.Equal(t, []int{2, 4}, []int{records[0].Line, records[1].Line}, "描述中的换行占用一行")
+			} else {
+				assert.Equal(t, []int{1, 2}, []int{records[0].Line, records[1].Line})
+			}
+		})
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	t.Run("按表头匹配列", func(t *testing.T) {
+		input := "\ufefftitle,file_id,extra,tags\n编辑后的标题,video1,ignored,\"[\"\"a\"\"]\"\n"
+		records, err := Read(strings.NewReader(input), FormatCSV)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, &metadata.FileMetadata{FileID: "video1", Title: "编辑后的标题", Tags: []string{"a"}}, records[0].Metadata)
+	})
+
+	t.Run("文件格式错误", func(t *testing.T) {
+		for name, input := range map[string]string{
+			"空文件":       "",
+			"缺少file_id": "title\n标题\n",
+			"数值无效":      "file_id,file_size\nvideo1,abc\n",
+			"JSON列无效":   "file_id,tags\nvideo1,[a\n",
+		} {
+			_, err := Read(strings.NewReader(input), FormatCSV)
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func TestReadJSON(t *testing.T) {
+	_, err := Read(strings.NewReader(`{"version":2,"videos":[]}`), FormatJSON)
+	assert.ErrorContains(t, err, "格式版本")
+
+	_, err = Read(strings.NewReader(`{"version":1,"videos":[null]}`), FormatJSON)
+	assert.Error(t, err)
+
+	_, err = Read(strings.NewReader(`[`), FormatJSON)
+	assert.Error(t, err)
+}
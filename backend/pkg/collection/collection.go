@@ -0,0 +1,300 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// 合集名称和描述长度限制（按字符计）
+const (
+	MaxNameLength        = 100
+	MaxDescriptionLength = 1000
+)
+
+var (
+	// ErrCollectionNotFound 合集不存在
+	ErrCollectionNotFound = errors.New("合集不存在")
+	// ErrCollectionExists 合集名称已存在
+	ErrCollectionExists = errors.New("合集名称已存在")
+)
+
+// Collection 视频合集
+type Collection struct {
+	ID          string    `json:"id"`          // 合集ID
+	Name        string    `json:"name"`        // 合集名称
+	Description string    `json:"description"` // 合集描述
+	VideoCount  int       `json:"video_count"` // 视频数量
+	CreatedBy   string    `json:"created_by"`  // 创建者
+	CreatedAt   time.Time `json:"created_at"`  // 创建时间
+	UpdatedAt   time.Time `json:"updated_at"`  // 更新时间
+}
+
+// CreateRequest 创建合集请求
+type CreateRequest struct {
+	Name        string `json:"name"`        // 合集名称
+	Description string `json:"description"` // 合集描述（可选）
+	CreatedBy   string `json:"created_by"`  // 创建者
+}
+
+// UpdateRequest 更新合集请求，只更新非nil字段
+type UpdateRequest struct {
+	Name        *string `json:"name"`        // 合集名称（可选）
+	Description *string `json:"description"` // 合集描述（可选）
+}
+
+// CollectionService 合集管理服务
+type CollectionService struct {
+	collections map[string]*Collection          // 合集ID -> 合集
+	names       map[string]string               // 小写合集名称 -> 合集ID
+	videos      map[string]map[string]time.Time // 合集ID -> 视频ID -> 加入时间
+	mutex       sync.RWMutex
+}
+
+// NewCollectionService 创建合集服务
+func NewCollectionService() *CollectionService {
+	return &CollectionService{
+		collections: make(map[string]*Collection),
+		names:       make(map[string]string),
+		videos:      make(map[string]map[string]time.Time),
+	}
+}
+
+// Create 创建合集，合集名称不区分大小写且不能重复
+func (s *CollectionService) Create(ctx context.Context, req *CreateRequest) (*Collection, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	if err := validateDescription(req.Description); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := strings.ToLower(name)
+	if _, exists := s.names[key]; exists {
+		return nil, ErrCollectionExists
+	}
+
+	now := time.Now()
+	collection := &Collection{
+		ID:          uuid.New().String(),
+		Name:        name,
+		Description: req.Description,
+		CreatedBy:   req.CreatedBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	s.collections[collection.ID] = collection
+	s.names[key] = collection.ID
+	s.videos[collection.ID] = make(map[string]time.Time)
+
+	return s.copyCollectionLocked(collection), nil
+}
+
+// Get 根据ID获取合集
+func (s *CollectionService) Get(ctx context.Context, collectionID string) (*Collection, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	collection, exists := s.collections[collectionID]
+	if !exists {
+		return nil, ErrCollectionNotFound
+	}
+
+	return s.copyCollectionLocked(collection), nil
+}
+
+// Update 重命名合集或修改描述
+func (s *CollectionService) Update(ctx context.Context, collectionID string, req *UpdateRequest) (*Collection, error) {
+	var name string
+	if req.Name != nil {
+		name = strings.TrimSpace(*req.Name)
+		if err := validateName(name); err != nil {
+			return nil, err
+		}
+	}
+	if req.Description != nil {
+		if err := validateDescription(*req.Description); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	collection, exists := s.collections[collectionID]
+	if !exists {
+		return nil, ErrCollectionNotFound
+	}
+
+	if req.Name != nil {
+		key := strings.ToLower(name)
+		if id, exists := s.names[key]; exists && id != collectionID {
+			return nil, ErrCollectionExists
+		}
+		delete(s.names, strings.ToLower(collection.Name))
+		s.names[key] = collectionID
+		collection.Name = name
+	}
+	if req.Description != nil {
+		collection.Description = *req.Description
+	}
+	collection.UpdatedAt = time.Now()
+
+	return s.copyCollectionLocked(collection), nil
+}
+
+// Delete 删除合集，合集中的视频不受影响
+func (s *CollectionService) Delete(ctx context.Context, collectionID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	collection, exists := s.collections[collectionID]
+	if !exists {
+		return ErrCollectionNotFound
+	}
+
+	delete(s.names, strings.ToLower(collection.Name))
+	delete(s.videos, collectionID)
+	delete(s.collections, collectionID)
+	return nil
+}
+
+// List 列出所有合集，按名称升序
+func (s *CollectionService) List(ctx context.Context) []*Collection {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	collections := make([]*Collection, 0, len(s.collections))
+	for _, collection := range s.collections {
+		collections = append(collections, s.copyCollectionLocked(collection))
+	}
+
+	sort.Slice(collections, func(i, j int) bool {
+		return strings.ToLower(collections[i].Name) < strings.ToLower(collections[j].Name)
+	})
+
+	return collections
+}
+
+// AddVideos 将视频加入合集，已在合集中的视频会被忽略
+func (s *CollectionService) AddVideos(ctx context.Context, collectionID string, videoIDs []string) (*Collection, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	collection, exists := s.collections[collectionID]
+	if !exists {
+		return nil, ErrCollectionNotFound
+	}
+
+	now := time.Now()
+	members := s.videos[collectionID]
+	for _, videoID := range videoIDs {
+		if _, added := members[videoID]; !added {
+			members[videoID] = now
+		}
+	}
+	collection.UpdatedAt = now
+
+	return s.copyCollectionLocked(collection), nil
+}
+
+// RemoveVideos 将视频移出合集，不在合集中的视频会被忽略
+func (s *CollectionService) RemoveVideos(ctx context.Context, collectionID string, videoIDs []string) (*Collection, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	collection, exists := s.collections[collectionID]
+	if !exists {
+		return nil, ErrCollectionNotFound
+	}
+
+	members := s.videos[collectionID]
+	for _, videoID := range videoIDs {
+		delete(members, videoID)
+	}
+	collection.UpdatedAt = time.Now()
+
+	return s.copyCollectionLocked(collection), nil
+}
+
+// ListVideoIDs 列出合集中的视频ID，按加入时间升序
+// 合集为空时返回非nil的空切片
+func (s *CollectionService) ListVideoIDs(ctx context.Context, collectionID string) ([]string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	members, exists := s.videos[collectionID]
+	if !exists {
+		return nil, ErrCollectionNotFound
+	}
+
+	videoIDs := make([]string, 0, len(members))
+	for videoID := range members {
+		videoIDs = append(videoIDs, videoID)
+	}
+
+	sort.Slice(videoIDs, func(i, j int) bool {
+		ti, tj := members[videoIDs[i]], members[videoIDs[j]]
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return videoIDs[i] < videoIDs[j]
+	})
+
+	return videoIDs, nil
+}
+
+// RemoveVideoFromAll 将视频移出所有合集，视频删除时调用
+func (s *CollectionService) RemoveVideoFromAll(ctx context.Context, videoID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, members := range s.videos {
+		delete(members, videoID)
+	}
+}
+
+// copyCollectionLocked 复制合集信息并填充视频数量，调用方需持有锁
+func (s *CollectionService) copyCollectionLocked(collection *Collection) *Collection {
+	copied := *collection
+	copied.VideoCount = len(s.videos[collection.ID])
+	return &copied
+}
+
+// validateName 验证合集名称
+func validateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("合集名称不能为空")
+	}
+	if utf8.RuneCountInString(name) > MaxNameLength {
+		return fmt.Errorf("合集名称长度不能超过%d个字符", MaxNameLength)
+	}
+	return nil
+}
+
+// validateDescription 验证合集描述
+func validateDescription(description string) error {
+	if utf8.RuneCountInString(description) > MaxDescriptionLength {
+		return fmt.Errorf("合集描述长度不能超过%d个字符", MaxDescriptionLength)
+	}
+	return nil
+}
+
+// Count 统计合集数量
+func (s *CollectionService) Count(ctx context.Context) int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.collections)
+}
@@ -0,0 +1,129 @@
+package collection
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stringPtr 返回字符串指针
+func stringPtr(value string) *string {
+	return &value
+}
+
+// TestCollectionService_Create 测试创建合集
+func TestCollectionService_Create(t *testing.T) {
+	service := NewCollectionService()
+	ctx := context.Background()
+
+	created, err := service.Create(ctx, &CreateRequest{Name: "  旅行  ", Description: "旅行视频", CreatedBy: "alice"})
+	require.NoError(t, err, "创建合集应该成功")
+	assert.NotEmpty(t, created.ID, "合集ID不应为空")
+	assert.Equal(t, "旅行", created.Name, "合集名称应该去除首尾空白")
+	assert.Equal(t, "alice", created.CreatedBy)
+	assert.Equal(t, 0, created.VideoCount)
+
+	_, err = service.Create(ctx, &CreateRequest{Name: "旅行"})
+	assert.ErrorIs(t, err, ErrCollectionExists, "合集名称不能重复")
+
+	_, err = service.Create(ctx, &CreateRequest{Name: "Movies"})
+	require.NoError(t, err)
+	_, err = service.Create(ctx, &CreateRequest{Name: "MOVIES"})
+	assert.ErrorIs(t, err, ErrCollectionExists, "合集名称应该不区分大小写去重")
+
+	_, err = service.Create(ctx, &CreateRequest{Name: "   "})
+	assert.Error(t, err, "合集名称不能为空")
+	_, err = service.Create(ctx, &CreateRequest{Name: strings.Repeat("长", MaxNameLength+1)})
+	assert.Error(t, err, "合集名称不能过长")
+}
+
+// TestCollectionService_Update 测试重命名合集
+func TestCollectionService_Update(t *testing.T) {
+	service := NewCollectionService()
+	ctx := context.Background()
+
+	travel, err := service.Create(ctx, &CreateRequest{Name: "旅行", Description: "原始描述"})
+	require.NoError(t, err)
+	_, err = service.Create(ctx, &CreateRequest{Name: "音乐"})
+	require.NoError(t, err)
+
+	updated, err := service.Update(ctx, travel.ID, &UpdateRequest{Name: stringPtr("旅行Vlog")})
+	require.NoError(t, err, "重命名应该成功")
+	assert.Equal(t, "旅行Vlog", updated.Name)
+	assert.Equal(t, "原始描述", updated.Description, "未传入的字段不应该被修改")
+
+	_, err = service.Create(ctx, &CreateRequest{Name: "旅行"})
+	assert.NoError(t, err, "重命名后原名称应该可以再次使用")
+
+	_, err = service.Update(ctx, travel.ID, &UpdateRequest{Name: stringPtr("音乐")})
+	assert.ErrorIs(t, err, ErrCollectionExists, "不能重命名为已存在的名称")
+
+	_, err = service.Update(ctx, travel.ID, &UpdateRequest{Name: stringPtr("旅行VLOG")})
+	assert.NoError(t, err, "可以修改自身名称的大小写")
+
+	_, err = service.Update(ctx, "missing", &UpdateRequest{Name: stringPtr("新名称")})
+	assert.ErrorIs(t, err, ErrCollectionNotFound)
+}
+
+// TestCollectionService_Videos 测试合集视频管理
+func TestCollectionService_Videos(t *testing.T) {
+	service := NewCollectionService()
+	ctx := context.Background()
+
+	travel, err := service.Create(ctx, &CreateRequest{Name: "旅行"})
+	require.NoError(t, err)
+	music, err := service.Create(ctx, &CreateRequest{Name: "音乐"})
+	require.NoError(t, err)
+
+	videoIDs, err := service.ListVideoIDs(ctx, travel.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, videoIDs, "空合集应该返回非nil的空切片")
+	assert.Empty(t, videoIDs)
+
+	updated, err := service.AddVideos(ctx, travel.ID, []string{"video1", "video2", "video1"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.VideoCount, "重复的视频应该被忽略")
+
+	_, err = service.AddVideos(ctx, music.ID, []string{"video2"})
+	require.NoError(t, err)
+
+	updated, err = service.RemoveVideos(ctx, travel.ID, []string{"video1", "video3"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated.VideoCount)
+
+	service.RemoveVideoFromAll(ctx, "video2")
+	for _, collection := range service.List(ctx) {
+		assert.Equal(t, 0, collection.VideoCount, "删除的视频应该移出所有合集")
+	}
+
+	_, err = service.AddVideos(ctx, "missing", []string{"video1"})
+	assert.ErrorIs(t, err, ErrCollectionNotFound)
+	_, err = service.ListVideoIDs(ctx, "missing")
+	assert.ErrorIs(t, err, ErrCollectionNotFound)
+}
+
+// TestCollectionService_Delete 测试删除合集
+func TestCollectionService_Delete(t *testing.T) {
+	service := NewCollectionService()
+	ctx := context.Background()
+
+	travel, err := service.Create(ctx, &CreateRequest{Name: "旅行"})
+	require.NoError(t, err)
+	_, err = service.AddVideos(ctx, travel.ID, []string{"video1"})
+	require.NoError(t, err)
+
+	require.NoError(t, service.Delete(ctx, travel.ID))
+	_, err = service.Get(ctx, travel.ID)
+	assert.ErrorIs(t, err, ErrCollectionNotFound, "删除后合集应该不存在")
+	assert.Empty(t, service.List(ctx))
+	assert.Equal(t, 0, service.Count(ctx))
+
+	_, err = service.Create(ctx, &CreateRequest{Name: "旅行"})
+	assert.NoError(t, err, "删除后名称应该可以再次使用")
+	assert.Equal(t, 1, service.Count(ctx))
+
+	assert.ErrorIs(t, service.Delete(ctx, travel.ID), ErrCollectionNotFound)
+}
@@ -2,28 +2,75 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/cloudwego/hertz/pkg/common/hlog"
 	"github.com/fsnotify/fsnotify"
 	"gopkg.in/yaml.v3"
 	
+	"github.com/manteia/zhulong/pkg/cache"
+	"github.com/manteia/zhulong/pkg/i18n"
+	"github.com/manteia/zhulong/pkg/metadata"
+	"github.com/manteia/zhulong/pkg/middleware"
+	"github.com/manteia/zhulong/pkg/moderation"
 	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/tempfile"
+	"github.com/manteia/zhulong/pkg/user"
+	"github.com/manteia/zhulong/pkg/webhook"
 )
 
 // Config 应用配置结构
 type Config struct {
-	Server ServerConfig `yaml:"server"`
-	MinIO  MinIOConfig  `yaml:"minio"`
-	App    AppConfig    `yaml:"app"`
+	Server    ServerConfig    `yaml:"server"`
+	MinIO     MinIOConfig     `yaml:"minio"`
+	Storage   StorageConfig   `yaml:"storage"`
+	App       AppConfig       `yaml:"app"`
+	JWT       JWTConfig       `yaml:"jwt"`
+	Admin     AdminConfig     `yaml:"admin"`
+	Streaming StreamingConfig `yaml:"streaming"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Quota     QuotaConfig     `yaml:"quota"`
+	Upload    UploadConfig    `yaml:"upload"`
+	Archive   ArchiveConfig   `yaml:"archive"`
+	Backup    BackupConfig    `yaml:"backup"`
+	Playback  PlaybackConfig  `yaml:"playback"`
+	Worker    WorkerConfig    `yaml:"worker"`
+	Cache     CacheConfig     `yaml:"cache"`
+	CORS      CORSConfig      `yaml:"cors"`
+	Webhooks  []WebhookConfig `yaml:"webhooks"`
+
+	Moderation  ModerationConfig  `yaml:"moderation"`
+	Temp        TempConfig        `yaml:"temp"`
+	Replication ReplicationConfig `yaml:"replication"`
+	Schedule    ScheduleConfig    `yaml:"schedule"`
+
+	sourceFile string // 加载配置的文件路径，用于备份配置文件
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
 	Host string `yaml:"host"`
 	Port int    `yaml:"port"`
+	// DefaultLanguage 请求未通过Accept-Language指定语言时响应消息使用的语言（zh-CN或en）
+	DefaultLanguage string `yaml:"default_language"`
+	// TrustedProxies 受信任的反向代理地址（CIDR、IP或private），只有来自这些地址的请求才使用X-Forwarded-*请求头
+	TrustedProxies []string  `yaml:"trusted_proxies"`
+	TLS            TLSConfig `yaml:"tls"` // HTTPS配置
+}
+
+// TLSConfig HTTPS配置
+type TLSConfig struct {
+	Enabled  bool     `yaml:"enabled"`   // 是否使用HTTPS监听
+	CertFile string   `yaml:"cert_file"` // 证书文件，与key_file都为空时自动生成自签名证书
+	KeyFile  string   `yaml:"key_file"`  // 私钥文件
+	CertDir  string   `yaml:"cert_dir"`  // 自签名证书的保存目录，为空时使用配置文件所在目录下的tls目录
+	Hosts    []string `yaml:"hosts"`     // 自签名证书额外包含的主机名或IP，本机主机名和网卡地址自动包含
 }
 
 // MinIOConfig MinIO配置
@@ -36,18 +83,291 @@ type MinIOConfig struct {
 	Bucket    string `yaml:"bucket"`
 }
 
+// StorageConfig 存储驱动配置
+type StorageConfig struct {
+	Driver            string             `yaml:"driver"`             // 存储驱动：minio/s3/local/memory，默认minio（使用minio配置）
+	S3                S3Config           `yaml:"s3"`
+	Local             LocalStorageConfig `yaml:"local"`
+	DeleteConcurrency int                `yaml:"delete_concurrency"` // 批量删除的并发数，为0时使用默认值8
+	Buckets           BucketsConfig      `yaml:"buckets"`            // 按内容类别选择存储桶
+	PublicEndpoint    string             `yaml:"public_endpoint"`    // 预签名URL的对外基础URL，存储服务位于反向代理之后时使用，为空时使用存储服务地址
+	PublicHost        string             `yaml:"public_host"`        // 预签名URL的对外主机，只替换主机名和端口，不能与public_endpoint同时配置
+
+	PrefixDeleteMaxObjects int `yaml:"prefix_delete_max_objects"` // 按前缀删除单次最多删除的文件数，为0时使用默认值1000
+
+	Timeouts       StorageTimeoutsConfig       `yaml:"timeouts"`        // 各类存储操作的超时时间
+	Retry          StorageRetryConfig          `yaml:"retry"`           // 临时错误的重试
+	CircuitBreaker StorageCircuitBreakerConfig `yaml:"circuit_breaker"` // 连续失败后的熔断
+}
+
+// StorageTimeoutsConfig 存储操作超时时间，如"30s"，"0"表示不限制
+type StorageTimeoutsConfig struct {
+	Metadata string `yaml:"metadata"` // 查询文件信息、删除单个文件、对象标签和存储桶等操作，默认30s
+	Batch    string `yaml:"batch"`    // 列出文件和批量删除，默认5m
+	Upload   string `yaml:"upload"`   // 上传、复制和移动单个文件的总时长，默认1h
+	Download string `yaml:"download"` // 打开文件和读取时等待数据的最长时间，默认1m
+}
+
+// StorageRetryConfig 存储服务临时错误（网络错误、5xx响应）的重试，只重试可以安全重复的操作
+type StorageRetryConfig struct {
+	MaxAttempts int    `yaml:"max_attempts"` // 最多尝试次数（包括首次），为0时使用默认值3，1表示不重试
+	BaseDelay   string `yaml:"base_delay"`   // 第一次重试前的最长等待时间，之后每次翻倍，默认100ms
+	MaxDelay    string `yaml:"max_delay"`    // 重试等待时间的上限，默认2s
+}
+
+// StorageCircuitBreakerConfig 存储服务连续失败后的熔断，熔断期间存储操作直接失败，不再等待超时
+type StorageCircuitBreakerConfig struct {
+	FailureThreshold int    `yaml:"failure_threshold"` // 连续失败多少次后熔断，为0时使用默认值5
+	OpenDuration     string `yaml:"open_duration"`     // 熔断持续时间，之后放行一次探测请求，默认30s，"0"表示不熔断
+}
+
+// BucketsConfig 各类内容使用的存储桶，为空时使用minio.bucket
+type BucketsConfig struct {
+	Videos     string `yaml:"videos"`     // 原始视频文件
+	Thumbnails string `yaml:"thumbnails"` // 缩略图、动态预览和进度条预览图
+	Renditions string `yaml:"renditions"` // HLS播放列表和分片
+	Subtitles  string `yaml:"subtitles"`  // 字幕文件
+}
+
+// S3Config AWS S3配置
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint"` // 为空时使用AWS默认端点
+	AccessKey string `yaml:"access_key"`
+	SecretKey string `yaml:"secret_key"`
+	UseSSL    bool   `yaml:"use_ssl"`
+	Region    string `yaml:"region"`
+}
+
+// LocalStorageConfig 本地文件系统存储配置
+type LocalStorageConfig struct {
+	RootDir    string `yaml:"root_dir"`
+	BaseURL    string `yaml:"base_url"`    // 预签名URL前缀，为空时根据服务器地址生成
+	SigningKey string `yaml:"signing_key"` // 预签名URL签名密钥，为空时每次启动随机生成
+}
+
 // AppConfig 应用配置
 type AppConfig struct {
-	Name    string `yaml:"name"`
-	Version string `yaml:"version"`
-	Debug   bool   `yaml:"debug"`
+	Name     string `yaml:"name"`
+	Version  string `yaml:"version"`
+	Debug    bool   `yaml:"debug"`
+	LogLevel string `yaml:"log_level"` // 日志级别：trace/debug/info/notice/warn/error/fatal，为空时开启debug为debug，否则为info
+}
+
+// JWTConfig JWT认证配置
+type JWTConfig struct {
+	Secret string `yaml:"secret"`
+	Expire string `yaml:"expire"` // 有效期，支持Go时长格式及天数（如"7d"）
+}
+
+// AdminConfig 初始管理员账号配置
+// 启动时账号不存在则创建管理员，已存在则提升为管理员；注册接口创建的用户都为观看者
+type AdminConfig struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Email    string `yaml:"email"`
+}
+
+// StreamingConfig 流媒体（HLS）配置
+type StreamingConfig struct {
+	Enabled         bool              `yaml:"enabled"`
+	PackageOnUpload bool              `yaml:"package_on_upload"`
+	FFmpegPath      string            `yaml:"ffmpeg_path"`
+	SegmentDuration int               `yaml:"segment_duration"`
+	TempDir         string            `yaml:"temp_dir"` // 已由temp.dir代替，temp.dir为空时使用
+	Renditions      []RenditionConfig `yaml:"renditions"`
+	Workers         int               `yaml:"workers"`      // 同时执行的转码任务数量
+	MaxAttempts     int               `yaml:"max_attempts"` // 转码任务进入死信状态前的最大执行次数
+}
+
+// RenditionConfig HLS码率档位配置
+type RenditionConfig struct {
+	Name         string `yaml:"name"`
+	Width        int    `yaml:"width"`
+	Height       int    `yaml:"height"`
+	VideoBitrate int    `yaml:"video_bitrate"` // kbps
+	AudioBitrate int    `yaml:"audio_bitrate"` // kbps
+}
+
+// RateLimitConfig 限流配置，上传和播放接口使用独立规则，其他接口使用全局规则
+type RateLimitConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Global   RateLimitRule `yaml:"global"`
+	Upload   RateLimitRule `yaml:"upload"`
+	Playback RateLimitRule `yaml:"playback"`
+}
+
+// RateLimitRule 令牌桶限流规则，按用户（未登录时按IP）分别计数
+type RateLimitRule struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second"` // 令牌补充速率
+	Burst             int     `yaml:"burst"`               // 桶容量，允许的突发请求数
+}
+
+// QuotaConfig 用户存储配额配置
+type QuotaConfig struct {
+	UserLimit string `yaml:"user_limit"` // 每个用户可存储的总大小，如"10GB"，"0"表示不限制
+}
+
+// UploadConfig 上传配置
+type UploadConfig struct {
+	MaxSize        string `yaml:"max_size"`        // 单个视频最大大小，如"500MB"，为空时使用默认值2GB
+	AllowedTypes   string `yaml:"allowed_types"`   // 允许的内容类型（逗号分隔），可用"类型=格式"映射新的内容类型，为空时使用内置映射
+	AllowedFormats string `yaml:"allowed_formats"` // 允许上传的视频格式（逗号分隔，如"mp4,webm"），为空时允许所有可识别的格式
+	// MediaTypes 允许上传的媒体类型（逗号分隔）：video/image/audio，为空时只允许视频
+	// 图片和音频允许所有可识别的格式，不受allowed_types和allowed_formats限制
+	MediaTypes string `yaml:"media_types"`
+	Deduplication  string `yaml:"deduplication"`   // 重复视频处理方式：off/reject/alias，为空时不检测
+	// DefaultVisibility 上传和导入的视频未指定可见性时使用的可见性：private/unlisted/public，为空时为private
+	DefaultVisibility string `yaml:"default_visibility"`
+	// 分片上传会话有效期和清理间隔，如"24h"；超过有效期没有上传分片的会话会被中止并清理已上传的分片
+	MultipartSessionTTL      string `yaml:"multipart_session_ttl"`
+	MultipartCleanupInterval string `yaml:"multipart_cleanup_interval"`
+	// 上传带宽限制（每秒字节数，如"20MB"），为空或"0"时不限制
+	// bandwidth_limit为所有上传共享的总带宽，connection_bandwidth_limit为单个上传请求的带宽
+	BandwidthLimit           string `yaml:"bandwidth_limit"`
+	ConnectionBandwidthLimit string `yaml:"connection_bandwidth_limit"`
+	// MaxVersions 替换视频文件时每个视频保留的历史版本数量，超出时删除最旧的版本，为0时使用默认值5
+	MaxVersions int `yaml:"max_versions"`
+	// 按格式（文件扩展名）和按用户角色的单个视频最大大小，如{"mp4": "4GB"}、{"uploader": "1GB"}，代替max_size
+	// 同一上传同时匹配格式和角色的限制时使用较小的一个
+	FormatMaxSizes map[string]string `yaml:"format_max_sizes"`
+	RoleMaxSizes   map[string]string `yaml:"role_max_sizes"`
+	// Verification 保存前的文件验证方式：header只检查文件头；structure解析完整的容器结构；
+	// probe在structure的基础上使用ffprobe解码检查，ffprobe不可用时退回structure。为空时为header
+	Verification string `yaml:"verification"`
+	FFprobePath  string `yaml:"ffprobe_path"` // ffprobe可执行文件路径，默认ffprobe
+}
+
+// ArchiveConfig 冷存储归档配置，长时间未播放的视频文件移动到归档存储桶，播放时自动恢复
+type ArchiveConfig struct {
+	Enabled       bool   `yaml:"enabled"`        // 是否定期归档长时间未播放的视频
+	Bucket        string `yaml:"bucket"`         // 归档存储桶，可配置为低成本存储类别的存储桶
+	IdleAfter     string `yaml:"idle_after"`     // 超过该时长未播放（从未播放时按上传时间）的视频会被归档，如"30d"
+	CheckInterval string `yaml:"check_interval"` // 检查间隔，如"1h"
+}
+
+// BackupConfig 元数据和配置文件的定期备份
+type BackupConfig struct {
+	Enabled   bool   `yaml:"enabled"`   // 是否定期备份
+	Bucket    string `yaml:"bucket"`    // 备份存储桶，不能与视频等内容的存储桶相同
+	Interval  string `yaml:"interval"`  // 备份间隔，如"24h"
+	Retention int    `yaml:"retention"` // 保留的备份数量，保存新备份后删除更早的备份
+}
+
+// ScheduleConfig 视频定时发布和到期配置，发布时间和到期时间在视频上单独设置
+type ScheduleConfig struct {
+	CheckInterval string `yaml:"check_interval"` // 处理到期视频的检查间隔，如"1m"
+}
+
+// ReplicationConfig 存储复制，将写入存储的文件异步复制到第二个存储服务中的同名存储桶
+type ReplicationConfig struct {
+	Enabled       bool   `yaml:"enabled"`        // 是否启用复制
+	Driver        string `yaml:"driver"`         // 副本存储驱动：minio/s3/local，默认minio
+	Endpoint      string `yaml:"endpoint"`       // minio和s3驱动的端点，s3驱动为空时使用AWS默认端点
+	AccessKey     string `yaml:"access_key"`
+	SecretKey     string `yaml:"secret_key"`
+	UseSSL        bool   `yaml:"use_ssl"`
+	Region        string `yaml:"region"`
+	RootDir       string `yaml:"root_dir"`       // local驱动的根目录，如挂载的异地NAS目录
+	Workers       int    `yaml:"workers"`        // 并发复制的文件数
+	QueueSize     int    `yaml:"queue_size"`     // 等待复制的操作上限，队列已满时丢弃，由定期同步补齐
+	SyncInterval  string `yaml:"sync_interval"`  // 定期对比两个存储并补齐缺失文件的间隔，如"6h"，"0"表示不定期同步
+	MirrorDeletes bool   `yaml:"mirror_deletes"` // 是否同步删除，默认保留副本中已删除的文件
+}
+
+type PlaybackConfig struct {
+	SignedURLs bool   `yaml:"signed_urls"` // 播放URL使用服务签发的播放令牌（/stream/:video_id?token=...），不返回存储的预签名URL
+	Secret     string `yaml:"secret"`      // 播放令牌签名密钥，未配置时使用JWT密钥
+	BaseURL    string `yaml:"base_url"`    // 播放URL的服务地址前缀，如"http://nas.lan:8888"，为空时返回相对路径
+}
+
+// WorkerConfig 处理任务（HLS打包和预览图生成）的执行方式配置
+type WorkerConfig struct {
+	Mode         string `yaml:"mode"`          // local：由API服务执行；remote：由zhulong worker进程通过任务接口领取执行
+	Secret       string `yaml:"secret"`        // worker访问任务接口的共享密钥，remote模式下必须配置
+	LeaseTimeout string `yaml:"lease_timeout"` // worker超过该时长未上报心跳时任务按失败处理，如"2m"
+}
+
+// CacheConfig 缓存配置，用于复用近期签发的预签名URL
+type CacheConfig struct {
+	Driver     string           `yaml:"driver"`      // none：不缓存；memory：进程内LRU缓存；redis：多个服务实例共享的Redis缓存
+	MaxEntries int              `yaml:"max_entries"` // memory驱动的最大缓存条目数
+	Redis      CacheRedisConfig `yaml:"redis"`
+}
+
+// CacheRedisConfig Redis缓存配置
+type CacheRedisConfig struct {
+	Addr     string `yaml:"addr"`     // Redis地址，如"localhost:6379"
+	Password string `yaml:"password"` // Redis密码，为空时不认证
+	DB       int    `yaml:"db"`       // Redis数据库编号
+}
+
+// CORSConfig 跨域请求配置
+type CORSConfig struct {
+	Mode             string   `yaml:"mode"`              // off：不处理跨域请求；lan：允许本机和局域网来源及allowed_origins；strict：只允许allowed_origins
+	AllowedOrigins   []string `yaml:"allowed_origins"`   // 允许的来源，如"https://video.example.com"，"https://*.example.com"匹配其子域名
+	AllowCredentials bool     `yaml:"allow_credentials"` // 是否允许跨域请求携带Cookie和Authorization凭据
+	MaxAge           string   `yaml:"max_age"`           // 预检请求结果的缓存时长，如"12h"
+	PublicStreaming  bool     `yaml:"public_streaming"`  // 是否允许任意来源跨域读取播放令牌签名的视频流、海报和字幕，投屏时需要开启
+}
+
+// WebhookConfig Webhook订阅配置，视频生命周期事件以签名的JSON请求POST到URL
+type WebhookConfig struct {
+	URL    string   `yaml:"url"`    // 接收事件的地址
+	Secret string   `yaml:"secret"` // 签名密钥，为空时不签名
+	Events []string `yaml:"events"` // 订阅的事件：video.uploaded/video.transcoded/video.deleted/thumbnail.ready，为空时订阅全部
+}
+
+// ModerationConfig 内容审核配置，上传完成后按顺序执行审核钩子，未配置钩子时不审核
+type ModerationConfig struct {
+	Hooks   []ModerationHookConfig `yaml:"hooks"`
+	OnError string                 `yaml:"on_error"` // 钩子执行失败时的处理：flag标记视频交给管理员审核（默认），approve忽略
 }
 
+// TempConfig 临时目录配置，上传缓冲到磁盘的文件、转码和帧提取的临时文件都写入该目录
+type TempConfig struct {
+	Dir             string `yaml:"dir"`              // 临时目录，为空时使用系统临时目录下的zhulong目录
+	MaxSize         string `yaml:"max_size"`         // 临时目录占用上限，达到上限后拒绝新的转码和预览任务，如"10GB"，为0时不限制
+	MaxAge          string `yaml:"max_age"`          // 超过该时长未修改的残留临时文件在清理时删除，如"24h"
+	CleanupInterval string `yaml:"cleanup_interval"` // 清理间隔，如"10m"
+}
+
+// ModerationHookConfig 审核钩子配置，执行外部命令检查视频，如NSFW检测或自定义脚本
+type ModerationHookConfig struct {
+	Name    string   `yaml:"name"`    // 钩子名称，记录在审核原因中，为空时使用命令
+	Command string   `yaml:"command"` // 执行的命令
+	Args    []string `yaml:"args"`    // 命令参数
+	Timeout string   `yaml:"timeout"` // 执行超时时间，默认60s
+}
+
+// 重复视频处理方式
+const (
+	DeduplicationOff    = "off"    // 不检测重复视频
+	DeduplicationReject = "reject" // 拒绝上传并返回已存在的视频
+	DeduplicationAlias  = "alias"  // 创建共享同一存储对象的视频记录
+)
+
+// 上传文件验证方式
+const (
+	VerificationHeader    = "header"    // 只检查文件头的魔数
+	VerificationStructure = "structure" // 解析完整的容器结构
+	VerificationProbe     = "probe"     // 解析容器结构并使用ffprobe检查
+)
+
+// 处理任务执行方式
+const (
+	WorkerModeLocal  = "local"  // API服务在本进程执行处理任务
+	WorkerModeRemote = "remote" // 处理任务由独立的worker进程执行
+)
+
+// configReloadDelay 配置文件最后一次变化后等待的时间，编辑器保存时可能先清空再分多次写入
+const configReloadDelay = 100 * time.Millisecond
+
 // ConfigWatcher 配置文件监听器
 type ConfigWatcher struct {
-	configFile string
-	watcher    *fsnotify.Watcher
-	stopCh     chan struct{}
+	configFile   string
+	watcher      *fsnotify.Watcher
+	stopCh       chan struct{}
+	errorHandler func(error)
 }
 
 // LoadFromFile 从文件加载配置
@@ -75,9 +395,15 @@ func LoadFromFile(filePath string) (*Config, error) {
 	// 应用环境变量覆盖
 	config.applyEnvironmentOverrides()
 	
+	config.sourceFile = filePath
 	return config, nil
 }
 
+// SourceFile 获取加载配置的文件路径，不是从文件加载时为空
+func (c *Config) SourceFile() string {
+	return c.sourceFile
+}
+
 // LoadEnvironmentConfig 加载环境特定配置
 func LoadEnvironmentConfig(configDir, environment string) (*Config, error) {
 	configFile := filepath.Join(configDir, environment+".yml")
@@ -93,6 +419,9 @@ func (c *Config) applyDefaults() {
 	if c.Server.Port == 0 {
 		c.Server.Port = 8888
 	}
+	if c.Server.DefaultLanguage == "" {
+		c.Server.DefaultLanguage = i18n.DefaultLanguage
+	}
 	
 	// MinIO默认值
 	if c.MinIO.Region == "" {
@@ -102,6 +431,44 @@ func (c *Config) applyDefaults() {
 		c.MinIO.Bucket = "zhulong-videos"
 	}
 	
+	// 存储驱动默认值
+	if c.Storage.Driver == "" {
+		c.Storage.Driver = storage.DriverMinIO
+	}
+	if c.Storage.S3.Region == "" {
+		c.Storage.S3.Region = "us-east-1"
+	}
+	if c.Storage.Local.RootDir == "" {
+		c.Storage.Local.RootDir = "./data/storage"
+	}
+	if c.Storage.Timeouts.Metadata == "" {
+		c.Storage.Timeouts.Metadata = "30s"
+	}
+	if c.Storage.Timeouts.Batch == "" {
+		c.Storage.Timeouts.Batch = "5m"
+	}
+	if c.Storage.Timeouts.Upload == "" {
+		c.Storage.Timeouts.Upload = "1h"
+	}
+	if c.Storage.Timeouts.Download == "" {
+		c.Storage.Timeouts.Download = "1m"
+	}
+	if c.Storage.Retry.MaxAttempts == 0 {
+		c.Storage.Retry.MaxAttempts = 3
+	}
+	if c.Storage.Retry.BaseDelay == "" {
+		c.Storage.Retry.BaseDelay = "100ms"
+	}
+	if c.Storage.Retry.MaxDelay == "" {
+		c.Storage.Retry.MaxDelay = "2s"
+	}
+	if c.Storage.CircuitBreaker.FailureThreshold == 0 {
+		c.Storage.CircuitBreaker.FailureThreshold = 5
+	}
+	if c.Storage.CircuitBreaker.OpenDuration == "" {
+		c.Storage.CircuitBreaker.OpenDuration = "30s"
+	}
+	
 	// 应用默认值
 	if c.App.Name == "" {
 		c.App.Name = "Zhulong Video Server"
@@ -109,6 +476,138 @@ func (c *Config) applyDefaults() {
 	if c.App.Version == "" {
 		c.App.Version = "v1.0.0"
 	}
+	
+	// JWT默认值
+	if c.JWT.Expire == "" {
+		c.JWT.Expire = "24h"
+	}
+	
+	// 流媒体默认值
+	if c.Streaming.FFmpegPath == "" {
+		c.Streaming.FFmpegPath = "ffmpeg"
+	}
+	if c.Streaming.SegmentDuration <= 0 {
+		c.Streaming.SegmentDuration = 6
+	}
+	if c.Streaming.Workers <= 0 {
+		c.Streaming.Workers = 1
+	}
+	if c.Streaming.MaxAttempts <= 0 {
+		c.Streaming.MaxAttempts = 3
+	}
+	
+	// 处理任务默认值
+	if c.Worker.Mode == "" {
+		c.Worker.Mode = WorkerModeLocal
+	}
+	if c.Worker.LeaseTimeout == "" {
+		c.Worker.LeaseTimeout = "2m"
+	}
+	
+	// 缓存默认值
+	if c.Cache.Driver == "" {
+		c.Cache.Driver = cache.DriverNone
+	}
+	if c.Cache.MaxEntries <= 0 {
+		c.Cache.MaxEntries = 10000
+	}
+	if c.Cache.Redis.Addr == "" {
+		c.Cache.Redis.Addr = "localhost:6379"
+	}
+	
+	// 跨域默认值
+	if c.CORS.Mode == "" {
+		c.CORS.Mode = middleware.CORSModeOff
+	}
+	if c.CORS.MaxAge == "" {
+		c.CORS.MaxAge = "12h"
+	}
+	
+	// 配额默认值
+	if c.Quota.UserLimit == "" {
+		c.Quota.UserLimit = "10GB"
+	}
+	
+	// 分片上传默认值
+	if c.Upload.Verification == "" {
+		c.Upload.Verification = VerificationStructure
+	}
+	if c.Upload.FFprobePath == "" {
+		c.Upload.FFprobePath = "ffprobe"
+	}
+	if c.Upload.MultipartSessionTTL == "" {
+		c.Upload.MultipartSessionTTL = "24h"
+	}
+	if c.Upload.MultipartCleanupInterval == "" {
+		c.Upload.MultipartCleanupInterval = "1h"
+	}
+	
+	// 归档默认值
+	if c.Archive.Bucket == "" {
+		c.Archive.Bucket = "zhulong-archive"
+	}
+	if c.Archive.IdleAfter == "" {
+		c.Archive.IdleAfter = "30d"
+	}
+	if c.Archive.CheckInterval == "" {
+		c.Archive.CheckInterval = "1h"
+	}
+	
+	// 备份默认值
+	if c.Backup.Bucket == "" {
+		c.Backup.Bucket = "zhulong-backups"
+	}
+	if c.Backup.Interval == "" {
+		c.Backup.Interval = "24h"
+	}
+	if c.Backup.Retention == 0 {
+		c.Backup.Retention = 7
+	}
+	
+	// 定时发布和到期默认值
+	if c.Schedule.CheckInterval == "" {
+		c.Schedule.CheckInterval = "1m"
+	}
+	
+	// 存储复制默认值
+	if c.Replication.Driver == "" {
+		c.Replication.Driver = storage.DriverMinIO
+	}
+	if c.Replication.Workers == 0 {
+		c.Replication.Workers = 2
+	}
+	if c.Replication.QueueSize == 0 {
+		c.Replication.QueueSize = 1000
+	}
+	if c.Replication.SyncInterval == "" {
+		c.Replication.SyncInterval = "6h"
+	}
+	
+	// 临时目录默认值
+	if c.Temp.MaxSize == "" {
+		c.Temp.MaxSize = "10GB"
+	}
+	if c.Temp.MaxAge == "" {
+		c.Temp.MaxAge = "24h"
+	}
+	if c.Temp.CleanupInterval == "" {
+		c.Temp.CleanupInterval = "10m"
+	}
+	
+	// 限流默认值
+	c.RateLimit.Global.applyDefaults(10, 20)
+	c.RateLimit.Upload.applyDefaults(1, 3)
+	c.RateLimit.Playback.applyDefaults(50, 100)
+}
+
+// applyDefaults 为未配置的限流规则应用默认值
+func (r *RateLimitRule) applyDefaults(requestsPerSecond float64, burst int) {
+	if r.RequestsPerSecond <= 0 {
+		r.RequestsPerSecond = requestsPerSecond
+	}
+	if r.Burst <= 0 {
+		r.Burst = burst
+	}
 }
 
 // applyEnvironmentOverrides 应用环境变量覆盖
@@ -122,6 +621,29 @@ func (c *Config) applyEnvironmentOverrides() {
 	if host := os.Getenv("ZHULONG_SERVER_HOST"); host != "" {
 		c.Server.Host = host
 	}
+	if proxies := os.Getenv("ZHULONG_SERVER_TRUSTED_PROXIES"); proxies != "" {
+		c.Server.TrustedProxies = splitList(proxies)
+	}
+	if enabled := os.Getenv("ZHULONG_TLS_ENABLED"); enabled != "" {
+		if e, err := strconv.ParseBool(enabled); err == nil {
+			c.Server.TLS.Enabled = e
+		}
+	}
+	if certFile := os.Getenv("ZHULONG_TLS_CERT_FILE"); certFile != "" {
+		c.Server.TLS.CertFile = certFile
+	}
+	if keyFile := os.Getenv("ZHULONG_TLS_KEY_FILE"); keyFile != "" {
+		c.Server.TLS.KeyFile = keyFile
+	}
+	if certDir := os.Getenv("ZHULONG_TLS_CERT_DIR"); certDir != "" {
+		c.Server.TLS.CertDir = certDir
+	}
+	if hosts := os.Getenv("ZHULONG_TLS_HOSTS"); hosts != "" {
+		c.Server.TLS.Hosts = splitList(hosts)
+	}
+	if language := os.Getenv("ZHULONG_DEFAULT_LANGUAGE"); language != "" {
+		c.Server.DefaultLanguage = language
+	}
 	
 	// MinIO配置环境变量覆盖
 	if endpoint := os.Getenv("ZHULONG_MINIO_ENDPOINT"); endpoint != "" {
@@ -145,12 +667,281 @@ func (c *Config) applyEnvironmentOverrides() {
 		}
 	}
 	
+	// 存储驱动环境变量覆盖
+	if driver := os.Getenv("ZHULONG_STORAGE_DRIVER"); driver != "" {
+		c.Storage.Driver = driver
+	}
+	if accessKey := os.Getenv("ZHULONG_S3_ACCESS_KEY"); accessKey != "" {
+		c.Storage.S3.AccessKey = accessKey
+	}
+	if secretKey := os.Getenv("ZHULONG_S3_SECRET_KEY"); secretKey != "" {
+		c.Storage.S3.SecretKey = secretKey
+	}
+	if region := os.Getenv("ZHULONG_S3_REGION"); region != "" {
+		c.Storage.S3.Region = region
+	}
+	if rootDir := os.Getenv("ZHULONG_STORAGE_LOCAL_ROOT"); rootDir != "" {
+		c.Storage.Local.RootDir = rootDir
+	}
+	if baseURL := os.Getenv("ZHULONG_STORAGE_LOCAL_BASE_URL"); baseURL != "" {
+		c.Storage.Local.BaseURL = baseURL
+	}
+	if concurrency := os.Getenv("ZHULONG_STORAGE_DELETE_CONCURRENCY"); concurrency != "" {
+		if n, err := strconv.Atoi(concurrency); err == nil {
+			c.Storage.DeleteConcurrency = n
+		}
+	}
+	if maxObjects := os.Getenv("ZHULONG_STORAGE_PREFIX_DELETE_MAX_OBJECTS"); maxObjects != "" {
+		if n, err := strconv.Atoi(maxObjects); err == nil {
+			c.Storage.PrefixDeleteMaxObjects = n
+		}
+	}
+	if timeout := os.Getenv("ZHULONG_STORAGE_TIMEOUT_METADATA"); timeout != "" {
+		c.Storage.Timeouts.Metadata = timeout
+	}
+	if timeout := os.Getenv("ZHULONG_STORAGE_TIMEOUT_BATCH"); timeout != "" {
+		c.Storage.Timeouts.Batch = timeout
+	}
+	if timeout := os.Getenv("ZHULONG_STORAGE_TIMEOUT_UPLOAD"); timeout != "" {
+		c.Storage.Timeouts.Upload = timeout
+	}
+	if timeout := os.Getenv("ZHULONG_STORAGE_TIMEOUT_DOWNLOAD"); timeout != "" {
+		c.Storage.Timeouts.Download = timeout
+	}
+	if attempts := os.Getenv("ZHULONG_STORAGE_RETRY_MAX_ATTEMPTS"); attempts != "" {
+		if n, err := strconv.Atoi(attempts); err == nil {
+			c.Storage.Retry.MaxAttempts = n
+		}
+	}
+	if duration := os.Getenv("ZHULONG_STORAGE_CIRCUIT_OPEN_DURATION"); duration != "" {
+		c.Storage.CircuitBreaker.OpenDuration = duration
+	}
+	if bucket := os.Getenv("ZHULONG_STORAGE_BUCKET_VIDEOS"); bucket != "" {
+		c.Storage.Buckets.Videos = bucket
+	}
+	if bucket := os.Getenv("ZHULONG_STORAGE_BUCKET_THUMBNAILS"); bucket != "" {
+		c.Storage.Buckets.Thumbnails = bucket
+	}
+	if bucket := os.Getenv("ZHULONG_STORAGE_BUCKET_RENDITIONS"); bucket != "" {
+		c.Storage.Buckets.Renditions = bucket
+	}
+	if bucket := os.Getenv("ZHULONG_STORAGE_BUCKET_SUBTITLES"); bucket != "" {
+		c.Storage.Buckets.Subtitles = bucket
+	}
+	if endpoint := os.Getenv("ZHULONG_STORAGE_PUBLIC_ENDPOINT"); endpoint != "" {
+		c.Storage.PublicEndpoint = endpoint
+	}
+	if host := os.Getenv("ZHULONG_STORAGE_PUBLIC_HOST"); host != "" {
+		c.Storage.PublicHost = host
+	}
+	
+	// JWT配置环境变量覆盖
+	if secret := os.Getenv("ZHULONG_JWT_SECRET"); secret != "" {
+		c.JWT.Secret = secret
+	}
+	if expire := os.Getenv("ZHULONG_JWT_EXPIRE"); expire != "" {
+		c.JWT.Expire = expire
+	}
+
+	// 初始管理员环境变量覆盖
+	if username := os.Getenv("ZHULONG_ADMIN_USERNAME"); username != "" {
+		c.Admin.Username = username
+	}
+	if password := os.Getenv("ZHULONG_ADMIN_PASSWORD"); password != "" {
+		c.Admin.Password = password
+	}
+	
+	// 流媒体配置环境变量覆盖
+	if enabled := os.Getenv("ZHULONG_STREAMING_ENABLED"); enabled != "" {
+		if e, err := strconv.ParseBool(enabled); err == nil {
+			c.Streaming.Enabled = e
+		}
+	}
+	if ffmpegPath := os.Getenv("ZHULONG_FFMPEG_PATH"); ffmpegPath != "" {
+		c.Streaming.FFmpegPath = ffmpegPath
+	}
+	
+	// 临时目录配置环境变量覆盖
+	if dir := os.Getenv("ZHULONG_TEMP_DIR"); dir != "" {
+		c.Temp.Dir = dir
+	}
+	if maxSize := os.Getenv("ZHULONG_TEMP_MAX_SIZE"); maxSize != "" {
+		c.Temp.MaxSize = maxSize
+	}
+	
+	// 限流配置环境变量覆盖
+	if enabled := os.Getenv("ZHULONG_RATE_LIMIT_ENABLED"); enabled != "" {
+		if e, err := strconv.ParseBool(enabled); err == nil {
+			c.RateLimit.Enabled = e
+		}
+	}
+	
+	// 配额配置环境变量覆盖
+	if userLimit := os.Getenv("ZHULONG_QUOTA_USER_LIMIT"); userLimit != "" {
+		c.Quota.UserLimit = userLimit
+	}
+	
+	// 上传配置环境变量覆盖
+	if maxSize := os.Getenv("ZHULONG_UPLOAD_MAX_SIZE"); maxSize != "" {
+		c.Upload.MaxSize = maxSize
+	}
+	if types := os.Getenv("ZHULONG_UPLOAD_ALLOWED_TYPES"); types != "" {
+		c.Upload.AllowedTypes = types
+	}
+	if formats := os.Getenv("ZHULONG_UPLOAD_ALLOWED_FORMATS"); formats != "" {
+		c.Upload.AllowedFormats = formats
+	}
+	if mediaTypes := os.Getenv("ZHULONG_UPLOAD_MEDIA_TYPES"); mediaTypes != "" {
+		c.Upload.MediaTypes = mediaTypes
+	}
+	if sizes := os.Getenv("ZHULONG_UPLOAD_FORMAT_MAX_SIZES"); sizes != "" {
+		c.Upload.FormatMaxSizes = splitPairs(sizes)
+	}
+	if sizes := os.Getenv("ZHULONG_UPLOAD_ROLE_MAX_SIZES"); sizes != "" {
+		c.Upload.RoleMaxSizes = splitPairs(sizes)
+	}
+	if deduplication := os.Getenv("ZHULONG_UPLOAD_DEDUPLICATION"); deduplication != "" {
+		c.Upload.Deduplication = deduplication
+	}
+	if verification := os.Getenv("ZHULONG_UPLOAD_VERIFICATION"); verification != "" {
+		c.Upload.Verification = verification
+	}
+	if ffprobePath := os.Getenv("ZHULONG_FFPROBE_PATH"); ffprobePath != "" {
+		c.Upload.FFprobePath = ffprobePath
+	}
+	if visibility := os.Getenv("ZHULONG_UPLOAD_DEFAULT_VISIBILITY"); visibility != "" {
+		c.Upload.DefaultVisibility = visibility
+	}
+	if ttl := os.Getenv("ZHULONG_UPLOAD_MULTIPART_SESSION_TTL"); ttl != "" {
+		c.Upload.MultipartSessionTTL = ttl
+	}
+	if interval := os.Getenv("ZHULONG_UPLOAD_MULTIPART_CLEANUP_INTERVAL"); interval != "" {
+		c.Upload.MultipartCleanupInterval = interval
+	}
+	if limit := os.Getenv("ZHULONG_UPLOAD_BANDWIDTH_LIMIT"); limit != "" {
+		c.Upload.BandwidthLimit = limit
+	}
+	if limit := os.Getenv("ZHULONG_UPLOAD_CONNECTION_BANDWIDTH_LIMIT"); limit != "" {
+		c.Upload.ConnectionBandwidthLimit = limit
+	}
+	if versions := os.Getenv("ZHULONG_UPLOAD_MAX_VERSIONS"); versions != "" {
+		if n, err := strconv.Atoi(versions); err == nil {
+			c.Upload.MaxVersions = n
+		}
+	}
+	
+	// 归档配置环境变量覆盖
+	if enabled := os.Getenv("ZHULONG_ARCHIVE_ENABLED"); enabled != "" {
+		if e, err := strconv.ParseBool(enabled); err == nil {
+			c.Archive.Enabled = e
+		}
+	}
+	if bucket := os.Getenv("ZHULONG_ARCHIVE_BUCKET"); bucket != "" {
+		c.Archive.Bucket = bucket
+	}
+	if idleAfter := os.Getenv("ZHULONG_ARCHIVE_IDLE_AFTER"); idleAfter != "" {
+		c.Archive.IdleAfter = idleAfter
+	}
+	
+	// 备份配置环境变量覆盖
+	if enabled := os.Getenv("ZHULONG_BACKUP_ENABLED"); enabled != "" {
+		if e, err := strconv.ParseBool(enabled); err == nil {
+			c.Backup.Enabled = e
+		}
+	}
+	if bucket := os.Getenv("ZHULONG_BACKUP_BUCKET"); bucket != "" {
+		c.Backup.Bucket = bucket
+	}
+	if interval := os.Getenv("ZHULONG_BACKUP_INTERVAL"); interval != "" {
+		c.Backup.Interval = interval
+	}
+	if retention := os.Getenv("ZHULONG_BACKUP_RETENTION"); retention != "" {
+		if n, err := strconv.Atoi(retention); err == nil {
+			c.Backup.Retention = n
+		}
+	}
+	
+	// 定时发布和到期配置环境变量覆盖
+	if interval := os.Getenv("ZHULONG_SCHEDULE_CHECK_INTERVAL"); interval != "" {
+		c.Schedule.CheckInterval = interval
+	}
+	
+	// 存储复制配置环境变量覆盖
+	if enabled := os.Getenv("ZHULONG_REPLICATION_ENABLED"); enabled != "" {
+		if e, err := strconv.ParseBool(enabled); err == nil {
+			c.Replication.Enabled = e
+		}
+	}
+	if driver := os.Getenv("ZHULONG_REPLICATION_DRIVER"); driver != "" {
+		c.Replication.Driver = driver
+	}
+	if endpoint := os.Getenv("ZHULONG_REPLICATION_ENDPOINT"); endpoint != "" {
+		c.Replication.Endpoint = endpoint
+	}
+	if accessKey := os.Getenv("ZHULONG_REPLICATION_ACCESS_KEY"); accessKey != "" {
+		c.Replication.AccessKey = accessKey
+	}
+	if secretKey := os.Getenv("ZHULONG_REPLICATION_SECRET_KEY"); secretKey != "" {
+		c.Replication.SecretKey = secretKey
+	}
+	if rootDir := os.Getenv("ZHULONG_REPLICATION_ROOT_DIR"); rootDir != "" {
+		c.Replication.RootDir = rootDir
+	}
+	
+	// 播放地址配置环境变量覆盖
+	if signedURLs := os.Getenv("ZHULONG_PLAYBACK_SIGNED_URLS"); signedURLs != "" {
+		if e, err := strconv.ParseBool(signedURLs); err == nil {
+			c.Playback.SignedURLs = e
+		}
+	}
+	if secret := os.Getenv("ZHULONG_PLAYBACK_SECRET"); secret != "" {
+		c.Playback.Secret = secret
+	}
+	if baseURL := os.Getenv("ZHULONG_PLAYBACK_BASE_URL"); baseURL != "" {
+		c.Playback.BaseURL = baseURL
+	}
+	
+	// 处理任务配置环境变量覆盖
+	if mode := os.Getenv("ZHULONG_WORKER_MODE"); mode != "" {
+		c.Worker.Mode = mode
+	}
+	if secret := os.Getenv("ZHULONG_WORKER_SECRET"); secret != "" {
+		c.Worker.Secret = secret
+	}
+	
+	// 缓存配置环境变量覆盖
+	if driver := os.Getenv("ZHULONG_CACHE_DRIVER"); driver != "" {
+		c.Cache.Driver = driver
+	}
+	if addr := os.Getenv("ZHULONG_CACHE_REDIS_ADDR"); addr != "" {
+		c.Cache.Redis.Addr = addr
+	}
+	if password := os.Getenv("ZHULONG_CACHE_REDIS_PASSWORD"); password != "" {
+		c.Cache.Redis.Password = password
+	}
+	
+	// 跨域配置环境变量覆盖，允许的来源以逗号分隔
+	if mode := os.Getenv("ZHULONG_CORS_MODE"); mode != "" {
+		c.CORS.Mode = mode
+	}
+	if origins := os.Getenv("ZHULONG_CORS_ALLOWED_ORIGINS"); origins != "" {
+		c.CORS.AllowedOrigins = splitList(origins)
+	}
+	if public := os.Getenv("ZHULONG_CORS_PUBLIC_STREAMING"); public != "" {
+		if p, err := strconv.ParseBool(public); err == nil {
+			c.CORS.PublicStreaming = p
+		}
+	}
+	
 	// 应用配置环境变量覆盖
 	if debug := os.Getenv("ZHULONG_APP_DEBUG"); debug != "" {
 		if d, err := strconv.ParseBool(debug); err == nil {
 			c.App.Debug = d
 		}
 	}
+	if level := os.Getenv("ZHULONG_APP_LOG_LEVEL"); level != "" {
+		c.App.LogLevel = level
+	}
 }
 
 // Validate 验证配置
@@ -164,19 +955,239 @@ func (c *Config) Validate() error {
 	if c.Server.Host == "" {
 		errors = append(errors, "服务器主机不能为空")
 	}
+	if c.Server.DefaultLanguage != "" {
+		if _, ok := i18n.Normalize(c.Server.DefaultLanguage); !ok {
+			errors = append(errors, "默认语言必须为zh-CN或en")
+		}
+	}
+	if _, err := c.GetLogLevel(); err != nil {
+		errors = append(errors, err.Error())
+	}
+	if _, err := c.GetProxyPolicy(); err != nil {
+		errors = append(errors, err.Error())
+	}
+	if (c.Server.TLS.CertFile == "") != (c.Server.TLS.KeyFile == "") {
+		errors = append(errors, "TLS证书文件和私钥文件必须同时配置")
+	}
+	
+	// 按存储驱动验证对应配置，其他驱动的配置由驱动自身验证
+	switch strings.ToLower(c.Storage.Driver) {
+	case "", storage.DriverMinIO:
+		if c.MinIO.Endpoint == "" {
+			errors = append(errors, "MinIO端点不能为空")
+		}
+		if c.MinIO.AccessKey == "" {
+			errors = append(errors, "MinIO访问密钥不能为空")
+		}
+		if c.MinIO.SecretKey == "" {
+			errors = append(errors, "MinIO秘密密钥不能为空")
+		}
+		if c.MinIO.Bucket == "" {
+			errors = append(errors, "MinIO存储桶不能为空")
+		}
+	case storage.DriverS3:
+		if c.Storage.S3.AccessKey == "" {
+			errors = append(errors, "S3访问密钥不能为空")
+		}
+		if c.Storage.S3.SecretKey == "" {
+			errors = append(errors, "S3秘密密钥不能为空")
+		}
+	case storage.DriverLocal:
+		if c.Storage.Local.RootDir == "" {
+			errors = append(errors, "本地存储根目录不能为空")
+		}
+	}
 	
-	// 验证MinIO配置
-	if c.MinIO.Endpoint == "" {
-		errors = append(errors, "MinIO端点不能为空")
+	if c.Storage.DeleteConcurrency < 0 {
+		errors = append(errors, "批量删除并发数不能为负数")
 	}
-	if c.MinIO.AccessKey == "" {
-		errors = append(errors, "MinIO访问密钥不能为空")
+	if c.Storage.PrefixDeleteMaxObjects < 0 {
+		errors = append(errors, "按前缀删除的文件数上限不能为负数")
 	}
-	if c.MinIO.SecretKey == "" {
-		errors = append(errors, "MinIO秘密密钥不能为空")
+	if _, err := c.GetStoragePublicEndpoint(); err != nil {
+		errors = append(errors, fmt.Sprintf("存储对外访问地址无效: %v", err))
 	}
-	if c.MinIO.Bucket == "" {
-		errors = append(errors, "MinIO存储桶不能为空")
+	if _, err := c.GetStorageTimeouts(); err != nil {
+		errors = append(errors, fmt.Sprintf("存储操作超时时间无效: %v", err))
+	}
+	if _, err := c.GetStorageResilience(); err != nil {
+		errors = append(errors, fmt.Sprintf("存储重试或熔断配置无效: %v", err))
+	}
+	
+	// 验证配额配置
+	if c.Quota.UserLimit != "" {
+		if _, err := ParseSize(c.Quota.UserLimit); err != nil {
+			errors = append(errors, "用户存储配额格式无效")
+		}
+	}
+	
+	// 验证上传配置
+	if c.Upload.MaxSize != "" {
+		if size, err := ParseSize(c.Upload.MaxSize); err != nil || size == 0 {
+			errors = append(errors, "上传文件大小限制格式无效")
+		}
+	}
+	if _, err := c.GetUploadFormatMaxSizes(); err != nil {
+		errors = append(errors, err.Error())
+	}
+	if _, err := c.GetUploadRoleMaxSizes(); err != nil {
+		errors = append(errors, err.Error())
+	}
+	switch c.GetDeduplicationMode() {
+	case DeduplicationOff, DeduplicationReject, DeduplicationAlias:
+	default:
+		errors = append(errors, "重复视频处理方式必须为off、reject或alias")
+	}
+	if _, err := c.GetUploadMediaTypes(); err != nil {
+		errors = append(errors, err.Error())
+	}
+	switch c.GetUploadVerification() {
+	case VerificationHeader, VerificationStructure, VerificationProbe:
+	default:
+		errors = append(errors, "上传文件验证方式必须为header、structure或probe")
+	}
+	if _, err := metadata.NormalizeVisibility(c.Upload.DefaultVisibility); err != nil {
+		errors = append(errors, "默认可见性必须为private、unlisted或public")
+	}
+	if c.Upload.MaxVersions < 0 {
+		errors = append(errors, "历史版本保留数量不能为负数")
+	}
+	if c.Upload.MultipartSessionTTL != "" {
+		if ttl, err := c.GetMultipartSessionTTL(); err != nil || ttl <= 0 {
+			errors = append(errors, "分片上传会话有效期格式无效")
+		}
+	}
+	if c.Upload.MultipartCleanupInterval != "" {
+		if interval, err := c.GetMultipartCleanupInterval(); err != nil || interval <= 0 {
+			errors = append(errors, "分片上传清理间隔格式无效")
+		}
+	}
+	if _, err := c.GetUploadBandwidthLimit(); err != nil {
+		errors = append(errors, "上传总带宽限制格式无效")
+	}
+	if _, err := c.GetUploadConnectionBandwidthLimit(); err != nil {
+		errors = append(errors, "单个上传带宽限制格式无效")
+	}
+	
+	// 验证归档配置
+	if c.Archive.Enabled {
+		if c.Archive.Bucket == "" || slices.Contains(c.GetBucketResolver().Buckets(), c.Archive.Bucket) {
+			errors = append(errors, "归档存储桶不能为空且不能与其他内容的存储桶相同")
+		}
+		if idleAfter, err := c.GetArchiveIdleAfter(); err != nil || idleAfter <= 0 {
+			errors = append(errors, "归档闲置时长格式无效")
+		}
+		if interval, err := c.GetArchiveCheckInterval(); err != nil || interval <= 0 {
+			errors = append(errors, "归档检查间隔格式无效")
+		}
+	}
+	
+	// 验证备份配置
+	if c.Backup.Enabled {
+		if c.Backup.Bucket == "" || slices.Contains(c.GetBucketResolver().Buckets(), c.Backup.Bucket) ||
+			(c.Archive.Enabled && c.Backup.Bucket == c.Archive.Bucket) {
+			errors = append(errors, "备份存储桶不能为空且不能与其他内容的存储桶相同")
+		}
+		if c.Backup.Retention < 1 {
+			errors = append(errors, "备份保留数量必须大于0")
+		}
+		if interval, err := c.GetBackupInterval(); err != nil || interval <= 0 {
+			errors = append(errors, "备份间隔格式无效")
+		}
+	}
+	
+	// 验证定时发布和到期配置
+	if c.Schedule.CheckInterval != "" {
+		if interval, err := c.GetScheduleCheckInterval(); err != nil || interval <= 0 {
+			errors = append(errors, "到期视频检查间隔格式无效")
+		}
+	}
+	
+	// 验证存储复制配置
+	if c.Replication.Enabled {
+		switch strings.ToLower(c.Replication.Driver) {
+		case storage.DriverMinIO:
+			if c.Replication.Endpoint == "" {
+				errors = append(errors, "minio副本存储的端点不能为空")
+			}
+		case storage.DriverS3:
+		case storage.DriverLocal:
+			if c.Replication.RootDir == "" {
+				errors = append(errors, "local副本存储的根目录不能为空")
+			} else if strings.EqualFold(c.Storage.Driver, storage.DriverLocal) &&
+				filepath.Clean(c.Replication.RootDir) == filepath.Clean(c.Storage.Local.RootDir) {
+				errors = append(errors, "副本存储的根目录不能与存储根目录相同")
+			}
+		default:
+			errors = append(errors, "副本存储驱动必须是minio、s3或local")
+		}
+		if c.Replication.Workers < 1 {
+			errors = append(errors, "复制并发数必须大于0")
+		}
+		if c.Replication.QueueSize < 1 {
+			errors = append(errors, "复制队列大小必须大于0")
+		}
+		if interval, err := c.GetReplicationSyncInterval(); err != nil || interval < 0 {
+			errors = append(errors, "复制同步间隔格式无效")
+		}
+	}
+	
+	// 验证处理任务配置
+	switch c.Worker.Mode {
+	case "", WorkerModeLocal:
+	case WorkerModeRemote:
+		if c.Worker.Secret == "" {
+			errors = append(errors, "remote模式下worker密钥不能为空")
+		}
+	default:
+		errors = append(errors, "处理任务执行方式必须为local或remote")
+	}
+	if c.Worker.LeaseTimeout != "" {
+		if leaseTimeout, err := c.GetWorkerLeaseTimeout(); err != nil || leaseTimeout <= 0 {
+			errors = append(errors, "worker租约时长格式无效")
+		}
+	}
+	
+	// 验证缓存配置
+	switch c.Cache.Driver {
+	case "", cache.DriverNone, cache.DriverMemory:
+	case cache.DriverRedis:
+		if c.Cache.Redis.Addr == "" {
+			errors = append(errors, "Redis缓存地址不能为空")
+		}
+		if c.Cache.Redis.DB < 0 {
+			errors = append(errors, "Redis数据库编号不能小于0")
+		}
+	default:
+		errors = append(errors, "缓存驱动必须为none、memory或redis")
+	}
+	
+	// 验证跨域配置
+	if _, err := c.GetCORSPolicy(); err != nil {
+		errors = append(errors, err.Error())
+	}
+	if c.CORS.Mode == middleware.CORSModeStrict && len(c.CORS.AllowedOrigins) == 0 {
+		errors = append(errors, "strict跨域模式下允许的来源不能为空")
+	}
+	
+	// 验证Webhook配置
+	for _, endpoint := range c.GetWebhookEndpoints() {
+		if err := webhook.ValidateEndpoint(endpoint); err != nil {
+			errors = append(errors, err.Error())
+		}
+	}
+
+	// 验证内容审核配置
+	if _, err := c.GetModerationHooks(); err != nil {
+		errors = append(errors, err.Error())
+	}
+	if _, err := moderation.ValidateOnError(c.Moderation.OnError); err != nil {
+		errors = append(errors, err.Error())
+	}
+
+	// 验证临时目录配置
+	if _, err := c.GetTempConfig(); err != nil {
+		errors = append(errors, err.Error())
 	}
 	
 	if len(errors) > 0 {
@@ -186,6 +1197,503 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// GetJWTExpiry 解析JWT有效期
+func (c *Config) GetJWTExpiry() (time.Duration, error) {
+	return ParseDuration(c.JWT.Expire)
+}
+
+// ParseDuration 解析时长，在Go时长格式基础上支持以"d"结尾的天数
+func ParseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("无效的时长: %s", value)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("无效的时长: %s", value)
+	}
+	return duration, nil
+}
+
+// GetArchiveIdleAfter 解析视频被归档前的闲置时长
+func (c *Config) GetArchiveIdleAfter() (time.Duration, error) {
+	return ParseDuration(c.Archive.IdleAfter)
+}
+
+// GetArchiveCheckInterval 解析归档检查间隔
+func (c *Config) GetArchiveCheckInterval() (time.Duration, error) {
+	return ParseDuration(c.Archive.CheckInterval)
+}
+
+// GetBackupInterval 解析定期备份的间隔
+func (c *Config) GetBackupInterval() (time.Duration, error) {
+	return ParseDuration(c.Backup.Interval)
+}
+
+// GetScheduleCheckInterval 解析处理到期视频的检查间隔
+func (c *Config) GetScheduleCheckInterval() (time.Duration, error) {
+	return ParseDuration(c.Schedule.CheckInterval)
+}
+
+// GetReplicationSyncInterval 解析存储复制定期同步的间隔，0表示不定期同步
+func (c *Config) GetReplicationSyncInterval() (time.Duration, error) {
+	return ParseDuration(c.Replication.SyncInterval)
+}
+
+// GetReplicationDriverConfig 获取副本存储的驱动配置
+// 副本存储只用于写入和对比，local驱动不签发预签名URL；与主存储使用相同的超时、重试和熔断配置
+func (c *Config) GetReplicationDriverConfig() *storage.DriverConfig {
+	timeouts, _ := c.GetStorageTimeouts()
+	resilience, _ := c.GetStorageResilience()
+	endpoint := &storage.MinIOConfig{
+		Endpoint:  c.Replication.Endpoint,
+		AccessKey: c.Replication.AccessKey,
+		SecretKey: c.Replication.SecretKey,
+		UseSSL:    c.Replication.UseSSL,
+		Region:    c.Replication.Region,
+	}
+	return &storage.DriverConfig{
+		Driver:     c.Replication.Driver,
+		MinIO:      endpoint,
+		S3:         endpoint,
+		Local:      &storage.LocalConfig{RootDir: c.Replication.RootDir},
+		Timeouts:   timeouts,
+		Resilience: resilience,
+	}
+}
+
+// GetStorageTimeouts 解析各类存储操作的超时时间，为空或"0"时不限制
+func (c *Config) GetStorageTimeouts() (storage.Timeouts, error) {
+	var timeouts storage.Timeouts
+	for _, item := range []struct {
+		name   string
+		value  string
+		target *time.Duration
+	}{
+		{"metadata", c.Storage.Timeouts.Metadata, &timeouts.Metadata},
+		{"batch", c.Storage.Timeouts.Batch, &timeouts.Batch},
+		{"upload", c.Storage.Timeouts.Upload, &timeouts.Upload},
+		{"download", c.Storage.Timeouts.Download, &timeouts.Download},
+	} {
+		if item.value == "" {
+			continue
+		}
+		timeout, err := ParseDuration(item.value)
+		if err != nil || timeout < 0 {
+			return storage.Timeouts{}, fmt.Errorf("%s: %s", item.name, item.value)
+		}
+		*item.target = timeout
+	}
+	return timeouts, nil
+}
+
+// GetStorageResilience 获取存储调用的重试和熔断选项，open_duration为"0"时不熔断
+func (c *Config) GetStorageResilience() (storage.ResilienceOptions, error) {
+	retry := c.Storage.Retry
+	breaker := c.Storage.CircuitBreaker
+	if retry.MaxAttempts < 0 {
+		return storage.ResilienceOptions{}, fmt.Errorf("最多尝试次数不能为负数")
+	}
+	if breaker.FailureThreshold < 0 {
+		return storage.ResilienceOptions{}, fmt.Errorf("熔断的连续失败次数不能为负数")
+	}
+
+	opts := storage.ResilienceOptions{MaxAttempts: retry.MaxAttempts, FailureThreshold: breaker.FailureThreshold}
+	for _, item := range []struct {
+		name   string
+		value  string
+		target *time.Duration
+	}{
+		{"base_delay", retry.BaseDelay, &opts.BaseDelay},
+		{"max_delay", retry.MaxDelay, &opts.MaxDelay},
+		{"open_duration", breaker.OpenDuration, &opts.OpenDuration},
+	} {
+		if item.value == "" {
+			continue
+		}
+		duration, err := ParseDuration(item.value)
+		if err != nil || duration < 0 {
+			return storage.ResilienceOptions{}, fmt.Errorf("%s: %s", item.name, item.value)
+		}
+		*item.target = duration
+	}
+	if opts.OpenDuration == 0 {
+		opts.FailureThreshold = 0
+	}
+	return opts, nil
+}
+
+// GetMultipartSessionTTL 解析分片上传会话有效期
+func (c *Config) GetMultipartSessionTTL() (time.Duration, error) {
+	return ParseDuration(c.Upload.MultipartSessionTTL)
+}
+
+// GetMultipartCleanupInterval 解析过期分片上传的清理间隔
+func (c *Config) GetMultipartCleanupInterval() (time.Duration, error) {
+	return ParseDuration(c.Upload.MultipartCleanupInterval)
+}
+
+// GetWorkerLeaseTimeout 解析worker租约时长
+func (c *Config) GetWorkerLeaseTimeout() (time.Duration, error) {
+	return ParseDuration(c.Worker.LeaseTimeout)
+}
+
+// IsRemoteWorkerMode 处理任务是否由独立的worker进程执行
+func (c *Config) IsRemoteWorkerMode() bool {
+	return c.Worker.Mode == WorkerModeRemote
+}
+
+// GetCacheConfig 获取缓存配置，用于cache.NewFromConfig
+func (c *Config) GetCacheConfig() *cache.Config {
+	return &cache.Config{
+		Driver:        c.Cache.Driver,
+		MaxEntries:    c.Cache.MaxEntries,
+		RedisAddr:     c.Cache.Redis.Addr,
+		RedisPassword: c.Cache.Redis.Password,
+		RedisDB:       c.Cache.Redis.DB,
+	}
+}
+
+// GetTempConfig 获取临时目录配置，用于tempfile.NewManager
+// temp.dir为空时使用streaming.temp_dir，未配置的上限、有效期和清理间隔为0
+func (c *Config) GetTempConfig() (*tempfile.Config, error) {
+	cfg := &tempfile.Config{Dir: c.Temp.Dir}
+	if cfg.Dir == "" {
+		cfg.Dir = c.Streaming.TempDir
+	}
+	if c.Temp.MaxSize != "" {
+		maxBytes, err := ParseSize(c.Temp.MaxSize)
+		if err != nil {
+			return nil, fmt.Errorf("临时目录占用上限格式无效: %s", c.Temp.MaxSize)
+		}
+		cfg.MaxBytes = maxBytes
+	}
+	if c.Temp.MaxAge != "" {
+		maxAge, err := ParseDuration(c.Temp.MaxAge)
+		if err != nil || maxAge < 0 {
+			return nil, fmt.Errorf("临时文件有效期格式无效: %s", c.Temp.MaxAge)
+		}
+		cfg.MaxAge = maxAge
+	}
+	if c.Temp.CleanupInterval != "" {
+		interval, err := ParseDuration(c.Temp.CleanupInterval)
+		if err != nil || interval < 0 {
+			return nil, fmt.Errorf("临时文件清理间隔格式无效: %s", c.Temp.CleanupInterval)
+		}
+		cfg.CleanupInterval = interval
+	}
+	return cfg, nil
+}
+
+// signedStreamPathPrefix 播放令牌签名的视频流、海报和字幕的路径前缀，地址中的令牌即授权
+const signedStreamPathPrefix = "/stream/"
+
+// GetCORSPolicy 根据跨域配置创建跨域来源策略，未配置跨域处理方式时不处理跨域请求
+func (c *Config) GetCORSPolicy() (*middleware.CORSPolicy, error) {
+	mode := c.CORS.Mode
+	if mode == "" {
+		mode = middleware.CORSModeOff
+	}
+	var maxAge time.Duration
+	if c.CORS.MaxAge != "" {
+		var err error
+		if maxAge, err = ParseDuration(c.CORS.MaxAge); err != nil || maxAge < 0 {
+			return nil, fmt.Errorf("跨域预检缓存时长格式无效: %s", c.CORS.MaxAge)
+		}
+	}
+	policy, err := middleware.NewCORSPolicy(mode, c.CORS.AllowedOrigins, c.CORS.AllowCredentials, maxAge)
+	if err != nil {
+		return nil, err
+	}
+	if c.CORS.PublicStreaming {
+		policy.AllowAnyOriginFor(signedStreamPathPrefix)
+	}
+	return policy, nil
+}
+
+// logLevels 支持的日志级别
+var logLevels = map[string]hlog.Level{
+	"trace":  hlog.LevelTrace,
+	"debug":  hlog.LevelDebug,
+	"info":   hlog.LevelInfo,
+	"notice": hlog.LevelNotice,
+	"warn":   hlog.LevelWarn,
+	"error":  hlog.LevelError,
+	"fatal":  hlog.LevelFatal,
+}
+
+// GetLogLevel 获取日志级别，未配置时开启debug为debug，否则为info
+func (c *Config) GetLogLevel() (hlog.Level, error) {
+	if c.App.LogLevel == "" {
+		if c.App.Debug {
+			return hlog.LevelDebug, nil
+		}
+		return hlog.LevelInfo, nil
+	}
+	level, ok := logLevels[strings.ToLower(strings.TrimSpace(c.App.LogLevel))]
+	if !ok {
+		return hlog.LevelInfo, fmt.Errorf("日志级别无效: %s", c.App.LogLevel)
+	}
+	return level, nil
+}
+
+// GetServerAddress 获取服务的监听地址，如"0.0.0.0:8080"，IPv6地址带方括号
+func (c *Config) GetServerAddress() string {
+	return net.JoinHostPort(c.Server.Host, strconv.Itoa(c.Server.Port))
+}
+
+// GetServerScheme 获取服务的访问协议，启用TLS时为https
+func (c *Config) GetServerScheme() string {
+	if c.Server.TLS.Enabled {
+		return "https"
+	}
+	return "http"
+}
+
+// GetProxyPolicy 获取受信任的反向代理策略，未配置时不信任任何代理
+func (c *Config) GetProxyPolicy() (*middleware.ProxyPolicy, error) {
+	return middleware.NewProxyPolicy(c.Server.TrustedProxies)
+}
+
+// GetUserQuotaLimit 获取每个用户的存储配额（字节），0表示不限制
+func (c *Config) GetUserQuotaLimit() (int64, error) {
+	return ParseSize(c.Quota.UserLimit)
+}
+
+// GetAllowedFormats 获取允许上传的视频格式列表，未配置时返回nil
+func (c *Config) GetAllowedFormats() []string {
+	return splitList(c.Upload.AllowedFormats)
+}
+
+// GetUploadMediaTypes 获取允许上传的媒体类型，未配置时只允许视频
+func (c *Config) GetUploadMediaTypes() ([]string, error) {
+	items := splitList(c.Upload.MediaTypes)
+	if len(items) == 0 {
+		return []string{metadata.MediaTypeVideo}, nil
+	}
+	var mediaTypes []string
+	for _, item := range items {
+		mediaType, err := metadata.NormalizeMediaType(item)
+		if err != nil {
+			return nil, fmt.Errorf("允许上传的媒体类型无效: %w", err)
+		}
+		if !slices.Contains(mediaTypes, mediaType) {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+	return mediaTypes, nil
+}
+
+// GetAllowedTypes 获取允许上传的内容类型列表，未配置时返回nil
+func (c *Config) GetAllowedTypes() []string {
+	return splitList(c.Upload.AllowedTypes)
+}
+
+// GetUploadMaxSize 获取单个视频最大大小（字节），未配置时返回0
+func (c *Config) GetUploadMaxSize() (int64, error) {
+	if strings.TrimSpace(c.Upload.MaxSize) == "" {
+		return 0, nil
+	}
+	return ParseSize(c.Upload.MaxSize)
+}
+
+// GetUploadFormatMaxSizes 获取按格式的单个视频最大大小（字节），格式转换为小写，未配置时返回空
+func (c *Config) GetUploadFormatMaxSizes() (map[string]int64, error) {
+	limits, err := parseSizeLimits(c.Upload.FormatMaxSizes)
+	if err != nil {
+		return nil, fmt.Errorf("按格式的上传文件大小限制无效: %w", err)
+	}
+	return limits, nil
+}
+
+// GetUploadRoleMaxSizes 获取按用户角色的单个视频最大大小（字节），未配置时返回空
+func (c *Config) GetUploadRoleMaxSizes() (map[string]int64, error) {
+	limits, err := parseSizeLimits(c.Upload.RoleMaxSizes)
+	if err != nil {
+		return nil, fmt.Errorf("按角色的上传文件大小限制无效: %w", err)
+	}
+	for role := range limits {
+		if !user.IsValidRole(role) {
+			return nil, fmt.Errorf("按角色的上传文件大小限制无效: 角色不存在: %s", role)
+		}
+	}
+	return limits, nil
+}
+
+// parseSizeLimits 解析键到大小的映射，键转换为小写，大小必须大于0
+func parseSizeLimits(values map[string]string) (map[string]int64, error) {
+	limits := make(map[string]int64, len(values))
+	for key, value := range values {
+		size, err := ParseSize(value)
+		if err != nil || size == 0 {
+			return nil, fmt.Errorf("%s的大小格式无效: %s", key, value)
+		}
+		limits[strings.ToLower(strings.TrimSpace(key))] = size
+	}
+	return limits, nil
+}
+
+// GetUploadBandwidthLimit 获取所有上传共享的总带宽（每秒字节数），未配置时返回0表示不限制
+func (c *Config) GetUploadBandwidthLimit() (int64, error) {
+	if strings.TrimSpace(c.Upload.BandwidthLimit) == "" {
+		return 0, nil
+	}
+	return ParseSize(c.Upload.BandwidthLimit)
+}
+
+// GetUploadConnectionBandwidthLimit 获取单个上传请求的带宽（每秒字节数），未配置时返回0表示不限制
+func (c *Config) GetUploadConnectionBandwidthLimit() (int64, error) {
+	if strings.TrimSpace(c.Upload.ConnectionBandwidthLimit) == "" {
+		return 0, nil
+	}
+	return ParseSize(c.Upload.ConnectionBandwidthLimit)
+}
+
+// GetDeduplicationMode 获取重复视频处理方式，未配置时返回DeduplicationOff
+func (c *Config) GetDeduplicationMode() string {
+	mode := strings.ToLower(strings.TrimSpace(c.Upload.Deduplication))
+	if mode == "" {
+		return DeduplicationOff
+	}
+	return mode
+}
+
+// GetUploadVerification 获取上传文件的验证方式，未配置时返回VerificationHeader
+func (c *Config) GetUploadVerification() string {
+	mode := strings.ToLower(strings.TrimSpace(c.Upload.Verification))
+	if mode == "" {
+		return VerificationHeader
+	}
+	return mode
+}
+
+// GetDefaultVisibility 获取上传视频的默认可见性，未配置时为私有
+func (c *Config) GetDefaultVisibility() string {
+	visibility, err := metadata.NormalizeVisibility(c.Upload.DefaultVisibility)
+	if err != nil {
+		return metadata.VisibilityPrivate
+	}
+	return visibility
+}
+
+// GetMaxVersions 获取每个视频保留的历史版本数量，未配置时为5
+func (c *Config) GetMaxVersions() int {
+	if c.Upload.MaxVersions <= 0 {
+		return 5
+	}
+	return c.Upload.MaxVersions
+}
+
+// GetBucketResolver 获取按内容类别选择存储桶的选择器，未单独配置的类别使用minio.bucket
+func (c *Config) GetBucketResolver() *storage.BucketResolver {
+	return storage.NewBucketResolver(c.MinIO.Bucket, map[storage.ContentClass]string{
+		storage.ContentVideos:     strings.TrimSpace(c.Storage.Buckets.Videos),
+		storage.ContentThumbnails: strings.TrimSpace(c.Storage.Buckets.Thumbnails),
+		storage.ContentRenditions: strings.TrimSpace(c.Storage.Buckets.Renditions),
+		storage.ContentSubtitles:  strings.TrimSpace(c.Storage.Buckets.Subtitles),
+	})
+}
+
+// GetPlaybackSecret 获取播放令牌签名密钥，未配置时使用JWT密钥
+func (c *Config) GetPlaybackSecret() string {
+	if c.Playback.Secret != "" {
+		return c.Playback.Secret
+	}
+	return c.JWT.Secret
+}
+
+// GetWebhookEndpoints 获取Webhook订阅端点
+func (c *Config) GetWebhookEndpoints() []*webhook.Endpoint {
+	endpoints := make([]*webhook.Endpoint, 0, len(c.Webhooks))
+	for _, hook := range c.Webhooks {
+		endpoints = append(endpoints, &webhook.Endpoint{
+			URL:    strings.TrimSpace(hook.URL),
+			Secret: hook.Secret,
+			Events: hook.Events,
+		})
+	}
+	return endpoints
+}
+
+// GetModerationHooks 获取内容审核钩子，未配置时返回空列表
+func (c *Config) GetModerationHooks() ([]moderation.Hook, error) {
+	hooks := make([]moderation.Hook, 0, len(c.Moderation.Hooks))
+	for _, hook := range c.Moderation.Hooks {
+		var timeout time.Duration
+		if hook.Timeout != "" {
+			var err error
+			if timeout, err = ParseDuration(hook.Timeout); err != nil || timeout <= 0 {
+				return nil, fmt.Errorf("审核钩子超时时间无效: %s", hook.Timeout)
+			}
+		}
+		commandHook, err := moderation.NewCommandHook(hook.Name, hook.Command, hook.Args, timeout)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, commandHook)
+	}
+	return hooks, nil
+}
+
+// splitList 解析逗号分隔的列表，统一转换为小写并忽略空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// splitPairs 解析逗号分隔的"键=值"列表，如"mp4=4GB,avi=500MB"，缺少"="的项值为空
+func splitPairs(value string) map[string]string {
+	pairs := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		key, val, _ := strings.Cut(item, "=")
+		if key = strings.TrimSpace(key); key != "" {
+			pairs[key] = strings.TrimSpace(val)
+		}
+	}
+	return pairs
+}
+
+// sizeUnits 大小单位，按1024进制换算
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize 解析大小，支持B/KB/MB/GB/TB单位（不区分大小写），无单位时按字节处理
+func ParseSize(size string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(size))
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+	
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("无效的大小: %s", size)
+	}
+	return int64(number * float64(multiplier)), nil
+}
+
 // GetStorageConfig 获取存储配置
 func (c *Config) GetStorageConfig() storage.Config {
 	return &StorageConfigAdapter{
@@ -197,6 +1705,57 @@ func (c *Config) GetStorageConfig() storage.Config {
 	}
 }
 
+// GetStoragePublicEndpoint 获取预签名URL的对外访问地址，未配置时返回nil
+func (c *Config) GetStoragePublicEndpoint() (*storage.PublicEndpoint, error) {
+	return storage.ParsePublicEndpoint(c.Storage.PublicEndpoint, c.Storage.PublicHost)
+}
+
+// GetDriverConfig 获取存储驱动配置，用于storage.NewFromConfig
+// 所有驱动生成的预签名URL都使用配置的对外访问地址，地址、超时时间和重试配置无效时在Validate中报告
+func (c *Config) GetDriverConfig() *storage.DriverConfig {
+	baseURL := c.Storage.Local.BaseURL
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("%s://%s:%d/storage", c.GetServerScheme(), c.Server.Host, c.Server.Port)
+	}
+	publicEndpoint, _ := c.GetStoragePublicEndpoint()
+	timeouts, _ := c.GetStorageTimeouts()
+	resilience, _ := c.GetStorageResilience()
+	
+	return &storage.DriverConfig{
+		Driver: c.Storage.Driver,
+		MinIO: &storage.MinIOConfig{
+			Endpoint:       c.MinIO.Endpoint,
+			AccessKey:      c.MinIO.AccessKey,
+			SecretKey:      c.MinIO.SecretKey,
+			UseSSL:         c.MinIO.UseSSL,
+			Region:         c.MinIO.Region,
+			PublicEndpoint: publicEndpoint,
+		},
+		S3: &storage.MinIOConfig{
+			Endpoint:       c.Storage.S3.Endpoint,
+			AccessKey:      c.Storage.S3.AccessKey,
+			SecretKey:      c.Storage.S3.SecretKey,
+			UseSSL:         c.Storage.S3.UseSSL,
+			Region:         c.Storage.S3.Region,
+			PublicEndpoint: publicEndpoint,
+		},
+		Local: &storage.LocalConfig{
+			RootDir:        c.Storage.Local.RootDir,
+			BaseURL:        baseURL,
+			SigningKey:     c.Storage.Local.SigningKey,
+			PublicEndpoint: publicEndpoint,
+		},
+		// 内存驱动与本地驱动共用/storage路由和签名密钥
+		Memory: &storage.MemoryConfig{
+			BaseURL:        baseURL,
+			SigningKey:     c.Storage.Local.SigningKey,
+			PublicEndpoint: publicEndpoint,
+		},
+		Timeouts:   timeouts,
+		Resilience: resilience,
+	}
+}
+
 // StorageConfigAdapter 存储配置适配器
 type StorageConfigAdapter struct {
 	endpoint  string
@@ -245,14 +1804,22 @@ func NewConfigWatcher(configFile string) (*ConfigWatcher, error) {
 	}, nil
 }
 
+// SetErrorHandler 设置重新加载失败时的处理函数，需要在Watch之前调用
+func (w *ConfigWatcher) SetErrorHandler(handler func(error)) {
+	w.errorHandler = handler
+}
+
 // Watch 启动配置监听
+// 监听配置文件所在目录，编辑器通过重命名替换文件后仍能收到变更
 func (w *ConfigWatcher) Watch(changes chan<- *Config) error {
-	// 添加配置文件到监听列表
-	if err := w.watcher.Add(w.configFile); err != nil {
+	// 添加配置文件所在目录到监听列表
+	if err := w.watcher.Add(filepath.Dir(w.configFile)); err != nil {
 		return fmt.Errorf("添加文件监听失败: %w", err)
 	}
+	configFile := filepath.Clean(w.configFile)
 	
 	go func() {
+		var reload <-chan time.Time
 		for {
 			select {
 			case event, ok := <-w.watcher.Events:
@@ -260,25 +1827,29 @@ func (w *ConfigWatcher) Watch(changes chan<- *Config) error {
 					return
 				}
 				
-				// 只处理写入事件
-				if event.Op&fsnotify.Write == fsnotify.Write {
-					// 重新加载配置
-					config, err := LoadFromFile(w.configFile)
-					if err == nil {
-						select {
-						case changes <- config:
-						case <-w.stopCh:
-							return
-						}
-					}
+				// 只处理配置文件的写入和创建事件，等待写入完成后再加载
+				if filepath.Clean(event.Name) == configFile && event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+					reload = time.After(configReloadDelay)
+				}
+				
+			case <-reload:
+				reload = nil
+				config, err := LoadFromFile(w.configFile)
+				if err != nil {
+					w.reportError(err)
+					continue
+				}
+				select {
+				case changes <- config:
+				case <-w.stopCh:
+					return
 				}
 				
 			case err, ok := <-w.watcher.Errors:
 				if !ok {
 					return
 				}
-				// 在实际应用中，这里应该记录错误日志
-				_ = err
+				w.reportError(err)
 				
 			case <-w.stopCh:
 				return
@@ -289,6 +1860,13 @@ func (w *ConfigWatcher) Watch(changes chan<- *Config) error {
 	return nil
 }
 
+// reportError 报告重新加载失败，未设置处理函数时忽略
+func (w *ConfigWatcher) reportError(err error) {
+	if w.errorHandler != nil {
+		w.errorHandler(err)
+	}
+}
+
 // Stop 停止监听
 func (w *ConfigWatcher) Stop() error {
 	close(w.stopCh)
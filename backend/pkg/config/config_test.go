@@ -6,8 +6,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cloudwego/hertz/pkg/common/hlog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/storage"
 )
 
 // TestConfig_LoadFromYAML 测试从YAML文件加载配置
@@ -173,9 +176,973 @@ minio:
 	
 	// 验证默认值
 	assert.Equal(t, "localhost", config.Server.Host, "应该使用默认主机")
+	assert.Equal(t, "zh-CN", config.Server.DefaultLanguage, "应该默认使用中文消息")
 	assert.Equal(t, "us-east-1", config.MinIO.Region, "应该使用默认区域")
 	assert.Equal(t, "zhulong-videos", config.MinIO.Bucket, "应该使用默认存储桶")
 	assert.False(t, config.MinIO.UseSSL, "应该默认不使用SSL")
+	assert.Equal(t, "ffmpeg", config.Streaming.FFmpegPath, "应该使用默认FFmpeg路径")
+	assert.Equal(t, 6, config.Streaming.SegmentDuration, "应该使用默认HLS分片时长")
+	assert.Equal(t, 1, config.Streaming.Workers, "应该默认串行执行转码任务")
+	assert.Equal(t, 3, config.Streaming.MaxAttempts, "应该使用默认最大执行次数")
+	assert.Equal(t, "local", config.Worker.Mode, "应该默认由API服务执行处理任务")
+	assert.Equal(t, "2m", config.Worker.LeaseTimeout, "应该使用默认worker租约时长")
+	assert.Equal(t, "none", config.Cache.Driver, "应该默认不启用缓存")
+	assert.Equal(t, 10000, config.Cache.MaxEntries, "应该使用默认缓存条目数")
+	assert.False(t, config.Streaming.Enabled, "应该默认不启用HLS")
+	assert.Equal(t, "24h", config.JWT.Expire, "应该使用默认JWT有效期")
+	assert.Equal(t, "minio", config.Storage.Driver, "应该默认使用MinIO存储驱动")
+	assert.Equal(t, "./data/storage", config.Storage.Local.RootDir, "应该使用默认本地存储目录")
+	assert.Equal(t, "10GB", config.Quota.UserLimit, "应该使用默认用户存储配额")
+	assert.False(t, config.RateLimit.Enabled, "应该默认不启用限流")
+	assert.Equal(t, RateLimitRule{RequestsPerSecond: 10, Burst: 20}, config.RateLimit.Global, "应该使用默认全局限流规则")
+	assert.Equal(t, RateLimitRule{RequestsPerSecond: 1, Burst: 3}, config.RateLimit.Upload, "应该使用默认上传限流规则")
+	assert.Equal(t, RateLimitRule{RequestsPerSecond: 50, Burst: 100}, config.RateLimit.Playback, "应该使用默认播放限流规则")
+}
+
+// TestConfig_RateLimit 测试限流配置加载
+func TestConfig_RateLimit(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "rate_limit.yml")
+	rateLimitYAML := `
+rate_limit:
+  enabled: true
+  upload:
+    requests_per_second: 0.5
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(rateLimitYAML), 0644))
+
+	config, err := LoadFromFile(configFile)
+	require.NoError(t, err)
+	assert.True(t, config.RateLimit.Enabled)
+	assert.Equal(t, 0.5, config.RateLimit.Upload.RequestsPerSecond, "应该使用配置的上传速率")
+	assert.Equal(t, 3, config.RateLimit.Upload.Burst, "未配置的桶容量应该使用默认值")
+
+	t.Setenv("ZHULONG_RATE_LIMIT_ENABLED", "false")
+	config, err = LoadFromFile(configFile)
+	require.NoError(t, err)
+	assert.False(t, config.RateLimit.Enabled, "环境变量应该覆盖配置文件")
+}
+
+// TestConfig_AllowedFormats 测试允许上传的视频格式配置
+func TestConfig_AllowedFormats(t *testing.T) {
+	config := &Config{}
+	assert.Nil(t, config.GetAllowedFormats(), "未配置时应该返回nil")
+
+	config.Upload.AllowedFormats = "MP4, webm,,flv "
+	assert.Equal(t, []string{"mp4", "webm", "flv"}, config.GetAllowedFormats())
+
+	t.Setenv("ZHULONG_UPLOAD_ALLOWED_FORMATS", "mkv,ts")
+	t.Setenv("ZHULONG_UPLOAD_ALLOWED_TYPES", "video/x-matroska,video/mp2t")
+	t.Setenv("ZHULONG_UPLOAD_MAX_SIZE", "100MB")
+	config.applyEnvironmentOverrides()
+	assert.Equal(t, []string{"mkv", "ts"}, config.GetAllowedFormats(), "环境变量应该覆盖配置文件")
+	assert.Equal(t, []string{"video/x-matroska", "video/mp2t"}, config.GetAllowedTypes())
+
+	maxSize, err := config.GetUploadMaxSize()
+	require.NoError(t, err)
+	assert.Equal(t, int64(100<<20), maxSize)
+
+	config = &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+		Upload:  UploadConfig{MaxSize: "0"},
+	}
+	err = config.Validate()
+	require.Error(t, err, "上传大小限制不能为0")
+	assert.Contains(t, err.Error(), "上传文件大小限制")
+}
+
+// TestConfig_UploadSizeLimits 测试按格式和用户角色的上传大小限制配置
+func TestConfig_UploadSizeLimits(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+	}
+	formatLimits, err := config.GetUploadFormatMaxSizes()
+	require.NoError(t, err)
+	assert.Empty(t, formatLimits, "未配置时应该返回空")
+
+	t.Setenv("ZHULONG_UPLOAD_FORMAT_MAX_SIZES", "MP4=4GB, avi = 500MB,,")
+	t.Setenv("ZHULONG_UPLOAD_ROLE_MAX_SIZES", "uploader=1GB")
+	config.applyEnvironmentOverrides()
+	require.NoError(t, config.Validate())
+
+	formatLimits, err = config.GetUploadFormatMaxSizes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"mp4": 4 << 30, "avi": 500 << 20}, formatLimits)
+	roleLimits, err := config.GetUploadRoleMaxSizes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"uploader": 1 << 30}, roleLimits)
+
+	config.Upload.FormatMaxSizes = map[string]string{"mkv": "0"}
+	err = config.Validate()
+	require.Error(t, err, "大小不能为0")
+	assert.Contains(t, err.Error(), "按格式的上传文件大小限制无效")
+
+	config.Upload.FormatMaxSizes = nil
+	config.Upload.RoleMaxSizes = map[string]string{"guest": "1GB"}
+	err = config.Validate()
+	require.Error(t, err, "角色必须存在")
+	assert.Contains(t, err.Error(), "角色不存在")
+}
+
+// TestConfig_UploadBandwidth 测试上传带宽限制配置
+func TestConfig_UploadBandwidth(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+	}
+	limit, err := config.GetUploadBandwidthLimit()
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), limit, "未配置时不限制")
+
+	t.Setenv("ZHULONG_UPLOAD_BANDWIDTH_LIMIT", "20MB")
+	t.Setenv("ZHULONG_UPLOAD_CONNECTION_BANDWIDTH_LIMIT", "512KB")
+	config.applyEnvironmentOverrides()
+	require.NoError(t, config.Validate())
+
+	limit, err = config.GetUploadBandwidthLimit()
+	require.NoError(t, err)
+	assert.Equal(t, int64(20<<20), limit)
+	limit, err = config.GetUploadConnectionBandwidthLimit()
+	require.NoError(t, err)
+	assert.Equal(t, int64(512<<10), limit)
+
+	config.Upload.ConnectionBandwidthLimit = "fast"
+	err = config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "单个上传带宽限制")
+}
+
+// TestConfig_TrustedProxies 测试受信任的反向代理配置
+func TestConfig_TrustedProxies(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+	}
+	policy, err := config.GetProxyPolicy()
+	require.NoError(t, err, "未配置时不信任任何代理")
+	require.NotNil(t, policy)
+
+	t.Setenv("ZHULONG_SERVER_TRUSTED_PROXIES", "172.18.0.0/16, 127.0.0.1")
+	config.applyEnvironmentOverrides()
+	assert.Equal(t, []string{"172.18.0.0/16", "127.0.0.1"}, config.Server.TrustedProxies)
+	require.NoError(t, config.Validate())
+
+	config.Server.TrustedProxies = []string{"nginx"}
+	err = config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "受信任代理地址格式无效")
+}
+
+// TestConfig_GetServerAddress 测试服务监听地址
+func TestConfig_GetServerAddress(t *testing.T) {
+	config := &Config{Server: ServerConfig{Host: "0.0.0.0", Port: 8080}}
+	assert.Equal(t, "0.0.0.0:8080", config.GetServerAddress())
+
+	t.Setenv("ZHULONG_SERVER_HOST", "::")
+	t.Setenv("ZHULONG_SERVER_PORT", "9000")
+	config.applyEnvironmentOverrides()
+	assert.Equal(t, "[::]:9000", config.GetServerAddress(), "IPv6地址需要方括号")
+}
+
+// TestConfig_GetLogLevel 测试日志级别配置
+func TestConfig_GetLogLevel(t *testing.T) {
+	config := &Config{}
+	level, err := config.GetLogLevel()
+	require.NoError(t, err)
+	assert.Equal(t, hlog.LevelInfo, level, "未配置时为info")
+
+	config.App.Debug = true
+	level, _ = config.GetLogLevel()
+	assert.Equal(t, hlog.LevelDebug, level, "开启debug时为debug")
+
+	t.Setenv("ZHULONG_APP_LOG_LEVEL", "WARN")
+	config.applyEnvironmentOverrides()
+	level, err = config.GetLogLevel()
+	require.NoError(t, err)
+	assert.Equal(t, hlog.LevelWarn, level, "配置的级别优先于debug")
+
+	config.App.LogLevel = "verbose"
+	_, err = config.GetLogLevel()
+	assert.Error(t, err)
+}
+
+// TestConfig_TLS 测试HTTPS配置
+func TestConfig_TLS(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+	}
+	assert.Equal(t, "http", config.GetServerScheme())
+
+	t.Setenv("ZHULONG_TLS_ENABLED", "true")
+	t.Setenv("ZHULONG_TLS_CERT_DIR", "/data/tls")
+	t.Setenv("ZHULONG_TLS_HOSTS", "NAS.lan, 192.168.1.10")
+	config.applyEnvironmentOverrides()
+	assert.True(t, config.Server.TLS.Enabled)
+	assert.Equal(t, "/data/tls", config.Server.TLS.CertDir)
+	assert.Equal(t, []string{"nas.lan", "192.168.1.10"}, config.Server.TLS.Hosts)
+	require.NoError(t, config.Validate(), "未配置证书时使用自签名证书")
+	assert.Equal(t, "https", config.GetServerScheme())
+	assert.Equal(t, "https://localhost:8080/storage", config.GetDriverConfig().Local.BaseURL, "本地存储的预签名URL应该使用https")
+
+	t.Setenv("ZHULONG_TLS_CERT_FILE", "/etc/zhulong/server.crt")
+	config.applyEnvironmentOverrides()
+	err := config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "TLS证书文件和私钥文件必须同时配置")
+
+	t.Setenv("ZHULONG_TLS_KEY_FILE", "/etc/zhulong/server.key")
+	config.applyEnvironmentOverrides()
+	require.NoError(t, config.Validate())
+}
+
+// TestConfig_StoragePublicEndpoint 测试预签名URL对外访问地址配置
+func TestConfig_StoragePublicEndpoint(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+	}
+	require.NoError(t, config.Validate(), "未配置时使用存储返回的地址")
+
+	t.Setenv("ZHULONG_STORAGE_PUBLIC_ENDPOINT", "https://cdn.example.com/minio")
+	config.applyEnvironmentOverrides()
+	assert.Equal(t, "https://cdn.example.com/minio", config.Storage.PublicEndpoint)
+	require.NoError(t, config.Validate())
+
+	publicEndpoint, err := config.GetStoragePublicEndpoint()
+	require.NoError(t, err)
+	assert.Equal(t, &storage.PublicEndpoint{Scheme: "https", Host: "cdn.example.com", PathPrefix: "/minio"}, publicEndpoint)
+	assert.Equal(t, publicEndpoint, config.GetDriverConfig().MinIO.PublicEndpoint, "所有驱动都应该使用对外访问地址")
+	assert.Equal(t, publicEndpoint, config.GetDriverConfig().Local.PublicEndpoint)
+
+	t.Setenv("ZHULONG_STORAGE_PUBLIC_HOST", "nas.lan:9000")
+	config.applyEnvironmentOverrides()
+	err = config.Validate()
+	require.Error(t, err, "不能同时配置对外基础URL和对外主机")
+	assert.Contains(t, err.Error(), "存储对外访问地址无效")
+
+	config.Storage.PublicEndpoint = ""
+	require.NoError(t, config.Validate())
+	publicEndpoint, err = config.GetStoragePublicEndpoint()
+	require.NoError(t, err)
+	assert.Equal(t, &storage.PublicEndpoint{Host: "nas.lan:9000"}, publicEndpoint)
+
+	config.Storage.PublicHost = ""
+	for _, endpoint := range []string{"cdn.example.com", "ftp://cdn.example.com", "https://cdn.example.com/?a=1"} {
+		config.Storage.PublicEndpoint = endpoint
+		err := config.Validate()
+		require.Error(t, err, endpoint)
+		assert.Contains(t, err.Error(), "存储对外访问地址无效")
+	}
+}
+
+// TestConfig_Deduplication 测试重复视频处理方式配置
+func TestConfig_Deduplication(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+	}
+	assert.Equal(t, DeduplicationOff, config.GetDeduplicationMode(), "未配置时不检测重复视频")
+	assert.NoError(t, config.Validate())
+
+	t.Setenv("ZHULONG_UPLOAD_DEDUPLICATION", " Alias ")
+	config.applyEnvironmentOverrides()
+	assert.Equal(t, DeduplicationAlias, config.GetDeduplicationMode(), "环境变量应该覆盖配置文件")
+	assert.NoError(t, config.Validate())
+
+	config.Upload.Deduplication = "merge"
+	err := config.Validate()
+	require.Error(t, err, "未知的处理方式应该验证失败")
+	assert.Contains(t, err.Error(), "重复视频处理方式")
+}
+
+// TestConfig_UploadMediaTypes 测试允许上传的媒体类型配置
+func TestConfig_UploadMediaTypes(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+	}
+	mediaTypes, err := config.GetUploadMediaTypes()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"video"}, mediaTypes, "未配置时只允许视频")
+
+	t.Setenv("ZHULONG_UPLOAD_MEDIA_TYPES", "video, Image,audio,image")
+	config.applyEnvironmentOverrides()
+	mediaTypes, err = config.GetUploadMediaTypes()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"video", "image", "audio"}, mediaTypes)
+	assert.NoError(t, config.Validate())
+
+	config.Upload.MediaTypes = "video,document"
+	err = config.Validate()
+	require.Error(t, err, "未知的媒体类型应该验证失败")
+	assert.Contains(t, err.Error(), "允许上传的媒体类型无效")
+}
+
+// TestConfig_UploadVerification 测试上传文件验证方式配置
+func TestConfig_UploadVerification(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+	}
+	assert.Equal(t, VerificationHeader, config.GetUploadVerification(), "未配置时只检查文件头")
+	assert.NoError(t, config.Validate())
+
+	config.applyDefaults()
+	assert.Equal(t, VerificationStructure, config.GetUploadVerification(), "默认解析完整的容器结构")
+	assert.Equal(t, "ffprobe", config.Upload.FFprobePath)
+
+	t.Setenv("ZHULONG_UPLOAD_VERIFICATION", " Probe ")
+	t.Setenv("ZHULONG_FFPROBE_PATH", "/usr/local/bin/ffprobe")
+	config.applyEnvironmentOverrides()
+	assert.Equal(t, VerificationProbe, config.GetUploadVerification(), "环境变量应该覆盖配置文件")
+	assert.Equal(t, "/usr/local/bin/ffprobe", config.Upload.FFprobePath)
+	assert.NoError(t, config.Validate())
+
+	config.Upload.Verification = "full"
+	err := config.Validate()
+	require.Error(t, err, "未知的验证方式应该验证失败")
+	assert.Contains(t, err.Error(), "上传文件验证方式")
+}
+
+// TestConfig_DefaultVisibility 测试上传视频默认可见性配置
+func TestConfig_DefaultVisibility(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+	}
+	assert.Equal(t, "private", config.GetDefaultVisibility(), "未配置时默认为私有")
+	assert.NoError(t, config.Validate())
+
+	t.Setenv("ZHULONG_UPLOAD_DEFAULT_VISIBILITY", "Public")
+	config.applyEnvironmentOverrides()
+	assert.Equal(t, "public", config.GetDefaultVisibility())
+	assert.NoError(t, config.Validate())
+
+	config.Upload.DefaultVisibility = "friends"
+	err := config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "默认可见性")
+}
+
+// TestConfig_MaxVersions 测试视频历史版本保留数量配置
+func TestConfig_MaxVersions(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+	}
+	assert.Equal(t, 5, config.GetMaxVersions(), "未配置时使用默认值")
+
+	t.Setenv("ZHULONG_UPLOAD_MAX_VERSIONS", "2")
+	config.applyEnvironmentOverrides()
+	assert.Equal(t, 2, config.GetMaxVersions(), "环境变量应该覆盖配置文件")
+	assert.NoError(t, config.Validate())
+
+	config.Upload.MaxVersions = -1
+	err := config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "历史版本保留数量")
+}
+
+// TestConfig_Temp 测试临时目录配置
+func TestConfig_Temp(t *testing.T) {
+	config := &Config{
+		Server:    ServerConfig{Host: "localhost", Port: 8080},
+		Storage:   StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+		Streaming: StreamingConfig{TempDir: "/var/tmp/zhulong-hls"},
+	}
+	tempConfig, err := config.GetTempConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "/var/tmp/zhulong-hls", tempConfig.Dir, "未配置temp.dir时使用streaming.temp_dir")
+	assert.Zero(t, tempConfig.MaxBytes)
+	assert.Zero(t, tempConfig.CleanupInterval)
+
+	config.Temp = TempConfig{MaxSize: "1GB", MaxAge: "1d", CleanupInterval: "5m"}
+	t.Setenv("ZHULONG_TEMP_DIR", "/data/tmp")
+	t.Setenv("ZHULONG_TEMP_MAX_SIZE", "2GB")
+	config.applyEnvironmentOverrides()
+	assert.NoError(t, config.Validate())
+	tempConfig, err = config.GetTempConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "/data/tmp", tempConfig.Dir, "环境变量应该覆盖配置文件")
+	assert.Equal(t, int64(2*1024*1024*1024), tempConfig.MaxBytes)
+	assert.Equal(t, 24*time.Hour, tempConfig.MaxAge)
+	assert.Equal(t, 5*time.Minute, tempConfig.CleanupInterval)
+
+	config.Temp.MaxAge = "soon"
+	err = config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "临时文件有效期")
+}
+
+// TestConfig_DefaultLanguage 测试响应消息默认语言配置
+func TestConfig_DefaultLanguage(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+	}
+	t.Setenv("ZHULONG_DEFAULT_LANGUAGE", "en-US")
+	config.applyEnvironmentOverrides()
+	assert.Equal(t, "en-US", config.Server.DefaultLanguage, "环境变量应该覆盖配置文件")
+	assert.NoError(t, config.Validate())
+
+	config.Server.DefaultLanguage = "fr"
+	err := config.Validate()
+	require.Error(t, err, "不支持的语言应该验证失败")
+	assert.Contains(t, err.Error(), "默认语言")
+}
+
+// TestConfig_Playback 测试播放令牌配置
+func TestConfig_Playback(t *testing.T) {
+	config := &Config{JWT: JWTConfig{Secret: "jwt-secret"}}
+	assert.False(t, config.Playback.SignedURLs, "应该默认返回存储的预签名URL")
+	assert.Equal(t, "jwt-secret", config.GetPlaybackSecret(), "未配置时应该使用JWT密钥")
+
+	t.Setenv("ZHULONG_PLAYBACK_SIGNED_URLS", "true")
+	t.Setenv("ZHULONG_PLAYBACK_SECRET", "playback-secret")
+	t.Setenv("ZHULONG_PLAYBACK_BASE_URL", "http://nas.lan:8888")
+	config.applyEnvironmentOverrides()
+	assert.True(t, config.Playback.SignedURLs)
+	assert.Equal(t, "playback-secret", config.GetPlaybackSecret())
+	assert.Equal(t, "http://nas.lan:8888", config.Playback.BaseURL)
+}
+
+// TestConfig_Webhooks 测试Webhook配置验证和转换
+func TestConfig_Webhooks(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+		Webhooks: []WebhookConfig{
+			{URL: " https://chat.example.com/hooks/zhulong ", Secret: "secret", Events: []string{"video.uploaded", "video.deleted"}},
+			{URL: "http://indexer.internal/events"},
+		},
+	}
+	require.NoError(t, config.Validate())
+
+	endpoints := config.GetWebhookEndpoints()
+	require.Len(t, endpoints, 2)
+	assert.Equal(t, "https://chat.example.com/hooks/zhulong", endpoints[0].URL, "地址应该去除首尾空白")
+	assert.Equal(t, "secret", endpoints[0].Secret)
+	assert.Equal(t, []string{"video.uploaded", "video.deleted"}, endpoints[0].Events)
+	assert.Empty(t, endpoints[1].Events)
+
+	config.Webhooks[1].Events = []string{"video.played"}
+	err := config.Validate()
+	require.Error(t, err, "未知的事件应该验证失败")
+	assert.Contains(t, err.Error(), "Webhook事件")
+
+	config.Webhooks[1] = WebhookConfig{URL: "indexer.internal/events"}
+	err = config.Validate()
+	require.Error(t, err, "缺少协议的地址应该验证失败")
+	assert.Contains(t, err.Error(), "Webhook地址")
+}
+
+// TestConfig_Moderation 测试内容审核配置验证和转换
+func TestConfig_Moderation(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+	}
+	require.NoError(t, config.Validate())
+	hooks, err := config.GetModerationHooks()
+	require.NoError(t, err)
+	assert.Empty(t, hooks, "未配置钩子时不审核")
+
+	config.Moderation = ModerationConfig{
+		Hooks: []ModerationHookConfig{
+			{Name: "nsfw", Command: "/usr/local/bin/nsfw-check", Args: []string{"--threshold", "0.8"}, Timeout: "2m"},
+			{Command: "/opt/zhulong/keywords.sh"},
+		},
+		OnError: "approve",
+	}
+	require.NoError(t, config.Validate())
+	hooks, err = config.GetModerationHooks()
+	require.NoError(t, err)
+	require.Len(t, hooks, 2)
+	assert.Equal(t, "nsfw", hooks[0].Name())
+	assert.Equal(t, "/opt/zhulong/keywords.sh", hooks[1].Name())
+
+	config.Moderation.OnError = "block"
+	err = config.Validate()
+	require.Error(t, err, "未知的失败处理方式应该验证失败")
+	assert.Contains(t, err.Error(), "审核钩子失败处理方式")
+
+	config.Moderation.OnError = ""
+	config.Moderation.Hooks[0].Timeout = "soon"
+	err = config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "审核钩子超时时间无效")
+
+	config.Moderation.Hooks[0] = ModerationHookConfig{Name: "empty"}
+	err = config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "审核钩子命令不能为空")
+}
+
+// TestConfig_Archive 测试归档配置默认值和验证
+func TestConfig_Archive(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+		Archive: ArchiveConfig{Enabled: true},
+	}
+	config.applyDefaults()
+	require.NoError(t, config.Validate())
+	assert.Equal(t, "zhulong-archive", config.Archive.Bucket)
+
+	idleAfter, err := config.GetArchiveIdleAfter()
+	require.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, idleAfter)
+	interval, err := config.GetArchiveCheckInterval()
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, interval)
+
+	config.Archive.Bucket = config.MinIO.Bucket
+	err = config.Validate()
+	require.Error(t, err, "归档存储桶与视频存储桶相同时应该验证失败")
+	assert.Contains(t, err.Error(), "归档存储桶")
+
+	config.Archive.Bucket = "zhulong-archive"
+	config.Archive.IdleAfter = "0d"
+	err = config.Validate()
+	require.Error(t, err, "闲置时长必须大于0")
+	assert.Contains(t, err.Error(), "归档闲置时长")
+
+	config.Archive.Enabled = false
+	assert.NoError(t, config.Validate(), "未启用归档时不验证归档配置")
+}
+
+// TestConfig_Backup 测试备份配置默认值、验证和环境变量覆盖
+func TestConfig_Backup(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+		Backup:  BackupConfig{Enabled: true},
+	}
+	config.applyDefaults()
+	require.NoError(t, config.Validate())
+	assert.Equal(t, "zhulong-backups", config.Backup.Bucket)
+	assert.Equal(t, 7, config.Backup.Retention)
+	interval, err := config.GetBackupInterval()
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, interval)
+
+	config.Backup.Bucket = config.MinIO.Bucket
+	err = config.Validate()
+	require.Error(t, err, "备份存储桶与视频存储桶相同时应该验证失败")
+	assert.Contains(t, err.Error(), "备份存储桶")
+
+	os.Setenv("ZHULONG_BACKUP_BUCKET", "nas-backups")
+	os.Setenv("ZHULONG_BACKUP_INTERVAL", "0s")
+	os.Setenv("ZHULONG_BACKUP_RETENTION", "30")
+	defer os.Unsetenv("ZHULONG_BACKUP_BUCKET")
+	defer os.Unsetenv("ZHULONG_BACKUP_INTERVAL")
+	defer os.Unsetenv("ZHULONG_BACKUP_RETENTION")
+	config.applyEnvironmentOverrides()
+	assert.Equal(t, "nas-backups", config.Backup.Bucket)
+	assert.Equal(t, 30, config.Backup.Retention)
+	err = config.Validate()
+	require.Error(t, err, "备份间隔必须大于0")
+	assert.Contains(t, err.Error(), "备份间隔")
+
+	config.Backup.Enabled = false
+	assert.NoError(t, config.Validate(), "未启用定期备份时不验证备份配置")
+
+	file := filepath.Join(t.TempDir(), "zhulong.yml")
+	require.NoError(t, os.WriteFile(file, []byte("server:\n  port: 8080\nstorage:\n  driver: local\n"), 0o644))
+	loaded, err := LoadFromFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, file, loaded.SourceFile(), "从文件加载的配置记录文件路径")
+	assert.Empty(t, config.SourceFile())
+}
+
+// TestConfig_Schedule 测试到期视频检查间隔的默认值、验证和环境变量覆盖
+func TestConfig_Schedule(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+	}
+	config.applyDefaults()
+	require.NoError(t, config.Validate())
+	interval, err := config.GetScheduleCheckInterval()
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, interval)
+
+	os.Setenv("ZHULONG_SCHEDULE_CHECK_INTERVAL", "0s")
+	defer os.Unsetenv("ZHULONG_SCHEDULE_CHECK_INTERVAL")
+	config.applyEnvironmentOverrides()
+	err = config.Validate()
+	require.Error(t, err, "检查间隔必须大于0")
+	assert.Contains(t, err.Error(), "到期视频检查间隔")
+}
+
+// TestConfig_Replication 测试存储复制配置的默认值、验证和环境变量覆盖
+func TestConfig_Replication(t *testing.T) {
+	config := &Config{
+		Server:      ServerConfig{Host: "localhost", Port: 8080},
+		Storage:     StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/data/zhulong"}},
+		Replication: ReplicationConfig{Enabled: true},
+	}
+	config.applyDefaults()
+	assert.Equal(t, "minio", config.Replication.Driver)
+	assert.Equal(t, 2, config.Replication.Workers)
+	assert.Equal(t, 1000, config.Replication.QueueSize)
+	interval, err := config.GetReplicationSyncInterval()
+	require.NoError(t, err)
+	assert.Equal(t, 6*time.Hour, interval)
+	err = config.Validate()
+	require.Error(t, err, "minio副本存储需要端点")
+	assert.Contains(t, err.Error(), "端点")
+
+	os.Setenv("ZHULONG_REPLICATION_DRIVER", "local")
+	os.Setenv("ZHULONG_REPLICATION_ROOT_DIR", "/data/zhulong/")
+	defer os.Unsetenv("ZHULONG_REPLICATION_DRIVER")
+	defer os.Unsetenv("ZHULONG_REPLICATION_ROOT_DIR")
+	config.applyEnvironmentOverrides()
+	err = config.Validate()
+	require.Error(t, err, "副本根目录不能与存储根目录相同")
+	assert.Contains(t, err.Error(), "根目录")
+
+	config.Replication.RootDir = "/mnt/offsite"
+	config.Replication.SyncInterval = "0"
+	require.NoError(t, config.Validate(), "sync_interval为0表示不定期同步")
+	driverConfig := config.GetReplicationDriverConfig()
+	assert.Equal(t, "local", driverConfig.Driver)
+	assert.Equal(t, "/mnt/offsite", driverConfig.Local.RootDir)
+
+	config.Replication.Driver = "ftp"
+	require.Error(t, config.Validate())
+	config.Replication.Enabled = false
+	assert.NoError(t, config.Validate(), "未启用复制时不验证复制配置")
+}
+
+// TestConfig_StorageTimeouts 测试存储操作超时时间的默认值、环境变量覆盖和验证
+func TestConfig_StorageTimeouts(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/data/zhulong"}},
+	}
+	config.applyDefaults()
+	timeouts, err := config.GetStorageTimeouts()
+	require.NoError(t, err)
+	assert.Equal(t, storage.Timeouts{
+		Metadata: 30 * time.Second,
+		Batch:    5 * time.Minute,
+		Upload:   time.Hour,
+		Download: time.Minute,
+	}, timeouts)
+	assert.Equal(t, timeouts, config.GetDriverConfig().Timeouts)
+
+	os.Setenv("ZHULONG_STORAGE_TIMEOUT_UPLOAD", "0")
+	defer os.Unsetenv("ZHULONG_STORAGE_TIMEOUT_UPLOAD")
+	config.applyEnvironmentOverrides()
+	timeouts, err = config.GetStorageTimeouts()
+	require.NoError(t, err)
+	assert.Zero(t, timeouts.Upload, "0表示不限制")
+	require.NoError(t, config.Validate())
+
+	config.Storage.Timeouts.Download = "-1s"
+	err = config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "download")
+}
+
+// TestConfig_StorageResilience 测试存储重试和熔断配置的默认值、环境变量覆盖和验证
+func TestConfig_StorageResilience(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/data/zhulong"}},
+	}
+	config.applyDefaults()
+	opts, err := config.GetStorageResilience()
+	require.NoError(t, err)
+	assert.Equal(t, storage.ResilienceOptions{
+		MaxAttempts:      3,
+		BaseDelay:        100 * time.Millisecond,
+		MaxDelay:         2 * time.Second,
+		FailureThreshold: 5,
+		OpenDuration:     30 * time.Second,
+	}, opts)
+	assert.Equal(t, opts, config.GetDriverConfig().Resilience)
+
+	os.Setenv("ZHULONG_STORAGE_RETRY_MAX_ATTEMPTS", "1")
+	os.Setenv("ZHULONG_STORAGE_CIRCUIT_OPEN_DURATION", "0")
+	defer os.Unsetenv("ZHULONG_STORAGE_RETRY_MAX_ATTEMPTS")
+	defer os.Unsetenv("ZHULONG_STORAGE_CIRCUIT_OPEN_DURATION")
+	config.applyEnvironmentOverrides()
+	opts, err = config.GetStorageResilience()
+	require.NoError(t, err)
+	assert.Equal(t, 1, opts.MaxAttempts)
+	assert.Zero(t, opts.FailureThreshold, "open_duration为0时不熔断")
+	require.NoError(t, config.Validate())
+
+	config.Storage.Retry.MaxDelay = "soon"
+	err = config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max_delay")
+}
+
+// TestConfig_Worker 测试处理任务执行方式配置验证和环境变量覆盖
+func TestConfig_Worker(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+	}
+	config.applyDefaults()
+	require.NoError(t, config.Validate())
+	assert.False(t, config.IsRemoteWorkerMode())
+	leaseTimeout, err := config.GetWorkerLeaseTimeout()
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, leaseTimeout)
+
+	os.Setenv("ZHULONG_WORKER_MODE", "remote")
+	defer os.Unsetenv("ZHULONG_WORKER_MODE")
+	config.applyEnvironmentOverrides()
+	assert.True(t, config.IsRemoteWorkerMode())
+	err = config.Validate()
+	require.Error(t, err, "remote模式下未配置密钥应该验证失败")
+	assert.Contains(t, err.Error(), "worker密钥")
+
+	os.Setenv("ZHULONG_WORKER_SECRET", "worker-secret")
+	defer os.Unsetenv("ZHULONG_WORKER_SECRET")
+	config.applyEnvironmentOverrides()
+	assert.Equal(t, "worker-secret", config.Worker.Secret)
+	require.NoError(t, config.Validate())
+
+	config.Worker.LeaseTimeout = "0s"
+	err = config.Validate()
+	require.Error(t, err, "租约时长必须大于0")
+	assert.Contains(t, err.Error(), "worker租约时长")
+
+	config.Worker = WorkerConfig{Mode: "gpu"}
+	err = config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "local或remote")
+}
+
+// TestConfig_Cache 测试缓存配置验证和环境变量覆盖
+func TestConfig_Cache(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+	}
+	config.applyDefaults()
+	require.NoError(t, config.Validate())
+	assert.Equal(t, "localhost:6379", config.Cache.Redis.Addr)
+
+	os.Setenv("ZHULONG_CACHE_DRIVER", "redis")
+	defer os.Unsetenv("ZHULONG_CACHE_DRIVER")
+	os.Setenv("ZHULONG_CACHE_REDIS_ADDR", "redis:6379")
+	defer os.Unsetenv("ZHULONG_CACHE_REDIS_ADDR")
+	os.Setenv("ZHULONG_CACHE_REDIS_PASSWORD", "redis-secret")
+	defer os.Unsetenv("ZHULONG_CACHE_REDIS_PASSWORD")
+	config.applyEnvironmentOverrides()
+	require.NoError(t, config.Validate())
+
+	cacheConfig := config.GetCacheConfig()
+	assert.Equal(t, "redis", cacheConfig.Driver)
+	assert.Equal(t, "redis:6379", cacheConfig.RedisAddr)
+	assert.Equal(t, "redis-secret", cacheConfig.RedisPassword)
+
+	config.Cache.Redis.DB = -1
+	err := config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Redis数据库编号")
+
+	config.Cache = CacheConfig{Driver: "memcached"}
+	err = config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "none、memory或redis")
+}
+
+// TestConfig_CORS 测试跨域配置
+func TestConfig_CORS(t *testing.T) {
+	config := &Config{
+		Server:  ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+	}
+	config.applyDefaults()
+	require.NoError(t, config.Validate())
+	assert.Equal(t, "off", config.CORS.Mode)
+	assert.Equal(t, "12h", config.CORS.MaxAge)
+
+	os.Setenv("ZHULONG_CORS_MODE", "strict")
+	defer os.Unsetenv("ZHULONG_CORS_MODE")
+	os.Setenv("ZHULONG_CORS_ALLOWED_ORIGINS", "https://Video.Example.com, https://*.example.org")
+	defer os.Unsetenv("ZHULONG_CORS_ALLOWED_ORIGINS")
+	config.applyEnvironmentOverrides()
+	require.NoError(t, config.Validate())
+	assert.Equal(t, []string{"https://video.example.com", "https://*.example.org"}, config.CORS.AllowedOrigins)
+
+	policy, err := config.GetCORSPolicy()
+	require.NoError(t, err)
+	assert.True(t, policy.Allows("https://video.example.com"))
+	assert.True(t, policy.Allows("https://cdn.example.org"))
+	assert.False(t, policy.Allows("http://192.168.1.10:3000"), "strict模式不应该允许未配置的局域网来源")
+
+	assert.False(t, config.CORS.PublicStreaming)
+	os.Setenv("ZHULONG_CORS_PUBLIC_STREAMING", "true")
+	defer os.Unsetenv("ZHULONG_CORS_PUBLIC_STREAMING")
+	config.applyEnvironmentOverrides()
+	assert.True(t, config.CORS.PublicStreaming)
+	_, err = config.GetCORSPolicy()
+	require.NoError(t, err)
+
+	config.CORS.AllowedOrigins = nil
+	err = config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "允许的来源不能为空")
+
+	config.CORS.AllowedOrigins = []string{"https://example.com/app"}
+	err = config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "不能包含路径")
+
+	config.CORS = CORSConfig{Mode: "any", MaxAge: "12h"}
+	err = config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "off、lan或strict")
+
+	config.CORS = CORSConfig{Mode: "lan", MaxAge: "soon"}
+	err = config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "预检缓存时长")
+}
+
+// TestConfig_Buckets 测试按内容类别选择存储桶
+func TestConfig_Buckets(t *testing.T) {
+	config := &Config{
+		MinIO: MinIOConfig{Bucket: "zhulong-videos"},
+		Storage: StorageConfig{Buckets: BucketsConfig{
+			Thumbnails: " zhulong-images ",
+			Renditions: "zhulong-hls",
+		}},
+	}
+
+	resolver := config.GetBucketResolver()
+	assert.Equal(t, "zhulong-videos", resolver.Bucket(storage.ContentVideos), "未配置的类别应该使用minio.bucket")
+	assert.Equal(t, "zhulong-images", resolver.Bucket(storage.ContentThumbnails))
+	assert.Equal(t, "zhulong-hls", resolver.Bucket(storage.ContentRenditions))
+	assert.Equal(t, "zhulong-videos", resolver.Bucket(storage.ContentSubtitles))
+
+	os.Setenv("ZHULONG_STORAGE_BUCKET_SUBTITLES", "zhulong-subtitles")
+	defer os.Unsetenv("ZHULONG_STORAGE_BUCKET_SUBTITLES")
+	config.applyEnvironmentOverrides()
+	assert.Equal(t, "zhulong-subtitles", config.GetBucketResolver().Bucket(storage.ContentSubtitles))
+
+	config.Server = ServerConfig{Host: "localhost", Port: 8080}
+	config.Storage.Driver = "local"
+	config.Storage.Local.RootDir = "/tmp"
+	config.Archive = ArchiveConfig{Enabled: true, Bucket: "zhulong-hls", IdleAfter: "30d", CheckInterval: "1h"}
+	err := config.Validate()
+	require.Error(t, err, "归档存储桶与其他内容的存储桶相同时应该验证失败")
+	assert.Contains(t, err.Error(), "归档存储桶")
+}
+
+// TestConfig_StorageDriver 测试存储驱动配置验证和转换
+func TestConfig_StorageDriver(t *testing.T) {
+	config := &Config{
+		Server: ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{
+			Driver: "local",
+			Local:  LocalStorageConfig{RootDir: "/var/lib/zhulong"},
+		},
+	}
+	assert.NoError(t, config.Validate(), "本地驱动不需要MinIO配置")
+
+	driverConfig := config.GetDriverConfig()
+	assert.Equal(t, "local", driverConfig.Driver)
+	assert.Equal(t, "/var/lib/zhulong", driverConfig.Local.RootDir)
+	assert.Equal(t, "http://localhost:8080/storage", driverConfig.Local.BaseURL, "未配置访问地址时应该根据服务器地址生成")
+
+	t.Setenv("ZHULONG_STORAGE_DELETE_CONCURRENCY", "16")
+	config.applyEnvironmentOverrides()
+	assert.Equal(t, 16, config.Storage.DeleteConcurrency, "环境变量应该覆盖批量删除并发数")
+
+	config.Storage.DeleteConcurrency = -1
+	err := config.Validate()
+	require.Error(t, err, "批量删除并发数不能为负数")
+	assert.Contains(t, err.Error(), "批量删除并发数")
+	config.Storage.DeleteConcurrency = 0
+
+	t.Setenv("ZHULONG_STORAGE_PREFIX_DELETE_MAX_OBJECTS", "500")
+	config.applyEnvironmentOverrides()
+	assert.Equal(t, 500, config.Storage.PrefixDeleteMaxObjects, "环境变量应该覆盖按前缀删除的文件数上限")
+	config.Storage.PrefixDeleteMaxObjects = -1
+	err = config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "按前缀删除的文件数上限")
+	config.Storage.PrefixDeleteMaxObjects = 0
+
+	config.Storage.Driver = "s3"
+	err = config.Validate()
+	require.Error(t, err, "S3驱动缺少密钥时应该验证失败")
+	assert.Contains(t, err.Error(), "S3访问密钥")
+	assert.NotContains(t, err.Error(), "MinIO端点", "S3驱动不应该验证MinIO配置")
+}
+
+// TestParseSize 测试大小解析
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"10GB", 10 << 30},
+		{"500mb", 500 << 20},
+		{"1.5 KB", 1536},
+		{"2TB", 2 << 40},
+		{"1024", 1024},
+		{"100B", 100},
+		{"0", 0},
+	}
+	for _, tt := range tests {
+		size, err := ParseSize(tt.input)
+		require.NoError(t, err, "解析%s应该成功", tt.input)
+		assert.Equal(t, tt.expected, size, "解析%s结果不正确", tt.input)
+	}
+
+	for _, invalid := range []string{"", "abc", "-1GB", "10XB"} {
+		_, err := ParseSize(invalid)
+		assert.Error(t, err, "解析%q应该失败", invalid)
+	}
+
+	config := &Config{
+		Server: ServerConfig{Host: "localhost", Port: 8080},
+		Storage: StorageConfig{Driver: "local", Local: LocalStorageConfig{RootDir: "/tmp"}},
+		Quota:  QuotaConfig{UserLimit: "lots"},
+	}
+	err := config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "用户存储配额")
+}
+
+// TestParseDuration 测试时长解析
+func TestParseDuration(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"24h", 24 * time.Hour},
+		{"30m", 30 * time.Minute},
+		{"7d", 7 * 24 * time.Hour},
+	}
+	
+	for _, tc := range testCases {
+		duration, err := ParseDuration(tc.value)
+		assert.NoError(t, err, "解析%s应该成功", tc.value)
+		assert.Equal(t, tc.expected, duration)
+	}
+	
+	_, err := ParseDuration("abc")
+	assert.Error(t, err, "无效时长应该返回错误")
+	_, err = ParseDuration("xd")
+	assert.Error(t, err, "无效天数应该返回错误")
+	
+	config := &Config{JWT: JWTConfig{Expire: "7d"}}
+	expiry, err := config.GetJWTExpiry()
+	assert.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, expiry)
 }
 
 // TestConfig_GetStorageConfig 测试获取存储配置
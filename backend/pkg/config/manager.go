@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"sync"
 	
 	"github.com/manteia/zhulong/pkg/storage"
 )
@@ -36,11 +37,17 @@ type WatcherInterface interface {
 	Stop() error
 }
 
+// ChangeHandler 配置变更处理函数，previous为变更前的配置
+type ChangeHandler func(previous, current *Config)
+
 // Manager 配置管理器
 type Manager struct {
 	config     *Config
 	configFile string
 	watcher    WatcherInterface
+	handlers   []ChangeHandler
+	stopCh     chan struct{}
+	mutex      sync.RWMutex
 }
 
 // NewManager 创建配置管理器
@@ -57,44 +64,72 @@ func (m *Manager) Load() error {
 		return err
 	}
 	
+	return m.Apply(config)
+}
+
+// Apply 验证并替换当前配置，替换前已有配置时按订阅顺序通知订阅者
+// 验证失败时保留当前配置
+func (m *Manager) Apply(config *Config) error {
 	if err := config.Validate(); err != nil {
 		return err
 	}
 	
+	m.mutex.Lock()
+	previous := m.config
 	m.config = config
+	handlers := m.handlers
+	m.mutex.Unlock()
+	
+	if previous != nil {
+		for _, handler := range handlers {
+			handler(previous, config)
+		}
+	}
 	return nil
 }
 
+// Subscribe 订阅配置变更，重新加载的配置通过验证后调用
+// 处理函数在监听协程中同步执行，需要尽快返回
+func (m *Manager) Subscribe(handler ChangeHandler) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.handlers = append(m.handlers, handler)
+}
+
 // Validate 验证配置
 func (m *Manager) Validate() error {
-	if m.config == nil {
+	config := m.GetConfig()
+	if config == nil {
 		return fmt.Errorf("配置未加载")
 	}
-	return m.config.Validate()
+	return config.Validate()
 }
 
 // GetStorageConfig 获取存储配置
 func (m *Manager) GetStorageConfig() storage.Config {
-	if m.config == nil {
+	config := m.GetConfig()
+	if config == nil {
 		return nil
 	}
-	return m.config.GetStorageConfig()
+	return config.GetStorageConfig()
 }
 
 // GetServerConfig 获取服务器配置
 func (m *Manager) GetServerConfig() ServerConfig {
-	if m.config == nil {
+	config := m.GetConfig()
+	if config == nil {
 		return ServerConfig{}
 	}
-	return m.config.Server
+	return config.Server
 }
 
 // GetAppConfig 获取应用配置
 func (m *Manager) GetAppConfig() AppConfig {
-	if m.config == nil {
+	config := m.GetConfig()
+	if config == nil {
 		return AppConfig{}
 	}
-	return m.config.App
+	return config.App
 }
 
 // Reload 重新加载配置
@@ -104,17 +139,58 @@ func (m *Manager) Reload() error {
 
 // StartWatching 启动配置监听
 func (m *Manager) StartWatching(changes chan<- *Config) error {
+	return m.startWatching(changes, nil)
+}
+
+// startWatching 启动配置监听，配置文件无法加载时调用onError
+func (m *Manager) startWatching(changes chan<- *Config, onError func(error)) error {
 	watcher, err := NewConfigWatcher(m.configFile)
 	if err != nil {
 		return err
 	}
+	watcher.SetErrorHandler(onError)
 	
 	m.watcher = watcher
 	return watcher.Watch(changes)
 }
 
+// StartAutoReload 监听配置文件，修改后自动重新加载并通知订阅者
+// 新配置无法解析或验证失败时保留当前配置，错误交给onError处理
+func (m *Manager) StartAutoReload(onError func(error)) error {
+	changes := make(chan *Config)
+	if err := m.startWatching(changes, onError); err != nil {
+		return err
+	}
+	
+	stopCh := make(chan struct{})
+	m.mutex.Lock()
+	m.stopCh = stopCh
+	m.mutex.Unlock()
+	
+	go func() {
+		for {
+			select {
+			case config := <-changes:
+				if err := m.Apply(config); err != nil && onError != nil {
+					onError(err)
+				}
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
 // StopWatching 停止配置监听
 func (m *Manager) StopWatching() error {
+	m.mutex.Lock()
+	if m.stopCh != nil {
+		close(m.stopCh)
+		m.stopCh = nil
+	}
+	m.mutex.Unlock()
+	
 	if m.watcher == nil {
 		return nil
 	}
@@ -123,5 +199,7 @@ func (m *Manager) StopWatching() error {
 
 // GetConfig 获取当前配置
 func (m *Manager) GetConfig() *Config {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
 	return m.config
 }
\ No newline at end of file
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -225,6 +226,61 @@ app:
 	}
 }
 
+// TestManager_AutoReload 测试自动重新加载配置并通知订阅者
+func TestManager_AutoReload(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "reload.yml")
+	writeConfig := func(debug bool, logLevel string) {
+		yamlContent := fmt.Sprintf(`
+server:
+  port: 8000
+minio:
+  endpoint: "localhost:9000"
+  access_key: "minioadmin"
+  secret_key: "minioadmin"
+app:
+  debug: %t
+  log_level: %q
+`, debug, logLevel)
+		require.NoError(t, os.WriteFile(configFile, []byte(yamlContent), 0644))
+	}
+	writeConfig(false, "")
+	
+	manager := NewManager(configFile)
+	require.NoError(t, manager.Load())
+	
+	changes := make(chan [2]*Config, 1)
+	manager.Subscribe(func(previous, current *Config) {
+		changes <- [2]*Config{previous, current}
+	})
+	errs := make(chan error, 1)
+	require.NoError(t, manager.StartAutoReload(func(err error) { errs <- err }))
+	defer func() {
+		assert.NoError(t, manager.StopWatching())
+	}()
+	
+	writeConfig(true, "")
+	select {
+	case change := <-changes:
+		assert.False(t, change[0].App.Debug, "应该传入变更前的配置")
+		assert.True(t, change[1].App.Debug)
+		assert.Same(t, change[1], manager.GetConfig())
+	case <-time.After(2 * time.Second):
+		t.Fatal("超时：未接收到配置变更通知")
+	}
+	
+	// 无效配置不替换当前配置，也不通知订阅者
+	writeConfig(false, "verbose")
+	select {
+	case err := <-errs:
+		assert.Contains(t, err.Error(), "日志级别无效")
+	case <-time.After(2 * time.Second):
+		t.Fatal("超时：未接收到配置错误")
+	}
+	assert.True(t, manager.GetAppConfig().Debug, "验证失败时应该保留当前配置")
+	assert.Empty(t, changes)
+}
+
 // TestManager_UnloadedConfig 测试未加载配置时的行为
 func TestManager_UnloadedConfig(t *testing.T) {
 	manager := NewManager("non-existent.yml")
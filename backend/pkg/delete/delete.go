@@ -2,17 +2,41 @@ package delete
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+
 	"github.com/manteia/zhulong/pkg/storage"
 )
 
+// DefaultConcurrency 批量删除时检查文件的默认并发数
+const DefaultConcurrency = 8
+
+// DefaultMaxPrefixObjects 按前缀删除时默认最多删除的文件数
+const DefaultMaxPrefixObjects = 1000
+
+var (
+	// ErrConfirmationRequired 按前缀删除缺少确认令牌或令牌与当前匹配的文件不一致
+	ErrConfirmationRequired = errors.New("按前缀删除需要先预演并提供确认令牌")
+	// ErrTooManyObjects 按前缀匹配的文件数超过单次删除上限
+	ErrTooManyObjects = errors.New("匹配的文件数超过按前缀删除的上限")
+)
+
 // DeleteService 文件删除服务
 type DeleteService struct {
 	storage       storage.StorageInterface
 	maxBatchSize  int           // 批量删除最大文件数
 	deleteTimeout time.Duration // 删除操作超时时间
+	concurrency   int           // 批量删除时检查文件的并发数
+
+	maxPrefixObjects int           // 按前缀删除最多删除的文件数
+	auditRecorder    AuditRecorder // 按前缀删除的审计记录
 }
 
 // DeleteRequest 单文件删除请求
@@ -26,6 +50,7 @@ type DeleteResult struct {
 	BucketName   string    // 存储桶名
 	ObjectName   string    // 对象名
 	Success      bool      // 是否成功
+	NotFound     bool      // 文件是否不存在（失败时）
 	ErrorMessage string    // 错误信息（失败时）
 	DeletedAt    time.Time // 删除时间
 }
@@ -46,25 +71,86 @@ type BatchDeleteResult struct {
 }
 
 // PrefixDeleteRequest 按前缀删除请求
+// 需要先以DryRun预演获取确认令牌，再携带令牌执行删除，匹配的文件发生变化时令牌失效
 type PrefixDeleteRequest struct {
-	BucketName string // 存储桶名
-	Prefix     string // 文件前缀
+	BucketName   string // 存储桶名
+	Prefix       string // 文件前缀
+	DryRun       bool   // 只列出匹配的文件，不执行删除
+	ConfirmToken string // 预演返回的确认令牌
+	Operator     string // 操作人，写入审计记录
 }
 
 // PrefixDeleteResult 按前缀删除结果
 type PrefixDeleteResult struct {
+	DryRun       bool      // 是否为预演
+	MatchedCount int       // 匹配前缀的文件数量
+	MatchedFiles []string  // 匹配前缀的文件列表（预演时）
+	ConfirmToken string    // 执行删除所需的确认令牌（预演时）
 	DeletedCount int       // 删除的文件数量
 	DeletedFiles []string  // 删除的文件列表
 	ProcessedAt  time.Time // 处理时间
 }
 
+// PrefixDeleteAudit 按前缀删除的审计记录，在执行删除前写入
+type PrefixDeleteAudit struct {
+	BucketName   string    // 存储桶名
+	Prefix       string    // 文件前缀
+	Operator     string    // 操作人
+	ObjectCount  int       // 将要删除的文件数量
+	ConfirmToken string    // 确认令牌
+	RequestedAt  time.Time // 请求时间
+}
+
+// AuditRecorder 审计记录器，写入失败时不执行删除
+type AuditRecorder interface {
+	RecordPrefixDelete(ctx context.Context, audit *PrefixDeleteAudit) error
+}
+
+// logAuditRecorder 将审计记录写入日志的默认记录器
+type logAuditRecorder struct{}
+
+// RecordPrefixDelete 将按前缀删除的审计记录写入日志
+func (logAuditRecorder) RecordPrefixDelete(ctx context.Context, audit *PrefixDeleteAudit) error {
+	hlog.CtxWarnf(ctx, "prefix delete: bucket=%s prefix=%s operator=%s objects=%d token=%s",
+		audit.BucketName, audit.Prefix, audit.Operator, audit.ObjectCount, audit.ConfirmToken)
+	return nil
+}
+
 // NewDeleteService 创建删除服务
 func NewDeleteService(storage storage.StorageInterface) *DeleteService {
 	return &DeleteService{
 		storage:       storage,
 		maxBatchSize:  1000,             // 一次最多删除1000个文件
 		deleteTimeout: 30 * time.Second, // 30秒超时
+		concurrency:   DefaultConcurrency,
+
+		maxPrefixObjects: DefaultMaxPrefixObjects,
+		auditRecorder:    logAuditRecorder{},
+	}
+}
+
+// SetConcurrency 设置批量删除时检查文件的并发数，小于1时使用默认值
+func (s *DeleteService) SetConcurrency(concurrency int) {
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
 	}
+	s.concurrency = concurrency
+}
+
+// SetMaxPrefixObjects 设置按前缀删除最多删除的文件数，小于1时使用默认值
+func (s *DeleteService) SetMaxPrefixObjects(maxObjects int) {
+	if maxObjects < 1 {
+		maxObjects = DefaultMaxPrefixObjects
+	}
+	s.maxPrefixObjects = maxObjects
+}
+
+// SetAuditRecorder 设置按前缀删除的审计记录器，为nil时写入日志
+func (s *DeleteService) SetAuditRecorder(recorder AuditRecorder) {
+	if recorder == nil {
+		recorder = logAuditRecorder{}
+	}
+	s.auditRecorder = recorder
 }
 
 // DeleteFile 删除单个文件
@@ -105,37 +191,48 @@ func (s *DeleteService) DeleteFile(ctx context.Context, req *DeleteRequest) (*De
 }
 
 // DeleteMultipleFiles 批量删除文件
+// 使用协程池并发检查文件是否存在以区分不存在的文件，然后一次请求删除所有存在的文件
 func (s *DeleteService) DeleteMultipleFiles(ctx context.Context, req *BatchDeleteRequest) (*BatchDeleteResult, error) {
 	// 验证请求
 	if err := s.ValidateBatchDeleteRequest(req); err != nil {
 		return nil, err
 	}
 
+	// 文件存在时结果为空，稍后批量删除
 	results := make([]*DeleteResult, len(req.ObjectNames))
-	successCount := 0
-	failureCount := 0
-
-	// 逐个删除文件
-	for i, objectName := range req.ObjectNames {
-		deleteReq := &DeleteRequest{
+	s.forEachConcurrently(len(req.ObjectNames), func(i int) {
+		results[i] = s.checkFileExists(ctx, &DeleteRequest{
 			BucketName: req.BucketName,
-			ObjectName: objectName,
+			ObjectName: req.ObjectNames[i],
+		})
+	})
+
+	objectNames := make([]string, 0, len(results))
+	for i, result := range results {
+		if result == nil {
+			objectNames = append(objectNames, req.ObjectNames[i])
 		}
+	}
 
-		// 尝试删除单个文件
-		result, err := s.deleteSingleFile(ctx, deleteReq)
-		results[i] = result
+	if len(objectNames) > 0 {
+		failures, err := s.storage.DeleteFiles(ctx, req.BucketName, objectNames)
+		for i, result := range results {
+			if result != nil {
+				continue
+			}
+
+			deleteErr := err
+			if deleteErr == nil {
+				deleteErr = failures[req.ObjectNames[i]]
+			}
+			results[i] = newDeleteResult(&DeleteRequest{BucketName: req.BucketName, ObjectName: req.ObjectNames[i]}, deleteErr)
+		}
+	}
 
+	successCount := 0
+	for _, result := range results {
 		if result.Success {
 			successCount++
-		} else {
-			failureCount++
-		}
-
-		// 如果是验证错误，记录但继续处理其他文件
-		if err != nil && result.Success == false {
-			// 已经在result中记录了错误信息
-			continue
 		}
 	}
 
@@ -143,14 +240,34 @@ func (s *DeleteService) DeleteMultipleFiles(ctx context.Context, req *BatchDelet
 		Results:      results,
 		TotalCount:   len(req.ObjectNames),
 		SuccessCount: successCount,
-		FailureCount: failureCount,
+		FailureCount: len(req.ObjectNames) - successCount,
 		ProcessedAt:  time.Now(),
 	}, nil
 }
 
-// deleteSingleFile 删除单个文件（内部方法，不进行请求验证）
-func (s *DeleteService) deleteSingleFile(ctx context.Context, req *DeleteRequest) (*DeleteResult, error) {
-	// 检查文件是否存在
+// forEachConcurrently 使用最多concurrency个协程对[0, count)中的每个索引执行task
+func (s *DeleteService) forEachConcurrently(count int, task func(i int)) {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < min(s.concurrency, count); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				task(i)
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// checkFileExists 检查待删除的文件是否存在，存在时返回nil，否则返回失败的删除结果
+func (s *DeleteService) checkFileExists(ctx context.Context, req *DeleteRequest) *DeleteResult {
 	exists, err := s.storage.FileExists(ctx, req.BucketName, req.ObjectName)
 	if err != nil {
 		return &DeleteResult{
@@ -159,7 +276,7 @@ func (s *DeleteService) deleteSingleFile(ctx context.Context, req *DeleteRequest
 			Success:      false,
 			ErrorMessage: fmt.Sprintf("检查文件存在性失败: %v", err),
 			DeletedAt:    time.Now(),
-		}, nil // 返回nil错误，因为错误已记录在result中
+		}
 	}
 
 	if !exists {
@@ -167,13 +284,17 @@ func (s *DeleteService) deleteSingleFile(ctx context.Context, req *DeleteRequest
 			BucketName:   req.BucketName,
 			ObjectName:   req.ObjectName,
 			Success:      false,
+			NotFound:     true,
 			ErrorMessage: "文件不存在",
 			DeletedAt:    time.Now(),
-		}, nil
+		}
 	}
 
-	// 删除文件
-	err = s.storage.DeleteFile(ctx, req.BucketName, req.ObjectName)
+	return nil
+}
+
+// newDeleteResult 根据删除错误创建删除结果
+func newDeleteResult(req *DeleteRequest, err error) *DeleteResult {
 	if err != nil {
 		return &DeleteResult{
 			BucketName:   req.BucketName,
@@ -181,7 +302,7 @@ func (s *DeleteService) deleteSingleFile(ctx context.Context, req *DeleteRequest
 			Success:      false,
 			ErrorMessage: fmt.Sprintf("删除文件失败: %v", err),
 			DeletedAt:    time.Now(),
-		}, nil
+		}
 	}
 
 	return &DeleteResult{
@@ -190,10 +311,11 @@ func (s *DeleteService) deleteSingleFile(ctx context.Context, req *DeleteRequest
 		Success:      true,
 		ErrorMessage: "",
 		DeletedAt:    time.Now(),
-	}, nil
+	}
 }
 
 // DeleteFilesByPrefix 按前缀删除文件
+// 预演时返回匹配的文件和确认令牌；执行删除时校验令牌和文件数上限，并在删除前写入审计记录
 func (s *DeleteService) DeleteFilesByPrefix(ctx context.Context, req *PrefixDeleteRequest) (*PrefixDeleteResult, error) {
 	// 验证请求
 	if err := s.validatePrefixDeleteRequest(req); err != nil {
@@ -206,45 +328,89 @@ func (s *DeleteService) DeleteFilesByPrefix(ctx context.Context, req *PrefixDele
 		return nil, fmt.Errorf("列出文件失败: %w", err)
 	}
 
-	if len(files) == 0 {
+	objectNames := make([]string, len(files))
+	for i, file := range files {
+		objectNames[i] = file.Key
+	}
+	confirmToken := prefixDeleteToken(req.BucketName, req.Prefix, objectNames)
+
+	if req.DryRun {
 		return &PrefixDeleteResult{
-			DeletedCount: 0,
+			DryRun:       true,
+			MatchedCount: len(objectNames),
+			MatchedFiles: objectNames,
+			ConfirmToken: confirmToken,
 			DeletedFiles: []string{},
 			ProcessedAt:  time.Now(),
 		}, nil
 	}
 
-	// 批量删除匹配的文件
-	objectNames := make([]string, len(files))
-	for i, file := range files {
-		objectNames[i] = file.Key
+	if len(objectNames) == 0 {
+		return &PrefixDeleteResult{
+			DeletedCount: 0,
+			DeletedFiles: []string{},
+			ProcessedAt:  time.Now(),
+		}, nil
 	}
 
-	batchRequest := &BatchDeleteRequest{
-		BucketName:  req.BucketName,
-		ObjectNames: objectNames,
+	if len(objectNames) > s.maxPrefixObjects {
+		return nil, fmt.Errorf("%w: 匹配%d个文件，最多%d个", ErrTooManyObjects, len(objectNames), s.maxPrefixObjects)
+	}
+	if req.ConfirmToken != confirmToken {
+		return nil, ErrConfirmationRequired
 	}
 
-	batchResult, err := s.DeleteMultipleFiles(ctx, batchRequest)
-	if err != nil {
-		return nil, fmt.Errorf("批量删除失败: %w", err)
+	// 删除前写入审计记录，写入失败时不执行删除
+	if err := s.auditRecorder.RecordPrefixDelete(ctx, &PrefixDeleteAudit{
+		BucketName:   req.BucketName,
+		Prefix:       req.Prefix,
+		Operator:     req.Operator,
+		ObjectCount:  len(objectNames),
+		ConfirmToken: confirmToken,
+		RequestedAt:  time.Now(),
+	}); err != nil {
+		return nil, fmt.Errorf("写入审计记录失败: %w", err)
 	}
 
-	// 统计成功删除的文件
-	deletedFiles := make([]string, 0, batchResult.SuccessCount)
-	for _, result := range batchResult.Results {
-		if result.Success {
-			deletedFiles = append(deletedFiles, result.ObjectName)
+	// 文件刚刚列出，无需检查是否存在，按单次批量删除的上限分批删除
+	deletedFiles := make([]string, 0, len(objectNames))
+	for start := 0; start < len(objectNames); start += s.maxBatchSize {
+		batch := objectNames[start:min(start+s.maxBatchSize, len(objectNames))]
+
+		failures, err := s.storage.DeleteFiles(ctx, req.BucketName, batch)
+		if err != nil {
+			return nil, fmt.Errorf("批量删除失败: %w", err)
+		}
+
+		// 统计成功删除的文件
+		for _, objectName := range batch {
+			if _, failed := failures[objectName]; !failed {
+				deletedFiles = append(deletedFiles, objectName)
+			}
 		}
 	}
 
 	return &PrefixDeleteResult{
-		DeletedCount: batchResult.SuccessCount,
+		MatchedCount: len(objectNames),
+		DeletedCount: len(deletedFiles),
 		DeletedFiles: deletedFiles,
 		ProcessedAt:  time.Now(),
 	}, nil
 }
 
+// prefixDeleteToken 根据存储桶、前缀和匹配的文件列表计算确认令牌，匹配的文件变化时令牌随之变化
+func prefixDeleteToken(bucketName, prefix string, objectNames []string) string {
+	sorted := slices.Clone(objectNames)
+	slices.Sort(sorted)
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00", bucketName, prefix)
+	for _, objectName := range sorted {
+		fmt.Fprintf(hash, "%s\x00", objectName)
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
 // ValidateDeleteRequest 验证删除请求
 func (s *DeleteService) ValidateDeleteRequest(req *DeleteRequest) error {
 	if req.BucketName == "" {
@@ -2,7 +2,11 @@ package delete
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -12,10 +16,6 @@ import (
 
 // TestDeleteService_DeleteFile 测试单文件删除
 func TestDeleteService_DeleteFile(t *testing.T) {
-	if !isStorageAvailable() {
-		t.Skip("跳过测试：MinIO存储服务不可用")
-	}
-
 	storageService := setupTestStorage(t)
 	deleteService := NewDeleteService(storageService)
 
@@ -64,10 +64,6 @@ func TestDeleteService_DeleteFile(t *testing.T) {
 
 // TestDeleteService_DeleteMultipleFiles 测试批量文件删除
 func TestDeleteService_DeleteMultipleFiles(t *testing.T) {
-	if !isStorageAvailable() {
-		t.Skip("跳过测试：MinIO存储服务不可用")
-	}
-
 	storageService := setupTestStorage(t)
 	deleteService := NewDeleteService(storageService)
 
@@ -138,10 +134,6 @@ func TestDeleteService_DeleteMultipleFiles(t *testing.T) {
 
 // TestDeleteService_DeleteFile_NotFound 测试删除不存在的文件
 func TestDeleteService_DeleteFile_NotFound(t *testing.T) {
-	if !isStorageAvailable() {
-		t.Skip("跳过测试：MinIO存储服务不可用")
-	}
-
 	storageService := setupTestStorage(t)
 	deleteService := NewDeleteService(storageService)
 
@@ -170,10 +162,6 @@ func TestDeleteService_DeleteFile_NotFound(t *testing.T) {
 
 // TestDeleteService_DeleteMultipleFiles_PartialFailure 测试批量删除部分失败
 func TestDeleteService_DeleteMultipleFiles_PartialFailure(t *testing.T) {
-	if !isStorageAvailable() {
-		t.Skip("跳过测试：MinIO存储服务不可用")
-	}
-
 	storageService := setupTestStorage(t)
 	deleteService := NewDeleteService(storageService)
 
@@ -212,6 +200,8 @@ func TestDeleteService_DeleteMultipleFiles_PartialFailure(t *testing.T) {
 	// 验证失败的文件有错误信息
 	assert.NotEmpty(t, results.Results[1].ErrorMessage, "失败时应该有错误信息")
 	assert.NotEmpty(t, results.Results[2].ErrorMessage, "失败时应该有错误信息")
+	assert.False(t, results.Results[0].NotFound, "删除成功的文件不应标记为不存在")
+	assert.True(t, results.Results[1].NotFound, "不存在的文件应该标记为不存在")
 
 	// 验证批量删除结果统计
 	assert.Equal(t, 3, results.TotalCount, "总数应该是3")
@@ -329,10 +319,6 @@ func TestDeleteService_ValidateBatchDeleteRequest(t *testing.T) {
 
 // TestDeleteService_DeleteFilesByPrefix 测试按前缀删除文件
 func TestDeleteService_DeleteFilesByPrefix(t *testing.T) {
-	if !isStorageAvailable() {
-		t.Skip("跳过测试：MinIO存储服务不可用")
-	}
-
 	storageService := setupTestStorage(t)
 	deleteService := NewDeleteService(storageService)
 
@@ -362,12 +348,18 @@ func TestDeleteService_DeleteFilesByPrefix(t *testing.T) {
 		require.NoError(t, err)
 	}
 
-	// 测试按前缀删除
+	// 测试按前缀删除，先预演获取确认令牌
 	prefixDeleteRequest := &PrefixDeleteRequest{
 		BucketName: bucketName,
 		Prefix:     "videos/2025/08/",
+		DryRun:     true,
 	}
+	preview, err := deleteService.DeleteFilesByPrefix(ctx, prefixDeleteRequest)
+	require.NoError(t, err, "预演应该成功")
+	assert.Equal(t, 2, preview.MatchedCount, "应该匹配2个文件")
 
+	prefixDeleteRequest.DryRun = false
+	prefixDeleteRequest.ConfirmToken = preview.ConfirmToken
 	result, err := deleteService.DeleteFilesByPrefix(ctx, prefixDeleteRequest)
 	assert.NoError(t, err, "按前缀删除应该成功")
 	require.NotNil(t, result, "删除结果不应为空")
@@ -394,38 +386,254 @@ func TestDeleteService_DeleteFilesByPrefix(t *testing.T) {
 	assert.True(t, exists4, "doc1.pdf应该仍然存在")
 }
 
-// isStorageAvailable 检查存储服务是否可用
-func isStorageAvailable() bool {
-	storageConfig := &storage.MinIOConfig{
-		Endpoint:  "localhost:9000",
-		AccessKey: "admin",
-		SecretKey: "admin123456",
-		UseSSL:    false,
-		Region:    "us-east-1",
+// fakeStorage 测试用内存存储，记录同时进行的存在性检查数和批量删除请求
+type fakeStorage struct {
+	storage.StorageInterface
+	mutex       sync.Mutex
+	objects     map[string]bool
+	failOnKey   string
+	inFlight    int
+	maxInFlight int
+	batchCalls  [][]string
+}
+
+// newFakeStorage 创建包含指定对象的内存存储
+func newFakeStorage(keys ...string) *fakeStorage {
+	store := &fakeStorage{objects: make(map[string]bool)}
+	for _, key := range keys {
+		store.objects[key] = true
+	}
+	return store
+}
+
+func (f *fakeStorage) FileExists(ctx context.Context, bucketName, objectName string) (bool, error) {
+	f.mutex.Lock()
+	f.inFlight++
+	f.maxInFlight = max(f.maxInFlight, f.inFlight)
+	f.mutex.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.inFlight--
+	return f.objects[objectName], nil
+}
+
+func (f *fakeStorage) DeleteFile(ctx context.Context, bucketName, objectName string) error {
+	return fmt.Errorf("批量删除不应该逐个删除文件")
+}
+
+func (f *fakeStorage) DeleteFiles(ctx context.Context, bucketName string, objectNames []string) (map[string]error, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.batchCalls = append(f.batchCalls, objectNames)
+	failures := make(map[string]error)
+	for _, objectName := range objectNames {
+		if objectName == f.failOnKey {
+			failures[objectName] = fmt.Errorf("模拟删除失败")
+			continue
+		}
+		delete(f.objects, objectName)
 	}
+	return failures, nil
+}
 
-	storageService, err := storage.NewMinIOStorage(storageConfig)
-	if err != nil {
-		return false
+func (f *fakeStorage) ListFiles(ctx context.Context, bucketName, prefix string) ([]*storage.FileInfo, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var files []*storage.FileInfo
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			files = append(files, &storage.FileInfo{Key: key})
+		}
 	}
+	return files, nil
+}
 
-	ctx := context.Background()
-	err = storageService.TestConnection(ctx)
-	return err == nil
+// testObjectNames 生成测试对象名
+func testObjectNames(count int) []string {
+	names := make([]string, count)
+	for i := range names {
+		names[i] = fmt.Sprintf("hls/video1/segment_%05d.ts", i)
+	}
+	return names
 }
 
-// setupTestStorage 设置测试存储服务
-func setupTestStorage(t *testing.T) storage.StorageInterface {
-	storageConfig := &storage.MinIOConfig{
-		Endpoint:  "localhost:9000",
-		AccessKey: "admin",
-		SecretKey: "admin123456",
-		UseSSL:    false,
-		Region:    "us-east-1",
+// TestDeleteService_DeleteMultipleFilesInOneRequest 测试并发检查文件后一次请求批量删除
+func TestDeleteService_DeleteMultipleFilesInOneRequest(t *testing.T) {
+	objectNames := testObjectNames(20)
+	store := newFakeStorage(objectNames...)
+	store.failOnKey = objectNames[3]
+	deleteService := NewDeleteService(store)
+	deleteService.SetConcurrency(4)
+
+	result, err := deleteService.DeleteMultipleFiles(context.Background(), &BatchDeleteRequest{
+		BucketName:  "test-bucket",
+		ObjectNames: append([]string{"missing.ts"}, objectNames...),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, store.batchCalls, 1, "应该只发起一次批量删除请求")
+	assert.Equal(t, objectNames, store.batchCalls[0], "只应该批量删除存在的文件")
+	assert.LessOrEqual(t, store.maxInFlight, 4, "并发数不应该超过设置值")
+	assert.Greater(t, store.maxInFlight, 1, "应该并发检查文件是否存在")
+
+	// 结果顺序与请求一致
+	for i, objectName := range objectNames {
+		assert.Equal(t, objectName, result.Results[i+1].ObjectName)
 	}
+	assert.True(t, result.Results[0].NotFound, "不存在的文件应该标记为不存在")
+	assert.False(t, result.Results[4].Success, "批量删除失败的文件应该记录错误")
+	assert.Contains(t, result.Results[4].ErrorMessage, "模拟删除失败")
+	assert.Equal(t, 21, result.TotalCount)
+	assert.Equal(t, 19, result.SuccessCount)
+	assert.Equal(t, 2, result.FailureCount)
+	assert.Len(t, store.objects, 1, "只应该保留删除失败的文件")
+}
+
+// TestDeleteService_DeleteFilesByPrefixInBatches 测试按前缀删除超过单次批量上限的文件
+func TestDeleteService_DeleteFilesByPrefixInBatches(t *testing.T) {
+	store := newFakeStorage(append(testObjectNames(5), "hls/video2/master.m3u8")...)
+	store.failOnKey = "hls/video1/segment_00002.ts"
+	deleteService := NewDeleteService(store)
+	deleteService.maxBatchSize = 2
+
+	req := &PrefixDeleteRequest{
+		BucketName: "test-bucket",
+		Prefix:     "hls/video1/",
+		DryRun:     true,
+	}
+	preview, err := deleteService.DeleteFilesByPrefix(context.Background(), req)
+	require.NoError(t, err)
 
-	storageService, err := storage.NewMinIOStorage(storageConfig)
+	req.DryRun = false
+	req.ConfirmToken = preview.ConfirmToken
+	result, err := deleteService.DeleteFilesByPrefix(context.Background(), req)
 	require.NoError(t, err)
+	assert.Equal(t, 4, result.DeletedCount)
+	assert.NotContains(t, result.DeletedFiles, store.failOnKey, "删除失败的文件不应该计入")
+	assert.Len(t, store.batchCalls, 3, "应该按批量上限分3次删除")
+	assert.Zero(t, store.maxInFlight, "按前缀删除不需要检查文件是否存在")
+	assert.Equal(t, map[string]bool{"hls/video2/master.m3u8": true, store.failOnKey: true}, store.objects)
+}
+
+// fakeAuditRecorder 测试用审计记录器，记录写入的审计记录
+type fakeAuditRecorder struct {
+	audits []*PrefixDeleteAudit
+	err    error
+}
+
+// RecordPrefixDelete 记录按前缀删除的审计记录
+func (r *fakeAuditRecorder) RecordPrefixDelete(ctx context.Context, audit *PrefixDeleteAudit) error {
+	r.audits = append(r.audits, audit)
+	return r.err
+}
+
+// TestDeleteService_DeleteFilesByPrefixSafety 测试按前缀删除的预演、确认令牌、数量上限和审计记录
+func TestDeleteService_DeleteFilesByPrefixSafety(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("预演不删除文件", func(t *testing.T) {
+		store := newFakeStorage(testObjectNames(3)...)
+		deleteService := NewDeleteService(store)
+
+		result, err := deleteService.DeleteFilesByPrefix(ctx, &PrefixDeleteRequest{BucketName: "test-bucket", Prefix: "hls/", DryRun: true})
+		require.NoError(t, err)
+		assert.True(t, result.DryRun)
+		assert.Equal(t, 3, result.MatchedCount)
+		assert.ElementsMatch(t, testObjectNames(3), result.MatchedFiles)
+		assert.NotEmpty(t, result.ConfirmToken)
+		assert.Zero(t, result.DeletedCount)
+		assert.Len(t, store.objects, 3, "预演不应该删除文件")
+		assert.Empty(t, store.batchCalls)
+	})
+
+	t.Run("缺少或过期的确认令牌", func(t *testing.T) {
+		store := newFakeStorage(testObjectNames(3)...)
+		recorder := &fakeAuditRecorder{}
+		deleteService := NewDeleteService(store)
+		deleteService.SetAuditRecorder(recorder)
+
+		_, err := deleteService.DeleteFilesByPrefix(ctx, &PrefixDeleteRequest{BucketName: "test-bucket", Prefix: "hls/"})
+		assert.ErrorIs(t, err, ErrConfirmationRequired)
+
+		preview, err := deleteService.DeleteFilesByPrefix(ctx, &PrefixDeleteRequest{BucketName: "test-bucket", Prefix: "hls/", DryRun: true})
+		require.NoError(t, err)
+		_, err = deleteService.DeleteFilesByPrefix(ctx, &PrefixDeleteRequest{BucketName: "test-bucket", Prefix: "hls/video1/", ConfirmToken: preview.ConfirmToken})
+		assert.ErrorIs(t, err, ErrConfirmationRequired, "令牌不能用于其他前缀")
+
+		store.objects["hls/video9/master.m3u8"] = true
+		_, err = deleteService.DeleteFilesByPrefix(ctx, &PrefixDeleteRequest{BucketName: "test-bucket", Prefix: "hls/", ConfirmToken: preview.ConfirmToken})
+		assert.ErrorIs(t, err, ErrConfirmationRequired, "匹配的文件变化后令牌应该失效")
+
+		assert.Len(t, store.objects, 4, "未确认时不应该删除文件")
+		assert.Empty(t, recorder.audits, "未确认时不应该写入审计记录")
+	})
+
+	t.Run("超过数量上限", func(t *testing.T) {
+		store := newFakeStorage(testObjectNames(5)...)
+		deleteService := NewDeleteService(store)
+		deleteService.SetMaxPrefixObjects(3)
+
+		preview, err := deleteService.DeleteFilesByPrefix(ctx, &PrefixDeleteRequest{BucketName: "test-bucket", Prefix: "hls/", DryRun: true})
+		require.NoError(t, err)
+		assert.Equal(t, 5, preview.MatchedCount, "预演不受数量上限限制")
+
+		_, err = deleteService.DeleteFilesByPrefix(ctx, &PrefixDeleteRequest{BucketName: "test-bucket", Prefix: "hls/", ConfirmToken: preview.ConfirmToken})
+		assert.ErrorIs(t, err, ErrTooManyObjects)
+		assert.Len(t, store.objects, 5)
+
+		deleteService.SetMaxPrefixObjects(0)
+		assert.Equal(t, DefaultMaxPrefixObjects, deleteService.maxPrefixObjects, "小于1时应该使用默认值")
+	})
+
+	t.Run("删除前写入审计记录", func(t *testing.T) {
+		store := newFakeStorage(testObjectNames(3)...)
+		recorder := &fakeAuditRecorder{err: fmt.Errorf("audit unavailable")}
+		deleteService := NewDeleteService(store)
+		deleteService.SetAuditRecorder(recorder)
+		req := &PrefixDeleteRequest{BucketName: "test-bucket", Prefix: "hls/", DryRun: true, Operator: "admin"}
+		preview, err := deleteService.DeleteFilesByPrefix(ctx, req)
+		require.NoError(t, err)
+		req.DryRun = false
+		req.ConfirmToken = preview.ConfirmToken
+
+		_, err = deleteService.DeleteFilesByPrefix(ctx, req)
+		assert.Error(t, err)
+		assert.Len(t, store.objects, 3, "审计记录写入失败时不应该删除文件")
 
-	return storageService
+		recorder.err = nil
+		result, err := deleteService.DeleteFilesByPrefix(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.DeletedCount)
+		require.Len(t, recorder.audits, 2)
+		audit := recorder.audits[1]
+		assert.Equal(t, "test-bucket", audit.BucketName)
+		assert.Equal(t, "hls/", audit.Prefix)
+		assert.Equal(t, "admin", audit.Operator)
+		assert.Equal(t, 3, audit.ObjectCount)
+		assert.Equal(t, preview.ConfirmToken, audit.ConfirmToken)
+	})
+}
+
+// TestDeleteService_SetConcurrency 测试设置并发数
+func TestDeleteService_SetConcurrency(t *testing.T) {
+	deleteService := NewDeleteService(newFakeStorage())
+	assert.Equal(t, DefaultConcurrency, deleteService.concurrency)
+
+	deleteService.SetConcurrency(16)
+	assert.Equal(t, 16, deleteService.concurrency)
+
+	deleteService.SetConcurrency(0)
+	assert.Equal(t, DefaultConcurrency, deleteService.concurrency, "小于1时应该使用默认值")
+}
+
+// setupTestStorage 设置测试存储，使用内存存储，不依赖MinIO服务
+func setupTestStorage(t *testing.T) storage.StorageInterface {
+	store, err := storage.NewMemoryStorage(&storage.MemoryConfig{BaseURL: "http://localhost:8888/storage"})
+	require.NoError(t, err, "创建测试存储应该成功")
+	return store
 }
@@ -139,8 +139,8 @@ func (s *DownloadService) GeneratePresignedURL(ctx context.Context, req *Presign
 	}, nil
 }
 
-// GenerateDownloadURL 生成下载URL (GET方法的预签名URL)
-func (s *DownloadService) GenerateDownloadURL(req *DownloadURLRequest) (*DownloadURLResult, error) {
+// GenerateDownloadURL 生成下载URL (GET方法的预签名URL)，配置了存储对外访问地址时由存储驱动使用对外地址
+func (s *DownloadService) GenerateDownloadURL(ctx context.Context, req *DownloadURLRequest) (*DownloadURLResult, error) {
 	// 验证基本参数
 	if req.BucketName == "" {
 		return nil, fmt.Errorf("存储桶名不能为空")
@@ -155,14 +155,15 @@ func (s *DownloadService) GenerateDownloadURL(req *DownloadURLRequest) (*Downloa
 		return nil, fmt.Errorf("过期时间不能超过%v", s.maxPresignedExpiry)
 	}
 
-	// 这里简化实现，直接构造一个模拟的下载URL
-	// 在实际实现中，这会调用MinIO的预签名URL生成
-	downloadURL := fmt.Sprintf("http://localhost:9000/%s/%s?expires=%d",
-		req.BucketName, req.ObjectName, time.Now().Add(req.ExpiresIn).Unix())
+	expiresAt := time.Now().Add(req.ExpiresIn)
+	downloadURL, err := s.storage.GetPresignedURL(ctx, req.BucketName, req.ObjectName, req.ExpiresIn)
+	if err != nil {
+		return nil, fmt.Errorf("生成预签名URL失败: %w", err)
+	}
 
 	return &DownloadURLResult{
 		DownloadURL: downloadURL,
-		ExpiresAt:   time.Now().Add(req.ExpiresIn),
+		ExpiresAt:   expiresAt,
 		BucketName:  req.BucketName,
 		ObjectName:  req.ObjectName,
 	}, nil
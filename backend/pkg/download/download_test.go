@@ -2,6 +2,7 @@ package download
 
 import (
 	"context"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
@@ -374,24 +375,45 @@ func TestDownloadService_FileNotFound(t *testing.T) {
 
 // TestDownloadService_GenerateDownloadURL 测试生成下载URL
 func TestDownloadService_GenerateDownloadURL(t *testing.T) {
-	downloadService := NewDownloadService(nil)
+	publicEndpoint, err := storage.ParsePublicEndpoint("https://cdn.example.com/zhulong", "")
+	require.NoError(t, err)
+	store, err := storage.NewLocalStorage(&storage.LocalConfig{RootDir: t.TempDir(), BaseURL: "http://10.0.0.5:8080/storage", PublicEndpoint: publicEndpoint})
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, store.CreateBucket(ctx, streamTestBucket))
+	downloadService := NewDownloadService(store)
+	_, err = store.UploadFile(ctx, streamTestBucket, "videos/2025/08/uuid-test.mp4", []byte("0123456789"), "video/mp4")
+	require.NoError(t, err)
 
 	// 测试生成下载URL
 	request := &DownloadURLRequest{
-		BucketName: "test-bucket",
+		BucketName: streamTestBucket,
 		ObjectName: "videos/2025/08/uuid-test.mp4",
 		ExpiresIn:  24 * time.Hour,
 	}
 
-	result, err := downloadService.GenerateDownloadURL(request)
+	result, err := downloadService.GenerateDownloadURL(ctx, request)
 	assert.NoError(t, err, "生成下载URL应该成功")
 	require.NotNil(t, result, "下载URL结果不应为空")
 
 	// 验证结果
-	assert.NotEmpty(t, result.DownloadURL, "下载URL不应为空")
+	presignedURL, err := store.GetPresignedURL(ctx, request.BucketName, request.ObjectName, request.ExpiresIn)
+	require.NoError(t, err)
+	presigned, err := url.Parse(presignedURL)
+	require.NoError(t, err)
+	downloadURL, err := url.Parse(result.DownloadURL)
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com", presigned.Scheme+"://"+presigned.Host, "存储驱动应该使用对外访问地址")
+	assert.Equal(t, presigned.Host, downloadURL.Host, "应该使用存储生成的预签名URL")
+	assert.Equal(t, "/zhulong/storage/"+streamTestBucket+"/videos/2025/08/uuid-test.mp4", downloadURL.Path)
+	assert.NotEmpty(t, downloadURL.Query().Get("signature"), "应该带有签名参数")
 	assert.Equal(t, request.BucketName, result.BucketName, "存储桶名应该匹配")
 	assert.Equal(t, request.ObjectName, result.ObjectName, "对象名应该匹配")
 	assert.True(t, result.ExpiresAt.After(time.Now()), "URL应该在未来过期")
+
+	request.ExpiresIn = 8 * 24 * time.Hour
+	_, err = downloadService.GenerateDownloadURL(ctx, request)
+	assert.Error(t, err, "过期时间不能超过7天")
 }
 
 // isStorageAvailable 检查存储服务是否可用
@@ -0,0 +1,79 @@
+package download
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrRangeNotSatisfiable 请求的字节范围超出文件大小
+var ErrRangeNotSatisfiable = errors.New("请求的范围无法满足")
+
+// ByteRange 文件字节范围，Start和End均包含在内
+type ByteRange struct {
+	Start int64 // 起始偏移量
+	End   int64 // 结束偏移量
+}
+
+// Length 获取范围长度
+func (r *ByteRange) Length() int64 {
+	return r.End - r.Start + 1
+}
+
+// ContentRange 生成Content-Range响应头
+func (r *ByteRange) ContentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.Start, r.End, size)
+}
+
+// ParseRange 解析HTTP Range请求头
+// 未携带Range、格式无效或包含多个范围时返回nil，调用方应返回完整文件；
+// 范围超出文件大小时返回ErrRangeNotSatisfiable
+func ParseRange(header string, size int64) (*ByteRange, error) {
+	header = strings.TrimSpace(header)
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return nil, nil
+	}
+
+	startStr, endStr, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return nil, nil
+	}
+
+	// 后缀范围：bytes=-500 表示最后500字节
+	if startStr == "" {
+		suffix, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || suffix < 0 {
+			return nil, nil
+		}
+		if suffix == 0 || size == 0 {
+			return nil, ErrRangeNotSatisfiable
+		}
+		if suffix > size {
+			suffix = size
+		}
+		return &ByteRange{Start: size - suffix, End: size - 1}, nil
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return nil, nil
+	}
+
+	end := size - 1
+	if endStr != "" {
+		end, err = strconv.ParseInt(endStr, 10, 64)
+		if err != nil || end < start {
+			return nil, nil
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+
+	if start >= size {
+		return nil, ErrRangeNotSatisfiable
+	}
+	return &ByteRange{Start: start, End: end}, nil
+}
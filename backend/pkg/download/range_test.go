@@ -0,0 +1,55 @@
+package download
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseRange 测试解析Range请求头
+func TestParseRange(t *testing.T) {
+	const size = 1000
+
+	tests := []struct {
+		name     string
+		header   string
+		expected *ByteRange
+	}{
+		{"完整范围", "bytes=0-499", &ByteRange{Start: 0, End: 499}},
+		{"开放结尾", "bytes=500-", &ByteRange{Start: 500, End: 999}},
+		{"后缀范围", "bytes=-100", &ByteRange{Start: 900, End: 999}},
+		{"后缀超过文件大小", "bytes=-2000", &ByteRange{Start: 0, End: 999}},
+		{"结尾超过文件大小", "bytes=900-5000", &ByteRange{Start: 900, End: 999}},
+		{"单字节", "bytes=0-0", &ByteRange{Start: 0, End: 0}},
+		{"未携带Range", "", nil},
+		{"不支持的单位", "items=0-10", nil},
+		{"多个范围", "bytes=0-10,20-30", nil},
+		{"格式错误", "bytes=abc-10", nil},
+		{"结尾小于起始", "bytes=10-5", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseRange(tt.header, size)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	_, err := ParseRange("bytes=1000-", size)
+	assert.ErrorIs(t, err, ErrRangeNotSatisfiable, "起始位置超出文件大小时应该返回错误")
+
+	_, err = ParseRange("bytes=-0", size)
+	assert.ErrorIs(t, err, ErrRangeNotSatisfiable, "空后缀范围应该返回错误")
+
+	_, err = ParseRange("bytes=0-", 0)
+	assert.ErrorIs(t, err, ErrRangeNotSatisfiable, "空文件无法满足任何范围")
+}
+
+// TestByteRange 测试字节范围辅助方法
+func TestByteRange(t *testing.T) {
+	r := &ByteRange{Start: 100, End: 199}
+	assert.Equal(t, int64(100), r.Length())
+	assert.Equal(t, "bytes 100-199/1000", r.ContentRange(1000))
+}
@@ -0,0 +1,181 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// FileStream 文件下载流，由调用方负责关闭Body
+type FileStream struct {
+	Body         io.ReadCloser // 文件内容读取流
+	Size         int64         // 文件总大小
+	Range        *ByteRange    // 请求的字节范围，为nil时返回完整文件
+	ContentType  string        // 内容类型
+	ETag         string        // 文件ETag，拼接多个对象时为空
+	LastModified time.Time     // 最后修改时间
+}
+
+// ContentLength 获取响应内容长度
+func (f *FileStream) ContentLength() int64 {
+	if f.Range != nil {
+		return f.Range.Length()
+	}
+	return f.Size
+}
+
+// OpenFile 打开文件下载流，根据Range请求头返回部分内容，支持断点续传
+// 范围超出文件大小时返回ErrRangeNotSatisfiable，同时返回只有Size的下载流，供调用方生成Content-Range
+func (s *DownloadService) OpenFile(ctx context.Context, req *DownloadRequest, rangeHeader string) (*FileStream, error) {
+	if err := s.ValidateDownloadRequest(req); err != nil {
+		return nil, err
+	}
+
+	fileInfo, err := s.storage.GetFileInfo(ctx, req.BucketName, req.ObjectName)
+	if err != nil {
+		return nil, fmt.Errorf("获取文件信息失败: %w", err)
+	}
+
+	byteRange, err := ParseRange(rangeHeader, fileInfo.Size)
+	if errors.Is(err, ErrRangeNotSatisfiable) {
+		return &FileStream{Size: fileInfo.Size}, err
+	}
+
+	offset, length := int64(0), int64(-1)
+	if byteRange != nil {
+		offset, length = byteRange.Start, byteRange.Length()
+	}
+	body, err := s.storage.OpenFileRange(ctx, req.BucketName, req.ObjectName, offset, length)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %w", err)
+	}
+
+	return &FileStream{
+		Body:         body,
+		Size:         fileInfo.Size,
+		Range:        byteRange,
+		ContentType:  fileInfo.ContentType,
+		ETag:         fileInfo.ETag,
+		LastModified: fileInfo.LastModified,
+	}, nil
+}
+
+// OpenObjects 按顺序拼接同一存储桶中的多个对象为一个下载流，如HLS档位的初始化分片和媒体分片
+// 对象在读取到时才打开，不支持Range请求
+func (s *DownloadService) OpenObjects(ctx context.Context, bucketName string, objectNames []string, contentType string) (*FileStream, error) {
+	if bucketName == "" {
+		return nil, fmt.Errorf("存储桶名不能为空")
+	}
+	if len(objectNames) == 0 {
+		return nil, fmt.Errorf("对象列表不能为空")
+	}
+
+	var size int64
+	var lastModified time.Time
+	for _, objectName := range objectNames {
+		fileInfo, err := s.storage.GetFileInfo(ctx, bucketName, objectName)
+		if err != nil {
+			return nil, fmt.Errorf("获取文件信息失败(%s): %w", objectName, err)
+		}
+		size += fileInfo.Size
+		if fileInfo.LastModified.After(lastModified) {
+			lastModified = fileInfo.LastModified
+		}
+	}
+
+	return &FileStream{
+		Body: &objectsReader{
+			ctx:        ctx,
+			service:    s,
+			bucketName: bucketName,
+			objects:    objectNames,
+		},
+		Size:         size,
+		ContentType:  contentType,
+		LastModified: lastModified,
+	}, nil
+}
+
+// objectsReader 依次读取多个对象的读取流
+type objectsReader struct {
+	ctx        context.Context
+	service    *DownloadService
+	bucketName string
+	objects    []string
+	current    io.ReadCloser
+}
+
+// Read 读取当前对象，读完后打开下一个对象
+func (r *objectsReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.objects) == 0 {
+				return 0, io.EOF
+			}
+			body, err := r.service.storage.OpenFileRange(r.ctx, r.bucketName, r.objects[0], 0, -1)
+			if err != nil {
+				return 0, fmt.Errorf("打开文件失败(%s): %w", r.objects[0], err)
+			}
+			r.current = body
+			r.objects = r.objects[1:]
+		}
+
+		n, err := r.current.Read(p)
+		if errors.Is(err, io.EOF) {
+			r.current.Close()
+			r.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+// Close 关闭正在读取的对象
+func (r *objectsReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
+
+// ContentDisposition 生成附件下载的Content-Disposition响应头
+// filename参数为替换了非ASCII字符的文件名，供不支持RFC 5987的客户端使用，filename*参数保留UTF-8原始文件名
+func ContentDisposition(filename string) string {
+	if filename == "" {
+		return "attachment"
+	}
+
+	var fallback strings.Builder
+	for _, r := range filename {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			fallback.WriteByte('_')
+			continue
+		}
+		fallback.WriteRune(r)
+	}
+
+	return fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, fallback.String(), encodeRFC5987(filename))
+}
+
+// encodeRFC5987 按RFC 5987对扩展参数值进行百分号编码
+func encodeRFC5987(value string) string {
+	const attrChars = "!#$&+-.^_`|~"
+
+	var encoded strings.Builder
+	for _, b := range []byte(value) {
+		if (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9') || strings.IndexByte(attrChars, b) >= 0 {
+			encoded.WriteByte(b)
+			continue
+		}
+		fmt.Fprintf(&encoded, "%%%02X", b)
+	}
+	return encoded.String()
+}
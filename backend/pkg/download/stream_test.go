@@ -0,0 +1,88 @@
+package download
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+const streamTestBucket = "stream-test"
+
+// setupStreamTestService 创建使用本地存储的下载服务
+func setupStreamTestService(t *testing.T) (*DownloadService, storage.StorageInterface) {
+	localStorage, err := storage.NewLocalStorage(&storage.LocalConfig{RootDir: t.TempDir()})
+	require.NoError(t, err)
+	require.NoError(t, localStorage.CreateBucket(context.Background(), streamTestBucket))
+	return NewDownloadService(localStorage), localStorage
+}
+
+// readStream 读取并关闭下载流
+func readStream(t *testing.T, stream *FileStream) []byte {
+	defer stream.Body.Close()
+	data, err := io.ReadAll(stream.Body)
+	require.NoError(t, err)
+	return data
+}
+
+// TestDownloadService_OpenFile 测试打开文件下载流
+func TestDownloadService_OpenFile(t *testing.T) {
+	service, store := setupStreamTestService(t)
+	ctx := context.Background()
+	data := []byte("0123456789")
+	_, err := store.UploadFile(ctx, streamTestBucket, "video.mp4", data, "video/mp4")
+	require.NoError(t, err)
+	req := &DownloadRequest{BucketName: streamTestBucket, ObjectName: "video.mp4"}
+
+	stream, err := service.OpenFile(ctx, req, "")
+	require.NoError(t, err)
+	assert.Nil(t, stream.Range)
+	assert.Equal(t, int64(10), stream.ContentLength())
+	assert.Equal(t, data, readStream(t, stream))
+
+	stream, err = service.OpenFile(ctx, req, "bytes=4-")
+	require.NoError(t, err)
+	require.NotNil(t, stream.Range)
+	assert.Equal(t, int64(6), stream.ContentLength())
+	assert.Equal(t, data[4:], readStream(t, stream), "应该从断点继续下载")
+
+	stream, err = service.OpenFile(ctx, req, "bytes=20-")
+	assert.ErrorIs(t, err, ErrRangeNotSatisfiable)
+	assert.Equal(t, int64(10), stream.Size, "范围无法满足时应该返回文件大小")
+
+	_, err = service.OpenFile(ctx, &DownloadRequest{BucketName: streamTestBucket, ObjectName: "missing.mp4"}, "")
+	assert.Error(t, err)
+}
+
+// TestDownloadService_OpenObjects 测试拼接多个对象的下载流
+func TestDownloadService_OpenObjects(t *testing.T) {
+	service, store := setupStreamTestService(t)
+	ctx := context.Background()
+	for name, content := range map[string]string{"init.mp4": "init-", "seg1.m4s": "first-", "seg2.m4s": "second"} {
+		_, err := store.UploadFile(ctx, streamTestBucket, name, []byte(content), "video/mp4")
+		require.NoError(t, err)
+	}
+
+	stream, err := service.OpenObjects(ctx, streamTestBucket, []string{"init.mp4", "seg1.m4s", "seg2.m4s"}, "video/mp4")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("init-first-second")), stream.Size)
+	assert.Equal(t, "video/mp4", stream.ContentType)
+	assert.Equal(t, "init-first-second", string(readStream(t, stream)), "应该按顺序拼接")
+
+	_, err = service.OpenObjects(ctx, streamTestBucket, []string{"init.mp4", "missing.m4s"}, "video/mp4")
+	assert.Error(t, err, "对象不存在时应该在打开时返回错误")
+	_, err = service.OpenObjects(ctx, streamTestBucket, nil, "video/mp4")
+	assert.Error(t, err)
+}
+
+// TestContentDisposition 测试附件下载响应头
+func TestContentDisposition(t *testing.T) {
+	assert.Equal(t, "attachment", ContentDisposition(""))
+	assert.Equal(t, `attachment; filename="holiday 2024.mp4"; filename*=UTF-8''holiday%202024.mp4`, ContentDisposition("holiday 2024.mp4"))
+	assert.Equal(t, `attachment; filename="__.mp4"; filename*=UTF-8''%E5%81%87%E6%9C%9F.mp4`, ContentDisposition("假期.mp4"), "非ASCII字符应该替换并使用UTF-8编码")
+	assert.Equal(t, `attachment; filename="a_b_.mp4"; filename*=UTF-8''a%22b%5C.mp4`, ContentDisposition(`a"b\.mp4`), "引号和反斜杠不能出现在带引号的文件名中")
+}
@@ -0,0 +1,84 @@
+package download
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ZipEntry 打包下载中的一个文件
+type ZipEntry struct {
+	BucketName string // 存储桶名
+	ObjectName string // 对象名
+	Name       string // 压缩包内的文件路径
+}
+
+// OpenZip 将多个对象打包为zip下载流，文件以存储方式写入不重新压缩，视频已经是压缩格式，再压缩只会浪费CPU
+// 压缩包在读取时边读取对象边生成，不占用额外的内存和磁盘；大小无法预先确定，返回的下载流Size为-1
+func (s *DownloadService) OpenZip(ctx context.Context, entries []ZipEntry) (*FileStream, error) {
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("打包文件列表不能为空")
+	}
+
+	// 打开前检查所有对象是否存在，避免响应开始后才发现文件缺失
+	headers := make([]*zip.FileHeader, len(entries))
+	names := make(map[string]bool, len(entries))
+	var lastModified time.Time
+	for i, entry := range entries {
+		if entry.Name == "" {
+			return nil, fmt.Errorf("压缩包内的文件名不能为空: %s", entry.ObjectName)
+		}
+		if names[entry.Name] {
+			return nil, fmt.Errorf("压缩包内的文件名重复: %s", entry.Name)
+		}
+		names[entry.Name] = true
+
+		fileInfo, err := s.storage.GetFileInfo(ctx, entry.BucketName, entry.ObjectName)
+		if err != nil {
+			return nil, fmt.Errorf("获取文件信息失败(%s): %w", entry.ObjectName, err)
+		}
+		headers[i] = &zip.FileHeader{
+			Name:     entry.Name,
+			Method:   zip.Store,
+			Modified: fileInfo.LastModified,
+		}
+		if fileInfo.LastModified.After(lastModified) {
+			lastModified = fileInfo.LastModified
+		}
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(s.writeZip(ctx, writer, entries, headers))
+	}()
+
+	return &FileStream{
+		Body:         reader,
+		Size:         -1,
+		ContentType:  "application/zip",
+		LastModified: lastModified,
+	}, nil
+}
+
+// writeZip 依次将对象写入压缩包，读取方关闭下载流后写入失败并停止
+func (s *DownloadService) writeZip(ctx context.Context, w io.Writer, entries []ZipEntry, headers []*zip.FileHeader) error {
+	archive := zip.NewWriter(w)
+	for i, entry := range entries {
+		fileWriter, err := archive.CreateHeader(headers[i])
+		if err != nil {
+			return fmt.Errorf("写入压缩包失败(%s): %w", entry.Name, err)
+		}
+		body, err := s.storage.OpenFileRange(ctx, entry.BucketName, entry.ObjectName, 0, -1)
+		if err != nil {
+			return fmt.Errorf("打开文件失败(%s): %w", entry.ObjectName, err)
+		}
+		_, err = io.Copy(fileWriter, body)
+		body.Close()
+		if err != nil {
+			return fmt.Errorf("写入压缩包失败(%s): %w", entry.Name, err)
+		}
+	}
+	return archive.Close()
+}
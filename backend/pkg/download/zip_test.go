@@ -0,0 +1,65 @@
+package download
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDownloadService_OpenZip 测试打包多个对象的zip下载流
+func TestDownloadService_OpenZip(t *testing.T) {
+	service, store := setupStreamTestService(t)
+	ctx := context.Background()
+	files := map[string]string{"video1.mp4": "0123456789", "video1/en.vtt": "WEBVTT\n", "video2.mov": "abcdef"}
+	for name, content := range files {
+		_, err := store.UploadFile(ctx, streamTestBucket, name, []byte(content), "application/octet-stream")
+		require.NoError(t, err)
+	}
+
+	entries := []ZipEntry{
+		{BucketName: streamTestBucket, ObjectName: "video1.mp4", Name: "假期.mp4"},
+		{BucketName: streamTestBucket, ObjectName: "video1/en.vtt", Name: "假期.en.vtt"},
+		{BucketName: streamTestBucket, ObjectName: "video2.mov", Name: "clip.mov"},
+	}
+	stream, err := service.OpenZip(ctx, entries)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), stream.ContentLength(), "压缩包大小无法预先确定")
+	assert.Equal(t, "application/zip", stream.ContentType)
+
+	data := readStream(t, stream)
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Len(t, archive.File, 3)
+	for i, file := range archive.File {
+		assert.Equal(t, entries[i].Name, file.Name, "应该按顺序写入")
+		assert.Equal(t, zip.Store, file.Method, "不应该重新压缩")
+		body, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(body)
+		body.Close()
+		require.NoError(t, err)
+		assert.Equal(t, files[entries[i].ObjectName], string(content))
+	}
+
+	t.Run("提前关闭下载流", func(t *testing.T) {
+		stream, err := service.OpenZip(ctx, entries)
+		require.NoError(t, err)
+		_, err = stream.Body.Read(make([]byte, 8))
+		require.NoError(t, err)
+		assert.NoError(t, stream.Body.Close())
+	})
+
+	t.Run("无效的打包列表", func(t *testing.T) {
+		_, err := service.OpenZip(ctx, nil)
+		assert.Error(t, err)
+		_, err = service.OpenZip(ctx, []ZipEntry{{BucketName: streamTestBucket, ObjectName: "missing.mp4", Name: "missing.mp4"}})
+		assert.Error(t, err, "对象不存在时应该在打开时返回错误")
+		_, err = service.OpenZip(ctx, []ZipEntry{entries[0], {BucketName: streamTestBucket, ObjectName: "video2.mov", Name: "假期.mp4"}})
+		assert.Error(t, err, "文件名重复")
+	})
+}
@@ -0,0 +1,131 @@
+package favorite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Favorite 用户收藏的视频
+type Favorite struct {
+	UserID      string    `json:"user_id"`      // 用户ID
+	VideoID     string    `json:"video_id"`     // 视频ID
+	FavoritedAt time.Time `json:"favorited_at"` // 收藏时间
+}
+
+// FavoriteService 视频收藏服务，按用户记录收藏的视频
+type FavoriteService struct {
+	favorites map[string]map[string]time.Time // 用户ID -> 视频ID -> 收藏时间
+	mutex     sync.RWMutex
+}
+
+// NewFavoriteService 创建视频收藏服务
+func NewFavoriteService() *FavoriteService {
+	return &FavoriteService{
+		favorites: make(map[string]map[string]time.Time),
+	}
+}
+
+// Add 收藏视频，已收藏时保留原收藏时间
+func (s *FavoriteService) Add(ctx context.Context, userID, videoID string) (*Favorite, error) {
+	if userID == "" || videoID == "" {
+		return nil, fmt.Errorf("用户ID和视频ID不能为空")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	videos, exists := s.favorites[userID]
+	if !exists {
+		videos = make(map[string]time.Time)
+		s.favorites[userID] = videos
+	}
+	favoritedAt, exists := videos[videoID]
+	if !exists {
+		favoritedAt = time.Now()
+		videos[videoID] = favoritedAt
+	}
+
+	return &Favorite{UserID: userID, VideoID: videoID, FavoritedAt: favoritedAt}, nil
+}
+
+// Remove 取消收藏，未收藏时不做处理
+func (s *FavoriteService) Remove(ctx context.Context, userID, videoID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	videos, exists := s.favorites[userID]
+	if !exists {
+		return
+	}
+	delete(videos, videoID)
+	if len(videos) == 0 {
+		delete(s.favorites, userID)
+	}
+}
+
+// Favorited 批量判断用户是否收藏了视频，未收藏的视频不包含在结果中
+func (s *FavoriteService) Favorited(ctx context.Context, userID string, videoIDs []string) map[string]bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	favorited := make(map[string]bool)
+	videos := s.favorites[userID]
+	for _, videoID := range videoIDs {
+		if _, exists := videos[videoID]; exists {
+			favorited[videoID] = true
+		}
+	}
+	return favorited
+}
+
+// List 列出用户收藏的视频，按收藏时间降序，limit小于等于0时不限制数量
+func (s *FavoriteService) List(ctx context.Context, userID string, limit int) []*Favorite {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	videos := s.favorites[userID]
+	favorites := make([]*Favorite, 0, len(videos))
+	for videoID, favoritedAt := range videos {
+		favorites = append(favorites, &Favorite{UserID: userID, VideoID: videoID, FavoritedAt: favoritedAt})
+	}
+
+	sort.Slice(favorites, func(i, j int) bool {
+		if !favorites[i].FavoritedAt.Equal(favorites[j].FavoritedAt) {
+			return favorites[i].FavoritedAt.After(favorites[j].FavoritedAt)
+		}
+		return favorites[i].VideoID < favorites[j].VideoID
+	})
+
+	if limit > 0 && len(favorites) > limit {
+		favorites = favorites[:limit]
+	}
+	return favorites
+}
+
+// RemoveVideo 删除所有用户对该视频的收藏，视频删除时调用
+func (s *FavoriteService) RemoveVideo(ctx context.Context, videoID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for userID, videos := range s.favorites {
+		delete(videos, videoID)
+		if len(videos) == 0 {
+			delete(s.favorites, userID)
+		}
+	}
+}
+
+// Count 统计全部用户的收藏记录数量
+func (s *FavoriteService) Count(ctx context.Context) int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	count := 0
+	for _, videos := range s.favorites {
+		count += len(videos)
+	}
+	return count
+}
@@ -0,0 +1,63 @@
+package favorite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFavoriteService_Add 测试收藏和取消收藏
+func TestFavoriteService_Add(t *testing.T) {
+	service := NewFavoriteService()
+	ctx := context.Background()
+
+	first, err := service.Add(ctx, "alice", "video1")
+	require.NoError(t, err, "收藏视频应该成功")
+	assert.Equal(t, "video1", first.VideoID)
+	assert.False(t, first.FavoritedAt.IsZero())
+
+	time.Sleep(time.Millisecond)
+	again, err := service.Add(ctx, "alice", "video1")
+	require.NoError(t, err, "重复收藏应该成功")
+	assert.Equal(t, first.FavoritedAt, again.FavoritedAt, "重复收藏应该保留原收藏时间")
+
+	_, err = service.Add(ctx, "", "video1")
+	assert.Error(t, err, "用户ID不能为空")
+
+	assert.Equal(t, map[string]bool{"video1": true}, service.Favorited(ctx, "alice", []string{"video1", "video2"}))
+	assert.Empty(t, service.Favorited(ctx, "bob", []string{"video1"}), "收藏应该按用户隔离")
+
+	service.Remove(ctx, "alice", "video1")
+	service.Remove(ctx, "alice", "video1")
+	assert.Empty(t, service.Favorited(ctx, "alice", []string{"video1"}), "取消收藏后不应该再标记为已收藏")
+}
+
+// TestFavoriteService_List 测试收藏列表
+func TestFavoriteService_List(t *testing.T) {
+	service := NewFavoriteService()
+	ctx := context.Background()
+
+	for _, videoID := range []string{"video1", "video2", "video3"} {
+		_, err := service.Add(ctx, "alice", videoID)
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+	}
+	_, err := service.Add(ctx, "bob", "video1")
+	require.NoError(t, err)
+
+	favorites := service.List(ctx, "alice", 0)
+	require.Len(t, favorites, 3)
+	assert.Equal(t, "video3", favorites[0].VideoID, "最近收藏的视频应该排在最前")
+	assert.Equal(t, "video1", favorites[2].VideoID)
+	assert.Len(t, service.List(ctx, "alice", 2), 2)
+	assert.Empty(t, service.List(ctx, "carol", 0))
+	assert.Equal(t, 4, service.Count(ctx), "应该统计全部用户的收藏记录")
+
+	service.RemoveVideo(ctx, "video1")
+	assert.Len(t, service.List(ctx, "alice", 0), 2, "删除视频后应该清除收藏")
+	assert.Empty(t, service.List(ctx, "bob", 0))
+	assert.Equal(t, 2, service.Count(ctx))
+}
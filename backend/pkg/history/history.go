@@ -0,0 +1,160 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CompletionRatio 播放进度达到视频时长的该比例时视为已看完，再次播放时从头开始
+const CompletionRatio = 0.95
+
+// ErrProgressNotFound 没有播放记录
+var ErrProgressNotFound = errors.New("播放记录不存在")
+
+// Progress 用户对某个视频的播放进度
+type Progress struct {
+	UserID        string    `json:"user_id"`         // 用户ID
+	VideoID       string    `json:"video_id"`        // 视频ID
+	Position      int64     `json:"position"`        // 播放位置（秒）
+	Completed     bool      `json:"completed"`       // 是否已看完
+	LastWatchedAt time.Time `json:"last_watched_at"` // 最后观看时间
+}
+
+// ResumePosition 续播位置（秒），已看完时从头开始
+func (p *Progress) ResumePosition() int64 {
+	if p.Completed {
+		return 0
+	}
+	return p.Position
+}
+
+// HistoryService 观看历史服务，按用户记录每个视频的播放进度
+type HistoryService struct {
+	progress map[string]map[string]*Progress // 用户ID -> 视频ID -> 播放进度
+	mutex    sync.RWMutex
+}
+
+// NewHistoryService 创建观看历史服务
+func NewHistoryService() *HistoryService {
+	return &HistoryService{
+		progress: make(map[string]map[string]*Progress),
+	}
+}
+
+// RecordProgress 记录播放进度，duration为视频时长（秒），未知时传0
+// 播放位置超过视频时长时按时长记录
+func (s *HistoryService) RecordProgress(ctx context.Context, userID, videoID string, position, duration int64) (*Progress, error) {
+	if userID == "" || videoID == "" {
+		return nil, fmt.Errorf("用户ID和视频ID不能为空")
+	}
+	if position < 0 {
+		return nil, fmt.Errorf("播放位置不能为负数")
+	}
+	if duration > 0 && position > duration {
+		position = duration
+	}
+
+	progress := &Progress{
+		UserID:        userID,
+		VideoID:       videoID,
+		Position:      position,
+		Completed:     duration > 0 && float64(position) >= float64(duration)*CompletionRatio,
+		LastWatchedAt: time.Now(),
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	videos, exists := s.progress[userID]
+	if !exists {
+		videos = make(map[string]*Progress)
+		s.progress[userID] = videos
+	}
+	videos[videoID] = progress
+
+	copied := *progress
+	return &copied, nil
+}
+
+// GetProgress 获取用户对某个视频的播放进度
+func (s *HistoryService) GetProgress(ctx context.Context, userID, videoID string) (*Progress, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	progress, exists := s.progress[userID][videoID]
+	if !exists {
+		return nil, ErrProgressNotFound
+	}
+
+	copied := *progress
+	return &copied, nil
+}
+
+// ResumePositions 批量获取用户对多个视频的续播位置（秒），没有记录的视频不包含在结果中
+func (s *HistoryService) ResumePositions(ctx context.Context, userID string, videoIDs []string) map[string]int64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	positions := make(map[string]int64)
+	videos := s.progress[userID]
+	for _, videoID := range videoIDs {
+		if progress, exists := videos[videoID]; exists {
+			positions[videoID] = progress.ResumePosition()
+		}
+	}
+	return positions
+}
+
+// ListHistory 列出用户的观看历史，按最后观看时间降序，limit小于等于0时不限制数量
+func (s *HistoryService) ListHistory(ctx context.Context, userID string, limit int) []*Progress {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	videos := s.progress[userID]
+	history := make([]*Progress, 0, len(videos))
+	for _, progress := range videos {
+		copied := *progress
+		history = append(history, &copied)
+	}
+
+	sort.Slice(history, func(i, j int) bool {
+		if !history[i].LastWatchedAt.Equal(history[j].LastWatchedAt) {
+			return history[i].LastWatchedAt.After(history[j].LastWatchedAt)
+		}
+		return history[i].VideoID < history[j].VideoID
+	})
+
+	if limit > 0 && len(history) > limit {
+		history = history[:limit]
+	}
+	return history
+}
+
+// RemoveVideo 删除所有用户对该视频的播放记录，视频删除时调用
+func (s *HistoryService) RemoveVideo(ctx context.Context, videoID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for userID, videos := range s.progress {
+		delete(videos, videoID)
+		if len(videos) == 0 {
+			delete(s.progress, userID)
+		}
+	}
+}
+
+// Count 统计全部用户的观看记录数量
+func (s *HistoryService) Count(ctx context.Context) int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	count := 0
+	for _, videos := range s.progress {
+		count += len(videos)
+	}
+	return count
+}
@@ -0,0 +1,72 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHistoryService_RecordProgress 测试记录播放进度
+func TestHistoryService_RecordProgress(t *testing.T) {
+	service := NewHistoryService()
+	ctx := context.Background()
+
+	progress, err := service.RecordProgress(ctx, "alice", "video1", 30, 100)
+	require.NoError(t, err, "记录播放进度应该成功")
+	assert.Equal(t, int64(30), progress.Position)
+	assert.False(t, progress.Completed)
+	assert.Equal(t, int64(30), progress.ResumePosition())
+
+	progress, err = service.RecordProgress(ctx, "alice", "video1", 96, 100)
+	require.NoError(t, err)
+	assert.True(t, progress.Completed, "接近结尾时应该视为已看完")
+	assert.Equal(t, int64(0), progress.ResumePosition(), "已看完的视频应该从头播放")
+
+	progress, err = service.RecordProgress(ctx, "alice", "video2", 500, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), progress.Position, "播放位置不应该超过视频时长")
+
+	progress, err = service.RecordProgress(ctx, "alice", "video3", 500, 0)
+	require.NoError(t, err)
+	assert.False(t, progress.Completed, "时长未知时不应该视为已看完")
+
+	_, err = service.RecordProgress(ctx, "alice", "video1", -1, 100)
+	assert.Error(t, err, "播放位置不能为负数")
+	_, err = service.RecordProgress(ctx, "", "video1", 10, 100)
+	assert.Error(t, err, "用户ID不能为空")
+
+	_, err = service.GetProgress(ctx, "bob", "video1")
+	assert.ErrorIs(t, err, ErrProgressNotFound, "播放进度应该按用户隔离")
+}
+
+// TestHistoryService_ListHistory 测试观看历史和续播位置
+func TestHistoryService_ListHistory(t *testing.T) {
+	service := NewHistoryService()
+	ctx := context.Background()
+
+	for _, videoID := range []string{"video1", "video2", "video3"} {
+		_, err := service.RecordProgress(ctx, "alice", videoID, 10, 100)
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+	}
+	_, err := service.RecordProgress(ctx, "alice", "video1", 20, 100)
+	require.NoError(t, err)
+
+	history := service.ListHistory(ctx, "alice", 0)
+	require.Len(t, history, 3)
+	assert.Equal(t, "video1", history[0].VideoID, "最近观看的视频应该排在最前")
+	assert.Equal(t, "video3", history[1].VideoID)
+	assert.Len(t, service.ListHistory(ctx, "alice", 2), 2)
+	assert.Empty(t, service.ListHistory(ctx, "bob", 0))
+	assert.Equal(t, 3, service.Count(ctx), "同一视频的多次观看只有一条记录")
+
+	positions := service.ResumePositions(ctx, "alice", []string{"video1", "video4"})
+	assert.Equal(t, map[string]int64{"video1": 20}, positions, "没有记录的视频不应该包含在结果中")
+
+	service.RemoveVideo(ctx, "video1")
+	_, err = service.GetProgress(ctx, "alice", "video1")
+	assert.ErrorIs(t, err, ErrProgressNotFound, "删除视频后应该清除播放记录")
+}
@@ -0,0 +1,101 @@
+package i18n
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// 支持的响应消息语言
+const (
+	LanguageChinese = "zh-CN" // 简体中文，错误消息的原始语言
+	LanguageEnglish = "en"    // 英文
+)
+
+// DefaultLanguage 请求未指定语言且未配置默认语言时使用的语言
+const DefaultLanguage = LanguageChinese
+
+// Normalize 将语言标签规范化为支持的语言，不支持时返回false
+// zh、zh-CN、zh-Hans等中文标签统一为zh-CN，en、en-US等英文标签统一为en
+func Normalize(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	primary, _, _ := strings.Cut(tag, "-")
+	switch primary {
+	case "zh":
+		return LanguageChinese, true
+	case "en":
+		return LanguageEnglish, true
+	default:
+		return "", false
+	}
+}
+
+// languageRange Accept-Language中的一个语言及其权重
+type languageRange struct {
+	tag     string
+	quality float64
+}
+
+// Negotiate 根据Accept-Language请求头选择响应语言，没有可用语言时返回fallback
+// 按权重从高到低选择第一个支持的语言，权重相同时保持请求头中的顺序，"*"表示使用fallback
+func Negotiate(acceptLanguage, fallback string) string {
+	ranges := parseAcceptLanguage(acceptLanguage)
+	for _, r := range ranges {
+		if r.tag == "*" {
+			return fallback
+		}
+		if language, ok := Normalize(r.tag); ok {
+			return language
+		}
+	}
+	return fallback
+}
+
+// parseAcceptLanguage 解析Accept-Language请求头，忽略权重为0和格式无效的语言
+func parseAcceptLanguage(header string) []languageRange {
+	var ranges []languageRange
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+
+		quality := 1.0
+		if name, value, found := strings.Cut(strings.TrimSpace(params), "="); found && strings.TrimSpace(name) == "q" {
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || q < 0 || q > 1 {
+				continue
+			}
+			quality = q
+		}
+		if quality == 0 {
+			continue
+		}
+		ranges = append(ranges, languageRange{tag: tag, quality: quality})
+	}
+
+	slices.SortStableFunc(ranges, func(a, b languageRange) int {
+		return cmp.Compare(b.quality, a.quality)
+	})
+	return ranges
+}
+
+// Message 获取错误码在指定语言下的消息
+// 中文直接返回原始消息；其他语言返回错误码对应的译文，没有译文时返回原始消息
+// 译文按错误码统一，不包含原始消息中的具体原因
+func Message(language string, code int, message string) string {
+	if language == LanguageChinese {
+		return message
+	}
+	if translated, exists := catalogs[language][code]; exists {
+		return translated
+	}
+	return message
+}
+
+// catalogs 各语言的错误码译文
+var catalogs = map[string]map[int]string{
+	LanguageEnglish: englishMessages,
+}
@@ -0,0 +1,61 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+		ok       bool
+	}{
+		{"zh", LanguageChinese, true},
+		{"zh-CN", LanguageChinese, true},
+		{"ZH-hans", LanguageChinese, true},
+		{"en", LanguageEnglish, true},
+		{" en-US ", LanguageEnglish, true},
+		{"fr", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		language, ok := Normalize(tt.tag)
+		assert.Equal(t, tt.ok, ok, "语言标签: %q", tt.tag)
+		assert.Equal(t, tt.expected, language, "语言标签: %q", tt.tag)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		fallback string
+		expected string
+	}{
+		{"未指定语言", "", LanguageChinese, LanguageChinese},
+		{"未指定语言时使用配置的默认语言", "", LanguageEnglish, LanguageEnglish},
+		{"英文", "en-US,en;q=0.9", LanguageChinese, LanguageEnglish},
+		{"中文", "zh-CN,zh;q=0.9,en;q=0.8", LanguageEnglish, LanguageChinese},
+		{"按权重选择", "zh;q=0.5,en;q=0.8", LanguageChinese, LanguageEnglish},
+		{"跳过不支持的语言", "fr-FR,de;q=0.9,en;q=0.5", LanguageChinese, LanguageEnglish},
+		{"忽略权重为0的语言", "en;q=0,zh;q=0.1", LanguageEnglish, LanguageChinese},
+		{"忽略格式无效的权重", "en;q=abc", LanguageChinese, LanguageChinese},
+		{"通配符", "fr,*;q=0.5,en;q=0.1", LanguageEnglish, LanguageEnglish},
+		{"都不支持", "fr,de", LanguageChinese, LanguageChinese},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Negotiate(tt.header, tt.fallback))
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	assert.Equal(t, "视频不存在", Message(LanguageChinese, 2102, "视频不存在"), "中文应该返回原始消息")
+	assert.Equal(t, "Video not found", Message(LanguageEnglish, 2102, "视频不存在"))
+	assert.Equal(t, "Success", Message(LanguageEnglish, 0, "获取成功"))
+	assert.Equal(t, "未知错误", Message(LanguageEnglish, 1234, "未知错误"), "没有译文时应该返回原始消息")
+	assert.Equal(t, "视频不存在", Message("fr", 2102, "视频不存在"), "不支持的语言应该返回原始消息")
+}
@@ -0,0 +1,201 @@
+package i18n
+
+// englishMessages 错误码的英文消息
+var englishMessages = map[int]string{
+	0: "Success",
+
+	// 上传
+	1001: "Invalid upload request",
+	1002: "Failed to read the uploaded file",
+	1003: "File size validation failed",
+	1004: "File format validation failed",
+	1005: "Unsupported file format",
+	1006: "Failed to store the uploaded file",
+	1007: "Invalid or expired upload token",
+	1008: "The file has not been uploaded yet",
+	1009: "Storage quota exceeded",
+	1010: "Checksum mismatch",
+	1011: "The video already exists",
+	1012: "You are not allowed to upload videos",
+	1013: "Failed to save the video",
+
+	// 视频列表、详情、观看进度、收藏和下载
+	2000: "Invalid request parameters",
+	2001: "Invalid video list query",
+	2002: "Failed to query the video list",
+	2003: "Collection not found",
+	2101: "Invalid video detail request",
+	2102: "Video not found",
+	2201: "Invalid watch progress request",
+	2202: "Video not found",
+	2301: "Invalid favorite request",
+	2302: "Video not found",
+	2401: "Invalid download request",
+	2402: "Video not found",
+	2403: "The rendition does not exist or the video has not been packaged yet",
+	2404: "Requested range not satisfiable",
+	2501: "Invalid export request",
+	2502: "Video not found",
+
+	// 删除
+	3001: "Invalid delete request",
+	3002: "Video not found",
+	3003: "Some files could not be deleted; the video was kept and the delete can be retried",
+	3004: "The video is being packaged for HLS, please retry later",
+	3005: "You can only delete your own videos",
+
+	// 更新、标签、合集、播放列表、章节、归档、重新处理和缩略图
+	4001: "Invalid update request",
+	4002: "Video not found",
+	4003: "The video was modified by another request",
+	4004: "You can only modify your own videos",
+	4101: "Invalid tag request",
+	4102: "Video not found",
+	4103: "You can only modify your own videos",
+	4201: "Invalid collection request",
+	4202: "Collection not found",
+	4203: "Collection name already exists",
+	4204: "Video not found",
+	4205: "Permission denied for this collection",
+	4301: "Invalid playlist request",
+	4302: "Playlist not found",
+	4303: "Video not found",
+	4304: "The video is not in the playlist",
+	4305: "Permission denied for this playlist",
+	4401: "Invalid chapters",
+	4402: "Video not found",
+	4403: "You can only modify your own videos",
+	4501: "Invalid archive request",
+	4502: "Video not found",
+	4503: "Cold storage archiving is not enabled",
+	4504: "The video is being archived or restored, please retry later",
+	4601: "Invalid reprocess request",
+	4602: "Video not found",
+	4603: "You can only reprocess your own videos",
+	4701: "Invalid thumbnail request",
+	4702: "Video not found",
+	4703: "You can only change the thumbnail of your own videos",
+	4704: "Thumbnail candidate not found, please generate candidates first",
+	4705: "Frame extraction is unavailable, cannot generate thumbnail candidates",
+	4706: "Invalid cover image, only JPEG and PNG are supported",
+	4801: "Invalid video version request",
+	4802: "Video not found",
+	4803: "You can only manage versions of your own videos",
+	4804: "Video version not found",
+	4805: "Storage quota exceeded",
+	4806: "The video is being processed, please try again later",
+
+	5000: "Internal server error",
+	5030: "Storage service temporarily unavailable, please retry later",
+	5040: "Storage service timed out, please retry later",
+
+	// 播放
+	6001: "Invalid HLS request",
+	6002: "HLS streaming is not available",
+	6003: "Video not found",
+	6004: "The video is being packaged for HLS, please retry later",
+	6005: "Failed to get the HLS playlist",
+	6101: "Invalid stream request",
+	6102: "Video not found",
+	6103: "Requested range not satisfiable",
+	6201: "Invalid thumbnail track request",
+	6202: "Video not found",
+	6203: "Frame extraction is not available, the thumbnail track cannot be generated",
+	6204: "The thumbnail track is being generated, please retry later",
+	6301: "Invalid play URL request",
+	6302: "Video not found",
+	6401: "Invalid, expired or revoked playback token",
+	6402: "Invalid playback token revoke request",
+	6501: "Invalid share request",
+	6502: "Share or video not found",
+	6503: "Share password required",
+	6504: "Wrong share password",
+	6505: "Share has expired or reached its view limit",
+	6506: "Not allowed to revoke another user's share",
+	6601: "Invalid embed request",
+	6602: "Video not found",
+	6603: "Unsupported oEmbed format, only json is supported",
+	6701: "Invalid DASH request",
+	6702: "Stream packaging is not available",
+	6703: "Video not found",
+	6704: "The video is being packaged, please retry later",
+	6705: "Failed to get the DASH manifest",
+	6801: "Invalid transcode job request",
+	6802: "Transcoding is not enabled or the packager is not available",
+	6803: "Video not found",
+	6804: "Transcode job not found",
+	6805: "The video already has an unfinished transcode job",
+	6806: "The transcode job cannot be changed in its current state",
+	6901: "Invalid worker request",
+	6902: "Remote worker mode is not enabled",
+	6903: "Worker job not found",
+	6904: "The job lease was lost, the job was canceled or reassigned",
+
+	// 用户、认证和限流
+	7001: "Invalid user request",
+	7002: "Username already exists",
+	7003: "Invalid username or password",
+	7004: "User not found",
+	7005: "Invalid user role",
+	7010: "Not logged in or the token is invalid",
+	7011: "Permission denied",
+	7012: "Too many requests, please retry later",
+
+	// 投屏
+	7101: "Invalid cast request",
+	7102: "Video not found",
+	7103: "The poster or subtitle does not exist",
+
+	// 视频目录导出和导入
+	7201: "Invalid catalog request",
+	7202: "The catalog file could not be parsed",
+
+	// 视频剪辑
+	7301: "Invalid clip request",
+	7302: "Video not found",
+	7303: "Only videos can be clipped",
+	7304: "FFmpeg is unavailable, cannot clip the video",
+	7305: "Storage quota exceeded",
+	7306: "Failed to clip the video",
+
+	8001: "Invalid upload progress request",
+	8101: "Invalid multipart upload request",
+	8102: "The multipart upload does not exist or has expired",
+	8103: "Uploaded parts do not match the completion request",
+	9001: "WebSocket handshake failed",
+
+	// 本地存储文件
+	9101: "Invalid or expired signature",
+	9102: "File not found",
+	9103: "Local storage is not enabled",
+
+	9201: "Invalid analytics request",
+
+	// 存储迁移和批量导入
+	9301: "Invalid storage migration request",
+	9302: "Storage migration or source bucket not found",
+	9303: "A storage migration is already running",
+	9401: "Invalid video import request",
+	9402: "Import directory or import job not found",
+	9403: "A video import is already running",
+
+	// 内容审核
+	9501: "Invalid moderation request",
+	9502: "The video is not in the moderation queue",
+
+	// NFO导出
+	9601: "Invalid NFO export request",
+	9602: "No NFO export has been run",
+	9603: "An NFO export is already running",
+
+	// 元数据备份
+	9701: "Invalid backup request or backups are not configured",
+	9702: "Backup not found",
+
+	// 存储复制
+	9801: "Storage replication is not enabled",
+	9802: "A replication sync is already running",
+
+	// 一致性检查
+	9901: "Invalid consistency check request",
+}
@@ -0,0 +1,200 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 导入状态
+const (
+	StatusPending   = "pending"   // 已创建，尚未开始
+	StatusRunning   = "running"   // 正在导入
+	StatusCompleted = "completed" // 已处理全部文件，Failed大于0时可以重新导入
+	StatusFailed    = "failed"    // 扫描目录失败或被取消，导入中止
+)
+
+// File 待导入的视频文件
+type File struct {
+	Path    string // 文件的绝对路径
+	RelPath string // 相对导入目录的路径，使用/分隔
+	Size    int64  // 文件大小
+}
+
+// Handler 导入单个文件，返回false表示文件被跳过
+type Handler func(ctx context.Context, file File) (bool, error)
+
+// Progress 导入进度
+type Progress struct {
+	Status        string    // 导入状态
+	Total         int       // 目录中的视频文件数量
+	Imported      int       // 已导入的文件数量
+	Skipped       int       // 跳过的文件数量
+	Failed        int       // 导入失败的文件数量
+	BytesImported int64     // 已导入的字节数
+	Current       string    // 正在导入的文件
+	Error         string    // 最近一次错误
+	StartedAt     time.Time // 开始时间
+	FinishedAt    time.Time // 结束时间，运行中为零值
+}
+
+// Importer 递归扫描目录中扩展名匹配的视频文件并逐个导入
+// 隐藏文件和隐藏目录（以.开头）被忽略，符号链接不会被跟随
+type Importer struct {
+	root       string
+	extensions map[string]bool
+	progress   Progress
+	mutex      sync.RWMutex
+}
+
+// NewImporter 创建目录导入，root必须是已存在目录的绝对路径，formats为允许的扩展名（不含.）
+func NewImporter(root string, formats []string) (*Importer, error) {
+	if !filepath.IsAbs(root) {
+		return nil, fmt.Errorf("导入目录必须是绝对路径: %s", root)
+	}
+	if len(formats) == 0 {
+		return nil, fmt.Errorf("视频格式列表不能为空")
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("访问导入目录失败: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("导入路径不是目录: %s", root)
+	}
+
+	extensions := make(map[string]bool, len(formats))
+	for _, format := range formats {
+		extensions["."+strings.ToLower(strings.TrimPrefix(format, "."))] = true
+	}
+
+	return &Importer{
+		root:       filepath.Clean(root),
+		extensions: extensions,
+		progress:   Progress{Status: StatusPending},
+	}, nil
+}
+
+// Root 获取导入目录
+func (i *Importer) Root() string {
+	return i.root
+}
+
+// Progress 获取导入进度快照
+func (i *Importer) Progress() Progress {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	return i.progress
+}
+
+// Running 判断导入是否尚未结束
+func (i *Importer) Running() bool {
+	status := i.Progress().Status
+	return status == StatusPending || status == StatusRunning
+}
+
+// Scan 列出目录中的全部视频文件，按路径排序
+func (i *Importer) Scan() ([]File, error) {
+	var files []File
+	err := filepath.WalkDir(i.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != i.root && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || !i.extensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(i.root, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, File{Path: path, RelPath: filepath.ToSlash(relPath), Size: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("扫描导入目录失败: %w", err)
+	}
+
+	slices.SortFunc(files, func(a, b File) int { return strings.Compare(a.RelPath, b.RelPath) })
+	return files, nil
+}
+
+// Run 扫描目录并逐个导入，单个文件失败时记录错误并继续，返回失败的文件数量
+// 扫描目录失败或ctx被取消时返回错误
+func (i *Importer) Run(ctx context.Context, handler Handler) (int, error) {
+	i.update(func(p *Progress) {
+		*p = Progress{Status: StatusRunning, StartedAt: time.Now()}
+	})
+
+	files, err := i.Scan()
+	if err != nil {
+		i.finish(StatusFailed, err)
+		return 0, err
+	}
+	i.update(func(p *Progress) { p.Total = len(files) })
+
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			i.finish(StatusFailed, err)
+			return i.Progress().Failed, err
+		}
+
+		i.update(func(p *Progress) { p.Current = file.RelPath })
+		imported, err := handler(ctx, file)
+
+		i.update(func(p *Progress) {
+			switch {
+			case err != nil:
+				p.Failed++
+				p.Error = fmt.Sprintf("%s: %v", file.RelPath, err)
+			case imported:
+				p.Imported++
+				p.BytesImported += file.Size
+			default:
+				p.Skipped++
+			}
+		})
+	}
+
+	i.finish(StatusCompleted, nil)
+	return i.Progress().Failed, nil
+}
+
+// finish 结束导入并记录状态
+func (i *Importer) finish(status string, err error) {
+	i.update(func(p *Progress) {
+		p.Status = status
+		p.Current = ""
+		p.FinishedAt = time.Now()
+		if err != nil && !errors.Is(err, context.Canceled) {
+			p.Error = err.Error()
+		} else if err != nil {
+			p.Error = "导入已取消"
+		}
+	})
+}
+
+// update 在锁内更新进度
+func (i *Importer) update(fn func(p *Progress)) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	fn(&i.progress)
+}
@@ -0,0 +1,130 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestFiles 在目录中写入测试文件
+func writeTestFiles(t *testing.T, root string, files map[string]string) {
+	for name, data := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+	}
+}
+
+// TestNewImporter 测试导入目录和格式校验
+func TestNewImporter(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{"a.mp4": "a"})
+
+	_, err := NewImporter("relative/dir", []string{"mp4"})
+	assert.Error(t, err, "相对路径应该被拒绝")
+
+	_, err = NewImporter(filepath.Join(root, "missing"), []string{"mp4"})
+	assert.Error(t, err, "不存在的目录应该被拒绝")
+
+	_, err = NewImporter(filepath.Join(root, "a.mp4"), []string{"mp4"})
+	assert.Error(t, err, "文件路径应该被拒绝")
+
+	_, err = NewImporter(root, nil)
+	assert.Error(t, err, "空格式列表应该被拒绝")
+
+	importer, err := NewImporter(root+"/", []string{"mp4"})
+	require.NoError(t, err)
+	assert.Equal(t, root, importer.Root())
+	assert.True(t, importer.Running(), "创建后尚未开始的导入视为未结束")
+}
+
+// TestImporter_Scan 测试递归扫描、扩展名过滤和隐藏文件忽略
+func TestImporter_Scan(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"movies/b.MKV":         "bb",
+		"movies/a.mp4":         "a",
+		"movies/notes.txt":     "txt",
+		"top.mp4":              "top",
+		".hidden.mp4":          "hidden",
+		".cache/c.mp4":         "cache",
+		"movies/.thumbs/d.mp4": "thumb",
+	})
+	require.NoError(t, os.Symlink(filepath.Join(root, "top.mp4"), filepath.Join(root, "link.mp4")))
+
+	importer, err := NewImporter(root, []string{"mp4", "mkv"})
+	require.NoError(t, err)
+
+	files, err := importer.Scan()
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+	assert.Equal(t, File{Path: filepath.Join(root, "movies", "a.mp4"), RelPath: "movies/a.mp4", Size: 1}, files[0])
+	assert.Equal(t, "movies/b.MKV", files[1].RelPath, "扩展名匹配不区分大小写")
+	assert.Equal(t, int64(2), files[1].Size)
+	assert.Equal(t, "top.mp4", files[2].RelPath)
+}
+
+// TestImporter_Run 测试导入进度统计
+func TestImporter_Run(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"a.mp4": "aaa",
+		"b.mp4": "bb",
+		"c.mp4": "c",
+	})
+
+	importer, err := NewImporter(root, []string{"mp4"})
+	require.NoError(t, err)
+
+	failed, err := importer.Run(context.Background(), func(ctx context.Context, file File) (bool, error) {
+		switch file.RelPath {
+		case "b.mp4":
+			return false, nil
+		case "c.mp4":
+			return false, errors.New("格式错误")
+		}
+		assert.Equal(t, StatusRunning, importer.Progress().Status)
+		assert.Equal(t, file.RelPath, importer.Progress().Current)
+		return true, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, failed)
+
+	progress := importer.Progress()
+	assert.Equal(t, StatusCompleted, progress.Status)
+	assert.Equal(t, 3, progress.Total)
+	assert.Equal(t, 1, progress.Imported)
+	assert.Equal(t, 1, progress.Skipped)
+	assert.Equal(t, 1, progress.Failed)
+	assert.Equal(t, int64(3), progress.BytesImported)
+	assert.Equal(t, "c.mp4: 格式错误", progress.Error)
+	assert.Empty(t, progress.Current)
+	assert.False(t, progress.FinishedAt.IsZero())
+	assert.False(t, importer.Running())
+}
+
+// TestImporter_RunCanceled 测试取消导入
+func TestImporter_RunCanceled(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{"a.mp4": "a", "b.mp4": "b"})
+
+	importer, err := NewImporter(root, []string{"mp4"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err = importer.Run(ctx, func(ctx context.Context, file File) (bool, error) {
+		cancel()
+		return true, nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	progress := importer.Progress()
+	assert.Equal(t, StatusFailed, progress.Status)
+	assert.Equal(t, 1, progress.Imported, "取消前已导入的文件应该被统计")
+	assert.Equal(t, "导入已取消", progress.Error)
+}
@@ -0,0 +1,300 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// audioCodecs 音频格式对应的编码名称，Ogg的编码从首个数据包判断
+var audioCodecs = map[string]string{
+	"mp3":  "MP3",
+	"flac": "FLAC",
+	"wav":  "PCM",
+}
+
+// oggTailSize 查找最后一个Ogg页时读取的文件末尾大小，Ogg页最大约64KB
+const oggTailSize = 64 * 1024
+
+// ExtractAudioInfo 解析音频文件的时长、码率、采样率和声道数，reader需要能随机访问完整文件
+func ExtractAudioInfo(reader io.ReaderAt, size int64, format string) (*Info, error) {
+	info := &Info{Format: format, MediaType: MediaTypeForFormat(format), Codec: audioCodecs[format]}
+
+	var err error
+	switch format {
+	case "wav":
+		err = parseWAVInfo(reader, size, info)
+	case "flac":
+		err = parseFLACInfo(reader, info)
+	case "mp3":
+		err = parseMP3Info(reader, size, info)
+	case "ogg":
+		err = parseOggInfo(reader, size, info)
+	default:
+		return nil, fmt.Errorf("不支持的音频格式: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 未从文件头得到码率时按文件大小计算平均码率
+	if info.Bitrate == 0 && info.Duration > 0 {
+		info.Bitrate = int64(float64(size*8) / info.Duration.Seconds())
+	}
+	return info, nil
+}
+
+// wavFormat WAV文件fmt块中的音频格式
+type wavFormat struct {
+	AudioFormat   uint16 // 1为PCM
+	Channels      int
+	SampleRate    int
+	ByteRate      int
+	BitsPerSample int
+	DataOffset    int64 // data块数据的偏移
+	DataSize      int64 // data块数据的大小
+}
+
+// parseWAVFormat 遍历RIFF块读取fmt和data块
+func parseWAVFormat(reader io.ReaderAt, size int64) (*wavFormat, error) {
+	header := make([]byte, 12)
+	if _, err := reader.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("读取WAV文件头失败: %w", err)
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("无效的WAV文件头")
+	}
+
+	format := &wavFormat{}
+	chunk := make([]byte, 24)
+	for offset := int64(12); offset+8 <= size; {
+		n, _ := reader.ReadAt(chunk, offset)
+		if n < 8 {
+			break
+		}
+		chunkSize := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+		switch string(chunk[0:4]) {
+		case "fmt ":
+			if n < 24 {
+				return nil, fmt.Errorf("WAV的fmt块不完整")
+			}
+			format.AudioFormat = binary.LittleEndian.Uint16(chunk[8:10])
+			format.Channels = int(binary.LittleEndian.Uint16(chunk[10:12]))
+			format.SampleRate = int(binary.LittleEndian.Uint32(chunk[12:16]))
+			format.ByteRate = int(binary.LittleEndian.Uint32(chunk[16:20]))
+			format.BitsPerSample = int(binary.LittleEndian.Uint16(chunk[22:24]))
+		case "data":
+			format.DataOffset = offset + 8
+			// 流式写入的文件data块大小可能为0或超出文件末尾，以实际大小为准
+			format.DataSize = min(chunkSize, size-format.DataOffset)
+			if chunkSize == 0 {
+				format.DataSize = size - format.DataOffset
+			}
+			if format.SampleRate == 0 {
+				return nil, fmt.Errorf("WAV的fmt块位于data块之后或缺失")
+			}
+			return format, nil
+		}
+		// 块按2字节对齐
+		offset += 8 + chunkSize + chunkSize%2
+	}
+	return nil, fmt.Errorf("未找到WAV的data块")
+}
+
+// parseWAVInfo 解析WAV的时长和码率
+func parseWAVInfo(reader io.ReaderAt, size int64, info *Info) error {
+	format, err := parseWAVFormat(reader, size)
+	if err != nil {
+		return err
+	}
+	if format.AudioFormat != 1 {
+		info.Codec = "WAV"
+	}
+	info.SampleRate, info.Channels = format.SampleRate, format.Channels
+	if format.ByteRate > 0 {
+		info.Bitrate = int64(format.ByteRate) * 8
+		info.Duration = time.Duration(float64(format.DataSize) / float64(format.ByteRate) * float64(time.Second))
+	}
+	return nil
+}
+
+// parseFLACInfo 从STREAMINFO元数据块读取采样率、声道数和总样本数
+func parseFLACInfo(reader io.ReaderAt, info *Info) error {
+	// "fLaC"、4字节元数据块头和34字节的STREAMINFO
+	data := make([]byte, 42)
+	if _, err := reader.ReadAt(data, 0); err != nil {
+		return fmt.Errorf("读取FLAC文件头失败: %w", err)
+	}
+	if string(data[0:4]) != "fLaC" || data[4]&0x7F != 0 || readUint24(data[5:8]) < 34 {
+		return fmt.Errorf("FLAC文件缺少STREAMINFO块")
+	}
+
+	// STREAMINFO偏移10处依次为20位采样率、3位声道数-1、5位位深-1和36位总样本数
+	streamInfo := data[8:]
+	packed := binary.BigEndian.Uint64(streamInfo[10:18])
+	info.SampleRate = int(packed >> 44)
+	info.Channels = int(packed>>41&0x07) + 1
+	totalSamples := packed & 0xFFFFFFFFF
+	if info.SampleRate == 0 {
+		return fmt.Errorf("FLAC采样率无效")
+	}
+	info.Duration = time.Duration(float64(totalSamples) / float64(info.SampleRate) * float64(time.Second))
+	return nil
+}
+
+// mp3FrameHeader MPEG音频帧头
+type mp3FrameHeader struct {
+	Version         int // 1、2或25（MPEG 2.5）
+	Bitrate         int // 码率（bps）
+	SampleRate      int
+	Channels        int
+	SamplesPerFrame int
+	SideInfoSize    int // 帧头之后Layer III边信息的大小，Xing头位于边信息之后
+}
+
+// MPEG-1和MPEG-2/2.5 Layer III的码率表（kbps）和采样率表
+var (
+	mp3BitratesV1    = []int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320}
+	mp3BitratesV2    = []int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160}
+	mp3SampleRatesV1 = []int{44100, 48000, 32000}
+)
+
+// errNotMP3Frame 数据不是有效的MPEG Layer III帧头
+var errNotMP3Frame = errors.New("不是有效的MP3帧")
+
+// parseMP3FrameHeader 解析MPEG Layer III帧头
+func parseMP3FrameHeader(data []byte) (*mp3FrameHeader, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1]&0xE0 != 0xE0 {
+		return nil, errNotMP3Frame
+	}
+	versionBits := data[1] >> 3 & 0x03
+	layerBits := data[1] >> 1 & 0x03
+	bitrateIndex := int(data[2] >> 4)
+	sampleRateIndex := int(data[2] >> 2 & 0x03)
+	// 只支持Layer III，保留值和自由码率视为无效
+	if versionBits == 0x01 || layerBits != 0x01 || bitrateIndex == 0 || bitrateIndex == 0x0F || sampleRateIndex == 0x03 {
+		return nil, errNotMP3Frame
+	}
+
+	header := &mp3FrameHeader{Channels: 2}
+	if data[3]>>6 == 0x03 {
+		header.Channels = 1
+	}
+	sampleRate := mp3SampleRatesV1[sampleRateIndex]
+	switch versionBits {
+	case 0x03:
+		header.Version = 1
+		header.Bitrate = mp3BitratesV1[bitrateIndex] * 1000
+		header.SampleRate = sampleRate
+		header.SamplesPerFrame = 1152
+		header.SideInfoSize = 32
+		if header.Channels == 1 {
+			header.SideInfoSize = 17
+		}
+	default:
+		header.Version = 2
+		header.SampleRate = sampleRate / 2
+		if versionBits == 0x00 {
+			header.Version = 25
+			header.SampleRate = sampleRate / 4
+		}
+		header.Bitrate = mp3BitratesV2[bitrateIndex] * 1000
+		header.SamplesPerFrame = 576
+		header.SideInfoSize = 17
+		if header.Channels == 1 {
+			header.SideInfoSize = 9
+		}
+	}
+	return header, nil
+}
+
+// parseMP3Info 跳过ID3v2标签后解析第一个帧头
+// 包含Xing/Info头的VBR文件按帧数计算时长，否则按固定码率和音频数据大小估算
+func parseMP3Info(reader io.ReaderAt, size int64, info *Info) error {
+	var audioStart int64
+	id3 := make([]byte, 10)
+	if _, err := reader.ReadAt(id3, 0); err != nil {
+		return fmt.Errorf("读取MP3文件头失败: %w", err)
+	}
+	if string(id3[0:3]) == "ID3" {
+		// 标签大小为4个7位的同步安全整数，不包含10字节的标签头
+		tagSize := int64(id3[6])<<21 | int64(id3[7])<<14 | int64(id3[8])<<7 | int64(id3[9])
+		audioStart = 10 + tagSize
+		if id3[5]&0x10 != 0 {
+			audioStart += 10
+		}
+	}
+
+	frame := make([]byte, 4+32+12)
+	n, _ := reader.ReadAt(frame, audioStart)
+	header, err := parseMP3FrameHeader(frame[:n])
+	if err != nil {
+		return fmt.Errorf("未找到MP3音频帧: %w", err)
+	}
+	info.SampleRate, info.Channels = header.SampleRate, header.Channels
+
+	// Xing/Info头：4字节标识、4字节标志，标志最低位表示之后4字节为帧数
+	xing := frame[min(4+header.SideInfoSize, n):]
+	if len(xing) >= 12 && (string(xing[0:4]) == "Xing" || string(xing[0:4]) == "Info") && xing[7]&0x01 != 0 {
+		frames := binary.BigEndian.Uint32(xing[8:12])
+		seconds := float64(frames) * float64(header.SamplesPerFrame) / float64(header.SampleRate)
+		info.Duration = time.Duration(seconds * float64(time.Second))
+		return nil
+	}
+
+	info.Bitrate = int64(header.Bitrate)
+	info.Duration = time.Duration(float64((size-audioStart)*8) / float64(header.Bitrate) * float64(time.Second))
+	return nil
+}
+
+// parseOggInfo 从首个数据包识别Vorbis或Opus编码，按最后一页的粒度位置计算时长
+func parseOggInfo(reader io.ReaderAt, size int64, info *Info) error {
+	page := make([]byte, 27+255+32)
+	n, _ := reader.ReadAt(page, 0)
+	if n < 27 || string(page[0:4]) != "OggS" {
+		return fmt.Errorf("无效的Ogg页")
+	}
+	// 页头27字节之后为段表，段表之后为首个数据包
+	segments := int(page[26])
+	if 27+segments >= n {
+		return fmt.Errorf("Ogg页不完整")
+	}
+	packet := page[27+segments : n]
+
+	var preSkip int64
+	switch {
+	case len(packet) >= 16 && bytes.HasPrefix(packet, []byte("\x01vorbis")):
+		info.Codec = "Vorbis"
+		info.Channels = int(packet[11])
+		info.SampleRate = int(binary.LittleEndian.Uint32(packet[12:16]))
+	case len(packet) >= 12 && bytes.HasPrefix(packet, []byte("OpusHead")):
+		// Opus的粒度位置始终按48kHz计算，需要减去解码器预跳过的样本
+		info.Codec = "Opus"
+		info.Channels = int(packet[9])
+		info.SampleRate = 48000
+		preSkip = int64(binary.LittleEndian.Uint16(packet[10:12]))
+	default:
+		return fmt.Errorf("不支持的Ogg编码")
+	}
+	if info.SampleRate == 0 {
+		return fmt.Errorf("Ogg采样率无效")
+	}
+
+	tailSize := min(size, oggTailSize)
+	tail := make([]byte, tailSize)
+	if _, err := reader.ReadAt(tail, size-tailSize); err != nil && err != io.EOF {
+		return fmt.Errorf("读取Ogg文件末尾失败: %w", err)
+	}
+	last := bytes.LastIndex(tail, []byte("OggS"))
+	if last < 0 || last+14 > len(tail) {
+		return nil
+	}
+	granule := int64(binary.LittleEndian.Uint64(tail[last+6 : last+14]))
+	if granule > preSkip {
+		info.Duration = time.Duration(float64(granule-preSkip) / float64(info.SampleRate) * float64(time.Second))
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mp3TestFrameSize 128kbps、44.1kHz的MPEG-1 Layer III帧大小
+const mp3TestFrameSize = 144 * 128000 / 44100
+
+// createTestWAV 构造16位PCM的WAV文件
+func createTestWAV(sampleRate, channels int, samples []int16) []byte {
+	data := make([]byte, 2*len(samples))
+	for i, sample := range samples {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(sample))
+	}
+	header := make([]byte, 44)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(36+len(data)))
+	copy(header[8:16], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(header[16:20], 16)
+	binary.LittleEndian.PutUint16(header[20:22], 1)
+	binary.LittleEndian.PutUint16(header[22:24], uint16(channels))
+	binary.LittleEndian.PutUint32(header[24:28], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(header[28:32], uint32(sampleRate*channels*2))
+	binary.LittleEndian.PutUint16(header[32:34], uint16(channels*2))
+	binary.LittleEndian.PutUint16(header[34:36], 16)
+	copy(header[36:40], "data")
+	binary.LittleEndian.PutUint32(header[40:44], uint32(len(data)))
+	return append(header, data...)
+}
+
+// createTestFLAC 构造只包含STREAMINFO块的FLAC文件头
+func createTestFLAC(sampleRate, channels int, totalSamples uint64) []byte {
+	data := make([]byte, 42+1024)
+	copy(data[0:4], "fLaC")
+	data[4] = 0x80 // 最后一个元数据块，类型0
+	data[7] = 34
+	packed := uint64(sampleRate)<<44 | uint64(channels-1)<<41 | uint64(15)<<36 | totalSamples
+	binary.BigEndian.PutUint64(data[18:26], packed)
+	return data
+}
+
+// createTestMP3 构造128kbps、44.1kHz立体声的固定码率MP3
+func createTestMP3(withID3 bool, frames int) []byte {
+	var buf bytes.Buffer
+	if withID3 {
+		// ID3v2.3标签，大小为同步安全整数
+		buf.Write([]byte{'I', 'D', '3', 0x03, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00})
+		buf.Write(make([]byte, 128))
+	}
+	for i := 0; i < frames; i++ {
+		frame := make([]byte, mp3TestFrameSize)
+		copy(frame, []byte{0xFF, 0xFB, 0x90, 0x00})
+		buf.Write(frame)
+	}
+	return buf.Bytes()
+}
+
+// createTestMP3Xing 构造第一帧包含Xing头的可变码率MP3
+func createTestMP3Xing(totalFrames uint32) []byte {
+	data := createTestMP3(false, 3)
+	// MPEG-1立体声的边信息为32字节，Xing头位于帧头和边信息之后
+	copy(data[36:44], []byte{'X', 'i', 'n', 'g', 0x00, 0x00, 0x00, 0x01})
+	binary.BigEndian.PutUint32(data[44:48], totalFrames)
+	return data
+}
+
+// createTestOggPage 构造只包含一个数据包的Ogg页
+func createTestOggPage(granule uint64, packet []byte) []byte {
+	page := make([]byte, 28)
+	copy(page[0:4], "OggS")
+	binary.LittleEndian.PutUint64(page[6:14], granule)
+	page[26] = 1
+	page[27] = byte(len(packet))
+	return append(page, packet...)
+}
+
+// createTestOgg 构造首页为编码头、末页粒度位置为lastGranule的Ogg文件
+func createTestOgg(headerPacket []byte, lastGranule uint64) []byte {
+	return bytes.Join([][]byte{
+		createTestOggPage(0, headerPacket),
+		make([]byte, 2048),
+		createTestOggPage(lastGranule, make([]byte, 64)),
+	}, nil)
+}
+
+// createTestVorbisHeader 构造Vorbis标识头
+func createTestVorbisHeader(sampleRate, channels int) []byte {
+	packet := make([]byte, 30)
+	copy(packet, "\x01vorbis")
+	packet[11] = byte(channels)
+	binary.LittleEndian.PutUint32(packet[12:16], uint32(sampleRate))
+	return packet
+}
+
+// createTestOpusHeader 构造Opus标识头
+func createTestOpusHeader(channels int, preSkip uint16) []byte {
+	packet := make([]byte, 19)
+	copy(packet, "OpusHead")
+	packet[8] = 1
+	packet[9] = byte(channels)
+	binary.LittleEndian.PutUint16(packet[10:12], preSkip)
+	return packet
+}
+
+// TestExtractAudioInfo 测试解析音频的时长、码率、采样率和声道数
+func TestExtractAudioInfo(t *testing.T) {
+	testCases := []struct {
+		name       string
+		format     string
+		data       []byte
+		codec      string
+		duration   time.Duration
+		sampleRate int
+		channels   int
+		bitrate    int64 // 为0时不检查
+	}{
+		{"WAV", "wav", createTestWAV(8000, 2, make([]int16, 8000*2*3)), "PCM", 3 * time.Second, 8000, 2, 256000},
+		{"FLAC", "flac", createTestFLAC(44100, 2, 441000), "FLAC", 10 * time.Second, 44100, 2, 0},
+		{"固定码率MP3", "mp3", createTestMP3(false, 100), "MP3", time.Duration(float64(100*mp3TestFrameSize*8) / 128000 * float64(time.Second)), 44100, 2, 128000},
+		{"带ID3标签的MP3", "mp3", createTestMP3(true, 100), "MP3", time.Duration(float64(100*mp3TestFrameSize*8) / 128000 * float64(time.Second)), 44100, 2, 128000},
+		{"可变码率MP3", "mp3", createTestMP3Xing(441), "MP3", time.Duration(float64(441*1152) / 44100 * float64(time.Second)), 44100, 2, 0},
+		{"Vorbis", "ogg", createTestOgg(createTestVorbisHeader(44100, 2), 441000), "Vorbis", 10 * time.Second, 44100, 2, 0},
+		{"Opus", "ogg", createTestOgg(createTestOpusHeader(1, 312), 48000*5+312), "Opus", 5 * time.Second, 48000, 1, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info, err := ExtractAudioInfo(bytes.NewReader(tc.data), int64(len(tc.data)), tc.format)
+			require.NoError(t, err)
+			assert.Equal(t, tc.codec, info.Codec)
+			assert.InDelta(t, tc.duration.Seconds(), info.Duration.Seconds(), 0.01)
+			assert.Equal(t, tc.sampleRate, info.SampleRate)
+			assert.Equal(t, tc.channels, info.Channels)
+			assert.Equal(t, "audio", info.MediaType)
+			if tc.bitrate > 0 {
+				assert.Equal(t, tc.bitrate, info.Bitrate)
+			} else {
+				assert.Greater(t, info.Bitrate, int64(0), "应该按文件大小计算平均码率")
+			}
+		})
+	}
+
+	invalid := []struct {
+		format string
+		data   []byte
+	}{
+		{"wav", []byte("RIFF\x00\x00\x00\x00WAVEdata\x00\x00\x00\x00")},
+		{"flac", []byte("fLaC\x01\x00\x00\x22")},
+		{"mp3", append([]byte("ID3\x03\x00\x00\x00\x00\x00\x00"), make([]byte, 64)...)},
+		{"ogg", createTestOgg([]byte("\x7fFLAC"), 1000)},
+		{"aac", []byte{0xFF, 0xF1}},
+	}
+	for _, tc := range invalid {
+		_, err := ExtractAudioInfo(bytes.NewReader(tc.data), int64(len(tc.data)), tc.format)
+		assert.Error(t, err, tc.format)
+	}
+}
@@ -0,0 +1,83 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/gif"  // 注册GIF解码器
+	_ "image/jpeg" // 注册JPEG解码器
+	_ "image/png"  // 注册PNG解码器
+	"time"
+)
+
+// Info 图片或音频信息，无法提取的字段为零值
+type Info struct {
+	Format     string        // 格式
+	MediaType  string        // 媒体类型：image/audio
+	Width      int           // 图片宽度
+	Height     int           // 图片高度
+	Duration   time.Duration // 音频时长
+	Bitrate    int64         // 音频码率（bps）
+	Codec      string        // 编码，如JPEG、MP3
+	SampleRate int           // 音频采样率（Hz）
+	Channels   int           // 音频声道数
+}
+
+// imageCodecs 图片格式对应的编码名称
+var imageCodecs = map[string]string{
+	"jpg":  "JPEG",
+	"png":  "PNG",
+	"gif":  "GIF",
+	"webp": "WebP",
+}
+
+// ExtractImageInfo 从图片文件头提取宽高，JPEG的尺寸可能位于较大的EXIF数据之后，需要传入足够的文件头
+func ExtractImageInfo(data []byte, format string) (*Info, error) {
+	info := &Info{Format: format, MediaType: MediaTypeForFormat(format), Codec: imageCodecs[format]}
+	if format == "webp" {
+		width, height, err := parseWebPSize(data)
+		if err != nil {
+			return nil, err
+		}
+		info.Width, info.Height = width, height
+		return info, nil
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("读取图片尺寸失败: %w", err)
+	}
+	info.Width, info.Height = config.Width, config.Height
+	return info, nil
+}
+
+// parseWebPSize 读取WebP图片的画布尺寸，支持有损（VP8）、无损（VP8L）和扩展格式（VP8X）
+func parseWebPSize(data []byte) (int, int, error) {
+	if len(data) < 30 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return 0, 0, fmt.Errorf("无效的WebP文件头")
+	}
+	chunk := data[20:]
+	switch string(data[12:16]) {
+	case "VP8 ":
+		// 关键帧头部：3字节帧标记、3字节起始码，之后为14位宽高
+		if len(chunk) < 10 || !bytes.Equal(chunk[3:6], []byte{0x9D, 0x01, 0x2A}) {
+			return 0, 0, fmt.Errorf("无效的VP8关键帧")
+		}
+		return int(binary.LittleEndian.Uint16(chunk[6:8]) & 0x3FFF), int(binary.LittleEndian.Uint16(chunk[8:10]) & 0x3FFF), nil
+	case "VP8L":
+		// 签名字节之后依次为14位的宽度-1和高度-1
+		if len(chunk) < 5 || chunk[0] != 0x2F {
+			return 0, 0, fmt.Errorf("无效的VP8L签名")
+		}
+		bits := binary.LittleEndian.Uint32(chunk[1:5])
+		return int(bits&0x3FFF) + 1, int(bits>>14&0x3FFF) + 1, nil
+	case "VP8X":
+		// 4字节标志之后为24位的画布宽度-1和高度-1
+		if len(chunk) < 10 {
+			return 0, 0, fmt.Errorf("无效的VP8X块")
+		}
+		return int(readUint24LE(chunk[4:7])) + 1, int(readUint24LE(chunk[7:10])) + 1, nil
+	}
+	return 0, 0, fmt.Errorf("不支持的WebP编码: %s", data[12:16])
+}
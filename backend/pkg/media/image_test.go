@@ -0,0 +1,95 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTestJPEG 生成指定尺寸的JPEG图片
+func createTestJPEG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)), nil))
+	return buf.Bytes()
+}
+
+// createTestPNG 生成指定尺寸的PNG图片
+func createTestPNG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+// createTestGIF 生成指定尺寸的GIF图片
+func createTestGIF(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, gif.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)), nil))
+	return buf.Bytes()
+}
+
+// createTestWebP 构造只包含一个图像块的WebP文件头
+func createTestWebP(chunkType string, payload []byte) []byte {
+	data := make([]byte, 20, 20+len(payload))
+	copy(data[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(data[4:8], uint32(12+len(payload)))
+	copy(data[8:12], "WEBP")
+	copy(data[12:16], chunkType)
+	binary.LittleEndian.PutUint32(data[16:20], uint32(len(payload)))
+	return append(data, payload...)
+}
+
+// createTestWebPLossless 构造无损（VP8L）WebP文件头
+func createTestWebPLossless(width, height int) []byte {
+	payload := make([]byte, 10)
+	payload[0] = 0x2F
+	binary.LittleEndian.PutUint32(payload[1:5], uint32(width-1)|uint32(height-1)<<14)
+	return createTestWebP("VP8L", payload)
+}
+
+// TestExtractImageInfo 测试提取图片尺寸
+func TestExtractImageInfo(t *testing.T) {
+	lossy := make([]byte, 10)
+	copy(lossy[3:6], []byte{0x9D, 0x01, 0x2A})
+	binary.LittleEndian.PutUint16(lossy[6:8], 1920)
+	binary.LittleEndian.PutUint16(lossy[8:10], 1080)
+	extended := make([]byte, 10)
+	copy(extended[4:7], []byte{0x7F, 0x0C, 0x00})  // 3200-1
+	copy(extended[7:10], []byte{0x57, 0x02, 0x00}) // 600-1
+
+	testCases := []struct {
+		name          string
+		format        string
+		data          []byte
+		codec         string
+		width, height int
+	}{
+		{"JPEG", "jpg", createTestJPEG(t, 320, 240), "JPEG", 320, 240},
+		{"PNG", "png", createTestPNG(t, 100, 50), "PNG", 100, 50},
+		{"GIF", "gif", createTestGIF(t, 64, 48), "GIF", 64, 48},
+		{"有损WebP", "webp", createTestWebP("VP8 ", lossy), "WebP", 1920, 1080},
+		{"无损WebP", "webp", createTestWebPLossless(640, 480), "WebP", 640, 480},
+		{"扩展WebP", "webp", createTestWebP("VP8X", extended), "WebP", 3200, 600},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info, err := ExtractImageInfo(tc.data, tc.format)
+			require.NoError(t, err)
+			assert.Equal(t, tc.width, info.Width)
+			assert.Equal(t, tc.height, info.Height)
+			assert.Equal(t, tc.codec, info.Codec)
+			assert.Equal(t, "image", info.MediaType)
+		})
+	}
+
+	_, err := ExtractImageInfo(createTestPNG(t, 10, 10)[:20], "png")
+	assert.Error(t, err, "截断的文件头应该返回错误")
+	_, err = ExtractImageInfo(createTestWebP("ALPH", make([]byte, 10)), "webp")
+	assert.Error(t, err)
+}
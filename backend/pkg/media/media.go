@@ -0,0 +1,170 @@
+// Package media 图片和音频文件的格式识别和信息提取，视频文件由video包处理
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+// 可通过魔数识别的图片和音频格式，允许上传的格式只能从中选择
+var (
+	ImageFormats = []string{"jpg", "png", "gif", "webp"}
+	AudioFormats = []string{"mp3", "flac", "wav", "ogg"}
+)
+
+// KnownFormats 可识别的图片和音频格式
+var KnownFormats = slices.Concat(ImageFormats, AudioFormats)
+
+// formatContentTypes 格式对应的标准内容类型
+var formatContentTypes = map[string]string{
+	"jpg":  "image/jpeg",
+	"png":  "image/png",
+	"gif":  "image/gif",
+	"webp": "image/webp",
+	"mp3":  "audio/mpeg",
+	"flac": "audio/flac",
+	"wav":  "audio/wav",
+	"ogg":  "audio/ogg",
+}
+
+// formatAliases 同一格式的其他扩展名
+var formatAliases = map[string]string{
+	"jpeg": "jpg",
+	"oga":  "ogg",
+	"opus": "ogg",
+}
+
+// FormatFromFilename 从文件扩展名获取格式，扩展名为别名时返回对应的格式
+func FormatFromFilename(filename string) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+	if format, ok := formatAliases[ext]; ok {
+		return format
+	}
+	return ext
+}
+
+// MediaTypeForFormat 获取格式对应的媒体类型，不是图片或音频格式时返回空
+func MediaTypeForFormat(format string) string {
+	switch {
+	case slices.Contains(ImageFormats, format):
+		return metadata.MediaTypeImage
+	case slices.Contains(AudioFormats, format):
+		return metadata.MediaTypeAudio
+	}
+	return ""
+}
+
+// ContentTypeForFormat 获取格式对应的内容类型，未知格式返回application/octet-stream
+func ContentTypeForFormat(format string) string {
+	if contentType, ok := formatContentTypes[format]; ok {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// FormatsForMediaTypes 获取媒体类型包含的格式，视频格式由video包管理
+func FormatsForMediaTypes(mediaTypes []string) []string {
+	var formats []string
+	for _, mediaType := range mediaTypes {
+		switch mediaType {
+		case metadata.MediaTypeImage:
+			formats = append(formats, ImageFormats...)
+		case metadata.MediaTypeAudio:
+			formats = append(formats, AudioFormats...)
+		}
+	}
+	return formats
+}
+
+// DetectFormat 通过魔数检测图片和音频格式
+func DetectFormat(data []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return "jpg", nil
+	case bytes.HasPrefix(data, []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}):
+		return "png", nil
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return "gif", nil
+	case len(data) >= 12 && bytes.HasPrefix(data, []byte("RIFF")) && string(data[8:12]) == "WEBP":
+		return "webp", nil
+	case len(data) >= 12 && bytes.HasPrefix(data, []byte("RIFF")) && string(data[8:12]) == "WAVE":
+		return "wav", nil
+	case bytes.HasPrefix(data, []byte("fLaC")):
+		return "flac", nil
+	case bytes.HasPrefix(data, []byte("OggS")):
+		return "ogg", nil
+	case bytes.HasPrefix(data, []byte("ID3")):
+		return "mp3", nil
+	}
+	if _, err := parseMP3FrameHeader(data); err == nil {
+		return "mp3", nil
+	}
+	return "", fmt.Errorf("无法识别的图片或音频格式")
+}
+
+// Validator 图片和音频格式验证器
+type Validator struct {
+	supportedFormats map[string]bool
+}
+
+// ValidationResult 格式验证结果
+type ValidationResult struct {
+	Format    string // 检测到的格式
+	MediaType string // 媒体类型：image/audio
+}
+
+// NewValidator 创建只允许指定格式的验证器，格式必须属于KnownFormats
+func NewValidator(formats []string) (*Validator, error) {
+	supported := make(map[string]bool, len(formats))
+	for _, format := range formats {
+		format = strings.ToLower(strings.TrimSpace(format))
+		if !slices.Contains(KnownFormats, format) {
+			return nil, fmt.Errorf("无法识别的图片或音频格式: %s", format)
+		}
+		supported[format] = true
+	}
+	return &Validator{supportedFormats: supported}, nil
+}
+
+// IsFormatSupported 检查格式是否允许上传，format为文件扩展名或格式
+func (v *Validator) IsFormatSupported(format string) bool {
+	if v == nil {
+		return false
+	}
+	if alias, ok := formatAliases[format]; ok {
+		format = alias
+	}
+	return v.supportedFormats[format]
+}
+
+// Validate 通过魔数检测文件格式，要求与文件扩展名一致
+func (v *Validator) Validate(filename string, data []byte) (*ValidationResult, error) {
+	format := FormatFromFilename(filename)
+	if !v.IsFormatSupported(format) {
+		return nil, fmt.Errorf("不支持的格式: %s", format)
+	}
+	detected, err := DetectFormat(data)
+	if err != nil {
+		return nil, err
+	}
+	if detected != format {
+		return nil, fmt.Errorf("文件内容与扩展名不匹配：扩展名为 %s，但内容为 %s", format, detected)
+	}
+	return &ValidationResult{Format: detected, MediaType: MediaTypeForFormat(detected)}, nil
+}
+
+// readUint24 读取大端序的24位整数
+func readUint24(data []byte) uint32 {
+	return uint32(data[0])<<16 | uint32(data[1])<<8 | uint32(data[2])
+}
+
+// readUint24LE 读取小端序的24位整数
+func readUint24LE(data []byte) uint32 {
+	return uint32(binary.LittleEndian.Uint16(data[0:2])) | uint32(data[2])<<16
+}
@@ -0,0 +1,80 @@
+package media
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+// TestDetectFormat 测试通过魔数识别图片和音频格式
+func TestDetectFormat(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{"JPEG", createTestJPEG(t, 16, 16), "jpg"},
+		{"PNG", createTestPNG(t, 16, 16), "png"},
+		{"GIF", createTestGIF(t, 16, 16), "gif"},
+		{"WebP", createTestWebPLossless(640, 480), "webp"},
+		{"WAV", createTestWAV(8000, 1, make([]int16, 800)), "wav"},
+		{"FLAC", createTestFLAC(44100, 2, 441000), "flac"},
+		{"Ogg", createTestOgg(createTestVorbisHeader(44100, 2), 441000), "ogg"},
+		{"带ID3标签的MP3", createTestMP3(true, 10), "mp3"},
+		{"没有ID3标签的MP3", createTestMP3(false, 10), "mp3"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			format, err := DetectFormat(tc.data)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, format)
+		})
+	}
+
+	for _, data := range [][]byte{
+		nil,
+		[]byte("plain text file"),
+		{0x00, 0x00, 0x00, 0x20, 'f', 't', 'y', 'p', 'i', 's', 'o', 'm'},
+		[]byte("RIFF\x00\x00\x00\x00AVI LIST"),
+	} {
+		_, err := DetectFormat(data)
+		assert.Error(t, err, "%q", data)
+	}
+}
+
+// TestValidator 测试按允许的格式和扩展名验证文件
+func TestValidator(t *testing.T) {
+	validator, err := NewValidator(FormatsForMediaTypes([]string{metadata.MediaTypeImage}))
+	require.NoError(t, err)
+
+	result, err := validator.Validate("photo.JPEG", createTestJPEG(t, 16, 16))
+	require.NoError(t, err)
+	assert.Equal(t, "jpg", result.Format)
+	assert.Equal(t, metadata.MediaTypeImage, result.MediaType)
+
+	_, err = validator.Validate("photo.png", createTestJPEG(t, 16, 16))
+	assert.ErrorContains(t, err, "不匹配", "扩展名与内容不一致时应该拒绝")
+	_, err = validator.Validate("song.wav", createTestWAV(8000, 1, make([]int16, 800)))
+	assert.ErrorContains(t, err, "不支持的格式", "未启用音频时应该拒绝")
+
+	assert.True(t, validator.IsFormatSupported("jpeg"), "应该识别扩展名别名")
+	assert.False(t, validator.IsFormatSupported("mp4"))
+	assert.False(t, (*Validator)(nil).IsFormatSupported("jpg"))
+
+	_, err = NewValidator([]string{"bmp"})
+	assert.Error(t, err)
+}
+
+// TestFormatHelpers 测试格式对应的媒体类型和内容类型
+func TestFormatHelpers(t *testing.T) {
+	assert.Equal(t, metadata.MediaTypeAudio, MediaTypeForFormat("flac"))
+	assert.Equal(t, metadata.MediaTypeImage, MediaTypeForFormat("webp"))
+	assert.Empty(t, MediaTypeForFormat("mp4"))
+	assert.Equal(t, "audio/mpeg", ContentTypeForFormat("mp3"))
+	assert.Equal(t, "application/octet-stream", ContentTypeForFormat("mp4"))
+	assert.Equal(t, "ogg", FormatFromFilename("voice.opus"))
+	assert.Equal(t, AudioFormats, FormatsForMediaTypes([]string{metadata.MediaTypeVideo, metadata.MediaTypeAudio}))
+}
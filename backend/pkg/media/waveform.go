@@ -0,0 +1,167 @@
+package media
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// 使用FFmpeg解码波形时的单声道采样率和每个区间的样本数，区间数量超过需要时再合并
+const (
+	waveformSampleRate  = 8000
+	waveformBlockFrames = 800
+)
+
+// WaveformExtractor 音频波形提取器，计算每个时间区间的峰值振幅（0~1）
+// 16位PCM的WAV直接解析，其他格式使用FFmpeg解码
+type WaveformExtractor struct {
+	ffmpegPath string
+	timeout    time.Duration
+}
+
+// NewWaveformExtractor 创建波形提取器
+func NewWaveformExtractor(ffmpegPath string) *WaveformExtractor {
+	if ffmpegPath == "" {
+		ffmpegPath = "ffmpeg"
+	}
+	return &WaveformExtractor{
+		ffmpegPath: ffmpegPath,
+		timeout:    2 * time.Minute,
+	}
+}
+
+// IsAvailable 检查FFmpeg是否可用
+func (e *WaveformExtractor) IsAvailable() bool {
+	if e == nil {
+		return false
+	}
+	_, err := exec.LookPath(e.ffmpegPath)
+	return err == nil
+}
+
+// Extract 读取完整的音频文件并计算buckets个区间的峰值振幅，size为文件大小
+// extractor为nil或FFmpeg不可用时只支持16位PCM的WAV
+func (e *WaveformExtractor) Extract(ctx context.Context, reader io.Reader, size int64, format string, buckets int) ([]float64, error) {
+	if buckets <= 0 {
+		return nil, fmt.Errorf("波形区间数量必须大于0")
+	}
+	if format == "wav" {
+		// 先读取文件头判断是否为16位PCM，其他编码交给FFmpeg
+		header := make([]byte, 4096)
+		n, err := io.ReadFull(reader, header)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("读取WAV文件头失败: %w", err)
+		}
+		header = header[:n]
+		reader = io.MultiReader(bytes.NewReader(header), reader)
+		if wav, err := parseWAVFormat(bytes.NewReader(header), size); err == nil && wav.AudioFormat == 1 && wav.BitsPerSample == 16 {
+			if _, err := io.CopyN(io.Discard, reader, wav.DataOffset); err != nil {
+				return nil, fmt.Errorf("读取WAV数据失败: %w", err)
+			}
+			return pcmPeaks(io.LimitReader(reader, wav.DataSize), wav.Channels, buckets, max(1, wav.DataSize/int64(2*wav.Channels)/int64(buckets)))
+		}
+	}
+	if !e.IsAvailable() {
+		return nil, fmt.Errorf("FFmpeg不可用，无法解码%s音频", format)
+	}
+	return e.extractWithFFmpeg(ctx, reader, buckets)
+}
+
+// extractWithFFmpeg 使用FFmpeg将音频解码为单声道16位PCM，按固定区间计算峰值后合并为buckets个区间
+func (e *WaveformExtractor) extractWithFFmpeg(ctx context.Context, reader io.Reader, buckets int) ([]float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.ffmpegPath, e.buildArgs()...)
+	cmd.Stdin = reader
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("启动FFmpeg失败: %w", err)
+	}
+
+	blocks, readErr := pcmPeaks(stdout, 1, 0, waveformBlockFrames)
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("FFmpeg解码音频失败: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if readErr != nil {
+		return nil, readErr
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("音频没有可解码的样本")
+	}
+	return mergePeaks(blocks, buckets), nil
+}
+
+// buildArgs 构建FFmpeg参数：从标准输入读取，输出单声道16位PCM到标准输出
+func (e *WaveformExtractor) buildArgs() []string {
+	return []string{
+		"-v", "error",
+		"-i", "pipe:0",
+		"-vn",
+		"-ac", "1",
+		"-ar", fmt.Sprint(waveformSampleRate),
+		"-f", "s16le",
+		"pipe:1",
+	}
+}
+
+// pcmPeaks 读取交错的16位小端PCM，每frames帧计算一个峰值（所有声道的最大振幅）
+// buckets大于0时最多返回buckets个区间，剩余的样本计入最后一个区间
+func pcmPeaks(reader io.Reader, channels, buckets int, frames int64) ([]float64, error) {
+	if channels <= 0 {
+		return nil, fmt.Errorf("声道数无效: %d", channels)
+	}
+	buffered := bufio.NewReader(reader)
+	sample := make([]byte, 2)
+	var peaks []float64
+	var peak float64
+	var count int64
+	for {
+		if _, err := io.ReadFull(buffered, sample); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return nil, fmt.Errorf("读取PCM数据失败: %w", err)
+		}
+		amplitude := float64(int16(binary.LittleEndian.Uint16(sample)))
+		if amplitude < 0 {
+			amplitude = -amplitude
+		}
+		peak = max(peak, min(amplitude/32767, 1))
+
+		count++
+		if count == frames*int64(channels) && (buckets <= 0 || len(peaks) < buckets-1) {
+			peaks = append(peaks, peak)
+			peak, count = 0, 0
+		}
+	}
+	if count > 0 {
+		peaks = append(peaks, peak)
+	}
+	return peaks, nil
+}
+
+// mergePeaks 将峰值合并为buckets个区间，峰值数量不足时保持原样
+func mergePeaks(peaks []float64, buckets int) []float64 {
+	if len(peaks) <= buckets {
+		return peaks
+	}
+	merged := make([]float64, buckets)
+	for i, peak := range peaks {
+		bucket := i * buckets / len(peaks)
+		merged[bucket] = max(merged[bucket], peak)
+	}
+	return merged
+}
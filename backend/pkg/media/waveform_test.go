@@ -0,0 +1,65 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWaveformExtractor_WAV 测试不使用FFmpeg解析16位PCM的WAV波形
+func TestWaveformExtractor_WAV(t *testing.T) {
+	// 立体声，前半段静音，后半段右声道满幅
+	samples := make([]int16, 2*1000)
+	for i := 1000; i < len(samples); i += 2 {
+		samples[i+1] = -32768
+	}
+	data := createTestWAV(8000, 2, samples)
+
+	var extractor *WaveformExtractor
+	peaks, err := extractor.Extract(context.Background(), bytes.NewReader(data), int64(len(data)), "wav", 4)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0, 0, 1, 1}, peaks)
+
+	peaks, err = extractor.Extract(context.Background(), bytes.NewReader(data), int64(len(data)), "wav", 3)
+	require.NoError(t, err)
+	assert.Len(t, peaks, 3, "样本数不能整除时剩余样本计入最后一个区间")
+
+	_, err = extractor.Extract(context.Background(), bytes.NewReader(createTestMP3(false, 10)), 0, "mp3", 4)
+	assert.Error(t, err, "FFmpeg不可用时无法解码其他格式")
+}
+
+// TestWaveformExtractor_FFmpeg 测试使用FFmpeg解码的PCM计算波形
+func TestWaveformExtractor_FFmpeg(t *testing.T) {
+	// 模拟FFmpeg：忽略输入，输出1600个单声道样本，第二个区间为半幅
+	pcm := make([]int16, 1600)
+	pcm[900] = 16384
+	output := createTestWAV(8000, 1, pcm)[44:]
+	outputPath := filepath.Join(t.TempDir(), "pcm")
+	require.NoError(t, os.WriteFile(outputPath, output, 0o644))
+	ffmpegPath := filepath.Join(t.TempDir(), "ffmpeg")
+	require.NoError(t, os.WriteFile(ffmpegPath, []byte("#!/bin/sh\ncat > /dev/null\ncat "+outputPath+"\n"), 0o755))
+
+	extractor := NewWaveformExtractor(ffmpegPath)
+	require.True(t, extractor.IsAvailable())
+	peaks, err := extractor.Extract(context.Background(), bytes.NewReader(createTestMP3(false, 10)), 0, "mp3", 100)
+	require.NoError(t, err)
+	require.Len(t, peaks, 2, "区间数量不足时保持原样")
+	assert.Equal(t, 0.0, peaks[0])
+	assert.InDelta(t, 0.5, peaks[1], 0.001)
+
+	failing := filepath.Join(t.TempDir(), "ffmpeg-fail")
+	require.NoError(t, os.WriteFile(failing, []byte("#!/bin/sh\necho invalid data >&2\nexit 1\n"), 0o755))
+	_, err = NewWaveformExtractor(failing).Extract(context.Background(), bytes.NewReader(nil), 0, "ogg", 10)
+	assert.ErrorContains(t, err, "invalid data")
+}
+
+// TestMergePeaks 测试合并峰值区间
+func TestMergePeaks(t *testing.T) {
+	assert.Equal(t, []float64{0.5, 0.9}, mergePeaks([]float64{0.1, 0.5, 0.9, 0.2}, 2))
+	assert.Equal(t, []float64{0.3}, mergePeaks([]float64{0.3}, 5))
+}
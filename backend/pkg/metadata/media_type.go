@@ -0,0 +1,33 @@
+package metadata
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 媒体类型，视频之外的图片和音频不进行HLS打包和预览图生成
+const (
+	MediaTypeVideo = "video" // 视频
+	MediaTypeImage = "image" // 图片，缩略图由原图缩放生成
+	MediaTypeAudio = "audio" // 音频，缩略图为波形图
+)
+
+// MediaTypes 所有媒体类型
+var MediaTypes = []string{MediaTypeVideo, MediaTypeImage, MediaTypeAudio}
+
+// NormalizeMediaType 规范化媒体类型，空值按视频处理（媒体类型支持之前上传的记录），无效的媒体类型返回错误
+func NormalizeMediaType(mediaType string) (string, error) {
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	switch mediaType {
+	case "":
+		return MediaTypeVideo, nil
+	case MediaTypeVideo, MediaTypeImage, MediaTypeAudio:
+		return mediaType, nil
+	}
+	return "", fmt.Errorf("媒体类型必须为video、image或audio: %s", mediaType)
+}
+
+// IsVideo 是否为视频，未设置媒体类型的记录按视频处理
+func (m *FileMetadata) IsVideo() bool {
+	return m.MediaType == "" || m.MediaType == MediaTypeVideo
+}
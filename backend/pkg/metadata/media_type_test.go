@@ -0,0 +1,61 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNormalizeMediaType 测试媒体类型规范化
+func TestNormalizeMediaType(t *testing.T) {
+	for input, expected := range map[string]string{
+		"":        MediaTypeVideo,
+		"video":   MediaTypeVideo,
+		" Image ": MediaTypeImage,
+		"AUDIO":   MediaTypeAudio,
+	} {
+		mediaType, err := NormalizeMediaType(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, mediaType, input)
+	}
+
+	_, err := NormalizeMediaType("document")
+	assert.Error(t, err)
+}
+
+// TestMetadataService_MediaTypeFilter 测试保存时规范化媒体类型并按媒体类型过滤列表
+func TestMetadataService_MediaTypeFilter(t *testing.T) {
+	ctx := context.Background()
+	service := NewMetadataService()
+	for id, mediaType := range map[string]string{"legacy": "", "photo": MediaTypeImage, "song": MediaTypeAudio} {
+		require.NoError(t, service.SaveMetadata(ctx, &FileMetadata{
+			FileID:     id,
+			BucketName: "zhulong-videos",
+			ObjectName: "videos/" + id,
+			FileName:   id,
+			Title:      id,
+			CreatedBy:  "user-1",
+			FileSize:   1024,
+			MediaType:  mediaType,
+		}))
+	}
+
+	legacy, err := service.GetMetadata(ctx, "legacy")
+	require.NoError(t, err)
+	assert.Equal(t, MediaTypeVideo, legacy.MediaType, "未设置媒体类型的记录按视频处理")
+	assert.True(t, (&FileMetadata{}).IsVideo())
+	assert.False(t, (&FileMetadata{MediaType: MediaTypeAudio}).IsVideo())
+
+	for mediaType, expected := range map[string]string{
+		MediaTypeVideo: "legacy",
+		MediaTypeImage: "photo",
+		MediaTypeAudio: "song",
+	} {
+		resp, err := service.ListMetadata(ctx, &ListMetadataRequest{Limit: 10, MediaType: mediaType})
+		require.NoError(t, err)
+		require.Len(t, resp.Items, 1, mediaType)
+		assert.Equal(t, expected, resp.Items[0].FileID)
+	}
+}
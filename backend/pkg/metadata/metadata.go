@@ -2,18 +2,47 @@ package metadata
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 )
 
+// ErrUpdateConflict 元数据已被其他请求修改
+var ErrUpdateConflict = errors.New("元数据已被修改，请刷新后重试")
+
 // MetadataService 文件元数据管理服务
 type MetadataService struct {
 	// 使用内存存储作为简单实现，实际项目中应该使用数据库
 	storage map[string]*FileMetadata
-	mutex   sync.RWMutex
+	// tagIndex 标签到文件ID集合的索引，相当于标签与文件的关联表
+	tagIndex map[string]map[string]struct{}
+	// stats 聚合统计，随元数据变化增量维护
+	stats *Stats
+	mutex sync.RWMutex
+}
+
+// TagCount 标签及使用该标签的文件数量
+type TagCount struct {
+	Tag   string `json:"tag"`   // 规范化后的标签
+	Count int    `json:"count"` // 文件数量
+}
+
+// Chapter 视频章节
+type Chapter struct {
+	Title     string `json:"title"`      // 章节标题
+	StartTime int64  `json:"start_time"` // 开始时间（毫秒）
+	EndTime   int64  `json:"end_time"`   // 结束时间（毫秒）
+}
+
+// ClipSource 剪辑片段的来源
+type ClipSource struct {
+	FileID    string `json:"file_id"`    // 源视频ID，源视频删除后保留
+	StartTime int64  `json:"start_time"` // 片段在源视频中的开始时间（毫秒）
+	EndTime   int64  `json:"end_time"`   // 片段在源视频中的结束时间（毫秒）
 }
 
 // FileMetadata 文件元数据结构
@@ -24,28 +53,77 @@ type FileMetadata struct {
 	FileName    string    `json:"file_name"`    // 原始文件名
 	FileSize    int64     `json:"file_size"`    // 文件大小（字节）
 	ContentType string    `json:"content_type"` // 文件类型
+	MediaType   string    `json:"media_type"`   // 媒体类型：video/image/audio，保存时空值按视频处理
 	Title       string    `json:"title"`        // 文件标题
 	Description string    `json:"description"`  // 文件描述
 	Tags        []string  `json:"tags"`         // 文件标签
 	Duration    int64     `json:"duration"`     // 视频时长（秒）
-	Resolution  string    `json:"resolution"`   // 分辨率
+	Width       int       `json:"width"`        // 显示宽度（像素），未知时为0
+	Height      int       `json:"height"`       // 显示高度（像素），未知时为0
+	Rotation    int       `json:"rotation"`     // 显示时的顺时针旋转角度（0/90/180/270），宽高已按旋转交换
 	Bitrate     int64     `json:"bitrate"`      // 比特率
+	VideoCodec  string    `json:"video_codec"`  // 视频编码
+	AudioCodec  string    `json:"audio_codec"`  // 音频编码，没有音频轨道时为空
+	FrameRate   float64   `json:"frame_rate"`   // 帧率（fps）
 	Thumbnail   string    `json:"thumbnail"`    // 缩略图路径
+	Preview     string    `json:"preview"`      // 动态预览路径
+	Checksum    string    `json:"checksum"`     // 文件内容的SHA-256校验和（十六进制）
+	Chapters    []Chapter `json:"chapters"`     // 章节，按开始时间排序
+	Visibility  string    `json:"visibility"`   // 可见性：private/unlisted/public，保存时空值按私有处理
+	Moderation  string    `json:"moderation"`   // 内容审核状态：空值表示未被审核钩子标记，pending/rejected/approved
+	Status      string    `json:"status"`       // 处理状态：processing/ready/failed，保存时空值按已就绪处理
+	Archived    bool      `json:"archived"`     // 视频文件是否已移动到归档存储桶
+	ArchivedAt  time.Time `json:"archived_at"`  // 归档时间，未归档时为零值
+	RestoredAt  time.Time `json:"restored_at"`  // 最近一次从归档恢复的时间
 	CreatedBy   string    `json:"created_by"`   // 创建者
 	CreatedAt   time.Time `json:"created_at"`   // 创建时间
 	UpdatedAt   time.Time `json:"updated_at"`   // 更新时间
+
+	// 内容版本：替换视频文件时原文件保存为历史版本，可以恢复为当前内容
+	Version          int       `json:"version"`            // 当前内容的版本号，保存时0按1处理
+	VersionCreatedBy string    `json:"version_created_by"` // 上传当前内容的用户，为空时为创建者
+	VersionCreatedAt time.Time `json:"version_created_at"` // 当前内容的上传时间，为零值时为创建时间
+	Versions         []Version `json:"versions"`           // 历史版本，按版本号升序
+
+	// 定时发布和到期：发布前只有上传者和管理员可以查看，到期后不再出现在其他用户的列表中
+	PublishAt    time.Time `json:"publish_at"`    // 定时发布时间，零值表示立即发布
+	ExpiresAt    time.Time `json:"expires_at"`    // 到期时间，零值表示不过期
+	ExpiryAction string    `json:"expiry_action"` // 到期处理方式：unlist/delete，为空时为unlist
+
+	// ClipSource 由其他视频剪辑生成时的来源，nil表示不是剪辑片段
+	ClipSource *ClipSource `json:"clip_source"`
 }
 
 // UpdateMetadataRequest 更新元数据请求
 type UpdateMetadataRequest struct {
-	FileID      string    `json:"file_id"`      // 文件ID
-	Title       *string   `json:"title"`        // 标题（可选）
-	Description *string   `json:"description"`  // 描述（可选）
-	Tags        *[]string `json:"tags"`         // 标签（可选）
-	Duration    *int64    `json:"duration"`     // 时长（可选）
-	Resolution  *string   `json:"resolution"`   // 分辨率（可选）
-	Bitrate     *int64    `json:"bitrate"`      // 比特率（可选）
-	Thumbnail   *string   `json:"thumbnail"`    // 缩略图（可选）
+	FileID      string     `json:"file_id"`     // 文件ID
+	Title       *string    `json:"title"`       // 标题（可选）
+	Description *string    `json:"description"` // 描述（可选）
+	FileSize    *int64     `json:"file_size"`   // 文件大小（可选）
+	Tags        *[]string  `json:"tags"`        // 标签（可选）
+	Duration    *int64     `json:"duration"`    // 时长（可选）
+	Width       *int       `json:"width"`       // 宽度（可选）
+	Height      *int       `json:"height"`      // 高度（可选）
+	Rotation    *int       `json:"rotation"`    // 旋转角度（可选）
+	Bitrate     *int64     `json:"bitrate"`     // 比特率（可选）
+	VideoCodec  *string    `json:"video_codec"` // 视频编码（可选）
+	AudioCodec  *string    `json:"audio_codec"` // 音频编码（可选）
+	FrameRate   *float64   `json:"frame_rate"`  // 帧率（可选）
+	Thumbnail   *string    `json:"thumbnail"`   // 缩略图（可选）
+	Preview     *string    `json:"preview"`     // 动态预览（可选）
+	Chapters    *[]Chapter `json:"chapters"`    // 章节（可选）
+	Visibility  *string    `json:"visibility"`  // 可见性（可选）
+	Moderation  *string    `json:"moderation"`  // 内容审核状态（可选）
+	Status      *string    `json:"status"`      // 处理状态（可选）
+
+	// 定时发布和到期（可选），时间为零值时取消
+	PublishAt    *time.Time `json:"publish_at"`    // 定时发布时间
+	ExpiresAt    *time.Time `json:"expires_at"`    // 到期时间
+	ExpiryAction *string    `json:"expiry_action"` // 到期处理方式
+
+	// ExpectedUpdatedAt 客户端读取时的更新时间（可选），与当前记录不一致时返回ErrUpdateConflict
+	// 按毫秒精度比较，与API返回的时间戳保持一致
+	ExpectedUpdatedAt *time.Time `json:"expected_updated_at"`
 }
 
 // SearchMetadataRequest 搜索元数据请求
@@ -65,10 +143,25 @@ type SearchMetadataResponse struct {
 
 // ListMetadataRequest 列表元数据请求
 type ListMetadataRequest struct {
-	Offset int    `json:"offset"` // 偏移量
-	Limit  int    `json:"limit"`  // 数量限制
-	SortBy string `json:"sort_by"` // 排序字段
-	Order  string `json:"order"`  // 排序方向 (asc/desc)
+	Offset int    `json:"offset"`  // 偏移量
+	Limit  int    `json:"limit"`   // 数量限制
+	SortBy string `json:"sort_by"` // 排序条件，逗号分隔的"字段 [asc|desc]"，见ParseSort
+	Order  string `json:"order"`   // 未指定方向的字段的排序方向 (asc/desc)，默认降序
+
+	// 过滤条件，零值表示不限制
+	ContentTypes  []string  `json:"content_types"`  // 内容类型（匹配任一，不区分大小写）
+	MinDuration   int64     `json:"min_duration"`   // 最短时长（秒）
+	MaxDuration   int64     `json:"max_duration"`   // 最长时长（秒）
+	MinWidth      int       `json:"min_width"`      // 最小宽度
+	MinHeight     int       `json:"min_height"`     // 最小高度
+	CreatedBy     string    `json:"created_by"`     // 创建者
+	Tags          []string  `json:"tags"`           // 标签（需包含全部）
+	FileIDs       []string  `json:"file_ids"`       // 限定文件ID范围，nil表示不限制，空切片表示不匹配任何文件
+	CreatedAfter  time.Time `json:"created_after"`  // 创建时间下限（包含）
+	CreatedBefore time.Time `json:"created_before"` // 创建时间上限（不包含）
+	ListedFor     *Viewer   `json:"listed_for"`     // 只返回出现在该用户列表中的文件，nil表示不按可见性过滤
+	Status        string    `json:"status"`         // 处理状态
+	MediaType     string    `json:"media_type"`     // 媒体类型
 }
 
 // ListMetadataResponse 列表元数据响应
@@ -80,8 +173,10 @@ type ListMetadataResponse struct {
 // NewMetadataService 创建元数据服务
 func NewMetadataService() *MetadataService {
 	return &MetadataService{
-		storage: make(map[string]*FileMetadata),
-		mutex:   sync.RWMutex{},
+		storage:  make(map[string]*FileMetadata),
+		tagIndex: make(map[string]map[string]struct{}),
+		stats:    newStats(),
+		mutex:    sync.RWMutex{},
 	}
 }
 
@@ -102,11 +197,23 @@ func (s *MetadataService) SaveMetadata(ctx context.Context, metadata *FileMetada
 	}
 	metadata.UpdatedAt = now
 
-	// 去重标签
+	// 规范化并去重标签，未设置可见性的视频默认为私有，未设置处理状态的视频默认为已就绪，未设置媒体类型的默认为视频
 	metadata.Tags = s.deduplicateTags(metadata.Tags)
+	metadata.Visibility, _ = NormalizeVisibility(metadata.Visibility)
+	metadata.Status, _ = NormalizeStatus(metadata.Status)
+	metadata.MediaType, _ = NormalizeMediaType(metadata.MediaType)
+	metadata.Version = max(metadata.Version, 1)
 
-	// 保存到存储
-	s.storage[metadata.FileID] = metadata
+	// 覆盖已有记录时先移除旧标签的索引和统计
+	if existing, exists := s.storage[metadata.FileID]; exists {
+		s.unindexTags(existing.FileID, existing.Tags)
+		s.addStats(existing, -1)
+	}
+
+	// 保存副本，避免调用方之后的修改绕过统计
+	s.storage[metadata.FileID] = s.copyMetadata(metadata)
+	s.indexTags(metadata.FileID, metadata.Tags)
+	s.addStats(metadata, 1)
 
 	return nil
 }
@@ -131,6 +238,28 @@ func (s *MetadataService) UpdateMetadata(ctx context.Context, req *UpdateMetadat
 		return fmt.Errorf("文件ID不能为空")
 	}
 
+	var visibility string
+	if req.Visibility != nil {
+		var err error
+		if visibility, err = NormalizeVisibility(*req.Visibility); err != nil {
+			return err
+		}
+	}
+	var status string
+	if req.Status != nil {
+		var err error
+		if status, err = NormalizeStatus(*req.Status); err != nil {
+			return err
+		}
+	}
+	var expiryAction string
+	if req.ExpiryAction != nil {
+		var err error
+		if expiryAction, err = NormalizeExpiryAction(*req.ExpiryAction); err != nil {
+			return err
+		}
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -139,6 +268,14 @@ func (s *MetadataService) UpdateMetadata(ctx context.Context, req *UpdateMetadat
 		return fmt.Errorf("元数据不存在: %s", req.FileID)
 	}
 
+	// 乐观并发控制
+	if req.ExpectedUpdatedAt != nil && req.ExpectedUpdatedAt.UnixMilli() != metadata.UpdatedAt.UnixMilli() {
+		return ErrUpdateConflict
+	}
+
+	s.addStats(metadata, -1)
+	defer s.addStats(metadata, 1)
+
 	// 更新字段
 	if req.Title != nil {
 		metadata.Title = *req.Title
@@ -146,24 +283,72 @@ func (s *MetadataService) UpdateMetadata(ctx context.Context, req *UpdateMetadat
 	if req.Description != nil {
 		metadata.Description = *req.Description
 	}
+	if req.FileSize != nil {
+		metadata.FileSize = *req.FileSize
+	}
 	if req.Tags != nil {
+		s.unindexTags(metadata.FileID, metadata.Tags)
 		metadata.Tags = s.deduplicateTags(*req.Tags)
+		s.indexTags(metadata.FileID, metadata.Tags)
 	}
 	if req.Duration != nil {
 		metadata.Duration = *req.Duration
 	}
-	if req.Resolution != nil {
-		metadata.Resolution = *req.Resolution
+	if req.Width != nil {
+		metadata.Width = *req.Width
+	}
+	if req.Height != nil {
+		metadata.Height = *req.Height
+	}
+	if req.Rotation != nil {
+		metadata.Rotation = *req.Rotation
 	}
 	if req.Bitrate != nil {
 		metadata.Bitrate = *req.Bitrate
 	}
+	if req.VideoCodec != nil {
+		metadata.VideoCodec = *req.VideoCodec
+	}
+	if req.AudioCodec != nil {
+		metadata.AudioCodec = *req.AudioCodec
+	}
+	if req.FrameRate != nil {
+		metadata.FrameRate = *req.FrameRate
+	}
 	if req.Thumbnail != nil {
 		metadata.Thumbnail = *req.Thumbnail
 	}
+	if req.Preview != nil {
+		metadata.Preview = *req.Preview
+	}
+	if req.Chapters != nil {
+		metadata.Chapters = slices.Clone(*req.Chapters)
+	}
+	if req.Visibility != nil {
+		metadata.Visibility = visibility
+	}
+	if req.Moderation != nil {
+		metadata.Moderation = *req.Moderation
+	}
+	if req.Status != nil {
+		metadata.Status = status
+	}
+	if req.PublishAt != nil {
+		metadata.PublishAt = *req.PublishAt
+	}
+	if req.ExpiresAt != nil {
+		metadata.ExpiresAt = *req.ExpiresAt
+	}
+	if req.ExpiryAction != nil {
+		metadata.ExpiryAction = expiryAction
+	}
 
-	// 更新时间戳
-	metadata.UpdatedAt = time.Now()
+	// 更新时间戳，保证毫秒级递增，避免同一毫秒内的修改绕过并发检查
+	now := time.Now()
+	if now.UnixMilli() <= metadata.UpdatedAt.UnixMilli() {
+		now = metadata.UpdatedAt.Truncate(time.Millisecond).Add(time.Millisecond)
+	}
+	metadata.UpdatedAt = now
 
 	return nil
 }
@@ -173,10 +358,13 @@ func (s *MetadataService) DeleteMetadata(ctx context.Context, fileID string) err
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	if _, exists := s.storage[fileID]; !exists {
+	metadata, exists := s.storage[fileID]
+	if !exists {
 		return fmt.Errorf("元数据不存在: %s", fileID)
 	}
 
+	s.unindexTags(fileID, metadata.Tags)
+	s.addStats(metadata, -1)
 	delete(s.storage, fileID)
 	return nil
 }
@@ -195,6 +383,124 @@ func (s *MetadataService) GetMetadataByObjectName(ctx context.Context, bucketNam
 	return nil, fmt.Errorf("未找到对象的元数据: %s/%s", bucketName, objectName)
 }
 
+// GetMetadataByChecksum 根据内容校验和获取最早保存的元数据，用于检测重复视频
+func (s *MetadataService) GetMetadataByChecksum(ctx context.Context, checksum string) (*FileMetadata, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var found *FileMetadata
+	for _, metadata := range s.storage {
+		if checksum == "" || metadata.Checksum != checksum {
+			continue
+		}
+		if found == nil || metadata.CreatedAt.Before(found.CreatedAt) {
+			found = metadata
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("未找到校验和对应的元数据: %s", checksum)
+	}
+
+	return s.copyMetadata(found), nil
+}
+
+// CountObjectReferences 统计引用指定存储对象的元数据数量，去重后多个视频可能共享同一对象
+// 以历史版本引用该对象的元数据也计入
+func (s *MetadataService) CountObjectReferences(ctx context.Context, bucketName, objectName string) int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	count := 0
+	for _, metadata := range s.storage {
+		if metadata.referencesObject(bucketName, objectName) {
+			count++
+		}
+	}
+	return count
+}
+
+// ListObjectReferences 获取以当前内容引用指定存储对象的所有元数据
+func (s *MetadataService) ListObjectReferences(ctx context.Context, bucketName, objectName string) []*FileMetadata {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var items []*FileMetadata
+	for _, metadata := range s.storage {
+		if metadata.BucketName == bucketName && metadata.ObjectName == objectName {
+			items = append(items, s.copyMetadata(metadata))
+		}
+	}
+	return items
+}
+
+// SetObjectArchived 设置引用指定存储对象的所有元数据的归档状态，返回更新的数量
+// 去重后共享同一对象的视频一起归档和恢复；归档状态不属于内容修改，不更新UpdatedAt
+func (s *MetadataService) SetObjectArchived(ctx context.Context, bucketName, objectName string, archived bool) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	count := 0
+	for _, metadata := range s.storage {
+		if metadata.BucketName != bucketName || metadata.ObjectName != objectName {
+			continue
+		}
+		s.addStats(metadata, -1)
+		if archived {
+			metadata.ArchivedAt = now
+		} else {
+			metadata.ArchivedAt = time.Time{}
+			metadata.RestoredAt = now
+		}
+		metadata.Archived = archived
+		s.addStats(metadata, 1)
+		count++
+	}
+	return count
+}
+
+// MoveObjectReferences 将引用bucketName中指定对象的所有元数据改为引用toBucket中的同名对象，返回更新的数量
+// 用于存储迁移，文件位置变化不属于内容修改，不更新UpdatedAt
+func (s *MetadataService) MoveObjectReferences(ctx context.Context, bucketName, objectName, toBucket string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	count := 0
+	for _, metadata := range s.storage {
+		if !metadata.referencesObject(bucketName, objectName) {
+			continue
+		}
+		if metadata.BucketName == bucketName && metadata.ObjectName == objectName {
+			metadata.BucketName = toBucket
+		}
+		for i := range metadata.Versions {
+			if metadata.Versions[i].BucketName == bucketName && metadata.Versions[i].ObjectName == objectName {
+				metadata.Versions[i].BucketName = toBucket
+			}
+		}
+		count++
+	}
+	return count
+}
+
+// GetStorageUsage 统计用户上传文件的总大小，包括视频的历史版本
+func (s *MetadataService) GetStorageUsage(ctx context.Context, createdBy string) (int64, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var total int64
+	for _, metadata := range s.storage {
+		if metadata.CreatedBy != createdBy {
+			continue
+		}
+		total += metadata.FileSize
+		for _, version := range metadata.Versions {
+			total += version.FileSize
+		}
+	}
+	return total, nil
+}
+
 // SearchMetadata 搜索文件元数据
 func (s *MetadataService) SearchMetadata(ctx context.Context, req *SearchMetadataRequest) (*SearchMetadataResponse, error) {
 	s.mutex.RLock()
@@ -233,19 +539,26 @@ func (s *MetadataService) SearchMetadata(ctx context.Context, req *SearchMetadat
 	}, nil
 }
 
-// ListMetadata 列出文件元数据
+// ListMetadata 列出文件元数据，排序条件无效时返回ErrInvalidSort
 func (s *MetadataService) ListMetadata(ctx context.Context, req *ListMetadataRequest) (*ListMetadataResponse, error) {
+	sortFields, err := ParseSort(req.SortBy, req.Order)
+	if err != nil {
+		return nil, err
+	}
+
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	// 获取所有元数据
+	// 获取符合过滤条件的元数据
 	var items []*FileMetadata
 	for _, metadata := range s.storage {
-		items = append(items, s.copyMetadata(metadata))
+		if s.matchesListFilter(metadata, req) {
+			items = append(items, s.copyMetadata(metadata))
+		}
 	}
 
 	// 排序
-	s.sortMetadata(items, req.SortBy, req.Order)
+	sortItems(items, sortFields)
 
 	// 应用分页
 	total := len(items)
@@ -282,8 +595,9 @@ func (s *MetadataService) AddTags(ctx context.Context, fileID string, tags []str
 	}
 
 	// 合并现有标签和新标签
-	allTags := append(metadata.Tags, tags...)
+	allTags := append(slices.Clone(metadata.Tags), tags...)
 	metadata.Tags = s.deduplicateTags(allTags)
+	s.indexTags(fileID, metadata.Tags)
 	metadata.UpdatedAt = time.Now()
 
 	return nil
@@ -302,23 +616,45 @@ func (s *MetadataService) RemoveTags(ctx context.Context, fileID string, tags []
 	// 创建要移除的标签映射
 	toRemove := make(map[string]bool)
 	for _, tag := range tags {
-		toRemove[tag] = true
+		toRemove[NormalizeTag(tag)] = true
 	}
 
 	// 过滤掉要移除的标签
-	var remainingTags []string
+	var remainingTags, removedTags []string
 	for _, tag := range metadata.Tags {
-		if !toRemove[tag] {
+		if toRemove[tag] {
+			removedTags = append(removedTags, tag)
+		} else {
 			remainingTags = append(remainingTags, tag)
 		}
 	}
 
 	metadata.Tags = remainingTags
+	s.unindexTags(fileID, removedTags)
 	metadata.UpdatedAt = time.Now()
 
 	return nil
 }
 
+// ListTags 列出所有标签及使用数量，按数量降序、标签名升序排列
+func (s *MetadataService) ListTags(ctx context.Context) []TagCount {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	tags := make([]TagCount, 0, len(s.tagIndex))
+	for tag, fileIDs := range s.tagIndex {
+		tags = append(tags, TagCount{Tag: tag, Count: len(fileIDs)})
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	return tags
+}
+
 // ValidateMetadata 验证元数据
 func (s *MetadataService) ValidateMetadata(metadata *FileMetadata) error {
 	if metadata.FileID == "" {
@@ -337,6 +673,14 @@ func (s *MetadataService) ValidateMetadata(metadata *FileMetadata) error {
 		return fmt.Errorf("创建者不能为空")
 	}
 
+	if _, err := NormalizeVisibility(metadata.Visibility); err != nil {
+		return err
+	}
+
+	if _, err := NormalizeStatus(metadata.Status); err != nil {
+		return err
+	}
+
 	if len(metadata.Description) > 1000 {
 		return fmt.Errorf("描述长度不能超过1000个字符")
 	}
@@ -379,37 +723,58 @@ func (s *MetadataService) matchesSearchCriteria(metadata *FileMetadata, req *Sea
 	return true
 }
 
-// sortMetadata 排序元数据
-func (s *MetadataService) sortMetadata(items []*FileMetadata, sortBy, order string) {
-	if sortBy == "" {
-		sortBy = "created_at"
-	}
-	if order == "" {
-		order = "desc"
-	}
-
-	sort.Slice(items, func(i, j int) bool {
-		var less bool
-		switch sortBy {
-		case "title":
-			less = items[i].Title < items[j].Title
-		case "duration":
-			less = items[i].Duration < items[j].Duration
-		case "file_size":
-			less = items[i].FileSize < items[j].FileSize
-		case "created_at":
-			less = items[i].CreatedAt.Before(items[j].CreatedAt)
-		case "updated_at":
-			less = items[i].UpdatedAt.Before(items[j].UpdatedAt)
-		default:
-			less = items[i].CreatedAt.Before(items[j].CreatedAt)
-		}
+// matchesListFilter 检查元数据是否符合列表过滤条件
+func (s *MetadataService) matchesListFilter(metadata *FileMetadata, req *ListMetadataRequest) bool {
+	if req.FileIDs != nil && !slices.Contains(req.FileIDs, metadata.FileID) {
+		return false
+	}
+
+	if req.ListedFor != nil && !metadata.ListedFor(*req.ListedFor) {
+		return false
+	}
 
-		if order == "desc" {
-			return !less
+	if len(req.ContentTypes) > 0 && !slices.ContainsFunc(req.ContentTypes, func(contentType string) bool {
+		return strings.EqualFold(contentType, metadata.ContentType)
+	}) {
+		return false
+	}
+
+	if req.MinDuration > 0 && metadata.Duration < req.MinDuration {
+		return false
+	}
+	if req.MaxDuration > 0 && metadata.Duration > req.MaxDuration {
+		return false
+	}
+
+	if metadata.Width < req.MinWidth || metadata.Height < req.MinHeight {
+		return false
+	}
+
+	if req.CreatedBy != "" && metadata.CreatedBy != req.CreatedBy {
+		return false
+	}
+	if req.Status != "" && metadata.Status != req.Status {
+		return false
+	}
+	// 媒体类型支持之前保存的记录没有媒体类型，按视频过滤
+	if req.MediaType != "" {
+		if mediaType, _ := NormalizeMediaType(metadata.MediaType); mediaType != req.MediaType {
+			return false
 		}
-		return less
-	})
+	}
+	for _, tag := range req.Tags {
+		if _, tagged := s.tagIndex[NormalizeTag(tag)][metadata.FileID]; !tagged {
+			return false
+		}
+	}
+	if !req.CreatedAfter.IsZero() && metadata.CreatedAt.Before(req.CreatedAfter) {
+		return false
+	}
+	if !req.CreatedBefore.IsZero() && !metadata.CreatedAt.Before(req.CreatedBefore) {
+		return false
+	}
+
+	return true
 }
 
 // copyMetadata 复制元数据以避免并发修改
@@ -423,15 +788,27 @@ func (s *MetadataService) copyMetadata(original *FileMetadata) *FileMetadata {
 			copySlice[i] = tag
 		}
 	}
+	copy.Chapters = slices.Clone(original.Chapters)
+	copy.Versions = slices.Clone(original.Versions)
+	if original.ClipSource != nil {
+		source := *original.ClipSource
+		copy.ClipSource = &source
+	}
 	return &copy
 }
 
-// deduplicateTags 去重标签
+// NormalizeTag 规范化标签：去除首尾空白、合并连续空白并转为小写
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
+
+// deduplicateTags 规范化并去重标签
 func (s *MetadataService) deduplicateTags(tags []string) []string {
 	seen := make(map[string]bool)
 	var result []string
 
 	for _, tag := range tags {
+		tag = NormalizeTag(tag)
 		if tag != "" && !seen[tag] {
 			seen[tag] = true
 			result = append(result, tag)
@@ -439,4 +816,27 @@ func (s *MetadataService) deduplicateTags(tags []string) []string {
 	}
 
 	return result
-}
\ No newline at end of file
+}
+
+// indexTags 将文件加入标签索引，调用方需持有写锁
+func (s *MetadataService) indexTags(fileID string, tags []string) {
+	for _, tag := range tags {
+		fileIDs, exists := s.tagIndex[tag]
+		if !exists {
+			fileIDs = make(map[string]struct{})
+			s.tagIndex[tag] = fileIDs
+		}
+		fileIDs[fileID] = struct{}{}
+	}
+}
+
+// unindexTags 从标签索引中移除文件，标签不再被使用时删除该标签，调用方需持有写锁
+func (s *MetadataService) unindexTags(fileID string, tags []string) {
+	for _, tag := range tags {
+		fileIDs := s.tagIndex[tag]
+		delete(fileIDs, fileID)
+		if len(fileIDs) == 0 {
+			delete(s.tagIndex, tag)
+		}
+	}
+}
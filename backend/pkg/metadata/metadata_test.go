@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -25,7 +26,8 @@ func TestMetadataService_SaveMetadata(t *testing.T) {
 		Description: "这是一个测试视频文件",
 		Tags:        []string{"测试", "视频", "demo"},
 		Duration:    300, // 5分钟
-		Resolution:  "1920x1080",
+		Width:       1920,
+		Height:      1080,
 		Bitrate:     2500,
 		CreatedBy:   "test-user",
 	}
@@ -76,6 +78,13 @@ func TestMetadataService_UpdateMetadata(t *testing.T) {
 		Title:       stringPtr("更新后的标题"),
 		Description: stringPtr("更新后的描述"),
 		Tags:        &[]string{"更新", "标签", "新增"},
+		Width:       intPtr(1080),
+		Height:      intPtr(1920),
+		Rotation:    intPtr(90),
+		VideoCodec:  stringPtr("H.265"),
+		AudioCodec:  stringPtr("AAC"),
+		FrameRate:   float64Ptr(29.97),
+		FileSize:    int64Ptr(2048),
 	}
 
 	err = metadataService.UpdateMetadata(ctx, updateRequest)
@@ -89,9 +98,63 @@ func TestMetadataService_UpdateMetadata(t *testing.T) {
 	assert.Equal(t, "更新后的标题", updatedMetadata.Title, "标题应该已更新")
 	assert.Equal(t, "更新后的描述", updatedMetadata.Description, "描述应该已更新")
 	assert.ElementsMatch(t, []string{"更新", "标签", "新增"}, updatedMetadata.Tags, "标签应该已更新")
+	assert.Equal(t, 1080, updatedMetadata.Width)
+	assert.Equal(t, 1920, updatedMetadata.Height)
+	assert.Equal(t, 90, updatedMetadata.Rotation, "旋转角度应该已更新")
+	assert.Equal(t, "H.265", updatedMetadata.VideoCodec, "视频编码应该已更新")
+	assert.Equal(t, "AAC", updatedMetadata.AudioCodec)
+	assert.Equal(t, 29.97, updatedMetadata.FrameRate)
+	assert.Equal(t, int64(2048), updatedMetadata.FileSize)
+	usage, err := metadataService.GetStorageUsage(ctx, "test-user")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2048), usage, "修正文件大小后存储用量应该同步更新")
 	assert.True(t, updatedMetadata.UpdatedAt.After(updatedMetadata.CreatedAt), "更新时间应该晚于创建时间")
 }
 
+// TestMetadataService_UpdateMetadataConflict 测试更新元数据的乐观并发控制
+func TestMetadataService_UpdateMetadataConflict(t *testing.T) {
+	metadataService := NewMetadataService()
+	ctx := context.Background()
+
+	err := metadataService.SaveMetadata(ctx, &FileMetadata{
+		FileID:     "test-file-conflict",
+		BucketName: "test-bucket",
+		ObjectName: "videos/2025/08/conflict-test.mp4",
+		FileName:   "conflict-test.mp4",
+		Title:      "原始标题",
+		CreatedBy:  "test-user",
+	})
+	require.NoError(t, err)
+
+	original, err := metadataService.GetMetadata(ctx, "test-file-conflict")
+	require.NoError(t, err)
+	readAt := original.UpdatedAt
+
+	// 使用读取时的更新时间进行更新应该成功
+	err = metadataService.UpdateMetadata(ctx, &UpdateMetadataRequest{
+		FileID:            "test-file-conflict",
+		Title:             stringPtr("第一次更新"),
+		ExpectedUpdatedAt: &readAt,
+	})
+	require.NoError(t, err, "版本一致时更新应该成功")
+
+	updated, err := metadataService.GetMetadata(ctx, "test-file-conflict")
+	require.NoError(t, err)
+	assert.Greater(t, updated.UpdatedAt.UnixMilli(), readAt.UnixMilli(), "更新时间应该按毫秒递增")
+
+	// 使用过期的更新时间应该冲突
+	err = metadataService.UpdateMetadata(ctx, &UpdateMetadataRequest{
+		FileID:            "test-file-conflict",
+		Title:             stringPtr("第二次更新"),
+		ExpectedUpdatedAt: &readAt,
+	})
+	assert.ErrorIs(t, err, ErrUpdateConflict, "版本过期时应该返回冲突错误")
+
+	current, err := metadataService.GetMetadata(ctx, "test-file-conflict")
+	require.NoError(t, err)
+	assert.Equal(t, "第一次更新", current.Title, "冲突时不应该修改元数据")
+}
+
 // TestMetadataService_DeleteMetadata 测试删除文件元数据
 func TestMetadataService_DeleteMetadata(t *testing.T) {
 	metadataService := NewMetadataService()
@@ -133,25 +196,25 @@ func TestMetadataService_SearchMetadata(t *testing.T) {
 	// 准备测试数据
 	testMetadata := []*FileMetadata{
 		{
-			FileID:     "search-001",
-			Title:      "Python教程视频",
-			Tags:       []string{"Python", "编程", "教程"},
-			Duration:   1800,
-			CreatedBy:  "teacher1",
+			FileID:    "search-001",
+			Title:     "Python教程视频",
+			Tags:      []string{"Python", "编程", "教程"},
+			Duration:  1800,
+			CreatedBy: "teacher1",
 		},
 		{
-			FileID:     "search-002",
-			Title:      "Go语言入门",
-			Tags:       []string{"Go", "编程", "入门"},
-			Duration:   2400,
-			CreatedBy:  "teacher2",
+			FileID:    "search-002",
+			Title:     "Go语言入门",
+			Tags:      []string{"Go", "编程", "入门"},
+			Duration:  2400,
+			CreatedBy: "teacher2",
 		},
 		{
-			FileID:     "search-003",
-			Title:      "JavaScript高级特性",
-			Tags:       []string{"JavaScript", "编程", "高级"},
-			Duration:   3600,
-			CreatedBy:  "teacher1",
+			FileID:    "search-003",
+			Title:     "JavaScript高级特性",
+			Tags:      []string{"JavaScript", "编程", "高级"},
+			Duration:  3600,
+			CreatedBy: "teacher1",
 		},
 	}
 
@@ -237,6 +300,58 @@ func TestMetadataService_ListMetadata(t *testing.T) {
 	assert.Len(t, results.Items, 5, "第二页应该返回5个结果")
 }
 
+// TestMetadataService_ListMetadataFilter 测试列表过滤条件
+func TestMetadataService_ListMetadataFilter(t *testing.T) {
+	metadataService := NewMetadataService()
+	ctx := context.Background()
+	base := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	files := []*FileMetadata{
+		{FileID: "short-sd", ContentType: "video/mp4", Duration: 30, Width: 640, Height: 360, CreatedBy: "alice", CreatedAt: base},
+		{FileID: "long-hd", ContentType: "video/mp4", Duration: 600, Width: 1280, Height: 720, CreatedBy: "alice", CreatedAt: base.Add(24 * time.Hour)},
+		{FileID: "webm-fhd", ContentType: "video/webm", Duration: 120, Width: 1920, Height: 1080, CreatedBy: "bob", CreatedAt: base.Add(48 * time.Hour)},
+		{FileID: "unknown", ContentType: "video/x-flv", Duration: 0, CreatedBy: "bob", CreatedAt: base.Add(72 * time.Hour)},
+	}
+	for _, file := range files {
+		file.Title = file.FileID
+		require.NoError(t, metadataService.SaveMetadata(ctx, file))
+	}
+
+	tests := []struct {
+		name     string
+		req      *ListMetadataRequest
+		expected []string
+	}{
+		{"不过滤", &ListMetadataRequest{}, []string{"short-sd", "long-hd", "webm-fhd", "unknown"}},
+		{"内容类型", &ListMetadataRequest{ContentTypes: []string{"VIDEO/MP4", "video/webm"}}, []string{"short-sd", "long-hd", "webm-fhd"}},
+		{"时长范围", &ListMetadataRequest{MinDuration: 60, MaxDuration: 300}, []string{"webm-fhd"}},
+		{"最长时长", &ListMetadataRequest{MaxDuration: 60}, []string{"short-sd", "unknown"}},
+		{"最低分辨率", &ListMetadataRequest{MinHeight: 720}, []string{"long-hd", "webm-fhd"}},
+		{"最小宽度", &ListMetadataRequest{MinWidth: 1920}, []string{"webm-fhd"}},
+		{"上传者", &ListMetadataRequest{CreatedBy: "bob"}, []string{"webm-fhd", "unknown"}},
+		{"上传时间范围", &ListMetadataRequest{CreatedAfter: base.Add(24 * time.Hour), CreatedBefore: base.Add(72 * time.Hour)}, []string{"long-hd", "webm-fhd"}},
+		{"组合条件", &ListMetadataRequest{ContentTypes: []string{"video/mp4"}, CreatedBy: "alice", MinHeight: 480}, []string{"long-hd"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.req.Limit = 10
+			tc.req.SortBy = "created_at"
+			tc.req.Order = "asc"
+
+			results, err := metadataService.ListMetadata(ctx, tc.req)
+			require.NoError(t, err)
+			assert.Equal(t, len(tc.expected), results.Total, "总数应该只统计符合条件的视频")
+
+			ids := make([]string, 0, len(results.Items))
+			for _, item := range results.Items {
+				ids = append(ids, item.FileID)
+			}
+			assert.Equal(t, tc.expected, ids)
+		})
+	}
+}
+
 // TestMetadataService_GetMetadataByObjectName 测试根据对象名获取元数据
 func TestMetadataService_GetMetadataByObjectName(t *testing.T) {
 	metadataService := NewMetadataService()
@@ -261,6 +376,136 @@ func TestMetadataService_GetMetadataByObjectName(t *testing.T) {
 	assert.Equal(t, metadata.Title, foundMetadata.Title, "标题应该匹配")
 }
 
+// TestMetadataService_GetMetadataByChecksum 测试根据校验和查找重复视频
+func TestMetadataService_GetMetadataByChecksum(t *testing.T) {
+	metadataService := NewMetadataService()
+	ctx := context.Background()
+	now := time.Now()
+
+	files := []*FileMetadata{
+		{FileID: "alias", ObjectName: "videos/a.mp4", Title: "别名", Checksum: "abc", CreatedAt: now, CreatedBy: "alice"},
+		{FileID: "original", ObjectName: "videos/a.mp4", Title: "原始视频", Checksum: "abc", CreatedAt: now.Add(-time.Hour), CreatedBy: "alice"},
+		{FileID: "other", ObjectName: "videos/b.mp4", Title: "其他视频", Checksum: "def", CreatedAt: now, CreatedBy: "alice"},
+		{FileID: "legacy", ObjectName: "videos/c.mp4", Title: "没有校验和的视频", CreatedAt: now, CreatedBy: "alice"},
+	}
+	for _, file := range files {
+		require.NoError(t, metadataService.SaveMetadata(ctx, file))
+	}
+
+	found, err := metadataService.GetMetadataByChecksum(ctx, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, "original", found.FileID, "应该返回最早保存的视频")
+
+	_, err = metadataService.GetMetadataByChecksum(ctx, "missing")
+	assert.Error(t, err)
+
+	_, err = metadataService.GetMetadataByChecksum(ctx, "")
+	assert.Error(t, err, "空校验和不应该匹配没有校验和的视频")
+
+	assert.Equal(t, 2, metadataService.CountObjectReferences(ctx, "", "videos/a.mp4"), "去重后多个视频共享同一对象")
+	assert.Equal(t, 1, metadataService.CountObjectReferences(ctx, "", "videos/b.mp4"))
+	assert.Equal(t, 0, metadataService.CountObjectReferences(ctx, "", "videos/none.mp4"))
+}
+
+// TestMetadataService_SetObjectArchived 测试按存储对象设置归档状态
+func TestMetadataService_SetObjectArchived(t *testing.T) {
+	metadataService := NewMetadataService()
+	ctx := context.Background()
+
+	files := []*FileMetadata{
+		{FileID: "archive-1", ObjectName: "videos/a.mp4", Title: "A", CreatedBy: "alice"},
+		{FileID: "archive-2", ObjectName: "videos/a.mp4", Title: "A副本", CreatedBy: "bob"},
+		{FileID: "archive-3", ObjectName: "videos/b.mp4", Title: "B", CreatedBy: "alice"},
+	}
+	for _, file := range files {
+		require.NoError(t, metadataService.SaveMetadata(ctx, file))
+	}
+	assert.Len(t, metadataService.ListObjectReferences(ctx, "", "videos/a.mp4"), 2)
+	assert.Empty(t, metadataService.ListObjectReferences(ctx, "", "videos/none.mp4"))
+	before, err := metadataService.GetMetadata(ctx, "archive-2")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, metadataService.SetObjectArchived(ctx, "", "videos/a.mp4", true), "共享同一对象的视频应该一起归档")
+
+	for _, fileID := range []string{"archive-1", "archive-2"} {
+		metadata, err := metadataService.GetMetadata(ctx, fileID)
+		require.NoError(t, err)
+		assert.True(t, metadata.Archived)
+		assert.False(t, metadata.ArchivedAt.IsZero())
+	}
+	after, err := metadataService.GetMetadata(ctx, "archive-2")
+	require.NoError(t, err)
+	assert.Equal(t, before.UpdatedAt, after.UpdatedAt, "归档不应该更新修改时间")
+	other, err := metadataService.GetMetadata(ctx, "archive-3")
+	require.NoError(t, err)
+	assert.False(t, other.Archived)
+
+	assert.Equal(t, 2, metadataService.SetObjectArchived(ctx, "", "videos/a.mp4", false))
+	restored, err := metadataService.GetMetadata(ctx, "archive-1")
+	require.NoError(t, err)
+	assert.False(t, restored.Archived)
+	assert.True(t, restored.ArchivedAt.IsZero(), "恢复后应该清除归档时间")
+	assert.False(t, restored.RestoredAt.IsZero(), "恢复后应该记录恢复时间")
+
+	assert.Equal(t, 0, metadataService.SetObjectArchived(ctx, "", "videos/none.mp4", true))
+}
+
+// TestMetadataService_MoveObjectReferences 测试存储迁移时改写存储桶引用
+func TestMetadataService_MoveObjectReferences(t *testing.T) {
+	metadataService := NewMetadataService()
+	ctx := context.Background()
+
+	files := []*FileMetadata{
+		{FileID: "move-1", BucketName: "old", ObjectName: "videos/a.mp4", Title: "A", CreatedBy: "alice"},
+		{FileID: "move-2", BucketName: "old", ObjectName: "videos/a.mp4", Title: "A副本", CreatedBy: "bob"},
+		{FileID: "move-3", BucketName: "old", ObjectName: "videos/b.mp4", Title: "B", CreatedBy: "alice"},
+	}
+	for _, file := range files {
+		require.NoError(t, metadataService.SaveMetadata(ctx, file))
+	}
+	before, err := metadataService.GetMetadata(ctx, "move-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, metadataService.MoveObjectReferences(ctx, "old", "videos/a.mp4", "new"), "共享同一对象的视频应该一起改写")
+	assert.Len(t, metadataService.ListObjectReferences(ctx, "new", "videos/a.mp4"), 2)
+	after, err := metadataService.GetMetadata(ctx, "move-1")
+	require.NoError(t, err)
+	assert.Equal(t, before.UpdatedAt, after.UpdatedAt, "迁移不应该更新修改时间")
+
+	other, err := metadataService.GetMetadata(ctx, "move-3")
+	require.NoError(t, err)
+	assert.Equal(t, "old", other.BucketName, "其他对象的引用不应该改变")
+	assert.Equal(t, 0, metadataService.MoveObjectReferences(ctx, "old", "videos/a.mp4", "new"))
+}
+
+// TestMetadataService_GetStorageUsage 测试统计用户存储用量
+func TestMetadataService_GetStorageUsage(t *testing.T) {
+	metadataService := NewMetadataService()
+	ctx := context.Background()
+
+	files := []*FileMetadata{
+		{FileID: "usage-1", ObjectName: "videos/a.mp4", Title: "A", FileSize: 100, CreatedBy: "alice"},
+		{FileID: "usage-2", ObjectName: "videos/b.mp4", Title: "B", FileSize: 250, CreatedBy: "alice"},
+		{FileID: "usage-3", ObjectName: "videos/c.mp4", Title: "C", FileSize: 400, CreatedBy: "bob"},
+	}
+	for _, file := range files {
+		require.NoError(t, metadataService.SaveMetadata(ctx, file))
+	}
+
+	usage, err := metadataService.GetStorageUsage(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, int64(350), usage, "应该只统计该用户的文件")
+
+	require.NoError(t, metadataService.DeleteMetadata(ctx, "usage-2"))
+	usage, err = metadataService.GetStorageUsage(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), usage, "删除文件后用量应该减少")
+
+	usage, err = metadataService.GetStorageUsage(ctx, "nobody")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), usage)
+}
+
 // TestMetadataService_AddTags 测试添加标签
 func TestMetadataService_AddTags(t *testing.T) {
 	metadataService := NewMetadataService()
@@ -313,6 +558,59 @@ func TestMetadataService_RemoveTags(t *testing.T) {
 	assert.ElementsMatch(t, expectedTags, updatedMetadata.Tags, "应该只剩下未移除的标签")
 }
 
+// TestMetadataService_TagIndex 测试标签规范化、标签统计和按标签过滤
+func TestMetadataService_TagIndex(t *testing.T) {
+	metadataService := NewMetadataService()
+	ctx := context.Background()
+
+	for _, item := range []*FileMetadata{
+		{FileID: "tag-001", Title: "视频1", Tags: []string{" Go ", "go", "Web  开发"}, CreatedBy: "test-user"},
+		{FileID: "tag-002", Title: "视频2", Tags: []string{"GO"}, CreatedBy: "test-user"},
+		{FileID: "tag-003", Title: "视频3", Tags: []string{"web 开发", "Rust"}, CreatedBy: "test-user"},
+	} {
+		require.NoError(t, metadataService.SaveMetadata(ctx, item))
+	}
+
+	saved, err := metadataService.GetMetadata(ctx, "tag-001")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go", "web 开发"}, saved.Tags, "标签应该规范化并去重")
+
+	assert.Equal(t, []TagCount{
+		{Tag: "go", Count: 2},
+		{Tag: "web 开发", Count: 2},
+		{Tag: "rust", Count: 1},
+	}, metadataService.ListTags(ctx))
+
+	resp, err := metadataService.ListMetadata(ctx, &ListMetadataRequest{Limit: 10, Tags: []string{"Go", "WEB 开发"}})
+	require.NoError(t, err)
+	require.Equal(t, 1, resp.Total, "应该只返回包含全部标签的文件")
+	assert.Equal(t, "tag-001", resp.Items[0].FileID)
+
+	resp, err = metadataService.ListMetadata(ctx, &ListMetadataRequest{Limit: 10, FileIDs: []string{"tag-002", "tag-003"}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, resp.Total, "应该只返回指定ID的文件")
+
+	resp, err = metadataService.ListMetadata(ctx, &ListMetadataRequest{Limit: 10, FileIDs: []string{}})
+	require.NoError(t, err)
+	assert.Equal(t, 0, resp.Total, "空的ID范围不应该匹配任何文件")
+
+	// 修改和删除后索引应该同步更新
+	require.NoError(t, metadataService.RemoveTags(ctx, "tag-003", []string{"RUST"}))
+	require.NoError(t, metadataService.UpdateMetadata(ctx, &UpdateMetadataRequest{FileID: "tag-002", Tags: &[]string{"新标签"}}))
+	require.NoError(t, metadataService.DeleteMetadata(ctx, "tag-001"))
+
+	assert.Equal(t, []TagCount{
+		{Tag: "web 开发", Count: 1},
+		{Tag: "新标签", Count: 1},
+	}, metadataService.ListTags(ctx), "未被使用的标签应该从统计中移除")
+
+	// 覆盖保存时应该移除旧标签的索引
+	require.NoError(t, metadataService.SaveMetadata(ctx, &FileMetadata{FileID: "tag-002", Title: "视频2", CreatedBy: "test-user"}))
+	resp, err = metadataService.ListMetadata(ctx, &ListMetadataRequest{Limit: 10, Tags: []string{"新标签"}})
+	require.NoError(t, err)
+	assert.Equal(t, 0, resp.Total)
+}
+
 // TestMetadataService_ValidateMetadata 测试元数据验证
 func TestMetadataService_ValidateMetadata(t *testing.T) {
 	metadataService := NewMetadataService()
@@ -386,4 +684,17 @@ func TestMetadataService_ValidateMetadata(t *testing.T) {
 // stringPtr 辅助函数，返回字符串指针
 func stringPtr(s string) *string {
 	return &s
-}
\ No newline at end of file
+}
+
+// intPtr 辅助函数，返回整数指针
+func intPtr(i int) *int {
+	return &i
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
@@ -0,0 +1,60 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Snapshot 获取全部元数据的副本，按文件ID排序，用于备份
+func (s *MetadataService) Snapshot(ctx context.Context) []*FileMetadata {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	items := make([]*FileMetadata, 0, len(s.storage))
+	for _, metadata := range s.storage {
+		items = append(items, s.copyMetadata(metadata))
+	}
+	slices.SortFunc(items, func(a, b *FileMetadata) int {
+		return strings.Compare(a.FileID, b.FileID)
+	})
+	return items
+}
+
+// Restore 用备份的元数据替换全部元数据，返回恢复的记录数量
+// 先验证全部记录，任一记录无效或文件ID重复时不做任何修改；记录中的创建和更新时间保持不变
+func (s *MetadataService) Restore(ctx context.Context, items []*FileMetadata) (int, error) {
+	storage := make(map[string]*FileMetadata, len(items))
+	for _, item := range items {
+		if item == nil {
+			return 0, fmt.Errorf("元数据不能为空")
+		}
+		if err := s.ValidateMetadata(item); err != nil {
+			return 0, fmt.Errorf("元数据无效(%s): %w", item.FileID, err)
+		}
+		if _, exists := storage[item.FileID]; exists {
+			return 0, fmt.Errorf("文件ID重复: %s", item.FileID)
+		}
+
+		metadata := s.copyMetadata(item)
+		metadata.Tags = s.deduplicateTags(metadata.Tags)
+		metadata.Visibility, _ = NormalizeVisibility(metadata.Visibility)
+		metadata.Status, _ = NormalizeStatus(metadata.Status)
+		metadata.MediaType, _ = NormalizeMediaType(metadata.MediaType)
+		metadata.Version = max(metadata.Version, 1)
+		storage[metadata.FileID] = metadata
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.storage = storage
+	s.tagIndex = make(map[string]map[string]struct{})
+	s.stats = newStats()
+	for _, metadata := range storage {
+		s.indexTags(metadata.FileID, metadata.Tags)
+		s.addStats(metadata, 1)
+	}
+	return len(storage), nil
+}
@@ -0,0 +1,72 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMetadataService_SnapshotRestore 测试元数据快照和恢复
+func TestMetadataService_SnapshotRestore(t *testing.T) {
+	ctx := context.Background()
+	source := NewMetadataService()
+	createdAt := time.Date(2025, 8, 10, 12, 0, 0, 0, time.UTC)
+	for _, fileID := range []string{"b", "a"} {
+		require.NoError(t, source.SaveMetadata(ctx, &FileMetadata{
+			FileID:    fileID,
+			FileSize:  100,
+			FileName:  fileID + ".mp4",
+			Title:     fileID,
+			Tags:      []string{"Demo"},
+			Chapters:  []Chapter{{Title: "开头", StartTime: 0, EndTime: 1000}},
+			CreatedBy: "alice",
+			CreatedAt: createdAt,
+		}))
+	}
+
+	snapshot := source.Snapshot(ctx)
+	require.Len(t, snapshot, 2)
+	assert.Equal(t, "a", snapshot[0].FileID, "快照按文件ID排序")
+	snapshot[0].Chapters[0].Title = "修改"
+	meta, err := source.GetMetadata(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "开头", meta.Chapters[0].Title, "快照是副本")
+	snapshot[0].Chapters[0].Title = "开头"
+
+	target := NewMetadataService()
+	require.NoError(t, target.SaveMetadata(ctx, &FileMetadata{FileID: "old", Title: "old", Tags: []string{"old"}, CreatedBy: "bob"}))
+
+	count, err := target.Restore(ctx, snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	_, err = target.GetMetadata(ctx, "old")
+	assert.Error(t, err, "恢复时替换全部元数据")
+
+	restored, err := target.GetMetadata(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, snapshot[0], restored)
+	assert.True(t, restored.CreatedAt.Equal(createdAt), "创建时间保持不变")
+	assert.Equal(t, []TagCount{{Tag: "demo", Count: 2}}, target.ListTags(ctx))
+	assert.Equal(t, 2, target.GetStats(ctx).Total.Count)
+	assert.Equal(t, int64(200), target.GetStats(ctx).Total.Bytes)
+}
+
+// TestMetadataService_RestoreInvalid 测试无效备份不修改已有元数据
+func TestMetadataService_RestoreInvalid(t *testing.T) {
+	ctx := context.Background()
+	service := NewMetadataService()
+	require.NoError(t, service.SaveMetadata(ctx, &FileMetadata{FileID: "old", Title: "old", CreatedBy: "bob"}))
+
+	for name, items := range map[string][]*FileMetadata{
+		"缺少创建者":  {{FileID: "a", Title: "a"}},
+		"文件ID重复": {{FileID: "a", Title: "a", CreatedBy: "alice"}, {FileID: "a", Title: "b", CreatedBy: "alice"}},
+		"空记录":    {nil},
+	} {
+		_, err := service.Restore(ctx, items)
+		assert.Error(t, err, name)
+	}
+	assert.Equal(t, 1, service.Count(ctx))
+}
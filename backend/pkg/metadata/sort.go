@@ -0,0 +1,119 @@
+package metadata
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidSort 排序条件无效
+var ErrInvalidSort = errors.New("排序条件无效")
+
+// 排序方向
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// SortField 排序条件中的一个字段
+type SortField struct {
+	Field string // 排序字段，必须在sortableFields中
+	Desc  bool   // 是否降序
+}
+
+// sortableFields 允许排序的字段及其升序比较函数，排序字段只能从这里选择
+var sortableFields = map[string]func(a, b *FileMetadata) int{
+	"title":      func(a, b *FileMetadata) int { return strings.Compare(a.Title, b.Title) },
+	"duration":   func(a, b *FileMetadata) int { return cmp.Compare(a.Duration, b.Duration) },
+	"file_size":  func(a, b *FileMetadata) int { return cmp.Compare(a.FileSize, b.FileSize) },
+	"resolution": compareResolution,
+	"created_at": func(a, b *FileMetadata) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updated_at": func(a, b *FileMetadata) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+}
+
+// compareResolution 按像素数比较分辨率，像素数相同时按高度比较，未知分辨率排在最小
+func compareResolution(a, b *FileMetadata) int {
+	return cmp.Or(
+		cmp.Compare(int64(a.Width)*int64(a.Height), int64(b.Width)*int64(b.Height)),
+		cmp.Compare(a.Height, b.Height),
+	)
+}
+
+// sortFieldAliases 排序字段别名，接口中的上传时间对应元数据的创建时间
+var sortFieldAliases = map[string]string{
+	"uploaded_at": "created_at",
+	"size":        "file_size",
+}
+
+// defaultSortField 未指定排序字段时按创建时间排序
+const defaultSortField = "created_at"
+
+// ParseSort 解析排序条件，sortBy为逗号分隔的"字段 [asc|desc]"，如"duration desc, created_at desc"
+// 未指定方向的字段使用defaultOrder，defaultOrder为空时降序；sortBy为空时按创建时间排序
+// 字段不在白名单中、重复或方向无效时返回ErrInvalidSort
+func ParseSort(sortBy, defaultOrder string) ([]SortField, error) {
+	defaultDesc, err := parseSortOrder(defaultOrder)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(sortBy) == "" {
+		return []SortField{{Field: defaultSortField, Desc: defaultDesc}}, nil
+	}
+
+	var fields []SortField
+	for _, item := range strings.Split(sortBy, ",") {
+		parts := strings.Fields(strings.ToLower(item))
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSort, strings.TrimSpace(item))
+		}
+
+		field := parts[0]
+		if alias, ok := sortFieldAliases[field]; ok {
+			field = alias
+		}
+		if _, ok := sortableFields[field]; !ok {
+			return nil, fmt.Errorf("%w: 不支持按%s排序", ErrInvalidSort, parts[0])
+		}
+		if slices.ContainsFunc(fields, func(f SortField) bool { return f.Field == field }) {
+			return nil, fmt.Errorf("%w: 排序字段%s重复", ErrInvalidSort, parts[0])
+		}
+
+		desc := defaultDesc
+		if len(parts) == 2 {
+			if desc, err = parseSortOrder(parts[1]); err != nil {
+				return nil, err
+			}
+		}
+		fields = append(fields, SortField{Field: field, Desc: desc})
+	}
+	return fields, nil
+}
+
+// parseSortOrder 解析排序方向，空值为降序
+func parseSortOrder(order string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(order)) {
+	case "", SortDesc:
+		return true, nil
+	case SortAsc:
+		return false, nil
+	}
+	return false, fmt.Errorf("%w: 排序方向必须为asc或desc", ErrInvalidSort)
+}
+
+// sortItems 按排序条件排序，所有字段都相同时按文件ID排序，保证分页结果稳定
+func sortItems(items []*FileMetadata, fields []SortField) {
+	slices.SortFunc(items, func(a, b *FileMetadata) int {
+		for _, field := range fields {
+			result := sortableFields[field.Field](a, b)
+			if field.Desc {
+				result = -result
+			}
+			if result != 0 {
+				return result
+			}
+		}
+		return strings.Compare(a.FileID, b.FileID)
+	})
+}
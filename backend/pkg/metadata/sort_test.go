@@ -0,0 +1,94 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseSort 测试解析排序条件
+func TestParseSort(t *testing.T) {
+	fields, err := ParseSort("", "")
+	require.NoError(t, err)
+	assert.Equal(t, []SortField{{Field: "created_at", Desc: true}}, fields, "默认按创建时间降序")
+
+	fields, err = ParseSort("Duration DESC, uploaded_at, title asc", "asc")
+	require.NoError(t, err)
+	assert.Equal(t, []SortField{
+		{Field: "duration", Desc: true},
+		{Field: "created_at", Desc: false},
+		{Field: "title", Desc: false},
+	}, fields, "未指定方向的字段使用默认方向")
+
+	for _, sortBy := range []string{
+		"title; drop table videos",
+		"title desc nulls",
+		"views",
+		"title,",
+		"title sideways",
+		"created_at, uploaded_at",
+	} {
+		_, err := ParseSort(sortBy, "")
+		assert.ErrorIs(t, err, ErrInvalidSort, sortBy)
+	}
+	_, err = ParseSort("title", "up")
+	assert.ErrorIs(t, err, ErrInvalidSort)
+}
+
+// TestMetadataService_ListMetadataSort 测试多字段排序
+func TestMetadataService_ListMetadataSort(t *testing.T) {
+	metadataService := NewMetadataService()
+	ctx := context.Background()
+	for _, file := range []*FileMetadata{
+		{FileID: "a", Title: "短片", Duration: 30},
+		{FileID: "b", Title: "长片", Duration: 600},
+		{FileID: "c", Title: "另一个短片", Duration: 30},
+		{FileID: "d", Title: "短片", Duration: 30},
+	} {
+		file.CreatedBy = "test-user"
+		require.NoError(t, metadataService.SaveMetadata(ctx, file))
+	}
+
+	list := func(sortBy, order string) []string {
+		resp, err := metadataService.ListMetadata(ctx, &ListMetadataRequest{Limit: 10, SortBy: sortBy, Order: order})
+		require.NoError(t, err)
+		var ids []string
+		for _, item := range resp.Items {
+			ids = append(ids, item.FileID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"b", "c", "a", "d"}, list("duration desc, title asc", ""), "时长相同时按标题排序，全部相同时按文件ID排序")
+	assert.Equal(t, []string{"c", "a", "d", "b"}, list("duration, title", "asc"))
+
+	_, err := metadataService.ListMetadata(ctx, &ListMetadataRequest{Limit: 10, SortBy: "checksum"})
+	assert.ErrorIs(t, err, ErrInvalidSort)
+}
+
+// TestMetadataService_ListMetadataSortByResolution 测试按分辨率排序
+func TestMetadataService_ListMetadataSortByResolution(t *testing.T) {
+	metadataService := NewMetadataService()
+	ctx := context.Background()
+	for _, file := range []*FileMetadata{
+		{FileID: "hd", Width: 1280, Height: 720},
+		{FileID: "unknown"},
+		{FileID: "portrait", Width: 1080, Height: 1920},
+		{FileID: "fhd", Width: 1920, Height: 1080},
+		{FileID: "uhd", Width: 3840, Height: 2160},
+	} {
+		file.Title = file.FileID
+		file.CreatedBy = "test-user"
+		require.NoError(t, metadataService.SaveMetadata(ctx, file))
+	}
+
+	resp, err := metadataService.ListMetadata(ctx, &ListMetadataRequest{Limit: 10, SortBy: "resolution desc"})
+	require.NoError(t, err)
+	var ids []string
+	for _, item := range resp.Items {
+		ids = append(ids, item.FileID)
+	}
+	assert.Equal(t, []string{"uhd", "portrait", "fhd", "hd", "unknown"}, ids, "像素数相同时按高度排序，未知分辨率排在最后")
+}
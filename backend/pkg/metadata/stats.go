@@ -0,0 +1,109 @@
+package metadata
+
+import (
+	"context"
+	"maps"
+	"path"
+	"strings"
+)
+
+// UnknownStatsKey 无法从文件名确定格式时使用的统计键
+const UnknownStatsKey = "unknown"
+
+// Usage 文件数量和总大小
+type Usage struct {
+	Count int   `json:"count"` // 文件数量
+	Bytes int64 `json:"bytes"` // 总大小（字节）
+}
+
+// Stats 元数据聚合统计，随元数据的保存、更新和删除增量维护，获取时无需遍历全部元数据
+// 去重共享同一存储对象的视频分别计入大小
+type Stats struct {
+	Total         Usage            `json:"total"`          // 全部视频
+	Archived      Usage            `json:"archived"`       // 已归档的视频
+	WithThumbnail int              `json:"with_thumbnail"` // 有缩略图的视频数量
+	WithPreview   int              `json:"with_preview"`   // 有动态预览的视频数量
+	ByFormat      map[string]Usage `json:"by_format"`      // 按文件扩展名（小写，不含.）
+	ByUploader    map[string]Usage `json:"by_uploader"`    // 按上传者
+	ByMonth       map[string]Usage `json:"by_month"`       // 按上传月份（YYYY-MM）
+	Tags          int              `json:"tags"`           // 标签数量
+}
+
+// newStats 创建空的聚合统计
+func newStats() *Stats {
+	return &Stats{
+		ByFormat:   make(map[string]Usage),
+		ByUploader: make(map[string]Usage),
+		ByMonth:    make(map[string]Usage),
+	}
+}
+
+// GetStats 获取元数据聚合统计的副本
+func (s *MetadataService) GetStats(ctx context.Context) *Stats {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	stats := *s.stats
+	stats.ByFormat = maps.Clone(s.stats.ByFormat)
+	stats.ByUploader = maps.Clone(s.stats.ByUploader)
+	stats.ByMonth = maps.Clone(s.stats.ByMonth)
+	stats.Tags = len(s.tagIndex)
+	return &stats
+}
+
+// Count 获取元数据数量
+func (s *MetadataService) Count(ctx context.Context) int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.storage)
+}
+
+// addStats 将元数据计入聚合统计，sign为-1时移除，调用方需持有写锁
+func (s *MetadataService) addStats(metadata *FileMetadata, sign int) {
+	stats := s.stats
+	size := int64(sign) * metadata.FileSize
+
+	addUsage(&stats.Total, sign, size)
+	if metadata.Archived {
+		addUsage(&stats.Archived, sign, size)
+	}
+	if metadata.Thumbnail != "" {
+		stats.WithThumbnail += sign
+	}
+	if metadata.Preview != "" {
+		stats.WithPreview += sign
+	}
+
+	addGroupUsage(stats.ByFormat, statsFormat(metadata), sign, size)
+	addGroupUsage(stats.ByUploader, metadata.CreatedBy, sign, size)
+	addGroupUsage(stats.ByMonth, metadata.CreatedAt.Format("2006-01"), sign, size)
+}
+
+// addUsage 累加文件数量和大小
+func addUsage(usage *Usage, sign int, size int64) {
+	usage.Count += sign
+	usage.Bytes += size
+}
+
+// addGroupUsage 累加分组的文件数量和大小，数量归零时删除分组
+func addGroupUsage(groups map[string]Usage, key string, sign int, size int64) {
+	usage := groups[key]
+	addUsage(&usage, sign, size)
+	if usage.Count <= 0 {
+		delete(groups, key)
+		return
+	}
+	groups[key] = usage
+}
+
+// statsFormat 获取元数据的格式统计键：原始文件名的扩展名，缺失时使用对象名的扩展名
+func statsFormat(metadata *FileMetadata) string {
+	ext := path.Ext(metadata.FileName)
+	if ext == "" {
+		ext = path.Ext(metadata.ObjectName)
+	}
+	if ext == "" {
+		return UnknownStatsKey
+	}
+	return strings.ToLower(strings.TrimPrefix(ext, "."))
+}
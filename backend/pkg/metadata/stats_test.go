@@ -0,0 +1,74 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMetadataService_Stats 测试聚合统计随元数据变化增量维护
+func TestMetadataService_Stats(t *testing.T) {
+	service := NewMetadataService()
+	ctx := context.Background()
+	august := time.Date(2025, 8, 10, 12, 0, 0, 0, time.Local)
+	september := time.Date(2025, 9, 1, 12, 0, 0, 0, time.Local)
+
+	save := func(fileID, fileName string, size int64, createdBy string, createdAt time.Time) *FileMetadata {
+		metadata := &FileMetadata{
+			FileID:      fileID,
+			BucketName:  "videos",
+			ObjectName:  "videos/" + fileID,
+			FileName:    fileName,
+			FileSize:    size,
+			ContentType: "video/mp4",
+			Title:       fileID,
+			Tags:        []string{"demo"},
+			CreatedBy:   createdBy,
+			CreatedAt:   createdAt,
+		}
+		require.NoError(t, service.SaveMetadata(ctx, metadata))
+		return metadata
+	}
+
+	save("a", "a.MP4", 100, "alice", august)
+	b := save("b", "b.mkv", 200, "alice", september)
+	save("c", "c.mp4", 300, "carol", september)
+
+	stats := service.GetStats(ctx)
+	assert.Equal(t, Usage{Count: 3, Bytes: 600}, stats.Total)
+	assert.Equal(t, map[string]Usage{"mp4": {Count: 2, Bytes: 400}, "mkv": {Count: 1, Bytes: 200}}, stats.ByFormat, "扩展名应该不区分大小写")
+	assert.Equal(t, map[string]Usage{"alice": {Count: 2, Bytes: 300}, "carol": {Count: 1, Bytes: 300}}, stats.ByUploader)
+	assert.Equal(t, map[string]Usage{"2025-08": {Count: 1, Bytes: 100}, "2025-09": {Count: 2, Bytes: 500}}, stats.ByMonth)
+	assert.Equal(t, 1, stats.Tags)
+	assert.Equal(t, 3, service.Count(ctx))
+
+	// 保存后修改调用方的对象不影响已保存的元数据和统计
+	b.FileSize = 999
+	assert.Equal(t, int64(600), service.GetStats(ctx).Total.Bytes)
+
+	thumbnail := "thumbnails/a.jpg"
+	require.NoError(t, service.UpdateMetadata(ctx, &UpdateMetadataRequest{FileID: "a", Thumbnail: &thumbnail}))
+	assert.Equal(t, 1, service.GetStats(ctx).WithThumbnail)
+
+	assert.Equal(t, 1, service.SetObjectArchived(ctx, "videos", "videos/b", true))
+	assert.Equal(t, Usage{Count: 1, Bytes: 200}, service.GetStats(ctx).Archived)
+
+	// 覆盖保存时替换原有统计
+	save("c", "c.webm", 50, "bob", august)
+	stats = service.GetStats(ctx)
+	assert.Equal(t, Usage{Count: 3, Bytes: 350}, stats.Total)
+	assert.Equal(t, map[string]Usage{"alice": {Count: 2, Bytes: 300}, "bob": {Count: 1, Bytes: 50}}, stats.ByUploader, "数量归零的分组应该被删除")
+
+	require.NoError(t, service.DeleteMetadata(ctx, "b"))
+	stats = service.GetStats(ctx)
+	assert.Equal(t, Usage{Count: 2, Bytes: 150}, stats.Total)
+	assert.Equal(t, Usage{}, stats.Archived)
+	assert.Equal(t, map[string]Usage{"2025-08": {Count: 2, Bytes: 150}}, stats.ByMonth)
+
+	// 返回的统计是副本
+	stats.ByFormat["mp4"] = Usage{}
+	assert.Equal(t, Usage{Count: 1, Bytes: 100}, service.GetStats(ctx).ByFormat["mp4"])
+}
@@ -0,0 +1,52 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// 视频处理状态
+const (
+	StatusUploading  = "uploading"  // 直传或分片上传尚未完成，视频记录还未保存，只出现在视频详情中
+	StatusProcessing = "processing" // 文件已上传，等待HLS打包完成
+	StatusReady      = "ready"      // 处理完成
+	StatusFailed     = "failed"     // HLS打包失败或被取消，原始文件仍可以播放
+)
+
+// NormalizeStatus 规范化视频记录的处理状态，空值按已就绪处理
+// 上传中的视频还没有记录，不是有效的记录状态
+func NormalizeStatus(status string) (string, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	switch status {
+	case "":
+		return StatusReady, nil
+	case StatusProcessing, StatusReady, StatusFailed:
+		return status, nil
+	}
+	return "", fmt.Errorf("处理状态必须为processing、ready或failed: %s", status)
+}
+
+// TransitionStatus 视频的处理状态属于from之一时改为to，返回是否已修改
+// 处理状态由转码流程维护，不属于内容修改，不更新UpdatedAt
+func (s *MetadataService) TransitionStatus(ctx context.Context, fileID string, from []string, to string) (bool, error) {
+	to, err := NormalizeStatus(to)
+	if err != nil {
+		return false, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	metadata, exists := s.storage[fileID]
+	if !exists {
+		return false, fmt.Errorf("元数据不存在: %s", fileID)
+	}
+	if metadata.Status == to || !slices.Contains(from, metadata.Status) {
+		return false, nil
+	}
+
+	metadata.Status = to
+	return true, nil
+}
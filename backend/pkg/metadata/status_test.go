@@ -0,0 +1,87 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNormalizeStatus 测试处理状态规范化
+func TestNormalizeStatus(t *testing.T) {
+	for input, expected := range map[string]string{
+		"":             StatusReady,
+		"ready":        StatusReady,
+		" Processing ": StatusProcessing,
+		"FAILED":       StatusFailed,
+	} {
+		status, err := NormalizeStatus(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, status, input)
+	}
+
+	for _, input := range []string{StatusUploading, "done"} {
+		_, err := NormalizeStatus(input)
+		assert.Error(t, err, input)
+	}
+}
+
+// TestMetadataService_Status 测试保存、过滤和转换处理状态
+func TestMetadataService_Status(t *testing.T) {
+	ctx := context.Background()
+	service := NewMetadataService()
+
+	for _, meta := range []*FileMetadata{
+		{FileID: "ready", Title: "已就绪", CreatedBy: "user-1"},
+		{FileID: "processing", Title: "处理中", CreatedBy: "user-1", Status: StatusProcessing},
+	} {
+		require.NoError(t, service.SaveMetadata(ctx, meta))
+	}
+
+	saved, err := service.GetMetadata(ctx, "ready")
+	require.NoError(t, err)
+	assert.Equal(t, StatusReady, saved.Status, "未设置处理状态时默认为已就绪")
+
+	err = service.SaveMetadata(ctx, &FileMetadata{FileID: "invalid", Title: "无效", CreatedBy: "user-1", Status: StatusUploading})
+	assert.Error(t, err, "上传中不是有效的记录状态")
+
+	resp, err := service.ListMetadata(ctx, &ListMetadataRequest{Limit: 10, Status: StatusProcessing})
+	require.NoError(t, err)
+	require.Len(t, resp.Items, 1)
+	assert.Equal(t, "processing", resp.Items[0].FileID)
+
+	t.Run("按条件转换", func(t *testing.T) {
+		before, err := service.GetMetadata(ctx, "processing")
+		require.NoError(t, err)
+
+		changed, err := service.TransitionStatus(ctx, "processing", []string{StatusProcessing}, StatusFailed)
+		require.NoError(t, err)
+		assert.True(t, changed)
+
+		// 当前状态不在from中时不修改
+		changed, err = service.TransitionStatus(ctx, "processing", []string{StatusProcessing}, StatusReady)
+		require.NoError(t, err)
+		assert.False(t, changed)
+
+		after, err := service.GetMetadata(ctx, "processing")
+		require.NoError(t, err)
+		assert.Equal(t, StatusFailed, after.Status)
+		assert.Equal(t, before.UpdatedAt, after.UpdatedAt, "处理状态变化不更新UpdatedAt")
+
+		_, err = service.TransitionStatus(ctx, "processing", []string{StatusFailed}, "done")
+		assert.Error(t, err)
+		_, err = service.TransitionStatus(ctx, "missing", []string{StatusProcessing}, StatusReady)
+		assert.Error(t, err)
+	})
+
+	t.Run("更新处理状态", func(t *testing.T) {
+		require.NoError(t, service.UpdateMetadata(ctx, &UpdateMetadataRequest{FileID: "ready", Status: stringPtr(StatusProcessing)}))
+		updated, err := service.GetMetadata(ctx, "ready")
+		require.NoError(t, err)
+		assert.Equal(t, StatusProcessing, updated.Status)
+
+		err = service.UpdateMetadata(ctx, &UpdateMetadataRequest{FileID: "ready", Status: stringPtr("done")})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,172 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ErrVersionNotFound 视频没有指定的历史版本
+var ErrVersionNotFound = errors.New("视频版本不存在")
+
+// Version 视频内容的一个版本，历史版本的视频文件以带版本号的对象名保存在存储中
+type Version struct {
+	Number      int       `json:"number"`       // 版本号，从1开始递增
+	BucketName  string    `json:"bucket_name"`  // 存储桶名
+	ObjectName  string    `json:"object_name"`  // 对象名（存储路径）
+	FileName    string    `json:"file_name"`    // 原始文件名
+	FileSize    int64     `json:"file_size"`    // 文件大小（字节）
+	ContentType string    `json:"content_type"` // 文件类型
+	Checksum    string    `json:"checksum"`     // 文件内容的SHA-256校验和（十六进制）
+	CreatedBy   string    `json:"created_by"`   // 上传该版本的用户
+	CreatedAt   time.Time `json:"created_at"`   // 该版本的上传时间
+}
+
+// CurrentVersion 获取视频当前内容对应的版本
+func (m *FileMetadata) CurrentVersion() Version {
+	version := Version{
+		Number:      max(m.Version, 1),
+		BucketName:  m.BucketName,
+		ObjectName:  m.ObjectName,
+		FileName:    m.FileName,
+		FileSize:    m.FileSize,
+		ContentType: m.ContentType,
+		Checksum:    m.Checksum,
+		CreatedBy:   m.VersionCreatedBy,
+		CreatedAt:   m.VersionCreatedAt,
+	}
+	// 版本功能上线前上传的视频以视频的创建信息作为第一个版本
+	if version.CreatedBy == "" {
+		version.CreatedBy = m.CreatedBy
+	}
+	if version.CreatedAt.IsZero() {
+		version.CreatedAt = m.CreatedAt
+	}
+	return version
+}
+
+// FindVersion 按版本号查找视频的版本，包括当前版本
+func (m *FileMetadata) FindVersion(number int) (Version, bool) {
+	current := m.CurrentVersion()
+	if number == current.Number {
+		return current, true
+	}
+	for _, version := range m.Versions {
+		if version.Number == number {
+			return version, true
+		}
+	}
+	return Version{}, false
+}
+
+// NextVersion 获取下一个新版本的版本号，在当前版本和所有历史版本中递增
+func (m *FileMetadata) NextVersion() int {
+	number := max(m.Version, 1)
+	for _, version := range m.Versions {
+		number = max(number, version.Number)
+	}
+	return number + 1
+}
+
+// AddVersion 以新的视频文件作为视频的当前内容，原内容保存为历史版本
+// 历史版本超过keep个时移除最旧的版本（keep<=0时不限制），返回新的版本号和被移除的版本，调用方负责删除被移除版本的文件
+// 从文件中提取的视频信息随内容一起失效，需要重新提取
+func (s *MetadataService) AddVersion(ctx context.Context, fileID string, content Version, keep int) (int, []Version, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	metadata, exists := s.storage[fileID]
+	if !exists {
+		return 0, nil, fmt.Errorf("元数据不存在: %s", fileID)
+	}
+	if metadata.Archived {
+		return 0, nil, fmt.Errorf("视频已归档，请先恢复: %s", fileID)
+	}
+
+	s.addStats(metadata, -1)
+	defer s.addStats(metadata, 1)
+
+	content.Number = metadata.NextVersion()
+	metadata.Versions = append(metadata.Versions, metadata.CurrentVersion())
+	var removed []Version
+	if keep > 0 && len(metadata.Versions) > keep {
+		removed = slices.Clone(metadata.Versions[:len(metadata.Versions)-keep])
+		metadata.Versions = slices.Delete(metadata.Versions, 0, len(removed))
+	}
+
+	s.setContent(metadata, content)
+	return content.Number, removed, nil
+}
+
+// RestoreVersion 将历史版本恢复为视频的当前内容，原内容保存为历史版本，版本号保持不变
+func (s *MetadataService) RestoreVersion(ctx context.Context, fileID string, number int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	metadata, exists := s.storage[fileID]
+	if !exists {
+		return fmt.Errorf("元数据不存在: %s", fileID)
+	}
+	index := slices.IndexFunc(metadata.Versions, func(version Version) bool {
+		return version.Number == number
+	})
+	if index < 0 {
+		return ErrVersionNotFound
+	}
+	if metadata.Archived {
+		return fmt.Errorf("视频已归档，请先恢复: %s", fileID)
+	}
+
+	s.addStats(metadata, -1)
+	defer s.addStats(metadata, 1)
+
+	restored := metadata.Versions[index]
+	metadata.Versions = slices.Delete(metadata.Versions, index, index+1)
+	metadata.Versions = append(metadata.Versions, metadata.CurrentVersion())
+	slices.SortFunc(metadata.Versions, func(a, b Version) int {
+		return a.Number - b.Number
+	})
+
+	s.setContent(metadata, restored)
+	return nil
+}
+
+// setContent 设置视频的当前内容并清除从原文件中提取的视频信息，调用方需持有写锁
+func (s *MetadataService) setContent(metadata *FileMetadata, content Version) {
+	metadata.Version = content.Number
+	metadata.VersionCreatedBy = content.CreatedBy
+	metadata.VersionCreatedAt = content.CreatedAt
+	metadata.BucketName = content.BucketName
+	metadata.ObjectName = content.ObjectName
+	metadata.FileName = content.FileName
+	metadata.FileSize = content.FileSize
+	metadata.ContentType = content.ContentType
+	metadata.Checksum = content.Checksum
+
+	metadata.Duration = 0
+	metadata.Width = 0
+	metadata.Height = 0
+	metadata.Rotation = 0
+	metadata.Bitrate = 0
+	metadata.VideoCodec = ""
+	metadata.AudioCodec = ""
+	metadata.FrameRate = 0
+
+	now := time.Now()
+	if now.UnixMilli() <= metadata.UpdatedAt.UnixMilli() {
+		now = metadata.UpdatedAt.Truncate(time.Millisecond).Add(time.Millisecond)
+	}
+	metadata.UpdatedAt = now
+}
+
+// referencesObject 元数据的当前内容或历史版本是否引用指定存储对象
+func (m *FileMetadata) referencesObject(bucketName, objectName string) bool {
+	if m.BucketName == bucketName && m.ObjectName == objectName {
+		return true
+	}
+	return slices.ContainsFunc(m.Versions, func(version Version) bool {
+		return version.BucketName == bucketName && version.ObjectName == objectName
+	})
+}
@@ -0,0 +1,167 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// saveVersionTestMetadata 保存用于版本测试的元数据
+func saveVersionTestMetadata(t *testing.T, service *MetadataService) {
+	require.NoError(t, service.SaveMetadata(context.Background(), &FileMetadata{
+		FileID:      "video",
+		BucketName:  "videos",
+		ObjectName:  "videos/video.mp4",
+		FileName:    "v1.mp4",
+		FileSize:    100,
+		ContentType: "video/mp4",
+		Title:       "版本测试",
+		Duration:    60,
+		Width:       1280,
+		Height:      720,
+		VideoCodec:  "H.264",
+		Checksum:    "sum1",
+		CreatedBy:   "alice",
+	}))
+}
+
+// versionContent 创建新版本内容
+func versionContent(number int, size int64) Version {
+	return Version{
+		BucketName:  "videos",
+		ObjectName:  fmt.Sprintf("videos/video-v%d.mp4", number),
+		FileName:    fmt.Sprintf("v%d.mp4", number),
+		FileSize:    size,
+		ContentType: "video/mp4",
+		CreatedBy:   "bob",
+		CreatedAt:   time.Now(),
+	}
+}
+
+// TestMetadataService_AddVersion 测试替换视频内容并保留历史版本
+func TestMetadataService_AddVersion(t *testing.T) {
+	service := NewMetadataService()
+	ctx := context.Background()
+	saveVersionTestMetadata(t, service)
+
+	meta, err := service.GetMetadata(ctx, "video")
+	require.NoError(t, err)
+	assert.Equal(t, 1, meta.Version, "保存时应该从版本1开始")
+	first := meta.CurrentVersion()
+	assert.Equal(t, "alice", first.CreatedBy, "未记录版本上传者时使用创建者")
+	assert.Equal(t, meta.CreatedAt, first.CreatedAt)
+
+	number, removed, err := service.AddVersion(ctx, "video", versionContent(2, 200), 5)
+	require.NoError(t, err)
+	assert.Equal(t, 2, number)
+	assert.Empty(t, removed)
+
+	meta, err = service.GetMetadata(ctx, "video")
+	require.NoError(t, err)
+	assert.Equal(t, 2, meta.Version)
+	assert.Equal(t, "videos/video-v2.mp4", meta.ObjectName)
+	assert.Equal(t, "v2.mp4", meta.FileName)
+	assert.Equal(t, int64(200), meta.FileSize)
+	assert.Equal(t, "bob", meta.CurrentVersion().CreatedBy)
+	assert.Equal(t, "alice", meta.CreatedBy, "替换内容不改变视频的创建者")
+	assert.Zero(t, meta.Duration, "原文件的视频信息应该失效")
+	assert.Zero(t, meta.Width)
+	assert.Empty(t, meta.VideoCodec)
+	require.Len(t, meta.Versions, 1)
+	assert.Equal(t, first, meta.Versions[0])
+
+	stats := service.GetStats(ctx)
+	assert.Equal(t, Usage{Count: 1, Bytes: 200}, stats.Total, "统计只计入当前内容")
+	usage, err := service.GetStorageUsage(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), usage, "存储用量应该包括历史版本")
+
+	assert.Equal(t, 1, service.CountObjectReferences(ctx, "videos", "videos/video.mp4"), "历史版本也引用原文件")
+	assert.Len(t, service.ListObjectReferences(ctx, "videos", "videos/video.mp4"), 0)
+
+	t.Run("超过保留数量时移除最旧的版本", func(t *testing.T) {
+		number, removed, err := service.AddVersion(ctx, "video", versionContent(3, 300), 1)
+		require.NoError(t, err)
+		assert.Equal(t, 3, number)
+		require.Len(t, removed, 1)
+		assert.Equal(t, 1, removed[0].Number)
+
+		meta, err := service.GetMetadata(ctx, "video")
+		require.NoError(t, err)
+		require.Len(t, meta.Versions, 1)
+		assert.Equal(t, 2, meta.Versions[0].Number)
+	})
+
+	t.Run("已归档或不存在的视频", func(t *testing.T) {
+		_, _, err := service.AddVersion(ctx, "missing", versionContent(4, 400), 5)
+		assert.Error(t, err)
+
+		service.SetObjectArchived(ctx, "videos", "videos/video-v3.mp4", true)
+		defer service.SetObjectArchived(ctx, "videos", "videos/video-v3.mp4", false)
+		_, _, err = service.AddVersion(ctx, "video", versionContent(4, 400), 5)
+		assert.Error(t, err)
+	})
+}
+
+// TestMetadataService_RestoreVersion 测试恢复历史版本
+func TestMetadataService_RestoreVersion(t *testing.T) {
+	service := NewMetadataService()
+	ctx := context.Background()
+	saveVersionTestMetadata(t, service)
+	_, _, err := service.AddVersion(ctx, "video", versionContent(2, 200), 0)
+	require.NoError(t, err)
+	_, _, err = service.AddVersion(ctx, "video", versionContent(3, 300), 0)
+	require.NoError(t, err)
+	before, err := service.GetMetadata(ctx, "video")
+	require.NoError(t, err)
+
+	require.NoError(t, service.RestoreVersion(ctx, "video", 1))
+	meta, err := service.GetMetadata(ctx, "video")
+	require.NoError(t, err)
+	assert.Equal(t, 1, meta.Version, "恢复后保持原版本号")
+	assert.Equal(t, "videos/video.mp4", meta.ObjectName)
+	assert.Equal(t, "v1.mp4", meta.FileName)
+	assert.Equal(t, "sum1", meta.Checksum)
+	assert.Equal(t, "alice", meta.CurrentVersion().CreatedBy)
+	assert.True(t, meta.UpdatedAt.After(before.UpdatedAt))
+
+	numbers := []int{}
+	for _, version := range meta.Versions {
+		numbers = append(numbers, version.Number)
+	}
+	assert.Equal(t, []int{2, 3}, numbers, "被替换的内容应该保存为历史版本")
+
+	found, ok := meta.FindVersion(3)
+	require.True(t, ok)
+	assert.Equal(t, "videos/video-v3.mp4", found.ObjectName)
+	_, ok = meta.FindVersion(4)
+	assert.False(t, ok)
+
+	// 新版本的版本号在所有版本中递增
+	number, _, err := service.AddVersion(ctx, "video", versionContent(4, 400), 0)
+	require.NoError(t, err)
+	assert.Equal(t, 4, number)
+
+	assert.ErrorIs(t, service.RestoreVersion(ctx, "video", 4), ErrVersionNotFound, "当前版本不需要恢复")
+	assert.ErrorIs(t, service.RestoreVersion(ctx, "video", 9), ErrVersionNotFound)
+	assert.Error(t, service.RestoreVersion(ctx, "missing", 1))
+}
+
+// TestMetadataService_MoveVersionReferences 测试存储迁移时改写历史版本的存储桶
+func TestMetadataService_MoveVersionReferences(t *testing.T) {
+	service := NewMetadataService()
+	ctx := context.Background()
+	saveVersionTestMetadata(t, service)
+	_, _, err := service.AddVersion(ctx, "video", versionContent(2, 200), 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, service.MoveObjectReferences(ctx, "videos", "videos/video.mp4", "videos-new"))
+	meta, err := service.GetMetadata(ctx, "video")
+	require.NoError(t, err)
+	assert.Equal(t, "videos", meta.BucketName, "当前内容不受影响")
+	assert.Equal(t, "videos-new", meta.Versions[0].BucketName)
+}
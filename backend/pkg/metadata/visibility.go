@@ -0,0 +1,96 @@
+package metadata
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 视频可见性
+const (
+	VisibilityPrivate  = "private"  // 私有：只有上传者和管理员可以查看和播放
+	VisibilityUnlisted = "unlisted" // 不公开：不出现在其他用户的列表中，知道视频ID的用户可以查看和播放
+	VisibilityPublic   = "public"   // 公开：局域网内所有用户可以浏览和播放，包括未登录的用户
+)
+
+// 内容审核状态，待审核和审核拒绝的视频只有上传者和管理员可以查看
+const (
+	ModerationPending  = "pending"  // 被审核钩子标记，等待管理员审核
+	ModerationRejected = "rejected" // 被审核钩子拦截或管理员审核拒绝
+	ModerationApproved = "approved" // 管理员审核通过
+)
+
+// 视频到期处理方式，到期后视频不再出现在其他用户的列表中，由定时任务完成处理
+const (
+	ExpiryUnlist = "unlist" // 公开视频改为不公开，知道视频ID的用户仍可查看
+	ExpiryDelete = "delete" // 删除视频，删除前其他用户按不存在处理
+)
+
+// Viewer 浏览视频的用户，用于可见性判断
+type Viewer struct {
+	UserID string // 用户ID，未登录时为空
+	Admin  bool   // 是否为管理员，管理员可以查看所有视频
+}
+
+// NormalizeVisibility 规范化可见性，空值按私有处理，无效的可见性返回错误
+func NormalizeVisibility(visibility string) (string, error) {
+	visibility = strings.ToLower(strings.TrimSpace(visibility))
+	switch visibility {
+	case "":
+		return VisibilityPrivate, nil
+	case VisibilityPrivate, VisibilityUnlisted, VisibilityPublic:
+		return visibility, nil
+	}
+	return "", fmt.Errorf("可见性必须为private、unlisted或public: %s", visibility)
+}
+
+// NormalizeExpiryAction 规范化到期处理方式，空值按unlist处理，无效的处理方式返回错误
+func NormalizeExpiryAction(action string) (string, error) {
+	action = strings.ToLower(strings.TrimSpace(action))
+	switch action {
+	case "":
+		return ExpiryUnlist, nil
+	case ExpiryUnlist, ExpiryDelete:
+		return action, nil
+	}
+	return "", fmt.Errorf("到期处理方式必须为unlist或delete: %s", action)
+}
+
+// VisibleTo 视频是否可以被用户查看和播放
+// 定时发布前和按delete处理的视频到期后，只有上传者和管理员可以查看
+func (m *FileMetadata) VisibleTo(viewer Viewer) bool {
+	now := time.Now()
+	return (m.Visibility != VisibilityPrivate && m.Published(now) && !m.Blocked(now)) || m.OwnedBy(viewer)
+}
+
+// Blocked 视频在now时是否被内容审核限制或已按delete处理到期
+// 分享链接不校验可见性，但被限制的视频同样不能通过分享链接播放
+func (m *FileMetadata) Blocked(now time.Time) bool {
+	return m.UnderReview() || (m.Expired(now) && m.ExpiryAction == ExpiryDelete)
+}
+
+// ListedFor 视频是否出现在用户的视频列表中：已发布且未到期的公开视频对所有用户列出，其他视频只对上传者和管理员列出
+func (m *FileMetadata) ListedFor(viewer Viewer) bool {
+	now := time.Now()
+	return (m.Visibility == VisibilityPublic && !m.UnderReview() && m.Published(now) && !m.Expired(now)) || m.OwnedBy(viewer)
+}
+
+// Published 视频在now时是否已发布，没有设置定时发布时间的视频始终已发布
+func (m *FileMetadata) Published(now time.Time) bool {
+	return m.PublishAt.IsZero() || !now.Before(m.PublishAt)
+}
+
+// Expired 视频在now时是否已到期，没有设置到期时间的视频不会到期
+func (m *FileMetadata) Expired(now time.Time) bool {
+	return !m.ExpiresAt.IsZero() && !now.Before(m.ExpiresAt)
+}
+
+// UnderReview 视频是否因内容审核被限制，待审核和审核拒绝的视频按私有处理
+func (m *FileMetadata) UnderReview() bool {
+	return m.Moderation == ModerationPending || m.Moderation == ModerationRejected
+}
+
+// OwnedBy 用户是否为视频的上传者或管理员
+func (m *FileMetadata) OwnedBy(viewer Viewer) bool {
+	return viewer.Admin || (viewer.UserID != "" && viewer.UserID == m.CreatedBy)
+}
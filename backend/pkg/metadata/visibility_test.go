@@ -0,0 +1,187 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNormalizeVisibility 测试可见性规范化
+func TestNormalizeVisibility(t *testing.T) {
+	for input, expected := range map[string]string{
+		"":          VisibilityPrivate,
+		"private":   VisibilityPrivate,
+		" Unlisted": VisibilityUnlisted,
+		"PUBLIC":    VisibilityPublic,
+	} {
+		visibility, err := NormalizeVisibility(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, visibility, input)
+	}
+
+	_, err := NormalizeVisibility("friends")
+	assert.Error(t, err)
+}
+
+// TestFileMetadata_Visibility 测试可见性判断
+func TestFileMetadata_Visibility(t *testing.T) {
+	owner := Viewer{UserID: "user-1"}
+	other := Viewer{UserID: "user-2"}
+	anonymous := Viewer{}
+	admin := Viewer{UserID: "admin-1", Admin: true}
+
+	private := &FileMetadata{CreatedBy: "user-1", Visibility: VisibilityPrivate}
+	assert.True(t, private.VisibleTo(owner))
+	assert.True(t, private.VisibleTo(admin))
+	assert.False(t, private.VisibleTo(other))
+	assert.False(t, private.VisibleTo(anonymous))
+	assert.True(t, private.ListedFor(owner))
+	assert.False(t, private.ListedFor(other))
+
+	unlisted := &FileMetadata{CreatedBy: "user-1", Visibility: VisibilityUnlisted}
+	assert.True(t, unlisted.VisibleTo(other), "知道视频ID的用户可以观看不公开的视频")
+	assert.True(t, unlisted.VisibleTo(anonymous))
+	assert.False(t, unlisted.ListedFor(other), "不公开的视频不出现在其他用户的列表中")
+	assert.True(t, unlisted.ListedFor(owner))
+	assert.True(t, unlisted.ListedFor(admin))
+
+	public := &FileMetadata{CreatedBy: "user-1", Visibility: VisibilityPublic}
+	assert.True(t, public.VisibleTo(anonymous))
+	assert.True(t, public.ListedFor(anonymous))
+
+	// 未登录的用户不会因为创建者为空而被当作上传者
+	assert.False(t, (&FileMetadata{Visibility: VisibilityPrivate}).VisibleTo(anonymous))
+}
+
+// TestFileMetadata_UnderReview 测试内容审核对可见性的限制
+func TestFileMetadata_UnderReview(t *testing.T) {
+	owner := Viewer{UserID: "user-1"}
+	other := Viewer{UserID: "user-2"}
+	admin := Viewer{UserID: "admin-1", Admin: true}
+
+	for _, status := range []string{ModerationPending, ModerationRejected} {
+		meta := &FileMetadata{CreatedBy: "user-1", Visibility: VisibilityPublic, Moderation: status}
+		assert.True(t, meta.UnderReview())
+		assert.True(t, meta.Blocked(time.Now()), "%s的视频不能通过分享链接播放", status)
+		assert.False(t, meta.VisibleTo(other), "%s的视频不能被其他用户查看", status)
+		assert.False(t, meta.ListedFor(other))
+		assert.True(t, meta.VisibleTo(owner))
+		assert.True(t, meta.ListedFor(admin))
+	}
+
+	approved := &FileMetadata{CreatedBy: "user-1", Visibility: VisibilityPublic, Moderation: ModerationApproved}
+	assert.False(t, approved.UnderReview())
+	assert.False(t, approved.Blocked(time.Now()))
+	assert.True(t, approved.VisibleTo(other))
+	assert.True(t, approved.ListedFor(other))
+}
+
+// TestNormalizeExpiryAction 测试到期处理方式规范化
+func TestNormalizeExpiryAction(t *testing.T) {
+	for input, expected := range map[string]string{
+		"":         ExpiryUnlist,
+		" Unlist ": ExpiryUnlist,
+		"DELETE":   ExpiryDelete,
+	} {
+		action, err := NormalizeExpiryAction(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, action, input)
+	}
+
+	_, err := NormalizeExpiryAction("archive")
+	assert.Error(t, err)
+}
+
+// TestFileMetadata_Schedule 测试定时发布和到期对可见性的限制
+func TestFileMetadata_Schedule(t *testing.T) {
+	owner := Viewer{UserID: "user-1"}
+	other := Viewer{UserID: "user-2"}
+	admin := Viewer{UserID: "admin-1", Admin: true}
+	now := time.Now()
+
+	scheduled := &FileMetadata{CreatedBy: "user-1", Visibility: VisibilityPublic, PublishAt: now.Add(time.Hour)}
+	assert.False(t, scheduled.Published(now))
+	assert.True(t, scheduled.Published(now.Add(time.Hour)), "到达发布时间即发布")
+	assert.False(t, scheduled.VisibleTo(other), "发布前其他用户不能查看")
+	assert.False(t, scheduled.ListedFor(other))
+	assert.True(t, scheduled.VisibleTo(owner))
+	assert.True(t, scheduled.ListedFor(admin))
+
+	published := &FileMetadata{CreatedBy: "user-1", Visibility: VisibilityPublic, PublishAt: now.Add(-time.Hour)}
+	assert.True(t, published.VisibleTo(other))
+	assert.True(t, published.ListedFor(other))
+
+	unlisted := &FileMetadata{CreatedBy: "user-1", Visibility: VisibilityPublic, ExpiresAt: now.Add(-time.Minute)}
+	assert.True(t, unlisted.Expired(now))
+	assert.False(t, unlisted.Expired(now.Add(-time.Hour)))
+	assert.True(t, unlisted.VisibleTo(other), "按unlist处理的视频到期后仍可通过ID查看")
+	assert.False(t, unlisted.Blocked(now))
+	assert.False(t, unlisted.ListedFor(other), "到期的视频不出现在其他用户的列表中")
+	assert.True(t, unlisted.ListedFor(owner))
+
+	deleted := &FileMetadata{CreatedBy: "user-1", Visibility: VisibilityPublic, ExpiresAt: now.Add(-time.Minute), ExpiryAction: ExpiryDelete}
+	assert.False(t, deleted.VisibleTo(other), "按delete处理的视频到期后按不存在处理")
+	assert.True(t, deleted.Blocked(now))
+	assert.True(t, deleted.VisibleTo(admin))
+
+	assert.False(t, (&FileMetadata{}).Expired(now), "没有设置到期时间的视频不会到期")
+}
+
+// TestMetadataService_Visibility 测试保存、更新和按可见性列出元数据
+func TestMetadataService_Visibility(t *testing.T) {
+	ctx := context.Background()
+	service := NewMetadataService()
+
+	for _, meta := range []*FileMetadata{
+		{FileID: "private", Title: "私有", CreatedBy: "user-1"},
+		{FileID: "unlisted", Title: "不公开", CreatedBy: "user-1", Visibility: VisibilityUnlisted},
+		{FileID: "public", Title: "公开", CreatedBy: "user-2", Visibility: VisibilityPublic},
+	} {
+		require.NoError(t, service.SaveMetadata(ctx, meta))
+	}
+
+	saved, err := service.GetMetadata(ctx, "private")
+	require.NoError(t, err)
+	assert.Equal(t, VisibilityPrivate, saved.Visibility, "未设置可见性时默认为私有")
+
+	err = service.SaveMetadata(ctx, &FileMetadata{FileID: "invalid", Title: "无效", CreatedBy: "user-1", Visibility: "friends"})
+	assert.Error(t, err)
+
+	listIDs := func(viewer *Viewer) []string {
+		resp, err := service.ListMetadata(ctx, &ListMetadataRequest{Limit: 10, ListedFor: viewer})
+		require.NoError(t, err)
+		var ids []string
+		for _, item := range resp.Items {
+			ids = append(ids, item.FileID)
+		}
+		return ids
+	}
+	assert.ElementsMatch(t, []string{"public"}, listIDs(&Viewer{}))
+	assert.ElementsMatch(t, []string{"private", "unlisted", "public"}, listIDs(&Viewer{UserID: "user-1"}))
+	assert.ElementsMatch(t, []string{"public"}, listIDs(&Viewer{UserID: "user-2"}))
+	assert.ElementsMatch(t, []string{"private", "unlisted", "public"}, listIDs(&Viewer{Admin: true}))
+	assert.Len(t, listIDs(nil), 3, "不指定用户时不按可见性过滤")
+
+	public := VisibilityPublic
+	require.NoError(t, service.UpdateMetadata(ctx, &UpdateMetadataRequest{FileID: "private", Visibility: &public}))
+	assert.ElementsMatch(t, []string{"private", "public"}, listIDs(&Viewer{}))
+
+	invalid := "friends"
+	assert.Error(t, service.UpdateMetadata(ctx, &UpdateMetadataRequest{FileID: "private", Visibility: &invalid}))
+
+	publishAt := time.Now().Add(time.Hour)
+	require.NoError(t, service.UpdateMetadata(ctx, &UpdateMetadataRequest{FileID: "public", PublishAt: &publishAt}))
+	assert.ElementsMatch(t, []string{"private"}, listIDs(&Viewer{}), "定时发布前不列出")
+	assert.ElementsMatch(t, []string{"private", "public"}, listIDs(&Viewer{UserID: "user-2"}))
+
+	expiry := "archive"
+	assert.Error(t, service.UpdateMetadata(ctx, &UpdateMetadataRequest{FileID: "public", ExpiryAction: &expiry}))
+	expiry = " Delete"
+	require.NoError(t, service.UpdateMetadata(ctx, &UpdateMetadataRequest{FileID: "public", ExpiryAction: &expiry}))
+	saved, err = service.GetMetadata(ctx, "public")
+	require.NoError(t, err)
+	assert.Equal(t, ExpiryDelete, saved.ExpiryAction)
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// ContextKeyClaims 请求上下文中保存令牌声明的键
+const ContextKeyClaims = "current_user"
+
+// WorkerSecretHeader 远程worker携带共享密钥的请求头
+const WorkerSecretHeader = "X-Worker-Secret"
+
+// 认证相关错误码
+const (
+	CodeUnauthorized = 7010 // 未登录或令牌无效
+	CodeForbidden    = 7011 // 权限不足
+)
+
+// TokenParser 令牌解析接口
+type TokenParser interface {
+	ParseToken(token string) (*user.Claims, error)
+}
+
+// JWTAuth JWT认证中间件
+// required为false时未携带令牌的请求直接放行，携带令牌时必须有效
+func JWTAuth(parser TokenParser, required bool) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		token := extractBearerToken(c)
+		if token == "" {
+			if required {
+				abortWithError(c, consts.StatusUnauthorized, CodeUnauthorized, "未登录")
+				return
+			}
+			c.Next(ctx)
+			return
+		}
+
+		claims, err := parser.ParseToken(token)
+		if err != nil {
+			abortWithError(c, consts.StatusUnauthorized, CodeUnauthorized, err.Error())
+			return
+		}
+
+		c.Set(ContextKeyClaims, claims)
+		c.Next(user.ContextWithClaims(ctx, claims))
+	}
+}
+
+// RequireRoles 角色校验中间件，不传角色时只要求已登录
+func RequireRoles(roles ...string) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			abortWithError(c, consts.StatusUnauthorized, CodeUnauthorized, "未登录")
+			return
+		}
+
+		if len(roles) > 0 && !containsRole(roles, claims.Role) {
+			abortWithError(c, consts.StatusForbidden, CodeForbidden, "权限不足")
+			return
+		}
+
+		c.Next(ctx)
+	}
+}
+
+// RequirePermission 权限校验中间件，当前用户的角色必须拥有指定权限
+// 只校验操作类型，视频归属等需要查询数据的校验由服务层完成
+func RequirePermission(permission user.Permission) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			abortWithError(c, consts.StatusUnauthorized, CodeUnauthorized, "未登录")
+			return
+		}
+
+		if !user.HasPermission(claims.Role, permission) {
+			abortWithError(c, consts.StatusForbidden, CodeForbidden, "权限不足")
+			return
+		}
+
+		c.Next(ctx)
+	}
+}
+
+// RequireWorkerSecret 远程worker认证中间件，请求头中的密钥必须与配置一致
+// 未配置密钥时拒绝所有请求
+func RequireWorkerSecret(secret string) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		provided := c.GetHeader(WorkerSecretHeader)
+		if secret == "" || subtle.ConstantTimeCompare(provided, []byte(secret)) != 1 {
+			abortWithError(c, consts.StatusUnauthorized, CodeUnauthorized, "worker密钥无效")
+			return
+		}
+
+		c.Next(ctx)
+	}
+}
+
+// GetClaims 获取当前请求的令牌声明
+func GetClaims(c *app.RequestContext) (*user.Claims, bool) {
+	value, exists := c.Get(ContextKeyClaims)
+	if !exists {
+		return nil, false
+	}
+	claims, ok := value.(*user.Claims)
+	return claims, ok && claims != nil
+}
+
+// extractBearerToken 从Authorization头中提取Bearer令牌
+func extractBearerToken(c *app.RequestContext) string {
+	header := strings.TrimSpace(string(c.GetHeader("Authorization")))
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(header[7:])
+}
+
+// containsRole 检查角色是否在允许列表中
+func containsRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// abortWithError 返回错误响应并终止请求
+func abortWithError(c *app.RequestContext, status, code int, message string) {
+	c.AbortWithStatusJSON(status, utils.H{
+		"base": utils.H{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
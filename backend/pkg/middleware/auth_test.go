@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// setupAuthTestServer 创建认证测试服务器
+func setupAuthTestServer(t *testing.T) (*server.Hertz, *user.TokenManager) {
+	manager, err := user.NewTokenManager("test-secret", time.Hour)
+	require.NoError(t, err)
+
+	h := server.New()
+	whoami := func(ctx context.Context, c *app.RequestContext) {
+		claims, ok := user.ClaimsFromContext(ctx)
+		if !ok {
+			c.String(http.StatusOK, "anonymous")
+			return
+		}
+		c.String(http.StatusOK, claims.Username)
+	}
+
+	h.GET("/optional", JWTAuth(manager, false), whoami)
+	h.GET("/required", JWTAuth(manager, true), whoami)
+	h.GET("/admin", JWTAuth(manager, false), RequireRoles(user.RoleAdmin), whoami)
+	h.POST("/videos", JWTAuth(manager, false), RequirePermission(user.PermissionVideoUpload), whoami)
+
+	return h, manager
+}
+
+// issueToken 为测试用户签发令牌
+func issueToken(t *testing.T, manager *user.TokenManager, role string) string {
+	token, _, err := manager.GenerateToken(&user.User{ID: "user-1", Username: "alice", Role: role})
+	require.NoError(t, err)
+	return token
+}
+
+// TestJWTAuth_Optional 测试可选认证
+func TestJWTAuth_Optional(t *testing.T) {
+	h, manager := setupAuthTestServer(t)
+
+	w := ut.PerformRequest(h.Engine, "GET", "/optional", nil)
+	assert.Equal(t, http.StatusOK, w.Code, "未携带令牌应该放行")
+	assert.Equal(t, "anonymous", w.Body.String())
+
+	token := issueToken(t, manager, user.RoleViewer)
+	w = ut.PerformRequest(h.Engine, "GET", "/optional", nil, ut.Header{Key: "Authorization", Value: "Bearer " + token})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "alice", w.Body.String(), "有效令牌应该注入用户信息")
+
+	w = ut.PerformRequest(h.Engine, "GET", "/optional", nil, ut.Header{Key: "Authorization", Value: "Bearer invalid"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "无效令牌应该被拒绝")
+}
+
+// TestJWTAuth_Required 测试必需认证
+func TestJWTAuth_Required(t *testing.T) {
+	h, manager := setupAuthTestServer(t)
+
+	w := ut.PerformRequest(h.Engine, "GET", "/required", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "未携带令牌应该返回401")
+	assert.Contains(t, w.Body.String(), "未登录")
+
+	token := issueToken(t, manager, user.RoleViewer)
+	w = ut.PerformRequest(h.Engine, "GET", "/required", nil, ut.Header{Key: "Authorization", Value: "bearer " + token})
+	assert.Equal(t, http.StatusOK, w.Code, "Bearer前缀应该不区分大小写")
+}
+
+// TestRequireRoles 测试角色校验
+func TestRequireRoles(t *testing.T) {
+	h, manager := setupAuthTestServer(t)
+
+	w := ut.PerformRequest(h.Engine, "GET", "/admin", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "未登录应该返回401")
+
+	viewerToken := issueToken(t, manager, user.RoleViewer)
+	w = ut.PerformRequest(h.Engine, "GET", "/admin", nil, ut.Header{Key: "Authorization", Value: "Bearer " + viewerToken})
+	assert.Equal(t, http.StatusForbidden, w.Code, "非管理员应该返回403")
+	assert.Contains(t, w.Body.String(), "权限不足")
+
+	adminToken := issueToken(t, manager, user.RoleAdmin)
+	w = ut.PerformRequest(h.Engine, "GET", "/admin", nil, ut.Header{Key: "Authorization", Value: "Bearer " + adminToken})
+	assert.Equal(t, http.StatusOK, w.Code, "管理员应该放行")
+}
+
+// TestRequirePermission 测试权限校验
+func TestRequirePermission(t *testing.T) {
+	h, manager := setupAuthTestServer(t)
+
+	w := ut.PerformRequest(h.Engine, "POST", "/videos", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "未登录应该返回401")
+
+	viewerToken := issueToken(t, manager, user.RoleViewer)
+	w = ut.PerformRequest(h.Engine, "POST", "/videos", nil, ut.Header{Key: "Authorization", Value: "Bearer " + viewerToken})
+	assert.Equal(t, http.StatusForbidden, w.Code, "观看者不能上传")
+	assert.Contains(t, w.Body.String(), "权限不足")
+
+	for _, role := range []string{user.RoleUploader, user.RoleAdmin} {
+		token := issueToken(t, manager, role)
+		w = ut.PerformRequest(h.Engine, "POST", "/videos", nil, ut.Header{Key: "Authorization", Value: "Bearer " + token})
+		assert.Equal(t, http.StatusOK, w.Code, "%s应该可以上传", role)
+	}
+}
+
+// TestRequireWorkerSecret 测试worker密钥认证
+func TestRequireWorkerSecret(t *testing.T) {
+	h := server.New()
+	ok := func(ctx context.Context, c *app.RequestContext) {
+		c.String(http.StatusOK, "ok")
+	}
+	h.POST("/worker", RequireWorkerSecret("worker-secret"), ok)
+	h.POST("/disabled", RequireWorkerSecret(""), ok)
+
+	w := ut.PerformRequest(h.Engine, "POST", "/worker", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "未携带密钥应该返回401")
+
+	w = ut.PerformRequest(h.Engine, "POST", "/worker", nil, ut.Header{Key: WorkerSecretHeader, Value: "wrong"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "密钥错误应该返回401")
+	assert.Contains(t, w.Body.String(), "worker密钥无效")
+
+	w = ut.PerformRequest(h.Engine, "POST", "/worker", nil, ut.Header{Key: WorkerSecretHeader, Value: "worker-secret"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = ut.PerformRequest(h.Engine, "POST", "/disabled", nil, ut.Header{Key: WorkerSecretHeader, Value: ""})
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "未配置密钥时应该拒绝所有请求")
+}
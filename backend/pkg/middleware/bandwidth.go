@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// bandwidthChunkSize 限速时单次读取的最大字节数，按该粒度等待，避免读取大块数据后长时间停顿
+const bandwidthChunkSize = 32 * 1024
+
+// BandwidthLimiter 按字节计数的令牌桶，限制读取速率，可由多个请求共享
+// 令牌不足时允许预支，预支的请求按欠下的字节数等待，多个请求共享时按读取顺序轮流获得带宽
+type BandwidthLimiter struct {
+	rate     float64 // 每秒补充的字节数
+	burst    float64 // 桶容量
+	tokens   float64
+	lastSeen time.Time
+	now      func() time.Time
+	mutex    sync.Mutex
+}
+
+// NewBandwidthLimiter 创建带宽限制器，bytesPerSecond不大于0时返回nil表示不限制
+// 桶容量为一秒的带宽，且不小于单次读取的字节数
+func NewBandwidthLimiter(bytesPerSecond int64) *BandwidthLimiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	burst := max(float64(bytesPerSecond), bandwidthChunkSize)
+	return &BandwidthLimiter{
+		rate:   float64(bytesPerSecond),
+		burst:  burst,
+		tokens: burst,
+		now:    time.Now,
+	}
+}
+
+// reserve 消耗n个字节的令牌，返回需要等待的时间
+func (l *BandwidthLimiter) reserve(n int) time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	if !l.lastSeen.IsZero() {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.lastSeen).Seconds()*l.rate)
+	}
+	l.lastSeen = now
+
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// throttledReader 按带宽限制读取请求体
+type throttledReader struct {
+	reader   io.Reader
+	limiters []*BandwidthLimiter
+	sleep    func(time.Duration)
+}
+
+// Read 读取后按所有限制器中最长的等待时间等待
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > bandwidthChunkSize {
+		p = p[:bandwidthChunkSize]
+	}
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		var wait time.Duration
+		for _, limiter := range r.limiters {
+			wait = max(wait, limiter.reserve(n))
+		}
+		if wait > 0 {
+			r.sleep(wait)
+		}
+	}
+	return n, err
+}
+
+// Close 关闭原始请求体，服务端结束请求时调用
+func (r *throttledReader) Close() error {
+	if closer, ok := r.reader.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// BandwidthPolicy 上传带宽限制策略，只限制设置过的路由
+// 所有受限请求共享总带宽，每个请求另有独立的单请求带宽
+type BandwidthPolicy struct {
+	global       *BandwidthLimiter
+	requestLimit int64
+	routes       map[string]bool
+	now          func() time.Time
+	sleep        func(time.Duration)
+}
+
+// NewBandwidthPolicy 创建带宽限制策略，参数为每秒字节数，不大于0表示不限制；都不限制时返回nil
+func NewBandwidthPolicy(globalBytesPerSecond, requestBytesPerSecond int64) *BandwidthPolicy {
+	if globalBytesPerSecond <= 0 && requestBytesPerSecond <= 0 {
+		return nil
+	}
+	return &BandwidthPolicy{
+		global:       NewBandwidthLimiter(globalBytesPerSecond),
+		requestLimit: requestBytesPerSecond,
+		routes:       make(map[string]bool),
+		now:          time.Now,
+		sleep:        time.Sleep,
+	}
+}
+
+// SetRoute 限制路由的请求体读取带宽，fullPath为注册时的路由模式
+func (p *BandwidthPolicy) SetRoute(method, fullPath string) {
+	p.routes[method+" "+fullPath] = true
+}
+
+// limitersFor 获取请求使用的带宽限制器，路由不受限时返回nil
+func (p *BandwidthPolicy) limitersFor(c *app.RequestContext) []*BandwidthLimiter {
+	if !p.routes[string(c.Method())+" "+c.FullPath()] {
+		return nil
+	}
+
+	limiters := make([]*BandwidthLimiter, 0, 2)
+	if p.global != nil {
+		limiters = append(limiters, p.global)
+	}
+	if limiter := NewBandwidthLimiter(p.requestLimit); limiter != nil {
+		limiter.now = p.now
+		limiters = append(limiters, limiter)
+	}
+	return limiters
+}
+
+// UploadBandwidth 上传带宽限制中间件，policy为nil时不限制
+// 需要服务端以流的方式读取请求体，替换请求体流后处理函数读取请求体时按带宽限制等待
+func UploadBandwidth(policy *BandwidthPolicy) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		if policy == nil || !c.Request.IsBodyStream() {
+			c.Next(ctx)
+			return
+		}
+
+		limiters := policy.limitersFor(c)
+		if len(limiters) == 0 {
+			c.Next(ctx)
+			return
+		}
+
+		// 不能使用SetBodyStream，它会先关闭原始请求体流
+		c.Request.ConstructBodyStream(c.Request.BodyBuffer(), &throttledReader{
+			reader:   c.Request.BodyStream(),
+			limiters: limiters,
+			sleep:    policy.sleep,
+		})
+		c.Next(ctx)
+	}
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBandwidthClock 可控时钟，等待时直接推进时间并累计等待时长
+type fakeBandwidthClock struct {
+	current time.Time
+	waited  time.Duration
+}
+
+func newFakeBandwidthClock() *fakeBandwidthClock {
+	return &fakeBandwidthClock{current: time.Unix(1700000000, 0)}
+}
+
+func (c *fakeBandwidthClock) now() time.Time {
+	return c.current
+}
+
+func (c *fakeBandwidthClock) sleep(d time.Duration) {
+	c.current = c.current.Add(d)
+	c.waited += d
+}
+
+// newTestBandwidthPolicy 创建使用可控时钟的带宽限制策略
+func newTestBandwidthPolicy(globalBytesPerSecond, requestBytesPerSecond int64) (*BandwidthPolicy, *fakeBandwidthClock) {
+	clock := newFakeBandwidthClock()
+	policy := NewBandwidthPolicy(globalBytesPerSecond, requestBytesPerSecond)
+	policy.now = clock.now
+	policy.sleep = clock.sleep
+	if policy.global != nil {
+		policy.global.now = clock.now
+	}
+	policy.SetRoute("PUT", "/upload")
+	return policy, clock
+}
+
+// performBandwidthRequest 经过带宽限制中间件读取请求体，返回读取到的数据
+func performBandwidthRequest(t *testing.T, policy *BandwidthPolicy, method, fullPath string, body []byte) []byte {
+	c := app.NewContext(0)
+	c.Request.SetMethod(method)
+	c.SetFullPath(fullPath)
+	c.Request.SetBodyStream(bytes.NewReader(body), len(body))
+
+	var received []byte
+	c.SetHandlers(app.HandlersChain{
+		UploadBandwidth(policy),
+		func(ctx context.Context, c *app.RequestContext) {
+			var err error
+			received, err = io.ReadAll(c.RequestBodyStream())
+			require.NoError(t, err)
+		},
+	})
+	c.Next(context.Background())
+	return received
+}
+
+// TestBandwidthLimiter_Reserve 测试按字节计数的令牌消耗和补充
+func TestBandwidthLimiter_Reserve(t *testing.T) {
+	assert.Nil(t, NewBandwidthLimiter(0), "不大于0时不限制")
+
+	clock := newFakeBandwidthClock()
+	limiter := NewBandwidthLimiter(64 * 1024)
+	limiter.now = clock.now
+
+	assert.Zero(t, limiter.reserve(64*1024), "桶容量内不需要等待")
+	assert.Equal(t, 500*time.Millisecond, limiter.reserve(32*1024), "应该按欠下的字节数等待")
+	assert.Equal(t, time.Second, limiter.reserve(32*1024), "后续读取排在已预支的读取之后")
+
+	clock.sleep(time.Hour)
+	assert.Zero(t, limiter.reserve(64*1024), "令牌补充不应该超过桶容量")
+	assert.Equal(t, 250*time.Millisecond, limiter.reserve(16*1024))
+}
+
+// TestUploadBandwidth 测试限制请求体的读取带宽
+func TestUploadBandwidth(t *testing.T) {
+	body := bytes.Repeat([]byte("zhulong"), 100*1024/7)
+
+	t.Run("单个请求带宽", func(t *testing.T) {
+		policy, clock := newTestBandwidthPolicy(0, 64*1024)
+
+		received := performBandwidthRequest(t, policy, "PUT", "/upload", body)
+		assert.Equal(t, body, received, "限速不应该改变请求体")
+		expected := time.Duration(float64(len(body)-64*1024) / (64 * 1024) * float64(time.Second))
+		assert.InDelta(t, expected, clock.waited, float64(time.Millisecond))
+
+		// 每个请求有独立的带宽
+		clock.waited = 0
+		performBandwidthRequest(t, policy, "PUT", "/upload", body)
+		assert.InDelta(t, expected, clock.waited, float64(time.Millisecond))
+	})
+
+	t.Run("所有请求共享总带宽", func(t *testing.T) {
+		policy, clock := newTestBandwidthPolicy(int64(len(body)), 0)
+
+		performBandwidthRequest(t, policy, "PUT", "/upload", body)
+		assert.Zero(t, clock.waited, "桶容量内不需要等待")
+
+		performBandwidthRequest(t, policy, "PUT", "/upload", body)
+		assert.InDelta(t, time.Second, clock.waited, float64(time.Millisecond), "第二个请求应该等待总带宽补充")
+	})
+
+	t.Run("未设置的路由不限速", func(t *testing.T) {
+		policy, clock := newTestBandwidthPolicy(1024, 1024)
+
+		received := performBandwidthRequest(t, policy, "GET", "/upload", body)
+		assert.Equal(t, body, received)
+		performBandwidthRequest(t, policy, "PUT", "/other", body)
+		assert.Zero(t, clock.waited)
+	})
+
+	t.Run("未配置带宽限制", func(t *testing.T) {
+		assert.Nil(t, NewBandwidthPolicy(0, 0))
+		assert.Equal(t, body, performBandwidthRequest(t, nil, "PUT", "/upload", body))
+	})
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// bodyTooLargeCode 请求体超过大小限制时返回的错误码，与上传文件大小验证失败一致
+const bodyTooLargeCode = 1003
+
+// ErrRequestBodyTooLarge 读取的请求体超过大小限制
+var ErrRequestBodyTooLarge = errors.New("请求体超过大小限制")
+
+// BodyLimitFunc 获取请求允许的最大请求体大小（字节），不大于0时不限制
+// 在处理函数之前调用，只能使用路由参数、请求头和上下文中已有的信息
+type BodyLimitFunc func(ctx context.Context, c *app.RequestContext) int64
+
+// BodyLimitPolicy 请求体大小限制策略，只限制设置过的路由
+type BodyLimitPolicy struct {
+	routes map[string]BodyLimitFunc
+}
+
+// NewBodyLimitPolicy 创建请求体大小限制策略
+func NewBodyLimitPolicy() *BodyLimitPolicy {
+	return &BodyLimitPolicy{routes: make(map[string]BodyLimitFunc)}
+}
+
+// SetRoute 设置路由的请求体大小限制，fullPath为注册时的路由模式
+func (p *BodyLimitPolicy) SetRoute(method, fullPath string, limit BodyLimitFunc) {
+	p.routes[method+" "+fullPath] = limit
+}
+
+// limitFor 获取请求的请求体大小限制，路由不受限时返回0
+func (p *BodyLimitPolicy) limitFor(ctx context.Context, c *app.RequestContext) int64 {
+	limit, ok := p.routes[string(c.Method())+" "+c.FullPath()]
+	if !ok || limit == nil {
+		return 0
+	}
+	return limit(ctx, c)
+}
+
+// limitedBodyReader 读取超过大小限制时返回ErrRequestBodyTooLarge
+type limitedBodyReader struct {
+	reader   io.Reader
+	limit    int64
+	read     int64
+	exceeded bool
+}
+
+// Read 最多读取比限制多一个字节，用于判断请求体是否超过限制
+func (r *limitedBodyReader) Read(p []byte) (int, error) {
+	if r.exceeded {
+		return 0, ErrRequestBodyTooLarge
+	}
+	if remaining := r.limit - r.read + 1; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	if r.read > r.limit {
+		r.exceeded = true
+		return n, ErrRequestBodyTooLarge
+	}
+	return n, err
+}
+
+// Close 关闭原始请求体，服务端结束请求时调用
+func (r *limitedBodyReader) Close() error {
+	if closer, ok := r.reader.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// BodyLimit 请求体大小限制中间件，policy为nil时不限制
+// Content-Length超过限制时在读取请求体之前返回413；未声明长度（分块传输）时在读取超过限制后中断读取并返回413
+func BodyLimit(policy *BodyLimitPolicy) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		if policy == nil {
+			c.Next(ctx)
+			return
+		}
+		limit := policy.limitFor(ctx, c)
+		if limit <= 0 {
+			c.Next(ctx)
+			return
+		}
+
+		if size := int64(c.Request.Header.ContentLength()); size > limit {
+			abortWithError(c, consts.StatusRequestEntityTooLarge, bodyTooLargeCode,
+				fmt.Sprintf("%s，最大允许%d字节，请求%d字节", ErrRequestBodyTooLarge.Error(), limit, size))
+			return
+		}
+		if !c.Request.IsBodyStream() {
+			// 较小的请求体已由服务端完整读取
+			if size := int64(len(c.Request.Body())); size > limit {
+				abortWithError(c, consts.StatusRequestEntityTooLarge, bodyTooLargeCode,
+					fmt.Sprintf("%s，最大允许%d字节，请求%d字节", ErrRequestBodyTooLarge.Error(), limit, size))
+				return
+			}
+			c.Next(ctx)
+			return
+		}
+
+		// 不能使用SetBodyStream，它会先关闭原始请求体流
+		reader := &limitedBodyReader{reader: c.Request.BodyStream(), limit: limit}
+		c.Request.ConstructBodyStream(c.Request.BodyBuffer(), reader)
+		c.Next(ctx)
+		if reader.exceeded {
+			// 处理函数读取失败时返回的错误不能区分原因，统一替换为413
+			abortWithError(c, consts.StatusRequestEntityTooLarge, bodyTooLargeCode,
+				fmt.Sprintf("%s，最大允许%d字节", ErrRequestBodyTooLarge.Error(), limit))
+		}
+	}
+}
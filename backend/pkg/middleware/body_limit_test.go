@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+// performBodyLimitRequest 经过请求体大小限制中间件读取请求体，contentLength为-1时表示分块传输
+// 返回处理函数是否执行和读取请求体时的错误
+func performBodyLimitRequest(policy *BodyLimitPolicy, fullPath string, body []byte, contentLength int) (*app.RequestContext, bool, error) {
+	c := app.NewContext(0)
+	c.Request.SetMethod("PUT")
+	c.SetFullPath(fullPath)
+	c.Request.SetBodyStream(bytes.NewReader(body), contentLength)
+
+	called := false
+	var readErr error
+	c.SetHandlers(app.HandlersChain{
+		BodyLimit(policy),
+		func(ctx context.Context, c *app.RequestContext) {
+			called = true
+			_, readErr = io.ReadAll(c.RequestBodyStream())
+			if readErr != nil {
+				c.JSON(consts.StatusBadRequest, map[string]string{"message": readErr.Error()})
+				return
+			}
+			c.Status(consts.StatusOK)
+		},
+	})
+	c.Next(context.Background())
+	return c, called, readErr
+}
+
+// TestBodyLimit 测试按Content-Length和实际读取的字节数限制请求体大小
+func TestBodyLimit(t *testing.T) {
+	policy := NewBodyLimitPolicy()
+	policy.SetRoute("PUT", "/upload", func(ctx context.Context, c *app.RequestContext) int64 {
+		return 100
+	})
+	policy.SetRoute("PUT", "/unlimited", func(ctx context.Context, c *app.RequestContext) int64 {
+		return 0
+	})
+
+	t.Run("Content-Length超过限制时不执行处理函数", func(t *testing.T) {
+		c, called, _ := performBodyLimitRequest(policy, "/upload", make([]byte, 101), 101)
+		assert.False(t, called, "应该在读取请求体之前拒绝")
+		assert.Equal(t, consts.StatusRequestEntityTooLarge, c.Response.StatusCode())
+		assert.Contains(t, string(c.Response.Body()), `"code":1003`)
+	})
+
+	t.Run("未超过限制", func(t *testing.T) {
+		c, called, err := performBodyLimitRequest(policy, "/upload", make([]byte, 100), 100)
+		assert.True(t, called)
+		assert.NoError(t, err)
+		assert.Equal(t, consts.StatusOK, c.Response.StatusCode())
+
+		c, _, err = performBodyLimitRequest(policy, "/upload", make([]byte, 100), -1)
+		assert.NoError(t, err, "分块传输未超过限制时应该完整读取")
+		assert.Equal(t, consts.StatusOK, c.Response.StatusCode())
+	})
+
+	t.Run("分块传输读取超过限制时中断", func(t *testing.T) {
+		c, called, err := performBodyLimitRequest(policy, "/upload", make([]byte, 1000), -1)
+		assert.True(t, called)
+		assert.ErrorIs(t, err, ErrRequestBodyTooLarge)
+		assert.Equal(t, consts.StatusRequestEntityTooLarge, c.Response.StatusCode(), "处理函数的错误响应应该替换为413")
+	})
+
+	t.Run("未设置或不限制的路由", func(t *testing.T) {
+		for _, fullPath := range []string{"/unlimited", "/other"} {
+			c, _, err := performBodyLimitRequest(policy, fullPath, make([]byte, 1000), 1000)
+			assert.NoError(t, err)
+			assert.Equal(t, consts.StatusOK, c.Response.StatusCode())
+		}
+
+		c, _, err := performBodyLimitRequest(nil, "/upload", make([]byte, 1000), 1000)
+		assert.NoError(t, err)
+		assert.Equal(t, consts.StatusOK, c.Response.StatusCode())
+	})
+}
@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// 跨域请求处理方式
+const (
+	CORSModeOff    = "off"    // 不处理跨域请求，浏览器只允许同源访问
+	CORSModeLAN    = "lan"    // 允许本机和局域网地址的来源，以及配置的来源
+	CORSModeStrict = "strict" // 只允许配置的来源
+)
+
+const (
+	// corsAllowMethods 允许跨域使用的请求方法
+	corsAllowMethods = "GET, HEAD, POST, PUT, DELETE, OPTIONS"
+	// corsAllowHeaders 允许跨域携带的请求头
+	corsAllowHeaders = "Authorization, Content-Type, Accept-Language, Range, If-None-Match, " + HeaderRequestID
+	// corsExposeHeaders 允许跨域读取的响应头
+	corsExposeHeaders = "Content-Length, Content-Range, Accept-Ranges, ETag, Retry-After, Content-Language, " + HeaderRequestID
+	// publicAllowMethods 公开路径允许跨域使用的请求方法，只允许读取
+	publicAllowMethods = "GET, HEAD, OPTIONS"
+	// publicAllowHeaders 公开路径允许跨域携带的请求头，投屏接收端拖动播放时使用Range
+	publicAllowHeaders = "Range, Accept-Language"
+)
+
+// CORSPolicy 跨域来源策略
+// 允许的来源始终原样返回在Access-Control-Allow-Origin中，不使用"*"，允许携带凭据时也符合浏览器要求
+type CORSPolicy struct {
+	mode             string
+	origins          []originPattern
+	allowCredentials bool
+	maxAge           time.Duration
+	publicPrefixes   []string // 允许任意来源读取的路径前缀
+}
+
+// originPattern 允许的来源，host以"*."开头时匹配其任意层级的子域名
+type originPattern struct {
+	scheme string
+	host   string
+	port   string
+	suffix string // 通配子域名时为".example.com"
+}
+
+// NewCORSPolicy 创建跨域来源策略
+// allowedOrigins为精确来源（如"https://video.example.com"）或通配子域名来源（如"https://*.example.com"），不能包含路径
+func NewCORSPolicy(mode string, allowedOrigins []string, allowCredentials bool, maxAge time.Duration) (*CORSPolicy, error) {
+	switch mode {
+	case CORSModeOff, CORSModeLAN, CORSModeStrict:
+	default:
+		return nil, fmt.Errorf("跨域处理方式必须为off、lan或strict: %s", mode)
+	}
+
+	policy := &CORSPolicy{mode: mode, allowCredentials: allowCredentials, maxAge: maxAge}
+	for _, origin := range allowedOrigins {
+		pattern, err := parseOriginPattern(origin)
+		if err != nil {
+			return nil, err
+		}
+		policy.origins = append(policy.origins, pattern)
+	}
+	return policy, nil
+}
+
+// parseOriginPattern 解析允许的来源
+func parseOriginPattern(origin string) (originPattern, error) {
+	u, err := url.Parse(strings.ToLower(strings.TrimSpace(origin)))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return originPattern{}, fmt.Errorf("跨域来源格式无效: %s", origin)
+	}
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return originPattern{}, fmt.Errorf("跨域来源不能包含路径或参数: %s", origin)
+	}
+
+	pattern := originPattern{scheme: u.Scheme, host: u.Hostname(), port: u.Port()}
+	if strings.Contains(pattern.host, "*") {
+		suffix, ok := strings.CutPrefix(pattern.host, "*.")
+		if !ok || suffix == "" || strings.Contains(suffix, "*") || !strings.Contains(suffix, ".") {
+			return originPattern{}, fmt.Errorf("跨域来源只支持\"*.域名\"形式的通配: %s", origin)
+		}
+		pattern.host = ""
+		pattern.suffix = "." + suffix
+	}
+	return pattern, nil
+}
+
+// matches 来源是否匹配，协议和端口必须一致
+func (p originPattern) matches(u *url.URL) bool {
+	if u.Scheme != p.scheme || u.Port() != p.port {
+		return false
+	}
+	if p.suffix != "" {
+		return strings.HasSuffix(u.Hostname(), p.suffix)
+	}
+	return u.Hostname() == p.host
+}
+
+// AllowAnyOriginFor 允许任意来源跨域读取路径前缀下的资源，不允许携带凭据，不受跨域处理方式限制
+// 用于播放令牌签名的视频流等地址中自带授权的资源，投屏接收端（如Chromecast）从自己的来源加载媒体和字幕
+func (p *CORSPolicy) AllowAnyOriginFor(prefixes ...string) {
+	p.publicPrefixes = append(p.publicPrefixes, prefixes...)
+}
+
+// isPublicPath 路径是否允许任意来源读取
+func (p *CORSPolicy) isPublicPath(path string) bool {
+	if p == nil {
+		return false
+	}
+	for _, prefix := range p.publicPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows 是否允许该来源跨域访问
+func (p *CORSPolicy) Allows(origin string) bool {
+	if p == nil || p.mode == CORSModeOff {
+		return false
+	}
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || u.Path != "" {
+		return false
+	}
+
+	for _, pattern := range p.origins {
+		if pattern.matches(u) {
+			return true
+		}
+	}
+	return p.mode == CORSModeLAN && isLANHost(u.Hostname())
+}
+
+// isLANHost 是否为本机或局域网地址：回环、私有和链路本地IP，localhost以及.local/.lan域名
+func isLANHost(host string) bool {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast()
+	}
+	return host == "localhost" || strings.HasSuffix(host, ".localhost") ||
+		strings.HasSuffix(host, ".local") || strings.HasSuffix(host, ".lan")
+}
+
+// CORS 跨域请求中间件，需要通过Use注册为全局中间件，未匹配路由的预检请求也会经过
+// 允许的来源返回Access-Control-Allow-Origin等响应头，预检请求直接返回204；
+// 不允许的来源不返回跨域响应头，由浏览器拦截，预检请求返回403
+func CORS(policy *CORSPolicy) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		origin := string(c.GetHeader("Origin"))
+		if origin == "" {
+			c.Next(ctx)
+			return
+		}
+		preflight := string(c.Method()) == http.MethodOptions && len(c.GetHeader("Access-Control-Request-Method")) > 0
+		if policy.isPublicPath(string(c.Path())) {
+			publicCORS(ctx, c, policy, preflight)
+			return
+		}
+		if policy == nil || policy.mode == CORSModeOff {
+			c.Next(ctx)
+			return
+		}
+
+		c.Response.Header.Add("Vary", "Origin")
+		if !policy.Allows(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next(ctx)
+			return
+		}
+
+		c.Header("Access-Control-Allow-Origin", origin)
+		if policy.allowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+		if preflight {
+			c.Header("Access-Control-Allow-Methods", corsAllowMethods)
+			c.Header("Access-Control-Allow-Headers", corsAllowHeaders)
+			if policy.maxAge > 0 {
+				c.Header("Access-Control-Max-Age", strconv.Itoa(int(policy.maxAge.Seconds())))
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Header("Access-Control-Expose-Headers", corsExposeHeaders)
+		c.Next(ctx)
+	}
+}
+
+// publicCORS 公开路径的跨域处理：任意来源返回"*"，响应不随来源变化，浏览器不会携带凭据
+func publicCORS(ctx context.Context, c *app.RequestContext, policy *CORSPolicy, preflight bool) {
+	c.Header("Access-Control-Allow-Origin", "*")
+	if preflight {
+		c.Header("Access-Control-Allow-Methods", publicAllowMethods)
+		c.Header("Access-Control-Allow-Headers", publicAllowHeaders)
+		if policy.maxAge > 0 {
+			c.Header("Access-Control-Max-Age", strconv.Itoa(int(policy.maxAge.Seconds())))
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+		return
+	}
+	c.Header("Access-Control-Expose-Headers", corsExposeHeaders)
+	c.Next(ctx)
+}
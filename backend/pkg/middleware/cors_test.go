@@ -0,0 +1,207 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupCORSTestServer 创建跨域测试服务器
+func setupCORSTestServer(t *testing.T, mode string, origins []string, allowCredentials bool) *server.Hertz {
+	policy, err := NewCORSPolicy(mode, origins, allowCredentials, 12*time.Hour)
+	require.NoError(t, err)
+
+	h := server.New()
+	h.Use(CORS(policy))
+	h.GET("/videos", func(ctx context.Context, c *app.RequestContext) {
+		c.String(http.StatusOK, "ok")
+	})
+	return h
+}
+
+// TestNewCORSPolicy 测试跨域来源配置的校验
+func TestNewCORSPolicy(t *testing.T) {
+	valid := []string{
+		"https://video.example.com",
+		"https://*.example.com",
+		"http://nas.lan:8080",
+		"https://video.example.com/",
+	}
+	for _, origin := range valid {
+		_, err := NewCORSPolicy(CORSModeStrict, []string{origin}, false, 0)
+		assert.NoError(t, err, origin)
+	}
+
+	invalid := []string{
+		"*",
+		"video.example.com",
+		"ftp://example.com",
+		"https://example.com/app",
+		"https://example.com?a=1",
+		"https://*",
+		"https://*.com",
+		"https://video.*.example.com",
+		"https://*.*.example.com",
+	}
+	for _, origin := range invalid {
+		_, err := NewCORSPolicy(CORSModeStrict, []string{origin}, false, 0)
+		assert.Error(t, err, origin)
+	}
+
+	_, err := NewCORSPolicy("all", nil, false, 0)
+	assert.Error(t, err)
+}
+
+// TestCORSPolicy_Allows 测试来源匹配
+func TestCORSPolicy_Allows(t *testing.T) {
+	strict, err := NewCORSPolicy(CORSModeStrict, []string{"https://video.example.com", "https://*.cdn.example.org", "http://nas.lan:8080"}, false, 0)
+	require.NoError(t, err)
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://video.example.com", true},
+		{"https://VIDEO.example.com", true},
+		{"http://video.example.com", false},
+		{"https://video.example.com:8443", false},
+		{"https://evil-video.example.com", false},
+		{"https://video.example.com.evil.com", false},
+		{"https://a.cdn.example.org", true},
+		{"https://a.b.cdn.example.org", true},
+		{"https://cdn.example.org", false},
+		{"https://evilcdn.example.org", false},
+		{"http://nas.lan:8080", true},
+		{"http://nas.lan", false},
+		{"http://localhost:3000", false},
+		{"null", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, strict.Allows(tt.origin), tt.origin)
+	}
+
+	lan, err := NewCORSPolicy(CORSModeLAN, []string{"https://video.example.com"}, false, 0)
+	require.NoError(t, err)
+	for _, origin := range []string{"http://localhost:3000", "http://127.0.0.1:8080", "http://192.168.1.10", "http://10.0.0.2:5173", "http://[fe80::1]:3000", "http://nas.local", "http://nas.lan:8080", "https://video.example.com"} {
+		assert.True(t, lan.Allows(origin), origin)
+	}
+	for _, origin := range []string{"http://8.8.8.8", "https://example.com", "http://lan.example.com"} {
+		assert.False(t, lan.Allows(origin), origin)
+	}
+
+	off, err := NewCORSPolicy(CORSModeOff, []string{"https://video.example.com"}, false, 0)
+	require.NoError(t, err)
+	assert.False(t, off.Allows("https://video.example.com"))
+}
+
+// TestCORS 测试跨域响应头和预检请求
+func TestCORS(t *testing.T) {
+	h := setupCORSTestServer(t, CORSModeStrict, []string{"https://video.example.com"}, true)
+
+	t.Run("允许的来源原样返回并允许凭据", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/videos", nil, ut.Header{Key: "Origin", Value: "https://video.example.com"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://video.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), HeaderRequestID)
+		assert.Equal(t, "Origin", w.Header().Get("Vary"))
+	})
+
+	t.Run("不允许的来源不返回跨域响应头", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/videos", nil, ut.Header{Key: "Origin", Value: "https://evil.example.com"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "Origin", w.Header().Get("Vary"))
+	})
+
+	t.Run("同源请求不处理", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/videos", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Vary"))
+	})
+
+	t.Run("预检请求", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "OPTIONS", "/videos", nil,
+			ut.Header{Key: "Origin", Value: "https://video.example.com"},
+			ut.Header{Key: "Access-Control-Request-Method", Value: "DELETE"})
+		assert.Equal(t, http.StatusNoContent, w.Code, "未注册OPTIONS路由时预检请求也应该由中间件响应")
+		assert.Equal(t, "https://video.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "DELETE")
+		assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+		assert.Equal(t, "43200", w.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("不允许的来源预检失败", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "OPTIONS", "/videos", nil,
+			ut.Header{Key: "Origin", Value: "https://evil.example.com"},
+			ut.Header{Key: "Access-Control-Request-Method", Value: "DELETE"})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("未配置凭据时不允许携带凭据", func(t *testing.T) {
+		h := setupCORSTestServer(t, CORSModeLAN, nil, false)
+		w := ut.PerformRequest(h.Engine, "GET", "/videos", nil, ut.Header{Key: "Origin", Value: "http://192.168.1.10:3000"})
+		assert.Equal(t, "http://192.168.1.10:3000", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("off模式不处理跨域请求", func(t *testing.T) {
+		h := setupCORSTestServer(t, CORSModeOff, nil, false)
+		w := ut.PerformRequest(h.Engine, "OPTIONS", "/videos", nil,
+			ut.Header{Key: "Origin", Value: "http://localhost:3000"},
+			ut.Header{Key: "Access-Control-Request-Method", Value: "GET"})
+		assert.NotEqual(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
+
+// TestCORS_PublicPaths 测试公开路径允许任意来源读取
+func TestCORS_PublicPaths(t *testing.T) {
+	for _, mode := range []string{CORSModeStrict, CORSModeOff} {
+		policy, err := NewCORSPolicy(mode, []string{"https://video.example.com"}, true, time.Hour)
+		require.NoError(t, err)
+		policy.AllowAnyOriginFor("/stream/")
+
+		h := server.New()
+		h.Use(CORS(policy))
+		h.GET("/stream/:video_id", func(ctx context.Context, c *app.RequestContext) {
+			c.String(http.StatusOK, "video")
+		})
+		h.GET("/videos", func(ctx context.Context, c *app.RequestContext) {
+			c.String(http.StatusOK, "ok")
+		})
+
+		t.Run(mode+"_任意来源读取", func(t *testing.T) {
+			w := ut.PerformRequest(h.Engine, "GET", "/stream/video1?token=abc", nil, ut.Header{Key: "Origin", Value: "https://www.gstatic.com"})
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+			assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"), "公开路径不允许携带凭据")
+			assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "Content-Range")
+		})
+
+		t.Run(mode+"_预检请求", func(t *testing.T) {
+			w := ut.PerformRequest(h.Engine, "OPTIONS", "/stream/video1", nil,
+				ut.Header{Key: "Origin", Value: "https://www.gstatic.com"},
+				ut.Header{Key: "Access-Control-Request-Method", Value: "GET"})
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, publicAllowMethods, w.Header().Get("Access-Control-Allow-Methods"))
+			assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Range")
+		})
+
+		t.Run(mode+"_其他路径不受影响", func(t *testing.T) {
+			w := ut.PerformRequest(h.Engine, "GET", "/videos", nil, ut.Header{Key: "Origin", Value: "https://www.gstatic.com"})
+			assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// RevalidateCacheControl 允许客户端缓存响应，但每次使用前需要携带If-None-Match重新验证
+// 响应可能与登录用户有关，只允许浏览器等私有缓存保存
+const RevalidateCacheControl = "private, no-cache"
+
+// ETag 条件请求中间件
+// GET和HEAD请求的200响应按响应语言和响应体计算强ETag，If-None-Match匹配时返回304且不返回响应体，
+// 多个客户端轮询未变化的列表或详情时只传输响应头；cacheControl不为空时同时设置Cache-Control
+// 响应体仍由处理函数完整生成，节省的是网络传输；流式响应不处理
+func ETag(cacheControl string) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		c.Next(ctx)
+
+		method := string(c.Method())
+		if method != http.MethodGet && method != http.MethodHead {
+			return
+		}
+		if c.Response.StatusCode() != http.StatusOK || c.Response.IsBodyStream() {
+			return
+		}
+
+		// 本地化中间件在之后按语言替换消息，不同语言的响应使用不同的ETag
+		hash := sha256.New()
+		hash.Write([]byte(GetLanguage(c)))
+		hash.Write([]byte{0})
+		hash.Write(c.Response.Body())
+		etag := `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+
+		c.Header("ETag", etag)
+		if cacheControl != "" {
+			c.Header("Cache-Control", cacheControl)
+		}
+		if etagMatches(string(c.GetHeader("If-None-Match")), etag) {
+			c.Response.ResetBody()
+			c.Status(http.StatusNotModified)
+		}
+	}
+}
+
+// etagMatches If-None-Match是否包含etag，按弱比较忽略W/前缀，*匹配任意ETag
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
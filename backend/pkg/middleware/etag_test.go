@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupETagTestServer 创建条件请求测试服务器，title为列表接口返回的标题
+func setupETagTestServer(title *string) *server.Hertz {
+	h := server.New()
+	h.Use(RequestID())
+	h.Use(Localize("zh-CN"))
+
+	h.GET("/videos", ETag(RevalidateCacheControl), func(ctx context.Context, c *app.RequestContext) {
+		c.JSON(http.StatusOK, utils.H{
+			"base":   utils.H{"code": 0, "message": "获取成功"},
+			"videos": []string{*title},
+		})
+	})
+	h.GET("/missing", ETag(RevalidateCacheControl), func(ctx context.Context, c *app.RequestContext) {
+		c.JSON(http.StatusNotFound, utils.H{"base": utils.H{"code": 2002, "message": "视频不存在"}})
+	})
+	h.POST("/videos", ETag(RevalidateCacheControl), func(ctx context.Context, c *app.RequestContext) {
+		c.JSON(http.StatusOK, utils.H{"base": utils.H{"code": 0, "message": "ok"}})
+	})
+	return h
+}
+
+// TestETag 测试ETag生成和If-None-Match条件请求
+func TestETag(t *testing.T) {
+	title := "视频1"
+	h := setupETagTestServer(&title)
+
+	w := ut.PerformRequest(h.Engine, "GET", "/videos", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, RevalidateCacheControl, w.Header().Get("Cache-Control"))
+
+	t.Run("请求ID不影响ETag", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/videos", nil)
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+	})
+
+	t.Run("内容未变化时返回304", func(t *testing.T) {
+		for _, ifNoneMatch := range []string{etag, `"other", W/` + etag, "*"} {
+			w := ut.PerformRequest(h.Engine, "GET", "/videos", nil, ut.Header{Key: "If-None-Match", Value: ifNoneMatch})
+			assert.Equal(t, http.StatusNotModified, w.Code, ifNoneMatch)
+			assert.Empty(t, w.Body.Bytes(), "304响应不应该有响应体")
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("不同语言使用不同的ETag", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/videos", nil,
+			ut.Header{Key: "Accept-Language", Value: "en"},
+			ut.Header{Key: "If-None-Match", Value: etag})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	})
+
+	t.Run("内容变化后返回新内容", func(t *testing.T) {
+		title = "视频2"
+		t.Cleanup(func() { title = "视频1" })
+
+		w := ut.PerformRequest(h.Engine, "GET", "/videos", nil, ut.Header{Key: "If-None-Match", Value: etag})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+		assert.Contains(t, w.Body.String(), "视频2")
+	})
+
+	t.Run("错误响应和非GET请求不设置ETag", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/missing", nil, ut.Header{Key: "If-None-Match", Value: "*"})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, w.Header().Get("ETag"))
+
+		w = ut.PerformRequest(h.Engine, "POST", "/videos", nil, ut.Header{Key: "If-None-Match", Value: "*"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("ETag"))
+	})
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/manteia/zhulong/pkg/i18n"
+)
+
+// ContextKeyLanguage 请求上下文中保存响应语言的键
+const ContextKeyLanguage = "language"
+
+// GetLanguage 获取当前请求协商的响应语言，未经过本地化中间件时返回默认语言
+func GetLanguage(c *app.RequestContext) string {
+	if language := c.GetString(ContextKeyLanguage); language != "" {
+		return language
+	}
+	return i18n.DefaultLanguage
+}
+
+// Localize 响应消息本地化中间件
+// 根据Accept-Language协商响应语言，未指定或不支持时使用defaultLanguage；
+// 响应语言不是中文时，按错误码替换JSON响应中base.message（或顶层message）为对应语言的消息
+func Localize(defaultLanguage string) app.HandlerFunc {
+	if language, ok := i18n.Normalize(defaultLanguage); ok {
+		defaultLanguage = language
+	} else {
+		defaultLanguage = i18n.DefaultLanguage
+	}
+
+	return func(ctx context.Context, c *app.RequestContext) {
+		language := i18n.Negotiate(string(c.GetHeader("Accept-Language")), defaultLanguage)
+		c.Set(ContextKeyLanguage, language)
+		c.Response.Header.Add("Vary", "Accept-Language")
+
+		c.Next(ctx)
+
+		c.Header("Content-Language", language)
+		if language != i18n.LanguageChinese {
+			localizeResponse(c, language)
+		}
+	}
+}
+
+// localizeResponse 替换JSON响应中的消息，流式响应和非JSON响应保持不变
+func localizeResponse(c *app.RequestContext, language string) {
+	body, ok := jsonResponseBody(c)
+	if !ok {
+		return
+	}
+
+	// 大部分响应的错误码位于base中，本地存储文件接口直接返回BaseResponse
+	if base, ok := jsonObjectField(body, "base"); ok {
+		if localizeMessage(base, language) {
+			setJSONObjectField(body, "base", base)
+			setJSONResponseBody(c, body)
+		}
+		return
+	}
+	if localizeMessage(body, language) {
+		setJSONResponseBody(c, body)
+	}
+}
+
+// localizeMessage 按错误码替换message字段，消息有变化时返回true
+func localizeMessage(fields map[string]json.RawMessage, language string) bool {
+	var code int
+	var message string
+	if err := json.Unmarshal(fields["code"], &code); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(fields["message"], &message); err != nil {
+		return false
+	}
+
+	translated := i18n.Message(language, code, message)
+	if translated == message {
+		return false
+	}
+	fields["message"], _ = json.Marshal(translated)
+	return true
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/manteia/zhulong/pkg/i18n"
+)
+
+// setupLocalizeTestServer 创建本地化测试服务器
+func setupLocalizeTestServer(defaultLanguage string) *server.Hertz {
+	h := server.New()
+	h.Use(Localize(defaultLanguage))
+
+	h.GET("/error", func(ctx context.Context, c *app.RequestContext) {
+		c.JSON(http.StatusNotFound, utils.H{
+			"base":  utils.H{"code": 2102, "message": "视频不存在"},
+			"video": nil,
+		})
+	})
+	h.GET("/bare", func(ctx context.Context, c *app.RequestContext) {
+		c.JSON(http.StatusForbidden, utils.H{"code": 9101, "message": "签名已过期"})
+	})
+	h.GET("/unknown", func(ctx context.Context, c *app.RequestContext) {
+		c.JSON(http.StatusOK, utils.H{"base": utils.H{"code": 1234, "message": "未知错误"}})
+	})
+	h.GET("/language", func(ctx context.Context, c *app.RequestContext) {
+		c.String(http.StatusOK, GetLanguage(c))
+	})
+
+	return h
+}
+
+// TestLocalize 测试按Accept-Language替换响应消息
+func TestLocalize(t *testing.T) {
+	h := setupLocalizeTestServer(i18n.LanguageChinese)
+
+	t.Run("默认返回中文消息", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/error", nil)
+		base := decodeBase(t, w.Body.Bytes())
+		assert.Equal(t, "视频不存在", base["message"])
+		assert.Equal(t, i18n.LanguageChinese, w.Header().Get("Content-Language"))
+		assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+	})
+
+	t.Run("请求英文时按错误码替换消息", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/error", nil,
+			ut.Header{Key: "Accept-Language", Value: "en-US,en;q=0.9"})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, i18n.LanguageEnglish, w.Header().Get("Content-Language"))
+
+		var resp map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "null", string(resp["video"]), "其他字段应该保持不变")
+		base := decodeBase(t, w.Body.Bytes())
+		assert.Equal(t, float64(2102), base["code"])
+		assert.Equal(t, "Video not found", base["message"])
+	})
+
+	t.Run("替换顶层错误消息", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/bare", nil,
+			ut.Header{Key: "Accept-Language", Value: "en"})
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "Invalid or expired signature", resp["message"])
+	})
+
+	t.Run("没有译文时保留原始消息", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/unknown", nil,
+			ut.Header{Key: "Accept-Language", Value: "en"})
+		assert.Equal(t, "未知错误", decodeBase(t, w.Body.Bytes())["message"])
+	})
+
+	t.Run("处理器可以读取协商的语言", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/language", nil,
+			ut.Header{Key: "Accept-Language", Value: "fr;q=0.9,en;q=0.5"})
+		assert.Equal(t, i18n.LanguageEnglish, w.Body.String())
+	})
+}
+
+// TestLocalize_DefaultLanguage 测试配置的默认语言
+func TestLocalize_DefaultLanguage(t *testing.T) {
+	t.Run("未指定语言时使用默认语言", func(t *testing.T) {
+		h := setupLocalizeTestServer("en-US")
+		w := ut.PerformRequest(h.Engine, "GET", "/error", nil)
+		assert.Equal(t, "Video not found", decodeBase(t, w.Body.Bytes())["message"])
+
+		w = ut.PerformRequest(h.Engine, "GET", "/error", nil,
+			ut.Header{Key: "Accept-Language", Value: "zh-CN"})
+		assert.Equal(t, "视频不存在", decodeBase(t, w.Body.Bytes())["message"], "请求指定的语言优先于默认语言")
+	})
+
+	t.Run("不支持的默认语言使用中文", func(t *testing.T) {
+		h := setupLocalizeTestServer("fr")
+		w := ut.PerformRequest(h.Engine, "GET", "/error", nil)
+		assert.Equal(t, "视频不存在", decodeBase(t, w.Body.Bytes())["message"])
+	})
+}
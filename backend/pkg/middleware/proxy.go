@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// TrustedProxiesPrivate 信任本机和局域网地址的代理，适用于反向代理与应用运行在同一主机或Docker网络中
+const TrustedProxiesPrivate = "private"
+
+// privateNetworks 本机和局域网地址段：回环、私有和链路本地地址
+var privateNetworks = []string{
+	"127.0.0.0/8", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "169.254.0.0/16",
+	"::1/128", "fc00::/7", "fe80::/10",
+}
+
+// forwardedForHeaders 受信任代理传递客户端IP的请求头，按顺序使用第一个有效的
+var forwardedForHeaders = []string{"X-Forwarded-For", "X-Real-IP"}
+
+// ProxyPolicy 受信任的反向代理
+// 只有直接连接来自受信任代理时才使用X-Forwarded-*请求头，否则客户端可以伪造IP绕过限流或伪造生成的绝对地址
+type ProxyPolicy struct {
+	trusted []*net.IPNet
+}
+
+// NewProxyPolicy 创建受信任的反向代理策略，proxies为CIDR（如"172.18.0.0/16"）、单个IP或"private"
+// 未配置时不信任任何代理，客户端IP为直接连接的地址
+func NewProxyPolicy(proxies []string) (*ProxyPolicy, error) {
+	policy := &ProxyPolicy{}
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if strings.EqualFold(proxy, TrustedProxiesPrivate) {
+			for _, cidr := range privateNetworks {
+				_, network, _ := net.ParseCIDR(cidr)
+				policy.trusted = append(policy.trusted, network)
+			}
+			continue
+		}
+
+		network, err := parseProxyNetwork(proxy)
+		if err != nil {
+			return nil, err
+		}
+		policy.trusted = append(policy.trusted, network)
+	}
+	return policy, nil
+}
+
+// parseProxyNetwork 解析受信任代理的地址段，单个IP视为只包含该地址的地址段
+func parseProxyNetwork(proxy string) (*net.IPNet, error) {
+	if strings.Contains(proxy, "/") {
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("受信任代理地址格式无效: %s", proxy)
+		}
+		return network, nil
+	}
+
+	ip := net.ParseIP(proxy)
+	if ip == nil {
+		return nil, fmt.Errorf("受信任代理地址格式无效: %s", proxy)
+	}
+	bits := 128
+	if ip.To4() != nil {
+		ip, bits = ip.To4(), 32
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// ClientIPFunc 获取客户端IP的函数，通过engine.SetClientIPFunc注册后c.ClientIP()和限流使用
+// 从X-Forwarded-For末尾向前跳过受信任代理，返回第一个不受信任的地址
+func (p *ProxyPolicy) ClientIPFunc() app.ClientIP {
+	return app.ClientIPWithOption(app.ClientIPOptions{
+		RemoteIPHeaders: forwardedForHeaders,
+		TrustedCIDRs:    p.trusted,
+	})
+}
+
+// trustedPeer 直接连接是否来自受信任代理，Unix套接字连接视为本机
+func (p *ProxyPolicy) trustedPeer(c *app.RequestContext) bool {
+	if p == nil || len(p.trusted) == 0 {
+		return false
+	}
+
+	addr := c.RemoteAddr()
+	if addr == nil {
+		return false
+	}
+	var ip net.IP
+	if strings.HasPrefix(addr.Network(), "unix") {
+		ip = net.IPv4(127, 0, 0, 1)
+	} else {
+		host, _, err := net.SplitHostPort(strings.TrimSpace(addr.String()))
+		if err != nil {
+			return false
+		}
+		ip = net.ParseIP(host)
+	}
+	if ip == nil {
+		return false
+	}
+	for _, network := range p.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ForwardedHeaders 请求来自受信任代理时按X-Forwarded-Proto和X-Forwarded-Host还原客户端访问的协议和主机
+// 生成的绝对地址（如oEmbed嵌入地址）使用请求的协议和主机，需要注册在路由之前
+func ForwardedHeaders(policy *ProxyPolicy) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		if policy.trustedPeer(c) {
+			if proto := firstForwardedValue(c.Request.Header.Get("X-Forwarded-Proto")); proto == "http" || proto == "https" {
+				c.Request.URI().SetScheme(proto)
+			}
+			if host := firstForwardedValue(c.Request.Header.Get("X-Forwarded-Host")); host != "" && !strings.ContainsAny(host, "/?#@ ") {
+				c.Request.SetHost(host)
+			}
+		}
+		c.Next(ctx)
+	}
+}
+
+// firstForwardedValue 经过多级代理时请求头为逗号分隔的列表，第一个值为最初的客户端请求
+func firstForwardedValue(value string) string {
+	first, _, _ := strings.Cut(value, ",")
+	return strings.ToLower(strings.TrimSpace(first))
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/test/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// remoteAddrConn 指定对端地址的模拟连接
+type remoteAddrConn struct {
+	*mock.Conn
+	addr net.Addr
+}
+
+func (c *remoteAddrConn) RemoteAddr() net.Addr {
+	return c.addr
+}
+
+// newProxyTestContext 创建来自指定对端地址的请求
+func newProxyTestContext(policy *ProxyPolicy, remoteIP string, headers map[string]string) *app.RequestContext {
+	c := app.NewContext(0)
+	c.SetConn(&remoteAddrConn{Conn: mock.NewConn(""), addr: &net.TCPAddr{IP: net.ParseIP(remoteIP), Port: 40000}})
+	c.SetClientIPFunc(policy.ClientIPFunc())
+	c.Request.SetRequestURI("http://zhulong:8888/embed/video1")
+	for key, value := range headers {
+		c.Request.Header.Set(key, value)
+	}
+	return c
+}
+
+// TestNewProxyPolicy 测试解析受信任代理
+func TestNewProxyPolicy(t *testing.T) {
+	policy, err := NewProxyPolicy([]string{"172.18.0.0/16", " 10.0.0.5 ", "::1"})
+	require.NoError(t, err)
+	require.Len(t, policy.trusted, 3)
+	assert.Equal(t, "10.0.0.5/32", policy.trusted[1].String(), "单个IP只包含该地址")
+	assert.Equal(t, "::1/128", policy.trusted[2].String())
+
+	policy, err = NewProxyPolicy([]string{"Private"})
+	require.NoError(t, err)
+	assert.Len(t, policy.trusted, len(privateNetworks))
+
+	policy, err = NewProxyPolicy(nil)
+	require.NoError(t, err)
+	assert.Empty(t, policy.trusted)
+
+	for _, proxy := range []string{"nginx", "10.0.0.0/33", ""} {
+		_, err := NewProxyPolicy([]string{proxy})
+		assert.Error(t, err, proxy)
+	}
+}
+
+// TestProxyPolicy_ClientIP 测试经过反向代理时获取客户端IP
+func TestProxyPolicy_ClientIP(t *testing.T) {
+	policy, err := NewProxyPolicy([]string{"172.18.0.0/16"})
+	require.NoError(t, err)
+	forwarded := map[string]string{"X-Forwarded-For": "203.0.113.7, 172.18.0.3"}
+
+	c := newProxyTestContext(policy, "172.18.0.2", forwarded)
+	assert.Equal(t, "203.0.113.7", c.ClientIP(), "应该跳过受信任的代理")
+
+	c = newProxyTestContext(policy, "198.51.100.9", forwarded)
+	assert.Equal(t, "198.51.100.9", c.ClientIP(), "不受信任的连接不能伪造客户端IP")
+
+	c = newProxyTestContext(policy, "172.18.0.2", map[string]string{"X-Forwarded-For": "1.1.1.1, 198.51.100.9"})
+	assert.Equal(t, "198.51.100.9", c.ClientIP(), "客户端伪造的X-Forwarded-For前缀应该被忽略")
+
+	c = newProxyTestContext(policy, "172.18.0.2", map[string]string{"X-Real-IP": "203.0.113.7"})
+	assert.Equal(t, "203.0.113.7", c.ClientIP())
+
+	untrusted, err := NewProxyPolicy(nil)
+	require.NoError(t, err)
+	c = newProxyTestContext(untrusted, "127.0.0.1", forwarded)
+	assert.Equal(t, "127.0.0.1", c.ClientIP(), "未配置时不信任任何代理")
+}
+
+// TestForwardedHeaders 测试按X-Forwarded-Proto和X-Forwarded-Host还原请求的协议和主机
+func TestForwardedHeaders(t *testing.T) {
+	policy, err := NewProxyPolicy([]string{"172.18.0.0/16"})
+	require.NoError(t, err)
+	headers := map[string]string{
+		"X-Forwarded-Proto": "https, http",
+		"X-Forwarded-Host":  "Video.Example.com",
+	}
+
+	serve := func(c *app.RequestContext) string {
+		var baseURL string
+		c.SetHandlers(app.HandlersChain{
+			ForwardedHeaders(policy),
+			func(ctx context.Context, c *app.RequestContext) {
+				baseURL = string(c.URI().Scheme()) + "://" + string(c.Host())
+			},
+		})
+		c.Next(context.Background())
+		return baseURL
+	}
+
+	assert.Equal(t, "https://video.example.com", serve(newProxyTestContext(policy, "172.18.0.2", headers)))
+	assert.Equal(t, "http://zhulong:8888", serve(newProxyTestContext(policy, "198.51.100.9", headers)), "不受信任的连接不能改写主机")
+	assert.Equal(t, "http://zhulong:8888", serve(newProxyTestContext(policy, "172.18.0.2", map[string]string{
+		"X-Forwarded-Proto": "javascript",
+		"X-Forwarded-Host":  "evil.com/path",
+	})), "无效的请求头应该被忽略")
+}
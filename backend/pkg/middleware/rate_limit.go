@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// CodeTooManyRequests 请求过于频繁错误码
+const CodeTooManyRequests = 7012
+
+// rateLimitSweepInterval 清理空闲令牌桶的间隔
+const rateLimitSweepInterval = time.Minute
+
+// tokenBucket 单个客户端的令牌桶
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// RateLimiter 令牌桶限流器，按客户端键分别计数
+type RateLimiter struct {
+	rate      float64 // 每秒补充的令牌数
+	burst     float64 // 桶容量
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+	mutex     sync.Mutex
+}
+
+// NewRateLimiter 创建令牌桶限流器
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:    requestsPerSecond,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// SetRate 修改令牌补充速率和桶容量，配置重新加载时使用
+// 已有的令牌桶保留剩余令牌，超过新容量的部分被丢弃
+func (l *RateLimiter) SetRate(requestsPerSecond float64, burst int) {
+	if burst < 1 {
+		burst = 1
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.rate = requestsPerSecond
+	l.burst = float64(burst)
+	for _, bucket := range l.buckets {
+		bucket.tokens = math.Min(l.burst, bucket.tokens)
+	}
+}
+
+// Allow 尝试为客户端消耗一个令牌，被拒绝时返回需要等待的时间
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, exists := l.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: l.burst, lastSeen: now}
+		l.buckets[key] = bucket
+	} else {
+		elapsed := now.Sub(bucket.lastSeen).Seconds()
+		bucket.tokens = math.Min(l.burst, bucket.tokens+elapsed*l.rate)
+		bucket.lastSeen = now
+	}
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	if l.rate <= 0 {
+		return false, rateLimitSweepInterval
+	}
+	wait := time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// sweep 定期清理已补满的空闲令牌桶，避免客户端键无限增长
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.lastSeen).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RateLimitPolicy 限流策略，按路由选择限流器，未单独配置的路由使用默认限流器
+type RateLimitPolicy struct {
+	defaultLimiter *RateLimiter
+	routes         map[string]*RateLimiter
+	disabled       atomic.Bool
+}
+
+// NewRateLimitPolicy 创建限流策略，defaultLimiter为nil时未单独配置的路由不限流
+func NewRateLimitPolicy(defaultLimiter *RateLimiter) *RateLimitPolicy {
+	return &RateLimitPolicy{
+		defaultLimiter: defaultLimiter,
+		routes:         make(map[string]*RateLimiter),
+	}
+}
+
+// SetEnabled 开启或关闭限流，关闭时所有请求直接放行，创建后默认开启
+// 中间件在路由注册时绑定策略，配置重新加载时通过它切换而不需要重新注册路由
+func (p *RateLimitPolicy) SetEnabled(enabled bool) {
+	p.disabled.Store(!enabled)
+}
+
+// Enabled 是否开启限流
+func (p *RateLimitPolicy) Enabled() bool {
+	return p != nil && !p.disabled.Load()
+}
+
+// SetRouteLimiter 为路由设置独立的限流器，fullPath为注册时的路由模式
+// 多个路由可以共用同一个限流器，共享同一份配额
+func (p *RateLimitPolicy) SetRouteLimiter(method, fullPath string, limiter *RateLimiter) {
+	p.routes[method+" "+fullPath] = limiter
+}
+
+// limiterFor 获取请求对应的限流器
+func (p *RateLimitPolicy) limiterFor(c *app.RequestContext) *RateLimiter {
+	if limiter, exists := p.routes[string(c.Method())+" "+c.FullPath()]; exists {
+		return limiter
+	}
+	return p.defaultLimiter
+}
+
+// RateLimit 限流中间件，policy为nil或已关闭时不限流
+// 已登录用户按用户ID计数，未登录时按客户端IP计数，需要放在认证中间件之后
+func RateLimit(policy *RateLimitPolicy) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		if !policy.Enabled() {
+			c.Next(ctx)
+			return
+		}
+
+		limiter := policy.limiterFor(c)
+		if limiter == nil {
+			c.Next(ctx)
+			return
+		}
+
+		allowed, wait := limiter.Allow(rateLimitKey(c))
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			abortWithError(c, consts.StatusTooManyRequests, CodeTooManyRequests, "请求过于频繁，请稍后重试")
+			return
+		}
+
+		c.Next(ctx)
+	}
+}
+
+// rateLimitKey 获取限流计数键
+func rateLimitKey(c *app.RequestContext) string {
+	if claims, ok := GetClaims(c); ok {
+		return "user:" + claims.UserID
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/user"
+)
+
+// newTestRateLimiter 创建使用可控时钟的限流器
+func newTestRateLimiter(requestsPerSecond float64, burst int) (*RateLimiter, *time.Time) {
+	now := time.Unix(1700000000, 0)
+	limiter := NewRateLimiter(requestsPerSecond, burst)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+// TestRateLimiter_Allow 测试令牌桶的消耗和补充
+func TestRateLimiter_Allow(t *testing.T) {
+	limiter, now := newTestRateLimiter(2, 3)
+
+	for i := 0; i < 3; i++ {
+		allowed, _ := limiter.Allow("client")
+		assert.True(t, allowed, "桶容量内的突发请求应该放行")
+	}
+
+	allowed, wait := limiter.Allow("client")
+	assert.False(t, allowed, "超出桶容量应该被拒绝")
+	assert.Equal(t, 500*time.Millisecond, wait, "应该返回补充一个令牌所需的时间")
+
+	allowed, _ = limiter.Allow("other")
+	assert.True(t, allowed, "不同客户端应该分别计数")
+
+	*now = now.Add(500 * time.Millisecond)
+	allowed, _ = limiter.Allow("client")
+	assert.True(t, allowed, "补充令牌后应该放行")
+	allowed, _ = limiter.Allow("client")
+	assert.False(t, allowed)
+
+	*now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		allowed, _ = limiter.Allow("client")
+		assert.True(t, allowed, "令牌补充不应该超过桶容量")
+	}
+	allowed, _ = limiter.Allow("client")
+	assert.False(t, allowed)
+}
+
+// TestRateLimiter_SetRate 测试修改限流规则
+func TestRateLimiter_SetRate(t *testing.T) {
+	limiter, now := newTestRateLimiter(1, 5)
+	limiter.Allow("client")
+
+	limiter.SetRate(10, 2)
+	for i := 0; i < 2; i++ {
+		allowed, _ := limiter.Allow("client")
+		assert.True(t, allowed)
+	}
+	allowed, wait := limiter.Allow("client")
+	assert.False(t, allowed, "剩余令牌不应该超过新的桶容量")
+	assert.Equal(t, 100*time.Millisecond, wait, "应该按新的速率补充令牌")
+
+	*now = now.Add(100 * time.Millisecond)
+	allowed, _ = limiter.Allow("client")
+	assert.True(t, allowed)
+}
+
+// TestRateLimiter_Sweep 测试清理空闲令牌桶
+func TestRateLimiter_Sweep(t *testing.T) {
+	limiter, now := newTestRateLimiter(1, 1)
+
+	limiter.Allow("idle")
+	*now = now.Add(2 * rateLimitSweepInterval)
+	limiter.Allow("active")
+
+	assert.NotContains(t, limiter.buckets, "idle", "已补满的空闲令牌桶应该被清理")
+	assert.Contains(t, limiter.buckets, "active")
+}
+
+// TestRateLimit_Middleware 测试限流中间件
+func TestRateLimit_Middleware(t *testing.T) {
+	manager, err := user.NewTokenManager("test-secret", time.Hour)
+	require.NoError(t, err)
+
+	uploadLimiter := NewRateLimiter(0.001, 1)
+	policy := NewRateLimitPolicy(NewRateLimiter(0.001, 2))
+	policy.SetRouteLimiter("POST", "/upload", uploadLimiter)
+	policy.SetRouteLimiter("POST", "/upload-url", uploadLimiter)
+
+	h := server.New()
+	h.Use(JWTAuth(manager, false), RateLimit(policy))
+	ok := func(ctx context.Context, c *app.RequestContext) {
+		c.String(http.StatusOK, "ok")
+	}
+	h.GET("/list", ok)
+	h.POST("/upload", ok)
+	h.POST("/upload-url", ok)
+
+	t.Run("默认规则", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, ut.PerformRequest(h.Engine, "GET", "/list", nil).Code)
+		assert.Equal(t, http.StatusOK, ut.PerformRequest(h.Engine, "GET", "/list", nil).Code)
+
+		w := ut.PerformRequest(h.Engine, "GET", "/list", nil)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code, "超出限制应该返回429")
+		assert.Contains(t, w.Body.String(), "7012")
+		assert.NotEmpty(t, w.Header().Get("Retry-After"), "应该返回Retry-After")
+	})
+
+	t.Run("路由独立规则共享配额", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, ut.PerformRequest(h.Engine, "POST", "/upload", nil).Code, "上传接口不受默认规则影响")
+		assert.Equal(t, http.StatusTooManyRequests, ut.PerformRequest(h.Engine, "POST", "/upload-url", nil).Code, "同一限流器的路由共享配额")
+	})
+
+	t.Run("登录用户按用户计数", func(t *testing.T) {
+		token := issueToken(t, manager, user.RoleViewer)
+		header := ut.Header{Key: "Authorization", Value: "Bearer " + token}
+		assert.Equal(t, http.StatusOK, ut.PerformRequest(h.Engine, "GET", "/list", nil, header).Code, "登录用户不受同IP匿名请求影响")
+	})
+
+	t.Run("关闭后不限流", func(t *testing.T) {
+		policy.SetEnabled(false)
+		assert.Equal(t, http.StatusOK, ut.PerformRequest(h.Engine, "POST", "/upload-url", nil).Code)
+		policy.SetEnabled(true)
+		assert.Equal(t, http.StatusTooManyRequests, ut.PerformRequest(h.Engine, "POST", "/upload-url", nil).Code)
+	})
+
+	t.Run("未配置策略时不限流", func(t *testing.T) {
+		h := server.New()
+		h.Use(RateLimit(nil))
+		h.GET("/list", ok)
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, http.StatusOK, ut.PerformRequest(h.Engine, "GET", "/list", nil).Code)
+		}
+	})
+}
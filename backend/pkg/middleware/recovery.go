@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"runtime/debug"
+	"sync/atomic"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// panicCount 启动以来捕获的处理函数panic次数
+var panicCount atomic.Int64
+
+// PanicCount 获取启动以来捕获的处理函数panic次数，通过健康检查接口暴露
+func PanicCount() int64 {
+	return panicCount.Load()
+}
+
+// Recovery panic恢复中间件
+// 捕获后续中间件和处理函数的panic，记录请求ID、请求方法、路径和调用栈，累加panic次数，
+// 丢弃已写入的响应体并返回统一的500响应（错误码5000），不中断连接
+// 需要放在请求ID和本地化中间件之后，响应中才会带有trace_id并按语言替换消息
+func Recovery() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		defer func() {
+			if r := recover(); r != nil {
+				panicCount.Add(1)
+				hlog.CtxErrorf(ctx, "panic recovered: request_id=%s method=%s path=%s error=%v\n%s",
+					GetRequestID(c), c.Method(), c.Path(), r, debug.Stack())
+
+				c.Response.ResetBody()
+				abortWithError(c, http.StatusInternalServerError, 5000, "服务器内部错误")
+			}
+		}()
+
+		c.Next(ctx)
+	}
+}
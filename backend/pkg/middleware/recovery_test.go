@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupRecoveryTestServer 创建panic恢复测试服务器
+func setupRecoveryTestServer() *server.Hertz {
+	h := server.New()
+	h.Use(RequestID())
+	h.Use(Localize("zh-CN"))
+	h.Use(Recovery())
+
+	h.GET("/panic", func(ctx context.Context, c *app.RequestContext) {
+		c.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+	h.GET("/ok", func(ctx context.Context, c *app.RequestContext) {
+		c.String(http.StatusOK, "ok")
+	})
+	return h
+}
+
+// TestRecovery 测试panic恢复后返回统一的错误响应
+func TestRecovery(t *testing.T) {
+	h := setupRecoveryTestServer()
+	before := PanicCount()
+
+	w := ut.PerformRequest(h.Engine, "GET", "/panic", nil, ut.Header{Key: HeaderRequestID, Value: "req-panic"})
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	base := decodeBase(t, w.Body.Bytes())
+	require.NotNil(t, base, "已写入的响应体应该被替换为JSON错误响应")
+	assert.Equal(t, float64(5000), base["code"])
+	assert.Equal(t, "服务器内部错误", base["message"])
+	assert.Equal(t, "req-panic", base["trace_id"], "错误响应应该带有请求ID")
+	assert.Equal(t, before+1, PanicCount())
+
+	t.Run("按请求语言返回消息", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/panic", nil, ut.Header{Key: "Accept-Language", Value: "en"})
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "Internal server error", decodeBase(t, w.Body.Bytes())["message"])
+	})
+
+	t.Run("panic后继续处理请求", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/ok", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "ok", w.Body.String())
+	})
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/google/uuid"
+)
+
+// HeaderRequestID 请求ID的HTTP头
+const HeaderRequestID = "X-Request-ID"
+
+// ContextKeyRequestID 请求上下文中保存请求ID的键
+const ContextKeyRequestID = "request_id"
+
+// requestIDPattern 允许透传的客户端请求ID格式，不符合时重新生成
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// requestIDContextKey 上下文中保存请求ID的键
+type requestIDContextKey struct{}
+
+// ContextWithRequestID 将请求ID写入上下文
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext 从上下文读取请求ID，不存在时返回空字符串
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// GetRequestID 获取当前请求的请求ID
+func GetRequestID(c *app.RequestContext) string {
+	return c.GetString(ContextKeyRequestID)
+}
+
+// RequestID 请求ID中间件
+// 透传客户端携带的X-Request-ID，未携带或格式无效时生成新ID；
+// 请求ID写入上下文、响应头和访问日志，并回填到JSON响应的base.trace_id中
+func RequestID() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		requestID := string(c.GetHeader(HeaderRequestID))
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		c.Set(ContextKeyRequestID, requestID)
+		c.Header(HeaderRequestID, requestID)
+
+		start := time.Now()
+		c.Next(ContextWithRequestID(ctx, requestID))
+
+		injectTraceID(c, requestID)
+		hlog.CtxInfof(ctx, "request_id=%s method=%s path=%s status=%d latency=%s",
+			requestID, c.Method(), c.Path(), c.Response.StatusCode(), time.Since(start))
+	}
+}
+
+// injectTraceID 将请求ID写入JSON响应的base.trace_id，流式响应和非JSON响应保持不变
+func injectTraceID(c *app.RequestContext, requestID string) {
+	body, ok := jsonResponseBody(c)
+	if !ok {
+		return
+	}
+	base, ok := jsonObjectField(body, "base")
+	if !ok {
+		return
+	}
+	if traceID, exists := base["trace_id"]; exists && string(traceID) != `""` {
+		return
+	}
+
+	base["trace_id"], _ = json.Marshal(requestID)
+	setJSONObjectField(body, "base", base)
+	setJSONResponseBody(c, body)
+}
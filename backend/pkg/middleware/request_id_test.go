@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/common/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupRequestIDTestServer 创建请求ID测试服务器
+func setupRequestIDTestServer() *server.Hertz {
+	h := server.New()
+	h.Use(RequestID())
+
+	h.GET("/context", func(ctx context.Context, c *app.RequestContext) {
+		c.String(http.StatusOK, RequestIDFromContext(ctx)+"|"+GetRequestID(c))
+	})
+	h.GET("/error", func(ctx context.Context, c *app.RequestContext) {
+		c.JSON(http.StatusBadRequest, utils.H{
+			"base":  utils.H{"code": 1001, "message": "参数错误"},
+			"video": nil,
+		})
+	})
+	h.GET("/traced", func(ctx context.Context, c *app.RequestContext) {
+		c.JSON(http.StatusOK, utils.H{
+			"base": utils.H{"code": 0, "message": "ok", "trace_id": "custom"},
+		})
+	})
+	h.GET("/plain", func(ctx context.Context, c *app.RequestContext) {
+		c.JSON(http.StatusOK, utils.H{"status": "ok"})
+	})
+
+	return h
+}
+
+// decodeBase 解析响应中的base字段
+func decodeBase(t *testing.T, body []byte) map[string]interface{} {
+	var resp map[string]map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &resp), "响应应该是有效的JSON")
+	return resp["base"]
+}
+
+// TestRequestID 测试请求ID的生成和透传
+func TestRequestID(t *testing.T) {
+	h := setupRequestIDTestServer()
+
+	t.Run("未携带请求ID时生成新ID", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/context", nil)
+		requestID := w.Header().Get(HeaderRequestID)
+		_, err := uuid.Parse(requestID)
+		require.NoError(t, err, "生成的请求ID应该是UUID")
+		assert.Equal(t, requestID+"|"+requestID, w.Body.String(), "上下文中应该可以读取请求ID")
+	})
+
+	t.Run("透传客户端请求ID", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/context", nil,
+			ut.Header{Key: HeaderRequestID, Value: "client-req.123"})
+		assert.Equal(t, "client-req.123", w.Header().Get(HeaderRequestID))
+		assert.Equal(t, "client-req.123|client-req.123", w.Body.String())
+	})
+
+	t.Run("无效的客户端请求ID被替换", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/context", nil,
+			ut.Header{Key: HeaderRequestID, Value: "bad id\r\n<script>"})
+		requestID := w.Header().Get(HeaderRequestID)
+		_, err := uuid.Parse(requestID)
+		assert.NoError(t, err, "格式无效时应该重新生成请求ID")
+	})
+}
+
+// TestRequestID_TraceID 测试请求ID回填到响应base.trace_id
+func TestRequestID_TraceID(t *testing.T) {
+	h := setupRequestIDTestServer()
+
+	w := ut.PerformRequest(h.Engine, "GET", "/error", nil,
+		ut.Header{Key: HeaderRequestID, Value: "req-1"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	base := decodeBase(t, w.Body.Bytes())
+	assert.Equal(t, "req-1", base["trace_id"], "错误响应应该包含请求ID")
+	assert.Equal(t, float64(1001), base["code"], "原有字段应该保留")
+	assert.Equal(t, "参数错误", base["message"])
+	assert.Contains(t, w.Body.String(), `"video":null`)
+
+	w = ut.PerformRequest(h.Engine, "GET", "/traced", nil)
+	assert.Equal(t, "custom", decodeBase(t, w.Body.Bytes())["trace_id"], "已设置的trace_id不应该被覆盖")
+
+	w = ut.PerformRequest(h.Engine, "GET", "/plain", nil)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String(), "没有base字段的响应保持不变")
+}
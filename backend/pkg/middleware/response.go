@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// jsonResponseBody 解析JSON响应体的顶层字段，流式响应、非JSON响应和非对象响应返回false
+func jsonResponseBody(c *app.RequestContext) (map[string]json.RawMessage, bool) {
+	if c.Response.IsBodyStream() || !bytes.HasPrefix(c.Response.Header.ContentType(), []byte("application/json")) {
+		return nil, false
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(c.Response.Body(), &body); err != nil || body == nil {
+		return nil, false
+	}
+	return body, true
+}
+
+// jsonObjectField 解析对象类型的字段，字段不存在或不是对象时返回false
+func jsonObjectField(body map[string]json.RawMessage, name string) (map[string]json.RawMessage, bool) {
+	raw, exists := body[name]
+	if !exists {
+		return nil, false
+	}
+
+	var field map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &field); err != nil || field == nil {
+		return nil, false
+	}
+	return field, true
+}
+
+// setJSONObjectField 将对象写回字段
+func setJSONObjectField(body map[string]json.RawMessage, name string, field map[string]json.RawMessage) {
+	if raw, err := json.Marshal(field); err == nil {
+		body[name] = raw
+	}
+}
+
+// setJSONResponseBody 将修改后的字段写回响应体，编码失败时保持原响应
+func setJSONResponseBody(c *app.RequestContext, body map[string]json.RawMessage) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return
+	}
+	c.Response.SetBody(data)
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+const (
+	// DefaultContentSecurityPolicy 接口和存储文件的内容安全策略，响应不应该作为页面加载脚本、样式或被嵌入
+	// 本地存储中用户上传的文件即使被浏览器当作HTML打开也无法执行脚本
+	DefaultContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'; sandbox"
+	// PlayerContentSecurityPolicy 播放页面的内容安全策略，只允许内联样式，视频、海报和字幕可以来自任意存储地址
+	PlayerContentSecurityPolicy = "default-src 'none'; style-src 'unsafe-inline'; media-src * blob: data:; img-src * data:; form-action 'self'; base-uri 'none'"
+)
+
+// SecurityHeaders 安全响应头中间件
+// 设置X-Content-Type-Options、Referrer-Policy和默认的Content-Security-Policy；
+// 返回HTML页面的处理函数可以覆盖Content-Security-Policy
+// 分享链接和预签名URL的令牌位于地址中，Referrer-Policy为no-referrer，避免通过Referer泄露给第三方
+func SecurityHeaders() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Header("Referrer-Policy", "no-referrer")
+		c.Header("Content-Security-Policy", DefaultContentSecurityPolicy)
+		c.Next(ctx)
+	}
+}
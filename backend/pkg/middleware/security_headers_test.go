@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSecurityHeaders 测试安全响应头
+func TestSecurityHeaders(t *testing.T) {
+	h := server.New()
+	h.Use(SecurityHeaders())
+	h.GET("/videos", func(ctx context.Context, c *app.RequestContext) {
+		c.String(http.StatusOK, "ok")
+	})
+	h.GET("/player", func(ctx context.Context, c *app.RequestContext) {
+		c.Header("Content-Security-Policy", PlayerContentSecurityPolicy)
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte("<html></html>"))
+	})
+
+	w := ut.PerformRequest(h.Engine, "GET", "/videos", nil)
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "no-referrer", w.Header().Get("Referrer-Policy"))
+	assert.Equal(t, DefaultContentSecurityPolicy, w.Header().Get("Content-Security-Policy"))
+
+	w = ut.PerformRequest(h.Engine, "GET", "/player", nil)
+	assert.Equal(t, PlayerContentSecurityPolicy, w.Header().Get("Content-Security-Policy"), "处理函数应该可以覆盖内容安全策略")
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+
+	w = ut.PerformRequest(h.Engine, "GET", "/missing", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"), "未匹配路由的响应也应该带有安全响应头")
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// StorageFailure 存储故障识别中间件
+// 记录请求处理过程中的存储操作是否超过配置的超时时间或因熔断被拒绝，处理函数因此返回500内部错误（错误码5000）时，
+// 超时改为504和错误码5040，熔断改为503和错误码5030，客户端可以据此区分存储服务故障和其他服务器错误并稍后重试
+// 需要放在本地化中间件之后，替换后的消息才会按语言返回
+func StorageFailure() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		ctx = storage.WithFailureRecorder(ctx)
+		c.Next(ctx)
+
+		if c.Response.StatusCode() != http.StatusInternalServerError {
+			return
+		}
+		// 熔断前的请求通常已经超时，同时出现时按熔断返回
+		switch {
+		case storage.Unavailable(ctx):
+			replaceInternalError(c, http.StatusServiceUnavailable, 5030, "存储服务暂时不可用，请稍后重试")
+		case storage.TimedOut(ctx):
+			replaceInternalError(c, http.StatusGatewayTimeout, 5040, "存储服务响应超时，请稍后重试")
+		}
+	}
+}
+
+// replaceInternalError 将JSON响应中的内部错误替换为指定的错误，其他错误码和非JSON响应保持不变
+func replaceInternalError(c *app.RequestContext, status, code int, message string) {
+	body, ok := jsonResponseBody(c)
+	if !ok {
+		return
+	}
+
+	// 与本地化中间件相同，错误码位于base中，本地存储文件接口直接返回BaseResponse
+	fields, hasBase := jsonObjectField(body, "base")
+	if !hasBase {
+		fields = body
+	}
+	var current int
+	if err := json.Unmarshal(fields["code"], &current); err != nil || current != 5000 {
+		return
+	}
+
+	fields["code"], _ = json.Marshal(code)
+	fields["message"], _ = json.Marshal(message)
+	if hasBase {
+		setJSONObjectField(body, "base", fields)
+	}
+	setJSONResponseBody(c, body)
+	c.Response.SetStatusCode(status)
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// stalledStorage 获取文件信息时阻塞到上下文取消的存储服务
+type stalledStorage struct {
+	*storage.LocalStorage
+}
+
+func (s *stalledStorage) GetFileInfo(ctx context.Context, bucketName, objectName string) (*storage.FileInfo, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// setupStorageFailureTestServer 创建存储故障测试服务器，/stat超时，/circuit在第一次失败后熔断
+func setupStorageFailureTestServer(t *testing.T) *server.Hertz {
+	local, err := storage.NewLocalStorage(&storage.LocalConfig{RootDir: t.TempDir()})
+	require.NoError(t, err)
+	client := storage.WithTimeouts(&stalledStorage{LocalStorage: local}, storage.Timeouts{Metadata: 10 * time.Millisecond})
+	breaker := storage.WithResilience(client, storage.ResilienceOptions{FailureThreshold: 1, OpenDuration: time.Minute})
+
+	h := server.New()
+	h.Use(Localize("zh-CN"))
+	h.Use(StorageFailure())
+
+	internalError := func(c *app.RequestContext, code int, err error) {
+		c.JSON(http.StatusInternalServerError, map[string]any{
+			"base": map[string]any{"code": code, "message": "服务器内部错误: " + err.Error()},
+		})
+	}
+	h.GET("/stat", func(ctx context.Context, c *app.RequestContext) {
+		if _, err := client.GetFileInfo(ctx, "bucket", "object"); err != nil {
+			internalError(c, 5000, err)
+		}
+	})
+	h.GET("/save", func(ctx context.Context, c *app.RequestContext) {
+		if _, err := client.GetFileInfo(ctx, "bucket", "object"); err != nil {
+			internalError(c, 1013, err)
+		}
+	})
+	h.GET("/circuit", func(ctx context.Context, c *app.RequestContext) {
+		if _, err := breaker.GetFileInfo(ctx, "bucket", "object"); err != nil {
+			internalError(c, 5000, err)
+		}
+	})
+	h.GET("/error", func(ctx context.Context, c *app.RequestContext) {
+		internalError(c, 5000, context.Canceled)
+	})
+	return h
+}
+
+// TestStorageFailure 测试存储操作超时时返回504和错误码5040，熔断时返回503和错误码5030
+func TestStorageTimeout(t *testing.T) {
+	h := setupStorageFailureTestServer(t)
+
+	w := ut.PerformRequest(h.Engine, "GET", "/stat", nil)
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	base := decodeBase(t, w.Body.Bytes())
+	assert.Equal(t, float64(5040), base["code"])
+	assert.Equal(t, "存储服务响应超时，请稍后重试", base["message"])
+
+	t.Run("按请求语言返回消息", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/stat", nil, ut.Header{Key: "Accept-Language", Value: "en"})
+		assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+		assert.Equal(t, "Storage service timed out, please retry later", decodeBase(t, w.Body.Bytes())["message"])
+	})
+
+	t.Run("熔断", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/circuit", nil)
+		assert.Equal(t, http.StatusGatewayTimeout, w.Code, "熔断前的请求超时")
+
+		w = ut.PerformRequest(h.Engine, "GET", "/circuit", nil)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		base := decodeBase(t, w.Body.Bytes())
+		assert.Equal(t, float64(5030), base["code"])
+		assert.Equal(t, "存储服务暂时不可用，请稍后重试", base["message"])
+
+		w = ut.PerformRequest(h.Engine, "GET", "/circuit", nil, ut.Header{Key: "Accept-Language", Value: "en"})
+		assert.Equal(t, "Storage service temporarily unavailable, please retry later", decodeBase(t, w.Body.Bytes())["message"])
+	})
+
+	t.Run("其他错误码保持不变", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/save", nil)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, float64(1013), decodeBase(t, w.Body.Bytes())["code"])
+	})
+
+	t.Run("没有超时的内部错误保持不变", func(t *testing.T) {
+		w := ut.PerformRequest(h.Engine, "GET", "/error", nil)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, float64(5000), decodeBase(t, w.Body.Bytes())["code"])
+	})
+}
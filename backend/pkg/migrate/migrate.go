@@ -0,0 +1,243 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// 迁移状态
+const (
+	StatusPending   = "pending"   // 已创建，尚未开始
+	StatusRunning   = "running"   // 正在复制
+	StatusCompleted = "completed" // 已处理全部文件，Failed大于0时需要重新发起迁移
+	StatusFailed    = "failed"    // 列出源文件、创建目标存储桶或完成回调失败，迁移中止
+)
+
+// Endpoint 迁移的源或目标：存储服务和存储桶
+type Endpoint struct {
+	Storage storage.StorageInterface
+	Bucket  string
+}
+
+// ObjectHook 单个文件复制完成（或因目标已存在而跳过）后调用，用于改写元数据中的存储桶引用
+type ObjectHook func(ctx context.Context, objectName string) error
+
+// CompleteHook 全部文件处理完成后、迁移标记为完成前调用
+type CompleteHook func(ctx context.Context) error
+
+// Progress 迁移进度
+type Progress struct {
+	Status      string    // 迁移状态
+	Total       int       // 源存储桶中的文件数量
+	Copied      int       // 已复制的文件数量
+	Skipped     int       // 目标已存在相同大小的文件而跳过的数量
+	Failed      int       // 复制失败的文件数量
+	BytesCopied int64     // 已复制的字节数
+	Current     string    // 正在复制的文件
+	Error       string    // 最近一次错误
+	StartedAt   time.Time // 开始时间
+	FinishedAt  time.Time // 结束时间，运行中为零值
+}
+
+// Migrator 将源存储桶中的全部文件复制到目标存储桶，对象名保持不变
+// 源和目标使用同一存储服务时在服务端复制，否则流式复制并保留用户元数据和对象标签；
+// 目标已存在相同大小的文件时跳过，中断后重新运行即可从断点继续
+type Migrator struct {
+	source   Endpoint
+	target   Endpoint
+	hook     ObjectHook
+	complete CompleteHook
+	progress Progress
+	mutex    sync.RWMutex
+}
+
+// NewMigrator 创建存储迁移
+func NewMigrator(source, target Endpoint) (*Migrator, error) {
+	if source.Storage == nil || target.Storage == nil {
+		return nil, fmt.Errorf("存储服务不能为空")
+	}
+	if source.Bucket == "" || target.Bucket == "" {
+		return nil, fmt.Errorf("存储桶不能为空")
+	}
+	if source.Storage == target.Storage && source.Bucket == target.Bucket {
+		return nil, fmt.Errorf("源存储桶和目标存储桶不能相同")
+	}
+
+	return &Migrator{
+		source:   source,
+		target:   target,
+		progress: Progress{Status: StatusPending},
+	}, nil
+}
+
+// SetObjectHook 设置文件复制完成后的回调
+func (m *Migrator) SetObjectHook(hook ObjectHook) {
+	m.hook = hook
+}
+
+// SetCompleteHook 设置全部文件处理完成后的回调，回调失败时迁移标记为失败
+func (m *Migrator) SetCompleteHook(hook CompleteHook) {
+	m.complete = hook
+}
+
+// Source 获取迁移源
+func (m *Migrator) Source() Endpoint {
+	return m.source
+}
+
+// Target 获取迁移目标
+func (m *Migrator) Target() Endpoint {
+	return m.target
+}
+
+// Progress 获取迁移进度快照
+func (m *Migrator) Progress() Progress {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.progress
+}
+
+// Running 判断迁移是否尚未结束
+func (m *Migrator) Running() bool {
+	status := m.Progress().Status
+	return status == StatusPending || status == StatusRunning
+}
+
+// Run 执行迁移，单个文件失败时记录错误并继续，返回失败的文件数量
+// 列出源文件、创建目标存储桶或完成回调失败时返回错误
+func (m *Migrator) Run(ctx context.Context) (int, error) {
+	m.update(func(p *Progress) {
+		*p = Progress{Status: StatusRunning, StartedAt: time.Now()}
+	})
+
+	files, err := m.prepare(ctx)
+	if err != nil {
+		m.finish(StatusFailed, err)
+		return 0, err
+	}
+	m.update(func(p *Progress) { p.Total = len(files) })
+
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			m.finish(StatusFailed, err)
+			return m.Progress().Failed, err
+		}
+
+		m.update(func(p *Progress) { p.Current = file.Key })
+		copied, err := m.migrateObject(ctx, file)
+		if err == nil && m.hook != nil {
+			err = m.hook(ctx, file.Key)
+		}
+
+		m.update(func(p *Progress) {
+			switch {
+			case err != nil:
+				p.Failed++
+				p.Error = fmt.Sprintf("%s: %v", file.Key, err)
+			case copied:
+				p.Copied++
+				p.BytesCopied += file.Size
+			default:
+				p.Skipped++
+			}
+		})
+	}
+
+	if m.complete != nil {
+		if err := m.complete(ctx); err != nil {
+			m.finish(StatusFailed, err)
+			return m.Progress().Failed, err
+		}
+	}
+
+	m.finish(StatusCompleted, nil)
+	return m.Progress().Failed, nil
+}
+
+// prepare 确保目标存储桶存在并列出源存储桶中的全部文件
+func (m *Migrator) prepare(ctx context.Context) ([]*storage.FileInfo, error) {
+	exists, err := m.target.Storage.BucketExists(ctx, m.target.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("检查目标存储桶失败: %w", err)
+	}
+	if !exists {
+		if err := m.target.Storage.CreateBucket(ctx, m.target.Bucket); err != nil {
+			return nil, fmt.Errorf("创建目标存储桶失败: %w", err)
+		}
+	}
+
+	files, err := m.source.Storage.ListFiles(ctx, m.source.Bucket, "")
+	if err != nil {
+		return nil, fmt.Errorf("列出源文件失败: %w", err)
+	}
+	return files, nil
+}
+
+// migrateObject 复制单个文件，目标已存在相同大小的文件时跳过并返回false
+func (m *Migrator) migrateObject(ctx context.Context, file *storage.FileInfo) (bool, error) {
+	if info, err := m.target.Storage.GetFileInfo(ctx, m.target.Bucket, file.Key); err == nil && info.Size == file.Size {
+		return false, nil
+	}
+
+	if m.source.Storage == m.target.Storage {
+		if err := m.source.Storage.CopyFile(ctx, m.source.Bucket, file.Key, m.target.Bucket, file.Key); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	if err := m.streamObject(ctx, file.Key); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// streamObject 在不同存储服务之间流式复制文件，保留内容类型、用户元数据和对象标签
+func (m *Migrator) streamObject(ctx context.Context, objectName string) error {
+	info, err := m.source.Storage.GetFileInfo(ctx, m.source.Bucket, objectName)
+	if err != nil {
+		return fmt.Errorf("获取文件信息失败: %w", err)
+	}
+	tags, err := m.source.Storage.GetObjectTags(ctx, m.source.Bucket, objectName)
+	if err != nil {
+		return fmt.Errorf("获取对象标签失败: %w", err)
+	}
+
+	reader, err := m.source.Storage.OpenFile(ctx, m.source.Bucket, objectName)
+	if err != nil {
+		return fmt.Errorf("读取文件失败: %w", err)
+	}
+	defer reader.Close()
+
+	opts := storage.ObjectOptions{Metadata: info.Metadata, Tags: tags}
+	if _, err := m.target.Storage.UploadStream(ctx, m.target.Bucket, objectName, reader, info.Size, info.ContentType, opts); err != nil {
+		return fmt.Errorf("写入目标文件失败: %w", err)
+	}
+	return nil
+}
+
+// finish 结束迁移并记录状态
+func (m *Migrator) finish(status string, err error) {
+	m.update(func(p *Progress) {
+		p.Status = status
+		p.Current = ""
+		p.FinishedAt = time.Now()
+		if err != nil && !errors.Is(err, context.Canceled) {
+			p.Error = err.Error()
+		} else if err != nil {
+			p.Error = "迁移已取消"
+		}
+	})
+}
+
+// update 在锁内更新进度
+func (m *Migrator) update(fn func(p *Progress)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	fn(&m.progress)
+}
@@ -0,0 +1,134 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// newTestStorage 创建使用临时目录的本地存储，并写入测试文件
+func newTestStorage(t *testing.T, bucket string, files map[string]string) *storage.LocalStorage {
+	store, err := storage.NewLocalStorage(&storage.LocalConfig{RootDir: t.TempDir()})
+	require.NoError(t, err)
+	require.NoError(t, store.CreateBucket(context.Background(), bucket))
+	for name, data := range files {
+		_, err := store.UploadFile(context.Background(), bucket, name, []byte(data), "video/mp4")
+		require.NoError(t, err)
+	}
+	return store
+}
+
+// TestMigrator_SameStorage 测试同一存储服务内的存储桶迁移和断点续传
+func TestMigrator_SameStorage(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStorage(t, "old", map[string]string{
+		"videos/a.mp4":     "aaa",
+		"videos/b.mp4":     "bbbb",
+		"thumbnails/a.jpg": "jpg",
+	})
+	_, err := store.UploadFile(ctx, "new", "videos/a.mp4", []byte("aaa"), "video/mp4")
+	require.NoError(t, err)
+
+	migrator, err := NewMigrator(Endpoint{Storage: store, Bucket: "old"}, Endpoint{Storage: store, Bucket: "new"})
+	require.NoError(t, err)
+	assert.True(t, migrator.Running(), "创建后尚未开始的迁移视为未结束")
+
+	var migrated []string
+	migrator.SetObjectHook(func(ctx context.Context, objectName string) error {
+		migrated = append(migrated, objectName)
+		return nil
+	})
+	completed := false
+	migrator.SetCompleteHook(func(ctx context.Context) error {
+		completed = true
+		assert.Equal(t, StatusRunning, migrator.Progress().Status, "完成回调应该在标记完成前调用")
+		return nil
+	})
+
+	failed, err := migrator.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, failed)
+
+	progress := migrator.Progress()
+	assert.Equal(t, StatusCompleted, progress.Status)
+	assert.Equal(t, 3, progress.Total)
+	assert.Equal(t, 2, progress.Copied)
+	assert.Equal(t, 1, progress.Skipped, "目标已存在相同大小的文件应该跳过")
+	assert.Equal(t, int64(7), progress.BytesCopied)
+	assert.False(t, progress.FinishedAt.IsZero())
+	assert.False(t, migrator.Running())
+	assert.ElementsMatch(t, []string{"videos/a.mp4", "videos/b.mp4", "thumbnails/a.jpg"}, migrated, "跳过的文件也应该调用回调")
+	assert.True(t, completed)
+
+	data, err := store.DownloadFile(ctx, "new", "videos/b.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("bbbb"), data)
+	exists, err := store.FileExists(ctx, "old", "videos/b.mp4")
+	require.NoError(t, err)
+	assert.True(t, exists, "迁移后源文件应该保留")
+
+	// 重新运行时全部跳过
+	_, err = migrator.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, migrator.Progress().Skipped)
+	assert.Equal(t, 0, migrator.Progress().Copied)
+}
+
+// TestMigrator_CrossStorage 测试不同存储服务之间的迁移
+func TestMigrator_CrossStorage(t *testing.T) {
+	ctx := context.Background()
+	source := newTestStorage(t, "videos", map[string]string{"videos/a.mp4": "aaa"})
+	target := newTestStorage(t, "other", nil)
+
+	migrator, err := NewMigrator(Endpoint{Storage: source, Bucket: "videos"}, Endpoint{Storage: target, Bucket: "videos"})
+	require.NoError(t, err, "不同存储服务可以使用相同的存储桶名")
+
+	failed, err := migrator.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, failed)
+
+	data, err := target.DownloadFile(ctx, "videos", "videos/a.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("aaa"), data, "目标存储桶不存在时应该自动创建")
+}
+
+// TestMigrator_Failures 测试迁移失败的处理
+func TestMigrator_Failures(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStorage(t, "old", map[string]string{"videos/a.mp4": "aaa", "videos/b.mp4": "bbb"})
+
+	_, err := NewMigrator(Endpoint{Storage: store, Bucket: "old"}, Endpoint{Storage: store, Bucket: "old"})
+	assert.Error(t, err, "源和目标相同时应该返回错误")
+	_, err = NewMigrator(Endpoint{Storage: store}, Endpoint{Storage: store, Bucket: "new"})
+	assert.Error(t, err)
+
+	migrator, err := NewMigrator(Endpoint{Storage: store, Bucket: "old"}, Endpoint{Storage: store, Bucket: "new"})
+	require.NoError(t, err)
+	migrator.SetObjectHook(func(ctx context.Context, objectName string) error {
+		if objectName == "videos/b.mp4" {
+			return errors.New("更新元数据失败")
+		}
+		return nil
+	})
+
+	failed, err := migrator.Run(ctx)
+	require.NoError(t, err, "单个文件失败不应该中止迁移")
+	assert.Equal(t, 1, failed)
+	assert.Contains(t, migrator.Progress().Error, "videos/b.mp4")
+
+	migrator.SetCompleteHook(func(ctx context.Context) error { return errors.New("改写引用失败") })
+	_, err = migrator.Run(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, StatusFailed, migrator.Progress().Status, "完成回调失败时迁移应该标记为失败")
+
+	missing, err := NewMigrator(Endpoint{Storage: store, Bucket: "missing"}, Endpoint{Storage: store, Bucket: "new"})
+	require.NoError(t, err)
+	_, err = missing.Run(ctx)
+	assert.Error(t, err, "源存储桶不存在时应该返回错误")
+	assert.Equal(t, StatusFailed, missing.Progress().Status)
+}
@@ -0,0 +1,37 @@
+package migrate
+
+import (
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// BucketPair 迁移的源存储桶和目标存储桶
+type BucketPair struct {
+	Source string
+	Target string
+}
+
+// PlanBuckets 根据源配置和目标配置生成需要迁移的存储桶
+// 按内容类别对应源和目标存储桶，多个类别共用同一存储桶时只迁移一次；
+// 源配置启用归档时同时迁移归档存储桶
+func PlanBuckets(source, target *config.Config) []BucketPair {
+	sourceBuckets := source.GetBucketResolver()
+	targetBuckets := target.GetBucketResolver()
+
+	seen := make(map[BucketPair]bool)
+	var pairs []BucketPair
+	add := func(pair BucketPair) {
+		if !seen[pair] {
+			seen[pair] = true
+			pairs = append(pairs, pair)
+		}
+	}
+
+	for _, class := range storage.ContentClasses {
+		add(BucketPair{Source: sourceBuckets.Bucket(class), Target: targetBuckets.Bucket(class)})
+	}
+	if source.Archive.Enabled {
+		add(BucketPair{Source: source.Archive.Bucket, Target: target.Archive.Bucket})
+	}
+	return pairs
+}
@@ -0,0 +1,31 @@
+package migrate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/manteia/zhulong/pkg/config"
+)
+
+// TestPlanBuckets 测试按内容类别生成迁移的存储桶
+func TestPlanBuckets(t *testing.T) {
+	source := &config.Config{}
+	source.MinIO.Bucket = "zhulong-videos"
+	source.Archive.Enabled = true
+	source.Archive.Bucket = "zhulong-archive"
+
+	target := &config.Config{}
+	target.MinIO.Bucket = "media"
+	target.Storage.Buckets.Thumbnails = "images"
+	target.Archive.Bucket = "cold"
+
+	assert.Equal(t, []BucketPair{
+		{Source: "zhulong-videos", Target: "media"},
+		{Source: "zhulong-videos", Target: "images"},
+		{Source: "zhulong-archive", Target: "cold"},
+	}, PlanBuckets(source, target), "多个类别对应相同的存储桶时只迁移一次")
+
+	source.Archive.Enabled = false
+	assert.Len(t, PlanBuckets(source, target), 2, "源配置未启用归档时不迁移归档存储桶")
+}
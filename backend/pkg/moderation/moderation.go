@@ -0,0 +1,210 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// 审核结论，按严重程度递增
+const (
+	VerdictApproved = "approved" // 通过，视频正常发布
+	VerdictFlagged  = "flagged"  // 标记，视频进入审核队列，管理员审核通过前只有上传者和管理员可见
+	VerdictBlocked  = "blocked"  // 拦截，视频被拒绝，管理员可以在审核队列中改为通过
+)
+
+// 钩子执行失败时的处理方式
+const (
+	OnErrorFlag    = "flag"    // 标记视频，交给管理员审核
+	OnErrorApprove = "approve" // 忽略失败的钩子
+)
+
+// DefaultHookTimeout 钩子的默认执行超时时间
+const DefaultHookTimeout = 60 * time.Second
+
+// Subject 待审核的视频，以JSON格式传给审核钩子
+type Subject struct {
+	VideoID     string `json:"video_id"`     // 视频ID
+	Title       string `json:"title"`        // 标题
+	Description string `json:"description"`  // 描述
+	FileName    string `json:"file_name"`    // 原始文件名
+	ContentType string `json:"content_type"` // 内容类型
+	Size        int64  `json:"size"`         // 文件大小（字节）
+	Duration    int64  `json:"duration"`     // 时长（秒）
+	CreatedBy   string `json:"created_by"`   // 上传者
+	URL         string `json:"url"`          // 视频文件的临时下载地址，供钩子读取视频内容
+}
+
+// Result 审核结果
+type Result struct {
+	Verdict string `json:"verdict"`        // 审核结论
+	Reason  string `json:"reason"`         // 标记或拦截的原因
+	Hook    string `json:"hook,omitempty"` // 给出结论的钩子名称
+}
+
+// Hook 审核钩子，上传完成后对视频进行检查，如NSFW检测或自定义脚本
+type Hook interface {
+	// Name 钩子名称，用于记录审核原因
+	Name() string
+	// Check 检查视频并返回审核结果
+	Check(ctx context.Context, subject *Subject) (*Result, error)
+}
+
+// ValidateVerdict 验证审核结论，空值按通过处理
+func ValidateVerdict(verdict string) (string, error) {
+	verdict = strings.ToLower(strings.TrimSpace(verdict))
+	switch verdict {
+	case "":
+		return VerdictApproved, nil
+	case VerdictApproved, VerdictFlagged, VerdictBlocked:
+		return verdict, nil
+	}
+	return "", fmt.Errorf("审核结论必须为approved、flagged或blocked: %s", verdict)
+}
+
+// ValidateOnError 验证钩子执行失败时的处理方式，空值按标记处理
+func ValidateOnError(onError string) (string, error) {
+	onError = strings.ToLower(strings.TrimSpace(onError))
+	switch onError {
+	case "":
+		return OnErrorFlag, nil
+	case OnErrorFlag, OnErrorApprove:
+		return onError, nil
+	}
+	return "", fmt.Errorf("审核钩子失败处理方式必须为flag或approve: %s", onError)
+}
+
+// severity 审核结论的严重程度
+func severity(verdict string) int {
+	switch verdict {
+	case VerdictFlagged:
+		return 1
+	case VerdictBlocked:
+		return 2
+	}
+	return 0
+}
+
+// CommandHook 执行外部命令的审核钩子
+// 待审核的视频以JSON写入标准输入，命令在标准输出返回{"verdict": "...", "reason": "..."}，
+// 没有输出时视为通过，退出码非0时视为执行失败
+type CommandHook struct {
+	name    string
+	command string
+	args    []string
+	timeout time.Duration
+}
+
+// NewCommandHook 创建执行外部命令的审核钩子，timeout不大于0时使用默认超时时间
+func NewCommandHook(name, command string, args []string, timeout time.Duration) (*CommandHook, error) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return nil, fmt.Errorf("审核钩子命令不能为空")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = command
+	}
+	if timeout <= 0 {
+		timeout = DefaultHookTimeout
+	}
+	return &CommandHook{name: name, command: command, args: args, timeout: timeout}, nil
+}
+
+// Name 钩子名称
+func (h *CommandHook) Name() string {
+	return h.name
+}
+
+// Check 执行命令检查视频
+func (h *CommandHook) Check(ctx context.Context, subject *Subject) (*Result, error) {
+	input, err := json.Marshal(subject)
+	if err != nil {
+		return nil, fmt.Errorf("序列化待审核视频失败: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, h.command, h.args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("审核钩子执行失败: %w, 输出: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	output := bytes.TrimSpace(stdout.Bytes())
+	if len(output) == 0 {
+		return &Result{Verdict: VerdictApproved}, nil
+	}
+	var result Result
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("解析审核钩子输出失败: %w", err)
+	}
+	if result.Verdict, err = ValidateVerdict(result.Verdict); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Decision 审核流水线的最终结论
+type Decision struct {
+	Verdict string   // 最严重的审核结论
+	Reasons []string // 标记和拦截原因，格式为"钩子名称: 原因"
+}
+
+// Pipeline 审核流水线，按顺序执行审核钩子，取最严重的结论
+type Pipeline struct {
+	hooks   []Hook
+	onError string
+}
+
+// NewPipeline 创建审核流水线，没有钩子时返回nil
+func NewPipeline(hooks []Hook, onError string) (*Pipeline, error) {
+	if len(hooks) == 0 {
+		return nil, nil
+	}
+	onError, err := ValidateOnError(onError)
+	if err != nil {
+		return nil, err
+	}
+	return &Pipeline{hooks: hooks, onError: onError}, nil
+}
+
+// Run 依次执行审核钩子，遇到拦截时不再执行后续钩子
+// 返回最严重的结论，Reasons为所有标记、拦截和执行失败的原因
+func (p *Pipeline) Run(ctx context.Context, subject *Subject) *Decision {
+	decision := &Decision{Verdict: VerdictApproved, Reasons: []string{}}
+	for _, hook := range p.hooks {
+		result, err := hook.Check(ctx, subject)
+		if err != nil {
+			if p.onError == OnErrorApprove {
+				continue
+			}
+			result = &Result{Verdict: VerdictFlagged, Reason: err.Error()}
+		}
+		result.Hook = hook.Name()
+		if result.Verdict == VerdictApproved {
+			continue
+		}
+
+		reason := result.Reason
+		if reason == "" {
+			reason = result.Verdict
+		}
+		decision.Reasons = append(decision.Reasons, fmt.Sprintf("%s: %s", result.Hook, reason))
+		if severity(result.Verdict) > severity(decision.Verdict) {
+			decision.Verdict = result.Verdict
+		}
+		if decision.Verdict == VerdictBlocked {
+			break
+		}
+	}
+	return decision
+}
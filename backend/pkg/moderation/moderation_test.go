@@ -0,0 +1,146 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubHook 返回固定结果的测试钩子
+type stubHook struct {
+	name   string
+	result *Result
+	err    error
+	calls  int
+}
+
+func (h *stubHook) Name() string {
+	return h.name
+}
+
+func (h *stubHook) Check(ctx context.Context, subject *Subject) (*Result, error) {
+	h.calls++
+	if h.err != nil {
+		return nil, h.err
+	}
+	result := *h.result
+	return &result, nil
+}
+
+// shellHook 创建执行shell脚本的审核钩子
+func shellHook(t *testing.T, script string) *CommandHook {
+	hook, err := NewCommandHook("script", "sh", []string{"-c", script}, 5*time.Second)
+	require.NoError(t, err)
+	return hook
+}
+
+func TestCommandHook(t *testing.T) {
+	ctx := context.Background()
+	subject := &Subject{VideoID: "video1", Title: "测试视频"}
+
+	t.Run("读取标准输入并返回结论", func(t *testing.T) {
+		hook := shellHook(t, `grep -q '"video_id":"video1"' && echo '{"verdict": "Flagged", "reason": "疑似不适宜内容"}'`)
+
+		result, err := hook.Check(ctx, subject)
+		require.NoError(t, err)
+		assert.Equal(t, VerdictFlagged, result.Verdict)
+		assert.Equal(t, "疑似不适宜内容", result.Reason)
+	})
+
+	t.Run("没有输出视为通过", func(t *testing.T) {
+		result, err := shellHook(t, "cat > /dev/null").Check(ctx, subject)
+		require.NoError(t, err)
+		assert.Equal(t, VerdictApproved, result.Verdict)
+	})
+
+	t.Run("执行失败", func(t *testing.T) {
+		_, err := shellHook(t, "echo 模型加载失败 >&2; exit 2").Check(ctx, subject)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "模型加载失败")
+
+		_, err = shellHook(t, "echo not-json").Check(ctx, subject)
+		assert.Error(t, err, "无法解析的输出应该视为执行失败")
+
+		_, err = shellHook(t, `echo '{"verdict": "unknown"}'`).Check(ctx, subject)
+		assert.Error(t, err, "未知的结论应该视为执行失败")
+	})
+
+	t.Run("执行超时", func(t *testing.T) {
+		hook, err := NewCommandHook("slow", "sleep", []string{"5"}, 100*time.Millisecond)
+		require.NoError(t, err)
+		_, err = hook.Check(ctx, subject)
+		assert.Error(t, err)
+	})
+
+	t.Run("命令不能为空", func(t *testing.T) {
+		_, err := NewCommandHook("empty", " ", nil, 0)
+		assert.Error(t, err)
+
+		hook, err := NewCommandHook("", "/usr/local/bin/nsfw-check", nil, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "/usr/local/bin/nsfw-check", hook.Name(), "未指定名称时使用命令作为名称")
+	})
+}
+
+func TestPipeline(t *testing.T) {
+	ctx := context.Background()
+	subject := &Subject{VideoID: "video1"}
+
+	t.Run("没有钩子时不创建流水线", func(t *testing.T) {
+		pipeline, err := NewPipeline(nil, "")
+		require.NoError(t, err)
+		assert.Nil(t, pipeline)
+	})
+
+	t.Run("取最严重的结论", func(t *testing.T) {
+		pipeline, err := NewPipeline([]Hook{
+			&stubHook{name: "metadata", result: &Result{Verdict: VerdictApproved}},
+			&stubHook{name: "nsfw", result: &Result{Verdict: VerdictFlagged, Reason: "置信度0.7"}},
+			&stubHook{name: "keywords", result: &Result{Verdict: VerdictFlagged}},
+		}, OnErrorFlag)
+		require.NoError(t, err)
+
+		decision := pipeline.Run(ctx, subject)
+		assert.Equal(t, VerdictFlagged, decision.Verdict)
+		assert.Equal(t, []string{"nsfw: 置信度0.7", "keywords: flagged"}, decision.Reasons)
+	})
+
+	t.Run("拦截后不再执行后续钩子", func(t *testing.T) {
+		after := &stubHook{name: "after", result: &Result{Verdict: VerdictApproved}}
+		pipeline, err := NewPipeline([]Hook{
+			&stubHook{name: "nsfw", result: &Result{Verdict: VerdictBlocked, Reason: "置信度0.99"}},
+			after,
+		}, OnErrorFlag)
+		require.NoError(t, err)
+
+		decision := pipeline.Run(ctx, subject)
+		assert.Equal(t, VerdictBlocked, decision.Verdict)
+		assert.Equal(t, []string{"nsfw: 置信度0.99"}, decision.Reasons)
+		assert.Zero(t, after.calls)
+	})
+
+	t.Run("钩子执行失败", func(t *testing.T) {
+		failing := &stubHook{name: "nsfw", err: fmt.Errorf("服务不可用")}
+
+		pipeline, err := NewPipeline([]Hook{failing}, "")
+		require.NoError(t, err)
+		decision := pipeline.Run(ctx, subject)
+		assert.Equal(t, VerdictFlagged, decision.Verdict, "默认标记视频交给管理员审核")
+		assert.Equal(t, []string{"nsfw: 服务不可用"}, decision.Reasons)
+
+		pipeline, err = NewPipeline([]Hook{failing}, OnErrorApprove)
+		require.NoError(t, err)
+		decision = pipeline.Run(ctx, subject)
+		assert.Equal(t, VerdictApproved, decision.Verdict)
+		assert.Empty(t, decision.Reasons)
+	})
+
+	t.Run("无效的失败处理方式", func(t *testing.T) {
+		_, err := NewPipeline([]Hook{&stubHook{name: "nsfw"}}, "block")
+		assert.Error(t, err)
+	})
+}
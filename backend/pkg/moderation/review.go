@@ -0,0 +1,142 @@
+package moderation
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 审核队列中视频的状态
+const (
+	StatusPending  = "pending"  // 被钩子标记，等待管理员审核
+	StatusRejected = "rejected" // 被钩子拦截或管理员审核拒绝
+	StatusApproved = "approved" // 管理员审核通过
+)
+
+// 管理员审核操作
+const (
+	ActionApprove = "approve" // 审核通过，视频正常发布
+	ActionReject  = "reject"  // 审核拒绝，视频只有上传者和管理员可见
+)
+
+// ReviewItem 审核队列中的视频
+type ReviewItem struct {
+	VideoID    string    // 视频ID
+	Status     string    // 审核状态
+	Verdict    string    // 钩子给出的审核结论
+	Reasons    []string  // 钩子给出的标记和拦截原因
+	CreatedAt  time.Time // 进入审核队列的时间
+	ReviewedBy string    // 审核的管理员，未审核时为空
+	ReviewedAt time.Time // 审核时间，未审核时为零值
+	Note       string    // 管理员的审核备注
+}
+
+// ReviewQueue 内容审核队列，保存被钩子标记或拦截的视频及管理员的审核结果
+type ReviewQueue struct {
+	items map[string]*ReviewItem
+	mutex sync.RWMutex
+}
+
+// NewReviewQueue 创建内容审核队列
+func NewReviewQueue() *ReviewQueue {
+	return &ReviewQueue{items: make(map[string]*ReviewItem)}
+}
+
+// Add 将钩子标记或拦截的视频加入审核队列，返回视频的审核状态
+// 同一视频重复加入时覆盖此前的记录
+func (q *ReviewQueue) Add(videoID string, decision *Decision) (*ReviewItem, error) {
+	status := StatusPending
+	switch decision.Verdict {
+	case VerdictFlagged:
+	case VerdictBlocked:
+		status = StatusRejected
+	default:
+		return nil, fmt.Errorf("审核通过的视频不需要加入审核队列: %s", videoID)
+	}
+
+	item := &ReviewItem{
+		VideoID:   videoID,
+		Status:    status,
+		Verdict:   decision.Verdict,
+		Reasons:   slices.Clone(decision.Reasons),
+		CreatedAt: time.Now(),
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.items[videoID] = item
+	return cloneItem(item), nil
+}
+
+// Get 获取视频的审核记录
+func (q *ReviewQueue) Get(videoID string) (*ReviewItem, bool) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+	item, ok := q.items[videoID]
+	if !ok {
+		return nil, false
+	}
+	return cloneItem(item), true
+}
+
+// List 按状态列出审核记录，status为空时列出全部，按进入队列的时间升序
+func (q *ReviewQueue) List(status string) []*ReviewItem {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	items := make([]*ReviewItem, 0, len(q.items))
+	for _, item := range q.items {
+		if status == "" || item.Status == status {
+			items = append(items, cloneItem(item))
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].CreatedAt.Before(items[j].CreatedAt)
+		}
+		return items[i].VideoID < items[j].VideoID
+	})
+	return items
+}
+
+// Review 管理员审核视频，已审核的视频可以再次审核以更正结果
+func (q *ReviewQueue) Review(videoID, action, reviewer, note string) (*ReviewItem, error) {
+	var status string
+	switch strings.ToLower(strings.TrimSpace(action)) {
+	case ActionApprove:
+		status = StatusApproved
+	case ActionReject:
+		status = StatusRejected
+	default:
+		return nil, fmt.Errorf("审核操作必须为approve或reject: %s", action)
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	item, ok := q.items[videoID]
+	if !ok {
+		return nil, fmt.Errorf("视频不在审核队列中: %s", videoID)
+	}
+	item.Status = status
+	item.ReviewedBy = reviewer
+	item.ReviewedAt = time.Now()
+	item.Note = strings.TrimSpace(note)
+	return cloneItem(item), nil
+}
+
+// Remove 从审核队列中移除视频，视频被删除时调用
+func (q *ReviewQueue) Remove(videoID string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	delete(q.items, videoID)
+}
+
+// cloneItem 复制审核记录，避免调用方修改队列中的数据
+func cloneItem(item *ReviewItem) *ReviewItem {
+	clone := *item
+	clone.Reasons = slices.Clone(item.Reasons)
+	return &clone
+}
@@ -0,0 +1,80 @@
+package moderation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviewQueue(t *testing.T) {
+	t.Run("按结论加入审核队列", func(t *testing.T) {
+		queue := NewReviewQueue()
+
+		item, err := queue.Add("video1", &Decision{Verdict: VerdictFlagged, Reasons: []string{"nsfw: 疑似"}})
+		require.NoError(t, err)
+		assert.Equal(t, StatusPending, item.Status)
+
+		item, err = queue.Add("video2", &Decision{Verdict: VerdictBlocked, Reasons: []string{"nsfw: 确定"}})
+		require.NoError(t, err)
+		assert.Equal(t, StatusRejected, item.Status)
+
+		_, err = queue.Add("video3", &Decision{Verdict: VerdictApproved})
+		assert.Error(t, err, "通过的视频不应该加入审核队列")
+
+		assert.Len(t, queue.List(""), 2)
+		pending := queue.List(StatusPending)
+		require.Len(t, pending, 1)
+		assert.Equal(t, "video1", pending[0].VideoID)
+		assert.Equal(t, []string{"nsfw: 疑似"}, pending[0].Reasons)
+	})
+
+	t.Run("管理员审核", func(t *testing.T) {
+		queue := NewReviewQueue()
+		_, err := queue.Add("video1", &Decision{Verdict: VerdictFlagged})
+		require.NoError(t, err)
+
+		item, err := queue.Review("video1", " Approve ", "admin", " 误报 ")
+		require.NoError(t, err)
+		assert.Equal(t, StatusApproved, item.Status)
+		assert.Equal(t, "admin", item.ReviewedBy)
+		assert.Equal(t, "误报", item.Note)
+		assert.False(t, item.ReviewedAt.IsZero())
+
+		item, err = queue.Review("video1", ActionReject, "admin", "")
+		require.NoError(t, err)
+		assert.Equal(t, StatusRejected, item.Status, "已审核的视频可以再次审核")
+
+		_, err = queue.Review("video1", "delete", "admin", "")
+		assert.Error(t, err)
+		_, err = queue.Review("missing", ActionApprove, "admin", "")
+		assert.Error(t, err)
+	})
+
+	t.Run("返回的记录是副本", func(t *testing.T) {
+		queue := NewReviewQueue()
+		_, err := queue.Add("video1", &Decision{Verdict: VerdictFlagged, Reasons: []string{"nsfw: 疑似"}})
+		require.NoError(t, err)
+
+		item, ok := queue.Get("video1")
+		require.True(t, ok)
+		item.Status = StatusApproved
+		item.Reasons[0] = "修改"
+
+		item, ok = queue.Get("video1")
+		require.True(t, ok)
+		assert.Equal(t, StatusPending, item.Status)
+		assert.Equal(t, []string{"nsfw: 疑似"}, item.Reasons)
+	})
+
+	t.Run("移除视频", func(t *testing.T) {
+		queue := NewReviewQueue()
+		_, err := queue.Add("video1", &Decision{Verdict: VerdictFlagged})
+		require.NoError(t, err)
+
+		queue.Remove("video1")
+		_, ok := queue.Get("video1")
+		assert.False(t, ok)
+		assert.Empty(t, queue.List(""))
+	})
+}
@@ -0,0 +1,252 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// 导出状态
+const (
+	StatusPending   = "pending"   // 已创建，尚未开始
+	StatusRunning   = "running"   // 正在导出
+	StatusCompleted = "completed" // 已处理全部视频，Failed大于0时可以重新导出
+	StatusFailed    = "failed"    // 被取消，导出中止
+)
+
+// Target 导出目标，name为相对目标的路径，使用/分隔，已存在的文件被覆盖
+type Target interface {
+	Write(ctx context.Context, name string, data []byte, contentType string) error
+	String() string
+}
+
+// DirTarget 导出到服务器上的目录
+type DirTarget struct {
+	root string
+}
+
+// NewDirTarget 创建目录导出目标，root必须是绝对路径，不存在时自动创建
+func NewDirTarget(root string) (*DirTarget, error) {
+	if !filepath.IsAbs(root) {
+		return nil, fmt.Errorf("导出目录必须是绝对路径: %s", root)
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("创建导出目录失败: %w", err)
+	}
+	return &DirTarget{root: filepath.Clean(root)}, nil
+}
+
+// Write 先写入临时文件再重命名，媒体中心扫描时不会读到写了一半的文件
+func (t *DirTarget) Write(ctx context.Context, name string, data []byte, contentType string) error {
+	filePath, err := t.filePath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".nfo-export-*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("设置文件权限失败: %w", err)
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
+
+// filePath 获取文件在导出目录中的路径，拒绝目录之外的路径
+func (t *DirTarget) filePath(name string) (string, error) {
+	cleaned := path.Clean("/" + name)
+	if cleaned == "/" || cleaned != "/"+name {
+		return "", fmt.Errorf("导出文件名无效: %s", name)
+	}
+	return filepath.Join(t.root, filepath.FromSlash(cleaned)), nil
+}
+
+// String 导出目标的描述
+func (t *DirTarget) String() string {
+	return t.root
+}
+
+// BucketTarget 导出到存储桶中的前缀下，前缀为空时导出到存储桶根目录
+type BucketTarget struct {
+	Storage storage.StorageInterface
+	Bucket  string
+	Prefix  string
+}
+
+// Write 上传文件到存储桶
+func (t *BucketTarget) Write(ctx context.Context, name string, data []byte, contentType string) error {
+	_, err := t.Storage.UploadFile(ctx, t.Bucket, t.objectName(name), data, contentType)
+	return err
+}
+
+// objectName 文件在存储桶中的对象名
+func (t *BucketTarget) objectName(name string) string {
+	prefix := strings.Trim(t.Prefix, "/")
+	if prefix == "" {
+		return name
+	}
+	return prefix + "/" + name
+}
+
+// String 导出目标的描述，如bucket/prefix
+func (t *BucketTarget) String() string {
+	return strings.TrimSuffix(t.Bucket+"/"+strings.Trim(t.Prefix, "/"), "/")
+}
+
+// Entry 一个视频的导出内容
+type Entry struct {
+	BaseName string // 相对导出目标的路径，不含扩展名，如videos/2025/08/<视频ID>
+	Movie    *Movie
+	Poster   []byte // JPEG海报，为nil时不导出海报
+}
+
+// Source 获取视频的导出内容，返回nil表示跳过该视频
+type Source func(ctx context.Context, videoID string) (*Entry, error)
+
+// Progress 导出进度
+type Progress struct {
+	Status     string    // 导出状态
+	Total      int       // 待导出的视频数量
+	Exported   int       // 已导出的视频数量
+	Skipped    int       // 跳过的视频数量
+	Failed     int       // 导出失败的视频数量
+	Current    string    // 正在导出的视频
+	Error      string    // 最近一次错误
+	StartedAt  time.Time // 开始时间
+	FinishedAt time.Time // 结束时间，运行中为零值
+}
+
+// Exporter 为视频逐个生成NFO文件和海报并写入导出目标
+type Exporter struct {
+	target   Target
+	progress Progress
+	mutex    sync.RWMutex
+}
+
+// NewExporter 创建NFO导出
+func NewExporter(target Target) (*Exporter, error) {
+	if target == nil {
+		return nil, fmt.Errorf("导出目标不能为空")
+	}
+	return &Exporter{
+		target:   target,
+		progress: Progress{Status: StatusPending},
+	}, nil
+}
+
+// Target 获取导出目标
+func (e *Exporter) Target() Target {
+	return e.target
+}
+
+// Progress 获取导出进度快照
+func (e *Exporter) Progress() Progress {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.progress
+}
+
+// Running 判断导出是否尚未结束
+func (e *Exporter) Running() bool {
+	status := e.Progress().Status
+	return status == StatusPending || status == StatusRunning
+}
+
+// Run 逐个导出视频，单个视频失败时记录错误并继续，返回失败的视频数量；ctx被取消时返回错误
+func (e *Exporter) Run(ctx context.Context, videoIDs []string, source Source) (int, error) {
+	e.update(func(p *Progress) {
+		*p = Progress{Status: StatusRunning, Total: len(videoIDs), StartedAt: time.Now()}
+	})
+
+	for _, videoID := range videoIDs {
+		if err := ctx.Err(); err != nil {
+			e.finish(StatusFailed, err)
+			return e.Progress().Failed, err
+		}
+
+		e.update(func(p *Progress) { p.Current = videoID })
+		exported, err := e.export(ctx, videoID, source)
+
+		e.update(func(p *Progress) {
+			switch {
+			case err != nil:
+				p.Failed++
+				p.Error = fmt.Sprintf("%s: %v", videoID, err)
+			case exported:
+				p.Exported++
+			default:
+				p.Skipped++
+			}
+		})
+	}
+
+	e.finish(StatusCompleted, nil)
+	return e.Progress().Failed, nil
+}
+
+// export 导出单个视频，先写海报再写NFO，NFO引用的海报总是已存在
+func (e *Exporter) export(ctx context.Context, videoID string, source Source) (bool, error) {
+	entry, err := source(ctx, videoID)
+	if err != nil || entry == nil {
+		return false, err
+	}
+
+	movie := *entry.Movie
+	movie.Poster = ""
+	if entry.Poster != nil {
+		movie.Poster = path.Base(entry.BaseName) + PosterSuffix
+		if err := e.target.Write(ctx, entry.BaseName+PosterSuffix, entry.Poster, "image/jpeg"); err != nil {
+			return false, fmt.Errorf("写入海报失败: %w", err)
+		}
+	}
+
+	data, err := Render(&movie)
+	if err != nil {
+		return false, err
+	}
+	if err := e.target.Write(ctx, entry.BaseName+NFOExtension, data, "text/xml; charset=utf-8"); err != nil {
+		return false, fmt.Errorf("写入NFO失败: %w", err)
+	}
+	return true, nil
+}
+
+// finish 结束导出并记录状态
+func (e *Exporter) finish(status string, err error) {
+	e.update(func(p *Progress) {
+		p.Status = status
+		p.Current = ""
+		p.FinishedAt = time.Now()
+		if err != nil && !errors.Is(err, context.Canceled) {
+			p.Error = err.Error()
+		} else if err != nil {
+			p.Error = "导出已取消"
+		}
+	})
+}
+
+// update 在锁内更新进度
+func (e *Exporter) update(fn func(p *Progress)) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	fn(&e.progress)
+}
@@ -0,0 +1,115 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+func TestDirTarget(t *testing.T) {
+	ctx := context.Background()
+	root := filepath.Join(t.TempDir(), "library")
+	target, err := NewDirTarget(root)
+	require.NoError(t, err)
+	assert.Equal(t, root, target.String())
+
+	require.NoError(t, target.Write(ctx, "videos/2025/a.nfo", []byte("old"), "text/xml"))
+	require.NoError(t, target.Write(ctx, "videos/2025/a.nfo", []byte("new"), "text/xml"))
+	data, err := os.ReadFile(filepath.Join(root, "videos", "2025", "a.nfo"))
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data), "已存在的文件被覆盖")
+
+	entries, err := os.ReadDir(filepath.Join(root, "videos", "2025"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "不应该留下临时文件")
+
+	for _, name := range []string{"", "../a.nfo", "videos/../../a.nfo", "/a.nfo", "videos//a.nfo"} {
+		assert.Error(t, target.Write(ctx, name, []byte("x"), "text/xml"), name)
+	}
+
+	_, err = NewDirTarget("library")
+	assert.Error(t, err, "导出目录必须是绝对路径")
+}
+
+func TestBucketTarget(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewLocalStorage(&storage.LocalConfig{RootDir: t.TempDir()})
+	require.NoError(t, err)
+	require.NoError(t, store.CreateBucket(ctx, "media"))
+
+	target := &BucketTarget{Storage: store, Bucket: "media", Prefix: "/kodi/"}
+	assert.Equal(t, "media/kodi", target.String())
+	require.NoError(t, target.Write(ctx, "videos/a.nfo", []byte("nfo"), "text/xml"))
+
+	data, err := store.DownloadFile(ctx, "media", "kodi/videos/a.nfo")
+	require.NoError(t, err)
+	assert.Equal(t, "nfo", string(data))
+
+	assert.Equal(t, "media", (&BucketTarget{Bucket: "media"}).String())
+	assert.Equal(t, "videos/a.nfo", (&BucketTarget{Bucket: "media"}).objectName("videos/a.nfo"))
+}
+
+func TestExporter_Run(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	target, err := NewDirTarget(root)
+	require.NoError(t, err)
+	exporter, err := NewExporter(target)
+	require.NoError(t, err)
+	assert.True(t, exporter.Running(), "创建后尚未开始的导出视为未结束")
+
+	failed, err := exporter.Run(ctx, []string{"a", "b", "c"}, func(ctx context.Context, videoID string) (*Entry, error) {
+		switch videoID {
+		case "a":
+			return &Entry{BaseName: "videos/a", Movie: &Movie{ID: "a", Title: "A"}, Poster: []byte("jpg")}, nil
+		case "b":
+			return nil, nil
+		default:
+			return nil, errors.New("读取失败")
+		}
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, failed)
+
+	progress := exporter.Progress()
+	assert.Equal(t, StatusCompleted, progress.Status)
+	assert.Equal(t, 3, progress.Total)
+	assert.Equal(t, 1, progress.Exported)
+	assert.Equal(t, 1, progress.Skipped)
+	assert.Equal(t, 1, progress.Failed)
+	assert.Equal(t, "c: 读取失败", progress.Error)
+	assert.False(t, progress.FinishedAt.IsZero())
+	assert.False(t, exporter.Running())
+
+	poster, err := os.ReadFile(filepath.Join(root, "videos", "a-poster.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "jpg", string(poster))
+	data, err := os.ReadFile(filepath.Join(root, "videos", "a.nfo"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `<thumb aspect="poster">a-poster.jpg</thumb>`, "海报使用相对NFO的文件名")
+}
+
+func TestExporter_Canceled(t *testing.T) {
+	exporter, err := NewExporter(&BucketTarget{Bucket: "media"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = exporter.Run(ctx, []string{"a"}, func(ctx context.Context, videoID string) (*Entry, error) {
+		t.Fatal("取消后不应该继续导出")
+		return nil, nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, StatusFailed, exporter.Progress().Status)
+	assert.Equal(t, "导出已取消", exporter.Progress().Error)
+
+	_, err = NewExporter(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,127 @@
+package nfo
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// UniqueIDType NFO中zhulong视频ID的uniqueid类型，媒体中心据此识别同一视频
+const UniqueIDType = "zhulong"
+
+// 导出文件的命名，与Kodi和Jellyfin按视频文件名查找元数据和封面的规则一致
+const (
+	NFOExtension   = ".nfo"        // 元数据文件为<视频文件名>.nfo
+	PosterSuffix   = "-poster.jpg" // 海报为<视频文件名>-poster.jpg
+	dateTimeFormat = "2006-01-02 15:04:05"
+	dateFormat     = "2006-01-02"
+)
+
+// Movie 视频元数据，对应Kodi和Jellyfin的movie类型NFO
+type Movie struct {
+	ID         string    // zhulong视频ID
+	Title      string    // 标题
+	Plot       string    // 简介
+	Tags       []string  // 标签
+	Duration   int64     // 时长（秒）
+	Width      int       // 显示宽度（像素），未知时为0
+	Height     int       // 显示高度（像素），未知时为0
+	VideoCodec string    // 视频编码
+	AudioCodec string    // 音频编码，没有音频轨道时为空
+	Poster     string    // 海报文件名，相对NFO文件所在目录，没有海报时为空
+	CreatedBy  string    // 上传者，记录为导演以便在媒体中心中按上传者浏览
+	CreatedAt  time.Time // 上传时间，记录为添加日期和首映日期
+}
+
+// movieXML NFO文件的XML结构，空字段不输出
+type movieXML struct {
+	XMLName   xml.Name     `xml:"movie"`
+	Title     string       `xml:"title"`
+	Plot      string       `xml:"plot,omitempty"`
+	Runtime   int64        `xml:"runtime,omitempty"`
+	Tags      []string     `xml:"tag"`
+	Director  string       `xml:"director,omitempty"`
+	Premiered string       `xml:"premiered,omitempty"`
+	Year      int          `xml:"year,omitempty"`
+	DateAdded string       `xml:"dateadded,omitempty"`
+	UniqueID  uniqueIDXML  `xml:"uniqueid"`
+	Thumb     *thumbXML    `xml:"thumb,omitempty"`
+	FileInfo  *fileInfoXML `xml:"fileinfo,omitempty"`
+}
+
+type uniqueIDXML struct {
+	Type    string `xml:"type,attr"`
+	Default bool   `xml:"default,attr"`
+	Value   string `xml:",chardata"`
+}
+
+type thumbXML struct {
+	Aspect string `xml:"aspect,attr"`
+	Value  string `xml:",chardata"`
+}
+
+type fileInfoXML struct {
+	Video *videoStreamXML `xml:"streamdetails>video,omitempty"`
+	Audio *audioStreamXML `xml:"streamdetails>audio,omitempty"`
+}
+
+type videoStreamXML struct {
+	Codec             string `xml:"codec,omitempty"`
+	Aspect            string `xml:"aspect,omitempty"`
+	Width             int    `xml:"width,omitempty"`
+	Height            int    `xml:"height,omitempty"`
+	DurationInSeconds int64  `xml:"durationinseconds,omitempty"`
+}
+
+type audioStreamXML struct {
+	Codec string `xml:"codec"`
+}
+
+// Render 生成movie类型的NFO文件内容，时长按分钟向上取整记录在runtime中
+func Render(movie *Movie) ([]byte, error) {
+	if movie.ID == "" {
+		return nil, fmt.Errorf("视频ID不能为空")
+	}
+
+	doc := movieXML{
+		Title:    movie.Title,
+		Plot:     movie.Plot,
+		Runtime:  (movie.Duration + 59) / 60,
+		Tags:     movie.Tags,
+		Director: movie.CreatedBy,
+		UniqueID: uniqueIDXML{Type: UniqueIDType, Default: true, Value: movie.ID},
+	}
+	if !movie.CreatedAt.IsZero() {
+		doc.Premiered = movie.CreatedAt.Format(dateFormat)
+		doc.Year = movie.CreatedAt.Year()
+		doc.DateAdded = movie.CreatedAt.Format(dateTimeFormat)
+	}
+	if movie.Poster != "" {
+		doc.Thumb = &thumbXML{Aspect: "poster", Value: movie.Poster}
+	}
+
+	video := &videoStreamXML{
+		Codec:             movie.VideoCodec,
+		Width:             movie.Width,
+		Height:            movie.Height,
+		DurationInSeconds: movie.Duration,
+	}
+	if movie.Width > 0 && movie.Height > 0 {
+		video.Aspect = fmt.Sprintf("%.2f", float64(movie.Width)/float64(movie.Height))
+	}
+	if *video != (videoStreamXML{}) {
+		doc.FileInfo = &fileInfoXML{Video: video}
+	}
+	if movie.AudioCodec != "" {
+		if doc.FileInfo == nil {
+			doc.FileInfo = &fileInfoXML{}
+		}
+		doc.FileInfo.Audio = &audioStreamXML{Codec: movie.AudioCodec}
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("生成NFO失败: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
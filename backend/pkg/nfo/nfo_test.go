@@ -0,0 +1,72 @@
+package nfo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	data, err := Render(&Movie{
+		ID:         "video1",
+		Title:      "假期 & 旅行",
+		Plot:       "海边<日落>",
+		Tags:       []string{"旅行", "海边"},
+		Duration:   61,
+		Width:      1920,
+		Height:     1080,
+		VideoCodec: "h264",
+		AudioCodec: "aac",
+		Poster:     "video1-poster.jpg",
+		CreatedBy:  "alice",
+		CreatedAt:  time.Date(2025, 8, 1, 12, 30, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<movie>
+  <title>假期 &amp; 旅行</title>
+  <plot>海边&lt;日落&gt;</plot>
+  <runtime>2</runtime>
+  <tag>旅行</tag>
+  <tag>海边</tag>
+  <director>alice</director>
+  <premiered>2025-08-01</premiered>
+  <year>2025</year>
+  <dateadded>2025-08-01 12:30:00</dateadded>
+  <uniqueid type="zhulong" default="true">video1</uniqueid>
+  <thumb aspect="poster">video1-poster.jpg</thumb>
+  <fileinfo>
+    <streamdetails>
+      <video>
+        <codec>h264</codec>
+        <aspect>1.78</aspect>
+        <width>1920</width>
+        <height>1080</height>
+        <durationinseconds>61</durationinseconds>
+      </video>
+      <audio>
+        <codec>aac</codec>
+      </audio>
+    </streamdetails>
+  </fileinfo>
+</movie>
+`
+	assert.Equal(t, expected, string(data))
+}
+
+func TestRender_MinimalMovie(t *testing.T) {
+	data, err := Render(&Movie{ID: "video1", Title: "clip"})
+	require.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<movie>
+  <title>clip</title>
+  <uniqueid type="zhulong" default="true">video1</uniqueid>
+</movie>
+`, string(data), "未知的信息不输出")
+
+	_, err = Render(&Movie{Title: "clip"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"sync"
+	"time"
+
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+// 通知事件类型
+const (
+	EventUploadCompleted   = "upload.completed"   // 视频上传完成
+	EventThumbnailReady    = "thumbnail.ready"    // 缩略图已生成
+	EventSpriteReady       = "sprite.ready"       // 进度条预览图已生成
+	EventPreviewReady      = "preview.ready"      // 动态预览已生成
+	EventTranscodeFinished = "transcode.finished" // HLS转码打包完成
+	EventVideoDeleted      = "video.deleted"      // 视频已删除
+	EventStatusChanged     = "status.changed"     // 视频处理状态已变化
+)
+
+// clientBufferSize 每个客户端的事件缓冲大小
+const clientBufferSize = 64
+
+// Event 通知事件
+type Event struct {
+	Type      string      `json:"type"`           // 事件类型
+	VideoID   string      `json:"video_id"`       // 视频ID
+	Data      interface{} `json:"data,omitempty"` // 事件数据
+	Timestamp int64       `json:"timestamp"`      // 事件时间戳（毫秒）
+
+	video *metadata.FileMetadata // 事件所属的视频，用于按订阅者的可见性过滤
+}
+
+// NewEvent 创建通知事件
+func NewEvent(eventType, videoID string, data interface{}) *Event {
+	return &Event{
+		Type:      eventType,
+		VideoID:   videoID,
+		Data:      data,
+		Timestamp: time.Now().UnixMilli(),
+	}
+}
+
+// NewVideoEvent 创建视频事件，只推送给可以查看该视频的订阅者
+func NewVideoEvent(eventType string, video *metadata.FileMetadata, data interface{}) *Event {
+	event := NewEvent(eventType, video.FileID, data)
+	event.video = video
+	return event
+}
+
+// Hub 通知中心，将事件广播给可以接收的订阅客户端
+type Hub struct {
+	clients map[chan *Event]metadata.Viewer
+	mutex   sync.Mutex
+}
+
+// Subscription 通知订阅
+type Subscription struct {
+	C   <-chan *Event // 事件通道，订阅被取消或客户端处理过慢时关闭
+	hub *Hub
+	ch  chan *Event
+}
+
+// NewHub 创建通知中心
+func NewHub() *Hub {
+	return &Hub{
+		clients: make(map[chan *Event]metadata.Viewer),
+	}
+}
+
+// Subscribe 以viewer的身份订阅通知，视频事件只在viewer可以查看该视频时推送
+func (h *Hub) Subscribe(viewer metadata.Viewer) *Subscription {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	ch := make(chan *Event, clientBufferSize)
+	h.clients[ch] = viewer
+
+	return &Subscription{
+		C:   ch,
+		hub: h,
+		ch:  ch,
+	}
+}
+
+// Close 取消订阅
+func (s *Subscription) Close() {
+	s.hub.mutex.Lock()
+	defer s.hub.mutex.Unlock()
+
+	s.hub.removeLocked(s.ch)
+}
+
+// Publish 广播事件，视频事件跳过不能查看该视频的订阅者
+// 客户端缓冲已满时断开该客户端，由客户端重连后重新拉取列表，避免阻塞其他客户端
+func (h *Hub) Publish(event *Event) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for ch, viewer := range h.clients {
+		if event.video != nil && !event.video.VisibleTo(viewer) {
+			continue
+		}
+		select {
+		case ch <- event:
+		default:
+			h.removeLocked(ch)
+		}
+	}
+}
+
+// ClientCount 获取当前订阅的客户端数量
+func (h *Hub) ClientCount() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return len(h.clients)
+}
+
+// removeLocked 移除客户端并关闭通道，调用方需持有锁
+func (h *Hub) removeLocked(ch chan *Event) {
+	if _, exists := h.clients[ch]; exists {
+		delete(h.clients, ch)
+		close(ch)
+	}
+}
@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+// receiveEvent 从订阅通道读取一条事件
+func receiveEvent(t *testing.T, sub *Subscription) *Event {
+	select {
+	case event, ok := <-sub.C:
+		require.True(t, ok, "订阅通道不应该已关闭")
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("等待事件超时")
+		return nil
+	}
+}
+
+// TestHub_Publish 测试事件广播
+func TestHub_Publish(t *testing.T) {
+	hub := NewHub()
+	first := hub.Subscribe(metadata.Viewer{})
+	defer first.Close()
+	second := hub.Subscribe(metadata.Viewer{})
+	defer second.Close()
+	assert.Equal(t, 2, hub.ClientCount())
+
+	hub.Publish(NewEvent(EventUploadCompleted, "video-1", map[string]string{"title": "测试视频"}))
+
+	for _, sub := range []*Subscription{first, second} {
+		event := receiveEvent(t, sub)
+		assert.Equal(t, EventUploadCompleted, event.Type, "每个订阅者都应该收到事件")
+		assert.Equal(t, "video-1", event.VideoID)
+		assert.NotZero(t, event.Timestamp, "事件应该带有时间戳")
+	}
+}
+
+// TestHub_VideoVisibility 测试视频事件只推送给可以查看该视频的订阅者
+func TestHub_VideoVisibility(t *testing.T) {
+	hub := NewHub()
+	anonymous := hub.Subscribe(metadata.Viewer{})
+	defer anonymous.Close()
+	owner := hub.Subscribe(metadata.Viewer{UserID: "user-1"})
+	defer owner.Close()
+	admin := hub.Subscribe(metadata.Viewer{UserID: "admin-1", Admin: true})
+	defer admin.Close()
+
+	private := &metadata.FileMetadata{FileID: "video-1", Visibility: metadata.VisibilityPrivate, CreatedBy: "user-1"}
+	hub.Publish(NewVideoEvent(EventUploadCompleted, private, nil))
+
+	for _, sub := range []*Subscription{owner, admin} {
+		event := receiveEvent(t, sub)
+		assert.Equal(t, "video-1", event.VideoID, "上传者和管理员应该收到私有视频的事件")
+	}
+	select {
+	case event := <-anonymous.C:
+		t.Fatalf("其他用户不应该收到私有视频的事件: %s", event.Type)
+	default:
+	}
+
+	public := &metadata.FileMetadata{FileID: "video-2", Visibility: metadata.VisibilityPublic, CreatedBy: "user-1"}
+	hub.Publish(NewVideoEvent(EventUploadCompleted, public, nil))
+	assert.Equal(t, "video-2", receiveEvent(t, anonymous).VideoID, "公开视频的事件推送给所有订阅者")
+}
+
+// TestHub_Close 测试取消订阅
+func TestHub_Close(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe(metadata.Viewer{})
+	sub.Close()
+	sub.Close()
+
+	assert.Equal(t, 0, hub.ClientCount(), "取消订阅后应该移除客户端")
+	_, open := <-sub.C
+	assert.False(t, open, "取消订阅后通道应该关闭")
+
+	// 没有订阅者时广播不应该阻塞
+	hub.Publish(NewEvent(EventVideoDeleted, "video-1", nil))
+}
+
+// TestHub_SlowClient 测试处理过慢的客户端被断开
+func TestHub_SlowClient(t *testing.T) {
+	hub := NewHub()
+	slow := hub.Subscribe(metadata.Viewer{})
+	defer slow.Close()
+
+	for i := 0; i <= clientBufferSize; i++ {
+		hub.Publish(NewEvent(EventThumbnailReady, "video-1", nil))
+	}
+
+	assert.Equal(t, 0, hub.ClientCount(), "缓冲已满的客户端应该被断开")
+
+	received := 0
+	for range slow.C {
+		received++
+	}
+	assert.Equal(t, clientBufferSize, received, "断开前已缓冲的事件仍可读取")
+}
@@ -0,0 +1,288 @@
+package notify
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebSocket协议常量（RFC 6455）
+const (
+	websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+
+	// CloseNormal 正常关闭
+	CloseNormal = 1000
+	// ClosePolicyViolation 客户端处理过慢被断开
+	ClosePolicyViolation = 1008
+	// CloseMessageTooBig 客户端消息过大
+	CloseMessageTooBig = 1009
+)
+
+const (
+	// pingInterval 服务端心跳间隔
+	pingInterval = 30 * time.Second
+	// writeTimeout 单次写入超时时间
+	writeTimeout = 10 * time.Second
+	// maxClientMessageSize 客户端消息最大长度，通知通道不处理客户端数据
+	maxClientMessageSize = 4096
+)
+
+// WebSocket协议错误
+var (
+	ErrProtocol        = errors.New("WebSocket协议错误")
+	ErrMessageTooLarge = errors.New("WebSocket消息过大")
+)
+
+// ValidateHandshake 验证WebSocket握手请求头
+func ValidateHandshake(upgrade, connection, version, key string) error {
+	if !headerContainsToken(upgrade, "websocket") {
+		return fmt.Errorf("缺少Upgrade: websocket请求头")
+	}
+	if !headerContainsToken(connection, "upgrade") {
+		return fmt.Errorf("缺少Connection: Upgrade请求头")
+	}
+	if version != "13" {
+		return fmt.Errorf("不支持的WebSocket版本: %s", version)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(decoded) != 16 {
+		return fmt.Errorf("无效的Sec-WebSocket-Key")
+	}
+	return nil
+}
+
+// AcceptKey 根据客户端Sec-WebSocket-Key计算Sec-WebSocket-Accept
+func AcceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// headerContainsToken 判断逗号分隔的请求头是否包含指定值（忽略大小写）
+func headerContainsToken(header, token string) bool {
+	for _, value := range strings.Split(header, ",") {
+		if strings.EqualFold(strings.TrimSpace(value), token) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeTimeoutSetter 支持设置写超时的连接
+// Hertz基于netpoll的连接不支持SetWriteDeadline，需要通过SetWriteTimeout设置
+type writeTimeoutSetter interface {
+	SetWriteTimeout(t time.Duration) error
+}
+
+// Conn 服务端WebSocket连接，只支持服务端推送文本消息
+type Conn struct {
+	conn         net.Conn
+	reader       *bufio.Reader
+	mutex        sync.Mutex // 保护写入，心跳和推送可能并发写
+	fixedTimeout bool       // 是否已通过SetWriteTimeout设置写超时
+}
+
+// NewConn 包装已完成握手的连接
+func NewConn(conn net.Conn) *Conn {
+	c := &Conn{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+	}
+	if setter, ok := conn.(writeTimeoutSetter); ok {
+		c.fixedTimeout = setter.SetWriteTimeout(writeTimeout) == nil
+	}
+	return c
+}
+
+// WriteJSON 以文本消息发送JSON数据
+func (c *Conn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("序列化消息失败: %w", err)
+	}
+	return c.writeFrame(opText, data)
+}
+
+// WritePing 发送心跳
+func (c *Conn) WritePing() error {
+	return c.writeFrame(opPing, nil)
+}
+
+// WriteClose 发送关闭帧
+func (c *Conn) WriteClose(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	return c.writeFrame(opClose, payload)
+}
+
+// ReadLoop 读取客户端消息直到连接关闭
+// 自动回复ping和关闭帧，客户端发送的数据消息被忽略；客户端正常关闭时返回nil
+func (c *Conn) ReadLoop() error {
+	for {
+		opcode, payload, err := c.readFrame()
+		if err != nil {
+			if errors.Is(err, ErrMessageTooLarge) {
+				c.WriteClose(CloseMessageTooBig, "")
+			}
+			return err
+		}
+
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return err
+			}
+		case opClose:
+			code := CloseNormal
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+			}
+			c.WriteClose(code, "")
+			return nil
+		}
+	}
+}
+
+// Close 关闭底层连接
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// writeFrame 写入单个未分片的服务端帧（服务端帧不加掩码）
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | opcode
+
+	length := len(payload)
+	switch {
+	case length <= 125:
+		header[1] = byte(length)
+	case length <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.fixedTimeout {
+		if err := c.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+			return fmt.Errorf("设置写超时失败: %w", err)
+		}
+	}
+	if _, err := c.conn.Write(append(header, payload...)); err != nil {
+		return fmt.Errorf("写入WebSocket消息失败: %w", err)
+	}
+	return nil
+}
+
+// readFrame 读取单个客户端帧，客户端帧必须加掩码
+func (c *Conn) readFrame() (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	opcode := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	if !masked {
+		return 0, nil, fmt.Errorf("%w: 客户端消息未加掩码", ErrProtocol)
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+
+	isControl := opcode&0x08 != 0
+	if isControl && (length > 125 || header[0]&0x80 == 0) {
+		return 0, nil, fmt.Errorf("%w: 控制帧格式错误", ErrProtocol)
+	}
+	if length > maxClientMessageSize {
+		return 0, nil, ErrMessageTooLarge
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+		return 0, nil, err
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	switch opcode {
+	case opContinuation, opText, opBinary, opClose, opPing, opPong:
+		return opcode, payload, nil
+	default:
+		return 0, nil, fmt.Errorf("%w: 未知的操作码 %d", ErrProtocol, opcode)
+	}
+}
+
+// ServeConn 将订阅的事件推送到WebSocket连接，直到客户端断开或订阅关闭
+func ServeConn(conn net.Conn, sub *Subscription) {
+	ws := NewConn(conn)
+	defer ws.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ws.ReadLoop()
+	}()
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-sub.C:
+			if !ok {
+				ws.WriteClose(ClosePolicyViolation, "client too slow")
+				return
+			}
+			if err := ws.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := ws.WritePing(); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
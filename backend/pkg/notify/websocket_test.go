@@ -0,0 +1,130 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/metadata"
+)
+
+// writeClientFrame 以客户端身份写入加掩码的帧
+func writeClientFrame(t *testing.T, conn net.Conn, opcode byte, payload []byte) {
+	mask := [4]byte{0x12, 0x34, 0x56, 0x78}
+	frame := []byte{0x80 | opcode, 0x80 | byte(len(payload))}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := conn.Write(frame)
+	require.NoError(t, err)
+}
+
+// readServerFrame 以客户端身份读取服务端帧
+func readServerFrame(t *testing.T, reader *bufio.Reader) (byte, []byte) {
+	var header [2]byte
+	_, err := io.ReadFull(reader, header[:])
+	require.NoError(t, err)
+	assert.Equal(t, byte(0), header[1]&0x80, "服务端帧不应该加掩码")
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		_, err = io.ReadFull(reader, extended[:])
+		require.NoError(t, err)
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		_, err = io.ReadFull(reader, extended[:])
+		require.NoError(t, err)
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+
+	payload := make([]byte, length)
+	_, err = io.ReadFull(reader, payload)
+	require.NoError(t, err)
+	return header[0] & 0x0F, payload
+}
+
+// TestValidateHandshake 测试握手请求头验证
+func TestValidateHandshake(t *testing.T) {
+	key := "dGhlIHNhbXBsZSBub25jZQ=="
+	assert.NoError(t, ValidateHandshake("websocket", "keep-alive, Upgrade", "13", key))
+	assert.Error(t, ValidateHandshake("", "Upgrade", "13", key), "缺少Upgrade应该失败")
+	assert.Error(t, ValidateHandshake("websocket", "keep-alive", "13", key), "缺少Connection: Upgrade应该失败")
+	assert.Error(t, ValidateHandshake("websocket", "Upgrade", "8", key), "不支持的版本应该失败")
+	assert.Error(t, ValidateHandshake("websocket", "Upgrade", "13", "invalid"), "无效的Key应该失败")
+}
+
+// TestAcceptKey 测试握手应答计算（RFC 6455 示例）
+func TestAcceptKey(t *testing.T) {
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+// TestServeConn 测试事件推送、心跳应答和关闭
+func TestServeConn(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe(metadata.Viewer{})
+	defer sub.Close()
+
+	server, client := net.Pipe()
+	defer client.Close()
+
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		ServeConn(server, sub)
+	}()
+
+	client.SetDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(client)
+
+	// 推送事件
+	hub.Publish(NewEvent(EventVideoDeleted, "video-1", nil))
+	opcode, payload := readServerFrame(t, reader)
+	assert.Equal(t, byte(opText), opcode, "事件应该以文本消息发送")
+
+	var event Event
+	require.NoError(t, json.Unmarshal(payload, &event))
+	assert.Equal(t, EventVideoDeleted, event.Type)
+	assert.Equal(t, "video-1", event.VideoID)
+
+	// 客户端ping应该收到pong
+	writeClientFrame(t, client, opPing, []byte("hi"))
+	opcode, payload = readServerFrame(t, reader)
+	assert.Equal(t, byte(opPong), opcode)
+	assert.Equal(t, []byte("hi"), payload)
+
+	// 客户端关闭时服务端回复关闭帧并结束
+	closePayload := binary.BigEndian.AppendUint16(nil, CloseNormal)
+	writeClientFrame(t, client, opClose, closePayload)
+	opcode, payload = readServerFrame(t, reader)
+	assert.Equal(t, byte(opClose), opcode)
+	assert.Equal(t, uint16(CloseNormal), binary.BigEndian.Uint16(payload))
+
+	select {
+	case <-served:
+	case <-time.After(time.Second):
+		t.Fatal("客户端关闭后服务端应该结束推送")
+	}
+}
+
+// TestConn_ReadLoopRejectsUnmasked 测试拒绝未加掩码的客户端帧
+func TestConn_ReadLoopRejectsUnmasked(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go client.Write([]byte{0x81, 0x02, 'h', 'i'})
+
+	err := NewConn(server).ReadLoop()
+	assert.ErrorIs(t, err, ErrProtocol, "未加掩码的客户端帧应该返回协议错误")
+}
@@ -0,0 +1,149 @@
+package playback
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidToken 播放令牌无效或与视频不匹配
+	ErrInvalidToken = errors.New("播放令牌无效")
+	// ErrTokenExpired 播放令牌已过期
+	ErrTokenExpired = errors.New("播放令牌已过期")
+	// ErrTokenRevoked 播放令牌已被撤销
+	ErrTokenRevoked = errors.New("播放令牌已被撤销")
+)
+
+// Claims 播放令牌声明，令牌只能用于播放签发时指定的视频
+type Claims struct {
+	UserID    string `json:"sub"` // 签发时的用户ID
+	VideoID   string `json:"vid"` // 视频ID
+	IssuedAt  int64  `json:"iat"` // 签发时间（Unix毫秒），用于判断是否在撤销之前签发
+	ExpiresAt int64  `json:"exp"` // 过期时间（Unix秒）
+}
+
+// Signer 播放令牌签发和校验
+// 令牌由JSON声明和HMAC-SHA256签名组成，按用户或视频撤销时，撤销时间之前签发的令牌全部失效
+type Signer struct {
+	secret []byte
+	// 撤销记录：用户ID或视频ID -> 撤销时间（Unix毫秒）
+	revokedUsers  map[string]int64
+	revokedVideos map[string]int64
+	mutex         sync.RWMutex
+}
+
+// NewSigner 创建播放令牌签发器
+func NewSigner(secret string) (*Signer, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("播放令牌密钥不能为空")
+	}
+
+	return &Signer{
+		secret:        []byte(secret),
+		revokedUsers:  make(map[string]int64),
+		revokedVideos: make(map[string]int64),
+	}, nil
+}
+
+// Sign 为用户签发视频的播放令牌
+func (s *Signer) Sign(userID, videoID string, expiry time.Duration) (string, *Claims, error) {
+	if videoID == "" {
+		return "", nil, fmt.Errorf("视频ID不能为空")
+	}
+	if expiry <= 0 {
+		return "", nil, fmt.Errorf("令牌有效期必须大于0")
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		UserID:    userID,
+		VideoID:   videoID,
+		IssuedAt:  now.UnixMilli(),
+		ExpiresAt: now.Add(expiry).Unix(),
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", nil, fmt.Errorf("序列化令牌声明失败: %w", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + s.sign(encoded), claims, nil
+}
+
+// Verify 校验播放令牌是否可用于播放指定视频
+func (s *Signer) Verify(token, videoID string) (*Claims, error) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found || !hmac.Equal([]byte(s.sign(encoded)), []byte(signature)) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	claims := &Claims{}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.VideoID != videoID {
+		return nil, ErrInvalidToken
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrTokenExpired
+	}
+	if s.isRevoked(claims) {
+		return nil, ErrTokenRevoked
+	}
+
+	return claims, nil
+}
+
+// RevokeUser 撤销此前为用户签发的全部播放令牌，返回撤销时间
+func (s *Signer) RevokeUser(userID string) time.Time {
+	return s.revoke(s.revokedUsers, userID)
+}
+
+// RevokeVideo 撤销此前为视频签发的全部播放令牌，返回撤销时间
+func (s *Signer) RevokeVideo(videoID string) time.Time {
+	return s.revoke(s.revokedVideos, videoID)
+}
+
+// revoke 记录撤销时间
+func (s *Signer) revoke(revoked map[string]int64, key string) time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	revoked[key] = now.UnixMilli()
+	return now
+}
+
+// isRevoked 判断令牌是否在用户或视频的撤销时间之前（含）签发
+func (s *Signer) isRevoked(claims *Claims) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if revokedAt, exists := s.revokedUsers[claims.UserID]; exists && claims.IssuedAt <= revokedAt {
+		return true
+	}
+	if revokedAt, exists := s.revokedVideos[claims.VideoID]; exists && claims.IssuedAt <= revokedAt {
+		return true
+	}
+	return false
+}
+
+// sign 计算HMAC-SHA256签名
+func (s *Signer) sign(encoded string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,110 @@
+package playback
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewSigner 测试播放令牌签发器创建
+func TestNewSigner(t *testing.T) {
+	_, err := NewSigner("")
+	assert.Error(t, err, "空密钥应该返回错误")
+
+	signer, err := NewSigner("secret")
+	require.NoError(t, err)
+	_, _, err = signer.Sign("user-1", "", time.Hour)
+	assert.Error(t, err, "视频ID为空时应该返回错误")
+	_, _, err = signer.Sign("user-1", "video-1", 0)
+	assert.Error(t, err, "无效有效期应该返回错误")
+}
+
+// TestSigner_SignAndVerify 测试播放令牌签发与校验
+func TestSigner_SignAndVerify(t *testing.T) {
+	signer, err := NewSigner("test-secret")
+	require.NoError(t, err)
+
+	token, claims, err := signer.Sign("user-1", "video-1", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID)
+	assert.Equal(t, "video-1", claims.VideoID)
+
+	verified, err := signer.Verify(token, "video-1")
+	require.NoError(t, err, "校验令牌应该成功")
+	assert.Equal(t, *claims, *verified)
+
+	_, err = signer.Verify(token, "video-2")
+	assert.ErrorIs(t, err, ErrInvalidToken, "令牌不能用于其他视频")
+
+	other, err := NewSigner("other-secret")
+	require.NoError(t, err)
+	_, err = other.Verify(token, "video-1")
+	assert.ErrorIs(t, err, ErrInvalidToken, "其他密钥签发的令牌应该无效")
+
+	for _, invalid := range []string{"", "abc", "abc.def", token + "x"} {
+		_, err = signer.Verify(invalid, "video-1")
+		assert.ErrorIs(t, err, ErrInvalidToken, "令牌: %q", invalid)
+	}
+
+	// 篡改声明中的视频ID
+	encoded, signature, _ := strings.Cut(token, ".")
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	var tampered Claims
+	require.NoError(t, json.Unmarshal(payload, &tampered))
+	tampered.VideoID = "video-2"
+	payload, err = json.Marshal(tampered)
+	require.NoError(t, err)
+	_, err = signer.Verify(base64.RawURLEncoding.EncodeToString(payload)+"."+signature, "video-2")
+	assert.ErrorIs(t, err, ErrInvalidToken, "篡改后的令牌应该无效")
+}
+
+// TestSigner_Expired 测试过期的播放令牌
+func TestSigner_Expired(t *testing.T) {
+	signer, err := NewSigner("test-secret")
+	require.NoError(t, err)
+
+	token, _, err := signer.Sign("user-1", "video-1", time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(1100 * time.Millisecond)
+
+	_, err = signer.Verify(token, "video-1")
+	assert.ErrorIs(t, err, ErrTokenExpired)
+}
+
+// TestSigner_Revoke 测试按用户和视频撤销播放令牌
+func TestSigner_Revoke(t *testing.T) {
+	signer, err := NewSigner("test-secret")
+	require.NoError(t, err)
+
+	aliceToken, _, err := signer.Sign("alice", "video-1", time.Hour)
+	require.NoError(t, err)
+	bobToken, _, err := signer.Sign("bob", "video-1", time.Hour)
+	require.NoError(t, err)
+	bobOtherToken, _, err := signer.Sign("bob", "video-2", time.Hour)
+	require.NoError(t, err)
+
+	signer.RevokeUser("alice")
+	_, err = signer.Verify(aliceToken, "video-1")
+	assert.ErrorIs(t, err, ErrTokenRevoked, "撤销用户后此前签发的令牌应该失效")
+	_, err = signer.Verify(bobToken, "video-1")
+	assert.NoError(t, err, "其他用户的令牌不受影响")
+
+	signer.RevokeVideo("video-1")
+	_, err = signer.Verify(bobToken, "video-1")
+	assert.ErrorIs(t, err, ErrTokenRevoked, "撤销视频后此前签发的令牌应该失效")
+	_, err = signer.Verify(bobOtherToken, "video-2")
+	assert.NoError(t, err, "其他视频的令牌不受影响")
+
+	// 撤销之后重新签发的令牌可以使用
+	time.Sleep(2 * time.Millisecond)
+	aliceToken, _, err = signer.Sign("alice", "video-1", time.Hour)
+	require.NoError(t, err)
+	_, err = signer.Verify(aliceToken, "video-1")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,339 @@
+package playlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// 播放列表限制
+const (
+	MaxNameLength        = 100  // 名称最大长度（按字符计）
+	MaxDescriptionLength = 1000 // 描述最大长度（按字符计）
+	MaxItems             = 1000 // 单个播放列表的最大视频数量
+)
+
+var (
+	// ErrPlaylistNotFound 播放列表不存在
+	ErrPlaylistNotFound = errors.New("播放列表不存在")
+	// ErrVideoNotInPlaylist 视频不在播放列表中
+	ErrVideoNotInPlaylist = errors.New("视频不在播放列表中")
+)
+
+// Playlist 播放列表
+type Playlist struct {
+	ID          string    `json:"id"`          // 播放列表ID
+	Name        string    `json:"name"`        // 名称
+	Description string    `json:"description"` // 描述
+	VideoIDs    []string  `json:"video_ids"`   // 按播放顺序排列的视频ID
+	CreatedBy   string    `json:"created_by"`  // 创建者
+	CreatedAt   time.Time `json:"created_at"`  // 创建时间
+	UpdatedAt   time.Time `json:"updated_at"`  // 更新时间
+}
+
+// CreateRequest 创建播放列表请求
+type CreateRequest struct {
+	Name        string `json:"name"`        // 名称
+	Description string `json:"description"` // 描述（可选）
+	CreatedBy   string `json:"created_by"`  // 创建者
+}
+
+// UpdateRequest 更新播放列表请求，只更新非nil字段
+type UpdateRequest struct {
+	Name        *string `json:"name"`        // 名称（可选）
+	Description *string `json:"description"` // 描述（可选）
+}
+
+// Navigation 播放列表中某个视频的位置及前后视频
+type Navigation struct {
+	Position int    `json:"position"` // 当前视频的位置（从0开始）
+	Total    int    `json:"total"`    // 播放列表视频总数
+	Previous string `json:"previous"` // 上一个视频ID，没有时为空
+	Next     string `json:"next"`     // 下一个视频ID，没有时为空
+}
+
+// PlaylistService 播放列表管理服务
+type PlaylistService struct {
+	playlists map[string]*Playlist // 播放列表ID -> 播放列表
+	mutex     sync.RWMutex
+}
+
+// NewPlaylistService 创建播放列表服务
+func NewPlaylistService() *PlaylistService {
+	return &PlaylistService{
+		playlists: make(map[string]*Playlist),
+	}
+}
+
+// Create 创建空的播放列表
+func (s *PlaylistService) Create(ctx context.Context, req *CreateRequest) (*Playlist, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	if err := validateDescription(req.Description); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	playlist := &Playlist{
+		ID:          uuid.New().String(),
+		Name:        name,
+		Description: req.Description,
+		VideoIDs:    []string{},
+		CreatedBy:   req.CreatedBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.playlists[playlist.ID] = playlist
+	return copyPlaylist(playlist), nil
+}
+
+// Get 根据ID获取播放列表
+func (s *PlaylistService) Get(ctx context.Context, playlistID string) (*Playlist, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	playlist, exists := s.playlists[playlistID]
+	if !exists {
+		return nil, ErrPlaylistNotFound
+	}
+
+	return copyPlaylist(playlist), nil
+}
+
+// List 列出所有播放列表，按创建时间升序
+func (s *PlaylistService) List(ctx context.Context) []*Playlist {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	playlists := make([]*Playlist, 0, len(s.playlists))
+	for _, playlist := range s.playlists {
+		playlists = append(playlists, copyPlaylist(playlist))
+	}
+
+	sort.Slice(playlists, func(i, j int) bool {
+		if !playlists[i].CreatedAt.Equal(playlists[j].CreatedAt) {
+			return playlists[i].CreatedAt.Before(playlists[j].CreatedAt)
+		}
+		return playlists[i].ID < playlists[j].ID
+	})
+
+	return playlists
+}
+
+// Update 修改播放列表名称或描述
+func (s *PlaylistService) Update(ctx context.Context, playlistID string, req *UpdateRequest) (*Playlist, error) {
+	var name string
+	if req.Name != nil {
+		name = strings.TrimSpace(*req.Name)
+		if err := validateName(name); err != nil {
+			return nil, err
+		}
+	}
+	if req.Description != nil {
+		if err := validateDescription(*req.Description); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	playlist, exists := s.playlists[playlistID]
+	if !exists {
+		return nil, ErrPlaylistNotFound
+	}
+
+	if req.Name != nil {
+		playlist.Name = name
+	}
+	if req.Description != nil {
+		playlist.Description = *req.Description
+	}
+	playlist.UpdatedAt = time.Now()
+
+	return copyPlaylist(playlist), nil
+}
+
+// Delete 删除播放列表，列表中的视频不受影响
+func (s *PlaylistService) Delete(ctx context.Context, playlistID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.playlists[playlistID]; !exists {
+		return ErrPlaylistNotFound
+	}
+
+	delete(s.playlists, playlistID)
+	return nil
+}
+
+// AddVideos 在指定位置插入视频，position小于0或超出列表长度时追加到末尾
+// 已在播放列表中的视频会被忽略，不改变其原有位置
+func (s *PlaylistService) AddVideos(ctx context.Context, playlistID string, videoIDs []string, position int) (*Playlist, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	playlist, exists := s.playlists[playlistID]
+	if !exists {
+		return nil, ErrPlaylistNotFound
+	}
+
+	var added []string
+	for _, videoID := range videoIDs {
+		if !slices.Contains(playlist.VideoIDs, videoID) && !slices.Contains(added, videoID) {
+			added = append(added, videoID)
+		}
+	}
+	if len(playlist.VideoIDs)+len(added) > MaxItems {
+		return nil, fmt.Errorf("播放列表最多包含%d个视频", MaxItems)
+	}
+
+	if position < 0 || position > len(playlist.VideoIDs) {
+		position = len(playlist.VideoIDs)
+	}
+	playlist.VideoIDs = slices.Insert(playlist.VideoIDs, position, added...)
+	playlist.UpdatedAt = time.Now()
+
+	return copyPlaylist(playlist), nil
+}
+
+// RemoveVideos 从播放列表中移除视频，不在列表中的视频会被忽略
+func (s *PlaylistService) RemoveVideos(ctx context.Context, playlistID string, videoIDs []string) (*Playlist, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	playlist, exists := s.playlists[playlistID]
+	if !exists {
+		return nil, ErrPlaylistNotFound
+	}
+
+	playlist.VideoIDs = slices.DeleteFunc(playlist.VideoIDs, func(videoID string) bool {
+		return slices.Contains(videoIDs, videoID)
+	})
+	playlist.UpdatedAt = time.Now()
+
+	return copyPlaylist(playlist), nil
+}
+
+// Reorder 按给定顺序重新排列播放列表，videoIDs必须恰好包含列表中的全部视频
+func (s *PlaylistService) Reorder(ctx context.Context, playlistID string, videoIDs []string) (*Playlist, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	playlist, exists := s.playlists[playlistID]
+	if !exists {
+		return nil, ErrPlaylistNotFound
+	}
+
+	if len(videoIDs) != len(playlist.VideoIDs) {
+		return nil, fmt.Errorf("排序必须包含播放列表中的全部%d个视频", len(playlist.VideoIDs))
+	}
+	seen := make(map[string]bool, len(videoIDs))
+	for _, videoID := range videoIDs {
+		if seen[videoID] {
+			return nil, fmt.Errorf("排序中的视频重复: %s", videoID)
+		}
+		if !slices.Contains(playlist.VideoIDs, videoID) {
+			return nil, fmt.Errorf("%w: %s", ErrVideoNotInPlaylist, videoID)
+		}
+		seen[videoID] = true
+	}
+
+	playlist.VideoIDs = slices.Clone(videoIDs)
+	playlist.UpdatedAt = time.Now()
+
+	return copyPlaylist(playlist), nil
+}
+
+// Navigate 获取视频在播放列表中的位置及上一个、下一个视频
+// loop为true时首尾相连，用于循环播放
+func (s *PlaylistService) Navigate(ctx context.Context, playlistID, videoID string, loop bool) (*Navigation, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	playlist, exists := s.playlists[playlistID]
+	if !exists {
+		return nil, ErrPlaylistNotFound
+	}
+
+	position := slices.Index(playlist.VideoIDs, videoID)
+	if position < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrVideoNotInPlaylist, videoID)
+	}
+
+	total := len(playlist.VideoIDs)
+	navigation := &Navigation{Position: position, Total: total}
+	switch {
+	case position > 0:
+		navigation.Previous = playlist.VideoIDs[position-1]
+	case loop && total > 1:
+		navigation.Previous = playlist.VideoIDs[total-1]
+	}
+	switch {
+	case position < total-1:
+		navigation.Next = playlist.VideoIDs[position+1]
+	case loop && total > 1:
+		navigation.Next = playlist.VideoIDs[0]
+	}
+
+	return navigation, nil
+}
+
+// RemoveVideoFromAll 将视频从所有播放列表中移除，视频删除时调用
+func (s *PlaylistService) RemoveVideoFromAll(ctx context.Context, videoID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, playlist := range s.playlists {
+		if index := slices.Index(playlist.VideoIDs, videoID); index >= 0 {
+			playlist.VideoIDs = slices.Delete(playlist.VideoIDs, index, index+1)
+		}
+	}
+}
+
+// copyPlaylist 复制播放列表以避免并发修改
+func copyPlaylist(playlist *Playlist) *Playlist {
+	copied := *playlist
+	copied.VideoIDs = slices.Clone(playlist.VideoIDs)
+	return &copied
+}
+
+// validateName 验证播放列表名称
+func validateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("播放列表名称不能为空")
+	}
+	if utf8.RuneCountInString(name) > MaxNameLength {
+		return fmt.Errorf("播放列表名称长度不能超过%d个字符", MaxNameLength)
+	}
+	return nil
+}
+
+// validateDescription 验证播放列表描述
+func validateDescription(description string) error {
+	if utf8.RuneCountInString(description) > MaxDescriptionLength {
+		return fmt.Errorf("播放列表描述长度不能超过%d个字符", MaxDescriptionLength)
+	}
+	return nil
+}
+
+// Count 统计播放列表数量
+func (s *PlaylistService) Count(ctx context.Context) int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.playlists)
+}
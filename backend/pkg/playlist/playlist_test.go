@@ -0,0 +1,141 @@
+package playlist
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTestPlaylist 创建包含指定视频的播放列表
+func createTestPlaylist(t *testing.T, service *PlaylistService, videoIDs ...string) *Playlist {
+	created, err := service.Create(context.Background(), &CreateRequest{Name: "测试列表", CreatedBy: "alice"})
+	require.NoError(t, err)
+	if len(videoIDs) == 0 {
+		return created
+	}
+
+	updated, err := service.AddVideos(context.Background(), created.ID, videoIDs, -1)
+	require.NoError(t, err)
+	return updated
+}
+
+// TestPlaylistService_Create 测试创建和修改播放列表
+func TestPlaylistService_Create(t *testing.T) {
+	service := NewPlaylistService()
+	ctx := context.Background()
+
+	created, err := service.Create(ctx, &CreateRequest{Name: "  周末连播  ", CreatedBy: "alice"})
+	require.NoError(t, err, "创建播放列表应该成功")
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "周末连播", created.Name, "名称应该去除首尾空白")
+	assert.NotNil(t, created.VideoIDs, "新建的播放列表应该为空列表")
+	assert.Equal(t, 1, service.Count(ctx))
+
+	_, err = service.Create(ctx, &CreateRequest{Name: " "})
+	assert.Error(t, err, "名称不能为空")
+
+	name := "剧集"
+	updated, err := service.Update(ctx, created.ID, &UpdateRequest{Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, "剧集", updated.Name)
+
+	require.NoError(t, service.Delete(ctx, created.ID))
+	_, err = service.Get(ctx, created.ID)
+	assert.ErrorIs(t, err, ErrPlaylistNotFound)
+	assert.ErrorIs(t, service.Delete(ctx, created.ID), ErrPlaylistNotFound)
+	assert.Equal(t, 0, service.Count(ctx))
+}
+
+// TestPlaylistService_AddVideos 测试添加视频
+func TestPlaylistService_AddVideos(t *testing.T) {
+	service := NewPlaylistService()
+	ctx := context.Background()
+	playlist := createTestPlaylist(t, service, "v1", "v2")
+
+	updated, err := service.AddVideos(ctx, playlist.ID, []string{"v3", "v1", "v3"}, -1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1", "v2", "v3"}, updated.VideoIDs, "已存在的视频应该被忽略")
+
+	updated, err = service.AddVideos(ctx, playlist.ID, []string{"v4", "v5"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1", "v4", "v5", "v2", "v3"}, updated.VideoIDs, "应该插入到指定位置")
+
+	updated, err = service.AddVideos(ctx, playlist.ID, []string{"v6"}, 100)
+	require.NoError(t, err)
+	assert.Equal(t, "v6", updated.VideoIDs[len(updated.VideoIDs)-1], "位置超出范围时应该追加到末尾")
+
+	updated, err = service.RemoveVideos(ctx, playlist.ID, []string{"v4", "v5", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1", "v2", "v3", "v6"}, updated.VideoIDs)
+
+	_, err = service.AddVideos(ctx, "missing", []string{"v1"}, -1)
+	assert.ErrorIs(t, err, ErrPlaylistNotFound)
+}
+
+// TestPlaylistService_Reorder 测试调整播放顺序
+func TestPlaylistService_Reorder(t *testing.T) {
+	service := NewPlaylistService()
+	ctx := context.Background()
+	playlist := createTestPlaylist(t, service, "v1", "v2", "v3")
+
+	updated, err := service.Reorder(ctx, playlist.ID, []string{"v3", "v1", "v2"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v3", "v1", "v2"}, updated.VideoIDs)
+
+	invalid := [][]string{
+		{"v1", "v2"},
+		{"v1", "v2", "v2"},
+		{"v1", "v2", "v4"},
+	}
+	for _, order := range invalid {
+		_, err := service.Reorder(ctx, playlist.ID, order)
+		assert.Error(t, err, "无效的排序应该返回错误: %v", order)
+	}
+
+	current, err := service.Get(ctx, playlist.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v3", "v1", "v2"}, current.VideoIDs, "无效的排序不应该修改播放列表")
+}
+
+// TestPlaylistService_Navigate 测试获取上一个和下一个视频
+func TestPlaylistService_Navigate(t *testing.T) {
+	service := NewPlaylistService()
+	ctx := context.Background()
+	playlist := createTestPlaylist(t, service, "v1", "v2", "v3")
+
+	testCases := []struct {
+		name     string
+		videoID  string
+		loop     bool
+		expected Navigation
+	}{
+		{"中间位置", "v2", false, Navigation{Position: 1, Total: 3, Previous: "v1", Next: "v3"}},
+		{"第一个", "v1", false, Navigation{Position: 0, Total: 3, Next: "v2"}},
+		{"最后一个", "v3", false, Navigation{Position: 2, Total: 3, Previous: "v2"}},
+		{"循环_第一个", "v1", true, Navigation{Position: 0, Total: 3, Previous: "v3", Next: "v2"}},
+		{"循环_最后一个", "v3", true, Navigation{Position: 2, Total: 3, Previous: "v2", Next: "v1"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			navigation, err := service.Navigate(ctx, playlist.ID, tc.videoID, tc.loop)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, *navigation)
+		})
+	}
+
+	single := createTestPlaylist(t, service, "v1")
+	navigation, err := service.Navigate(ctx, single.ID, "v1", true)
+	require.NoError(t, err)
+	assert.Empty(t, navigation.Next, "只有一个视频时循环播放不应该返回自身")
+
+	_, err = service.Navigate(ctx, playlist.ID, "missing", false)
+	assert.ErrorIs(t, err, ErrVideoNotInPlaylist)
+
+	service.RemoveVideoFromAll(ctx, "v2")
+	navigation, err = service.Navigate(ctx, playlist.ID, "v1", false)
+	require.NoError(t, err)
+	assert.Equal(t, "v3", navigation.Next, "删除的视频应该从播放列表中移除")
+}
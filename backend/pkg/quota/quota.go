@@ -0,0 +1,136 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrQuotaExceeded 存储配额不足
+var ErrQuotaExceeded = errors.New("存储配额不足")
+
+// UsageFunc 统计用户已存储的总字节数
+type UsageFunc func(ctx context.Context, owner string) (int64, error)
+
+// Usage 用户配额使用情况
+type Usage struct {
+	Owner     string // 用户ID
+	Used      int64  // 已存储字节数
+	Reserved  int64  // 上传中预占的字节数
+	Limit     int64  // 配额上限，0表示不限制
+	Remaining int64  // 剩余可用字节数，不限制时为-1
+}
+
+// QuotaManager 用户存储配额管理
+// 已用空间由UsageFunc统计，上传过程中通过预占避免并发上传同时通过检查
+type QuotaManager struct {
+	limit     int64
+	usageFunc UsageFunc
+	reserved  map[string]int64
+	mutex     sync.Mutex
+}
+
+// Reservation 上传预占的配额，上传结束后需要调用Release释放
+type Reservation struct {
+	manager *QuotaManager
+	owner   string
+	size    int64
+	once    sync.Once
+}
+
+// NewQuotaManager 创建配额管理器，limit为0时不限制
+func NewQuotaManager(limit int64, usageFunc UsageFunc) *QuotaManager {
+	return &QuotaManager{
+		limit:     limit,
+		usageFunc: usageFunc,
+		reserved:  make(map[string]int64),
+	}
+}
+
+// Limit 获取每个用户的配额上限
+func (m *QuotaManager) Limit() int64 {
+	return m.limit
+}
+
+// Check 检查用户是否还能存储指定大小的文件，不预占配额
+func (m *QuotaManager) Check(ctx context.Context, owner string, size int64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	_, err := m.checkLocked(ctx, owner, size)
+	return err
+}
+
+// Reserve 为上传预占配额，配额不足时返回ErrQuotaExceeded
+func (m *QuotaManager) Reserve(ctx context.Context, owner string, size int64) (*Reservation, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, err := m.checkLocked(ctx, owner, size); err != nil {
+		return nil, err
+	}
+
+	m.reserved[owner] += size
+	return &Reservation{manager: m, owner: owner, size: size}, nil
+}
+
+// Release 释放预占的配额，上传的文件应在释放前计入已用空间；重复调用无副作用
+func (r *Reservation) Release() {
+	if r == nil {
+		return
+	}
+	r.once.Do(func() {
+		r.manager.mutex.Lock()
+		defer r.manager.mutex.Unlock()
+
+		r.manager.reserved[r.owner] -= r.size
+		if r.manager.reserved[r.owner] <= 0 {
+			delete(r.manager.reserved, r.owner)
+		}
+	})
+}
+
+// GetUsage 获取用户配额使用情况
+func (m *QuotaManager) GetUsage(ctx context.Context, owner string) (*Usage, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.usageLocked(ctx, owner)
+}
+
+// checkLocked 检查配额，调用方需要持有锁
+func (m *QuotaManager) checkLocked(ctx context.Context, owner string, size int64) (*Usage, error) {
+	usage, err := m.usageLocked(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	if usage.Limit > 0 && size > usage.Remaining {
+		return usage, fmt.Errorf("%w: 需要%d字节，剩余%d字节", ErrQuotaExceeded, size, usage.Remaining)
+	}
+	return usage, nil
+}
+
+// usageLocked 统计配额使用情况，调用方需要持有锁
+func (m *QuotaManager) usageLocked(ctx context.Context, owner string) (*Usage, error) {
+	used, err := m.usageFunc(ctx, owner)
+	if err != nil {
+		return nil, fmt.Errorf("统计存储用量失败: %w", err)
+	}
+
+	usage := &Usage{
+		Owner:     owner,
+		Used:      used,
+		Reserved:  m.reserved[owner],
+		Limit:     m.limit,
+		Remaining: -1,
+	}
+	if m.limit > 0 {
+		usage.Remaining = m.limit - used - usage.Reserved
+		if usage.Remaining < 0 {
+			usage.Remaining = 0
+		}
+	}
+	return usage, nil
+}
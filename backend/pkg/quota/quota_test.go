@@ -0,0 +1,96 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticUsage 返回固定已用空间的统计函数
+func staticUsage(used map[string]int64) UsageFunc {
+	return func(ctx context.Context, owner string) (int64, error) {
+		return used[owner], nil
+	}
+}
+
+// TestQuotaManager_Reserve 测试配额预占和释放
+func TestQuotaManager_Reserve(t *testing.T) {
+	ctx := context.Background()
+	manager := NewQuotaManager(100, staticUsage(map[string]int64{"alice": 60}))
+
+	reservation, err := manager.Reserve(ctx, "alice", 30)
+	require.NoError(t, err, "剩余配额内的上传应该成功")
+
+	_, err = manager.Reserve(ctx, "alice", 20)
+	assert.ErrorIs(t, err, ErrQuotaExceeded, "预占后剩余配额不足应该被拒绝")
+
+	usage, err := manager.GetUsage(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, &Usage{Owner: "alice", Used: 60, Reserved: 30, Limit: 100, Remaining: 10}, usage)
+
+	reservation.Release()
+	reservation.Release()
+	usage, err = manager.GetUsage(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), usage.Reserved, "重复释放不应该重复扣减")
+	assert.Equal(t, int64(40), usage.Remaining)
+
+	_, err = manager.Reserve(ctx, "bob", 100)
+	assert.NoError(t, err, "不同用户的配额应该独立计算")
+
+	assert.ErrorIs(t, manager.Check(ctx, "bob", 1), ErrQuotaExceeded)
+	assert.NoError(t, manager.Check(ctx, "alice", 40), "检查不应该预占配额")
+	assert.NoError(t, manager.Check(ctx, "alice", 40))
+}
+
+// TestQuotaManager_Unlimited 测试不限制配额
+func TestQuotaManager_Unlimited(t *testing.T) {
+	ctx := context.Background()
+	manager := NewQuotaManager(0, staticUsage(map[string]int64{"alice": 1 << 40}))
+
+	reservation, err := manager.Reserve(ctx, "alice", 1<<40)
+	require.NoError(t, err)
+	defer reservation.Release()
+
+	usage, err := manager.GetUsage(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), usage.Remaining, "不限制时剩余空间应该为-1")
+}
+
+// TestQuotaManager_UsageError 测试统计用量失败
+func TestQuotaManager_UsageError(t *testing.T) {
+	manager := NewQuotaManager(100, func(ctx context.Context, owner string) (int64, error) {
+		return 0, errors.New("统计失败")
+	})
+
+	_, err := manager.Reserve(context.Background(), "alice", 1)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrQuotaExceeded)
+}
+
+// TestQuotaManager_Concurrent 测试并发预占不会超出配额
+func TestQuotaManager_Concurrent(t *testing.T) {
+	manager := NewQuotaManager(100, staticUsage(nil))
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	granted := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := manager.Reserve(context.Background(), "alice", 10); err == nil {
+				mutex.Lock()
+				granted++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, granted, "并发预占的总量不应该超过配额")
+}
@@ -0,0 +1,239 @@
+package replication
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// 复制操作
+const (
+	OpPut    = "put"    // 将主存储中的文件复制到副本，覆盖副本中的同名文件
+	OpDelete = "delete" // 删除副本中的文件，只在同步删除时使用
+)
+
+// Task 一个复制操作
+type Task struct {
+	Op     string
+	Bucket string
+	Object string
+}
+
+// Options 复制选项
+type Options struct {
+	Buckets       []string // 需要复制的存储桶，副本中使用同名存储桶
+	Workers       int      // 并发复制的文件数，为0时使用1
+	QueueSize     int      // 等待复制的操作上限，为0时使用1000
+	MirrorDeletes bool     // 是否同步删除
+}
+
+// Stats 复制统计
+type Stats struct {
+	Queued         int       // 队列中等待复制的操作数量
+	Replicated     int64     // 已复制的文件数量
+	Deleted        int64     // 已从副本删除的文件数量
+	Failed         int64     // 复制或删除失败的操作数量，由下一次同步补齐
+	Dropped        int64     // 队列已满而丢弃的操作数量，由下一次同步补齐
+	LastError      string    // 最近一次错误
+	LastErrorAt    time.Time // 最近一次错误的时间
+	LastSyncAt     time.Time // 最近一次完成同步的时间
+	LastSyncQueued int       // 最近一次同步入队的操作数量
+}
+
+// Replicator 将主存储中新写入的文件异步复制到副本存储的同名存储桶
+// 写入操作通过Wrap包装的存储入队，不经过服务写入的文件（如客户端直传）由Sync补齐
+type Replicator struct {
+	primary       storage.StorageInterface
+	replica       storage.StorageInterface
+	buckets       []string
+	mirrorDeletes bool
+	workers       int
+	queue         chan Task
+	stats         Stats
+	mutex         sync.Mutex
+	readyBuckets  sync.Map // 副本中已确认存在的存储桶
+	startOnce     sync.Once
+	syncing       atomic.Bool
+}
+
+// NewReplicator 创建存储复制
+func NewReplicator(primary, replica storage.StorageInterface, opts Options) (*Replicator, error) {
+	if primary == nil || replica == nil {
+		return nil, fmt.Errorf("存储服务不能为空")
+	}
+	if primary == replica {
+		return nil, fmt.Errorf("副本存储不能与主存储相同")
+	}
+
+	buckets := make([]string, 0, len(opts.Buckets))
+	for _, bucket := range opts.Buckets {
+		if bucket != "" && !slices.Contains(buckets, bucket) {
+			buckets = append(buckets, bucket)
+		}
+	}
+	if len(buckets) == 0 {
+		return nil, fmt.Errorf("需要复制的存储桶不能为空")
+	}
+	slices.Sort(buckets)
+
+	workers := max(opts.Workers, 1)
+	queueSize := opts.QueueSize
+	if queueSize <= 0 {
+		queueSize = 1000
+	}
+	return &Replicator{
+		primary:       primary,
+		replica:       replica,
+		buckets:       buckets,
+		mirrorDeletes: opts.MirrorDeletes,
+		workers:       workers,
+		queue:         make(chan Task, queueSize),
+	}, nil
+}
+
+// Buckets 获取需要复制的存储桶
+func (r *Replicator) Buckets() []string {
+	return slices.Clone(r.buckets)
+}
+
+// MirrorDeletes 判断是否同步删除
+func (r *Replicator) MirrorDeletes() bool {
+	return r.mirrorDeletes
+}
+
+// Start 启动复制worker，重复调用只启动一次；ctx取消后worker退出，队列中的操作由下一次同步补齐
+func (r *Replicator) Start(ctx context.Context) {
+	r.startOnce.Do(func() {
+		for i := 0; i < r.workers; i++ {
+			go r.work(ctx)
+		}
+	})
+}
+
+// work 逐个执行队列中的复制操作
+func (r *Replicator) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case task := <-r.queue:
+			if err := r.Replicate(ctx, task); err != nil {
+				r.recordError(task, err)
+			}
+		}
+	}
+}
+
+// Enqueue 将复制操作加入队列，不需要复制的存储桶和未同步删除时的删除操作被忽略
+// 队列已满时丢弃并返回false
+func (r *Replicator) Enqueue(op, bucket, object string) bool {
+	if !slices.Contains(r.buckets, bucket) || (op == OpDelete && !r.mirrorDeletes) {
+		return true
+	}
+
+	select {
+	case r.queue <- Task{Op: op, Bucket: bucket, Object: object}:
+		return true
+	default:
+		r.update(func(s *Stats) { s.Dropped++ })
+		return false
+	}
+}
+
+// Replicate 立即执行一个复制操作；主存储中的文件已不存在时不复制
+func (r *Replicator) Replicate(ctx context.Context, task Task) error {
+	switch task.Op {
+	case OpPut:
+		copied, err := r.copyObject(ctx, task.Bucket, task.Object)
+		if err == nil && copied {
+			r.update(func(s *Stats) { s.Replicated++ })
+		}
+		return err
+	case OpDelete:
+		if err := r.replica.DeleteFile(ctx, task.Bucket, task.Object); err != nil {
+			return err
+		}
+		r.update(func(s *Stats) { s.Deleted++ })
+		return nil
+	default:
+		return fmt.Errorf("不支持的复制操作: %s", task.Op)
+	}
+}
+
+// copyObject 流式复制文件，保留内容类型、用户元数据和对象标签
+func (r *Replicator) copyObject(ctx context.Context, bucket, object string) (bool, error) {
+	info, err := r.primary.GetFileInfo(ctx, bucket, object)
+	if err != nil {
+		if exists, existsErr := r.primary.FileExists(ctx, bucket, object); existsErr == nil && !exists {
+			return false, nil
+		}
+		return false, fmt.Errorf("获取文件信息失败: %w", err)
+	}
+	tags, err := r.primary.GetObjectTags(ctx, bucket, object)
+	if err != nil {
+		return false, fmt.Errorf("获取对象标签失败: %w", err)
+	}
+	if err := r.ensureBucket(ctx, bucket); err != nil {
+		return false, err
+	}
+
+	reader, err := r.primary.OpenFile(ctx, bucket, object)
+	if err != nil {
+		return false, fmt.Errorf("读取文件失败: %w", err)
+	}
+	defer reader.Close()
+
+	opts := storage.ObjectOptions{Metadata: info.Metadata, Tags: tags}
+	if _, err := r.replica.UploadStream(ctx, bucket, object, reader, info.Size, info.ContentType, opts); err != nil {
+		return false, fmt.Errorf("写入副本失败: %w", err)
+	}
+	return true, nil
+}
+
+// ensureBucket 确保副本中存在同名存储桶
+func (r *Replicator) ensureBucket(ctx context.Context, bucket string) error {
+	if _, ok := r.readyBuckets.Load(bucket); ok {
+		return nil
+	}
+	exists, err := r.replica.BucketExists(ctx, bucket)
+	if err != nil {
+		return fmt.Errorf("检查副本存储桶失败: %w", err)
+	}
+	if !exists {
+		if err := r.replica.CreateBucket(ctx, bucket); err != nil {
+			return fmt.Errorf("创建副本存储桶失败: %w", err)
+		}
+	}
+	r.readyBuckets.Store(bucket, struct{}{})
+	return nil
+}
+
+// Stats 获取复制统计快照
+func (r *Replicator) Stats() Stats {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	stats := r.stats
+	stats.Queued = len(r.queue)
+	return stats
+}
+
+// recordError 记录失败的复制操作
+func (r *Replicator) recordError(task Task, err error) {
+	r.update(func(s *Stats) {
+		s.Failed++
+		s.LastError = fmt.Sprintf("%s %s/%s: %v", task.Op, task.Bucket, task.Object, err)
+		s.LastErrorAt = time.Now()
+	})
+}
+
+// update 在锁内更新统计
+func (r *Replicator) update(fn func(s *Stats)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	fn(&r.stats)
+}
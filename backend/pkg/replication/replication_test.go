@@ -0,0 +1,148 @@
+package replication
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// newTestStorage 创建使用临时目录的本地存储
+func newTestStorage(t *testing.T) *storage.LocalStorage {
+	store, err := storage.NewLocalStorage(&storage.LocalConfig{RootDir: t.TempDir()})
+	require.NoError(t, err)
+	return store
+}
+
+// newTestReplicator 创建复制videos存储桶的测试复制
+func newTestReplicator(t *testing.T, opts Options) (*Replicator, *storage.LocalStorage, *storage.LocalStorage) {
+	primary, replica := newTestStorage(t), newTestStorage(t)
+	require.NoError(t, primary.CreateBucket(context.Background(), "videos"))
+	opts.Buckets = []string{"videos"}
+	replicator, err := NewReplicator(primary, replica, opts)
+	require.NoError(t, err)
+	return replicator, primary, replica
+}
+
+// readReplica 读取副本中的文件，不存在时返回空字符串
+func readReplica(t *testing.T, replica storage.StorageInterface, object string) string {
+	exists, err := replica.FileExists(context.Background(), "videos", object)
+	require.NoError(t, err)
+	if !exists {
+		return ""
+	}
+	data, err := replica.DownloadFile(context.Background(), "videos", object)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestNewReplicator(t *testing.T) {
+	primary, replica := newTestStorage(t), newTestStorage(t)
+
+	_, err := NewReplicator(primary, primary, Options{Buckets: []string{"videos"}})
+	assert.Error(t, err, "副本不能与主存储相同")
+	_, err = NewReplicator(primary, replica, Options{Buckets: []string{""}})
+	assert.Error(t, err)
+
+	replicator, err := NewReplicator(primary, replica, Options{Buckets: []string{"videos", "thumbnails", "videos"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"thumbnails", "videos"}, replicator.Buckets())
+}
+
+func TestStorage_ReplicatesWrites(t *testing.T) {
+	ctx := context.Background()
+	replicator, primary, replica := newTestReplicator(t, Options{QueueSize: 10})
+	wrapped := replicator.Wrap(primary)
+	assert.Same(t, primary, wrapped.Unwrap())
+
+	_, err := wrapped.UploadFile(ctx, "videos", "a.mp4", []byte("aaa"), "video/mp4")
+	require.NoError(t, err)
+	require.NoError(t, wrapped.CopyFile(ctx, "videos", "a.mp4", "videos", "b.mp4"))
+	require.NoError(t, primary.CreateBucket(ctx, "other"))
+	_, err = wrapped.UploadFile(ctx, "other", "c.mp4", []byte("c"), "video/mp4")
+	require.NoError(t, err)
+	require.NoError(t, wrapped.DeleteFile(ctx, "videos", "b.mp4"))
+	assert.Equal(t, 2, replicator.Stats().Queued, "不需要复制的存储桶和未同步删除时的删除不入队")
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	replicator.Start(workerCtx)
+	require.Eventually(t, func() bool { return replicator.Stats().Queued == 0 && replicator.Stats().Replicated == 1 }, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, "aaa", readReplica(t, replica, "a.mp4"))
+	assert.Empty(t, readReplica(t, replica, "b.mp4"), "复制前已删除的文件不复制")
+	info, err := replica.GetFileInfo(ctx, "videos", "a.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, "video/mp4", info.ContentType)
+	assert.Zero(t, replicator.Stats().Failed)
+}
+
+func TestReplicator_Enqueue_QueueFull(t *testing.T) {
+	replicator, _, _ := newTestReplicator(t, Options{QueueSize: 1})
+
+	assert.True(t, replicator.Enqueue(OpPut, "videos", "a.mp4"))
+	assert.False(t, replicator.Enqueue(OpPut, "videos", "b.mp4"))
+	assert.Equal(t, int64(1), replicator.Stats().Dropped)
+}
+
+func TestReplicator_CheckAndSync(t *testing.T) {
+	ctx := context.Background()
+
+	for _, mirrorDeletes := range []bool{false, true} {
+		replicator, primary, replica := newTestReplicator(t, Options{QueueSize: 10, MirrorDeletes: mirrorDeletes})
+		for name, data := range map[string]string{"same.mp4": "same", "changed.mp4": "new data", "missing.mp4": "m"} {
+			_, err := primary.UploadFile(ctx, "videos", name, []byte(data), "video/mp4")
+			require.NoError(t, err)
+		}
+
+		report, err := replicator.Check(ctx)
+		require.NoError(t, err)
+		require.Len(t, report.Buckets, 1)
+		assert.False(t, report.Consistent)
+		assert.Equal(t, 3, report.Buckets[0].Missing, "副本中的存储桶不存在时全部文件都缺少")
+		assert.Equal(t, 0, report.Buckets[0].ReplicaObjects)
+
+		require.NoError(t, replica.CreateBucket(ctx, "videos"))
+		for name, data := range map[string]string{"same.mp4": "same", "changed.mp4": "old", "deleted.mp4": "d"} {
+			_, err := replica.UploadFile(ctx, "videos", name, []byte(data), "video/mp4")
+			require.NoError(t, err)
+		}
+
+		report, queued, err := replicator.Sync(ctx)
+		require.NoError(t, err)
+		bucket := report.Buckets[0]
+		assert.Equal(t, 3, bucket.PrimaryObjects)
+		assert.Equal(t, int64(13), bucket.PrimaryBytes)
+		assert.Equal(t, 3, bucket.ReplicaObjects)
+		assert.Equal(t, []string{"missing.mp4"}, bucket.MissingObjects)
+		assert.Equal(t, []string{"changed.mp4"}, bucket.MismatchedObjects)
+		assert.Equal(t, []string{"deleted.mp4"}, bucket.ExtraObjects)
+		assert.False(t, replicator.Stats().LastSyncAt.IsZero())
+
+		if mirrorDeletes {
+			assert.Equal(t, 3, queued)
+		} else {
+			assert.Equal(t, 2, queued, "未同步删除时保留副本中多余的文件")
+		}
+
+		workerCtx, cancel := context.WithCancel(ctx)
+		replicator.Start(workerCtx)
+		require.Eventually(t, func() bool { return replicator.Stats().Queued == 0 }, time.Second, 10*time.Millisecond)
+		require.Eventually(t, func() bool {
+			report, err := replicator.Check(ctx)
+			return err == nil && report.Consistent
+		}, time.Second, 10*time.Millisecond)
+		cancel()
+
+		assert.Equal(t, "new data", readReplica(t, replica, "changed.mp4"))
+		if mirrorDeletes {
+			assert.Empty(t, readReplica(t, replica, "deleted.mp4"))
+		} else {
+			assert.Equal(t, "d", readReplica(t, replica, "deleted.mp4"))
+		}
+	}
+}
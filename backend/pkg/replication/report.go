@@ -0,0 +1,158 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// SampleLimit 一致性报告中每类差异最多列出的文件数量
+const SampleLimit = 20
+
+// BucketReport 一个存储桶的一致性报告，文件大小不同视为不一致
+type BucketReport struct {
+	Bucket            string
+	PrimaryObjects    int      // 主存储中的文件数量
+	PrimaryBytes      int64    // 主存储中的文件总大小
+	ReplicaObjects    int      // 副本中的文件数量
+	ReplicaBytes      int64    // 副本中的文件总大小
+	Missing           int      // 副本中缺少的文件数量
+	Mismatched        int      // 两边大小不同的文件数量
+	Extra             int      // 只在副本中存在的文件数量，未同步删除时为已删除的文件
+	MissingObjects    []string // 副本中缺少的文件，最多SampleLimit个
+	MismatchedObjects []string // 两边大小不同的文件，最多SampleLimit个
+	ExtraObjects      []string // 只在副本中存在的文件，最多SampleLimit个
+}
+
+// Report 一致性报告
+type Report struct {
+	Buckets    []BucketReport
+	Consistent bool // 副本是否包含主存储中的全部文件；同步删除时还要求副本中没有多余的文件
+	CheckedAt  time.Time
+}
+
+// Check 对比主存储和副本中需要复制的存储桶，生成一致性报告
+func (r *Replicator) Check(ctx context.Context) (*Report, error) {
+	report, _, err := r.check(ctx)
+	return report, err
+}
+
+// ErrSyncRunning 已有同步正在执行
+var ErrSyncRunning = errors.New("同步正在执行")
+
+// Sync 对比主存储和副本，将缺少或大小不同的文件加入复制队列，同步删除时还删除副本中多余的文件
+// 队列已满时等待worker处理，返回同步前的一致性报告和入队的操作数量；同一时间只执行一个同步
+func (r *Replicator) Sync(ctx context.Context) (*Report, int, error) {
+	if !r.syncing.CompareAndSwap(false, true) {
+		return nil, 0, ErrSyncRunning
+	}
+	defer r.syncing.Store(false)
+
+	report, tasks, err := r.check(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	queued := 0
+	for _, task := range tasks {
+		select {
+		case <-ctx.Done():
+			return report, queued, ctx.Err()
+		case r.queue <- task:
+			queued++
+		}
+	}
+	r.update(func(s *Stats) {
+		s.LastSyncAt = time.Now()
+		s.LastSyncQueued = queued
+	})
+	return report, queued, nil
+}
+
+// Syncing 判断是否有同步正在执行
+func (r *Replicator) Syncing() bool {
+	return r.syncing.Load()
+}
+
+// check 生成一致性报告，同时返回使副本一致需要的操作
+func (r *Replicator) check(ctx context.Context) (*Report, []Task, error) {
+	report := &Report{Consistent: true, CheckedAt: time.Now()}
+	var tasks []Task
+	for _, bucket := range r.buckets {
+		primaryFiles, err := listBucket(ctx, r.primary, bucket)
+		if err != nil {
+			return nil, nil, fmt.Errorf("列出主存储文件失败(%s): %w", bucket, err)
+		}
+		replicaFiles, err := listBucket(ctx, r.replica, bucket)
+		if err != nil {
+			return nil, nil, fmt.Errorf("列出副本文件失败(%s): %w", bucket, err)
+		}
+
+		bucketReport := BucketReport{
+			Bucket:            bucket,
+			PrimaryObjects:    len(primaryFiles),
+			ReplicaObjects:    len(replicaFiles),
+			MissingObjects:    []string{},
+			MismatchedObjects: []string{},
+			ExtraObjects:      []string{},
+		}
+		replicaSizes := make(map[string]int64, len(replicaFiles))
+		for _, file := range replicaFiles {
+			replicaSizes[file.Key] = file.Size
+			bucketReport.ReplicaBytes += file.Size
+		}
+		for _, file := range primaryFiles {
+			bucketReport.PrimaryBytes += file.Size
+			size, exists := replicaSizes[file.Key]
+			delete(replicaSizes, file.Key)
+			switch {
+			case !exists:
+				bucketReport.Missing++
+				bucketReport.MissingObjects = appendSample(bucketReport.MissingObjects, file.Key)
+			case size != file.Size:
+				bucketReport.Mismatched++
+				bucketReport.MismatchedObjects = appendSample(bucketReport.MismatchedObjects, file.Key)
+			default:
+				continue
+			}
+			tasks = append(tasks, Task{Op: OpPut, Bucket: bucket, Object: file.Key})
+		}
+		// 剩下的是只在副本中存在的文件，按副本列出的顺序记录
+		for _, file := range replicaFiles {
+			if _, extra := replicaSizes[file.Key]; !extra {
+				continue
+			}
+			bucketReport.Extra++
+			bucketReport.ExtraObjects = appendSample(bucketReport.ExtraObjects, file.Key)
+			if r.mirrorDeletes {
+				tasks = append(tasks, Task{Op: OpDelete, Bucket: bucket, Object: file.Key})
+			}
+		}
+
+		if bucketReport.Missing > 0 || bucketReport.Mismatched > 0 || (r.mirrorDeletes && bucketReport.Extra > 0) {
+			report.Consistent = false
+		}
+		report.Buckets = append(report.Buckets, bucketReport)
+	}
+	return report, tasks, nil
+}
+
+// listBucket 列出存储桶中的全部文件，存储桶不存在时返回空列表
+func listBucket(ctx context.Context, storageClient storage.StorageInterface, bucket string) ([]*storage.FileInfo, error) {
+	exists, err := storageClient.BucketExists(ctx, bucket)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return storageClient.ListFiles(ctx, bucket, "")
+}
+
+// appendSample 在未达到SampleLimit时追加文件名
+func appendSample(samples []string, name string) []string {
+	if len(samples) >= SampleLimit {
+		return samples
+	}
+	return append(samples, name)
+}
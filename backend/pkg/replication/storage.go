@@ -0,0 +1,103 @@
+package replication
+
+import (
+	"context"
+	"io"
+
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// Storage 包装主存储，写入、复制、移动和删除成功后将对应的复制操作加入队列
+// 其余方法直接使用主存储
+type Storage struct {
+	storage.StorageInterface
+	replicator *Replicator
+}
+
+// Wrap 包装主存储，写入主存储的文件由replicator复制到副本
+func (r *Replicator) Wrap(primary storage.StorageInterface) *Storage {
+	return &Storage{StorageInterface: primary, replicator: r}
+}
+
+// Unwrap 获取被包装的主存储
+func (s *Storage) Unwrap() storage.StorageInterface {
+	return s.StorageInterface
+}
+
+// UploadFile 上传文件并复制到副本
+func (s *Storage) UploadFile(ctx context.Context, bucketName, objectName string, data []byte, contentType string, opts ...storage.ObjectOptions) (*storage.UploadResult, error) {
+	result, err := s.StorageInterface.UploadFile(ctx, bucketName, objectName, data, contentType, opts...)
+	if err == nil {
+		s.replicator.Enqueue(OpPut, bucketName, objectName)
+	}
+	return result, err
+}
+
+// UploadStream 流式上传文件并复制到副本
+func (s *Storage) UploadStream(ctx context.Context, bucketName, objectName string, reader io.Reader, size int64, contentType string, opts ...storage.ObjectOptions) (*storage.UploadResult, error) {
+	result, err := s.StorageInterface.UploadStream(ctx, bucketName, objectName, reader, size, contentType, opts...)
+	if err == nil {
+		s.replicator.Enqueue(OpPut, bucketName, objectName)
+	}
+	return result, err
+}
+
+// CompleteMultipartUpload 完成分片上传并复制合并后的文件
+func (s *Storage) CompleteMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string, parts []storage.CompletePart) (*storage.UploadResult, error) {
+	result, err := s.StorageInterface.CompleteMultipartUpload(ctx, bucketName, objectName, uploadID, parts)
+	if err == nil {
+		s.replicator.Enqueue(OpPut, bucketName, objectName)
+	}
+	return result, err
+}
+
+// CopyFile 复制文件并将目标文件复制到副本
+func (s *Storage) CopyFile(ctx context.Context, srcBucket, srcObject, dstBucket, dstObject string) error {
+	err := s.StorageInterface.CopyFile(ctx, srcBucket, srcObject, dstBucket, dstObject)
+	if err == nil {
+		s.replicator.Enqueue(OpPut, dstBucket, dstObject)
+	}
+	return err
+}
+
+// MoveFile 移动文件，将目标文件复制到副本，同步删除时删除副本中的源文件
+func (s *Storage) MoveFile(ctx context.Context, srcBucket, srcObject, dstBucket, dstObject string) error {
+	err := s.StorageInterface.MoveFile(ctx, srcBucket, srcObject, dstBucket, dstObject)
+	if err == nil {
+		s.replicator.Enqueue(OpPut, dstBucket, dstObject)
+		s.replicator.Enqueue(OpDelete, srcBucket, srcObject)
+	}
+	return err
+}
+
+// SetObjectTags 替换对象标签，副本中的文件重新复制以更新标签
+func (s *Storage) SetObjectTags(ctx context.Context, bucketName, objectName string, tags map[string]string) error {
+	err := s.StorageInterface.SetObjectTags(ctx, bucketName, objectName, tags)
+	if err == nil {
+		s.replicator.Enqueue(OpPut, bucketName, objectName)
+	}
+	return err
+}
+
+// DeleteFile 删除文件，同步删除时删除副本中的文件
+func (s *Storage) DeleteFile(ctx context.Context, bucketName, objectName string) error {
+	err := s.StorageInterface.DeleteFile(ctx, bucketName, objectName)
+	if err == nil {
+		s.replicator.Enqueue(OpDelete, bucketName, objectName)
+	}
+	return err
+}
+
+// DeleteFiles 批量删除文件，同步删除时删除副本中已成功删除的文件
+func (s *Storage) DeleteFiles(ctx context.Context, bucketName string, objectNames []string) (map[string]error, error) {
+	failed, err := s.StorageInterface.DeleteFiles(ctx, bucketName, objectNames)
+	if err != nil {
+		return failed, err
+	}
+	for _, objectName := range objectNames {
+		if _, ok := failed[objectName]; !ok {
+			s.replicator.Enqueue(OpDelete, bucketName, objectName)
+		}
+	}
+	return failed, nil
+}
@@ -0,0 +1,267 @@
+package share
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 分享链接限制
+const (
+	tokenBytes        = 16      // 分享令牌的随机字节数
+	MaxPasswordLength = 72      // 密码最大长度（字节），bcrypt只使用前72字节
+	MaxViews          = 1000000 // 最大访问次数上限
+)
+
+var (
+	// ErrShareNotFound 分享不存在或已撤销
+	ErrShareNotFound = errors.New("分享不存在或已撤销")
+	// ErrShareExpired 分享已过期
+	ErrShareExpired = errors.New("分享已过期")
+	// ErrViewLimitReached 分享访问次数已用完
+	ErrViewLimitReached = errors.New("分享访问次数已用完")
+	// ErrPasswordRequired 分享需要密码
+	ErrPasswordRequired = errors.New("分享需要密码")
+	// ErrWrongPassword 分享密码错误
+	ErrWrongPassword = errors.New("分享密码错误")
+)
+
+// Share 视频分享链接
+type Share struct {
+	Token        string    `json:"token"`      // 分享令牌，同时作为分享ID
+	VideoID      string    `json:"video_id"`   // 分享的视频ID
+	CreatedBy    string    `json:"created_by"` // 创建者
+	PasswordHash string    `json:"-"`          // 访问密码的bcrypt哈希，为空时不需要密码
+	MaxViews     int       `json:"max_views"`  // 最大访问次数，0表示不限制
+	Views        int       `json:"views"`      // 已访问次数
+	ExpiresAt    time.Time `json:"expires_at"` // 过期时间，零值表示永不过期
+	CreatedAt    time.Time `json:"created_at"` // 创建时间
+}
+
+// HasPassword 分享是否需要密码
+func (s *Share) HasPassword() bool {
+	return s.PasswordHash != ""
+}
+
+// Expired 分享在指定时间是否已过期
+func (s *Share) Expired(now time.Time) bool {
+	return !s.ExpiresAt.IsZero() && !now.Before(s.ExpiresAt)
+}
+
+// Exhausted 分享的访问次数是否已用完
+func (s *Share) Exhausted() bool {
+	return s.MaxViews > 0 && s.Views >= s.MaxViews
+}
+
+// CreateRequest 创建分享请求
+type CreateRequest struct {
+	VideoID   string        `json:"video_id"`   // 分享的视频ID
+	CreatedBy string        `json:"created_by"` // 创建者
+	Password  string        `json:"password"`   // 访问密码（可选）
+	MaxViews  int           `json:"max_views"`  // 最大访问次数（可选），0表示不限制
+	ExpiresIn time.Duration `json:"expires_in"` // 有效期（可选），0表示永不过期
+}
+
+// ShareService 分享链接管理服务
+type ShareService struct {
+	shares     map[string]*Share // 分享令牌 -> 分享
+	bcryptCost int
+	mutex      sync.RWMutex
+}
+
+// NewShareService 创建分享链接服务
+func NewShareService() *ShareService {
+	return &ShareService{
+		shares:     make(map[string]*Share),
+		bcryptCost: bcrypt.DefaultCost,
+	}
+}
+
+// Create 创建分享链接
+func (s *ShareService) Create(ctx context.Context, req *CreateRequest) (*Share, error) {
+	if req.VideoID == "" {
+		return nil, fmt.Errorf("视频ID不能为空")
+	}
+	if req.MaxViews < 0 || req.MaxViews > MaxViews {
+		return nil, fmt.Errorf("最大访问次数必须在0到%d之间", MaxViews)
+	}
+	if req.ExpiresIn < 0 {
+		return nil, fmt.Errorf("有效期不能为负数")
+	}
+	if len(req.Password) > MaxPasswordLength {
+		return nil, fmt.Errorf("密码长度不能超过%d字节", MaxPasswordLength)
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	share := &Share{
+		Token:     token,
+		VideoID:   req.VideoID,
+		CreatedBy: req.CreatedBy,
+		MaxViews:  req.MaxViews,
+		CreatedAt: now,
+	}
+	if req.ExpiresIn > 0 {
+		share.ExpiresAt = now.Add(req.ExpiresIn)
+	}
+	if req.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), s.bcryptCost)
+		if err != nil {
+			return nil, fmt.Errorf("密码加密失败: %w", err)
+		}
+		share.PasswordHash = string(hash)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.shares[share.Token] = share
+	return copyShare(share), nil
+}
+
+// Get 根据令牌获取分享，不校验是否过期
+func (s *ShareService) Get(ctx context.Context, token string) (*Share, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	share, exists := s.shares[token]
+	if !exists {
+		return nil, ErrShareNotFound
+	}
+
+	return copyShare(share), nil
+}
+
+// List 列出分享链接，createdBy为空时列出全部，按创建时间降序
+func (s *ShareService) List(ctx context.Context, createdBy string) []*Share {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	shares := make([]*Share, 0, len(s.shares))
+	for _, share := range s.shares {
+		if createdBy == "" || share.CreatedBy == createdBy {
+			shares = append(shares, copyShare(share))
+		}
+	}
+
+	sort.Slice(shares, func(i, j int) bool {
+		if !shares[i].CreatedAt.Equal(shares[j].CreatedAt) {
+			return shares[i].CreatedAt.After(shares[j].CreatedAt)
+		}
+		return shares[i].Token < shares[j].Token
+	})
+
+	return shares
+}
+
+// Resolve 使用密码访问分享，校验通过后访问次数加一
+// 分享过期、访问次数用完或密码错误时返回对应错误，不计入访问次数
+func (s *ShareService) Resolve(ctx context.Context, token, password string) (*Share, error) {
+	// 先在读锁下校验密码，避免bcrypt比较期间阻塞其他分享的访问
+	s.mutex.RLock()
+	share, exists := s.shares[token]
+	if !exists {
+		s.mutex.RUnlock()
+		return nil, ErrShareNotFound
+	}
+	passwordHash := share.PasswordHash
+	err := checkAvailable(share)
+	s.mutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if passwordHash != "" {
+		if password == "" {
+			return nil, ErrPasswordRequired
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password)); err != nil {
+			return nil, ErrWrongPassword
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// 校验密码期间分享可能已被撤销或用完访问次数
+	share, exists = s.shares[token]
+	if !exists {
+		return nil, ErrShareNotFound
+	}
+	if err := checkAvailable(share); err != nil {
+		return nil, err
+	}
+
+	share.Views++
+	return copyShare(share), nil
+}
+
+// Revoke 撤销分享链接
+func (s *ShareService) Revoke(ctx context.Context, token string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.shares[token]; !exists {
+		return ErrShareNotFound
+	}
+
+	delete(s.shares, token)
+	return nil
+}
+
+// RemoveVideo 撤销视频的全部分享链接，用于视频删除时清理
+func (s *ShareService) RemoveVideo(ctx context.Context, videoID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for token, share := range s.shares {
+		if share.VideoID == videoID {
+			delete(s.shares, token)
+		}
+	}
+}
+
+// Count 获取分享链接数量
+func (s *ShareService) Count(ctx context.Context) int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return len(s.shares)
+}
+
+// checkAvailable 检查分享是否仍可访问
+func checkAvailable(share *Share) error {
+	if share.Expired(time.Now()) {
+		return ErrShareExpired
+	}
+	if share.Exhausted() {
+		return ErrViewLimitReached
+	}
+	return nil
+}
+
+// newToken 生成URL安全的随机分享令牌
+func newToken() (string, error) {
+	buf := make([]byte, tokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成分享令牌失败: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// copyShare 复制分享，避免调用方修改内部状态
+func copyShare(share *Share) *Share {
+	copied := *share
+	return &copied
+}
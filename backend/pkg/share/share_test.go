@@ -0,0 +1,121 @@
+package share
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newTestShareService 创建测试分享服务（降低bcrypt成本以加快测试）
+func newTestShareService() *ShareService {
+	service := NewShareService()
+	service.bcryptCost = bcrypt.MinCost
+	return service
+}
+
+// TestShareService_Create 测试创建分享
+func TestShareService_Create(t *testing.T) {
+	service := newTestShareService()
+	ctx := context.Background()
+
+	created, err := service.Create(ctx, &CreateRequest{VideoID: "v1", CreatedBy: "alice", ExpiresIn: time.Hour, MaxViews: 3})
+	require.NoError(t, err, "创建分享应该成功")
+	assert.Len(t, created.Token, 22, "分享令牌应该是16字节的base64编码")
+	assert.Equal(t, "v1", created.VideoID)
+	assert.Equal(t, 3, created.MaxViews)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), created.ExpiresAt, time.Second)
+	assert.False(t, created.HasPassword())
+
+	forever, err := service.Create(ctx, &CreateRequest{VideoID: "v1", CreatedBy: "alice"})
+	require.NoError(t, err)
+	assert.True(t, forever.ExpiresAt.IsZero(), "未指定有效期时应该永不过期")
+	assert.NotEqual(t, created.Token, forever.Token, "分享令牌应该随机生成")
+
+	_, err = service.Create(ctx, &CreateRequest{})
+	assert.Error(t, err, "视频ID不能为空")
+	_, err = service.Create(ctx, &CreateRequest{VideoID: "v1", MaxViews: -1})
+	assert.Error(t, err, "最大访问次数不能为负数")
+	_, err = service.Create(ctx, &CreateRequest{VideoID: "v1", ExpiresIn: -time.Second})
+	assert.Error(t, err, "有效期不能为负数")
+	_, err = service.Create(ctx, &CreateRequest{VideoID: "v1", Password: string(make([]byte, 73))})
+	assert.Error(t, err, "密码不能超过72字节")
+
+	assert.Equal(t, 2, service.Count(ctx))
+}
+
+// TestShareService_Resolve 测试访问分享
+func TestShareService_Resolve(t *testing.T) {
+	service := newTestShareService()
+	ctx := context.Background()
+
+	created, err := service.Create(ctx, &CreateRequest{VideoID: "v1", Password: "secret", MaxViews: 2})
+	require.NoError(t, err)
+	assert.True(t, created.HasPassword())
+
+	_, err = service.Resolve(ctx, created.Token, "")
+	assert.ErrorIs(t, err, ErrPasswordRequired)
+	_, err = service.Resolve(ctx, created.Token, "wrong")
+	assert.ErrorIs(t, err, ErrWrongPassword)
+
+	resolved, err := service.Resolve(ctx, created.Token, "secret")
+	require.NoError(t, err, "密码正确时应该可以访问")
+	assert.Equal(t, 1, resolved.Views, "密码错误的访问不应该计数")
+
+	_, err = service.Resolve(ctx, created.Token, "secret")
+	require.NoError(t, err)
+	_, err = service.Resolve(ctx, created.Token, "secret")
+	assert.ErrorIs(t, err, ErrViewLimitReached, "访问次数用完后应该拒绝访问")
+
+	_, err = service.Resolve(ctx, "missing", "")
+	assert.ErrorIs(t, err, ErrShareNotFound)
+}
+
+// TestShareService_Expired 测试过期分享
+func TestShareService_Expired(t *testing.T) {
+	service := newTestShareService()
+	ctx := context.Background()
+
+	created, err := service.Create(ctx, &CreateRequest{VideoID: "v1", ExpiresIn: time.Hour})
+	require.NoError(t, err)
+	assert.False(t, created.Expired(time.Now()))
+	assert.True(t, created.Expired(created.ExpiresAt), "到达过期时间时应该已过期")
+
+	service.shares[created.Token].ExpiresAt = time.Now().Add(-time.Second)
+	_, err = service.Resolve(ctx, created.Token, "")
+	assert.ErrorIs(t, err, ErrShareExpired)
+
+	found, err := service.Get(ctx, created.Token)
+	require.NoError(t, err, "过期的分享仍然可以查询")
+	assert.Equal(t, 0, found.Views)
+}
+
+// TestShareService_ListAndRevoke 测试列出和撤销分享
+func TestShareService_ListAndRevoke(t *testing.T) {
+	service := newTestShareService()
+	ctx := context.Background()
+
+	first, err := service.Create(ctx, &CreateRequest{VideoID: "v1", CreatedBy: "alice"})
+	require.NoError(t, err)
+	second, err := service.Create(ctx, &CreateRequest{VideoID: "v2", CreatedBy: "alice"})
+	require.NoError(t, err)
+	_, err = service.Create(ctx, &CreateRequest{VideoID: "v1", CreatedBy: "bob"})
+	require.NoError(t, err)
+
+	assert.Len(t, service.List(ctx, ""), 3, "不指定创建者时应该列出全部分享")
+	aliceShares := service.List(ctx, "alice")
+	require.Len(t, aliceShares, 2)
+	assert.ElementsMatch(t, []string{first.Token, second.Token}, []string{aliceShares[0].Token, aliceShares[1].Token})
+
+	require.NoError(t, service.Revoke(ctx, first.Token))
+	_, err = service.Resolve(ctx, first.Token, "")
+	assert.ErrorIs(t, err, ErrShareNotFound, "撤销后应该无法访问")
+	assert.ErrorIs(t, service.Revoke(ctx, first.Token), ErrShareNotFound)
+
+	service.RemoveVideo(ctx, "v1")
+	assert.Equal(t, 1, service.Count(ctx), "删除视频应该撤销其全部分享")
+	assert.Len(t, service.List(ctx, "alice"), 1)
+}
@@ -0,0 +1,60 @@
+package storage
+
+import "sort"
+
+// ContentClass 存储内容类别，不同类别的文件可以存放在不同的存储桶
+type ContentClass string
+
+// 存储内容类别
+const (
+	ContentVideos     ContentClass = "videos"     // 原始视频文件
+	ContentThumbnails ContentClass = "thumbnails" // 缩略图、动态预览和进度条预览图
+	ContentRenditions ContentClass = "renditions" // HLS播放列表和分片
+	ContentSubtitles  ContentClass = "subtitles"  // 字幕文件
+)
+
+// ContentClasses 全部存储内容类别
+var ContentClasses = []ContentClass{ContentVideos, ContentThumbnails, ContentRenditions, ContentSubtitles}
+
+// BucketResolver 按内容类别选择存储桶，未单独配置的类别使用默认存储桶
+type BucketResolver struct {
+	defaultBucket string
+	buckets       map[ContentClass]string
+}
+
+// NewBucketResolver 创建存储桶选择器，buckets中为空的类别使用defaultBucket
+func NewBucketResolver(defaultBucket string, buckets map[ContentClass]string) *BucketResolver {
+	resolver := &BucketResolver{
+		defaultBucket: defaultBucket,
+		buckets:       make(map[ContentClass]string),
+	}
+	for class, bucket := range buckets {
+		if bucket != "" {
+			resolver.buckets[class] = bucket
+		}
+	}
+	return resolver
+}
+
+// Bucket 获取内容类别对应的存储桶
+func (r *BucketResolver) Bucket(class ContentClass) string {
+	if bucket, exists := r.buckets[class]; exists {
+		return bucket
+	}
+	return r.defaultBucket
+}
+
+// Buckets 获取所有内容类别使用的存储桶，已去重并排序
+func (r *BucketResolver) Buckets() []string {
+	seen := make(map[string]bool)
+	var buckets []string
+	for _, class := range ContentClasses {
+		bucket := r.Bucket(class)
+		if !seen[bucket] {
+			seen[bucket] = true
+			buckets = append(buckets, bucket)
+		}
+	}
+	sort.Strings(buckets)
+	return buckets
+}
@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketResolver(t *testing.T) {
+	resolver := NewBucketResolver("zhulong-videos", map[ContentClass]string{
+		ContentThumbnails: "zhulong-images",
+		ContentRenditions: "zhulong-hls",
+		ContentSubtitles:  "",
+	})
+
+	assert.Equal(t, "zhulong-videos", resolver.Bucket(ContentVideos), "未配置的类别应该使用默认存储桶")
+	assert.Equal(t, "zhulong-images", resolver.Bucket(ContentThumbnails))
+	assert.Equal(t, "zhulong-hls", resolver.Bucket(ContentRenditions))
+	assert.Equal(t, "zhulong-videos", resolver.Bucket(ContentSubtitles), "配置为空的类别应该使用默认存储桶")
+	assert.Equal(t, []string{"zhulong-hls", "zhulong-images", "zhulong-videos"}, resolver.Buckets())
+
+	single := NewBucketResolver("zhulong-videos", nil)
+	for _, class := range ContentClasses {
+		assert.Equal(t, "zhulong-videos", single.Bucket(class))
+	}
+	assert.Equal(t, []string{"zhulong-videos"}, single.Buckets())
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 内置存储驱动名称
+const (
+	DriverMinIO  = "minio"  // MinIO或其他S3兼容服务
+	DriverS3     = "s3"     // AWS S3
+	DriverLocal  = "local"  // 本地文件系统
+	DriverMemory = "memory" // 进程内存，用于测试和演示模式
+)
+
+// defaultS3Endpoint AWS S3默认端点
+const defaultS3Endpoint = "s3.amazonaws.com"
+
+// DriverConfig 存储驱动配置，Driver为空时使用MinIO
+type DriverConfig struct {
+	Driver string        // 驱动名称
+	MinIO  *MinIOConfig  // MinIO驱动配置
+	S3     *MinIOConfig  // S3驱动配置，通过S3兼容客户端访问
+	Local  *LocalConfig  // 本地文件系统驱动配置
+	Memory *MemoryConfig // 内存驱动配置
+
+	Timeouts   Timeouts          // 各类存储操作的超时时间，全部为0时不限制
+	Resilience ResilienceOptions // 临时错误的重试和熔断，不重试也不熔断时不包装
+}
+
+// DriverFactory 存储驱动构造函数
+type DriverFactory func(cfg *DriverConfig) (StorageInterface, error)
+
+var (
+	drivers = map[string]DriverFactory{
+		DriverMinIO:  newMinIODriver,
+		DriverS3:     newS3Driver,
+		DriverLocal:  newLocalDriver,
+		DriverMemory: newMemoryDriver,
+	}
+	driversMutex sync.RWMutex
+)
+
+// RegisterDriver 注册存储驱动，同名驱动会被覆盖
+func RegisterDriver(name string, factory DriverFactory) {
+	driversMutex.Lock()
+	defer driversMutex.Unlock()
+
+	drivers[strings.ToLower(name)] = factory
+}
+
+// Drivers 获取已注册的驱动名称
+func Drivers() []string {
+	driversMutex.RLock()
+	defer driversMutex.RUnlock()
+
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewFromConfig 根据配置中的驱动名称创建存储服务，配置了超时时间、重试或熔断时返回包装后的存储服务
+func NewFromConfig(cfg *DriverConfig) (StorageInterface, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("配置不能为空")
+	}
+
+	name := strings.ToLower(strings.TrimSpace(cfg.Driver))
+	if name == "" {
+		name = DriverMinIO
+	}
+
+	driversMutex.RLock()
+	factory, exists := drivers[name]
+	driversMutex.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("不支持的存储驱动: %s（可用: %s）", cfg.Driver, strings.Join(Drivers(), ", "))
+	}
+
+	storage, err := factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("创建%s存储驱动失败: %w", name, err)
+	}
+	// 每次重试单独计算超时时间
+	return WithResilience(WithTimeouts(storage, cfg.Timeouts), cfg.Resilience), nil
+}
+
+// newMinIODriver 创建MinIO存储驱动
+func newMinIODriver(cfg *DriverConfig) (StorageInterface, error) {
+	return NewMinIOStorage(cfg.MinIO)
+}
+
+// newS3Driver 创建AWS S3存储驱动，未配置端点时使用AWS默认端点并强制启用SSL
+func newS3Driver(cfg *DriverConfig) (StorageInterface, error) {
+	if cfg.S3 == nil {
+		return nil, fmt.Errorf("S3配置不能为空")
+	}
+
+	s3Config := *cfg.S3
+	if s3Config.Endpoint == "" {
+		s3Config.Endpoint = defaultS3Endpoint
+		s3Config.UseSSL = true
+	}
+	return NewMinIOStorage(&s3Config)
+}
+
+// newLocalDriver 创建本地文件系统存储驱动
+func newLocalDriver(cfg *DriverConfig) (StorageInterface, error) {
+	return NewLocalStorage(cfg.Local)
+}
+
+// newMemoryDriver 创建内存存储驱动，每次调用得到独立的空存储
+func newMemoryDriver(cfg *DriverConfig) (StorageInterface, error) {
+	return NewMemoryStorage(cfg.Memory)
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewFromConfig 测试根据驱动名称创建存储服务
+func TestNewFromConfig(t *testing.T) {
+	minioConfig := &MinIOConfig{
+		Endpoint:  "localhost:9000",
+		AccessKey: "admin",
+		SecretKey: "admin123456",
+		Region:    "us-east-1",
+	}
+
+	t.Run("默认使用MinIO驱动", func(t *testing.T) {
+		storage, err := NewFromConfig(&DriverConfig{MinIO: minioConfig})
+		require.NoError(t, err)
+		assert.IsType(t, &MinIOStorage{}, storage)
+	})
+
+	t.Run("S3驱动使用AWS默认端点", func(t *testing.T) {
+		storage, err := NewFromConfig(&DriverConfig{
+			Driver: "S3",
+			S3:     &MinIOConfig{AccessKey: "key", SecretKey: "secret", Region: "ap-east-1"},
+		})
+		require.NoError(t, err)
+
+		s3Storage, ok := storage.(*MinIOStorage)
+		require.True(t, ok)
+		assert.Equal(t, defaultS3Endpoint, s3Storage.config.GetEndpoint())
+		assert.True(t, s3Storage.config.IsSSLEnabled(), "AWS默认端点应该启用SSL")
+	})
+
+	t.Run("本地驱动", func(t *testing.T) {
+		storage, err := NewFromConfig(&DriverConfig{
+			Driver: DriverLocal,
+			Local:  &LocalConfig{RootDir: t.TempDir()},
+		})
+		require.NoError(t, err)
+		assert.IsType(t, &LocalStorage{}, storage)
+	})
+
+	t.Run("内存驱动", func(t *testing.T) {
+		storage, err := NewFromConfig(&DriverConfig{Driver: DriverMemory})
+		require.NoError(t, err, "内存驱动不需要配置")
+		assert.IsType(t, &MemoryStorage{}, storage)
+	})
+
+	t.Run("配置超时时间时包装存储服务", func(t *testing.T) {
+		storage, err := NewFromConfig(&DriverConfig{
+			Driver:   DriverLocal,
+			Local:    &LocalConfig{RootDir: t.TempDir()},
+			Timeouts: Timeouts{Metadata: time.Second},
+		})
+		require.NoError(t, err)
+		require.IsType(t, &TimeoutStorage{}, storage)
+		assert.IsType(t, &LocalStorage{}, Underlying(storage))
+	})
+
+	t.Run("驱动配置缺失", func(t *testing.T) {
+		_, err := NewFromConfig(&DriverConfig{Driver: DriverLocal})
+		assert.Error(t, err, "缺少本地驱动配置时应该返回错误")
+
+		_, err = NewFromConfig(nil)
+		assert.Error(t, err, "空配置应该返回错误")
+	})
+
+	t.Run("未知驱动", func(t *testing.T) {
+		_, err := NewFromConfig(&DriverConfig{Driver: "ftp"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "不支持的存储驱动", "错误信息应该包含驱动名称提示")
+	})
+}
+
+// TestRegisterDriver 测试注册自定义驱动
+func TestRegisterDriver(t *testing.T) {
+	custom := &LocalStorage{}
+	RegisterDriver("Custom", func(cfg *DriverConfig) (StorageInterface, error) {
+		return custom, nil
+	})
+	defer func() {
+		driversMutex.Lock()
+		delete(drivers, "custom")
+		driversMutex.Unlock()
+	}()
+
+	assert.Contains(t, Drivers(), "custom", "注册后应该出现在驱动列表中")
+
+	storage, err := NewFromConfig(&DriverConfig{Driver: "custom"})
+	require.NoError(t, err)
+	assert.Same(t, custom, storage)
+}
@@ -2,9 +2,14 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"io"
 	"time"
 )
 
+// ErrSameObject 复制或移动文件时源文件和目标文件相同
+var ErrSameObject = errors.New("源文件和目标文件相同")
+
 // StorageInterface 存储服务接口
 type StorageInterface interface {
 	// 连接测试
@@ -16,12 +21,35 @@ type StorageInterface interface {
 	RemoveBucket(ctx context.Context, bucketName string) error
 
 	// 文件操作
-	UploadFile(ctx context.Context, bucketName, objectName string, data []byte, contentType string) (*UploadResult, error)
+	// UploadFile和UploadStream可选传入用户元数据和对象标签，多个选项按顺序合并
+	UploadFile(ctx context.Context, bucketName, objectName string, data []byte, contentType string, opts ...ObjectOptions) (*UploadResult, error)
+	UploadStream(ctx context.Context, bucketName, objectName string, reader io.Reader, size int64, contentType string, opts ...ObjectOptions) (*UploadResult, error)
 	DownloadFile(ctx context.Context, bucketName, objectName string) ([]byte, error)
+	OpenFile(ctx context.Context, bucketName, objectName string) (io.ReadCloser, error)
+	OpenFileRange(ctx context.Context, bucketName, objectName string, offset, length int64) (io.ReadCloser, error)
 	FileExists(ctx context.Context, bucketName, objectName string) (bool, error)
 	GetFileInfo(ctx context.Context, bucketName, objectName string) (*FileInfo, error)
 	DeleteFile(ctx context.Context, bucketName, objectName string) error
+	// DeleteFiles 批量删除文件，返回删除失败的对象及原因，对象不存在视为删除成功；整个请求失败时返回错误
+	DeleteFiles(ctx context.Context, bucketName string, objectNames []string) (map[string]error, error)
 	ListFiles(ctx context.Context, bucketName, prefix string) ([]*FileInfo, error)
+	// CopyFile 在存储服务端复制文件，不经过应用下载和上传，保留内容类型、用户元数据和对象标签；目标文件已存在时覆盖
+	CopyFile(ctx context.Context, srcBucket, srcObject, dstBucket, dstObject string) error
+	// MoveFile 复制文件后删除源文件；复制成功但删除源文件失败时返回错误，目标文件保留
+	MoveFile(ctx context.Context, srcBucket, srcObject, dstBucket, dstObject string) error
+
+	// 对象标签，可用于存储桶生命周期规则和审计
+	GetObjectTags(ctx context.Context, bucketName, objectName string) (map[string]string, error)
+	// SetObjectTags 替换对象的全部标签
+	SetObjectTags(ctx context.Context, bucketName, objectName string, tags map[string]string) error
+
+	// 分片上传
+	InitiateMultipartUpload(ctx context.Context, bucketName, objectName, contentType string, opts ...ObjectOptions) (string, error)
+	UploadPart(ctx context.Context, bucketName, objectName, uploadID string, partNumber int, reader io.Reader, size int64) (*PartInfo, error)
+	CompleteMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string, parts []CompletePart) (*UploadResult, error)
+	// ListParts 列出分片上传中已上传的分片，按分片号升序
+	ListParts(ctx context.Context, bucketName, objectName, uploadID string) ([]*PartInfo, error)
+	AbortMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string) error
 
 	// URL生成
 	GetPresignedURL(ctx context.Context, bucketName, objectName string, expiry time.Duration) (string, error)
@@ -0,0 +1,761 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// localMultipartDir 分片上传临时目录，以"."开头不会与存储桶重名
+	localMultipartDir = ".multipart"
+	// localTempDir 写入中的临时文件目录
+	localTempDir = ".tmp"
+	// localMultipartTargetFile 记录分片上传目标对象的文件
+	localMultipartTargetFile = "target"
+	// maxLocalPartNumber 最大分片号，与S3保持一致
+	maxLocalPartNumber = 10000
+)
+
+// 本地存储相关错误
+var (
+	ErrObjectNotFound      = errors.New("文件不存在")
+	ErrPresignedURLInvalid = errors.New("预签名URL无效")
+	ErrPresignedURLExpired = errors.New("预签名URL已过期")
+)
+
+// bucketNamePattern 存储桶名格式，与S3命名规则保持一致
+var bucketNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// LocalConfig 本地文件系统存储配置
+type LocalConfig struct {
+	RootDir    string // 存储根目录，每个存储桶对应一个子目录
+	BaseURL    string // 预签名URL前缀，如 http://localhost:8080/storage
+	SigningKey string // 预签名URL签名密钥，为空时随机生成（重启后已签发的URL失效）
+	// PublicEndpoint 预签名URL的对外访问地址，替换BaseURL的协议和主机，签名不包含主机
+	PublicEndpoint *PublicEndpoint
+}
+
+// LocalStorage 本地文件系统存储服务
+// 适用于单机部署和开发环境，预签名URL由应用自身的/storage路由校验并提供访问
+type LocalStorage struct {
+	rootDir string
+	signer  *urlSigner
+}
+
+// 确保LocalStorage实现了StorageInterface接口
+var _ StorageInterface = (*LocalStorage)(nil)
+
+// NewLocalStorage 创建本地文件系统存储服务实例
+func NewLocalStorage(config *LocalConfig) (*LocalStorage, error) {
+	if config == nil {
+		return nil, fmt.Errorf("配置不能为空")
+	}
+	if config.RootDir == "" {
+		return nil, fmt.Errorf("存储根目录不能为空")
+	}
+
+	rootDir, err := filepath.Abs(config.RootDir)
+	if err != nil {
+		return nil, fmt.Errorf("解析存储根目录失败: %w", err)
+	}
+	if err := os.MkdirAll(rootDir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建存储根目录失败: %w", err)
+	}
+
+	signer, err := newURLSigner(config.BaseURL, config.SigningKey, config.PublicEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalStorage{
+		rootDir: rootDir,
+		signer:  signer,
+	}, nil
+}
+
+// RootDir 获取存储根目录
+func (s *LocalStorage) RootDir() string {
+	return s.rootDir
+}
+
+// TestConnection 测试存储根目录是否可用
+func (s *LocalStorage) TestConnection(ctx context.Context) error {
+	info, err := os.Stat(s.rootDir)
+	if err != nil {
+		return fmt.Errorf("本地存储连接测试失败: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("本地存储连接测试失败: %s不是目录", s.rootDir)
+	}
+	return nil
+}
+
+// BucketExists 检查存储桶是否存在
+func (s *LocalStorage) BucketExists(ctx context.Context, bucketName string) (bool, error) {
+	bucketPath, err := s.bucketPath(bucketName)
+	if err != nil {
+		return false, err
+	}
+
+	info, err := os.Stat(bucketPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("检查存储桶存在性失败: %w", err)
+	}
+	return info.IsDir(), nil
+}
+
+// CreateBucket 创建存储桶
+func (s *LocalStorage) CreateBucket(ctx context.Context, bucketName string) error {
+	bucketPath, err := s.bucketPath(bucketName)
+	if err != nil {
+		return err
+	}
+	if err := os.Mkdir(bucketPath, 0o755); err != nil {
+		return fmt.Errorf("创建存储桶失败: %w", err)
+	}
+	return nil
+}
+
+// RemoveBucket 删除存储桶，存储桶非空时失败
+func (s *LocalStorage) RemoveBucket(ctx context.Context, bucketName string) error {
+	bucketPath, err := s.bucketPath(bucketName)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(bucketPath); err != nil {
+		return fmt.Errorf("删除存储桶失败: %w", err)
+	}
+	return nil
+}
+
+// UploadFile 上传文件
+func (s *LocalStorage) UploadFile(ctx context.Context, bucketName, objectName string, data []byte, contentType string, opts ...ObjectOptions) (*UploadResult, error) {
+	return s.UploadStream(ctx, bucketName, objectName, bytes.NewReader(data), int64(len(data)), contentType, opts...)
+}
+
+// UploadStream 流式上传文件，size未知时传-1
+// 内容类型根据扩展名推断，不单独保存；本地文件系统不保存用户元数据和对象标签
+func (s *LocalStorage) UploadStream(ctx context.Context, bucketName, objectName string, reader io.Reader, size int64, contentType string, opts ...ObjectOptions) (*UploadResult, error) {
+	if reader == nil {
+		return nil, fmt.Errorf("文件读取器不能为空")
+	}
+
+	objectPath, err := s.ObjectPath(bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.writeFile(ctx, objectPath, reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("上传文件失败: %w", err)
+	}
+	return result, nil
+}
+
+// DownloadFile 下载文件
+func (s *LocalStorage) DownloadFile(ctx context.Context, bucketName, objectName string) ([]byte, error) {
+	objectPath, err := s.ObjectPath(bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(objectPath)
+	if err != nil {
+		return nil, fmt.Errorf("获取文件失败: %w", wrapNotFound(err))
+	}
+	return data, nil
+}
+
+// OpenFile 打开文件读取流，调用方负责关闭
+func (s *LocalStorage) OpenFile(ctx context.Context, bucketName, objectName string) (io.ReadCloser, error) {
+	objectPath, err := s.ObjectPath(bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(objectPath)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %w", wrapNotFound(err))
+	}
+	return file, nil
+}
+
+// OpenFileRange 打开文件指定字节范围的读取流，length小于0时读取到文件末尾
+func (s *LocalStorage) OpenFileRange(ctx context.Context, bucketName, objectName string, offset, length int64) (io.ReadCloser, error) {
+	if offset < 0 || length == 0 {
+		return nil, fmt.Errorf("无效的读取范围: offset=%d, length=%d", offset, length)
+	}
+
+	reader, err := s.OpenFile(ctx, bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	file := reader.(*os.File)
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("定位文件失败: %w", err)
+	}
+	if length < 0 {
+		return file, nil
+	}
+	return &limitedReadCloser{Reader: io.LimitReader(file, length), Closer: file}, nil
+}
+
+// limitedReadCloser 限制读取长度并保留底层关闭方法
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// FileExists 检查文件是否存在
+func (s *LocalStorage) FileExists(ctx context.Context, bucketName, objectName string) (bool, error) {
+	objectPath, err := s.ObjectPath(bucketName, objectName)
+	if err != nil {
+		return false, err
+	}
+
+	info, err := os.Stat(objectPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("检查文件存在性失败: %w", err)
+	}
+	return info.Mode().IsRegular(), nil
+}
+
+// GetFileInfo 获取文件信息
+func (s *LocalStorage) GetFileInfo(ctx context.Context, bucketName, objectName string) (*FileInfo, error) {
+	objectPath, err := s.ObjectPath(bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(objectPath)
+	if err != nil {
+		return nil, fmt.Errorf("获取文件信息失败: %w", wrapNotFound(err))
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("获取文件信息失败: %w", ErrObjectNotFound)
+	}
+
+	fileInfo := newLocalFileInfo(objectName, info)
+	fileInfo.ContentType = contentTypeByName(objectName)
+	return fileInfo, nil
+}
+
+// DeleteFile 删除文件，文件不存在时视为成功，并清理因此变空的目录
+func (s *LocalStorage) DeleteFile(ctx context.Context, bucketName, objectName string) error {
+	objectPath, err := s.ObjectPath(bucketName, objectName)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(objectPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("删除文件失败: %w", err)
+	}
+
+	bucketPath, _ := s.bucketPath(bucketName)
+	s.removeEmptyDirs(filepath.Dir(objectPath), bucketPath)
+	return nil
+}
+
+// CopyFile 复制文件，先写入临时文件再原子替换目标文件
+func (s *LocalStorage) CopyFile(ctx context.Context, srcBucket, srcObject, dstBucket, dstObject string) error {
+	srcPath, dstPath, err := s.copyPaths(srcBucket, srcObject, dstBucket, dstObject)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("复制文件失败: %w", wrapNotFound(err))
+	}
+	defer file.Close()
+
+	if _, err := s.writeFile(ctx, dstPath, file, -1); err != nil {
+		return fmt.Errorf("复制文件失败: %w", err)
+	}
+	return nil
+}
+
+// MoveFile 移动文件，源文件和目标文件位于同一文件系统，直接重命名
+func (s *LocalStorage) MoveFile(ctx context.Context, srcBucket, srcObject, dstBucket, dstObject string) error {
+	srcPath, dstPath, err := s.copyPaths(srcBucket, srcObject, dstBucket, dstObject)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return fmt.Errorf("移动文件失败: %w", wrapNotFound(err))
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("移动文件失败: %w", ErrObjectNotFound)
+	}
+	if err := s.moveIntoPlace(srcPath, dstPath); err != nil {
+		return fmt.Errorf("移动文件失败: %w", err)
+	}
+
+	bucketPath, _ := s.bucketPath(srcBucket)
+	s.removeEmptyDirs(filepath.Dir(srcPath), bucketPath)
+	return nil
+}
+
+// DeleteFiles 批量删除文件，本地文件系统没有批量接口，逐个删除
+func (s *LocalStorage) DeleteFiles(ctx context.Context, bucketName string, objectNames []string) (map[string]error, error) {
+	if _, err := s.bucketPath(bucketName); err != nil {
+		return nil, err
+	}
+
+	failures := make(map[string]error)
+	for _, objectName := range objectNames {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := s.DeleteFile(ctx, bucketName, objectName); err != nil {
+			failures[objectName] = err
+		}
+	}
+	return failures, nil
+}
+
+// ListFiles 按前缀列出文件，结果按对象名排序
+func (s *LocalStorage) ListFiles(ctx context.Context, bucketName, prefix string) ([]*FileInfo, error) {
+	bucketPath, err := s.bucketPath(bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*FileInfo
+	err = filepath.WalkDir(bucketPath, func(filePath string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		relative, err := filepath.Rel(bucketPath, filePath)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(relative)
+
+		if entry.IsDir() {
+			// 跳过与前缀无关的目录
+			if key != "." && !strings.HasPrefix(key+"/", prefix) && !strings.HasPrefix(prefix, key+"/") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, newLocalFileInfo(key, info))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("列出文件失败: %w", wrapNotFound(err))
+	}
+
+	return files, nil
+}
+
+// GetObjectTags 获取对象标签，本地文件系统不保存对象标签，文件存在时返回空标签
+func (s *LocalStorage) GetObjectTags(ctx context.Context, bucketName, objectName string) (map[string]string, error) {
+	if _, err := s.GetFileInfo(ctx, bucketName, objectName); err != nil {
+		return nil, fmt.Errorf("获取对象标签失败: %w", err)
+	}
+	return map[string]string{}, nil
+}
+
+// SetObjectTags 设置对象标签，本地文件系统不保存对象标签，只检查文件是否存在
+func (s *LocalStorage) SetObjectTags(ctx context.Context, bucketName, objectName string, tags map[string]string) error {
+	if _, err := s.GetFileInfo(ctx, bucketName, objectName); err != nil {
+		return fmt.Errorf("设置对象标签失败: %w", err)
+	}
+	return nil
+}
+
+// InitiateMultipartUpload 初始化分片上传，返回上传ID
+func (s *LocalStorage) InitiateMultipartUpload(ctx context.Context, bucketName, objectName, contentType string, opts ...ObjectOptions) (string, error) {
+	if _, err := s.ObjectPath(bucketName, objectName); err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("初始化分片上传失败: %w", err)
+	}
+	uploadID := hex.EncodeToString(buf)
+
+	uploadDir := s.multipartDir(uploadID)
+	if err := os.MkdirAll(uploadDir, 0o755); err != nil {
+		return "", fmt.Errorf("初始化分片上传失败: %w", err)
+	}
+
+	target := bucketName + "\n" + objectName
+	if err := os.WriteFile(filepath.Join(uploadDir, localMultipartTargetFile), []byte(target), 0o644); err != nil {
+		os.RemoveAll(uploadDir)
+		return "", fmt.Errorf("初始化分片上传失败: %w", err)
+	}
+
+	return uploadID, nil
+}
+
+// UploadPart 上传分片
+func (s *LocalStorage) UploadPart(ctx context.Context, bucketName, objectName, uploadID string, partNumber int, reader io.Reader, size int64) (*PartInfo, error) {
+	if reader == nil {
+		return nil, fmt.Errorf("分片读取器不能为空")
+	}
+	if partNumber < 1 || partNumber > maxLocalPartNumber {
+		return nil, fmt.Errorf("分片号必须在1-%d范围内", maxLocalPartNumber)
+	}
+
+	uploadDir, err := s.checkMultipartUpload(bucketName, objectName, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.writeFile(ctx, filepath.Join(uploadDir, localPartName(partNumber)), reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("上传分片失败: %w", err)
+	}
+
+	return &PartInfo{
+		PartNumber: partNumber,
+		ETag:       result.ETag,
+		Size:       result.Size,
+	}, nil
+}
+
+// CompleteMultipartUpload 完成分片上传，按给定顺序合并分片并校验ETag
+func (s *LocalStorage) CompleteMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string, parts []CompletePart) (*UploadResult, error) {
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("完成分片上传失败: 分片列表不能为空")
+	}
+
+	uploadDir, err := s.checkMultipartUpload(bucketName, objectName, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	objectPath, err := s.ObjectPath(bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	tempFile, err := s.createTempFile()
+	if err != nil {
+		return nil, fmt.Errorf("完成分片上传失败: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	var size int64
+	partHashes := md5.New()
+	for _, part := range parts {
+		written, etag, err := appendLocalPart(tempFile, filepath.Join(uploadDir, localPartName(part.PartNumber)))
+		if err != nil {
+			tempFile.Close()
+			return nil, fmt.Errorf("完成分片上传失败: 分片%d: %w", part.PartNumber, err)
+		}
+		if strings.Trim(part.ETag, `"`) != hex.EncodeToString(etag) {
+			tempFile.Close()
+			return nil, fmt.Errorf("完成分片上传失败: 分片%d的ETag不匹配", part.PartNumber)
+		}
+		partHashes.Write(etag)
+		size += written
+	}
+
+	if err := tempFile.Close(); err != nil {
+		return nil, fmt.Errorf("完成分片上传失败: %w", err)
+	}
+	if err := s.moveIntoPlace(tempFile.Name(), objectPath); err != nil {
+		return nil, fmt.Errorf("完成分片上传失败: %w", err)
+	}
+	os.RemoveAll(uploadDir)
+
+	return &UploadResult{
+		// 与S3一致，合并后的ETag为各分片MD5的MD5加分片数
+		ETag: fmt.Sprintf("%s-%d", hex.EncodeToString(partHashes.Sum(nil)), len(parts)),
+		Size: size,
+	}, nil
+}
+
+// ListParts 列出分片上传中已上传的分片，按分片号升序
+func (s *LocalStorage) ListParts(ctx context.Context, bucketName, objectName, uploadID string) ([]*PartInfo, error) {
+	uploadDir, err := s.checkMultipartUpload(bucketName, objectName, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(uploadDir)
+	if err != nil {
+		return nil, fmt.Errorf("列出分片失败: %w", err)
+	}
+
+	// 目录项按文件名排序，分片文件名补零后与分片号顺序一致
+	var parts []*PartInfo
+	for _, entry := range entries {
+		partNumber, err := strconv.Atoi(entry.Name())
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		size, etag, err := appendLocalPart(io.Discard, filepath.Join(uploadDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("列出分片失败: 分片%d: %w", partNumber, err)
+		}
+		parts = append(parts, &PartInfo{
+			PartNumber: partNumber,
+			ETag:       hex.EncodeToString(etag),
+			Size:       size,
+		})
+	}
+	return parts, nil
+}
+
+// AbortMultipartUpload 中止分片上传并清理已上传的分片
+func (s *LocalStorage) AbortMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string) error {
+	uploadDir, err := s.checkMultipartUpload(bucketName, objectName, uploadID)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(uploadDir); err != nil {
+		return fmt.Errorf("中止分片上传失败: %w", err)
+	}
+	return nil
+}
+
+// GetPresignedURL 生成预签名下载URL
+func (s *LocalStorage) GetPresignedURL(ctx context.Context, bucketName, objectName string, expiry time.Duration) (string, error) {
+	return s.GeneratePresignedURL(ctx, bucketName, objectName, expiry, "GET")
+}
+
+// GeneratePresignedURL 生成预签名URL，支持GET、HEAD和PUT
+func (s *LocalStorage) GeneratePresignedURL(ctx context.Context, bucketName, objectName string, expiry time.Duration, method string) (string, error) {
+	if _, err := s.ObjectPath(bucketName, objectName); err != nil {
+		return "", err
+	}
+	return s.signer.presign(bucketName, objectName, expiry, method)
+}
+
+// VerifyPresignedURL 校验预签名URL参数，HEAD请求可以使用GET签名
+func (s *LocalStorage) VerifyPresignedURL(method, bucketName, objectName, expires, signature string) error {
+	return s.signer.verify(method, bucketName, objectName, expires, signature)
+}
+
+// ObjectPath 获取对象在本地文件系统中的路径，拒绝越出存储桶目录的对象名
+func (s *LocalStorage) ObjectPath(bucketName, objectName string) (string, error) {
+	bucketPath, err := s.bucketPath(bucketName)
+	if err != nil {
+		return "", err
+	}
+	if !validObjectName(objectName) {
+		return "", fmt.Errorf("无效的对象名: %s", objectName)
+	}
+	return filepath.Join(bucketPath, filepath.FromSlash(objectName)), nil
+}
+
+// validObjectName 对象名是否为不越出存储桶的规范相对路径
+func validObjectName(objectName string) bool {
+	return objectName != "" && !strings.HasPrefix(objectName, "/") && !strings.HasSuffix(objectName, "/") &&
+		path.Clean(objectName) == objectName && !strings.HasPrefix(objectName, "../") && objectName != ".."
+}
+
+// ObjectName 获取存储桶目录中的本地文件对应的对象名，文件不在存储桶目录内时返回错误
+func (s *LocalStorage) ObjectName(bucketName, filePath string) (string, error) {
+	bucketPath, err := s.bucketPath(bucketName)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(filePath) {
+		return "", fmt.Errorf("文件路径必须是绝对路径: %s", filePath)
+	}
+	relPath, err := filepath.Rel(bucketPath, filePath)
+	if err != nil {
+		return "", fmt.Errorf("文件不在存储桶目录内: %s", filePath)
+	}
+
+	// ObjectPath拒绝以..开头或指向存储桶目录本身的对象名
+	objectName := filepath.ToSlash(relPath)
+	if _, err := s.ObjectPath(bucketName, objectName); err != nil || objectName == "." {
+		return "", fmt.Errorf("文件不在存储桶目录内: %s", filePath)
+	}
+	return objectName, nil
+}
+
+// bucketPath 获取存储桶目录
+func (s *LocalStorage) bucketPath(bucketName string) (string, error) {
+	if !bucketNamePattern.MatchString(bucketName) {
+		return "", fmt.Errorf("无效的存储桶名: %s", bucketName)
+	}
+	return filepath.Join(s.rootDir, bucketName), nil
+}
+
+// multipartDir 获取分片上传目录
+func (s *LocalStorage) multipartDir(uploadID string) string {
+	return filepath.Join(s.rootDir, localMultipartDir, uploadID)
+}
+
+// checkMultipartUpload 检查分片上传是否存在且目标对象一致
+func (s *LocalStorage) checkMultipartUpload(bucketName, objectName, uploadID string) (string, error) {
+	if _, err := hex.DecodeString(uploadID); err != nil || uploadID == "" {
+		return "", fmt.Errorf("分片上传不存在: %s", uploadID)
+	}
+
+	uploadDir := s.multipartDir(uploadID)
+	target, err := os.ReadFile(filepath.Join(uploadDir, localMultipartTargetFile))
+	if err != nil {
+		return "", fmt.Errorf("分片上传不存在: %s", uploadID)
+	}
+	if string(target) != bucketName+"\n"+objectName {
+		return "", fmt.Errorf("分片上传与目标对象不匹配: %s", uploadID)
+	}
+	return uploadDir, nil
+}
+
+// writeFile 将数据写入临时文件后原子替换目标文件，返回大小和MD5 ETag
+func (s *LocalStorage) writeFile(ctx context.Context, targetPath string, reader io.Reader, size int64) (*UploadResult, error) {
+	tempFile, err := s.createTempFile()
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tempFile.Name())
+
+	hash := md5.New()
+	written, err := io.Copy(io.MultiWriter(tempFile, hash), reader)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if size >= 0 && written != size {
+		return nil, fmt.Errorf("文件大小不一致: 期望%d字节，实际%d字节", size, written)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.moveIntoPlace(tempFile.Name(), targetPath); err != nil {
+		return nil, err
+	}
+
+	return &UploadResult{
+		ETag: hex.EncodeToString(hash.Sum(nil)),
+		Size: written,
+	}, nil
+}
+
+// copyPaths 获取复制或移动的源文件和目标文件路径
+func (s *LocalStorage) copyPaths(srcBucket, srcObject, dstBucket, dstObject string) (string, string, error) {
+	srcPath, err := s.ObjectPath(srcBucket, srcObject)
+	if err != nil {
+		return "", "", err
+	}
+	dstPath, err := s.ObjectPath(dstBucket, dstObject)
+	if err != nil {
+		return "", "", err
+	}
+	if srcPath == dstPath {
+		return "", "", ErrSameObject
+	}
+	return srcPath, dstPath, nil
+}
+
+// createTempFile 在存储根目录下创建临时文件，保证与目标文件位于同一文件系统
+func (s *LocalStorage) createTempFile() (*os.File, error) {
+	tempDir := filepath.Join(s.rootDir, localTempDir)
+	if err := os.MkdirAll(tempDir, 0o755); err != nil {
+		return nil, err
+	}
+	return os.CreateTemp(tempDir, "upload-*")
+}
+
+// moveIntoPlace 将临时文件移动到目标位置，按需创建存储桶和父目录
+func (s *LocalStorage) moveIntoPlace(tempPath, targetPath string) error {
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, targetPath)
+}
+
+// removeEmptyDirs 自下而上删除空目录，直到存储桶目录为止
+func (s *LocalStorage) removeEmptyDirs(dir, bucketPath string) {
+	for dir != bucketPath && strings.HasPrefix(dir, bucketPath+string(filepath.Separator)) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// appendLocalPart 将分片追加到目标文件，返回写入字节数和分片MD5
+func appendLocalPart(dst io.Writer, partPath string) (int64, []byte, error) {
+	part, err := os.Open(partPath)
+	if err != nil {
+		return 0, nil, wrapNotFound(err)
+	}
+	defer part.Close()
+
+	hash := md5.New()
+	written, err := io.Copy(io.MultiWriter(dst, hash), part)
+	if err != nil {
+		return 0, nil, err
+	}
+	return written, hash.Sum(nil), nil
+}
+
+// localPartName 分片文件名
+func localPartName(partNumber int) string {
+	return fmt.Sprintf("%05d", partNumber)
+}
+
+// newLocalFileInfo 根据文件状态构造文件信息，ETag由修改时间和大小生成
+func newLocalFileInfo(key string, info fs.FileInfo) *FileInfo {
+	return &FileInfo{
+		Key:          key,
+		Size:         info.Size(),
+		LastModified: info.ModTime(),
+		ETag:         fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size()),
+	}
+}
+
+// contentTypeByName 根据文件扩展名推断内容类型
+func contentTypeByName(objectName string) string {
+	if contentType := mime.TypeByExtension(path.Ext(objectName)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// wrapNotFound 将文件不存在错误转换为ErrObjectNotFound
+func wrapNotFound(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %v", ErrObjectNotFound, err)
+	}
+	return err
+}
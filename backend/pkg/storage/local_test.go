@@ -0,0 +1,358 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupLocalStorage 创建使用临时目录的本地存储
+func setupLocalStorage(t *testing.T) *LocalStorage {
+	storage, err := NewLocalStorage(&LocalConfig{
+		RootDir:    t.TempDir(),
+		BaseURL:    "http://localhost:8080/storage/",
+		SigningKey: "test-signing-key",
+	})
+	require.NoError(t, err, "创建本地存储实例应该成功")
+	return storage
+}
+
+// TestLocalStorage_Creation 测试本地存储实例创建
+func TestLocalStorage_Creation(t *testing.T) {
+	_, err := NewLocalStorage(nil)
+	assert.Error(t, err, "使用空配置应该返回错误")
+
+	_, err = NewLocalStorage(&LocalConfig{})
+	assert.Error(t, err, "未配置根目录应该返回错误")
+
+	storage := setupLocalStorage(t)
+	assert.NoError(t, storage.TestConnection(context.Background()), "根目录可用时连接测试应该成功")
+}
+
+// TestLocalStorage_FileOperations 测试文件上传、读取、列出和删除
+func TestLocalStorage_FileOperations(t *testing.T) {
+	storage := setupLocalStorage(t)
+	ctx := context.Background()
+	bucket := "test-bucket"
+
+	result, err := storage.UploadStream(ctx, bucket, "videos/2025/08/a.mp4", strings.NewReader("video-a"), 7, "video/mp4")
+	require.NoError(t, err, "上传文件应该成功")
+	assert.Equal(t, int64(7), result.Size)
+	assert.Len(t, result.ETag, 32, "ETag应该为MD5十六进制字符串")
+
+	_, err = storage.UploadFile(ctx, bucket, "videos/2025/08/b.mp4", []byte("video-b"), "video/mp4")
+	require.NoError(t, err)
+	_, err = storage.UploadFile(ctx, bucket, "thumbnails/a.jpg", []byte("thumb"), "image/jpeg")
+	require.NoError(t, err)
+
+	exists, err := storage.BucketExists(ctx, bucket)
+	require.NoError(t, err)
+	assert.True(t, exists, "首次写入时应该自动创建存储桶")
+
+	data, err := storage.DownloadFile(ctx, bucket, "videos/2025/08/a.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("video-a"), data)
+
+	reader, err := storage.OpenFile(ctx, bucket, "videos/2025/08/b.mp4")
+	require.NoError(t, err)
+	data, err = io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("video-b"), data)
+
+	info, err := storage.GetFileInfo(ctx, bucket, "videos/2025/08/a.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), info.Size)
+	assert.Equal(t, "video/mp4", info.ContentType, "内容类型应该根据扩展名推断")
+
+	files, err := storage.ListFiles(ctx, bucket, "videos/")
+	require.NoError(t, err)
+	require.Len(t, files, 2, "应该只列出前缀下的文件")
+	assert.Equal(t, "videos/2025/08/a.mp4", files[0].Key)
+	assert.Equal(t, "videos/2025/08/b.mp4", files[1].Key)
+
+	require.NoError(t, storage.DeleteFile(ctx, bucket, "videos/2025/08/a.mp4"))
+	require.NoError(t, storage.DeleteFile(ctx, bucket, "videos/2025/08/a.mp4"), "删除不存在的文件应该成功")
+
+	exists, err = storage.FileExists(ctx, bucket, "videos/2025/08/a.mp4")
+	require.NoError(t, err)
+	assert.False(t, exists, "删除后文件不应该存在")
+
+	_, err = storage.GetFileInfo(ctx, bucket, "videos/2025/08/a.mp4")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+
+	// 删除最后一个文件后清理空目录
+	require.NoError(t, storage.DeleteFile(ctx, bucket, "videos/2025/08/b.mp4"))
+	files, err = storage.ListFiles(ctx, bucket, "")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "thumbnails/a.jpg", files[0].Key)
+	assert.NoDirExists(t, storage.RootDir()+"/"+bucket+"/videos", "空目录应该被清理")
+}
+
+// TestLocalStorage_DeleteFiles 测试批量删除文件
+func TestLocalStorage_DeleteFiles(t *testing.T) {
+	storage := setupLocalStorage(t)
+	ctx := context.Background()
+	bucket := "test-bucket"
+	require.NoError(t, storage.CreateBucket(ctx, bucket))
+
+	for _, name := range []string{"hls/v1/a.ts", "hls/v1/b.ts", "hls/v2/a.ts"} {
+		_, err := storage.UploadFile(ctx, bucket, name, []byte("segment"), "video/mp2t")
+		require.NoError(t, err)
+	}
+
+	failures, err := storage.DeleteFiles(ctx, bucket, []string{"hls/v1/a.ts", "hls/v1/b.ts", "hls/v1/missing.ts", "../escape"})
+	require.NoError(t, err)
+	assert.Len(t, failures, 1, "只有无效的对象名应该删除失败")
+	assert.Contains(t, failures, "../escape")
+
+	files, err := storage.ListFiles(ctx, bucket, "hls/")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "hls/v2/a.ts", files[0].Key)
+
+	_, err = storage.DeleteFiles(ctx, "Invalid_Bucket", []string{"a.ts"})
+	assert.Error(t, err, "存储桶名无效时整个请求应该失败")
+}
+
+// TestLocalStorage_OpenFileRange 测试按字节范围读取文件
+func TestLocalStorage_OpenFileRange(t *testing.T) {
+	storage := setupLocalStorage(t)
+	ctx := context.Background()
+
+	_, err := storage.UploadFile(ctx, "test-bucket", "range.mp4", []byte("0123456789"), "video/mp4")
+	require.NoError(t, err)
+
+	tests := []struct {
+		offset, length int64
+		expected       string
+	}{
+		{0, 1, "0"},
+		{2, 3, "234"},
+		{7, -1, "789"},
+		{8, 10, "89"},
+	}
+	for _, tt := range tests {
+		reader, err := storage.OpenFileRange(ctx, "test-bucket", "range.mp4", tt.offset, tt.length)
+		require.NoError(t, err, "按范围打开文件应该成功")
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, string(data), "offset=%d length=%d", tt.offset, tt.length)
+	}
+
+	_, err = storage.OpenFileRange(ctx, "test-bucket", "range.mp4", 0, 0)
+	assert.Error(t, err, "长度为0应该返回错误")
+
+	_, err = storage.OpenFileRange(ctx, "test-bucket", "missing.mp4", 0, 1)
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+}
+
+// TestLocalStorage_SizeMismatch 测试上传大小与声明不一致
+func TestLocalStorage_SizeMismatch(t *testing.T) {
+	storage := setupLocalStorage(t)
+	ctx := context.Background()
+
+	_, err := storage.UploadStream(ctx, "test-bucket", "short.mp4", strings.NewReader("abc"), 10, "video/mp4")
+	assert.Error(t, err, "大小不一致时应该上传失败")
+
+	exists, err := storage.FileExists(ctx, "test-bucket", "short.mp4")
+	require.NoError(t, err)
+	assert.False(t, exists, "上传失败时不应该留下文件")
+}
+
+// TestLocalStorage_InvalidNames 测试拒绝越界的存储桶名和对象名
+func TestLocalStorage_InvalidNames(t *testing.T) {
+	storage := setupLocalStorage(t)
+	ctx := context.Background()
+
+	invalidObjects := []string{"", "/abs.mp4", "../escape.mp4", "a/../../escape.mp4", "a//b.mp4", "dir/"}
+	for _, objectName := range invalidObjects {
+		_, err := storage.UploadFile(ctx, "test-bucket", objectName, []byte("x"), "")
+		assert.Error(t, err, "无效对象名应该被拒绝: %q", objectName)
+	}
+
+	invalidBuckets := []string{"", ".multipart", "../etc", "Upper", "a"}
+	for _, bucketName := range invalidBuckets {
+		_, err := storage.UploadFile(ctx, bucketName, "a.mp4", []byte("x"), "")
+		assert.Error(t, err, "无效存储桶名应该被拒绝: %q", bucketName)
+	}
+}
+
+// TestLocalStorage_MultipartUpload 测试分片上传
+func TestLocalStorage_MultipartUpload(t *testing.T) {
+	storage := setupLocalStorage(t)
+	ctx := context.Background()
+	bucket := "test-bucket"
+	objectName := "videos/multipart.mp4"
+
+	uploadID, err := storage.InitiateMultipartUpload(ctx, bucket, objectName, "video/mp4")
+	require.NoError(t, err, "初始化分片上传应该成功")
+
+	_, err = storage.UploadPart(ctx, bucket, "videos/other.mp4", uploadID, 1, strings.NewReader("x"), 1)
+	assert.Error(t, err, "目标对象不一致时应该拒绝上传分片")
+
+	first, err := storage.UploadPart(ctx, bucket, objectName, uploadID, 1, strings.NewReader("hello "), 6)
+	require.NoError(t, err)
+	second, err := storage.UploadPart(ctx, bucket, objectName, uploadID, 2, strings.NewReader("world"), 5)
+	require.NoError(t, err)
+
+	parts, err := storage.ListParts(ctx, bucket, objectName, uploadID)
+	require.NoError(t, err, "列出已上传分片应该成功")
+	assert.Equal(t, []*PartInfo{
+		{PartNumber: 1, ETag: first.ETag, Size: 6},
+		{PartNumber: 2, ETag: second.ETag, Size: 5},
+	}, parts)
+	_, err = storage.ListParts(ctx, bucket, "videos/other.mp4", uploadID)
+	assert.Error(t, err, "目标对象不一致时应该拒绝列出分片")
+
+	_, err = storage.CompleteMultipartUpload(ctx, bucket, objectName, uploadID, []CompletePart{
+		{PartNumber: 1, ETag: second.ETag},
+	})
+	assert.Error(t, err, "ETag不匹配时应该合并失败")
+
+	result, err := storage.CompleteMultipartUpload(ctx, bucket, objectName, uploadID, []CompletePart{
+		{PartNumber: 1, ETag: first.ETag},
+		{PartNumber: 2, ETag: `"` + second.ETag + `"`},
+	})
+	require.NoError(t, err, "完成分片上传应该成功")
+	assert.Equal(t, int64(11), result.Size)
+	assert.True(t, strings.HasSuffix(result.ETag, "-2"), "合并后的ETag应该带有分片数")
+
+	data, err := storage.DownloadFile(ctx, bucket, objectName)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello world"), data)
+
+	assert.Error(t, storage.AbortMultipartUpload(ctx, bucket, objectName, uploadID), "完成后上传ID应该失效")
+
+	// 中止分片上传
+	uploadID, err = storage.InitiateMultipartUpload(ctx, bucket, "videos/aborted.mp4", "video/mp4")
+	require.NoError(t, err)
+	_, err = storage.UploadPart(ctx, bucket, "videos/aborted.mp4", uploadID, 1, bytes.NewReader([]byte("x")), 1)
+	require.NoError(t, err)
+	require.NoError(t, storage.AbortMultipartUpload(ctx, bucket, "videos/aborted.mp4", uploadID))
+
+	exists, err := storage.FileExists(ctx, bucket, "videos/aborted.mp4")
+	require.NoError(t, err)
+	assert.False(t, exists, "中止后不应该生成文件")
+}
+
+// TestLocalStorage_ObjectTags 测试本地存储忽略用户元数据和对象标签
+func TestLocalStorage_ObjectTags(t *testing.T) {
+	storage := setupLocalStorage(t)
+	ctx := context.Background()
+	bucket := "videos"
+	require.NoError(t, storage.CreateBucket(ctx, bucket))
+
+	_, err := storage.UploadFile(ctx, bucket, "videos/tagged.mp4", []byte("video"), "video/mp4", ObjectOptions{
+		Tags: map[string]string{ObjectKeyVideoID: "video-1"},
+	})
+	require.NoError(t, err, "带标签上传应该成功")
+
+	tags, err := storage.GetObjectTags(ctx, bucket, "videos/tagged.mp4")
+	require.NoError(t, err)
+	assert.Empty(t, tags, "本地存储不保存对象标签")
+	assert.NoError(t, storage.SetObjectTags(ctx, bucket, "videos/tagged.mp4", map[string]string{ObjectKeyVideoID: "video-1"}))
+
+	_, err = storage.GetObjectTags(ctx, bucket, "videos/missing.mp4")
+	assert.ErrorIs(t, err, ErrObjectNotFound, "文件不存在时应该返回错误")
+	assert.ErrorIs(t, storage.SetObjectTags(ctx, bucket, "videos/missing.mp4", nil), ErrObjectNotFound)
+}
+
+// TestLocalStorage_CopyAndMoveFile 测试复制和移动文件
+func TestLocalStorage_CopyAndMoveFile(t *testing.T) {
+	storage := setupLocalStorage(t)
+	ctx := context.Background()
+	require.NoError(t, storage.CreateBucket(ctx, "videos"))
+
+	_, err := storage.UploadFile(ctx, "videos", "videos/2025/08/a.mp4", []byte("video"), "video/mp4")
+	require.NoError(t, err)
+
+	require.NoError(t, storage.CopyFile(ctx, "videos", "videos/2025/08/a.mp4", "trash", "videos/2025/08/a.mp4"))
+	data, err := storage.DownloadFile(ctx, "trash", "videos/2025/08/a.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("video"), data, "目标存储桶不存在时应该自动创建")
+	exists, err := storage.FileExists(ctx, "videos", "videos/2025/08/a.mp4")
+	require.NoError(t, err)
+	assert.True(t, exists, "复制后源文件应该保留")
+
+	require.NoError(t, storage.MoveFile(ctx, "videos", "videos/2025/08/a.mp4", "videos", "videos/renamed.mp4"))
+	data, err = storage.DownloadFile(ctx, "videos", "videos/renamed.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("video"), data)
+	exists, err = storage.FileExists(ctx, "videos", "videos/2025/08/a.mp4")
+	require.NoError(t, err)
+	assert.False(t, exists, "移动后源文件应该被删除")
+	_, err = os.Stat(filepath.Join(storage.RootDir(), "videos", "videos", "2025"))
+	assert.True(t, os.IsNotExist(err), "移动后应该清理变空的目录")
+
+	assert.ErrorIs(t, storage.CopyFile(ctx, "videos", "videos/missing.mp4", "videos", "videos/b.mp4"), ErrObjectNotFound)
+	assert.ErrorIs(t, storage.MoveFile(ctx, "videos", "videos/missing.mp4", "videos", "videos/b.mp4"), ErrObjectNotFound)
+	assert.ErrorIs(t, storage.MoveFile(ctx, "videos", "videos/renamed.mp4", "videos", "videos/renamed.mp4"), ErrSameObject)
+	assert.Error(t, storage.CopyFile(ctx, "videos", "videos/renamed.mp4", "videos", "../escape.mp4"), "非法对象名应该返回错误")
+}
+
+// TestLocalStorage_ObjectName 测试本地文件路径与对象名的转换
+func TestLocalStorage_ObjectName(t *testing.T) {
+	storage := setupLocalStorage(t)
+	bucketDir := filepath.Join(storage.RootDir(), "videos")
+
+	objectName, err := storage.ObjectName("videos", filepath.Join(bucketDir, "library", "a.mp4"))
+	require.NoError(t, err)
+	assert.Equal(t, "library/a.mp4", objectName)
+
+	path, err := storage.ObjectPath("videos", objectName)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(bucketDir, "library", "a.mp4"), path)
+
+	_, err = storage.ObjectName("videos", filepath.Join(storage.RootDir(), "thumbnails", "a.jpg"))
+	assert.Error(t, err, "其他存储桶中的文件应该被拒绝")
+	_, err = storage.ObjectName("videos", bucketDir)
+	assert.Error(t, err, "存储桶目录本身应该被拒绝")
+	_, err = storage.ObjectName("videos", "videos/a.mp4")
+	assert.Error(t, err, "相对路径应该被拒绝")
+}
+
+// TestLocalStorage_PresignedURL 测试预签名URL的生成和校验
+func TestLocalStorage_PresignedURL(t *testing.T) {
+	storage := setupLocalStorage(t)
+	ctx := context.Background()
+
+	rawURL, err := storage.GeneratePresignedURL(ctx, "test-bucket", "videos/测试 视频.mp4", time.Hour, "GET")
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	assert.Equal(t, "/storage/test-bucket/videos/测试 视频.mp4", parsed.Path, "对象名应该正确编码")
+
+	expires := parsed.Query().Get("expires")
+	signature := parsed.Query().Get("signature")
+	assert.NoError(t, storage.VerifyPresignedURL("GET", "test-bucket", "videos/测试 视频.mp4", expires, signature))
+	assert.NoError(t, storage.VerifyPresignedURL("HEAD", "test-bucket", "videos/测试 视频.mp4", expires, signature), "HEAD应该可以使用GET签名")
+	assert.ErrorIs(t, storage.VerifyPresignedURL("PUT", "test-bucket", "videos/测试 视频.mp4", expires, signature), ErrPresignedURLInvalid, "签名不能用于其他方法")
+	assert.ErrorIs(t, storage.VerifyPresignedURL("GET", "test-bucket", "videos/other.mp4", expires, signature), ErrPresignedURLInvalid, "签名不能用于其他对象")
+
+	expiredURL, err := storage.GeneratePresignedURL(ctx, "test-bucket", "a.mp4", -time.Minute, "PUT")
+	require.NoError(t, err)
+	parsed, err = url.Parse(expiredURL)
+	require.NoError(t, err)
+	assert.ErrorIs(t, storage.VerifyPresignedURL("PUT", "test-bucket", "a.mp4", parsed.Query().Get("expires"), parsed.Query().Get("signature")), ErrPresignedURLExpired)
+
+	_, err = storage.GeneratePresignedURL(ctx, "test-bucket", "a.mp4", time.Hour, "DELETE")
+	assert.Error(t, err, "不支持的方法应该返回错误")
+
+	noBaseURL, err := NewLocalStorage(&LocalConfig{RootDir: t.TempDir()})
+	require.NoError(t, err)
+	_, err = noBaseURL.GetPresignedURL(ctx, "test-bucket", "a.mp4", time.Hour)
+	assert.Error(t, err, "未配置访问地址时应该返回错误")
+}
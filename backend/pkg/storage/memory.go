@@ -0,0 +1,583 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryConfig 内存存储配置
+type MemoryConfig struct {
+	BaseURL    string // 预签名URL前缀，与local驱动共用应用的/storage路由
+	SigningKey string // 预签名URL签名密钥，为空时随机生成
+	// PublicEndpoint 预签名URL的对外访问地址，替换BaseURL的协议和主机，签名不包含主机
+	PublicEndpoint *PublicEndpoint
+}
+
+// MemoryStorage 进程内存存储服务
+// 数据只保存在内存中，进程退出后丢失；用于单元测试和演示模式，不依赖MinIO或本地目录
+// 与local驱动一样写入时按需创建存储桶，与S3一样保存内容类型、用户元数据和对象标签
+type MemoryStorage struct {
+	mutex   sync.RWMutex
+	buckets map[string]map[string]*memoryObject
+	uploads map[string]*memoryUpload
+	signer  *urlSigner
+}
+
+// memoryObject 内存中的对象，数据写入后不再修改，读取时可以直接共享
+type memoryObject struct {
+	data        []byte
+	contentType string
+	metadata    map[string]string
+	tags        map[string]string
+	etag        string
+	modified    time.Time
+}
+
+// memoryUpload 进行中的分片上传
+type memoryUpload struct {
+	bucketName  string
+	objectName  string
+	contentType string
+	options     ObjectOptions
+	parts       map[int]*memoryObject
+}
+
+// 确保MemoryStorage实现了StorageInterface接口
+var _ StorageInterface = (*MemoryStorage)(nil)
+
+// NewMemoryStorage 创建内存存储服务实例，config为nil时不能生成预签名URL
+func NewMemoryStorage(config *MemoryConfig) (*MemoryStorage, error) {
+	if config == nil {
+		config = &MemoryConfig{}
+	}
+	signer, err := newURLSigner(config.BaseURL, config.SigningKey, config.PublicEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MemoryStorage{
+		buckets: make(map[string]map[string]*memoryObject),
+		uploads: make(map[string]*memoryUpload),
+		signer:  signer,
+	}, nil
+}
+
+// TestConnection 内存存储始终可用
+func (s *MemoryStorage) TestConnection(ctx context.Context) error {
+	return nil
+}
+
+// BucketExists 检查存储桶是否存在
+func (s *MemoryStorage) BucketExists(ctx context.Context, bucketName string) (bool, error) {
+	if !bucketNamePattern.MatchString(bucketName) {
+		return false, fmt.Errorf("无效的存储桶名: %s", bucketName)
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	_, exists := s.buckets[bucketName]
+	return exists, nil
+}
+
+// CreateBucket 创建存储桶
+func (s *MemoryStorage) CreateBucket(ctx context.Context, bucketName string) error {
+	if !bucketNamePattern.MatchString(bucketName) {
+		return fmt.Errorf("无效的存储桶名: %s", bucketName)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.buckets[bucketName]; exists {
+		return fmt.Errorf("创建存储桶失败: 存储桶已存在: %s", bucketName)
+	}
+	s.buckets[bucketName] = make(map[string]*memoryObject)
+	return nil
+}
+
+// RemoveBucket 删除存储桶，存储桶非空时失败
+func (s *MemoryStorage) RemoveBucket(ctx context.Context, bucketName string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	objects, exists := s.buckets[bucketName]
+	if !exists {
+		return fmt.Errorf("删除存储桶失败: 存储桶不存在: %s", bucketName)
+	}
+	if len(objects) > 0 {
+		return fmt.Errorf("删除存储桶失败: 存储桶不为空: %s", bucketName)
+	}
+	delete(s.buckets, bucketName)
+	return nil
+}
+
+// UploadFile 上传文件
+func (s *MemoryStorage) UploadFile(ctx context.Context, bucketName, objectName string, data []byte, contentType string, opts ...ObjectOptions) (*UploadResult, error) {
+	return s.UploadStream(ctx, bucketName, objectName, bytes.NewReader(data), int64(len(data)), contentType, opts...)
+}
+
+// UploadStream 流式上传文件，size未知时传-1；未指定内容类型时根据扩展名推断
+func (s *MemoryStorage) UploadStream(ctx context.Context, bucketName, objectName string, reader io.Reader, size int64, contentType string, opts ...ObjectOptions) (*UploadResult, error) {
+	if reader == nil {
+		return nil, fmt.Errorf("文件读取器不能为空")
+	}
+	if !validObjectName(objectName) {
+		return nil, fmt.Errorf("无效的对象名: %s", objectName)
+	}
+
+	object, err := readMemoryObject(ctx, reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("上传文件失败: %w", err)
+	}
+	object.setOptions(objectName, contentType, mergeObjectOptions(opts))
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	objects, err := s.writableBucketLocked(bucketName)
+	if err != nil {
+		return nil, err
+	}
+	objects[objectName] = object
+	return &UploadResult{ETag: object.etag, Size: int64(len(object.data))}, nil
+}
+
+// DownloadFile 下载文件
+func (s *MemoryStorage) DownloadFile(ctx context.Context, bucketName, objectName string) ([]byte, error) {
+	object, err := s.object(bucketName, objectName)
+	if err != nil {
+		return nil, fmt.Errorf("获取文件失败: %w", err)
+	}
+	return bytes.Clone(object.data), nil
+}
+
+// OpenFile 打开文件读取流，调用方负责关闭
+func (s *MemoryStorage) OpenFile(ctx context.Context, bucketName, objectName string) (io.ReadCloser, error) {
+	return s.OpenFileRange(ctx, bucketName, objectName, 0, -1)
+}
+
+// OpenFileRange 打开文件指定字节范围的读取流，length小于0时读取到文件末尾
+func (s *MemoryStorage) OpenFileRange(ctx context.Context, bucketName, objectName string, offset, length int64) (io.ReadCloser, error) {
+	if offset < 0 || length == 0 {
+		return nil, fmt.Errorf("无效的读取范围: offset=%d, length=%d", offset, length)
+	}
+
+	object, err := s.object(bucketName, objectName)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %w", err)
+	}
+
+	size := int64(len(object.data))
+	if offset > size || (offset == size && size > 0) {
+		return nil, fmt.Errorf("无效的读取范围: offset=%d超出文件大小%d", offset, size)
+	}
+	end := size
+	if length > 0 {
+		end = min(offset+length, size)
+	}
+	return io.NopCloser(bytes.NewReader(object.data[offset:end])), nil
+}
+
+// FileExists 检查文件是否存在
+func (s *MemoryStorage) FileExists(ctx context.Context, bucketName, objectName string) (bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	_, exists := s.buckets[bucketName][objectName]
+	return exists, nil
+}
+
+// GetFileInfo 获取文件信息，包含用户元数据
+func (s *MemoryStorage) GetFileInfo(ctx context.Context, bucketName, objectName string) (*FileInfo, error) {
+	object, err := s.object(bucketName, objectName)
+	if err != nil {
+		return nil, fmt.Errorf("获取文件信息失败: %w", err)
+	}
+
+	info := object.fileInfo(objectName)
+	info.Metadata = maps.Clone(object.metadata)
+	return info, nil
+}
+
+// DeleteFile 删除文件，文件或存储桶不存在时视为成功
+func (s *MemoryStorage) DeleteFile(ctx context.Context, bucketName, objectName string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.buckets[bucketName], objectName)
+	return nil
+}
+
+// DeleteFiles 批量删除文件，对象不存在视为删除成功
+func (s *MemoryStorage) DeleteFiles(ctx context.Context, bucketName string, objectNames []string) (map[string]error, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, objectName := range objectNames {
+		delete(s.buckets[bucketName], objectName)
+	}
+	return map[string]error{}, nil
+}
+
+// ListFiles 按前缀列出文件，结果按对象名排序
+func (s *MemoryStorage) ListFiles(ctx context.Context, bucketName, prefix string) ([]*FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	objects, exists := s.buckets[bucketName]
+	if !exists {
+		return nil, fmt.Errorf("列出文件失败: 存储桶不存在: %s", bucketName)
+	}
+
+	var files []*FileInfo
+	for _, key := range slices.Sorted(maps.Keys(objects)) {
+		if strings.HasPrefix(key, prefix) {
+			files = append(files, objects[key].fileInfo(key))
+		}
+	}
+	return files, nil
+}
+
+// CopyFile 复制文件，保留内容类型、用户元数据和对象标签
+func (s *MemoryStorage) CopyFile(ctx context.Context, srcBucket, srcObject, dstBucket, dstObject string) error {
+	if err := s.copyObject(srcBucket, srcObject, dstBucket, dstObject, false); err != nil {
+		return fmt.Errorf("复制文件失败: %w", err)
+	}
+	return nil
+}
+
+// MoveFile 移动文件，复制和删除源文件在同一个锁内完成
+func (s *MemoryStorage) MoveFile(ctx context.Context, srcBucket, srcObject, dstBucket, dstObject string) error {
+	if err := s.copyObject(srcBucket, srcObject, dstBucket, dstObject, true); err != nil {
+		return fmt.Errorf("移动文件失败: %w", err)
+	}
+	return nil
+}
+
+// GetObjectTags 获取对象标签
+func (s *MemoryStorage) GetObjectTags(ctx context.Context, bucketName, objectName string) (map[string]string, error) {
+	object, err := s.object(bucketName, objectName)
+	if err != nil {
+		return nil, fmt.Errorf("获取对象标签失败: %w", err)
+	}
+	tags := maps.Clone(object.tags)
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	return tags, nil
+}
+
+// SetObjectTags 替换对象的全部标签
+func (s *MemoryStorage) SetObjectTags(ctx context.Context, bucketName, objectName string, tags map[string]string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	object, err := s.objectLocked(bucketName, objectName)
+	if err != nil {
+		return fmt.Errorf("设置对象标签失败: %w", err)
+	}
+
+	// 对象数据可能正被读取方共享，替换为新的对象而不是修改原对象
+	updated := *object
+	updated.tags = maps.Clone(tags)
+	s.buckets[bucketName][objectName] = &updated
+	return nil
+}
+
+// InitiateMultipartUpload 初始化分片上传，返回上传ID
+func (s *MemoryStorage) InitiateMultipartUpload(ctx context.Context, bucketName, objectName, contentType string, opts ...ObjectOptions) (string, error) {
+	if !validObjectName(objectName) {
+		return "", fmt.Errorf("无效的对象名: %s", objectName)
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("初始化分片上传失败: %w", err)
+	}
+	uploadID := hex.EncodeToString(buf)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !bucketNamePattern.MatchString(bucketName) {
+		return "", fmt.Errorf("无效的存储桶名: %s", bucketName)
+	}
+	s.uploads[uploadID] = &memoryUpload{
+		bucketName:  bucketName,
+		objectName:  objectName,
+		contentType: contentType,
+		options:     mergeObjectOptions(opts),
+		parts:       make(map[int]*memoryObject),
+	}
+	return uploadID, nil
+}
+
+// UploadPart 上传分片，相同分片号重复上传时覆盖
+func (s *MemoryStorage) UploadPart(ctx context.Context, bucketName, objectName, uploadID string, partNumber int, reader io.Reader, size int64) (*PartInfo, error) {
+	if reader == nil {
+		return nil, fmt.Errorf("分片读取器不能为空")
+	}
+	if partNumber < 1 || partNumber > maxLocalPartNumber {
+		return nil, fmt.Errorf("分片号必须在1-%d范围内", maxLocalPartNumber)
+	}
+
+	part, err := readMemoryObject(ctx, reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("上传分片失败: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	upload, err := s.uploadLocked(bucketName, objectName, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	upload.parts[partNumber] = part
+
+	return &PartInfo{
+		PartNumber: partNumber,
+		ETag:       part.etag,
+		Size:       int64(len(part.data)),
+	}, nil
+}
+
+// CompleteMultipartUpload 完成分片上传，按给定顺序合并分片并校验ETag
+func (s *MemoryStorage) CompleteMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string, parts []CompletePart) (*UploadResult, error) {
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("完成分片上传失败: 分片列表不能为空")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	upload, err := s.uploadLocked(bucketName, objectName, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := s.writableBucketLocked(bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	var data bytes.Buffer
+	partHashes := md5.New()
+	for _, completed := range parts {
+		part, exists := upload.parts[completed.PartNumber]
+		if !exists {
+			return nil, fmt.Errorf("完成分片上传失败: 分片%d不存在", completed.PartNumber)
+		}
+		if strings.Trim(completed.ETag, `"`) != part.etag {
+			return nil, fmt.Errorf("完成分片上传失败: 分片%d的ETag不匹配", completed.PartNumber)
+		}
+		etag, _ := hex.DecodeString(part.etag)
+		partHashes.Write(etag)
+		data.Write(part.data)
+	}
+
+	object := &memoryObject{
+		data: data.Bytes(),
+		// 与S3一致，合并后的ETag为各分片MD5的MD5加分片数
+		etag:     fmt.Sprintf("%s-%d", hex.EncodeToString(partHashes.Sum(nil)), len(parts)),
+		modified: time.Now(),
+	}
+	object.setOptions(objectName, upload.contentType, upload.options)
+	objects[objectName] = object
+	delete(s.uploads, uploadID)
+
+	return &UploadResult{ETag: object.etag, Size: int64(len(object.data))}, nil
+}
+
+// ListParts 列出分片上传中已上传的分片，按分片号升序
+func (s *MemoryStorage) ListParts(ctx context.Context, bucketName, objectName, uploadID string) ([]*PartInfo, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	upload, err := s.uploadLocked(bucketName, objectName, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []*PartInfo
+	for _, partNumber := range slices.Sorted(maps.Keys(upload.parts)) {
+		part := upload.parts[partNumber]
+		parts = append(parts, &PartInfo{
+			PartNumber: partNumber,
+			ETag:       part.etag,
+			Size:       int64(len(part.data)),
+		})
+	}
+	return parts, nil
+}
+
+// AbortMultipartUpload 中止分片上传并丢弃已上传的分片
+func (s *MemoryStorage) AbortMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := s.uploadLocked(bucketName, objectName, uploadID); err != nil {
+		return err
+	}
+	delete(s.uploads, uploadID)
+	return nil
+}
+
+// GetPresignedURL 生成预签名下载URL
+func (s *MemoryStorage) GetPresignedURL(ctx context.Context, bucketName, objectName string, expiry time.Duration) (string, error) {
+	return s.GeneratePresignedURL(ctx, bucketName, objectName, expiry, "GET")
+}
+
+// GeneratePresignedURL 生成预签名URL，支持GET、HEAD和PUT，由应用的/storage路由提供访问
+func (s *MemoryStorage) GeneratePresignedURL(ctx context.Context, bucketName, objectName string, expiry time.Duration, method string) (string, error) {
+	if !bucketNamePattern.MatchString(bucketName) {
+		return "", fmt.Errorf("无效的存储桶名: %s", bucketName)
+	}
+	if !validObjectName(objectName) {
+		return "", fmt.Errorf("无效的对象名: %s", objectName)
+	}
+	return s.signer.presign(bucketName, objectName, expiry, method)
+}
+
+// VerifyPresignedURL 校验预签名URL参数，HEAD请求可以使用GET签名
+func (s *MemoryStorage) VerifyPresignedURL(method, bucketName, objectName, expires, signature string) error {
+	return s.signer.verify(method, bucketName, objectName, expires, signature)
+}
+
+// writableBucketLocked 获取写入的存储桶，存储桶不存在时创建，调用方需要持有锁
+func (s *MemoryStorage) writableBucketLocked(bucketName string) (map[string]*memoryObject, error) {
+	objects, exists := s.buckets[bucketName]
+	if exists {
+		return objects, nil
+	}
+	if !bucketNamePattern.MatchString(bucketName) {
+		return nil, fmt.Errorf("无效的存储桶名: %s", bucketName)
+	}
+	objects = make(map[string]*memoryObject)
+	s.buckets[bucketName] = objects
+	return objects, nil
+}
+
+// object 获取对象，对象不存在时返回ErrObjectNotFound
+func (s *MemoryStorage) object(bucketName, objectName string) (*memoryObject, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.objectLocked(bucketName, objectName)
+}
+
+// objectLocked 获取对象，存储桶不存在时同样返回ErrObjectNotFound，调用方需要持有锁
+func (s *MemoryStorage) objectLocked(bucketName, objectName string) (*memoryObject, error) {
+	object, exists := s.buckets[bucketName][objectName]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s/%s", ErrObjectNotFound, bucketName, objectName)
+	}
+	return object, nil
+}
+
+// copyObject 复制对象，remove为true时删除源对象
+func (s *MemoryStorage) copyObject(srcBucket, srcObject, dstBucket, dstObject string, remove bool) error {
+	if srcBucket == dstBucket && srcObject == dstObject {
+		return ErrSameObject
+	}
+	if !validObjectName(dstObject) {
+		return fmt.Errorf("无效的对象名: %s", dstObject)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	object, err := s.objectLocked(srcBucket, srcObject)
+	if err != nil {
+		return err
+	}
+	objects, err := s.writableBucketLocked(dstBucket)
+	if err != nil {
+		return err
+	}
+
+	copied := *object
+	copied.modified = time.Now()
+	objects[dstObject] = &copied
+	if remove {
+		delete(s.buckets[srcBucket], srcObject)
+	}
+	return nil
+}
+
+// uploadLocked 获取分片上传并检查目标对象是否一致，调用方需要持有锁
+func (s *MemoryStorage) uploadLocked(bucketName, objectName, uploadID string) (*memoryUpload, error) {
+	upload, exists := s.uploads[uploadID]
+	if !exists {
+		return nil, fmt.Errorf("分片上传不存在: %s", uploadID)
+	}
+	if upload.bucketName != bucketName || upload.objectName != objectName {
+		return nil, fmt.Errorf("分片上传与目标对象不匹配: %s", uploadID)
+	}
+	return upload, nil
+}
+
+// readMemoryObject 读取全部数据并计算MD5 ETag，size不小于0时校验大小
+func readMemoryObject(ctx context.Context, reader io.Reader, size int64) (*memoryObject, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if size >= 0 && int64(len(data)) != size {
+		return nil, fmt.Errorf("文件大小不一致: 期望%d字节，实际%d字节", size, len(data))
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	hash := md5.Sum(data)
+	return &memoryObject{
+		data:     data,
+		etag:     hex.EncodeToString(hash[:]),
+		modified: time.Now(),
+	}, nil
+}
+
+// setOptions 设置内容类型、用户元数据和对象标签，用户元数据的键转换为小写
+func (o *memoryObject) setOptions(objectName, contentType string, options ObjectOptions) {
+	if contentType == "" {
+		contentType = contentTypeByName(objectName)
+	}
+	o.contentType = contentType
+	o.tags = maps.Clone(options.Tags)
+	if len(options.Metadata) > 0 {
+		o.metadata = make(map[string]string, len(options.Metadata))
+		for key, value := range options.Metadata {
+			o.metadata[strings.ToLower(key)] = value
+		}
+	}
+}
+
+// fileInfo 构造不含用户元数据的文件信息
+func (o *memoryObject) fileInfo(key string) *FileInfo {
+	return &FileInfo{
+		Key:          key,
+		Size:         int64(len(o.data)),
+		ContentType:  o.contentType,
+		LastModified: o.modified,
+		ETag:         o.etag,
+	}
+}
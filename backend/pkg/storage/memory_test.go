@@ -0,0 +1,225 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupMemoryStorage 创建内存存储
+func setupMemoryStorage(t *testing.T) *MemoryStorage {
+	storage, err := NewMemoryStorage(&MemoryConfig{
+		BaseURL:    "http://localhost:8080/storage/",
+		SigningKey: "test-signing-key",
+	})
+	require.NoError(t, err, "创建内存存储实例应该成功")
+	return storage
+}
+
+// TestMemoryStorage_Buckets 测试存储桶操作
+func TestMemoryStorage_Buckets(t *testing.T) {
+	storage, err := NewMemoryStorage(nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	exists, err := storage.BucketExists(ctx, "videos")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = storage.GetFileInfo(ctx, "videos", "a.mp4")
+	assert.ErrorIs(t, err, ErrObjectNotFound, "存储桶不存在时应该返回文件不存在")
+
+	_, err = storage.UploadFile(ctx, "videos", "a.mp4", []byte("a"), "video/mp4")
+	require.NoError(t, err, "与local驱动一致，写入时应该按需创建存储桶")
+	exists, err = storage.BucketExists(ctx, "videos")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	assert.Error(t, storage.CreateBucket(ctx, "videos"), "重复创建存储桶应该失败")
+	assert.Error(t, storage.CreateBucket(ctx, "A"), "无效的存储桶名应该被拒绝")
+	_, err = storage.UploadFile(ctx, "A", "a.mp4", []byte("a"), "video/mp4")
+	assert.Error(t, err, "无效的存储桶名应该被拒绝")
+	assert.Error(t, storage.RemoveBucket(ctx, "videos"), "存储桶非空时应该删除失败")
+
+	require.NoError(t, storage.DeleteFile(ctx, "videos", "a.mp4"))
+	require.NoError(t, storage.RemoveBucket(ctx, "videos"))
+	exists, err = storage.BucketExists(ctx, "videos")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+// TestMemoryStorage_FileOperations 测试文件上传、读取、列出和删除
+func TestMemoryStorage_FileOperations(t *testing.T) {
+	storage := setupMemoryStorage(t)
+	ctx := context.Background()
+	bucket := "test-bucket"
+
+	result, err := storage.UploadFile(ctx, bucket, "videos/a.mp4", []byte("0123456789"), "", ObjectOptions{
+		Metadata: map[string]string{"Video-ID": "video-1"},
+		Tags:     map[string]string{ObjectKeyVideoID: "video-1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), result.Size)
+	assert.Equal(t, "781e5e245d69b566979b86e28d23f2c7", result.ETag, "ETag应该是内容的MD5")
+
+	info, err := storage.GetFileInfo(ctx, bucket, "videos/a.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, "video/mp4", info.ContentType, "未指定内容类型时应该根据扩展名推断")
+	assert.Equal(t, map[string]string{"video-id": "video-1"}, info.Metadata, "用户元数据的键应该转换为小写")
+
+	data, err := storage.DownloadFile(ctx, bucket, "videos/a.mp4")
+	require.NoError(t, err)
+	data[0] = 'x'
+	data, err = storage.DownloadFile(ctx, bucket, "videos/a.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789", string(data), "修改下载的数据不应该影响存储中的对象")
+
+	reader, err := storage.OpenFileRange(ctx, bucket, "videos/a.mp4", 8, 5)
+	require.NoError(t, err)
+	data, err = io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "89", string(data), "范围超出文件末尾时应该读取到末尾")
+	_, err = storage.OpenFileRange(ctx, bucket, "videos/a.mp4", 10, -1)
+	assert.Error(t, err, "起始位置超出文件大小时应该返回错误")
+
+	_, err = storage.UploadFile(ctx, bucket, "videos/b.mp4", []byte("b"), "video/mp4")
+	require.NoError(t, err)
+	_, err = storage.UploadFile(ctx, bucket, "thumbnails/a.jpg", []byte("c"), "image/jpeg")
+	require.NoError(t, err)
+
+	files, err := storage.ListFiles(ctx, bucket, "videos/")
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "videos/a.mp4", files[0].Key)
+	assert.Equal(t, "videos/b.mp4", files[1].Key)
+	assert.Nil(t, files[0].Metadata, "列出文件时不返回用户元数据")
+
+	failures, err := storage.DeleteFiles(ctx, bucket, []string{"videos/a.mp4", "videos/missing.mp4"})
+	require.NoError(t, err)
+	assert.Empty(t, failures, "对象不存在应该视为删除成功")
+
+	_, err = storage.GetFileInfo(ctx, bucket, "videos/a.mp4")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+	exists, err := storage.FileExists(ctx, bucket, "videos/b.mp4")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	_, err = storage.UploadStream(ctx, bucket, "videos/c.mp4", strings.NewReader("abc"), 5, "video/mp4")
+	assert.Error(t, err, "大小不一致时应该上传失败")
+	_, err = storage.UploadFile(ctx, bucket, "../a.mp4", []byte("a"), "video/mp4")
+	assert.Error(t, err, "越出存储桶的对象名应该被拒绝")
+}
+
+// TestMemoryStorage_CopyAndTags 测试复制、移动文件和对象标签
+func TestMemoryStorage_CopyAndTags(t *testing.T) {
+	storage := setupMemoryStorage(t)
+	ctx := context.Background()
+	bucket := "test-bucket"
+
+	_, err := storage.UploadFile(ctx, bucket, "a.mp4", []byte("video"), "video/mp4", ObjectOptions{
+		Tags: map[string]string{ObjectKeyVideoID: "video-1"},
+	})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, storage.CopyFile(ctx, bucket, "a.mp4", bucket, "a.mp4"), ErrSameObject)
+	require.NoError(t, storage.CopyFile(ctx, bucket, "a.mp4", "archive", "copy.mp4"))
+	tags, err := storage.GetObjectTags(ctx, "archive", "copy.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{ObjectKeyVideoID: "video-1"}, tags, "复制时应该保留对象标签")
+
+	require.NoError(t, storage.SetObjectTags(ctx, "archive", "copy.mp4", map[string]string{"state": "archived"}))
+	tags, err = storage.GetObjectTags(ctx, "archive", "copy.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"state": "archived"}, tags, "设置标签应该替换全部标签")
+	tags, err = storage.GetObjectTags(ctx, bucket, "a.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{ObjectKeyVideoID: "video-1"}, tags, "修改副本的标签不应该影响源文件")
+
+	require.NoError(t, storage.MoveFile(ctx, bucket, "a.mp4", "archive", "moved.mp4"))
+	exists, err := storage.FileExists(ctx, bucket, "a.mp4")
+	require.NoError(t, err)
+	assert.False(t, exists, "移动后源文件应该被删除")
+	data, err := storage.DownloadFile(ctx, "archive", "moved.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, "video", string(data))
+
+	assert.ErrorIs(t, storage.MoveFile(ctx, bucket, "a.mp4", "archive", "again.mp4"), ErrObjectNotFound)
+}
+
+// TestMemoryStorage_MultipartUpload 测试分片上传
+func TestMemoryStorage_MultipartUpload(t *testing.T) {
+	storage := setupMemoryStorage(t)
+	ctx := context.Background()
+	bucket := "test-bucket"
+	objectName := "videos/multipart.mp4"
+
+	uploadID, err := storage.InitiateMultipartUpload(ctx, bucket, objectName, "video/mp4", ObjectOptions{
+		Metadata: map[string]string{ObjectKeyVideoID: "video-1"},
+	})
+	require.NoError(t, err)
+
+	_, err = storage.UploadPart(ctx, bucket, "videos/other.mp4", uploadID, 1, strings.NewReader("x"), 1)
+	assert.Error(t, err, "目标对象不一致时应该拒绝上传分片")
+
+	second, err := storage.UploadPart(ctx, bucket, objectName, uploadID, 2, strings.NewReader("world"), 5)
+	require.NoError(t, err)
+	first, err := storage.UploadPart(ctx, bucket, objectName, uploadID, 1, strings.NewReader("hello "), 6)
+	require.NoError(t, err)
+
+	parts, err := storage.ListParts(ctx, bucket, objectName, uploadID)
+	require.NoError(t, err)
+	assert.Equal(t, []*PartInfo{
+		{PartNumber: 1, ETag: first.ETag, Size: 6},
+		{PartNumber: 2, ETag: second.ETag, Size: 5},
+	}, parts, "分片应该按分片号升序")
+
+	_, err = storage.CompleteMultipartUpload(ctx, bucket, objectName, uploadID, []CompletePart{
+		{PartNumber: 1, ETag: second.ETag},
+	})
+	assert.Error(t, err, "ETag不匹配时应该合并失败")
+
+	result, err := storage.CompleteMultipartUpload(ctx, bucket, objectName, uploadID, []CompletePart{
+		{PartNumber: 1, ETag: first.ETag},
+		{PartNumber: 2, ETag: `"` + second.ETag + `"`},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), result.Size)
+	assert.True(t, strings.HasSuffix(result.ETag, "-2"), "合并后的ETag应该带有分片数")
+
+	data, err := storage.DownloadFile(ctx, bucket, objectName)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(data))
+	info, err := storage.GetFileInfo(ctx, bucket, objectName)
+	require.NoError(t, err)
+	assert.Equal(t, "video-1", info.Metadata[ObjectKeyVideoID], "合并后的对象应该带有初始化时的用户元数据")
+
+	assert.Error(t, storage.AbortMultipartUpload(ctx, bucket, objectName, uploadID), "完成后上传ID应该失效")
+}
+
+// TestMemoryStorage_PresignedURL 测试预签名URL与local驱动使用相同的格式和校验
+func TestMemoryStorage_PresignedURL(t *testing.T) {
+	storage := setupMemoryStorage(t)
+	ctx := context.Background()
+
+	rawURL, err := storage.GeneratePresignedURL(ctx, "test-bucket", "videos/a b.mp4", time.Hour, "GET")
+	require.NoError(t, err)
+	parsed, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	assert.Equal(t, "/storage/test-bucket/videos/a b.mp4", parsed.Path)
+
+	expires := parsed.Query().Get("expires")
+	signature := parsed.Query().Get("signature")
+	assert.NoError(t, storage.VerifyPresignedURL("HEAD", "test-bucket", "videos/a b.mp4", expires, signature))
+	assert.ErrorIs(t, storage.VerifyPresignedURL("PUT", "test-bucket", "videos/a b.mp4", expires, signature), ErrPresignedURLInvalid)
+
+	noBaseURL, err := NewMemoryStorage(nil)
+	require.NoError(t, err)
+	_, err = noBaseURL.GetPresignedURL(ctx, "test-bucket", "a.mp4", time.Hour)
+	assert.Error(t, err, "未配置访问地址时应该返回错误")
+}
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/tags"
 )
 
 // MinIOConfig MinIO配置结构
@@ -19,8 +21,14 @@ type MinIOConfig struct {
 	SecretKey string // 秘密密钥
 	UseSSL    bool   // 是否使用SSL
 	Region    string // 区域
+	// PublicEndpoint 预签名URL的对外访问地址，为nil时使用Endpoint
+	// 预签名URL使用对外主机签名，经过反向代理时需要保留请求的Host头
+	PublicEndpoint *PublicEndpoint
 }
 
+// defaultPresignRegion 未配置区域时预签名使用的区域，预签名前不再请求存储桶所在区域
+const defaultPresignRegion = "us-east-1"
+
 // GetEndpoint 获取服务端点
 func (c *MinIOConfig) GetEndpoint() string {
 	return c.Endpoint
@@ -48,10 +56,16 @@ func (c *MinIOConfig) GetRegion() string {
 
 // MinIOStorage MinIO存储服务
 type MinIOStorage struct {
-	client *minio.Client
-	config Config
+	client         *minio.Client
+	core           *minio.Core     // 底层S3 API，用于原生分片上传
+	presignClient  *minio.Client   // 生成预签名URL的客户端，配置了对外地址时使用对外主机
+	publicEndpoint *PublicEndpoint // 预签名URL的对外访问地址
+	config         Config
 }
 
+// maxSingleCopySize S3单次CopyObject请求支持的最大对象大小
+const maxSingleCopySize = 5 * 1024 * 1024 * 1024
+
 // 确保MinIOStorage实现了StorageInterface接口
 var _ StorageInterface = (*MinIOStorage)(nil)
 
@@ -63,11 +77,70 @@ type UploadResult struct {
 
 // FileInfo 文件信息
 type FileInfo struct {
-	Key          string    // 文件名/键
-	Size         int64     // 文件大小
-	ContentType  string    // 内容类型
-	LastModified time.Time // 最后修改时间
-	ETag         string    // ETag
+	Key          string            // 文件名/键
+	Size         int64             // 文件大小
+	ContentType  string            // 内容类型
+	LastModified time.Time         // 最后修改时间
+	ETag         string            // ETag
+	Metadata     map[string]string // 用户元数据，键为小写，只有GetFileInfo返回
+}
+
+// 上传视频时附加的用户元数据和对象标签的键
+const (
+	ObjectKeyVideoID     = "video-id"     // 视频ID
+	ObjectKeyUploader    = "uploader"     // 上传者用户ID
+	ObjectKeyContentHash = "content-hash" // 文件内容的SHA-256校验和
+)
+
+// ObjectOptions 上传对象时附加的用户元数据和对象标签
+// MinIO/S3中用户元数据以x-amz-meta-前缀的请求头保存，上传后不能修改；对象标签可以单独修改，
+// 可用于存储桶生命周期规则按标签筛选对象
+type ObjectOptions struct {
+	Metadata map[string]string // 用户元数据
+	Tags     map[string]string // 对象标签，S3限制每个对象最多10个标签
+}
+
+// mergeObjectOptions 按顺序合并上传选项，后面的同名键覆盖前面的
+func mergeObjectOptions(opts []ObjectOptions) ObjectOptions {
+	var merged ObjectOptions
+	for _, opt := range opts {
+		for key, value := range opt.Metadata {
+			if merged.Metadata == nil {
+				merged.Metadata = make(map[string]string)
+			}
+			merged.Metadata[key] = value
+		}
+		for key, value := range opt.Tags {
+			if merged.Tags == nil {
+				merged.Tags = make(map[string]string)
+			}
+			merged.Tags[key] = value
+		}
+	}
+	return merged
+}
+
+// putObjectOptions 构造MinIO上传选项
+func putObjectOptions(contentType string, opts []ObjectOptions) minio.PutObjectOptions {
+	merged := mergeObjectOptions(opts)
+	return minio.PutObjectOptions{
+		ContentType:  contentType,
+		UserMetadata: merged.Metadata,
+		UserTags:     merged.Tags,
+	}
+}
+
+// PartInfo 已上传分片信息
+type PartInfo struct {
+	PartNumber int    // 分片号
+	ETag       string // 分片ETag
+	Size       int64  // 分片大小
+}
+
+// CompletePart 完成分片上传时提交的分片
+type CompletePart struct {
+	PartNumber int    // 分片号
+	ETag       string // 分片ETag
 }
 
 // NewMinIOStorage 创建MinIO存储服务实例
@@ -86,9 +159,33 @@ func NewMinIOStorage(config *MinIOConfig) (*MinIOStorage, error) {
 		return nil, fmt.Errorf("创建MinIO客户端失败: %w", err)
 	}
 
+	// 预签名在本地完成，使用对外主机签名的URL可以被直接访问或经保留Host头的反向代理访问
+	presignClient := client
+	if public := config.PublicEndpoint; public != nil {
+		secure := config.IsSSLEnabled()
+		if public.Scheme != "" {
+			secure = public.Scheme == "https"
+		}
+		region := config.GetRegion()
+		if region == "" {
+			region = defaultPresignRegion
+		}
+		presignClient, err = minio.New(public.Host, &minio.Options{
+			Creds:  credentials.NewStaticV4(config.GetAccessKey(), config.GetSecretKey(), ""),
+			Secure: secure,
+			Region: region,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("创建MinIO预签名客户端失败: %w", err)
+		}
+	}
+
 	return &MinIOStorage{
-		client: client,
-		config: config,
+		client:         client,
+		core:           &minio.Core{Client: client},
+		presignClient:  presignClient,
+		publicEndpoint: config.PublicEndpoint,
+		config:         config,
 	}, nil
 }
 
@@ -132,12 +229,27 @@ func (s *MinIOStorage) RemoveBucket(ctx context.Context, bucketName string) erro
 }
 
 // UploadFile 上传文件
-func (s *MinIOStorage) UploadFile(ctx context.Context, bucketName, objectName string, data []byte, contentType string) (*UploadResult, error) {
+func (s *MinIOStorage) UploadFile(ctx context.Context, bucketName, objectName string, data []byte, contentType string, opts ...ObjectOptions) (*UploadResult, error) {
 	reader := bytes.NewReader(data)
 
-	info, err := s.client.PutObject(ctx, bucketName, objectName, reader, int64(len(data)), minio.PutObjectOptions{
-		ContentType: contentType,
-	})
+	info, err := s.client.PutObject(ctx, bucketName, objectName, reader, int64(len(data)), putObjectOptions(contentType, opts))
+	if err != nil {
+		return nil, fmt.Errorf("上传文件失败: %w", err)
+	}
+
+	return &UploadResult{
+		ETag: info.ETag,
+		Size: info.Size,
+	}, nil
+}
+
+// UploadStream 流式上传文件，size未知时传-1，由客户端自动进行分片上传
+func (s *MinIOStorage) UploadStream(ctx context.Context, bucketName, objectName string, reader io.Reader, size int64, contentType string, opts ...ObjectOptions) (*UploadResult, error) {
+	if reader == nil {
+		return nil, fmt.Errorf("文件读取器不能为空")
+	}
+
+	info, err := s.client.PutObject(ctx, bucketName, objectName, reader, size, putObjectOptions(contentType, opts))
 	if err != nil {
 		return nil, fmt.Errorf("上传文件失败: %w", err)
 	}
@@ -148,6 +260,51 @@ func (s *MinIOStorage) UploadFile(ctx context.Context, bucketName, objectName st
 	}, nil
 }
 
+// CopyFile 在服务端复制文件
+// 超过5GiB的对象由ComposeObject自动分片复制，分片复制不会复制内容类型和对象标签，需要显式设置
+func (s *MinIOStorage) CopyFile(ctx context.Context, srcBucket, srcObject, dstBucket, dstObject string) error {
+	if srcBucket == dstBucket && srcObject == dstObject {
+		return ErrSameObject
+	}
+
+	stat, err := s.client.StatObject(ctx, srcBucket, srcObject, minio.StatObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("获取源文件信息失败: %w", err)
+	}
+
+	dst := minio.CopyDestOptions{Bucket: dstBucket, Object: dstObject}
+	if stat.Size > maxSingleCopySize {
+		objectTags, err := s.client.GetObjectTagging(ctx, srcBucket, srcObject, minio.GetObjectTaggingOptions{})
+		if err != nil {
+			return fmt.Errorf("获取对象标签失败: %w", err)
+		}
+		metadata := make(map[string]string, len(stat.UserMetadata)+1)
+		for key, value := range stat.UserMetadata {
+			metadata[key] = value
+		}
+		metadata["Content-Type"] = stat.ContentType
+		dst.ReplaceMetadata, dst.UserMetadata = true, metadata
+		dst.ReplaceTags, dst.UserTags = true, objectTags.ToMap()
+	}
+
+	src := minio.CopySrcOptions{Bucket: srcBucket, Object: srcObject, MatchETag: stat.ETag}
+	if _, err := s.client.ComposeObject(ctx, dst, src); err != nil {
+		return fmt.Errorf("复制文件失败: %w", err)
+	}
+	return nil
+}
+
+// MoveFile 在服务端复制文件后删除源文件
+func (s *MinIOStorage) MoveFile(ctx context.Context, srcBucket, srcObject, dstBucket, dstObject string) error {
+	if err := s.CopyFile(ctx, srcBucket, srcObject, dstBucket, dstObject); err != nil {
+		return err
+	}
+	if err := s.DeleteFile(ctx, srcBucket, srcObject); err != nil {
+		return fmt.Errorf("删除源文件失败: %w", err)
+	}
+	return nil
+}
+
 // FileExists 检查文件是否存在
 func (s *MinIOStorage) FileExists(ctx context.Context, bucketName, objectName string) (bool, error) {
 	_, err := s.client.StatObject(ctx, bucketName, objectName, minio.StatObjectOptions{})
@@ -163,11 +320,19 @@ func (s *MinIOStorage) FileExists(ctx context.Context, bucketName, objectName st
 
 // GetPresignedURL 生成预签名URL
 func (s *MinIOStorage) GetPresignedURL(ctx context.Context, bucketName, objectName string, expiry time.Duration) (string, error) {
-	url, err := s.client.PresignedGetObject(ctx, bucketName, objectName, expiry, nil)
+	presignedURL, err := s.presignClient.PresignedGetObject(ctx, bucketName, objectName, expiry, nil)
 	if err != nil {
 		return "", fmt.Errorf("生成预签名URL失败: %w", err)
 	}
-	return url.String(), nil
+	return s.publicURL(presignedURL), nil
+}
+
+// publicURL 返回预签名URL，配置了对外地址时加上对外地址的路径前缀
+func (s *MinIOStorage) publicURL(presignedURL *url.URL) string {
+	if s.publicEndpoint != nil {
+		s.publicEndpoint.apply(presignedURL)
+	}
+	return presignedURL.String()
 }
 
 // GetFileInfo 获取文件信息
@@ -177,15 +342,43 @@ func (s *MinIOStorage) GetFileInfo(ctx context.Context, bucketName, objectName s
 		return nil, fmt.Errorf("获取文件信息失败: %w", err)
 	}
 
+	// MinIO返回的用户元数据键为规范化的请求头格式（如Video-Id），统一转为小写
+	metadata := make(map[string]string, len(stat.UserMetadata))
+	for key, value := range stat.UserMetadata {
+		metadata[strings.ToLower(key)] = value
+	}
+
 	return &FileInfo{
 		Key:          stat.Key,
 		Size:         stat.Size,
 		ContentType:  stat.ContentType,
 		LastModified: stat.LastModified,
 		ETag:         stat.ETag,
+		Metadata:     metadata,
 	}, nil
 }
 
+// GetObjectTags 获取对象标签
+func (s *MinIOStorage) GetObjectTags(ctx context.Context, bucketName, objectName string) (map[string]string, error) {
+	objectTags, err := s.client.GetObjectTagging(ctx, bucketName, objectName, minio.GetObjectTaggingOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取对象标签失败: %w", err)
+	}
+	return objectTags.ToMap(), nil
+}
+
+// SetObjectTags 替换对象的全部标签
+func (s *MinIOStorage) SetObjectTags(ctx context.Context, bucketName, objectName string, objectTags map[string]string) error {
+	parsed, err := tags.NewTags(objectTags, true)
+	if err != nil {
+		return fmt.Errorf("对象标签无效: %w", err)
+	}
+	if err := s.client.PutObjectTagging(ctx, bucketName, objectName, parsed, minio.PutObjectTaggingOptions{}); err != nil {
+		return fmt.Errorf("设置对象标签失败: %w", err)
+	}
+	return nil
+}
+
 // DeleteFile 删除文件
 func (s *MinIOStorage) DeleteFile(ctx context.Context, bucketName, objectName string) error {
 	err := s.client.RemoveObject(ctx, bucketName, objectName, minio.RemoveObjectOptions{})
@@ -195,12 +388,36 @@ func (s *MinIOStorage) DeleteFile(ctx context.Context, bucketName, objectName st
 	return nil
 }
 
+// DeleteFiles 批量删除文件，通过S3 DeleteObjects接口每次请求最多删除1000个对象
+func (s *MinIOStorage) DeleteFiles(ctx context.Context, bucketName string, objectNames []string) (map[string]error, error) {
+	objectsCh := make(chan minio.ObjectInfo, len(objectNames))
+	for _, objectName := range objectNames {
+		objectsCh <- minio.ObjectInfo{Key: objectName}
+	}
+	close(objectsCh)
+
+	failures := make(map[string]error)
+	for removeErr := range s.client.RemoveObjects(ctx, bucketName, objectsCh, minio.RemoveObjectsOptions{}) {
+		if removeErr.ObjectName == "" {
+			// 整个请求失败（如存储桶不存在）
+			return nil, fmt.Errorf("批量删除文件失败: %w", removeErr.Err)
+		}
+		failures[removeErr.ObjectName] = removeErr.Err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("批量删除文件失败: %w", err)
+	}
+	return failures, nil
+}
+
 // ListFiles 列出文件
 func (s *MinIOStorage) ListFiles(ctx context.Context, bucketName, prefix string) ([]*FileInfo, error) {
 	var files []*FileInfo
 
+	// 递归列出前缀下的全部文件，与本地存储驱动保持一致
 	objectCh := s.client.ListObjects(ctx, bucketName, minio.ListObjectsOptions{
-		Prefix: prefix,
+		Prefix:    prefix,
+		Recursive: true,
 	})
 
 	for object := range objectCh {
@@ -238,6 +455,124 @@ func (s *MinIOStorage) DownloadFile(ctx context.Context, bucketName, objectName
 	return data, nil
 }
 
+// OpenFile 打开文件读取流，不将文件读入内存，调用方负责关闭
+func (s *MinIOStorage) OpenFile(ctx context.Context, bucketName, objectName string) (io.ReadCloser, error) {
+	object, err := s.client.GetObject(ctx, bucketName, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %w", err)
+	}
+	return object, nil
+}
+
+// OpenFileRange 打开文件指定字节范围的读取流，length小于0时读取到文件末尾
+func (s *MinIOStorage) OpenFileRange(ctx context.Context, bucketName, objectName string, offset, length int64) (io.ReadCloser, error) {
+	if offset < 0 || length == 0 {
+		return nil, fmt.Errorf("无效的读取范围: offset=%d, length=%d", offset, length)
+	}
+
+	opts := minio.GetObjectOptions{}
+	end := int64(0)
+	if length > 0 {
+		end = offset + length - 1
+	}
+	if offset > 0 || length > 0 {
+		if err := opts.SetRange(offset, end); err != nil {
+			return nil, fmt.Errorf("设置读取范围失败: %w", err)
+		}
+	}
+
+	object, err := s.client.GetObject(ctx, bucketName, objectName, opts)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %w", err)
+	}
+	return object, nil
+}
+
+// InitiateMultipartUpload 初始化原生分片上传，返回上传ID
+func (s *MinIOStorage) InitiateMultipartUpload(ctx context.Context, bucketName, objectName, contentType string, opts ...ObjectOptions) (string, error) {
+	uploadID, err := s.core.NewMultipartUpload(ctx, bucketName, objectName, putObjectOptions(contentType, opts))
+	if err != nil {
+		return "", fmt.Errorf("初始化分片上传失败: %w", err)
+	}
+	return uploadID, nil
+}
+
+// UploadPart 上传分片，除最后一个分片外每个分片不能小于5MB
+func (s *MinIOStorage) UploadPart(ctx context.Context, bucketName, objectName, uploadID string, partNumber int, reader io.Reader, size int64) (*PartInfo, error) {
+	if reader == nil {
+		return nil, fmt.Errorf("分片读取器不能为空")
+	}
+
+	part, err := s.core.PutObjectPart(ctx, bucketName, objectName, uploadID, partNumber, reader, size, minio.PutObjectPartOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("上传分片失败: %w", err)
+	}
+
+	return &PartInfo{
+		PartNumber: part.PartNumber,
+		ETag:       part.ETag,
+		Size:       part.Size,
+	}, nil
+}
+
+// CompleteMultipartUpload 完成分片上传，由存储服务端合并分片
+func (s *MinIOStorage) CompleteMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string, parts []CompletePart) (*UploadResult, error) {
+	completeParts := make([]minio.CompletePart, 0, len(parts))
+	for _, part := range parts {
+		completeParts = append(completeParts, minio.CompletePart{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+		})
+	}
+
+	info, err := s.core.CompleteMultipartUpload(ctx, bucketName, objectName, uploadID, completeParts, minio.PutObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("完成分片上传失败: %w", err)
+	}
+
+	// 合并响应中不包含对象大小，需要单独查询
+	stat, err := s.client.StatObject(ctx, bucketName, objectName, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取合并后文件信息失败: %w", err)
+	}
+
+	return &UploadResult{
+		ETag: info.ETag,
+		Size: stat.Size,
+	}, nil
+}
+
+// ListParts 列出分片上传中已上传的分片，按分片号升序
+func (s *MinIOStorage) ListParts(ctx context.Context, bucketName, objectName, uploadID string) ([]*PartInfo, error) {
+	var parts []*PartInfo
+	marker := 0
+	for {
+		result, err := s.core.ListObjectParts(ctx, bucketName, objectName, uploadID, marker, 1000)
+		if err != nil {
+			return nil, fmt.Errorf("列出分片失败: %w", err)
+		}
+		for _, part := range result.ObjectParts {
+			parts = append(parts, &PartInfo{
+				PartNumber: part.PartNumber,
+				ETag:       strings.Trim(part.ETag, `"`),
+				Size:       part.Size,
+			})
+		}
+		if !result.IsTruncated {
+			return parts, nil
+		}
+		marker = result.NextPartNumberMarker
+	}
+}
+
+// AbortMultipartUpload 中止分片上传并清理已上传的分片
+func (s *MinIOStorage) AbortMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string) error {
+	if err := s.core.AbortMultipartUpload(ctx, bucketName, objectName, uploadID); err != nil {
+		return fmt.Errorf("中止分片上传失败: %w", err)
+	}
+	return nil
+}
+
 // GeneratePresignedURL 生成预签名URL（支持不同HTTP方法）
 func (s *MinIOStorage) GeneratePresignedURL(ctx context.Context, bucketName, objectName string, expiry time.Duration, method string) (string, error) {
 	// 将HTTP方法字符串转换为MinIO的方法类型
@@ -245,35 +580,35 @@ func (s *MinIOStorage) GeneratePresignedURL(ctx context.Context, bucketName, obj
 
 	switch method {
 	case "GET":
-		presignedURL, err := s.client.PresignedGetObject(ctx, bucketName, objectName, expiry, reqParams)
+		presignedURL, err := s.presignClient.PresignedGetObject(ctx, bucketName, objectName, expiry, reqParams)
 		if err != nil {
 			return "", fmt.Errorf("生成GET预签名URL失败: %w", err)
 		}
-		return presignedURL.String(), nil
+		return s.publicURL(presignedURL), nil
 
 	case "PUT":
-		presignedURL, err := s.client.PresignedPutObject(ctx, bucketName, objectName, expiry)
+		presignedURL, err := s.presignClient.PresignedPutObject(ctx, bucketName, objectName, expiry)
 		if err != nil {
 			return "", fmt.Errorf("生成PUT预签名URL失败: %w", err)
 		}
-		return presignedURL.String(), nil
+		return s.publicURL(presignedURL), nil
 
 	case "DELETE":
 		// 对于DELETE方法，使用PresignedGetObject生成URL，但注意这实际上是GET方法
 		// 在实际应用中，DELETE操作通常不通过预签名URL完成
-		presignedURL, err := s.client.PresignedGetObject(ctx, bucketName, objectName, expiry, reqParams)
+		presignedURL, err := s.presignClient.PresignedGetObject(ctx, bucketName, objectName, expiry, reqParams)
 		if err != nil {
 			return "", fmt.Errorf("生成DELETE预签名URL失败: %w", err)
 		}
-		return presignedURL.String(), nil
+		return s.publicURL(presignedURL), nil
 
 	case "HEAD":
 		// 对于HEAD方法，使用PresignedGetObject生成URL
-		presignedURL, err := s.client.PresignedGetObject(ctx, bucketName, objectName, expiry, reqParams)
+		presignedURL, err := s.presignClient.PresignedGetObject(ctx, bucketName, objectName, expiry, reqParams)
 		if err != nil {
 			return "", fmt.Errorf("生成HEAD预签名URL失败: %w", err)
 		}
-		return presignedURL.String(), nil
+		return s.publicURL(presignedURL), nil
 
 	default:
 		return "", fmt.Errorf("不支持的HTTP方法: %s", method)
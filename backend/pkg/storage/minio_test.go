@@ -1,7 +1,9 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"os"
 	"strings"
 	"testing"
@@ -136,6 +138,187 @@ func TestMinIOStorage_FileOperations(t *testing.T) {
 	assert.False(t, exists, "删除后文件应该不存在")
 }
 
+// TestMinIOStorage_UploadStream 测试流式上传（需要真实服务）
+func TestMinIOStorage_UploadStream(t *testing.T) {
+	if !isMinIOAvailable() {
+		t.Skip("跳过测试：MinIO服务不可用")
+	}
+
+	storage := setupTestStorage(t)
+	ctx := context.Background()
+	testBucket := "test-bucket-" + generateTestID()
+
+	err := storage.CreateBucket(ctx, testBucket)
+	require.NoError(t, err)
+
+	objectName := "videos/2025/08/stream-video.mp4"
+	defer func() {
+		_ = storage.DeleteFile(ctx, testBucket, objectName)
+		_ = storage.RemoveBucket(ctx, testBucket)
+	}()
+
+	testData := strings.Repeat("视频流数据", 1024)
+
+	// 已知大小的流式上传
+	result, err := storage.UploadStream(ctx, testBucket, objectName, strings.NewReader(testData), int64(len(testData)), "video/mp4")
+	assert.NoError(t, err, "流式上传应该成功")
+	require.NotNil(t, result)
+	assert.Equal(t, int64(len(testData)), result.Size, "上传文件大小应该匹配")
+
+	// 未知大小的流式上传
+	result, err = storage.UploadStream(ctx, testBucket, objectName, strings.NewReader(testData), -1, "video/mp4")
+	assert.NoError(t, err, "未知大小的流式上传应该成功")
+	require.NotNil(t, result)
+	assert.Equal(t, int64(len(testData)), result.Size)
+
+	// 空读取器
+	_, err = storage.UploadStream(ctx, testBucket, objectName, nil, 0, "video/mp4")
+	assert.Error(t, err, "空读取器应该返回错误")
+}
+
+// TestMinIOStorage_OpenFile 测试流式读取文件（需要真实服务）
+func TestMinIOStorage_OpenFile(t *testing.T) {
+	if !isMinIOAvailable() {
+		t.Skip("跳过测试：MinIO服务不可用")
+	}
+
+	storage := setupTestStorage(t)
+	ctx := context.Background()
+	testBucket := "test-bucket-" + generateTestID()
+
+	err := storage.CreateBucket(ctx, testBucket)
+	require.NoError(t, err)
+
+	objectName := "videos/2025/08/open-video.mp4"
+	defer func() {
+		_ = storage.DeleteFile(ctx, testBucket, objectName)
+		_ = storage.RemoveBucket(ctx, testBucket)
+	}()
+
+	testData := strings.Repeat("视频流数据", 1024)
+	_, err = storage.UploadFile(ctx, testBucket, objectName, []byte(testData), "video/mp4")
+	require.NoError(t, err)
+
+	reader, err := storage.OpenFile(ctx, testBucket, objectName)
+	require.NoError(t, err, "打开文件应该成功")
+	defer reader.Close()
+
+	head := make([]byte, 15)
+	_, err = io.ReadFull(reader, head)
+	require.NoError(t, err, "读取文件头部应该成功")
+	assert.Equal(t, testData[:15], string(head), "读取的内容应该匹配")
+
+	missing, err := storage.OpenFile(ctx, testBucket, "not-exist.mp4")
+	if err == nil {
+		_, err = missing.Read(make([]byte, 1))
+		missing.Close()
+	}
+	assert.Error(t, err, "读取不存在的文件应该返回错误")
+}
+
+// TestMinIOStorage_OpenFileRange 测试按字节范围读取文件（需要真实服务）
+func TestMinIOStorage_OpenFileRange(t *testing.T) {
+	if !isMinIOAvailable() {
+		t.Skip("跳过测试：MinIO服务不可用")
+	}
+
+	storage := setupTestStorage(t)
+	ctx := context.Background()
+	testBucket := "test-bucket-" + generateTestID()
+
+	err := storage.CreateBucket(ctx, testBucket)
+	require.NoError(t, err)
+
+	objectName := "videos/2025/08/range-video.mp4"
+	defer func() {
+		_ = storage.DeleteFile(ctx, testBucket, objectName)
+		_ = storage.RemoveBucket(ctx, testBucket)
+	}()
+
+	_, err = storage.UploadFile(ctx, testBucket, objectName, []byte("0123456789"), "video/mp4")
+	require.NoError(t, err)
+
+	tests := []struct {
+		offset, length int64
+		expected       string
+	}{
+		{0, 1, "0"},
+		{2, 3, "234"},
+		{7, -1, "789"},
+		{0, -1, "0123456789"},
+	}
+	for _, tt := range tests {
+		reader, err := storage.OpenFileRange(ctx, testBucket, objectName, tt.offset, tt.length)
+		require.NoError(t, err, "按范围打开文件应该成功")
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, string(data), "offset=%d length=%d", tt.offset, tt.length)
+	}
+
+	_, err = storage.OpenFileRange(ctx, testBucket, objectName, -1, 1)
+	assert.Error(t, err, "负数偏移量应该返回错误")
+}
+
+// TestMinIOStorage_MultipartUpload 测试原生分片上传（需要真实服务）
+func TestMinIOStorage_MultipartUpload(t *testing.T) {
+	if !isMinIOAvailable() {
+		t.Skip("跳过测试：MinIO服务不可用")
+	}
+
+	storage := setupTestStorage(t)
+	ctx := context.Background()
+	testBucket := "test-bucket-" + generateTestID()
+
+	err := storage.CreateBucket(ctx, testBucket)
+	require.NoError(t, err)
+
+	objectName := "videos/2025/08/multipart-video.mp4"
+	defer func() {
+		_ = storage.DeleteFile(ctx, testBucket, objectName)
+		_ = storage.RemoveBucket(ctx, testBucket)
+	}()
+
+	uploadID, err := storage.InitiateMultipartUpload(ctx, testBucket, objectName, "video/mp4")
+	require.NoError(t, err, "初始化分片上传应该成功")
+	require.NotEmpty(t, uploadID)
+
+	// 除最后一个分片外每个分片至少5MB
+	first := []byte(strings.Repeat("a", 5*1024*1024))
+	second := []byte("last-part")
+
+	part1, err := storage.UploadPart(ctx, testBucket, objectName, uploadID, 1, bytes.NewReader(first), int64(len(first)))
+	require.NoError(t, err, "上传第一个分片应该成功")
+	part2, err := storage.UploadPart(ctx, testBucket, objectName, uploadID, 2, bytes.NewReader(second), int64(len(second)))
+	require.NoError(t, err, "上传第二个分片应该成功")
+
+	result, err := storage.CompleteMultipartUpload(ctx, testBucket, objectName, uploadID, []CompletePart{
+		{PartNumber: part1.PartNumber, ETag: part1.ETag},
+		{PartNumber: part2.PartNumber, ETag: part2.ETag},
+	})
+	require.NoError(t, err, "完成分片上传应该成功")
+	assert.Equal(t, int64(len(first)+len(second)), result.Size, "合并后文件大小应该正确")
+
+	reader, err := storage.OpenFile(ctx, testBucket, objectName)
+	require.NoError(t, err)
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasSuffix(data, second), "分片应该按顺序合并")
+
+	// 中止分片上传后对象不应存在
+	abortObject := "videos/2025/08/aborted-video.mp4"
+	abortID, err := storage.InitiateMultipartUpload(ctx, testBucket, abortObject, "video/mp4")
+	require.NoError(t, err)
+	_, err = storage.UploadPart(ctx, testBucket, abortObject, abortID, 1, bytes.NewReader(second), int64(len(second)))
+	require.NoError(t, err)
+	assert.NoError(t, storage.AbortMultipartUpload(ctx, testBucket, abortObject, abortID), "中止分片上传应该成功")
+
+	exists, err := storage.FileExists(ctx, testBucket, abortObject)
+	assert.NoError(t, err)
+	assert.False(t, exists, "中止后对象不应存在")
+}
+
 // TestMinIOStorage_ListFiles 测试文件列表（需要真实服务）
 func TestMinIOStorage_ListFiles(t *testing.T) {
 	if !isMinIOAvailable() {
@@ -202,6 +385,105 @@ func TestMinIOStorage_FileExists_NotFound(t *testing.T) {
 	assert.False(t, exists, "不存在的文件应该返回false")
 }
 
+// TestMinIOStorage_ObjectMetadataAndTags 测试上传时附加用户元数据和对象标签（需要真实服务）
+func TestMinIOStorage_ObjectMetadataAndTags(t *testing.T) {
+	if !isMinIOAvailable() {
+		t.Skip("跳过测试：MinIO服务不可用")
+	}
+
+	storage := setupTestStorage(t)
+	ctx := context.Background()
+	testBucket := "test-bucket-" + generateTestID()
+
+	err := storage.CreateBucket(ctx, testBucket)
+	require.NoError(t, err)
+
+	objectName := "videos/2025/08/tagged.mp4"
+	defer func() {
+		_ = storage.DeleteFile(ctx, testBucket, objectName)
+		_ = storage.RemoveBucket(ctx, testBucket)
+	}()
+
+	_, err = storage.UploadFile(ctx, testBucket, objectName, []byte("视频数据"), "video/mp4", ObjectOptions{
+		Metadata: map[string]string{ObjectKeyVideoID: "video-1", ObjectKeyUploader: "user-1"},
+		Tags:     map[string]string{ObjectKeyVideoID: "video-1"},
+	})
+	require.NoError(t, err, "带元数据和标签上传应该成功")
+
+	fileInfo, err := storage.GetFileInfo(ctx, testBucket, objectName)
+	require.NoError(t, err)
+	assert.Equal(t, "video-1", fileInfo.Metadata[ObjectKeyVideoID], "用户元数据键应该转为小写")
+	assert.Equal(t, "user-1", fileInfo.Metadata[ObjectKeyUploader])
+
+	tags, err := storage.GetObjectTags(ctx, testBucket, objectName)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{ObjectKeyVideoID: "video-1"}, tags)
+
+	err = storage.SetObjectTags(ctx, testBucket, objectName, map[string]string{ObjectKeyContentHash: "abc"})
+	require.NoError(t, err)
+	tags, err = storage.GetObjectTags(ctx, testBucket, objectName)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{ObjectKeyContentHash: "abc"}, tags, "设置标签应该替换全部标签")
+}
+
+// TestMinIOStorage_CopyAndMoveFile 测试服务端复制和移动文件（需要真实服务）
+func TestMinIOStorage_CopyAndMoveFile(t *testing.T) {
+	if !isMinIOAvailable() {
+		t.Skip("跳过测试：MinIO服务不可用")
+	}
+
+	storage := setupTestStorage(t)
+	ctx := context.Background()
+	testBucket := "test-bucket-" + generateTestID()
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+
+	source, copied, moved := "videos/source.mp4", "videos/copied.mp4", "videos/moved.mp4"
+	defer func() {
+		_, _ = storage.DeleteFiles(ctx, testBucket, []string{source, copied, moved})
+		_ = storage.RemoveBucket(ctx, testBucket)
+	}()
+
+	_, err := storage.UploadFile(ctx, testBucket, source, []byte("视频数据"), "video/mp4", ObjectOptions{
+		Metadata: map[string]string{ObjectKeyVideoID: "video-1"},
+		Tags:     map[string]string{ObjectKeyVideoID: "video-1"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, storage.CopyFile(ctx, testBucket, source, testBucket, copied))
+	fileInfo, err := storage.GetFileInfo(ctx, testBucket, copied)
+	require.NoError(t, err)
+	assert.Equal(t, "video/mp4", fileInfo.ContentType, "复制应该保留内容类型")
+	assert.Equal(t, "video-1", fileInfo.Metadata[ObjectKeyVideoID], "复制应该保留用户元数据")
+	tags, err := storage.GetObjectTags(ctx, testBucket, copied)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{ObjectKeyVideoID: "video-1"}, tags, "复制应该保留对象标签")
+
+	require.NoError(t, storage.MoveFile(ctx, testBucket, copied, testBucket, moved))
+	exists, err := storage.FileExists(ctx, testBucket, copied)
+	require.NoError(t, err)
+	assert.False(t, exists, "移动后源文件应该被删除")
+	data, err := storage.DownloadFile(ctx, testBucket, moved)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("视频数据"), data)
+
+	assert.ErrorIs(t, storage.MoveFile(ctx, testBucket, moved, testBucket, moved), ErrSameObject)
+	assert.Error(t, storage.CopyFile(ctx, testBucket, "videos/missing.mp4", testBucket, copied))
+}
+
+// TestMergeObjectOptions 测试合并上传选项
+func TestMergeObjectOptions(t *testing.T) {
+	merged := mergeObjectOptions(nil)
+	assert.Nil(t, merged.Metadata, "没有选项时不应该创建元数据")
+	assert.Nil(t, merged.Tags)
+
+	merged = mergeObjectOptions([]ObjectOptions{
+		{Metadata: map[string]string{"a": "1", "b": "2"}},
+		{Metadata: map[string]string{"b": "3"}, Tags: map[string]string{"t": "x"}},
+	})
+	assert.Equal(t, map[string]string{"a": "1", "b": "3"}, merged.Metadata, "后面的选项应该覆盖同名键")
+	assert.Equal(t, map[string]string{"t": "x"}, merged.Tags)
+}
+
 // isMinIOAvailable 检查MinIO服务是否可用
 func isMinIOAvailable() bool {
 	// 检查环境变量或其他方式来确定是否应该运行需要MinIO的测试
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// PublicEndpoint 预签名URL的对外访问地址
+// 存储服务位于反向代理之后或客户端在其他网段时，存储服务的内网地址无法访问，生成的预签名URL改用对外地址
+type PublicEndpoint struct {
+	Scheme     string // 协议，为空时保持原协议
+	Host       string // 主机，可以带端口
+	PathPrefix string // 路径前缀，如/minio，反向代理转发前需要去掉
+}
+
+// ParsePublicEndpoint 解析对外访问地址，都为空时返回nil
+// baseURL为完整的对外基础URL（如https://cdn.example.com/minio），只需要替换主机名时使用host（如nas.lan:9000），两者不能同时配置
+func ParsePublicEndpoint(baseURL, host string) (*PublicEndpoint, error) {
+	switch {
+	case baseURL == "" && host == "":
+		return nil, nil
+	case baseURL != "" && host != "":
+		return nil, fmt.Errorf("对外基础URL和对外主机不能同时配置")
+	case host != "":
+		if strings.ContainsAny(host, "/?#@") {
+			return nil, fmt.Errorf("对外主机不能包含协议或路径: %s", host)
+		}
+		if hostname, _, err := net.SplitHostPort(host); err == nil && hostname == "" {
+			return nil, fmt.Errorf("对外主机缺少主机名: %s", host)
+		}
+		return &PublicEndpoint{Host: host}, nil
+	}
+
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("无效的对外基础URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("对外基础URL必须使用http或https: %s", baseURL)
+	}
+	if parsed.Host == "" {
+		return nil, fmt.Errorf("对外基础URL缺少主机: %s", baseURL)
+	}
+	if parsed.User != nil || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return nil, fmt.Errorf("对外基础URL不能包含用户信息或查询参数: %s", baseURL)
+	}
+	return &PublicEndpoint{
+		Scheme:     parsed.Scheme,
+		Host:       parsed.Host,
+		PathPrefix: strings.TrimSuffix(parsed.Path, "/"),
+	}, nil
+}
+
+// Rewrite 将URL的协议和主机替换为对外地址，对外地址的路径作为前缀，保留原有路径和查询参数
+func (e *PublicEndpoint) Rewrite(rawURL string) (string, error) {
+	if e == nil {
+		return rawURL, nil
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("解析URL失败: %w", err)
+	}
+	e.apply(parsed)
+	return parsed.String(), nil
+}
+
+// apply 替换URL的协议、主机和路径前缀
+func (e *PublicEndpoint) apply(u *url.URL) {
+	if e.Scheme != "" {
+		u.Scheme = e.Scheme
+	}
+	u.Host = e.Host
+	if e.PathPrefix != "" {
+		u.Path = e.PathPrefix + u.Path
+		if u.RawPath != "" {
+			u.RawPath = (&url.URL{Path: e.PathPrefix}).EscapedPath() + u.RawPath
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// ObjectReaderAt 通过范围读取随机访问存储对象，用于只需要读取文件少量片段的解析场景
+type ObjectReaderAt struct {
+	ctx        context.Context
+	storage    StorageInterface
+	bucketName string
+	objectName string
+	size       int64
+}
+
+// NewObjectReaderAt 创建存储对象随机访问读取器，size为对象大小
+func NewObjectReaderAt(ctx context.Context, storage StorageInterface, bucketName, objectName string, size int64) *ObjectReaderAt {
+	return &ObjectReaderAt{
+		ctx:        ctx,
+		storage:    storage,
+		bucketName: bucketName,
+		objectName: objectName,
+		size:       size,
+	}
+}
+
+// ReadAt 读取从off开始的len(p)字节，超出对象末尾时返回io.EOF
+func (r *ObjectReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("无效的读取位置: %d", off)
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	length := int64(len(p))
+	if remaining := r.size - off; length > remaining {
+		length = remaining
+	}
+
+	reader, err := r.storage.OpenFileRange(r.ctx, r.bucketName, r.objectName, off, length)
+	if err != nil {
+		return 0, fmt.Errorf("读取对象范围失败: %w", err)
+	}
+	defer reader.Close()
+
+	n, err := io.ReadFull(reader, p[:length])
+	if err != nil {
+		return n, fmt.Errorf("读取对象范围失败: %w", err)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectReaderAt(t *testing.T) {
+	storage := setupLocalStorage(t)
+	ctx := context.Background()
+
+	_, err := storage.UploadFile(ctx, "test-bucket", "reader.mp4", []byte("0123456789"), "video/mp4")
+	require.NoError(t, err)
+
+	reader := NewObjectReaderAt(ctx, storage, "test-bucket", "reader.mp4", 10)
+
+	buf := make([]byte, 4)
+	n, err := reader.ReadAt(buf, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, "3456", string(buf))
+
+	n, err = reader.ReadAt(buf, 8)
+	assert.Equal(t, io.EOF, err, "读取超出末尾时应该返回io.EOF")
+	assert.Equal(t, 2, n)
+	assert.Equal(t, "89", string(buf[:n]))
+
+	n, err = reader.ReadAt(buf, 10)
+	assert.Equal(t, io.EOF, err)
+	assert.Zero(t, n)
+
+	_, err = reader.ReadAt(buf, -1)
+	assert.Error(t, err)
+
+	missing := NewObjectReaderAt(ctx, storage, "test-bucket", "missing.mp4", 10)
+	_, err = missing.ReadAt(buf, 0)
+	assert.Error(t, err, "对象不存在时应该返回错误")
+}
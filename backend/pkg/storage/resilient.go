@@ -0,0 +1,467 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// ErrCircuitOpen 存储服务连续失败后熔断，调用在熔断期间直接失败
+var ErrCircuitOpen = errors.New("存储服务暂时不可用")
+
+// 熔断器状态
+const (
+	CircuitClosed   = "closed"    // 正常放行
+	CircuitOpen     = "open"      // 熔断中，直接拒绝调用
+	CircuitHalfOpen = "half_open" // 熔断时间已过，放行一次探测调用
+)
+
+// ResilienceOptions 存储调用的重试和熔断选项
+type ResilienceOptions struct {
+	MaxAttempts      int           // 幂等操作的最多尝试次数（包括首次），不大于1时不重试
+	BaseDelay        time.Duration // 第一次重试前的最长等待时间，之后每次翻倍，实际等待时间在0到该值之间随机
+	MaxDelay         time.Duration // 重试等待时间的上限
+	FailureThreshold int           // 连续失败多少次后熔断，为0时不熔断
+	OpenDuration     time.Duration // 熔断持续时间，之后放行一次探测调用，成功后恢复
+}
+
+// enabled 判断是否需要重试或熔断
+func (o ResilienceOptions) enabled() bool {
+	return o.MaxAttempts > 1 || o.FailureThreshold > 0
+}
+
+// ResilienceStats 存储调用的重试和熔断统计
+type ResilienceStats struct {
+	Calls        int64     // 实际发往存储服务的调用次数，包括重试
+	Retries      int64     // 重试次数
+	Failures     int64     // 重试后仍因临时错误失败的操作次数
+	Rejected     int64     // 熔断期间直接拒绝的操作次数
+	CircuitState string    // 熔断器状态
+	CircuitOpens int64     // 熔断次数
+	LastError    string    // 最近一次临时错误
+	LastErrorAt  time.Time // 最近一次临时错误的时间
+}
+
+// IsTransient 判断错误是否为临时错误：网络错误、连接中断、超时和存储服务的5xx、429响应
+// 文件不存在、权限不足等错误和调用方取消不是临时错误
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var response minio.ErrorResponse
+	if errors.As(err, &response) {
+		switch response.Code {
+		case "RequestTimeout", "SlowDown", "InternalError", "ServiceUnavailable", "XMinioServerNotInitialized":
+			return true
+		}
+		return response.StatusCode >= http.StatusInternalServerError || response.StatusCode == http.StatusTooManyRequests
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
+
+// ResilientStorage 在存储服务出现临时错误时重试幂等操作，连续失败后熔断
+// 重试的操作：查询、下载、打开文件、列出、删除、对象标签、上传字节数据和服务端复制，等待时间按指数退避并加入随机抖动；
+// 读取流、上传流、分片上传和移动文件无法安全重复，只经过熔断器；预签名URL在本地生成，直接使用被包装的存储
+type ResilientStorage struct {
+	storage StorageInterface
+	opts    ResilienceOptions
+	breaker *circuitBreaker
+	stats   ResilienceStats
+	mutex   sync.Mutex
+}
+
+var _ StorageInterface = (*ResilientStorage)(nil)
+
+// WithResilience 包装存储服务，不重试也不熔断时直接返回storage
+func WithResilience(storage StorageInterface, opts ResilienceOptions) StorageInterface {
+	if !opts.enabled() {
+		return storage
+	}
+	return &ResilientStorage{
+		storage: storage,
+		opts:    opts,
+		breaker: &circuitBreaker{threshold: opts.FailureThreshold, openDuration: opts.OpenDuration, state: CircuitClosed},
+	}
+}
+
+// FindResilientStorage 在包装链中查找ResilientStorage，未启用重试和熔断时返回false
+func FindResilientStorage(storage StorageInterface) (*ResilientStorage, bool) {
+	for {
+		if resilient, ok := storage.(*ResilientStorage); ok {
+			return resilient, true
+		}
+		wrapper, ok := storage.(interface{ Unwrap() StorageInterface })
+		if !ok {
+			return nil, false
+		}
+		storage = wrapper.Unwrap()
+	}
+}
+
+// Unwrap 获取被包装的存储服务
+func (s *ResilientStorage) Unwrap() StorageInterface {
+	return s.storage
+}
+
+// Stats 获取重试和熔断统计快照
+func (s *ResilientStorage) Stats() ResilienceStats {
+	s.mutex.Lock()
+	stats := s.stats
+	s.mutex.Unlock()
+	stats.CircuitState, stats.CircuitOpens = s.breaker.snapshot()
+	return stats
+}
+
+// update 在锁内更新统计
+func (s *ResilientStorage) update(fn func(stats *ResilienceStats)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	fn(&s.stats)
+}
+
+// retry 执行操作，retryable为true时在临时错误后按退避时间重试
+// 操作超时不重试，避免存储服务无响应时请求等待数倍的超时时间，但计入熔断
+func retry[T any](ctx context.Context, s *ResilientStorage, op string, retryable bool, fn func(ctx context.Context) (T, error)) (T, error) {
+	attempts := 1
+	if retryable {
+		attempts = max(s.opts.MaxAttempts, 1)
+	}
+
+	var result T
+	var err error
+	for attempt := 1; ; attempt++ {
+		if !s.breaker.allow() {
+			s.update(func(stats *ResilienceStats) { stats.Rejected++ })
+			recordUnavailable(ctx)
+			return result, fmt.Errorf("%s: %w", op, ErrCircuitOpen)
+		}
+
+		s.update(func(stats *ResilienceStats) { stats.Calls++ })
+		result, err = fn(ctx)
+		transient := err != nil && ctx.Err() == nil && IsTransient(err)
+		switch {
+		case transient:
+			s.breaker.record(outcomeFailure)
+		case err != nil && ctx.Err() != nil:
+			// 调用方取消时无法判断存储服务是否正常
+			s.breaker.record(outcomeUnknown)
+		default:
+			s.breaker.record(outcomeSuccess)
+		}
+		if !transient {
+			return result, err
+		}
+
+		s.update(func(stats *ResilienceStats) {
+			stats.LastError = fmt.Sprintf("%s: %v", op, err)
+			stats.LastErrorAt = time.Now()
+		})
+		if attempt >= attempts || errors.Is(err, context.DeadlineExceeded) || !s.wait(ctx, attempt) {
+			s.update(func(stats *ResilienceStats) { stats.Failures++ })
+			return result, err
+		}
+		s.update(func(stats *ResilienceStats) { stats.Retries++ })
+	}
+}
+
+// retryErr 执行没有返回值的操作
+func retryErr(ctx context.Context, s *ResilientStorage, op string, retryable bool, fn func(ctx context.Context) error) error {
+	_, err := retry(ctx, s, op, retryable, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// wait 等待第attempt次失败后的退避时间，调用方取消时返回false
+func (s *ResilientStorage) wait(ctx context.Context, attempt int) bool {
+	ceiling := s.opts.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || (s.opts.MaxDelay > 0 && ceiling > s.opts.MaxDelay) {
+		ceiling = s.opts.MaxDelay
+	}
+	if ceiling <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(rand.N(ceiling + 1))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// 调用结果
+const (
+	outcomeSuccess = iota // 成功或非临时错误，存储服务正常响应
+	outcomeFailure        // 临时错误
+	outcomeUnknown        // 调用方取消，不影响熔断状态
+)
+
+// circuitBreaker 连续失败threshold次后熔断openDuration，之后放行一次探测调用
+type circuitBreaker struct {
+	threshold    int
+	openDuration time.Duration
+	mutex        sync.Mutex
+	state        string
+	failures     int
+	openedAt     time.Time
+	probing      bool
+	opens        int64
+}
+
+// allow 判断是否放行调用
+func (b *circuitBreaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if time.Since(b.openedAt) < b.openDuration {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		return true
+	case CircuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record 记录调用结果
+func (b *circuitBreaker) record(outcome int) {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch outcome {
+	case outcomeSuccess:
+		b.state = CircuitClosed
+		b.failures = 0
+		b.probing = false
+	case outcomeFailure:
+		b.failures++
+		if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+			if b.state != CircuitOpen {
+				b.opens++
+			}
+			b.state = CircuitOpen
+			b.openedAt = time.Now()
+			b.probing = false
+		}
+	default:
+		// 探测调用被取消时允许下一次调用继续探测
+		if b.state == CircuitHalfOpen {
+			b.probing = false
+		}
+	}
+}
+
+// snapshot 获取熔断器状态和熔断次数
+func (b *circuitBreaker) snapshot() (string, int64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.openDuration {
+		return CircuitHalfOpen, b.opens
+	}
+	return b.state, b.opens
+}
+
+// TestConnection 测试连接，不重试以便如实反映存储服务状态
+func (s *ResilientStorage) TestConnection(ctx context.Context) error {
+	return retryErr(ctx, s, "TestConnection", false, s.storage.TestConnection)
+}
+
+// BucketExists 检查存储桶是否存在
+func (s *ResilientStorage) BucketExists(ctx context.Context, bucketName string) (bool, error) {
+	return retry(ctx, s, "BucketExists", true, func(ctx context.Context) (bool, error) {
+		return s.storage.BucketExists(ctx, bucketName)
+	})
+}
+
+// CreateBucket 创建存储桶
+func (s *ResilientStorage) CreateBucket(ctx context.Context, bucketName string) error {
+	return retryErr(ctx, s, "CreateBucket", false, func(ctx context.Context) error {
+		return s.storage.CreateBucket(ctx, bucketName)
+	})
+}
+
+// RemoveBucket 删除存储桶
+func (s *ResilientStorage) RemoveBucket(ctx context.Context, bucketName string) error {
+	return retryErr(ctx, s, "RemoveBucket", false, func(ctx context.Context) error {
+		return s.storage.RemoveBucket(ctx, bucketName)
+	})
+}
+
+// UploadFile 上传文件，数据在内存中，可以重复上传
+func (s *ResilientStorage) UploadFile(ctx context.Context, bucketName, objectName string, data []byte, contentType string, opts ...ObjectOptions) (*UploadResult, error) {
+	return retry(ctx, s, "UploadFile", true, func(ctx context.Context) (*UploadResult, error) {
+		return s.storage.UploadFile(ctx, bucketName, objectName, data, contentType, opts...)
+	})
+}
+
+// UploadStream 流式上传文件，读取器无法重复读取，不重试
+func (s *ResilientStorage) UploadStream(ctx context.Context, bucketName, objectName string, reader io.Reader, size int64, contentType string, opts ...ObjectOptions) (*UploadResult, error) {
+	return retry(ctx, s, "UploadStream", false, func(ctx context.Context) (*UploadResult, error) {
+		return s.storage.UploadStream(ctx, bucketName, objectName, reader, size, contentType, opts...)
+	})
+}
+
+// DownloadFile 下载文件
+func (s *ResilientStorage) DownloadFile(ctx context.Context, bucketName, objectName string) ([]byte, error) {
+	return retry(ctx, s, "DownloadFile", true, func(ctx context.Context) ([]byte, error) {
+		return s.storage.DownloadFile(ctx, bucketName, objectName)
+	})
+}
+
+// OpenFile 打开文件，只重试打开，读取过程中的错误由读取方处理
+func (s *ResilientStorage) OpenFile(ctx context.Context, bucketName, objectName string) (io.ReadCloser, error) {
+	return retry(ctx, s, "OpenFile", true, func(ctx context.Context) (io.ReadCloser, error) {
+		return s.storage.OpenFile(ctx, bucketName, objectName)
+	})
+}
+
+// OpenFileRange 打开文件的指定范围，只重试打开
+func (s *ResilientStorage) OpenFileRange(ctx context.Context, bucketName, objectName string, offset, length int64) (io.ReadCloser, error) {
+	return retry(ctx, s, "OpenFileRange", true, func(ctx context.Context) (io.ReadCloser, error) {
+		return s.storage.OpenFileRange(ctx, bucketName, objectName, offset, length)
+	})
+}
+
+// FileExists 检查文件是否存在
+func (s *ResilientStorage) FileExists(ctx context.Context, bucketName, objectName string) (bool, error) {
+	return retry(ctx, s, "FileExists", true, func(ctx context.Context) (bool, error) {
+		return s.storage.FileExists(ctx, bucketName, objectName)
+	})
+}
+
+// GetFileInfo 获取文件信息
+func (s *ResilientStorage) GetFileInfo(ctx context.Context, bucketName, objectName string) (*FileInfo, error) {
+	return retry(ctx, s, "GetFileInfo", true, func(ctx context.Context) (*FileInfo, error) {
+		return s.storage.GetFileInfo(ctx, bucketName, objectName)
+	})
+}
+
+// DeleteFile 删除文件，文件不存在时视为删除成功，可以重复删除
+func (s *ResilientStorage) DeleteFile(ctx context.Context, bucketName, objectName string) error {
+	return retryErr(ctx, s, "DeleteFile", true, func(ctx context.Context) error {
+		return s.storage.DeleteFile(ctx, bucketName, objectName)
+	})
+}
+
+// DeleteFiles 批量删除文件，整个请求失败时重试
+func (s *ResilientStorage) DeleteFiles(ctx context.Context, bucketName string, objectNames []string) (map[string]error, error) {
+	return retry(ctx, s, "DeleteFiles", true, func(ctx context.Context) (map[string]error, error) {
+		return s.storage.DeleteFiles(ctx, bucketName, objectNames)
+	})
+}
+
+// ListFiles 列出文件
+func (s *ResilientStorage) ListFiles(ctx context.Context, bucketName, prefix string) ([]*FileInfo, error) {
+	return retry(ctx, s, "ListFiles", true, func(ctx context.Context) ([]*FileInfo, error) {
+		return s.storage.ListFiles(ctx, bucketName, prefix)
+	})
+}
+
+// CopyFile 服务端复制文件，目标文件已存在时覆盖，可以重复复制
+func (s *ResilientStorage) CopyFile(ctx context.Context, srcBucket, srcObject, dstBucket, dstObject string) error {
+	return retryErr(ctx, s, "CopyFile", true, func(ctx context.Context) error {
+		return s.storage.CopyFile(ctx, srcBucket, srcObject, dstBucket, dstObject)
+	})
+}
+
+// MoveFile 移动文件，删除源文件后重试会失败，不重试
+func (s *ResilientStorage) MoveFile(ctx context.Context, srcBucket, srcObject, dstBucket, dstObject string) error {
+	return retryErr(ctx, s, "MoveFile", false, func(ctx context.Context) error {
+		return s.storage.MoveFile(ctx, srcBucket, srcObject, dstBucket, dstObject)
+	})
+}
+
+// GetObjectTags 获取对象标签
+func (s *ResilientStorage) GetObjectTags(ctx context.Context, bucketName, objectName string) (map[string]string, error) {
+	return retry(ctx, s, "GetObjectTags", true, func(ctx context.Context) (map[string]string, error) {
+		return s.storage.GetObjectTags(ctx, bucketName, objectName)
+	})
+}
+
+// SetObjectTags 替换对象标签，可以重复设置
+func (s *ResilientStorage) SetObjectTags(ctx context.Context, bucketName, objectName string, tags map[string]string) error {
+	return retryErr(ctx, s, "SetObjectTags", true, func(ctx context.Context) error {
+		return s.storage.SetObjectTags(ctx, bucketName, objectName, tags)
+	})
+}
+
+// InitiateMultipartUpload 初始化分片上传，每次调用创建新的上传，不重试
+func (s *ResilientStorage) InitiateMultipartUpload(ctx context.Context, bucketName, objectName, contentType string, opts ...ObjectOptions) (string, error) {
+	return retry(ctx, s, "InitiateMultipartUpload", false, func(ctx context.Context) (string, error) {
+		return s.storage.InitiateMultipartUpload(ctx, bucketName, objectName, contentType, opts...)
+	})
+}
+
+// UploadPart 上传分片，读取器无法重复读取，不重试
+func (s *ResilientStorage) UploadPart(ctx context.Context, bucketName, objectName, uploadID string, partNumber int, reader io.Reader, size int64) (*PartInfo, error) {
+	return retry(ctx, s, "UploadPart", false, func(ctx context.Context) (*PartInfo, error) {
+		return s.storage.UploadPart(ctx, bucketName, objectName, uploadID, partNumber, reader, size)
+	})
+}
+
+// CompleteMultipartUpload 完成分片上传，完成后上传ID失效，不重试
+func (s *ResilientStorage) CompleteMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string, parts []CompletePart) (*UploadResult, error) {
+	return retry(ctx, s, "CompleteMultipartUpload", false, func(ctx context.Context) (*UploadResult, error) {
+		return s.storage.CompleteMultipartUpload(ctx, bucketName, objectName, uploadID, parts)
+	})
+}
+
+// ListParts 列出已上传的分片
+func (s *ResilientStorage) ListParts(ctx context.Context, bucketName, objectName, uploadID string) ([]*PartInfo, error) {
+	return retry(ctx, s, "ListParts", true, func(ctx context.Context) ([]*PartInfo, error) {
+		return s.storage.ListParts(ctx, bucketName, objectName, uploadID)
+	})
+}
+
+// AbortMultipartUpload 取消分片上传，取消后上传ID失效，不重试
+func (s *ResilientStorage) AbortMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string) error {
+	return retryErr(ctx, s, "AbortMultipartUpload", false, func(ctx context.Context) error {
+		return s.storage.AbortMultipartUpload(ctx, bucketName, objectName, uploadID)
+	})
+}
+
+// GetPresignedURL 生成预签名下载URL
+func (s *ResilientStorage) GetPresignedURL(ctx context.Context, bucketName, objectName string, expiry time.Duration) (string, error) {
+	return s.storage.GetPresignedURL(ctx, bucketName, objectName, expiry)
+}
+
+// GeneratePresignedURL 生成指定方法的预签名URL
+func (s *ResilientStorage) GeneratePresignedURL(ctx context.Context, bucketName, objectName string, expiry time.Duration, method string) (string, error) {
+	return s.storage.GeneratePresignedURL(ctx, bucketName, objectName, expiry, method)
+}
@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyStorage 前failures次调用返回err的存储服务
+type flakyStorage struct {
+	*LocalStorage
+	failures atomic.Int32
+	calls    atomic.Int32
+	err      error
+}
+
+// fail 返回剩余的错误
+func (s *flakyStorage) fail() error {
+	s.calls.Add(1)
+	if s.failures.Add(-1) >= 0 {
+		return s.err
+	}
+	return nil
+}
+
+func (s *flakyStorage) GetFileInfo(ctx context.Context, bucketName, objectName string) (*FileInfo, error) {
+	if err := s.fail(); err != nil {
+		return nil, fmt.Errorf("获取文件信息失败: %w", err)
+	}
+	return &FileInfo{Key: objectName, Size: 4}, nil
+}
+
+func (s *flakyStorage) UploadStream(ctx context.Context, bucketName, objectName string, reader io.Reader, size int64, contentType string, opts ...ObjectOptions) (*UploadResult, error) {
+	if err := s.fail(); err != nil {
+		return nil, err
+	}
+	return &UploadResult{Size: size}, nil
+}
+
+// newFlakyStorage 创建前failures次调用返回err的存储服务
+func newFlakyStorage(t *testing.T, failures int32, err error) *flakyStorage {
+	storage := &flakyStorage{LocalStorage: setupLocalStorage(t), err: err}
+	storage.failures.Store(failures)
+	return storage
+}
+
+// unavailable 存储服务返回503
+var unavailable = minio.ErrorResponse{StatusCode: 503, Code: "ServiceUnavailable", Message: "Service unavailable"}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"空错误", nil, false},
+		{"调用方取消", fmt.Errorf("失败: %w", context.Canceled), false},
+		{"操作超时", fmt.Errorf("失败: %w", context.DeadlineExceeded), true},
+		{"连接中断", io.ErrUnexpectedEOF, true},
+		{"网络错误", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"存储服务503", fmt.Errorf("失败: %w", unavailable), true},
+		{"限流", minio.ErrorResponse{StatusCode: 503, Code: "SlowDown"}, true},
+		{"文件不存在", minio.ErrorResponse{StatusCode: 404, Code: "NoSuchKey"}, false},
+		{"权限不足", minio.ErrorResponse{StatusCode: 403, Code: "AccessDenied"}, false},
+		{"本地文件不存在", &os.PathError{Op: "open", Path: "a", Err: os.ErrNotExist}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsTransient(tt.err))
+		})
+	}
+}
+
+func TestResilientStorage(t *testing.T) {
+	opts := ResilienceOptions{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	ctx := context.Background()
+
+	t.Run("临时错误后重试成功", func(t *testing.T) {
+		flaky := newFlakyStorage(t, 2, unavailable)
+		storage := WithResilience(flaky, opts).(*ResilientStorage)
+
+		info, err := storage.GetFileInfo(ctx, "bucket", "object")
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), info.Size)
+		assert.Equal(t, int32(3), flaky.calls.Load())
+
+		stats := storage.Stats()
+		assert.Equal(t, int64(3), stats.Calls)
+		assert.Equal(t, int64(2), stats.Retries)
+		assert.Zero(t, stats.Failures)
+		assert.Contains(t, stats.LastError, "GetFileInfo")
+		assert.Equal(t, CircuitClosed, stats.CircuitState)
+	})
+
+	t.Run("超过尝试次数后返回错误", func(t *testing.T) {
+		flaky := newFlakyStorage(t, 5, unavailable)
+		storage := WithResilience(flaky, opts).(*ResilientStorage)
+
+		_, err := storage.GetFileInfo(ctx, "bucket", "object")
+		assert.ErrorAs(t, err, &minio.ErrorResponse{})
+		assert.Equal(t, int32(3), flaky.calls.Load())
+		assert.Equal(t, int64(1), storage.Stats().Failures)
+	})
+
+	t.Run("非临时错误和不可重复的操作不重试", func(t *testing.T) {
+		flaky := newFlakyStorage(t, 5, minio.ErrorResponse{StatusCode: 404, Code: "NoSuchKey"})
+		storage := WithResilience(flaky, opts)
+		_, err := storage.GetFileInfo(ctx, "bucket", "object")
+		require.Error(t, err)
+		assert.Equal(t, int32(1), flaky.calls.Load())
+
+		flaky = newFlakyStorage(t, 5, unavailable)
+		storage = WithResilience(flaky, opts)
+		_, err = storage.UploadStream(ctx, "bucket", "object", strings.NewReader("data"), 4, "text/plain")
+		require.Error(t, err)
+		assert.Equal(t, int32(1), flaky.calls.Load(), "读取器已被消费，不能重试")
+	})
+
+	t.Run("操作超时不重试", func(t *testing.T) {
+		flaky := newFlakyStorage(t, 5, fmt.Errorf("存储操作GetFileInfo超时: %w", context.DeadlineExceeded))
+		storage := WithResilience(flaky, opts)
+
+		_, err := storage.GetFileInfo(ctx, "bucket", "object")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, int32(1), flaky.calls.Load())
+	})
+
+	t.Run("连续失败后熔断并在探测成功后恢复", func(t *testing.T) {
+		flaky := newFlakyStorage(t, 2, unavailable)
+		storage := WithResilience(flaky, ResilienceOptions{FailureThreshold: 2, OpenDuration: 50 * time.Millisecond}).(*ResilientStorage)
+		recorded := WithFailureRecorder(ctx)
+
+		for i := 0; i < 2; i++ {
+			_, err := storage.GetFileInfo(recorded, "bucket", "object")
+			require.Error(t, err)
+		}
+		assert.Equal(t, CircuitOpen, storage.Stats().CircuitState)
+		assert.False(t, Unavailable(recorded))
+
+		_, err := storage.GetFileInfo(recorded, "bucket", "object")
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.Equal(t, int32(2), flaky.calls.Load(), "熔断期间不调用存储服务")
+		assert.True(t, Unavailable(recorded))
+
+		time.Sleep(60 * time.Millisecond)
+		assert.Equal(t, CircuitHalfOpen, storage.Stats().CircuitState)
+		_, err = storage.GetFileInfo(ctx, "bucket", "object")
+		require.NoError(t, err)
+
+		stats := storage.Stats()
+		assert.Equal(t, CircuitClosed, stats.CircuitState)
+		assert.Equal(t, int64(1), stats.CircuitOpens)
+		assert.Equal(t, int64(1), stats.Rejected)
+	})
+
+	t.Run("调用方取消时停止重试", func(t *testing.T) {
+		flaky := newFlakyStorage(t, 5, unavailable)
+		storage := WithResilience(flaky, ResilienceOptions{MaxAttempts: 5, BaseDelay: time.Minute})
+		cancelCtx, cancel := context.WithCancel(ctx)
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		_, err := storage.GetFileInfo(cancelCtx, "bucket", "object")
+		require.Error(t, err)
+		assert.Equal(t, int32(1), flaky.calls.Load())
+	})
+
+	t.Run("查找包装链中的ResilientStorage", func(t *testing.T) {
+		local := setupLocalStorage(t)
+		wrapped := WithResilience(WithTimeouts(local, Timeouts{Metadata: time.Second}), opts)
+
+		resilient, ok := FindResilientStorage(wrapped)
+		require.True(t, ok)
+		assert.Same(t, wrapped, resilient)
+		assert.Same(t, local, Underlying(wrapped))
+
+		_, ok = FindResilientStorage(local)
+		assert.False(t, ok)
+		assert.Same(t, local, WithResilience(local, ResilienceOptions{MaxAttempts: 1}), "不重试也不熔断时不包装")
+	})
+}
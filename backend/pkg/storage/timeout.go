@@ -0,0 +1,383 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// Timeouts 各类存储操作的超时时间，为0时不限制
+type Timeouts struct {
+	Metadata time.Duration // 查询文件信息、删除单个文件、对象标签、存储桶、分片上传管理和预签名URL等操作
+	Batch    time.Duration // 列出文件和批量删除
+	Upload   time.Duration // 上传文件、上传分片、复制和移动文件的总时长
+	Download time.Duration // 打开文件和每次读取时等待数据的最长时间；DownloadFile整体不超过该时间
+}
+
+// IsZero 判断是否所有操作都不限制超时时间
+func (t Timeouts) IsZero() bool {
+	return t.Metadata <= 0 && t.Batch <= 0 && t.Upload <= 0 && t.Download <= 0
+}
+
+// failureRecorderKey 上下文中记录存储操作失败类型的键
+type failureRecorderKey struct{}
+
+// failureRecorder 记录请求处理过程中存储操作是否超时或因熔断被拒绝
+type failureRecorder struct {
+	timedOut    atomic.Bool
+	unavailable atomic.Bool
+}
+
+// WithFailureRecorder 返回记录存储操作失败类型的上下文，之后通过TimedOut和Unavailable判断使用该上下文的存储操作是否超时或被拒绝
+func WithFailureRecorder(ctx context.Context) context.Context {
+	return context.WithValue(ctx, failureRecorderKey{}, new(failureRecorder))
+}
+
+// TimedOut 判断使用ctx的存储操作是否因超过超时时间失败，ctx未经过WithFailureRecorder时返回false
+func TimedOut(ctx context.Context) bool {
+	recorder, ok := ctx.Value(failureRecorderKey{}).(*failureRecorder)
+	return ok && recorder.timedOut.Load()
+}
+
+// Unavailable 判断使用ctx的存储操作是否因熔断被拒绝，ctx未经过WithFailureRecorder时返回false
+func Unavailable(ctx context.Context) bool {
+	recorder, ok := ctx.Value(failureRecorderKey{}).(*failureRecorder)
+	return ok && recorder.unavailable.Load()
+}
+
+// recordTimeout 在上下文中记录存储操作超时
+func recordTimeout(ctx context.Context) {
+	if recorder, ok := ctx.Value(failureRecorderKey{}).(*failureRecorder); ok {
+		recorder.timedOut.Store(true)
+	}
+}
+
+// recordUnavailable 在上下文中记录存储操作因熔断被拒绝
+func recordUnavailable(ctx context.Context) {
+	if recorder, ok := ctx.Value(failureRecorderKey{}).(*failureRecorder); ok {
+		recorder.unavailable.Store(true)
+	}
+}
+
+// TimeoutStorage 为每个存储操作设置超时时间，存储服务无响应时操作返回包装context.DeadlineExceeded的错误，不会一直阻塞
+// 调用方的上下文被取消（如客户端断开连接）时操作立即中断，返回存储服务的原始错误
+type TimeoutStorage struct {
+	storage  StorageInterface
+	timeouts Timeouts
+}
+
+var _ StorageInterface = (*TimeoutStorage)(nil)
+
+// WithTimeouts 包装存储服务，所有操作都不限制超时时间时直接返回storage
+func WithTimeouts(storage StorageInterface, timeouts Timeouts) StorageInterface {
+	if timeouts.IsZero() {
+		return storage
+	}
+	return &TimeoutStorage{storage: storage, timeouts: timeouts}
+}
+
+// Unwrap 获取被包装的存储服务
+func (s *TimeoutStorage) Unwrap() StorageInterface {
+	return s.storage
+}
+
+// Underlying 依次展开实现了Unwrap的包装，获取最内层的存储服务
+func Underlying(storage StorageInterface) StorageInterface {
+	for {
+		wrapper, ok := storage.(interface{ Unwrap() StorageInterface })
+		if !ok {
+			return storage
+		}
+		storage = wrapper.Unwrap()
+	}
+}
+
+// call 在超时时间内执行操作
+func call[T any](ctx context.Context, op string, timeout time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+	opCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result, err := fn(opCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(opCtx.Err(), context.DeadlineExceeded) {
+		err = timeoutError(ctx, op, timeout)
+	}
+	return result, err
+}
+
+// callErr 在超时时间内执行没有返回值的操作
+func callErr(ctx context.Context, op string, timeout time.Duration, fn func(ctx context.Context) error) error {
+	_, err := call(ctx, op, timeout, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// timeoutError 记录超时并返回包装context.DeadlineExceeded的错误
+func timeoutError(ctx context.Context, op string, timeout time.Duration) error {
+	recordTimeout(ctx)
+	return fmt.Errorf("存储操作%s超时（%s）: %w", op, timeout, context.DeadlineExceeded)
+}
+
+// TestConnection 测试连接
+func (s *TimeoutStorage) TestConnection(ctx context.Context) error {
+	return callErr(ctx, "TestConnection", s.timeouts.Metadata, s.storage.TestConnection)
+}
+
+// BucketExists 检查存储桶是否存在
+func (s *TimeoutStorage) BucketExists(ctx context.Context, bucketName string) (bool, error) {
+	return call(ctx, "BucketExists", s.timeouts.Metadata, func(ctx context.Context) (bool, error) {
+		return s.storage.BucketExists(ctx, bucketName)
+	})
+}
+
+// CreateBucket 创建存储桶
+func (s *TimeoutStorage) CreateBucket(ctx context.Context, bucketName string) error {
+	return callErr(ctx, "CreateBucket", s.timeouts.Metadata, func(ctx context.Context) error {
+		return s.storage.CreateBucket(ctx, bucketName)
+	})
+}
+
+// RemoveBucket 删除存储桶
+func (s *TimeoutStorage) RemoveBucket(ctx context.Context, bucketName string) error {
+	return callErr(ctx, "RemoveBucket", s.timeouts.Metadata, func(ctx context.Context) error {
+		return s.storage.RemoveBucket(ctx, bucketName)
+	})
+}
+
+// UploadFile 上传文件
+func (s *TimeoutStorage) UploadFile(ctx context.Context, bucketName, objectName string, data []byte, contentType string, opts ...ObjectOptions) (*UploadResult, error) {
+	return call(ctx, "UploadFile", s.timeouts.Upload, func(ctx context.Context) (*UploadResult, error) {
+		return s.storage.UploadFile(ctx, bucketName, objectName, data, contentType, opts...)
+	})
+}
+
+// UploadStream 流式上传文件
+func (s *TimeoutStorage) UploadStream(ctx context.Context, bucketName, objectName string, reader io.Reader, size int64, contentType string, opts ...ObjectOptions) (*UploadResult, error) {
+	return call(ctx, "UploadStream", s.timeouts.Upload, func(ctx context.Context) (*UploadResult, error) {
+		return s.storage.UploadStream(ctx, bucketName, objectName, reader, size, contentType, opts...)
+	})
+}
+
+// DownloadFile 下载文件
+func (s *TimeoutStorage) DownloadFile(ctx context.Context, bucketName, objectName string) ([]byte, error) {
+	return call(ctx, "DownloadFile", s.timeouts.Download, func(ctx context.Context) ([]byte, error) {
+		return s.storage.DownloadFile(ctx, bucketName, objectName)
+	})
+}
+
+// OpenFile 打开文件
+func (s *TimeoutStorage) OpenFile(ctx context.Context, bucketName, objectName string) (io.ReadCloser, error) {
+	return s.open(ctx, "OpenFile", func(ctx context.Context) (io.ReadCloser, error) {
+		return s.storage.OpenFile(ctx, bucketName, objectName)
+	})
+}
+
+// OpenFileRange 打开文件的指定范围
+func (s *TimeoutStorage) OpenFileRange(ctx context.Context, bucketName, objectName string, offset, length int64) (io.ReadCloser, error) {
+	return s.open(ctx, "OpenFileRange", func(ctx context.Context) (io.ReadCloser, error) {
+		return s.storage.OpenFileRange(ctx, bucketName, objectName, offset, length)
+	})
+}
+
+// open 打开文件，打开和每次读取等待数据超过download超时时间时中断
+// 读取方消费数据的时间（如向较慢的客户端写入）不计入超时
+func (s *TimeoutStorage) open(ctx context.Context, op string, fn func(ctx context.Context) (io.ReadCloser, error)) (io.ReadCloser, error) {
+	if s.timeouts.Download <= 0 {
+		return fn(ctx)
+	}
+
+	watchdog, opCtx := newIdleWatchdog(ctx, s.timeouts.Download)
+	reader, err := fn(opCtx)
+	watchdog.pause()
+	if err != nil {
+		watchdog.close()
+		return nil, watchdog.wrap(ctx, op, err)
+	}
+	return &idleTimeoutReader{reader: reader, ctx: ctx, op: op, watchdog: watchdog}, nil
+}
+
+// idleWatchdog 等待超过timeout时取消上下文
+type idleWatchdog struct {
+	timeout time.Duration
+	timer   *time.Timer
+	expired atomic.Bool
+	cancel  context.CancelFunc
+}
+
+// newIdleWatchdog 创建并立即开始计时
+func newIdleWatchdog(ctx context.Context, timeout time.Duration) (*idleWatchdog, context.Context) {
+	opCtx, cancel := context.WithCancel(ctx)
+	watchdog := &idleWatchdog{timeout: timeout, cancel: cancel}
+	watchdog.timer = time.AfterFunc(timeout, func() {
+		watchdog.expired.Store(true)
+		cancel()
+	})
+	return watchdog, opCtx
+}
+
+// resume 重新开始计时
+func (w *idleWatchdog) resume() {
+	if !w.expired.Load() {
+		w.timer.Reset(w.timeout)
+	}
+}
+
+// pause 停止计时
+func (w *idleWatchdog) pause() {
+	w.timer.Stop()
+}
+
+// close 停止计时并释放上下文
+func (w *idleWatchdog) close() {
+	w.timer.Stop()
+	w.cancel()
+}
+
+// wrap 等待超时导致的错误转换为超时错误，其他错误原样返回
+func (w *idleWatchdog) wrap(ctx context.Context, op string, err error) error {
+	if err == nil || err == io.EOF || !w.expired.Load() || ctx.Err() != nil {
+		return err
+	}
+	return timeoutError(ctx, op, w.timeout)
+}
+
+// idleTimeoutReader 每次读取等待数据超过超时时间时中断的文件流
+type idleTimeoutReader struct {
+	reader   io.ReadCloser
+	ctx      context.Context // 调用方的上下文
+	op       string
+	watchdog *idleWatchdog
+}
+
+// Read 读取数据
+func (r *idleTimeoutReader) Read(p []byte) (int, error) {
+	r.watchdog.resume()
+	n, err := r.reader.Read(p)
+	r.watchdog.pause()
+	return n, r.watchdog.wrap(r.ctx, r.op, err)
+}
+
+// Close 关闭文件流并释放上下文
+func (r *idleTimeoutReader) Close() error {
+	err := r.reader.Close()
+	r.watchdog.close()
+	return err
+}
+
+// FileExists 检查文件是否存在
+func (s *TimeoutStorage) FileExists(ctx context.Context, bucketName, objectName string) (bool, error) {
+	return call(ctx, "FileExists", s.timeouts.Metadata, func(ctx context.Context) (bool, error) {
+		return s.storage.FileExists(ctx, bucketName, objectName)
+	})
+}
+
+// GetFileInfo 获取文件信息
+func (s *TimeoutStorage) GetFileInfo(ctx context.Context, bucketName, objectName string) (*FileInfo, error) {
+	return call(ctx, "GetFileInfo", s.timeouts.Metadata, func(ctx context.Context) (*FileInfo, error) {
+		return s.storage.GetFileInfo(ctx, bucketName, objectName)
+	})
+}
+
+// DeleteFile 删除文件
+func (s *TimeoutStorage) DeleteFile(ctx context.Context, bucketName, objectName string) error {
+	return callErr(ctx, "DeleteFile", s.timeouts.Metadata, func(ctx context.Context) error {
+		return s.storage.DeleteFile(ctx, bucketName, objectName)
+	})
+}
+
+// DeleteFiles 批量删除文件
+func (s *TimeoutStorage) DeleteFiles(ctx context.Context, bucketName string, objectNames []string) (map[string]error, error) {
+	return call(ctx, "DeleteFiles", s.timeouts.Batch, func(ctx context.Context) (map[string]error, error) {
+		return s.storage.DeleteFiles(ctx, bucketName, objectNames)
+	})
+}
+
+// ListFiles 列出文件
+func (s *TimeoutStorage) ListFiles(ctx context.Context, bucketName, prefix string) ([]*FileInfo, error) {
+	return call(ctx, "ListFiles", s.timeouts.Batch, func(ctx context.Context) ([]*FileInfo, error) {
+		return s.storage.ListFiles(ctx, bucketName, prefix)
+	})
+}
+
+// CopyFile 复制文件
+func (s *TimeoutStorage) CopyFile(ctx context.Context, srcBucket, srcObject, dstBucket, dstObject string) error {
+	return callErr(ctx, "CopyFile", s.timeouts.Upload, func(ctx context.Context) error {
+		return s.storage.CopyFile(ctx, srcBucket, srcObject, dstBucket, dstObject)
+	})
+}
+
+// MoveFile 移动文件
+func (s *TimeoutStorage) MoveFile(ctx context.Context, srcBucket, srcObject, dstBucket, dstObject string) error {
+	return callErr(ctx, "MoveFile", s.timeouts.Upload, func(ctx context.Context) error {
+		return s.storage.MoveFile(ctx, srcBucket, srcObject, dstBucket, dstObject)
+	})
+}
+
+// GetObjectTags 获取对象标签
+func (s *TimeoutStorage) GetObjectTags(ctx context.Context, bucketName, objectName string) (map[string]string, error) {
+	return call(ctx, "GetObjectTags", s.timeouts.Metadata, func(ctx context.Context) (map[string]string, error) {
+		return s.storage.GetObjectTags(ctx, bucketName, objectName)
+	})
+}
+
+// SetObjectTags 替换对象标签
+func (s *TimeoutStorage) SetObjectTags(ctx context.Context, bucketName, objectName string, tags map[string]string) error {
+	return callErr(ctx, "SetObjectTags", s.timeouts.Metadata, func(ctx context.Context) error {
+		return s.storage.SetObjectTags(ctx, bucketName, objectName, tags)
+	})
+}
+
+// InitiateMultipartUpload 初始化分片上传
+func (s *TimeoutStorage) InitiateMultipartUpload(ctx context.Context, bucketName, objectName, contentType string, opts ...ObjectOptions) (string, error) {
+	return call(ctx, "InitiateMultipartUpload", s.timeouts.Metadata, func(ctx context.Context) (string, error) {
+		return s.storage.InitiateMultipartUpload(ctx, bucketName, objectName, contentType, opts...)
+	})
+}
+
+// UploadPart 上传分片
+func (s *TimeoutStorage) UploadPart(ctx context.Context, bucketName, objectName, uploadID string, partNumber int, reader io.Reader, size int64) (*PartInfo, error) {
+	return call(ctx, "UploadPart", s.timeouts.Upload, func(ctx context.Context) (*PartInfo, error) {
+		return s.storage.UploadPart(ctx, bucketName, objectName, uploadID, partNumber, reader, size)
+	})
+}
+
+// CompleteMultipartUpload 完成分片上传，存储服务合并较大的文件需要一段时间，使用上传的超时时间
+func (s *TimeoutStorage) CompleteMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string, parts []CompletePart) (*UploadResult, error) {
+	return call(ctx, "CompleteMultipartUpload", s.timeouts.Upload, func(ctx context.Context) (*UploadResult, error) {
+		return s.storage.CompleteMultipartUpload(ctx, bucketName, objectName, uploadID, parts)
+	})
+}
+
+// ListParts 列出已上传的分片
+func (s *TimeoutStorage) ListParts(ctx context.Context, bucketName, objectName, uploadID string) ([]*PartInfo, error) {
+	return call(ctx, "ListParts", s.timeouts.Metadata, func(ctx context.Context) ([]*PartInfo, error) {
+		return s.storage.ListParts(ctx, bucketName, objectName, uploadID)
+	})
+}
+
+// AbortMultipartUpload 取消分片上传
+func (s *TimeoutStorage) AbortMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string) error {
+	return callErr(ctx, "AbortMultipartUpload", s.timeouts.Metadata, func(ctx context.Context) error {
+		return s.storage.AbortMultipartUpload(ctx, bucketName, objectName, uploadID)
+	})
+}
+
+// GetPresignedURL 生成预签名下载URL
+func (s *TimeoutStorage) GetPresignedURL(ctx context.Context, bucketName, objectName string, expiry time.Duration) (string, error) {
+	return call(ctx, "GetPresignedURL", s.timeouts.Metadata, func(ctx context.Context) (string, error) {
+		return s.storage.GetPresignedURL(ctx, bucketName, objectName, expiry)
+	})
+}
+
+// GeneratePresignedURL 生成指定方法的预签名URL
+func (s *TimeoutStorage) GeneratePresignedURL(ctx context.Context, bucketName, objectName string, expiry time.Duration, method string) (string, error) {
+	return call(ctx, "GeneratePresignedURL", s.timeouts.Metadata, func(ctx context.Context) (string, error) {
+		return s.storage.GeneratePresignedURL(ctx, bucketName, objectName, expiry, method)
+	})
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stalledStorage 模拟无响应的存储服务，操作一直阻塞到上下文取消
+type stalledStorage struct {
+	*LocalStorage
+	stallRead bool // 打开文件成功，读取时阻塞
+}
+
+func (s *stalledStorage) GetFileInfo(ctx context.Context, bucketName, objectName string) (*FileInfo, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (s *stalledStorage) OpenFile(ctx context.Context, bucketName, objectName string) (io.ReadCloser, error) {
+	if !s.stallRead {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &stalledReader{ctx: ctx, data: strings.NewReader("0123")}, nil
+}
+
+// stalledReader 读完数据后阻塞到上下文取消
+type stalledReader struct {
+	ctx  context.Context
+	data *strings.Reader
+}
+
+func (r *stalledReader) Read(p []byte) (int, error) {
+	if r.data.Len() > 0 {
+		time.Sleep(30 * time.Millisecond)
+		return r.data.Read(p[:1])
+	}
+	<-r.ctx.Done()
+	return 0, r.ctx.Err()
+}
+
+func (r *stalledReader) Close() error {
+	return nil
+}
+
+func TestTimeoutStorage(t *testing.T) {
+	timeouts := Timeouts{Metadata: 20 * time.Millisecond, Download: 50 * time.Millisecond}
+
+	t.Run("操作超时返回DeadlineExceeded并记录", func(t *testing.T) {
+		storage := WithTimeouts(&stalledStorage{LocalStorage: setupLocalStorage(t)}, timeouts)
+		ctx := WithFailureRecorder(context.Background())
+
+		_, err := storage.GetFileInfo(ctx, "bucket", "object")
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Contains(t, err.Error(), "GetFileInfo")
+		assert.True(t, TimedOut(ctx))
+	})
+
+	t.Run("调用方取消时返回原始错误", func(t *testing.T) {
+		storage := WithTimeouts(&stalledStorage{LocalStorage: setupLocalStorage(t)}, Timeouts{Metadata: time.Minute})
+		ctx, cancel := context.WithCancel(WithFailureRecorder(context.Background()))
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		_, err := storage.GetFileInfo(ctx, "bucket", "object")
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.False(t, TimedOut(ctx))
+	})
+
+	t.Run("打开文件超时", func(t *testing.T) {
+		storage := WithTimeouts(&stalledStorage{LocalStorage: setupLocalStorage(t)}, timeouts)
+
+		_, err := storage.OpenFile(context.Background(), "bucket", "object")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("读取时等待数据超时", func(t *testing.T) {
+		storage := WithTimeouts(&stalledStorage{LocalStorage: setupLocalStorage(t), stallRead: true}, timeouts)
+		ctx := WithFailureRecorder(context.Background())
+
+		reader, err := storage.OpenFile(ctx, "bucket", "object")
+		require.NoError(t, err)
+		defer reader.Close()
+
+		// 总读取时间超过超时时间，但每次读取都在超时时间内返回
+		data, err := io.ReadAll(reader)
+		assert.Equal(t, "0123", string(data))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.True(t, TimedOut(ctx))
+	})
+
+	t.Run("正常操作不受影响", func(t *testing.T) {
+		local := setupLocalStorage(t)
+		storage := WithTimeouts(local, timeouts)
+		ctx := WithFailureRecorder(context.Background())
+		require.NoError(t, storage.CreateBucket(ctx, "bucket"))
+		_, err := storage.UploadFile(ctx, "bucket", "object", []byte("data"), "text/plain")
+		require.NoError(t, err)
+
+		reader, err := storage.OpenFile(ctx, "bucket", "object")
+		require.NoError(t, err)
+		time.Sleep(80 * time.Millisecond)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err, "读取方消费数据的时间不计入超时")
+		assert.Equal(t, "data", string(data))
+		require.NoError(t, reader.Close())
+		assert.False(t, TimedOut(ctx))
+
+		assert.Same(t, local, Underlying(storage))
+		assert.Same(t, local, WithTimeouts(local, Timeouts{}), "不限制超时时间时不包装")
+	})
+}
@@ -0,0 +1,299 @@
+package streaming
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ManifestName DASH清单文件名
+	ManifestName = "manifest.mpd"
+	// ManifestContentType DASH清单MIME类型
+	ManifestContentType = "application/dash+xml"
+
+	// manifestTimescale 清单中时间轴的单位（毫秒）
+	manifestTimescale = 1000
+	// manifestProfile 使用SegmentList引用独立分片文件的DASH配置
+	manifestProfile = "urn:mpeg:dash:profile:isoff-main:2011"
+)
+
+// MediaSegment 媒体播放列表中的分片
+type MediaSegment struct {
+	URI      string
+	Duration float64 // 秒
+}
+
+// MediaPlaylist 解析后的HLS媒体播放列表
+type MediaPlaylist struct {
+	InitURI  string // fMP4初始化分片地址，TS分片时为空
+	Segments []*MediaSegment
+}
+
+// Duration 播放列表总时长（秒）
+func (m *MediaPlaylist) Duration() float64 {
+	var total float64
+	for _, segment := range m.Segments {
+		total += segment.Duration
+	}
+	return total
+}
+
+// ParseMediaPlaylist 解析HLS媒体播放列表中的初始化分片和分片时长
+func ParseMediaPlaylist(content []byte) (*MediaPlaylist, error) {
+	playlist := &MediaPlaylist{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+
+	duration := -1.0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#EXT-X-MAP:"):
+			playlist.InitURI = mapURI(line)
+		case strings.HasPrefix(line, "#EXTINF:"):
+			value := strings.TrimPrefix(line, "#EXTINF:")
+			if i := strings.Index(value, ","); i >= 0 {
+				value = value[:i]
+			}
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil || parsed < 0 {
+				return nil, fmt.Errorf("无效的分片时长: %s", line)
+			}
+			duration = parsed
+		case strings.HasPrefix(line, "#"):
+		default:
+			if duration < 0 {
+				return nil, fmt.Errorf("分片缺少时长: %s", line)
+			}
+			playlist.Segments = append(playlist.Segments, &MediaSegment{URI: line, Duration: duration})
+			duration = -1
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取播放列表失败: %w", err)
+	}
+
+	return playlist, nil
+}
+
+// Representation DASH清单中的档位，引用HLS切片产生的fMP4分片
+type Representation struct {
+	ID        string
+	Bandwidth int
+	Width     int
+	Height    int
+	Codecs    string
+	Playlist  *MediaPlaylist
+}
+
+// mpd DASH清单（只包含本服务生成的元素）
+type mpd struct {
+	XMLName                   xml.Name  `xml:"urn:mpeg:dash:schema:mpd:2011 MPD"`
+	Profiles                  string    `xml:"profiles,attr"`
+	Type                      string    `xml:"type,attr"`
+	MediaPresentationDuration string    `xml:"mediaPresentationDuration,attr"`
+	MinBufferTime             string    `xml:"minBufferTime,attr"`
+	Periods                   []*period `xml:"Period"`
+}
+
+type period struct {
+	ID             string           `xml:"id,attr"`
+	AdaptationSets []*adaptationSet `xml:"AdaptationSet"`
+}
+
+type adaptationSet struct {
+	MimeType         string               `xml:"mimeType,attr"`
+	SegmentAlignment bool                 `xml:"segmentAlignment,attr"`
+	StartWithSAP     int                  `xml:"startWithSAP,attr"`
+	Representations  []*mpdRepresentation `xml:"Representation"`
+}
+
+type mpdRepresentation struct {
+	ID          string       `xml:"id,attr"`
+	Bandwidth   int          `xml:"bandwidth,attr"`
+	Width       int          `xml:"width,attr,omitempty"`
+	Height      int          `xml:"height,attr,omitempty"`
+	Codecs      string       `xml:"codecs,attr,omitempty"`
+	SegmentList *segmentList `xml:"SegmentList"`
+}
+
+type segmentList struct {
+	Timescale       int              `xml:"timescale,attr"`
+	Initialization  *initialization  `xml:"Initialization"`
+	SegmentTimeline *segmentTimeline `xml:"SegmentTimeline"`
+	SegmentURLs     []*segmentURL    `xml:"SegmentURL"`
+}
+
+type initialization struct {
+	SourceURL string `xml:"sourceURL,attr"`
+}
+
+type segmentTimeline struct {
+	Segments []*timelineSegment `xml:"S"`
+}
+
+type timelineSegment struct {
+	Start    *int64 `xml:"t,attr"`
+	Duration int64  `xml:"d,attr"`
+	Repeat   int    `xml:"r,attr,omitempty"`
+}
+
+type segmentURL struct {
+	Media string `xml:"media,attr"`
+}
+
+// GenerateManifest 生成点播DASH清单，各档位直接引用HLS媒体播放列表中的fMP4分片
+func GenerateManifest(representations []*Representation) ([]byte, error) {
+	if len(representations) == 0 {
+		return nil, fmt.Errorf("DASH清单至少需要一个档位")
+	}
+
+	set := &adaptationSet{
+		MimeType:         "video/mp4",
+		SegmentAlignment: true,
+		StartWithSAP:     1,
+	}
+	var duration, maxSegment float64
+	for _, rep := range representations {
+		if rep.Playlist == nil || rep.Playlist.InitURI == "" {
+			return nil, fmt.Errorf("档位%s缺少fMP4初始化分片", rep.ID)
+		}
+		if len(rep.Playlist.Segments) == 0 {
+			return nil, fmt.Errorf("档位%s没有分片", rep.ID)
+		}
+
+		list := &segmentList{
+			Timescale:       manifestTimescale,
+			Initialization:  &initialization{SourceURL: rep.Playlist.InitURI},
+			SegmentTimeline: &segmentTimeline{},
+		}
+		var start int64
+		for _, segment := range rep.Playlist.Segments {
+			d := int64(math.Round(segment.Duration * manifestTimescale))
+			timeline := list.SegmentTimeline.Segments
+			// 连续相同时长的分片合并为一个S元素
+			if n := len(timeline); n > 0 && timeline[n-1].Duration == d {
+				timeline[n-1].Repeat++
+			} else {
+				s := &timelineSegment{Duration: d}
+				if n == 0 {
+					s.Start = &start
+				}
+				list.SegmentTimeline.Segments = append(timeline, s)
+			}
+			list.SegmentURLs = append(list.SegmentURLs, &segmentURL{Media: segment.URI})
+			if segment.Duration > maxSegment {
+				maxSegment = segment.Duration
+			}
+		}
+
+		set.Representations = append(set.Representations, &mpdRepresentation{
+			ID:          rep.ID,
+			Bandwidth:   rep.Bandwidth,
+			Width:       rep.Width,
+			Height:      rep.Height,
+			Codecs:      rep.Codecs,
+			SegmentList: list,
+		})
+		if d := rep.Playlist.Duration(); d > duration {
+			duration = d
+		}
+	}
+
+	manifest := &mpd{
+		Profiles:                  manifestProfile,
+		Type:                      "static",
+		MediaPresentationDuration: formatDuration(duration),
+		MinBufferTime:             formatDuration(math.Ceil(maxSegment)),
+		Periods: []*period{{
+			ID:             "0",
+			AdaptationSets: []*adaptationSet{set},
+		}},
+	}
+	return marshalManifest(manifest)
+}
+
+// RewriteManifest 重写DASH清单中初始化分片和媒体分片的地址
+func RewriteManifest(content []byte, rewrite func(uri string) (string, error)) ([]byte, error) {
+	var manifest mpd
+	if err := xml.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("解析DASH清单失败: %w", err)
+	}
+
+	for _, p := range manifest.Periods {
+		for _, set := range p.AdaptationSets {
+			for _, rep := range set.Representations {
+				list := rep.SegmentList
+				if list == nil {
+					continue
+				}
+				if list.Initialization != nil {
+					rewritten, err := rewrite(list.Initialization.SourceURL)
+					if err != nil {
+						return nil, fmt.Errorf("重写DASH清单地址失败: %w", err)
+					}
+					list.Initialization.SourceURL = rewritten
+				}
+				for _, segment := range list.SegmentURLs {
+					rewritten, err := rewrite(segment.Media)
+					if err != nil {
+						return nil, fmt.Errorf("重写DASH清单地址失败: %w", err)
+					}
+					segment.Media = rewritten
+				}
+			}
+		}
+	}
+
+	return marshalManifest(&manifest)
+}
+
+// DetectCodecs 从fMP4初始化分片中识别RFC 6381编解码器字符串
+// 只识别H.264视频和AAC音频，包含其他视频编码时返回空字符串，由播放器自行探测
+func DetectCodecs(init []byte) string {
+	var codecs []string
+
+	i := bytes.Index(init, []byte("avcC"))
+	if i < 0 || len(init) < i+8 {
+		return ""
+	}
+	// avcC内容依次为configurationVersion、profile、profile兼容性、level
+	codecs = append(codecs, fmt.Sprintf("avc1.%02x%02x%02x", init[i+5], init[i+6], init[i+7]))
+
+	if bytes.Contains(init, []byte("mp4a")) {
+		codecs = append(codecs, "mp4a.40.2")
+	}
+	return strings.Join(codecs, ",")
+}
+
+// marshalManifest 序列化DASH清单
+func marshalManifest(manifest *mpd) ([]byte, error) {
+	data, err := xml.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("生成DASH清单失败: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// formatDuration 格式化为ISO 8601时长
+func formatDuration(seconds float64) string {
+	return "PT" + strconv.FormatFloat(seconds, 'f', 3, 64) + "S"
+}
+
+// mapURI 解析EXT-X-MAP标签中的URI属性
+func mapURI(line string) string {
+	i := strings.Index(line, `URI="`)
+	if i < 0 {
+		return ""
+	}
+	value := line[i+len(`URI="`):]
+	if j := strings.Index(value, `"`); j >= 0 {
+		return value[:j]
+	}
+	return ""
+}
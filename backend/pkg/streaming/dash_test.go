@@ -0,0 +1,107 @@
+package streaming
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseMediaPlaylist 测试媒体播放列表解析
+func TestParseMediaPlaylist(t *testing.T) {
+	content := []byte("#EXTM3U\n#EXT-X-VERSION:7\n#EXT-X-MAP:URI=\"source/init.mp4\"\n" +
+		"#EXTINF:6.000000,\nsource/segment_00000.m4s\n#EXTINF:4.5,\nsource/segment_00001.m4s\n#EXT-X-ENDLIST\n")
+
+	playlist, err := ParseMediaPlaylist(content)
+	require.NoError(t, err, "解析播放列表应该成功")
+	assert.Equal(t, "source/init.mp4", playlist.InitURI)
+	require.Len(t, playlist.Segments, 2)
+	assert.Equal(t, "source/segment_00001.m4s", playlist.Segments[1].URI)
+	assert.Equal(t, 4.5, playlist.Segments[1].Duration)
+	assert.Equal(t, 10.5, playlist.Duration())
+
+	ts, err := ParseMediaPlaylist([]byte("#EXTM3U\n#EXTINF:6.0,\nsource/segment_00000.ts\n"))
+	require.NoError(t, err)
+	assert.Empty(t, ts.InitURI, "TS播放列表没有初始化分片")
+
+	_, err = ParseMediaPlaylist([]byte("#EXTM3U\nsource/segment_00000.m4s\n"))
+	assert.Error(t, err, "分片缺少时长时应该返回错误")
+
+	_, err = ParseMediaPlaylist([]byte("#EXTM3U\n#EXTINF:abc,\nsource/segment_00000.m4s\n"))
+	assert.Error(t, err, "无效时长应该返回错误")
+}
+
+// TestGenerateManifest 测试DASH清单生成
+func TestGenerateManifest(t *testing.T) {
+	playlist := &MediaPlaylist{
+		InitURI: "720p/init.mp4",
+		Segments: []*MediaSegment{
+			{URI: "720p/segment_00000.m4s", Duration: 6},
+			{URI: "720p/segment_00001.m4s", Duration: 6},
+			{URI: "720p/segment_00002.m4s", Duration: 2.5},
+		},
+	}
+
+	manifest, err := GenerateManifest([]*Representation{
+		{ID: "720p", Bandwidth: 2928000, Width: 1280, Height: 720, Codecs: "avc1.64001f,mp4a.40.2", Playlist: playlist},
+	})
+	require.NoError(t, err, "生成DASH清单应该成功")
+
+	content := string(manifest)
+	assert.True(t, strings.HasPrefix(content, "<?xml"), "应该包含XML声明")
+	assert.Contains(t, content, `<MPD xmlns="urn:mpeg:dash:schema:mpd:2011"`)
+	assert.Contains(t, content, `type="static"`)
+	assert.Contains(t, content, `mediaPresentationDuration="PT14.500S"`)
+	assert.Contains(t, content, `minBufferTime="PT6.000S"`)
+	assert.Contains(t, content, `<Representation id="720p" bandwidth="2928000" width="1280" height="720" codecs="avc1.64001f,mp4a.40.2">`)
+	assert.Contains(t, content, `<Initialization sourceURL="720p/init.mp4">`)
+	assert.Contains(t, content, `<S t="0" d="6000" r="1"></S>`, "相同时长的分片应该合并")
+	assert.Contains(t, content, `<S d="2500"></S>`)
+	assert.Equal(t, 3, strings.Count(content, "<SegmentURL "), "每个分片都应该有SegmentURL")
+
+	_, err = GenerateManifest(nil)
+	assert.Error(t, err, "没有档位时应该返回错误")
+
+	_, err = GenerateManifest([]*Representation{{ID: "source", Playlist: &MediaPlaylist{Segments: playlist.Segments}}})
+	assert.Error(t, err, "TS分片不能生成DASH清单")
+}
+
+// TestRewriteManifest 测试DASH清单地址重写
+func TestRewriteManifest(t *testing.T) {
+	manifest, err := GenerateManifest([]*Representation{{
+		ID:        "source",
+		Bandwidth: 1000000,
+		Playlist: &MediaPlaylist{
+			InitURI:  "source/init.mp4",
+			Segments: []*MediaSegment{{URI: "source/segment_00000.m4s", Duration: 6}},
+		},
+	}})
+	require.NoError(t, err)
+
+	rewritten, err := RewriteManifest(manifest, func(uri string) (string, error) {
+		return "https://cdn.example.com/" + uri + "?a=1&sig=2", nil
+	})
+	require.NoError(t, err, "重写DASH清单应该成功")
+	content := string(rewritten)
+	assert.Contains(t, content, `sourceURL="https://cdn.example.com/source/init.mp4?a=1&amp;sig=2"`, "初始化分片地址应该被重写并转义")
+	assert.Contains(t, content, `media="https://cdn.example.com/source/segment_00000.m4s?a=1&amp;sig=2"`, "分片地址应该被重写")
+	assert.Contains(t, content, `<S t="0" d="6000"></S>`, "时间轴应该保持不变")
+
+	_, err = RewriteManifest(manifest, func(uri string) (string, error) {
+		return "", fmt.Errorf("签名失败")
+	})
+	assert.Error(t, err, "重写函数出错时应该返回错误")
+
+	_, err = RewriteManifest([]byte("not xml"), func(uri string) (string, error) { return uri, nil })
+	assert.Error(t, err, "无效清单应该返回错误")
+}
+
+// TestDetectCodecs 测试编解码器识别
+func TestDetectCodecs(t *testing.T) {
+	assert.Equal(t, "avc1.64001f,mp4a.40.2", DetectCodecs([]byte("moov-avcC\x01\x64\x00\x1f-trak-mp4a")))
+	assert.Equal(t, "avc1.42e01e", DetectCodecs([]byte("avcC\x01\x42\xe0\x1e")), "没有音轨时只返回视频编码")
+	assert.Empty(t, DetectCodecs([]byte("hvcC-mp4a")), "无法识别的视频编码应该返回空字符串")
+	assert.Empty(t, DetectCodecs([]byte("avcC\x01")), "数据不完整时应该返回空字符串")
+}
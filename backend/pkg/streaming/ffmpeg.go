@@ -0,0 +1,186 @@
+package streaming
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// initSegmentFile 档位分片目录中的初始化分片文件名
+const initSegmentFile = "init.mp4"
+
+// FFmpegSegmenter 基于FFmpeg的HLS切片器，输出fMP4分片以便同时用于DASH
+type FFmpegSegmenter struct {
+	ffmpegPath string
+}
+
+// NewFFmpegSegmenter 创建FFmpeg切片器
+func NewFFmpegSegmenter(ffmpegPath string) *FFmpegSegmenter {
+	if ffmpegPath == "" {
+		ffmpegPath = "ffmpeg"
+	}
+	return &FFmpegSegmenter{
+		ffmpegPath: ffmpegPath,
+	}
+}
+
+// IsAvailable 检查FFmpeg是否可用
+func (s *FFmpegSegmenter) IsAvailable() bool {
+	_, err := exec.LookPath(s.ffmpegPath)
+	return err == nil
+}
+
+// Segment 调用FFmpeg将视频切片为HLS格式的fMP4分片
+func (s *FFmpegSegmenter) Segment(ctx context.Context, req *SegmentRequest) (*SegmentResult, error) {
+	if req == nil || req.Rendition == nil {
+		return nil, fmt.Errorf("切片请求不能为空")
+	}
+	if req.Rendition.Name == "" {
+		return nil, fmt.Errorf("档位名称不能为空")
+	}
+
+	segmentDir := filepath.Join(req.OutputDir, req.Rendition.Name)
+	if err := os.MkdirAll(segmentDir, 0755); err != nil {
+		return nil, fmt.Errorf("创建分片目录失败: %w", err)
+	}
+
+	playlistFile := req.Rendition.Name + ".m3u8"
+	args := s.buildArgs(req, filepath.Join(req.OutputDir, playlistFile), segmentDir)
+
+	cmd := exec.CommandContext(ctx, s.ffmpegPath, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("FFmpeg执行失败: %w, 输出: %s", err, lastLines(output, 512))
+	}
+
+	initFile, err := s.moveInitSegment(req, segmentDir, filepath.Join(req.OutputDir, playlistFile))
+	if err != nil {
+		return nil, err
+	}
+
+	segments, err := filepath.Glob(filepath.Join(segmentDir, "*.m4s"))
+	if err != nil {
+		return nil, fmt.Errorf("查找分片文件失败: %w", err)
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("FFmpeg未生成任何分片")
+	}
+	sort.Strings(segments)
+
+	result := &SegmentResult{
+		PlaylistFile: playlistFile,
+		InitFile:     initFile,
+	}
+	for _, segment := range segments {
+		result.SegmentFiles = append(result.SegmentFiles, req.Rendition.Name+"/"+filepath.Base(segment))
+	}
+
+	return result, nil
+}
+
+// buildArgs 构建FFmpeg命令行参数
+func (s *FFmpegSegmenter) buildArgs(req *SegmentRequest, playlistPath, segmentDir string) []string {
+	duration := req.SegmentDuration
+	if duration <= 0 {
+		duration = 6
+	}
+
+	args := []string{"-hide_banner", "-loglevel", "error", "-y", "-i", req.InputPath}
+
+	rendition := req.Rendition
+	if rendition.IsSource() {
+		args = append(args, "-c", "copy")
+	} else {
+		args = append(args,
+			"-c:v", "libx264",
+			"-preset", "veryfast",
+			"-b:v", strconv.Itoa(rendition.VideoBitrate)+"k",
+			"-maxrate", strconv.Itoa(rendition.VideoBitrate)+"k",
+			"-bufsize", strconv.Itoa(rendition.VideoBitrate*2)+"k",
+			// 按固定间隔插入关键帧，保证分片边界对齐
+			"-force_key_frames", fmt.Sprintf("expr:gte(t,n_forced*%d)", duration),
+		)
+		if rendition.Height > 0 {
+			// FFmpeg会按旋转信息自动旋转画面，竖屏视频以档位高度作为输出宽度
+			scale := fmt.Sprintf("scale=-2:%d", rendition.Height)
+			if isPortraitRotation(req.Rotation) {
+				scale = fmt.Sprintf("scale=%d:-2", rendition.Height)
+			}
+			args = append(args, "-vf", scale)
+		}
+
+		audioBitrate := rendition.AudioBitrate
+		if audioBitrate <= 0 {
+			audioBitrate = 128
+		}
+		args = append(args, "-c:a", "aac", "-b:a", strconv.Itoa(audioBitrate)+"k")
+	}
+
+	args = append(args,
+		"-f", "hls",
+		"-hls_time", strconv.Itoa(duration),
+		"-hls_playlist_type", "vod",
+		"-hls_segment_type", "fmp4",
+		"-hls_fmp4_init_filename", initSegmentName(rendition),
+		"-hls_base_url", rendition.Name+"/",
+		"-hls_segment_filename", filepath.Join(segmentDir, "segment_%05d.m4s"),
+		playlistPath,
+	)
+
+	return args
+}
+
+// moveInitSegment 将初始化分片移动到档位的分片目录，并修正播放列表中的EXT-X-MAP地址
+// 不同版本的FFmpeg会把初始化分片写到播放列表目录或分片目录，且EXT-X-MAP不带hls_base_url前缀
+func (s *FFmpegSegmenter) moveInitSegment(req *SegmentRequest, segmentDir, playlistPath string) (string, error) {
+	name := initSegmentName(req.Rendition)
+	target := filepath.Join(segmentDir, initSegmentFile)
+
+	var source string
+	for _, candidate := range []string{filepath.Join(req.OutputDir, name), filepath.Join(segmentDir, name)} {
+		if _, err := os.Stat(candidate); err == nil {
+			source = candidate
+			break
+		}
+	}
+	if source == "" {
+		return "", fmt.Errorf("FFmpeg未生成初始化分片")
+	}
+	if err := os.Rename(source, target); err != nil {
+		return "", fmt.Errorf("移动初始化分片失败: %w", err)
+	}
+
+	relPath := req.Rendition.Name + "/" + initSegmentFile
+	content, err := os.ReadFile(playlistPath)
+	if err != nil {
+		return "", fmt.Errorf("读取媒体播放列表失败: %w", err)
+	}
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "#EXT-X-MAP:") {
+			lines[i] = fmt.Sprintf(`#EXT-X-MAP:URI="%s"`, relPath)
+		}
+	}
+	if err := os.WriteFile(playlistPath, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		return "", fmt.Errorf("写入媒体播放列表失败: %w", err)
+	}
+
+	return relPath, nil
+}
+
+// initSegmentName FFmpeg输出的初始化分片文件名，按档位区分避免写到同一目录时互相覆盖
+func initSegmentName(rendition *Rendition) string {
+	return rendition.Name + "_" + initSegmentFile
+}
+
+// lastLines 截取输出的末尾部分，避免错误信息过长
+func lastLines(output []byte, limit int) string {
+	if len(output) <= limit {
+		return string(output)
+	}
+	return string(output[len(output)-limit:])
+}
@@ -0,0 +1,145 @@
+package streaming
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFFmpegSegmenter_BuildArgs_Source 测试原画档位参数
+func TestFFmpegSegmenter_BuildArgs_Source(t *testing.T) {
+	segmenter := NewFFmpegSegmenter("")
+	assert.Equal(t, "ffmpeg", segmenter.ffmpegPath, "默认应该使用PATH中的ffmpeg")
+
+	args := segmenter.buildArgs(&SegmentRequest{
+		InputPath:       "/tmp/input.mp4",
+		SegmentDuration: 4,
+		Rendition:       &Rendition{Name: SourceRenditionName},
+	}, "/tmp/out/source.m3u8", "/tmp/out/source")
+
+	joined := strings.Join(args, " ")
+	assert.Contains(t, joined, "-c copy", "原画档位应该直接复制流")
+	assert.Contains(t, joined, "-hls_time 4")
+	assert.Contains(t, joined, "-hls_playlist_type vod")
+	assert.Contains(t, joined, "-hls_base_url source/")
+	assert.Contains(t, joined, "-hls_segment_type fmp4", "应该输出fMP4分片以便同时用于DASH")
+	assert.Contains(t, joined, "-hls_fmp4_init_filename source_init.mp4")
+	assert.Contains(t, joined, "/tmp/out/source/segment_%05d.m4s")
+	assert.NotContains(t, joined, "libx264", "原画档位不应该转码")
+	assert.Equal(t, "/tmp/out/source.m3u8", args[len(args)-1], "最后一个参数应该是播放列表路径")
+}
+
+// TestFFmpegSegmenter_BuildArgs_Transcode 测试转码档位参数
+func TestFFmpegSegmenter_BuildArgs_Transcode(t *testing.T) {
+	segmenter := NewFFmpegSegmenter("/usr/bin/ffmpeg")
+
+	args := segmenter.buildArgs(&SegmentRequest{
+		InputPath: "/tmp/input.mp4",
+		Rendition: &Rendition{Name: "720p", Width: 1280, Height: 720, VideoBitrate: 2800},
+	}, "/tmp/out/720p.m3u8", "/tmp/out/720p")
+
+	joined := strings.Join(args, " ")
+	assert.Contains(t, joined, "-c:v libx264")
+	assert.Contains(t, joined, "-b:v 2800k")
+	assert.Contains(t, joined, "-vf scale=-2:720")
+	assert.Contains(t, joined, "-b:a 128k", "未指定音频码率时应该使用默认值")
+	assert.Contains(t, joined, "-hls_time 6", "未指定分片时长时应该使用默认值")
+}
+
+// TestFFmpegSegmenter_BuildArgs_Rotation 测试竖屏视频转码时按短边缩放
+func TestFFmpegSegmenter_BuildArgs_Rotation(t *testing.T) {
+	segmenter := NewFFmpegSegmenter("/usr/bin/ffmpeg")
+	rendition := &Rendition{Name: "720p", Width: 1280, Height: 720, VideoBitrate: 2800}
+
+	for _, rotation := range []int{90, 270} {
+		args := segmenter.buildArgs(&SegmentRequest{InputPath: "/tmp/input.mov", Rendition: rendition, Rotation: rotation},
+			"/tmp/out/720p.m3u8", "/tmp/out/720p")
+		assert.Contains(t, strings.Join(args, " "), "-vf scale=720:-2", "旋转%d度时应该以档位高度作为输出宽度", rotation)
+	}
+
+	args := segmenter.buildArgs(&SegmentRequest{InputPath: "/tmp/input.mov", Rendition: rendition, Rotation: 180},
+		"/tmp/out/720p.m3u8", "/tmp/out/720p")
+	assert.Contains(t, strings.Join(args, " "), "-vf scale=-2:720", "旋转180度不改变画面方向")
+}
+
+// TestFFmpegSegmenter_Segment_InvalidRequest 测试无效切片请求
+func TestFFmpegSegmenter_Segment_InvalidRequest(t *testing.T) {
+	segmenter := NewFFmpegSegmenter("")
+
+	_, err := segmenter.Segment(context.Background(), nil)
+	assert.Error(t, err)
+
+	_, err = segmenter.Segment(context.Background(), &SegmentRequest{Rendition: &Rendition{}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "档位名称不能为空")
+}
+
+// TestFFmpegSegmenter_Segment 测试真实切片（需要FFmpeg）
+func TestFFmpegSegmenter_Segment(t *testing.T) {
+	segmenter := NewFFmpegSegmenter("")
+	if !segmenter.IsAvailable() {
+		t.Skip("跳过测试：FFmpeg不可用")
+	}
+
+	tempDir := t.TempDir()
+	inputPath := filepath.Join(tempDir, "input.mp4")
+
+	// 生成3秒测试视频
+	cmd := exec.Command("ffmpeg", "-hide_banner", "-loglevel", "error", "-y",
+		"-f", "lavfi", "-i", "testsrc=duration=3:size=320x240:rate=25",
+		"-c:v", "libx264", "-g", "25", inputPath)
+	require.NoError(t, cmd.Run(), "生成测试视频应该成功")
+
+	outputDir := filepath.Join(tempDir, "out")
+	require.NoError(t, os.MkdirAll(outputDir, 0755))
+
+	result, err := segmenter.Segment(context.Background(), &SegmentRequest{
+		InputPath:       inputPath,
+		OutputDir:       outputDir,
+		SegmentDuration: 1,
+		Rendition:       &Rendition{Name: SourceRenditionName},
+	})
+	require.NoError(t, err, "切片应该成功")
+	assert.Equal(t, "source.m3u8", result.PlaylistFile)
+	assert.Equal(t, "source/init.mp4", result.InitFile, "初始化分片应该移动到分片目录")
+	assert.NotEmpty(t, result.SegmentFiles, "应该生成分片")
+	assert.FileExists(t, filepath.Join(outputDir, "source", "init.mp4"))
+
+	playlist, err := os.ReadFile(filepath.Join(outputDir, result.PlaylistFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(playlist), "source/segment_00000.m4s", "播放列表应该引用子目录中的分片")
+	assert.Contains(t, string(playlist), `#EXT-X-MAP:URI="source/init.mp4"`, "播放列表应该引用子目录中的初始化分片")
+	assert.Contains(t, string(playlist), "#EXT-X-ENDLIST")
+}
+
+// TestFFmpegSegmenter_MoveInitSegment 测试初始化分片移动和EXT-X-MAP修正
+func TestFFmpegSegmenter_MoveInitSegment(t *testing.T) {
+	segmenter := NewFFmpegSegmenter("")
+	outputDir := t.TempDir()
+	segmentDir := filepath.Join(outputDir, "720p")
+	require.NoError(t, os.MkdirAll(segmentDir, 0755))
+
+	playlistPath := filepath.Join(outputDir, "720p.m3u8")
+	require.NoError(t, os.WriteFile(playlistPath, []byte("#EXTM3U\n#EXT-X-MAP:URI=\"720p_init.mp4\"\n#EXTINF:6.0,\n720p/segment_00000.m4s\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "720p_init.mp4"), []byte("init"), 0644))
+
+	req := &SegmentRequest{OutputDir: outputDir, Rendition: &Rendition{Name: "720p"}}
+	initFile, err := segmenter.moveInitSegment(req, segmentDir, playlistPath)
+	require.NoError(t, err)
+	assert.Equal(t, "720p/init.mp4", initFile)
+	assert.FileExists(t, filepath.Join(segmentDir, "init.mp4"))
+
+	playlist, err := os.ReadFile(playlistPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(playlist), `#EXT-X-MAP:URI="720p/init.mp4"`)
+	assert.Contains(t, string(playlist), "720p/segment_00000.m4s", "分片行应该保持不变")
+
+	_, err = segmenter.moveInitSegment(req, segmentDir, playlistPath)
+	assert.Error(t, err, "找不到初始化分片时应该返回错误")
+}
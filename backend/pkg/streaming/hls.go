@@ -0,0 +1,515 @@
+package streaming
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/tempfile"
+)
+
+const (
+	// HLSRootPrefix HLS文件在存储桶中的根前缀
+	HLSRootPrefix = "hls"
+	// MasterPlaylistName 主播放列表文件名
+	MasterPlaylistName = "master.m3u8"
+	// PlaylistContentType HLS播放列表MIME类型
+	PlaylistContentType = "application/vnd.apple.mpegurl"
+	// SegmentContentType fMP4媒体分片MIME类型
+	SegmentContentType = "video/iso.segment"
+	// InitSegmentContentType fMP4初始化分片MIME类型
+	InitSegmentContentType = "video/mp4"
+	// SourceRenditionName 原画（不转码）档位名称
+	SourceRenditionName = "source"
+
+	// defaultBandwidth 无法获取码率时声明的默认带宽（bps）
+	defaultBandwidth = 2000000
+)
+
+// ErrPackagingInProgress 视频正在打包中
+var ErrPackagingInProgress = errors.New("视频正在进行HLS打包")
+
+// Rendition HLS码率档位
+type Rendition struct {
+	Name         string `json:"name"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	VideoBitrate int    `json:"video_bitrate"` // kbps，0表示直接复制原始流
+	AudioBitrate int    `json:"audio_bitrate"` // kbps
+}
+
+// IsSource 是否为不转码的原画档位
+func (r *Rendition) IsSource() bool {
+	return r.VideoBitrate <= 0
+}
+
+// Bandwidth 主播放列表中声明的带宽（bps）
+func (r *Rendition) Bandwidth() int {
+	return (r.VideoBitrate + r.AudioBitrate) * 1000
+}
+
+// Segmenter 视频切片器接口
+type Segmenter interface {
+	// IsAvailable 切片器是否可用
+	IsAvailable() bool
+	// Segment 将输入文件切片为HLS媒体播放列表和分片，fMP4分片可同时用于DASH
+	Segment(ctx context.Context, req *SegmentRequest) (*SegmentResult, error)
+}
+
+// SegmentRequest 切片请求
+type SegmentRequest struct {
+	InputPath       string     // 输入视频文件路径
+	OutputDir       string     // 输出目录
+	SegmentDuration int        // 分片时长（秒）
+	Rendition       *Rendition // 码率档位
+	Rotation        int        // 原始视频显示时的顺时针旋转角度
+}
+
+// SegmentResult 切片结果
+type SegmentResult struct {
+	PlaylistFile string   // 媒体播放列表文件名（相对OutputDir）
+	InitFile     string   // fMP4初始化分片文件名（相对OutputDir），TS分片时为空
+	SegmentFiles []string // 分片文件名列表（相对OutputDir）
+	Bandwidth    int      // 实际带宽估算（bps）
+}
+
+// PackageRequest HLS打包请求
+type PackageRequest struct {
+	VideoID      string `json:"video_id"`
+	BucketName   string `json:"bucket_name"` // 原始视频所在存储桶
+	ObjectName   string `json:"object_name"`
+	OutputBucket string `json:"output_bucket"` // 播放列表和分片的存储桶，为空时与原始视频相同
+	Width        int    `json:"width"`         // 显示宽度（已按旋转交换宽高）
+	Height       int    `json:"height"`        // 显示高度
+	Bitrate      int64  `json:"bitrate"`       // 原始视频码率（bps）
+	Rotation     int    `json:"rotation"`      // 原始视频显示时的顺时针旋转角度，转码档位按旋转后的方向缩放
+}
+
+// outputBucket 获取播放列表和分片的存储桶
+func (r *PackageRequest) outputBucket() string {
+	if r.OutputBucket != "" {
+		return r.OutputBucket
+	}
+	return r.BucketName
+}
+
+// PackageResult HLS打包结果
+type PackageResult struct {
+	VideoID        string    `json:"video_id"`
+	MasterPlaylist string    `json:"master_playlist"`
+	Manifest       string    `json:"manifest,omitempty"` // DASH清单，分片不是fMP4时为空
+	Renditions     []string  `json:"renditions"`
+	SegmentCount   int       `json:"segment_count"`
+	PackagedAt     time.Time `json:"packaged_at"`
+}
+
+// HLSPackager HLS打包服务，分片为fMP4时同时生成引用相同分片的DASH清单
+type HLSPackager struct {
+	storage         storage.StorageInterface
+	segmenter       Segmenter
+	segmentDuration int
+	renditions      []*Rendition
+	tempFiles       *tempfile.Manager
+	mutex           sync.Mutex
+	inFlight        map[string]struct{}
+}
+
+// NewHLSPackager 创建HLS打包服务
+func NewHLSPackager(storage storage.StorageInterface, segmenter Segmenter, segmentDuration int, renditions []*Rendition) *HLSPackager {
+	if segmentDuration <= 0 {
+		segmentDuration = 6
+	}
+	if len(renditions) == 0 {
+		renditions = []*Rendition{{Name: SourceRenditionName}}
+	}
+
+	return &HLSPackager{
+		storage:         storage,
+		segmenter:       segmenter,
+		segmentDuration: segmentDuration,
+		renditions:      renditions,
+		inFlight:        make(map[string]struct{}),
+	}
+}
+
+// SetTempFiles 设置切片使用的临时目录管理器，未设置时使用系统临时目录
+func (p *HLSPackager) SetTempFiles(tempFiles *tempfile.Manager) {
+	p.tempFiles = tempFiles
+}
+
+// IsAvailable 打包服务是否可用
+func (p *HLSPackager) IsAvailable() bool {
+	return p.segmenter != nil && p.segmenter.IsAvailable()
+}
+
+// IsPackaging 视频是否正在打包
+func (p *HLSPackager) IsPackaging(videoID string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	_, ok := p.inFlight[videoID]
+	return ok
+}
+
+// IsPackaged 检查视频是否已完成HLS打包
+func (p *HLSPackager) IsPackaged(ctx context.Context, bucketName, videoID string) (bool, error) {
+	return p.storage.FileExists(ctx, bucketName, PlaylistObjectName(videoID, MasterPlaylistName))
+}
+
+// Package 对视频进行HLS打包，同一视频正在打包时返回ErrPackagingInProgress
+func (p *HLSPackager) Package(ctx context.Context, req *PackageRequest) (*PackageResult, error) {
+	if err := p.validatePackageRequest(req); err != nil {
+		return nil, err
+	}
+	if !p.IsAvailable() {
+		return nil, fmt.Errorf("HLS切片器不可用")
+	}
+
+	p.mutex.Lock()
+	if _, ok := p.inFlight[req.VideoID]; ok {
+		p.mutex.Unlock()
+		return nil, ErrPackagingInProgress
+	}
+	p.inFlight[req.VideoID] = struct{}{}
+	p.mutex.Unlock()
+
+	defer func() {
+		p.mutex.Lock()
+		delete(p.inFlight, req.VideoID)
+		p.mutex.Unlock()
+	}()
+
+	return p.doPackage(ctx, req)
+}
+
+// doPackage 执行打包：下载原始视频、切片、上传播放列表、分片和DASH清单
+func (p *HLSPackager) doPackage(ctx context.Context, req *PackageRequest) (*PackageResult, error) {
+	workDir, err := p.tempFiles.MkdirTemp("zhulong-hls-*")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	defer p.tempFiles.Remove(workDir)
+
+	inputPath := filepath.Join(workDir, "input"+path.Ext(req.ObjectName))
+	if err := p.downloadSource(ctx, req.BucketName, req.ObjectName, inputPath); err != nil {
+		return nil, err
+	}
+
+	result := &PackageResult{
+		VideoID:        req.VideoID,
+		MasterPlaylist: PlaylistObjectName(req.VideoID, MasterPlaylistName),
+	}
+
+	var variants []*Variant
+	var representations []*Representation
+	for _, rendition := range p.renditions {
+		segResult, err := p.segmenter.Segment(ctx, &SegmentRequest{
+			InputPath:       inputPath,
+			OutputDir:       workDir,
+			SegmentDuration: p.segmentDuration,
+			Rendition:       rendition,
+			Rotation:        req.Rotation,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("视频切片失败(%s): %w", rendition.Name, err)
+		}
+
+		if segResult.InitFile != "" {
+			if err := p.uploadLocalFile(ctx, req.outputBucket(), workDir, req.VideoID, segResult.InitFile, InitSegmentContentType); err != nil {
+				return nil, err
+			}
+		}
+		for _, segmentFile := range segResult.SegmentFiles {
+			if err := p.uploadLocalFile(ctx, req.outputBucket(), workDir, req.VideoID, segmentFile, SegmentContentType); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.uploadLocalFile(ctx, req.outputBucket(), workDir, req.VideoID, segResult.PlaylistFile, PlaylistContentType); err != nil {
+			return nil, err
+		}
+
+		variant := p.buildVariant(rendition, segResult, req)
+		variants = append(variants, variant)
+		representation, err := p.buildRepresentation(rendition, variant, workDir, segResult)
+		if err != nil {
+			return nil, err
+		}
+		representations = append(representations, representation)
+		result.Renditions = append(result.Renditions, rendition.Name)
+		result.SegmentCount += len(segResult.SegmentFiles)
+	}
+
+	// 所有档位都是fMP4分片时才能生成DASH清单
+	if dashCompatible(representations) {
+		manifest, err := GenerateManifest(representations)
+		if err != nil {
+			return nil, err
+		}
+		manifestObject := PlaylistObjectName(req.VideoID, ManifestName)
+		if _, err := p.storage.UploadFile(ctx, req.outputBucket(), manifestObject, manifest, ManifestContentType); err != nil {
+			return nil, fmt.Errorf("上传DASH清单失败: %w", err)
+		}
+		result.Manifest = manifestObject
+	}
+
+	// 主播放列表最后上传，作为打包完成的标志
+	master := GenerateMasterPlaylist(variants)
+	if _, err := p.storage.UploadFile(ctx, req.outputBucket(), result.MasterPlaylist, []byte(master), PlaylistContentType); err != nil {
+		return nil, fmt.Errorf("上传主播放列表失败: %w", err)
+	}
+
+	result.PackagedAt = time.Now()
+	return result, nil
+}
+
+// downloadSource 将原始视频流式写入本地文件，不将整个视频读入内存
+func (p *HLSPackager) downloadSource(ctx context.Context, bucketName, objectName, inputPath string) error {
+	reader, err := p.storage.OpenFile(ctx, bucketName, objectName)
+	if err != nil {
+		return fmt.Errorf("下载原始视频失败: %w", err)
+	}
+	defer reader.Close()
+
+	file, err := os.OpenFile(inputPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	if _, err := io.Copy(file, reader); err != nil {
+		file.Close()
+		return fmt.Errorf("下载原始视频失败: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("写入临时文件失败: %w", err)
+	}
+	return nil
+}
+
+// buildVariant 构建主播放列表中的档位条目
+func (p *HLSPackager) buildVariant(rendition *Rendition, segResult *SegmentResult, req *PackageRequest) *Variant {
+	variant := &Variant{
+		URI:       segResult.PlaylistFile,
+		Bandwidth: rendition.Bandwidth(),
+		Width:     rendition.Width,
+		Height:    rendition.Height,
+	}
+
+	if rendition.IsSource() {
+		variant.Width = req.Width
+		variant.Height = req.Height
+		variant.Bandwidth = int(req.Bitrate)
+	} else if isPortraitRotation(req.Rotation) {
+		// 旋转90/270度的视频按短边缩放，转码后的画面为竖屏
+		variant.Width, variant.Height = rendition.Height, rendition.Width
+	}
+	if segResult.Bandwidth > 0 {
+		variant.Bandwidth = segResult.Bandwidth
+	}
+	if variant.Bandwidth <= 0 {
+		variant.Bandwidth = defaultBandwidth
+	}
+
+	return variant
+}
+
+// isPortraitRotation 检查旋转角度是否使画面宽高互换
+func isPortraitRotation(rotation int) bool {
+	return rotation == 90 || rotation == 270
+}
+
+// buildRepresentation 根据媒体播放列表构建DASH清单中的档位
+func (p *HLSPackager) buildRepresentation(rendition *Rendition, variant *Variant, workDir string, segResult *SegmentResult) (*Representation, error) {
+	content, err := os.ReadFile(filepath.Join(workDir, segResult.PlaylistFile))
+	if err != nil {
+		return nil, fmt.Errorf("读取媒体播放列表失败: %w", err)
+	}
+	playlist, err := ParseMediaPlaylist(content)
+	if err != nil {
+		return nil, err
+	}
+
+	representation := &Representation{
+		ID:        rendition.Name,
+		Bandwidth: variant.Bandwidth,
+		Width:     variant.Width,
+		Height:    variant.Height,
+		Playlist:  playlist,
+	}
+	if segResult.InitFile != "" {
+		init, err := os.ReadFile(filepath.Join(workDir, filepath.FromSlash(segResult.InitFile)))
+		if err != nil {
+			return nil, fmt.Errorf("读取初始化分片失败: %w", err)
+		}
+		representation.Codecs = DetectCodecs(init)
+	}
+	return representation, nil
+}
+
+// dashCompatible 所有档位是否都可以用于DASH清单
+func dashCompatible(representations []*Representation) bool {
+	for _, representation := range representations {
+		if representation.Playlist.InitURI == "" || len(representation.Playlist.Segments) == 0 {
+			return false
+		}
+	}
+	return len(representations) > 0
+}
+
+// uploadLocalFile 上传本地切片产物到存储桶
+func (p *HLSPackager) uploadLocalFile(ctx context.Context, bucketName, workDir, videoID, relPath, contentType string) error {
+	data, err := os.ReadFile(filepath.Join(workDir, filepath.FromSlash(relPath)))
+	if err != nil {
+		return fmt.Errorf("读取切片文件失败: %w", err)
+	}
+
+	objectName := PlaylistObjectName(videoID, relPath)
+	if _, err := p.storage.UploadFile(ctx, bucketName, objectName, data, contentType); err != nil {
+		return fmt.Errorf("上传切片文件失败: %w", err)
+	}
+
+	return nil
+}
+
+// IsManifestPackaged 检查视频是否已生成DASH清单
+func (p *HLSPackager) IsManifestPackaged(ctx context.Context, bucketName, videoID string) (bool, error) {
+	return p.storage.FileExists(ctx, bucketName, PlaylistObjectName(videoID, ManifestName))
+}
+
+// GetManifest 获取DASH清单内容，分片地址会被替换为预签名URL
+func (p *HLSPackager) GetManifest(ctx context.Context, bucketName, videoID string, expiry time.Duration) ([]byte, error) {
+	content, err := p.storage.DownloadFile(ctx, bucketName, PlaylistObjectName(videoID, ManifestName))
+	if err != nil {
+		return nil, fmt.Errorf("获取DASH清单失败: %w", err)
+	}
+
+	return RewriteManifest(content, func(uri string) (string, error) {
+		return p.storage.GetPresignedURL(ctx, bucketName, PlaylistObjectName(videoID, uri), expiry)
+	})
+}
+
+// GetPlaylist 获取播放列表内容，媒体播放列表中的分片地址会被替换为预签名URL
+func (p *HLSPackager) GetPlaylist(ctx context.Context, bucketName, videoID, playlist string, expiry time.Duration) ([]byte, error) {
+	if err := ValidatePlaylistName(playlist); err != nil {
+		return nil, err
+	}
+
+	content, err := p.storage.DownloadFile(ctx, bucketName, PlaylistObjectName(videoID, playlist))
+	if err != nil {
+		return nil, fmt.Errorf("获取播放列表失败: %w", err)
+	}
+
+	if playlist == MasterPlaylistName {
+		return content, nil
+	}
+
+	return RewritePlaylist(content, func(uri string) (string, error) {
+		return p.storage.GetPresignedURL(ctx, bucketName, PlaylistObjectName(videoID, uri), expiry)
+	})
+}
+
+// validatePackageRequest 验证打包请求
+func (p *HLSPackager) validatePackageRequest(req *PackageRequest) error {
+	if req == nil {
+		return fmt.Errorf("打包请求不能为空")
+	}
+	if req.VideoID == "" {
+		return fmt.Errorf("视频ID不能为空")
+	}
+	if req.BucketName == "" {
+		return fmt.Errorf("存储桶名称不能为空")
+	}
+	if req.ObjectName == "" {
+		return fmt.Errorf("对象名称不能为空")
+	}
+	return nil
+}
+
+// Variant 主播放列表中的档位条目
+type Variant struct {
+	URI       string
+	Bandwidth int
+	Width     int
+	Height    int
+}
+
+// GenerateMasterPlaylist 生成HLS主播放列表
+func GenerateMasterPlaylist(variants []*Variant) string {
+	var builder strings.Builder
+	builder.WriteString("#EXTM3U\n")
+	builder.WriteString("#EXT-X-VERSION:3\n")
+
+	for _, variant := range variants {
+		builder.WriteString(fmt.Sprintf("#EXT-X-STREAM-INF:BANDWIDTH=%d", variant.Bandwidth))
+		if variant.Width > 0 && variant.Height > 0 {
+			builder.WriteString(fmt.Sprintf(",RESOLUTION=%dx%d", variant.Width, variant.Height))
+		}
+		builder.WriteString("\n")
+		builder.WriteString(variant.URI)
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+// RewritePlaylist 重写播放列表中的URI行和EXT-X-MAP初始化分片地址
+func RewritePlaylist(content []byte, rewrite func(uri string) (string, error)) ([]byte, error) {
+	var buffer bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if uri := mapURI(line); strings.HasPrefix(line, "#EXT-X-MAP:") && uri != "" {
+			rewritten, err := rewrite(uri)
+			if err != nil {
+				return nil, fmt.Errorf("重写播放列表地址失败: %w", err)
+			}
+			line = strings.Replace(line, `URI="`+uri+`"`, `URI="`+rewritten+`"`, 1)
+		} else if line != "" && !strings.HasPrefix(line, "#") {
+			rewritten, err := rewrite(line)
+			if err != nil {
+				return nil, fmt.Errorf("重写播放列表地址失败: %w", err)
+			}
+			line = rewritten
+		}
+		buffer.WriteString(line)
+		buffer.WriteString("\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取播放列表失败: %w", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// PlaylistObjectName 获取HLS文件在存储桶中的对象名称
+func PlaylistObjectName(videoID, name string) string {
+	return path.Join(HLSRootPrefix, videoID, name)
+}
+
+// VideoPrefix 获取视频所有HLS文件的对象前缀
+func VideoPrefix(videoID string) string {
+	return path.Join(HLSRootPrefix, videoID) + "/"
+}
+
+// ValidatePlaylistName 验证播放列表名称，防止路径穿越
+func ValidatePlaylistName(name string) error {
+	if name == "" {
+		return fmt.Errorf("播放列表名称不能为空")
+	}
+	if !strings.HasSuffix(name, ".m3u8") {
+		return fmt.Errorf("不支持的播放列表格式: %s", name)
+	}
+	if strings.Contains(name, "/") || strings.Contains(name, "\\") || strings.Contains(name, "..") {
+		return fmt.Errorf("无效的播放列表名称: %s", name)
+	}
+	return nil
+}
@@ -0,0 +1,285 @@
+package streaming
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/storage"
+	"github.com/manteia/zhulong/pkg/tempfile"
+)
+
+// fakeSegmenter 测试用切片器，生成固定数量的分片
+type fakeSegmenter struct {
+	available bool
+	segments  int
+}
+
+func (f *fakeSegmenter) IsAvailable() bool {
+	return f.available
+}
+
+func (f *fakeSegmenter) Segment(ctx context.Context, req *SegmentRequest) (*SegmentResult, error) {
+	name := req.Rendition.Name
+	if err := os.MkdirAll(filepath.Join(req.OutputDir, name), 0755); err != nil {
+		return nil, err
+	}
+
+	result := &SegmentResult{PlaylistFile: name + ".m3u8", InitFile: name + "/init.mp4"}
+	if err := os.WriteFile(filepath.Join(req.OutputDir, result.InitFile), []byte("ftyp-moov-avcC\x01\x64\x00\x1f-mp4a"), 0644); err != nil {
+		return nil, err
+	}
+	playlist := fmt.Sprintf("#EXTM3U\n#EXT-X-VERSION:7\n#EXT-X-PLAYLIST-TYPE:VOD\n#EXT-X-MAP:URI=\"%s\"\n", result.InitFile)
+	for i := 0; i < f.segments; i++ {
+		segment := fmt.Sprintf("%s/segment_%05d.m4s", name, i)
+		if err := os.WriteFile(filepath.Join(req.OutputDir, segment), []byte("m4s-data"), 0644); err != nil {
+			return nil, err
+		}
+		result.SegmentFiles = append(result.SegmentFiles, segment)
+		playlist += fmt.Sprintf("#EXTINF:%d.000000,\n%s\n", req.SegmentDuration, segment)
+	}
+	playlist += "#EXT-X-ENDLIST\n"
+
+	if err := os.WriteFile(filepath.Join(req.OutputDir, result.PlaylistFile), []byte(playlist), 0644); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TestGenerateMasterPlaylist 测试主播放列表生成
+func TestGenerateMasterPlaylist(t *testing.T) {
+	playlist := GenerateMasterPlaylist([]*Variant{
+		{URI: "720p.m3u8", Bandwidth: 2928000, Width: 1280, Height: 720},
+		{URI: "source.m3u8", Bandwidth: 5000000},
+	})
+
+	lines := strings.Split(strings.TrimSpace(playlist), "\n")
+	require.Len(t, lines, 6, "主播放列表行数应该正确")
+	assert.Equal(t, "#EXTM3U", lines[0], "应该以EXTM3U开头")
+	assert.Equal(t, "#EXT-X-STREAM-INF:BANDWIDTH=2928000,RESOLUTION=1280x720", lines[2], "应该包含带宽和分辨率")
+	assert.Equal(t, "720p.m3u8", lines[3])
+	assert.Equal(t, "#EXT-X-STREAM-INF:BANDWIDTH=5000000", lines[4], "未知分辨率时不应输出RESOLUTION")
+	assert.Equal(t, "source.m3u8", lines[5])
+}
+
+// TestRewritePlaylist 测试播放列表地址重写
+func TestRewritePlaylist(t *testing.T) {
+	content := []byte("#EXTM3U\n#EXT-X-MAP:URI=\"source/init.mp4\"\n#EXTINF:6.0,\nsource/segment_00000.m4s\n\n#EXT-X-ENDLIST\n")
+
+	rewritten, err := RewritePlaylist(content, func(uri string) (string, error) {
+		return "https://cdn.example.com/" + uri + "?sig=1", nil
+	})
+	require.NoError(t, err, "重写播放列表应该成功")
+	assert.Contains(t, string(rewritten), "https://cdn.example.com/source/segment_00000.m4s?sig=1", "分片地址应该被重写")
+	assert.Contains(t, string(rewritten), `#EXT-X-MAP:URI="https://cdn.example.com/source/init.mp4?sig=1"`, "初始化分片地址应该被重写")
+	assert.Contains(t, string(rewritten), "#EXTINF:6.0,", "标签行应该保持不变")
+
+	_, err = RewritePlaylist(content, func(uri string) (string, error) {
+		return "", fmt.Errorf("签名失败")
+	})
+	assert.Error(t, err, "重写函数出错时应该返回错误")
+}
+
+// TestValidatePlaylistName 测试播放列表名称验证
+func TestValidatePlaylistName(t *testing.T) {
+	assert.NoError(t, ValidatePlaylistName("master.m3u8"))
+	assert.NoError(t, ValidatePlaylistName("720p.m3u8"))
+
+	invalid := []string{"", "segment.ts", "../master.m3u8", "source/index.m3u8", "a\\b.m3u8"}
+	for _, name := range invalid {
+		assert.Error(t, ValidatePlaylistName(name), "无效名称应该验证失败: %q", name)
+	}
+}
+
+// TestPlaylistObjectName 测试HLS对象名称
+func TestPlaylistObjectName(t *testing.T) {
+	assert.Equal(t, "hls/video-1/master.m3u8", PlaylistObjectName("video-1", MasterPlaylistName))
+	assert.Equal(t, "hls/video-1/source/segment_00000.ts", PlaylistObjectName("video-1", "source/segment_00000.ts"))
+	assert.Equal(t, "hls/video-1/", VideoPrefix("video-1"))
+}
+
+// TestHLSPackager_Defaults 测试打包服务默认配置
+func TestHLSPackager_Defaults(t *testing.T) {
+	packager := NewHLSPackager(nil, &fakeSegmenter{available: true}, 0, nil)
+
+	assert.Equal(t, 6, packager.segmentDuration, "默认分片时长应该为6秒")
+	require.Len(t, packager.renditions, 1, "默认应该只有原画档位")
+	assert.Equal(t, SourceRenditionName, packager.renditions[0].Name)
+	assert.True(t, packager.renditions[0].IsSource(), "默认档位应该不转码")
+	assert.True(t, packager.IsAvailable())
+	assert.False(t, packager.IsPackaging("video-1"))
+}
+
+// TestHLSPackager_PackageValidation 测试打包请求验证
+func TestHLSPackager_PackageValidation(t *testing.T) {
+	ctx := context.Background()
+	packager := NewHLSPackager(nil, &fakeSegmenter{available: true}, 6, nil)
+
+	_, err := packager.Package(ctx, nil)
+	assert.Error(t, err, "空请求应该返回错误")
+
+	_, err = packager.Package(ctx, &PackageRequest{BucketName: "b", ObjectName: "o"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "视频ID不能为空")
+
+	unavailable := NewHLSPackager(nil, &fakeSegmenter{available: false}, 6, nil)
+	_, err = unavailable.Package(ctx, &PackageRequest{VideoID: "v", BucketName: "b", ObjectName: "o"})
+	assert.Error(t, err, "切片器不可用时应该返回错误")
+	assert.Contains(t, err.Error(), "不可用")
+}
+
+// TestHLSPackager_PackageAndGetPlaylist 测试完整打包流程
+func TestHLSPackager_PackageAndGetPlaylist(t *testing.T) {
+	store := setupTestStorage(t)
+	ctx := context.Background()
+	bucket := "test-hls-" + strings.ReplaceAll(time.Now().Format("20060102-150405.000"), ".", "")
+	require.NoError(t, store.CreateBucket(ctx, bucket))
+
+	videoID := "video-hls-test"
+	objectName := "videos/2025/08/video-hls-test.mp4"
+	_, err := store.UploadFile(ctx, bucket, objectName, []byte("fake video content"), "video/mp4")
+	require.NoError(t, err)
+
+	defer func() {
+		files, _ := store.ListFiles(ctx, bucket, "")
+		for _, file := range files {
+			_ = store.DeleteFile(ctx, bucket, file.Key)
+		}
+		_ = store.RemoveBucket(ctx, bucket)
+	}()
+
+	packager := NewHLSPackager(store, &fakeSegmenter{available: true, segments: 3}, 6, nil)
+	result, err := packager.Package(ctx, &PackageRequest{
+		VideoID:    videoID,
+		BucketName: bucket,
+		ObjectName: objectName,
+		Width:      1920,
+		Height:     1080,
+		Bitrate:    4000000,
+	})
+	require.NoError(t, err, "打包应该成功")
+	assert.Equal(t, "hls/video-hls-test/master.m3u8", result.MasterPlaylist)
+	assert.Equal(t, []string{SourceRenditionName}, result.Renditions)
+	assert.Equal(t, 3, result.SegmentCount)
+
+	packaged, err := packager.IsPackaged(ctx, bucket, videoID)
+	require.NoError(t, err)
+	assert.True(t, packaged, "打包后应该存在主播放列表")
+
+	master, err := packager.GetPlaylist(ctx, bucket, videoID, MasterPlaylistName, time.Hour)
+	require.NoError(t, err)
+	assert.Contains(t, string(master), "BANDWIDTH=4000000,RESOLUTION=1920x1080")
+	assert.Contains(t, string(master), "source.m3u8")
+
+	media, err := packager.GetPlaylist(ctx, bucket, videoID, "source.m3u8", time.Hour)
+	require.NoError(t, err)
+	assert.Contains(t, string(media), "hls/video-hls-test/source/segment_00000.m4s", "分片地址应该被替换为预签名URL")
+	assert.Contains(t, string(media), "signature=", "分片地址应该带有签名")
+}
+
+// setupTestStorage 设置测试存储，使用内存存储，不依赖MinIO服务
+func setupTestStorage(t *testing.T) storage.StorageInterface {
+	store, err := storage.NewMemoryStorage(&storage.MemoryConfig{BaseURL: "http://localhost:8888/storage"})
+	require.NoError(t, err, "创建测试存储应该成功")
+	return store
+}
+
+// TestHLSPackager_OutputBucket 测试播放列表和分片写入独立的存储桶
+func TestHLSPackager_OutputBucket(t *testing.T) {
+	store, err := storage.NewLocalStorage(&storage.LocalConfig{RootDir: t.TempDir(), BaseURL: "http://localhost:8888/storage"})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = store.UploadFile(ctx, "videos", "videos/source.mp4", []byte("fake video content"), "video/mp4")
+	require.NoError(t, err)
+
+	packager := NewHLSPackager(store, &fakeSegmenter{available: true, segments: 2}, 6, nil)
+	_, err = packager.Package(ctx, &PackageRequest{
+		VideoID:      "video-output",
+		BucketName:   "videos",
+		ObjectName:   "videos/source.mp4",
+		OutputBucket: "renditions",
+	})
+	require.NoError(t, err)
+
+	packaged, err := packager.IsPackaged(ctx, "renditions", "video-output")
+	require.NoError(t, err)
+	assert.True(t, packaged, "主播放列表应该写入输出存储桶")
+
+	packaged, err = packager.IsPackaged(ctx, "videos", "video-output")
+	require.NoError(t, err)
+	assert.False(t, packaged, "原始视频所在存储桶中不应该有打包结果")
+
+	hasManifest, err := packager.IsManifestPackaged(ctx, "renditions", "video-output")
+	require.NoError(t, err)
+	assert.True(t, hasManifest, "fMP4分片应该同时生成DASH清单")
+
+	manifest, err := packager.GetManifest(ctx, "renditions", "video-output", time.Hour)
+	require.NoError(t, err)
+	assert.Contains(t, string(manifest), `codecs="avc1.64001f,mp4a.40.2"`)
+	assert.Contains(t, string(manifest), "hls/video-output/source/init.mp4", "初始化分片地址应该被替换为存储地址")
+	assert.Contains(t, string(manifest), "hls/video-output/source/segment_00001.m4s", "分片地址应该被替换为存储地址")
+}
+
+// TestHLSPackager_RotatedVariant 测试竖屏视频的转码档位分辨率
+func TestHLSPackager_RotatedVariant(t *testing.T) {
+	store, err := storage.NewLocalStorage(&storage.LocalConfig{RootDir: t.TempDir(), BaseURL: "http://localhost:8888/storage"})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = store.UploadFile(ctx, "videos", "videos/portrait.mov", []byte("fake video content"), "video/mp4")
+	require.NoError(t, err)
+
+	packager := NewHLSPackager(store, &fakeSegmenter{available: true, segments: 1}, 6, []*Rendition{
+		{Name: SourceRenditionName},
+		{Name: "720p", Width: 1280, Height: 720, VideoBitrate: 2800},
+	})
+	_, err = packager.Package(ctx, &PackageRequest{
+		VideoID:    "video-portrait",
+		BucketName: "videos",
+		ObjectName: "videos/portrait.mov",
+		Width:      1080,
+		Height:     1920,
+		Bitrate:    6000000,
+		Rotation:   90,
+	})
+	require.NoError(t, err)
+
+	master, err := packager.GetPlaylist(ctx, "videos", "video-portrait", MasterPlaylistName, time.Hour)
+	require.NoError(t, err)
+	assert.Contains(t, string(master), "RESOLUTION=1080x1920", "原画档位使用显示分辨率")
+	assert.Contains(t, string(master), "RESOLUTION=720x1280", "转码档位应该交换宽高")
+}
+
+// TestHLSPackager_TempFiles 测试切片使用受管理的临时目录，占用达到上限时拒绝打包
+func TestHLSPackager_TempFiles(t *testing.T) {
+	store, err := storage.NewLocalStorage(&storage.LocalConfig{RootDir: t.TempDir(), BaseURL: "http://localhost:8888/storage"})
+	require.NoError(t, err)
+	ctx := context.Background()
+	_, err = store.UploadFile(ctx, "videos", "videos/source.mp4", []byte("fake video content"), "video/mp4")
+	require.NoError(t, err)
+
+	tempFiles, err := tempfile.NewManager(&tempfile.Config{Dir: t.TempDir(), MaxBytes: 8})
+	require.NoError(t, err)
+	packager := NewHLSPackager(store, &fakeSegmenter{available: true, segments: 2}, 6, nil)
+	packager.SetTempFiles(tempFiles)
+	req := &PackageRequest{VideoID: "video-temp", BucketName: "videos", ObjectName: "videos/source.mp4"}
+
+	_, err = packager.Package(ctx, req)
+	require.NoError(t, err)
+	files, _, err := tempFiles.Usage()
+	require.NoError(t, err)
+	assert.Zero(t, files, "打包完成后应该删除临时文件")
+	assert.Equal(t, int64(1), tempFiles.Stats().Created)
+
+	require.NoError(t, os.WriteFile(filepath.Join(tempFiles.Dir(), "multipart-1"), []byte("0123456789"), 0o644))
+	_, err = packager.Package(ctx, req)
+	assert.ErrorIs(t, err, tempfile.ErrSpaceExhausted)
+}
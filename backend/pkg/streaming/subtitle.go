@@ -0,0 +1,26 @@
+package streaming
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// WebVTTContentType WebVTT字幕的内容类型
+const WebVTTContentType = "text/vtt; charset=utf-8"
+
+// srtTimingPattern SRT的时间轴行，毫秒使用逗号分隔
+var srtTimingPattern = regexp.MustCompile(`(?m)^(\d{2,}:\d{2}:\d{2}),(\d{3})\s+-->\s+(\d{2,}:\d{2}:\d{2}),(\d{3})`)
+
+// SRTToWebVTT 将SRT字幕转换为WebVTT，投屏接收端和浏览器的字幕轨道只支持WebVTT
+// 序号保留为cue标识，时间轴的毫秒分隔符替换为点，去除UTF-8 BOM并统一换行符
+func SRTToWebVTT(data []byte) []byte {
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	data = bytes.ReplaceAll(data, []byte("\r"), []byte("\n"))
+	data = srtTimingPattern.ReplaceAll(data, []byte("$1.$2 --> $3.$4"))
+
+	var out bytes.Buffer
+	out.WriteString("WEBVTT\n\n")
+	out.Write(bytes.TrimLeft(data, "\n"))
+	return out.Bytes()
+}
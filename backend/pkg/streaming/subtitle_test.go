@@ -0,0 +1,17 @@
+package streaming
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSRTToWebVTT 测试SRT字幕转换为WebVTT
+func TestSRTToWebVTT(t *testing.T) {
+	srt := "\xEF\xBB\xBF1\r\n00:00:01,000 --> 00:00:02,500\r\n你好\r\n\r\n2\r\n00:00:03,000 --> 00:00:04,000\r\n第二行, 带逗号\r\n"
+
+	vtt := string(SRTToWebVTT([]byte(srt)))
+	assert.Equal(t, "WEBVTT\n\n1\n00:00:01.000 --> 00:00:02.500\n你好\n\n2\n00:00:03.000 --> 00:00:04.000\n第二行, 带逗号\n", vtt)
+
+	assert.Equal(t, "WEBVTT\n\n", string(SRTToWebVTT(nil)))
+}
@@ -0,0 +1,285 @@
+package tempfile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultDirName 未配置临时目录时在系统临时目录下使用的子目录
+const defaultDirName = "zhulong"
+
+// ErrSpaceExhausted 临时目录占用已达到上限
+var ErrSpaceExhausted = errors.New("临时目录空间不足")
+
+// Config 临时目录配置
+type Config struct {
+	Dir             string        // 临时目录，为空时使用系统临时目录下的zhulong目录
+	MaxBytes        int64         // 临时目录占用上限（字节），达到上限后拒绝创建新的临时文件，为0时不限制
+	MaxAge          time.Duration // 超过该时长未修改且未被使用的临时文件在清理时删除，为0时不按时长清理
+	CleanupInterval time.Duration // 定期清理的间隔，为0时不定期清理
+}
+
+// Stats 临时目录统计
+type Stats struct {
+	Dir           string    // 临时目录
+	Files         int       // 当前文件数量
+	Bytes         int64     // 当前占用（字节）
+	MaxBytes      int64     // 占用上限（字节），为0时不限制
+	Created       int64     // 创建的临时文件和目录数量
+	Rejected      int64     // 因空间不足拒绝创建的次数
+	CleanedFiles  int64     // 清理删除的过期文件和目录数量
+	CleanedBytes  int64     // 清理释放的空间（字节）
+	LastCleanupAt time.Time // 最近一次清理时间，未清理时为零值
+}
+
+// Manager 临时目录管理器，上传缓冲、转码和帧提取使用的临时文件统一创建在该目录下
+// 创建前检查占用上限，定期删除残留的过期文件；为nil时使用系统临时目录且不做限制
+type Manager struct {
+	dir             string
+	maxBytes        int64
+	maxAge          time.Duration
+	cleanupInterval time.Duration
+
+	mutex  sync.Mutex
+	active map[string]struct{} // 正在使用的临时文件和目录，清理时跳过
+
+	created      atomic.Int64
+	rejected     atomic.Int64
+	cleanedFiles atomic.Int64
+	cleanedBytes atomic.Int64
+	lastCleanup  atomic.Pointer[time.Time]
+}
+
+// NewManager 创建临时目录管理器，目录不存在时创建
+func NewManager(cfg *Config) (*Manager, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	dir := cfg.Dir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), defaultDirName)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建临时目录失败: %w", err)
+	}
+
+	return &Manager{
+		dir:             dir,
+		maxBytes:        max(cfg.MaxBytes, 0),
+		maxAge:          max(cfg.MaxAge, 0),
+		cleanupInterval: max(cfg.CleanupInterval, 0),
+		active:          make(map[string]struct{}),
+	}, nil
+}
+
+// Dir 获取临时目录
+func (m *Manager) Dir() string {
+	if m == nil {
+		return os.TempDir()
+	}
+	return m.dir
+}
+
+// CreateTemp 在临时目录下创建临时文件，占用达到上限时返回ErrSpaceExhausted
+// 使用完毕后调用Remove删除
+func (m *Manager) CreateTemp(pattern string) (*os.File, error) {
+	if m == nil {
+		return os.CreateTemp("", pattern)
+	}
+	if err := m.checkSpace(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.CreateTemp(m.dir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	m.track(file.Name())
+	return file, nil
+}
+
+// MkdirTemp 在临时目录下创建临时目录，占用达到上限时返回ErrSpaceExhausted
+// 使用完毕后调用Remove删除
+func (m *Manager) MkdirTemp(pattern string) (string, error) {
+	if m == nil {
+		return os.MkdirTemp("", pattern)
+	}
+	if err := m.checkSpace(); err != nil {
+		return "", err
+	}
+
+	dir, err := os.MkdirTemp(m.dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	m.track(dir)
+	return dir, nil
+}
+
+// Remove 删除临时文件或目录
+func (m *Manager) Remove(path string) error {
+	if m != nil {
+		m.mutex.Lock()
+		delete(m.active, path)
+		m.mutex.Unlock()
+	}
+	return os.RemoveAll(path)
+}
+
+// Usage 统计临时目录下的文件数量和占用
+func (m *Manager) Usage() (int, int64, error) {
+	files := 0
+	var bytes int64
+	err := filepath.WalkDir(m.Dir(), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// 统计期间被删除的文件忽略
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		files++
+		bytes += info.Size()
+		return nil
+	})
+	return files, bytes, err
+}
+
+// Cleanup 删除超过有效期且未被使用的临时文件和目录，返回删除的数量和释放的空间
+// 不是由管理器创建的文件（例如上传请求缓冲到磁盘的文件）同样按修改时间清理
+func (m *Manager) Cleanup() (int, int64) {
+	if m == nil || m.maxAge <= 0 {
+		return 0, 0
+	}
+	now := time.Now()
+	defer m.lastCleanup.Store(&now)
+
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return 0, 0
+	}
+
+	count := 0
+	var bytes int64
+	for _, entry := range entries {
+		path := filepath.Join(m.dir, entry.Name())
+		if m.isActive(path) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || now.Sub(info.ModTime()) < m.maxAge {
+			continue
+		}
+
+		size := pathSize(path)
+		if err := os.RemoveAll(path); err != nil {
+			continue
+		}
+		count++
+		bytes += size
+	}
+
+	m.cleanedFiles.Add(int64(count))
+	m.cleanedBytes.Add(bytes)
+	return count, bytes
+}
+
+// Start 在后台按配置的间隔定期清理过期的临时文件，未配置清理间隔或有效期时不启动
+func (m *Manager) Start() {
+	if m == nil || m.cleanupInterval <= 0 || m.maxAge <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(m.cleanupInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if count, bytes := m.Cleanup(); count > 0 {
+				fmt.Printf("清理了%d个过期的临时文件，释放%d字节\n", count, bytes)
+			}
+		}
+	}()
+}
+
+// Stats 获取临时目录统计
+func (m *Manager) Stats() Stats {
+	if m == nil {
+		return Stats{Dir: os.TempDir()}
+	}
+	files, bytes, _ := m.Usage()
+	stats := Stats{
+		Dir:          m.dir,
+		Files:        files,
+		Bytes:        bytes,
+		MaxBytes:     m.maxBytes,
+		Created:      m.created.Load(),
+		Rejected:     m.rejected.Load(),
+		CleanedFiles: m.cleanedFiles.Load(),
+		CleanedBytes: m.cleanedBytes.Load(),
+	}
+	if lastCleanup := m.lastCleanup.Load(); lastCleanup != nil {
+		stats.LastCleanupAt = *lastCleanup
+	}
+	return stats
+}
+
+// checkSpace 检查临时目录占用是否已达到上限
+func (m *Manager) checkSpace() error {
+	if m.maxBytes <= 0 {
+		return nil
+	}
+	_, bytes, err := m.Usage()
+	if err != nil {
+		return fmt.Errorf("统计临时目录占用失败: %w", err)
+	}
+	if bytes >= m.maxBytes {
+		m.rejected.Add(1)
+		return fmt.Errorf("%w: 已占用%d字节，上限%d字节", ErrSpaceExhausted, bytes, m.maxBytes)
+	}
+	return nil
+}
+
+// track 记录正在使用的临时文件或目录
+func (m *Manager) track(path string) {
+	m.created.Add(1)
+	m.mutex.Lock()
+	m.active[path] = struct{}{}
+	m.mutex.Unlock()
+}
+
+// isActive 临时文件或目录是否正在使用
+func (m *Manager) isActive(path string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	_, ok := m.active[path]
+	return ok
+}
+
+// pathSize 统计文件或目录的总大小
+func pathSize(path string) int64 {
+	var size int64
+	_ = filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,104 @@
+package tempfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewManager 测试创建临时目录管理器
+func TestNewManager(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested", "tmp")
+	manager, err := NewManager(&Config{Dir: dir, MaxBytes: -1})
+	require.NoError(t, err)
+	assert.Equal(t, dir, manager.Dir())
+	assert.DirExists(t, dir, "目录不存在时应该创建")
+	assert.Zero(t, manager.Stats().MaxBytes, "负数上限视为不限制")
+
+	var nilManager *Manager
+	assert.Equal(t, os.TempDir(), nilManager.Dir(), "为nil时使用系统临时目录")
+	file, err := nilManager.CreateTemp("zhulong-test-*")
+	require.NoError(t, err)
+	file.Close()
+	assert.NoError(t, nilManager.Remove(file.Name()))
+}
+
+// TestManager_CreateTemp 测试创建临时文件和目录并检查占用上限
+func TestManager_CreateTemp(t *testing.T) {
+	manager, err := NewManager(&Config{Dir: t.TempDir(), MaxBytes: 10})
+	require.NoError(t, err)
+
+	file, err := manager.CreateTemp("upload-*")
+	require.NoError(t, err)
+	assert.Equal(t, manager.Dir(), filepath.Dir(file.Name()))
+	_, err = file.Write([]byte("0123456789"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	_, err = manager.CreateTemp("upload-*")
+	assert.ErrorIs(t, err, ErrSpaceExhausted, "占用达到上限时应该拒绝创建")
+	_, err = manager.MkdirTemp("hls-*")
+	assert.ErrorIs(t, err, ErrSpaceExhausted)
+
+	stats := manager.Stats()
+	assert.Equal(t, 1, stats.Files)
+	assert.Equal(t, int64(10), stats.Bytes)
+	assert.Equal(t, int64(1), stats.Created)
+	assert.Equal(t, int64(2), stats.Rejected)
+
+	require.NoError(t, manager.Remove(file.Name()))
+	dir, err := manager.MkdirTemp("hls-*")
+	require.NoError(t, err, "删除后应该可以继续创建")
+	assert.DirExists(t, dir)
+	require.NoError(t, manager.Remove(dir))
+	assert.NoDirExists(t, dir)
+}
+
+// TestManager_Cleanup 测试清理过期且未使用的临时文件
+func TestManager_Cleanup(t *testing.T) {
+	manager, err := NewManager(&Config{Dir: t.TempDir(), MaxAge: time.Hour})
+	require.NoError(t, err)
+	old := time.Now().Add(-2 * time.Hour)
+
+	// 上传请求缓冲到磁盘的文件不由管理器创建，同样按修改时间清理
+	stale := filepath.Join(manager.Dir(), "multipart-123")
+	require.NoError(t, os.WriteFile(stale, []byte("stale"), 0o644))
+	require.NoError(t, os.Chtimes(stale, old, old))
+
+	staleDir, err := manager.MkdirTemp("hls-*")
+	require.NoError(t, err)
+	require.NoError(t, manager.Remove(staleDir))
+	require.NoError(t, os.Mkdir(staleDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(staleDir, "segment.ts"), []byte("segment"), 0o644))
+	require.NoError(t, os.Chtimes(staleDir, old, old))
+
+	active, err := manager.CreateTemp("preview-*")
+	require.NoError(t, err)
+	active.Close()
+	require.NoError(t, os.Chtimes(active.Name(), old, old))
+
+	fresh := filepath.Join(manager.Dir(), "multipart-456")
+	require.NoError(t, os.WriteFile(fresh, []byte("fresh"), 0o644))
+
+	count, bytes := manager.Cleanup()
+	assert.Equal(t, 2, count)
+	assert.Equal(t, int64(len("stale")+len("segment")), bytes)
+	assert.NoFileExists(t, stale)
+	assert.NoDirExists(t, staleDir)
+	assert.FileExists(t, active.Name(), "正在使用的临时文件不应该被清理")
+	assert.FileExists(t, fresh, "未过期的文件不应该被清理")
+
+	stats := manager.Stats()
+	assert.Equal(t, int64(2), stats.CleanedFiles)
+	assert.Equal(t, bytes, stats.CleanedBytes)
+	assert.False(t, stats.LastCleanupAt.IsZero())
+
+	noAge, err := NewManager(&Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+	count, _ = noAge.Cleanup()
+	assert.Zero(t, count, "未配置有效期时不清理")
+}
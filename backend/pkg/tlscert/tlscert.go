@@ -0,0 +1,183 @@
+package tlscert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 自签名证书的文件名
+const (
+	CertFileName = "zhulong.crt"
+	KeyFileName  = "zhulong.key"
+)
+
+const (
+	// selfSignedValidity 自签名证书有效期，部分系统不接受有效期超过825天的服务器证书
+	selfSignedValidity = 825 * 24 * time.Hour
+	// renewBefore 自签名证书在过期前多久重新生成
+	renewBefore = 30 * 24 * time.Hour
+)
+
+// now 当前时间，测试时替换
+var now = time.Now
+
+// LoadConfig 加载证书和私钥，创建HTTPS服务的TLS配置，最低版本为TLS 1.2
+func LoadConfig(certFile, keyFile string) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("加载TLS证书失败: %w", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// EnsureSelfSigned 确保目录中存在覆盖所有主机的自签名证书，返回证书和私钥文件路径
+// 证书不存在、无法解析、即将过期或缺少主机时重新生成，私钥文件只允许所有者读写
+func EnsureSelfSigned(dir string, hosts []string) (certFile, keyFile string, err error) {
+	if len(hosts) == 0 {
+		return "", "", fmt.Errorf("自签名证书的主机不能为空")
+	}
+	certFile = filepath.Join(dir, CertFileName)
+	keyFile = filepath.Join(dir, KeyFileName)
+
+	if selfSignedValid(certFile, keyFile, hosts) {
+		return certFile, keyFile, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", "", fmt.Errorf("创建证书目录失败: %w", err)
+	}
+	certPEM, keyPEM, err := generateSelfSigned(hosts)
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		return "", "", fmt.Errorf("保存私钥失败: %w", err)
+	}
+	if err := os.WriteFile(certFile, certPEM, 0o644); err != nil {
+		return "", "", fmt.Errorf("保存证书失败: %w", err)
+	}
+	return certFile, keyFile, nil
+}
+
+// selfSignedValid 已有的证书是否可以继续使用，证书不存在、损坏或与私钥不匹配时返回false
+func selfSignedValid(certFile, keyFile string, hosts []string) bool {
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return false
+	}
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		return false
+	}
+
+	if now().Add(renewBefore).After(leaf.NotAfter) {
+		return false
+	}
+	for _, host := range hosts {
+		if err := leaf.VerifyHostname(host); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// generateSelfSigned 生成ECDSA P-256自签名服务器证书，返回PEM编码的证书和私钥
+func generateSelfSigned(hosts []string) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("生成私钥失败: %w", err)
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("生成证书序列号失败: %w", err)
+	}
+
+	notBefore := now().Add(-time.Hour)
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: hosts[0], Organization: []string{"Zhulong self-signed"}},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("生成证书失败: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("编码私钥失败: %w", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// DefaultHosts 自签名证书默认包含的主机：localhost、本机主机名（及其.local名称）、回环地址和网卡上的地址
+// 局域网内的设备通过IP或mDNS名称访问时都能匹配证书
+func DefaultHosts() []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		hostname = strings.ToLower(hostname)
+		hosts = append(hosts, hostname)
+		if !strings.Contains(hostname, ".") {
+			hosts = append(hosts, hostname+".local")
+		}
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return hosts
+	}
+	for _, addr := range addrs {
+		network, ok := addr.(*net.IPNet)
+		if !ok || network.IP.IsLoopback() || network.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		hosts = append(hosts, network.IP.String())
+	}
+	return hosts
+}
+
+// MergeHosts 合并主机列表，去掉空值和重复项并保持顺序
+func MergeHosts(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	var hosts []string
+	for _, list := range lists {
+		for _, host := range list {
+			host = strings.ToLower(strings.TrimSpace(host))
+			if host == "" || seen[host] {
+				continue
+			}
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
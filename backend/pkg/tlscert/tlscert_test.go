@@ -0,0 +1,101 @@
+package tlscert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadLeaf 读取证书文件中的服务器证书
+func loadLeaf(t *testing.T, certFile, keyFile string) *x509.Certificate {
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	require.NoError(t, err)
+	return leaf
+}
+
+// TestEnsureSelfSigned 测试生成和复用自签名证书
+func TestEnsureSelfSigned(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tls")
+	hosts := []string{"nas.local", "192.168.1.10"}
+
+	certFile, keyFile, err := EnsureSelfSigned(dir, hosts)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, CertFileName), certFile)
+	assert.Equal(t, filepath.Join(dir, KeyFileName), keyFile)
+
+	info, err := os.Stat(keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "私钥只允许所有者读写")
+
+	leaf := loadLeaf(t, certFile, keyFile)
+	assert.NoError(t, leaf.VerifyHostname("nas.local"))
+	assert.NoError(t, leaf.VerifyHostname("192.168.1.10"))
+	assert.Contains(t, leaf.ExtKeyUsage, x509.ExtKeyUsageServerAuth)
+	assert.LessOrEqual(t, leaf.NotAfter.Sub(leaf.NotBefore), selfSignedValidity)
+
+	t.Run("复用有效的证书", func(t *testing.T) {
+		_, _, err := EnsureSelfSigned(dir, []string{"192.168.1.10"})
+		require.NoError(t, err)
+		assert.Equal(t, leaf.SerialNumber, loadLeaf(t, certFile, keyFile).SerialNumber, "主机已覆盖时不重新生成")
+	})
+
+	t.Run("缺少主机时重新生成", func(t *testing.T) {
+		_, _, err := EnsureSelfSigned(dir, []string{"nas.local", "192.168.2.20"})
+		require.NoError(t, err)
+		renewed := loadLeaf(t, certFile, keyFile)
+		assert.NotEqual(t, leaf.SerialNumber, renewed.SerialNumber)
+		assert.NoError(t, renewed.VerifyHostname("192.168.2.20"))
+		leaf = renewed
+	})
+
+	t.Run("即将过期时重新生成", func(t *testing.T) {
+		defer func() { now = time.Now }()
+		now = func() time.Time { return time.Now().Add(selfSignedValidity - renewBefore/2) }
+
+		_, _, err := EnsureSelfSigned(dir, []string{"nas.local"})
+		require.NoError(t, err)
+		assert.NotEqual(t, leaf.SerialNumber, loadLeaf(t, certFile, keyFile).SerialNumber)
+	})
+
+	t.Run("证书损坏时重新生成", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(certFile, []byte("broken"), 0o644))
+		_, _, err := EnsureSelfSigned(dir, hosts)
+		require.NoError(t, err)
+		loadLeaf(t, certFile, keyFile)
+	})
+
+	_, _, err = EnsureSelfSigned(dir, nil)
+	assert.Error(t, err)
+}
+
+// TestLoadConfig 测试加载证书创建TLS配置
+func TestLoadConfig(t *testing.T) {
+	certFile, keyFile, err := EnsureSelfSigned(t.TempDir(), []string{"localhost"})
+	require.NoError(t, err)
+
+	config, err := LoadConfig(certFile, keyFile)
+	require.NoError(t, err)
+	assert.Len(t, config.Certificates, 1)
+	assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+
+	_, err = LoadConfig(filepath.Join(t.TempDir(), "missing.crt"), keyFile)
+	assert.Error(t, err)
+}
+
+// TestDefaultHosts 测试自签名证书默认包含的主机
+func TestDefaultHosts(t *testing.T) {
+	hosts := DefaultHosts()
+	assert.Contains(t, hosts, "localhost")
+	assert.Contains(t, hosts, "127.0.0.1")
+	assert.NotContains(t, hosts, "")
+
+	assert.Equal(t, []string{"localhost", "nas.lan", "10.0.0.2"}, MergeHosts([]string{"localhost", " NAS.lan "}, []string{"nas.lan", "", "10.0.0.2"}))
+}
@@ -0,0 +1,718 @@
+package transcode
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 任务状态
+const (
+	StatusPending   = "pending"   // 等待执行
+	StatusRunning   = "running"   // 正在执行
+	StatusRetrying  = "retrying"  // 执行失败，等待自动重试
+	StatusSucceeded = "succeeded" // 执行成功
+	StatusCanceled  = "canceled"  // 已取消
+	StatusDead      = "dead"      // 连续失败达到最大执行次数，进入死信状态，需要手动重新排队
+)
+
+// 任务类型
+const (
+	KindHLS     = "hls"     // HLS打包（同时生成DASH清单）
+	KindPreview = "preview" // 进度条预览图和动态预览
+)
+
+// 优先级范围，数值越大越先执行，相同优先级按入队顺序执行
+const (
+	MinPriority     = -100
+	MaxPriority     = 100
+	DefaultPriority = 0
+)
+
+// 队列参数
+const (
+	defaultWorkers     = 1                // 并发执行的worker数量，转码占用大量CPU，默认串行执行
+	defaultMaxAttempts = 3                // 进入死信状态前的最大执行次数
+	defaultRetryDelay  = 30 * time.Second // 首次自动重试的等待时间，之后每次翻倍
+	maxFinishedJobs    = 500              // 保留的已结束任务数量，超出时删除最早结束的任务
+	minSweepInterval   = time.Second      // 检查租约过期的最短间隔
+)
+
+var (
+	// ErrJobNotFound 任务不存在
+	ErrJobNotFound = errors.New("转码任务不存在")
+	// ErrJobActive 视频已有未结束的任务
+	ErrJobActive = errors.New("视频已有未完成的转码任务")
+	// ErrInvalidState 任务当前状态不支持该操作
+	ErrInvalidState = errors.New("转码任务当前状态不支持该操作")
+	// ErrInvalidKind 不支持的任务类型
+	ErrInvalidKind = fmt.Errorf("任务类型必须为%s或%s", KindHLS, KindPreview)
+	// ErrLeaseLost worker不再持有任务：任务已被取消、租约已过期或由其他worker执行
+	ErrLeaseLost = errors.New("worker不再持有该转码任务")
+	// ErrLeaseExpired worker未在租约到期前上报心跳
+	ErrLeaseExpired = errors.New("worker租约已过期")
+	// ErrInvalidPriority 优先级超出范围
+	ErrInvalidPriority = fmt.Errorf("优先级必须在%d到%d之间", MinPriority, MaxPriority)
+)
+
+// Job 转码任务
+type Job struct {
+	ID             string
+	Kind           string
+	VideoID        string
+	Priority       int
+	Status         string
+	Attempts       int    // 累计执行次数
+	MaxAttempts    int    // 执行次数达到该值仍失败时进入死信状态，重新排队时增加
+	Error          string // 最近一次失败原因
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	StartedAt      time.Time // 最近一次开始执行的时间
+	FinishedAt     time.Time // 结束时间，未结束时为零值
+	NextAttemptAt  time.Time // 等待重试时的下次执行时间
+	Worker         string    // 领取任务的远程worker，由本进程执行时为空
+	LeaseExpiresAt time.Time // 远程worker的租约到期时间，到期前未上报心跳时任务按失败处理
+}
+
+// Finished 任务是否已结束
+func (j *Job) Finished() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusCanceled || j.Status == StatusDead
+}
+
+// Handler 执行转码任务，ctx在任务被取消或队列关闭时取消
+type Handler func(ctx context.Context, job Job) error
+
+// FinishHandler 任务结束（成功、取消或进入死信状态）后调用，在单独的goroutine中执行
+type FinishHandler func(job Job)
+
+// entry 队列中的任务及其调度状态
+type entry struct {
+	job    Job
+	seq    uint64             // 入队顺序
+	index  int                // 在待执行堆中的位置，不在堆中时为-1
+	cancel context.CancelFunc // 正在执行时取消任务
+	timer  *time.Timer        // 等待重试的定时器
+}
+
+// Queue 转码任务优先级队列，按优先级执行，失败时按指数退避自动重试
+// 任务由本进程的worker执行，或由远程worker通过Claim领取；每个视频的每种任务同时只有一个未结束的任务，任务只保存在内存中
+type Queue struct {
+	handler      Handler
+	onFinish     FinishHandler
+	maxAttempts  int
+	retryDelay   time.Duration
+	leaseTimeout time.Duration
+	entries      map[string]*entry // 任务ID -> 任务
+	active       map[string]*entry // 任务类型和视频ID -> 未结束的任务
+	pending      entryHeap
+	seq          uint64
+	mutex        sync.Mutex
+	wake         chan struct{}
+	ctx          context.Context // 关闭时取消，中断正在执行的任务
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+}
+
+// NewQueue 创建转码队列并启动worker，workers和maxAttempts不大于0时使用默认值
+func NewQueue(handler Handler, workers, maxAttempts int) *Queue {
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	q := newQueue(handler, maxAttempts)
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.run()
+	}
+	return q
+}
+
+// NewRemoteQueue 创建只由远程worker领取任务的转码队列，本进程不执行任务
+// 远程worker需要在leaseTimeout内上报心跳，否则任务按失败处理并自动重试
+func NewRemoteQueue(maxAttempts int, leaseTimeout time.Duration) *Queue {
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	q := newQueue(nil, maxAttempts)
+	q.leaseTimeout = leaseTimeout
+	q.wg.Add(1)
+	go q.sweepLeases(max(leaseTimeout/2, minSweepInterval))
+	return q
+}
+
+// newQueue 创建未启动worker的队列
+func newQueue(handler Handler, maxAttempts int) *Queue {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Queue{
+		handler:     handler,
+		maxAttempts: maxAttempts,
+		retryDelay:  defaultRetryDelay,
+		entries:     make(map[string]*entry),
+		active:      make(map[string]*entry),
+		wake:        make(chan struct{}, 1),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// SetRetryDelay 设置首次自动重试的等待时间
+func (q *Queue) SetRetryDelay(delay time.Duration) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if delay > 0 {
+		q.retryDelay = delay
+	}
+}
+
+// SetFinishHandler 设置任务结束后的回调，重新排队的任务再次结束时也会调用
+func (q *Queue) SetFinishHandler(handler FinishHandler) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.onFinish = handler
+}
+
+// Enqueue 为视频创建指定类型的任务
+// 视频已有未结束的同类任务时返回该任务和ErrJobActive，等待执行的任务优先级取两者中的较高值
+func (q *Queue) Enqueue(kind, videoID string, priority int) (Job, error) {
+	if videoID == "" {
+		return Job{}, fmt.Errorf("视频ID不能为空")
+	}
+	if kind != KindHLS && kind != KindPreview {
+		return Job{}, ErrInvalidKind
+	}
+	if err := validatePriority(priority); err != nil {
+		return Job{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if existing, ok := q.active[activeKey(kind, videoID)]; ok {
+		if priority > existing.job.Priority && existing.job.Status != StatusRunning {
+			q.setPriority(existing, priority)
+		}
+		return existing.job, ErrJobActive
+	}
+
+	now := time.Now()
+	e := &entry{
+		job: Job{
+			ID:          uuid.New().String(),
+			Kind:        kind,
+			VideoID:     videoID,
+			Priority:    priority,
+			Status:      StatusPending,
+			MaxAttempts: q.maxAttempts,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		},
+		index: -1,
+	}
+	q.entries[e.job.ID] = e
+	q.active[activeKey(kind, videoID)] = e
+	q.push(e)
+	return e.job, nil
+}
+
+// Get 获取任务
+func (q *Queue) Get(jobID string) (Job, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	e, ok := q.entries[jobID]
+	if !ok {
+		return Job{}, ErrJobNotFound
+	}
+	return e.job, nil
+}
+
+// ActiveJob 获取视频未结束的指定类型任务
+func (q *Queue) ActiveJob(kind, videoID string) (Job, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	e, ok := q.active[activeKey(kind, videoID)]
+	if !ok {
+		return Job{}, false
+	}
+	return e.job, true
+}
+
+// List 列出任务，videoID和status为空时不过滤
+// 未结束的任务在前，按执行顺序排列；已结束的任务按结束时间降序排列
+func (q *Queue) List(videoID, status string) []Job {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	entries := make([]*entry, 0, len(q.entries))
+	for _, e := range q.entries {
+		if (videoID == "" || e.job.VideoID == videoID) && (status == "" || e.job.Status == status) {
+			entries = append(entries, e)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.job.Finished() != b.job.Finished() {
+			return !a.job.Finished()
+		}
+		if a.job.Finished() {
+			return a.job.FinishedAt.After(b.job.FinishedAt)
+		}
+		if statusRank(a.job.Status) != statusRank(b.job.Status) {
+			return statusRank(a.job.Status) < statusRank(b.job.Status)
+		}
+		return runsBefore(a, b)
+	})
+
+	jobs := make([]Job, len(entries))
+	for i, e := range entries {
+		jobs[i] = e.job
+	}
+	return jobs
+}
+
+// SetPriority 修改等待执行或等待重试的任务的优先级
+func (q *Queue) SetPriority(jobID string, priority int) (Job, error) {
+	if err := validatePriority(priority); err != nil {
+		return Job{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	e, ok := q.entries[jobID]
+	if !ok {
+		return Job{}, ErrJobNotFound
+	}
+	if e.job.Status != StatusPending && e.job.Status != StatusRetrying {
+		return e.job, ErrInvalidState
+	}
+
+	q.setPriority(e, priority)
+	return e.job, nil
+}
+
+// Cancel 取消未结束的任务，正在执行的任务会被中断
+func (q *Queue) Cancel(jobID string) (Job, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	e, ok := q.entries[jobID]
+	if !ok {
+		return Job{}, ErrJobNotFound
+	}
+
+	switch e.job.Status {
+	case StatusPending:
+		heap.Remove(&q.pending, e.index)
+	case StatusRetrying:
+		e.timer.Stop()
+		e.timer = nil
+	case StatusRunning:
+		// worker在任务返回后才释放视频，期间不能为该视频创建新任务
+		e.cancel()
+	default:
+		return e.job, ErrInvalidState
+	}
+
+	now := time.Now()
+	e.job.Status = StatusCanceled
+	e.job.NextAttemptAt = time.Time{}
+	e.job.UpdatedAt = now
+	e.job.FinishedAt = now
+	if e.cancel == nil {
+		q.release(e)
+	}
+	return e.job, nil
+}
+
+// Requeue 将已取消或进入死信状态的任务重新排队，等待重试的任务立即执行
+// 重新排队后任务可以再执行maxAttempts次
+func (q *Queue) Requeue(jobID string) (Job, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	e, ok := q.entries[jobID]
+	if !ok {
+		return Job{}, ErrJobNotFound
+	}
+
+	switch e.job.Status {
+	case StatusRetrying:
+		e.timer.Stop()
+		e.timer = nil
+	case StatusCanceled, StatusDead:
+		if e.cancel != nil {
+			// 已取消但仍在停止中
+			return e.job, ErrInvalidState
+		}
+		key := activeKey(e.job.Kind, e.job.VideoID)
+		if _, ok := q.active[key]; ok {
+			return e.job, ErrJobActive
+		}
+		q.active[key] = e
+		e.job.MaxAttempts = e.job.Attempts + q.maxAttempts
+		e.job.FinishedAt = time.Time{}
+		q.seq++
+		e.seq = q.seq
+	default:
+		return e.job, ErrInvalidState
+	}
+
+	e.job.Status = StatusPending
+	e.job.NextAttemptAt = time.Time{}
+	e.job.UpdatedAt = time.Now()
+	q.push(e)
+	return e.job, nil
+}
+
+// Claim 远程worker领取优先级最高的任务，kinds为空时领取任意类型，没有待执行的任务时返回false
+func (q *Queue) Claim(workerID string, kinds []string) (Job, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var claimed *entry
+	for _, e := range q.pending {
+		if (len(kinds) == 0 || slices.Contains(kinds, e.job.Kind)) && (claimed == nil || runsBefore(e, claimed)) {
+			claimed = e
+		}
+	}
+	if claimed == nil || q.ctx.Err() != nil {
+		return Job{}, false
+	}
+
+	heap.Remove(&q.pending, claimed.index)
+	q.start(claimed)
+	claimed.job.Worker = workerID
+	claimed.job.LeaseExpiresAt = claimed.job.StartedAt.Add(q.leaseTimeout)
+	return claimed.job, true
+}
+
+// Heartbeat 远程worker续租正在执行的任务
+// 任务已被取消、租约已过期或由其他worker执行时返回ErrLeaseLost，worker应停止执行
+func (q *Queue) Heartbeat(jobID, workerID string) (Job, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	e, err := q.leased(jobID, workerID)
+	if err != nil {
+		return e.job, err
+	}
+	if e.job.Status != StatusRunning {
+		return e.job, ErrLeaseLost
+	}
+
+	e.job.LeaseExpiresAt = time.Now().Add(q.leaseTimeout)
+	return e.job, nil
+}
+
+// Complete 远程worker上报任务执行结果，jobErr为nil表示执行成功
+// 任务执行期间被取消时仍需上报，以便释放视频
+func (q *Queue) Complete(jobID, workerID string, jobErr error) (Job, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	e, err := q.leased(jobID, workerID)
+	if err != nil {
+		return e.job, err
+	}
+
+	q.finishLocked(e, jobErr)
+	return e.job, nil
+}
+
+// leased 获取workerID持有的任务，调用方需持有锁
+func (q *Queue) leased(jobID, workerID string) (*entry, error) {
+	e, ok := q.entries[jobID]
+	if !ok {
+		return &entry{}, ErrJobNotFound
+	}
+	if e.cancel == nil || e.job.Worker == "" || e.job.Worker != workerID {
+		return e, ErrLeaseLost
+	}
+	return e, nil
+}
+
+// Close 停止worker并中断正在执行的任务，等待中的任务不再执行
+func (q *Queue) Close() {
+	q.cancel()
+	q.wg.Wait()
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for _, e := range q.entries {
+		if e.timer != nil {
+			e.timer.Stop()
+		}
+	}
+}
+
+// run worker循环，取出优先级最高的任务执行
+func (q *Queue) run() {
+	defer q.wg.Done()
+
+	for {
+		e, ctx := q.next()
+		if e == nil {
+			select {
+			case <-q.ctx.Done():
+				return
+			case <-q.wake:
+			}
+			continue
+		}
+
+		q.mutex.Lock()
+		job := e.job
+		q.mutex.Unlock()
+
+		err := q.handler(ctx, job)
+		q.finish(e, err)
+	}
+}
+
+// next 取出下一个待执行的任务并标记为执行中
+func (q *Queue) next() (*entry, context.Context) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.ctx.Err() != nil || q.pending.Len() == 0 {
+		return nil, nil
+	}
+
+	e := heap.Pop(&q.pending).(*entry)
+	ctx := q.start(e)
+
+	// 还有待执行的任务时唤醒其他worker
+	if q.pending.Len() > 0 {
+		q.signal()
+	}
+	return e, ctx
+}
+
+// start 将任务标记为执行中，返回任务被取消时取消的ctx，调用方需持有锁
+func (q *Queue) start(e *entry) context.Context {
+	now := time.Now()
+	e.job.Status = StatusRunning
+	e.job.Attempts++
+	e.job.StartedAt = now
+	e.job.UpdatedAt = now
+	e.job.Worker = ""
+	e.job.LeaseExpiresAt = time.Time{}
+
+	ctx, cancel := context.WithCancel(q.ctx)
+	e.cancel = cancel
+	return ctx
+}
+
+// finish 记录任务执行结果，失败时安排重试或进入死信状态
+func (q *Queue) finish(e *entry, err error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.finishLocked(e, err)
+}
+
+// finishLocked 记录任务执行结果，调用方需持有锁
+func (q *Queue) finishLocked(e *entry, err error) {
+	e.cancel()
+	e.cancel = nil
+	now := time.Now()
+	e.job.UpdatedAt = now
+	e.job.LeaseExpiresAt = time.Time{}
+
+	switch {
+	case e.job.Status == StatusCanceled:
+		q.release(e)
+	case err == nil:
+		e.job.Status = StatusSucceeded
+		e.job.Error = ""
+		e.job.FinishedAt = now
+		q.release(e)
+	case e.job.Attempts >= e.job.MaxAttempts:
+		e.job.Status = StatusDead
+		e.job.Error = err.Error()
+		e.job.FinishedAt = now
+		q.release(e)
+	default:
+		e.job.Status = StatusRetrying
+		e.job.Error = err.Error()
+		delay := q.retryDelay << (e.job.Attempts - (e.job.MaxAttempts - q.maxAttempts) - 1)
+		e.job.NextAttemptAt = now.Add(delay)
+		e.timer = time.AfterFunc(delay, func() {
+			q.retry(e)
+		})
+	}
+}
+
+// sweepLeases 定期将租约过期的远程任务按失败处理
+func (q *Queue) sweepLeases(interval time.Duration) {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case now := <-ticker.C:
+			q.expireLeases(now)
+		}
+	}
+}
+
+// expireLeases 将租约在now之前过期的远程任务按失败处理，已取消的任务直接释放
+func (q *Queue) expireLeases(now time.Time) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, e := range q.entries {
+		if e.cancel != nil && e.job.Worker != "" && now.After(e.job.LeaseExpiresAt) {
+			q.finishLocked(e, fmt.Errorf("%w: %s", ErrLeaseExpired, e.job.Worker))
+		}
+	}
+}
+
+// retry 重试等待结束后将任务放回待执行队列
+func (q *Queue) retry(e *entry) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if e.job.Status != StatusRetrying || q.ctx.Err() != nil {
+		return
+	}
+	e.timer = nil
+	e.job.Status = StatusPending
+	e.job.NextAttemptAt = time.Time{}
+	e.job.UpdatedAt = time.Now()
+	q.push(e)
+}
+
+// push 将任务放入待执行堆并唤醒worker，调用方需持有锁
+func (q *Queue) push(e *entry) {
+	if e.seq == 0 {
+		q.seq++
+		e.seq = q.seq
+	}
+	heap.Push(&q.pending, e)
+	q.signal()
+}
+
+// setPriority 修改任务优先级并调整待执行堆，调用方需持有锁
+func (q *Queue) setPriority(e *entry, priority int) {
+	e.job.Priority = priority
+	e.job.UpdatedAt = time.Now()
+	if e.index >= 0 {
+		heap.Fix(&q.pending, e.index)
+	}
+}
+
+// release 任务结束后释放视频并清理过多的已结束任务，调用方需持有锁
+func (q *Queue) release(e *entry) {
+	key := activeKey(e.job.Kind, e.job.VideoID)
+	if q.active[key] == e {
+		delete(q.active, key)
+	}
+	if q.onFinish != nil {
+		go q.onFinish(e.job)
+	}
+
+	var finished []*entry
+	for _, other := range q.entries {
+		if other.job.Finished() && other.cancel == nil {
+			finished = append(finished, other)
+		}
+	}
+	if len(finished) <= maxFinishedJobs {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].job.FinishedAt.Before(finished[j].job.FinishedAt)
+	})
+	for _, old := range finished[:len(finished)-maxFinishedJobs] {
+		delete(q.entries, old.job.ID)
+	}
+}
+
+// signal 唤醒一个等待中的worker
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// activeKey 未结束任务索引的键
+func activeKey(kind, videoID string) string {
+	return kind + "/" + videoID
+}
+
+// validatePriority 验证优先级范围
+func validatePriority(priority int) error {
+	if priority < MinPriority || priority > MaxPriority {
+		return ErrInvalidPriority
+	}
+	return nil
+}
+
+// statusRank 未结束任务的排列顺序
+func statusRank(status string) int {
+	switch status {
+	case StatusRunning:
+		return 0
+	case StatusPending:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// runsBefore 任务a是否先于任务b执行
+func runsBefore(a, b *entry) bool {
+	if a.job.Priority != b.job.Priority {
+		return a.job.Priority > b.job.Priority
+	}
+	return a.seq < b.seq
+}
+
+// entryHeap 按优先级排列的待执行任务堆
+type entryHeap []*entry
+
+func (h entryHeap) Len() int           { return len(h) }
+func (h entryHeap) Less(i, j int) bool { return runsBefore(h[i], h[j]) }
+
+func (h entryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *entryHeap) Push(x any) {
+	e := x.(*entry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *entryHeap) Pop() any {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.index = -1
+	*h = old[:n-1]
+	return e
+}
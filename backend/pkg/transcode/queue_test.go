@@ -0,0 +1,465 @@
+package transcode
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitForStatus 等待任务进入指定状态
+func waitForStatus(t *testing.T, q *Queue, jobID, status string) Job {
+	t.Helper()
+
+	var job Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = q.Get(jobID)
+		return err == nil && job.Status == status
+	}, 2*time.Second, 5*time.Millisecond, "任务应该进入%s状态", status)
+	return job
+}
+
+// blockingHandler 执行时阻塞直到收到放行信号，记录执行顺序
+type blockingHandler struct {
+	mutex   sync.Mutex
+	order   []string
+	started chan string
+	release chan error
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{
+		started: make(chan string, 16),
+		release: make(chan error),
+	}
+}
+
+func (h *blockingHandler) handle(ctx context.Context, job Job) error {
+	h.mutex.Lock()
+	h.order = append(h.order, job.VideoID)
+	h.mutex.Unlock()
+
+	h.started <- job.VideoID
+	select {
+	case err := <-h.release:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *blockingHandler) executed() []string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return append([]string(nil), h.order...)
+}
+
+func TestQueue_Priority(t *testing.T) {
+	handler := newBlockingHandler()
+	q := NewQueue(handler.handle, 1, 3)
+	defer q.Close()
+
+	first, err := q.Enqueue(KindHLS, "video-1", DefaultPriority)
+	require.NoError(t, err)
+	assert.Equal(t, "video-1", <-handler.started, "第一个任务应该立即开始")
+	waitForStatus(t, q, first.ID, StatusRunning)
+
+	_, err = q.Enqueue(KindHLS, "low", -10)
+	require.NoError(t, err)
+	_, err = q.Enqueue(KindHLS, "normal-1", DefaultPriority)
+	require.NoError(t, err)
+	_, err = q.Enqueue(KindHLS, "normal-2", DefaultPriority)
+	require.NoError(t, err)
+	high, err := q.Enqueue(KindHLS, "high", 50)
+	require.NoError(t, err)
+
+	listed := q.List("", "")
+	require.Len(t, listed, 5)
+	assert.Equal(t, first.ID, listed[0].ID, "正在执行的任务应该排在最前")
+	assert.Equal(t, high.ID, listed[1].ID, "待执行的任务应该按执行顺序排列")
+
+	for i := 0; i < 5; i++ {
+		if i > 0 {
+			<-handler.started
+		}
+		handler.release <- nil
+	}
+	waitForStatus(t, q, high.ID, StatusSucceeded)
+
+	assert.Equal(t, []string{"video-1", "high", "normal-1", "normal-2", "low"}, handler.executed(),
+		"应该按优先级执行，相同优先级先入先出")
+	assert.Len(t, q.List("", StatusSucceeded), 5)
+}
+
+func TestQueue_EnqueueActiveVideo(t *testing.T) {
+	handler := newBlockingHandler()
+	q := NewQueue(handler.handle, 1, 3)
+	defer q.Close()
+
+	_, err := q.Enqueue(KindHLS, "running", DefaultPriority)
+	require.NoError(t, err)
+	<-handler.started
+
+	pending, err := q.Enqueue(KindHLS, "video-1", DefaultPriority)
+	require.NoError(t, err)
+
+	existing, err := q.Enqueue(KindHLS, "video-1", 20)
+	assert.ErrorIs(t, err, ErrJobActive, "同一视频不能有两个未结束的任务")
+	assert.Equal(t, pending.ID, existing.ID)
+	assert.Equal(t, 20, existing.Priority, "重复入队时应该提升等待中任务的优先级")
+
+	existing, err = q.Enqueue(KindHLS, "video-1", -5)
+	assert.ErrorIs(t, err, ErrJobActive)
+	assert.Equal(t, 20, existing.Priority, "不应该降低优先级")
+
+	active, ok := q.ActiveJob(KindHLS, "video-1")
+	assert.True(t, ok)
+	assert.Equal(t, pending.ID, active.ID)
+
+	_, err = q.Enqueue(KindHLS, "", DefaultPriority)
+	assert.Error(t, err)
+	_, err = q.Enqueue(KindHLS, "video-2", MaxPriority+1)
+	assert.ErrorIs(t, err, ErrInvalidPriority)
+}
+
+func TestQueue_SetPriority(t *testing.T) {
+	handler := newBlockingHandler()
+	q := NewQueue(handler.handle, 1, 3)
+	defer q.Close()
+
+	running, err := q.Enqueue(KindHLS, "running", DefaultPriority)
+	require.NoError(t, err)
+	<-handler.started
+
+	first, err := q.Enqueue(KindHLS, "first", DefaultPriority)
+	require.NoError(t, err)
+	second, err := q.Enqueue(KindHLS, "second", DefaultPriority)
+	require.NoError(t, err)
+
+	updated, err := q.SetPriority(second.ID, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 10, updated.Priority)
+
+	_, err = q.SetPriority(running.ID, 10)
+	assert.ErrorIs(t, err, ErrInvalidState, "正在执行的任务不能修改优先级")
+	_, err = q.SetPriority("missing", 10)
+	assert.ErrorIs(t, err, ErrJobNotFound)
+	_, err = q.SetPriority(first.ID, MinPriority-1)
+	assert.ErrorIs(t, err, ErrInvalidPriority)
+
+	handler.release <- nil
+	assert.Equal(t, "second", <-handler.started, "提升优先级后应该先执行")
+	handler.release <- nil
+	assert.Equal(t, "first", <-handler.started)
+	handler.release <- nil
+}
+
+func TestQueue_Cancel(t *testing.T) {
+	handler := newBlockingHandler()
+	q := NewQueue(handler.handle, 1, 3)
+	defer q.Close()
+
+	running, err := q.Enqueue(KindHLS, "running", DefaultPriority)
+	require.NoError(t, err)
+	<-handler.started
+	pending, err := q.Enqueue(KindHLS, "pending", DefaultPriority)
+	require.NoError(t, err)
+
+	canceled, err := q.Cancel(pending.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCanceled, canceled.Status)
+	assert.False(t, canceled.FinishedAt.IsZero())
+	_, ok := q.ActiveJob(KindHLS, "pending")
+	assert.False(t, ok, "取消等待中的任务后应该释放视频")
+
+	canceled, err = q.Cancel(running.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCanceled, canceled.Status)
+
+	_, err = q.Enqueue(KindHLS, "running", DefaultPriority)
+	assert.ErrorIs(t, err, ErrJobActive, "正在停止的任务仍然占用视频")
+
+	require.Eventually(t, func() bool {
+		_, ok := q.ActiveJob(KindHLS, "running")
+		return !ok
+	}, 2*time.Second, 5*time.Millisecond, "任务中断后应该释放视频")
+	job := waitForStatus(t, q, running.ID, StatusCanceled)
+	assert.Equal(t, 1, job.Attempts)
+
+	_, err = q.Cancel(running.ID)
+	assert.ErrorIs(t, err, ErrInvalidState, "已结束的任务不能取消")
+	_, err = q.Cancel("missing")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+	assert.Equal(t, []string{"running"}, handler.executed(), "已取消的任务不应该执行")
+}
+
+func TestQueue_RetryAndDeadLetter(t *testing.T) {
+	var mutex sync.Mutex
+	attempts := 0
+	q := NewQueue(func(ctx context.Context, job Job) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		attempts++
+		return errors.New("FFmpeg执行失败")
+	}, 1, 2)
+	defer q.Close()
+	q.SetRetryDelay(10 * time.Millisecond)
+
+	created, err := q.Enqueue(KindHLS, "video-1", DefaultPriority)
+	require.NoError(t, err)
+	assert.Equal(t, 2, created.MaxAttempts)
+
+	job := waitForStatus(t, q, created.ID, StatusDead)
+	assert.Equal(t, 2, job.Attempts, "应该自动重试到最大执行次数")
+	assert.Equal(t, "FFmpeg执行失败", job.Error)
+	assert.False(t, job.FinishedAt.IsZero())
+	assert.Len(t, q.List("", StatusDead), 1)
+	_, ok := q.ActiveJob(KindHLS, "video-1")
+	assert.False(t, ok, "进入死信状态后应该释放视频")
+
+	requeued, err := q.Requeue(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, requeued.MaxAttempts, "重新排队后应该可以再执行最大次数")
+
+	job = waitForStatus(t, q, created.ID, StatusDead)
+	assert.Equal(t, 4, job.Attempts, "执行次数应该累计")
+	mutex.Lock()
+	assert.Equal(t, 4, attempts)
+	mutex.Unlock()
+}
+
+func TestQueue_RequeueAfterFailure(t *testing.T) {
+	var mutex sync.Mutex
+	fail := true
+	q := NewQueue(func(ctx context.Context, job Job) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if fail {
+			return errors.New("存储不可用")
+		}
+		return nil
+	}, 1, 3)
+	defer q.Close()
+	q.SetRetryDelay(time.Hour)
+
+	created, err := q.Enqueue(KindHLS, "video-1", DefaultPriority)
+	require.NoError(t, err)
+	job := waitForStatus(t, q, created.ID, StatusRetrying)
+	assert.Equal(t, 1, job.Attempts)
+	assert.True(t, job.NextAttemptAt.After(time.Now()), "应该记录下次重试时间")
+
+	_, err = q.Requeue("missing")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+
+	mutex.Lock()
+	fail = false
+	mutex.Unlock()
+
+	_, err = q.Requeue(created.ID)
+	require.NoError(t, err, "等待重试的任务应该可以立即重新执行")
+	job = waitForStatus(t, q, created.ID, StatusSucceeded)
+	assert.Equal(t, 2, job.Attempts)
+	assert.Empty(t, job.Error, "成功后应该清除错误")
+
+	_, err = q.Requeue(created.ID)
+	assert.ErrorIs(t, err, ErrInvalidState, "成功的任务不能重新排队")
+}
+
+func TestQueue_RequeueCanceledWithActiveVideo(t *testing.T) {
+	handler := newBlockingHandler()
+	q := NewQueue(handler.handle, 1, 3)
+	defer q.Close()
+
+	_, err := q.Enqueue(KindHLS, "running", DefaultPriority)
+	require.NoError(t, err)
+	<-handler.started
+
+	first, err := q.Enqueue(KindHLS, "video-1", DefaultPriority)
+	require.NoError(t, err)
+	_, err = q.Cancel(first.ID)
+	require.NoError(t, err)
+	second, err := q.Enqueue(KindHLS, "video-1", DefaultPriority)
+	require.NoError(t, err)
+
+	_, err = q.Requeue(first.ID)
+	assert.ErrorIs(t, err, ErrJobActive, "视频已有未结束的任务时不能重新排队")
+
+	_, err = q.Cancel(second.ID)
+	require.NoError(t, err)
+	requeued, err := q.Requeue(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, requeued.Status)
+	assert.True(t, requeued.FinishedAt.IsZero())
+
+	listed := q.List("video-1", "")
+	require.Len(t, listed, 2)
+	assert.Equal(t, first.ID, listed[0].ID, "未结束的任务应该排在前面")
+}
+
+func TestQueue_EnqueueKinds(t *testing.T) {
+	q := NewRemoteQueue(3, time.Minute)
+	defer q.Close()
+
+	hls, err := q.Enqueue(KindHLS, "video-1", DefaultPriority)
+	require.NoError(t, err)
+	preview, err := q.Enqueue(KindPreview, "video-1", DefaultPriority)
+	require.NoError(t, err, "同一视频的不同类型任务可以同时存在")
+	assert.NotEqual(t, hls.ID, preview.ID)
+
+	active, ok := q.ActiveJob(KindPreview, "video-1")
+	require.True(t, ok)
+	assert.Equal(t, preview.ID, active.ID)
+
+	_, err = q.Enqueue("thumbnail", "video-1", DefaultPriority)
+	assert.ErrorIs(t, err, ErrInvalidKind)
+}
+
+func TestQueue_RemoteClaim(t *testing.T) {
+	q := NewRemoteQueue(3, time.Minute)
+	defer q.Close()
+
+	_, ok := q.Claim("worker-1", nil)
+	assert.False(t, ok, "没有待执行的任务时不应该领取到任务")
+
+	low, err := q.Enqueue(KindHLS, "low", -10)
+	require.NoError(t, err)
+	preview, err := q.Enqueue(KindPreview, "preview", DefaultPriority)
+	require.NoError(t, err)
+	high, err := q.Enqueue(KindHLS, "high", 10)
+	require.NoError(t, err)
+
+	claimed, ok := q.Claim("worker-1", []string{KindHLS})
+	require.True(t, ok)
+	assert.Equal(t, high.ID, claimed.ID, "应该领取指定类型中优先级最高的任务")
+	assert.Equal(t, StatusRunning, claimed.Status)
+	assert.Equal(t, "worker-1", claimed.Worker)
+	assert.Equal(t, 1, claimed.Attempts)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), claimed.LeaseExpiresAt, time.Second)
+
+	claimed, ok = q.Claim("worker-2", nil)
+	require.True(t, ok)
+	assert.Equal(t, preview.ID, claimed.ID)
+
+	_, err = q.Heartbeat(high.ID, "worker-2")
+	assert.ErrorIs(t, err, ErrLeaseLost, "不能续租其他worker的任务")
+	_, err = q.Heartbeat("missing", "worker-1")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+	_, err = q.Heartbeat(high.ID, "worker-1")
+	require.NoError(t, err)
+
+	job, err := q.Complete(high.ID, "worker-1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, StatusSucceeded, job.Status)
+	_, err = q.Complete(high.ID, "worker-1", nil)
+	assert.ErrorIs(t, err, ErrLeaseLost, "不能重复上报结果")
+
+	job, err = q.Complete(preview.ID, "worker-2", errors.New("FFmpeg执行失败"))
+	require.NoError(t, err)
+	assert.Equal(t, StatusRetrying, job.Status, "失败后应该等待重试")
+	assert.Equal(t, "FFmpeg执行失败", job.Error)
+
+	claimed, ok = q.Claim("worker-1", nil)
+	require.True(t, ok)
+	assert.Equal(t, low.ID, claimed.ID)
+}
+
+func TestQueue_RemoteCancelAndLeaseExpiry(t *testing.T) {
+	q := NewRemoteQueue(1, time.Minute)
+	defer q.Close()
+
+	first, err := q.Enqueue(KindHLS, "video-1", DefaultPriority)
+	require.NoError(t, err)
+	_, ok := q.Claim("worker-1", nil)
+	require.True(t, ok)
+
+	_, err = q.Cancel(first.ID)
+	require.NoError(t, err)
+	job, err := q.Heartbeat(first.ID, "worker-1")
+	assert.ErrorIs(t, err, ErrLeaseLost, "任务被取消后worker应该停止执行")
+	assert.Equal(t, StatusCanceled, job.Status)
+	_, ok = q.ActiveJob(KindHLS, "video-1")
+	assert.True(t, ok, "worker上报结果前仍然占用视频")
+
+	job, err = q.Complete(first.ID, "worker-1", context.Canceled)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCanceled, job.Status)
+	_, ok = q.ActiveJob(KindHLS, "video-1")
+	assert.False(t, ok, "上报结果后应该释放视频")
+
+	second, err := q.Enqueue(KindHLS, "video-2", DefaultPriority)
+	require.NoError(t, err)
+	claimed, ok := q.Claim("worker-1", nil)
+	require.True(t, ok)
+
+	q.expireLeases(claimed.LeaseExpiresAt.Add(time.Second))
+	job, err = q.Get(second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDead, job.Status, "租约过期应该按失败处理")
+	assert.Contains(t, job.Error, ErrLeaseExpired.Error())
+	_, err = q.Complete(second.ID, "worker-1", nil)
+	assert.ErrorIs(t, err, ErrLeaseLost, "租约过期后不能再上报结果")
+}
+
+func TestQueue_FinishHandler(t *testing.T) {
+	handler := newBlockingHandler()
+	q := NewQueue(handler.handle, 1, 1)
+	defer q.Close()
+
+	finished := make(chan Job, 4)
+	q.SetFinishHandler(func(job Job) {
+		finished <- job
+	})
+
+	waitFinished := func() Job {
+		t.Helper()
+		select {
+		case job := <-finished:
+			return job
+		case <-time.After(2 * time.Second):
+			t.Fatal("任务结束后应该调用回调")
+			return Job{}
+		}
+	}
+
+	succeeded, err := q.Enqueue(KindHLS, "video-1", DefaultPriority)
+	require.NoError(t, err)
+	<-handler.started
+	handler.release <- nil
+	job := waitFinished()
+	assert.Equal(t, succeeded.ID, job.ID)
+	assert.Equal(t, StatusSucceeded, job.Status)
+
+	failed, err := q.Enqueue(KindHLS, "video-2", DefaultPriority)
+	require.NoError(t, err)
+	<-handler.started
+	handler.release <- errors.New("FFmpeg执行失败")
+	job = waitFinished()
+	assert.Equal(t, failed.ID, job.ID)
+	assert.Equal(t, StatusDead, job.Status)
+
+	// 重新排队后再次结束时同样调用
+	_, err = q.Requeue(failed.ID)
+	require.NoError(t, err)
+	<-handler.started
+	handler.release <- nil
+	assert.Equal(t, StatusSucceeded, waitFinished().Status)
+
+	blocker, err := q.Enqueue(KindHLS, "video-3", DefaultPriority)
+	require.NoError(t, err)
+	<-handler.started
+	pending, err := q.Enqueue(KindPreview, "video-3", DefaultPriority)
+	require.NoError(t, err)
+	_, err = q.Cancel(pending.ID)
+	require.NoError(t, err)
+	job = waitFinished()
+	assert.Equal(t, pending.ID, job.ID)
+	assert.Equal(t, StatusCanceled, job.Status)
+
+	handler.release <- nil
+	assert.Equal(t, blocker.ID, waitFinished().ID)
+}
@@ -0,0 +1,67 @@
+package upload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// ChecksumLength SHA-256校验和的十六进制长度
+const ChecksumLength = sha256.Size * 2
+
+// ErrChecksumMismatch 上传内容的校验和与客户端提供的不一致
+var ErrChecksumMismatch = errors.New("文件校验和不匹配")
+
+// NormalizeChecksum 校验并规范化客户端提供的SHA-256校验和（小写十六进制），为空表示不校验
+func NormalizeChecksum(checksum string) (string, error) {
+	checksum = strings.ToLower(strings.TrimSpace(checksum))
+	if checksum == "" {
+		return "", nil
+	}
+	if len(checksum) != ChecksumLength {
+		return "", fmt.Errorf("校验和必须为%d位十六进制SHA-256值", ChecksumLength)
+	}
+	if _, err := hex.DecodeString(checksum); err != nil {
+		return "", fmt.Errorf("校验和必须为%d位十六进制SHA-256值", ChecksumLength)
+	}
+	return checksum, nil
+}
+
+// checksumHex 返回哈希的十六进制结果
+func checksumHex(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// verifyChecksum 比较实际校验和与期望值，期望值为空时不校验
+func verifyChecksum(expected, actual string) error {
+	if expected == "" || strings.EqualFold(expected, actual) {
+		return nil
+	}
+	return fmt.Errorf("%w: 期望 %s, 实际 %s", ErrChecksumMismatch, strings.ToLower(expected), actual)
+}
+
+// ComputeChecksum 流式读取存储中的对象并计算SHA-256校验和
+// 用于分片上传和客户端直传等服务端未经手数据流的场景
+func (s *UploadService) ComputeChecksum(ctx context.Context, bucketName, objectName string) (string, error) {
+	reader, err := s.storage.OpenFile(ctx, bucketName, objectName)
+	if err != nil {
+		return "", fmt.Errorf("读取文件失败: %w", err)
+	}
+	defer reader.Close()
+
+	return ChecksumReader(reader)
+}
+
+// ChecksumReader 读取全部数据并计算SHA-256校验和
+func ChecksumReader(reader io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, reader); err != nil {
+		return "", fmt.Errorf("计算校验和失败: %w", err)
+	}
+	return checksumHex(h), nil
+}
@@ -0,0 +1,166 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+const checksumTestBucket = "checksum-bucket"
+
+// setupChecksumTestService 创建基于本地存储的上传服务
+func setupChecksumTestService(t *testing.T) (*UploadService, storage.StorageInterface) {
+	localStorage, err := storage.NewLocalStorage(&storage.LocalConfig{RootDir: t.TempDir()})
+	require.NoError(t, err)
+	require.NoError(t, localStorage.CreateBucket(context.Background(), checksumTestBucket))
+	return NewUploadService(localStorage), localStorage
+}
+
+// sha256Hex 计算测试数据的SHA-256校验和
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// TestNormalizeChecksum 测试校验和格式校验
+func TestNormalizeChecksum(t *testing.T) {
+	valid := sha256Hex([]byte("test"))
+
+	checksum, err := NormalizeChecksum("  " + strings.ToUpper(valid) + " ")
+	require.NoError(t, err)
+	assert.Equal(t, valid, checksum, "校验和应该转换为小写")
+
+	checksum, err = NormalizeChecksum("")
+	require.NoError(t, err)
+	assert.Empty(t, checksum, "未提供校验和时不校验")
+
+	_, err = NormalizeChecksum(valid[:32])
+	assert.Error(t, err, "长度不正确时应该返回错误")
+
+	_, err = NormalizeChecksum(strings.Repeat("g", ChecksumLength))
+	assert.Error(t, err, "非十六进制字符应该返回错误")
+}
+
+// TestUploadService_UploadFileChecksum 测试单文件上传时计算和校验校验和
+func TestUploadService_UploadFileChecksum(t *testing.T) {
+	service, storageService := setupChecksumTestService(t)
+	ctx := context.Background()
+	data := []byte("checksum test video content")
+
+	newRequest := func(checksum string) *UploadRequest {
+		return &UploadRequest{
+			FileName:    "test.mp4",
+			ContentType: "video/mp4",
+			Size:        int64(len(data)),
+			Reader:      bytes.NewReader(data),
+			BucketName:  checksumTestBucket,
+			Checksum:    checksum,
+		}
+	}
+
+	t.Run("返回文件内容的校验和", func(t *testing.T) {
+		result, err := service.UploadFile(ctx, newRequest(""))
+		require.NoError(t, err)
+		assert.Equal(t, sha256Hex(data), result.Checksum)
+	})
+
+	t.Run("校验和一致时上传成功", func(t *testing.T) {
+		result, err := service.UploadFile(ctx, newRequest(strings.ToUpper(sha256Hex(data))))
+		require.NoError(t, err)
+		assert.Equal(t, sha256Hex(data), result.Checksum)
+	})
+
+	t.Run("校验和不一致时删除已上传的文件", func(t *testing.T) {
+		req := newRequest(sha256Hex([]byte("other")))
+		req.ObjectName = "videos/mismatch.mp4"
+
+		_, err := service.UploadFile(ctx, req)
+		assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+		exists, err := storageService.FileExists(ctx, checksumTestBucket, req.ObjectName)
+		require.NoError(t, err)
+		assert.False(t, exists, "校验失败的文件应该被删除")
+	})
+
+	t.Run("校验和格式不正确", func(t *testing.T) {
+		_, err := service.UploadFile(ctx, newRequest("abc"))
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrChecksumMismatch)
+	})
+}
+
+// TestObjectOptions 测试构造对象的用户元数据和标签
+func TestObjectOptions(t *testing.T) {
+	opts := objectOptions(nil, nil, sha256Hex([]byte("test")))
+	assert.Nil(t, opts.Metadata, "未要求附加元数据时不添加校验和")
+	assert.Nil(t, opts.Tags)
+
+	labels := map[string]string{storage.ObjectKeyVideoID: "video-1"}
+	opts = objectOptions(labels, labels, "abc")
+	assert.Equal(t, "abc", opts.Metadata[storage.ObjectKeyContentHash])
+	assert.Equal(t, "abc", opts.Tags[storage.ObjectKeyContentHash])
+	assert.NotContains(t, labels, storage.ObjectKeyContentHash, "不应该修改调用方传入的map")
+
+	opts = objectOptions(labels, labels, "")
+	assert.NotContains(t, opts.Metadata, storage.ObjectKeyContentHash, "未知校验和时不添加content-hash")
+}
+
+// TestUploadService_CompleteMultipartChecksum 测试完成分片上传时校验整个文件的校验和
+func TestUploadService_CompleteMultipartChecksum(t *testing.T) {
+	service, storageService := setupChecksumTestService(t)
+	ctx := context.Background()
+	data := []byte("multipart checksum test content")
+
+	upload := func(t *testing.T, checksum string) (*UploadResult, string, error) {
+		session, err := service.InitMultipartUpload(ctx, &MultipartUploadRequest{
+			FileName:    "test.mp4",
+			ContentType: "video/mp4",
+			TotalSize:   int64(len(data)),
+			BucketName:  checksumTestBucket,
+			ChunkSize:   int64(len(data)),
+		})
+		require.NoError(t, err)
+
+		part, err := service.UploadPart(ctx, &UploadPartRequest{
+			UploadID:   session.UploadID,
+			ObjectName: session.ObjectName,
+			PartNumber: 1,
+			Reader:     bytes.NewReader(data),
+			Size:       int64(len(data)),
+			BucketName: checksumTestBucket,
+		})
+		require.NoError(t, err)
+
+		result, err := service.CompleteMultipartUpload(ctx, &CompleteMultipartRequest{
+			UploadID:   session.UploadID,
+			ObjectName: session.ObjectName,
+			Parts:      []CompletedPart{{PartNumber: 1, ETag: part.ETag}},
+			BucketName: checksumTestBucket,
+			Checksum:   checksum,
+		})
+		return result, session.ObjectName, err
+	}
+
+	t.Run("校验和一致时完成上传", func(t *testing.T) {
+		result, _, err := upload(t, sha256Hex(data))
+		require.NoError(t, err)
+		assert.Equal(t, sha256Hex(data), result.Checksum)
+	})
+
+	t.Run("校验和不一致时删除合并后的文件", func(t *testing.T) {
+		_, objectName, err := upload(t, sha256Hex([]byte("other")))
+		assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+		exists, err := storageService.FileExists(ctx, checksumTestBucket, objectName)
+		require.NoError(t, err)
+		assert.False(t, exists, "校验失败的文件应该被删除")
+	})
+}
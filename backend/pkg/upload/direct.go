@@ -0,0 +1,142 @@
+package upload
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 直传会话相关错误
+var (
+	ErrUploadTokenInvalid = errors.New("上传令牌无效")
+	ErrUploadTokenExpired = errors.New("上传令牌已过期")
+)
+
+// DirectUploadSession 客户端直传会话
+// 客户端使用预签名PUT URL直接上传到存储，完成后凭上传令牌确认
+type DirectUploadSession struct {
+	Token       string    // 上传令牌
+	VideoID     string    // 视频ID
+	BucketName  string    // 存储桶名
+	ObjectName  string    // 对象名
+	FileName    string    // 原始文件名
+	ContentType string    // 内容类型
+	Size        int64     // 声明的文件大小
+	Title       string    // 视频标题
+	Description string    // 视频描述
+	Visibility  string    // 视频可见性
+	CreatedBy   string    // 发起上传的用户
+	ExpiresAt   time.Time // 过期时间
+}
+
+// DirectUploadManager 直传会话管理器
+type DirectUploadManager struct {
+	// 使用内存存储作为简单实现，服务重启后未确认的会话将失效
+	sessions map[string]*DirectUploadSession
+	expiry   time.Duration
+	mutex    sync.Mutex
+}
+
+// NewDirectUploadManager 创建直传会话管理器
+func NewDirectUploadManager(expiry time.Duration) *DirectUploadManager {
+	return &DirectUploadManager{
+		sessions: make(map[string]*DirectUploadSession),
+		expiry:   expiry,
+	}
+}
+
+// GetExpiry 获取会话有效期
+func (m *DirectUploadManager) GetExpiry() time.Duration {
+	return m.expiry
+}
+
+// CreateSession 创建直传会话，生成上传令牌和过期时间
+func (m *DirectUploadManager) CreateSession(session *DirectUploadSession) (*DirectUploadSession, error) {
+	if session == nil {
+		return nil, fmt.Errorf("直传会话不能为空")
+	}
+	if session.VideoID == "" || session.BucketName == "" || session.ObjectName == "" {
+		return nil, fmt.Errorf("视频ID、存储桶名和对象名不能为空")
+	}
+
+	token, err := generateUploadToken()
+	if err != nil {
+		return nil, fmt.Errorf("生成上传令牌失败: %w", err)
+	}
+
+	created := *session
+	created.Token = token
+	created.ExpiresAt = time.Now().Add(m.expiry)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.removeExpiredLocked()
+	m.sessions[token] = &created
+
+	result := created
+	return &result, nil
+}
+
+// GetSession 根据上传令牌获取直传会话
+func (m *DirectUploadManager) GetSession(token string) (*DirectUploadSession, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	session, exists := m.sessions[token]
+	if !exists {
+		return nil, ErrUploadTokenInvalid
+	}
+	if time.Now().After(session.ExpiresAt) {
+		delete(m.sessions, token)
+		return nil, ErrUploadTokenExpired
+	}
+
+	result := *session
+	return &result, nil
+}
+
+// FindByVideoID 根据视频ID获取未过期的直传会话
+func (m *DirectUploadManager) FindByVideoID(videoID string) (*DirectUploadSession, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	for _, session := range m.sessions {
+		if session.VideoID == videoID && !now.After(session.ExpiresAt) {
+			result := *session
+			return &result, true
+		}
+	}
+	return nil, false
+}
+
+// RemoveSession 移除直传会话
+func (m *DirectUploadManager) RemoveSession(token string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.sessions, token)
+}
+
+// removeExpiredLocked 清理过期会话，调用方需持有锁
+func (m *DirectUploadManager) removeExpiredLocked() {
+	now := time.Now()
+	for token, session := range m.sessions {
+		if now.After(session.ExpiresAt) {
+			delete(m.sessions, token)
+		}
+	}
+}
+
+// generateUploadToken 生成随机上传令牌
+func generateUploadToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,74 @@
+package upload
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDirectSession 创建测试直传会话
+func newTestDirectSession() *DirectUploadSession {
+	return &DirectUploadSession{
+		VideoID:     "video-1",
+		BucketName:  "test-bucket",
+		ObjectName:  "videos/2025/08/video-1.mp4",
+		FileName:    "video-1.mp4",
+		ContentType: "video/mp4",
+		Size:        1024,
+	}
+}
+
+// TestDirectUploadManager_Session 测试直传会话的创建、获取和移除
+func TestDirectUploadManager_Session(t *testing.T) {
+	manager := NewDirectUploadManager(time.Minute)
+
+	session, err := manager.CreateSession(newTestDirectSession())
+	require.NoError(t, err, "创建直传会话应该成功")
+	assert.Len(t, session.Token, 64, "上传令牌应该为64位十六进制字符串")
+	assert.WithinDuration(t, time.Now().Add(time.Minute), session.ExpiresAt, time.Second, "过期时间应该正确")
+
+	another, err := manager.CreateSession(newTestDirectSession())
+	require.NoError(t, err)
+	assert.NotEqual(t, session.Token, another.Token, "每次生成的令牌应该不同")
+
+	found, err := manager.GetSession(session.Token)
+	require.NoError(t, err, "获取直传会话应该成功")
+	assert.Equal(t, "videos/2025/08/video-1.mp4", found.ObjectName)
+
+	found, ok := manager.FindByVideoID("video-1")
+	require.True(t, ok, "应该可以按视频ID查找会话")
+	assert.Equal(t, "video-1", found.VideoID)
+	_, ok = manager.FindByVideoID("video-2")
+	assert.False(t, ok)
+
+	manager.RemoveSession(session.Token)
+	_, err = manager.GetSession(session.Token)
+	assert.ErrorIs(t, err, ErrUploadTokenInvalid, "移除后令牌应该失效")
+}
+
+// TestDirectUploadManager_Expired 测试过期会话
+func TestDirectUploadManager_Expired(t *testing.T) {
+	manager := NewDirectUploadManager(-time.Second)
+
+	session, err := manager.CreateSession(newTestDirectSession())
+	require.NoError(t, err)
+
+	_, err = manager.GetSession(session.Token)
+	assert.ErrorIs(t, err, ErrUploadTokenExpired, "过期令牌应该返回过期错误")
+
+	_, err = manager.GetSession(session.Token)
+	assert.ErrorIs(t, err, ErrUploadTokenInvalid, "过期会话应该被移除")
+}
+
+// TestDirectUploadManager_Validation 测试会话参数验证
+func TestDirectUploadManager_Validation(t *testing.T) {
+	manager := NewDirectUploadManager(time.Minute)
+
+	_, err := manager.CreateSession(nil)
+	assert.Error(t, err, "空会话应该返回错误")
+
+	_, err = manager.CreateSession(&DirectUploadSession{VideoID: "video-1"})
+	assert.Error(t, err, "缺少对象信息时应该返回错误")
+}
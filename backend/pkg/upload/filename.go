@@ -0,0 +1,86 @@
+package upload
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxSlugLength 对象名中文件名部分的最大长度（字节）
+	MaxSlugLength = 64
+	// MaxExtensionLength 对象名中扩展名的最大长度（不含点）
+	MaxExtensionLength = 10
+	// objectNameAttempts 生成的对象名已存在时重新生成的最大次数
+	objectNameAttempts = 3
+)
+
+// BaseFileName 去掉客户端文件名中的目录部分，同时识别"/"和"\"分隔符
+func BaseFileName(fileName string) string {
+	if index := strings.LastIndexAny(fileName, `/\`); index >= 0 {
+		fileName = fileName[index+1:]
+	}
+	return fileName
+}
+
+// ObjectExtension 获取用于对象名的扩展名：小写，只保留ASCII字母和数字，包含其他字符或过长时返回空
+// 原始文件名（包括扩展名）只保存在元数据中
+func ObjectExtension(fileName string) string {
+	base := BaseFileName(fileName)
+	index := strings.LastIndexByte(base, '.')
+	if index <= 0 || index == len(base)-1 {
+		return ""
+	}
+
+	ext := strings.ToLower(base[index+1:])
+	if len(ext) > MaxExtensionLength {
+		return ""
+	}
+	for i := 0; i < len(ext); i++ {
+		if !isSlugByte(ext[i]) {
+			return ""
+		}
+	}
+	return "." + ext
+}
+
+// Slugify 将文件名（不含扩展名）转换为只包含小写ASCII字母、数字和"-"的片段，最长maxLength字节
+// 空格、标点、路径分隔符和非ASCII字符（中日韩文字、emoji等）合并为单个"-"，无法保留任何字符时返回空
+func Slugify(fileName string, maxLength int) string {
+	base := BaseFileName(fileName)
+	if ext := ObjectExtension(base); ext != "" {
+		base = base[:len(base)-len(ext)]
+	}
+
+	var builder strings.Builder
+	separator := false
+	for _, r := range base {
+		if r < utf8.RuneSelf && isSlugByte(toLowerASCII(byte(r))) {
+			if separator && builder.Len() > 0 {
+				builder.WriteByte('-')
+			}
+			separator = false
+			builder.WriteByte(toLowerASCII(byte(r)))
+			continue
+		}
+		separator = true
+	}
+
+	slug := builder.String()
+	if maxLength > 0 && len(slug) > maxLength {
+		slug = strings.TrimRight(slug[:maxLength], "-")
+	}
+	return slug
+}
+
+// isSlugByte 字符是否为小写ASCII字母或数字
+func isSlugByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
+
+// toLowerASCII 将ASCII大写字母转换为小写
+func toLowerASCII(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
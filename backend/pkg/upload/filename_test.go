@@ -0,0 +1,123 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// TestSlugify 测试文件名转换为对象名片段
+func TestSlugify(t *testing.T) {
+	testCases := []struct {
+		fileName string
+		expected string
+	}{
+		{"test.mp4", "test"},
+		{"My Holiday Video (1).MOV", "my-holiday-video-1"},
+		{"  --leading and trailing--  .mp4", "leading-and-trailing"},
+		{"../../etc/passwd", "passwd"},
+		{`C:\Users\alice\clip.mp4`, "clip"},
+		{"会议记录 2025-08.mp4", "2025-08"},
+		{"假期视频.mp4", ""},
+		{"🎬🎬.mp4", ""},
+		{"café.mp4", "caf"},
+		{"archive.tar.gz", "archive-tar"},
+		{".hidden", "hidden"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.fileName, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Slugify(tc.fileName, MaxSlugLength))
+		})
+	}
+
+	long := Slugify(strings.Repeat("ab ", 40)+".mp4", 10)
+	assert.Equal(t, "ab-ab-ab-a", long, "超过长度限制时应该截断")
+	assert.Equal(t, "ab-ab", Slugify("ab-ab-ab.mp4", 6), "截断后不应该以-结尾")
+	assert.Len(t, Slugify(strings.Repeat("x", 200), 0), 200, "长度限制为0时不截断")
+}
+
+// TestObjectExtension 测试对象名扩展名
+func TestObjectExtension(t *testing.T) {
+	testCases := []struct {
+		fileName string
+		expected string
+	}{
+		{"test.mp4", ".mp4"},
+		{"TEST.MOV", ".mov"},
+		{"archive.tar.gz", ".gz"},
+		{"noext", ""},
+		{"trailing.", ""},
+		{".hidden", ""},
+		{"clip.m p4", ""},
+		{"clip.视频", ""},
+		{"clip.abcdefghijk", ""},
+		{`dir.v2\clip`, ""},
+		{"dir.v2/clip.webm", ".webm"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.fileName, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ObjectExtension(tc.fileName))
+		})
+	}
+}
+
+// collidingStorage 测试用存储，前几次检查对象时报告对象已存在
+type collidingStorage struct {
+	storage.StorageInterface
+	collisions int
+	checked    []string
+}
+
+// FileExists 前collisions次检查返回已存在
+func (s *collidingStorage) FileExists(ctx context.Context, bucketName, objectName string) (bool, error) {
+	s.checked = append(s.checked, objectName)
+	return len(s.checked) <= s.collisions, nil
+}
+
+// TestUploadService_NewObjectName 测试生成的对象名已存在时重新生成
+func TestUploadService_NewObjectName(t *testing.T) {
+	ctx := context.Background()
+
+	store := &collidingStorage{collisions: 1}
+	service := NewUploadService(store)
+	objectName, err := service.newObjectName(ctx, "videos", "clip.mp4")
+	require.NoError(t, err)
+	require.Len(t, store.checked, 2, "已存在时应该重新生成")
+	assert.NotEqual(t, store.checked[0], objectName)
+	assert.Equal(t, store.checked[1], objectName)
+
+	store = &collidingStorage{collisions: objectNameAttempts}
+	_, err = NewUploadService(store).newObjectName(ctx, "videos", "clip.mp4")
+	assert.Error(t, err, "多次生成的对象名均已存在时应该返回错误")
+	assert.Len(t, store.checked, objectNameAttempts)
+}
+
+// TestUploadService_UploadFileSanitizedName 测试未指定对象名时上传使用转换后的文件名
+func TestUploadService_UploadFileSanitizedName(t *testing.T) {
+	service, storageService := setupChecksumTestService(t)
+	ctx := context.Background()
+	data := []byte("sanitized name video content")
+
+	result, err := service.UploadFile(ctx, &UploadRequest{
+		FileName:    "../旅行 Vlog #1.MP4",
+		ContentType: "video/mp4",
+		Size:        int64(len(data)),
+		Reader:      bytes.NewReader(data),
+		BucketName:  checksumTestBucket,
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(result.ObjectName, "-vlog-1.mp4"), result.ObjectName)
+	assert.NotContains(t, result.ObjectName, "..")
+
+	exists, err := storageService.FileExists(ctx, checksumTestBucket, result.ObjectName)
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
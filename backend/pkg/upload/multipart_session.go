@@ -0,0 +1,193 @@
+package upload
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultMultipartSessionTTL 分片上传会话的默认有效期，超过有效期没有上传分片的会话视为已放弃
+const DefaultMultipartSessionTTL = 24 * time.Hour
+
+// 分片上传会话相关错误
+var (
+	ErrMultipartSessionNotFound = errors.New("分片上传不存在")
+	ErrMultipartSessionExpired  = errors.New("分片上传已过期")
+)
+
+// MultipartSession 分片上传会话
+// 记录存储服务端的上传ID和目标对象，客户端只需凭上传ID上传分片、完成或中止
+type MultipartSession struct {
+	UploadID    string    // 存储服务端的上传ID
+	VideoID     string    // 视频ID
+	BucketName  string    // 存储桶名
+	ObjectName  string    // 对象名
+	FileName    string    // 原始文件名
+	ContentType string    // 内容类型
+	TotalSize   int64     // 声明的文件总大小
+	ChunkSize   int64     // 分片大小
+	Title       string    // 视频标题
+	Description string    // 视频描述
+	Visibility  string    // 视频可见性
+	CreatedBy   string    // 发起上传的用户
+	CreatedAt   time.Time // 创建时间
+	UpdatedAt   time.Time // 最近一次上传分片的时间
+	ExpiresAt   time.Time // 过期时间，每次上传分片后顺延
+}
+
+// TotalParts 按分片大小计算的分片总数
+func (s *MultipartSession) TotalParts() int {
+	if s.ChunkSize <= 0 {
+		return 0
+	}
+	return int((s.TotalSize + s.ChunkSize - 1) / s.ChunkSize)
+}
+
+// MultipartSessionManager 分片上传会话管理器
+type MultipartSessionManager struct {
+	// 使用内存存储作为简单实现，服务重启后会话丢失，存储中未完成的分片需要由存储桶生命周期规则清理
+	sessions map[string]*MultipartSession
+	ttl      time.Duration
+	mutex    sync.Mutex
+}
+
+// NewMultipartSessionManager 创建分片上传会话管理器，ttl不大于0时使用默认有效期
+func NewMultipartSessionManager(ttl time.Duration) *MultipartSessionManager {
+	if ttl <= 0 {
+		ttl = DefaultMultipartSessionTTL
+	}
+	return &MultipartSessionManager{
+		sessions: make(map[string]*MultipartSession),
+		ttl:      ttl,
+	}
+}
+
+// GetTTL 获取会话有效期
+func (m *MultipartSessionManager) GetTTL() time.Duration {
+	return m.ttl
+}
+
+// CreateSession 保存新创建的分片上传会话，设置创建时间和过期时间
+func (m *MultipartSessionManager) CreateSession(session *MultipartSession) (*MultipartSession, error) {
+	if session == nil {
+		return nil, fmt.Errorf("分片上传会话不能为空")
+	}
+	if session.UploadID == "" || session.BucketName == "" || session.ObjectName == "" {
+		return nil, fmt.Errorf("上传ID、存储桶名和对象名不能为空")
+	}
+
+	now := time.Now()
+	created := *session
+	created.CreatedAt = now
+	created.UpdatedAt = now
+	created.ExpiresAt = now.Add(m.ttl)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.sessions[created.UploadID]; exists {
+		return nil, fmt.Errorf("分片上传已存在: %s", created.UploadID)
+	}
+	m.sessions[created.UploadID] = &created
+
+	result := created
+	return &result, nil
+}
+
+// GetSession 根据上传ID获取分片上传会话，已过期的会话返回ErrMultipartSessionExpired，由清理任务中止
+func (m *MultipartSessionManager) GetSession(uploadID string) (*MultipartSession, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	session, exists := m.sessions[uploadID]
+	if !exists {
+		return nil, ErrMultipartSessionNotFound
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, ErrMultipartSessionExpired
+	}
+
+	result := *session
+	return &result, nil
+}
+
+// Touch 记录分片上传活动并顺延过期时间
+func (m *MultipartSessionManager) Touch(uploadID string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if session, exists := m.sessions[uploadID]; exists {
+		now := time.Now()
+		session.UpdatedAt = now
+		session.ExpiresAt = now.Add(m.ttl)
+	}
+}
+
+// FindByVideoID 根据视频ID获取未过期的分片上传会话
+func (m *MultipartSessionManager) FindByVideoID(videoID string) (*MultipartSession, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	for _, session := range m.sessions {
+		if session.VideoID == videoID && !now.After(session.ExpiresAt) {
+			result := *session
+			return &result, true
+		}
+	}
+	return nil, false
+}
+
+// RemoveSession 移除分片上传会话，上传完成或中止后调用
+func (m *MultipartSessionManager) RemoveSession(uploadID string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.sessions, uploadID)
+}
+
+// ListSessions 列出用户进行中的分片上传，userID为空时列出全部，按创建时间升序
+func (m *MultipartSessionManager) ListSessions(userID string) []*MultipartSession {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	sessions := make([]*MultipartSession, 0)
+	for _, session := range m.sessions {
+		if now.After(session.ExpiresAt) || (userID != "" && session.CreatedBy != userID) {
+			continue
+		}
+		result := *session
+		sessions = append(sessions, &result)
+	}
+	sortSessions(sessions)
+	return sessions
+}
+
+// ExpiredSessions 列出已过期的分片上传会话，按创建时间升序
+func (m *MultipartSessionManager) ExpiredSessions(now time.Time) []*MultipartSession {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	sessions := make([]*MultipartSession, 0)
+	for _, session := range m.sessions {
+		if now.After(session.ExpiresAt) {
+			result := *session
+			sessions = append(sessions, &result)
+		}
+	}
+	sortSessions(sessions)
+	return sessions
+}
+
+// sortSessions 按创建时间升序排列会话，创建时间相同时按上传ID排序
+func sortSessions(sessions []*MultipartSession) {
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].CreatedAt.Equal(sessions[j].CreatedAt) {
+			return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+		}
+		return sessions[i].UploadID < sessions[j].UploadID
+	})
+}
@@ -0,0 +1,116 @@
+package upload
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestMultipartSession 创建测试用分片上传会话
+func newTestMultipartSession(uploadID, userID string) *MultipartSession {
+	return &MultipartSession{
+		UploadID:   uploadID,
+		VideoID:    "video-" + uploadID,
+		BucketName: "videos",
+		ObjectName: "videos/" + uploadID + ".mp4",
+		TotalSize:  11 * 1024 * 1024,
+		ChunkSize:  MinPartSize,
+		CreatedBy:  userID,
+	}
+}
+
+// TestMultipartSession_TotalParts 测试分片总数计算
+func TestMultipartSession_TotalParts(t *testing.T) {
+	session := newTestMultipartSession("upload-1", "user-1")
+	assert.Equal(t, 3, session.TotalParts())
+
+	session.TotalSize = 2 * MinPartSize
+	assert.Equal(t, 2, session.TotalParts(), "整除时不应该多出一个分片")
+
+	session.ChunkSize = 0
+	assert.Equal(t, 0, session.TotalParts())
+}
+
+// TestMultipartSessionManager 测试分片上传会话的保存、查询和移除
+func TestMultipartSessionManager(t *testing.T) {
+	manager := NewMultipartSessionManager(time.Hour)
+	assert.Equal(t, time.Hour, manager.GetTTL())
+	assert.Equal(t, DefaultMultipartSessionTTL, NewMultipartSessionManager(0).GetTTL())
+
+	created, err := manager.CreateSession(newTestMultipartSession("upload-1", "user-1"))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), created.ExpiresAt, time.Second)
+
+	_, err = manager.CreateSession(newTestMultipartSession("upload-1", "user-1"))
+	assert.Error(t, err, "上传ID重复时应该返回错误")
+	_, err = manager.CreateSession(&MultipartSession{UploadID: "upload-2"})
+	assert.Error(t, err, "缺少目标对象时应该返回错误")
+
+	session, err := manager.GetSession("upload-1")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", session.CreatedBy)
+
+	// 返回的是副本，修改不影响管理器中的会话
+	session.CreatedBy = "user-2"
+	session, err = manager.GetSession("upload-1")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", session.CreatedBy)
+
+	manager.RemoveSession("upload-1")
+	_, err = manager.GetSession("upload-1")
+	assert.ErrorIs(t, err, ErrMultipartSessionNotFound)
+}
+
+// TestMultipartSessionManager_ListSessions 测试按用户列出进行中的分片上传
+func TestMultipartSessionManager_ListSessions(t *testing.T) {
+	manager := NewMultipartSessionManager(time.Hour)
+	for _, session := range []*MultipartSession{
+		newTestMultipartSession("upload-1", "user-1"),
+		newTestMultipartSession("upload-2", "user-2"),
+		newTestMultipartSession("upload-3", "user-1"),
+	} {
+		_, err := manager.CreateSession(session)
+		require.NoError(t, err)
+	}
+
+	sessions := manager.ListSessions("user-1")
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "upload-1", sessions[0].UploadID)
+	assert.Equal(t, "upload-3", sessions[1].UploadID)
+
+	assert.Len(t, manager.ListSessions(""), 3, "用户为空时列出全部")
+	assert.Empty(t, manager.ListSessions("user-3"))
+}
+
+// TestMultipartSessionManager_Expiry 测试会话过期和上传分片后顺延
+func TestMultipartSessionManager_Expiry(t *testing.T) {
+	manager := NewMultipartSessionManager(50 * time.Millisecond)
+	_, err := manager.CreateSession(newTestMultipartSession("idle", "user-1"))
+	require.NoError(t, err)
+	_, err = manager.CreateSession(newTestMultipartSession("active", "user-1"))
+	require.NoError(t, err)
+
+	time.Sleep(30 * time.Millisecond)
+	manager.Touch("active")
+	time.Sleep(30 * time.Millisecond)
+
+	_, err = manager.GetSession("idle")
+	assert.ErrorIs(t, err, ErrMultipartSessionExpired)
+	_, err = manager.GetSession("active")
+	assert.NoError(t, err, "上传分片后应该顺延过期时间")
+	_, ok := manager.FindByVideoID("video-idle")
+	assert.False(t, ok, "不应该按视频ID找到已过期的会话")
+	found, ok := manager.FindByVideoID("video-active")
+	require.True(t, ok)
+	assert.Equal(t, "active", found.UploadID)
+
+	expired := manager.ExpiredSessions(time.Now())
+	require.Len(t, expired, 1)
+	assert.Equal(t, "idle", expired[0].UploadID)
+
+	sessions := manager.ListSessions("user-1")
+	require.Len(t, sessions, 1, "不应该列出已过期的会话")
+	assert.Equal(t, "active", sessions[0].UploadID)
+}
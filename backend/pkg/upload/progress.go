@@ -0,0 +1,291 @@
+package upload
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const (
+	// progressRetention 上传结束后保留进度的时长，便于客户端晚订阅时仍能拿到最终状态
+	progressRetention = 5 * time.Minute
+	// progressStaleTimeout 长时间没有更新的进度视为已失效
+	progressStaleTimeout = time.Hour
+	// progressPendingTimeout 订阅后迟迟未开始的上传视为已失效
+	progressPendingTimeout = 10 * time.Minute
+	// subscriberBufferSize 订阅者通道缓冲大小
+	subscriberBufferSize = 16
+)
+
+// 上传进度状态
+const (
+	ProgressPending   = "pending"   // 已订阅，等待上传开始
+	ProgressUploading = "uploading" // 上传中
+	ProgressCompleted = "completed" // 上传完成
+	ProgressFailed    = "failed"    // 上传失败
+)
+
+// uploadIDPattern 上传ID格式：字母、数字及._=-，兼容存储服务端生成的分片上传ID
+var uploadIDPattern = regexp.MustCompile(`^[A-Za-z0-9._=-]{1,256}$`)
+
+// ProgressRegistry 上传进度注册表，按上传ID保存进度并推送给订阅者
+type ProgressRegistry struct {
+	entries map[string]*progressEntry
+	mutex   sync.Mutex
+}
+
+// progressEntry 单个上传的进度及其订阅者
+type progressEntry struct {
+	progress    UploadProgress
+	subscribers map[chan *UploadProgress]struct{}
+}
+
+// ProgressSubscription 上传进度订阅
+type ProgressSubscription struct {
+	C        <-chan *UploadProgress // 进度通道，上传结束后关闭
+	registry *ProgressRegistry
+	uploadID string
+	ch       chan *UploadProgress
+}
+
+// NewProgressRegistry 创建上传进度注册表
+func NewProgressRegistry() *ProgressRegistry {
+	return &ProgressRegistry{
+		entries: make(map[string]*progressEntry),
+	}
+}
+
+// ValidateUploadID 验证上传ID格式
+func ValidateUploadID(uploadID string) error {
+	if !uploadIDPattern.MatchString(uploadID) {
+		return fmt.Errorf("上传ID只能包含字母、数字和._=-，长度1-256")
+	}
+	return nil
+}
+
+// Start 开始跟踪上传进度，已存在的同名进度会被重置
+func (r *ProgressRegistry) Start(uploadID string, totalBytes int64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.removeExpiredLocked()
+
+	entry, exists := r.entries[uploadID]
+	if !exists {
+		entry = &progressEntry{subscribers: make(map[chan *UploadProgress]struct{})}
+		r.entries[uploadID] = entry
+	}
+	entry.progress = UploadProgress{
+		UploadID:   uploadID,
+		TotalBytes: totalBytes,
+		Status:     ProgressUploading,
+		UpdatedAt:  time.Now(),
+	}
+	r.notifyLocked(entry)
+}
+
+// Add 增加已上传字节数，百分比变化时推送给订阅者
+func (r *ProgressRegistry) Add(uploadID string, n int64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, exists := r.entries[uploadID]
+	if !exists || entry.progress.Status != ProgressUploading {
+		return
+	}
+
+	previous := entry.progress.Percentage
+	entry.progress.BytesUploaded += n
+	entry.progress.Percentage = calculatePercentage(entry.progress.BytesUploaded, entry.progress.TotalBytes)
+	entry.progress.UpdatedAt = time.Now()
+
+	// 未完成前最多推送到99%，100%留给Complete
+	if entry.progress.Percentage >= 100 {
+		entry.progress.Percentage = 99
+	}
+	if entry.progress.Percentage != previous {
+		r.notifyLocked(entry)
+	}
+}
+
+// Complete 标记上传完成
+func (r *ProgressRegistry) Complete(uploadID string) {
+	r.finish(uploadID, "")
+}
+
+// Fail 标记上传失败
+func (r *ProgressRegistry) Fail(uploadID string, reason string) {
+	r.finish(uploadID, reason)
+}
+
+// Get 获取上传进度
+func (r *ProgressRegistry) Get(uploadID string) (*UploadProgress, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, exists := r.entries[uploadID]
+	if !exists {
+		return nil, false
+	}
+	progress := entry.progress
+	return &progress, true
+}
+
+// Subscribe 订阅上传进度，订阅后立即推送当前进度
+// 上传尚未开始时创建等待状态的进度，客户端可以先订阅再上传；上传已结束时推送最终状态后关闭通道
+func (r *ProgressRegistry) Subscribe(uploadID string) *ProgressSubscription {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.removeExpiredLocked()
+
+	entry, exists := r.entries[uploadID]
+	if !exists {
+		entry = &progressEntry{
+			progress: UploadProgress{
+				UploadID:  uploadID,
+				Status:    ProgressPending,
+				UpdatedAt: time.Now(),
+			},
+			subscribers: make(map[chan *UploadProgress]struct{}),
+		}
+		r.entries[uploadID] = entry
+	}
+
+	ch := make(chan *UploadProgress, subscriberBufferSize)
+	progress := entry.progress
+	ch <- &progress
+
+	if entry.progress.IsCompleted {
+		close(ch)
+	} else {
+		entry.subscribers[ch] = struct{}{}
+	}
+
+	return &ProgressSubscription{
+		C:        ch,
+		registry: r,
+		uploadID: uploadID,
+		ch:       ch,
+	}
+}
+
+// Close 取消订阅
+func (s *ProgressSubscription) Close() {
+	s.registry.mutex.Lock()
+	defer s.registry.mutex.Unlock()
+
+	entry, exists := s.registry.entries[s.uploadID]
+	if !exists {
+		return
+	}
+	if _, subscribed := entry.subscribers[s.ch]; subscribed {
+		delete(entry.subscribers, s.ch)
+		close(s.ch)
+	}
+}
+
+// finish 结束上传并关闭所有订阅
+func (r *ProgressRegistry) finish(uploadID string, reason string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, exists := r.entries[uploadID]
+	if !exists || entry.progress.IsCompleted {
+		return
+	}
+
+	entry.progress.IsCompleted = true
+	entry.progress.Error = reason
+	if reason == "" {
+		entry.progress.Status = ProgressCompleted
+		entry.progress.Percentage = 100
+		entry.progress.BytesUploaded = entry.progress.TotalBytes
+	} else {
+		entry.progress.Status = ProgressFailed
+	}
+	entry.progress.UpdatedAt = time.Now()
+
+	r.notifyLocked(entry)
+	for ch := range entry.subscribers {
+		close(ch)
+	}
+	entry.subscribers = make(map[chan *UploadProgress]struct{})
+}
+
+// notifyLocked 向订阅者推送当前进度，调用方需持有锁
+// 订阅者处理过慢时丢弃最旧的一条，保证最新状态能够送达
+func (r *ProgressRegistry) notifyLocked(entry *progressEntry) {
+	for ch := range entry.subscribers {
+		progress := entry.progress
+		select {
+		case ch <- &progress:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- &progress
+		}
+	}
+}
+
+// removeExpiredLocked 清理已结束或失效的进度，调用方需持有锁
+func (r *ProgressRegistry) removeExpiredLocked() {
+	now := time.Now()
+	for uploadID, entry := range r.entries {
+		age := now.Sub(entry.progress.UpdatedAt)
+		expired := age > progressStaleTimeout
+		switch entry.progress.Status {
+		case ProgressPending:
+			expired = age > progressPendingTimeout
+		case ProgressCompleted, ProgressFailed:
+			expired = age > progressRetention
+		}
+		if expired {
+			for ch := range entry.subscribers {
+				close(ch)
+			}
+			delete(r.entries, uploadID)
+		}
+	}
+}
+
+// calculatePercentage 计算完成百分比
+func calculatePercentage(uploaded, total int64) int {
+	if total <= 0 {
+		return 0
+	}
+	percentage := int(uploaded * 100 / total)
+	if percentage > 100 {
+		return 100
+	}
+	return percentage
+}
+
+// progressReader 统计读取字节数并更新上传进度的读取器
+type progressReader struct {
+	reader   io.Reader
+	registry *ProgressRegistry
+	uploadID string
+}
+
+// NewProgressReader 创建更新上传进度的读取器
+func NewProgressReader(reader io.Reader, registry *ProgressRegistry, uploadID string) io.Reader {
+	return &progressReader{
+		reader:   reader,
+		registry: registry,
+		uploadID: uploadID,
+	}
+}
+
+// Read 读取数据并累计进度
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.registry.Add(r.uploadID, int64(n))
+	}
+	return n, err
+}
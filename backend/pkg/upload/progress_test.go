@@ -0,0 +1,151 @@
+package upload
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiveProgress 从订阅通道读取一条进度
+func receiveProgress(t *testing.T, sub *ProgressSubscription) *UploadProgress {
+	select {
+	case progress, ok := <-sub.C:
+		require.True(t, ok, "订阅通道不应该已关闭")
+		return progress
+	case <-time.After(time.Second):
+		t.Fatal("等待进度超时")
+		return nil
+	}
+}
+
+// TestProgressRegistry_Subscribe 测试进度订阅和推送
+func TestProgressRegistry_Subscribe(t *testing.T) {
+	registry := NewProgressRegistry()
+
+	// 先订阅再开始上传
+	sub := registry.Subscribe("upload-1")
+	defer sub.Close()
+
+	pending := receiveProgress(t, sub)
+	assert.Equal(t, ProgressPending, pending.Status, "上传开始前应该处于等待状态")
+
+	registry.Add("upload-1", 100)
+	registry.Start("upload-1", 1000)
+	initial := receiveProgress(t, sub)
+	assert.Equal(t, ProgressUploading, initial.Status)
+	assert.Equal(t, int64(0), initial.BytesUploaded, "开始前的进度更新应该被忽略")
+	assert.Equal(t, int64(1000), initial.TotalBytes)
+
+	registry.Add("upload-1", 250)
+	progress := receiveProgress(t, sub)
+	assert.Equal(t, 25, progress.Percentage)
+	assert.Equal(t, int64(250), progress.BytesUploaded)
+
+	// 百分比未变化时不推送
+	registry.Add("upload-1", 1)
+	registry.Add("upload-1", 749)
+	progress = receiveProgress(t, sub)
+	assert.Equal(t, 99, progress.Percentage, "完成前进度最多为99%")
+	assert.False(t, progress.IsCompleted)
+
+	registry.Complete("upload-1")
+	progress = receiveProgress(t, sub)
+	assert.Equal(t, 100, progress.Percentage)
+	assert.True(t, progress.IsCompleted)
+	assert.Equal(t, ProgressCompleted, progress.Status)
+	assert.Empty(t, progress.Error)
+
+	_, open := <-sub.C
+	assert.False(t, open, "上传结束后订阅通道应该关闭")
+}
+
+// TestProgressRegistry_SubscribeAfterFinish 测试上传结束后订阅
+func TestProgressRegistry_SubscribeAfterFinish(t *testing.T) {
+	registry := NewProgressRegistry()
+	registry.Start("upload-2", 100)
+	registry.Fail("upload-2", "存储写入失败")
+
+	sub := registry.Subscribe("upload-2")
+	defer sub.Close()
+
+	progress := receiveProgress(t, sub)
+	assert.True(t, progress.IsCompleted, "结束后的进度应该在保留期内可订阅")
+	assert.Equal(t, ProgressFailed, progress.Status)
+	assert.Equal(t, "存储写入失败", progress.Error, "应该返回失败原因")
+
+	_, open := <-sub.C
+	assert.False(t, open, "已结束的上传推送最终状态后应该关闭通道")
+}
+
+// TestProgressRegistry_Close 测试取消订阅
+func TestProgressRegistry_Close(t *testing.T) {
+	registry := NewProgressRegistry()
+	registry.Start("upload-3", 100)
+
+	sub := registry.Subscribe("upload-3")
+	sub.Close()
+	sub.Close()
+
+	// 取消订阅后推送不应该阻塞或panic
+	registry.Add("upload-3", 50)
+	registry.Complete("upload-3")
+
+	progress, ok := registry.Get("upload-3")
+	require.True(t, ok)
+	assert.True(t, progress.IsCompleted)
+}
+
+// TestProgressRegistry_SlowSubscriber 测试订阅者处理过慢时仍能收到最终状态
+func TestProgressRegistry_SlowSubscriber(t *testing.T) {
+	registry := NewProgressRegistry()
+	registry.Start("upload-4", 100)
+
+	sub := registry.Subscribe("upload-4")
+	defer sub.Close()
+
+	for i := 0; i < 99; i++ {
+		registry.Add("upload-4", 1)
+	}
+	registry.Complete("upload-4")
+
+	var last *UploadProgress
+	for progress := range sub.C {
+		last = progress
+	}
+	require.NotNil(t, last)
+	assert.True(t, last.IsCompleted, "最后一条应该是完成状态")
+	assert.Equal(t, 100, last.Percentage)
+}
+
+// TestNewProgressReader 测试进度读取器
+func TestNewProgressReader(t *testing.T) {
+	registry := NewProgressRegistry()
+	registry.Start("upload-5", 4096)
+
+	reader := NewProgressReader(bytes.NewReader(make([]byte, 4096)), registry, "upload-5")
+	n, err := io.Copy(io.Discard, reader)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4096), n)
+
+	progress, ok := registry.Get("upload-5")
+	require.True(t, ok)
+	assert.Equal(t, int64(4096), progress.BytesUploaded, "应该累计读取的字节数")
+	assert.False(t, progress.IsCompleted, "读取完成不代表上传完成")
+}
+
+// TestValidateUploadID 测试上传ID验证
+func TestValidateUploadID(t *testing.T) {
+	assert.NoError(t, ValidateUploadID("upload_1-ABC"))
+	assert.NoError(t, ValidateUploadID("0f8fad5b-d9cb-469f-a165-70867728950e"))
+	assert.NoError(t, ValidateUploadID("ZjQ5NzQ2MzgtZTAxNi00.MTk4ZDg="), "应该兼容分片上传ID")
+
+	invalid := []string{"", "a/b", "../etc", "带中文", strings.Repeat("a", 257)}
+	for _, id := range invalid {
+		assert.Error(t, ValidateUploadID(id), "无效上传ID应该验证失败: %q", id)
+	}
+}
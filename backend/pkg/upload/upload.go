@@ -2,8 +2,13 @@ package upload
 
 import (
 	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,19 +16,39 @@ import (
 	"github.com/manteia/zhulong/pkg/storage"
 )
 
+// MinPartSize 分片上传的最小分片大小（最后一个分片除外），由S3协议规定
+const MinPartSize = 5 * 1024 * 1024
+
+// 完成分片上传时的校验错误
+var (
+	ErrPartMissing  = errors.New("分片缺失")
+	ErrPartMismatch = errors.New("分片ETag不匹配")
+	ErrSizeMismatch = errors.New("文件大小不匹配")
+)
+
+// ErrPartIncomplete 分片数据少于声明的大小，通常是客户端中断了请求
+var ErrPartIncomplete = errors.New("分片数据不完整")
+
 // UploadService 文件上传服务
 type UploadService struct {
 	storage     storage.StorageInterface
-	maxFileSize int64 // 最大文件大小限制（字节）
+	maxFileSize int64             // 最大文件大小限制（字节）
+	progress    *ProgressRegistry // 上传进度注册表（可选）
 }
 
 // UploadRequest 单文件上传请求
 type UploadRequest struct {
 	FileName    string    // 文件名
+	ObjectName  string    // 对象名（可选，为空时根据文件名生成）
 	ContentType string    // 内容类型
 	Size        int64     // 文件大小
 	Reader      io.Reader // 文件读取器
 	BucketName  string    // 存储桶名
+	Checksum    string    // 客户端提供的SHA-256校验和（可选，不一致时删除已上传的文件）
+	// Metadata和Tags为对象的用户元数据和标签（可选），非空时自动添加content-hash；
+	// 用户元数据上传后不能修改，只有上传前已知校验和时才包含content-hash
+	Metadata map[string]string
+	Tags     map[string]string
 }
 
 // UploadResult 上传结果
@@ -32,16 +57,21 @@ type UploadResult struct {
 	ObjectName string    // 对象名（存储路径）
 	Size       int64     // 文件大小
 	ETag       string    // 文件ETag
+	Checksum   string    // 文件内容的SHA-256校验和（十六进制）
 	UploadedAt time.Time // 上传时间
 }
 
 // MultipartUploadRequest 分片上传请求
 type MultipartUploadRequest struct {
 	FileName    string // 文件名
+	ObjectName  string // 对象名（可选，为空时根据文件名生成）
 	ContentType string // 内容类型
 	TotalSize   int64  // 总文件大小
 	BucketName  string // 存储桶名
 	ChunkSize   int64  // 分片大小
+	// 对象的用户元数据和标签（可选）
+	Metadata map[string]string
+	Tags     map[string]string
 }
 
 // MultipartUploadSession 分片上传会话
@@ -53,11 +83,12 @@ type MultipartUploadSession struct {
 
 // UploadPartRequest 分片上传请求
 type UploadPartRequest struct {
-	UploadID   string // 上传ID
-	ObjectName string // 对象名
-	PartNumber int    // 分片号（从1开始）
-	Data       []byte // 分片数据
-	BucketName string // 存储桶名
+	UploadID   string    // 上传ID
+	ObjectName string    // 对象名
+	PartNumber int       // 分片号（从1开始）
+	Reader     io.Reader // 分片数据读取器，流式写入存储，不在内存中缓存整个分片
+	Size       int64     // 分片大小，最多读取该大小的数据
+	BucketName string    // 存储桶名
 }
 
 // UploadPartResult 分片上传结果
@@ -79,6 +110,15 @@ type CompleteMultipartRequest struct {
 	ObjectName string          // 对象名
 	Parts      []CompletedPart // 已完成的分片列表
 	BucketName string          // 存储桶名
+	Checksum   string          // 客户端提供的整个文件的SHA-256校验和（可选）
+	TotalSize  int64           // 客户端声明的文件总大小（可选，为0时不校验）
+}
+
+// ListPartsRequest 列出已上传分片请求
+type ListPartsRequest struct {
+	UploadID   string // 上传ID
+	ObjectName string // 对象名
+	BucketName string // 存储桶名
 }
 
 // AbortMultipartRequest 中止分片上传请求
@@ -94,7 +134,9 @@ type UploadProgress struct {
 	Percentage    int       // 完成百分比
 	BytesUploaded int64     // 已上传字节数
 	TotalBytes    int64     // 总字节数
-	IsCompleted   bool      // 是否完成
+	Status        string    // 状态：pending/uploading/completed/failed
+	IsCompleted   bool      // 是否结束（成功或失败）
+	Error         string    // 失败原因，为空表示成功或进行中
 	UpdatedAt     time.Time // 更新时间
 }
 
@@ -112,6 +154,11 @@ func NewUploadService(storage storage.StorageInterface) *UploadService {
 	}
 }
 
+// SetProgressRegistry 设置上传进度注册表，分片上传时按上传ID记录进度
+func (s *UploadService) SetProgressRegistry(registry *ProgressRegistry) {
+	s.progress = registry
+}
+
 // UploadFile 上传单个文件
 func (s *UploadService) UploadFile(ctx context.Context, req *UploadRequest) (*UploadResult, error) {
 	// 验证请求
@@ -120,20 +167,39 @@ func (s *UploadService) UploadFile(ctx context.Context, req *UploadRequest) (*Up
 	}
 
 	// 生成对象名
-	objectName := s.GenerateObjectName(req.FileName)
-
-	// 读取所有数据
-	data, err := io.ReadAll(req.Reader)
-	if err != nil {
-		return nil, fmt.Errorf("读取文件数据失败: %w", err)
+	objectName := req.ObjectName
+	if objectName == "" {
+		generated, err := s.newObjectName(ctx, req.BucketName, req.FileName)
+		if err != nil {
+			return nil, err
+		}
+		objectName = generated
 	}
 
-	// 上传到存储
-	uploadResult, err := s.storage.UploadFile(ctx, req.BucketName, objectName, data, req.ContentType)
+	// 流式上传到存储，不在内存中缓存整个文件，同时计算校验和
+	hasher := sha256.New()
+	reader := io.TeeReader(req.Reader, hasher)
+	expectedChecksum, _ := NormalizeChecksum(req.Checksum) // 格式已在ValidateUploadRequest中校验
+	opts := objectOptions(req.Metadata, req.Tags, expectedChecksum)
+	uploadResult, err := s.storage.UploadStream(ctx, req.BucketName, objectName, reader, req.Size, req.ContentType, opts)
 	if err != nil {
 		return nil, fmt.Errorf("上传文件失败: %w", err)
 	}
 
+	checksum := checksumHex(hasher)
+	if err := verifyChecksum(req.Checksum, checksum); err != nil {
+		s.storage.DeleteFile(ctx, req.BucketName, objectName)
+		return nil, err
+	}
+
+	// 上传前未知校验和时，上传完成后补充content-hash标签，失败不影响上传结果
+	if opts.Tags != nil && opts.Tags[storage.ObjectKeyContentHash] == "" {
+		opts.Tags[storage.ObjectKeyContentHash] = checksum
+		if err := s.storage.SetObjectTags(ctx, req.BucketName, objectName, opts.Tags); err != nil {
+			fmt.Printf("设置对象标签失败(%s/%s): %v\n", req.BucketName, objectName, err)
+		}
+	}
+
 	// 生成文件ID
 	fileID := uuid.New().String()
 
@@ -142,10 +208,29 @@ func (s *UploadService) UploadFile(ctx context.Context, req *UploadRequest) (*Up
 		ObjectName: objectName,
 		Size:       uploadResult.Size,
 		ETag:       uploadResult.ETag,
+		Checksum:   checksum,
 		UploadedAt: time.Now(),
 	}, nil
 }
 
+// objectOptions 构造上传对象的用户元数据和标签，复制调用方传入的map，已知校验和时添加content-hash
+func objectOptions(metadata, tags map[string]string, checksum string) storage.ObjectOptions {
+	var opts storage.ObjectOptions
+	if len(metadata) > 0 {
+		opts.Metadata = maps.Clone(metadata)
+		if checksum != "" {
+			opts.Metadata[storage.ObjectKeyContentHash] = checksum
+		}
+	}
+	if len(tags) > 0 {
+		opts.Tags = maps.Clone(tags)
+		if checksum != "" {
+			opts.Tags[storage.ObjectKeyContentHash] = checksum
+		}
+	}
+	return opts
+}
+
 // InitMultipartUpload 初始化分片上传
 func (s *UploadService) InitMultipartUpload(ctx context.Context, req *MultipartUploadRequest) (*MultipartUploadSession, error) {
 	// 验证请求
@@ -154,10 +239,25 @@ func (s *UploadService) InitMultipartUpload(ctx context.Context, req *MultipartU
 	}
 
 	// 生成对象名
-	objectName := s.GenerateObjectName(req.FileName)
+	objectName := req.ObjectName
+	if objectName == "" {
+		generated, err := s.newObjectName(ctx, req.BucketName, req.FileName)
+		if err != nil {
+			return nil, err
+		}
+		objectName = generated
+	}
 
-	// 生成上传ID（在实际MinIO实现中，这会调用MinIO的InitiateMultipartUpload）
-	uploadID := uuid.New().String()
+	// 在存储服务端创建分片上传
+	opts := objectOptions(req.Metadata, req.Tags, "")
+	uploadID, err := s.storage.InitiateMultipartUpload(ctx, req.BucketName, objectName, req.ContentType, opts)
+	if err != nil {
+		return nil, fmt.Errorf("初始化分片上传失败: %w", err)
+	}
+
+	if s.progress != nil {
+		s.progress.Start(uploadID, req.TotalSize)
+	}
 
 	return &MultipartUploadSession{
 		UploadID:   uploadID,
@@ -173,63 +273,96 @@ func (s *UploadService) UploadPart(ctx context.Context, req *UploadPartRequest)
 		return nil, err
 	}
 
-	// 在实际实现中，这里会调用MinIO的UploadPart
-	// 现在我们模拟一个简单的实现
-	partObjectName := fmt.Sprintf("%s.part.%d", req.ObjectName, req.PartNumber)
-	uploadResult, err := s.storage.UploadFile(ctx, req.BucketName, partObjectName, req.Data, "application/octet-stream")
+	reader := &partReader{reader: io.LimitReader(req.Reader, req.Size)}
+	partInfo, err := s.storage.UploadPart(ctx, req.BucketName, req.ObjectName, req.UploadID, req.PartNumber, reader, req.Size)
+	// 请求体提前结束时存储服务按声明的大小校验会拒绝写入，客户端重新上传该分片即可
+	if err != nil && reader.eof && reader.count < req.Size {
+		return nil, fmt.Errorf("%w: 期望%d字节，实际%d字节", ErrPartIncomplete, req.Size, reader.count)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("上传分片失败: %w", err)
 	}
+	// 存储服务未校验大小时已写入的分片大小不符，完成上传时会被拒绝
+	if partInfo.Size != req.Size {
+		return nil, fmt.Errorf("%w: 期望%d字节，实际%d字节", ErrPartIncomplete, req.Size, partInfo.Size)
+	}
+
+	if s.progress != nil {
+		s.progress.Add(req.UploadID, req.Size)
+	}
 
 	return &UploadPartResult{
 		PartNumber: req.PartNumber,
-		ETag:       uploadResult.ETag,
-		Size:       int64(len(req.Data)),
+		ETag:       partInfo.ETag,
+		Size:       req.Size,
 	}, nil
 }
 
-// CompleteMultipartUpload 完成分片上传
+// partReader 统计分片读取的字节数，记录请求体是否已读完
+type partReader struct {
+	reader io.Reader
+	count  int64
+	eof    bool
+}
+
+// Read 读取数据并累计字节数
+func (r *partReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	if err == io.EOF {
+		r.eof = true
+	}
+	return n, err
+}
+
+// CompleteMultipartUpload 完成分片上传，由存储服务端按分片号顺序合并
+// 合并前核对分片列表与已上传的分片，合并后校验文件大小和校验和，任一不一致时拒绝完成
 func (s *UploadService) CompleteMultipartUpload(ctx context.Context, req *CompleteMultipartRequest) (*UploadResult, error) {
 	// 验证请求
 	if err := s.validateCompleteMultipartRequest(req); err != nil {
 		return nil, err
 	}
 
-	// 在实际实现中，这里会调用MinIO的CompleteMultipartUpload
-	// 现在我们模拟：将所有分片合并成一个文件
-	var totalData []byte
-	var totalSize int64
+	// 分片缺失或不匹配时保留上传会话，客户端可以重新上传后再次完成
+	expectedSize, err := s.verifyParts(ctx, req)
+	if err != nil {
+		return nil, err
+	}
 
+	parts := make([]storage.CompletePart, 0, len(req.Parts))
 	for _, part := range req.Parts {
-		partObjectName := fmt.Sprintf("%s.part.%d", req.ObjectName, part.PartNumber)
+		parts = append(parts, storage.CompletePart{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+		})
+	}
 
-		// 检查分片是否存在
-		exists, err := s.storage.FileExists(ctx, req.BucketName, partObjectName)
-		if err != nil {
-			return nil, fmt.Errorf("检查分片存在性失败: %w", err)
-		}
-		if !exists {
-			return nil, fmt.Errorf("分片 %d 不存在", part.PartNumber)
-		}
+	uploadResult, err := s.storage.CompleteMultipartUpload(ctx, req.BucketName, req.ObjectName, req.UploadID, parts)
+	if err != nil {
+		return nil, fmt.Errorf("完成分片上传失败: %w", err)
+	}
 
-		// 获取分片信息来计算总大小
-		fileInfo, err := s.storage.GetFileInfo(ctx, req.BucketName, partObjectName)
-		if err != nil {
-			return nil, fmt.Errorf("获取分片信息失败: %w", err)
-		}
-		totalSize += fileInfo.Size
+	if uploadResult.Size != expectedSize {
+		err := fmt.Errorf("%w: 期望 %d 字节, 合并后 %d 字节", ErrSizeMismatch, expectedSize, uploadResult.Size)
+		s.failMultipartUpload(ctx, req, err)
+		return nil, err
 	}
 
-	// 创建最终文件（模拟合并）
-	uploadResult, err := s.storage.UploadFile(ctx, req.BucketName, req.ObjectName, totalData, "video/mp4")
+	// 分片由存储服务端合并，需要重新读取合并后的文件计算校验和
+	checksum, err := s.ComputeChecksum(ctx, req.BucketName, req.ObjectName)
 	if err != nil {
-		return nil, fmt.Errorf("创建最终文件失败: %w", err)
+		if s.progress != nil {
+			s.progress.Fail(req.UploadID, err.Error())
+		}
+		return nil, err
+	}
+	if err := verifyChecksum(req.Checksum, checksum); err != nil {
+		s.failMultipartUpload(ctx, req, err)
+		return nil, err
 	}
 
-	// 删除分片文件
-	for _, part := range req.Parts {
-		partObjectName := fmt.Sprintf("%s.part.%d", req.ObjectName, part.PartNumber)
-		_ = s.storage.DeleteFile(ctx, req.BucketName, partObjectName)
+	if s.progress != nil {
+		s.progress.Complete(req.UploadID)
 	}
 
 	// 生成文件ID
@@ -238,24 +371,110 @@ func (s *UploadService) CompleteMultipartUpload(ctx context.Context, req *Comple
 	return &UploadResult{
 		FileID:     fileID,
 		ObjectName: req.ObjectName,
-		Size:       totalSize,
+		Size:       uploadResult.Size,
 		ETag:       uploadResult.ETag,
+		Checksum:   checksum,
 		UploadedAt: time.Now(),
 	}, nil
 }
 
-// AbortMultipartUpload 中止分片上传
+// verifyParts 核对分片列表与存储服务端已上传的分片，返回合并后的文件大小
+// 分片未上传、ETag不一致或已上传的分片不在列表中时返回错误，避免合并出缺失内容或空的文件
+func (s *UploadService) verifyParts(ctx context.Context, req *CompleteMultipartRequest) (int64, error) {
+	uploaded, err := s.storage.ListParts(ctx, req.BucketName, req.ObjectName, req.UploadID)
+	if err != nil {
+		return 0, fmt.Errorf("获取已上传分片失败: %w", err)
+	}
+	uploadedParts := make(map[int]*storage.PartInfo, len(uploaded))
+	for _, part := range uploaded {
+		uploadedParts[part.PartNumber] = part
+	}
+
+	var size int64
+	for _, part := range req.Parts {
+		uploadedPart, ok := uploadedParts[part.PartNumber]
+		if !ok {
+			return 0, fmt.Errorf("%w: 分片 %d 未上传", ErrPartMissing, part.PartNumber)
+		}
+		if normalizeETag(part.ETag) != normalizeETag(uploadedPart.ETag) {
+			return 0, fmt.Errorf("%w: 分片 %d", ErrPartMismatch, part.PartNumber)
+		}
+		size += uploadedPart.Size
+		delete(uploadedParts, part.PartNumber)
+	}
+	if len(uploadedParts) > 0 {
+		extra := make([]int, 0, len(uploadedParts))
+		for partNumber := range uploadedParts {
+			extra = append(extra, partNumber)
+		}
+		slices.Sort(extra)
+		return 0, fmt.Errorf("%w: 已上传的分片 %v 不在分片列表中", ErrPartMissing, extra)
+	}
+
+	if size == 0 {
+		return 0, fmt.Errorf("%w: 已上传的分片为空", ErrSizeMismatch)
+	}
+	if req.TotalSize > 0 && size != req.TotalSize {
+		return 0, fmt.Errorf("%w: 期望 %d 字节, 已上传 %d 字节", ErrSizeMismatch, req.TotalSize, size)
+	}
+	return size, nil
+}
+
+// failMultipartUpload 删除校验失败的合并文件并标记上传失败
+func (s *UploadService) failMultipartUpload(ctx context.Context, req *CompleteMultipartRequest, err error) {
+	s.storage.DeleteFile(ctx, req.BucketName, req.ObjectName)
+	if s.progress != nil {
+		s.progress.Fail(req.UploadID, err.Error())
+	}
+}
+
+// normalizeETag 去掉ETag的引号并转换为小写
+func normalizeETag(etag string) string {
+	return strings.ToLower(strings.Trim(strings.TrimSpace(etag), `"`))
+}
+
+// ListParts 列出存储服务端已上传的分片，按分片号升序
+func (s *UploadService) ListParts(ctx context.Context, req *ListPartsRequest) ([]*UploadPartResult, error) {
+	// 验证请求
+	if err := s.validateListPartsRequest(req); err != nil {
+		return nil, err
+	}
+
+	uploaded, err := s.storage.ListParts(ctx, req.BucketName, req.ObjectName, req.UploadID)
+	if err != nil {
+		return nil, fmt.Errorf("获取已上传分片失败: %w", err)
+	}
+
+	parts := make([]*UploadPartResult, 0, len(uploaded))
+	for _, part := range uploaded {
+		parts = append(parts, &UploadPartResult{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+			Size:       part.Size,
+		})
+	}
+	return parts, nil
+}
+
+// AbortMultipartUpload 中止分片上传，存储服务端会清理已上传的分片
 func (s *UploadService) AbortMultipartUpload(ctx context.Context, req *AbortMultipartRequest) error {
-	// 在实际实现中，这里会调用MinIO的AbortMultipartUpload
-	// 现在我们模拟：删除可能存在的分片文件
+	// 验证请求
+	if err := s.validateAbortMultipartRequest(req); err != nil {
+		return err
+	}
+
+	if err := s.storage.AbortMultipartUpload(ctx, req.BucketName, req.ObjectName, req.UploadID); err != nil {
+		return fmt.Errorf("中止分片上传失败: %w", err)
+	}
 
-	// 由于我们没有跟踪分片，这里只是一个占位符实现
-	// 在真实场景中，MinIO会自动清理未完成的分片上传
+	if s.progress != nil {
+		s.progress.Fail(req.UploadID, "上传已中止")
+	}
 
 	return nil
 }
 
-// GenerateObjectName 生成对象名
+// GenerateObjectName 生成对象名，文件名转换为只包含ASCII字母、数字和"-"的片段，原始文件名只保存在元数据中
 func (s *UploadService) GenerateObjectName(fileName string) string {
 	now := time.Now()
 	year := now.Format("2006")
@@ -264,12 +483,31 @@ func (s *UploadService) GenerateObjectName(fileName string) string {
 	// 生成UUID作为文件前缀
 	fileID := uuid.New().String()
 
-	// 构造对象名：videos/{year}/{month}/{uuid}-{filename}
-	objectName := fmt.Sprintf("videos/%s/%s/%s-%s", year, month, fileID, fileName)
+	// 构造对象名：videos/{year}/{month}/{uuid}-{slug}{ext}，文件名无法转换时省略
+	name := fileID
+	if slug := Slugify(fileName, MaxSlugLength); slug != "" {
+		name += "-" + slug
+	}
+	objectName := fmt.Sprintf("videos/%s/%s/%s%s", year, month, name, ObjectExtension(fileName))
 
 	return objectName
 }
 
+// newObjectName 生成存储桶中不存在的对象名，已存在时重新生成
+func (s *UploadService) newObjectName(ctx context.Context, bucketName, fileName string) (string, error) {
+	for attempt := 0; attempt < objectNameAttempts; attempt++ {
+		objectName := s.GenerateObjectName(fileName)
+		exists, err := s.storage.FileExists(ctx, bucketName, objectName)
+		if err != nil {
+			return "", fmt.Errorf("检查对象是否存在失败: %w", err)
+		}
+		if !exists {
+			return objectName, nil
+		}
+	}
+	return "", fmt.Errorf("生成对象名失败: %d次生成的对象名均已存在", objectNameAttempts)
+}
+
 // ValidateUploadRequest 验证上传请求
 func (s *UploadService) ValidateUploadRequest(req *UploadRequest) error {
 	if req.FileName == "" {
@@ -296,6 +534,10 @@ func (s *UploadService) ValidateUploadRequest(req *UploadRequest) error {
 		return fmt.Errorf("存储桶名不能为空")
 	}
 
+	if _, err := NormalizeChecksum(req.Checksum); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -325,6 +567,11 @@ func (s *UploadService) validateMultipartRequest(req *MultipartUploadRequest) er
 		return fmt.Errorf("分片大小必须大于0")
 	}
 
+	// 只有一个分片时不受最小分片大小限制
+	if req.ChunkSize < MinPartSize && req.ChunkSize < req.TotalSize {
+		return fmt.Errorf("分片大小不能小于%d字节", MinPartSize)
+	}
+
 	return nil
 }
 
@@ -342,7 +589,7 @@ func (s *UploadService) validateUploadPartRequest(req *UploadPartRequest) error
 		return fmt.Errorf("分片号必须大于0")
 	}
 
-	if len(req.Data) == 0 {
+	if req.Reader == nil || req.Size <= 0 {
 		return fmt.Errorf("分片数据不能为空")
 	}
 
@@ -381,6 +628,48 @@ func (s *UploadService) validateCompleteMultipartRequest(req *CompleteMultipartR
 		}
 	}
 
+	if req.TotalSize < 0 {
+		return fmt.Errorf("文件总大小不能小于0")
+	}
+
+	if _, err := NormalizeChecksum(req.Checksum); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateListPartsRequest 验证列出已上传分片请求
+func (s *UploadService) validateListPartsRequest(req *ListPartsRequest) error {
+	if req.UploadID == "" {
+		return fmt.Errorf("上传ID不能为空")
+	}
+
+	if req.ObjectName == "" {
+		return fmt.Errorf("对象名不能为空")
+	}
+
+	if req.BucketName == "" {
+		return fmt.Errorf("存储桶名不能为空")
+	}
+
+	return nil
+}
+
+// validateAbortMultipartRequest 验证中止分片上传请求
+func (s *UploadService) validateAbortMultipartRequest(req *AbortMultipartRequest) error {
+	if req.UploadID == "" {
+		return fmt.Errorf("上传ID不能为空")
+	}
+
+	if req.ObjectName == "" {
+		return fmt.Errorf("对象名不能为空")
+	}
+
+	if req.BucketName == "" {
+		return fmt.Errorf("存储桶名不能为空")
+	}
+
 	return nil
 }
 
@@ -397,6 +686,7 @@ func (t *ProgressTracker) UpdateProgress(percentage int) {
 	progress := &UploadProgress{
 		UploadID:    t.uploadID,
 		Percentage:  percentage,
+		Status:      ProgressUploading,
 		IsCompleted: false,
 		UpdatedAt:   time.Now(),
 	}
@@ -413,6 +703,7 @@ func (t *ProgressTracker) Complete() {
 	progress := &UploadProgress{
 		UploadID:    t.uploadID,
 		Percentage:  100,
+		Status:      ProgressCompleted,
 		IsCompleted: true,
 		UpdatedAt:   time.Now(),
 	}
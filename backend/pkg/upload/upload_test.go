@@ -16,10 +16,6 @@ import (
 
 // TestUploadService_SingleFileUpload 测试单文件上传
 func TestUploadService_SingleFileUpload(t *testing.T) {
-	if !isStorageAvailable() {
-		t.Skip("跳过测试：MinIO存储服务不可用")
-	}
-
 	storageService := setupTestStorage(t)
 	uploadService := NewUploadService(storageService)
 
@@ -64,12 +60,39 @@ func TestUploadService_SingleFileUpload(t *testing.T) {
 	assert.True(t, exists, "上传的文件应该存在")
 }
 
+// TestUploadService_UploadWithObjectName 测试指定对象名的流式上传
+func TestUploadService_UploadWithObjectName(t *testing.T) {
+	storageService := setupTestStorage(t)
+	uploadService := NewUploadService(storageService)
+
+	ctx := context.Background()
+	bucketName := "test-bucket-object-name"
+
+	err := storageService.CreateBucket(ctx, bucketName)
+	require.NoError(t, err)
+
+	objectName := "videos/2025/08/fixed-id.mp4"
+	defer func() {
+		_ = storageService.DeleteFile(ctx, bucketName, objectName)
+		_ = storageService.RemoveBucket(ctx, bucketName)
+	}()
+
+	testData := bytes.Repeat([]byte("stream"), 1024)
+	result, err := uploadService.UploadFile(ctx, &UploadRequest{
+		FileName:    "original.mp4",
+		ObjectName:  objectName,
+		ContentType: "video/mp4",
+		Size:        int64(len(testData)),
+		Reader:      bytes.NewReader(testData),
+		BucketName:  bucketName,
+	})
+	require.NoError(t, err, "指定对象名上传应该成功")
+	assert.Equal(t, objectName, result.ObjectName, "应该使用指定的对象名")
+	assert.Equal(t, int64(len(testData)), result.Size)
+}
+
 // TestUploadService_MultipartUpload 测试分片上传
 func TestUploadService_MultipartUpload(t *testing.T) {
-	if !isStorageAvailable() {
-		t.Skip("跳过测试：MinIO存储服务不可用")
-	}
-
 	storageService := setupTestStorage(t)
 	uploadService := NewUploadService(storageService)
 
@@ -98,7 +121,7 @@ func TestUploadService_MultipartUpload(t *testing.T) {
 		ContentType: contentType,
 		TotalSize:   fileSize,
 		BucketName:  bucketName,
-		ChunkSize:   1024 * 1024, // 1MB 分片
+		ChunkSize:   MinPartSize, // 5MB 分片
 	}
 
 	// 初始化分片上传
@@ -110,7 +133,7 @@ func TestUploadService_MultipartUpload(t *testing.T) {
 
 	// 分片上传
 	var parts []CompletedPart
-	chunkSize := int64(1024 * 1024) // 1MB
+	chunkSize := int64(MinPartSize)
 
 	for i := int64(0); i < fileSize; i += chunkSize {
 		end := i + chunkSize
@@ -125,7 +148,8 @@ func TestUploadService_MultipartUpload(t *testing.T) {
 			UploadID:   session.UploadID,
 			ObjectName: session.ObjectName,
 			PartNumber: partNumber,
-			Data:       chunkData,
+			Reader:     bytes.NewReader(chunkData),
+			Size:       int64(len(chunkData)),
 			BucketName: bucketName,
 		}
 
@@ -169,10 +193,6 @@ func TestUploadService_MultipartUpload(t *testing.T) {
 
 // TestUploadService_AbortMultipartUpload 测试中止分片上传
 func TestUploadService_AbortMultipartUpload(t *testing.T) {
-	if !isStorageAvailable() {
-		t.Skip("跳过测试：MinIO存储服务不可用")
-	}
-
 	storageService := setupTestStorage(t)
 	uploadService := NewUploadService(storageService)
 
@@ -189,15 +209,26 @@ func TestUploadService_AbortMultipartUpload(t *testing.T) {
 	multipartRequest := &MultipartUploadRequest{
 		FileName:    "abort-test.mp4",
 		ContentType: "video/mp4",
-		TotalSize:   5 * 1024 * 1024, // 5MB
+		TotalSize:   10 * 1024 * 1024, // 10MB
 		BucketName:  bucketName,
-		ChunkSize:   1024 * 1024, // 1MB
+		ChunkSize:   MinPartSize,
 	}
 
 	// 初始化分片上传
 	session, err := uploadService.InitMultipartUpload(ctx, multipartRequest)
 	require.NoError(t, err)
 
+	// 上传一个分片后中止
+	_, err = uploadService.UploadPart(ctx, &UploadPartRequest{
+		UploadID:   session.UploadID,
+		ObjectName: session.ObjectName,
+		PartNumber: 1,
+		Reader:     bytes.NewReader(make([]byte, MinPartSize)),
+		Size:       MinPartSize,
+		BucketName: bucketName,
+	})
+	require.NoError(t, err, "上传分片应该成功")
+
 	// 中止分片上传
 	abortRequest := &AbortMultipartRequest{
 		UploadID:   session.UploadID,
@@ -214,18 +245,222 @@ func TestUploadService_AbortMultipartUpload(t *testing.T) {
 	assert.False(t, exists, "中止后文件不应存在")
 }
 
+// TestUploadService_UploadPartIncomplete 测试分片请求体少于声明的大小
+func TestUploadService_UploadPartIncomplete(t *testing.T) {
+	storageService := setupTestStorage(t)
+	uploadService := NewUploadService(storageService)
+
+	ctx := context.Background()
+	bucketName := "test-bucket"
+	require.NoError(t, storageService.CreateBucket(ctx, bucketName))
+
+	session, err := uploadService.InitMultipartUpload(ctx, &MultipartUploadRequest{
+		FileName:    "incomplete.mp4",
+		ContentType: "video/mp4",
+		TotalSize:   MinPartSize,
+		BucketName:  bucketName,
+		ChunkSize:   MinPartSize,
+	})
+	require.NoError(t, err)
+
+	_, err = uploadService.UploadPart(ctx, &UploadPartRequest{
+		UploadID:   session.UploadID,
+		ObjectName: session.ObjectName,
+		PartNumber: 1,
+		Reader:     bytes.NewReader(make([]byte, 1024)),
+		Size:       MinPartSize,
+		BucketName: bucketName,
+	})
+	assert.ErrorIs(t, err, ErrPartIncomplete, "存储服务拒绝写入时也应该识别为分片不完整")
+}
+
+// TestUploadService_CompleteMultipartVerification 测试完成分片上传时核对分片和文件大小
+func TestUploadService_CompleteMultipartVerification(t *testing.T) {
+	service, storageService := setupChecksumTestService(t)
+	ctx := context.Background()
+	chunks := [][]byte{[]byte("first part "), []byte("second part")}
+	totalSize := int64(len(chunks[0]) + len(chunks[1]))
+
+	// start 初始化分片上传并上传指定的分片，返回会话和各分片的ETag
+	start := func(t *testing.T, partNumbers ...int) (*MultipartUploadSession, map[int]string) {
+		session, err := service.InitMultipartUpload(ctx, &MultipartUploadRequest{
+			FileName:    "verify.mp4",
+			ContentType: "video/mp4",
+			TotalSize:   totalSize,
+			BucketName:  checksumTestBucket,
+			ChunkSize:   totalSize,
+		})
+		require.NoError(t, err)
+
+		etags := make(map[int]string)
+		for _, partNumber := range partNumbers {
+			part, err := service.UploadPart(ctx, &UploadPartRequest{
+				UploadID:   session.UploadID,
+				ObjectName: session.ObjectName,
+				PartNumber: partNumber,
+				Reader:     bytes.NewReader(chunks[partNumber-1]),
+				Size:       int64(len(chunks[partNumber-1])),
+				BucketName: checksumTestBucket,
+			})
+			require.NoError(t, err)
+			etags[partNumber] = part.ETag
+		}
+		return session, etags
+	}
+	complete := func(session *MultipartUploadSession, parts []CompletedPart, size int64) (*UploadResult, error) {
+		return service.CompleteMultipartUpload(ctx, &CompleteMultipartRequest{
+			UploadID:   session.UploadID,
+			ObjectName: session.ObjectName,
+			Parts:      parts,
+			BucketName: checksumTestBucket,
+			TotalSize:  size,
+		})
+	}
+	assertNotCreated := func(t *testing.T, session *MultipartUploadSession) {
+		exists, err := storageService.FileExists(ctx, checksumTestBucket, session.ObjectName)
+		require.NoError(t, err)
+		assert.False(t, exists, "校验失败时不应该生成文件")
+	}
+
+	t.Run("分片和大小一致时完成上传", func(t *testing.T) {
+		session, etags := start(t, 1, 2)
+		result, err := complete(session, []CompletedPart{{PartNumber: 1, ETag: etags[1]}, {PartNumber: 2, ETag: `"` + etags[2] + `"`}}, totalSize)
+		require.NoError(t, err)
+		assert.Equal(t, totalSize, result.Size)
+		assert.Equal(t, sha256Hex(append(append([]byte{}, chunks[0]...), chunks[1]...)), result.Checksum)
+	})
+
+	t.Run("分片未上传时拒绝完成并保留会话", func(t *testing.T) {
+		session, etags := start(t, 1)
+		_, err := complete(session, []CompletedPart{{PartNumber: 1, ETag: etags[1]}, {PartNumber: 2, ETag: etags[1]}}, totalSize)
+		assert.ErrorIs(t, err, ErrPartMissing)
+		assertNotCreated(t, session)
+
+		// 补传缺失的分片后可以再次完成
+		part, err := service.UploadPart(ctx, &UploadPartRequest{
+			UploadID:   session.UploadID,
+			ObjectName: session.ObjectName,
+			PartNumber: 2,
+			Reader:     bytes.NewReader(chunks[1]),
+			Size:       int64(len(chunks[1])),
+			BucketName: checksumTestBucket,
+		})
+		require.NoError(t, err)
+		_, err = complete(session, []CompletedPart{{PartNumber: 1, ETag: etags[1]}, {PartNumber: 2, ETag: part.ETag}}, totalSize)
+		assert.NoError(t, err)
+	})
+
+	t.Run("已上传的分片不在列表中时拒绝完成", func(t *testing.T) {
+		session, etags := start(t, 1, 2)
+		_, err := complete(session, []CompletedPart{{PartNumber: 1, ETag: etags[1]}}, 0)
+		assert.ErrorIs(t, err, ErrPartMissing)
+		assertNotCreated(t, session)
+	})
+
+	t.Run("ETag不一致时拒绝完成", func(t *testing.T) {
+		session, etags := start(t, 1, 2)
+		_, err := complete(session, []CompletedPart{{PartNumber: 1, ETag: etags[2]}, {PartNumber: 2, ETag: etags[2]}}, totalSize)
+		assert.ErrorIs(t, err, ErrPartMismatch)
+		assertNotCreated(t, session)
+	})
+
+	t.Run("总大小不一致时拒绝完成", func(t *testing.T) {
+		session, etags := start(t, 1, 2)
+		_, err := complete(session, []CompletedPart{{PartNumber: 1, ETag: etags[1]}, {PartNumber: 2, ETag: etags[2]}}, totalSize+1)
+		assert.ErrorIs(t, err, ErrSizeMismatch)
+		assertNotCreated(t, session)
+	})
+}
+
+// TestUploadService_ListParts 测试列出已上传的分片
+func TestUploadService_ListParts(t *testing.T) {
+	service, _ := setupChecksumTestService(t)
+	ctx := context.Background()
+
+	session, err := service.InitMultipartUpload(ctx, &MultipartUploadRequest{
+		FileName:    "parts.mp4",
+		ContentType: "video/mp4",
+		TotalSize:   2*MinPartSize + 10,
+		BucketName:  checksumTestBucket,
+		ChunkSize:   MinPartSize,
+	})
+	require.NoError(t, err)
+
+	// 分片可以不按顺序上传
+	etags := make(map[int]string)
+	for partNumber, size := range map[int]int{3: 10, 1: MinPartSize} {
+		part, err := service.UploadPart(ctx, &UploadPartRequest{
+			UploadID:   session.UploadID,
+			ObjectName: session.ObjectName,
+			PartNumber: partNumber,
+			Reader:     bytes.NewReader(bytes.Repeat([]byte{byte(partNumber)}, size)),
+			Size:       int64(size),
+			BucketName: checksumTestBucket,
+		})
+		require.NoError(t, err)
+		etags[partNumber] = part.ETag
+	}
+
+	parts, err := service.ListParts(ctx, &ListPartsRequest{
+		UploadID:   session.UploadID,
+		ObjectName: session.ObjectName,
+		BucketName: checksumTestBucket,
+	})
+	require.NoError(t, err)
+	require.Len(t, parts, 2)
+	assert.Equal(t, 1, parts[0].PartNumber, "应该按分片号升序")
+	assert.Equal(t, etags[1], parts[0].ETag)
+	assert.Equal(t, int64(MinPartSize), parts[0].Size)
+	assert.Equal(t, 3, parts[1].PartNumber)
+	assert.Equal(t, etags[3], parts[1].ETag)
+	assert.Equal(t, int64(10), parts[1].Size)
+
+	_, err = service.ListParts(ctx, &ListPartsRequest{ObjectName: session.ObjectName, BucketName: checksumTestBucket})
+	assert.Error(t, err, "上传ID为空时应该返回错误")
+}
+
+// TestUploadService_ValidateMultipartRequest 测试分片上传请求验证
+func TestUploadService_ValidateMultipartRequest(t *testing.T) {
+	uploadService := NewUploadService(nil)
+
+	request := &MultipartUploadRequest{
+		FileName:    "large-video.mp4",
+		ContentType: "video/mp4",
+		TotalSize:   20 * 1024 * 1024,
+		BucketName:  "test-bucket",
+		ChunkSize:   MinPartSize,
+	}
+	assert.NoError(t, uploadService.validateMultipartRequest(request), "有效请求应该通过验证")
+
+	request.ChunkSize = 1024 * 1024
+	err := uploadService.validateMultipartRequest(request)
+	assert.Error(t, err, "分片小于最小分片大小时应该验证失败")
+	assert.Contains(t, err.Error(), "分片大小不能小于")
+
+	request.TotalSize = 512 * 1024
+	assert.NoError(t, uploadService.validateMultipartRequest(request), "只有一个分片时不受最小分片大小限制")
+
+	err = uploadService.AbortMultipartUpload(context.Background(), &AbortMultipartRequest{ObjectName: "o", BucketName: "b"})
+	assert.Error(t, err, "缺少上传ID时中止应该失败")
+	assert.Contains(t, err.Error(), "上传ID不能为空")
+}
+
 // TestUploadService_GenerateObjectName 测试对象名生成
 func TestUploadService_GenerateObjectName(t *testing.T) {
 	uploadService := NewUploadService(nil)
 
 	testCases := []struct {
-		fileName     string
-		expectedPath string
+		fileName string
+		suffix   string
 	}{
-		{"test.mp4", "videos"},
-		{"movie.avi", "videos"},
-		{"video.mov", "videos"},
-		{"document.pdf", "videos"}, // 即使不是视频文件，也应该放在videos目录
+		{"test.mp4", "-test.mp4"},
+		{"movie.avi", "-movie.avi"},
+		{"document.pdf", "-document.pdf"}, // 即使不是视频文件，也应该放在videos目录
+		{"My Holiday Video (1).MOV", "-my-holiday-video-1.mov"},
+		{"../../etc/passwd.mp4", "-passwd.mp4"},
+		{`C:\Users\alice\旅行 🎬.mp4`, ".mp4"},
+		{"假期视频.mp4", ".mp4"},
+		{"noext", "-noext"},
 	}
 
 	for _, tc := range testCases {
@@ -233,16 +468,15 @@ func TestUploadService_GenerateObjectName(t *testing.T) {
 			objectName := uploadService.GenerateObjectName(tc.fileName)
 
 			assert.NotEmpty(t, objectName, "对象名不应为空")
-			assert.True(t, strings.HasPrefix(objectName, tc.expectedPath), "对象名应该以正确路径开头")
-			assert.True(t, strings.Contains(objectName, tc.fileName), "对象名应该包含原文件名")
+			assert.True(t, strings.HasSuffix(objectName, tc.suffix), "对象名应该以转换后的文件名结尾: %s", objectName)
 
-			// 验证路径格式：videos/{year}/{month}/{uuid}-{filename}
+			// 验证路径格式：videos/{year}/{month}/{uuid}-{slug}{ext}
 			parts := strings.Split(objectName, "/")
 			assert.Len(t, parts, 4, "对象名应该有4个路径部分")
 			assert.Equal(t, "videos", parts[0], "第一部分应该是videos")
 			assert.Len(t, parts[1], 4, "年份应该是4位数")
 			assert.Len(t, parts[2], 2, "月份应该是2位数")
-			assert.True(t, strings.Contains(parts[3], tc.fileName), "文件名部分应该包含原文件名")
+			assert.Len(t, parts[3], 36+len(tc.suffix), "文件名部分应该是UUID加转换后的文件名")
 		})
 	}
 }
@@ -385,38 +619,9 @@ func TestUploadService_UploadProgress(t *testing.T) {
 	assert.True(t, progressUpdates[4].IsCompleted, "最后应该标记为完成")
 }
 
-// isStorageAvailable 检查存储服务是否可用
-func isStorageAvailable() bool {
-	storageConfig := &storage.MinIOConfig{
-		Endpoint:  "localhost:9000",
-		AccessKey: "minioadmin",
-		SecretKey: "minioadmin",
-		UseSSL:    false,
-		Region:    "us-east-1",
-	}
-
-	storageService, err := storage.NewMinIOStorage(storageConfig)
-	if err != nil {
-		return false
-	}
-
-	ctx := context.Background()
-	err = storageService.TestConnection(ctx)
-	return err == nil
-}
-
-// setupTestStorage 设置测试存储服务
+// setupTestStorage 设置测试存储，使用内存存储，不依赖MinIO服务
 func setupTestStorage(t *testing.T) storage.StorageInterface {
-	storageConfig := &storage.MinIOConfig{
-		Endpoint:  "localhost:9000",
-		AccessKey: "minioadmin",
-		SecretKey: "minioadmin",
-		UseSSL:    false,
-		Region:    "us-east-1",
-	}
-
-	storageService, err := storage.NewMinIOStorage(storageConfig)
-	require.NoError(t, err)
-
-	return storageService
+	store, err := storage.NewMemoryStorage(&storage.MemoryConfig{BaseURL: "http://localhost:8888/storage"})
+	require.NoError(t, err, "创建测试存储应该成功")
+	return store
 }
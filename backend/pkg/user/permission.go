@@ -0,0 +1,42 @@
+package user
+
+// Permission 操作权限
+type Permission string
+
+// 视频和管理操作权限
+const (
+	PermissionVideoView   Permission = "video:view"   // 浏览和播放视频
+	PermissionVideoUpload Permission = "video:upload" // 上传视频
+	PermissionVideoEdit   Permission = "video:edit"   // 修改和删除自己上传的视频
+	PermissionVideoManage Permission = "video:manage" // 修改和删除任意用户的视频
+	PermissionAdmin       Permission = "admin"        // 访问管理接口
+)
+
+// rolePermissions 各角色拥有的权限
+var rolePermissions = map[string][]Permission{
+	RoleViewer:   {PermissionVideoView},
+	RoleUploader: {PermissionVideoView, PermissionVideoUpload, PermissionVideoEdit},
+	RoleAdmin:    {PermissionVideoView, PermissionVideoUpload, PermissionVideoEdit, PermissionVideoManage, PermissionAdmin},
+}
+
+// HasPermission 角色是否拥有权限，未知角色没有任何权限
+func HasPermission(role string, permission Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// CanModifyVideo 用户是否可以修改或删除视频，ownerID为上传视频的用户ID
+// 拥有video:manage权限时可以修改任意视频，拥有video:edit权限时只能修改自己上传的视频
+func CanModifyVideo(claims *Claims, ownerID string) bool {
+	if claims == nil {
+		return false
+	}
+	if HasPermission(claims.Role, PermissionVideoManage) {
+		return true
+	}
+	return HasPermission(claims.Role, PermissionVideoEdit) && ownerID != "" && ownerID == claims.UserID
+}
@@ -0,0 +1,47 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHasPermission 测试角色权限
+func TestHasPermission(t *testing.T) {
+	tests := []struct {
+		role       string
+		permission Permission
+		expected   bool
+	}{
+		{RoleViewer, PermissionVideoView, true},
+		{RoleViewer, PermissionVideoUpload, false},
+		{RoleViewer, PermissionVideoEdit, false},
+		{RoleUploader, PermissionVideoUpload, true},
+		{RoleUploader, PermissionVideoEdit, true},
+		{RoleUploader, PermissionVideoManage, false},
+		{RoleUploader, PermissionAdmin, false},
+		{RoleAdmin, PermissionVideoManage, true},
+		{RoleAdmin, PermissionAdmin, true},
+		{"guest", PermissionVideoView, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, HasPermission(tt.role, tt.permission), "%s %s", tt.role, tt.permission)
+	}
+}
+
+// TestCanModifyVideo 测试视频修改权限
+func TestCanModifyVideo(t *testing.T) {
+	uploader := &Claims{UserID: "user-1", Role: RoleUploader}
+	assert.True(t, CanModifyVideo(uploader, "user-1"), "上传者可以修改自己的视频")
+	assert.False(t, CanModifyVideo(uploader, "user-2"), "上传者不能修改其他用户的视频")
+	assert.False(t, CanModifyVideo(uploader, ""), "没有上传者的视频只有管理员可以修改")
+
+	viewer := &Claims{UserID: "user-1", Role: RoleViewer}
+	assert.False(t, CanModifyVideo(viewer, "user-1"), "观看者不能修改视频，即使是降级前自己上传的")
+
+	admin := &Claims{UserID: "admin-1", Role: RoleAdmin}
+	assert.True(t, CanModifyVideo(admin, "user-2"))
+	assert.True(t, CanModifyVideo(admin, ""))
+
+	assert.False(t, CanModifyVideo(nil, "user-1"))
+}
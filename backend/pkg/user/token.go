@@ -0,0 +1,134 @@
+package user
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidToken 令牌无效
+	ErrInvalidToken = errors.New("令牌无效")
+	// ErrTokenExpired 令牌已过期
+	ErrTokenExpired = errors.New("令牌已过期")
+)
+
+// jwtHeader HS256令牌头（固定值）
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// Claims 令牌声明
+type Claims struct {
+	UserID    string `json:"sub"`      // 用户ID
+	Username  string `json:"username"` // 用户名
+	Role      string `json:"role"`     // 角色
+	IssuedAt  int64  `json:"iat"`      // 签发时间（Unix秒）
+	ExpiresAt int64  `json:"exp"`      // 过期时间（Unix秒）
+}
+
+// TokenManager JWT令牌管理器
+type TokenManager struct {
+	secret []byte
+	expiry time.Duration
+}
+
+// NewTokenManager 创建令牌管理器
+func NewTokenManager(secret string, expiry time.Duration) (*TokenManager, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("JWT密钥不能为空")
+	}
+	if expiry <= 0 {
+		return nil, fmt.Errorf("令牌有效期必须大于0")
+	}
+
+	return &TokenManager{
+		secret: []byte(secret),
+		expiry: expiry,
+	}, nil
+}
+
+// GenerateToken 为用户签发令牌
+func (m *TokenManager) GenerateToken(user *User) (string, *Claims, error) {
+	if user == nil {
+		return "", nil, fmt.Errorf("用户不能为空")
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		UserID:    user.ID,
+		Username:  user.Username,
+		Role:      user.Role,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(m.expiry).Unix(),
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", nil, fmt.Errorf("序列化令牌声明失败: %w", err)
+	}
+
+	signingInput := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	token := signingInput + "." + m.sign(signingInput)
+
+	return token, claims, nil
+}
+
+// ParseToken 校验并解析令牌
+func (m *TokenManager) ParseToken(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		return nil, ErrInvalidToken
+	}
+
+	expected := m.sign(parts[0] + "." + parts[1])
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	claims := &Claims{}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrTokenExpired
+	}
+
+	return claims, nil
+}
+
+// GetExpiry 获取令牌有效期
+func (m *TokenManager) GetExpiry() time.Duration {
+	return m.expiry
+}
+
+// sign 计算HMAC-SHA256签名
+func (m *TokenManager) sign(signingInput string) string {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(signingInput))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// claimsContextKey 上下文中保存令牌声明的键
+type claimsContextKey struct{}
+
+// ContextWithClaims 将令牌声明写入上下文
+func ContextWithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext 从上下文读取令牌声明
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*Claims)
+	return claims, ok && claims != nil
+}
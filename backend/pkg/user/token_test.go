@@ -0,0 +1,89 @@
+package user
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewTokenManager 测试令牌管理器创建
+func TestNewTokenManager(t *testing.T) {
+	_, err := NewTokenManager("", time.Hour)
+	assert.Error(t, err, "空密钥应该返回错误")
+
+	_, err = NewTokenManager("secret", 0)
+	assert.Error(t, err, "无效有效期应该返回错误")
+
+	manager, err := NewTokenManager("secret", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, manager.GetExpiry())
+}
+
+// TestTokenManager_GenerateAndParse 测试令牌签发与解析
+func TestTokenManager_GenerateAndParse(t *testing.T) {
+	manager, err := NewTokenManager("test-secret", time.Hour)
+	require.NoError(t, err)
+
+	user := &User{ID: "user-1", Username: "alice", Role: RoleUploader}
+	token, claims, err := manager.GenerateToken(user)
+	require.NoError(t, err, "签发令牌应该成功")
+	assert.Len(t, strings.Split(token, "."), 3, "令牌应该由三段组成")
+	assert.Equal(t, "user-1", claims.UserID)
+
+	parsed, err := manager.ParseToken(token)
+	require.NoError(t, err, "解析令牌应该成功")
+	assert.Equal(t, "user-1", parsed.UserID)
+	assert.Equal(t, "alice", parsed.Username)
+	assert.Equal(t, RoleUploader, parsed.Role)
+	assert.Equal(t, claims.ExpiresAt, parsed.ExpiresAt)
+}
+
+// TestTokenManager_InvalidTokens 测试无效令牌
+func TestTokenManager_InvalidTokens(t *testing.T) {
+	manager, err := NewTokenManager("test-secret", time.Hour)
+	require.NoError(t, err)
+	token, _, err := manager.GenerateToken(&User{ID: "user-1", Username: "alice", Role: RoleViewer})
+	require.NoError(t, err)
+
+	_, err = manager.ParseToken("not-a-token")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// 篡改载荷
+	parts := strings.Split(token, ".")
+	tampered := parts[0] + "." + parts[1] + "x." + parts[2]
+	_, err = manager.ParseToken(tampered)
+	assert.ErrorIs(t, err, ErrInvalidToken, "篡改的令牌应该无效")
+
+	// 其他密钥签发
+	other, err := NewTokenManager("other-secret", time.Hour)
+	require.NoError(t, err)
+	_, err = other.ParseToken(token)
+	assert.ErrorIs(t, err, ErrInvalidToken, "不同密钥签发的令牌应该无效")
+}
+
+// TestTokenManager_Expired 测试过期令牌
+func TestTokenManager_Expired(t *testing.T) {
+	manager, err := NewTokenManager("test-secret", time.Nanosecond)
+	require.NoError(t, err)
+
+	token, _, err := manager.GenerateToken(&User{ID: "user-1", Username: "alice", Role: RoleViewer})
+	require.NoError(t, err)
+
+	_, err = manager.ParseToken(token)
+	assert.ErrorIs(t, err, ErrTokenExpired, "过期令牌应该返回过期错误")
+}
+
+// TestClaimsContext 测试上下文中的令牌声明
+func TestClaimsContext(t *testing.T) {
+	_, ok := ClaimsFromContext(context.Background())
+	assert.False(t, ok, "空上下文不应包含声明")
+
+	ctx := ContextWithClaims(context.Background(), &Claims{UserID: "user-1"})
+	claims, ok := ClaimsFromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "user-1", claims.UserID)
+}
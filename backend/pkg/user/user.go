@@ -0,0 +1,274 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 用户角色
+const (
+	RoleAdmin    = "admin"    // 管理员：管理用户和全部视频
+	RoleUploader = "uploader" // 上传者：可以上传和管理自己的视频
+	RoleViewer   = "viewer"   // 观看者：只能浏览和播放
+)
+
+var (
+	// ErrUserNotFound 用户不存在
+	ErrUserNotFound = errors.New("用户不存在")
+	// ErrUserExists 用户名已存在
+	ErrUserExists = errors.New("用户名已存在")
+	// ErrInvalidCredentials 用户名或密码错误
+	ErrInvalidCredentials = errors.New("用户名或密码错误")
+)
+
+// usernamePattern 用户名规则：3-32位字母、数字、下划线或连字符
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,32}$`)
+
+// User 用户信息
+type User struct {
+	ID           string    `json:"id"`         // 用户ID
+	Username     string    `json:"username"`   // 用户名
+	Email        string    `json:"email"`      // 邮箱
+	PasswordHash string    `json:"-"`          // 密码哈希
+	Role         string    `json:"role"`       // 角色
+	CreatedAt    time.Time `json:"created_at"` // 创建时间
+	UpdatedAt    time.Time `json:"updated_at"` // 更新时间
+}
+
+// RegisterRequest 注册请求
+type RegisterRequest struct {
+	Username string `json:"username"` // 用户名
+	Password string `json:"password"` // 密码
+	Email    string `json:"email"`    // 邮箱（可选）
+}
+
+// UserService 用户服务
+type UserService struct {
+	users      map[string]*User  // 用户ID -> 用户
+	usernames  map[string]string // 小写用户名 -> 用户ID
+	bcryptCost int
+	mutex      sync.RWMutex
+}
+
+// NewUserService 创建用户服务
+func NewUserService() *UserService {
+	return &UserService{
+		users:      make(map[string]*User),
+		usernames:  make(map[string]string),
+		bcryptCost: bcrypt.DefaultCost,
+	}
+}
+
+// Register 注册用户，注册的用户都为观看者，由管理员调整角色
+func (s *UserService) Register(ctx context.Context, req *RegisterRequest) (*User, error) {
+	return s.createUser(req, RoleViewer)
+}
+
+// EnsureAdmin 确保用户名对应的账号为管理员：账号不存在时使用请求创建管理员，已存在时只提升为管理员，不修改密码
+func (s *UserService) EnsureAdmin(ctx context.Context, req *RegisterRequest) (*User, error) {
+	if req != nil {
+		if existing, err := s.GetUserByUsername(ctx, req.Username); err == nil {
+			return s.UpdateRole(ctx, existing.ID, RoleAdmin)
+		}
+	}
+
+	return s.createUser(req, RoleAdmin)
+}
+
+// createUser 验证请求并创建指定角色的用户
+func (s *UserService) createUser(req *RegisterRequest, role string) (*User, error) {
+	if err := s.ValidateRegisterRequest(req); err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), s.bcryptCost)
+	if err != nil {
+		return nil, fmt.Errorf("密码加密失败: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := strings.ToLower(req.Username)
+	if _, exists := s.usernames[key]; exists {
+		return nil, ErrUserExists
+	}
+
+	now := time.Now()
+	user := &User{
+		ID:           uuid.New().String(),
+		Username:     req.Username,
+		Email:        strings.TrimSpace(req.Email),
+		PasswordHash: string(hash),
+		Role:         role,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	s.users[user.ID] = user
+	s.usernames[key] = user.ID
+
+	return copyUser(user), nil
+}
+
+// Authenticate 校验用户名和密码
+func (s *UserService) Authenticate(ctx context.Context, username, password string) (*User, error) {
+	s.mutex.RLock()
+	id, exists := s.usernames[strings.ToLower(username)]
+	var user *User
+	if exists {
+		user = copyUser(s.users[id])
+	}
+	s.mutex.RUnlock()
+
+	if user == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+// GetUser 根据ID获取用户
+func (s *UserService) GetUser(ctx context.Context, userID string) (*User, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	user, exists := s.users[userID]
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	return copyUser(user), nil
+}
+
+// GetUserByUsername 根据用户名获取用户
+func (s *UserService) GetUserByUsername(ctx context.Context, username string) (*User, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	id, exists := s.usernames[strings.ToLower(username)]
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	return copyUser(s.users[id]), nil
+}
+
+// UpdateRole 更新用户角色
+func (s *UserService) UpdateRole(ctx context.Context, userID, role string) (*User, error) {
+	if !IsValidRole(role) {
+		return nil, fmt.Errorf("无效的角色: %s", role)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	user, exists := s.users[userID]
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	// 至少保留一个管理员
+	if user.Role == RoleAdmin && role != RoleAdmin && s.countRoleLocked(RoleAdmin) == 1 {
+		return nil, fmt.Errorf("不能移除最后一个管理员")
+	}
+
+	user.Role = role
+	user.UpdatedAt = time.Now()
+
+	return copyUser(user), nil
+}
+
+// ListUsers 列出所有用户，按创建时间升序
+func (s *UserService) ListUsers(ctx context.Context) []*User {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	users := make([]*User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, copyUser(user))
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].CreatedAt.Before(users[j].CreatedAt)
+	})
+
+	return users
+}
+
+// countRoleLocked 统计指定角色的用户数量，调用方需持有锁
+func (s *UserService) countRoleLocked(role string) int {
+	count := 0
+	for _, user := range s.users {
+		if user.Role == role {
+			count++
+		}
+	}
+	return count
+}
+
+// ValidateRegisterRequest 验证注册请求
+func (s *UserService) ValidateRegisterRequest(req *RegisterRequest) error {
+	if req == nil {
+		return fmt.Errorf("注册请求不能为空")
+	}
+
+	if !usernamePattern.MatchString(req.Username) {
+		return fmt.Errorf("用户名必须为3-32位字母、数字、下划线或连字符")
+	}
+
+	if len(req.Password) < 8 {
+		return fmt.Errorf("密码长度不能少于8位")
+	}
+
+	// bcrypt只使用前72字节
+	if len(req.Password) > 72 {
+		return fmt.Errorf("密码长度不能超过72字节")
+	}
+
+	if req.Email != "" && !strings.Contains(req.Email, "@") {
+		return fmt.Errorf("邮箱格式无效")
+	}
+
+	return nil
+}
+
+// IsValidRole 检查角色是否有效
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleUploader, RoleViewer:
+		return true
+	}
+	return false
+}
+
+// CanUpload 角色是否允许上传视频
+func CanUpload(role string) bool {
+	return HasPermission(role, PermissionVideoUpload)
+}
+
+// copyUser 复制用户信息
+func copyUser(user *User) *User {
+	copied := *user
+	return &copied
+}
+
+// Count 统计用户数量
+func (s *UserService) Count(ctx context.Context) int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.users)
+}
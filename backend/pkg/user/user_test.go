@@ -0,0 +1,177 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newTestUserService 创建测试用户服务（降低bcrypt成本以加快测试）
+func newTestUserService() *UserService {
+	service := NewUserService()
+	service.bcryptCost = bcrypt.MinCost
+	return service
+}
+
+// TestUserService_Register 测试用户注册
+func TestUserService_Register(t *testing.T) {
+	service := newTestUserService()
+	ctx := context.Background()
+
+	first, err := service.Register(ctx, &RegisterRequest{Username: "bob", Password: "password123", Email: "bob@example.com"})
+	require.NoError(t, err, "注册应该成功")
+	assert.NotEmpty(t, first.ID, "用户ID不应为空")
+	assert.Equal(t, RoleViewer, first.Role, "第一个注册的用户不应该成为管理员")
+	assert.NotEqual(t, "password123", first.PasswordHash, "密码不应明文保存")
+
+	viewer, err := service.Register(ctx, &RegisterRequest{Username: "alice", Password: "password123"})
+	require.NoError(t, err)
+	assert.Equal(t, RoleViewer, viewer.Role, "注册的用户默认应该为观看者")
+
+	_, err = service.Register(ctx, &RegisterRequest{Username: "ALICE", Password: "password123"})
+	assert.ErrorIs(t, err, ErrUserExists, "用户名应该不区分大小写去重")
+	assert.Equal(t, 2, service.Count(ctx))
+}
+
+// TestUserService_EnsureAdmin 测试初始管理员
+func TestUserService_EnsureAdmin(t *testing.T) {
+	service := newTestUserService()
+	ctx := context.Background()
+
+	admin, err := service.EnsureAdmin(ctx, &RegisterRequest{Username: "admin", Password: "password123"})
+	require.NoError(t, err)
+	assert.Equal(t, RoleAdmin, admin.Role, "不存在的账号应该创建为管理员")
+
+	again, err := service.EnsureAdmin(ctx, &RegisterRequest{Username: "ADMIN", Password: "other-password"})
+	require.NoError(t, err)
+	assert.Equal(t, admin.ID, again.ID, "已存在的账号不应该重复创建")
+	_, err = service.Authenticate(ctx, "admin", "password123")
+	assert.NoError(t, err, "已存在的账号不应该修改密码")
+
+	alice, err := service.Register(ctx, &RegisterRequest{Username: "alice", Password: "password123"})
+	require.NoError(t, err)
+	promoted, err := service.EnsureAdmin(ctx, &RegisterRequest{Username: "alice", Password: "password123"})
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, promoted.ID)
+	assert.Equal(t, RoleAdmin, promoted.Role, "已存在的账号应该提升为管理员")
+
+	_, err = service.EnsureAdmin(ctx, &RegisterRequest{Username: "root", Password: "short"})
+	assert.Error(t, err, "无效的账号信息应该返回错误")
+}
+
+// TestUserService_ValidateRegisterRequest 测试注册请求验证
+func TestUserService_ValidateRegisterRequest(t *testing.T) {
+	service := newTestUserService()
+
+	testCases := []struct {
+		name        string
+		req         *RegisterRequest
+		expectError string
+	}{
+		{"空请求", nil, "注册请求不能为空"},
+		{"用户名过短", &RegisterRequest{Username: "ab", Password: "password123"}, "用户名"},
+		{"用户名包含非法字符", &RegisterRequest{Username: "bad name", Password: "password123"}, "用户名"},
+		{"密码过短", &RegisterRequest{Username: "alice", Password: "short"}, "密码长度"},
+		{"邮箱无效", &RegisterRequest{Username: "alice", Password: "password123", Email: "invalid"}, "邮箱"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := service.ValidateRegisterRequest(tc.req)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectError)
+		})
+	}
+
+	assert.NoError(t, service.ValidateRegisterRequest(&RegisterRequest{Username: "alice_01", Password: "password123"}))
+}
+
+// TestUserService_Authenticate 测试用户认证
+func TestUserService_Authenticate(t *testing.T) {
+	service := newTestUserService()
+	ctx := context.Background()
+
+	registered, err := service.Register(ctx, &RegisterRequest{Username: "alice", Password: "password123"})
+	require.NoError(t, err)
+
+	user, err := service.Authenticate(ctx, "Alice", "password123")
+	require.NoError(t, err, "正确的用户名和密码应该认证成功")
+	assert.Equal(t, registered.ID, user.ID)
+
+	_, err = service.Authenticate(ctx, "alice", "wrong-password")
+	assert.ErrorIs(t, err, ErrInvalidCredentials, "错误密码应该认证失败")
+
+	_, err = service.Authenticate(ctx, "nobody", "password123")
+	assert.ErrorIs(t, err, ErrInvalidCredentials, "不存在的用户应该返回相同错误")
+}
+
+// TestUserService_UpdateRole 测试角色更新
+func TestUserService_UpdateRole(t *testing.T) {
+	service := newTestUserService()
+	ctx := context.Background()
+
+	admin, err := service.EnsureAdmin(ctx, &RegisterRequest{Username: "admin", Password: "password123"})
+	require.NoError(t, err)
+	alice, err := service.Register(ctx, &RegisterRequest{Username: "alice", Password: "password123"})
+	require.NoError(t, err)
+
+	_, err = service.UpdateRole(ctx, admin.ID, RoleViewer)
+	assert.Error(t, err, "不能降级最后一个管理员")
+
+	updated, err := service.UpdateRole(ctx, alice.ID, RoleUploader)
+	require.NoError(t, err)
+	assert.Equal(t, RoleUploader, updated.Role)
+
+	fetched, err := service.GetUser(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, RoleUploader, fetched.Role, "角色应该被持久化")
+
+	_, err = service.UpdateRole(ctx, alice.ID, "superuser")
+	assert.Error(t, err, "无效角色应该返回错误")
+
+	_, err = service.UpdateRole(ctx, "missing", RoleViewer)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+// TestUserService_Queries 测试用户查询
+func TestUserService_Queries(t *testing.T) {
+	service := newTestUserService()
+	ctx := context.Background()
+
+	_, err := service.GetUser(ctx, "missing")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	_, err = service.Register(ctx, &RegisterRequest{Username: "admin", Password: "password123"})
+	require.NoError(t, err)
+	_, err = service.Register(ctx, &RegisterRequest{Username: "bob", Password: "password123"})
+	require.NoError(t, err)
+
+	bob, err := service.GetUserByUsername(ctx, "BOB")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", bob.Username)
+
+	// 修改返回值不应影响内部数据
+	bob.Role = RoleAdmin
+	fetched, err := service.GetUserByUsername(ctx, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, RoleViewer, fetched.Role)
+
+	users := service.ListUsers(ctx)
+	require.Len(t, users, 2)
+	assert.Equal(t, "admin", users[0].Username, "应该按创建时间升序")
+}
+
+// TestRoles 测试角色辅助函数
+func TestRoles(t *testing.T) {
+	assert.True(t, IsValidRole(RoleAdmin))
+	assert.True(t, IsValidRole(RoleUploader))
+	assert.True(t, IsValidRole(RoleViewer))
+	assert.False(t, IsValidRole("guest"))
+
+	assert.True(t, CanUpload(RoleAdmin))
+	assert.True(t, CanUpload(RoleUploader))
+	assert.False(t, CanUpload(RoleViewer))
+}
@@ -0,0 +1,50 @@
+package video
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxChapters 单个视频最多保留的章节数量
+const MaxChapters = 500
+
+// Chapter 视频章节
+type Chapter struct {
+	Title string        `json:"title"` // 章节标题
+	Start time.Duration `json:"start"` // 开始时间
+	End   time.Duration `json:"end"`   // 结束时间
+}
+
+// normalizeChapters 整理从容器中解析出的章节：按开始时间排序，去除超出时长的章节，
+// 未记录结束时间的章节以下一章节的开始时间或视频时长作为结束时间，时长未知时最后一个章节的结束时间为0
+func normalizeChapters(chapters []Chapter, duration time.Duration) []Chapter {
+	if len(chapters) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(chapters, func(i, j int) bool {
+		return chapters[i].Start < chapters[j].Start
+	})
+
+	result := make([]Chapter, 0, len(chapters))
+	for i, chapter := range chapters {
+		if len(result) >= MaxChapters {
+			break
+		}
+		if chapter.Start < 0 || (duration > 0 && chapter.Start >= duration) {
+			continue
+		}
+
+		chapter.Title = strings.TrimSpace(chapter.Title)
+		next := duration
+		if i+1 < len(chapters) && (duration == 0 || chapters[i+1].Start < duration) {
+			next = chapters[i+1].Start
+		}
+		if chapter.End <= chapter.Start || (next > chapter.Start && chapter.End > next) {
+			chapter.End = next
+		}
+		result = append(result, chapter)
+	}
+	return result
+}
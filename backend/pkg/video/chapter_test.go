@@ -0,0 +1,78 @@
+package video
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNormalizeChapters 测试整理容器中解析出的章节
+func TestNormalizeChapters(t *testing.T) {
+	testCases := []struct {
+		name     string
+		chapters []Chapter
+		duration time.Duration
+		expected []Chapter
+	}{
+		{
+			name:     "没有章节",
+			chapters: nil,
+			duration: 10 * time.Second,
+			expected: nil,
+		},
+		{
+			name: "补全结束时间",
+			chapters: []Chapter{
+				{Title: " 第二章 ", Start: 4 * time.Second},
+				{Title: "第一章", Start: 0},
+			},
+			duration: 10 * time.Second,
+			expected: []Chapter{
+				{Title: "第一章", Start: 0, End: 4 * time.Second},
+				{Title: "第二章", Start: 4 * time.Second, End: 10 * time.Second},
+			},
+		},
+		{
+			name: "结束时间与下一章节重叠",
+			chapters: []Chapter{
+				{Title: "第一章", Start: 0, End: 6 * time.Second},
+				{Title: "第二章", Start: 4 * time.Second, End: 8 * time.Second},
+			},
+			duration: 10 * time.Second,
+			expected: []Chapter{
+				{Title: "第一章", Start: 0, End: 4 * time.Second},
+				{Title: "第二章", Start: 4 * time.Second, End: 8 * time.Second},
+			},
+		},
+		{
+			name: "超出视频时长的章节",
+			chapters: []Chapter{
+				{Title: "第一章", Start: 0},
+				{Title: "片尾", Start: 12 * time.Second},
+			},
+			duration: 10 * time.Second,
+			expected: []Chapter{
+				{Title: "第一章", Start: 0, End: 10 * time.Second},
+			},
+		},
+		{
+			name: "时长未知",
+			chapters: []Chapter{
+				{Title: "第一章", Start: 0},
+				{Title: "第二章", Start: 4 * time.Second},
+			},
+			duration: 0,
+			expected: []Chapter{
+				{Title: "第一章", Start: 0, End: 4 * time.Second},
+				{Title: "第二章", Start: 4 * time.Second},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, normalizeChapters(tc.chapters, tc.duration))
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// ClipRequest 视频剪辑请求
+type ClipRequest struct {
+	InputPath  string        // 源视频文件
+	OutputPath string        // 输出文件，容器格式由扩展名决定
+	Start      time.Duration // 开始时间
+	End        time.Duration // 结束时间，必须大于开始时间
+	Reencode   bool          // 是否直接重新编码，起止时间精确到帧
+}
+
+// ClipResult 视频剪辑结果
+type ClipResult struct {
+	StreamCopy bool  // 是否使用流复制（起点对齐到之前的关键帧）
+	Size       int64 // 输出文件大小
+}
+
+// FFmpegClipper 基于FFmpeg的视频剪辑器
+// 默认先使用流复制，不重新编码、速度快但起点只能对齐到关键帧；编码与容器不兼容等原因失败时改为重新编码
+type FFmpegClipper struct {
+	ffmpegPath string
+	timeout    time.Duration
+}
+
+// NewFFmpegClipper 创建FFmpeg剪辑器
+func NewFFmpegClipper(ffmpegPath string) *FFmpegClipper {
+	if ffmpegPath == "" {
+		ffmpegPath = "ffmpeg"
+	}
+	return &FFmpegClipper{
+		ffmpegPath: ffmpegPath,
+		timeout:    30 * time.Minute,
+	}
+}
+
+// IsAvailable 检查FFmpeg是否可用
+func (c *FFmpegClipper) IsAvailable() bool {
+	if c == nil {
+		return false
+	}
+	_, err := exec.LookPath(c.ffmpegPath)
+	return err == nil
+}
+
+// Clip 截取源视频中开始时间到结束时间的片段写入输出文件
+func (c *FFmpegClipper) Clip(ctx context.Context, req *ClipRequest) (*ClipResult, error) {
+	if req == nil || req.InputPath == "" || req.OutputPath == "" {
+		return nil, fmt.Errorf("剪辑请求不能为空")
+	}
+	if req.Start < 0 || req.End <= req.Start {
+		return nil, fmt.Errorf("结束时间必须大于开始时间")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	if !req.Reencode {
+		if size, err := c.run(ctx, c.buildArgs(req, true), req.OutputPath); err == nil {
+			return &ClipResult{StreamCopy: true, Size: size}, nil
+		} else if ctx.Err() != nil {
+			return nil, err
+		}
+	}
+	size, err := c.run(ctx, c.buildArgs(req, false), req.OutputPath)
+	if err != nil {
+		return nil, err
+	}
+	return &ClipResult{Size: size}, nil
+}
+
+// run 执行FFmpeg并返回输出文件大小，没有生成内容时返回错误
+func (c *FFmpegClipper) run(ctx context.Context, args []string, outputPath string) (int64, error) {
+	cmd := exec.CommandContext(ctx, c.ffmpegPath, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() != nil {
+			return 0, fmt.Errorf("FFmpeg执行超时或已取消: %w", ctx.Err())
+		}
+		return 0, fmt.Errorf("FFmpeg执行失败: %w, 输出: %s", err, tailOutput(output, 512))
+	}
+	info, err := os.Stat(outputPath)
+	if err != nil {
+		return 0, fmt.Errorf("读取剪辑结果失败: %w", err)
+	}
+	if info.Size() == 0 {
+		return 0, fmt.Errorf("FFmpeg未生成任何内容")
+	}
+	return info.Size(), nil
+}
+
+// buildArgs 构建FFmpeg命令行参数，重新编码时使用输出容器的默认编码
+func (c *FFmpegClipper) buildArgs(req *ClipRequest, streamCopy bool) []string {
+	args := []string{
+		"-hide_banner", "-loglevel", "error", "-y",
+		// -ss放在-i之前进行快速定位，重新编码时仍精确到帧
+		"-ss", formatSeconds(req.Start),
+		"-i", req.InputPath,
+		"-t", formatSeconds(req.End - req.Start),
+	}
+	if streamCopy {
+		// 流复制的起点对齐到关键帧，时间戳归零避免播放器从负时间开始
+		args = append(args, "-c", "copy", "-avoid_negative_ts", "make_zero")
+	}
+	return append(args, req.OutputPath)
+}
+
+// formatSeconds 将时长格式化为FFmpeg使用的秒数，精确到毫秒
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+// tailOutput 截取输出的末尾部分，避免错误信息过长
+func tailOutput(output []byte, limit int) string {
+	if len(output) <= limit {
+		return string(output)
+	}
+	return string(output[len(output)-limit:])
+}
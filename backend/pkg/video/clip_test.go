@@ -0,0 +1,106 @@
+package video
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClipFFmpeg 创建模拟FFmpeg：记录参数并向最后一个参数写入内容，failCopy为true时流复制失败
+func fakeClipFFmpeg(t *testing.T, failCopy bool) (string, string) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "args.log")
+	script := "#!/bin/sh\necho \"$@\" >> " + logPath + "\nfor arg; do out=$arg; done\n"
+	if failCopy {
+		script += "case \" $* \" in *\" copy \"*) echo 'codec not supported' >&2; exit 1;; esac\n"
+	}
+	script += "printf clip > \"$out\"\n"
+	ffmpegPath := filepath.Join(dir, "ffmpeg")
+	require.NoError(t, os.WriteFile(ffmpegPath, []byte(script), 0o755))
+	return ffmpegPath, logPath
+}
+
+// readClipArgs 读取模拟FFmpeg每次执行的参数
+func readClipArgs(t *testing.T, logPath string) []string {
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+// TestFFmpegClipper_BuildArgs 测试流复制和重新编码的FFmpeg参数
+func TestFFmpegClipper_BuildArgs(t *testing.T) {
+	clipper := NewFFmpegClipper("")
+	assert.Equal(t, "ffmpeg", clipper.ffmpegPath, "默认应该使用PATH中的ffmpeg")
+
+	req := &ClipRequest{InputPath: "in.mp4", OutputPath: "out.mp4", Start: 1500 * time.Millisecond, End: 10 * time.Second}
+	assert.Equal(t, []string{
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-ss", "1.500", "-i", "in.mp4", "-t", "8.500",
+		"-c", "copy", "-avoid_negative_ts", "make_zero",
+		"out.mp4",
+	}, clipper.buildArgs(req, true))
+	assert.NotContains(t, clipper.buildArgs(req, false), "copy", "重新编码时使用输出容器的默认编码")
+}
+
+// TestFFmpegClipper_Clip 测试优先使用流复制，失败时改为重新编码
+func TestFFmpegClipper_Clip(t *testing.T) {
+	ctx := context.Background()
+	newRequest := func(t *testing.T) *ClipRequest {
+		return &ClipRequest{InputPath: "in.mp4", OutputPath: filepath.Join(t.TempDir(), "out.mp4"), Start: time.Second, End: 3 * time.Second}
+	}
+
+	t.Run("流复制", func(t *testing.T) {
+		ffmpegPath, logPath := fakeClipFFmpeg(t, false)
+		clipper := NewFFmpegClipper(ffmpegPath)
+		require.True(t, clipper.IsAvailable())
+
+		result, err := clipper.Clip(ctx, newRequest(t))
+		require.NoError(t, err)
+		assert.True(t, result.StreamCopy)
+		assert.Equal(t, int64(4), result.Size)
+		assert.Len(t, readClipArgs(t, logPath), 1)
+	})
+
+	t.Run("流复制失败时重新编码", func(t *testing.T) {
+		ffmpegPath, logPath := fakeClipFFmpeg(t, true)
+		result, err := NewFFmpegClipper(ffmpegPath).Clip(ctx, newRequest(t))
+		require.NoError(t, err)
+		assert.False(t, result.StreamCopy)
+		assert.Len(t, readClipArgs(t, logPath), 2)
+	})
+
+	t.Run("指定重新编码", func(t *testing.T) {
+		ffmpegPath, logPath := fakeClipFFmpeg(t, false)
+		req := newRequest(t)
+		req.Reencode = true
+		result, err := NewFFmpegClipper(ffmpegPath).Clip(ctx, req)
+		require.NoError(t, err)
+		assert.False(t, result.StreamCopy)
+		args := readClipArgs(t, logPath)
+		require.Len(t, args, 1)
+		assert.NotContains(t, args[0], "copy")
+	})
+
+	t.Run("参数无效", func(t *testing.T) {
+		clipper := NewFFmpegClipper("")
+		_, err := clipper.Clip(ctx, nil)
+		assert.Error(t, err)
+		req := newRequest(t)
+		req.End = req.Start
+		_, err = clipper.Clip(ctx, req)
+		assert.Error(t, err)
+	})
+
+	t.Run("FFmpeg执行失败", func(t *testing.T) {
+		failing := filepath.Join(t.TempDir(), "ffmpeg")
+		require.NoError(t, os.WriteFile(failing, []byte("#!/bin/sh\necho invalid data >&2\nexit 1\n"), 0o755))
+		_, err := NewFFmpegClipper(failing).Clip(ctx, newRequest(t))
+		assert.ErrorContains(t, err, "invalid data")
+	})
+}
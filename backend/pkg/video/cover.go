@@ -0,0 +1,114 @@
+package video
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+)
+
+const (
+	// maxCoverPixels 自定义封面图片的最大像素数，避免解码超大图片占用过多内存
+	maxCoverPixels = 8192 * 8192
+	// candidateFallbackInterval 视频时长未知时候选缩略图的截取间隔（秒）
+	candidateFallbackInterval = 5.0
+)
+
+// DefaultCandidatePercents 默认的候选缩略图截取位置（占视频时长的百分比）
+var DefaultCandidatePercents = []int{10, 25, 50, 75}
+
+// CandidateRequest 候选缩略图生成请求
+type CandidateRequest struct {
+	VideoPath string            `json:"video_path"` // 本地视频文件路径，截取多帧需要可重复读取的输入
+	Duration  float64           `json:"duration"`   // 视频时长（秒），未知时为0
+	Percents  []int             `json:"percents"`   // 截取位置（占视频时长的百分比），默认为DefaultCandidatePercents
+	Options   *ThumbnailOptions `json:"options"`    // 生成选项，时间偏移按截取位置计算
+}
+
+// GenerateCandidates 在视频时长的多个位置截取候选缩略图，结果与截取位置一一对应
+// 时长未知时按固定间隔截取，第一帧之后的位置没有视频帧时提前结束
+func (g *ThumbnailGenerator) GenerateCandidates(request *CandidateRequest) ([]*ThumbnailResult, error) {
+	if request.VideoPath == "" {
+		return nil, fmt.Errorf("视频文件路径为空")
+	}
+
+	percents := request.Percents
+	if len(percents) == 0 {
+		percents = DefaultCandidatePercents
+	}
+	for _, percent := range percents {
+		if percent < 0 || percent >= 100 {
+			return nil, fmt.Errorf("截取位置必须在0到99之间: %d", percent)
+		}
+	}
+
+	options := request.Options
+	if options == nil {
+		options = g.GetDefaultOptions()
+	}
+	if err := g.ValidateOptions(options); err != nil {
+		return nil, err
+	}
+
+	// 候选缩略图需要真实视频帧，模拟帧没有选择意义
+	if !g.CanExtractFrames() {
+		return nil, fmt.Errorf("视频帧提取器不可用")
+	}
+
+	results := make([]*ThumbnailResult, 0, len(percents))
+	for i, percent := range percents {
+		frameOptions := *options
+		frameOptions.TimeOffset = float64(i+1) * candidateFallbackInterval
+		if request.Duration > 0 {
+			frameOptions.TimeOffset = request.Duration * float64(percent) / 100
+		}
+
+		frame, err := g.extractFrameFromFile(request.VideoPath, &frameOptions)
+		if err != nil {
+			if i > 0 {
+				break
+			}
+			return nil, fmt.Errorf("提取视频帧失败: %w", err)
+		}
+		result, err := g.encodeThumbnail(resizeImage(frame, &frameOptions), &frameOptions)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// GenerateFromImage 将用户上传的封面图片（JPEG或PNG）按选项缩放并重新编码为缩略图
+func (g *ThumbnailGenerator) GenerateFromImage(data []byte, options *ThumbnailOptions) (*ThumbnailResult, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("图片数据为空")
+	}
+	if options == nil {
+		options = g.GetDefaultOptions()
+	}
+	if err := g.ValidateOptions(options); err != nil {
+		return nil, err
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("无法识别的图片格式: %v", err)
+	}
+	if format != "jpeg" && format != "png" {
+		return nil, fmt.Errorf("不支持的图片格式: %s，支持的格式: [jpeg png]", format)
+	}
+	if config.Width < g.minWidth || config.Height < g.minHeight {
+		return nil, fmt.Errorf("图片尺寸不能小于%dx%d", g.minWidth, g.minHeight)
+	}
+	if config.Width*config.Height > maxCoverPixels {
+		return nil, fmt.Errorf("图片尺寸过大: %dx%d", config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("解码图片失败: %v", err)
+	}
+
+	return g.encodeThumbnail(resizeImage(img, options), options)
+}
@@ -0,0 +1,148 @@
+package video
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeTestPNG 生成指定尺寸的纯色PNG图片
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 40, B: 40, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// TestThumbnailGenerator_GenerateFromImage 测试从自定义封面生成缩略图
+func TestThumbnailGenerator_GenerateFromImage(t *testing.T) {
+	generator := NewThumbnailGenerator()
+	options := &ThumbnailOptions{Width: 320, Height: 240, Quality: 80, Format: "jpeg", KeepAspect: true}
+
+	t.Run("等比缩小并编码为JPEG", func(t *testing.T) {
+		result, err := generator.GenerateFromImage(encodeTestPNG(t, 1280, 720), options)
+		require.NoError(t, err)
+		assert.Equal(t, 320, result.Width)
+		assert.Equal(t, 180, result.Height)
+		assert.Equal(t, "jpeg", result.Format)
+		assert.Equal(t, int64(len(result.ImageData)), result.FileSize)
+
+		img, err := jpeg.Decode(bytes.NewReader(result.ImageData))
+		require.NoError(t, err)
+		assert.Equal(t, 320, img.Bounds().Dx())
+		r, g, _, _ := img.At(160, 90).RGBA()
+		assert.Greater(t, r, g, "应该保留原图颜色")
+	})
+
+	t.Run("小图片等比放大", func(t *testing.T) {
+		result, err := generator.GenerateFromImage(encodeTestPNG(t, 100, 80), options)
+		require.NoError(t, err)
+		assert.Equal(t, 300, result.Width)
+		assert.Equal(t, 240, result.Height)
+	})
+
+	t.Run("按缩放模式输出目标尺寸", func(t *testing.T) {
+		for _, fit := range []string{FitContain, FitCover} {
+			fitOptions := *options
+			fitOptions.Fit = fit
+			result, err := generator.GenerateFromImage(encodeTestPNG(t, 1280, 720), &fitOptions)
+			require.NoError(t, err)
+			assert.Equal(t, 320, result.Width, fit)
+			assert.Equal(t, 240, result.Height, fit)
+		}
+
+		fitOptions := *options
+		fitOptions.Fit = "fill"
+		_, err := generator.GenerateFromImage(encodeTestPNG(t, 1280, 720), &fitOptions)
+		assert.ErrorContains(t, err, "不支持的缩放模式")
+	})
+
+	t.Run("不保持宽高比时拉伸到目标尺寸", func(t *testing.T) {
+		stretch := *options
+		stretch.KeepAspect = false
+		result, err := generator.GenerateFromImage(encodeTestPNG(t, 1280, 720), &stretch)
+		require.NoError(t, err)
+		assert.Equal(t, 320, result.Width)
+		assert.Equal(t, 240, result.Height)
+	})
+
+	t.Run("无效图片", func(t *testing.T) {
+		_, err := generator.GenerateFromImage(nil, options)
+		assert.Error(t, err)
+
+		_, err = generator.GenerateFromImage([]byte("not an image"), options)
+		assert.Error(t, err)
+
+		_, err = generator.GenerateFromImage(encodeTestPNG(t, 32, 32), options)
+		assert.ErrorContains(t, err, "图片尺寸不能小于64x64")
+	})
+}
+
+// TestThumbnailGenerator_GenerateCandidates 测试截取候选缩略图
+func TestThumbnailGenerator_GenerateCandidates(t *testing.T) {
+	videoPath := createSpriteTestVideo(t)
+	options := &ThumbnailOptions{Width: 320, Height: 240, Quality: 80, Format: "jpeg", KeepAspect: true}
+
+	t.Run("按时长百分比截帧", func(t *testing.T) {
+		extractor := &recordingFrameExtractor{color: color.RGBA{0, 255, 0, 255}}
+		generator := NewThumbnailGenerator()
+		generator.SetFrameExtractor(extractor)
+
+		results, err := generator.GenerateCandidates(&CandidateRequest{VideoPath: videoPath, Duration: 200, Options: options})
+		require.NoError(t, err)
+		require.Len(t, results, 4)
+		assert.Equal(t, []float64{20, 50, 100, 150}, extractor.offsets)
+		for i, result := range results {
+			assert.Equal(t, extractor.offsets[i], result.TimeOffset)
+			assert.Equal(t, 320, result.Width)
+			assert.Equal(t, 180, result.Height)
+			_, err := jpeg.Decode(bytes.NewReader(result.ImageData))
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("时长未知时按固定间隔截帧", func(t *testing.T) {
+		extractor := &recordingFrameExtractor{color: color.Black, failAt: 3}
+		generator := NewThumbnailGenerator()
+		generator.SetFrameExtractor(extractor)
+
+		results, err := generator.GenerateCandidates(&CandidateRequest{VideoPath: videoPath, Options: options})
+		require.NoError(t, err)
+		assert.Len(t, results, 2, "后续位置没有视频帧时应该提前结束")
+		assert.Equal(t, []float64{5, 10, 15}, extractor.offsets)
+	})
+
+	t.Run("第一帧提取失败", func(t *testing.T) {
+		generator := NewThumbnailGenerator()
+		generator.SetFrameExtractor(&recordingFrameExtractor{color: color.Black, failAt: 1})
+
+		_, err := generator.GenerateCandidates(&CandidateRequest{VideoPath: videoPath, Options: options})
+		assert.ErrorContains(t, err, "提取视频帧失败")
+	})
+
+	t.Run("无效参数", func(t *testing.T) {
+		generator := NewThumbnailGenerator()
+		generator.SetFrameExtractor(&recordingFrameExtractor{color: color.Black})
+
+		_, err := generator.GenerateCandidates(&CandidateRequest{Options: options})
+		assert.Error(t, err)
+
+		_, err = generator.GenerateCandidates(&CandidateRequest{VideoPath: videoPath, Percents: []int{100}, Options: options})
+		assert.Error(t, err)
+
+		generator.SetFrameExtractor(nil)
+		_, err = generator.GenerateCandidates(&CandidateRequest{VideoPath: videoPath, Options: options})
+		assert.ErrorContains(t, err, "视频帧提取器不可用")
+	})
+}
@@ -0,0 +1,470 @@
+package video
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// EBML/Matroska元素ID
+const (
+	ebmlIDHeader          = 0x1A45DFA3
+	ebmlIDDocType         = 0x4282
+	ebmlIDSegment         = 0x18538067
+	ebmlIDInfo            = 0x1549A966
+	ebmlIDTimecodeScale   = 0x2AD7B1
+	ebmlIDDuration        = 0x4489
+	ebmlIDTracks          = 0x1654AE6B
+	ebmlIDTrackEntry      = 0xAE
+	ebmlIDTrackType       = 0x83
+	ebmlIDCodecID         = 0x86
+	ebmlIDDefaultDuration = 0x23E383
+	ebmlIDVideo           = 0xE0
+	ebmlIDPixelWidth      = 0xB0
+	ebmlIDPixelHeight     = 0xBA
+	ebmlIDCluster         = 0x1F43B675
+
+	ebmlIDChapters           = 0x1043A770
+	ebmlIDEditionEntry       = 0x45B9
+	ebmlIDEditionFlagDefault = 0x45DB
+	ebmlIDChapterAtom        = 0xB6
+	ebmlIDChapterTimeStart   = 0x91
+	ebmlIDChapterTimeEnd     = 0x92
+	ebmlIDChapterFlagHidden  = 0x98
+	ebmlIDChapterDisplay     = 0x80
+	ebmlIDChapString         = 0x85
+)
+
+const (
+	// ebmlMaxElementSize Info/Tracks元素最大读取大小，防止异常文件占用过多内存
+	ebmlMaxElementSize = 16 * 1024 * 1024
+	// ebmlMaxDepth 元素最大嵌套层级
+	ebmlMaxDepth = 8
+	// ebmlDefaultTimecodeScale 默认时间刻度（纳秒）
+	ebmlDefaultTimecodeScale = 1000000
+
+	// Matroska轨道类型
+	ebmlTrackTypeVideo = 1
+	ebmlTrackTypeAudio = 2
+)
+
+// errEBMLStop 提前结束遍历
+var errEBMLStop = errors.New("停止遍历")
+
+// ebmlVideoCodecs Matroska视频CodecID与编码名称映射
+var ebmlVideoCodecs = map[string]string{
+	"V_MPEG4/ISO/AVC":  "H.264",
+	"V_MPEGH/ISO/HEVC": "H.265",
+	"V_VP8":            "VP8",
+	"V_VP9":            "VP9",
+	"V_AV1":            "AV1",
+	"V_THEORA":         "Theora",
+	"V_MPEG4/ISO/ASP":  "MPEG-4",
+}
+
+// ebmlAudioCodecs Matroska音频CodecID与编码名称映射
+var ebmlAudioCodecs = map[string]string{
+	"A_AAC":     "AAC",
+	"A_OPUS":    "Opus",
+	"A_VORBIS":  "Vorbis",
+	"A_AC3":     "AC-3",
+	"A_EAC3":    "E-AC-3",
+	"A_MPEG/L3": "MP3",
+	"A_FLAC":    "FLAC",
+}
+
+// ebmlElement EBML元素位置信息
+type ebmlElement struct {
+	ID     uint32
+	Offset int64 // 内容起始位置（不含元素头）
+	Size   int64 // 内容大小（不含元素头）
+}
+
+// ebmlTrack 解析中的轨道信息
+type ebmlTrack struct {
+	trackType       uint64
+	codecID         string
+	width           int
+	height          int
+	defaultDuration uint64 // 每帧时长（纳秒）
+}
+
+// ebmlParser Matroska解析器，解析Segment中的Info、Tracks和Chapters
+type ebmlParser struct {
+	timecodeScale uint64
+	duration      float64 // 以timecodeScale为单位
+	tracks        []*ebmlTrack
+	chapters      []Chapter
+}
+
+// parseEBMLInfo 解析WebM/MKV文件的时长、分辨率、编码、码率、帧率和章节
+func parseEBMLInfo(reader io.ReaderAt, size int64, info *VideoInfo) error {
+	segment, err := findEBMLSegment(reader, size)
+	if err != nil {
+		return err
+	}
+
+	parser := &ebmlParser{timecodeScale: ebmlDefaultTimecodeScale}
+	var foundInfo, foundTracks, foundChapters bool
+	err = walkEBMLElements(reader, segment.Offset, segment.Offset+segment.Size, func(element *ebmlElement) error {
+		switch element.ID {
+		case ebmlIDInfo, ebmlIDTracks, ebmlIDChapters:
+			data, err := readEBMLPayload(reader, element)
+			if err != nil {
+				return err
+			}
+			switch element.ID {
+			case ebmlIDInfo:
+				foundInfo = true
+				return parser.parseInfo(data)
+			case ebmlIDTracks:
+				foundTracks = true
+				return parser.parseTracks(data)
+			}
+			foundChapters = true
+			return parser.parseChapters(data)
+		case ebmlIDCluster:
+			// Info、Tracks和Chapters位于Cluster之前，无需逐个跳过媒体数据
+			return errEBMLStop
+		}
+		if foundInfo && foundTracks && foundChapters {
+			return errEBMLStop
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errEBMLStop) {
+		return err
+	}
+	if !foundInfo && !foundTracks {
+		return fmt.Errorf("未找到Segment Info和Tracks元素")
+	}
+
+	parser.fill(info, size)
+	return nil
+}
+
+// parseEBMLDocType 解析EBML头中的DocType，如webm或matroska
+func parseEBMLDocType(data []byte) string {
+	var docType string
+	walkEBMLElements(bytes.NewReader(data), 0, int64(len(data)), func(element *ebmlElement) error {
+		if element.ID != ebmlIDHeader {
+			return errEBMLStop
+		}
+		payload := data[element.Offset : element.Offset+element.Size]
+		walkEBMLElements(bytes.NewReader(payload), 0, int64(len(payload)), func(child *ebmlElement) error {
+			if child.ID == ebmlIDDocType {
+				docType = strings.TrimRight(string(payload[child.Offset:child.Offset+child.Size]), "\x00")
+				return errEBMLStop
+			}
+			return nil
+		})
+		return errEBMLStop
+	})
+	return docType
+}
+
+// findEBMLSegment 查找顶层Segment元素
+func findEBMLSegment(reader io.ReaderAt, size int64) (*ebmlElement, error) {
+	var segment *ebmlElement
+	err := walkEBMLElements(reader, 0, size, func(element *ebmlElement) error {
+		if element.ID == ebmlIDSegment {
+			segment = element
+			return errEBMLStop
+		}
+		return nil
+	})
+	if segment != nil {
+		return segment, nil
+	}
+	if err != nil && !errors.Is(err, errEBMLStop) {
+		return nil, err
+	}
+	return nil, fmt.Errorf("未找到Segment元素")
+}
+
+// walkEBMLElements 遍历[start, end)范围内的同级元素
+// 未知大小的元素视为延伸到范围末尾，处理后结束遍历
+func walkEBMLElements(reader io.ReaderAt, start, end int64, fn func(element *ebmlElement) error) error {
+	header := make([]byte, 12)
+	for offset := start; offset < end; {
+		n, err := reader.ReadAt(header[:min64(int64(len(header)), end-offset)], offset)
+		if n == 0 {
+			if err == nil || err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("读取元素头失败: %w", err)
+		}
+
+		id, idLength, ok := readEBMLVint(header[:n], true)
+		if !ok || idLength > 4 {
+			return fmt.Errorf("无效的元素ID")
+		}
+		size, sizeLength, ok := readEBMLVint(header[idLength:n], false)
+		if !ok {
+			return fmt.Errorf("无效的元素大小")
+		}
+
+		dataOffset := offset + int64(idLength+sizeLength)
+		unknownSize := size == ebmlUnknownSize(sizeLength)
+		element := &ebmlElement{
+			ID:     uint32(id),
+			Offset: dataOffset,
+			Size:   end - dataOffset,
+		}
+		if !unknownSize && size <= uint64(end-dataOffset) {
+			element.Size = int64(size)
+		}
+		if err := fn(element); err != nil {
+			return err
+		}
+		if unknownSize {
+			return nil
+		}
+		offset = element.Offset + element.Size
+	}
+	return nil
+}
+
+// readEBMLVint 读取变长整数，返回值和占用字节数
+// 元素ID保留长度标记位，元素大小去掉长度标记位
+func readEBMLVint(data []byte, keepMarker bool) (uint64, int, bool) {
+	if len(data) == 0 || data[0] == 0 {
+		return 0, 0, false
+	}
+
+	length := 1
+	for mask := byte(0x80); data[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if len(data) < length {
+		return 0, 0, false
+	}
+
+	value := uint64(data[0])
+	if !keepMarker {
+		value &= uint64(0xFF >> length)
+	}
+	for i := 1; i < length; i++ {
+		value = value<<8 | uint64(data[i])
+	}
+	return value, length, true
+}
+
+// ebmlUnknownSize 返回指定长度下表示未知大小的值（所有数据位均为1）
+func ebmlUnknownSize(length int) uint64 {
+	return 1<<(7*uint(length)) - 1
+}
+
+// readEBMLPayload 读取元素内容
+func readEBMLPayload(reader io.ReaderAt, element *ebmlElement) ([]byte, error) {
+	if element.Size > ebmlMaxElementSize {
+		return nil, fmt.Errorf("元素过大: %d字节", element.Size)
+	}
+
+	data := make([]byte, element.Size)
+	n, err := reader.ReadAt(data, element.Offset)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("读取元素失败: %w", err)
+	}
+	return data[:n], nil
+}
+
+// walkEBMLPayload 遍历内存中元素内容的子元素
+func walkEBMLPayload(data []byte, fn func(id uint32, payload []byte) error) error {
+	return walkEBMLElements(bytes.NewReader(data), 0, int64(len(data)), func(element *ebmlElement) error {
+		return fn(element.ID, data[element.Offset:element.Offset+element.Size])
+	})
+}
+
+// parseInfo 解析Segment Info中的时间刻度和时长
+func (p *ebmlParser) parseInfo(data []byte) error {
+	return walkEBMLPayload(data, func(id uint32, payload []byte) error {
+		switch id {
+		case ebmlIDTimecodeScale:
+			if scale := ebmlUint(payload); scale > 0 {
+				p.timecodeScale = scale
+			}
+		case ebmlIDDuration:
+			p.duration = ebmlFloat(payload)
+		}
+		return nil
+	})
+}
+
+// parseTracks 解析Tracks中的轨道条目
+func (p *ebmlParser) parseTracks(data []byte) error {
+	return walkEBMLPayload(data, func(id uint32, payload []byte) error {
+		if id != ebmlIDTrackEntry {
+			return nil
+		}
+		track := &ebmlTrack{}
+		p.tracks = append(p.tracks, track)
+		return p.parseTrackEntry(track, payload, 1)
+	})
+}
+
+// parseTrackEntry 解析轨道条目，Video子元素中记录像素宽高
+func (p *ebmlParser) parseTrackEntry(track *ebmlTrack, data []byte, depth int) error {
+	if depth > ebmlMaxDepth {
+		return fmt.Errorf("元素嵌套层级过深")
+	}
+
+	return walkEBMLPayload(data, func(id uint32, payload []byte) error {
+		switch id {
+		case ebmlIDTrackType:
+			track.trackType = ebmlUint(payload)
+		case ebmlIDCodecID:
+			track.codecID = strings.TrimRight(string(payload), "\x00")
+		case ebmlIDDefaultDuration:
+			track.defaultDuration = ebmlUint(payload)
+		case ebmlIDVideo:
+			return p.parseTrackEntry(track, payload, depth+1)
+		case ebmlIDPixelWidth:
+			track.width = int(ebmlUint(payload))
+		case ebmlIDPixelHeight:
+			track.height = int(ebmlUint(payload))
+		}
+		return nil
+	})
+}
+
+// parseChapters 解析Chapters中默认版本（未标记时为第一个版本）的顶层章节，隐藏的章节会被忽略
+func (p *ebmlParser) parseChapters(data []byte) error {
+	var selected []byte
+	err := walkEBMLPayload(data, func(id uint32, payload []byte) error {
+		if id != ebmlIDEditionEntry {
+			return nil
+		}
+		if selected == nil {
+			selected = payload
+		}
+		return walkEBMLPayload(payload, func(childID uint32, child []byte) error {
+			if childID == ebmlIDEditionFlagDefault && ebmlUint(child) == 1 {
+				selected = payload
+				return errEBMLStop
+			}
+			return nil
+		})
+	})
+	if err != nil && !errors.Is(err, errEBMLStop) {
+		return err
+	}
+	if selected == nil {
+		return nil
+	}
+
+	return walkEBMLPayload(selected, func(id uint32, payload []byte) error {
+		if id != ebmlIDChapterAtom {
+			return nil
+		}
+		chapter, hidden, err := parseEBMLChapterAtom(payload)
+		if err != nil {
+			return err
+		}
+		if !hidden {
+			p.chapters = append(p.chapters, chapter)
+		}
+		return nil
+	})
+}
+
+// parseEBMLChapterAtom 解析章节条目，时间单位为纳秒，标题取第一个ChapterDisplay
+func parseEBMLChapterAtom(data []byte) (Chapter, bool, error) {
+	var chapter Chapter
+	var hidden, hasTitle bool
+	err := walkEBMLPayload(data, func(id uint32, payload []byte) error {
+		switch id {
+		case ebmlIDChapterTimeStart:
+			chapter.Start = time.Duration(ebmlUint(payload))
+		case ebmlIDChapterTimeEnd:
+			chapter.End = time.Duration(ebmlUint(payload))
+		case ebmlIDChapterFlagHidden:
+			hidden = ebmlUint(payload) == 1
+		case ebmlIDChapterDisplay:
+			if hasTitle {
+				return nil
+			}
+			return walkEBMLPayload(payload, func(childID uint32, child []byte) error {
+				if childID == ebmlIDChapString {
+					chapter.Title = strings.TrimRight(string(child), "\x00")
+					hasTitle = true
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return chapter, hidden, err
+}
+
+// fill 将解析结果写入视频信息
+func (p *ebmlParser) fill(info *VideoInfo, fileSize int64) {
+	if p.duration > 0 {
+		info.Duration = time.Duration(p.duration * float64(p.timecodeScale))
+	}
+	info.Chapters = p.chapters
+
+	for _, track := range p.tracks {
+		switch track.trackType {
+		case ebmlTrackTypeVideo:
+			if info.VideoCodec != "" {
+				continue
+			}
+			info.VideoCodec = ebmlCodecName(ebmlVideoCodecs, track.codecID)
+			info.Width, info.Height = track.width, track.height
+			if track.defaultDuration > 0 {
+				info.FrameRate = float64(time.Second) / float64(track.defaultDuration)
+			}
+		case ebmlTrackTypeAudio:
+			if info.AudioCodec == "" {
+				info.AudioCodec = ebmlCodecName(ebmlAudioCodecs, track.codecID)
+			}
+		}
+	}
+
+	// Matroska没有样本大小表，使用文件大小估算码率
+	if seconds := info.Duration.Seconds(); seconds > 0 {
+		info.Bitrate = int64(float64(fileSize*8) / seconds)
+	}
+}
+
+// ebmlUint 解析无符号整数元素
+func ebmlUint(payload []byte) uint64 {
+	if len(payload) > 8 {
+		return 0
+	}
+	var value uint64
+	for _, b := range payload {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+// ebmlFloat 解析浮点元素（4或8字节）
+func ebmlFloat(payload []byte) float64 {
+	switch len(payload) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(payload)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(payload))
+	}
+	return 0
+}
+
+// ebmlCodecName 将CodecID转换为编码名称，AAC等带配置后缀的ID按前缀匹配，未知编码保留原始标识
+func ebmlCodecName(codecs map[string]string, codecID string) string {
+	if name, ok := codecs[codecID]; ok {
+		return name
+	}
+	if prefix, _, found := strings.Cut(codecID, "/"); found {
+		if name, ok := codecs[prefix]; ok {
+			return name
+		}
+	}
+	return codecID
+}
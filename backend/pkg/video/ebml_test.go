@@ -0,0 +1,245 @@
+package video
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ebmlTestElement 构造EBML元素，大小统一使用8字节编码
+func ebmlTestElement(id uint32, children ...[]byte) []byte {
+	payload := bytes.Join(children, nil)
+
+	var idBytes []byte
+	for shift := 24; shift >= 0; shift -= 8 {
+		if b := byte(id >> shift); b != 0 || len(idBytes) > 0 {
+			idBytes = append(idBytes, b)
+		}
+	}
+
+	size := make([]byte, 8)
+	binary.BigEndian.PutUint64(size, uint64(len(payload)))
+	size[0] = 0x01
+
+	return bytes.Join([][]byte{idBytes, size, payload}, nil)
+}
+
+// ebmlTestUint 构造无符号整数元素
+func ebmlTestUint(id uint32, value uint64) []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, value)
+	return ebmlTestElement(id, data)
+}
+
+// ebmlTestFloat 构造8字节浮点元素
+func ebmlTestFloat(id uint32, value float64) []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, math.Float64bits(value))
+	return ebmlTestElement(id, data)
+}
+
+// ebmlTestTrack 构造轨道条目
+func ebmlTestTrack(trackType uint64, codecID string, width, height, defaultDuration uint64) []byte {
+	children := [][]byte{
+		ebmlTestUint(ebmlIDTrackType, trackType),
+		ebmlTestElement(ebmlIDCodecID, []byte(codecID)),
+	}
+	if defaultDuration > 0 {
+		children = append(children, ebmlTestUint(ebmlIDDefaultDuration, defaultDuration))
+	}
+	if width > 0 {
+		children = append(children, ebmlTestElement(ebmlIDVideo,
+			ebmlTestUint(ebmlIDPixelWidth, width),
+			ebmlTestUint(ebmlIDPixelHeight, height),
+		))
+	}
+	return ebmlTestElement(ebmlIDTrackEntry, children...)
+}
+
+// createTestEBML 构造包含视频轨和音频轨的10秒WebM/MKV
+func createTestEBML(docType, videoCodec, audioCodec string) []byte {
+	header := ebmlTestElement(ebmlIDHeader, ebmlTestElement(ebmlIDDocType, []byte(docType)))
+	segment := ebmlTestElement(ebmlIDSegment,
+		ebmlTestElement(ebmlIDInfo,
+			ebmlTestUint(ebmlIDTimecodeScale, 1000000),
+			ebmlTestFloat(ebmlIDDuration, 10000),
+		),
+		ebmlTestElement(ebmlIDTracks,
+			ebmlTestTrack(ebmlTrackTypeVideo, videoCodec, 1280, 720, 40000000),
+			ebmlTestTrack(ebmlTrackTypeAudio, audioCodec, 0, 0, 0),
+		),
+		ebmlTestElement(ebmlIDCluster, make([]byte, 4096)),
+	)
+	return append(header, segment...)
+}
+
+// TestVideoInfoExtractor_WebM 测试解析EBML结构中的Segment Info和Tracks
+func TestVideoInfoExtractor_WebM(t *testing.T) {
+	extractor := NewVideoInfoExtractor()
+
+	t.Run("WebM文件", func(t *testing.T) {
+		data := createTestEBML("webm", "V_VP9", "A_OPUS")
+
+		info, err := extractor.ExtractInfo(&InfoExtractionRequest{Data: data, Filename: "test.webm"})
+		require.NoError(t, err)
+		assert.Equal(t, "webm", info.Format)
+		assert.Equal(t, 10*time.Second, info.Duration)
+		assert.Equal(t, 1280, info.Width)
+		assert.Equal(t, 720, info.Height)
+		assert.Equal(t, "VP9", info.VideoCodec)
+		assert.Equal(t, "Opus", info.AudioCodec)
+		assert.InDelta(t, 25.0, info.FrameRate, 0.001)
+		assert.Equal(t, int64(len(data)*8/10), info.Bitrate)
+		assert.Equal(t, "00:10", info.DurationFormatted)
+	})
+
+	t.Run("MKV文件", func(t *testing.T) {
+		data := createTestEBML("matroska", "V_MPEG4/ISO/AVC", "A_AAC/MPEG4/LC")
+
+		info, err := extractor.ExtractInfo(&InfoExtractionRequest{
+			Data:     data[:64],
+			Filename: "test.mkv",
+			Reader:   bytes.NewReader(data),
+			Size:     int64(len(data)),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "mkv", info.Format)
+		assert.Equal(t, 10*time.Second, info.Duration)
+		assert.Equal(t, "H.264", info.VideoCodec)
+		assert.Equal(t, "AAC", info.AudioCodec, "带配置后缀的CodecID应该按前缀匹配")
+		assert.Equal(t, "1280x720", info.ResolutionFormatted)
+	})
+
+	t.Run("未知大小的Segment和自定义时间刻度", func(t *testing.T) {
+		header := ebmlTestElement(ebmlIDHeader, ebmlTestElement(ebmlIDDocType, []byte("webm")))
+		body := bytes.Join([][]byte{
+			ebmlTestElement(ebmlIDInfo,
+				ebmlTestUint(ebmlIDTimecodeScale, 1000),
+				ebmlTestFloat(ebmlIDDuration, 3500000),
+			),
+			ebmlTestElement(ebmlIDTracks, ebmlTestTrack(ebmlTrackTypeVideo, "V_AV1", 640, 360, 0)),
+		}, nil)
+		// 直播录制的Segment使用全1表示未知大小
+		segment := append([]byte{0x18, 0x53, 0x80, 0x67, 0xFF}, body...)
+
+		info, err := extractor.ExtractInfo(&InfoExtractionRequest{Data: append(header, segment...), Filename: "live.webm"})
+		require.NoError(t, err)
+		assert.Equal(t, 3500*time.Millisecond, info.Duration)
+		assert.Equal(t, "AV1", info.VideoCodec)
+		assert.Equal(t, 640, info.Width)
+		assert.Zero(t, info.FrameRate, "缺少DefaultDuration时不计算帧率")
+	})
+
+	t.Run("章节", func(t *testing.T) {
+		chapter := func(start uint64, title string, hidden bool) []byte {
+			children := [][]byte{
+				ebmlTestUint(ebmlIDChapterTimeStart, start),
+				ebmlTestElement(ebmlIDChapterDisplay, ebmlTestElement(ebmlIDChapString, []byte(title))),
+			}
+			if hidden {
+				children = append(children, ebmlTestUint(ebmlIDChapterFlagHidden, 1))
+			}
+			return ebmlTestElement(ebmlIDChapterAtom, children...)
+		}
+
+		header := ebmlTestElement(ebmlIDHeader, ebmlTestElement(ebmlIDDocType, []byte("matroska")))
+		segment := ebmlTestElement(ebmlIDSegment,
+			ebmlTestElement(ebmlIDInfo, ebmlTestFloat(ebmlIDDuration, 10000)),
+			ebmlTestElement(ebmlIDTracks, ebmlTestTrack(ebmlTrackTypeVideo, "V_VP9", 1280, 720, 0)),
+			ebmlTestElement(ebmlIDChapters,
+				ebmlTestElement(ebmlIDEditionEntry, chapter(0, "其他版本", false)),
+				ebmlTestElement(ebmlIDEditionEntry,
+					ebmlTestUint(ebmlIDEditionFlagDefault, 1),
+					chapter(uint64(2*time.Second), "正片", false),
+					chapter(uint64(5*time.Second), "隐藏章节", true),
+					chapter(0, "开场", false),
+				),
+			),
+			ebmlTestElement(ebmlIDCluster, make([]byte, 1024)),
+		)
+
+		info, err := extractor.ExtractInfo(&InfoExtractionRequest{Data: append(header, segment...), Filename: "test.mkv"})
+		require.NoError(t, err)
+		assert.Equal(t, []Chapter{
+			{Title: "开场", Start: 0, End: 2 * time.Second},
+			{Title: "正片", Start: 2 * time.Second, End: 10 * time.Second},
+		}, info.Chapters, "应该使用默认版本的章节，按开始时间排序并忽略隐藏章节")
+	})
+
+	t.Run("截断或损坏的文件不应该panic", func(t *testing.T) {
+		data := createTestEBML("webm", "V_VP8", "A_VORBIS")
+		for _, size := range []int{12, 30, 60, 100, 200} {
+			info, err := extractor.ExtractInfo(&InfoExtractionRequest{Data: data[:size], Filename: "test.webm"})
+			require.NoError(t, err)
+			assert.NotNil(t, info)
+		}
+
+		// EBML头占26字节，之后为Segment的ID和大小
+		corrupted := append([]byte{}, data...)
+		corrupted[30] = 0x00
+		info, err := extractor.ExtractInfo(&InfoExtractionRequest{Data: corrupted, Filename: "test.webm"})
+		require.NoError(t, err, "解析失败不应该导致信息提取失败")
+		assert.Zero(t, info.Duration)
+	})
+}
+
+// TestReadEBMLVint 测试变长整数解析
+func TestReadEBMLVint(t *testing.T) {
+	value, length, ok := readEBMLVint([]byte{0x1A, 0x45, 0xDF, 0xA3}, true)
+	require.True(t, ok)
+	assert.Equal(t, uint64(ebmlIDHeader), value, "元素ID应该保留长度标记位")
+	assert.Equal(t, 4, length)
+
+	value, length, ok = readEBMLVint([]byte{0x40, 0x02}, false)
+	require.True(t, ok)
+	assert.Equal(t, uint64(2), value, "元素大小应该去掉长度标记位")
+	assert.Equal(t, 2, length)
+
+	value, length, ok = readEBMLVint([]byte{0xFF}, false)
+	require.True(t, ok)
+	assert.Equal(t, ebmlUnknownSize(length), value, "全1表示未知大小")
+
+	_, _, ok = readEBMLVint([]byte{0x00, 0x01}, false)
+	assert.False(t, ok, "首字节为0是无效的变长整数")
+
+	_, _, ok = readEBMLVint([]byte{0x20, 0x01}, false)
+	assert.False(t, ok, "数据不足时应该失败")
+}
+
+// TestVideoInfoExtractor_RealWebM 测试解析FFmpeg生成的真实视频（需要FFmpeg）
+func TestVideoInfoExtractor_RealWebM(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("跳过测试：FFmpeg不可用")
+	}
+
+	inputPath := filepath.Join(t.TempDir(), "input.mkv")
+	cmd := exec.Command("ffmpeg", "-hide_banner", "-loglevel", "error", "-y",
+		"-f", "lavfi", "-i", "testsrc=duration=2:size=640x360:rate=25",
+		"-c:v", "libx264", inputPath)
+	require.NoError(t, cmd.Run(), "生成测试视频应该成功")
+
+	data, err := os.ReadFile(inputPath)
+	require.NoError(t, err)
+
+	info, err := NewVideoInfoExtractor().ExtractInfo(&InfoExtractionRequest{
+		Data:     data[:min(len(data), 512)],
+		Filename: "input.mkv",
+		Reader:   bytes.NewReader(data),
+		Size:     int64(len(data)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "mkv", info.Format)
+	assert.Equal(t, 640, info.Width)
+	assert.Equal(t, 360, info.Height)
+	assert.Equal(t, "H.264", info.VideoCodec)
+	assert.InDelta(t, 2.0, info.Duration.Seconds(), 0.1)
+	assert.InDelta(t, 25.0, info.FrameRate, 0.1)
+}
@@ -0,0 +1,335 @@
+package video
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	flvHeaderSize    = 9
+	flvTagHeaderSize = 11
+
+	// FLV tag类型
+	flvTagAudio  = 8
+	flvTagVideo  = 9
+	flvTagScript = 18
+
+	// flvMaxScriptSize 脚本tag最大读取大小
+	flvMaxScriptSize = 1024 * 1024
+	// flvMaxTags 查找元数据和音视频编码时最多检查的tag数
+	flvMaxTags = 256
+	// amf0MaxDepth AMF0对象最大嵌套层级
+	amf0MaxDepth = 8
+)
+
+// AMF0数据类型
+const (
+	amf0Number      = 0x00
+	amf0Boolean     = 0x01
+	amf0String      = 0x02
+	amf0Object      = 0x03
+	amf0Null        = 0x05
+	amf0Undefined   = 0x06
+	amf0ECMAArray   = 0x08
+	amf0ObjectEnd   = 0x09
+	amf0StrictArray = 0x0A
+	amf0Date        = 0x0B
+	amf0LongString  = 0x0C
+)
+
+// flvVideoCodecs FLV视频tag中的CodecID与编码名称映射
+var flvVideoCodecs = map[byte]string{
+	2:  "H.263",
+	3:  "Screen Video",
+	4:  "VP6",
+	5:  "VP6",
+	6:  "Screen Video 2",
+	7:  "H.264",
+	12: "H.265",
+}
+
+// flvAudioCodecs FLV音频tag中的SoundFormat与编码名称映射
+var flvAudioCodecs = map[byte]string{
+	0:  "PCM",
+	1:  "ADPCM",
+	2:  "MP3",
+	3:  "PCM",
+	4:  "Nellymoser",
+	5:  "Nellymoser",
+	6:  "Nellymoser",
+	7:  "G.711",
+	8:  "G.711",
+	10: "AAC",
+	11: "Speex",
+	14: "MP3",
+}
+
+// flvParser FLV解析器，解析onMetaData和音视频tag头
+type flvParser struct {
+	metadata   map[string]any
+	videoCodec string
+	audioCodec string
+}
+
+// parseFLVInfo 解析FLV文件的时长、分辨率、编码、码率和帧率
+func parseFLVInfo(reader io.ReaderAt, size int64, info *VideoInfo) error {
+	header := make([]byte, flvHeaderSize)
+	if _, err := reader.ReadAt(header, 0); err != nil {
+		return fmt.Errorf("读取FLV文件头失败: %w", err)
+	}
+	if string(header[0:3]) != "FLV" {
+		return fmt.Errorf("无效的FLV文件头")
+	}
+
+	parser := &flvParser{}
+	// 文件头之后是4字节的PreviousTagSize0
+	offset := int64(binary.BigEndian.Uint32(header[5:9])) + 4
+	// tag头之后读取5字节数据：音视频编码标识及扩展格式的FourCC
+	tagHeader := make([]byte, flvTagHeaderSize+5)
+	for i := 0; i < flvMaxTags && offset+flvTagHeaderSize <= size; i++ {
+		n, _ := reader.ReadAt(tagHeader, offset)
+		if n < flvTagHeaderSize {
+			break
+		}
+
+		tagType := tagHeader[0] & 0x1F
+		dataSize := int64(tagHeader[1])<<16 | int64(tagHeader[2])<<8 | int64(tagHeader[3])
+		dataOffset := offset + flvTagHeaderSize
+		tagData := tagHeader[flvTagHeaderSize:n]
+
+		if dataSize > 0 && len(tagData) > 0 {
+			switch tagType {
+			case flvTagVideo:
+				if parser.videoCodec == "" {
+					parser.videoCodec = flvVideoCodecName(tagData)
+				}
+			case flvTagAudio:
+				if parser.audioCodec == "" {
+					parser.audioCodec = flvCodecName(flvAudioCodecs, tagData[0]>>4)
+				}
+			case flvTagScript:
+				if parser.metadata == nil && dataSize <= flvMaxScriptSize {
+					parser.parseScript(reader, dataOffset, dataSize)
+				}
+			}
+		}
+
+		if parser.metadata != nil && parser.videoCodec != "" && parser.audioCodec != "" {
+			break
+		}
+		// 跳过tag数据和随后的PreviousTagSize
+		offset = dataOffset + dataSize + 4
+	}
+
+	if parser.metadata == nil && parser.videoCodec == "" && parser.audioCodec == "" {
+		return fmt.Errorf("未找到FLV元数据和音视频tag")
+	}
+
+	parser.fill(info, size)
+	return nil
+}
+
+// parseScript 解析脚本tag中的onMetaData
+func (p *flvParser) parseScript(reader io.ReaderAt, offset, size int64) {
+	data := make([]byte, size)
+	n, _ := reader.ReadAt(data, offset)
+
+	amf := &amf0Reader{data: data[:n]}
+	name, err := amf.readValue(0)
+	if err != nil || name != "onMetaData" {
+		return
+	}
+	value, err := amf.readValue(0)
+	if err != nil {
+		return
+	}
+	if metadata, ok := value.(map[string]any); ok {
+		p.metadata = metadata
+	}
+}
+
+// fill 将解析结果写入视频信息，tag头中的编码优先于元数据中的编码ID
+func (p *flvParser) fill(info *VideoInfo, fileSize int64) {
+	info.VideoCodec = p.videoCodec
+	info.AudioCodec = p.audioCodec
+
+	if p.metadata != nil {
+		if duration := amf0Float(p.metadata["duration"]); duration > 0 {
+			info.Duration = time.Duration(duration * float64(time.Second))
+		}
+		info.Width = int(amf0Float(p.metadata["width"]))
+		info.Height = int(amf0Float(p.metadata["height"]))
+		info.FrameRate = amf0Float(p.metadata["framerate"])
+
+		// 元数据中的码率单位为kbps
+		dataRate := amf0Float(p.metadata["videodatarate"]) + amf0Float(p.metadata["audiodatarate"])
+		info.Bitrate = int64(dataRate * 1000)
+
+		if info.VideoCodec == "" {
+			if id, ok := p.metadata["videocodecid"].(float64); ok {
+				info.VideoCodec = flvCodecName(flvVideoCodecs, byte(id))
+			}
+		}
+		if info.AudioCodec == "" {
+			if id, ok := p.metadata["audiocodecid"].(float64); ok {
+				info.AudioCodec = flvCodecName(flvAudioCodecs, byte(id))
+			}
+		}
+	}
+
+	// 元数据缺少码率时使用文件大小估算
+	if seconds := info.Duration.Seconds(); info.Bitrate == 0 && seconds > 0 {
+		info.Bitrate = int64(float64(fileSize*8) / seconds)
+	}
+}
+
+// flvVideoCodecName 解析视频tag的编码，扩展格式（Enhanced RTMP）使用FourCC标识
+func flvVideoCodecName(tagData []byte) string {
+	if tagData[0]&0x80 != 0 {
+		if len(tagData) < 5 {
+			return ""
+		}
+		return mp4CodecName(mp4VideoCodecs, string(tagData[1:5]))
+	}
+	return flvCodecName(flvVideoCodecs, tagData[0]&0x0F)
+}
+
+// flvCodecName 将FLV编码ID转换为编码名称，未知编码返回原始ID
+func flvCodecName(codecs map[byte]string, id byte) string {
+	if name, ok := codecs[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("FLV codec %d", id)
+}
+
+// amf0Reader AMF0解码器，只解析元数据中会出现的基本类型
+type amf0Reader struct {
+	data []byte
+	pos  int
+}
+
+// readValue 读取一个AMF0值：数字返回float64，对象和ECMA数组返回map[string]any
+func (r *amf0Reader) readValue(depth int) (any, error) {
+	if depth > amf0MaxDepth {
+		return nil, fmt.Errorf("AMF0嵌套层级过深")
+	}
+
+	marker, err := r.read(1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch marker[0] {
+	case amf0Number:
+		data, err := r.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+	case amf0Boolean:
+		data, err := r.read(1)
+		if err != nil {
+			return nil, err
+		}
+		return data[0] != 0, nil
+	case amf0String:
+		return r.readString(2)
+	case amf0LongString:
+		return r.readString(4)
+	case amf0Object:
+		return r.readProperties(depth)
+	case amf0ECMAArray:
+		// 数组长度只是提示，实际以结束标记为准
+		if _, err := r.read(4); err != nil {
+			return nil, err
+		}
+		return r.readProperties(depth)
+	case amf0StrictArray:
+		data, err := r.read(4)
+		if err != nil {
+			return nil, err
+		}
+		count := int(binary.BigEndian.Uint32(data))
+		values := make([]any, 0, min(count, 1024))
+		for i := 0; i < count; i++ {
+			value, err := r.readValue(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case amf0Date:
+		// 8字节时间戳和2字节时区
+		if _, err := r.read(10); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	case amf0Null, amf0Undefined:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("不支持的AMF0类型: %d", marker[0])
+}
+
+// readProperties 读取对象属性直到结束标记（空键名+0x09）
+func (r *amf0Reader) readProperties(depth int) (map[string]any, error) {
+	properties := make(map[string]any)
+	for {
+		key, err := r.readString(2)
+		if err != nil {
+			return nil, err
+		}
+		if key == "" {
+			if r.pos < len(r.data) && r.data[r.pos] == amf0ObjectEnd {
+				r.pos++
+			}
+			return properties, nil
+		}
+
+		value, err := r.readValue(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		properties[key] = value
+	}
+}
+
+// readString 读取以lengthSize字节长度开头的字符串
+func (r *amf0Reader) readString(lengthSize int) (string, error) {
+	data, err := r.read(lengthSize)
+	if err != nil {
+		return "", err
+	}
+
+	var length uint32
+	if lengthSize == 2 {
+		length = uint32(binary.BigEndian.Uint16(data))
+	} else {
+		length = binary.BigEndian.Uint32(data)
+	}
+
+	value, err := r.read(int(length))
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// read 读取n字节
+func (r *amf0Reader) read(n int) ([]byte, error) {
+	if n < 0 || r.pos+n > len(r.data) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	data := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return data, nil
+}
+
+// amf0Float 读取数字类型的元数据，其他类型返回0
+func amf0Float(value any) float64 {
+	number, _ := value.(float64)
+	return number
+}
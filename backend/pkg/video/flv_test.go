@@ -0,0 +1,132 @@
+package video
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flvTestTag 构造FLV tag及随后的PreviousTagSize
+func flvTestTag(tagType byte, data []byte) []byte {
+	tag := make([]byte, flvTagHeaderSize, flvTagHeaderSize+len(data)+4)
+	tag[0] = tagType
+	tag[1], tag[2], tag[3] = byte(len(data)>>16), byte(len(data)>>8), byte(len(data))
+	tag = append(tag, data...)
+	return binary.BigEndian.AppendUint32(tag, uint32(len(tag)))
+}
+
+// amf0TestString 构造AMF0字符串（不含类型标记）
+func amf0TestString(value string) []byte {
+	return append(binary.BigEndian.AppendUint16(nil, uint16(len(value))), value...)
+}
+
+// amf0TestNumber 构造AMF0数字
+func amf0TestNumber(value float64) []byte {
+	return binary.BigEndian.AppendUint64([]byte{amf0Number}, math.Float64bits(value))
+}
+
+// createTestFLVMetadata 构造onMetaData脚本数据
+func createTestFLVMetadata(properties map[string][]byte) []byte {
+	script := append([]byte{amf0String}, amf0TestString("onMetaData")...)
+	script = append(script, amf0ECMAArray)
+	script = binary.BigEndian.AppendUint32(script, uint32(len(properties)))
+	for key, value := range properties {
+		script = append(script, amf0TestString(key)...)
+		script = append(script, value...)
+	}
+	return append(script, 0x00, 0x00, amf0ObjectEnd)
+}
+
+// createTestFLV 构造包含元数据、H.264视频tag和AAC音频tag的FLV
+func createTestFLV(metadata map[string][]byte) []byte {
+	header := []byte{'F', 'L', 'V', 0x01, 0x05, 0x00, 0x00, 0x00, 0x09, 0x00, 0x00, 0x00, 0x00}
+	return bytes.Join([][]byte{
+		header,
+		flvTestTag(flvTagScript, createTestFLVMetadata(metadata)),
+		flvTestTag(flvTagVideo, []byte{0x17, 0x00, 0x00, 0x00, 0x00}),
+		flvTestTag(flvTagAudio, []byte{0xAF, 0x00, 0x12, 0x10}),
+	}, nil)
+}
+
+// TestVideoInfoExtractor_FLV 测试解析FLV元数据和音视频tag
+func TestVideoInfoExtractor_FLV(t *testing.T) {
+	extractor := NewVideoInfoExtractor()
+
+	t.Run("解析onMetaData", func(t *testing.T) {
+		data := createTestFLV(map[string][]byte{
+			"duration":      amf0TestNumber(12.5),
+			"width":         amf0TestNumber(854),
+			"height":        amf0TestNumber(480),
+			"framerate":     amf0TestNumber(30),
+			"videodatarate": amf0TestNumber(800),
+			"audiodatarate": amf0TestNumber(128),
+			"encoder":       append([]byte{amf0String}, amf0TestString("Lavf60.3.100")...),
+			"stereo":        {amf0Boolean, 0x01},
+		})
+
+		info, err := extractor.ExtractInfo(&InfoExtractionRequest{Data: data, Filename: "test.flv"})
+		require.NoError(t, err)
+		assert.Equal(t, "flv", info.Format)
+		assert.Equal(t, 12500*time.Millisecond, info.Duration)
+		assert.Equal(t, 854, info.Width)
+		assert.Equal(t, 480, info.Height)
+		assert.Equal(t, 30.0, info.FrameRate)
+		assert.Equal(t, int64(928000), info.Bitrate, "码率应该由kbps换算为bps")
+		assert.Equal(t, "H.264", info.VideoCodec)
+		assert.Equal(t, "AAC", info.AudioCodec)
+	})
+
+	t.Run("缺少码率时使用文件大小估算", func(t *testing.T) {
+		data := createTestFLV(map[string][]byte{"duration": amf0TestNumber(2)})
+
+		info, err := extractor.ExtractInfo(&InfoExtractionRequest{Data: data, Filename: "test.flv"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(data)*8/2), info.Bitrate)
+	})
+
+	t.Run("扩展格式的视频tag", func(t *testing.T) {
+		header := []byte{'F', 'L', 'V', 0x01, 0x01, 0x00, 0x00, 0x00, 0x09, 0x00, 0x00, 0x00, 0x00}
+		data := append(header, flvTestTag(flvTagVideo, []byte{0x90, 'h', 'v', 'c', '1'})...)
+
+		info, err := extractor.ExtractInfo(&InfoExtractionRequest{Data: data, Filename: "test.flv"})
+		require.NoError(t, err)
+		assert.Equal(t, "H.265", info.VideoCodec)
+	})
+
+	t.Run("截断的文件不应该panic", func(t *testing.T) {
+		data := createTestFLV(map[string][]byte{"duration": amf0TestNumber(2), "width": amf0TestNumber(640)})
+		for size := 12; size < len(data); size += 7 {
+			info, err := extractor.ExtractInfo(&InfoExtractionRequest{Data: data[:size], Filename: "test.flv"})
+			require.NoError(t, err)
+			assert.NotNil(t, info)
+		}
+	})
+}
+
+// TestAMF0Reader 测试AMF0解码
+func TestAMF0Reader(t *testing.T) {
+	data := []byte{amf0Object}
+	data = append(data, amf0TestString("nested")...)
+	data = append(data, amf0StrictArray, 0x00, 0x00, 0x00, 0x02)
+	data = append(data, amf0TestNumber(1)...)
+	data = append(data, amf0Null)
+	data = append(data, amf0TestString("date")...)
+	data = append(data, amf0Date)
+	data = append(data, make([]byte, 10)...)
+	data = append(data, 0x00, 0x00, amf0ObjectEnd)
+
+	value, err := (&amf0Reader{data: data}).readValue(0)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"nested": []any{1.0, nil}, "date": nil}, value)
+
+	_, err = (&amf0Reader{data: []byte{0x10}}).readValue(0)
+	assert.Error(t, err, "不支持的类型应该返回错误")
+
+	_, err = (&amf0Reader{data: []byte{amf0String, 0x00, 0x10, 'a'}}).readValue(0)
+	assert.Error(t, err, "数据不足时应该返回错误")
+}
@@ -0,0 +1,138 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/manteia/zhulong/pkg/tempfile"
+)
+
+// FrameExtractor 视频帧提取器接口
+type FrameExtractor interface {
+	// IsAvailable 提取器是否可用
+	IsAvailable() bool
+	// ExtractFrame 按选项中的时间偏移和尺寸提取一帧图像
+	ExtractFrame(video io.Reader, options *ThumbnailOptions) (image.Image, error)
+}
+
+// FFmpegFrameExtractor 基于FFmpeg的视频帧提取器
+type FFmpegFrameExtractor struct {
+	ffmpegPath string
+	timeout    time.Duration
+	tempFiles  *tempfile.Manager // 写入视频数据的临时目录，为nil时使用系统临时目录
+}
+
+// NewFFmpegFrameExtractor 创建FFmpeg帧提取器
+func NewFFmpegFrameExtractor(ffmpegPath string) *FFmpegFrameExtractor {
+	if ffmpegPath == "" {
+		ffmpegPath = "ffmpeg"
+	}
+	return &FFmpegFrameExtractor{
+		ffmpegPath: ffmpegPath,
+		timeout:    30 * time.Second,
+	}
+}
+
+// SetTempFiles 设置写入视频数据使用的临时目录管理器
+func (e *FFmpegFrameExtractor) SetTempFiles(tempFiles *tempfile.Manager) {
+	e.tempFiles = tempFiles
+}
+
+// IsAvailable 检查FFmpeg是否可用
+func (e *FFmpegFrameExtractor) IsAvailable() bool {
+	_, err := exec.LookPath(e.ffmpegPath)
+	return err == nil
+}
+
+// ExtractFrame 调用FFmpeg提取指定时间点的视频帧
+func (e *FFmpegFrameExtractor) ExtractFrame(video io.Reader, options *ThumbnailOptions) (image.Image, error) {
+	if video == nil {
+		return nil, fmt.Errorf("视频数据为空")
+	}
+	if options == nil {
+		return nil, fmt.Errorf("选项不能为空")
+	}
+
+	// MP4等格式的索引可能位于文件末尾，需要可随机访问的输入，因此写入临时文件
+	tempFile, err := e.tempFiles.CreateTemp("zhulong-frame-*")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer e.tempFiles.Remove(tempFile.Name())
+
+	written, err := io.Copy(tempFile, video)
+	if err != nil {
+		tempFile.Close()
+		return nil, fmt.Errorf("写入临时文件失败: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return nil, fmt.Errorf("关闭临时文件失败: %w", err)
+	}
+	if written == 0 {
+		return nil, fmt.Errorf("视频数据为空")
+	}
+
+	return e.ExtractFrameFromFile(tempFile.Name(), options)
+}
+
+// ExtractFrameFromFile 调用FFmpeg从本地视频文件提取指定时间点的视频帧
+func (e *FFmpegFrameExtractor) ExtractFrameFromFile(path string, options *ThumbnailOptions) (image.Image, error) {
+	if options == nil {
+		return nil, fmt.Errorf("选项不能为空")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.ffmpegPath, e.buildArgs(path, options)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("FFmpeg执行失败: %w, 输出: %s", err, stderr.String())
+	}
+	if stdout.Len() == 0 {
+		return nil, fmt.Errorf("时间偏移 %.1fs 处没有可提取的视频帧", options.TimeOffset)
+	}
+
+	img, err := png.Decode(&stdout)
+	if err != nil {
+		return nil, fmt.Errorf("解码视频帧失败: %w", err)
+	}
+
+	return img, nil
+}
+
+// buildArgs 构建FFmpeg命令行参数
+func (e *FFmpegFrameExtractor) buildArgs(inputPath string, options *ThumbnailOptions) []string {
+	scale := fmt.Sprintf("scale=%d:%d", options.Width, options.Height)
+	if options.KeepAspect {
+		switch options.Fit {
+		case FitContain:
+			scale += fmt.Sprintf(":force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2:black", options.Width, options.Height)
+		case FitCover:
+			scale += fmt.Sprintf(":force_original_aspect_ratio=increase,crop=%d:%d", options.Width, options.Height)
+		default:
+			scale += ":force_original_aspect_ratio=decrease"
+		}
+	}
+
+	return []string{
+		"-hide_banner", "-loglevel", "error",
+		// -ss放在-i之前进行快速定位
+		"-ss", strconv.FormatFloat(options.TimeOffset, 'f', 3, 64),
+		"-i", inputPath,
+		"-frames:v", "1",
+		"-vf", scale,
+		"-f", "image2pipe",
+		"-vcodec", "png",
+		"-",
+	}
+}
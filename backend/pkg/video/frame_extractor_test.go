@@ -0,0 +1,73 @@
+package video
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFFmpegFrameExtractor_BuildArgs 测试FFmpeg参数构建
+func TestFFmpegFrameExtractor_BuildArgs(t *testing.T) {
+	extractor := NewFFmpegFrameExtractor("")
+	assert.Equal(t, "ffmpeg", extractor.ffmpegPath, "默认应该使用PATH中的ffmpeg")
+
+	args := extractor.buildArgs("/tmp/input.mp4", &ThumbnailOptions{Width: 320, Height: 240, TimeOffset: 5})
+	joined := strings.Join(args, " ")
+	assert.Contains(t, joined, "-ss 5.000 -i /tmp/input.mp4", "时间偏移应该位于输入之前")
+	assert.Contains(t, joined, "-vf scale=320:240")
+	assert.NotContains(t, joined, "force_original_aspect_ratio")
+
+	args = extractor.buildArgs("/tmp/input.mp4", &ThumbnailOptions{Width: 320, Height: 240, KeepAspect: true})
+	assert.Contains(t, strings.Join(args, " "), "scale=320:240:force_original_aspect_ratio=decrease", "保持宽高比时应该限制在目标尺寸内")
+
+	args = extractor.buildArgs("/tmp/input.mp4", &ThumbnailOptions{Width: 320, Height: 240, KeepAspect: true, Fit: FitContain})
+	assert.Contains(t, strings.Join(args, " "), "scale=320:240:force_original_aspect_ratio=decrease,pad=320:240:(ow-iw)/2:(oh-ih)/2:black", "contain模式应该加黑边")
+
+	args = extractor.buildArgs("/tmp/input.mp4", &ThumbnailOptions{Width: 320, Height: 240, KeepAspect: true, Fit: FitCover})
+	assert.Contains(t, strings.Join(args, " "), "scale=320:240:force_original_aspect_ratio=increase,crop=320:240", "cover模式应该居中裁剪")
+}
+
+// TestFFmpegFrameExtractor_InvalidInput 测试无效输入
+func TestFFmpegFrameExtractor_InvalidInput(t *testing.T) {
+	extractor := NewFFmpegFrameExtractor("")
+
+	_, err := extractor.ExtractFrame(nil, &ThumbnailOptions{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "视频数据为空")
+
+	_, err = extractor.ExtractFrame(bytes.NewReader(nil), &ThumbnailOptions{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "视频数据为空")
+
+	_, err = extractor.ExtractFrame(bytes.NewReader([]byte("data")), nil)
+	assert.Error(t, err)
+}
+
+// TestFFmpegFrameExtractor_ExtractFrame 测试真实帧提取（需要FFmpeg）
+func TestFFmpegFrameExtractor_ExtractFrame(t *testing.T) {
+	extractor := NewFFmpegFrameExtractor("")
+	if !extractor.IsAvailable() {
+		t.Skip("跳过测试：FFmpeg不可用")
+	}
+
+	inputPath := filepath.Join(t.TempDir(), "input.mp4")
+	cmd := exec.Command("ffmpeg", "-hide_banner", "-loglevel", "error", "-y",
+		"-f", "lavfi", "-i", "testsrc=duration=2:size=640x360:rate=25",
+		"-c:v", "libx264", inputPath)
+	require.NoError(t, cmd.Run(), "生成测试视频应该成功")
+
+	file, err := os.Open(inputPath)
+	require.NoError(t, err)
+	defer file.Close()
+
+	frame, err := extractor.ExtractFrame(file, &ThumbnailOptions{Width: 320, Height: 240, TimeOffset: 1, KeepAspect: true})
+	require.NoError(t, err, "提取视频帧应该成功")
+	assert.Equal(t, 320, frame.Bounds().Dx())
+	assert.Equal(t, 180, frame.Bounds().Dy(), "应该保持16:9宽高比")
+}
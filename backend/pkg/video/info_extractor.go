@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
 	"time"
@@ -16,8 +17,10 @@ type VideoInfoExtractor struct {
 
 // InfoExtractionRequest 信息提取请求
 type InfoExtractionRequest struct {
-	Data     []byte `json:"data"`     // 文件数据
-	Filename string `json:"filename"` // 文件名
+	Data     []byte      `json:"data"`     // 文件数据（至少包含文件头）
+	Filename string      `json:"filename"` // 文件名
+	Reader   io.ReaderAt `json:"-"`        // 完整文件（可选），提供时可以解析位于文件末尾的MP4 moov
+	Size     int64       `json:"size"`     // 完整文件大小，提供Reader时必填
 }
 
 // VideoInfo 视频信息
@@ -29,8 +32,9 @@ type VideoInfo struct {
 
 	// 视频属性
 	Duration  time.Duration `json:"duration"`   // 时长
-	Width     int           `json:"width"`      // 宽度
-	Height    int           `json:"height"`     // 高度
+	Width     int           `json:"width"`      // 显示宽度（已按旋转角度交换）
+	Height    int           `json:"height"`     // 显示高度
+	Rotation  int           `json:"rotation"`   // 显示时的顺时针旋转角度：0/90/180/270
 	Bitrate   int64         `json:"bitrate"`    // 比特率（bps）
 	FrameRate float64       `json:"frame_rate"` // 帧率（fps）
 
@@ -38,6 +42,9 @@ type VideoInfo struct {
 	VideoCodec string `json:"video_codec"` // 视频编码
 	AudioCodec string `json:"audio_codec"` // 音频编码
 
+	// 章节（MKV的Chapters元素或MP4的chpl box）
+	Chapters []Chapter `json:"chapters"`
+
 	// 格式化显示
 	DurationFormatted   string `json:"duration_formatted"`   // 格式化时长
 	ResolutionFormatted string `json:"resolution_formatted"` // 格式化分辨率
@@ -75,8 +82,14 @@ func (e *VideoInfoExtractor) ExtractInfo(request *InfoExtractionRequest) (*Video
 		FileSize: int64(len(request.Data)),
 	}
 
-	// 提取详细信息
-	e.extractDetailedInfo(request.Data, format, info)
+	// 提取详细信息，提供完整文件时从完整文件解析
+	if request.Reader != nil && request.Size > 0 {
+		info.FileSize = request.Size
+		e.extractDetailedInfoFrom(request.Reader, request.Size, request.Data, format, info)
+	} else {
+		e.extractDetailedInfo(request.Data, format, info)
+	}
+	info.Chapters = normalizeChapters(info.Chapters, info.Duration)
 
 	// 生成格式化显示
 	e.formatDisplayInfo(info)
@@ -86,45 +99,29 @@ func (e *VideoInfoExtractor) ExtractInfo(request *InfoExtractionRequest) (*Video
 
 // extractDetailedInfo 提取详细信息
 func (e *VideoInfoExtractor) extractDetailedInfo(data []byte, format string, info *VideoInfo) {
+	e.extractDetailedInfoFrom(bytes.NewReader(data), int64(len(data)), data, format, info)
+}
+
+// extractDetailedInfoFrom 从可随机访问的文件中提取详细信息，head为文件头部数据
+func (e *VideoInfoExtractor) extractDetailedInfoFrom(reader io.ReaderAt, size int64, head []byte, format string, info *VideoInfo) {
 	switch format {
-	case "mp4", "mov":
-		e.extractMP4Info(data, info)
+	case "mp4", "mov", "3gp":
+		e.extractMP4Info(reader, size, info)
 	case "avi":
-		e.extractAVIInfo(data, info)
-	case "webm":
-		e.extractWebMInfo(data, info)
+		e.extractAVIInfo(head, info)
+	case "webm", "mkv":
+		e.extractWebMInfo(reader, size, info)
+	case "flv":
+		e.extractFLVInfo(reader, size, info)
+	case "ts":
+		e.extractTSInfo(reader, size, info)
 	}
 }
 
-// extractMP4Info 提取MP4信息
-func (e *VideoInfoExtractor) extractMP4Info(data []byte, info *VideoInfo) {
-	// 解析MP4 box结构
-	offset := 0
-	for offset < len(data)-8 {
-		if offset+8 > len(data) {
-			break
-		}
-
-		// 读取box头
-		boxSize := binary.BigEndian.Uint32(data[offset : offset+4])
-		boxType := string(data[offset+4 : offset+8])
-
-		if boxSize == 0 || boxSize > uint32(len(data)-offset) {
-			break
-		}
-
-		// 处理不同类型的box
-		switch boxType {
-		case "mvhd": // Movie header
-			e.extractMovieHeader(data[offset:offset+int(boxSize)], info)
-		case "tkhd": // Track header
-			e.extractTrackHeader(data[offset:offset+int(boxSize)], info)
-		case "stsd": // Sample description
-			e.extractSampleDescription(data[offset:offset+int(boxSize)], info)
-		}
-
-		offset += int(boxSize)
-	}
+// extractMP4Info 提取MP4信息，递归解析moov中的轨道信息
+func (e *VideoInfoExtractor) extractMP4Info(reader io.ReaderAt, size int64, info *VideoInfo) {
+	// 解析失败（如只提供了文件头而moov位于末尾）时保留默认值，不影响上传
+	parseMP4Info(reader, size, info)
 }
 
 // extractAVIInfo 提取AVI信息
@@ -153,83 +150,20 @@ func (e *VideoInfoExtractor) extractAVIInfo(data []byte, info *VideoInfo) {
 	}
 }
 
-// extractWebMInfo 提取WebM信息
-func (e *VideoInfoExtractor) extractWebMInfo(data []byte, info *VideoInfo) {
-	// 简化的WebM信息提取
-	// WebM使用Matroska容器格式，这里实现基本的信息提取
-	// 在实际项目中，可能需要更复杂的EBML解析
-
-	// 查找关键元素标识符
-	if e.findWebMElement(data, []byte{0x44, 0x89}) != -1 { // Duration element
-		// 提取时长信息（简化版）
-		info.Duration = 0 // 需要实际的EBML解析
-	}
-
-	if e.findWebMElement(data, []byte{0xB0}) != -1 { // PixelWidth
-		// 提取宽度信息（简化版）
-		info.Width = 0 // 需要实际的EBML解析
-	}
-
-	if e.findWebMElement(data, []byte{0xBA}) != -1 { // PixelHeight
-		// 提取高度信息（简化版）
-		info.Height = 0 // 需要实际的EBML解析
-	}
-}
-
-// findWebMElement 查找WebM元素
-func (e *VideoInfoExtractor) findWebMElement(data []byte, elementID []byte) int {
-	return bytes.Index(data, elementID)
-}
-
-// extractMovieHeader 提取电影头信息
-func (e *VideoInfoExtractor) extractMovieHeader(boxData []byte, info *VideoInfo) {
-	if len(boxData) < 32 {
-		return
-	}
-
-	// 跳过box头和版本/标志
-	offset := 12
-
-	// 时间刻度和时长
-	timeScale := binary.BigEndian.Uint32(boxData[offset+8 : offset+12])
-	duration := binary.BigEndian.Uint32(boxData[offset+12 : offset+16])
-
-	if timeScale > 0 {
-		info.Duration = time.Duration(duration) * time.Second / time.Duration(timeScale)
-	}
+// extractWebMInfo 提取WebM/MKV信息，解析EBML结构中的Segment Info和Tracks
+func (e *VideoInfoExtractor) extractWebMInfo(reader io.ReaderAt, size int64, info *VideoInfo) {
+	// 解析失败时保留默认值，不影响上传
+	parseEBMLInfo(reader, size, info)
 }
 
-// extractTrackHeader 提取轨道头信息
-func (e *VideoInfoExtractor) extractTrackHeader(boxData []byte, info *VideoInfo) {
-	if len(boxData) < 92 {
-		return
-	}
-
-	// 提取宽度和高度（固定点数格式）
-	widthFixed := binary.BigEndian.Uint32(boxData[len(boxData)-8 : len(boxData)-4])
-	heightFixed := binary.BigEndian.Uint32(boxData[len(boxData)-4:])
-
-	info.Width = int(widthFixed >> 16)   // 取整数部分
-	info.Height = int(heightFixed >> 16) // 取整数部分
+// extractFLVInfo 提取FLV信息，解析onMetaData和音视频tag头
+func (e *VideoInfoExtractor) extractFLVInfo(reader io.ReaderAt, size int64, info *VideoInfo) {
+	parseFLVInfo(reader, size, info)
 }
 
-// extractSampleDescription 提取样本描述信息
-func (e *VideoInfoExtractor) extractSampleDescription(boxData []byte, info *VideoInfo) {
-	if len(boxData) < 16 {
-		return
-	}
-
-	// 查找编解码器信息
-	// 这里简化处理，实际需要解析完整的sample description
-	if bytes.Contains(boxData, []byte("avc1")) {
-		info.VideoCodec = "H.264"
-	} else if bytes.Contains(boxData, []byte("hvc1")) {
-		info.VideoCodec = "H.265"
-	}
-
-	if bytes.Contains(boxData, []byte("mp4a")) {
-		info.AudioCodec = "AAC"
-	}
+// extractTSInfo 提取MPEG-TS信息，解析节目表和首尾PTS
+func (e *VideoInfoExtractor) extractTSInfo(reader io.ReaderAt, size int64, info *VideoInfo) {
+	parseTSInfo(reader, size, info)
 }
 
 // ExtractDuration 提取视频时长
@@ -0,0 +1,93 @@
+package video
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // 注册GIF解码器，GIF图片使用第一帧生成缩略图
+)
+
+// 波形缩略图的颜色
+var (
+	waveformBackground = color.RGBA{30, 34, 45, 255}
+	waveformForeground = color.RGBA{96, 165, 250, 255}
+)
+
+// waveformBarWidth 波形缩略图中每个峰值柱的宽度（像素），柱之间间隔1像素
+const waveformBarWidth = 2
+
+// GenerateFromPicture 将上传的图片（JPEG、PNG或GIF的第一帧）按选项缩放为缩略图
+// 与自定义封面不同，不限制图片的最小尺寸
+func (g *ThumbnailGenerator) GenerateFromPicture(data []byte, options *ThumbnailOptions) (*ThumbnailResult, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("图片数据为空")
+	}
+	if options == nil {
+		options = g.GetDefaultOptions()
+	}
+	if err := g.ValidateOptions(options); err != nil {
+		return nil, err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("无法识别的图片格式: %v", err)
+	}
+	if config.Width*config.Height > maxCoverPixels {
+		return nil, fmt.Errorf("图片尺寸过大: %dx%d", config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("解码图片失败: %v", err)
+	}
+	return g.encodeThumbnail(resizeImage(img, options), options)
+}
+
+// GenerateWaveform 将音频的峰值振幅（0~1）绘制为波形缩略图，尺寸为选项的宽高
+func (g *ThumbnailGenerator) GenerateWaveform(peaks []float64, options *ThumbnailOptions) (*ThumbnailResult, error) {
+	if len(peaks) == 0 {
+		return nil, fmt.Errorf("波形数据为空")
+	}
+	if options == nil {
+		options = g.GetDefaultOptions()
+	}
+	if err := g.ValidateOptions(options); err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, options.Width, options.Height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{waveformBackground}, image.Point{}, draw.Src)
+
+	// 每个柱取对应区间内的最大峰值，以水平中线对称绘制
+	bars := options.Width / (waveformBarWidth + 1)
+	centerY := options.Height / 2
+	for bar := 0; bar < bars; bar++ {
+		start := bar * len(peaks) / bars
+		end := (bar + 1) * len(peaks) / bars
+		peak := peaks[start]
+		for _, value := range peaks[start:end] {
+			peak = maxFloat(peak, value)
+		}
+		halfHeight := int(peak * float64(options.Height) * 0.45)
+		if halfHeight < 1 {
+			halfHeight = 1
+		}
+
+		x := bar * (waveformBarWidth + 1)
+		rect := image.Rect(x, centerY-halfHeight, x+waveformBarWidth, centerY+halfHeight)
+		draw.Draw(img, rect, &image.Uniform{waveformForeground}, image.Point{}, draw.Src)
+	}
+
+	return g.encodeThumbnail(img, options)
+}
+
+// maxFloat 返回较大的浮点数，包内的max只支持int
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
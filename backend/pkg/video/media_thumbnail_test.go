@@ -0,0 +1,58 @@
+package video
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestThumbnailGenerator_GenerateFromPicture 测试从上传的图片生成缩略图
+func TestThumbnailGenerator_GenerateFromPicture(t *testing.T) {
+	generator := NewThumbnailGenerator()
+	options := &ThumbnailOptions{Width: 320, Height: 240, Quality: 80, Format: "jpeg", KeepAspect: true}
+
+	result, err := generator.GenerateFromPicture(encodeTestPNG(t, 1280, 720), options)
+	require.NoError(t, err)
+	assert.Equal(t, 320, result.Width)
+	assert.Equal(t, 180, result.Height)
+
+	var gifData bytes.Buffer
+	require.NoError(t, gif.Encode(&gifData, image.NewRGBA(image.Rect(0, 0, 32, 32)), nil))
+	result, err = generator.GenerateFromPicture(gifData.Bytes(), options)
+	require.NoError(t, err, "应该支持GIF和小于封面最小尺寸的图片")
+	assert.Equal(t, 240, result.Height)
+
+	_, err = generator.GenerateFromPicture([]byte("RIFF\x00\x00\x00\x00WEBPVP8L"), options)
+	assert.Error(t, err, "无法解码的图片应该返回错误")
+	_, err = generator.GenerateFromPicture(nil, options)
+	assert.Error(t, err)
+}
+
+// TestThumbnailGenerator_GenerateWaveform 测试绘制音频波形缩略图
+func TestThumbnailGenerator_GenerateWaveform(t *testing.T) {
+	generator := NewThumbnailGenerator()
+	options := &ThumbnailOptions{Width: 320, Height: 240, Quality: 80, Format: "png"}
+
+	peaks := make([]float64, 1000)
+	for i := 500; i < len(peaks); i++ {
+		peaks[i] = 1
+	}
+	result, err := generator.GenerateWaveform(peaks, options)
+	require.NoError(t, err)
+	assert.Equal(t, 320, result.Width)
+	assert.Equal(t, 240, result.Height)
+
+	img, err := png.Decode(bytes.NewReader(result.ImageData))
+	require.NoError(t, err)
+	assert.Equal(t, waveformBackground, img.At(10, 20), "静音部分只绘制中线")
+	assert.Equal(t, waveformForeground, img.At(10, 120))
+	assert.Equal(t, waveformForeground, img.At(300, 20), "满幅部分应该接近上下边缘")
+
+	_, err = generator.GenerateWaveform(nil, options)
+	assert.Error(t, err)
+}
@@ -0,0 +1,417 @@
+package video
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// mp4MaxMoovSize moov box最大读取大小，防止异常文件占用过多内存
+	mp4MaxMoovSize = 64 * 1024 * 1024
+	// mp4MaxDepth box最大嵌套层级
+	mp4MaxDepth = 16
+)
+
+// mp4ContainerBoxes 需要递归解析子box的容器类型
+var mp4ContainerBoxes = map[string]bool{
+	"moov": true,
+	"trak": true,
+	"mdia": true,
+	"minf": true,
+	"stbl": true,
+	"edts": true,
+	"udta": true,
+}
+
+// mp4VideoCodecs 视频样本描述格式与编码名称映射
+var mp4VideoCodecs = map[string]string{
+	"avc1": "H.264",
+	"avc3": "H.264",
+	"hvc1": "H.265",
+	"hev1": "H.265",
+	"vp09": "VP9",
+	"av01": "AV1",
+	"mp4v": "MPEG-4",
+}
+
+// mp4AudioCodecs 音频样本描述格式与编码名称映射
+var mp4AudioCodecs = map[string]string{
+	"mp4a": "AAC",
+	"ac-3": "AC-3",
+	"ec-3": "E-AC-3",
+	"Opus": "Opus",
+	".mp3": "MP3",
+	"alac": "ALAC",
+}
+
+// mp4Box MP4 box位置信息
+type mp4Box struct {
+	Type      string
+	Offset    int64 // 内容起始位置（不含box头）
+	Size      int64 // 内容大小（不含box头）
+	Truncated bool  // 声明的大小超出遍历范围，Size已截断到范围末尾
+}
+
+// mp4Track 解析中的轨道信息
+type mp4Track struct {
+	handler     string // 轨道类型：vide/soun
+	format      string // 样本描述格式，如avc1
+	timeScale   uint32
+	duration    uint64
+	width       int // 样本描述中的编码宽度
+	height      int
+	tkhdWidth   int // 轨道头中的显示宽度
+	tkhdHeight  int
+	rotation    int    // 轨道头变换矩阵表示的顺时针旋转角度
+	sampleCount uint64 // stts统计的样本数
+	sampleTime  uint64 // stts统计的样本总时长（轨道时间刻度）
+	sampleBytes int64  // stsz统计的样本总字节数
+}
+
+// mp4Parser MP4解析器，递归遍历box结构
+type mp4Parser struct {
+	movieTimeScale uint32
+	movieDuration  uint64
+	tracks         []*mp4Track
+	current        *mp4Track
+	chapters       []Chapter
+}
+
+// parseMP4Info 解析MP4/MOV文件的时长、分辨率、编码、码率、帧率和章节
+// reader需要能随机访问整个文件，moov位于文件末尾时也可以解析
+func parseMP4Info(reader io.ReaderAt, size int64, info *VideoInfo) error {
+	var moov *mp4Box
+	err := walkMP4Boxes(reader, 0, size, func(box *mp4Box) error {
+		if box.Type == "moov" {
+			moov = box
+		}
+		return nil
+	})
+	if err != nil && moov == nil {
+		return err
+	}
+	if moov == nil {
+		return fmt.Errorf("未找到moov box")
+	}
+	if moov.Size > mp4MaxMoovSize {
+		return fmt.Errorf("moov box过大: %d字节", moov.Size)
+	}
+
+	data := make([]byte, moov.Size)
+	if _, err := reader.ReadAt(data, moov.Offset); err != nil && err != io.EOF {
+		return fmt.Errorf("读取moov box失败: %w", err)
+	}
+
+	parser := &mp4Parser{}
+	if err := parser.parseContainer(data, 1); err != nil {
+		return err
+	}
+	parser.fill(info, size)
+	return nil
+}
+
+// walkMP4Boxes 遍历[start, end)范围内的同级box
+func walkMP4Boxes(reader io.ReaderAt, start, end int64, fn func(box *mp4Box) error) error {
+	header := make([]byte, 16)
+	for offset := start; offset+8 <= end; {
+		n, err := reader.ReadAt(header, offset)
+		if n < 8 {
+			if err == nil || err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("读取box头失败: %w", err)
+		}
+
+		boxSize := int64(binary.BigEndian.Uint32(header[0:4]))
+		headerSize := int64(8)
+		switch boxSize {
+		case 0:
+			// 大小为0表示box延伸到文件末尾
+			boxSize = end - offset
+		case 1:
+			// 64位扩展大小
+			if n < 16 {
+				return nil
+			}
+			boxSize = int64(binary.BigEndian.Uint64(header[8:16]))
+			headerSize = 16
+		}
+		if boxSize < headerSize {
+			return fmt.Errorf("无效的box大小: %d", boxSize)
+		}
+
+		box := &mp4Box{
+			Type:      string(header[4:8]),
+			Offset:    offset + headerSize,
+			Size:      min64(boxSize, end-offset) - headerSize,
+			Truncated: boxSize > end-offset,
+		}
+		if err := fn(box); err != nil {
+			return err
+		}
+		offset += boxSize
+	}
+	return nil
+}
+
+// parseContainer 递归解析容器box内的子box
+func (p *mp4Parser) parseContainer(data []byte, depth int) error {
+	if depth > mp4MaxDepth {
+		return fmt.Errorf("box嵌套层级过深")
+	}
+
+	return walkMP4Boxes(bytes.NewReader(data), 0, int64(len(data)), func(box *mp4Box) error {
+		payload := data[box.Offset : box.Offset+box.Size]
+
+		if box.Type == "trak" {
+			p.current = &mp4Track{}
+			p.tracks = append(p.tracks, p.current)
+		}
+		if mp4ContainerBoxes[box.Type] {
+			return p.parseContainer(payload, depth+1)
+		}
+
+		switch box.Type {
+		case "mvhd":
+			p.movieTimeScale, p.movieDuration = parseMP4TimeHeader(payload)
+		case "chpl":
+			p.chapters = parseMP4ChapterList(payload)
+		}
+		if p.current == nil {
+			return nil
+		}
+
+		switch box.Type {
+		case "tkhd":
+			p.current.tkhdWidth, p.current.tkhdHeight = parseMP4TrackHeader(payload)
+			p.current.rotation = parseMP4TrackRotation(payload)
+		case "mdhd":
+			p.current.timeScale, p.current.duration = parseMP4TimeHeader(payload)
+		case "hdlr":
+			if len(payload) >= 12 {
+				p.current.handler = string(payload[8:12])
+			}
+		case "stsd":
+			p.parseSampleDescription(payload)
+		case "stts":
+			p.parseTimeToSample(payload)
+		case "stsz":
+			p.parseSampleSizes(payload)
+		}
+		return nil
+	})
+}
+
+// parseMP4TimeHeader 解析mvhd/mdhd中的时间刻度和时长
+// 两者布局相同：版本0为32位时间字段，版本1为64位时间字段
+func parseMP4TimeHeader(payload []byte) (uint32, uint64) {
+	if len(payload) < 4 {
+		return 0, 0
+	}
+
+	if payload[0] == 1 {
+		// version(1) flags(3) creation(8) modification(8) timescale(4) duration(8)
+		if len(payload) < 32 {
+			return 0, 0
+		}
+		return binary.BigEndian.Uint32(payload[20:24]), binary.BigEndian.Uint64(payload[24:32])
+	}
+
+	// version(1) flags(3) creation(4) modification(4) timescale(4) duration(4)
+	if len(payload) < 20 {
+		return 0, 0
+	}
+	return binary.BigEndian.Uint32(payload[12:16]), uint64(binary.BigEndian.Uint32(payload[16:20]))
+}
+
+// parseMP4TrackHeader 解析tkhd中的显示宽高（16.16定点数）
+func parseMP4TrackHeader(payload []byte) (int, int) {
+	// 宽高位于tkhd末尾8字节
+	if len(payload) < 84 {
+		return 0, 0
+	}
+	end := len(payload)
+	width := binary.BigEndian.Uint32(payload[end-8 : end-4])
+	height := binary.BigEndian.Uint32(payload[end-4:])
+	return int(width >> 16), int(height >> 16)
+}
+
+// parseMP4TrackRotation 解析tkhd变换矩阵中的旋转角度，手机竖拍的视频以横向编码并通过矩阵旋转显示
+// 矩阵为3x3定点数，位于宽高之前；只识别90度倍数的旋转，其他变换返回0
+func parseMP4TrackRotation(payload []byte) int {
+	if len(payload) < 84 {
+		return 0
+	}
+	matrix := payload[len(payload)-44 : len(payload)-8]
+	a := int32(binary.BigEndian.Uint32(matrix[0:4]))
+	b := int32(binary.BigEndian.Uint32(matrix[4:8]))
+	c := int32(binary.BigEndian.Uint32(matrix[12:16]))
+	d := int32(binary.BigEndian.Uint32(matrix[16:20]))
+
+	const one = 1 << 16
+	switch {
+	case a == 0 && b == one && c == -one && d == 0:
+		return 90
+	case a == -one && b == 0 && c == 0 && d == -one:
+		return 180
+	case a == 0 && b == -one && c == one && d == 0:
+		return 270
+	default:
+		return 0
+	}
+}
+
+// parseMP4ChapterList 解析udta中Nero格式的chpl章节列表，开始时间单位为100纳秒
+func parseMP4ChapterList(payload []byte) []Chapter {
+	// version(1) flags(3) [版本1: reserved(4)] chapter_count(1)，之后为 start(8) title_length(1) title
+	offset := 4
+	if len(payload) > 0 && payload[0] == 1 {
+		offset += 4
+	}
+	if len(payload) <= offset {
+		return nil
+	}
+	count := int(payload[offset])
+	offset++
+
+	chapters := make([]Chapter, 0, count)
+	for i := 0; i < count && offset+9 <= len(payload); i++ {
+		start := binary.BigEndian.Uint64(payload[offset : offset+8])
+		titleLength := int(payload[offset+8])
+		offset += 9
+		if offset+titleLength > len(payload) {
+			break
+		}
+		chapters = append(chapters, Chapter{
+			Title: string(payload[offset : offset+titleLength]),
+			Start: time.Duration(start) * 100,
+		})
+		offset += titleLength
+	}
+	return chapters
+}
+
+// parseSampleDescription 解析stsd中第一个样本描述的格式和编码尺寸
+func (p *mp4Parser) parseSampleDescription(payload []byte) {
+	// version(1) flags(3) entry_count(4)，之后为样本描述条目
+	if len(payload) < 16 {
+		return
+	}
+	entry := payload[8:]
+	entrySize := int(binary.BigEndian.Uint32(entry[0:4]))
+	p.current.format = string(entry[4:8])
+
+	// 视觉样本描述：box头(8) reserved(6) data_reference_index(2) pre_defined/reserved(16) width(2) height(2)
+	if entrySize >= 36 && len(entry) >= 36 {
+		p.current.width = int(binary.BigEndian.Uint16(entry[32:34]))
+		p.current.height = int(binary.BigEndian.Uint16(entry[34:36]))
+	}
+}
+
+// parseTimeToSample 解析stts统计样本数和总时长，用于计算帧率
+func (p *mp4Parser) parseTimeToSample(payload []byte) {
+	if len(payload) < 8 {
+		return
+	}
+	count := int(binary.BigEndian.Uint32(payload[4:8]))
+	for i := 0; i < count && 8+i*8+8 <= len(payload); i++ {
+		entry := payload[8+i*8:]
+		sampleCount := uint64(binary.BigEndian.Uint32(entry[0:4]))
+		sampleDelta := uint64(binary.BigEndian.Uint32(entry[4:8]))
+		p.current.sampleCount += sampleCount
+		p.current.sampleTime += sampleCount * sampleDelta
+	}
+}
+
+// parseSampleSizes 解析stsz统计样本总字节数，用于计算码率
+func (p *mp4Parser) parseSampleSizes(payload []byte) {
+	// version(1) flags(3) sample_size(4) sample_count(4) [entry_size(4)...]
+	if len(payload) < 12 {
+		return
+	}
+	sampleSize := int64(binary.BigEndian.Uint32(payload[4:8]))
+	sampleCount := int(binary.BigEndian.Uint32(payload[8:12]))
+	if sampleSize > 0 {
+		p.current.sampleBytes = sampleSize * int64(sampleCount)
+		return
+	}
+
+	var total int64
+	for i := 0; i < sampleCount && 12+i*4+4 <= len(payload); i++ {
+		total += int64(binary.BigEndian.Uint32(payload[12+i*4:]))
+	}
+	p.current.sampleBytes = total
+}
+
+// fill 将解析结果写入视频信息
+func (p *mp4Parser) fill(info *VideoInfo, fileSize int64) {
+	if p.movieTimeScale > 0 {
+		info.Duration = time.Duration(float64(p.movieDuration) / float64(p.movieTimeScale) * float64(time.Second))
+	}
+	info.Chapters = p.chapters
+
+	var mediaBytes int64
+	for _, track := range p.tracks {
+		mediaBytes += track.sampleBytes
+
+		// 电影头缺失时使用最长轨道的时长
+		if p.movieTimeScale == 0 && track.timeScale > 0 {
+			trackDuration := time.Duration(float64(track.duration) / float64(track.timeScale) * float64(time.Second))
+			if trackDuration > info.Duration {
+				info.Duration = trackDuration
+			}
+		}
+
+		switch track.handler {
+		case "vide":
+			if info.VideoCodec != "" {
+				continue
+			}
+			info.VideoCodec = mp4CodecName(mp4VideoCodecs, track.format)
+			info.Width, info.Height = track.width, track.height
+			if info.Width == 0 || info.Height == 0 {
+				info.Width, info.Height = track.tkhdWidth, track.tkhdHeight
+			}
+			// 样本描述中是编码尺寸，旋转90度或270度显示时交换宽高
+			info.Rotation = track.rotation
+			if info.Rotation == 90 || info.Rotation == 270 {
+				info.Width, info.Height = info.Height, info.Width
+			}
+			if track.sampleTime > 0 && track.timeScale > 0 {
+				info.FrameRate = float64(track.sampleCount) * float64(track.timeScale) / float64(track.sampleTime)
+			}
+		case "soun":
+			if info.AudioCodec == "" {
+				info.AudioCodec = mp4CodecName(mp4AudioCodecs, track.format)
+			}
+		}
+	}
+
+	// 优先使用样本总大小计算码率，缺少样本表时使用文件大小估算
+	seconds := info.Duration.Seconds()
+	if seconds > 0 {
+		if mediaBytes == 0 {
+			mediaBytes = fileSize
+		}
+		info.Bitrate = int64(float64(mediaBytes*8) / seconds)
+	}
+}
+
+// mp4CodecName 将样本描述格式转换为编码名称，未知格式保留原始标识
+func mp4CodecName(codecs map[string]string, format string) string {
+	if name, ok := codecs[format]; ok {
+		return name
+	}
+	return format
+}
+
+// min64 返回两个int64中的较小值
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
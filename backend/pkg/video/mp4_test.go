@@ -0,0 +1,279 @@
+package video
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mp4TestBox 构造MP4 box
+func mp4TestBox(boxType string, children ...[]byte) []byte {
+	payload := bytes.Join(children, nil)
+	box := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint32(box[0:4], uint32(8+len(payload)))
+	copy(box[4:8], boxType)
+	return append(box, payload...)
+}
+
+// mp4TestUint32 按大端序编码uint32列表
+func mp4TestUint32(values ...uint32) []byte {
+	data := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.BigEndian.PutUint32(data[i*4:], v)
+	}
+	return data
+}
+
+// mp4TestTimeHeader 构造版本0的mvhd/mdhd内容
+func mp4TestTimeHeader(timeScale, duration uint32, extra int) []byte {
+	return append(mp4TestUint32(0, 0, 0, timeScale, duration), make([]byte, extra)...)
+}
+
+// mp4TestTrack 构造轨道
+func mp4TestTrack(handler, format string, timeScale, duration uint32, width, height uint16, sampleCount, sampleDelta, sampleSize uint32) []byte {
+	// tkhd版本0内容共84字节，宽高位于末尾
+	tkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(tkhd[76:80], uint32(width)<<16)
+	binary.BigEndian.PutUint32(tkhd[80:84], uint32(height)<<16)
+
+	// 样本描述条目：视觉样本描述在偏移32处记录宽高
+	entry := make([]byte, 86)
+	binary.BigEndian.PutUint32(entry[0:4], uint32(len(entry)))
+	copy(entry[4:8], format)
+	binary.BigEndian.PutUint16(entry[32:34], width)
+	binary.BigEndian.PutUint16(entry[34:36], height)
+
+	hdlr := append(mp4TestUint32(0, 0), []byte(handler)...)
+	hdlr = append(hdlr, make([]byte, 13)...)
+
+	return mp4TestBox("trak",
+		mp4TestBox("tkhd", tkhd),
+		mp4TestBox("mdia",
+			mp4TestBox("mdhd", mp4TestTimeHeader(timeScale, duration, 4)),
+			mp4TestBox("hdlr", hdlr),
+			mp4TestBox("minf",
+				mp4TestBox("stbl",
+					mp4TestBox("stsd", mp4TestUint32(0, 1), entry),
+					mp4TestBox("stts", mp4TestUint32(0, 1, sampleCount, sampleDelta)),
+					mp4TestBox("stsz", mp4TestUint32(0, sampleSize, sampleCount)),
+				),
+			),
+		),
+	)
+}
+
+// createTestMP4 构造包含H.264视频轨和AAC音频轨的10秒MP4
+func createTestMP4(moovAtEnd bool) []byte {
+	ftyp := mp4TestBox("ftyp", []byte("isom"), mp4TestUint32(0x200), []byte("isomavc1mp41"))
+	moov := mp4TestBox("moov",
+		mp4TestBox("mvhd", mp4TestTimeHeader(1000, 10000, 80)),
+		mp4TestTrack("vide", "avc1", 12800, 128000, 1920, 1080, 250, 512, 4000),
+		mp4TestTrack("soun", "mp4a", 44100, 441000, 0, 0, 431, 1024, 250),
+	)
+	mdat := mp4TestBox("mdat", make([]byte, 4096))
+
+	if moovAtEnd {
+		return bytes.Join([][]byte{ftyp, mdat, moov}, nil)
+	}
+	return bytes.Join([][]byte{ftyp, moov, mdat}, nil)
+}
+
+// TestVideoInfoExtractor_MP4 测试递归解析MP4 box结构
+func TestVideoInfoExtractor_MP4(t *testing.T) {
+	extractor := NewVideoInfoExtractor()
+
+	assertInfo := func(t *testing.T, info *VideoInfo) {
+		assert.Equal(t, 10*time.Second, info.Duration)
+		assert.Equal(t, 1920, info.Width)
+		assert.Equal(t, 1080, info.Height)
+		assert.Equal(t, "H.264", info.VideoCodec)
+		assert.Equal(t, "AAC", info.AudioCodec)
+		assert.InDelta(t, 25.0, info.FrameRate, 0.001)
+		// (250*4000 + 431*250) 字节 / 10秒
+		assert.Equal(t, int64((250*4000+431*250)*8/10), info.Bitrate)
+	}
+
+	t.Run("moov位于文件开头", func(t *testing.T) {
+		info, err := extractor.ExtractInfo(&InfoExtractionRequest{Data: createTestMP4(false), Filename: "test.mp4"})
+		require.NoError(t, err)
+		assertInfo(t, info)
+		assert.Equal(t, "00:10", info.DurationFormatted)
+		assert.Equal(t, "1920x1080", info.ResolutionFormatted)
+	})
+
+	t.Run("moov位于文件末尾", func(t *testing.T) {
+		data := createTestMP4(true)
+		head := data[:64]
+
+		info, err := extractor.ExtractInfo(&InfoExtractionRequest{Data: head, Filename: "test.mp4"})
+		require.NoError(t, err)
+		assert.Zero(t, info.Duration, "只有文件头时无法解析末尾的moov")
+
+		info, err = extractor.ExtractInfo(&InfoExtractionRequest{
+			Data:     head,
+			Filename: "test.mp4",
+			Reader:   bytes.NewReader(data),
+			Size:     int64(len(data)),
+		})
+		require.NoError(t, err)
+		assertInfo(t, info)
+		assert.Equal(t, int64(len(data)), info.FileSize)
+	})
+
+	t.Run("64位box大小", func(t *testing.T) {
+		ftyp := mp4TestBox("ftyp", []byte("isom"), mp4TestUint32(0x200))
+		mdat := make([]byte, 16+32)
+		binary.BigEndian.PutUint32(mdat[0:4], 1)
+		copy(mdat[4:8], "mdat")
+		binary.BigEndian.PutUint64(mdat[8:16], uint64(len(mdat)))
+		moov := mp4TestBox("moov", mp4TestBox("mvhd", mp4TestTimeHeader(600, 1800, 80)))
+
+		width, height, err := extractor.ExtractResolution(bytes.Join([][]byte{ftyp, mdat, moov}, nil))
+		require.NoError(t, err)
+		assert.Zero(t, width)
+		assert.Zero(t, height)
+
+		duration, err := extractor.ExtractDuration(bytes.Join([][]byte{ftyp, mdat, moov}, nil))
+		require.NoError(t, err)
+		assert.Equal(t, 3*time.Second, duration)
+	})
+
+	t.Run("Nero章节", func(t *testing.T) {
+		chpl := []byte{0, 0, 0, 0, 2}
+		for _, chapter := range []struct {
+			start uint64
+			title string
+		}{{0, "Intro"}, {40000000, "Main"}} {
+			start := make([]byte, 8)
+			binary.BigEndian.PutUint64(start, chapter.start)
+			chpl = append(chpl, start...)
+			chpl = append(chpl, byte(len(chapter.title)))
+			chpl = append(chpl, chapter.title...)
+		}
+
+		ftyp := mp4TestBox("ftyp", []byte("isom"), mp4TestUint32(0x200))
+		moov := mp4TestBox("moov",
+			mp4TestBox("mvhd", mp4TestTimeHeader(1000, 10000, 80)),
+			mp4TestBox("udta", mp4TestBox("chpl", chpl)),
+		)
+
+		info, err := extractor.ExtractInfo(&InfoExtractionRequest{Data: bytes.Join([][]byte{ftyp, moov}, nil), Filename: "test.mp4"})
+		require.NoError(t, err)
+		assert.Equal(t, []Chapter{
+			{Title: "Intro", Start: 0, End: 4 * time.Second},
+			{Title: "Main", Start: 4 * time.Second, End: 10 * time.Second},
+		}, info.Chapters)
+	})
+
+	t.Run("损坏的box不应该panic", func(t *testing.T) {
+		data := createTestMP4(false)
+		for _, size := range []int{40, 100, 200, 400} {
+			info, err := extractor.ExtractInfo(&InfoExtractionRequest{Data: data[:size], Filename: "test.mp4"})
+			require.NoError(t, err)
+			assert.NotNil(t, info)
+		}
+
+		// ftyp占28字节，之后依次为moov和mvhd的box头
+		corrupted := append([]byte{}, data...)
+		binary.BigEndian.PutUint32(corrupted[28:32], 0xFFFFFFFF)
+		binary.BigEndian.PutUint32(corrupted[36:40], 3)
+		info, err := extractor.ExtractInfo(&InfoExtractionRequest{Data: corrupted, Filename: "test.mp4"})
+		require.NoError(t, err, "解析失败不应该导致信息提取失败")
+		assert.Zero(t, info.Duration)
+	})
+}
+
+// TestParseMP4TimeHeader 测试版本1的64位时间字段
+func TestParseMP4TimeHeader(t *testing.T) {
+	payload := make([]byte, 32)
+	payload[0] = 1
+	binary.BigEndian.PutUint32(payload[20:24], 90000)
+	binary.BigEndian.PutUint64(payload[24:32], 1<<33)
+
+	timeScale, duration := parseMP4TimeHeader(payload)
+	assert.Equal(t, uint32(90000), timeScale)
+	assert.Equal(t, uint64(1<<33), duration)
+}
+
+// TestVideoInfoExtractor_MP4Rotation 测试按tkhd变换矩阵识别旋转并交换显示宽高
+func TestVideoInfoExtractor_MP4Rotation(t *testing.T) {
+	const one = 1 << 16
+	// createRotatedMP4 构造以1920x1080编码、按指定矩阵旋转显示的MP4
+	createRotatedMP4 := func(a, b, c, d int32) []byte {
+		track := mp4TestTrack("vide", "avc1", 12800, 128000, 1920, 1080, 250, 512, 4000)
+		// trak头(8) + tkhd头(8)之后为tkhd内容，变换矩阵位于内容偏移40处
+		matrix := track[16+40:]
+		for i, v := range []int32{a, b, 0, c, d, 0} {
+			binary.BigEndian.PutUint32(matrix[i*4:], uint32(v))
+		}
+		binary.BigEndian.PutUint32(matrix[32:36], 1<<30)
+		return bytes.Join([][]byte{
+			mp4TestBox("ftyp", []byte("isom"), mp4TestUint32(0x200)),
+			mp4TestBox("moov", mp4TestBox("mvhd", mp4TestTimeHeader(1000, 10000, 80)), track),
+		}, nil)
+	}
+
+	testCases := []struct {
+		name       string
+		a, b, c, d int32
+		rotation   int
+		width      int
+		height     int
+	}{
+		{name: "无旋转", a: one, d: one, rotation: 0, width: 1920, height: 1080},
+		{name: "竖拍旋转90度", b: one, c: -one, rotation: 90, width: 1080, height: 1920},
+		{name: "倒置旋转180度", a: -one, d: -one, rotation: 180, width: 1920, height: 1080},
+		{name: "旋转270度", b: -one, c: one, rotation: 270, width: 1080, height: 1920},
+		{name: "非90度倍数的变换按无旋转处理", a: one, b: one, c: -one, d: one, rotation: 0, width: 1920, height: 1080},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info, err := NewVideoInfoExtractor().ExtractInfo(&InfoExtractionRequest{
+				Data:     createRotatedMP4(tc.a, tc.b, tc.c, tc.d),
+				Filename: "phone.mov",
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.rotation, info.Rotation)
+			assert.Equal(t, tc.width, info.Width)
+			assert.Equal(t, tc.height, info.Height)
+		})
+	}
+}
+
+// TestVideoInfoExtractor_RealMP4 测试解析FFmpeg生成的真实视频（需要FFmpeg）
+func TestVideoInfoExtractor_RealMP4(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("跳过测试：FFmpeg不可用")
+	}
+
+	inputPath := filepath.Join(t.TempDir(), "input.mp4")
+	cmd := exec.Command("ffmpeg", "-hide_banner", "-loglevel", "error", "-y",
+		"-f", "lavfi", "-i", "testsrc=duration=2:size=640x360:rate=25",
+		"-c:v", "libx264", inputPath)
+	require.NoError(t, cmd.Run(), "生成测试视频应该成功")
+
+	data, err := os.ReadFile(inputPath)
+	require.NoError(t, err)
+
+	info, err := NewVideoInfoExtractor().ExtractInfo(&InfoExtractionRequest{
+		Data:     data[:min(len(data), 512)],
+		Filename: "input.mp4",
+		Reader:   bytes.NewReader(data),
+		Size:     int64(len(data)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 640, info.Width)
+	assert.Equal(t, 360, info.Height)
+	assert.Equal(t, "H.264", info.VideoCodec)
+	assert.InDelta(t, 2.0, info.Duration.Seconds(), 0.1)
+	assert.InDelta(t, 25.0, info.FrameRate, 0.1)
+	assert.Greater(t, info.Bitrate, int64(0))
+}
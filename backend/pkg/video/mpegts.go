@@ -0,0 +1,219 @@
+package video
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	tsPacketSize = 188
+	tsSyncByte   = 0x47
+
+	// tsScanSize 从文件开头和末尾各扫描的数据量，用于查找节目表和首尾PTS
+	tsScanSize = 2 * 1024 * 1024
+	// tsClockRate PTS时钟频率
+	tsClockRate = 90000
+	// tsPTSWrap PTS为33位计数器
+	tsPTSWrap = 1 << 33
+
+	tsPIDPAT = 0x0000
+)
+
+// tsVideoStreamTypes PMT中视频流类型与编码名称映射
+var tsVideoStreamTypes = map[byte]string{
+	0x01: "MPEG-1",
+	0x02: "MPEG-2",
+	0x10: "MPEG-4",
+	0x1B: "H.264",
+	0x24: "H.265",
+}
+
+// tsAudioStreamTypes PMT中音频流类型与编码名称映射
+var tsAudioStreamTypes = map[byte]string{
+	0x03: "MP3",
+	0x04: "MP3",
+	0x0F: "AAC",
+	0x11: "AAC",
+	0x81: "AC-3",
+	0x87: "E-AC-3",
+}
+
+// tsParser MPEG-TS解析器，解析PAT/PMT获取编码，通过首尾PTS计算时长
+// 传输流没有容器级的分辨率和帧率信息，需要解析视频码流，这里不做处理
+type tsParser struct {
+	pmtPID     int
+	videoPID   int
+	audioPID   int
+	videoCodec string
+	audioCodec string
+	firstPTS   int64
+	lastPTS    int64
+}
+
+// parseTSInfo 解析MPEG-TS文件的时长、编码和码率
+func parseTSInfo(reader io.ReaderAt, size int64, info *VideoInfo) error {
+	parser := &tsParser{pmtPID: -1, videoPID: -1, audioPID: -1, firstPTS: -1, lastPTS: -1}
+
+	head := make([]byte, min64(size, tsScanSize))
+	n, err := reader.ReadAt(head, 0)
+	if n == 0 {
+		return fmt.Errorf("读取传输流失败: %w", err)
+	}
+	parser.parsePackets(head[:n])
+	if parser.pmtPID < 0 || parser.ptsPID() < 0 {
+		return fmt.Errorf("未找到节目映射表")
+	}
+
+	// 从文件末尾按传输包边界读取，获取最后的PTS
+	if tailStart := size - tsScanSize; tailStart > int64(n) {
+		tailStart += (tsPacketSize - tailStart%tsPacketSize) % tsPacketSize
+		tail := make([]byte, size-tailStart)
+		if n, _ := reader.ReadAt(tail, tailStart); n > 0 {
+			parser.parsePackets(tail[:n])
+		}
+	}
+
+	parser.fill(info, size)
+	return nil
+}
+
+// ptsPID 用于计算时长的PID，优先使用视频流
+func (p *tsParser) ptsPID() int {
+	if p.videoPID >= 0 {
+		return p.videoPID
+	}
+	return p.audioPID
+}
+
+// parsePackets 依次解析传输包
+func (p *tsParser) parsePackets(data []byte) {
+	for offset := 0; offset+tsPacketSize <= len(data); offset += tsPacketSize {
+		packet := data[offset : offset+tsPacketSize]
+		if packet[0] != tsSyncByte {
+			continue
+		}
+
+		payloadStart := packet[1]&0x40 != 0
+		pid := int(packet[1]&0x1F)<<8 | int(packet[2])
+		adaptation := (packet[3] >> 4) & 0x03
+		if adaptation&0x01 == 0 {
+			// 没有负载
+			continue
+		}
+
+		payload := packet[4:]
+		if adaptation&0x02 != 0 {
+			if len(payload) == 0 || int(payload[0])+1 > len(payload) {
+				continue
+			}
+			payload = payload[int(payload[0])+1:]
+		}
+		if !payloadStart {
+			continue
+		}
+
+		switch {
+		case pid == tsPIDPAT:
+			p.parsePAT(payload)
+		case pid == p.pmtPID:
+			p.parsePMT(payload)
+		case pid == p.ptsPID():
+			p.parsePES(payload)
+		}
+	}
+}
+
+// tsSection 去掉指针字段后返回PSI段内容（不含CRC）
+func tsSection(payload []byte, tableID byte) []byte {
+	if len(payload) == 0 || int(payload[0])+1 >= len(payload) {
+		return nil
+	}
+	section := payload[int(payload[0])+1:]
+	if len(section) < 3 || section[0] != tableID {
+		return nil
+	}
+	length := int(section[1]&0x0F)<<8 | int(section[2])
+	if length < 4 || 3+length > len(section) {
+		return nil
+	}
+	return section[:3+length-4]
+}
+
+// parsePAT 解析节目关联表，使用第一个节目的PMT
+func (p *tsParser) parsePAT(payload []byte) {
+	if p.pmtPID >= 0 {
+		return
+	}
+	section := tsSection(payload, 0x00)
+	for i := 8; i+4 <= len(section); i += 4 {
+		program := int(section[i])<<8 | int(section[i+1])
+		if program != 0 {
+			p.pmtPID = int(section[i+2]&0x1F)<<8 | int(section[i+3])
+			return
+		}
+	}
+}
+
+// parsePMT 解析节目映射表中的音视频流
+func (p *tsParser) parsePMT(payload []byte) {
+	if p.videoPID >= 0 || p.audioPID >= 0 {
+		return
+	}
+	section := tsSection(payload, 0x02)
+	if len(section) < 12 {
+		return
+	}
+
+	programInfoLength := int(section[10]&0x0F)<<8 | int(section[11])
+	for i := 12 + programInfoLength; i+5 <= len(section); {
+		streamType := section[i]
+		pid := int(section[i+1]&0x1F)<<8 | int(section[i+2])
+		esInfoLength := int(section[i+3]&0x0F)<<8 | int(section[i+4])
+
+		if codec, ok := tsVideoStreamTypes[streamType]; ok && p.videoPID < 0 {
+			p.videoPID, p.videoCodec = pid, codec
+		}
+		if codec, ok := tsAudioStreamTypes[streamType]; ok && p.audioPID < 0 {
+			p.audioPID, p.audioCodec = pid, codec
+		}
+		i += 5 + esInfoLength
+	}
+}
+
+// parsePES 解析PES头中的PTS
+func (p *tsParser) parsePES(payload []byte) {
+	// packet_start_code_prefix(3) stream_id(1) length(2) flags(2) header_length(1) PTS(5)
+	if len(payload) < 14 || payload[0] != 0 || payload[1] != 0 || payload[2] != 1 {
+		return
+	}
+	if payload[7]&0x80 == 0 {
+		return
+	}
+
+	b := payload[9:14]
+	pts := int64(b[0]>>1&0x07)<<30 | int64(b[1])<<22 | int64(b[2]>>1)<<15 | int64(b[3])<<7 | int64(b[4]>>1)
+	if p.firstPTS < 0 {
+		p.firstPTS = pts
+	}
+	// B帧会导致PTS小幅乱序，保留最大值；计数器回绕时换算到首个PTS之后
+	if p.firstPTS-pts > tsPTSWrap/2 {
+		pts += tsPTSWrap
+	}
+	if pts > p.lastPTS {
+		p.lastPTS = pts
+	}
+}
+
+// fill 将解析结果写入视频信息
+func (p *tsParser) fill(info *VideoInfo, fileSize int64) {
+	info.VideoCodec = p.videoCodec
+	info.AudioCodec = p.audioCodec
+
+	if p.firstPTS >= 0 && p.lastPTS > p.firstPTS {
+		info.Duration = time.Duration(p.lastPTS-p.firstPTS) * time.Second / tsClockRate
+	}
+	if seconds := info.Duration.Seconds(); seconds > 0 {
+		info.Bitrate = int64(float64(fileSize*8) / seconds)
+	}
+}
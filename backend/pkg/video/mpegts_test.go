@@ -0,0 +1,117 @@
+package video
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	tsTestPMTPID   = 0x1000
+	tsTestVideoPID = 0x0100
+	tsTestAudioPID = 0x0101
+)
+
+// tsTestPacket 构造负载单元起始的传输包，剩余空间用0xFF填充
+func tsTestPacket(pid int, payload []byte) []byte {
+	packet := bytes.Repeat([]byte{0xFF}, tsPacketSize)
+	packet[0] = tsSyncByte
+	packet[1] = 0x40 | byte(pid>>8)
+	packet[2] = byte(pid)
+	packet[3] = 0x10
+	copy(packet[4:], payload)
+	return packet
+}
+
+// tsTestSection 构造PSI段，包含指针字段和4字节CRC占位
+func tsTestSection(tableID byte, body []byte) []byte {
+	length := len(body) + 4
+	section := []byte{0x00, tableID, 0xB0 | byte(length>>8), byte(length)}
+	section = append(section, body...)
+	return append(section, 0x00, 0x00, 0x00, 0x00)
+}
+
+// tsTestPES 构造带PTS的PES头
+func tsTestPES(pts int64) []byte {
+	return []byte{
+		0x00, 0x00, 0x01, 0xE0, 0x00, 0x00, 0x80, 0x80, 0x05,
+		byte(0x21 | (pts>>29)&0x0E), byte(pts >> 22), byte(0x01 | (pts>>14)&0xFE), byte(pts >> 7), byte(0x01 | (pts<<1)&0xFE),
+	}
+}
+
+// createTestTS 构造包含H.264视频流和AAC音频流的传输流，视频PTS从first到last
+func createTestTS(first, last int64, padding int) []byte {
+	pat := tsTestSection(0x00, []byte{0x00, 0x01, 0xC1, 0x00, 0x00, 0x00, 0x01, 0xE0 | byte(tsTestPMTPID>>8), byte(tsTestPMTPID & 0xFF)})
+	pmt := tsTestSection(0x02, []byte{
+		0x00, 0x01, 0xC1, 0x00, 0x00, 0xE1, 0x00, 0xF0, 0x00,
+		0x1B, 0xE0 | byte(tsTestVideoPID>>8), byte(tsTestVideoPID & 0xFF), 0xF0, 0x00,
+		0x0F, 0xE0 | byte(tsTestAudioPID>>8), byte(tsTestAudioPID & 0xFF), 0xF0, 0x00,
+	})
+
+	packets := [][]byte{
+		tsTestPacket(tsPIDPAT, pat),
+		tsTestPacket(tsTestPMTPID, pmt),
+		tsTestPacket(tsTestVideoPID, tsTestPES(first)),
+		tsTestPacket(tsTestAudioPID, tsTestPES(first+90000*100)),
+	}
+	for i := 0; i < padding; i++ {
+		packets = append(packets, tsTestPacket(0x1FFF, nil))
+	}
+	packets = append(packets,
+		tsTestPacket(tsTestVideoPID, tsTestPES(last)),
+		// B帧的PTS小于之前的帧，不影响时长
+		tsTestPacket(tsTestVideoPID, tsTestPES(last-3000)),
+	)
+	return bytes.Join(packets, nil)
+}
+
+// TestVideoInfoExtractor_TS 测试解析MPEG-TS节目表和首尾PTS
+func TestVideoInfoExtractor_TS(t *testing.T) {
+	extractor := NewVideoInfoExtractor()
+
+	t.Run("文件头中包含全部数据", func(t *testing.T) {
+		data := createTestTS(126000, 126000+90000*8, 4)
+
+		info, err := extractor.ExtractInfo(&InfoExtractionRequest{Data: data, Filename: "test.ts"})
+		require.NoError(t, err)
+		assert.Equal(t, "ts", info.Format)
+		assert.Equal(t, 8*time.Second, info.Duration)
+		assert.Equal(t, "H.264", info.VideoCodec)
+		assert.Equal(t, "AAC", info.AudioCodec)
+		assert.Equal(t, int64(len(data)*8/8), info.Bitrate)
+	})
+
+	t.Run("从文件末尾读取最后的PTS", func(t *testing.T) {
+		// 填充超过扫描范围，最后的PTS只能从文件末尾读取
+		data := createTestTS(0, 90000*60, 2*tsScanSize/tsPacketSize)
+
+		info, err := extractor.ExtractInfo(&InfoExtractionRequest{
+			Data:     data[:1024],
+			Filename: "test.ts",
+			Reader:   bytes.NewReader(data),
+			Size:     int64(len(data)),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, info.Duration)
+	})
+
+	t.Run("PTS计数器回绕", func(t *testing.T) {
+		data := createTestTS(tsPTSWrap-90000, 90000, 1)
+
+		info, err := extractor.ExtractInfo(&InfoExtractionRequest{Data: data, Filename: "test.ts"})
+		require.NoError(t, err)
+		assert.Equal(t, 2*time.Second, info.Duration)
+	})
+
+	t.Run("截断的文件不应该panic", func(t *testing.T) {
+		data := createTestTS(0, 90000, 1)
+		for _, size := range []int{tsPacketSize + 1, 2 * tsPacketSize, 3*tsPacketSize - 10} {
+			info, err := extractor.ExtractInfo(&InfoExtractionRequest{Data: data[:size], Filename: "test.ts"})
+			require.NoError(t, err)
+			assert.Zero(t, info.Duration)
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package video
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+)
+
+const (
+	// PreviewContentType 动态预览MIME类型
+	// 标准库不支持WebP编码，动态预览统一输出为GIF
+	PreviewContentType = "image/gif"
+
+	// maxPreviewFrames 动态预览最大帧数
+	maxPreviewFrames = 60
+)
+
+// AnimatedPreviewOptions 动态预览选项
+type AnimatedPreviewOptions struct {
+	Width      int `json:"width"`       // 宽度
+	Height     int `json:"height"`      // 高度
+	Frames     int `json:"frames"`      // 帧数
+	FrameDelay int `json:"frame_delay"` // 每帧显示时长（毫秒）
+}
+
+// AnimatedPreviewRequest 动态预览生成请求
+type AnimatedPreviewRequest struct {
+	VideoPath string                  `json:"video_path"` // 本地视频文件路径
+	Duration  float64                 `json:"duration"`   // 视频时长（秒），未知时从开头连续截帧
+	Options   *AnimatedPreviewOptions `json:"options"`    // 生成选项
+}
+
+// AnimatedPreviewResult 动态预览生成结果
+type AnimatedPreviewResult struct {
+	ImageData []byte `json:"image_data"` // GIF数据
+	Width     int    `json:"width"`      // 宽度
+	Height    int    `json:"height"`     // 高度
+	Frames    int    `json:"frames"`     // 实际帧数
+	FileSize  int64  `json:"file_size"`  // 文件大小
+}
+
+// GenerateAnimatedPreview 在整个视频范围内均匀截帧，生成循环播放的短动态预览
+func (g *ThumbnailGenerator) GenerateAnimatedPreview(request *AnimatedPreviewRequest) (*AnimatedPreviewResult, error) {
+	if request.VideoPath == "" {
+		return nil, fmt.Errorf("视频文件路径为空")
+	}
+
+	options := request.Options
+	if options == nil {
+		options = g.GetDefaultPreviewOptions()
+	}
+	if err := g.ValidatePreviewOptions(options); err != nil {
+		return nil, err
+	}
+
+	if !g.CanExtractFrames() {
+		return nil, fmt.Errorf("视频帧提取器不可用")
+	}
+
+	frameOptions := &ThumbnailOptions{
+		Width:      options.Width,
+		Height:     options.Height,
+		Format:     "jpeg",
+		Quality:    80,
+		KeepAspect: true,
+	}
+	bounds := image.Rect(0, 0, options.Width, options.Height)
+	animation := &gif.GIF{LoopCount: 0}
+
+	for i := 0; i < options.Frames; i++ {
+		frameOptions.TimeOffset = previewFrameOffset(i, request.Duration, options)
+
+		frame, err := g.extractFrameFromFile(request.VideoPath, frameOptions)
+		if err != nil {
+			// 时长未知或不准确时，后续时间点可能没有视频帧
+			if i > 0 {
+				break
+			}
+			return nil, fmt.Errorf("提取视频帧失败: %w", err)
+		}
+
+		canvas := image.NewRGBA(bounds)
+		draw.Draw(canvas, bounds, &image.Uniform{color.Black}, image.Point{}, draw.Src)
+		drawFitted(canvas, bounds, frame)
+
+		animation.Image = append(animation.Image, quantizeWebSafe(canvas))
+		// GIF帧延迟单位为1/100秒
+		animation.Delay = append(animation.Delay, max(1, options.FrameDelay/10))
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, animation); err != nil {
+		return nil, fmt.Errorf("GIF编码失败: %v", err)
+	}
+
+	return &AnimatedPreviewResult{
+		ImageData: buf.Bytes(),
+		Width:     options.Width,
+		Height:    options.Height,
+		Frames:    len(animation.Image),
+		FileSize:  int64(buf.Len()),
+	}, nil
+}
+
+// ValidatePreviewOptions 验证动态预览选项
+func (g *ThumbnailGenerator) ValidatePreviewOptions(options *AnimatedPreviewOptions) error {
+	if options == nil {
+		return fmt.Errorf("选项不能为空")
+	}
+
+	if options.Width < minSpriteTileSize || options.Width > maxSpriteTileSize {
+		return fmt.Errorf("宽度必须在%d到%d之间", minSpriteTileSize, maxSpriteTileSize)
+	}
+	if options.Height < minSpriteTileSize || options.Height > maxSpriteTileSize {
+		return fmt.Errorf("高度必须在%d到%d之间", minSpriteTileSize, maxSpriteTileSize)
+	}
+	if options.Frames < 1 || options.Frames > maxPreviewFrames {
+		return fmt.Errorf("帧数必须在1到%d之间", maxPreviewFrames)
+	}
+	if options.FrameDelay < 10 {
+		return fmt.Errorf("每帧显示时长不能小于10毫秒")
+	}
+
+	return nil
+}
+
+// GetDefaultPreviewOptions 获取默认动态预览选项（30帧×100毫秒，共3秒）
+func (g *ThumbnailGenerator) GetDefaultPreviewOptions() *AnimatedPreviewOptions {
+	return &AnimatedPreviewOptions{
+		Width:      240,
+		Height:     135,
+		Frames:     30,
+		FrameDelay: 100,
+	}
+}
+
+// previewFrameOffset 计算第index帧的截取时间，时长已知时均匀分布在整个视频中
+func previewFrameOffset(index int, duration float64, options *AnimatedPreviewOptions) float64 {
+	if duration <= 0 {
+		return float64(index*options.FrameDelay) / 1000
+	}
+	return duration * (float64(index) + 0.5) / float64(options.Frames)
+}
+
+// quantizeWebSafe 将图像量化到216色Web安全调色板
+// 调色板按RGB各6级排列，可直接计算索引，避免逐像素搜索最近颜色
+func quantizeWebSafe(src *image.RGBA) *image.Paletted {
+	bounds := src.Bounds()
+	dst := image.NewPaletted(bounds, palette.WebSafe)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			offset := src.PixOffset(x, y)
+			r := (int(src.Pix[offset]) + 25) / 51
+			g := (int(src.Pix[offset+1]) + 25) / 51
+			b := (int(src.Pix[offset+2]) + 25) / 51
+			dst.Pix[dst.PixOffset(x, y)] = uint8(r*36 + g*6 + b)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,79 @@
+package video
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestThumbnailGenerator_GenerateAnimatedPreview 测试生成动态预览
+func TestThumbnailGenerator_GenerateAnimatedPreview(t *testing.T) {
+	videoPath := createSpriteTestVideo(t)
+	options := &AnimatedPreviewOptions{Width: 64, Height: 48, Frames: 4, FrameDelay: 250}
+
+	t.Run("在整个视频范围内均匀截帧", func(t *testing.T) {
+		extractor := &recordingFrameExtractor{color: color.RGBA{0, 0, 255, 255}}
+		generator := NewThumbnailGenerator()
+		generator.SetFrameExtractor(extractor)
+
+		result, err := generator.GenerateAnimatedPreview(&AnimatedPreviewRequest{VideoPath: videoPath, Duration: 100, Options: options})
+		require.NoError(t, err)
+		assert.Equal(t, []float64{12.5, 37.5, 62.5, 87.5}, extractor.offsets)
+		assert.Equal(t, 4, result.Frames)
+		assert.Equal(t, int64(len(result.ImageData)), result.FileSize)
+
+		animation, err := gif.DecodeAll(bytes.NewReader(result.ImageData))
+		require.NoError(t, err, "动态预览应该是有效的GIF")
+		assert.Len(t, animation.Image, 4)
+		assert.Equal(t, []int{25, 25, 25, 25}, animation.Delay, "帧延迟应该换算为1/100秒")
+		assert.Equal(t, 0, animation.LoopCount, "应该无限循环播放")
+		assert.Equal(t, image.Rect(0, 0, 64, 48), animation.Image[0].Bounds())
+	})
+
+	t.Run("时长未知时从开头连续截帧", func(t *testing.T) {
+		extractor := &recordingFrameExtractor{color: color.White, failAt: 3}
+		generator := NewThumbnailGenerator()
+		generator.SetFrameExtractor(extractor)
+
+		result, err := generator.GenerateAnimatedPreview(&AnimatedPreviewRequest{VideoPath: videoPath, Options: options})
+		require.NoError(t, err)
+		assert.Equal(t, []float64{0, 0.25, 0.5}, extractor.offsets)
+		assert.Equal(t, 2, result.Frames, "提取失败后应该停止截帧")
+	})
+
+	t.Run("提取器不可用", func(t *testing.T) {
+		generator := NewThumbnailGenerator()
+		generator.SetFrameExtractor(nil)
+
+		_, err := generator.GenerateAnimatedPreview(&AnimatedPreviewRequest{VideoPath: videoPath, Duration: 100})
+		assert.Error(t, err)
+	})
+}
+
+// TestThumbnailGenerator_ValidatePreviewOptions 测试动态预览选项验证
+func TestThumbnailGenerator_ValidatePreviewOptions(t *testing.T) {
+	generator := NewThumbnailGenerator()
+	assert.NoError(t, generator.ValidatePreviewOptions(generator.GetDefaultPreviewOptions()), "默认选项应该有效")
+	assert.Error(t, generator.ValidatePreviewOptions(nil))
+	assert.Error(t, generator.ValidatePreviewOptions(&AnimatedPreviewOptions{Width: 64, Height: 48, Frames: 61, FrameDelay: 100}))
+	assert.Error(t, generator.ValidatePreviewOptions(&AnimatedPreviewOptions{Width: 64, Height: 48, Frames: 10, FrameDelay: 5}))
+	assert.Error(t, generator.ValidatePreviewOptions(&AnimatedPreviewOptions{Width: 8, Height: 48, Frames: 10, FrameDelay: 100}))
+}
+
+// TestQuantizeWebSafe 测试Web安全调色板量化
+func TestQuantizeWebSafe(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	src.Set(0, 0, color.RGBA{0, 0, 255, 255})
+	src.Set(1, 0, color.RGBA{250, 130, 10, 255})
+	src.Set(2, 0, color.White)
+
+	dst := quantizeWebSafe(src)
+	assert.Equal(t, color.RGBA{0x00, 0x00, 0xff, 0xff}, dst.At(0, 0))
+	assert.Equal(t, color.RGBA{0xff, 0x99, 0x00, 0xff}, dst.At(1, 0))
+	assert.Equal(t, color.RGBA{0xff, 0xff, 0xff, 0xff}, dst.At(2, 0))
+}
@@ -0,0 +1,75 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// FFprobeChecker 基于ffprobe的视频完整性检查，由ffprobe解析完整文件并读取视频流信息
+// 比ValidateStructure更严格，可以发现容器结构正确但码流无法解析的文件，需要本地文件
+type FFprobeChecker struct {
+	ffprobePath string
+	timeout     time.Duration
+}
+
+// NewFFprobeChecker 创建ffprobe检查器
+func NewFFprobeChecker(ffprobePath string) *FFprobeChecker {
+	if ffprobePath == "" {
+		ffprobePath = "ffprobe"
+	}
+	return &FFprobeChecker{
+		ffprobePath: ffprobePath,
+		timeout:     60 * time.Second,
+	}
+}
+
+// IsAvailable 检查ffprobe是否可用
+func (c *FFprobeChecker) IsAvailable() bool {
+	_, err := exec.LookPath(c.ffprobePath)
+	return err == nil
+}
+
+// Check 检查本地视频文件，ffprobe无法解析或没有视频流时返回ErrInvalidStructure
+// ffprobe无法执行或超时时返回其他错误
+func (c *FFprobeChecker) Check(ctx context.Context, path string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.ffprobePath, c.buildArgs(path)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && ctx.Err() == nil {
+			return fmt.Errorf("%w: ffprobe无法解析文件: %s", ErrInvalidStructure, strings.TrimSpace(stderr.String()))
+		}
+		return fmt.Errorf("ffprobe执行失败: %w", err)
+	}
+
+	// 输出格式为"video,宽,高"
+	fields := strings.Split(strings.TrimSpace(stdout.String()), ",")
+	if fields[0] != "video" {
+		return fmt.Errorf("%w: ffprobe未找到视频流", ErrInvalidStructure)
+	}
+	if len(fields) >= 3 && (fields[1] == "0" || fields[2] == "0") {
+		return fmt.Errorf("%w: 视频流分辨率无效", ErrInvalidStructure)
+	}
+	return nil
+}
+
+// buildArgs 构建ffprobe命令行参数，只输出第一个视频流的类型和分辨率
+func (c *FFprobeChecker) buildArgs(path string) []string {
+	return []string{
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "stream=codec_type,width,height",
+		"-of", "csv=p=0",
+		path,
+	}
+}
@@ -0,0 +1,53 @@
+package video
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFakeFFprobe 写入模拟ffprobe的脚本，输出固定内容并以指定状态码退出
+func writeFakeFFprobe(t *testing.T, output string, exitCode int) string {
+	path := filepath.Join(t.TempDir(), "ffprobe")
+	script := "#!/bin/sh\necho '" + output + "'\nexit " + strconv.Itoa(exitCode) + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+	return path
+}
+
+// TestFFprobeChecker_Check 测试根据ffprobe的输出判断文件是否有效
+func TestFFprobeChecker_Check(t *testing.T) {
+	ctx := context.Background()
+
+	checker := NewFFprobeChecker(writeFakeFFprobe(t, "video,1280,720", 0))
+	assert.True(t, checker.IsAvailable())
+	assert.NoError(t, checker.Check(ctx, "clip.mp4"))
+
+	testCases := map[string]*FFprobeChecker{
+		"没有视频流":  NewFFprobeChecker(writeFakeFFprobe(t, "", 0)),
+		"分辨率为0":  NewFFprobeChecker(writeFakeFFprobe(t, "video,0,0", 0)),
+		"无法解析文件": NewFFprobeChecker(writeFakeFFprobe(t, "moov atom not found", 1)),
+	}
+	for name, checker := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, checker.Check(ctx, "clip.mp4"), ErrInvalidStructure)
+		})
+	}
+
+	missing := NewFFprobeChecker(filepath.Join(t.TempDir(), "missing-ffprobe"))
+	assert.False(t, missing.IsAvailable())
+	err := missing.Check(ctx, "clip.mp4")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidStructure, "ffprobe无法执行时不应该判定文件无效")
+}
+
+// TestFFprobeChecker_BuildArgs 测试ffprobe命令行参数
+func TestFFprobeChecker_BuildArgs(t *testing.T) {
+	args := NewFFprobeChecker("").buildArgs("/tmp/clip.mp4")
+	assert.Equal(t, "/tmp/clip.mp4", args[len(args)-1])
+	assert.Contains(t, args, "v:0")
+}
@@ -0,0 +1,96 @@
+package video
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// 保持宽高比时的缩放模式，为空时等比缩放到目标尺寸以内，输出尺寸与原图比例一致
+const (
+	// FitContain 等比缩放到目标尺寸以内，空白区域以黑边填充（letterbox），输出尺寸等于目标尺寸
+	FitContain = "contain"
+	// FitCover 等比缩放至覆盖目标尺寸，居中裁剪超出部分，输出尺寸等于目标尺寸
+	FitCover = "cover"
+)
+
+// IsValidFit 检查缩放模式是否有效
+func IsValidFit(fit string) bool {
+	return fit == "" || fit == FitContain || fit == FitCover
+}
+
+// resizeImage 按选项的尺寸、KeepAspect和Fit缩放图像（最近邻采样），与FFmpeg截帧使用相同的缩放语义
+// 不保持宽高比时拉伸到目标尺寸；尺寸已符合要求时直接返回原图
+func resizeImage(src image.Image, options *ThumbnailOptions) image.Image {
+	bounds := src.Bounds()
+	if bounds.Empty() {
+		return src
+	}
+	target := image.Rect(0, 0, options.Width, options.Height)
+
+	if !options.KeepAspect {
+		return scaleImage(src, bounds, target)
+	}
+
+	switch options.Fit {
+	case FitContain:
+		width, height := fitSize(bounds.Dx(), bounds.Dy(), options.Width, options.Height)
+		if width == options.Width && height == options.Height {
+			return scaleImage(src, bounds, target)
+		}
+		dst := image.NewRGBA(target)
+		draw.Draw(dst, target, &image.Uniform{color.Black}, image.Point{}, draw.Src)
+		offset := image.Pt((options.Width-width)/2, (options.Height-height)/2)
+		scaleInto(dst, image.Rectangle{Min: offset, Max: offset.Add(image.Pt(width, height))}, src, bounds)
+		return dst
+	case FitCover:
+		return scaleImage(src, coverCrop(bounds, options.Width, options.Height), target)
+	default:
+		width, height := fitSize(bounds.Dx(), bounds.Dy(), options.Width, options.Height)
+		return scaleImage(src, bounds, image.Rect(0, 0, width, height))
+	}
+}
+
+// fitSize 计算等比缩放到目标尺寸以内的最大尺寸
+func fitSize(width, height, maxWidth, maxHeight int) (int, int) {
+	scale := math.Min(float64(maxWidth)/float64(width), float64(maxHeight)/float64(height))
+	return max(1, min(maxWidth, int(math.Round(float64(width)*scale)))),
+		max(1, min(maxHeight, int(math.Round(float64(height)*scale))))
+}
+
+// coverCrop 计算等比覆盖目标尺寸时原图中居中保留的区域
+func coverCrop(bounds image.Rectangle, width, height int) image.Rectangle {
+	scale := math.Max(float64(width)/float64(bounds.Dx()), float64(height)/float64(bounds.Dy()))
+	cropWidth := max(1, min(bounds.Dx(), int(math.Round(float64(width)/scale))))
+	cropHeight := max(1, min(bounds.Dy(), int(math.Round(float64(height)/scale))))
+	offset := image.Pt((bounds.Dx()-cropWidth)/2, (bounds.Dy()-cropHeight)/2)
+	return image.Rectangle{Min: bounds.Min.Add(offset), Max: bounds.Min.Add(offset).Add(image.Pt(cropWidth, cropHeight))}
+}
+
+// scaleImage 将原图的srcRect区域缩放为dstRect尺寸的新图像，区域与尺寸均未变化时直接返回原图
+func scaleImage(src image.Image, srcRect, dstRect image.Rectangle) image.Image {
+	if srcRect == src.Bounds() && srcRect.Dx() == dstRect.Dx() && srcRect.Dy() == dstRect.Dy() {
+		return src
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstRect.Dx(), dstRect.Dy()))
+	scaleInto(dst, dst.Bounds(), src, srcRect)
+	return dst
+}
+
+// scaleInto 将原图的srcRect区域缩放绘制到目标图像的dstRect区域（最近邻采样）
+func scaleInto(dst *image.RGBA, dstRect image.Rectangle, src image.Image, srcRect image.Rectangle) {
+	width, height := dstRect.Dx(), dstRect.Dy()
+	if width == srcRect.Dx() && height == srcRect.Dy() {
+		draw.Draw(dst, dstRect, src, srcRect.Min, draw.Src)
+		return
+	}
+
+	for y := 0; y < height; y++ {
+		srcY := srcRect.Min.Y + y*srcRect.Dy()/height
+		for x := 0; x < width; x++ {
+			srcX := srcRect.Min.X + x*srcRect.Dx()/width
+			dst.Set(dstRect.Min.X+x, dstRect.Min.Y+y, src.At(srcX, srcY))
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package video
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stripedImage 生成左半红色、右半蓝色的测试图像
+func stripedImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+// TestResizeImage 测试各缩放模式的输出尺寸和内容
+func TestResizeImage(t *testing.T) {
+	src := stripedImage(1280, 720)
+	options := func(keepAspect bool, fit string) *ThumbnailOptions {
+		return &ThumbnailOptions{Width: 320, Height: 240, KeepAspect: keepAspect, Fit: fit}
+	}
+
+	t.Run("不保持宽高比时拉伸", func(t *testing.T) {
+		img := resizeImage(src, options(false, ""))
+		assert.Equal(t, image.Rect(0, 0, 320, 240), img.Bounds())
+	})
+
+	t.Run("默认缩放到目标尺寸以内", func(t *testing.T) {
+		img := resizeImage(src, options(true, ""))
+		assert.Equal(t, image.Rect(0, 0, 320, 180), img.Bounds())
+
+		img = resizeImage(stripedImage(90, 160), options(true, ""))
+		assert.Equal(t, image.Rect(0, 0, 135, 240), img.Bounds(), "竖屏图片应该以高度为准")
+	})
+
+	t.Run("contain模式加黑边", func(t *testing.T) {
+		img := resizeImage(src, options(true, FitContain))
+		assert.Equal(t, image.Rect(0, 0, 320, 240), img.Bounds())
+		assert.Equal(t, color.RGBAModel.Convert(color.Black), color.RGBAModel.Convert(img.At(160, 10)), "上方应该是黑边")
+		assert.Equal(t, color.RGBAModel.Convert(color.Black), color.RGBAModel.Convert(img.At(160, 230)), "下方应该是黑边")
+		assert.Equal(t, color.RGBA{R: 255, A: 255}, img.At(10, 120))
+	})
+
+	t.Run("cover模式居中裁剪", func(t *testing.T) {
+		img := resizeImage(src, options(true, FitCover))
+		assert.Equal(t, image.Rect(0, 0, 320, 240), img.Bounds())
+		assert.Equal(t, color.RGBA{R: 255, A: 255}, img.At(0, 0), "不应该有黑边")
+		assert.Equal(t, color.RGBA{B: 255, A: 255}, img.At(319, 239))
+		assert.Equal(t, color.RGBA{B: 255, A: 255}, img.At(160, 120), "应该保留画面中央")
+	})
+
+	t.Run("尺寸已符合时返回原图", func(t *testing.T) {
+		frame := stripedImage(320, 180)
+		assert.Same(t, frame, resizeImage(frame, options(true, "")))
+
+		frame = stripedImage(320, 240)
+		assert.Same(t, frame, resizeImage(frame, options(true, FitContain)))
+		assert.Same(t, frame, resizeImage(frame, options(true, FitCover)))
+	})
+}
+
+// TestIsValidFit 测试缩放模式校验
+func TestIsValidFit(t *testing.T) {
+	assert.True(t, IsValidFit(""))
+	assert.True(t, IsValidFit(FitContain))
+	assert.True(t, IsValidFit(FitCover))
+	assert.False(t, IsValidFit("fill"))
+}
@@ -9,6 +9,7 @@ type SizeLimitManager struct {
 	maxFileSize   int64            // 全局最大文件大小
 	minFileSize   int64            // 全局最小文件大小
 	formatLimits  map[string]int64 // 按格式的大小限制
+	roleLimits    map[string]int64 // 按用户角色的大小限制
 }
 
 // SizeLimits 大小限制信息
@@ -25,6 +26,7 @@ func NewSizeLimitManager() *SizeLimitManager {
 		maxFileSize:  2 * 1024 * 1024 * 1024, // 2GB
 		minFileSize:  1,                      // 1字节
 		formatLimits: make(map[string]int64),
+		roleLimits:   make(map[string]int64),
 	}
 }
 
@@ -179,6 +181,77 @@ func (s *SizeLimitManager) GetFormatLimit(format string) int64 {
 	return s.maxFileSize // 返回默认限制
 }
 
+// SetRoleLimits 设置按用户角色的大小限制
+func (s *SizeLimitManager) SetRoleLimits(limits map[string]int64) {
+	s.roleLimits = make(map[string]int64)
+	for role, limit := range limits {
+		if limit > 0 {
+			s.roleLimits[role] = limit
+		}
+	}
+}
+
+// MaxFileSizeFor 获取指定格式和用户角色的最大文件大小
+// 格式或角色配置了限制时代替全局限制，两者都配置时使用较小的一个
+func (s *SizeLimitManager) MaxFileSizeFor(format, role string) int64 {
+	formatLimit, hasFormat := s.formatLimits[format]
+	roleLimit, hasRole := s.roleLimits[role]
+	switch {
+	case hasFormat && hasRole && roleLimit < formatLimit:
+		return roleLimit
+	case hasFormat:
+		return formatLimit
+	case hasRole:
+		return roleLimit
+	default:
+		return s.maxFileSize
+	}
+}
+
+// MaxUploadSize 获取用户角色上传任意格式时允许的最大文件大小，role为空时返回所有角色中最大的限制
+// 用于在读取请求体之前拒绝明显过大的上传，格式未知时只能使用上限
+func (s *SizeLimitManager) MaxUploadSize(role string) int64 {
+	roles := []string{role}
+	if role == "" {
+		for limitedRole := range s.roleLimits {
+			roles = append(roles, limitedRole)
+		}
+	}
+
+	formats := []string{""}
+	for format := range s.formatLimits {
+		formats = append(formats, format)
+	}
+
+	var largest int64
+	for _, r := range roles {
+		for _, format := range formats {
+			if limit := s.MaxFileSizeFor(format, r); limit > largest {
+				largest = limit
+			}
+		}
+	}
+	return largest
+}
+
+// ValidateSizeFor 针对特定格式和用户角色验证文件大小，format和role为空时只使用全局限制
+func (s *SizeLimitManager) ValidateSizeFor(format, role string, size int64) error {
+	if size < 0 {
+		return fmt.Errorf("文件大小无效：%d", size)
+	}
+
+	if size < s.minFileSize {
+		return fmt.Errorf("文件不能为空")
+	}
+
+	if limit := s.MaxFileSizeFor(format, role); size > limit {
+		return fmt.Errorf("文件大小超过限制，最大允许 %s，当前文件 %s",
+			s.FormatSize(limit), s.FormatSize(size))
+	}
+
+	return nil
+}
+
 // GetSupportedSizeRange 获取支持的文件大小范围
 func (s *SizeLimitManager) GetSupportedSizeRange() (min, max int64) {
 	return s.minFileSize, s.maxFileSize
@@ -285,4 +285,43 @@ func TestSizeLimitManager_ValidateWithVideoTypes(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+// TestSizeLimitManager_ValidateSizeFor 测试按格式和用户角色的大小验证
+func TestSizeLimitManager_ValidateSizeFor(t *testing.T) {
+	const MB = int64(1024 * 1024)
+	manager := NewSizeLimitManager()
+	manager.SetMaxFileSize(1000 * MB)
+	manager.SetFormatLimits(map[string]int64{"mp4": 4000 * MB, "avi": 300 * MB})
+	manager.SetRoleLimits(map[string]int64{"uploader": 500 * MB, "viewer": 0})
+
+	testCases := []struct {
+		name     string
+		format   string
+		role     string
+		expected int64
+	}{
+		{"未配置格式和角色使用全局限制", "mkv", "admin", 1000 * MB},
+		{"只配置格式限制时可以超过全局限制", "mp4", "admin", 4000 * MB},
+		{"只配置角色限制", "mkv", "uploader", 500 * MB},
+		{"同时配置时使用较小的角色限制", "mp4", "uploader", 500 * MB},
+		{"同时配置时使用较小的格式限制", "avi", "uploader", 300 * MB},
+		{"限制为0的角色不生效", "mkv", "viewer", 1000 * MB},
+		{"格式和角色为空", "", "", 1000 * MB},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, manager.MaxFileSizeFor(tc.format, tc.role))
+			assert.NoError(t, manager.ValidateSizeFor(tc.format, tc.role, tc.expected))
+			assert.Error(t, manager.ValidateSizeFor(tc.format, tc.role, tc.expected+1))
+		})
+	}
+
+	assert.Equal(t, 4000*MB, manager.MaxUploadSize("admin"), "任意格式中最大的限制")
+	assert.Equal(t, 500*MB, manager.MaxUploadSize("uploader"))
+	assert.Equal(t, 4000*MB, manager.MaxUploadSize(""), "角色未知时使用所有角色中最大的限制")
+
+	assert.Error(t, manager.ValidateSizeFor("mp4", "admin", 0), "空文件应该验证失败")
+	assert.Error(t, manager.ValidateSizeFor("mp4", "admin", -1), "负数大小应该验证失败")
+}
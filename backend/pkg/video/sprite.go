@@ -0,0 +1,281 @@
+package video
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"math"
+	"os"
+	"strings"
+)
+
+const (
+	// SpriteImageName 雪碧图在WebVTT中引用的默认文件名
+	SpriteImageName = "sprite.jpg"
+	// SpriteVTTContentType WebVTT缩略图轨道MIME类型
+	SpriteVTTContentType = "text/vtt; charset=utf-8"
+
+	// maxSpriteGrid 雪碧图最大行列数
+	maxSpriteGrid = 20
+	// minSpriteTileSize 雪碧图单帧最小边长
+	minSpriteTileSize = 16
+	// maxSpriteTileSize 雪碧图单帧最大边长
+	maxSpriteTileSize = 640
+)
+
+// SpriteSheetOptions 雪碧图选项
+type SpriteSheetOptions struct {
+	Columns    int     `json:"columns"`     // 列数
+	Rows       int     `json:"rows"`        // 最大行数
+	Interval   float64 `json:"interval"`    // 截帧间隔（秒）
+	TileWidth  int     `json:"tile_width"`  // 单帧宽度
+	TileHeight int     `json:"tile_height"` // 单帧高度
+	Quality    int     `json:"quality"`     // JPEG质量 (1-100)
+}
+
+// SpriteSheetRequest 雪碧图生成请求
+type SpriteSheetRequest struct {
+	VideoPath string              `json:"video_path"` // 本地视频文件路径，截取多帧需要可重复读取的输入
+	Duration  float64             `json:"duration"`   // 视频时长（秒），未知时为0
+	ImageURL  string              `json:"image_url"`  // WebVTT中引用的雪碧图地址，默认为SpriteImageName
+	Options   *SpriteSheetOptions `json:"options"`    // 生成选项
+}
+
+// SpriteSheetResult 雪碧图生成结果
+type SpriteSheetResult struct {
+	ImageData  []byte  `json:"image_data"`  // JPEG雪碧图数据
+	VTT        []byte  `json:"vtt"`         // WebVTT缩略图轨道
+	Width      int     `json:"width"`       // 雪碧图宽度
+	Height     int     `json:"height"`      // 雪碧图高度
+	Columns    int     `json:"columns"`     // 列数
+	Rows       int     `json:"rows"`        // 实际行数
+	TileWidth  int     `json:"tile_width"`  // 单帧宽度
+	TileHeight int     `json:"tile_height"` // 单帧高度
+	Frames     int     `json:"frames"`      // 帧数
+	Interval   float64 `json:"interval"`    // 实际截帧间隔（秒）
+}
+
+// FileFrameExtractor 可直接从本地文件提取视频帧的提取器，避免每帧复制一次视频
+type FileFrameExtractor interface {
+	ExtractFrameFromFile(path string, options *ThumbnailOptions) (image.Image, error)
+}
+
+// CanExtractFrames 是否可以提取真实视频帧
+func (g *ThumbnailGenerator) CanExtractFrames() bool {
+	return g.frameExtractor != nil && g.frameExtractor.IsAvailable()
+}
+
+// GenerateSpriteSheet 按固定间隔截取视频帧，拼接为N×M的JPEG雪碧图并生成WebVTT缩略图轨道
+// 视频时长超出雪碧图容量时会等比放大截帧间隔，保证预览覆盖整个视频
+func (g *ThumbnailGenerator) GenerateSpriteSheet(request *SpriteSheetRequest) (*SpriteSheetResult, error) {
+	if request.VideoPath == "" {
+		return nil, fmt.Errorf("视频文件路径为空")
+	}
+
+	options := request.Options
+	if options == nil {
+		options = g.GetDefaultSpriteOptions()
+	}
+	if err := g.ValidateSpriteOptions(options); err != nil {
+		return nil, err
+	}
+
+	// 雪碧图需要真实视频帧，模拟帧没有预览意义
+	if !g.CanExtractFrames() {
+		return nil, fmt.Errorf("视频帧提取器不可用")
+	}
+
+	interval, frames := spriteLayout(request.Duration, options)
+	frameOptions := &ThumbnailOptions{
+		Width:      options.TileWidth,
+		Height:     options.TileHeight,
+		Format:     "jpeg",
+		Quality:    options.Quality,
+		KeepAspect: true,
+	}
+
+	tiles := make([]image.Image, 0, frames)
+	for i := 0; i < frames; i++ {
+		// 取每个区间的中点，避开片头黑帧
+		start, end := spriteCueRange(i, frames, interval, request.Duration)
+		frameOptions.TimeOffset = (start + end) / 2
+
+		frame, err := g.extractFrameFromFile(request.VideoPath, frameOptions)
+		if err != nil {
+			// 时长未知或不准确时，后续时间点可能没有视频帧
+			if i > 0 {
+				break
+			}
+			return nil, fmt.Errorf("提取视频帧失败: %w", err)
+		}
+		tiles = append(tiles, frame)
+	}
+
+	columns := min(options.Columns, len(tiles))
+	rows := (len(tiles) + columns - 1) / columns
+	sheet := image.NewRGBA(image.Rect(0, 0, columns*options.TileWidth, rows*options.TileHeight))
+	draw.Draw(sheet, sheet.Bounds(), &image.Uniform{color.Black}, image.Point{}, draw.Src)
+	for i, tile := range tiles {
+		drawFitted(sheet, spriteTileRect(i, columns, options), tile)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, sheet, &jpeg.Options{Quality: options.Quality}); err != nil {
+		return nil, fmt.Errorf("JPEG编码失败: %v", err)
+	}
+
+	imageURL := request.ImageURL
+	if imageURL == "" {
+		imageURL = SpriteImageName
+	}
+
+	return &SpriteSheetResult{
+		ImageData:  buf.Bytes(),
+		VTT:        buildSpriteVTT(imageURL, len(tiles), columns, interval, request.Duration, options),
+		Width:      sheet.Bounds().Dx(),
+		Height:     sheet.Bounds().Dy(),
+		Columns:    columns,
+		Rows:       rows,
+		TileWidth:  options.TileWidth,
+		TileHeight: options.TileHeight,
+		Frames:     len(tiles),
+		Interval:   interval,
+	}, nil
+}
+
+// extractFrameFromFile 从本地视频文件提取一帧
+func (g *ThumbnailGenerator) extractFrameFromFile(path string, options *ThumbnailOptions) (image.Image, error) {
+	if extractor, ok := g.frameExtractor.(FileFrameExtractor); ok {
+		return extractor.ExtractFrameFromFile(path, options)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开视频文件失败: %w", err)
+	}
+	defer file.Close()
+
+	return g.frameExtractor.ExtractFrame(file, options)
+}
+
+// ValidateSpriteOptions 验证雪碧图选项
+func (g *ThumbnailGenerator) ValidateSpriteOptions(options *SpriteSheetOptions) error {
+	if options == nil {
+		return fmt.Errorf("选项不能为空")
+	}
+
+	if options.Columns < 1 || options.Columns > maxSpriteGrid {
+		return fmt.Errorf("列数必须在1到%d之间", maxSpriteGrid)
+	}
+	if options.Rows < 1 || options.Rows > maxSpriteGrid {
+		return fmt.Errorf("行数必须在1到%d之间", maxSpriteGrid)
+	}
+	if options.Interval <= 0 {
+		return fmt.Errorf("截帧间隔必须大于0")
+	}
+	if options.TileWidth < minSpriteTileSize || options.TileWidth > maxSpriteTileSize {
+		return fmt.Errorf("单帧宽度必须在%d到%d之间", minSpriteTileSize, maxSpriteTileSize)
+	}
+	if options.TileHeight < minSpriteTileSize || options.TileHeight > maxSpriteTileSize {
+		return fmt.Errorf("单帧高度必须在%d到%d之间", minSpriteTileSize, maxSpriteTileSize)
+	}
+	if options.Quality < 1 || options.Quality > 100 {
+		return fmt.Errorf("JPEG质量必须在1到100之间")
+	}
+
+	return nil
+}
+
+// GetDefaultSpriteOptions 获取默认雪碧图选项
+func (g *ThumbnailGenerator) GetDefaultSpriteOptions() *SpriteSheetOptions {
+	return &SpriteSheetOptions{
+		Columns:    10,
+		Rows:       10,
+		Interval:   10,
+		TileWidth:  160,
+		TileHeight: 90,
+		Quality:    70,
+	}
+}
+
+// ReplaceSpriteImageURL 替换WebVTT中引用的雪碧图地址，用于将相对文件名替换为可访问的URL
+func ReplaceSpriteImageURL(vtt []byte, oldURL, newURL string) []byte {
+	var buf bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(vtt))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, oldURL+"#xywh=") {
+			line = newURL + strings.TrimPrefix(line, oldURL)
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// spriteLayout 计算实际截帧间隔和帧数
+func spriteLayout(duration float64, options *SpriteSheetOptions) (float64, int) {
+	capacity := options.Columns * options.Rows
+	if duration <= 0 {
+		return options.Interval, capacity
+	}
+
+	interval := math.Max(options.Interval, duration/float64(capacity))
+	frames := int(math.Ceil(duration / interval))
+	return interval, max(1, min(frames, capacity))
+}
+
+// spriteCueRange 计算第index帧覆盖的时间区间，最后一帧截止到视频结束
+func spriteCueRange(index, frames int, interval, duration float64) (float64, float64) {
+	start := float64(index) * interval
+	end := start + interval
+	if duration > 0 && (index == frames-1 || end > duration) {
+		end = math.Max(duration, start)
+	}
+	return start, end
+}
+
+// spriteTileRect 计算第index帧在雪碧图中的区域
+func spriteTileRect(index, columns int, options *SpriteSheetOptions) image.Rectangle {
+	x := (index % columns) * options.TileWidth
+	y := (index / columns) * options.TileHeight
+	return image.Rect(x, y, x+options.TileWidth, y+options.TileHeight)
+}
+
+// buildSpriteVTT 生成WebVTT缩略图轨道，每个cue指向雪碧图中的一个区域
+func buildSpriteVTT(imageURL string, frames, columns int, interval, duration float64, options *SpriteSheetOptions) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("WEBVTT\n")
+
+	for i := 0; i < frames; i++ {
+		start, end := spriteCueRange(i, frames, interval, duration)
+		rect := spriteTileRect(i, columns, options)
+		fmt.Fprintf(&buf, "\n%s --> %s\n%s#xywh=%d,%d,%d,%d\n",
+			formatVTTTimestamp(start), formatVTTTimestamp(end),
+			imageURL, rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy())
+	}
+
+	return buf.Bytes()
+}
+
+// formatVTTTimestamp 格式化WebVTT时间戳（HH:MM:SS.mmm）
+func formatVTTTimestamp(seconds float64) string {
+	millis := int64(math.Round(seconds * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", millis/3600000, millis/60000%60, millis/1000%60, millis%1000)
+}
+
+// drawFitted 将图像等比缩放后居中绘制到目标区域（最近邻采样）
+func drawFitted(dst *image.RGBA, rect image.Rectangle, src image.Image) {
+	bounds := src.Bounds()
+	if bounds.Empty() {
+		return
+	}
+
+	width, height := fitSize(bounds.Dx(), bounds.Dy(), rect.Dx(), rect.Dy())
+	offset := rect.Min.Add(image.Pt((rect.Dx()-width)/2, (rect.Dy()-height)/2))
+	scaleInto(dst, image.Rectangle{Min: offset, Max: offset.Add(image.Pt(width, height))}, src, bounds)
+}
@@ -0,0 +1,194 @@
+package video
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingFrameExtractor 记录截帧时间点的测试用帧提取器
+type recordingFrameExtractor struct {
+	offsets []float64
+	color   color.Color
+	failAt  int // 第几次调用开始失败，0表示不失败
+}
+
+func (r *recordingFrameExtractor) IsAvailable() bool {
+	return true
+}
+
+func (r *recordingFrameExtractor) ExtractFrame(video io.Reader, options *ThumbnailOptions) (image.Image, error) {
+	if _, err := io.ReadAll(video); err != nil {
+		return nil, err
+	}
+	r.offsets = append(r.offsets, options.TimeOffset)
+	if r.failAt > 0 && len(r.offsets) >= r.failAt {
+		return nil, assert.AnError
+	}
+
+	// 模拟保持宽高比缩放后的16:9视频帧
+	frame := image.NewRGBA(image.Rect(0, 0, options.Width, options.Width*9/16))
+	for y := 0; y < frame.Bounds().Dy(); y++ {
+		for x := 0; x < frame.Bounds().Dx(); x++ {
+			frame.Set(x, y, r.color)
+		}
+	}
+	return frame, nil
+}
+
+// createSpriteTestVideo 创建测试用视频文件
+func createSpriteTestVideo(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "input.mp4")
+	require.NoError(t, os.WriteFile(path, createSampleMP4Data(), 0644))
+	return path
+}
+
+// TestThumbnailGenerator_GenerateSpriteSheet 测试生成雪碧图和WebVTT轨道
+func TestThumbnailGenerator_GenerateSpriteSheet(t *testing.T) {
+	videoPath := createSpriteTestVideo(t)
+	options := &SpriteSheetOptions{Columns: 3, Rows: 2, Interval: 10, TileWidth: 64, TileHeight: 48, Quality: 80}
+
+	t.Run("按固定间隔截帧", func(t *testing.T) {
+		extractor := &recordingFrameExtractor{color: color.RGBA{255, 0, 0, 255}}
+		generator := NewThumbnailGenerator()
+		generator.SetFrameExtractor(extractor)
+
+		result, err := generator.GenerateSpriteSheet(&SpriteSheetRequest{VideoPath: videoPath, Duration: 45, Options: options})
+		require.NoError(t, err)
+
+		assert.Equal(t, []float64{5, 15, 25, 35, 42.5}, extractor.offsets, "应该截取每个区间中点的视频帧")
+		assert.Equal(t, 5, result.Frames)
+		assert.Equal(t, 3, result.Columns)
+		assert.Equal(t, 2, result.Rows)
+		assert.Equal(t, 192, result.Width)
+		assert.Equal(t, 96, result.Height)
+		assert.Equal(t, float64(10), result.Interval)
+
+		img, err := jpeg.Decode(bytes.NewReader(result.ImageData))
+		require.NoError(t, err, "雪碧图应该是有效的JPEG")
+		assert.Equal(t, image.Rect(0, 0, 192, 96), img.Bounds())
+		r, g, b, _ := img.At(32, 24).RGBA()
+		assert.Greater(t, r>>8, uint32(200), "帧区域应该绘制视频帧")
+		assert.Less(t, g>>8+b>>8, uint32(100))
+		r, _, _, _ = img.At(32, 2).RGBA()
+		assert.Less(t, r>>8, uint32(50), "保持宽高比时上下应该留黑边")
+
+		vtt := string(result.VTT)
+		assert.True(t, strings.HasPrefix(vtt, "WEBVTT\n"))
+		assert.Contains(t, vtt, "00:00:00.000 --> 00:00:10.000\nsprite.jpg#xywh=0,0,64,48\n")
+		assert.Contains(t, vtt, "00:00:30.000 --> 00:00:40.000\nsprite.jpg#xywh=0,48,64,48\n", "第四帧应该换行")
+		assert.Contains(t, vtt, "00:00:40.000 --> 00:00:45.000\nsprite.jpg#xywh=64,48,64,48\n", "最后一帧应该截止到视频结束")
+	})
+
+	t.Run("时长超出容量时放大间隔", func(t *testing.T) {
+		extractor := &recordingFrameExtractor{color: color.White}
+		generator := NewThumbnailGenerator()
+		generator.SetFrameExtractor(extractor)
+
+		result, err := generator.GenerateSpriteSheet(&SpriteSheetRequest{
+			VideoPath: videoPath,
+			Duration:  3600,
+			ImageURL:  "https://cdn.example.com/sprite.jpg",
+			Options:   options,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 6, result.Frames, "帧数不应该超过雪碧图容量")
+		assert.Equal(t, float64(600), result.Interval)
+		assert.Contains(t, string(result.VTT), "00:50:00.000 --> 01:00:00.000\nhttps://cdn.example.com/sprite.jpg#xywh=128,48,64,48\n")
+	})
+
+	t.Run("时长未知时截帧直到失败", func(t *testing.T) {
+		extractor := &recordingFrameExtractor{color: color.White, failAt: 3}
+		generator := NewThumbnailGenerator()
+		generator.SetFrameExtractor(extractor)
+
+		result, err := generator.GenerateSpriteSheet(&SpriteSheetRequest{VideoPath: videoPath, Options: options})
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Frames)
+		assert.Equal(t, 2, result.Columns, "帧数不足一行时应该收缩列数")
+		assert.Equal(t, 1, result.Rows)
+		assert.Contains(t, string(result.VTT), "00:00:10.000 --> 00:00:20.000\nsprite.jpg#xywh=64,0,64,48\n")
+	})
+
+	t.Run("第一帧提取失败", func(t *testing.T) {
+		generator := NewThumbnailGenerator()
+		generator.SetFrameExtractor(&recordingFrameExtractor{failAt: 1})
+
+		_, err := generator.GenerateSpriteSheet(&SpriteSheetRequest{VideoPath: videoPath, Duration: 45, Options: options})
+		assert.Error(t, err)
+	})
+
+	t.Run("提取器不可用", func(t *testing.T) {
+		generator := NewThumbnailGenerator()
+		generator.SetFrameExtractor(&stubFrameExtractor{available: false})
+
+		assert.False(t, generator.CanExtractFrames())
+		_, err := generator.GenerateSpriteSheet(&SpriteSheetRequest{VideoPath: videoPath, Duration: 45})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "不可用")
+	})
+
+	t.Run("无效参数", func(t *testing.T) {
+		generator := NewThumbnailGenerator()
+		generator.SetFrameExtractor(&recordingFrameExtractor{})
+
+		_, err := generator.GenerateSpriteSheet(&SpriteSheetRequest{Duration: 45})
+		assert.Error(t, err, "缺少视频路径应该失败")
+
+		_, err = generator.GenerateSpriteSheet(&SpriteSheetRequest{
+			VideoPath: videoPath,
+			Options:   &SpriteSheetOptions{Columns: 0, Rows: 2, Interval: 10, TileWidth: 64, TileHeight: 48, Quality: 80},
+		})
+		assert.Error(t, err, "无效选项应该失败")
+	})
+}
+
+// TestThumbnailGenerator_ValidateSpriteOptions 测试雪碧图选项验证
+func TestThumbnailGenerator_ValidateSpriteOptions(t *testing.T) {
+	generator := NewThumbnailGenerator()
+	assert.NoError(t, generator.ValidateSpriteOptions(generator.GetDefaultSpriteOptions()), "默认选项应该有效")
+	assert.Error(t, generator.ValidateSpriteOptions(nil))
+
+	tests := []struct {
+		name   string
+		modify func(o *SpriteSheetOptions)
+	}{
+		{"列数过大", func(o *SpriteSheetOptions) { o.Columns = 21 }},
+		{"行数为0", func(o *SpriteSheetOptions) { o.Rows = 0 }},
+		{"间隔为0", func(o *SpriteSheetOptions) { o.Interval = 0 }},
+		{"宽度过小", func(o *SpriteSheetOptions) { o.TileWidth = 8 }},
+		{"高度过大", func(o *SpriteSheetOptions) { o.TileHeight = 1000 }},
+		{"质量无效", func(o *SpriteSheetOptions) { o.Quality = 101 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := generator.GetDefaultSpriteOptions()
+			tt.modify(options)
+			assert.Error(t, generator.ValidateSpriteOptions(options))
+		})
+	}
+}
+
+// TestReplaceSpriteImageURL 测试替换WebVTT中的雪碧图地址
+func TestReplaceSpriteImageURL(t *testing.T) {
+	vtt := "WEBVTT\n\n00:00:00.000 --> 00:00:10.000\nsprite.jpg#xywh=0,0,160,90\n"
+
+	replaced := ReplaceSpriteImageURL([]byte(vtt), SpriteImageName, "http://storage.local/sprite.jpg?sig=abc")
+	assert.Equal(t, "WEBVTT\n\n00:00:00.000 --> 00:00:10.000\nhttp://storage.local/sprite.jpg?sig=abc#xywh=0,0,160,90\n", string(replaced))
+}
+
+// TestFormatVTTTimestamp 测试WebVTT时间戳格式
+func TestFormatVTTTimestamp(t *testing.T) {
+	assert.Equal(t, "00:00:00.000", formatVTTTimestamp(0))
+	assert.Equal(t, "00:01:05.250", formatVTTTimestamp(65.25))
+	assert.Equal(t, "02:00:00.001", formatVTTTimestamp(7200.001))
+}
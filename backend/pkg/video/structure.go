@@ -0,0 +1,227 @@
+package video
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidStructure 文件结构无法解析或不包含视频数据，通常是伪造文件头或截断的文件
+var ErrInvalidStructure = errors.New("视频文件结构无效")
+
+// ValidateStructure 解析完整文件的容器结构，检查文件头之外的部分是否是有效的视频
+// 魔数只检查文件开头，修改扩展名并伪造文件头的文件可以通过格式验证，需要在保存前调用该方法
+// 检查内容：MP4/MOV的box完整、包含moov和mdat且有视频轨道；WebM/MKV包含视频轨道和Cluster；
+// FLV包含视频tag；MPEG-TS的节目映射表包含视频流；AVI包含hdrl和movi列表
+func (v *VideoValidator) ValidateStructure(reader io.ReaderAt, size int64, format string) error {
+	if reader == nil || size <= 0 {
+		return fmt.Errorf("%w: 文件为空", ErrInvalidStructure)
+	}
+
+	var err error
+	switch format {
+	case "mp4", "mov", "3gp":
+		err = validateMP4Structure(reader, size)
+	case "webm", "mkv":
+		err = validateEBMLStructure(reader, size)
+	case "flv":
+		err = validateFLVStructure(reader, size)
+	case "ts":
+		err = validateTSStructure(reader, size)
+	case "avi":
+		err = validateAVIStructure(reader, size)
+	}
+	if err != nil && !errors.Is(err, ErrInvalidStructure) {
+		return fmt.Errorf("%w: %v", ErrInvalidStructure, err)
+	}
+	return err
+}
+
+// validateMP4Structure 检查MP4/MOV的顶层box和moov中的轨道
+// 非分片文件要求视频轨道有样本，且样本表中的数据量不超过mdat的大小
+func validateMP4Structure(reader io.ReaderAt, size int64) error {
+	var moov *mp4Box
+	var mdatBytes int64
+	fragmented, first := false, true
+	err := walkMP4Boxes(reader, 0, size, func(box *mp4Box) error {
+		if first && box.Type != "ftyp" {
+			return fmt.Errorf("%w: 第一个box不是ftyp", ErrInvalidStructure)
+		}
+		first = false
+		if box.Truncated {
+			return fmt.Errorf("%w: %s box超出文件末尾，文件不完整", ErrInvalidStructure, box.Type)
+		}
+
+		switch box.Type {
+		case "moov":
+			if moov != nil {
+				return fmt.Errorf("%w: 包含多个moov box", ErrInvalidStructure)
+			}
+			moov = box
+		case "mdat":
+			mdatBytes += box.Size
+		case "moof":
+			fragmented = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if moov == nil {
+		return fmt.Errorf("%w: 未找到moov box", ErrInvalidStructure)
+	}
+	if mdatBytes == 0 {
+		return fmt.Errorf("%w: 未找到媒体数据（mdat box）", ErrInvalidStructure)
+	}
+	if moov.Size > mp4MaxMoovSize {
+		return fmt.Errorf("%w: moov box过大: %d字节", ErrInvalidStructure, moov.Size)
+	}
+
+	data := make([]byte, moov.Size)
+	if _, err := reader.ReadAt(data, moov.Offset); err != nil && err != io.EOF {
+		return fmt.Errorf("读取moov box失败: %w", err)
+	}
+	parser := &mp4Parser{}
+	if err := parser.parseContainer(data, 1); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidStructure, err)
+	}
+
+	var videoTrack *mp4Track
+	var sampleBytes int64
+	for _, track := range parser.tracks {
+		sampleBytes += track.sampleBytes
+		if track.handler == "vide" && videoTrack == nil {
+			videoTrack = track
+		}
+	}
+	if videoTrack == nil {
+		return fmt.Errorf("%w: 未找到视频轨道", ErrInvalidStructure)
+	}
+	// 分片文件的样本信息位于各个moof中，moov中的样本表为空
+	if fragmented {
+		return nil
+	}
+	if videoTrack.sampleCount == 0 {
+		return fmt.Errorf("%w: 视频轨道没有样本", ErrInvalidStructure)
+	}
+	if sampleBytes > mdatBytes {
+		return fmt.Errorf("%w: 样本数据%d字节超过mdat大小%d字节，文件不完整", ErrInvalidStructure, sampleBytes, mdatBytes)
+	}
+	return nil
+}
+
+// validateEBMLStructure 检查WebM/MKV包含视频轨道和媒体数据（Cluster）
+func validateEBMLStructure(reader io.ReaderAt, size int64) error {
+	info := &VideoInfo{}
+	if err := parseEBMLInfo(reader, size, info); err != nil {
+		return err
+	}
+	if info.VideoCodec == "" {
+		return fmt.Errorf("%w: 未找到视频轨道", ErrInvalidStructure)
+	}
+
+	segment, err := findEBMLSegment(reader, size)
+	if err != nil {
+		return err
+	}
+	foundCluster := false
+	err = walkEBMLElements(reader, segment.Offset, segment.Offset+segment.Size, func(element *ebmlElement) error {
+		if element.ID == ebmlIDCluster {
+			foundCluster = true
+			return errEBMLStop
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errEBMLStop) {
+		return err
+	}
+	if !foundCluster {
+		return fmt.Errorf("%w: 未找到媒体数据（Cluster元素）", ErrInvalidStructure)
+	}
+	return nil
+}
+
+// validateFLVStructure 检查FLV文件头声明了视频且包含视频tag
+func validateFLVStructure(reader io.ReaderAt, size int64) error {
+	header := make([]byte, flvHeaderSize)
+	if _, err := reader.ReadAt(header, 0); err != nil {
+		return fmt.Errorf("%w: 读取FLV文件头失败", ErrInvalidStructure)
+	}
+	// 文件头第5字节的最低位表示包含视频
+	if header[4]&0x01 == 0 {
+		return fmt.Errorf("%w: FLV文件头未声明视频", ErrInvalidStructure)
+	}
+
+	info := &VideoInfo{}
+	if err := parseFLVInfo(reader, size, info); err != nil {
+		return err
+	}
+	if info.VideoCodec == "" {
+		return fmt.Errorf("%w: 未找到视频tag", ErrInvalidStructure)
+	}
+	return nil
+}
+
+// validateTSStructure 检查传输流的节目映射表包含视频流
+func validateTSStructure(reader io.ReaderAt, size int64) error {
+	if size < tsPacketSize {
+		return fmt.Errorf("%w: 文件小于一个传输包", ErrInvalidStructure)
+	}
+
+	info := &VideoInfo{}
+	if err := parseTSInfo(reader, size, info); err != nil {
+		return err
+	}
+	if info.VideoCodec == "" {
+		return fmt.Errorf("%w: 节目映射表中没有视频流", ErrInvalidStructure)
+	}
+	return nil
+}
+
+// validateAVIStructure 检查AVI的RIFF块完整且包含hdrl和movi列表
+func validateAVIStructure(reader io.ReaderAt, size int64) error {
+	header := make([]byte, 12)
+	if _, err := reader.ReadAt(header, 0); err != nil {
+		return fmt.Errorf("%w: 读取RIFF头失败", ErrInvalidStructure)
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "AVI " {
+		return fmt.Errorf("%w: 无效的RIFF头", ErrInvalidStructure)
+	}
+	riffEnd := 8 + int64(binary.LittleEndian.Uint32(header[4:8]))
+	if riffEnd > size {
+		return fmt.Errorf("%w: RIFF块超出文件末尾，文件不完整", ErrInvalidStructure)
+	}
+
+	var foundHeader, foundMovie bool
+	chunk := make([]byte, 12)
+	for offset := int64(12); offset+8 <= riffEnd; {
+		n, _ := reader.ReadAt(chunk, offset)
+		if n < 8 {
+			break
+		}
+		chunkSize := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+		if offset+8+chunkSize > riffEnd {
+			return fmt.Errorf("%w: %s块超出RIFF末尾，文件不完整", ErrInvalidStructure, chunk[0:4])
+		}
+		if string(chunk[0:4]) == "LIST" && n >= 12 {
+			switch string(chunk[8:12]) {
+			case "hdrl":
+				foundHeader = true
+			case "movi":
+				foundMovie = chunkSize > 4
+			}
+		}
+		// 块按2字节对齐
+		offset += 8 + chunkSize + chunkSize%2
+	}
+
+	if !foundHeader {
+		return fmt.Errorf("%w: 未找到hdrl列表", ErrInvalidStructure)
+	}
+	if !foundMovie {
+		return fmt.Errorf("%w: 未找到媒体数据（movi列表）", ErrInvalidStructure)
+	}
+	return nil
+}
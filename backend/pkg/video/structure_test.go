@@ -0,0 +1,98 @@
+package video
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// createStructureTestMP4 构造包含指定轨道的MP4，mdatSize为0时不包含mdat
+func createStructureTestMP4(moovAtEnd bool, mdatSize int, tracks ...[]byte) []byte {
+	ftyp := mp4TestBox("ftyp", []byte("isom"), mp4TestUint32(0x200), []byte("isomavc1mp41"))
+	moov := mp4TestBox("moov", append([][]byte{mp4TestBox("mvhd", mp4TestTimeHeader(1000, 10000, 80))}, tracks...)...)
+	var mdat []byte
+	if mdatSize > 0 {
+		mdat = mp4TestBox("mdat", make([]byte, mdatSize))
+	}
+
+	if moovAtEnd {
+		return bytes.Join([][]byte{ftyp, mdat, moov}, nil)
+	}
+	return bytes.Join([][]byte{ftyp, moov, mdat}, nil)
+}
+
+// aviTestChunk 构造RIFF块，listType不为空时构造LIST块
+func aviTestChunk(id, listType string, payload []byte) []byte {
+	if listType != "" {
+		payload = append([]byte(listType), payload...)
+	}
+	chunk := make([]byte, 8, 8+len(payload))
+	copy(chunk[0:4], id)
+	binary.LittleEndian.PutUint32(chunk[4:8], uint32(len(payload)))
+	return append(chunk, payload...)
+}
+
+// createStructureTestAVI 构造包含hdrl和movi列表的AVI
+func createStructureTestAVI(movi []byte) []byte {
+	body := bytes.Join([][]byte{
+		[]byte("AVI "),
+		aviTestChunk("LIST", "hdrl", aviTestChunk("avih", "", make([]byte, 56))),
+		aviTestChunk("LIST", "movi", movi),
+	}, nil)
+	return aviTestChunk("RIFF", "", body)
+}
+
+// TestVideoValidator_ValidateStructure 测试解析完整文件的容器结构
+func TestVideoValidator_ValidateStructure(t *testing.T) {
+	validator := NewVideoValidator()
+	videoTrack := mp4TestTrack("vide", "avc1", 12800, 128000, 1280, 720, 250, 512, 16)
+	audioTrack := mp4TestTrack("soun", "mp4a", 44100, 441000, 0, 0, 100, 1024, 8)
+
+	forgedMP4 := make([]byte, 4096)
+	copy(forgedMP4, []byte{0x00, 0x00, 0x00, 0x20, 'f', 't', 'y', 'p', 'i', 's', 'o', 'm'})
+	// 样本数据共250*16+100*8字节
+	validMP4 := createStructureTestMP4(false, 4800, videoTrack, audioTrack)
+	validAVI := createStructureTestAVI(aviTestChunk("00dc", "", make([]byte, 64)))
+	validTS := createTestTS(90000, 90000*11, 10)
+	validWebM := createTestEBML("webm", "V_VP9", "A_OPUS")
+	// 去掉末尾的Cluster：4字节ID、2字节大小和4096字节数据
+	webmWithoutCluster := validWebM[:len(validWebM)-4102]
+
+	testCases := []struct {
+		name   string
+		format string
+		data   []byte
+		valid  bool
+	}{
+		{"MP4", "mp4", validMP4, true},
+		{"moov位于末尾", "mp4", createStructureTestMP4(true, 4800, videoTrack, audioTrack), true},
+		{"伪造的ftyp文件头", "mp4", forgedMP4, false},
+		{"截断的MP4", "mp4", validMP4[:len(validMP4)-100], false},
+		{"没有视频轨道", "mp4", createStructureTestMP4(false, 800, audioTrack), false},
+		{"没有mdat", "mp4", createStructureTestMP4(false, 0, videoTrack, audioTrack), false},
+		{"样本数据超过mdat", "mp4", createStructureTestMP4(false, 4799, videoTrack, audioTrack), false},
+		{"WebM", "webm", validWebM, true},
+		{"没有Cluster的WebM", "webm", webmWithoutCluster, false},
+		{"FLV", "flv", createTestFLV(nil), true},
+		{"只有音频的FLV", "flv", append([]byte{'F', 'L', 'V', 0x01, 0x04, 0x00, 0x00, 0x00, 0x09}, make([]byte, 64)...), false},
+		{"MPEG-TS", "ts", validTS, true},
+		{"只有同步字节的传输流", "ts", createTestTSPackets(20), false},
+		{"AVI", "avi", validAVI, true},
+		{"截断的AVI", "avi", validAVI[:len(validAVI)-10], false},
+		{"没有movi的AVI", "avi", aviTestChunk("RIFF", "", append([]byte("AVI "), aviTestChunk("LIST", "hdrl", nil)...)), false},
+		{"空文件", "mp4", nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validator.ValidateStructure(bytes.NewReader(tc.data), int64(len(tc.data)), tc.format)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidStructure)
+			}
+		})
+	}
+}
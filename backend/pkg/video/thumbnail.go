@@ -8,16 +8,18 @@ import (
 	"image/draw"
 	"image/jpeg"
 	"image/png"
+	"io"
 )
 
 // ThumbnailGenerator 缩略图生成器
 type ThumbnailGenerator struct {
-	validator  *VideoValidator
-	extractor  *VideoInfoExtractor
-	maxWidth   int
-	maxHeight  int
-	minWidth   int
-	minHeight  int
+	validator      *VideoValidator
+	extractor      *VideoInfoExtractor
+	frameExtractor FrameExtractor
+	maxWidth       int
+	maxHeight      int
+	minWidth       int
+	minHeight      int
 }
 
 // ThumbnailOptions 缩略图选项
@@ -28,12 +30,14 @@ type ThumbnailOptions struct {
 	Format     string  `json:"format"`      // 输出格式 (jpeg/png)
 	TimeOffset float64 `json:"time_offset"` // 时间偏移（秒）
 	KeepAspect bool    `json:"keep_aspect"` // 保持宽高比
+	Fit        string  `json:"fit"`         // 保持宽高比时的缩放模式：空（缩放到目标尺寸以内）、contain（加黑边）、cover（居中裁剪）
 }
 
 // ThumbnailRequest 缩略图生成请求
 type ThumbnailRequest struct {
-	VideoData []byte            `json:"video_data"` // 视频数据
-	Options   *ThumbnailOptions `json:"options"`    // 生成选项
+	VideoData   []byte            `json:"video_data"` // 视频数据（至少包含文件头，用于格式检测）
+	VideoReader io.Reader         `json:"-"`          // 完整视频数据流（可选，提供时用于帧提取）
+	Options     *ThumbnailOptions `json:"options"`    // 生成选项
 }
 
 // MultipleThumbnailRequest 多个缩略图生成请求
@@ -56,12 +60,13 @@ type ThumbnailResult struct {
 // NewThumbnailGenerator 创建缩略图生成器
 func NewThumbnailGenerator() *ThumbnailGenerator {
 	return &ThumbnailGenerator{
-		validator:  NewVideoValidator(),
-		extractor:  NewVideoInfoExtractor(),
-		maxWidth:   1920,
-		maxHeight:  1080,
-		minWidth:   64,
-		minHeight:  64,
+		validator:      NewVideoValidator(),
+		extractor:      NewVideoInfoExtractor(),
+		frameExtractor: NewFFmpegFrameExtractor(""),
+		maxWidth:       1920,
+		maxHeight:      1080,
+		minWidth:       64,
+		minHeight:      64,
 	}
 }
 
@@ -89,11 +94,56 @@ func (g *ThumbnailGenerator) GenerateFromVideo(request *ThumbnailRequest) (*Thum
 		return nil, err
 	}
 
-	// 由于这是一个简化实现，我们创建一个模拟的缩略图
-	// 在实际项目中，这里需要使用FFmpeg或类似的视频处理库
+	// 优先提取真实视频帧，提取器不可用或提取失败时回退到模拟缩略图
+	if g.frameExtractor != nil && g.frameExtractor.IsAvailable() {
+		var source io.Reader = bytes.NewReader(request.VideoData)
+		if request.VideoReader != nil {
+			source = request.VideoReader
+		}
+
+		frame, err := g.frameExtractor.ExtractFrame(source, options)
+		if err == nil {
+			return g.encodeThumbnail(resizeImage(frame, options), options)
+		}
+	}
+
 	return g.generateMockThumbnail(request.VideoData, options, format)
 }
 
+// SetFrameExtractor 设置视频帧提取器，传入nil时始终生成模拟缩略图
+func (g *ThumbnailGenerator) SetFrameExtractor(extractor FrameExtractor) {
+	g.frameExtractor = extractor
+}
+
+// encodeThumbnail 将图像编码为缩略图
+func (g *ThumbnailGenerator) encodeThumbnail(img image.Image, options *ThumbnailOptions) (*ThumbnailResult, error) {
+	var buf bytes.Buffer
+
+	switch options.Format {
+	case "jpeg":
+		jpegOptions := &jpeg.Options{Quality: options.Quality}
+		if err := jpeg.Encode(&buf, img, jpegOptions); err != nil {
+			return nil, fmt.Errorf("JPEG编码失败: %v", err)
+		}
+	case "png":
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, fmt.Errorf("PNG编码失败: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("不支持的输出格式: %s", options.Format)
+	}
+
+	bounds := img.Bounds()
+	return &ThumbnailResult{
+		ImageData:  buf.Bytes(),
+		Width:      bounds.Dx(),
+		Height:     bounds.Dy(),
+		Format:     options.Format,
+		FileSize:   int64(buf.Len()),
+		TimeOffset: options.TimeOffset,
+	}, nil
+}
+
 // generateMockThumbnail 生成模拟缩略图（用于演示）
 func (g *ThumbnailGenerator) generateMockThumbnail(videoData []byte, options *ThumbnailOptions, format string) (*ThumbnailResult, error) {
 	// 创建一个简单的彩色缩略图
@@ -104,7 +154,7 @@ func (g *ThumbnailGenerator) generateMockThumbnail(videoData []byte, options *Th
 	switch format {
 	case "mp4":
 		bgColor = color.RGBA{100, 149, 237, 255} // 蓝色
-	case "webm":
+	case "webm", "mkv":
 		bgColor = color.RGBA{144, 238, 144, 255} // 浅绿色
 	case "avi":
 		bgColor = color.RGBA{255, 182, 193, 255} // 浅粉色
@@ -120,34 +170,7 @@ func (g *ThumbnailGenerator) generateMockThumbnail(videoData []byte, options *Th
 	// 添加一些简单的图案（模拟视频帧）
 	g.drawVideoPattern(img, options.Width, options.Height)
 
-	// 编码图片
-	var buf bytes.Buffer
-	var fileSize int64
-
-	switch options.Format {
-	case "jpeg":
-		jpegOptions := &jpeg.Options{Quality: options.Quality}
-		if err := jpeg.Encode(&buf, img, jpegOptions); err != nil {
-			return nil, fmt.Errorf("JPEG编码失败: %v", err)
-		}
-	case "png":
-		if err := png.Encode(&buf, img); err != nil {
-			return nil, fmt.Errorf("PNG编码失败: %v", err)
-		}
-	default:
-		return nil, fmt.Errorf("不支持的输出格式: %s", options.Format)
-	}
-
-	fileSize = int64(buf.Len())
-
-	return &ThumbnailResult{
-		ImageData:  buf.Bytes(),
-		Width:      options.Width,
-		Height:     options.Height,
-		Format:     options.Format,
-		FileSize:   fileSize,
-		TimeOffset: options.TimeOffset,
-	}, nil
+	return g.encodeThumbnail(img, options)
 }
 
 // drawVideoPattern 绘制视频图案
@@ -278,6 +301,10 @@ func (g *ThumbnailGenerator) ValidateOptions(options *ThumbnailOptions) error {
 		}
 	}
 
+	if !IsValidFit(options.Fit) {
+		return fmt.Errorf("不支持的缩放模式: %s，支持的模式: [%s %s]", options.Fit, FitContain, FitCover)
+	}
+
 	// 验证时间偏移
 	if options.TimeOffset < 0 {
 		return fmt.Errorf("时间偏移不能为负数")
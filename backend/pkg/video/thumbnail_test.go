@@ -5,6 +5,7 @@ import (
 	"image"
 	"image/jpeg"
 	"image/png"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -35,9 +36,9 @@ func TestThumbnailGenerator_GenerateFromVideo(t *testing.T) {
 			expectValid: true,
 		},
 		{
-			name:      "使用默认选项",
-			videoData: createSampleMP4Data(),
-			options:   nil, // 使用默认选项
+			name:        "使用默认选项",
+			videoData:   createSampleMP4Data(),
+			options:     nil, // 使用默认选项
 			expectValid: true,
 		},
 		{
@@ -386,7 +387,7 @@ func TestThumbnailGenerator_EstimateFileSize(t *testing.T) {
 			width:    640,
 			height:   480,
 			format:   "png",
-			quality:  0, // PNG不使用质量参数
+			quality:  0,      // PNG不使用质量参数
 			expected: 800000, // 约800KB
 		},
 	}
@@ -394,11 +395,11 @@ func TestThumbnailGenerator_EstimateFileSize(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			estimatedSize := generator.EstimateFileSize(tc.width, tc.height, tc.format, tc.quality)
-			
+
 			// 允许50%的误差范围
 			minSize := int64(float64(tc.expected) * 0.5)
 			maxSize := int64(float64(tc.expected) * 1.5)
-			
+
 			assert.GreaterOrEqual(t, estimatedSize, minSize, "估算大小不应过小")
 			assert.LessOrEqual(t, estimatedSize, maxSize, "估算大小不应过大")
 		})
@@ -435,7 +436,7 @@ func TestThumbnailGenerator_CreatePlaceholder(t *testing.T) {
 // decodeImage 解码图片数据验证其有效性
 func decodeImage(data []byte, format string) (image.Image, error) {
 	reader := bytes.NewReader(data)
-	
+
 	switch format {
 	case "jpeg":
 		return jpeg.Decode(reader)
@@ -445,4 +446,73 @@ func decodeImage(data []byte, format string) (image.Image, error) {
 		img, _, err := image.Decode(reader)
 		return img, err
 	}
-}
\ No newline at end of file
+}
+
+// stubFrameExtractor 测试用帧提取器
+type stubFrameExtractor struct {
+	available bool
+	frame     image.Image
+	err       error
+}
+
+func (s *stubFrameExtractor) IsAvailable() bool {
+	return s.available
+}
+
+func (s *stubFrameExtractor) ExtractFrame(video io.Reader, options *ThumbnailOptions) (image.Image, error) {
+	return s.frame, s.err
+}
+
+// TestThumbnailGenerator_FrameExtractor 测试真实帧提取与回退
+func TestThumbnailGenerator_FrameExtractor(t *testing.T) {
+	options := &ThumbnailOptions{
+		Width:   320,
+		Height:  240,
+		Quality: 80,
+		Format:  "png",
+	}
+
+	t.Run("使用提取的视频帧", func(t *testing.T) {
+		generator := NewThumbnailGenerator()
+		generator.SetFrameExtractor(&stubFrameExtractor{
+			available: true,
+			frame:     image.NewRGBA(image.Rect(0, 0, 320, 180)),
+		})
+		keepAspect := *options
+		keepAspect.KeepAspect = true
+
+		result, err := generator.GenerateFromVideo(&ThumbnailRequest{VideoData: createSampleMP4Data(), Options: &keepAspect})
+		require.NoError(t, err)
+		assert.Equal(t, 320, result.Width, "宽度应该来自提取的视频帧")
+		assert.Equal(t, 180, result.Height, "高度应该来自提取的视频帧")
+
+		result, err = generator.GenerateFromVideo(&ThumbnailRequest{VideoData: createSampleMP4Data(), Options: options})
+		require.NoError(t, err)
+		assert.Equal(t, 240, result.Height, "不保持宽高比时应该拉伸到目标尺寸")
+
+		keepAspect.Fit = FitCover
+		result, err = generator.GenerateFromVideo(&ThumbnailRequest{VideoData: createSampleMP4Data(), Options: &keepAspect})
+		require.NoError(t, err)
+		assert.Equal(t, 320, result.Width)
+		assert.Equal(t, 240, result.Height, "提取器未按选项裁剪时应该补充裁剪")
+	})
+
+	t.Run("提取失败时回退到模拟缩略图", func(t *testing.T) {
+		generator := NewThumbnailGenerator()
+		generator.SetFrameExtractor(&stubFrameExtractor{available: true, err: assert.AnError})
+
+		result, err := generator.GenerateFromVideo(&ThumbnailRequest{VideoData: createSampleMP4Data(), Options: options})
+		require.NoError(t, err, "提取失败不应该导致生成失败")
+		assert.Equal(t, 320, result.Width)
+		assert.Equal(t, 240, result.Height)
+	})
+
+	t.Run("提取器不可用时回退到模拟缩略图", func(t *testing.T) {
+		generator := NewThumbnailGenerator()
+		generator.SetFrameExtractor(&stubFrameExtractor{available: false})
+
+		result, err := generator.GenerateFromVideo(&ThumbnailRequest{VideoData: createSampleMP4Data(), Options: options})
+		require.NoError(t, err)
+		assert.Equal(t, 240, result.Height)
+	})
+}
@@ -4,9 +4,35 @@ import (
 	"bytes"
 	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
+
+	"github.com/manteia/zhulong/pkg/config"
 )
 
+// KnownFormats 可通过魔数识别的视频格式，允许上传的格式只能从中选择
+var KnownFormats = []string{"mp4", "mov", "3gp", "webm", "mkv", "avi", "flv", "ts"}
+
+// formatContentTypes 视频格式对应的标准内容类型
+var formatContentTypes = map[string]string{
+	"mp4":  "video/mp4",
+	"mov":  "video/quicktime",
+	"3gp":  "video/3gpp",
+	"webm": "video/webm",
+	"mkv":  "video/x-matroska",
+	"avi":  "video/x-msvideo",
+	"flv":  "video/x-flv",
+	"ts":   "video/mp2t",
+}
+
+// ContentTypeForFormat 获取视频格式对应的内容类型，未知格式返回application/octet-stream
+func ContentTypeForFormat(format string) string {
+	if contentType, ok := formatContentTypes[strings.ToLower(format)]; ok {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
 // VideoValidator 视频格式验证器
 type VideoValidator struct {
 	supportedFormats   map[string]bool
@@ -64,21 +90,95 @@ func NewVideoValidator() *VideoValidator {
 	return validator
 }
 
-// initSupportedFormats 初始化支持的格式
+// NewVideoValidatorFromConfig 根据上传配置创建视频验证器
+// 允许的格式优先使用upload.allowed_formats，未配置时由upload.allowed_types对应的格式决定
+func NewVideoValidatorFromConfig(cfg *config.Config) (*VideoValidator, error) {
+	validator := NewVideoValidator()
+
+	maxSize, err := cfg.GetUploadMaxSize()
+	if err != nil {
+		return nil, fmt.Errorf("解析上传文件大小限制失败: %w", err)
+	}
+	if maxSize > 0 {
+		validator.maxFileSize = maxSize
+	}
+
+	formats := cfg.GetAllowedFormats()
+	if types := cfg.GetAllowedTypes(); len(types) > 0 {
+		mapping := make(map[string]string, len(types))
+		var typeFormats []string
+		for _, item := range types {
+			// 内置映射之外的内容类型使用"类型=格式"指定对应的格式
+			contentType, format, custom := strings.Cut(item, "=")
+			contentType = strings.TrimSpace(contentType)
+			if custom {
+				format = strings.TrimSpace(format)
+			} else {
+				format = validator.contentTypeMapping[contentType]
+			}
+			if !slices.Contains(KnownFormats, format) {
+				return nil, fmt.Errorf("无法识别的内容类型: %s", item)
+			}
+
+			mapping[contentType] = format
+			if !slices.Contains(typeFormats, format) {
+				typeFormats = append(typeFormats, format)
+			}
+		}
+		validator.contentTypeMapping = mapping
+
+		if len(formats) == 0 {
+			formats = typeFormats
+		}
+	}
+
+	if len(formats) > 0 {
+		if err := validator.SetSupportedFormats(formats); err != nil {
+			return nil, err
+		}
+	}
+
+	return validator, nil
+}
+
+// initSupportedFormats 初始化支持的格式，默认允许所有可识别的格式
 func (v *VideoValidator) initSupportedFormats() {
-	formats := []string{"mp4", "webm", "avi", "mov"}
-	for _, format := range formats {
+	for _, format := range KnownFormats {
 		v.supportedFormats[format] = true
 	}
 }
 
+// SetSupportedFormats 设置允许的视频格式，格式必须属于KnownFormats
+func (v *VideoValidator) SetSupportedFormats(formats []string) error {
+	if len(formats) == 0 {
+		return fmt.Errorf("支持的格式列表不能为空")
+	}
+
+	supported := make(map[string]bool, len(formats))
+	for _, format := range formats {
+		format = strings.ToLower(strings.TrimSpace(format))
+		if !slices.Contains(KnownFormats, format) {
+			return fmt.Errorf("无法识别的视频格式: %s", format)
+		}
+		supported[format] = true
+	}
+
+	v.supportedFormats = supported
+	return nil
+}
+
 // initContentTypeMapping 初始化内容类型映射
 func (v *VideoValidator) initContentTypeMapping() {
 	v.contentTypeMapping["video/mp4"] = "mp4"
 	v.contentTypeMapping["video/webm"] = "webm"
+	v.contentTypeMapping["video/x-matroska"] = "mkv"
 	v.contentTypeMapping["video/avi"] = "avi"
 	v.contentTypeMapping["video/x-msvideo"] = "avi"
 	v.contentTypeMapping["video/quicktime"] = "mov"
+	v.contentTypeMapping["video/3gpp"] = "3gp"
+	v.contentTypeMapping["video/3gpp2"] = "3gp"
+	v.contentTypeMapping["video/x-flv"] = "flv"
+	v.contentTypeMapping["video/mp2t"] = "ts"
 }
 
 // initMagicNumbers 初始化文件魔数
@@ -88,12 +188,24 @@ func (v *VideoValidator) initMagicNumbers() {
 	
 	// WebM 魔数：EBML header
 	v.magicNumbers["webm"] = []byte{0x1A, 0x45, 0xDF, 0xA3}
+
+	// MKV 魔数：与WebM相同的EBML header，通过DocType区分
+	v.magicNumbers["mkv"] = []byte{0x1A, 0x45, 0xDF, 0xA3}
 	
 	// AVI 魔数：RIFF...AVI
 	v.magicNumbers["avi"] = []byte{0x52, 0x49, 0x46, 0x46} // RIFF
 	
 	// MOV 魔数：ftyp
 	v.magicNumbers["mov"] = []byte{0x66, 0x74, 0x79, 0x70}
+
+	// 3GP 魔数：ftyp，通过品牌标识区分
+	v.magicNumbers["3gp"] = []byte{0x66, 0x74, 0x79, 0x70}
+
+	// FLV 魔数：FLV
+	v.magicNumbers["flv"] = []byte{0x46, 0x4C, 0x56}
+
+	// MPEG-TS 魔数：每188字节的传输包以同步字节开头
+	v.magicNumbers["ts"] = []byte{tsSyncByte}
 }
 
 // ValidateFormat 验证视频格式
@@ -143,8 +255,11 @@ func (v *VideoValidator) DetectFormatByMagicNumber(data []byte) (string, error)
 		return "", fmt.Errorf("数据长度不足以检测格式")
 	}
 
-	// 检测WebM格式（EBML header）
+	// 检测WebM和MKV格式（都使用EBML header，DocType为matroska时为MKV）
 	if bytes.HasPrefix(data, v.magicNumbers["webm"]) {
+		if parseEBMLDocType(data) == "matroska" {
+			return "mkv", nil
+		}
 		return "webm", nil
 	}
 
@@ -156,7 +271,17 @@ func (v *VideoValidator) DetectFormatByMagicNumber(data []byte) (string, error)
 		}
 	}
 
-	// 检测MP4和MOV格式（都使用FTYP box）
+	// 检测FLV格式
+	if bytes.HasPrefix(data, v.magicNumbers["flv"]) {
+		return "flv", nil
+	}
+
+	// 检测MPEG-TS格式，要求连续的传输包都以同步字节开头，避免误判
+	if v.isMPEGTS(data) {
+		return "ts", nil
+	}
+
+	// 检测MP4、MOV和3GP格式（都使用FTYP box）
 	if len(data) >= 12 {
 		// 查找ftyp标识（可能在偏移4的位置）
 		if bytes.Equal(data[4:8], v.magicNumbers["mp4"]) {
@@ -187,12 +312,30 @@ func (v *VideoValidator) DetectFormatByMagicNumber(data []byte) (string, error)
 					return "mov", nil
 				}
 			}
+
+			// 3GP品牌标识：3gp4/3gp5/3gp6/3g2a等
+			if bytes.HasPrefix(brand, []byte("3gp")) || bytes.HasPrefix(brand, []byte("3g2")) {
+				return "3gp", nil
+			}
 		}
 	}
 
 	return "", fmt.Errorf("无法识别的视频格式")
 }
 
+// isMPEGTS 检查数据是否为MPEG-TS传输流，至少需要两个传输包
+func (v *VideoValidator) isMPEGTS(data []byte) bool {
+	if len(data) <= tsPacketSize {
+		return false
+	}
+	for offset := 0; offset < len(data); offset += tsPacketSize {
+		if data[offset] != tsSyncByte {
+			return false
+		}
+	}
+	return true
+}
+
 // ValidateFileSize 验证文件大小
 func (v *VideoValidator) ValidateFileSize(size int64) error {
 	if size < 0 {
@@ -216,8 +359,8 @@ func (v *VideoValidator) ValidateContentType(contentType string) error {
 		return fmt.Errorf("内容类型不能为空")
 	}
 
-	_, exists := v.contentTypeMapping[contentType]
-	if !exists {
+	format, exists := v.contentTypeMapping[contentType]
+	if !exists || !v.supportedFormats[format] {
 		return fmt.Errorf("不支持的内容类型: %s", contentType)
 	}
 
@@ -5,6 +5,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/manteia/zhulong/pkg/config"
 )
 
 // TestVideoValidator_ValidateFormat 测试视频格式验证
@@ -119,7 +121,7 @@ func TestVideoValidator_GetSupportedFormats(t *testing.T) {
 	assert.NotEmpty(t, formats, "支持的格式列表不应为空")
 
 	// 验证包含预期的格式
-	expectedFormats := []string{"mp4", "webm", "avi", "mov"}
+	expectedFormats := []string{"mp4", "webm", "mkv", "avi", "mov", "flv", "ts", "3gp"}
 	for _, expected := range expectedFormats {
 		assert.Contains(t, formats, expected, "应该支持%s格式", expected)
 	}
@@ -201,6 +203,12 @@ func TestVideoValidator_DetectFormatByMagicNumber(t *testing.T) {
 			expectedFormat: "webm",
 			expectError:    false,
 		},
+		{
+			name:           "MKV格式",
+			data:           []byte{0x1A, 0x45, 0xDF, 0xA3, 0x8B, 0x42, 0x82, 0x88, 'm', 'a', 't', 'r', 'o', 's', 'k', 'a'},
+			expectedFormat: "mkv",
+			expectError:    false,
+		},
 		{
 			name:           "AVI格式",
 			data:           []byte{0x52, 0x49, 0x46, 0x46, 0x00, 0x00, 0x00, 0x00, 0x41, 0x56, 0x49, 0x20},
@@ -213,6 +221,29 @@ func TestVideoValidator_DetectFormatByMagicNumber(t *testing.T) {
 			expectedFormat: "mov",
 			expectError:    false,
 		},
+		{
+			name:           "FLV格式",
+			data:           []byte{0x46, 0x4C, 0x56, 0x01, 0x05, 0x00, 0x00, 0x00, 0x09, 0x00, 0x00, 0x00},
+			expectedFormat: "flv",
+			expectError:    false,
+		},
+		{
+			name:           "MPEG-TS格式",
+			data:           createTestTSPackets(3),
+			expectedFormat: "ts",
+			expectError:    false,
+		},
+		{
+			name:        "只有一个传输包的数据不识别为MPEG-TS",
+			data:        createTestTSPackets(1),
+			expectError: true,
+		},
+		{
+			name:           "3GP格式",
+			data:           []byte{0x00, 0x00, 0x00, 0x14, 0x66, 0x74, 0x79, 0x70, 0x33, 0x67, 0x70, 0x35},
+			expectedFormat: "3gp",
+			expectError:    false,
+		},
 		{
 			name:        "未知格式",
 			data:        []byte{0xFF, 0xFF, 0xFF, 0xFF},
@@ -342,13 +373,104 @@ func TestVideoValidator_GetMaxFileSize(t *testing.T) {
 func TestVideoValidator_IsFormatSupported(t *testing.T) {
 	validator := NewVideoValidator()
 
-	supportedFormats := []string{"mp4", "webm", "avi", "mov"}
+	supportedFormats := []string{"mp4", "webm", "mkv", "avi", "mov", "flv", "ts", "3gp"}
 	for _, format := range supportedFormats {
 		assert.True(t, validator.IsFormatSupported(format), "%s格式应该被支持", format)
 	}
 
-	unsupportedFormats := []string{"wmv", "flv", "mkv", "rmvb"}
+	unsupportedFormats := []string{"wmv", "rmvb"}
 	for _, format := range unsupportedFormats {
 		assert.False(t, validator.IsFormatSupported(format), "%s格式不应该被支持", format)
 	}
-}
\ No newline at end of file
+}
+
+// TestContentTypeForFormat 测试视频格式对应的内容类型
+func TestContentTypeForFormat(t *testing.T) {
+	validator := NewVideoValidator()
+	for _, format := range KnownFormats {
+		contentType := ContentTypeForFormat(format)
+		assert.Equal(t, format, validator.contentTypeMapping[contentType], "%s格式的内容类型应该能映射回该格式", format)
+	}
+	assert.Equal(t, "video/x-matroska", ContentTypeForFormat("MKV"))
+	assert.Equal(t, "application/octet-stream", ContentTypeForFormat("wmv"))
+}
+
+// TestVideoValidator_SetSupportedFormats 测试配置允许的视频格式
+func TestVideoValidator_SetSupportedFormats(t *testing.T) {
+	validator := NewVideoValidator()
+
+	require.NoError(t, validator.SetSupportedFormats([]string{"MP4", " webm "}))
+	assert.ElementsMatch(t, []string{"mp4", "webm"}, validator.GetSupportedFormats(), "格式应该忽略大小写和空白")
+	assert.False(t, validator.IsFormatSupported("flv"))
+	assert.NoError(t, validator.ValidateContentType("video/webm"))
+	assert.Error(t, validator.ValidateContentType("video/x-flv"), "未允许格式的内容类型应该被拒绝")
+
+	_, err := validator.ValidateFormat(&ValidationRequest{
+		Filename: "test.flv",
+		Data:     []byte{0x46, 0x4C, 0x56, 0x01, 0x05, 0x00, 0x00, 0x00, 0x09, 0x00, 0x00, 0x00},
+	})
+	assert.Error(t, err, "未允许的格式应该验证失败")
+
+	assert.Error(t, validator.SetSupportedFormats([]string{"mp4", "wmv"}), "无法识别的格式应该返回错误")
+	assert.Error(t, validator.SetSupportedFormats(nil), "空列表应该返回错误")
+	assert.ElementsMatch(t, []string{"mp4", "webm"}, validator.GetSupportedFormats(), "设置失败时应该保留原有格式")
+}
+
+// TestNewVideoValidatorFromConfig 测试根据上传配置创建验证器
+func TestNewVideoValidatorFromConfig(t *testing.T) {
+	t.Run("未配置时使用默认值", func(t *testing.T) {
+		validator, err := NewVideoValidatorFromConfig(&config.Config{})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, KnownFormats, validator.GetSupportedFormats())
+		assert.Equal(t, int64(2*1024*1024*1024), validator.GetMaxFileSize())
+	})
+
+	t.Run("由内容类型决定允许的格式", func(t *testing.T) {
+		cfg := &config.Config{Upload: config.UploadConfig{
+			MaxSize:      "500MB",
+			AllowedTypes: "video/mp4, video/x-m4v=mp4, video/webm",
+		}}
+
+		validator, err := NewVideoValidatorFromConfig(cfg)
+		require.NoError(t, err)
+		assert.Equal(t, int64(500*1024*1024), validator.GetMaxFileSize())
+		assert.ElementsMatch(t, []string{"mp4", "webm"}, validator.GetSupportedFormats())
+		assert.NoError(t, validator.ValidateContentType("video/x-m4v"), "应该支持配置映射的内容类型")
+		assert.Error(t, validator.ValidateContentType("video/quicktime"), "未配置的内容类型应该被拒绝")
+		assert.Error(t, validator.ValidateFileSize(501*1024*1024))
+	})
+
+	t.Run("允许的格式优先于内容类型", func(t *testing.T) {
+		cfg := &config.Config{Upload: config.UploadConfig{
+			AllowedTypes:   "video/mp4,video/webm",
+			AllowedFormats: "mp4",
+		}}
+
+		validator, err := NewVideoValidatorFromConfig(cfg)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"mp4"}, validator.GetSupportedFormats())
+		assert.Error(t, validator.ValidateContentType("video/webm"))
+	})
+
+	t.Run("无效配置", func(t *testing.T) {
+		invalid := []config.UploadConfig{
+			{MaxSize: "huge"},
+			{AllowedTypes: "video/mov"},
+			{AllowedTypes: "video/x-ms-wmv=wmv"},
+			{AllowedFormats: "mp4,rmvb"},
+		}
+		for _, upload := range invalid {
+			_, err := NewVideoValidatorFromConfig(&config.Config{Upload: upload})
+			assert.Error(t, err, "配置%+v应该返回错误", upload)
+		}
+	})
+}
+
+// createTestTSPackets 构造指定数量的空传输包
+func createTestTSPackets(count int) []byte {
+	data := make([]byte, count*tsPacketSize)
+	for i := 0; i < count; i++ {
+		data[i*tsPacketSize] = tsSyncByte
+	}
+	return data
+}
@@ -0,0 +1,248 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook事件类型
+const (
+	EventVideoUploaded   = "video.uploaded"   // 视频上传完成
+	EventVideoTranscoded = "video.transcoded" // HLS转码打包完成
+	EventVideoDeleted    = "video.deleted"    // 视频已删除
+	EventThumbnailReady  = "thumbnail.ready"  // 缩略图已生成
+)
+
+// Events 支持订阅的全部事件类型
+var Events = []string{EventVideoUploaded, EventVideoTranscoded, EventVideoDeleted, EventThumbnailReady}
+
+// 请求头
+const (
+	HeaderEvent     = "X-Zhulong-Event"     // 事件类型
+	HeaderDelivery  = "X-Zhulong-Delivery"  // 投递ID，重试时保持不变，可用于去重
+	HeaderTimestamp = "X-Zhulong-Timestamp" // 签名时间戳（Unix秒）
+	HeaderSignature = "X-Zhulong-Signature" // 签名："sha256=" + HMAC-SHA256(secret, timestamp + "." + body)的十六进制
+)
+
+// 投递参数
+const (
+	defaultQueueSize   = 256              // 待投递队列长度，队列已满时丢弃新事件
+	defaultWorkers     = 4                // 并发投递的worker数量
+	defaultMaxAttempts = 3                // 每个事件的最大投递次数
+	defaultRetryDelay  = time.Second      // 首次重试的等待时间，之后每次翻倍
+	defaultTimeout     = 10 * time.Second // 单次请求超时时间
+)
+
+// Endpoint Webhook订阅端点
+type Endpoint struct {
+	URL    string   // 接收事件的地址，必须为http或https
+	Secret string   // 签名密钥，为空时不签名
+	Events []string // 订阅的事件类型，为空时订阅全部事件
+}
+
+// Payload Webhook请求体
+type Payload struct {
+	ID        string      `json:"id"`             // 投递ID
+	Event     string      `json:"event"`          // 事件类型
+	VideoID   string      `json:"video_id"`       // 视频ID
+	Timestamp int64       `json:"timestamp"`      // 事件时间戳（毫秒）
+	Data      interface{} `json:"data,omitempty"` // 事件数据
+}
+
+// delivery 待投递的事件
+type delivery struct {
+	endpoint *Endpoint
+	payload  *Payload
+	body     []byte
+}
+
+// Dispatcher Webhook分发器，在后台异步投递事件，失败时按指数退避重试
+type Dispatcher struct {
+	endpoints   []*Endpoint
+	client      *http.Client
+	queue       chan *delivery
+	maxAttempts int
+	retryDelay  time.Duration
+	ctx         context.Context // 关闭时取消，中断正在进行的请求和重试等待
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+// ValidateEndpoint 验证订阅端点的地址和事件类型
+func ValidateEndpoint(endpoint *Endpoint) error {
+	parsed, err := url.Parse(endpoint.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("无效的Webhook地址: %s", endpoint.URL)
+	}
+	for _, event := range endpoint.Events {
+		if !isKnownEvent(event) {
+			return fmt.Errorf("不支持的Webhook事件: %s", event)
+		}
+	}
+	return nil
+}
+
+// NewDispatcher 创建Webhook分发器并启动后台投递，没有订阅端点时返回nil
+func NewDispatcher(endpoints []*Endpoint) (*Dispatcher, error) {
+	if len(endpoints) == 0 {
+		return nil, nil
+	}
+	for _, endpoint := range endpoints {
+		if err := ValidateEndpoint(endpoint); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		endpoints:   endpoints,
+		client:      &http.Client{Timeout: defaultTimeout},
+		queue:       make(chan *delivery, defaultQueueSize),
+		maxAttempts: defaultMaxAttempts,
+		retryDelay:  defaultRetryDelay,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	for i := 0; i < defaultWorkers; i++ {
+		d.wg.Add(1)
+		go d.run()
+	}
+	return d, nil
+}
+
+// Dispatch 将事件投递给订阅了该事件的端点，不等待投递完成
+func (d *Dispatcher) Dispatch(event, videoID string, data interface{}) {
+	for _, endpoint := range d.endpoints {
+		if !endpoint.subscribes(event) {
+			continue
+		}
+
+		payload := &Payload{
+			ID:        uuid.New().String(),
+			Event:     event,
+			VideoID:   videoID,
+			Timestamp: time.Now().UnixMilli(),
+			Data:      data,
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			fmt.Printf("序列化Webhook事件失败(event=%s): %v\n", event, err)
+			return
+		}
+
+		select {
+		case d.queue <- &delivery{endpoint: endpoint, payload: payload, body: body}:
+		default:
+			fmt.Printf("Webhook队列已满，丢弃事件(event=%s, url=%s)\n", event, endpoint.URL)
+		}
+	}
+}
+
+// Close 停止后台投递，等待正在进行的投递结束，队列中未投递的事件会被丢弃
+func (d *Dispatcher) Close() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// run 从队列中取出事件并投递
+func (d *Dispatcher) run() {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case item := <-d.queue:
+			if err := d.deliver(item); err != nil {
+				fmt.Printf("Webhook投递失败(event=%s, url=%s): %v\n", item.payload.Event, item.endpoint.URL, err)
+			}
+		}
+	}
+}
+
+// deliver 投递事件，网络错误、5xx和429响应会重试
+func (d *Dispatcher) deliver(item *delivery) error {
+	delay := d.retryDelay
+	var lastErr error
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		retry, err := d.send(item)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry || attempt == d.maxAttempts {
+			break
+		}
+
+		select {
+		case <-d.ctx.Done():
+			return lastErr
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+	return lastErr
+}
+
+// send 发送一次请求，返回失败时是否需要重试
+func (d *Dispatcher) send(item *delivery) (bool, error) {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, item.endpoint.URL, bytes.NewReader(item.body))
+	if err != nil {
+		return false, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, item.payload.Event)
+	req.Header.Set(HeaderDelivery, item.payload.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if item.endpoint.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(item.endpoint.Secret, timestamp, item.body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("响应状态码: %d", resp.StatusCode)
+}
+
+// Sign 计算请求签名，接收方使用相同的密钥、X-Zhulong-Timestamp和原始请求体验证
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// subscribes 判断端点是否订阅了事件
+func (e *Endpoint) subscribes(event string) bool {
+	return len(e.Events) == 0 || slices.Contains(e.Events, event)
+}
+
+// isKnownEvent 判断是否为支持的事件类型
+func isKnownEvent(event string) bool {
+	return slices.Contains(Events, event)
+}
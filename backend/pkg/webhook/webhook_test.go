@@ -0,0 +1,136 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receivedRequest 测试服务器收到的请求
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newTestReceiver 创建按顺序返回指定状态码的测试服务器，状态码用完后返回200
+func newTestReceiver(t *testing.T, statuses ...int) (*httptest.Server, <-chan receivedRequest, *int32) {
+	requests := make(chan receivedRequest, 16)
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		n := int(atomic.AddInt32(&count, 1))
+		requests <- receivedRequest{header: r.Header.Clone(), body: body}
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests, &count
+}
+
+// newTestDispatcher 创建重试间隔很短的分发器
+func newTestDispatcher(t *testing.T, endpoints ...*Endpoint) *Dispatcher {
+	dispatcher, err := NewDispatcher(endpoints)
+	require.NoError(t, err)
+	dispatcher.retryDelay = time.Millisecond
+	t.Cleanup(dispatcher.Close)
+	return dispatcher
+}
+
+// receiveRequest 等待测试服务器收到请求
+func receiveRequest(t *testing.T, requests <-chan receivedRequest) receivedRequest {
+	select {
+	case req := <-requests:
+		return req
+	case <-time.After(2 * time.Second):
+		t.Fatal("等待Webhook请求超时")
+		return receivedRequest{}
+	}
+}
+
+// TestDispatcher_Dispatch 测试投递签名的事件
+func TestDispatcher_Dispatch(t *testing.T) {
+	server, requests, _ := newTestReceiver(t)
+	dispatcher := newTestDispatcher(t, &Endpoint{URL: server.URL, Secret: "secret"})
+
+	dispatcher.Dispatch(EventVideoUploaded, "video1", map[string]string{"title": "测试视频"})
+
+	req := receiveRequest(t, requests)
+	assert.Equal(t, "application/json", req.header.Get("Content-Type"))
+	assert.Equal(t, EventVideoUploaded, req.header.Get(HeaderEvent))
+	assert.Equal(t, Sign("secret", req.header.Get(HeaderTimestamp), req.body), req.header.Get(HeaderSignature), "签名应该可以用密钥验证")
+
+	var payload Payload
+	require.NoError(t, json.Unmarshal(req.body, &payload))
+	assert.Equal(t, req.header.Get(HeaderDelivery), payload.ID)
+	assert.Equal(t, EventVideoUploaded, payload.Event)
+	assert.Equal(t, "video1", payload.VideoID)
+	assert.Equal(t, map[string]interface{}{"title": "测试视频"}, payload.Data)
+	assert.NotZero(t, payload.Timestamp)
+}
+
+// TestDispatcher_EventFilter 测试按事件类型过滤
+func TestDispatcher_EventFilter(t *testing.T) {
+	server, requests, _ := newTestReceiver(t)
+	dispatcher := newTestDispatcher(t, &Endpoint{URL: server.URL, Events: []string{EventVideoDeleted}})
+
+	dispatcher.Dispatch(EventVideoUploaded, "video1", nil)
+	dispatcher.Dispatch(EventVideoDeleted, "video1", nil)
+
+	req := receiveRequest(t, requests)
+	assert.Equal(t, EventVideoDeleted, req.header.Get(HeaderEvent), "未订阅的事件不应该投递")
+	assert.Empty(t, req.header.Get(HeaderSignature), "未配置密钥时不签名")
+}
+
+// TestDispatcher_Retry 测试投递失败时重试
+func TestDispatcher_Retry(t *testing.T) {
+	t.Run("服务端错误时重试", func(t *testing.T) {
+		server, requests, count := newTestReceiver(t, http.StatusInternalServerError, http.StatusTooManyRequests)
+		dispatcher := newTestDispatcher(t, &Endpoint{URL: server.URL})
+
+		dispatcher.Dispatch(EventThumbnailReady, "video1", nil)
+
+		first := receiveRequest(t, requests)
+		receiveRequest(t, requests)
+		third := receiveRequest(t, requests)
+		assert.Equal(t, first.header.Get(HeaderDelivery), third.header.Get(HeaderDelivery), "重试时投递ID应该保持不变")
+		assert.Equal(t, int32(3), atomic.LoadInt32(count))
+	})
+
+	t.Run("客户端错误时不重试", func(t *testing.T) {
+		server, requests, count := newTestReceiver(t, http.StatusBadRequest)
+		dispatcher := newTestDispatcher(t, &Endpoint{URL: server.URL})
+
+		dispatcher.Dispatch(EventThumbnailReady, "video1", nil)
+		receiveRequest(t, requests)
+
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, int32(1), atomic.LoadInt32(count))
+	})
+}
+
+// TestNewDispatcher 测试端点配置验证
+func TestNewDispatcher(t *testing.T) {
+	dispatcher, err := NewDispatcher(nil)
+	require.NoError(t, err)
+	assert.Nil(t, dispatcher, "没有订阅端点时不需要分发器")
+
+	invalid := []*Endpoint{
+		{URL: "ftp://example.com/hook"},
+		{URL: "not a url"},
+		{URL: "https://example.com/hook", Events: []string{"video.unknown"}},
+	}
+	for _, endpoint := range invalid {
+		_, err := NewDispatcher([]*Endpoint{endpoint})
+		assert.Error(t, err, "无效的端点应该返回错误: %+v", endpoint)
+	}
+}
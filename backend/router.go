@@ -5,6 +5,8 @@ package main
 import (
 	"github.com/cloudwego/hertz/pkg/app/server"
 	handler "github.com/manteia/zhulong/biz/handler"
+	api "github.com/manteia/zhulong/biz/handler/zhulong/api"
+	"github.com/manteia/zhulong/pkg/middleware"
 )
 
 // customizeRegister registers customize routers.
@@ -12,4 +14,16 @@ func customizedRegister(r *server.Hertz) {
 	r.GET("/ping", handler.Ping)
 
 	// your code ...
+	// 本地存储驱动的预签名URL访问
+	rateLimit := middleware.RateLimit(api.RateLimitPolicy())
+	r.GET("/storage/:bucket/*object", rateLimit, api.ServeLocalFile)
+	r.HEAD("/storage/:bucket/*object", rateLimit, api.ServeLocalFile)
+	r.PUT("/storage/:bucket/*object", rateLimit, middleware.BodyLimit(api.UploadBodyLimitPolicy()),
+		middleware.UploadBandwidth(api.UploadBandwidthPolicy()), api.UploadLocalFile)
+
+	// 播放令牌签名的视频流，令牌即授权，不经过登录认证
+	r.GET("/stream/:video_id", rateLimit, api.ServeSignedStream)
+	// 投屏设备使用同一播放令牌读取海报和字幕
+	r.GET("/stream/:video_id/poster", rateLimit, api.ServeSignedPoster)
+	r.GET("/stream/:video_id/subtitles/:name", rateLimit, api.ServeSignedSubtitle)
 }
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"path/filepath"
+
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/tlscert"
+)
+
+// serverTLSConfig 根据配置创建HTTPS监听的TLS配置，未启用TLS时返回nil
+// 未配置证书文件时使用自签名证书，保存在配置文件所在目录下的tls目录中，重启后复用
+func serverTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	tlsConfig := cfg.Server.TLS
+	if !tlsConfig.Enabled {
+		return nil, nil
+	}
+
+	certFile, keyFile := tlsConfig.CertFile, tlsConfig.KeyFile
+	if certFile == "" {
+		certDir := tlsConfig.CertDir
+		if certDir == "" {
+			certDir = filepath.Join(filepath.Dir(configFile()), "tls")
+		}
+
+		hosts := tlscert.DefaultHosts()
+		// 监听所有地址时不加入证书，网卡地址已经包含在默认主机中
+		if ip := net.ParseIP(cfg.Server.Host); ip == nil || !ip.IsUnspecified() {
+			hosts = append(hosts, cfg.Server.Host)
+		}
+
+		var err error
+		certFile, keyFile, err = tlscert.EnsureSelfSigned(certDir, tlscert.MergeHosts(hosts, tlsConfig.Hosts))
+		if err != nil {
+			return nil, fmt.Errorf("生成自签名证书失败: %w", err)
+		}
+	}
+	return tlscert.LoadConfig(certFile, keyFile)
+}
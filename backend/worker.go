@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	api "github.com/manteia/zhulong/biz/model/zhulong/api"
+	"github.com/manteia/zhulong/biz/service"
+	"github.com/manteia/zhulong/pkg/config"
+	"github.com/manteia/zhulong/pkg/middleware"
+	"github.com/manteia/zhulong/pkg/storage"
+)
+
+// workerCommand 远程worker子命令名称
+const workerCommand = "worker"
+
+// workerPollInterval 没有待执行的任务或请求服务失败时，重新领取任务的间隔
+const workerPollInterval = 2 * time.Second
+
+// workerMinHeartbeatInterval 心跳间隔下限
+const workerMinHeartbeatInterval = time.Second
+
+// errWorkerLeaseLost 任务租约已失效，任务已被取消或由其他worker重新执行
+var errWorkerLeaseLost = errors.New("任务租约已失效")
+
+// runWorker 启动远程worker，从服务领取HLS打包和预览图生成任务并上报结果，返回进程退出码
+// worker与服务使用相同的配置文件，视频和处理结果直接读写共享存储
+func runWorker(args []string) int {
+	cfg, err := config.LoadFromFile(configFile())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+		return 1
+	}
+	hostname, _ := os.Hostname()
+
+	flags := flag.NewFlagSet(workerCommand, flag.ContinueOnError)
+	server := flags.String("server", "http://localhost:8888", "服务地址")
+	secret := flags.String("secret", cfg.Worker.Secret, "worker共享密钥，默认读取配置中的worker.secret")
+	workerID := flags.String("id", hostname, "worker标识，默认使用主机名")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *secret == "" || *workerID == "" {
+		fmt.Fprintf(os.Stderr, "用法: zhulong %s [-server <服务地址>] [-secret <共享密钥>] [-id <worker标识>]\n", workerCommand)
+		return 2
+	}
+
+	storageClient, err := storage.NewFromConfig(cfg.GetDriverConfig())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "初始化存储客户端失败: %v\n", err)
+		return 1
+	}
+	components, err := service.NewVideoComponents(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	processor, err := service.NewJobProcessor(cfg, storageClient, components)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "初始化任务执行器失败: %v\n", err)
+		return 1
+	}
+	kinds := processor.Kinds()
+	if len(kinds) == 0 {
+		fmt.Fprintln(os.Stderr, "ffmpeg不可用，没有可以执行的任务类型")
+		return 1
+	}
+
+	// 中断后不再领取新任务，正在执行的任务随之取消并上报失败，由服务按重试策略处理
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client := &workerClient{baseURL: strings.TrimRight(*server, "/"), secret: *secret, workerID: *workerID}
+	fmt.Printf("worker %s 已启动，任务类型: %s\n", *workerID, strings.Join(kinds, ","))
+	for {
+		job, err := client.claim(kinds)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "领取任务失败: %v\n", err)
+		}
+		if job != nil {
+			runWorkerJob(ctx, client, processor, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("worker已停止")
+			return 0
+		case <-time.After(workerPollInterval):
+		}
+	}
+}
+
+// runWorkerJob 执行任务并定期上报心跳，租约失效时取消执行且不上报结果
+func runWorkerJob(ctx context.Context, client *workerClient, processor *service.JobProcessor, job *api.WorkerJob) {
+	fmt.Printf("开始执行任务 %s(%s, %s)，第%d次\n", job.JobID, job.Kind, job.GetVideo().GetVideoID(), job.Attempts)
+
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	go heartbeatWorkerJob(jobCtx, cancel, client, job)
+
+	result := processor.Process(jobCtx, job)
+	if errors.Is(context.Cause(jobCtx), errWorkerLeaseLost) {
+		fmt.Printf("任务 %s 的租约已失效，放弃执行结果\n", job.JobID)
+		return
+	}
+
+	result.WorkerID = client.workerID
+	if err := client.complete(result); err != nil {
+		fmt.Fprintf(os.Stderr, "上报任务 %s 结果失败: %v\n", job.JobID, err)
+		return
+	}
+	if result.Error != nil {
+		fmt.Printf("任务 %s 执行失败: %s\n", job.JobID, *result.Error)
+		return
+	}
+	fmt.Printf("任务 %s 执行完成\n", job.JobID)
+}
+
+// heartbeatWorkerJob 在租约到期前续租，服务返回租约失效时取消任务
+func heartbeatWorkerJob(ctx context.Context, cancel context.CancelCauseFunc, client *workerClient, job *api.WorkerJob) {
+	interval := time.Until(time.UnixMilli(job.LeaseExpiresAt)) / 3
+	if interval < workerMinHeartbeatInterval {
+		interval = workerMinHeartbeatInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := client.heartbeat(job.JobID)
+		if errors.Is(err, errWorkerLeaseLost) {
+			cancel(err)
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "任务 %s 心跳失败: %v\n", job.JobID, err)
+		}
+	}
+}
+
+// workerClient worker接口客户端
+type workerClient struct {
+	baseURL  string
+	secret   string
+	workerID string
+}
+
+// claim 领取任务，没有待执行的任务时返回nil
+func (c *workerClient) claim(kinds []string) (*api.WorkerJob, error) {
+	return c.do("/api/v1/worker/jobs/claim", &api.WorkerJobClaimRequest{WorkerID: c.workerID, Kinds: kinds})
+}
+
+// heartbeat 续租任务
+func (c *workerClient) heartbeat(jobID string) error {
+	_, err := c.do("/api/v1/worker/jobs/"+jobID+"/heartbeat", &api.WorkerJobHeartbeatRequest{JobID: jobID, WorkerID: c.workerID})
+	return err
+}
+
+// complete 上报任务结果
+func (c *workerClient) complete(req *api.WorkerJobCompleteRequest) error {
+	_, err := c.do("/api/v1/worker/jobs/"+req.JobID+"/complete", req)
+	return err
+}
+
+// do 调用worker接口，租约失效时返回errWorkerLeaseLost
+func (c *workerClient) do(path string, body any) (*api.WorkerJob, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("编码请求失败: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set(middleware.WorkerSecretHeader, c.secret)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求服务失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result api.WorkerJobResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析响应失败(HTTP %d): %w", resp.StatusCode, err)
+	}
+	if result.Base == nil {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	switch result.Base.Code {
+	case 0:
+		return result.Job, nil
+	case 6903, 6904:
+		return nil, fmt.Errorf("%w: %s", errWorkerLeaseLost, result.Base.Message)
+	default:
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, result.Base.Message)
+	}
+}
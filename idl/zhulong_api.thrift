@@ -7,6 +7,20 @@ struct BaseResponse {
     3: optional string trace_id = ""
 }
 
+// 视频章节
+struct Chapter {
+    1: string title = ""                   // 章节标题
+    2: i64 start_time = 0                  // 开始时间（毫秒）
+    3: i64 end_time = 0                    // 结束时间（毫秒），为0时以下一章节的开始时间或视频时长作为结束时间
+}
+
+// 剪辑片段的来源
+struct ClipSource {
+    1: string video_id = ""                // 源视频ID，源视频删除后保留
+    2: i64 start_time = 0                  // 片段在源视频中的开始时间（毫秒）
+    3: i64 end_time = 0                    // 片段在源视频中的结束时间（毫秒）
+}
+
 // 视频信息结构
 struct Video {
     1: string id = ""                      // 视频唯一标识
@@ -21,12 +35,38 @@ struct Video {
     10: optional string thumbnail_path = "" // 缩略图路径
     11: i64 uploaded_at = 0                // 上传时间戳（毫秒）
     12: i64 updated_at = 0                 // 更新时间戳（毫秒）
+    13: string description = ""            // 视频描述
+    14: list<string> tags = []             // 视频标签
+    15: optional string preview_path = ""  // 动态预览路径（GIF，异步生成）
+    16: optional string checksum = ""      // 文件内容的SHA-256校验和（十六进制）
+    17: optional i64 resume_position = 0   // 当前登录用户的续播位置（秒），未播放或已看完时为0
+    18: optional i64 view_count = 0        // 播放次数（获取播放URL、开始流式播放或获取HLS主播放列表或DASH清单时计数）
+    19: optional bool is_favorited = false // 当前登录用户是否已收藏
+    20: list<Chapter> chapters = []        // 章节，按开始时间排序
+    21: bool archived = false              // 视频文件是否已归档到冷存储，播放时自动恢复
+    22: string visibility = ""             // 可见性：private（仅上传者和管理员）、unlisted（不在列表中显示）、public（局域网内公开）
+    23: string moderation_status = ""      // 内容审核状态：空值表示未被审核钩子标记，pending（待审核）、rejected（已拒绝）、approved（已通过）
+    24: string status = ""                 // 处理状态：uploading（直传或分片上传尚未完成）、processing（等待HLS打包）、ready（处理完成）、failed（HLS打包失败，仍可播放原始文件）
+    25: i32 rotation = 0                   // 显示时的顺时针旋转角度（0/90/180/270），宽高已按旋转交换为显示尺寸
+    26: string video_codec = ""            // 视频编码（如H.264、H.265），无法识别时为空
+    27: string audio_codec = ""            // 音频编码（如AAC），没有音频轨道或无法识别时为空
+    28: i64 bitrate = 0                    // 码率（bps），无法提取时为0
+    29: double frame_rate = 0              // 帧率（fps），无法提取时为0
+    30: i32 version = 0                    // 当前内容的版本号，替换视频文件后递增
+    31: string media_type = ""             // 媒体类型：video（视频）、image（图片）、audio（音频），图片和音频不进行HLS打包
+    32: i64 publish_at = 0                 // 定时发布时间戳（毫秒），发布前只有上传者和管理员可以查看；0表示立即发布
+    33: i64 expires_at = 0                 // 到期时间戳（毫秒），0表示不过期
+    34: string expiry_action = ""          // 到期处理方式：unlist（公开视频改为不公开）、delete（删除视频），未设置到期时间时为空
+    35: optional ClipSource clip_source    // 由其他视频剪辑生成时的来源
 }
 
 // 视频上传请求
 struct VideoUploadRequest {
     1: string title                        // 视频标题（必填）
     2: optional string description = ""    // 视频描述
+    3: optional string upload_id = ""      // 客户端生成的上传ID，用于订阅上传进度
+    4: optional string checksum = ""       // 客户端计算的SHA-256校验和，不一致时拒绝上传
+    5: optional string visibility = ""     // 可见性：private/unlisted/public，默认使用upload.default_visibility
 }
 
 // 视频上传响应
@@ -34,6 +74,32 @@ struct VideoUploadResponse {
     1: BaseResponse base
     2: optional Video video
     3: optional string upload_url = ""     // 预签名上传URL
+    4: optional string upload_id = ""      // 上传ID
+}
+
+// 直传上传地址请求
+struct VideoUploadURLRequest {
+    1: string filename                     // 原始文件名
+    2: i64 size                            // 文件大小（字节）
+    3: optional string content_type = ""   // MIME类型
+    4: optional string title = ""          // 视频标题，默认使用文件名
+    5: optional string description = ""    // 视频描述
+    6: optional string visibility = ""     // 可见性：private/unlisted/public，默认使用upload.default_visibility
+}
+
+// 直传上传地址响应
+struct VideoUploadURLResponse {
+    1: BaseResponse base
+    2: optional string video_id = ""       // 视频ID
+    3: optional string upload_url = ""     // 预签名PUT URL，客户端直接上传到存储
+    4: optional string upload_token = ""   // 上传令牌，确认上传时使用
+    5: optional i64 expires_at = 0         // 过期时间戳（毫秒）
+}
+
+// 直传确认请求
+struct VideoUploadConfirmRequest {
+    1: string upload_token                 // 上传令牌
+    2: optional string checksum = ""       // 客户端计算的SHA-256校验和，不一致时拒绝上传
 }
 
 // 视频列表请求
@@ -43,6 +109,18 @@ struct VideoListRequest {
     3: optional string search = ""         // 搜索关键词
     4: optional string sort_by = "uploaded_at" // 排序字段
     5: optional string sort_order = "desc" // 排序方向：asc/desc
+    6: optional string content_type = ""   // 内容类型过滤，多个用逗号分隔（如video/mp4,video/webm）
+    7: optional i64 min_duration = 0       // 最短时长（秒），0表示不限制
+    8: optional i64 max_duration = 0       // 最长时长（秒），0表示不限制
+    9: optional i32 min_width = 0          // 最小宽度，0表示不限制
+    10: optional i32 min_height = 0        // 最小高度，0表示不限制（如720筛选720p及以上）
+    11: optional string created_by = ""    // 上传者用户ID
+    12: optional i64 uploaded_after = 0    // 上传时间下限（毫秒时间戳，包含），0表示不限制
+    13: optional i64 uploaded_before = 0   // 上传时间上限（毫秒时间戳，不包含），0表示不限制
+    14: optional string tags = ""          // 标签过滤，多个用逗号分隔，需包含全部标签
+    15: optional string collection_id = "" // 合集ID，只返回该合集中的视频
+    16: optional string status = ""        // 处理状态过滤：processing/ready/failed
+    17: optional string media_type = ""    // 媒体类型过滤：video/image/audio
 }
 
 // 视频列表响应
@@ -57,73 +135,1652 @@ struct VideoListResponse {
 
 // 视频详情请求
 struct VideoDetailRequest {
-    1: string video_id                     // 视频ID
+    1: string video_id (api.path="video_id")   // 视频ID
 }
 
 // 视频详情响应
+// 视频的衍生文件
+struct VideoAsset {
+    1: string kind = ""                    // 类型：thumbnail（缩略图）、preview（动态预览）、sprite（进度条预览图）、thumbnail_candidates（候选缩略图）、hls_playlist（HLS主播放列表）、dash_manifest（DASH清单）、rendition（HLS码率档位）、subtitle（字幕）
+    2: string name = ""                    // 名称：码率档位名称、字幕语言，其他类型与kind相同
+    3: string bucket = ""                  // 存储桶
+    4: string path = ""                    // 对象名，由多个文件组成的衍生文件为对象前缀
+    5: i64 size = 0                        // 总大小（字节）
+    6: i32 file_count = 0                  // 文件数量
+    7: string status = ""                  // 状态：ready（已生成）、processing（正在生成）、incomplete（生成中断，只有部分文件）、missing（元数据引用的文件不存在）
+    8: i64 updated_at = 0                  // 最后修改时间戳（毫秒），没有文件时为0
+}
+
 struct VideoDetailResponse {
     1: BaseResponse base
     2: optional Video video
+    3: optional list<VideoAsset> assets    // 衍生文件清单，上传中的视频和无法访问存储时不返回
 }
 
 // 视频播放URL请求
 struct VideoPlayURLRequest {
-    1: string video_id                     // 视频ID
-    2: optional i32 expire_seconds = 3600  // URL过期时间（秒），默认1小时
+    1: string video_id (api.path="video_id")   // 视频ID
+    2: optional i32 expire_seconds = 3600 (api.query="expire_seconds")  // URL过期时间（秒），默认1小时，最长7天
+}
+
+// 字幕轨道
+struct SubtitleTrack {
+    1: string language                     // 语言（字幕文件名，不含扩展名）
+    2: string format                       // 字幕格式（vtt或srt）
+    3: string url                          // 字幕文件预签名URL
 }
 
-// 视频播放URL响应
+// 视频播放URL响应，同时返回播放器需要的缩略图、预览图和字幕地址
 struct VideoPlayURLResponse {
     1: BaseResponse base
     2: optional string play_url = ""       // 播放URL
     3: optional i64 expires_at = 0         // URL过期时间戳（毫秒）
+    4: optional string hls_url             // HLS主播放列表地址（接口路径，已打包时返回）
+    5: optional string thumbnail_url       // 缩略图预签名URL
+    6: optional string preview_url         // 动态预览预签名URL
+    7: optional string sprite_url          // 进度条预览雪碧图预签名URL（已生成时返回）
+    8: optional string thumbnail_track_url // 进度条预览WebVTT轨道地址（接口路径，已生成时返回）
+    9: optional list<SubtitleTrack> subtitles // 字幕轨道
+    10: optional string dash_url           // DASH清单地址（接口路径，已生成时返回）
+}
+
+// 投屏媒体信息请求
+struct VideoCastRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+    2: optional i32 expire_seconds = 0 (api.query="expire_seconds")  // 地址过期时间（秒），默认为视频时长加1小时，最长7天
+}
+
+// 投屏媒体信息，字段对应Chromecast的MediaInfo，content_url也可以直接作为AirPlay的播放地址
+// 所有地址都是带播放令牌的绝对地址，投屏设备不需要登录即可访问
+struct VideoCastResponse {
+    1: BaseResponse base
+    2: optional string content_url         // 媒体地址（播放令牌签名的视频流，支持Range）
+    3: optional string content_type        // 媒体的内容类型
+    4: optional string stream_type         // 流类型，点播固定为BUFFERED
+    5: optional double duration            // 时长（秒），未知时为0
+    6: optional string title               // 标题
+    7: optional string poster_url          // 海报地址，没有缩略图时为空
+    8: optional list<SubtitleTrack> subtitles // 字幕轨道，SRT字幕转换为WebVTT返回
+    9: optional i64 expires_at             // 地址过期时间戳（毫秒）
+    10: optional string media_type         // 媒体类型：video/image/audio
+    11: optional bool chromecast_supported // 格式是否可以由Chromecast默认接收器直接播放
+    12: optional bool airplay_supported    // 格式是否可以通过AirPlay直接播放
+}
+
+// 视频更新请求（只更新传入的字段）
+struct VideoUpdateRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+    2: optional string title               // 视频标题
+    3: optional string description         // 视频描述
+    4: optional list<string> tags          // 视频标签（整体替换）
+    5: optional i64 updated_at             // 读取时的更新时间戳（毫秒），传入时用于冲突检测
+    6: optional string visibility          // 可见性：private/unlisted/public
+    7: optional i64 publish_at             // 定时发布时间戳（毫秒），0表示取消定时发布
+    8: optional i64 expires_at             // 到期时间戳（毫秒），0表示取消到期
+    9: optional string expiry_action       // 到期处理方式：unlist/delete，默认unlist
+}
+
+// 视频更新响应
+struct VideoUpdateResponse {
+    1: BaseResponse base
+    2: optional Video video
+}
+
+// 视频标签修改请求（添加或移除）
+struct VideoTagsRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+    2: list<string> tags                   // 标签，规范化为小写并合并空白
+}
+
+// 视频标签修改响应
+struct VideoTagsResponse {
+    1: BaseResponse base
+    2: optional Video video
+}
+
+// 标签使用统计
+struct TagCount {
+    1: string tag = ""                     // 标签
+    2: i32 count = 0                       // 使用该标签的视频数量
+}
+
+// 标签列表响应
+struct TagListResponse {
+    1: BaseResponse base
+    2: list<TagCount> tags = []            // 按使用数量降序排列
+}
+
+// 播放进度
+struct WatchProgress {
+    1: string video_id = ""                // 视频ID
+    2: i64 position = 0                    // 播放位置（秒）
+    3: bool completed = false              // 是否已看完（达到时长的95%）
+    4: i64 last_watched_at = 0             // 最后观看时间戳（毫秒）
+    5: optional Video video                // 视频信息（观看历史中返回）
+}
+
+// 播放进度上报请求
+struct VideoProgressRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+    2: i64 position                        // 播放位置（秒）
+}
+
+// 播放进度上报响应
+struct VideoProgressResponse {
+    1: BaseResponse base
+    2: optional WatchProgress progress
+}
+
+// 观看历史请求
+struct WatchHistoryRequest {
+    1: optional i32 limit = 0 (api.query="limit")  // 返回数量限制，0表示不限制
+}
+
+// 观看历史响应
+struct WatchHistoryResponse {
+    1: BaseResponse base
+    2: list<WatchProgress> history = []    // 按最后观看时间降序排列
+}
+
+// 设置视频章节请求
+struct VideoChaptersRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+    2: list<Chapter> chapters = []         // 章节，替换视频现有的全部章节，为空时清除章节
+}
+
+// 设置视频章节响应
+struct VideoChaptersResponse {
+    1: BaseResponse base
+    2: optional Video video                // 更新后的视频信息
+}
+
+// 归档或恢复视频请求
+struct VideoArchiveRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+}
+
+// 归档或恢复视频响应
+struct VideoArchiveResponse {
+    1: BaseResponse base
+    2: optional Video video                // 操作后的视频信息
+}
+
+// 重新处理视频请求
+struct VideoReprocessRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+}
+
+// 重新处理视频响应
+struct VideoReprocessResponse {
+    1: BaseResponse base
+    2: optional Video video                // 重新处理后的视频信息
+    3: bool info_updated = false           // 是否重新提取了时长、分辨率等视频信息
+    4: bool thumbnail_updated = false      // 是否重新生成了缩略图
+    5: bool previews_scheduled = false     // 是否已安排重新生成预览图
+    6: bool transcode_scheduled = false    // 是否已安排重新进行HLS打包
+}
+
+// 生成候选缩略图请求
+struct ThumbnailCandidatesRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+}
+
+// 候选缩略图
+struct ThumbnailCandidate {
+    1: i32 index                           // 候选序号，选择缩略图时使用
+    2: i32 percent                         // 截取位置占视频时长的百分比
+    3: double time_offset                  // 截取位置（秒）
+    4: string url                          // 候选缩略图预签名URL
+}
+
+// 生成候选缩略图响应
+struct ThumbnailCandidatesResponse {
+    1: BaseResponse base
+    2: list<ThumbnailCandidate> candidates // 候选缩略图列表
+}
+
+// 选择候选缩略图请求
+struct ThumbnailSelectRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+    2: i32 index                           // 候选缩略图序号
+}
+
+// 上传自定义封面请求，封面图片通过multipart表单的file字段上传
+struct ThumbnailUploadRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+    2: optional string fit = ""            // 缩放模式：空（等比缩放到缩略图尺寸以内）、contain（加黑边）、cover（居中裁剪）
+}
+
+// 设置缩略图响应
+struct ThumbnailUpdateResponse {
+    1: BaseResponse base
+    2: optional Video video                // 更新后的视频信息
+}
+
+// 视频内容版本
+struct VideoVersion {
+    1: i32 version                         // 版本号
+    2: string filename = ""                // 原始文件名
+    3: string content_type = ""            // MIME类型
+    4: i64 size = 0                        // 文件大小（字节）
+    5: string checksum = ""                // 文件内容的SHA-256校验和（十六进制）
+    6: string created_by = ""              // 上传该版本的用户
+    7: i64 created_at = 0                  // 上传时间戳（毫秒）
+    8: bool current = false                // 是否为视频的当前内容
+}
+
+// 获取视频版本列表请求
+struct VideoVersionsRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+}
+
+// 上传新版本请求，视频文件通过multipart表单的file字段上传
+struct VideoVersionUploadRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+}
+
+// 恢复历史版本请求
+struct VideoVersionRestoreRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+    2: i32 version (api.path="version")        // 要恢复的版本号
+}
+
+// 视频版本响应
+struct VideoVersionsResponse {
+    1: BaseResponse base
+    2: optional Video video                // 视频信息
+    3: list<VideoVersion> versions         // 全部版本，按版本号降序，包括当前版本
+}
+
+// 剪辑视频请求，截取源视频的片段创建新视频
+struct VideoClipRequest {
+    1: string video_id (api.path="video_id")   // 源视频ID
+    2: i64 start_time                      // 开始时间（毫秒）
+    3: i64 end_time                        // 结束时间（毫秒），超过视频时长时截取到视频结尾
+    4: optional string title = ""          // 片段标题，默认为源视频标题加时间范围
+    5: optional string description = ""    // 片段描述
+    6: optional string visibility = ""     // 可见性：private/unlisted/public，默认使用upload.default_visibility
+    7: optional bool precise = false       // 是否重新编码使起止时间精确到帧，默认使用流复制，起点对齐到之前的关键帧
+}
+
+// 剪辑视频响应
+struct VideoClipResponse {
+    1: BaseResponse base
+    2: optional Video video                // 新建的片段视频
+    3: bool stream_copy = false            // 是否使用流复制生成（未重新编码）
+}
+
+// 批量导入视频请求
+struct VideoImportRequest {
+    1: string path                         // 服务器上的视频目录（绝对路径），递归导入其中的视频文件
+    2: bool register = false               // 仅登记文件而不复制，要求使用local存储驱动且目录位于视频存储桶内
+}
+
+// 批量导入进度
+struct VideoImport {
+    1: string path                         // 导入的目录
+    2: bool register = false               // 是否仅登记文件
+    3: string status                       // 状态：pending/running/completed/failed
+    4: i32 total = 0                       // 目录中的视频文件数量
+    5: i32 imported = 0                    // 已导入的文件数量
+    6: i32 skipped = 0                     // 内容已存在而跳过的文件数量
+    7: i32 failed = 0                      // 导入失败的文件数量
+    8: i64 bytes_imported = 0              // 已导入的字节数
+    9: optional string error              // 最近一次错误
+    10: i64 started_at = 0                 // 开始时间戳（毫秒）
+    11: optional i64 finished_at           // 结束时间戳（毫秒），运行中为空
+}
+
+// 批量导入响应
+struct VideoImportResponse {
+    1: BaseResponse base
+    2: optional VideoImport import_job     // 导入进度
+}
+
+// NFO导出请求，path和bucket只能指定一个
+struct NFOExportRequest {
+    1: optional string path                // 服务器上的导出目录（绝对路径），不存在时自动创建
+    2: optional string bucket              // 导出到的存储桶，不存在时自动创建
+    3: optional string prefix              // 存储桶中的路径前缀，为空时导出到存储桶根目录
+}
+
+// NFO导出进度
+struct NFOExport {
+    1: string target                       // 导出目标：目录或"存储桶/前缀"
+    2: string status                       // 状态：pending/running/completed/failed
+    3: i32 total = 0                       // 待导出的视频数量
+    4: i32 exported = 0                    // 已导出的视频数量
+    5: i32 skipped = 0                     // 图片和音频等跳过的数量
+    6: i32 failed = 0                      // 导出失败的视频数量
+    7: optional string error              // 最近一次错误
+    8: i64 started_at = 0                  // 开始时间戳（毫秒）
+    9: optional i64 finished_at           // 结束时间戳（毫秒），运行中为空
+}
+
+// NFO导出响应
+struct NFOExportResponse {
+    1: BaseResponse base
+    2: optional NFOExport export_job       // 导出进度
+}
+
+// 收藏视频请求
+struct VideoFavoriteRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+}
+
+// 收藏视频响应
+struct VideoFavoriteResponse {
+    1: BaseResponse base
+    2: bool favorited = false              // 操作后是否处于收藏状态
+    3: i64 favorited_at = 0                // 收藏时间戳（毫秒），未收藏时为0
+}
+
+// 收藏的视频
+struct FavoriteVideo {
+    1: Video video                         // 视频信息
+    2: i64 favorited_at = 0                // 收藏时间戳（毫秒）
+}
+
+// 收藏列表请求
+struct FavoriteListRequest {
+    1: optional i32 limit = 0 (api.query="limit")  // 返回数量限制，0表示不限制
+}
+
+// 收藏列表响应
+struct FavoriteListResponse {
+    1: BaseResponse base
+    2: list<FavoriteVideo> favorites = []  // 按收藏时间降序排列
 }
 
 // 视频删除请求
 struct VideoDeleteRequest {
-    1: string video_id                     // 视频ID
+    1: string video_id (api.path="video_id")   // 视频ID
+}
+
+// 删除失败的对象
+struct DeleteFailure {
+    1: string object_name = ""             // 对象名
+    2: string error = ""                   // 失败原因
 }
 
 // 视频删除响应
 struct VideoDeleteResponse {
     1: BaseResponse base
+    2: i32 deleted_count = 0               // 已删除的对象数量
+    3: list<DeleteFailure> failures = []   // 删除失败的对象（部分失败时返回）
 }
 
-// 健康检查响应
-struct HealthCheckResponse {
+// HLS播放列表请求
+struct HLSPlaylistRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+    2: string playlist (api.path="playlist")   // 播放列表名称，如master.m3u8
+}
+
+// HLS播放列表响应（成功时直接返回m3u8内容）
+struct HLSPlaylistResponse {
     1: BaseResponse base
-    2: string status = "ok"
-    3: string service = "zhulong-backend"
-    4: string version = "v1.0.0"
-    5: i64 timestamp = 0                   // 当前时间戳（毫秒）
+    2: optional string content = ""        // 播放列表内容
 }
 
-// 服务器信息响应
-struct ServerInfoResponse {
+// DASH清单请求
+struct DASHManifestRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+}
+
+// DASH清单响应（成功时直接返回mpd内容）
+struct DASHManifestResponse {
     1: BaseResponse base
-    2: string name = "Zhulong Video Server"
-    3: string description = "局域网视频播放服务后端"
-    4: string version = "v1.0.0"
-    5: string framework = "CloudWeGo Hertz"
-    6: map<string, string> capabilities = {} // 服务能力
+    2: optional string content = ""        // 清单内容
 }
 
-// 视频服务接口定义
-service VideoService {
-    // 视频上传接口
-    VideoUploadResponse UploadVideo(1: VideoUploadRequest req) (api.post="/api/v1/videos")
-    
-    // 获取视频列表
-    VideoListResponse GetVideoList(1: VideoListRequest req) (api.get="/api/v1/videos")
-    
-    // 获取视频详情
-    VideoDetailResponse GetVideoDetail(1: VideoDetailRequest req) (api.get="/api/v1/videos/:video_id")
-    
-    // 获取视频播放URL
-    VideoPlayURLResponse GetVideoPlayURL(1: VideoPlayURLRequest req) (api.get="/api/v1/videos/:video_id/play")
-    
-    // 删除视频
-    VideoDeleteResponse DeleteVideo(1: VideoDeleteRequest req) (api.delete="/api/v1/videos/:video_id")
+// 视频流请求（支持Range请求头）
+struct VideoStreamRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+}
+
+// 视频流响应（成功时直接返回视频内容，Range请求返回206）
+struct VideoStreamResponse {
+    1: BaseResponse base
+}
+
+// 视频下载请求（支持Range请求头断点续传）
+struct VideoDownloadRequest {
+    1: string video_id (api.path="video_id")            // 视频ID
+    2: optional string rendition = "" (api.query="rendition")   // HLS档位名称（如720p），为空时下载原始文件
+}
+
+// 视频下载响应（成功时以附件形式返回文件内容，文件名为原始文件名）
+struct VideoDownloadResponse {
+    1: BaseResponse base
+}
+
+// 批量导出视频请求
+struct VideoExportRequest {
+    1: list<string> video_ids (api.body="video_ids")                           // 视频ID列表
+    2: optional bool skip_subtitles = false (api.body="skip_subtitles")   // 是否不导出字幕，默认同时导出视频的字幕
+}
+
+// 批量导出视频响应（成功时返回zip压缩包，视频以原始文件名存放，字幕与视频同名并带语言后缀）
+struct VideoExportResponse {
+    1: BaseResponse base
+}
+
+// 缩略图预览轨道请求
+struct ThumbnailTrackRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+}
+
+// 缩略图预览轨道响应（成功时直接返回WebVTT内容）
+struct ThumbnailTrackResponse {
+    1: BaseResponse base
+    2: optional string content = ""        // WebVTT内容
+}
+
+// 上传进度
+struct UploadProgress {
+    1: string upload_id = ""               // 上传ID
+    2: i32 percentage = 0                  // 完成百分比
+    3: i64 bytes_uploaded = 0              // 已上传字节数
+    4: i64 total_bytes = 0                 // 总字节数
+    5: string status = ""                  // 状态：pending/uploading/completed/failed
+    6: optional string error = ""          // 失败原因
+    7: i64 updated_at = 0                  // 更新时间戳（毫秒）
+}
+
+// 上传进度请求
+struct UploadProgressRequest {
+    1: string upload_id (api.path="upload_id")   // 上传ID
+}
+
+// 上传进度响应（成功时以Server-Sent Events推送UploadProgress）
+struct UploadProgressResponse {
+    1: BaseResponse base
+    2: optional UploadProgress progress
+}
+
+// 分片上传会话
+struct MultipartUpload {
+    1: string upload_id = ""               // 上传ID
+    2: string video_id = ""                // 视频ID
+    3: string filename = ""                // 原始文件名
+    4: i64 size = 0                        // 文件总大小（字节）
+    5: i64 chunk_size = 0                  // 分片大小（字节），最后一个分片可以更小
+    6: i32 total_parts = 0                 // 分片总数
+    7: i64 created_at = 0                  // 创建时间戳（毫秒）
+    8: i64 updated_at = 0                  // 最近一次上传分片的时间戳（毫秒）
+    9: i64 expires_at = 0                  // 过期时间戳（毫秒），期间没有上传分片时自动中止
+}
+
+// 初始化分片上传请求
+struct MultipartUploadInitRequest {
+    1: string filename                     // 原始文件名
+    2: i64 size                            // 文件总大小（字节）
+    3: optional i64 chunk_size = 0         // 分片大小（字节），默认8MB，除只有一个分片外不能小于5MB
+    4: optional string content_type = ""   // MIME类型
+    5: optional string title = ""          // 视频标题，默认使用文件名
+    6: optional string description = ""    // 视频描述
+    7: optional string visibility = ""     // 可见性：private/unlisted/public，默认使用upload.default_visibility
+}
+
+// 初始化分片上传响应
+struct MultipartUploadInitResponse {
+    1: BaseResponse base
+    2: optional MultipartUpload upload
+}
+
+// 进行中的分片上传列表请求
+struct MultipartUploadListRequest {
+}
+
+// 进行中的分片上传列表响应
+struct MultipartUploadListResponse {
+    1: BaseResponse base
+    2: list<MultipartUpload> uploads = []
+}
+
+// 上传分片请求（请求体为分片数据）
+struct MultipartUploadPartRequest {
+    1: string upload_id (api.path="upload_id")   // 上传ID
+    2: i32 part_number (api.path="part_number")  // 分片号，从1开始
+}
+
+// 已上传的分片
+struct MultipartUploadPart {
+    1: i32 part_number = 0                 // 分片号
+    2: string etag = ""                    // 分片ETag，完成上传时提交
+    3: i64 size = 0                        // 分片大小（字节）
+}
+
+// 上传分片响应
+struct MultipartUploadPartResponse {
+    1: BaseResponse base
+    2: optional MultipartUploadPart part
+}
+
+// 分片状态请求
+struct MultipartUploadPartsRequest {
+    1: string upload_id (api.path="upload_id")   // 上传ID
+}
+
+// 分片状态响应
+struct MultipartUploadPartsResponse {
+    1: BaseResponse base
+    2: optional MultipartUpload upload
+    3: list<MultipartUploadPart> received_parts = []   // 已上传的分片，按分片号升序
+    4: list<i32> missing_parts = []                    // 尚未上传的分片号，按分片号升序
+    5: i64 received_size = 0                           // 已上传的字节数
+}
+
+// 完成分片上传请求
+struct MultipartUploadCompleteRequest {
+    1: string upload_id (api.path="upload_id")   // 上传ID
+    2: list<MultipartUploadPart> parts     // 全部分片，按分片号升序，只需part_number和etag
+    3: optional string checksum = ""       // 客户端计算的整个文件的SHA-256校验和，不一致时拒绝上传
+}
+
+// 中止分片上传请求
+struct MultipartUploadAbortRequest {
+    1: string upload_id (api.path="upload_id")   // 上传ID
+}
+
+// 中止分片上传响应
+struct MultipartUploadAbortResponse {
+    1: BaseResponse base
+}
+
+// 通知订阅响应（握手成功后升级为WebSocket，推送事件JSON：type/video_id/data/timestamp）
+struct NotificationResponse {
+    1: BaseResponse base
+}
+
+// 用户信息结构
+struct User {
+    1: string id = ""                      // 用户唯一标识
+    2: string username = ""                // 用户名
+    3: string email = ""                   // 邮箱
+    4: string role = ""                    // 角色：admin/uploader/viewer
+    5: i64 created_at = 0                  // 创建时间戳（毫秒）
+}
+
+// 用户注册请求
+struct RegisterRequest {
+    1: string username                     // 用户名（3-32位字母、数字、下划线或连字符）
+    2: string password                     // 密码（至少8位）
+    3: optional string email = ""          // 邮箱
+}
+
+// 用户登录请求
+struct LoginRequest {
+    1: string username                     // 用户名
+    2: string password                     // 密码
+}
+
+// 用户登录响应
+struct LoginResponse {
+    1: BaseResponse base
+    2: optional string token = ""          // 访问令牌（Bearer）
+    3: optional i64 expires_at = 0         // 令牌过期时间戳（毫秒）
+    4: optional User user
+}
+
+// 更新用户角色请求
+struct UpdateUserRoleRequest {
+    1: string user_id (api.path="user_id")     // 用户ID
+    2: string role                         // 新角色：admin/uploader/viewer
+}
+
+// 用户响应
+struct UserResponse {
+    1: BaseResponse base
+    2: optional User user
+}
+
+// 用户列表响应
+struct UserListResponse {
+    1: BaseResponse base
+    2: list<User> users = []
+}
+
+// 用户存储配额
+struct UserQuota {
+    1: i64 used = 0                        // 已存储字节数
+    2: i64 reserved = 0                    // 上传中预占的字节数
+    3: i64 limit = 0                       // 配额上限（字节），0表示不限制
+    4: i64 remaining = 0                   // 剩余可用字节数，不限制时为-1
+}
+
+// 用户存储配额响应
+struct UserQuotaResponse {
+    1: BaseResponse base
+    2: optional UserQuota quota
+}
+
+// 视频合集信息结构
+struct Collection {
+    1: string id = ""                      // 合集唯一标识
+    2: string name = ""                    // 合集名称
+    3: string description = ""             // 合集描述
+    4: i32 video_count = 0                 // 视频数量
+    5: string created_by = ""              // 创建者用户ID
+    6: i64 created_at = 0                  // 创建时间戳（毫秒）
+    7: i64 updated_at = 0                  // 更新时间戳（毫秒）
+}
+
+// 创建合集请求
+struct CollectionCreateRequest {
+    1: string name                         // 合集名称（不区分大小写，不能重复）
+    2: optional string description = ""    // 合集描述
+}
+
+// 更新合集请求（只更新传入的字段）
+struct CollectionUpdateRequest {
+    1: string collection_id (api.path="collection_id")   // 合集ID
+    2: optional string name                // 合集名称
+    3: optional string description         // 合集描述
+}
+
+// 删除合集请求
+struct CollectionDeleteRequest {
+    1: string collection_id (api.path="collection_id")   // 合集ID
+}
+
+// 合集视频修改请求（加入或移出）
+struct CollectionVideosRequest {
+    1: string collection_id (api.path="collection_id")   // 合集ID
+    2: list<string> video_ids              // 视频ID列表
+}
+
+// 合集响应
+struct CollectionResponse {
+    1: BaseResponse base
+    2: optional Collection collection
+}
+
+// 合集列表响应
+struct CollectionListResponse {
+    1: BaseResponse base
+    2: list<Collection> collections = []   // 按名称升序排列
+}
+
+// 播放列表信息结构
+struct Playlist {
+    1: string id = ""                      // 播放列表唯一标识
+    2: string name = ""                    // 名称
+    3: string description = ""             // 描述
+    4: i32 video_count = 0                 // 视频数量
+    5: list<Video> videos = []             // 按播放顺序排列的视频（列表接口中不返回）
+    6: string created_by = ""              // 创建者用户ID
+    7: i64 created_at = 0                  // 创建时间戳（毫秒）
+    8: i64 updated_at = 0                  // 更新时间戳（毫秒）
+}
+
+// 创建播放列表请求
+struct PlaylistCreateRequest {
+    1: string name                         // 名称
+    2: optional string description = ""    // 描述
+}
+
+// 播放列表请求（获取详情或删除）
+struct PlaylistRequest {
+    1: string playlist_id (api.path="playlist_id")   // 播放列表ID
+}
+
+// 更新播放列表请求（只更新传入的字段）
+struct PlaylistUpdateRequest {
+    1: string playlist_id (api.path="playlist_id")   // 播放列表ID
+    2: optional string name                // 名称
+    3: optional string description         // 描述
+}
+
+// 播放列表视频修改请求（添加、移除或排序）
+struct PlaylistVideosRequest {
+    1: string playlist_id (api.path="playlist_id")   // 播放列表ID
+    2: list<string> video_ids              // 视频ID列表，排序时需包含列表中的全部视频
+    3: optional i32 position               // 添加时插入的位置（从0开始），不传时追加到末尾
+}
+
+// 播放列表响应
+struct PlaylistResponse {
+    1: BaseResponse base
+    2: optional Playlist playlist
+}
+
+// 播放列表列表响应
+struct PlaylistListResponse {
+    1: BaseResponse base
+    2: list<Playlist> playlists = []       // 按创建时间升序排列
+}
+
+// 播放列表导航请求
+struct PlaylistNavigationRequest {
+    1: string playlist_id (api.path="playlist_id")   // 播放列表ID
+    2: string video_id (api.query="video_id")        // 当前播放的视频ID
+    3: optional bool loop = false (api.query="loop") // 是否循环播放（首尾相连）
+}
+
+// 播放列表导航响应
+struct PlaylistNavigationResponse {
+    1: BaseResponse base
+    2: i32 position = 0                    // 当前视频的位置（从0开始）
+    3: i32 total = 0                       // 播放列表视频总数
+    4: optional Video previous             // 上一个视频，没有时不返回
+    5: optional Video next                 // 下一个视频，没有时不返回
+}
+
+// 视频播放统计
+struct VideoViewStat {
+    1: Video video                         // 视频信息
+    2: i64 views = 0                       // 统计窗口内的播放次数
+}
+
+// 热门视频请求
+struct TopVideosRequest {
+    1: optional i32 window_hours = 24 (api.query="window_hours")  // 统计窗口（小时），默认24小时，最长90天
+    2: optional i32 limit = 10 (api.query="limit")                // 返回数量，默认10，最多100
+}
+
+// 热门视频响应
+struct TopVideosResponse {
+    1: BaseResponse base
+    2: i64 window_start = 0                // 统计窗口起始时间戳（毫秒，按小时对齐）
+    3: list<VideoViewStat> videos = []     // 按播放次数降序排列
+}
+
+// 存储迁移请求
+struct StorageMigrationRequest {
+    1: string from_bucket                  // 源存储桶
+    2: string to_bucket                    // 目标存储桶，不存在时自动创建
+}
+
+// 存储迁移进度
+struct StorageMigration {
+    1: string from_bucket                  // 源存储桶
+    2: string to_bucket                    // 目标存储桶
+    3: string status                       // 状态：pending/running/completed/failed
+    4: i32 total = 0                       // 源存储桶中的文件数量
+    5: i32 copied = 0                      // 已复制的文件数量
+    6: i32 skipped = 0                     // 目标已存在而跳过的文件数量
+    7: i32 failed = 0                      // 复制失败的文件数量
+    8: i64 bytes_copied = 0                // 已复制的字节数
+    9: i32 updated_videos = 0              // 已改写存储桶引用的视频数量
+    10: optional string error              // 最近一次错误
+    11: i64 started_at = 0                 // 开始时间戳（毫秒）
+    12: optional i64 finished_at           // 结束时间戳（毫秒），运行中为空
+}
+
+// 存储迁移响应
+struct StorageMigrationResponse {
+    1: BaseResponse base
+    2: optional StorageMigration migration // 迁移进度
+}
+
+// 转码任务
+struct TranscodeJob {
+    1: string job_id                       // 任务ID
+    2: string video_id                     // 视频ID
+    3: i32 priority = 0                    // 优先级（-100到100），数值越大越先执行
+    4: string status                       // 状态：pending/running/retrying/succeeded/canceled/dead
+    5: i32 attempts = 0                    // 累计执行次数
+    6: i32 max_attempts = 0                // 执行次数达到该值仍失败时进入死信状态（dead）
+    7: optional string error               // 最近一次失败原因
+    8: i64 created_at = 0                  // 创建时间戳（毫秒）
+    9: i64 updated_at = 0                  // 更新时间戳（毫秒）
+    10: optional i64 started_at            // 最近一次开始执行的时间戳（毫秒）
+    11: optional i64 finished_at           // 结束时间戳（毫秒），未结束时为空
+    12: optional i64 next_attempt_at       // 等待重试时的下次执行时间戳（毫秒）
+    13: string kind                        // 任务类型：hls（HLS打包）/preview（预览图生成）
+    14: optional string worker             // 领取任务的远程worker，由API服务执行时为空
+    15: optional i64 lease_expires_at      // 远程worker的租约到期时间戳（毫秒）
+}
+
+// 创建转码任务请求
+struct TranscodeJobCreateRequest {
+    1: string video_id                     // 视频ID
+    2: optional i32 priority = 0           // 优先级（-100到100），默认0
+    3: optional string kind                // 任务类型：hls/preview，默认hls
+}
+
+// 转码任务请求
+struct TranscodeJobRequest {
+    1: string job_id (api.path="job_id")   // 任务ID
+}
+
+// 修改转码任务优先级请求
+struct TranscodeJobPriorityRequest {
+    1: string job_id (api.path="job_id")   // 任务ID
+    2: i32 priority = 0                    // 优先级（-100到100）
+}
+
+// 转码任务响应
+struct TranscodeJobResponse {
+    1: BaseResponse base
+    2: optional TranscodeJob job           // 转码任务
+}
+
+// 转码任务列表请求
+struct TranscodeJobListRequest {
+    1: optional string status (api.query="status")       // 按状态过滤
+    2: optional string video_id (api.query="video_id")   // 按视频过滤
+}
+
+// 转码任务列表响应
+struct TranscodeJobListResponse {
+    1: BaseResponse base
+    2: list<TranscodeJob> jobs = []        // 未结束的任务按执行顺序在前，已结束的任务按结束时间降序在后
+}
+
+// 处理任务中的视频信息
+struct WorkerVideo {
+    1: string video_id                     // 视频ID
+    2: string bucket_name                  // 原始视频所在存储桶
+    3: string object_name                  // 原始视频对象名
+    4: string resolution                   // 分辨率，格式为宽x高（已废弃，兼容旧版本，使用width和height）
+    5: i64 bitrate = 0                     // 码率（bps）
+    6: i64 duration = 0                    // 时长（秒）
+    7: string preview                      // 已有的动态预览路径，为空时生成
+    8: i64 created_at = 0                  // 上传时间戳（毫秒），用于动态预览的存储路径
+    9: i32 rotation = 0                    // 显示时的顺时针旋转角度，转码时按旋转后的方向缩放
+    10: i32 width = 0                      // 显示宽度
+    11: i32 height = 0                     // 显示高度
+}
+
+// worker领取的处理任务
+struct WorkerJob {
+    1: string job_id                       // 任务ID
+    2: string kind                         // 任务类型：hls/preview
+    3: i32 attempts = 0                    // 累计执行次数，包括本次
+    4: i64 lease_expires_at = 0            // 租约到期时间戳（毫秒），到期前需要上报心跳
+    5: WorkerVideo video                   // 视频信息
+}
+
+// 领取处理任务请求
+struct WorkerJobClaimRequest {
+    1: string worker_id                    // worker标识
+    2: list<string> kinds = []             // 领取的任务类型，为空时领取全部类型
+}
+
+// 处理任务响应
+struct WorkerJobResponse {
+    1: BaseResponse base
+    2: optional WorkerJob job              // 领取到的任务，没有待执行的任务时为空
+}
+
+// 处理任务心跳请求
+struct WorkerJobHeartbeatRequest {
+    1: string job_id (api.path="job_id")   // 任务ID
+    2: string worker_id                    // worker标识
+}
+
+// HLS打包结果
+struct WorkerPackageResult {
+    1: string master_playlist              // 主播放列表对象名
+    2: optional string manifest            // DASH清单对象名，分片不是fMP4时为空
+    3: list<string> renditions = []        // 档位名称
+    4: i32 segment_count = 0               // 分片数量
+}
+
+// 进度条预览图生成结果
+struct WorkerSpriteResult {
+    1: i32 width = 0                       // 雪碧图宽度
+    2: i32 height = 0                      // 雪碧图高度
+    3: i32 columns = 0                     // 列数
+    4: i32 rows = 0                        // 行数
+    5: i32 tile_width = 0                  // 单帧宽度
+    6: i32 tile_height = 0                 // 单帧高度
+    7: i32 frames = 0                      // 帧数
+    8: double interval = 0                 // 截帧间隔（秒）
+}
+
+// 上报处理任务结果请求
+struct WorkerJobCompleteRequest {
+    1: string job_id (api.path="job_id")   // 任务ID
+    2: string worker_id                    // worker标识
+    3: optional string error               // 执行失败原因，为空表示执行成功
+    4: optional WorkerPackageResult package  // HLS打包结果
+    5: optional WorkerSpriteResult sprite  // 进度条预览图生成结果
+    6: optional string preview_path        // 新生成的动态预览路径
+}
+
+// 按分组统计的视频数量和大小
+struct UsageStat {
+    1: string key                          // 分组键：格式为扩展名，上传者为用户ID，月份为YYYY-MM
+    2: i32 count = 0                       // 视频数量
+    3: i64 bytes = 0                       // 总大小（字节）
+}
+
+// 存储扫描结果，定期在后台扫描存储得到
+struct StorageScanStats {
+    1: i64 scanned_at = 0                  // 扫描完成时间戳（毫秒）
+    2: i64 duration_ms = 0                 // 扫描耗时（毫秒）
+    3: i32 thumbnail_objects = 0           // 缩略图文件数量
+    4: i64 thumbnail_bytes = 0             // 缩略图总大小（字节）
+    5: i32 preview_objects = 0             // 动态预览文件数量
+    6: i64 preview_bytes = 0               // 动态预览总大小（字节）
+    7: i32 orphaned_objects = 0            // 没有被任何视频引用的文件数量（估计值）
+    8: i64 orphaned_bytes = 0              // 没有被任何视频引用的文件总大小（字节）
+    9: optional string error               // 扫描错误
+}
+
+// 数据表记录数量
+struct TableRowCounts {
+    1: i32 videos = 0                      // 视频元数据
+    2: i32 tags = 0                        // 标签
+    3: i32 users = 0                       // 用户
+    4: i32 collections = 0                 // 合集
+    5: i32 playlists = 0                   // 播放列表
+    6: i32 favorites = 0                   // 收藏记录
+    7: i32 watch_history = 0               // 观看记录
+    8: i32 view_stats = 0                  // 有播放统计的视频
+}
+
+// 临时目录统计
+struct TempDirStats {
+    1: string dir                          // 临时目录
+    2: i32 files = 0                       // 当前文件数量
+    3: i64 bytes = 0                       // 当前占用（字节）
+    4: i64 max_bytes = 0                   // 占用上限（字节），为0时不限制
+    5: i64 created = 0                     // 启动以来创建的临时文件和目录数量
+    6: i64 rejected = 0                    // 因空间不足拒绝创建的次数
+    7: i64 cleaned_files = 0               // 清理删除的过期文件和目录数量
+    8: i64 cleaned_bytes = 0               // 清理释放的空间（字节）
+    9: i64 last_cleanup_at = 0             // 最近一次清理时间戳（毫秒），未清理时为0
+}
+
+// 管理员存储统计
+struct AdminStats {
+    1: i32 total_videos = 0                // 视频总数
+    2: i64 total_bytes = 0                 // 视频文件总大小（字节），去重共享文件的视频分别计入
+    3: i32 archived_videos = 0             // 已归档的视频数量
+    4: i64 archived_bytes = 0              // 已归档的视频文件总大小（字节）
+    5: i32 videos_with_thumbnail = 0       // 有缩略图的视频数量
+    6: i32 videos_with_preview = 0         // 有动态预览的视频数量
+    7: list<UsageStat> by_format = []      // 按格式统计，按大小降序
+    8: list<UsageStat> by_uploader = []    // 按上传者统计，按大小降序
+    9: list<UsageStat> by_month = []       // 按上传月份统计，按月份升序
+    10: optional StorageScanStats storage  // 最近一次存储扫描结果，首次扫描完成前为空
+    11: TableRowCounts rows                // 数据表记录数量
+    12: i64 generated_at = 0               // 统计生成时间戳（毫秒）
+    13: optional TempDirStats temp         // 临时目录占用和清理统计
+}
+
+// 管理员存储统计响应
+struct AdminStatsResponse {
+    1: BaseResponse base
+    2: optional AdminStats stats           // 存储统计
+}
+
+// 播放令牌撤销请求，至少指定用户ID或视频ID之一
+struct PlaybackRevokeRequest {
+    1: optional string user_id             // 撤销此前为该用户签发的全部播放令牌
+    2: optional string video_id            // 撤销此前为该视频签发的全部播放令牌
+}
+
+// 播放令牌撤销响应
+struct PlaybackRevokeResponse {
+    1: BaseResponse base
+    2: optional i64 revoked_at = 0         // 撤销时间戳（毫秒），此前签发的令牌失效
+}
+
+// 内容审核队列中的视频
+struct ModerationItem {
+    1: string video_id                     // 视频ID
+    2: string status                       // 审核状态：pending（待审核）、rejected（已拒绝）、approved（已通过）
+    3: string verdict                      // 审核钩子的结论：flagged（标记）或blocked（拦截）
+    4: list<string> reasons = []           // 标记和拦截原因，格式为"钩子名称: 原因"
+    5: optional Video video                // 视频信息
+    6: i64 created_at = 0                  // 进入审核队列的时间戳（毫秒）
+    7: optional string reviewed_by         // 审核的管理员，未审核时为空
+    8: optional i64 reviewed_at            // 审核时间戳（毫秒），未审核时为空
+    9: optional string note                // 审核备注
+}
+
+// 内容审核队列查询请求
+struct ModerationListRequest {
+    1: optional string status (api.query="status")  // 按状态过滤：pending（默认）、rejected、approved，all列出全部
+}
+
+// 内容审核队列响应
+struct ModerationListResponse {
+    1: BaseResponse base
+    2: list<ModerationItem> items = []     // 审核记录，按进入队列的时间升序
+}
+
+// 内容审核请求
+struct ModerationReviewRequest {
+    1: string video_id (api.path="video_id")  // 视频ID
+    2: string action                       // 审核操作：approve（通过）或reject（拒绝）
+    3: optional string note                // 审核备注
+}
+
+// 内容审核响应
+struct ModerationReviewResponse {
+    1: BaseResponse base
+    2: optional ModerationItem item        // 审核后的记录
+}
+
+// 元数据备份
+struct BackupInfo {
+    1: string name                         // 备份名称（备份存储桶中的文件名）
+    2: i64 size = 0                        // 压缩后的大小（字节）
+    3: i64 created_at = 0                  // 备份时间戳（毫秒）
+}
+
+// 创建备份响应
+struct BackupResponse {
+    1: BaseResponse base
+    2: optional BackupInfo backup          // 创建的备份
+}
+
+// 备份列表响应
+struct BackupListResponse {
+    1: BaseResponse base
+    2: list<BackupInfo> backups = []       // 按时间从新到旧排列
+}
+
+// 恢复备份请求
+struct BackupRestoreRequest {
+    1: string name                         // 备份名称，latest表示最近一次备份
+}
+
+// 恢复备份响应
+struct BackupRestoreResponse {
+    1: BaseResponse base
+    2: optional BackupInfo backup          // 恢复的备份
+    3: i32 restored_videos = 0             // 恢复的视频数量
+    4: optional string config_file         // 备份时的配置文件路径
+    5: optional string config              // 备份时的配置文件内容，需要手动写回并重启服务
+}
+
+// 存储复制统计
+struct ReplicationStats {
+    1: i32 queued = 0                      // 队列中等待复制的操作数量
+    2: i64 replicated = 0                  // 已复制的文件数量
+    3: i64 deleted = 0                     // 已从副本删除的文件数量
+    4: i64 failed = 0                      // 复制或删除失败的操作数量
+    5: i64 dropped = 0                     // 队列已满而丢弃的操作数量
+    6: optional string last_error          // 最近一次错误
+    7: optional i64 last_error_at          // 最近一次错误的时间戳（毫秒）
+    8: optional i64 last_sync_at           // 最近一次完成同步的时间戳（毫秒）
+    9: i32 last_sync_queued = 0            // 最近一次同步入队的操作数量
+    10: bool syncing = false               // 是否有同步正在执行
+    11: bool mirror_deletes = false        // 是否同步删除
+}
+
+// 一个存储桶的复制一致性
+struct ReplicationBucketReport {
+    1: string bucket                       // 存储桶名称，主存储和副本中相同
+    2: i32 primary_objects = 0             // 主存储中的文件数量
+    3: i64 primary_bytes = 0               // 主存储中的文件总大小
+    4: i32 replica_objects = 0             // 副本中的文件数量
+    5: i64 replica_bytes = 0               // 副本中的文件总大小
+    6: i32 missing = 0                     // 副本中缺少的文件数量
+    7: i32 mismatched = 0                  // 两边大小不同的文件数量
+    8: i32 extra = 0                       // 只在副本中存在的文件数量
+    9: list<string> missing_objects = []   // 副本中缺少的文件（最多20个）
+    10: list<string> mismatched_objects = [] // 两边大小不同的文件（最多20个）
+    11: list<string> extra_objects = []    // 只在副本中存在的文件（最多20个）
+}
+
+// 复制一致性报告
+struct ReplicationReport {
+    1: bool consistent = false             // 副本是否包含主存储中的全部文件
+    2: list<ReplicationBucketReport> buckets = []
+    3: i64 checked_at = 0                  // 对比时间戳（毫秒）
+}
+
+// 复制状态和一致性报告响应
+struct ReplicationReportResponse {
+    1: BaseResponse base
+    2: optional ReplicationStats stats     // 复制统计
+    3: optional ReplicationReport report   // 一致性报告，发起同步时为空
+}
+
+// 元数据与存储一致性检查请求
+struct ConsistencyRequest {
+    1: bool checksum = false (api.query="checksum") // 下载视频文件重新计算SHA-256校验和，默认只比较上传时记录的校验和
+    2: bool fix = false (api.query="fix")           // 修复可以修复的问题
+    3: i32 limit = 0 (api.query="limit")            // 返回的问题数量上限，默认100，最大1000
+}
+
+// 一个不一致的问题
+struct ConsistencyIssue {
+    1: string video_id                     // 视频ID
+    2: string kind                         // 问题类型：missing_object/size_mismatch/checksum_mismatch/missing_thumbnail/missing_preview
+    3: string bucket                       // 存储桶
+    4: string object                       // 对象名
+    5: optional string expected            // 元数据中的值（大小或校验和）
+    6: optional string actual              // 存储中的值
+    7: bool fixed = false                  // 是否已修复
+}
+
+// 一致性检查报告
+struct ConsistencyReport {
+    1: i32 checked_videos = 0              // 检查的视频数量
+    2: i32 checked_objects = 0             // 检查的文件数量
+    3: i32 total_issues = 0                // 问题总数
+    4: i32 fixed_issues = 0                // 已修复的问题数量
+    5: map<string, i32> issues_by_kind = {} // 按类型统计的问题数量
+    6: list<ConsistencyIssue> issues = []  // 问题列表，最多limit个
+    7: i64 checked_at = 0                  // 检查时间戳（毫秒）
+}
+
+// 一致性检查响应
+struct ConsistencyResponse {
+    1: BaseResponse base
+    2: optional ConsistencyReport report
+}
+
+// 导出视频目录请求
+struct CatalogExportRequest {
+    1: string format = "json" (api.query="format") // 导出格式：json（默认）或csv
+}
+
+// 导出视频目录响应（成功时以附件形式返回目录文件）
+struct CatalogExportResponse {
+    1: BaseResponse base
+}
+
+// 导入视频目录请求（multipart/form-data，file字段为目录文件）
+struct CatalogImportRequest {
+    1: string format = "" (api.form="format")      // 目录格式：json或csv，默认按文件扩展名判断
+    2: bool dry_run = false (api.form="dry_run")   // 只检查不修改，返回将要执行的结果
+}
+
+// 导入时跳过的目录记录
+struct CatalogImportSkip {
+    1: i32 line                            // 记录位置：JSON为videos数组中的序号，CSV为行号
+    2: string file_id                      // 目录中的文件ID
+    3: string reason                       // 跳过原因
+}
+
+// 目录导入报告
+struct CatalogImportReport {
+    1: i32 total = 0                       // 目录中的记录数量
+    2: i32 created = 0                     // 新建的视频数量（没有匹配的视频且视频文件存在）
+    3: i32 updated = 0                     // 更新的视频数量
+    4: i32 unchanged = 0                   // 匹配到视频但内容相同的记录数量
+    5: i32 matched_by_checksum = 0         // 按校验和匹配到的视频数量（计入updated或unchanged）
+    6: i32 skipped = 0                     // 跳过的记录数量
+    7: list<CatalogImportSkip> skipped_records = [] // 跳过的记录，最多100条
+    8: bool dry_run = false                // 是否只检查未修改
+}
+
+// 导入视频目录响应
+struct CatalogImportResponse {
+    1: BaseResponse base
+    2: optional CatalogImportReport report
+}
+
+// 视频分享链接
+struct VideoShare {
+    1: string token                        // 分享令牌
+    2: string video_id                     // 分享的视频ID
+    3: string url                          // 分享访问地址
+    4: bool has_password = false           // 是否需要密码
+    5: i32 max_views = 0                   // 最大访问次数，0表示不限制
+    6: i32 views = 0                       // 已访问次数
+    7: optional i64 expires_at             // 过期时间戳（毫秒），永不过期时为空
+    8: bool active = false                 // 是否仍可访问（未过期且访问次数未用完）
+    9: string created_by = ""              // 创建者
+    10: i64 created_at = 0                 // 创建时间戳（毫秒）
+}
+
+// 创建分享请求
+struct ShareCreateRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+    2: optional string password            // 访问密码，为空时不需要密码
+    3: optional i32 max_views = 0          // 最大访问次数，0表示不限制
+    4: optional i64 expire_seconds = 0     // 有效期（秒），0表示永不过期，最长365天
+}
+
+// 分享响应
+struct ShareResponse {
+    1: BaseResponse base
+    2: optional VideoShare share           // 分享链接
+}
+
+// 分享列表请求
+struct ShareListRequest {
+    1: optional string video_id (api.query="video_id")  // 只列出该视频的分享
+}
+
+// 分享列表响应
+struct ShareListResponse {
+    1: BaseResponse base
+    2: list<VideoShare> shares = []        // 按创建时间降序排列
+}
+
+// 撤销分享请求
+struct ShareRevokeRequest {
+    1: string token (api.path="token")     // 分享令牌
+}
+
+// 访问分享请求
+struct ShareResolveRequest {
+    1: string token (api.path="token")     // 分享令牌
+    2: optional string password (api.query="password")  // 访问密码
+}
+
+// 分享的视频信息，不包含存储路径等内部信息
+struct SharedVideo {
+    1: string title = ""                   // 视频标题
+    2: string description = ""             // 视频描述
+    3: string content_type = ""            // MIME类型
+    4: i64 duration = 0                    // 视频时长（秒）
+    5: i32 width = 0                       // 视频宽度
+    6: i32 height = 0                      // 视频高度
+    7: list<Chapter> chapters = []         // 章节，按开始时间排序
+}
+
+// 访问分享响应
+struct ShareResolveResponse {
+    1: BaseResponse base
+    2: optional SharedVideo video          // 视频信息
+    3: optional string play_url            // 播放令牌签名的视频流URL
+    4: optional i64 expires_at             // 播放URL过期时间戳（毫秒）
+    5: optional string thumbnail_url       // 缩略图预签名URL
+    6: optional list<SubtitleTrack> subtitles // 字幕轨道
+}
+
+// 嵌入播放器请求
+struct EmbedPlayerRequest {
+    1: string video_id (api.path="video_id")   // 视频ID
+}
+
+// 嵌入播放器响应（成功时直接返回HTML播放页面）
+struct EmbedPlayerResponse {
+    1: BaseResponse base
+    2: optional SharedVideo video          // 视频信息
+    3: optional string play_url            // 播放URL
+    4: optional string thumbnail_url       // 缩略图预签名URL
+    5: optional list<SubtitleTrack> subtitles // 字幕轨道
+}
+
+// oEmbed请求
+struct OEmbedRequest {
+    1: string url (api.query="url")        // 视频页面或嵌入播放器地址，如http://nas.lan:8888/embed/<视频ID>
+    2: optional i32 maxwidth (api.query="maxwidth")    // 最大宽度
+    3: optional i32 maxheight (api.query="maxheight")  // 最大高度
+    4: optional string format (api.query="format")     // 响应格式，只支持json
+}
+
+// oEmbed响应，字段遵循oEmbed 1.0规范
+struct OEmbedResponse {
+    1: BaseResponse base
+    2: string version = "1.0"              // oEmbed版本
+    3: string type = "video"               // 资源类型
+    4: string title = ""                   // 视频标题
+    5: string provider_name = ""           // 服务名称
+    6: string provider_url = ""            // 服务地址
+    7: string html = ""                    // 嵌入播放器的iframe代码
+    8: i32 width = 0                       // 播放器宽度
+    9: i32 height = 0                      // 播放器高度
+    10: optional string thumbnail_url      // 缩略图预签名URL
+    11: optional i32 thumbnail_width       // 缩略图宽度
+    12: optional i32 thumbnail_height      // 缩略图高度
+    13: optional i64 cache_age             // 建议的缓存时间（秒）
+}
+
+// 存储调用的重试和熔断统计
+struct StorageResilienceStats {
+    1: string circuit_state = "closed"     // 熔断器状态：closed、open、half_open
+    2: i64 circuit_opens = 0               // 启动以来的熔断次数
+    3: i64 calls = 0                       // 发往存储服务的调用次数，包括重试
+    4: i64 retries = 0                     // 重试次数
+    5: i64 failures = 0                    // 重试后仍因临时错误失败的操作次数
+    6: i64 rejected = 0                    // 熔断期间直接拒绝的操作次数
+    7: optional string last_error          // 最近一次临时错误
+    8: optional i64 last_error_at          // 最近一次临时错误的时间（毫秒）
+}
+
+// 健康检查响应
+struct HealthCheckResponse {
+    1: BaseResponse base
+    2: string status = "ok"                // 存储服务熔断时为degraded
+    3: string service = "zhulong-backend"
+    4: string version = "v1.0.0"
+    5: i64 timestamp = 0                   // 当前时间戳（毫秒）
+    6: i64 panic_count = 0                 // 启动以来捕获的处理函数panic次数
+    7: optional StorageResilienceStats storage // 存储调用的重试和熔断统计，未启用重试和熔断时为空
+}
+
+// 服务器信息响应
+struct ServerInfoResponse {
+    1: BaseResponse base
+    2: string name = "Zhulong Video Server"
+    3: string description = "局域网视频播放服务后端"
+    4: string version = "v1.0.0"
+    5: string framework = "CloudWeGo Hertz"
+    6: map<string, string> capabilities = {} // 服务能力
+}
+
+// 视频服务接口定义
+service VideoService {
+    // 视频上传接口
+    VideoUploadResponse UploadVideo(1: VideoUploadRequest req) (api.post="/api/v1/videos")
+    
+    // 获取直传上传地址
+    VideoUploadURLResponse CreateUploadURL(1: VideoUploadURLRequest req) (api.post="/api/v1/videos/upload-url")
+    
+    // 确认直传上传完成
+    VideoUploadResponse ConfirmUpload(1: VideoUploadConfirmRequest req) (api.post="/api/v1/videos/confirm")
+    
+    // 获取视频列表
+    VideoListResponse GetVideoList(1: VideoListRequest req) (api.get="/api/v1/videos")
+    
+    // 获取视频详情
+    VideoDetailResponse GetVideoDetail(1: VideoDetailRequest req) (api.get="/api/v1/videos/:video_id")
+    
+    // 获取视频播放URL
+    VideoPlayURLResponse GetVideoPlayURL(1: VideoPlayURLRequest req) (api.get="/api/v1/videos/:video_id/play")
+    VideoCastResponse GetVideoCastInfo(1: VideoCastRequest req) (api.get="/api/v1/videos/:video_id/cast")
+    
+    // 更新视频信息
+    VideoUpdateResponse UpdateVideo(1: VideoUpdateRequest req) (api.put="/api/v1/videos/:video_id")
+    
+    // 删除视频
+    VideoDeleteResponse DeleteVideo(1: VideoDeleteRequest req) (api.delete="/api/v1/videos/:video_id")
+    
+    // 获取HLS播放列表
+    HLSPlaylistResponse GetHLSPlaylist(1: HLSPlaylistRequest req) (api.get="/api/v1/videos/:video_id/hls/:playlist")
+
+    // 获取DASH清单，与HLS共用相同的fMP4分片
+    DASHManifestResponse GetDASHManifest(1: DASHManifestRequest req) (api.get="/api/v1/videos/:video_id/manifest.mpd")
+    
+    // 代理视频流，支持Range请求
+    VideoStreamResponse StreamVideo(1: VideoStreamRequest req) (api.get="/api/v1/videos/:video_id/stream")
+
+    // 下载视频原始文件或指定的HLS档位
+    VideoDownloadResponse DownloadVideo(1: VideoDownloadRequest req) (api.get="/api/v1/videos/:video_id/download")
+
+    // 将多个视频及其字幕打包为zip压缩包下载
+    VideoExportResponse ExportVideos(1: VideoExportRequest req) (api.post="/api/v1/videos/export")
+    
+    // 获取进度条悬停预览的WebVTT缩略图轨道
+    ThumbnailTrackResponse GetThumbnailTrack(1: ThumbnailTrackRequest req) (api.get="/api/v1/videos/:video_id/thumbnails.vtt")
+
+    // 为视频添加标签
+    VideoTagsResponse AddVideoTags(1: VideoTagsRequest req) (api.post="/api/v1/videos/:video_id/tags")
+
+    // 移除视频的标签
+    VideoTagsResponse RemoveVideoTags(1: VideoTagsRequest req) (api.delete="/api/v1/videos/:video_id/tags")
+
+    // 设置视频章节
+    VideoChaptersResponse UpdateVideoChapters(1: VideoChaptersRequest req) (api.put="/api/v1/videos/:video_id/chapters")
+
+    // 上报播放进度（需登录）
+    VideoProgressResponse UpdateWatchProgress(1: VideoProgressRequest req) (api.put="/api/v1/videos/:video_id/progress")
+
+    // 获取当前用户的观看历史（需登录）
+    WatchHistoryResponse GetWatchHistory(1: WatchHistoryRequest req) (api.get="/api/v1/users/me/history")
+
+    // 收藏视频（需登录）
+    VideoFavoriteResponse AddFavorite(1: VideoFavoriteRequest req) (api.put="/api/v1/videos/:video_id/favorite")
+
+    // 取消收藏视频（需登录）
+    VideoFavoriteResponse RemoveFavorite(1: VideoFavoriteRequest req) (api.delete="/api/v1/videos/:video_id/favorite")
+
+    // 获取当前用户收藏的视频（需登录）
+    FavoriteListResponse GetFavorites(1: FavoriteListRequest req) (api.get="/api/v1/users/me/favorites")
+
+    // 将视频文件归档到冷存储（管理员）
+    VideoArchiveResponse ArchiveVideo(1: VideoArchiveRequest req) (api.put="/api/v1/videos/:video_id/archive")
+
+    // 将已归档的视频文件恢复到视频存储桶（管理员）
+    VideoArchiveResponse RestoreVideo(1: VideoArchiveRequest req) (api.delete="/api/v1/videos/:video_id/archive")
+
+    // 重新处理视频：重新提取视频信息、生成缩略图和预览图并重新打包（上传者只能处理自己的视频）
+    VideoReprocessResponse ReprocessVideo(1: VideoReprocessRequest req) (api.post="/api/v1/videos/:video_id/reprocess")
+
+    // 在视频时长的10%/25%/50%/75%处截取候选缩略图（上传者只能处理自己的视频），重新生成时覆盖上一批候选
+    ThumbnailCandidatesResponse GenerateThumbnailCandidates(1: ThumbnailCandidatesRequest req) (api.post="/api/v1/videos/:video_id/thumbnail/candidates")
+
+    // 选择一张候选缩略图作为视频缩略图（上传者只能修改自己的视频）
+    ThumbnailUpdateResponse SelectThumbnail(1: ThumbnailSelectRequest req) (api.put="/api/v1/videos/:video_id/thumbnail")
+
+    // 上传自定义封面作为视频缩略图（上传者只能修改自己的视频），支持JPEG和PNG
+    ThumbnailUpdateResponse UploadCustomThumbnail(1: ThumbnailUploadRequest req) (api.post="/api/v1/videos/:video_id/thumbnail")
+
+    // 获取视频的内容版本列表（上传者只能查看自己的视频）
+    VideoVersionsResponse ListVideoVersions(1: VideoVersionsRequest req) (api.get="/api/v1/videos/:video_id/versions")
+
+    // 上传新的视频文件替换视频内容（上传者只能修改自己的视频），原文件保存为历史版本
+    VideoVersionsResponse UploadVideoVersion(1: VideoVersionUploadRequest req) (api.post="/api/v1/videos/:video_id/versions")
+
+    // 将历史版本恢复为视频的当前内容（上传者只能修改自己的视频），被替换的内容保存为历史版本
+    VideoVersionsResponse RestoreVideoVersion(1: VideoVersionRestoreRequest req) (api.post="/api/v1/videos/:video_id/versions/:version/restore")
+
+    // 截取视频片段创建新视频（上传者或管理员，需能查看源视频），片段属于当前用户并关联源视频
+    VideoClipResponse CreateVideoClip(1: VideoClipRequest req) (api.post="/api/v1/videos/:video_id/clips")
+
+    // 从服务器目录批量导入视频（管理员），重新导入时跳过内容已存在的文件
+    VideoImportResponse StartVideoImport(1: VideoImportRequest req) (api.post="/api/v1/videos/import")
+
+    // 获取最近一次批量导入的进度（管理员）
+    VideoImportResponse GetVideoImport() (api.get="/api/v1/videos/import")
+
+    // 为全部视频生成Kodi/Jellyfin格式的NFO元数据文件和海报，导出到服务器目录或存储桶（管理员）
+    NFOExportResponse StartNFOExport(1: NFOExportRequest req) (api.post="/api/v1/videos/nfo-export")
+
+    // 获取最近一次NFO导出的进度（管理员）
+    NFOExportResponse GetNFOExport() (api.get="/api/v1/videos/nfo-export")
+
+    // 列出所有标签及使用数量
+    TagListResponse ListTags() (api.get="/api/v1/tags")
+}
+
+// 上传服务接口定义
+service UploadService {
+    // 订阅上传进度（Server-Sent Events）
+    UploadProgressResponse GetUploadProgress(1: UploadProgressRequest req) (api.get="/api/v1/uploads/:upload_id/progress")
+
+    // 初始化分片上传
+    MultipartUploadInitResponse InitMultipartUpload(1: MultipartUploadInitRequest req) (api.post="/api/v1/uploads")
+
+    // 列出当前用户进行中的分片上传
+    MultipartUploadListResponse ListMultipartUploads(1: MultipartUploadListRequest req) (api.get="/api/v1/uploads")
+
+    // 上传分片
+    MultipartUploadPartResponse UploadMultipartPart(1: MultipartUploadPartRequest req) (api.put="/api/v1/uploads/:upload_id/parts/:part_number")
+
+    // 查询分片状态（已上传和缺失的分片），用于并行上传和断线后只重传缺失的分片
+    MultipartUploadPartsResponse ListMultipartUploadParts(1: MultipartUploadPartsRequest req) (api.get="/api/v1/uploads/:upload_id/parts")
+
+    // 完成分片上传并创建视频
+    VideoUploadResponse CompleteMultipartUpload(1: MultipartUploadCompleteRequest req) (api.post="/api/v1/uploads/:upload_id/complete")
+
+    // 中止分片上传并清理已上传的分片
+    MultipartUploadAbortResponse AbortMultipartUpload(1: MultipartUploadAbortRequest req) (api.delete="/api/v1/uploads/:upload_id")
+}
+
+// 通知服务接口定义
+service NotificationService {
+    // 订阅处理事件（WebSocket）：upload.completed/thumbnail.ready/transcode.finished/video.deleted
+    NotificationResponse SubscribeNotifications() (api.get="/api/v1/notifications/ws")
+}
+
+// 用户服务接口定义
+service UserService {
+    // 用户注册
+    UserResponse Register(1: RegisterRequest req) (api.post="/api/v1/auth/register")
+    
+    // 用户登录
+    LoginResponse Login(1: LoginRequest req) (api.post="/api/v1/auth/login")
+    
+    // 获取当前登录用户
+    UserResponse GetCurrentUser() (api.get="/api/v1/users/me")
+    
+    // 获取当前用户存储配额（需登录）
+    UserQuotaResponse GetCurrentUserQuota() (api.get="/api/v1/users/me/quota")
+    
+    // 获取用户列表（管理员）
+    UserListResponse ListUsers() (api.get="/api/v1/users")
+    
+    // 更新用户角色（管理员）
+    UserResponse UpdateUserRole(1: UpdateUserRoleRequest req) (api.put="/api/v1/users/:user_id/role")
+}
+
+// 合集服务接口定义
+service CollectionService {
+    // 创建合集
+    CollectionResponse CreateCollection(1: CollectionCreateRequest req) (api.post="/api/v1/collections")
+
+    // 列出所有合集
+    CollectionListResponse ListCollections() (api.get="/api/v1/collections")
+
+    // 重命名合集或修改描述
+    CollectionResponse UpdateCollection(1: CollectionUpdateRequest req) (api.put="/api/v1/collections/:collection_id")
+
+    // 删除合集（不删除合集中的视频）
+    CollectionResponse DeleteCollection(1: CollectionDeleteRequest req) (api.delete="/api/v1/collections/:collection_id")
+
+    // 将视频加入合集
+    CollectionResponse AddCollectionVideos(1: CollectionVideosRequest req) (api.post="/api/v1/collections/:collection_id/videos")
+
+    // 将视频移出合集
+    CollectionResponse RemoveCollectionVideos(1: CollectionVideosRequest req) (api.delete="/api/v1/collections/:collection_id/videos")
+}
+
+// 播放列表服务接口定义
+service PlaylistService {
+    // 创建播放列表
+    PlaylistResponse CreatePlaylist(1: PlaylistCreateRequest req) (api.post="/api/v1/playlists")
+
+    // 列出所有播放列表
+    PlaylistListResponse ListPlaylists() (api.get="/api/v1/playlists")
+
+    // 获取播放列表详情（包含按顺序排列的视频）
+    PlaylistResponse GetPlaylist(1: PlaylistRequest req) (api.get="/api/v1/playlists/:playlist_id")
+
+    // 修改播放列表名称或描述
+    PlaylistResponse UpdatePlaylist(1: PlaylistUpdateRequest req) (api.put="/api/v1/playlists/:playlist_id")
+
+    // 删除播放列表（不删除其中的视频）
+    PlaylistResponse DeletePlaylist(1: PlaylistRequest req) (api.delete="/api/v1/playlists/:playlist_id")
+
+    // 向播放列表添加视频
+    PlaylistResponse AddPlaylistVideos(1: PlaylistVideosRequest req) (api.post="/api/v1/playlists/:playlist_id/videos")
+
+    // 从播放列表移除视频
+    PlaylistResponse RemovePlaylistVideos(1: PlaylistVideosRequest req) (api.delete="/api/v1/playlists/:playlist_id/videos")
+
+    // 调整播放顺序
+    PlaylistResponse ReorderPlaylist(1: PlaylistVideosRequest req) (api.put="/api/v1/playlists/:playlist_id/order")
+
+    // 获取当前视频的位置及上一个、下一个视频，用于连续播放
+    PlaylistNavigationResponse NavigatePlaylist(1: PlaylistNavigationRequest req) (api.get="/api/v1/playlists/:playlist_id/navigation")
+}
+
+// 统计服务接口定义
+service AnalyticsService {
+    // 获取统计窗口内播放次数最多的视频（管理员）
+    TopVideosResponse GetTopVideos(1: TopVideosRequest req) (api.get="/api/v1/analytics/top-videos")
+}
+
+// 存储服务接口定义
+service StorageService {
+    // 将存储桶中的全部文件复制到另一个存储桶，并改写视频的存储桶引用（管理员）
+    // 重新发起相同的迁移时跳过已复制的文件
+    StorageMigrationResponse StartStorageMigration(1: StorageMigrationRequest req) (api.post="/api/v1/storage/migration")
+
+    // 获取最近一次存储迁移的进度（管理员）
+    StorageMigrationResponse GetStorageMigration() (api.get="/api/v1/storage/migration")
+}
+
+// 转码服务接口定义
+service TranscodeService {
+    // 创建转码任务（管理员）
+    TranscodeJobResponse CreateTranscodeJob(1: TranscodeJobCreateRequest req) (api.post="/api/v1/transcode/jobs")
+
+    // 列出转码任务（管理员）
+    TranscodeJobListResponse ListTranscodeJobs(1: TranscodeJobListRequest req) (api.get="/api/v1/transcode/jobs")
+
+    // 获取转码任务（管理员）
+    TranscodeJobResponse GetTranscodeJob(1: TranscodeJobRequest req) (api.get="/api/v1/transcode/jobs/:job_id")
+
+    // 修改等待中的转码任务的优先级（管理员）
+    TranscodeJobResponse UpdateTranscodeJobPriority(1: TranscodeJobPriorityRequest req) (api.put="/api/v1/transcode/jobs/:job_id/priority")
+
+    // 取消转码任务（管理员）
+    TranscodeJobResponse CancelTranscodeJob(1: TranscodeJobRequest req) (api.delete="/api/v1/transcode/jobs/:job_id")
+
+    // 重新排队已取消或进入死信状态的转码任务（管理员）
+    TranscodeJobResponse RequeueTranscodeJob(1: TranscodeJobRequest req) (api.post="/api/v1/transcode/jobs/:job_id/requeue")
+}
+
+// worker任务接口定义，使用worker密钥认证
+service WorkerService {
+    // 领取优先级最高的处理任务，没有待执行的任务时job为空
+    WorkerJobResponse ClaimWorkerJob(1: WorkerJobClaimRequest req) (api.post="/api/v1/worker/jobs/claim")
+
+    // 续租正在执行的处理任务，任务已被取消或租约已过期时返回6904
+    WorkerJobResponse HeartbeatWorkerJob(1: WorkerJobHeartbeatRequest req) (api.post="/api/v1/worker/jobs/:job_id/heartbeat")
+
+    // 上报处理任务结果
+    WorkerJobResponse CompleteWorkerJob(1: WorkerJobCompleteRequest req) (api.post="/api/v1/worker/jobs/:job_id/complete")
+}
+
+// 管理服务接口定义
+service AdminService {
+    // 获取存储使用统计（管理员），存储扫描结果过期时在后台重新扫描
+    AdminStatsResponse GetAdminStats() (api.get="/api/v1/admin/stats")
+
+    // 撤销播放令牌（管理员），按用户或视频撤销此前签发的全部播放令牌
+    PlaybackRevokeResponse RevokePlaybackTokens(1: PlaybackRevokeRequest req) (api.post="/api/v1/admin/playback/revoke")
+
+    // 列出内容审核队列（管理员）
+    ModerationListResponse ListModerationQueue(1: ModerationListRequest req) (api.get="/api/v1/admin/moderation")
+
+    // 审核视频（管理员），通过后视频按可见性正常发布，拒绝后只有上传者和管理员可见
+    ModerationReviewResponse ReviewModeration(1: ModerationReviewRequest req) (api.post="/api/v1/admin/moderation/:video_id")
+
+    // 立即备份视频元数据和配置文件到备份存储桶（管理员）
+    BackupResponse CreateBackup() (api.post="/api/v1/admin/backups")
+
+    // 列出备份存储桶中的备份（管理员）
+    BackupListResponse ListBackups() (api.get="/api/v1/admin/backups")
+
+    // 用备份替换全部视频元数据（管理员），配置文件内容在响应中返回
+    BackupRestoreResponse RestoreBackup(1: BackupRestoreRequest req) (api.post="/api/v1/admin/backups/restore")
+
+    // 获取存储复制统计并对比主存储和副本，返回一致性报告（管理员）
+    ReplicationReportResponse GetReplicationReport() (api.get="/api/v1/admin/replication")
+
+    // 在后台对比主存储和副本并补齐缺失的文件（管理员）
+    ReplicationReportResponse SyncReplication() (api.post="/api/v1/admin/replication/sync")
+
+    // 检查每个视频的文件、缩略图和动态预览是否存在且与元数据一致，可选修复（管理员）
+    ConsistencyResponse CheckConsistency(1: ConsistencyRequest req) (api.get="/api/v1/admin/consistency")
+
+    // 导出全部视频元数据目录（管理员），用于迁移和审计
+    CatalogExportResponse ExportCatalog(1: CatalogExportRequest req) (api.get="/api/v1/admin/catalog")
+
+    // 导入视频元数据目录（管理员），按文件ID或校验和匹配已有视频
+    CatalogImportResponse ImportCatalog(1: CatalogImportRequest req) (api.post="/api/v1/admin/catalog/import")
+}
+
+// 分享服务接口定义
+service ShareService {
+    // 创建视频分享链接（需登录）
+    ShareResponse CreateShare(1: ShareCreateRequest req) (api.post="/api/v1/videos/:video_id/shares")
+
+    // 列出分享链接（需登录），管理员列出全部，其他用户列出自己创建的
+    ShareListResponse ListShares(1: ShareListRequest req) (api.get="/api/v1/shares")
+
+    // 撤销分享链接（需登录），只能撤销自己创建的，管理员可以撤销全部
+    ShareResponse RevokeShare(1: ShareRevokeRequest req) (api.delete="/api/v1/shares/:token")
+
+    // 访问分享链接（不需要登录），浏览器访问时返回播放页面
+    ShareResolveResponse ResolveShare(1: ShareResolveRequest req) (api.get="/s/:token")
+}
+
+// 嵌入服务接口定义
+service EmbedService {
+    // 嵌入播放器页面（不需要登录），用于iframe嵌入
+    EmbedPlayerResponse GetEmbedPlayer(1: EmbedPlayerRequest req) (api.get="/embed/:video_id")
+
+    // oEmbed接口（不需要登录），供Wiki和聊天工具展开视频链接
+    OEmbedResponse GetOEmbed(1: OEmbedRequest req) (api.get="/oembed")
 }
 
 // 系统服务接口定义